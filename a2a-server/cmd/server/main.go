@@ -2,19 +2,40 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/netip"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/a2aclient"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/agentcard"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/alerts"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/events"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/observability"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/server"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+	"github.com/bhatti/mcp-a2a-go/shared/auth"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/bhatti/mcp-a2a-go/shared/health"
+	"github.com/bhatti/mcp-a2a-go/shared/httpserver"
+	"github.com/bhatti/mcp-a2a-go/shared/lifecycle"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/mtls"
+	"github.com/bhatti/mcp-a2a-go/shared/ratelimit"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
@@ -28,39 +49,154 @@ func main() {
 
 	// Load configuration
 	port := getEnv("PORT", defaultPort)
+	cfg := loadConfig()
+
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrateCommand(cfg, os.Args[2:])
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	log.Println("Initializing A2A Cost-Controlled Research Assistant...")
+	// Structured logging; the standard log package writes through it too
+	if _, err := logging.Setup(os.Stderr, cfg.Logging); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid logging config: %v\n", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Initializing A2A Cost-Controlled Research Assistant")
 
 	// Initialize observability
-	log.Println("Setting up OpenTelemetry...")
-	cfg := loadConfig()
+	slog.Info("Setting up OpenTelemetry")
 	telemetry, err := observability.NewTelemetry(ctx, observability.Config{
-		ServiceName:    serverName,
-		ServiceVersion: serverVersion,
-		Environment:    cfg.Environment,
-		OTLPEndpoint:   cfg.OTLPEndpoint,
-		SamplingRate:   cfg.SamplingRate,
-		EnableTracing:  cfg.EnableTracing,
-		EnableMetrics:  cfg.EnableMetrics,
+		ServiceName:      serverName,
+		ServiceVersion:   serverVersion,
+		Environment:      cfg.Environment,
+		OTLPEndpoint:     cfg.OTLPEndpoint,
+		SamplingRate:     cfg.SamplingRate,
+		EnableTracing:    cfg.EnableTracing,
+		EnableMetrics:    cfg.EnableMetrics,
+		HistogramBuckets: cfg.HistogramBuckets,
+		Exemplars:        cfg.Exemplars,
 	})
 	if err != nil {
-		log.Fatalf("Failed to initialize telemetry: %v", err)
+		logging.Fatal("Failed to initialize telemetry", "error", err)
 	}
 	defer func() {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := telemetry.Shutdown(shutdownCtx); err != nil {
-			log.Printf("Error shutting down telemetry: %v", err)
+			slog.Error("Error shutting down telemetry", "error", err)
 		}
 	}()
-	log.Println("OpenTelemetry initialized successfully")
+	slog.Info("OpenTelemetry initialized successfully")
+
+	// TLS certificate of the listener and of calls to the MCP server, reloaded on rotation
+	var certificates *mtls.Certificates
+	if cfg.TLS.Enabled() {
+		if err := cfg.TLS.Validate(); err != nil {
+			logging.Fatal("Invalid TLS config", "error", err)
+		}
+		certificates, err = mtls.Load(cfg.TLS)
+		if err != nil {
+			logging.Fatal("Failed to load TLS certificate", "cert_file", cfg.TLS.CertFile, "error", err)
+		}
+		certificates.Start(ctx)
+		cfg.MCP.TLS = certificates.ClientConfig()
+		slog.Info("TLS enabled", "mutual", cfg.TLS.CAFile != "", "identities", len(cfg.TLS.Identities))
+	}
+
+	// Readiness probes ping each dependency as it is set up
+	probes := health.NewChecker(cfg.HealthCheckTimeout)
+	if cfg.EnableTracing {
+		probes.AddOptional("otlp_exporter", telemetry.CheckExporter)
+	}
 
 	// Initialize stores
-	taskStore := tasks.NewMemoryStore()
+	if cfg.AutoMigrate && (cfg.TaskStore == "postgres" || cfg.CostStore == "postgres") {
+		if err := autoMigrate(ctx, cfg.TaskDB); err != nil {
+			logging.Fatal("Failed to migrate the database schema", "error", err)
+		}
+	}
+	var taskStore tasks.Store
+	switch cfg.TaskStore {
+	case "memory":
+		taskStore = tasks.NewMemoryStore()
+	case "postgres":
+		pgStore, err := tasks.NewPostgresStore(ctx, cfg.TaskDB)
+		if err != nil {
+			logging.Fatal("Failed to connect to task database", "error", err)
+		}
+		defer pgStore.Close()
+		taskStore = pgStore
+		probes.Add("postgres", pgStore.Ping)
+		slog.Info("Using Postgres task store", "host", cfg.TaskDB.Host, "dbname", cfg.TaskDB.DBName)
+	default:
+		logging.Fatal("Unknown task store", "task_store", cfg.TaskStore)
+	}
+
+	// Replicas sharing a task queue share task events too, so streams and webhooks follow
+	// tasks other replicas process
+	if cfg.TaskQueue == "redis" {
+		eventsRedis := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+		defer eventsRedis.Close()
+		sharedEvents, err := tasks.NewRedisEvents(ctx, taskStore, eventsRedis, cfg.Queue.Stream+":events")
+		if err != nil {
+			logging.Fatal("Failed to share task events", "redis_addr", cfg.RedisAddr, "error", err)
+		}
+		defer sharedEvents.Close()
+		taskStore = sharedEvents
+		slog.Info("Sharing task events through Redis", "channel", cfg.Queue.Stream+":events")
+	}
+
+	// Publish task lifecycle events for external consumers such as billing and analytics
+	if cfg.Events.Backend != "" {
+		publisher, err := events.NewPublisher(ctx, cfg.Events)
+		if err != nil {
+			logging.Fatal("Failed to connect to the event broker", "backend", cfg.Events.Backend, "error", err)
+		}
+		eventStore := events.NewStore(taskStore, publisher, cfg.Events)
+		if telemetry.Metrics != nil {
+			eventStore.SetMetrics(telemetry.Metrics)
+		}
+		defer eventStore.Close()
+		taskStore = eventStore
+		slog.Info("Task event publishing enabled", "backend", cfg.Events.Backend, "topic", cfg.Events.Topic)
+	}
 	agentStore := agentcard.NewStore()
-	costTracker := cost.NewTracker()
+	var costTracker cost.Tracker
+	switch cfg.CostStore {
+	case "memory":
+		costTracker = cost.NewMemoryTracker()
+	case "postgres":
+		pool, err := tasks.Connect(ctx, cfg.TaskDB)
+		if err != nil {
+			logging.Fatal("Failed to connect to cost database", "error", err)
+		}
+		defer pool.Close()
+		costTracker = cost.NewPostgresTracker(pool)
+		probes.Add("postgres_costs", pool.Ping)
+		slog.Info("Using Postgres cost tracker", "host", cfg.TaskDB.Host, "dbname", cfg.TaskDB.DBName)
+	default:
+		logging.Fatal("Unknown cost store", "cost_store", cfg.CostStore)
+	}
 	budgetManager := cost.NewBudgetManager()
 
+	// Alert on budget thresholds before the journal is replayed, so those crossed before a
+	// restart are not alerted on again
+	if channels := cfg.BudgetAlerts.channels(); len(channels) > 0 {
+		dispatcher := alerts.NewDispatcher(cfg.BudgetAlerts.Timeout, channels...)
+		defer dispatcher.Close()
+		budgetManager.SetAlerts(cfg.BudgetAlerts.Thresholds, dispatcher)
+		slog.Info("Budget alerts enabled", "thresholds", cfg.BudgetAlerts.Thresholds, "channels", len(channels))
+	}
+
 	// Create agent card
 	agentCard := protocol.NewAgentCard(
 		serverName,
@@ -110,8 +246,10 @@ func main() {
 	})
 
 	agentCard.AddCapability(protocol.Capability{
-		Name:        "summarize_document",
-		Description: "Generate concise summaries of research documents",
+		Name:             "summarize_document",
+		Description:      "Generate concise summaries of research documents",
+		SubstituteGroup:  "summarize",
+		EstimatedCostUSD: 0.01,
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -129,32 +267,280 @@ func main() {
 		},
 	})
 
+	// Cheaper, lower quality summarizer that speculative tasks can race against summarize_document
+	agentCard.AddCapability(protocol.Capability{
+		Name:             "summarize_document_fast",
+		Description:      "Generate quick extractive summaries of documents",
+		SubstituteGroup:  "summarize",
+		EstimatedCostUSD: 0.004,
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"document": map[string]interface{}{
+					"type":        "string",
+					"description": "Document text to summarize",
+				},
+			},
+			"required": []string{"document"},
+		},
+	})
+
 	// Register agent
 	if err := agentStore.Register(ctx, agentCard); err != nil {
-		log.Fatalf("Failed to register agent: %v", err)
+		logging.Fatal("Failed to register agent", "error", err)
+	}
+	slog.Info("Registered agent", "name", agentCard.Name, "version", agentCard.Version)
+
+	// Rebuild budgets and usage from the write-ahead journal, reconciling charges and usage
+	// records a crash left unmatched
+	var usageJournal *cost.FileJournal
+	if cfg.UsageJournalPath != "" {
+		journal, err := cost.OpenFileJournal(cfg.UsageJournalPath)
+		if err != nil {
+			logging.Fatal("Failed to open usage journal", "path", cfg.UsageJournalPath, "error", err)
+		}
+		defer journal.Close()
+		usageJournal = journal
+		report, err := cost.Recover(ctx, journal, budgetManager, costTracker, func(ctx context.Context, taskID string) (bool, error) {
+			_, err := taskStore.Get(ctx, taskID)
+			if errors.Is(err, tasks.ErrTaskNotFound) {
+				return false, nil
+			}
+			return err == nil, err
+		})
+		if err != nil {
+			logging.Fatal("Failed to recover usage journal", "path", cfg.UsageJournalPath, "error", err)
+		}
+		slog.Info("Recovered usage journal", "path", cfg.UsageJournalPath, "entries", report.Entries,
+			"refunds", report.Refunds, "refund_usd", report.RefundUSD, "charges", report.Charges, "charge_usd", report.ChargeUSD)
 	}
-	log.Printf("Registered agent: %s v%s", agentCard.Name, agentCard.Version)
 
 	// Set up demo budgets
 	setupDemoBudgets(ctx, budgetManager)
 
 	// Create server with telemetry
 	srv := server.NewServer(taskStore, agentStore, costTracker, budgetManager, agentCard, telemetry)
+	srv.SetSpeculationCostCap(cfg.SpeculationCostCapUSD)
+	srv.SetAdminToken(cfg.AdminToken)
+	srv.SetBillingToken(cfg.BillingToken)
+	srv.SetBodyLimits(cfg.Body)
+	srv.SetMaxPending(cfg.TaskMaxPending)
+	srv.SetMaxInlineFileBytes(cfg.MaxInlineFileBytes)
+	if usageJournal != nil {
+		srv.SetUsageJournal(usageJournal)
+	}
+
+	// Authenticate callers with the MCP server's JWTs and rate limit each user
+	if cfg.JWTPublicKeys != "" {
+		validator, err := auth.NewJWTValidator(auth.Config{
+			PublicKeyPEM: cfg.JWTPublicKeys,
+			Issuer:       cfg.JWTIssuer,
+			Audience:     cfg.JWTAudience,
+		})
+		if err != nil {
+			logging.Fatal("Failed to create JWT validator", "error", err)
+		}
+		srv.SetAuth(auth.NewMiddleware(validator))
+		slog.Info("JWT authentication enabled", "issuer", cfg.JWTIssuer, "audience", cfg.JWTAudience)
+	} else {
+		slog.Warn("JWT_PUBLIC_KEYS is not set: tasks are created for the user_id requests name")
+	}
+	if cfg.RateLimit > 0 {
+		if cfg.JWTPublicKeys == "" {
+			logging.Fatal("RATE_LIMIT requires JWT_PUBLIC_KEYS: requests are limited per authenticated user")
+		}
+		if err := ratelimit.ValidatePolicy(cfg.RateLimitFailurePolicy); err != nil {
+			logging.Fatal("Invalid RATE_LIMIT_FAILURE_POLICY", "error", err)
+		}
+		rateLimitRedis := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+		defer rateLimitRedis.Close()
+		limiter := ratelimit.NewLimiter(rateLimitRedis, "a2a:ratelimit", cfg.RateLimit)
+		limiter.SetFailurePolicy(cfg.RateLimitFailurePolicy, cfg.RateLimitLocalFallback)
+		srv.SetRateLimiter(limiter)
+		slog.Info("Rate limiting enabled", "requests_per_minute", cfg.RateLimit, "redis_addr", cfg.RedisAddr,
+			"failure_policy", cfg.RateLimitFailurePolicy)
+	}
+
+	// Deliver task state transitions to the webhooks clients register
+	if cfg.WebhooksEnabled {
+		notifier := webhook.NewNotifier(taskStore, cfg.Webhook)
+		if telemetry.Metrics != nil {
+			notifier.SetMetrics(telemetry.Metrics)
+		}
+		defer notifier.Close()
+		srv.SetNotifier(notifier)
+		slog.Info("Push notifications enabled", "max_attempts", cfg.Webhook.MaxAttempts, "timeout", cfg.Webhook.Timeout.String(),
+			"allowed_networks", len(cfg.Webhook.AllowedNetworks))
+	}
+
+	// Track in-flight requests and SSE streams so shutdown can drain them
+	lifecycleManager := lifecycle.NewManager()
+	if telemetry.Metrics != nil {
+		lifecycleManager.SetRecorder(telemetry.Metrics)
+	}
+	srv.SetLifecycle(lifecycleManager)
+	probes.SetDraining(lifecycleManager.Draining())
+	srv.SetHealth(probes)
+	if certificates != nil {
+		var clientCerts *mtls.Middleware
+		if cfg.TLS.CAFile != "" {
+			clientCerts = mtls.NewMiddleware(cfg.TLS.Identities)
+		}
+		srv.SetTLS(certificates.ServerConfig(), clientCerts)
+	}
 
 	// Start task processor for background task execution
-	processor := server.NewTaskProcessor(taskStore, 1*time.Second)
+	if cfg.TaskPollInterval <= 0 {
+		logging.Fatal("TASK_POLL_INTERVAL must be positive", "task_poll_interval", cfg.TaskPollInterval)
+	}
+	processor := server.NewTaskProcessor(taskStore, cfg.TaskPollInterval)
+	processor.SetSpeculation(agentStore, cfg.SpeculationCostCapUSD)
+
+	// Run the advertised capabilities with their executors; any left without one are simulated
+	executors := capabilities.NewRegistry()
+	executors.SetTimeouts(cfg.CapabilityTimeout, cfg.CapabilityTimeouts)
+	// Price tokens from the pricing file, reloading it when it changes; the admin API can
+	// replace the prices too
+	if cfg.PricingFile != "" {
+		pricing := executors.Pricing()
+		if err := pricing.Load(cfg.PricingFile); err != nil {
+			logging.Fatal("Failed to load pricing file", "path", cfg.PricingFile, "error", err)
+		}
+		go pricing.Watch(ctx, cfg.PricingFile, cfg.PricingReloadInterval)
+		slog.Info("Model pricing loaded", "path", cfg.PricingFile, "version", pricing.Version(),
+			"reload_interval", cfg.PricingReloadInterval.String())
+	}
+	if cfg.TokenizerBPEFile != "" {
+		encoding, err := tokens.LoadBPEFile(cfg.TokenizerBPEFile)
+		if err != nil {
+			logging.Fatal("Failed to load tokenizer", "error", err)
+		}
+		tokens.SetBPE(encoding)
+	}
+	if missing := capabilities.RegisterBuiltins(executors, agentCard); len(missing) > 0 {
+		slog.Warn("Capabilities without executors are simulated", "capabilities", missing)
+	}
+	// With an MCP server, paper searches run over the caller's documents with its RAG tools
+	if cfg.MCP.URL != "" {
+		bridge := capabilities.NewMCPBridge(cfg.MCP)
+		searchPapers, _ := agentCard.Capability("search_papers")
+		executors.Register(searchPapers, bridge.SearchPapers(cfg.MCPSearchTool))
+		slog.Info("search_papers bridged to MCP server", "url", cfg.MCP.URL, "tool", cfg.MCPSearchTool)
+	}
+	processor.SetExecutors(executors, costTracker)
+	srv.SetExecutors(executors)
+	processor.SetBudgets(budgetManager)
+
+	// Delegate the capabilities left without an executor to remote agents that offer them
+	if len(cfg.RemoteAgents) > 0 {
+		delegator := a2aclient.NewDelegator(cfg.Delegation)
+		for _, remote := range cfg.RemoteAgents {
+			clientConfig := cfg.RemoteAgent
+			clientConfig.URL = remote.URL
+			if err := delegator.Add(ctx, remote.Name, a2aclient.New(clientConfig), cfg.RemoteAgentBudgetsUSD[remote.Name]); err != nil {
+				logging.Fatal("Failed to add remote agent", "remote_agent", remote.Name, "error", err)
+			}
+			slog.Info("Delegating to remote agent", "remote_agent", remote.Name, "url", remote.URL,
+				"budget_usd", cfg.RemoteAgentBudgetsUSD[remote.Name])
+		}
+		srv.SetDelegator(delegator)
+		processor.SetDelegator(delegator)
+	}
+	// Keep large artifact file parts out of the task store
+	var artifactStore blobs.Store
+	switch cfg.ArtifactStore {
+	case "":
+	case "filesystem":
+		artifactStore, err = blobs.NewFileStore(cfg.ArtifactDir)
+		slog.Info("Storing large artifacts on the filesystem", "dir", cfg.ArtifactDir)
+	case "s3":
+		artifactStore, err = blobs.NewS3Store(cfg.ArtifactS3)
+		slog.Info("Storing large artifacts in S3", "endpoint", cfg.ArtifactS3.Endpoint, "bucket", cfg.ArtifactS3.Bucket)
+	default:
+		logging.Fatal("Unknown artifact store", "artifact_store", cfg.ArtifactStore)
+	}
+	if err != nil {
+		logging.Fatal("Failed to set up artifact store", "artifact_store", cfg.ArtifactStore, "error", err)
+	}
+	if artifactStore != nil {
+		processor.SetArtifactStore(artifactStore, cfg.ArtifactInlineLimit)
+		srv.SetArtifactStore(artifactStore)
+	}
+	processor.SetScheduling(cfg.Scheduling)
+	processor.SetMetrics(telemetry.Metrics)
+	processor.SetLease(cfg.TaskLeaseTTL, cfg.TaskMaxAttempts)
+
+	// A shared queue lets several replicas process tasks; without one each replica polls its store
+	switch cfg.TaskQueue {
+	case "":
+	case "redis":
+		redisClient := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+		defer redisClient.Close()
+		queue, err := tasks.NewRedisQueue(ctx, redisClient, cfg.Queue)
+		if err != nil {
+			logging.Fatal("Failed to set up task queue", "redis_addr", cfg.RedisAddr, "error", err)
+		}
+		srv.SetQueue(queue)
+		probes.Add("redis", func(ctx context.Context) error { return redisClient.Ping(ctx).Err() })
+		processor.SetQueue(queue, cfg.QueueWorkers, cfg.QueueMaxDeliveries)
+		slog.Info("Using Redis task queue", "redis_addr", cfg.RedisAddr, "stream", cfg.Queue.Stream,
+			"consumer", cfg.Queue.Consumer, "workers", cfg.QueueWorkers)
+	default:
+		logging.Fatal("Unknown task queue", "task_queue", cfg.TaskQueue)
+	}
 	processor.Start(ctx)
 	defer processor.Stop()
-	log.Println("Task processor initialized")
+	slog.Info("Task processor initialized")
+
+	// Create the tasks of recurring schedules, kept alongside the tasks
+	var scheduleStore schedules.Store
+	switch cfg.TaskStore {
+	case "memory":
+		scheduleStore = schedules.NewMemoryStore()
+	case "postgres":
+		pool, err := tasks.Connect(ctx, cfg.TaskDB)
+		if err != nil {
+			logging.Fatal("Failed to connect to schedule database", "error", err)
+		}
+		defer pool.Close()
+		scheduleStore = schedules.NewPostgresStore(pool)
+		probes.Add("postgres_schedules", pool.Ping)
+	}
+	if cfg.ScheduleInterval <= 0 {
+		logging.Fatal("SCHEDULE_INTERVAL must be positive", "schedule_interval", cfg.ScheduleInterval)
+	}
+	srv.SetSchedules(scheduleStore)
+	go srv.RunSchedules(ctx, cfg.ScheduleInterval)
+	slog.Info("Schedules enabled", "interval", cfg.ScheduleInterval.String())
+
+	// Garbage collect finished tasks past their retention, archiving them first
+	if cfg.TaskGCInterval <= 0 {
+		logging.Fatal("TASK_GC_INTERVAL must be positive", "task_gc_interval", cfg.TaskGCInterval)
+	}
+	var taskArchive tasks.Archive
+	if cfg.TaskArchivePath != "" {
+		archive, err := tasks.OpenFileArchive(cfg.TaskArchivePath)
+		if err != nil {
+			logging.Fatal("Failed to open task archive", "path", cfg.TaskArchivePath, "error", err)
+		}
+		defer archive.Close()
+		taskArchive = archive
+	}
+	srv.SetRetention(cfg.TaskRetention, taskArchive)
+	go srv.RunTaskGC(ctx, cfg.TaskGCInterval)
+	slog.Info("Task garbage collection enabled", "retention", cfg.TaskRetention.String(),
+		"interval", cfg.TaskGCInterval.String(), "archive", cfg.TaskArchivePath)
 
 	// Start server in goroutine
 	addr := ":" + port
 	errCh := make(chan error, 1)
 	go func() {
-		log.Printf("Starting A2A server on %s", addr)
-		log.Printf("Agent Card available at: http://localhost:%s/agent", port)
-		log.Printf("Tasks endpoint: http://localhost:%s/tasks", port)
-		log.Printf("Health check: http://localhost:%s/health", port)
+		slog.Info("A2A endpoints",
+			"agent_card", "http://localhost:"+port+"/agent",
+			"tasks", "http://localhost:"+port+"/tasks",
+			"health_check", "http://localhost:"+port+health.LivenessPath,
+			"readiness_check", "http://localhost:"+port+health.ReadinessPath,
+		)
 		errCh <- srv.Start(addr)
 	}()
 
@@ -164,15 +550,30 @@ func main() {
 
 	select {
 	case err := <-errCh:
-		log.Fatalf("Server error: %v", err)
+		logging.Fatal("Server error", "error", err)
 	case sig := <-sigCh:
-		log.Printf("Received signal: %v. Shutting down gracefully...", sig)
+		slog.Info("Received signal, shutting down gracefully", "signal", sig.String())
+	}
+
+	// Refuse new requests, end SSE streams and wait for in-flight requests; whatever is
+	// still running after the drain period is cancelled
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.DrainTimeout)
+	defer cancelDrain()
+	if err := lifecycleManager.Drain(drainCtx); err != nil {
+		slog.Warn("Shutdown drain incomplete", "error", err)
 	}
 
-	log.Println("A2A server shutdown complete")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server forced to shutdown", "error", err)
+	}
+
+	slog.Info("A2A server shutdown complete")
 }
 
-// setupDemoBudgets configures demo budgets for testing
+// setupDemoBudgets configures demo budgets for testing, keeping budgets recovered from
+// the usage journal
 func setupDemoBudgets(ctx context.Context, manager *cost.BudgetManager) {
 	// Demo users with different budget tiers
 	budgets := map[string]float64{
@@ -182,10 +583,13 @@ func setupDemoBudgets(ctx context.Context, manager *cost.BudgetManager) {
 	}
 
 	for userID, limit := range budgets {
+		if _, err := manager.GetBudget(ctx, userID); err == nil {
+			continue
+		}
 		if err := manager.SetBudget(ctx, userID, limit); err != nil {
-			log.Printf("Warning: Failed to set budget for %s: %v", userID, err)
+			slog.Warn("Failed to set budget", "user_id", userID, "error", err)
 		} else {
-			log.Printf("Set budget for %s: $%.2f/month", userID, limit)
+			slog.Info("Set monthly budget", "user_id", userID, "limit_usd", limit)
 		}
 	}
 }
@@ -198,17 +602,301 @@ type Config struct {
 	SamplingRate  float64
 	EnableTracing bool
 	EnableMetrics bool
+	Logging       logging.Config
+	// HistogramBuckets overrides the bucket boundaries of duration histograms by name
+	HistogramBuckets map[string][]float64
+	// Exemplars link histogram buckets to the traces of sampled requests
+	Exemplars bool
+	// DrainTimeout is how long shutdown waits for in-flight requests before cancelling them
+	DrainTimeout time.Duration
+	// Body limits request bodies, after decompressing gzip bodies, and compresses large responses
+	Body httpserver.BodyConfig
+	// HealthCheckTimeout is how long each dependency has to answer a readiness probe
+	HealthCheckTimeout time.Duration
+	// SpeculationCostCapUSD bounds the estimated cost of capabilities raced for one speculative task
+	SpeculationCostCapUSD float64
+	// AdminToken authenticates the admin endpoints, e.g. budgets set by MCP tenant onboarding
+	AdminToken string
+	// BillingToken lets billing systems and the demo UI read budgets and usage
+	BillingToken string
+	// JWTPublicKeys, PEM holding one or more keys, require the agent card, task and JSON-RPC
+	// routes to carry a JWT like the MCP server's; tasks are then charged to the token's user
+	JWTPublicKeys string
+	JWTIssuer     string
+	JWTAudience   string
+	// RateLimit is how many requests a minute each authenticated user may make, zero for no
+	// limit. Counts are kept in Redis at RedisAddr; while it is unreachable requests are
+	// limited per server with RateLimitLocalFallback, or else by RateLimitFailurePolicy.
+	RateLimit              int
+	RateLimitFailurePolicy string
+	RateLimitLocalFallback bool
+	// WebhooksEnabled lets clients register push notification webhooks for their tasks
+	WebhooksEnabled bool
+	Webhook         webhook.Config
+	// Events publishes task lifecycle events to NATS JetStream or Kafka when its backend is set
+	Events events.Config
+	// TaskStore selects where tasks are kept: "memory" or "postgres"
+	TaskStore string
+	TaskDB    tasks.PostgresConfig
+	// AutoMigrate applies pending schema migrations of the task database at startup when
+	// tasks or usage records are kept in Postgres
+	AutoMigrate bool
+	// CostStore selects where usage records are kept: "memory" or "postgres", in the task database
+	CostStore string
+	// PricingFile, when set, is a YAML or JSON price table replacing the built-in model
+	// prices; it is checked for changes every PricingReloadInterval
+	PricingFile           string
+	PricingReloadInterval time.Duration
+	// TokenizerBPEFile, when set, is a tiktoken rank file (e.g. cl100k_base.tiktoken) that
+	// the prompt tokens of OpenAI models are counted with exactly; otherwise they are estimated
+	TokenizerBPEFile string
+	// TaskQueue selects how tasks reach the task processors: "" polls the task store, "redis"
+	// shares a Redis stream between replicas
+	TaskQueue string
+	RedisAddr string
+	Queue     tasks.RedisQueueConfig
+	// QueueWorkers is how many tasks a replica processes at once from the queue
+	QueueWorkers int
+	// QueueMaxDeliveries is how often a task is delivered before it is dead-lettered
+	QueueMaxDeliveries int
+	// TaskLeaseTTL is how long a running task's lease lasts without a heartbeat before the
+	// task is requeued; zero disables leases. A task whose lease expired after
+	// TaskMaxAttempts executions is failed instead.
+	TaskLeaseTTL    time.Duration
+	TaskMaxAttempts int
+	// Scheduling orders pending tasks by priority, with aging, and limits how many run at
+	// once overall, per priority and per capability
+	Scheduling server.SchedulingPolicy
+	// TaskPollInterval is how often a processor without a queue looks for pending tasks
+	TaskPollInterval time.Duration
+	// TaskMaxPending refuses new tasks while that many are pending; zero for no limit
+	TaskMaxPending int
+	// MaxInlineFileBytes refuses messages carrying a larger file inline; zero for no limit
+	MaxInlineFileBytes int
+	// ScheduleInterval is how often due schedules are checked for tasks to create
+	ScheduleInterval time.Duration
+	// TaskRetention is how long finished tasks without a TTL are kept, zero for ever;
+	// every TaskGCInterval those past it are deleted, appended first to the JSON lines
+	// file at TaskArchivePath when set
+	TaskRetention   time.Duration
+	TaskGCInterval  time.Duration
+	TaskArchivePath string
+	// CapabilityTimeout bounds each capability execution; CapabilityTimeouts overrides it per capability
+	CapabilityTimeout  time.Duration
+	CapabilityTimeouts map[string]time.Duration
+	// MCP, when its URL is set, bridges search_papers to MCPSearchTool on the MCP server
+	MCP           capabilities.MCPBridgeConfig
+	MCPSearchTool string
+	// TLS serves HTTPS, with mutual TLS when a CA is set; the certificate also
+	// authenticates calls to the MCP server
+	TLS mtls.Config
+	// UsageJournalPath is the write-ahead journal of budget charges and usage records; empty
+	// keeps them in memory only
+	UsageJournalPath string
+	// RemoteAgents run the capabilities this agent lacks, tried in order; RemoteAgentBudgetsUSD
+	// caps what is spent on each. RemoteAgent holds the settings shared by their clients.
+	RemoteAgents          []remoteAgent
+	RemoteAgentBudgetsUSD map[string]float64
+	RemoteAgent           a2aclient.Config
+	Delegation            a2aclient.DelegatorConfig
+	// BudgetAlerts notify the configured channels when budgets cross alert thresholds
+	BudgetAlerts budgetAlertsConfig
+	// ArtifactStore selects where artifact file parts larger than ArtifactInlineLimit bytes
+	// are kept: "" keeps them with the task, "filesystem" under ArtifactDir, "s3" in ArtifactS3
+	ArtifactStore       string
+	ArtifactInlineLimit int
+	ArtifactDir         string
+	ArtifactS3          blobs.S3Config
+}
+
+// budgetAlertsConfig selects the channels budget alerts are sent to; a channel is used
+// when its URL or SMTP address is set
+type budgetAlertsConfig struct {
+	// Thresholds are percentages of a budget's limit
+	Thresholds []float64
+	// Timeout bounds each delivery
+	Timeout time.Duration
+	Webhook alerts.Webhook
+	Slack   alerts.Slack
+	Email   alerts.Email
+}
+
+// channels returns the configured alert channels
+func (c budgetAlertsConfig) channels() []alerts.Channel {
+	var channels []alerts.Channel
+	if c.Webhook.URL != "" {
+		channels = append(channels, &c.Webhook)
+	}
+	if c.Slack.WebhookURL != "" {
+		channels = append(channels, &c.Slack)
+	}
+	if c.Email.Addr != "" && len(c.Email.To) > 0 {
+		channels = append(channels, &c.Email)
+	}
+	return channels
+}
+
+// remoteAgent names the base URL of a remote agent
+type remoteAgent struct {
+	Name string
+	URL  string
 }
 
 // loadConfig loads configuration from environment variables
 func loadConfig() Config {
+	queueDefaults := tasks.DefaultRedisQueueConfig()
 	return Config{
-		Port:          getEnv("PORT", defaultPort),
-		Environment:   getEnv("ENVIRONMENT", "development"),
-		OTLPEndpoint:  getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "jaeger:4318"),
-		SamplingRate:  getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
-		EnableTracing: getEnvBool("OTEL_ENABLE_TRACING", true),
-		EnableMetrics: getEnvBool("OTEL_ENABLE_METRICS", true),
+		Port:             getEnv("PORT", defaultPort),
+		Environment:      getEnv("ENVIRONMENT", "development"),
+		OTLPEndpoint:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "jaeger:4318"),
+		SamplingRate:     getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
+		EnableTracing:    getEnvBool("OTEL_ENABLE_TRACING", true),
+		EnableMetrics:    getEnvBool("OTEL_ENABLE_METRICS", true),
+		HistogramBuckets: getEnvHistogramBuckets("OTEL_HISTOGRAM_BUCKETS"),
+		Exemplars:        getEnvBool("OTEL_EXEMPLARS", true),
+		Logging: logging.Config{
+			Level:     getEnv("LOG_LEVEL", "info"),
+			Format:    getEnv("LOG_FORMAT", logging.FormatJSON),
+			AddSource: getEnvBool("LOG_ADD_SOURCE", false),
+		},
+		SpeculationCostCapUSD: getEnvFloat("SPECULATION_COST_CAP_USD", 0.02),
+		DrainTimeout:          getEnvDuration("SHUTDOWN_DRAIN_TIMEOUT", 10*time.Second),
+		Body: httpserver.BodyConfig{
+			MaxRequestBytes: int64(getEnvInt("MAX_REQUEST_BYTES", httpserver.DefaultMaxRequestBytes)),
+			Gzip:            getEnvBool("GZIP_RESPONSES", true),
+			GzipMinBytes:    getEnvInt("GZIP_MIN_BYTES", httpserver.DefaultGzipMinBytes),
+		},
+		HealthCheckTimeout: getEnvDuration("HEALTH_CHECK_TIMEOUT", health.DefaultTimeout),
+		AdminToken:         getEnv("A2A_ADMIN_TOKEN", ""),
+		BillingToken:       getEnv("A2A_BILLING_TOKEN", ""),
+		JWTPublicKeys:      getEnv("JWT_PUBLIC_KEYS", ""),
+		JWTIssuer:          getEnv("JWT_ISSUER", "mcp-server-demo"),
+		JWTAudience:        getEnv("JWT_AUDIENCE", "mcp-server"),
+		WebhooksEnabled:    getEnvBool("WEBHOOKS_ENABLED", true),
+
+		RateLimit:              getEnvInt("RATE_LIMIT", 0),
+		RateLimitFailurePolicy: getEnv("RATE_LIMIT_FAILURE_POLICY", ratelimit.FailOpen),
+		RateLimitLocalFallback: getEnvBool("RATE_LIMIT_LOCAL_FALLBACK", false),
+		Webhook: webhook.Config{
+			MaxAttempts:     getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			InitialBackoff:  getEnvDuration("WEBHOOK_INITIAL_BACKOFF", time.Second),
+			MaxBackoff:      getEnvDuration("WEBHOOK_MAX_BACKOFF", 30*time.Second),
+			Timeout:         getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			AllowedNetworks: getEnvPrefixes("WEBHOOK_ALLOWED_NETWORKS"),
+		},
+		Events: events.Config{
+			Backend:        getEnv("EVENTS_BACKEND", ""),
+			URL:            getEnv("EVENTS_URL", ""),
+			Topic:          getEnv("EVENTS_TOPIC", "a2a.tasks"),
+			Stream:         getEnv("EVENTS_STREAM", "A2A_TASKS"),
+			BufferSize:     getEnvInt("EVENTS_BUFFER_SIZE", 1000),
+			MaxAttempts:    getEnvInt("EVENTS_MAX_ATTEMPTS", 5),
+			InitialBackoff: getEnvDuration("EVENTS_INITIAL_BACKOFF", 500*time.Millisecond),
+			MaxBackoff:     getEnvDuration("EVENTS_MAX_BACKOFF", 10*time.Second),
+			Timeout:        getEnvDuration("EVENTS_TIMEOUT", 5*time.Second),
+		},
+		TaskStore: getEnv("TASK_STORE", "memory"),
+		TaskDB: tasks.PostgresConfig{
+			Host:     getEnv("DB_HOST", "localhost"),
+			Port:     getEnvInt("DB_PORT", 5432),
+			User:     getEnv("DB_USER", "app_user"),
+			Password: getEnv("DB_PASSWORD", "mcp_password"),
+			DBName:   getEnv("DB_NAME", "mcp_db"),
+			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			MaxConns: int32(getEnvInt("DB_MAX_CONNS", 10)),
+			MinConns: int32(getEnvInt("DB_MIN_CONNS", 1)),
+
+			MigrationUser:     getEnv("DB_MIGRATION_USER", ""),
+			MigrationPassword: getEnv("DB_MIGRATION_PASSWORD", ""),
+		},
+		AutoMigrate:           getEnvBool("DB_AUTO_MIGRATE", false),
+		CostStore:             getEnv("COST_STORE", "memory"),
+		PricingFile:           getEnv("PRICING_FILE", ""),
+		PricingReloadInterval: getEnvDuration("PRICING_RELOAD_INTERVAL", 30*time.Second),
+		TokenizerBPEFile:      getEnv("TOKENIZER_BPE_FILE", ""),
+		TaskQueue:             getEnv("TASK_QUEUE", ""),
+		RedisAddr:             getEnv("REDIS_ADDR", "localhost:6379"),
+		Queue: tasks.RedisQueueConfig{
+			Stream:            getEnv("TASK_QUEUE_STREAM", queueDefaults.Stream),
+			Group:             getEnv("TASK_QUEUE_GROUP", queueDefaults.Group),
+			Consumer:          getEnv("TASK_QUEUE_CONSUMER", queueDefaults.Consumer),
+			VisibilityTimeout: getEnvDuration("TASK_QUEUE_VISIBILITY_TIMEOUT", queueDefaults.VisibilityTimeout),
+			Block:             queueDefaults.Block,
+		},
+		QueueWorkers:       getEnvInt("TASK_QUEUE_WORKERS", 4),
+		QueueMaxDeliveries: getEnvInt("TASK_QUEUE_MAX_DELIVERIES", 5),
+		TaskLeaseTTL:       getEnvDuration("TASK_LEASE_TTL", server.DefaultLeaseTTL),
+		TaskMaxAttempts:    getEnvInt("TASK_MAX_ATTEMPTS", 3),
+		Scheduling: server.SchedulingPolicy{
+			MaxConcurrent:         getEnvInt("TASK_MAX_CONCURRENT", 0),
+			Concurrency:           getEnvPriorityLimits("TASK_PRIORITY_CONCURRENCY"),
+			CapabilityConcurrency: getEnvInts("TASK_CAPABILITY_CONCURRENCY"),
+			AgingInterval:         getEnvDuration("TASK_AGING_INTERVAL", 2*time.Minute),
+		},
+		TaskPollInterval:   getEnvDuration("TASK_POLL_INTERVAL", time.Second),
+		TaskMaxPending:     getEnvInt("TASK_MAX_PENDING", 0),
+		MaxInlineFileBytes: getEnvInt("MAX_INLINE_FILE_BYTES", protocol.DefaultMaxInlineFileBytes),
+		ScheduleInterval:   getEnvDuration("SCHEDULE_INTERVAL", server.DefaultScheduleInterval),
+		TaskRetention:      getEnvDuration("TASK_RETENTION", 0),
+		TaskGCInterval:     getEnvDuration("TASK_GC_INTERVAL", server.DefaultTaskGCInterval),
+		TaskArchivePath:    getEnv("TASK_ARCHIVE_PATH", ""),
+		CapabilityTimeout:  getEnvDuration("CAPABILITY_TIMEOUT", 30*time.Second),
+		CapabilityTimeouts: getEnvDurations("CAPABILITY_TIMEOUTS"),
+		MCP: capabilities.MCPBridgeConfig{
+			URL:     getEnv("MCP_SERVER_URL", ""),
+			Token:   getEnv("MCP_SERVICE_TOKEN", ""),
+			Timeout: getEnvDuration("MCP_TIMEOUT", 10*time.Second),
+		},
+		TLS: mtls.Config{
+			CertFile:       getEnv("TLS_CERT_FILE", ""),
+			KeyFile:        getEnv("TLS_KEY_FILE", ""),
+			CAFile:         getEnv("TLS_CA_FILE", ""),
+			ReloadInterval: getEnvDuration("TLS_RELOAD_INTERVAL", mtls.DefaultReloadInterval),
+			Identities:     mtls.ParseIdentities(os.Getenv("TLS_CLIENT_IDENTITIES")),
+		},
+		MCPSearchTool:         getEnv("MCP_SEARCH_TOOL", "hybrid_search"),
+		UsageJournalPath:      getEnv("USAGE_JOURNAL_PATH", ""),
+		RemoteAgents:          getEnvRemoteAgents("REMOTE_AGENTS"),
+		RemoteAgentBudgetsUSD: getEnvFloats("REMOTE_AGENT_BUDGETS_USD"),
+		RemoteAgent: a2aclient.Config{
+			Token:        getEnv("REMOTE_AGENT_TOKEN", ""),
+			UserID:       getEnv("REMOTE_AGENT_USER_ID", ""),
+			CardTTL:      getEnvDuration("REMOTE_AGENT_CARD_TTL", 5*time.Minute),
+			PollInterval: getEnvDuration("REMOTE_AGENT_POLL_INTERVAL", time.Second),
+			Timeout:      getEnvDuration("REMOTE_AGENT_TIMEOUT", 10*time.Second),
+		},
+		Delegation: a2aclient.DelegatorConfig{
+			FailureThreshold: getEnvInt("REMOTE_AGENT_FAILURE_THRESHOLD", 5),
+			Cooldown:         getEnvDuration("REMOTE_AGENT_COOLDOWN", 30*time.Second),
+			DefaultCostUSD:   getEnvFloat("REMOTE_AGENT_DEFAULT_COST_USD", 0.01),
+		},
+		BudgetAlerts: budgetAlertsConfig{
+			Thresholds: getEnvFloatList("BUDGET_ALERT_THRESHOLDS", cost.DefaultAlertThresholds),
+			Timeout:    getEnvDuration("BUDGET_ALERT_TIMEOUT", 10*time.Second),
+			Webhook: alerts.Webhook{
+				URL:    getEnv("BUDGET_ALERT_WEBHOOK_URL", ""),
+				Secret: getEnv("BUDGET_ALERT_WEBHOOK_SECRET", ""),
+			},
+			Slack: alerts.Slack{WebhookURL: getEnv("BUDGET_ALERT_SLACK_WEBHOOK_URL", "")},
+			Email: alerts.Email{
+				Addr:     getEnv("BUDGET_ALERT_SMTP_ADDR", ""),
+				Username: getEnv("BUDGET_ALERT_SMTP_USERNAME", ""),
+				Password: getEnv("BUDGET_ALERT_SMTP_PASSWORD", ""),
+				From:     getEnv("BUDGET_ALERT_EMAIL_FROM", "a2a-budgets@localhost"),
+				To:       getEnvList("BUDGET_ALERT_EMAIL_TO"),
+			},
+		},
+		ArtifactStore:       getEnv("ARTIFACT_STORE", ""),
+		ArtifactInlineLimit: getEnvInt("ARTIFACT_INLINE_LIMIT", server.DefaultArtifactInlineLimit),
+		ArtifactDir:         getEnv("ARTIFACT_DIR", "data/artifacts"),
+		ArtifactS3: blobs.S3Config{
+			Endpoint:        getEnv("ARTIFACT_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Bucket:          getEnv("ARTIFACT_S3_BUCKET", ""),
+			Region:          getEnv("ARTIFACT_S3_REGION", "us-east-1"),
+			AccessKeyID:     getEnv("ARTIFACT_S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("ARTIFACT_S3_SECRET_ACCESS_KEY", ""),
+			Prefix:          getEnv("ARTIFACT_S3_PREFIX", ""),
+		},
 	}
 }
 
@@ -231,6 +919,175 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+// getEnvInt retrieves an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration retrieves a duration environment variable or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDurations retrieves a comma-separated list of name=duration pairs, e.g.
+// "search_papers=5s,analyze_code=1m", skipping malformed entries
+func getEnvDurations(key string) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		if duration, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			durations[strings.TrimSpace(name)] = duration
+		} else {
+			slog.Warn("Ignoring invalid duration", "key", key, "name", name, "value", value)
+		}
+	}
+	return durations
+}
+
+// getEnvPrefixes retrieves a comma-separated list of CIDR prefixes, e.g.
+// "10.0.0.0/8,fd00::/8", skipping malformed entries
+func getEnvPrefixes(key string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else {
+			slog.Warn("Ignoring invalid network", "key", key, "value", value)
+		}
+	}
+	return prefixes
+}
+
+// getEnvRemoteAgents parses remote agents such as
+// "research=http://research-agent:8081,code=http://code-agent:8081", keeping their order
+func getEnvRemoteAgents(key string) []remoteAgent {
+	var agents []remoteAgent
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, url, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(url) == "" {
+			continue
+		}
+		agents = append(agents, remoteAgent{Name: strings.TrimSpace(name), URL: strings.TrimSuffix(strings.TrimSpace(url), "/")})
+	}
+	return agents
+}
+
+// getEnvFloats retrieves a comma-separated list of name=number pairs, e.g.
+// "research=5,code=2.5", skipping malformed entries
+func getEnvFloats(key string) map[string]float64 {
+	values := make(map[string]float64)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			values[strings.TrimSpace(name)] = number
+		} else {
+			slog.Warn("Ignoring invalid number", "key", key, "name", name, "value", value)
+		}
+	}
+	return values
+}
+
+// getEnvInts retrieves a comma-separated list of name=integer pairs, e.g.
+// "search_papers=2,analyze_code=1"; malformed pairs are logged and skipped
+func getEnvInts(key string) map[string]int {
+	values := make(map[string]int)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		if number, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			values[strings.TrimSpace(name)] = number
+		} else {
+			slog.Warn("Ignoring invalid integer", "key", key, "name", name, "value", value)
+		}
+	}
+	return values
+}
+
+// getEnvFloatList retrieves a comma-separated list of numbers, e.g. "50,80,100", or
+// returns a default value when it is unset or malformed
+func getEnvFloatList(key string, defaultValue []float64) []float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var numbers []float64
+	for _, field := range strings.Split(value, ",") {
+		number, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			slog.Warn("Ignoring invalid number list", "key", key, "value", value)
+			return defaultValue
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// getEnvHistogramBuckets parses bucket boundaries per histogram, e.g.
+// "a2a.request.duration=1,5,10;a2a.task.duration=100,1000,10000", ignoring a malformed value
+func getEnvHistogramBuckets(key string) map[string][]float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	buckets, err := observability.ParseHistogramBuckets(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid histogram buckets", "key", key, "error", err)
+		return nil
+	}
+	return buckets
+}
+
+// getEnvList retrieves a comma-separated list of strings, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, field := range strings.Split(os.Getenv(key), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			values = append(values, field)
+		}
+	}
+	return values
+}
+
+// getEnvPriorityLimits parses per-priority limits such as "high=8,low=2"
+func getEnvPriorityLimits(key string) map[protocol.TaskPriority]int {
+	limits := make(map[protocol.TaskPriority]int)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		priority := protocol.TaskPriority(strings.TrimSpace(name))
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if !priority.Valid() || err != nil {
+			slog.Warn("Ignoring invalid priority limit", "key", key, "priority", name, "value", value)
+			continue
+		}
+		limits[priority] = limit
+	}
+	return limits
+}
+
 // getEnvBool retrieves a boolean environment variable or returns a default value
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
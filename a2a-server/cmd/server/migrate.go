@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/migrations"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+// withMigrator runs fn with a migrator on its own connection to the task database
+func withMigrator(ctx context.Context, cfg tasks.PostgresConfig, fn func(*migrations.Migrator) error) error {
+	conn, err := tasks.ConnectForMigrations(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	migrator, err := migrations.New(conn)
+	if err != nil {
+		return err
+	}
+	return fn(migrator)
+}
+
+// autoMigrate applies the pending migrations of the task database before the stores use it
+func autoMigrate(ctx context.Context, cfg tasks.PostgresConfig) error {
+	return withMigrator(ctx, cfg, func(m *migrations.Migrator) error {
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			slog.Info("Applied schema migration", "version", migration.Version, "name", migration.Name)
+		}
+		if err == nil {
+			slog.Info("Database schema up to date", "version", m.Latest())
+		}
+		return err
+	})
+}
+
+// migrateCommand applies, reverts or lists the schema migrations of the task database,
+// as the migration user when one is configured:
+//
+//	a2a-server migrate [up|down|status]
+func migrateCommand(cfg Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: a2a-server migrate [up|down|status]")
+		fmt.Fprintln(flags.Output(), "  up      apply the pending migrations (default)")
+		fmt.Fprintln(flags.Output(), "  down    revert the newest applied migration")
+		fmt.Fprintln(flags.Output(), "  status  list the migrations and when they were applied")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	action := "up"
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		action = flags.Arg(0)
+	default:
+		return fmt.Errorf("expected one action, got %v", flags.Args())
+	}
+
+	var run func(io.Writer, *migrations.Migrator) error
+	switch action {
+	case "up":
+		run = migrateUp
+	case "down":
+		run = migrateDown
+	case "status":
+		run = migrateStatus
+	default:
+		return fmt.Errorf("unknown action %q: expected up, down or status", action)
+	}
+	return withMigrator(context.Background(), cfg.TaskDB, func(m *migrations.Migrator) error {
+		return run(os.Stdout, m)
+	})
+}
+
+func migrateUp(out io.Writer, m *migrations.Migrator) error {
+	applied, err := m.Up(context.Background())
+	for _, migration := range applied {
+		fmt.Fprintf(out, "applied %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Fprintf(out, "up to date at version %d\n", m.Latest())
+	}
+	return nil
+}
+
+func migrateDown(out io.Writer, m *migrations.Migrator) error {
+	reverted, err := m.Down(context.Background())
+	if err != nil {
+		return err
+	}
+	if reverted == nil {
+		fmt.Fprintln(out, "no migration to revert")
+		return nil
+	}
+	fmt.Fprintf(out, "reverted %04d_%s\n", reverted.Version, reverted.Name)
+	return nil
+}
+
+func migrateStatus(out io.Writer, m *migrations.Migrator) error {
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		state := "pending"
+		if status.AppliedAt != nil {
+			state = "applied " + status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Version > m.Latest() {
+			state += " (unknown to this binary)"
+		}
+		fmt.Fprintf(out, "%04d_%s %s\n", status.Version, status.Name, state)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Command tsgen writes TypeScript declarations for the A2A protocol types so JavaScript
+// clients stay in lockstep with the wire format. Regenerate after changing the types:
+//
+//	go generate ./cmd/tsgen
+package main
+
+//go:generate go run . -out ../../../clients/typescript/a2a.ts
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/server"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/tsgen"
+)
+
+const header = `Code generated by a2a-server/cmd/tsgen. DO NOT EDIT.
+A2A types: the REST API (/agent, /tasks, /schedules) and the JSON-RPC endpoint (/a2a).`
+
+func main() {
+	out := flag.String("out", "", "file to write (default stdout)")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	if err := generate(&buf); err != nil {
+		logging.Fatal("Failed to generate TypeScript", "error", err)
+	}
+	if *out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		logging.Fatal("Failed to write TypeScript", "path", *out, "error", err)
+	}
+}
+
+// generate writes the TypeScript declarations for the A2A protocol
+func generate(w io.Writer) error {
+	g := tsgen.New()
+
+	g.Const("JSONRPCVersion", protocol.JSONRPCVersion)
+	g.Const("MethodMessageSend", protocol.MethodMessageSend)
+	g.Const("MethodMessageStream", protocol.MethodMessageStream)
+	g.Const("MethodTasksGet", protocol.MethodTasksGet)
+	g.Const("MethodTasksCancel", protocol.MethodTasksCancel)
+	g.Const("MethodTasksResubscribe", protocol.MethodTasksResubscribe)
+	g.Const("MethodPushConfigSet", protocol.MethodPushConfigSet)
+	g.Const("MethodPushConfigGet", protocol.MethodPushConfigGet)
+
+	g.Const("ParseError", protocol.ParseError)
+	g.Const("InvalidRequest", protocol.InvalidRequest)
+	g.Const("MethodNotFound", protocol.MethodNotFound)
+	g.Const("InvalidParams", protocol.InvalidParams)
+	g.Const("InternalError", protocol.InternalError)
+	g.Const("TaskNotFound", protocol.TaskNotFound)
+	g.Const("TaskNotCancelable", protocol.TaskNotCancelable)
+	g.Const("PushNotificationNotSupported", protocol.PushNotificationNotSupported)
+	g.Const("UnsupportedOperation", protocol.UnsupportedOperation)
+	g.Const("ContentTypeNotSupported", protocol.ContentTypeNotSupported)
+	g.Const("BudgetExceeded", protocol.BudgetExceeded)
+	g.Const("RequestTooLarge", protocol.RequestTooLarge)
+	g.Const("ServerBusy", protocol.ServerBusy)
+	g.Const("TaskNotAwaitingInput", protocol.TaskNotAwaitingInput)
+
+	// REST API
+	g.Enum(protocol.TaskStatePending, protocol.TaskStateRunning, protocol.TaskStateInputRequired,
+		protocol.TaskStateCompleted, protocol.TaskStateFailed, protocol.TaskStateCancelled)
+	g.Enum(protocol.PriorityHigh, protocol.PriorityNormal, protocol.PriorityLow)
+	g.Add(
+		protocol.AgentCard{},
+		server.CreateTaskRequest{},
+		server.InputValidationResponse{},
+		server.TaskEstimate{},
+		protocol.Task{},
+		protocol.TaskEvent{},
+		protocol.TaskGraph{},
+		server.TaskInputRequest{},
+		server.ScheduleRequest{},
+		schedules.Schedule{},
+	)
+
+	// JSON-RPC endpoint; the shared error type is named Error, which would shadow the
+	// JavaScript global
+	g.Name(protocol.JSONRPCError{}, "JSONRPCError")
+	g.Add(
+		protocol.JSONRPCRequest{},
+		protocol.JSONRPCResponse{},
+		protocol.MessageSendParams{},
+		protocol.TaskPushNotificationConfig{},
+		protocol.TaskQueryParams{},
+		protocol.TaskIDParams{},
+		protocol.A2ATask{},
+		protocol.TaskStatusUpdateEvent{},
+		protocol.TaskArtifactUpdateEvent{},
+	)
+	return g.Write(w, header)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGeneratedFileIsCurrent fails when the protocol types changed without regenerating
+func TestGeneratedFileIsCurrent(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, generate(&buf))
+
+	committed, err := os.ReadFile("../../../clients/typescript/a2a.ts")
+	require.NoError(t, err)
+	require.Equal(t, string(committed), buf.String(), "run go generate ./cmd/tsgen")
+}
@@ -0,0 +1,100 @@
+package a2aclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for calls to a remote agent whose circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// Breaker stops calls to a remote agent after consecutive failures. Once open, it lets a
+// single trial call through after the cooldown: success closes it, failure opens it again.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	state    string
+	trial    bool // a half-open trial call is in flight
+}
+
+// NewBreaker creates a breaker that opens after threshold consecutive failures; a
+// threshold of zero or less never opens
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: time.Now, state: CircuitClosed}
+}
+
+// Allow returns ErrCircuitOpen if a call must not be made now; a nil result must be
+// followed by Record with the call's outcome
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.trial = true
+		return nil
+	case CircuitHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		b.state = CircuitClosed
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release ends an allowed call without an outcome, e.g. one the caller cancelled
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if b.state == CircuitHalfOpen {
+		// Let the next call be the trial
+		b.state = CircuitOpen
+		b.openedAt = b.now().Add(-b.cooldown)
+	}
+}
+
+// State returns the breaker's state, reporting an open breaker whose cooldown has passed
+// as half-open
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,61 @@
+package a2aclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_OpensAfterThresholdAndRecovers(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	failure := errors.New("connection refused")
+
+	assert.NoError(t, breaker.Allow())
+	breaker.Record(failure)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+	breaker.Record(failure)
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	// After the cooldown a single trial goes through; its failure opens the circuit again
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+	breaker.Record(failure)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	breaker.Record(nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestBreaker_ReleaseLetsTheNextCallBeTheTrial(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Record(errors.New("timeout"))
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	breaker.release()
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestBreaker_ZeroThresholdNeverOpens(t *testing.T) {
+	breaker := NewBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, breaker.Allow())
+		breaker.Record(errors.New("timeout"))
+	}
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
@@ -0,0 +1,244 @@
+// Package a2aclient talks to remote A2A agents: it discovers what they can do from their
+// agent cards and delegates tasks to them over JSON-RPC.
+package a2aclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Paths of a remote agent's endpoints, relative to its base URL
+const (
+	CardPath = "/agent"
+	RPCPath  = "/a2a"
+)
+
+// maxResponseBytes bounds the responses read from a remote agent
+const maxResponseBytes = 4 << 20
+
+// Config configures a client for one remote agent
+type Config struct {
+	// URL is the agent's base URL, e.g. http://research-agent:8081
+	URL string
+	// Token, when set, is sent as a bearer token
+	Token string
+	// UserID is sent as metadata.user_id, the user the remote agent charges for the tasks
+	UserID string
+	// CardTTL is how long a fetched agent card is used before it is fetched again
+	CardTTL time.Duration
+	// PollInterval is how often a delegated task is checked until it finishes
+	PollInterval time.Duration
+	// Timeout bounds each HTTP request; zero means no limit
+	Timeout time.Duration
+}
+
+// Client fetches a remote agent's card and runs tasks on it
+type Client struct {
+	config Config
+	client *http.Client
+	nextID atomic.Int64
+	now    func() time.Time
+
+	mu        sync.Mutex
+	card      *protocol.AgentCard
+	fetchedAt time.Time
+}
+
+// New creates a client for the agent at config.URL
+func New(config Config) *Client {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	return &Client{config: config, client: &http.Client{Timeout: config.Timeout}, now: time.Now}
+}
+
+// URL returns the agent's base URL
+func (c *Client) URL() string {
+	return c.config.URL
+}
+
+// AgentCard returns the agent's card, fetching it when the cached one is older than CardTTL
+func (c *Client) AgentCard(ctx context.Context) (*protocol.AgentCard, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.card != nil && c.now().Sub(c.fetchedAt) < c.config.CardTTL {
+		return c.card, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.URL+CardPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	var card protocol.AgentCard
+	if err := c.do(req, &card); err != nil {
+		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+	c.card = &card
+	c.fetchedAt = c.now()
+	return c.card, nil
+}
+
+// CachedCard returns the last card fetched, or nil, without fetching it
+func (c *Client) CachedCard() *protocol.AgentCard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.card
+}
+
+// SendMessage creates a task running capability on input and returns it as submitted
+func (c *Client) SendMessage(ctx context.Context, capability string, input map[string]interface{}) (*protocol.A2ATask, error) {
+	card, err := c.AgentCard(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := protocol.MessageSendParams{
+		Message: protocol.Message{
+			Kind:      protocol.KindMessage,
+			Role:      protocol.RoleUser,
+			Parts:     []protocol.Part{{Kind: protocol.KindData, Data: input}},
+			MessageID: uuid.New().String(),
+		},
+		Metadata: map[string]interface{}{"agent_id": card.ID, "capability": capability},
+	}
+	if c.config.UserID != "" {
+		params.Metadata["user_id"] = c.config.UserID
+	}
+
+	var task protocol.A2ATask
+	if err := c.call(ctx, protocol.MethodMessageSend, params, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask returns the current state of a task
+func (c *Client) GetTask(ctx context.Context, taskID string) (*protocol.A2ATask, error) {
+	var task protocol.A2ATask
+	if err := c.call(ctx, protocol.MethodTasksGet, protocol.TaskQueryParams{ID: taskID}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CancelTask cancels a task
+func (c *Client) CancelTask(ctx context.Context, taskID string) error {
+	var task protocol.A2ATask
+	return c.call(ctx, protocol.MethodTasksCancel, protocol.TaskIDParams{ID: taskID}, &task)
+}
+
+// Run creates a task and waits for it to finish; see Wait
+func (c *Client) Run(ctx context.Context, capability string, input map[string]interface{}) (*protocol.A2ATask, error) {
+	task, err := c.SendMessage(ctx, capability, input)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wait(ctx, task)
+}
+
+// Wait polls a task until it finishes or asks for input and returns it in that state. If
+// ctx is cancelled first, the remote task is cancelled too.
+func (c *Client) Wait(ctx context.Context, task *protocol.A2ATask) (*protocol.A2ATask, error) {
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+	for !Finished(task) && task.Status.State != protocol.A2AStateInputRequired {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+		next, err := c.GetTask(ctx, task.ID)
+		if ctx.Err() != nil {
+			c.abandon(ctx, task.ID)
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		task = next
+	}
+	return task, nil
+}
+
+// abandon cancels a task the caller gave up waiting for, without waiting on its ctx
+func (c *Client) abandon(ctx context.Context, taskID string) {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	c.CancelTask(cancelCtx, taskID)
+}
+
+// Finished reports whether a task reached a final state
+func Finished(task *protocol.A2ATask) bool {
+	switch task.Status.State {
+	case protocol.A2AStateCompleted, protocol.A2AStateFailed, protocol.A2AStateCanceled:
+		return true
+	}
+	return false
+}
+
+// call sends one JSON-RPC request and decodes its result into out. JSON-RPC errors are
+// returned as *protocol.JSONRPCError.
+func (c *Client) call(ctx context.Context, method string, params, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": protocol.JSONRPCVersion,
+		"id":      c.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL+RPCPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var rpc struct {
+		Result json.RawMessage        `json:"result"`
+		Error  *protocol.JSONRPCError `json:"error"`
+	}
+	if err := c.do(req, &rpc); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if rpc.Error != nil {
+		return rpc.Error
+	}
+	if err := json.Unmarshal(rpc.Result, out); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
+
+// do sends a request with the client's token and trace context and decodes the JSON
+// response into out
+func (c *Client) do(req *http.Request, out interface{}) error {
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("agent returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package a2aclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAgent serves an agent card and the JSON-RPC task methods. Tasks finish on their
+// first tasks/get with the outcome run returns.
+type fakeAgent struct {
+	card *protocol.AgentCard
+	run  func(task *protocol.Task)
+
+	mu          sync.Mutex
+	cardFetches int
+	params      []protocol.MessageSendParams
+	cancelled   []string
+	headers     []http.Header
+	tasks       map[string]*protocol.Task
+	status      int // when set, every request fails with this HTTP status
+}
+
+func newFakeAgent(capabilities ...protocol.Capability) *fakeAgent {
+	card := protocol.NewAgentCard("remote-agent", "Remote", "1.0.0", "Remote agent")
+	for _, capability := range capabilities {
+		card.AddCapability(capability)
+	}
+	return &fakeAgent{
+		card:  card,
+		tasks: make(map[string]*protocol.Task),
+		run: func(task *protocol.Task) {
+			task.State = protocol.TaskStateCompleted
+			task.Result = map[string]interface{}{"status": "success", "cost": 0.002}
+		},
+	}
+}
+
+func (f *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headers = append(f.headers, r.Header.Clone())
+	if f.status != 0 {
+		http.Error(w, "unavailable", f.status)
+		return
+	}
+	if r.URL.Path == CardPath {
+		f.cardFetches++
+		json.NewEncoder(w).Encode(f.card)
+		return
+	}
+
+	var req struct {
+		ID     interface{}     `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	var id struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(req.Params, &id)
+
+	switch req.Method {
+	case protocol.MethodMessageSend:
+		var params protocol.MessageSendParams
+		json.Unmarshal(req.Params, &params)
+		f.params = append(f.params, params)
+		input, _ := params.Message.Input()
+		task := protocol.NewTask(f.card.ID, params.Metadata["capability"].(string), input)
+		f.tasks[task.ID] = task
+		response["result"] = protocol.ToA2ATask(task)
+	case protocol.MethodTasksGet:
+		task, ok := f.tasks[id.ID]
+		if !ok {
+			response["error"] = protocol.JSONRPCError{Code: protocol.TaskNotFound, Message: "Task not found"}
+			break
+		}
+		if !task.State.IsTerminal() {
+			f.run(task)
+		}
+		response["result"] = protocol.ToA2ATask(task)
+	case protocol.MethodTasksCancel:
+		f.cancelled = append(f.cancelled, id.ID)
+		if task, ok := f.tasks[id.ID]; ok {
+			task.State = protocol.TaskStateCancelled
+			response["result"] = protocol.ToA2ATask(task)
+		}
+	default:
+		response["error"] = protocol.JSONRPCError{Code: protocol.MethodNotFound, Message: "Method not found"}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func TestClient_AgentCardIsCached(t *testing.T) {
+	fake := newFakeAgent(protocol.Capability{Name: "translate"})
+	remote := httptest.NewServer(fake)
+	defer remote.Close()
+
+	now := time.Now()
+	client := New(Config{URL: remote.URL, CardTTL: time.Minute})
+	client.now = func() time.Time { return now }
+	assert.Nil(t, client.CachedCard())
+
+	card, err := client.AgentCard(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "remote-agent", card.ID)
+	_, err = client.AgentCard(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.cardFetches)
+
+	now = now.Add(time.Minute)
+	_, err = client.AgentCard(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.cardFetches)
+	assert.Equal(t, card.ID, client.CachedCard().ID)
+}
+
+func TestClient_Run(t *testing.T) {
+	fake := newFakeAgent(protocol.Capability{Name: "translate"})
+	remote := httptest.NewServer(fake)
+	defer remote.Close()
+
+	client := New(Config{URL: remote.URL, Token: "remote-token", UserID: "delegator", CardTTL: time.Minute, PollInterval: time.Millisecond})
+	task, err := client.Run(context.Background(), "translate", map[string]interface{}{"text": "hallo"})
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.A2AStateCompleted, task.Status.State)
+	require.Len(t, fake.params, 1)
+	assert.Equal(t, map[string]interface{}{"agent_id": "remote-agent", "capability": "translate", "user_id": "delegator"}, fake.params[0].Metadata)
+	assert.Equal(t, "hallo", fake.params[0].Message.Parts[0].Data["text"])
+	for _, header := range fake.headers {
+		assert.Equal(t, "Bearer remote-token", header.Get("Authorization"))
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	fake := newFakeAgent(protocol.Capability{Name: "translate"})
+	remote := httptest.NewServer(fake)
+	defer remote.Close()
+	client := New(Config{URL: remote.URL, CardTTL: time.Minute})
+
+	// JSON-RPC errors keep their code
+	_, err := client.GetTask(context.Background(), "missing")
+	var rpcErr *protocol.JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.TaskNotFound, rpcErr.Code)
+
+	fake.status = http.StatusServiceUnavailable
+	_, err = client.GetTask(context.Background(), "missing")
+	assert.ErrorContains(t, err, "503")
+	assert.False(t, errors.As(err, &rpcErr))
+}
+
+func TestClient_WaitCancelsRemoteTask(t *testing.T) {
+	fake := newFakeAgent(protocol.Capability{Name: "translate"})
+	fake.run = func(task *protocol.Task) { task.State = protocol.TaskStateRunning }
+	remote := httptest.NewServer(fake)
+	defer remote.Close()
+	client := New(Config{URL: remote.URL, CardTTL: time.Minute, PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	task, err := client.SendMessage(ctx, "translate", map[string]interface{}{})
+	require.NoError(t, err)
+	_, err = client.Wait(ctx, task)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{task.ID}, fake.cancelled)
+}
@@ -0,0 +1,247 @@
+package a2aclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// Errors returned by Delegate
+var (
+	ErrNoRemoteAgent        = errors.New("no remote agent offers the capability")
+	ErrRemoteBudgetExceeded = errors.New("remote agent budget exceeded")
+)
+
+// DelegatorConfig configures how tasks are delegated to remote agents
+type DelegatorConfig struct {
+	// FailureThreshold consecutive failed calls open a remote agent's circuit for Cooldown
+	FailureThreshold int
+	Cooldown         time.Duration
+	// DefaultCostUSD is charged to a remote agent's budget for capabilities whose card
+	// gives no estimated cost
+	DefaultCostUSD float64
+}
+
+// remote is a remote agent tasks can be delegated to
+type remote struct {
+	name    string
+	client  *Client
+	breaker *Breaker
+}
+
+// Delegator runs capabilities the local agent lacks on remote agents that advertise them.
+// Each delegated task is charged to the remote agent's budget at the capability's
+// estimated cost, and a remote agent that keeps failing is skipped while its circuit is open.
+type Delegator struct {
+	config  DelegatorConfig
+	budgets *cost.BudgetManager
+
+	mu      sync.RWMutex
+	remotes []*remote
+}
+
+// NewDelegator creates a delegator without remote agents
+func NewDelegator(config DelegatorConfig) *Delegator {
+	return &Delegator{config: config, budgets: cost.NewBudgetManager()}
+}
+
+// Add delegates to the agent behind client under name, in the order agents are added.
+// A positive budgetUSD caps what may be spent on the agent's tasks; zero leaves it unlimited.
+func (d *Delegator) Add(ctx context.Context, name string, client *Client, budgetUSD float64) error {
+	if budgetUSD > 0 {
+		if err := d.budgets.SetBudget(ctx, name, budgetUSD); err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.remotes = append(d.remotes, &remote{
+		name:    name,
+		client:  client,
+		breaker: NewBreaker(d.config.FailureThreshold, d.config.Cooldown),
+	})
+	return nil
+}
+
+// Offers reports whether a reachable remote agent advertises the capability
+func (d *Delegator) Offers(ctx context.Context, capability string) bool {
+	for _, r := range d.list() {
+		if _, ok := d.offer(ctx, r, capability); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// list returns the remote agents
+func (d *Delegator) list() []*remote {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.remotes
+}
+
+// offer returns the remote agent's capability unless its circuit is open or its card
+// cannot be fetched
+func (d *Delegator) offer(ctx context.Context, r *remote, capability string) (protocol.Capability, bool) {
+	if r.breaker.State() == CircuitOpen {
+		return protocol.Capability{}, false
+	}
+	card, err := r.client.AgentCard(ctx)
+	if err != nil {
+		return protocol.Capability{}, false
+	}
+	return card.Capability(capability)
+}
+
+// Delegate runs the capability on the first remote agent that offers it and has budget
+// left, waiting for the remote task to finish. Failures to reach an agent count against
+// its circuit; a remote task that fails is returned as an error without doing so.
+func (d *Delegator) Delegate(ctx context.Context, capability string, input map[string]interface{}) (*capabilities.Result, error) {
+	overBudget := false
+	for _, r := range d.list() {
+		offered, ok := d.offer(ctx, r, capability)
+		if !ok {
+			continue
+		}
+
+		estimate := offered.EstimatedCostUSD
+		if estimate == 0 {
+			estimate = d.config.DefaultCostUSD
+		}
+		if !d.charge(ctx, r.name, estimate) {
+			overBudget = true
+			continue
+		}
+		if err := r.breaker.Allow(); err != nil {
+			d.refund(ctx, r.name, estimate)
+			continue
+		}
+		return d.run(ctx, r, capability, input, estimate)
+	}
+
+	if overBudget {
+		return nil, fmt.Errorf("%w: %s", ErrRemoteBudgetExceeded, capability)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoRemoteAgent, capability)
+}
+
+// run delegates one task to an agent whose circuit allowed the call
+func (d *Delegator) run(ctx context.Context, r *remote, capability string, input map[string]interface{}, estimate float64) (*capabilities.Result, error) {
+	task, err := r.client.SendMessage(ctx, capability, input)
+	if err != nil {
+		d.refund(ctx, r.name, estimate)
+		d.record(ctx, r, err)
+		return nil, fmt.Errorf("remote agent %s: %w", r.name, err)
+	}
+	task, err = r.client.Wait(ctx, task)
+	d.record(ctx, r, err)
+	if err != nil {
+		return nil, fmt.Errorf("remote agent %s: %w", r.name, err)
+	}
+
+	if task.Status.State == protocol.A2AStateInputRequired {
+		// Nobody answers the questions of delegated tasks
+		r.client.abandon(ctx, task.ID)
+	}
+	if task.Status.State != protocol.A2AStateCompleted {
+		reason := task.Status.State
+		if task.Status.Message != nil && len(task.Status.Message.Parts) > 0 {
+			reason += ": " + task.Status.Message.Parts[0].Text
+		}
+		return nil, fmt.Errorf("remote agent %s: task %s %s", r.name, task.ID, reason)
+	}
+
+	output := resultData(task)
+	costUSD := estimate
+	if reported, ok := output["cost"].(float64); ok {
+		costUSD = reported
+	}
+	return &capabilities.Result{
+		Output: map[string]interface{}{
+			"remote_agent":   r.name,
+			"remote_task_id": task.ID,
+			"result":         output,
+		},
+		CostUSD: costUSD,
+	}, nil
+}
+
+// record reports a call's outcome to the agent's circuit. Calls the caller cancelled and
+// JSON-RPC errors, which the agent answered, do not count as failures.
+func (d *Delegator) record(ctx context.Context, r *remote, err error) {
+	var rpcErr *protocol.JSONRPCError
+	switch {
+	case err != nil && ctx.Err() != nil:
+		r.breaker.release()
+	case errors.As(err, &rpcErr):
+		r.breaker.Record(nil)
+	default:
+		r.breaker.Record(err)
+	}
+}
+
+// charge charges an agent's budget, if it has one, reporting whether the cost fit
+func (d *Delegator) charge(ctx context.Context, name string, costUSD float64) bool {
+	if _, err := d.budgets.GetBudget(ctx, name); err != nil {
+		return true
+	}
+	allowed, err := d.budgets.Charge(ctx, name, "", costUSD)
+	return err == nil && allowed
+}
+
+// refund returns a charge for a task the agent never accepted
+func (d *Delegator) refund(ctx context.Context, name string, costUSD float64) {
+	if _, err := d.budgets.GetBudget(ctx, name); err == nil {
+		d.budgets.Refund(ctx, name, "", costUSD)
+	}
+}
+
+// resultData returns the data of the task's result artifact
+func resultData(task *protocol.A2ATask) map[string]interface{} {
+	for _, artifact := range task.Artifacts {
+		for _, part := range artifact.Parts {
+			if part.Kind == protocol.KindData && part.Data != nil {
+				return part.Data
+			}
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// RemoteStatus describes a remote agent tasks are delegated to
+type RemoteStatus struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Circuit string `json:"circuit"`
+	// Capabilities are those of the last agent card fetched
+	Capabilities []string `json:"capabilities"`
+	// BudgetUSD and SpendUSD are set for agents with a budget
+	BudgetUSD float64 `json:"budget_usd,omitempty"`
+	SpendUSD  float64 `json:"spend_usd,omitempty"`
+}
+
+// Status reports the remote agents without contacting them
+func (d *Delegator) Status(ctx context.Context) []RemoteStatus {
+	remotes := d.list()
+	statuses := make([]RemoteStatus, 0, len(remotes))
+	for _, r := range remotes {
+		status := RemoteStatus{Name: r.name, URL: r.client.URL(), Circuit: r.breaker.State(), Capabilities: []string{}}
+		if card := r.client.CachedCard(); card != nil {
+			for _, capability := range card.Capabilities {
+				status.Capabilities = append(status.Capabilities, capability.Name)
+			}
+		}
+		if budget, err := d.budgets.GetBudget(ctx, r.name); err == nil {
+			status.BudgetUSD = budget.MonthlyLimitUSD
+			status.SpendUSD = budget.CurrentSpendUSD
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,119 @@
+package a2aclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDelegator() *Delegator {
+	return NewDelegator(DelegatorConfig{FailureThreshold: 2, Cooldown: time.Minute, DefaultCostUSD: 0.01})
+}
+
+func addRemote(t *testing.T, delegator *Delegator, name string, fake *fakeAgent, budgetUSD float64) {
+	remote := httptest.NewServer(fake)
+	t.Cleanup(remote.Close)
+	client := New(Config{URL: remote.URL, CardTTL: time.Minute, PollInterval: time.Millisecond})
+	require.NoError(t, delegator.Add(context.Background(), name, client, budgetUSD))
+}
+
+func TestDelegator_DelegatesToTheAgentOfferingTheCapability(t *testing.T) {
+	ctx := context.Background()
+	delegator := newTestDelegator()
+	coder := newFakeAgent(protocol.Capability{Name: "analyze_code"})
+	translator := newFakeAgent(protocol.Capability{Name: "translate", EstimatedCostUSD: 0.005})
+	addRemote(t, delegator, "coder", coder, 0)
+	addRemote(t, delegator, "translator", translator, 1.0)
+
+	assert.True(t, delegator.Offers(ctx, "translate"))
+	assert.False(t, delegator.Offers(ctx, "summarize"))
+
+	result, err := delegator.Delegate(ctx, "translate", map[string]interface{}{"text": "hallo"})
+	require.NoError(t, err)
+	assert.Equal(t, "translator", result.Output["remote_agent"])
+	assert.NotEmpty(t, translator.params[0].Message.MessageID)
+	assert.Equal(t, map[string]interface{}{"status": "success", "cost": 0.002}, result.Output["result"])
+	// The remote agent's reported cost wins over the estimate
+	assert.Equal(t, 0.002, result.CostUSD)
+	assert.Empty(t, coder.params)
+
+	// The estimate is charged to the remote agent's budget
+	statuses := delegator.Status(ctx)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "coder", statuses[0].Name)
+	assert.Equal(t, []string{"analyze_code"}, statuses[0].Capabilities)
+	assert.Zero(t, statuses[0].BudgetUSD)
+	assert.Equal(t, CircuitClosed, statuses[1].Circuit)
+	assert.Equal(t, 1.0, statuses[1].BudgetUSD)
+	assert.InDelta(t, 0.005, statuses[1].SpendUSD, 1e-9)
+
+	_, err = delegator.Delegate(ctx, "summarize", map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrNoRemoteAgent)
+}
+
+func TestDelegator_RemoteBudget(t *testing.T) {
+	ctx := context.Background()
+	delegator := newTestDelegator()
+	expensive := newFakeAgent(protocol.Capability{Name: "translate", EstimatedCostUSD: 0.6})
+	addRemote(t, delegator, "expensive", expensive, 1.0)
+
+	_, err := delegator.Delegate(ctx, "translate", map[string]interface{}{})
+	require.NoError(t, err)
+	_, err = delegator.Delegate(ctx, "translate", map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrRemoteBudgetExceeded)
+	assert.Len(t, expensive.params, 1)
+
+	// Another agent with budget left takes over
+	fallback := newFakeAgent(protocol.Capability{Name: "translate"})
+	addRemote(t, delegator, "fallback", fallback, 0)
+	result, err := delegator.Delegate(ctx, "translate", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", result.Output["remote_agent"])
+}
+
+func TestDelegator_FailedRemoteTask(t *testing.T) {
+	ctx := context.Background()
+	delegator := newTestDelegator()
+	fake := newFakeAgent(protocol.Capability{Name: "translate"})
+	fake.run = func(task *protocol.Task) {
+		task.State = protocol.TaskStateFailed
+		task.Error = "unsupported language"
+	}
+	addRemote(t, delegator, "translator", fake, 0)
+
+	for i := 0; i < 3; i++ {
+		_, err := delegator.Delegate(ctx, "translate", map[string]interface{}{})
+		assert.ErrorContains(t, err, "failed: unsupported language")
+	}
+	// The agent answered, so its circuit stays closed
+	assert.Equal(t, CircuitClosed, delegator.Status(ctx)[0].Circuit)
+}
+
+func TestDelegator_CircuitOpensOnUnreachableAgent(t *testing.T) {
+	ctx := context.Background()
+	delegator := newTestDelegator()
+	fake := newFakeAgent(protocol.Capability{Name: "translate"})
+	addRemote(t, delegator, "translator", fake, 1.0)
+
+	// Fetch the card, then take the agent down
+	require.True(t, delegator.Offers(ctx, "translate"))
+	fake.status = http.StatusBadGateway
+	for i := 0; i < 2; i++ {
+		_, err := delegator.Delegate(ctx, "translate", map[string]interface{}{})
+		assert.ErrorContains(t, err, "502")
+	}
+
+	status := delegator.Status(ctx)[0]
+	assert.Equal(t, CircuitOpen, status.Circuit)
+	// Tasks the agent never accepted are refunded
+	assert.Zero(t, status.SpendUSD)
+	assert.False(t, delegator.Offers(ctx, "translate"))
+	_, err := delegator.Delegate(ctx, "translate", map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrNoRemoteAgent)
+}
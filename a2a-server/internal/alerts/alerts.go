@@ -0,0 +1,222 @@
+// Package alerts delivers budget alerts to webhooks, Slack and email, so operators and
+// users learn a budget is running out before tasks start being rejected.
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+)
+
+// Channel sends an alert to one destination
+type Channel interface {
+	// Name identifies the channel in logs
+	Name() string
+	Send(ctx context.Context, alert cost.Alert) error
+}
+
+// Errors returned for alerts that are dropped
+var (
+	ErrQueueFull = errors.New("alert queue full")
+	ErrClosed    = errors.New("alert dispatcher closed")
+)
+
+// queueSize bounds the alerts waiting to be delivered
+const queueSize = 256
+
+// Dispatcher queues budget alerts and delivers each to every channel in the background,
+// so charging a budget never waits on a slow webhook or mail server
+type Dispatcher struct {
+	channels []Channel
+	timeout  time.Duration
+	queue    chan cost.Alert
+	wg       sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ cost.AlertNotifier = (*Dispatcher)(nil)
+
+// NewDispatcher starts delivering alerts to channels, bounding each send by timeout
+func NewDispatcher(timeout time.Duration, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{channels: channels, timeout: timeout, queue: make(chan cost.Alert, queueSize)}
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+// NotifyBudgetAlert queues an alert for delivery
+func (d *Dispatcher) NotifyBudgetAlert(ctx context.Context, alert cost.Alert) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+	select {
+	case d.queue <- alert:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close delivers the queued alerts and stops; alerts notified after it are dropped
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// run delivers queued alerts until the queue is closed
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+	for alert := range d.queue {
+		for _, channel := range d.channels {
+			ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+			err := channel.Send(ctx, alert)
+			cancel()
+			if err != nil {
+				slog.Warn("Budget alert delivery failed", "channel", channel.Name(), "level", alert.Level,
+					"id", alert.ID, "threshold_percent", alert.ThresholdPercent, "error", err)
+				continue
+			}
+			slog.Info("Budget alert sent", "channel", channel.Name(), "level", alert.Level,
+				"id", alert.ID, "threshold_percent", alert.ThresholdPercent)
+		}
+	}
+}
+
+// Message returns the one-line text of an alert used by Slack and email
+func Message(alert cost.Alert) string {
+	return fmt.Sprintf("Budget alert: %s %s has spent $%.2f of its $%.2f monthly budget (%s%% threshold reached)",
+		alert.Level, alert.ID, alert.SpendUSD, alert.LimitUSD, strconv.FormatFloat(alert.ThresholdPercent, 'f', -1, 64))
+}
+
+// Webhook POSTs alerts as JSON, signed like task webhooks when Secret is set
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// Name implements Channel
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Send implements Channel
+func (w *Webhook) Send(ctx context.Context, alert cost.Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	timestamp := time.Now().Unix()
+	header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if w.Secret != "" {
+		header.Set(webhook.HeaderSignature, webhook.Sign(w.Secret, timestamp, body))
+	}
+	return post(ctx, w.Client, w.URL, header, body)
+}
+
+// Slack posts alerts to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Name implements Channel
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Send implements Channel
+func (s *Slack) Send(ctx context.Context, alert cost.Alert) error {
+	body, err := json.Marshal(map[string]string{"text": Message(alert)})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.WebhookURL, http.Header{}, body)
+}
+
+// post sends a JSON body, treating any non-2xx response as a failure
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Email sends alerts over SMTP, authenticating with PLAIN auth when Username is set
+type Email struct {
+	// Addr is the SMTP server's host:port
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Name implements Channel
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Send implements Channel. net/smtp takes no context, so a stalled server holds the
+// dispatcher until the connection times out.
+func (e *Email) Send(ctx context.Context, alert cost.Alert) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := strings.Cut(e.Addr, ":")
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	return smtp.SendMail(e.Addr, auth, e.From, e.To, e.message(alert))
+}
+
+// message formats an alert as an RFC 5322 message
+func (e *Email) message(alert cost.Alert) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: Budget alert: %s %s reached %s%%\r\n", alert.Level, alert.ID,
+		strconv.FormatFloat(alert.ThresholdPercent, 'f', -1, 64))
+	fmt.Fprintf(&b, "Date: %s\r\n", alert.TriggeredAt.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(Message(alert))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
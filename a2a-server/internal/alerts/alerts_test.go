@@ -0,0 +1,104 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAlert = cost.Alert{
+	Level:            cost.LevelUser,
+	ID:               "alice",
+	ThresholdPercent: 80,
+	LimitUSD:         10,
+	SpendUSD:         8.5,
+	TriggeredAt:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// recordingChannel records the alerts sent to it
+type recordingChannel struct {
+	mu     sync.Mutex
+	alerts []cost.Alert
+	err    error
+}
+
+func (c *recordingChannel) Name() string { return "recording" }
+
+func (c *recordingChannel) Send(ctx context.Context, alert cost.Alert) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alerts = append(c.alerts, alert)
+	return c.err
+}
+
+func TestDispatcher_DeliversToEveryChannel(t *testing.T) {
+	failing := &recordingChannel{err: errors.New("unreachable")}
+	working := &recordingChannel{}
+	dispatcher := NewDispatcher(time.Second, failing, working)
+
+	require.NoError(t, dispatcher.NotifyBudgetAlert(context.Background(), testAlert))
+	dispatcher.Close()
+	assert.ErrorIs(t, dispatcher.NotifyBudgetAlert(context.Background(), testAlert), ErrClosed)
+
+	assert.Equal(t, []cost.Alert{testAlert}, failing.alerts)
+	assert.Equal(t, []cost.Alert{testAlert}, working.alerts, "a failing channel does not stop the others")
+}
+
+func TestWebhook_SignsAlerts(t *testing.T) {
+	var got cost.Alert
+	var signature, timestamp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		timestamp = r.Header.Get(webhook.HeaderTimestamp)
+		ts, _ := strconv.ParseInt(timestamp, 10, 64)
+		if r.Header.Get(webhook.HeaderSignature) == webhook.Sign("s3cret", ts, body) {
+			signature = "valid"
+		}
+	}))
+	defer srv.Close()
+
+	channel := &Webhook{URL: srv.URL, Secret: "s3cret"}
+	require.NoError(t, channel.Send(context.Background(), testAlert))
+	assert.Equal(t, "alice", got.ID)
+	assert.Equal(t, 80.0, got.ThresholdPercent)
+	assert.Equal(t, "valid", signature)
+}
+
+func TestSlack_PostsText(t *testing.T) {
+	var got map[string]string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	channel := &Slack{WebhookURL: srv.URL}
+	require.NoError(t, channel.Send(context.Background(), testAlert))
+	assert.Equal(t, "Budget alert: user alice has spent $8.50 of its $10.00 monthly budget (80% threshold reached)", got["text"])
+
+	status = http.StatusForbidden
+	assert.Error(t, channel.Send(context.Background(), testAlert))
+}
+
+func TestEmail_Message(t *testing.T) {
+	channel := &Email{From: "budgets@example.com", To: []string{"ops@example.com", "alice@example.com"}}
+	message := string(channel.message(testAlert))
+
+	assert.Contains(t, message, "To: ops@example.com, alice@example.com\r\n")
+	assert.Contains(t, message, "Subject: Budget alert: user alice reached 80%\r\n")
+	assert.Contains(t, message, "\r\n\r\nBudget alert: user alice has spent $8.50")
+}
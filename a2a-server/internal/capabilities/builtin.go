@@ -0,0 +1,468 @@
+package capabilities
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+)
+
+// Models the built-in executors bill their tokens as
+const (
+	summaryModel     = "gpt-4-turbo"
+	fastSummaryModel = "gpt-3.5-turbo"
+	analysisModel    = "gpt-4-turbo"
+	searchModel      = "gpt-3.5-turbo"
+)
+
+// Builtins returns the built-in executors by capability name
+func Builtins() map[string]Executor {
+	return map[string]Executor{
+		"search_papers":           ExecutorFunc(SearchPapers),
+		"analyze_code":            ExecutorFunc(AnalyzeCode),
+		"summarize_document":      ExecutorFunc(Summarize),
+		"summarize_document_fast": ExecutorFunc(SummarizeFast),
+	}
+}
+
+// RegisterBuiltins registers the built-in executor and cost model of every capability on
+// the card that has one, returning the names of the capabilities left without an executor
+func RegisterBuiltins(registry *Registry, card *protocol.AgentCard) []string {
+	builtins := Builtins()
+	costModels := BuiltinCostModels()
+	var missing []string
+	for _, capability := range card.Capabilities {
+		executor, ok := builtins[capability.Name]
+		if !ok {
+			missing = append(missing, capability.Name)
+			continue
+		}
+		registry.Register(capability, executor)
+		if model, ok := costModels[capability.Name]; ok {
+			registry.SetCostModel(capability.Name, model)
+		}
+	}
+	return missing
+}
+
+// Paper is an entry of the built-in paper catalog
+type Paper struct {
+	Title    string   `json:"title"`
+	Authors  []string `json:"authors"`
+	Year     int      `json:"year"`
+	Abstract string   `json:"abstract"`
+	URL      string   `json:"url"`
+}
+
+// paperCatalog is the corpus search_papers searches when it is not bridged to a search service
+var paperCatalog = []Paper{
+	{
+		Title:    "Attention Is All You Need",
+		Authors:  []string{"Vaswani", "Shazeer", "Parmar", "Uszkoreit", "Jones", "Gomez", "Kaiser", "Polosukhin"},
+		Year:     2017,
+		Abstract: "We propose the Transformer, a sequence transduction architecture based solely on attention mechanisms, dispensing with recurrence and convolutions entirely.",
+		URL:      "https://arxiv.org/abs/1706.03762",
+	},
+	{
+		Title:    "BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding",
+		Authors:  []string{"Devlin", "Chang", "Lee", "Toutanova"},
+		Year:     2018,
+		Abstract: "BERT pre-trains deep bidirectional representations from unlabeled text by jointly conditioning on left and right context in all layers of a transformer.",
+		URL:      "https://arxiv.org/abs/1810.04805",
+	},
+	{
+		Title:    "Retrieval-Augmented Generation for Knowledge-Intensive NLP Tasks",
+		Authors:  []string{"Lewis", "Perez", "Piktus", "Petroni", "Karpukhin", "Goyal"},
+		Year:     2020,
+		Abstract: "Retrieval-augmented generation combines a pre-trained language model with a dense vector index of documents accessed by a neural retriever.",
+		URL:      "https://arxiv.org/abs/2005.11401",
+	},
+	{
+		Title:    "Dense Passage Retrieval for Open-Domain Question Answering",
+		Authors:  []string{"Karpukhin", "Oguz", "Min", "Lewis", "Wu", "Edunov", "Chen", "Yih"},
+		Year:     2020,
+		Abstract: "Dense passage retrieval learns embeddings of questions and passages with a dual encoder, outperforming sparse BM25 retrieval for open-domain question answering.",
+		URL:      "https://arxiv.org/abs/2004.04906",
+	},
+	{
+		Title:    "Efficient and Robust Approximate Nearest Neighbor Search Using Hierarchical Navigable Small World Graphs",
+		Authors:  []string{"Malkov", "Yashunin"},
+		Year:     2016,
+		Abstract: "HNSW builds a multi-layer proximity graph for approximate nearest neighbor vector search with logarithmic complexity scaling.",
+		URL:      "https://arxiv.org/abs/1603.09320",
+	},
+	{
+		Title:    "Language Models are Few-Shot Learners",
+		Authors:  []string{"Brown", "Mann", "Ryder", "Subbiah", "Kaplan"},
+		Year:     2020,
+		Abstract: "Scaling up language models to 175 billion parameters greatly improves task-agnostic few-shot performance without gradient updates or fine-tuning.",
+		URL:      "https://arxiv.org/abs/2005.14165",
+	},
+	{
+		Title:    "Chain-of-Thought Prompting Elicits Reasoning in Large Language Models",
+		Authors:  []string{"Wei", "Wang", "Schuurmans", "Bosma", "Ichter", "Xia", "Chi", "Le", "Zhou"},
+		Year:     2022,
+		Abstract: "Generating a chain of thought, a series of intermediate reasoning steps, significantly improves the ability of large language models to perform complex reasoning.",
+		URL:      "https://arxiv.org/abs/2201.11903",
+	},
+	{
+		Title:    "ReAct: Synergizing Reasoning and Acting in Language Models",
+		Authors:  []string{"Yao", "Zhao", "Yu", "Du", "Shafran", "Narasimhan", "Cao"},
+		Year:     2022,
+		Abstract: "ReAct interleaves reasoning traces and task-specific actions so language model agents can plan, use tools and update their plans from observations.",
+		URL:      "https://arxiv.org/abs/2210.03629",
+	},
+}
+
+// wordPattern splits text into lowercase search terms
+var wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+// terms returns the distinct words of text that are longer than two characters
+func terms(text string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if len(word) > 2 && !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// SearchPapers ranks the paper catalog by how many query terms each title and abstract
+// contains, title matches counting double
+func SearchPapers(ctx context.Context, input map[string]interface{}) (*Result, error) {
+	query := stringInput(input, "query")
+	maxResults := intInput(input, "max_results", 10)
+
+	type scored struct {
+		paper Paper
+		score int
+	}
+	var matches []scored
+	queryTerms := terms(query)
+	for _, paper := range paperCatalog {
+		title := strings.ToLower(paper.Title)
+		abstract := strings.ToLower(paper.Abstract)
+		score := 0
+		for _, term := range queryTerms {
+			if strings.Contains(title, term) {
+				score += 2
+			}
+			if strings.Contains(abstract, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{paper, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	if maxResults > 0 && len(matches) > maxResults {
+		matches = matches[:maxResults]
+	}
+
+	papers := make([]map[string]interface{}, 0, len(matches))
+	completion := 0
+	for _, match := range matches {
+		papers = append(papers, map[string]interface{}{
+			"title":    match.paper.Title,
+			"authors":  match.paper.Authors,
+			"year":     match.paper.Year,
+			"abstract": match.paper.Abstract,
+			"url":      match.paper.URL,
+			"score":    match.score,
+		})
+		completion += tokens.Count(searchModel, match.paper.Title+match.paper.Abstract)
+	}
+
+	return &Result{
+		Output: map[string]interface{}{
+			"query":  query,
+			"papers": papers,
+			"total":  len(papers),
+		},
+		Model:            searchModel,
+		Prompt:           query,
+		CompletionTokens: completion,
+	}, nil
+}
+
+// maxLineLength is the line length analyze_code reports as too long
+const maxLineLength = 120
+
+// functionPatterns match function declarations per language
+var functionPatterns = map[string]*regexp.Regexp{
+	"go":         regexp.MustCompile(`^\s*func\s`),
+	"python":     regexp.MustCompile(`^\s*(async\s+)?def\s`),
+	"javascript": regexp.MustCompile(`^\s*(async\s+)?function\s|=>\s*[{(]?`),
+	"typescript": regexp.MustCompile(`^\s*(export\s+)?(async\s+)?function\s|=>\s*[{(]?`),
+	"java":       regexp.MustCompile(`^\s*(public|private|protected|static|\s)+[\w<>\[\]]+\s+\w+\s*\([^)]*\)\s*\{`),
+	"rust":       regexp.MustCompile(`^\s*(pub\s+)?fn\s`),
+}
+
+// commentPrefixes start single-line comments per language
+var commentPrefixes = map[string]string{
+	"go":         "//",
+	"python":     "#",
+	"javascript": "//",
+	"typescript": "//",
+	"java":       "//",
+	"rust":       "//",
+}
+
+// detectLanguage guesses the language of code from telltale syntax
+func detectLanguage(code string) string {
+	switch {
+	case strings.Contains(code, "package ") && strings.Contains(code, "func "):
+		return "go"
+	case strings.Contains(code, "def ") && strings.Contains(code, ":\n"):
+		return "python"
+	case strings.Contains(code, "fn ") && strings.Contains(code, "let "):
+		return "rust"
+	case strings.Contains(code, "public class ") || strings.Contains(code, "private "):
+		return "java"
+	case strings.Contains(code, "interface ") && strings.Contains(code, ": "):
+		return "typescript"
+	case strings.Contains(code, "function ") || strings.Contains(code, "=>"):
+		return "javascript"
+	}
+	return "unknown"
+}
+
+// AnalyzeCode reports line and function counts, nesting depth and line-level issues:
+// TODO/FIXME markers, overlong lines and trailing whitespace
+func AnalyzeCode(ctx context.Context, input map[string]interface{}) (*Result, error) {
+	code := stringInput(input, "code")
+	language := strings.ToLower(stringInput(input, "language"))
+	if ext := strings.TrimPrefix(filepath.Ext(language), "."); ext != "" {
+		language = ext
+	}
+	switch language {
+	case "":
+		language = detectLanguage(code)
+	case "golang":
+		language = "go"
+	case "py":
+		language = "python"
+	case "js":
+		language = "javascript"
+	case "ts":
+		language = "typescript"
+	case "rs":
+		language = "rust"
+	}
+
+	var (
+		total, blank, comments, functions int
+		depth, maxDepth                   int
+		issues                            []map[string]interface{}
+	)
+	issue := func(line int, severity, message string) {
+		issues = append(issues, map[string]interface{}{"line": line, "severity": severity, "message": message})
+	}
+
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	for i, line := range lines {
+		number := i + 1
+		total++
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			blank++
+		case commentPrefixes[language] != "" && strings.HasPrefix(trimmed, commentPrefixes[language]):
+			comments++
+		}
+		if pattern, ok := functionPatterns[language]; ok && pattern.MatchString(line) {
+			functions++
+		}
+
+		if strings.Contains(line, "TODO") || strings.Contains(line, "FIXME") {
+			issue(number, "info", "unresolved TODO/FIXME marker")
+		}
+		if len(line) > maxLineLength {
+			issue(number, "warning", "line exceeds 120 characters")
+		}
+		if trimmed != "" && strings.TrimRight(line, " \t") != line {
+			issue(number, "info", "trailing whitespace")
+		}
+
+		// Brace nesting for C-like languages, indentation for Python
+		if language == "python" {
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			if trimmed != "" && indent/4 > maxDepth {
+				maxDepth = indent / 4
+			}
+			continue
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	if maxDepth > 4 {
+		issue(0, "warning", "nesting deeper than 4 levels")
+	}
+	if issues == nil {
+		issues = []map[string]interface{}{}
+	}
+
+	return &Result{
+		Output: map[string]interface{}{
+			"language": language,
+			"metrics": map[string]interface{}{
+				"lines":         total,
+				"code_lines":    total - blank - comments,
+				"comment_lines": comments,
+				"blank_lines":   blank,
+				"functions":     functions,
+				"max_nesting":   maxDepth,
+			},
+			"issues": issues,
+		},
+		Model:            analysisModel,
+		Prompt:           code,
+		CompletionTokens: 20 * (len(issues) + 1),
+	}, nil
+}
+
+// sentencePattern splits text after sentence-ending punctuation
+var sentencePattern = regexp.MustCompile(`[^.!?]+[.!?]*`)
+
+// sentences splits a document into trimmed, non-empty sentences
+func sentences(document string) []string {
+	var result []string
+	for _, s := range sentencePattern.FindAllString(document, -1) {
+		if s = strings.Join(strings.Fields(s), " "); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// fitWords appends sentences in order while the summary stays within maxWords; the first
+// sentence is always kept, truncated if it alone is too long
+func fitWords(candidates []string, maxWords int) []string {
+	var kept []string
+	words := 0
+	for _, s := range candidates {
+		n := len(strings.Fields(s))
+		if words+n > maxWords {
+			if len(kept) == 0 {
+				kept = append(kept, strings.Join(strings.Fields(s)[:maxWords], " ")+"...")
+			}
+			break
+		}
+		kept = append(kept, s)
+		words += n
+	}
+	return kept
+}
+
+// Summarize produces an extractive summary of at most max_length words from the
+// sentences whose words occur most often in the document, kept in document order
+func Summarize(ctx context.Context, input map[string]interface{}) (*Result, error) {
+	document := stringInput(input, "document")
+	maxWords := intInput(input, "max_length", 200)
+	if maxWords <= 0 {
+		maxWords = 200
+	}
+
+	all := sentences(document)
+	frequency := make(map[string]int)
+	for _, word := range wordPattern.FindAllString(strings.ToLower(document), -1) {
+		if len(word) > 3 {
+			frequency[word]++
+		}
+	}
+
+	// Score sentences by the average frequency of their words
+	scores := make([]float64, len(all))
+	for i, s := range all {
+		words := wordPattern.FindAllString(strings.ToLower(s), -1)
+		if len(words) == 0 {
+			continue
+		}
+		total := 0
+		for _, word := range words {
+			total += frequency[word]
+		}
+		scores[i] = float64(total) / float64(len(words))
+	}
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	// Take the best sentences that fit, then restore document order
+	selected := make(map[int]bool)
+	words := 0
+	for _, i := range order {
+		n := len(strings.Fields(all[i]))
+		if words+n > maxWords {
+			continue
+		}
+		selected[i] = true
+		words += n
+	}
+	var picked []string
+	for i, s := range all {
+		if selected[i] {
+			picked = append(picked, s)
+		}
+	}
+	if len(picked) == 0 && len(all) > 0 {
+		picked = fitWords(all, maxWords)
+	}
+
+	summary := strings.Join(picked, " ")
+	return &Result{
+		Output: map[string]interface{}{
+			"summary":         summary,
+			"sentences":       len(picked),
+			"source_words":    len(strings.Fields(document)),
+			"summary_words":   len(strings.Fields(summary)),
+			"compression_pct": compression(document, summary),
+		},
+		Model:            summaryModel,
+		Prompt:           document,
+		CompletionTokens: tokens.Count(summaryModel, summary),
+	}, nil
+}
+
+// fastSummaryWords bounds the length of a fast summary
+const fastSummaryWords = 60
+
+// SummarizeFast summarizes a document by its leading sentences
+func SummarizeFast(ctx context.Context, input map[string]interface{}) (*Result, error) {
+	document := stringInput(input, "document")
+	summary := strings.Join(fitWords(sentences(document), fastSummaryWords), " ")
+
+	// The cheaper model only reads what it keeps
+	return &Result{
+		Output: map[string]interface{}{
+			"summary":         summary,
+			"source_words":    len(strings.Fields(document)),
+			"summary_words":   len(strings.Fields(summary)),
+			"compression_pct": compression(document, summary),
+		},
+		Model:            fastSummaryModel,
+		Prompt:           summary,
+		CompletionTokens: tokens.Count(fastSummaryModel, summary),
+	}, nil
+}
+
+// compression returns how much shorter the summary is than the document, in percent
+func compression(document, summary string) int {
+	source := len(strings.Fields(document))
+	if source == 0 {
+		return 0
+	}
+	return 100 - 100*len(strings.Fields(summary))/source
+}
@@ -0,0 +1,91 @@
+package capabilities
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchPapers(t *testing.T) {
+	result, err := SearchPapers(context.Background(), map[string]interface{}{"query": "retrieval augmented generation", "max_results": float64(2)})
+	require.NoError(t, err)
+	papers := result.Output["papers"].([]map[string]interface{})
+	require.Len(t, papers, 2)
+	assert.Equal(t, "Retrieval-Augmented Generation for Knowledge-Intensive NLP Tasks", papers[0]["title"])
+	assert.Greater(t, result.CompletionTokens, 0)
+
+	result, err = SearchPapers(context.Background(), map[string]interface{}{"query": "zebra"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Output["total"])
+}
+
+func TestAnalyzeCode(t *testing.T) {
+	code := "package main\n\n// main does nothing yet\nfunc main() {\n\t// TODO: implement\n\tif true { \n\t}\n}\n"
+	result, err := AnalyzeCode(context.Background(), map[string]interface{}{"code": code})
+	require.NoError(t, err)
+	assert.Equal(t, "go", result.Output["language"])
+
+	metrics := result.Output["metrics"].(map[string]interface{})
+	assert.Equal(t, 8, metrics["lines"])
+	assert.Equal(t, 1, metrics["blank_lines"])
+	assert.Equal(t, 2, metrics["comment_lines"])
+	assert.Equal(t, 1, metrics["functions"])
+	assert.Equal(t, 2, metrics["max_nesting"])
+
+	issues := result.Output["issues"].([]map[string]interface{})
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue["message"].(string))
+	}
+	assert.ElementsMatch(t, []string{"unresolved TODO/FIXME marker", "trailing whitespace"}, messages)
+
+	result, err = AnalyzeCode(context.Background(), map[string]interface{}{"code": "def f():\n    return 1\n", "language": "py"})
+	require.NoError(t, err)
+	assert.Equal(t, "python", result.Output["language"])
+	assert.Equal(t, 1, result.Output["metrics"].(map[string]interface{})["functions"])
+}
+
+const document = "Vector databases store embeddings. Embeddings capture the meaning of documents. " +
+	"The weather was pleasant on Tuesday. Search over embeddings finds documents with similar meaning. " +
+	"Lunch was served at noon."
+
+func TestSummarize(t *testing.T) {
+	result, err := Summarize(context.Background(), map[string]interface{}{"document": document, "max_length": 15})
+	require.NoError(t, err)
+	summary := result.Output["summary"].(string)
+	assert.LessOrEqual(t, len(strings.Fields(summary)), 15)
+	assert.Contains(t, summary, "embeddings")
+	assert.NotContains(t, summary, "weather")
+	assert.Equal(t, summaryModel, result.Model)
+
+	// A first sentence longer than the limit is truncated rather than dropped
+	result, err = Summarize(context.Background(), map[string]interface{}{"document": document, "max_length": 2})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Output["summary"])
+}
+
+func TestSummarizeFast(t *testing.T) {
+	result, err := SummarizeFast(context.Background(), map[string]interface{}{"document": document})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Output["summary"].(string), "Vector databases store embeddings."))
+	assert.Equal(t, fastSummaryModel, result.Model)
+}
+
+func TestRegisterBuiltins(t *testing.T) {
+	card := protocol.NewAgentCard("agent", "Agent", "1.0.0", "test")
+	card.AddCapability(protocol.Capability{Name: "summarize_document", InputSchema: map[string]interface{}{"required": []string{"document"}}})
+	card.AddCapability(protocol.Capability{Name: "translate"})
+
+	registry := NewRegistry()
+	assert.Equal(t, []string{"translate"}, RegisterBuiltins(registry, card))
+	assert.True(t, registry.Has("summarize_document"))
+	assert.False(t, registry.Has("translate"))
+
+	result, err := registry.Execute(context.Background(), "summarize_document", map[string]interface{}{"document": document})
+	require.NoError(t, err)
+	assert.Greater(t, result.CostUSD, 0.0)
+}
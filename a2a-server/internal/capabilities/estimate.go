@@ -0,0 +1,117 @@
+package capabilities
+
+import (
+	"encoding/json"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+)
+
+// tokensPerWord approximates the tokens of English text per word
+const tokensPerWord = 4.0 / 3.0
+
+// CostModel projects the tokens a capability uses from its input, before it runs
+type CostModel struct {
+	// Model the tokens are billed as
+	Model string
+	// PromptKeys are the input properties sent to the model; empty counts the whole input
+	PromptKeys []string
+	// PromptTokens, when set, projects the prompt instead of PromptKeys
+	PromptTokens func(input map[string]interface{}) int
+	// CompletionTokens projects the completion from the input and its prompt tokens; nil
+	// projects none
+	CompletionTokens func(input map[string]interface{}, promptTokens int) int
+}
+
+// Estimate is the projected usage and cost of running a capability on an input
+type Estimate struct {
+	Model            string  `json:"model,omitempty"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// Estimate projects the usage of running the capability on input
+func (m CostModel) Estimate(input map[string]interface{}) Estimate {
+	prompt := 0
+	switch {
+	case m.PromptTokens != nil:
+		prompt = m.PromptTokens(input)
+	case len(m.PromptKeys) == 0:
+		data, _ := json.Marshal(input)
+		prompt = tokens.Count(m.Model, string(data))
+	default:
+		for _, key := range m.PromptKeys {
+			prompt += tokens.Count(m.Model, stringInput(input, key))
+		}
+	}
+	completion := 0
+	if m.CompletionTokens != nil {
+		completion = m.CompletionTokens(input, prompt)
+	}
+	return Estimate{
+		Model:            m.Model,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		CostUSD:          cost.CalculateCost(m.Model, prompt, completion),
+	}
+}
+
+// BuiltinCostModels returns the cost models of the built-in executors by capability name
+func BuiltinCostModels() map[string]CostModel {
+	return map[string]CostModel{
+		"search_papers": {
+			Model:      searchModel,
+			PromptKeys: []string{"query"},
+			// Every match returns its title and abstract
+			CompletionTokens: func(input map[string]interface{}, _ int) int {
+				results := intInput(input, "max_results", 10)
+				if results <= 0 || results > len(paperCatalog) {
+					results = len(paperCatalog)
+				}
+				return results * averagePaperTokens()
+			},
+		},
+		"analyze_code": {
+			Model:      analysisModel,
+			PromptKeys: []string{"code"},
+			// About one issue per 100 tokens of code, 20 tokens each
+			CompletionTokens: func(_ map[string]interface{}, prompt int) int {
+				return 20 * (1 + prompt/100)
+			},
+		},
+		"summarize_document": {
+			Model:      summaryModel,
+			PromptKeys: []string{"document"},
+			CompletionTokens: func(input map[string]interface{}, prompt int) int {
+				maxWords := intInput(input, "max_length", 200)
+				if maxWords <= 0 {
+					maxWords = 200
+				}
+				return min(prompt, int(float64(maxWords)*tokensPerWord))
+			},
+		},
+		"summarize_document_fast": {
+			// The cheaper model only reads the leading sentences it keeps
+			Model:        fastSummaryModel,
+			PromptTokens: fastSummaryTokens,
+			CompletionTokens: func(input map[string]interface{}, _ int) int {
+				return fastSummaryTokens(input)
+			},
+		},
+	}
+}
+
+// fastSummaryTokens projects the tokens of a fast summary of the input document
+func fastSummaryTokens(input map[string]interface{}) int {
+	return min(tokens.Count(fastSummaryModel, stringInput(input, "document")), int(fastSummaryWords*tokensPerWord))
+}
+
+// averagePaperTokens returns the average tokens of a catalog paper's title and abstract
+func averagePaperTokens() int {
+	total := 0
+	for _, paper := range paperCatalog {
+		total += tokens.Count(searchModel, paper.Title+paper.Abstract)
+	}
+	return total / len(paperCatalog)
+}
@@ -0,0 +1,58 @@
+package capabilities
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostModel_ScalesWithInput(t *testing.T) {
+	model := BuiltinCostModels()["summarize_document"]
+	short := model.Estimate(map[string]interface{}{"document": "Embeddings capture meaning."})
+	long := model.Estimate(map[string]interface{}{"document": strings.Repeat("Embeddings capture meaning. ", 200)})
+
+	assert.Equal(t, summaryModel, short.Model)
+	assert.Greater(t, long.PromptTokens, short.PromptTokens)
+	assert.Greater(t, long.CostUSD, short.CostUSD)
+	assert.Equal(t, cost.CalculateCost(summaryModel, long.PromptTokens, long.CompletionTokens), long.CostUSD)
+	// The summary is bounded by max_length
+	assert.Equal(t, 266, long.CompletionTokens)
+
+	whole := CostModel{Model: searchModel}.Estimate(map[string]interface{}{"query": "transformers"})
+	assert.Equal(t, tokens.Count(searchModel, `{"query":"transformers"}`), whole.PromptTokens)
+	assert.Zero(t, whole.CompletionTokens)
+}
+
+func TestRegistry_EstimateMatchesExecution(t *testing.T) {
+	registry := NewRegistry()
+	card := protocol.NewAgentCard("agent", "Agent", "1.0.0", "test")
+	card.AddCapability(protocol.Capability{
+		Name: "search_papers",
+		InputSchema: map[string]interface{}{
+			"properties": map[string]interface{}{"max_results": map[string]interface{}{"default": 2}},
+		},
+	})
+	card.AddCapability(protocol.Capability{Name: "translate"})
+	RegisterBuiltins(registry, card)
+
+	input := map[string]interface{}{"query": "attention transformer retrieval"}
+	estimate, ok := registry.Estimate("search_papers", input)
+	require.True(t, ok)
+	result, err := registry.Execute(context.Background(), "search_papers", input)
+	require.NoError(t, err)
+	assert.Equal(t, result.PromptTokens, estimate.PromptTokens)
+	// Two results by the schema default, of about average length
+	assert.InDelta(t, result.CompletionTokens, estimate.CompletionTokens, float64(result.CompletionTokens)/2)
+
+	_, ok = registry.Estimate("translate", input)
+	assert.False(t, ok, "no executor")
+	registry.Register(protocol.Capability{Name: "custom"}, ExecutorFunc(SearchPapers))
+	_, ok = registry.Estimate("custom", input)
+	assert.False(t, ok, "no cost model")
+}
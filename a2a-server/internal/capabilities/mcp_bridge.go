@@ -0,0 +1,280 @@
+package capabilities
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MCPProtocolVersion is the MCP protocol version the bridge negotiates
+const MCPProtocolVersion = "2024-11-05"
+
+// maxMCPResponseBytes bounds the response read from the MCP server
+const maxMCPResponseBytes = 4 << 20
+
+// tracerName names the spans of calls to the MCP server
+const tracerName = "github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+
+// authTokenKey is the context key of the caller's bearer token
+type authTokenKey struct{}
+
+// WithAuthToken returns a context carrying the bearer token of the caller a capability
+// runs for; executors calling other services forward it
+func WithAuthToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, authTokenKey{}, token)
+}
+
+// AuthToken returns the caller's bearer token, or "" if there is none
+func AuthToken(ctx context.Context) string {
+	token, _ := ctx.Value(authTokenKey{}).(string)
+	return token
+}
+
+// MCPBridgeConfig configures executors backed by the tools of an MCP server
+type MCPBridgeConfig struct {
+	// URL is the MCP server's JSON-RPC endpoint, e.g. http://mcp-server:8080/mcp
+	URL string
+	// Token authenticates calls made for tasks created without a bearer token; the MCP
+	// server scopes those calls to the token's tenant
+	Token string
+	// Timeout bounds each HTTP request; zero leaves it to the capability timeout
+	Timeout time.Duration
+	// TLS, when set, configures the connections to an https URL, e.g. with the client
+	// certificate of mutual TLS
+	TLS *tls.Config
+}
+
+// MCPError is a JSON-RPC error returned by the MCP server
+type MCPError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *MCPError) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// mcpContent is a content block of an MCP tool result
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpToolResult is the result of tools/call
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// MCPBridge calls MCP server tools over HTTP so capabilities can be fulfilled by them.
+// Calls carry the caller's bearer token, falling back to the configured token, and the
+// trace context of the task, so the MCP server's spans join the task's trace.
+type MCPBridge struct {
+	config MCPBridgeConfig
+	client *http.Client
+	nextID atomic.Int64
+
+	// The initialize handshake runs before the first tool call and again after a failure
+	mu          sync.Mutex
+	initialized bool
+	serverName  string
+}
+
+// NewMCPBridge creates a bridge to the MCP server at config.URL
+func NewMCPBridge(config MCPBridgeConfig) *MCPBridge {
+	client := &http.Client{Timeout: config.Timeout}
+	if config.TLS != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config.TLS
+		client.Transport = transport
+	}
+	return &MCPBridge{config: config, client: client}
+}
+
+// ServerName returns the name the MCP server reported during initialize, or "" before it
+func (b *MCPBridge) ServerName() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.serverName
+}
+
+// CallTool initializes the session if needed and calls an MCP tool, returning the text
+// of its first content block
+func (b *MCPBridge) CallTool(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	if err := b.initialize(ctx); err != nil {
+		return "", err
+	}
+
+	var result mcpToolResult
+	params := map[string]interface{}{"name": name, "arguments": args}
+	if err := b.call(ctx, "tools/call", params, &result); err != nil {
+		return "", fmt.Errorf("MCP tool %s: %w", name, err)
+	}
+	if len(result.Content) == 0 {
+		return "", fmt.Errorf("MCP tool %s returned no content", name)
+	}
+	if result.IsError {
+		return "", fmt.Errorf("MCP tool %s failed: %s", name, result.Content[0].Text)
+	}
+	return result.Content[0].Text, nil
+}
+
+// initialize runs the MCP initialize handshake once and checks the protocol version
+func (b *MCPBridge) initialize(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.initialized {
+		return nil
+	}
+
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	params := map[string]interface{}{
+		"protocolVersion": MCPProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "a2a-server", "version": "1.0.0"},
+	}
+	if err := b.call(ctx, "initialize", params, &result); err != nil {
+		return fmt.Errorf("MCP initialize: %w", err)
+	}
+	if result.ProtocolVersion != MCPProtocolVersion {
+		return fmt.Errorf("MCP server speaks protocol %q, want %q", result.ProtocolVersion, MCPProtocolVersion)
+	}
+	b.initialized = true
+	b.serverName = result.ServerInfo.Name
+	return nil
+}
+
+// call sends one JSON-RPC request and decodes its result into out
+func (b *MCPBridge) call(ctx context.Context, method string, params interface{}, out interface{}) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "mcp."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("rpc.system", "jsonrpc"), attribute.String("rpc.method", method)))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      b.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	token := AuthToken(ctx)
+	if token == "" {
+		token = b.config.Token
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("MCP server returned %s", resp.Status)
+	}
+
+	var rpc struct {
+		Result json.RawMessage `json:"result"`
+		Error  *MCPError       `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMCPResponseBytes)).Decode(&rpc); err != nil {
+		return fmt.Errorf("invalid MCP response: %w", err)
+	}
+	if rpc.Error != nil {
+		return rpc.Error
+	}
+	if err := json.Unmarshal(rpc.Result, out); err != nil {
+		return fmt.Errorf("invalid MCP %s result: %w", method, err)
+	}
+	return nil
+}
+
+// SearchPapers returns an executor for search_papers that searches the caller's documents
+// with an MCP search tool such as hybrid_search
+func (b *MCPBridge) SearchPapers(tool string) Executor {
+	return ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*Result, error) {
+		query := stringInput(input, "query")
+		maxResults := intInput(input, "max_results", 10)
+
+		text, err := b.CallTool(ctx, tool, map[string]interface{}{"query": query, "limit": maxResults})
+		if err != nil {
+			return nil, err
+		}
+		var hits []struct {
+			DocID      string  `json:"doc_id"`
+			Collection string  `json:"collection"`
+			Title      string  `json:"title"`
+			Content    string  `json:"content"`
+			Score      float64 `json:"score"`
+		}
+		if err := json.Unmarshal([]byte(text), &hits); err != nil {
+			return nil, fmt.Errorf("MCP tool %s did not return search results: %w", tool, err)
+		}
+
+		papers := make([]map[string]interface{}, 0, len(hits))
+		completion := 0
+		for _, hit := range hits {
+			papers = append(papers, map[string]interface{}{
+				"doc_id":     hit.DocID,
+				"collection": hit.Collection,
+				"title":      hit.Title,
+				"abstract":   hit.Content,
+				"score":      hit.Score,
+			})
+			completion += tokens.Count(searchModel, hit.Title+hit.Content)
+		}
+
+		return &Result{
+			Output: map[string]interface{}{
+				"query":  query,
+				"papers": papers,
+				"total":  len(papers),
+				"source": "mcp:" + tool,
+			},
+			Model:            searchModel,
+			Prompt:           query,
+			CompletionTokens: completion,
+		}, nil
+	})
+}
@@ -0,0 +1,150 @@
+package capabilities
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeMCPServer answers initialize and tools/call, recording the methods and headers it saw
+type fakeMCPServer struct {
+	mu      sync.Mutex
+	methods []string
+	headers []http.Header
+	result  interface{}
+}
+
+func (f *fakeMCPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     interface{}     `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.methods = append(f.methods, req.Method)
+	f.headers = append(f.headers, r.Header.Clone())
+	f.mu.Unlock()
+
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "initialize":
+		response["result"] = map[string]interface{}{
+			"protocolVersion": MCPProtocolVersion,
+			"serverInfo":      map[string]interface{}{"name": "secure-mcp-server", "version": "1.0.0"},
+		}
+	case "tools/call":
+		response["result"] = f.result
+	default:
+		response["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func searchResult(hits ...map[string]interface{}) map[string]interface{} {
+	text, _ := json.Marshal(hits)
+	return map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": string(text)}}}
+}
+
+func TestMCPBridge_SearchPapers(t *testing.T) {
+	fake := &fakeMCPServer{result: searchResult(
+		map[string]interface{}{"doc_id": "doc-1", "collection": "default", "title": "RAG overview", "content": "Retrieval first", "score": 0.8},
+	)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	bridge := NewMCPBridge(MCPBridgeConfig{URL: server.URL, Token: "service-token"})
+	executor := bridge.SearchPapers("hybrid_search")
+
+	// The caller's token wins over the service token
+	ctx := WithAuthToken(context.Background(), "caller-token")
+	result, err := executor.Execute(ctx, map[string]interface{}{"query": "rag", "max_results": 5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Output["total"])
+	assert.Equal(t, "mcp:hybrid_search", result.Output["source"])
+	papers := result.Output["papers"].([]map[string]interface{})
+	assert.Equal(t, "doc-1", papers[0]["doc_id"])
+	assert.Equal(t, "secure-mcp-server", bridge.ServerName())
+
+	_, err = executor.Execute(context.Background(), map[string]interface{}{"query": "rag"})
+	require.NoError(t, err)
+
+	// initialize runs once, before the first call
+	assert.Equal(t, []string{"initialize", "tools/call", "tools/call"}, fake.methods)
+	assert.Equal(t, "Bearer caller-token", fake.headers[1].Get("Authorization"))
+	assert.Equal(t, "Bearer service-token", fake.headers[2].Get("Authorization"))
+}
+
+func TestMCPBridge_PropagatesTraceContext(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	provider := otel.GetTracerProvider()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	defer func() {
+		otel.SetTextMapPropagator(propagator)
+		otel.SetTracerProvider(provider)
+	}()
+
+	fake := &fakeMCPServer{result: searchResult()}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "task")
+	defer span.End()
+	_, err := NewMCPBridge(MCPBridgeConfig{URL: server.URL}).CallTool(ctx, "hybrid_search", map[string]interface{}{"query": "rag"})
+	require.NoError(t, err)
+
+	for _, header := range fake.headers {
+		remote := propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(header))
+		assert.Equal(t, span.SpanContext().TraceID(), trace.SpanContextFromContext(remote).TraceID())
+	}
+}
+
+func TestMCPBridge_Errors(t *testing.T) {
+	fake := &fakeMCPServer{result: map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": "query is required"}},
+		"isError": true,
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	bridge := NewMCPBridge(MCPBridgeConfig{URL: server.URL})
+	_, err := bridge.CallTool(context.Background(), "hybrid_search", nil)
+	assert.ErrorContains(t, err, "query is required")
+
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}))
+	defer unauthorized.Close()
+	_, err = NewMCPBridge(MCPBridgeConfig{URL: unauthorized.URL}).CallTool(context.Background(), "hybrid_search", nil)
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestMCPBridge_TLS(t *testing.T) {
+	fake := &fakeMCPServer{result: searchResult()}
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+
+	// Without the server's CA the handshake fails
+	_, err := NewMCPBridge(MCPBridgeConfig{URL: server.URL}).CallTool(context.Background(), "hybrid_search", nil)
+	assert.Error(t, err)
+
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+	bridge := NewMCPBridge(MCPBridgeConfig{URL: server.URL, TLS: tlsConfig})
+	_, err = bridge.CallTool(context.Background(), "hybrid_search", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secure-mcp-server", bridge.ServerName())
+}
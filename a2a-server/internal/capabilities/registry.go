@@ -0,0 +1,325 @@
+// Package capabilities runs the capabilities an agent advertises. Each capability is
+// backed by an Executor registered with its protocol.Capability, one per version of a
+// versioned capability; the Registry validates
+// task input against the capability's input schema, bounds execution with per-capability
+// timeouts and prices the tokens executors report.
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+)
+
+// ErrUnknownCapability is returned when no executor is registered for a capability
+var ErrUnknownCapability = errors.New("no executor registered for capability")
+
+// Executor runs one capability
+type Executor interface {
+	// Execute runs the capability on validated input; it must stop when ctx is cancelled
+	Execute(ctx context.Context, input map[string]interface{}) (*Result, error)
+}
+
+// ExecutorFunc adapts a function to an Executor
+type ExecutorFunc func(ctx context.Context, input map[string]interface{}) (*Result, error)
+
+// Execute calls f
+func (f ExecutorFunc) Execute(ctx context.Context, input map[string]interface{}) (*Result, error) {
+	return f(ctx, input)
+}
+
+// Result is the output of a capability and what producing it used
+type Result struct {
+	Output map[string]interface{}
+	// Artifacts are typed outputs kept with the task besides Output, such as generated
+	// files; large file parts are moved to the blob store
+	Artifacts []protocol.Artifact
+	// Model the tokens are billed as; empty for capabilities that use no model
+	Model string
+	// Prompt is the text sent to the model. When set, Execute counts PromptTokens from it
+	// with the model's tokenizer instead of trusting the executor's count.
+	Prompt           string
+	PromptTokens     int
+	CompletionTokens int
+	// CostUSD is the cost of the execution; when zero it is calculated from the model and tokens
+	CostUSD float64
+	// PriceVersion is the version of the price table CostUSD was calculated with
+	PriceVersion string
+	// InputRequired pauses the task with this question for the user instead of completing
+	// it; Output and Artifacts are not kept. Once the user answers, the capability runs
+	// again with the conversation in its input under protocol.ConversationKey.
+	InputRequired string
+}
+
+// RequestInput returns the result of an execution that needs the user to answer prompt
+// before it can go on, such as a clarifying question. Executors read the answers so far
+// with protocol.Conversation.
+func RequestInput(prompt string) *Result {
+	return &Result{InputRequired: prompt}
+}
+
+// Usage returns the result's usage record for a task
+func (r *Result) Usage(userID, taskID string) cost.Usage {
+	return cost.Usage{
+		UserID:           userID,
+		TaskID:           taskID,
+		Model:            r.Model,
+		PromptTokens:     r.PromptTokens,
+		CompletionTokens: r.CompletionTokens,
+		TotalTokens:      r.PromptTokens + r.CompletionTokens,
+		CostUSD:          r.CostUSD,
+		PriceVersion:     r.PriceVersion,
+	}
+}
+
+// ValidationError reports task input that does not match the capability's input schema
+type ValidationError struct {
+	Capability string
+	Errors     []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Errors))
+	for i, problem := range e.Errors {
+		problems[i] = problem.String()
+	}
+	return fmt.Sprintf("invalid input for %s: %s", e.Capability, strings.Join(problems, "; "))
+}
+
+// TimeoutError is returned when a capability runs longer than its timeout
+type TimeoutError struct {
+	Capability string
+	Timeout    time.Duration
+	Elapsed    time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("capability %s timed out after %s (limit %s)", e.Capability, e.Elapsed.Round(time.Millisecond), e.Timeout)
+}
+
+// entry is a registered executor, the capability version it runs, whose input schema
+// input is validated against, and the cost model its usage is projected with
+type entry struct {
+	capability protocol.Capability
+	executor   Executor
+	costModel  *CostModel
+}
+
+// Registry maps capability names to the executors of their versions
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string][]entry
+
+	// Execution timeouts; zero means no limit
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+
+	pricing *cost.PricingRegistry
+}
+
+// NewRegistry creates an empty registry that prices tokens with the built-in prices
+func NewRegistry() *Registry {
+	return &Registry{
+		entries:  make(map[string][]entry),
+		timeouts: make(map[string]time.Duration),
+		pricing:  cost.NewPricingRegistry(cost.DefaultPriceTable()),
+	}
+}
+
+// SetPricing prices the tokens executors report with pricing
+func (r *Registry) SetPricing(pricing *cost.PricingRegistry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pricing = pricing
+}
+
+// Pricing returns the prices tokens are billed with
+func (r *Registry) Pricing() *cost.PricingRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pricing
+}
+
+// Register runs capability with executor, validating input against its InputSchema.
+// Each version of a capability is registered with its own executor; registering a
+// version again replaces its executor.
+func (r *Registry) Register(capability protocol.Capability, executor Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := r.entries[capability.Name]
+	for i, e := range versions {
+		if e.capability.Version == capability.Version {
+			versions[i] = entry{capability: capability, executor: executor}
+			return
+		}
+	}
+	r.entries[capability.Name] = append(versions, entry{capability: capability, executor: executor})
+}
+
+// SetCostModel projects the usage of every registered version of a capability with
+// model; see Estimate
+func (r *Registry) SetCostModel(name string, model CostModel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.entries[name] {
+		r.entries[name][i].costModel = &model
+	}
+}
+
+// lookup returns the entry of a capability version, or of its default version when
+// version is empty; see protocol.SelectVersion
+func (r *Registry) lookup(name, version string) (entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := make([]protocol.Capability, len(r.entries[name]))
+	for i, e := range r.entries[name] {
+		versions[i] = e.capability
+	}
+	selected, ok := protocol.SelectVersion(versions, version)
+	if !ok {
+		return entry{}, false
+	}
+	for _, e := range r.entries[name] {
+		if e.capability.Version == selected.Version {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// Estimate projects the usage and cost of running a capability's default version on
+// input, with schema defaults filled in. It reports false for capabilities without a
+// cost model.
+func (r *Registry) Estimate(name string, input map[string]interface{}) (Estimate, bool) {
+	return r.EstimateVersion(name, "", input)
+}
+
+// EstimateVersion is Estimate for a version of a capability
+func (r *Registry) EstimateVersion(name, version string, input map[string]interface{}) (Estimate, bool) {
+	e, ok := r.lookup(name, version)
+	if !ok || e.costModel == nil {
+		return Estimate{}, false
+	}
+	estimate := e.costModel.Estimate(withDefaults(e.capability.InputSchema, input))
+	estimate.CostUSD, _ = r.Pricing().Cost(estimate.Model, estimate.PromptTokens, estimate.CompletionTokens)
+	return estimate, true
+}
+
+// Has reports whether an executor is registered for any version of a capability
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.entries[name]) > 0
+}
+
+// SetTimeouts replaces the default timeout and every per-capability override at once
+func (r *Registry) SetTimeouts(defaultTimeout time.Duration, overrides map[string]time.Duration) {
+	timeouts := make(map[string]time.Duration, len(overrides))
+	for name, timeout := range overrides {
+		timeouts[name] = timeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultTimeout = defaultTimeout
+	r.timeouts = timeouts
+}
+
+// Timeout returns the execution timeout for a capability
+func (r *Registry) Timeout(name string) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if timeout, ok := r.timeouts[name]; ok {
+		return timeout
+	}
+	return r.defaultTimeout
+}
+
+// Execute validates input and runs the executor of the capability's default version.
+// A *ValidationError is returned for input that does not match the schema; schema
+// defaults are filled in for missing properties before the executor sees the input.
+// If the capability has a timeout, its context is cancelled when the timeout expires and
+// a *TimeoutError is returned right away, even if the executor ignores cancellation.
+func (r *Registry) Execute(ctx context.Context, name string, input map[string]interface{}) (*Result, error) {
+	return r.ExecuteVersion(ctx, name, "", input)
+}
+
+// ExecuteVersion is Execute for a version of a capability; an empty version runs the
+// default version. ErrUnknownCapability is returned when the version is not registered.
+func (r *Registry) ExecuteVersion(ctx context.Context, name, version string, input map[string]interface{}) (*Result, error) {
+	e, ok := r.lookup(name, version)
+	if !ok && version != "" {
+		return nil, fmt.Errorf("%w: %s version %s", ErrUnknownCapability, name, version)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCapability, name)
+	}
+
+	schema := e.capability.InputSchema
+	problems, err := Validate(schema, input)
+	if err != nil {
+		return nil, fmt.Errorf("capability %s: %w", name, err)
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Capability: name, Errors: problems}
+	}
+	input = withDefaults(schema, input)
+
+	result, err := r.run(ctx, name, e.executor, input)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &Result{}
+	}
+	if result.Prompt != "" {
+		result.PromptTokens = tokens.Count(result.Model, result.Prompt)
+	}
+	if result.CostUSD == 0 && result.Model != "" {
+		result.CostUSD, result.PriceVersion = r.Pricing().Cost(result.Model, result.PromptTokens, result.CompletionTokens)
+	}
+	return result, nil
+}
+
+// run calls the executor under the capability's timeout
+func (r *Registry) run(ctx context.Context, name string, executor Executor, input map[string]interface{}) (*Result, error) {
+	timeout := r.Timeout(name)
+	if timeout <= 0 {
+		return executor.Execute(ctx, input)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *Result
+		err    error
+	}
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := executor.Execute(ctx, input)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &TimeoutError{Capability: name, Timeout: timeout, Elapsed: time.Since(start)}
+		}
+		return out.result, out.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &TimeoutError{Capability: name, Timeout: timeout, Elapsed: time.Since(start)}
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,234 @@
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var echoCapability = protocol.Capability{
+	Name: "echo",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"text":  map[string]interface{}{"type": "string"},
+			"times": map[string]interface{}{"type": "integer", "default": 2},
+		},
+		"required": []string{"text"},
+	},
+}
+
+func echo(ctx context.Context, input map[string]interface{}) (*Result, error) {
+	return &Result{
+		Output:           map[string]interface{}{"text": input["text"], "times": input["times"]},
+		Model:            "gpt-4",
+		PromptTokens:     1000,
+		CompletionTokens: 500,
+	}, nil
+}
+
+func TestRegistry_ValidatesAndFillsDefaults(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(echoCapability, ExecutorFunc(echo))
+	ctx := context.Background()
+
+	result, err := registry.Execute(ctx, "echo", map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Output["times"])
+	assert.InDelta(t, cost.CalculateCost("gpt-4", 1000, 500), result.CostUSD, 1e-9)
+	assert.Equal(t, 1500, result.Usage("user-1", "task-1").TotalTokens)
+	assert.Equal(t, "builtin-2024", result.Usage("user-1", "task-1").PriceVersion)
+
+	// JSON numbers decode as float64
+	_, err = registry.Execute(ctx, "echo", map[string]interface{}{"text": "hi", "times": float64(3)})
+	assert.NoError(t, err)
+
+	_, err = registry.Execute(ctx, "echo", map[string]interface{}{"times": 1.5})
+	var validation *ValidationError
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, []FieldError{
+		{Field: "/text", Message: "is required"},
+		{Field: "/times", Message: "must be of type integer"},
+	}, validation.Errors)
+	assert.EqualError(t, err, "invalid input for echo: text is required; times must be of type integer")
+
+	_, err = registry.Execute(ctx, "missing", nil)
+	assert.ErrorIs(t, err, ErrUnknownCapability)
+}
+
+func TestRegistry_Versions(t *testing.T) {
+	registry := NewRegistry()
+	version := func(v string) Executor {
+		return ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*Result, error) {
+			return &Result{Output: map[string]interface{}{"version": v}}, nil
+		})
+	}
+	v1 := echoCapability
+	v1.Version, v1.Deprecated = "1", true
+	v2 := echoCapability
+	v2.Version = "2"
+	registry.Register(v1, version("1"))
+	registry.Register(v2, version("2"))
+	registry.SetCostModel("echo", CostModel{Model: "gpt-4"})
+	ctx := context.Background()
+	input := map[string]interface{}{"text": "hi"}
+
+	result, err := registry.ExecuteVersion(ctx, "echo", "1", input)
+	require.NoError(t, err)
+	assert.Equal(t, "1", result.Output["version"])
+
+	// Without a version the latest version not deprecated runs
+	result, err = registry.Execute(ctx, "echo", input)
+	require.NoError(t, err)
+	assert.Equal(t, "2", result.Output["version"])
+
+	_, err = registry.ExecuteVersion(ctx, "echo", "3", input)
+	assert.ErrorIs(t, err, ErrUnknownCapability)
+	assert.EqualError(t, err, "no executor registered for capability: echo version 3")
+
+	// Every version has the cost model
+	_, ok := registry.EstimateVersion("echo", "1", input)
+	assert.True(t, ok)
+	_, ok = registry.EstimateVersion("echo", "3", input)
+	assert.False(t, ok)
+
+	// Registering a version again replaces its executor
+	registry.Register(v1, version("1.1"))
+	result, err = registry.ExecuteVersion(ctx, "echo", "1", input)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1", result.Output["version"])
+}
+
+func TestRegistry_Pricing(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(echoCapability, ExecutorFunc(echo))
+	pricing := cost.NewPricingRegistry(cost.PriceTable{
+		Version: "2025-01",
+		Models:  map[string]cost.ModelPrice{"gpt-4": {PromptUSD: 10, CompletionUSD: 30, Unit: cost.UnitPer1M}},
+	})
+	registry.SetPricing(pricing)
+
+	result, err := registry.Execute(context.Background(), "echo", map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.025, result.CostUSD, 1e-12)
+	assert.Equal(t, "2025-01", result.PriceVersion)
+
+	// Executors that price their own work keep their cost and record no price version
+	registry.Register(protocol.Capability{Name: "priced"}, ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*Result, error) {
+		return &Result{Model: "gpt-4", PromptTokens: 1000, CostUSD: 0.5}, nil
+	}))
+	result, err = registry.Execute(context.Background(), "priced", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, result.CostUSD)
+	assert.Empty(t, result.PriceVersion)
+}
+
+func TestRegistry_CountsPromptTokens(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(protocol.Capability{Name: "overcounted"}, ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*Result, error) {
+		return &Result{Model: "gpt-4", Prompt: "The quick brown fox jumps over the lazy dog.", PromptTokens: 5000}, nil
+	}))
+
+	result, err := registry.Execute(context.Background(), "overcounted", nil)
+	require.NoError(t, err)
+	assert.Equal(t, tokens.Count("gpt-4", "The quick brown fox jumps over the lazy dog."), result.PromptTokens, "the prompt is counted, not the executor's number")
+	assert.Equal(t, result.PromptTokens, result.Usage("user-1", "task-1").PromptTokens)
+}
+
+func TestRegistry_Timeouts(t *testing.T) {
+	registry := NewRegistry()
+	// Ignores cancellation, so only the registry's timeout ends the call
+	registry.Register(protocol.Capability{Name: "slow"}, ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*Result, error) {
+		time.Sleep(200 * time.Millisecond)
+		return &Result{}, nil
+	}))
+	registry.Register(echoCapability, ExecutorFunc(echo))
+	registry.SetTimeouts(time.Second, map[string]time.Duration{"slow": 20 * time.Millisecond})
+	assert.Equal(t, time.Second, registry.Timeout("echo"))
+
+	start := time.Now()
+	_, err := registry.Execute(context.Background(), "slow", nil)
+	var timeout *TimeoutError
+	require.True(t, errors.As(err, &timeout))
+	assert.Equal(t, "slow", timeout.Capability)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+	_, err = registry.Execute(context.Background(), "echo", map[string]interface{}{"text": "hi"})
+	assert.NoError(t, err)
+}
+
+func TestValidate_DecodedSchema(t *testing.T) {
+	// Schemas of agent cards registered over HTTP are decoded from JSON
+	schema := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"tags":  map[string]interface{}{"type": "array"},
+			"score": map[string]interface{}{"type": "number"},
+		},
+		"required": []interface{}{"tags"},
+	}
+	problems, err := Validate(schema, map[string]interface{}{"tags": []interface{}{"a"}, "score": float64(1)})
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	problems, err = Validate(schema, map[string]interface{}{"score": "high"})
+	require.NoError(t, err)
+	assert.Equal(t, []FieldError{
+		{Field: "/score", Message: "must be of type number"},
+		{Field: "/tags", Message: "is required"},
+	}, problems)
+	problems, err = Validate(nil, map[string]interface{}{"anything": true})
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestValidate_Draft2020(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"$defs": map[string]interface{}{
+			"item": map[string]interface{}{
+				"type":                 "object",
+				"properties":           map[string]interface{}{"name": map[string]interface{}{"type": "string", "minLength": 1}},
+				"required":             []string{"name"},
+				"additionalProperties": false,
+			},
+		},
+		"properties": map[string]interface{}{
+			"mode":  map[string]interface{}{"enum": []string{"fast", "thorough"}},
+			"limit": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 100},
+			"items": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/item"}},
+		},
+	}
+	problems, err := Validate(schema, map[string]interface{}{
+		"mode":  "fast",
+		"limit": 10,
+		"items": []map[string]interface{}{{"name": "a"}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	problems, err = Validate(schema, map[string]interface{}{
+		"mode":  "slow",
+		"limit": 0,
+		"items": []interface{}{map[string]interface{}{"name": "", "extra": true}, map[string]interface{}{}},
+	})
+	require.NoError(t, err)
+	fields := make([]string, len(problems))
+	for i, problem := range problems {
+		fields[i] = problem.Field
+	}
+	assert.Equal(t, []string{"/items/0/extra", "/items/0/name", "/items/1/name", "/limit", "/mode"}, fields)
+	assert.Equal(t, "is not allowed", problems[0].Message)
+	assert.Equal(t, "is required", problems[2].Message)
+
+	// Schemas cannot load references from elsewhere
+	_, err = Validate(map[string]interface{}{"$ref": "file:///etc/passwd"}, nil)
+	assert.Error(t, err)
+	_, err = Validate(map[string]interface{}{"type": 42}, nil)
+	assert.Error(t, err)
+}
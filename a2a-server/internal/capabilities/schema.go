@@ -0,0 +1,189 @@
+package capabilities
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// FieldError is one way task input fails to match a capability's input schema
+type FieldError struct {
+	// Field is the JSON Pointer of the offending value, e.g. /items/0/name; it is empty
+	// when the input as a whole is at fault
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	if e.Field == "" {
+		return "input " + e.Message
+	}
+	return strings.TrimPrefix(e.Field, "/") + " " + e.Message
+}
+
+// schemaURL is the location schemas are compiled under; references to other locations
+// are not loaded
+const schemaURL = "urn:a2a:input-schema"
+
+// compiledSchemas caches compiled schemas by their JSON encoding
+var compiledSchemas sync.Map
+
+var messages = message.NewPrinter(language.English)
+
+// Validate checks input against a JSON Schema, draft 2020-12 unless the schema's $schema
+// names another draft. It returns one FieldError per problem, sorted by field so the
+// output is stable, and an error if the schema itself is invalid.
+func Validate(schema map[string]interface{}, input map[string]interface{}) ([]FieldError, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	value, err := jsonValue(input)
+	if err != nil {
+		return nil, fmt.Errorf("input is not JSON: %w", err)
+	}
+
+	var invalid *jsonschema.ValidationError
+	if err := compiled.Validate(value); err == nil {
+		return nil, nil
+	} else if !errors.As(err, &invalid) {
+		return nil, err
+	}
+	problems := fieldErrors(invalid, nil)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Field < problems[j].Field })
+	return problems, nil
+}
+
+// compileSchema compiles a schema built in Go or decoded from JSON, caching the result
+func compileSchema(schema map[string]interface{}) (*jsonschema.Schema, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	if compiled, ok := compiledSchemas.Load(string(data)); ok {
+		return compiled.(*jsonschema.Schema), nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiledSchemas.Store(string(data), compiled)
+	return compiled, nil
+}
+
+// jsonValue converts input to the values a JSON decoder produces, which is what the
+// validator expects; Go callers may pass ints, []string and the like
+func jsonValue(input map[string]interface{}) (interface{}, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+// fieldErrors flattens a validation error into the failures at its leaves, reporting
+// missing and unexpected properties against the properties themselves
+func fieldErrors(err *jsonschema.ValidationError, problems []FieldError) []FieldError {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			problems = fieldErrors(cause, problems)
+		}
+		return problems
+	}
+
+	field := jsonPointer(err.InstanceLocation)
+	switch k := err.ErrorKind.(type) {
+	case *kind.Required:
+		for _, name := range k.Missing {
+			problems = append(problems, FieldError{Field: field + "/" + escapePointer(name), Message: "is required"})
+		}
+	case *kind.AdditionalProperties:
+		for _, name := range k.Properties {
+			problems = append(problems, FieldError{Field: field + "/" + escapePointer(name), Message: "is not allowed"})
+		}
+	case *kind.Type:
+		problems = append(problems, FieldError{Field: field, Message: "must be of type " + strings.Join(k.Want, " or ")})
+	default:
+		problems = append(problems, FieldError{Field: field, Message: err.ErrorKind.LocalizedString(messages)})
+	}
+	return problems
+}
+
+// jsonPointer formats an instance location as a JSON Pointer
+func jsonPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/" + escapePointer(token))
+	}
+	return b.String()
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// withDefaults returns a copy of input with the schema's defaults for missing properties
+func withDefaults(schema map[string]interface{}, input map[string]interface{}) map[string]interface{} {
+	filled := make(map[string]interface{}, len(input))
+	for name, value := range input {
+		filled[name] = value
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, p := range properties {
+		property, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if def, ok := property["default"]; ok {
+			if _, set := filled[name]; !set {
+				filled[name] = def
+			}
+		}
+	}
+	return filled
+}
+
+// intInput reads an integer input, which JSON decodes as a float64
+func intInput(input map[string]interface{}, name string, fallback int) int {
+	switch v := input[name].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return fallback
+}
+
+// stringInput reads a string input
+func stringInput(input map[string]interface{}, name string) string {
+	s, _ := input[name].(string)
+	return s
+}
@@ -0,0 +1,125 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// DefaultAlertThresholds are the percentages of a budget alerted on by default
+var DefaultAlertThresholds = []float64{50, 80, 100}
+
+// Alert reports that a budget's spend crossed an alert threshold
+type Alert struct {
+	// Level is LevelTenant or LevelUser; ID is the tenant or user ID
+	Level            string    `json:"level"`
+	ID               string    `json:"id"`
+	ThresholdPercent float64   `json:"threshold_percent"`
+	LimitUSD         float64   `json:"limit_usd"`
+	SpendUSD         float64   `json:"spend_usd"`
+	TriggeredAt      time.Time `json:"triggered_at"`
+}
+
+// AlertNotifier delivers budget alerts. It is called with the budget manager locked, so
+// it must hand the alert off rather than deliver it, and must not call the manager back.
+type AlertNotifier interface {
+	NotifyBudgetAlert(ctx context.Context, alert Alert) error
+}
+
+// AlertState reports whether a budget crossed one alert threshold in the current period
+type AlertState struct {
+	ThresholdPercent float64    `json:"threshold_percent"`
+	Triggered        bool       `json:"triggered"`
+	TriggeredAt      *time.Time `json:"triggered_at,omitempty"`
+}
+
+// SetAlerts notifies notifier the first time a user's or tenant's spend crosses each of
+// the thresholds, in percent of its limit, within a budget period. A user's thresholds are
+// re-armed when its budget is set or reset; a tenant's when a new limit puts its spend
+// back under them. Set alerts before Recover, so thresholds crossed before a restart are
+// not alerted on again.
+func (bm *BudgetManager) SetAlerts(thresholds []float64, notifier AlertNotifier) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	bm.thresholds = nil
+	for _, threshold := range thresholds {
+		if threshold > 0 && !slices.Contains(bm.thresholds, threshold) {
+			bm.thresholds = append(bm.thresholds, threshold)
+		}
+	}
+	slices.Sort(bm.thresholds)
+	bm.notifier = notifier
+}
+
+// GetAlerts returns the state of a user's alert thresholds
+func (bm *BudgetManager) GetAlerts(ctx context.Context, userID string) ([]AlertState, error) {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+
+	budget, exists := bm.budgets[userID]
+	if !exists {
+		return nil, fmt.Errorf("budget for user %s not found", userID)
+	}
+	states := make([]AlertState, 0, len(bm.thresholds))
+	for _, threshold := range bm.thresholds {
+		state := AlertState{ThresholdPercent: threshold}
+		if at, ok := budget.alerted[threshold]; ok {
+			state.Triggered = true
+			state.TriggeredAt = &at
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// crossed marks the thresholds a budget's spend reached for the first time in its period
+// and returns their alerts; the caller holds bm.mu
+func (bm *BudgetManager) crossed(level, id string, budget *Budget, at time.Time) []Alert {
+	if budget.MonthlyLimitUSD <= 0 {
+		return nil
+	}
+	var alerts []Alert
+	for _, threshold := range bm.thresholds {
+		if _, done := budget.alerted[threshold]; done || budget.PercentUsed() < threshold {
+			continue
+		}
+		if budget.alerted == nil {
+			budget.alerted = make(map[float64]time.Time)
+		}
+		budget.alerted[threshold] = at
+		alerts = append(alerts, Alert{
+			Level:            level,
+			ID:               id,
+			ThresholdPercent: threshold,
+			LimitUSD:         budget.MonthlyLimitUSD,
+			SpendUSD:         budget.CurrentSpendUSD,
+			TriggeredAt:      at,
+		})
+	}
+	return alerts
+}
+
+// rearm forgets the alerts of thresholds the budget's spend is back under
+func (b *Budget) rearm() {
+	for threshold := range b.alerted {
+		if b.PercentUsed() < threshold {
+			delete(b.alerted, threshold)
+		}
+	}
+}
+
+// notify hands alerts to the notifier; the caller holds bm.mu
+func (bm *BudgetManager) notify(ctx context.Context, alerts []Alert) {
+	if bm.notifier == nil {
+		return
+	}
+	for _, alert := range alerts {
+		if err := bm.notifier.NotifyBudgetAlert(ctx, alert); err != nil {
+			slog.WarnContext(ctx, "Failed to send budget alert", "level", alert.Level, "id", alert.ID,
+				"threshold_percent", alert.ThresholdPercent, "error", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package cost
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records the alerts it is told about
+type recordingNotifier struct {
+	alerts []Alert
+}
+
+func (n *recordingNotifier) NotifyBudgetAlert(ctx context.Context, alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+// thresholds returns the levels and thresholds alerted on, in order
+func (n *recordingNotifier) thresholds() []string {
+	var got []string
+	for _, alert := range n.alerts {
+		got = append(got, alert.Level+":"+alert.ID+"@"+strconv.FormatFloat(alert.ThresholdPercent, 'f', -1, 64))
+	}
+	n.alerts = nil
+	return got
+}
+
+func TestBudgetManager_AlertsOncePerThreshold(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	manager := NewBudgetManager()
+	manager.SetAlerts([]float64{100, 50, 80, 50, -1}, notifier)
+	require.NoError(t, manager.SetBudget(ctx, "alice", 10))
+
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-1", 4, 0))
+	assert.Empty(t, notifier.thresholds())
+
+	// One charge may cross several thresholds
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-2", 4.5, 0))
+	assert.Equal(t, []string{"user:alice@50", "user:alice@80"}, notifier.thresholds())
+
+	// Further spend above a threshold is not alerted on again, even after a refund
+	require.NoError(t, manager.Refund(ctx, "alice", "task-2", 4.5))
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-3", 5, 0))
+	assert.Empty(t, notifier.thresholds())
+
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-4", 1, 0))
+	assert.Equal(t, []string{"user:alice@100"}, notifier.thresholds())
+
+	states, err := manager.GetAlerts(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, states, 3)
+	for _, state := range states {
+		assert.True(t, state.Triggered, state.ThresholdPercent)
+		assert.NotNil(t, state.TriggeredAt)
+	}
+
+	// A new period re-arms the thresholds
+	require.NoError(t, manager.ResetBudget(ctx, "alice"))
+	states, err = manager.GetAlerts(ctx, "alice")
+	require.NoError(t, err)
+	assert.False(t, states[0].Triggered)
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-5", 6, 0))
+	assert.Equal(t, []string{"user:alice@50"}, notifier.thresholds())
+
+	_, err = manager.GetAlerts(ctx, "nobody")
+	assert.Error(t, err)
+}
+
+func TestBudgetManager_TenantAlerts(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	manager := NewBudgetManager()
+	manager.SetAlerts(DefaultAlertThresholds, notifier)
+	require.NoError(t, manager.SetTenantBudget(ctx, "acme", 10))
+	require.NoError(t, manager.SetUserBudget(ctx, "acme", "alice", 100))
+
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-1", 6, 0))
+	assert.Equal(t, []string{"tenant:acme@50"}, notifier.thresholds())
+
+	// Lowering the limit crosses thresholds without a charge
+	require.NoError(t, manager.SetTenantBudget(ctx, "acme", 7))
+	assert.Equal(t, []string{"tenant:acme@80"}, notifier.thresholds())
+
+	// Raising it re-arms the thresholds the spend is back under
+	require.NoError(t, manager.SetTenantBudget(ctx, "acme", 100))
+	require.NoError(t, manager.SetTenantBudget(ctx, "acme", 10))
+	assert.Equal(t, []string{"tenant:acme@50"}, notifier.thresholds())
+}
+
+func TestRecover_DoesNotRepeatAlerts(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "usage.journal")
+	taskExists := func(ctx context.Context, taskID string) (bool, error) { return true, nil }
+
+	journal := openTestJournal(t, path)
+	notifier := &recordingNotifier{}
+	budgets := NewBudgetManager()
+	budgets.SetAlerts(DefaultAlertThresholds, notifier)
+	_, err := Recover(ctx, journal, budgets, NewMemoryTracker(), taskExists)
+	require.NoError(t, err)
+	require.NoError(t, budgets.SetBudget(ctx, "alice", 10))
+	require.NoError(t, budgets.ChargeTask(ctx, "alice", "task-1", 6, 0))
+	assert.Equal(t, []string{"user:alice@50"}, notifier.thresholds())
+	require.NoError(t, journal.Close())
+
+	journal = openTestJournal(t, path)
+	budgets = NewBudgetManager()
+	budgets.SetAlerts(DefaultAlertThresholds, notifier)
+	_, err = Recover(ctx, journal, budgets, NewMemoryTracker(), taskExists)
+	require.NoError(t, err)
+	assert.Empty(t, notifier.thresholds())
+
+	require.NoError(t, budgets.ChargeTask(ctx, "alice", "task-2", 3, 0))
+	assert.Equal(t, []string{"user:alice@80"}, notifier.thresholds())
+}
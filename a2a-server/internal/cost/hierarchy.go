@@ -0,0 +1,33 @@
+package cost
+
+import "fmt"
+
+// Budget levels, from the broadest
+const (
+	LevelTenant = "tenant"
+	LevelUser   = "user"
+	LevelTask   = "task"
+)
+
+// BudgetExceededError reports the budget level a cost would exceed
+type BudgetExceededError struct {
+	// Level is LevelTenant, LevelUser or LevelTask; ID is the tenant, user or task ID
+	Level    string  `json:"level"`
+	ID       string  `json:"id"`
+	LimitUSD float64 `json:"limit_usd"`
+	SpendUSD float64 `json:"spend_usd"`
+	CostUSD  float64 `json:"cost_usd"`
+}
+
+func (e *BudgetExceededError) Error() string {
+	if e.Level == LevelTask {
+		return fmt.Sprintf("task cost cap exceeded: $%.4f is over the $%.4f cap of task %s", e.CostUSD, e.LimitUSD, e.ID)
+	}
+	return fmt.Sprintf("%s budget exceeded: $%.4f more would bring %s %s to $%.4f of $%.4f",
+		e.Level, e.CostUSD, e.Level, e.ID, e.SpendUSD+e.CostUSD, e.LimitUSD)
+}
+
+// exceeded reports that costUSD does not fit in a budget
+func exceeded(level, id string, budget *Budget, costUSD float64) *BudgetExceededError {
+	return &BudgetExceededError{Level: level, ID: id, LimitUSD: budget.MonthlyLimitUSD, SpendUSD: budget.CurrentSpendUSD, CostUSD: costUSD}
+}
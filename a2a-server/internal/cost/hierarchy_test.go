@@ -0,0 +1,91 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetManager_ChargeTask_Levels(t *testing.T) {
+	ctx := context.Background()
+	manager := NewBudgetManager()
+	require.NoError(t, manager.SetTenantBudget(ctx, "acme", 1.0))
+	require.NoError(t, manager.SetUserBudget(ctx, "acme", "alice", 0.8))
+	require.NoError(t, manager.SetUserBudget(ctx, "acme", "bob", 0.8))
+
+	levelOf := func(err error) string {
+		var exceeded *BudgetExceededError
+		if errors.As(err, &exceeded) {
+			return exceeded.Level
+		}
+		return ""
+	}
+
+	// The task cap is checked after the budgets
+	err := manager.ChargeTask(ctx, "alice", "task-1", 0.3, 0.2)
+	assert.Equal(t, LevelTask, levelOf(err))
+	assert.Contains(t, err.Error(), "task-1")
+
+	require.NoError(t, manager.ChargeTask(ctx, "alice", "task-2", 0.6, 1.0))
+	assert.Equal(t, LevelUser, levelOf(manager.ChargeTask(ctx, "alice", "task-3", 0.3, 0)))
+
+	// Alice's spend counts against the tenant
+	err = manager.ChargeTask(ctx, "bob", "task-4", 0.5, 0)
+	assert.Equal(t, LevelTenant, levelOf(err))
+	var exceeded *BudgetExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, "acme", exceeded.ID)
+	assert.InDelta(t, 0.6, exceeded.SpendUSD, 1e-9)
+	assert.Equal(t, 1.0, exceeded.LimitUSD)
+
+	// A refund frees the tenant's budget too
+	require.NoError(t, manager.Refund(ctx, "alice", "task-2", 0.6))
+	require.NoError(t, manager.ChargeTask(ctx, "bob", "task-4", 0.5, 0))
+	tenant, err := manager.GetTenantBudget(ctx, "acme")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, tenant.CurrentSpendUSD, 1e-9)
+
+	// Lowering the tenant's limit keeps its spend
+	require.NoError(t, manager.SetTenantBudget(ctx, "acme", 0.4))
+	assert.Equal(t, LevelTenant, levelOf(manager.Check(ctx, "bob", 0)))
+	assert.Equal(t, LevelTenant, levelOf(manager.Check(ctx, "alice", 0)), "every user of the tenant is held to it")
+
+	// A user's budget keeps its tenant when only the limit changes
+	require.NoError(t, manager.SetBudget(ctx, "bob", 2))
+	budget, err := manager.GetBudget(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", budget.TenantID)
+}
+
+func TestRecover_TenantBudgets(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "usage.journal")
+	taskExists := func(ctx context.Context, taskID string) (bool, error) { return true, nil }
+
+	journal := openTestJournal(t, path)
+	budgets := NewBudgetManager()
+	_, err := Recover(ctx, journal, budgets, NewMemoryTracker(), taskExists)
+	require.NoError(t, err)
+	require.NoError(t, budgets.SetTenantBudget(ctx, "acme", 1))
+	require.NoError(t, budgets.SetUserBudget(ctx, "acme", "alice", 1))
+	require.NoError(t, budgets.ChargeTask(ctx, "alice", "task-1", 0.25, 0))
+	require.NoError(t, budgets.SetTenantBudget(ctx, "acme", 2))
+	require.NoError(t, journal.Close())
+
+	journal = openTestJournal(t, path)
+	budgets = NewBudgetManager()
+	_, err = Recover(ctx, journal, budgets, NewMemoryTracker(), taskExists)
+	require.NoError(t, err)
+
+	tenant, err := budgets.GetTenantBudget(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, tenant.MonthlyLimitUSD)
+	assert.InDelta(t, 0.25, tenant.CurrentSpendUSD, 1e-9)
+	user, err := budgets.GetBudget(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", user.TenantID)
+}
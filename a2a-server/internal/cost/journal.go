@@ -0,0 +1,262 @@
+package cost
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Journal entry types
+const (
+	EntryBudget       = "budget"        // a budget was set, resetting its spend
+	EntryReset        = "reset"         // a budget's spend was reset
+	EntryCharge       = "charge"        // a task was charged against a budget
+	EntryRefund       = "refund"        // a charge was returned to a budget
+	EntryUsage        = "usage"         // a usage record was added
+	EntryTenantBudget = "tenant_budget" // a tenant's budget limit was set, keeping its spend
+	EntryCollected    = "collected"     // a finished task was garbage collected, keeping its charge
+	EntryErased       = "erased"        // a user's usage records were erased or handed to Pseudonym
+)
+
+// JournalEntry is one change to budgets or usage records
+type JournalEntry struct {
+	Seq       int64     `json:"seq"`
+	Type      string    `json:"type"`
+	UserID    string    `json:"user_id,omitempty"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	TaskID    string    `json:"task_id,omitempty"`
+	AmountUSD float64   `json:"amount_usd,omitempty"`
+	LimitUSD  float64   `json:"limit_usd,omitempty"`
+	ResetAt   time.Time `json:"reset_at,omitempty"`
+	Usage     *Usage    `json:"usage,omitempty"`
+	Pseudonym string    `json:"pseudonym,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Journal is a write-ahead log of budget and usage changes. BudgetManager and Tracker
+// append each change durably before applying it, so the in-memory state can be rebuilt
+// and reconciled with Recover after a crash.
+type Journal interface {
+	// Append durably records an entry, assigning its sequence number
+	Append(ctx context.Context, entry JournalEntry) error
+	// Replay calls fn with every entry in the order they were appended
+	Replay(ctx context.Context, fn func(JournalEntry) error) error
+}
+
+// FileJournal is a Journal kept in an append-only file of JSON lines, fsynced after every
+// entry
+type FileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+	seq  int64
+}
+
+var _ Journal = (*FileJournal)(nil)
+
+// OpenFileJournal opens or creates the journal at path. A partial last line, left by a
+// crash during a write, is truncated: its change was never applied.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage journal: %w", err)
+	}
+
+	var seq, valid int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read usage journal: %w", err)
+		}
+		var entry JournalEntry
+		if json.Unmarshal(bytes.TrimSpace(line), &entry) != nil {
+			break
+		}
+		seq = entry.Seq
+		valid += int64(len(line))
+	}
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate usage journal: %w", err)
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek usage journal: %w", err)
+	}
+	return &FileJournal{file: file, seq: seq}, nil
+}
+
+// Append implements Journal
+func (j *FileJournal) Append(ctx context.Context, entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry.Seq = j.seq + 1
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync usage journal: %w", err)
+	}
+	j.seq = entry.Seq
+	return nil
+}
+
+// Replay implements Journal
+func (j *FileJournal) Replay(ctx context.Context, fn func(JournalEntry) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.Open(j.file.Name())
+	if err != nil {
+		return fmt.Errorf("failed to open usage journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("corrupt usage journal entry: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Close closes the journal file
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// TaskLookup reports whether a task still exists, i.e. whether it can still run
+type TaskLookup func(ctx context.Context, taskID string) (bool, error)
+
+// RecoveryReport summarizes what Recover replayed and reconciled
+type RecoveryReport struct {
+	Entries int `json:"entries"`
+	// Refunds return charges of tasks lost before they recorded usage
+	Refunds   int     `json:"refunds"`
+	RefundUSD float64 `json:"refund_usd"`
+	// Charges bill usage recorded for tasks whose charge is missing
+	Charges   int     `json:"charges"`
+	ChargeUSD float64 `json:"charge_usd"`
+}
+
+// Recover rebuilds budgets and usage records from the journal, attaches the journal to
+// both so later changes are logged, and reconciles the two:
+//   - a charge whose task recorded no usage and no longer exists, e.g. a task lost with
+//     the memory store, is refunded since the task will never run
+//   - usage recorded for a task that has no charge is charged to the user's budget
+//
+// Tasks collected once they finished are left as they are.
+//
+// budgets and tracker must be empty and not yet in use, except for a tracker that keeps
+// usage itself, such as a PostgresTracker: only a MemoryTracker is given the journaled usage.
+func Recover(ctx context.Context, journal Journal, budgets *BudgetManager, tracker Tracker, lookup TaskLookup) (*RecoveryReport, error) {
+	memory, _ := tracker.(*MemoryTracker)
+	report := &RecoveryReport{}
+	charges := make(map[string]JournalEntry)
+	usage := make(map[string][]Usage)
+	collected := make(map[string]bool)
+
+	err := journal.Replay(ctx, func(entry JournalEntry) error {
+		report.Entries++
+		budgets.apply(entry)
+		switch entry.Type {
+		case EntryCharge:
+			if entry.TaskID != "" {
+				charges[entry.TaskID] = entry
+			}
+		case EntryRefund:
+			delete(charges, entry.TaskID)
+		case EntryCollected:
+			collected[entry.TaskID] = true
+		case EntryUsage:
+			if entry.Usage != nil {
+				if memory != nil {
+					memory.usage = append(memory.usage, *entry.Usage)
+				}
+				if entry.Usage.TaskID != "" {
+					usage[entry.Usage.TaskID] = append(usage[entry.Usage.TaskID], *entry.Usage)
+				}
+			}
+		case EntryErased:
+			// Reconciling budgets still sees the erased usage: it is kept per task only
+			// until recovery finishes, and budgets are not erased
+			if memory != nil {
+				memory.usage, _ = eraseUsage(memory.usage, entry.UserID, entry.Pseudonym)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	budgets.SetJournal(journal)
+	tracker.SetJournal(journal)
+
+	for taskID, charge := range charges {
+		if len(usage[taskID]) > 0 || collected[taskID] {
+			continue
+		}
+		exists, err := lookup(ctx, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up task %s: %w", taskID, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := budgets.GetBudget(ctx, charge.UserID); err != nil {
+			continue
+		}
+		if err := budgets.Refund(ctx, charge.UserID, taskID, charge.AmountUSD); err != nil {
+			return nil, err
+		}
+		report.Refunds++
+		report.RefundUSD += charge.AmountUSD
+	}
+
+	for taskID, records := range usage {
+		if _, charged := charges[taskID]; charged || collected[taskID] {
+			continue
+		}
+		var total float64
+		for _, u := range records {
+			total += u.CostUSD
+		}
+		userID := records[0].UserID
+		if _, err := budgets.GetBudget(ctx, userID); err != nil || total == 0 {
+			continue
+		}
+		if err := budgets.forceCharge(ctx, userID, taskID, total); err != nil {
+			return nil, err
+		}
+		report.Charges++
+		report.ChargeUSD += total
+	}
+	return report, nil
+}
@@ -0,0 +1,145 @@
+package cost
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestJournal(t *testing.T, path string) *FileJournal {
+	journal, err := OpenFileJournal(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { journal.Close() })
+	return journal
+}
+
+func TestFileJournal_AppendReplayAndTornWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "usage.journal")
+	journal := openTestJournal(t, path)
+	require.NoError(t, journal.Append(ctx, JournalEntry{Type: EntryBudget, UserID: "user-1", LimitUSD: 10}))
+	require.NoError(t, journal.Append(ctx, JournalEntry{Type: EntryCharge, UserID: "user-1", TaskID: "task-1", AmountUSD: 0.01}))
+	require.NoError(t, journal.Close())
+
+	// A crash in the middle of a write leaves a partial line behind
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":3,"type":"cha`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	journal = openTestJournal(t, path)
+	require.NoError(t, journal.Append(ctx, JournalEntry{Type: EntryRefund, UserID: "user-1", TaskID: "task-1", AmountUSD: 0.01}))
+
+	var entries []JournalEntry
+	require.NoError(t, journal.Replay(ctx, func(entry JournalEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	require.Len(t, entries, 3)
+	assert.Equal(t, []int64{1, 2, 3}, []int64{entries[0].Seq, entries[1].Seq, entries[2].Seq})
+	assert.Equal(t, EntryRefund, entries[2].Type)
+}
+
+func TestRecover_ReconcilesChargesAndUsage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "usage.journal")
+
+	// Before the crash
+	journal := openTestJournal(t, path)
+	budgets := NewBudgetManager()
+	tracker := NewMemoryTracker()
+	_, err := Recover(ctx, journal, budgets, tracker, func(ctx context.Context, taskID string) (bool, error) {
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, budgets.SetBudget(ctx, "user-1", 1))
+	for _, taskID := range []string{"completed", "lost", "pending", "collected"} {
+		allowed, err := budgets.Charge(ctx, "user-1", taskID, 0.1)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+	require.NoError(t, tracker.RecordUsage(ctx, Usage{UserID: "user-1", TaskID: "completed", CostUSD: 0.05}))
+	// Usage journaled for a task whose charge never made it to the journal
+	require.NoError(t, tracker.RecordUsage(ctx, Usage{UserID: "user-1", TaskID: "uncharged", CostUSD: 0.2}))
+	// A finished task without usage was garbage collected; its charge stands
+	require.NoError(t, journal.Append(ctx, JournalEntry{Type: EntryCollected, UserID: "user-1", TaskID: "collected"}))
+	require.NoError(t, journal.Close())
+
+	// After the restart the "pending" task is still in the task store, "lost" and "collected" are gone
+	stillQueued := func(ctx context.Context, taskID string) (bool, error) {
+		return taskID == "pending", nil
+	}
+	journal = openTestJournal(t, path)
+	budgets = NewBudgetManager()
+	tracker = NewMemoryTracker()
+	report, err := Recover(ctx, journal, budgets, tracker, stillQueued)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Refunds)
+	assert.InDelta(t, 0.1, report.RefundUSD, 1e-9)
+	assert.Equal(t, 1, report.Charges)
+	assert.InDelta(t, 0.2, report.ChargeUSD, 1e-9)
+
+	budget, err := budgets.GetBudget(ctx, "user-1")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, budget.CurrentSpendUSD, 1e-9, "completed + pending + collected + uncharged usage")
+	total, err := tracker.GetTotalCost(ctx, "user-1", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.25, total, 1e-9)
+
+	// Reconciliation is journaled, so recovering again changes nothing
+	require.NoError(t, journal.Close())
+	journal = openTestJournal(t, path)
+	budgets = NewBudgetManager()
+	report, err = Recover(ctx, journal, budgets, NewMemoryTracker(), stillQueued)
+	require.NoError(t, err)
+	assert.Zero(t, report.Refunds+report.Charges)
+	budget, err = budgets.GetBudget(ctx, "user-1")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, budget.CurrentSpendUSD, 1e-9)
+}
+
+func TestRecover_KeepsErasures(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "usage.journal")
+	lookup := func(ctx context.Context, taskID string) (bool, error) { return true, nil }
+	window := func(tracker *MemoryTracker, userID string) []Usage {
+		usage, err := tracker.GetUsage(ctx, userID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		return usage
+	}
+
+	journal := openTestJournal(t, path)
+	tracker := NewMemoryTracker()
+	_, err := Recover(ctx, journal, NewBudgetManager(), tracker, lookup)
+	require.NoError(t, err)
+	for _, userID := range []string{"user-1", "user-1", "user-2", "user-3"} {
+		require.NoError(t, tracker.RecordUsage(ctx, Usage{UserID: userID, TaskID: "task-" + userID, CostUSD: 0.1}))
+	}
+
+	erased, err := tracker.EraseUsage(ctx, "user-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, erased)
+	erased, err = tracker.EraseUsage(ctx, "user-2", "erased-2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, erased)
+	assert.Empty(t, window(tracker, "user-1"))
+	assert.Empty(t, window(tracker, "user-2"))
+	assert.Len(t, window(tracker, "erased-2"), 1)
+	require.NoError(t, journal.Close())
+
+	// Recovering replays the erasures rather than bringing the records back
+	journal = openTestJournal(t, path)
+	tracker = NewMemoryTracker()
+	_, err = Recover(ctx, journal, NewBudgetManager(), tracker, lookup)
+	require.NoError(t, err)
+	assert.Empty(t, window(tracker, "user-1"))
+	assert.Empty(t, window(tracker, "user-2"))
+	assert.Len(t, window(tracker, "erased-2"), 1)
+	assert.Len(t, window(tracker, "user-3"), 1)
+}
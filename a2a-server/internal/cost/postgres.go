@@ -0,0 +1,179 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresTracker keeps usage records in the Postgres usage_records table (see
+// scripts/apply-a2a-usage-records.sql), so they survive restarts and can be aggregated
+// for billing without loading them
+type PostgresTracker struct {
+	pool *pgxpool.Pool
+
+	mu sync.RWMutex
+	// journal, when set, durably logs each usage record before it is inserted
+	journal Journal
+}
+
+var _ Tracker = (*PostgresTracker)(nil)
+
+// usageColumns lists the usage_records columns in the order scanUsage reads them
+const usageColumns = `user_id, task_id, capability, model, prompt_tokens, completion_tokens, total_tokens, cost_usd::float8, price_version, recorded_at`
+
+// NewPostgresTracker returns a cost tracker backed by the database pool connects to
+func NewPostgresTracker(pool *pgxpool.Pool) *PostgresTracker {
+	return &PostgresTracker{pool: pool}
+}
+
+// SetJournal logs every usage record to journal before it is inserted; see Recover
+func (t *PostgresTracker) SetJournal(journal Journal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.journal = journal
+}
+
+// RecordUsage records token usage and cost
+func (t *PostgresTracker) RecordUsage(ctx context.Context, usage Usage) error {
+	if usage.Timestamp.IsZero() {
+		usage.Timestamp = time.Now()
+	}
+
+	t.mu.RLock()
+	journal := t.journal
+	t.mu.RUnlock()
+	if journal != nil {
+		if err := journal.Append(ctx, JournalEntry{Type: EntryUsage, UserID: usage.UserID, TaskID: usage.TaskID, Usage: &usage}); err != nil {
+			return err
+		}
+	}
+
+	_, err := t.pool.Exec(ctx, `INSERT INTO usage_records
+		(user_id, task_id, capability, model, prompt_tokens, completion_tokens, total_tokens, cost_usd, price_version, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		usage.UserID, usage.TaskID, usage.Capability, usage.Model, usage.PromptTokens,
+		usage.CompletionTokens, usage.TotalTokens, usage.CostUSD, usage.PriceVersion, usage.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to insert usage record: %w", err)
+	}
+	return nil
+}
+
+// GetUsage retrieves usage records for a user within a time range
+func (t *PostgresTracker) GetUsage(ctx context.Context, userID string, start, end time.Time) ([]Usage, error) {
+	rows, err := t.pool.Query(ctx, `SELECT `+usageColumns+` FROM usage_records
+		WHERE user_id = $1 AND recorded_at BETWEEN $2 AND $3
+		ORDER BY recorded_at, id`, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage records: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Usage
+	for rows.Next() {
+		usage, err := scanUsage(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, usage)
+	}
+	return result, rows.Err()
+}
+
+// GetTotalCost calculates total cost for a user within a time range
+func (t *PostgresTracker) GetTotalCost(ctx context.Context, userID string, start, end time.Time) (float64, error) {
+	var total float64
+	err := t.pool.QueryRow(ctx, `SELECT COALESCE(SUM(cost_usd), 0)::float8 FROM usage_records
+		WHERE user_id = $1 AND recorded_at BETWEEN $2 AND $3`, userID, start, end).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum usage cost: %w", err)
+	}
+	return total, nil
+}
+
+// GetTotalTokens calculates total tokens for a user within a time range
+func (t *PostgresTracker) GetTotalTokens(ctx context.Context, userID string, start, end time.Time) (int, error) {
+	var total int
+	err := t.pool.QueryRow(ctx, `SELECT COALESCE(SUM(total_tokens), 0) FROM usage_records
+		WHERE user_id = $1 AND recorded_at BETWEEN $2 AND $3`, userID, start, end).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum usage tokens: %w", err)
+	}
+	return total, nil
+}
+
+// EraseUsage deletes or pseudonymizes a user's rows of usage_records, journaling the
+// erasure like RecordUsage journals the records
+func (t *PostgresTracker) EraseUsage(ctx context.Context, userID, pseudonym string) (int, error) {
+	t.mu.RLock()
+	journal := t.journal
+	t.mu.RUnlock()
+	if journal != nil {
+		if err := journal.Append(ctx, JournalEntry{Type: EntryErased, UserID: userID, Pseudonym: pseudonym}); err != nil {
+			return 0, err
+		}
+	}
+
+	query, args := `DELETE FROM usage_records WHERE user_id = $1`, []interface{}{userID}
+	if pseudonym != "" {
+		query, args = `UPDATE usage_records SET user_id = $2 WHERE user_id = $1`, []interface{}{userID, pseudonym}
+	}
+	tag, err := t.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to erase usage records: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// groupingKeys maps each grouping to the SQL expression of its bucket key
+var groupingKeys = map[Grouping]string{
+	ByDay:        `to_char(recorded_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')`,
+	ByModel:      `model`,
+	ByCapability: `capability`,
+}
+
+// CostBy aggregates the usage within a time range by day, model or capability
+func (t *PostgresTracker) CostBy(ctx context.Context, userID string, start, end time.Time, grouping Grouping) ([]CostBucket, error) {
+	key, ok := groupingKeys[grouping]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q", grouping)
+	}
+
+	rows, err := t.pool.Query(ctx, `SELECT `+key+` AS bucket, COUNT(*),
+			COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+			COALESCE(SUM(total_tokens), 0), COALESCE(SUM(cost_usd), 0)::float8
+		FROM usage_records
+		WHERE recorded_at BETWEEN $1 AND $2 AND ($3 = '' OR user_id = $3)
+		GROUP BY bucket ORDER BY bucket`, start, end, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage records: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]CostBucket, 0)
+	for rows.Next() {
+		var bucket CostBucket
+		if err := rows.Scan(&bucket.Key, &bucket.Records, &bucket.PromptTokens, &bucket.CompletionTokens,
+			&bucket.TotalTokens, &bucket.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan usage aggregate: %w", err)
+		}
+		result = append(result, bucket)
+	}
+	return result, rows.Err()
+}
+
+// scanUsage reads a usage record selected with usageColumns
+func scanUsage(row pgx.Row) (Usage, error) {
+	var usage Usage
+	err := row.Scan(&usage.UserID, &usage.TaskID, &usage.Capability, &usage.Model, &usage.PromptTokens,
+		&usage.CompletionTokens, &usage.TotalTokens, &usage.CostUSD, &usage.PriceVersion, &usage.Timestamp)
+	if err != nil {
+		return Usage{}, fmt.Errorf("failed to scan usage record: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package cost
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests for the Postgres cost tracker; the usage_records table comes from init-db.sql
+// Run with: go test -tags=integration -v ./internal/cost/
+
+func setupPostgresTracker(t *testing.T) *PostgresTracker {
+	t.Helper()
+	port, _ := strconv.Atoi(getEnvOrDefault("DB_PORT", "5432"))
+	pool, err := tasks.Connect(context.Background(), tasks.PostgresConfig{
+		Host:     getEnvOrDefault("DB_HOST", "localhost"),
+		Port:     port,
+		User:     getEnvOrDefault("DB_USER", "app_user"),
+		Password: getEnvOrDefault("DB_PASSWORD", "mcp_password"),
+		DBName:   getEnvOrDefault("DB_NAME", "mcp_db"),
+		SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
+		MaxConns: 5,
+	})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return NewPostgresTracker(pool)
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestPostgresTracker_RecordAndAggregate(t *testing.T) {
+	tracker := setupPostgresTracker(t)
+	ctx := context.Background()
+	userID := "pg-cost-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	t.Cleanup(func() { tracker.pool.Exec(ctx, `DELETE FROM usage_records WHERE user_id = $1`, userID) })
+
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	records := []Usage{
+		{UserID: userID, TaskID: "task-1", Capability: "search_papers", Model: "gpt-4", PromptTokens: 60, CompletionTokens: 40, TotalTokens: 100, CostUSD: 0.01, PriceVersion: "2025-03", Timestamp: day},
+		{UserID: userID, TaskID: "task-2", Capability: "summarize_document", Model: "gpt-4", TotalTokens: 50, CostUSD: 0.02, Timestamp: day.Add(time.Hour)},
+		{UserID: userID, TaskID: "task-3", Capability: "search_papers", Model: "claude-3-sonnet", TotalTokens: 10, CostUSD: 0.04, Timestamp: day.Add(24 * time.Hour)},
+	}
+	for _, usage := range records {
+		require.NoError(t, tracker.RecordUsage(ctx, usage))
+	}
+	start, end := day.Add(-time.Hour), day.Add(48*time.Hour)
+
+	usage, err := tracker.GetUsage(ctx, userID, start, end)
+	require.NoError(t, err)
+	require.Len(t, usage, 3)
+	assert.Equal(t, "task-1", usage[0].TaskID)
+	assert.Equal(t, "search_papers", usage[0].Capability)
+	assert.Equal(t, 60, usage[0].PromptTokens)
+	assert.Equal(t, "2025-03", usage[0].PriceVersion)
+	assert.True(t, day.Equal(usage[0].Timestamp))
+
+	total, err := tracker.GetTotalCost(ctx, userID, start, end)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.07, total, 1e-9)
+	tokens, err := tracker.GetTotalTokens(ctx, userID, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 160, tokens)
+
+	byDay, err := tracker.CostBy(ctx, userID, start, end, ByDay)
+	require.NoError(t, err)
+	require.Len(t, byDay, 2)
+	assert.Equal(t, "2025-03-10", byDay[0].Key)
+	assert.Equal(t, 2, byDay[0].Records)
+	assert.InDelta(t, 0.03, byDay[0].CostUSD, 1e-9)
+
+	byModel, err := tracker.CostBy(ctx, userID, start, end, ByModel)
+	require.NoError(t, err)
+	require.Len(t, byModel, 2)
+	assert.Equal(t, "claude-3-sonnet", byModel[0].Key)
+	assert.Equal(t, 150, byModel[1].TotalTokens)
+
+	byCapability, err := tracker.CostBy(ctx, userID, start, end, ByCapability)
+	require.NoError(t, err)
+	require.Len(t, byCapability, 2)
+	assert.Equal(t, "search_papers", byCapability[0].Key)
+	assert.InDelta(t, 0.05, byCapability[0].CostUSD, 1e-9)
+}
@@ -0,0 +1,264 @@
+package cost
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Token units model prices are quoted in
+const (
+	UnitPer1K = "per_1k"
+	UnitPer1M = "per_1m"
+)
+
+// ModelPrice is the price of a model's prompt and completion tokens
+type ModelPrice struct {
+	PromptUSD     float64 `json:"prompt_usd" yaml:"prompt_usd"`
+	CompletionUSD float64 `json:"completion_usd" yaml:"completion_usd"`
+	// Unit is the number of tokens the prices are for; empty means UnitPer1K
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+}
+
+// tokensPerUnit returns the number of tokens the price's unit stands for
+func (p ModelPrice) tokensPerUnit() float64 {
+	if p.Unit == UnitPer1M {
+		return 1_000_000
+	}
+	return 1000
+}
+
+// PriceTable prices the models usage is billed as. Version is recorded with every usage
+// record priced from the table, so a bill can be traced back to the prices it used.
+type PriceTable struct {
+	// Version identifies the table; when empty it is derived from the prices
+	Version string `json:"version" yaml:"version"`
+	// DefaultModel prices models missing from Models; empty prices them at zero
+	DefaultModel string                `json:"default_model,omitempty" yaml:"default_model,omitempty"`
+	Models       map[string]ModelPrice `json:"models" yaml:"models"`
+}
+
+// builtinPrices are the prices used until a table is loaded, per 1K tokens - based on
+// OpenAI and Anthropic pricing as of 2024
+var builtinPrices = PriceTable{
+	Version:      "builtin-2024",
+	DefaultModel: "gpt-3.5-turbo",
+	Models: map[string]ModelPrice{
+		"gpt-4":           {PromptUSD: 0.03, CompletionUSD: 0.06},
+		"gpt-4-turbo":     {PromptUSD: 0.01, CompletionUSD: 0.03},
+		"gpt-3.5-turbo":   {PromptUSD: 0.0015, CompletionUSD: 0.002},
+		"claude-3-opus":   {PromptUSD: 0.015, CompletionUSD: 0.075},
+		"claude-3-sonnet": {PromptUSD: 0.003, CompletionUSD: 0.015},
+	},
+}
+
+// DefaultPriceTable returns a copy of the built-in prices
+func DefaultPriceTable() PriceTable {
+	return builtinPrices.clone()
+}
+
+// clone copies the table so callers cannot change a registry's prices
+func (t PriceTable) clone() PriceTable {
+	models := make(map[string]ModelPrice, len(t.Models))
+	for name, price := range t.Models {
+		models[name] = price
+	}
+	t.Models = models
+	return t
+}
+
+// Validate reports prices that are negative or in an unknown unit and a default model
+// without a price
+func (t PriceTable) Validate() error {
+	var problems []string
+	names := make([]string, 0, len(t.Models))
+	for name := range t.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		price := t.Models[name]
+		if price.PromptUSD < 0 || price.CompletionUSD < 0 {
+			problems = append(problems, fmt.Sprintf("%s: prices must not be negative", name))
+		}
+		if price.Unit != "" && price.Unit != UnitPer1K && price.Unit != UnitPer1M {
+			problems = append(problems, fmt.Sprintf("%s: unit must be %s or %s, got %q", name, UnitPer1K, UnitPer1M, price.Unit))
+		}
+	}
+	if _, ok := t.Models[t.DefaultModel]; t.DefaultModel != "" && !ok {
+		problems = append(problems, fmt.Sprintf("default model %s has no price", t.DefaultModel))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid price table: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Cost prices a model's prompt and completion tokens
+func (t PriceTable) Cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := t.Models[model]
+	if !ok {
+		if price, ok = t.Models[t.DefaultModel]; !ok {
+			return 0
+		}
+	}
+	return (float64(promptTokens)*price.PromptUSD + float64(completionTokens)*price.CompletionUSD) / price.tokensPerUnit()
+}
+
+// contentVersion derives a version from the table's prices
+func (t PriceTable) contentVersion() string {
+	// Map keys are marshalled sorted, so equal tables hash equally
+	data, _ := json.Marshal(struct {
+		DefaultModel string                `json:"default_model"`
+		Models       map[string]ModelPrice `json:"models"`
+	}{t.DefaultModel, t.Models})
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// ParsePriceTable decodes a price table from YAML or JSON (which is valid YAML)
+func ParsePriceTable(data []byte) (PriceTable, error) {
+	var table PriceTable
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return PriceTable{}, fmt.Errorf("failed to parse price table: %w", err)
+	}
+	return table, nil
+}
+
+// LoadPriceTable reads a price table from a .yaml, .yml or .json file
+func LoadPriceTable(path string) (PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PriceTable{}, fmt.Errorf("failed to read price table: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var table PriceTable
+		if err := json.Unmarshal(data, &table); err != nil {
+			return PriceTable{}, fmt.Errorf("failed to parse price table %s: %w", path, err)
+		}
+		return table, nil
+	case ".yaml", ".yml":
+		return ParsePriceTable(data)
+	default:
+		return PriceTable{}, fmt.Errorf("price table %s must be .yaml, .yml or .json", path)
+	}
+}
+
+// PricingRegistry holds the price table usage is currently billed with. The table is
+// replaced as a whole, by an admin or when the file it was loaded from changes, so every
+// usage record is priced from exactly one version.
+type PricingRegistry struct {
+	mu    sync.RWMutex
+	table PriceTable
+	// loaded is the modification time of the file last loaded
+	loaded time.Time
+}
+
+// NewPricingRegistry prices usage with table, which must be valid
+func NewPricingRegistry(table PriceTable) *PricingRegistry {
+	r := &PricingRegistry{}
+	if err := r.Set(table); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Set validates table and bills usage with it from now on, deriving its version from
+// the prices when it has none
+func (r *PricingRegistry) Set(table PriceTable) error {
+	if err := table.Validate(); err != nil {
+		return err
+	}
+	table = table.clone()
+	if table.Version == "" {
+		table.Version = table.contentVersion()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.table = table
+	return nil
+}
+
+// Table returns a copy of the current price table
+func (r *PricingRegistry) Table() PriceTable {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.table.clone()
+}
+
+// Version returns the version of the current price table
+func (r *PricingRegistry) Version() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.table.Version
+}
+
+// Cost prices a model's tokens with the current table and returns the table's version
+func (r *PricingRegistry) Cost(model string, promptTokens, completionTokens int) (float64, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.table.Cost(model, promptTokens, completionTokens), r.table.Version
+}
+
+// Load replaces the prices with the table in path
+func (r *PricingRegistry) Load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read price table: %w", err)
+	}
+	r.mu.Lock()
+	r.loaded = info.ModTime()
+	r.mu.Unlock()
+
+	table, err := LoadPriceTable(path)
+	if err != nil {
+		return err
+	}
+	return r.Set(table)
+}
+
+// Watch reloads the prices from path every interval its modification time differs from
+// the file last loaded, until ctx is done. A file that fails to load keeps the current
+// prices until it changes again.
+func (r *PricingRegistry) Watch(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("Failed to stat price table", "path", path, "error", err)
+			}
+			continue
+		}
+		r.mu.RLock()
+		unchanged := info.ModTime().Equal(r.loaded)
+		r.mu.RUnlock()
+		if unchanged {
+			continue
+		}
+		if err := r.Load(path); err != nil {
+			slog.Error("Failed to reload price table; keeping current prices", "path", path, "error", err)
+			continue
+		}
+		slog.Info("Price table reloaded", "path", path, "version", r.Version())
+	}
+}
@@ -0,0 +1,124 @@
+package cost
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceTable_Units(t *testing.T) {
+	table := PriceTable{
+		DefaultModel: "small",
+		Models: map[string]ModelPrice{
+			"small": {PromptUSD: 0.5, CompletionUSD: 1.5, Unit: UnitPer1M},
+			"large": {PromptUSD: 0.03, CompletionUSD: 0.06},
+		},
+	}
+	require.NoError(t, table.Validate())
+
+	assert.InDelta(t, 0.06, table.Cost("large", 1000, 500), 1e-12)
+	assert.InDelta(t, 0.00125, table.Cost("small", 1000, 500), 1e-12)
+	assert.InDelta(t, 0.00125, table.Cost("unknown", 1000, 500), 1e-12, "priced as the default model")
+
+	table.DefaultModel = ""
+	assert.Zero(t, table.Cost("unknown", 1000, 500))
+}
+
+func TestPriceTable_Validate(t *testing.T) {
+	table := PriceTable{
+		DefaultModel: "missing",
+		Models: map[string]ModelPrice{
+			"a": {PromptUSD: -1},
+			"b": {PromptUSD: 1, Unit: "per_token"},
+		},
+	}
+	err := table.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a: prices must not be negative")
+	assert.Contains(t, err.Error(), `b: unit must be per_1k or per_1m, got "per_token"`)
+	assert.Contains(t, err.Error(), "default model missing has no price")
+}
+
+func TestPricingRegistry_Versions(t *testing.T) {
+	pricing := NewPricingRegistry(DefaultPriceTable())
+	cost, version := pricing.Cost("gpt-4", 1000, 500)
+	assert.Equal(t, CalculateCost("gpt-4", 1000, 500), cost)
+	assert.Equal(t, "builtin-2024", version)
+
+	// Tables without a version get one derived from their prices
+	table := PriceTable{Models: map[string]ModelPrice{"gpt-4": {PromptUSD: 10, CompletionUSD: 30, Unit: UnitPer1M}}}
+	require.NoError(t, pricing.Set(table))
+	derived := pricing.Version()
+	assert.Regexp(t, `^sha256:[0-9a-f]{12}$`, derived)
+	require.NoError(t, pricing.Set(table))
+	assert.Equal(t, derived, pricing.Version(), "equal prices, equal versions")
+
+	table.Models["gpt-4"] = ModelPrice{PromptUSD: 5, CompletionUSD: 15, Unit: UnitPer1M}
+	assert.Equal(t, 10.0, pricing.Table().Models["gpt-4"].PromptUSD, "the registry keeps its own copy")
+	require.NoError(t, pricing.Set(table))
+	assert.NotEqual(t, derived, pricing.Version())
+
+	// An invalid table keeps the current one
+	assert.Error(t, pricing.Set(PriceTable{Version: "bad", Models: map[string]ModelPrice{"x": {PromptUSD: -1}}}))
+	assert.NotEqual(t, "bad", pricing.Version())
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "pricing.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+version: "2025-01"
+default_model: gpt-4o-mini
+models:
+  gpt-4o-mini:
+    prompt_usd: 0.15
+    completion_usd: 0.6
+    unit: per_1m
+`), 0o600))
+	table, err := LoadPriceTable(yamlPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01", table.Version)
+	assert.Equal(t, ModelPrice{PromptUSD: 0.15, CompletionUSD: 0.6, Unit: UnitPer1M}, table.Models["gpt-4o-mini"])
+
+	jsonPath := filepath.Join(dir, "pricing.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"version": "2025-02", "models": {"gpt-4": {"prompt_usd": 0.03, "completion_usd": 0.06}}}`), 0o600))
+	table, err = LoadPriceTable(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-02", table.Version)
+
+	_, err = LoadPriceTable(filepath.Join(dir, "pricing.toml"))
+	assert.Error(t, err)
+}
+
+func TestPricingRegistry_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	write := func(version string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": "`+version+`", "models": {"gpt-4": {"prompt_usd": 0.03, "completion_usd": 0.06}}}`), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	start := time.Now().Add(-time.Hour)
+	write("v1", start)
+
+	pricing := NewPricingRegistry(DefaultPriceTable())
+	require.NoError(t, pricing.Load(path))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pricing.Watch(ctx, path, 10*time.Millisecond)
+
+	write("v2", start.Add(time.Minute))
+	assert.Eventually(t, func() bool { return pricing.Version() == "v2" }, time.Second, 10*time.Millisecond)
+
+	// A broken file keeps the prices it replaced
+	require.NoError(t, os.WriteFile(path, []byte(`{"models": `), 0o600))
+	require.NoError(t, os.Chtimes(path, start.Add(2*time.Minute), start.Add(2*time.Minute)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "v2", pricing.Version())
+
+	write("v3", start.Add(3*time.Minute))
+	assert.Eventually(t, func() bool { return pricing.Version() == "v3" }, time.Second, 10*time.Millisecond)
+}
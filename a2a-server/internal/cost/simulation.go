@@ -0,0 +1,133 @@
+package cost
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// BudgetPolicy is a proposed change to budget limits
+type BudgetPolicy struct {
+	// LimitsUSD are proposed monthly limits by user ID
+	LimitsUSD map[string]float64 `json:"limits_usd,omitempty"`
+	// DefaultLimitUSD, when set, is the proposed limit of every user not in LimitsUSD;
+	// otherwise they keep the limits recorded in the journal
+	DefaultLimitUSD *float64 `json:"default_limit_usd,omitempty"`
+}
+
+// limit returns the limit the policy proposes for a user, or the recorded one
+func (p BudgetPolicy) limit(userID string, recorded float64) float64 {
+	if limit, ok := p.LimitsUSD[userID]; ok {
+		return limit
+	}
+	if p.DefaultLimitUSD != nil {
+		return *p.DefaultLimitUSD
+	}
+	return recorded
+}
+
+// BudgetSimulation reports how a proposed budget policy would have treated the task
+// charges recorded in a window
+type BudgetSimulation struct {
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	Charges     int       `json:"charges"`
+	Rejected    int       `json:"rejected"`
+	RejectedUSD float64   `json:"rejected_usd"`
+	// Users lists the users charged in the window, those with the most rejections first
+	Users []UserBudgetSimulation `json:"users"`
+}
+
+// UserBudgetSimulation is one user's share of a budget simulation
+type UserBudgetSimulation struct {
+	UserID      string  `json:"user_id"`
+	LimitUSD    float64 `json:"limit_usd"`
+	Charges     int     `json:"charges"`
+	Rejected    int     `json:"rejected"`
+	RejectedUSD float64 `json:"rejected_usd"`
+	// SpendUSD is the spend at the end of the window under the proposed limit
+	SpendUSD        float64    `json:"spend_usd"`
+	FirstRejectedAt *time.Time `json:"first_rejected_at,omitempty"`
+}
+
+// SimulateBudgets replays the journal against a proposed budget policy without changing
+// any budget. Entries before since are applied as recorded to rebuild each budget's
+// spend; from since on the proposed limits apply, and a charge in [since, until) that
+// would take a budget over its limit is counted as rejected and left out of the spend,
+// along with any later refund of it. Budgets only see charges of admitted tasks, so
+// looser limits cannot show tasks the recorded ones rejected.
+func SimulateBudgets(ctx context.Context, journal Journal, policy BudgetPolicy, since, until time.Time) (*BudgetSimulation, error) {
+	budgets := NewBudgetManager()
+	users := make(map[string]*UserBudgetSimulation)
+	rejectedTasks := make(map[string]bool)
+	sim := &BudgetSimulation{Since: since, Until: until}
+
+	err := journal.Replay(ctx, func(entry JournalEntry) error {
+		if !entry.Time.Before(until) {
+			return nil
+		}
+		if entry.Time.Before(since) {
+			budgets.apply(entry)
+			return nil
+		}
+
+		switch entry.Type {
+		case EntryBudget:
+			entry.LimitUSD = policy.limit(entry.UserID, entry.LimitUSD)
+		case EntryRefund:
+			if entry.TaskID != "" && rejectedTasks[entry.TaskID] {
+				return nil
+			}
+		case EntryCharge:
+			budget, exists := budgets.budgets[entry.UserID]
+			if !exists {
+				return nil
+			}
+			user := users[entry.UserID]
+			if user == nil {
+				user = &UserBudgetSimulation{UserID: entry.UserID}
+				users[entry.UserID] = user
+			}
+			user.Charges++
+			sim.Charges++
+
+			// The proposed limit replaces the recorded one from the start of the window
+			budget.MonthlyLimitUSD = policy.limit(entry.UserID, budget.MonthlyLimitUSD)
+			if !budget.CheckBudget(entry.AmountUSD) {
+				user.Rejected++
+				user.RejectedUSD += entry.AmountUSD
+				sim.Rejected++
+				sim.RejectedUSD += entry.AmountUSD
+				if user.FirstRejectedAt == nil {
+					at := entry.Time
+					user.FirstRejectedAt = &at
+				}
+				if entry.TaskID != "" {
+					rejectedTasks[entry.TaskID] = true
+				}
+				return nil
+			}
+		}
+		budgets.apply(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sim.Users = make([]UserBudgetSimulation, 0, len(users))
+	for userID, user := range users {
+		if budget, exists := budgets.budgets[userID]; exists {
+			user.LimitUSD = budget.MonthlyLimitUSD
+			user.SpendUSD = budget.CurrentSpendUSD
+		}
+		sim.Users = append(sim.Users, *user)
+	}
+	sort.Slice(sim.Users, func(i, j int) bool {
+		if sim.Users[i].Rejected != sim.Users[j].Rejected {
+			return sim.Users[i].Rejected > sim.Users[j].Rejected
+		}
+		return sim.Users[i].UserID < sim.Users[j].UserID
+	})
+	return sim, nil
+}
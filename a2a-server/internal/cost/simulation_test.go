@@ -0,0 +1,65 @@
+package cost
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateBudgets(t *testing.T) {
+	ctx := context.Background()
+	journal := openTestJournal(t, filepath.Join(t.TempDir(), "usage.journal"))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	for _, entry := range []JournalEntry{
+		{Type: EntryBudget, UserID: "user-1", LimitUSD: 10, Time: at(0)},
+		{Type: EntryBudget, UserID: "user-2", LimitUSD: 10, Time: at(0)},
+		// Spend before the window counts against the proposed limit
+		{Type: EntryCharge, UserID: "user-1", TaskID: "task-0", AmountUSD: 2, Time: at(1)},
+		{Type: EntryCharge, UserID: "user-1", TaskID: "task-1", AmountUSD: 2, Time: at(10)},
+		{Type: EntryCharge, UserID: "user-1", TaskID: "task-2", AmountUSD: 2, Time: at(11)},
+		// The refund of a rejected charge is ignored
+		{Type: EntryRefund, UserID: "user-1", TaskID: "task-2", AmountUSD: 2, Time: at(12)},
+		{Type: EntryCharge, UserID: "user-1", TaskID: "task-3", AmountUSD: 1, Time: at(13)},
+		{Type: EntryCharge, UserID: "user-2", TaskID: "task-4", AmountUSD: 4, Time: at(14)},
+		// Entries after the window are not replayed
+		{Type: EntryCharge, UserID: "user-2", TaskID: "task-5", AmountUSD: 9, Time: at(30)},
+	} {
+		require.NoError(t, journal.Append(ctx, entry))
+	}
+
+	defaultLimit := 5.0
+	policy := BudgetPolicy{LimitsUSD: map[string]float64{"user-1": 5}, DefaultLimitUSD: &defaultLimit}
+	sim, err := SimulateBudgets(ctx, journal, policy, at(5), at(24))
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, sim.Charges)
+	assert.Equal(t, 1, sim.Rejected)
+	assert.InDelta(t, 2, sim.RejectedUSD, 1e-9)
+	require.Len(t, sim.Users, 2)
+
+	user1 := sim.Users[0]
+	assert.Equal(t, "user-1", user1.UserID)
+	assert.Equal(t, 5.0, user1.LimitUSD)
+	assert.Equal(t, 3, user1.Charges)
+	assert.Equal(t, 1, user1.Rejected)
+	assert.InDelta(t, 5, user1.SpendUSD, 1e-9)
+	require.NotNil(t, user1.FirstRejectedAt)
+	assert.True(t, user1.FirstRejectedAt.Equal(at(11)))
+
+	user2 := sim.Users[1]
+	assert.Equal(t, "user-2", user2.UserID)
+	assert.Equal(t, 5.0, user2.LimitUSD)
+	assert.Zero(t, user2.Rejected)
+	assert.InDelta(t, 4, user2.SpendUSD, 1e-9)
+
+	// Without proposed limits the recorded ones reject nothing that was admitted
+	sim, err = SimulateBudgets(ctx, journal, BudgetPolicy{}, at(5), at(24))
+	require.NoError(t, err)
+	assert.Zero(t, sim.Rejected)
+}
@@ -2,7 +2,9 @@ package cost
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -11,29 +13,94 @@ import (
 type Usage struct {
 	UserID           string    `json:"user_id"`
 	TaskID           string    `json:"task_id"`
+	Capability       string    `json:"capability,omitempty"`
 	Model            string    `json:"model"`
 	PromptTokens     int       `json:"prompt_tokens"`
 	CompletionTokens int       `json:"completion_tokens"`
 	TotalTokens      int       `json:"total_tokens"`
 	CostUSD          float64   `json:"cost_usd"`
+	PriceVersion     string    `json:"price_version,omitempty"` // version of the price table CostUSD came from
 	Timestamp        time.Time `json:"timestamp"`
 }
 
-// Tracker tracks token usage and costs
-type Tracker struct {
+// Tracker records token usage and costs and aggregates them for reporting and billing
+type Tracker interface {
+	// SetJournal logs every usage record to journal before it is recorded; see Recover
+	SetJournal(journal Journal)
+	// RecordUsage records token usage and cost
+	RecordUsage(ctx context.Context, usage Usage) error
+	// GetUsage retrieves usage records for a user within a time range
+	GetUsage(ctx context.Context, userID string, start, end time.Time) ([]Usage, error)
+	// GetTotalCost calculates total cost for a user within a time range
+	GetTotalCost(ctx context.Context, userID string, start, end time.Time) (float64, error)
+	// GetTotalTokens calculates total tokens for a user within a time range
+	GetTotalTokens(ctx context.Context, userID string, start, end time.Time) (int, error)
+	// CostBy aggregates the usage within a time range by day, model or capability, ordered
+	// by key. An empty userID aggregates the usage of all users.
+	CostBy(ctx context.Context, userID string, start, end time.Time, grouping Grouping) ([]CostBucket, error)
+	// EraseUsage deletes a user's usage records, or hands them to pseudonym when it is not
+	// empty, and returns how many there were
+	EraseUsage(ctx context.Context, userID, pseudonym string) (int, error)
+}
+
+// Grouping selects what CostBy aggregates usage by
+type Grouping string
+
+// Groupings supported by CostBy
+const (
+	// ByDay groups by UTC calendar day, keyed as 2006-01-02
+	ByDay        Grouping = "day"
+	ByModel      Grouping = "model"
+	ByCapability Grouping = "capability"
+)
+
+// Valid reports whether g is a supported grouping
+func (g Grouping) Valid() bool {
+	switch g {
+	case ByDay, ByModel, ByCapability:
+		return true
+	}
+	return false
+}
+
+// CostBucket is the usage aggregated under one day, model or capability
+type CostBucket struct {
+	Key              string  `json:"key"`
+	Records          int     `json:"records"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// MemoryTracker keeps usage records in memory; with a journal they are rebuilt on
+// startup by Recover
+type MemoryTracker struct {
 	mu    sync.RWMutex
 	usage []Usage
+
+	// journal, when set, durably logs each usage record before it is added
+	journal Journal
 }
 
-// NewTracker creates a new cost tracker
-func NewTracker() *Tracker {
-	return &Tracker{
+var _ Tracker = (*MemoryTracker)(nil)
+
+// NewMemoryTracker creates a new in-memory cost tracker
+func NewMemoryTracker() *MemoryTracker {
+	return &MemoryTracker{
 		usage: make([]Usage, 0),
 	}
 }
 
+// SetJournal logs every usage record to journal before it is added; see Recover
+func (t *MemoryTracker) SetJournal(journal Journal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.journal = journal
+}
+
 // RecordUsage records token usage and cost
-func (t *Tracker) RecordUsage(ctx context.Context, usage Usage) error {
+func (t *MemoryTracker) RecordUsage(ctx context.Context, usage Usage) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -41,20 +108,56 @@ func (t *Tracker) RecordUsage(ctx context.Context, usage Usage) error {
 		usage.Timestamp = time.Now()
 	}
 
+	if t.journal != nil {
+		if err := t.journal.Append(ctx, JournalEntry{Type: EntryUsage, UserID: usage.UserID, TaskID: usage.TaskID, Usage: &usage}); err != nil {
+			return err
+		}
+	}
 	t.usage = append(t.usage, usage)
 	return nil
 }
 
+// EraseUsage deletes or pseudonymizes a user's usage records, journaling the erasure so
+// Recover does not bring them back
+func (t *MemoryTracker) EraseUsage(ctx context.Context, userID, pseudonym string) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.journal != nil {
+		if err := t.journal.Append(ctx, JournalEntry{Type: EntryErased, UserID: userID, Pseudonym: pseudonym}); err != nil {
+			return 0, err
+		}
+	}
+	var erased int
+	t.usage, erased = eraseUsage(t.usage, userID, pseudonym)
+	return erased, nil
+}
+
+// eraseUsage drops the user's records from usage, or hands them to pseudonym when it is
+// not empty, and returns the records left and how many were the user's
+func eraseUsage(usage []Usage, userID, pseudonym string) ([]Usage, int) {
+	kept, erased := usage[:0], 0
+	for _, u := range usage {
+		if u.UserID == userID {
+			erased++
+			if pseudonym == "" {
+				continue
+			}
+			u.UserID = pseudonym
+		}
+		kept = append(kept, u)
+	}
+	return kept, erased
+}
+
 // GetUsage retrieves usage records for a user within a time range
-func (t *Tracker) GetUsage(ctx context.Context, userID string, start, end time.Time) ([]Usage, error) {
+func (t *MemoryTracker) GetUsage(ctx context.Context, userID string, start, end time.Time) ([]Usage, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
 	var result []Usage
 	for _, u := range t.usage {
-		if u.UserID == userID &&
-			(u.Timestamp.Equal(start) || u.Timestamp.After(start)) &&
-			(u.Timestamp.Equal(end) || u.Timestamp.Before(end)) {
+		if u.UserID == userID && inRange(u.Timestamp, start, end) {
 			result = append(result, u)
 		}
 	}
@@ -63,7 +166,7 @@ func (t *Tracker) GetUsage(ctx context.Context, userID string, start, end time.T
 }
 
 // GetTotalCost calculates total cost for a user within a time range
-func (t *Tracker) GetTotalCost(ctx context.Context, userID string, start, end time.Time) (float64, error) {
+func (t *MemoryTracker) GetTotalCost(ctx context.Context, userID string, start, end time.Time) (float64, error) {
 	usage, err := t.GetUsage(ctx, userID, start, end)
 	if err != nil {
 		return 0, err
@@ -78,7 +181,7 @@ func (t *Tracker) GetTotalCost(ctx context.Context, userID string, start, end ti
 }
 
 // GetTotalTokens calculates total tokens for a user within a time range
-func (t *Tracker) GetTotalTokens(ctx context.Context, userID string, start, end time.Time) (int, error) {
+func (t *MemoryTracker) GetTotalTokens(ctx context.Context, userID string, start, end time.Time) (int, error) {
 	usage, err := t.GetUsage(ctx, userID, start, end)
 	if err != nil {
 		return 0, err
@@ -92,12 +195,64 @@ func (t *Tracker) GetTotalTokens(ctx context.Context, userID string, start, end
 	return total, nil
 }
 
-// Budget represents a user's budget constraints
+// CostBy aggregates the usage within a time range by day, model or capability
+func (t *MemoryTracker) CostBy(ctx context.Context, userID string, start, end time.Time, grouping Grouping) ([]CostBucket, error) {
+	if !grouping.Valid() {
+		return nil, fmt.Errorf("unknown grouping %q", grouping)
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	buckets := make(map[string]*CostBucket)
+	for _, u := range t.usage {
+		if (userID != "" && u.UserID != userID) || !inRange(u.Timestamp, start, end) {
+			continue
+		}
+		key := u.Model
+		switch grouping {
+		case ByDay:
+			key = u.Timestamp.UTC().Format(time.DateOnly)
+		case ByCapability:
+			key = u.Capability
+		}
+
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &CostBucket{Key: key}
+			buckets[key] = bucket
+		}
+		bucket.Records++
+		bucket.PromptTokens += u.PromptTokens
+		bucket.CompletionTokens += u.CompletionTokens
+		bucket.TotalTokens += u.TotalTokens
+		bucket.CostUSD += u.CostUSD
+	}
+
+	result := make([]CostBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// inRange reports whether ts lies within [start, end]
+func inRange(ts, start, end time.Time) bool {
+	return !ts.Before(start) && !ts.After(end)
+}
+
+// Budget represents a user's or a tenant's budget constraints. A user budget with a
+// TenantID is a sub-budget of that tenant's: charges count against both.
 type Budget struct {
-	UserID          string    `json:"user_id"`
+	UserID          string    `json:"user_id,omitempty"`
+	TenantID        string    `json:"tenant_id,omitempty"`
 	MonthlyLimitUSD float64   `json:"monthly_limit_usd"`
 	CurrentSpendUSD float64   `json:"current_spend_usd"`
 	ResetAt         time.Time `json:"reset_at"`
+
+	// alerted holds when each alert threshold was crossed in the current period
+	alerted map[float64]time.Time
 }
 
 // CheckBudget checks if a cost is within budget
@@ -127,31 +282,134 @@ func (b *Budget) UpdateSpend(costUSD float64) {
 	b.CurrentSpendUSD += costUSD
 }
 
-// BudgetManager manages user budgets
+// BudgetManager manages user budgets and the tenant budgets they belong to
 type BudgetManager struct {
 	mu      sync.RWMutex
 	budgets map[string]*Budget
+	tenants map[string]*Budget
+
+	// journal, when set, durably logs each change before it is applied
+	journal Journal
+
+	// thresholds are the percentages of a budget whose crossing notifier is told about
+	thresholds []float64
+	notifier   AlertNotifier
 }
 
 // NewBudgetManager creates a new budget manager
 func NewBudgetManager() *BudgetManager {
 	return &BudgetManager{
 		budgets: make(map[string]*Budget),
+		tenants: make(map[string]*Budget),
+	}
+}
+
+// SetJournal logs every budget change to journal before it is applied; see Recover
+func (bm *BudgetManager) SetJournal(journal Journal) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.journal = journal
+}
+
+// record logs a change to the journal, if there is one; the caller holds bm.mu
+func (bm *BudgetManager) record(ctx context.Context, entry JournalEntry) error {
+	if bm.journal == nil {
+		return nil
+	}
+	return bm.journal.Append(ctx, entry)
+}
+
+// apply replays a journaled change, returning the alerts for the thresholds it crossed;
+// the caller holds bm.mu or has exclusive use of bm
+func (bm *BudgetManager) apply(entry JournalEntry) []Alert {
+	at := entry.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	switch entry.Type {
+	case EntryBudget:
+		bm.budgets[entry.UserID] = &Budget{
+			UserID:          entry.UserID,
+			TenantID:        entry.TenantID,
+			MonthlyLimitUSD: entry.LimitUSD,
+			ResetAt:         entry.ResetAt,
+		}
+		return nil
+	case EntryTenantBudget:
+		tenant, exists := bm.tenants[entry.TenantID]
+		if !exists {
+			tenant = &Budget{TenantID: entry.TenantID, ResetAt: entry.ResetAt}
+			bm.tenants[entry.TenantID] = tenant
+		}
+		tenant.MonthlyLimitUSD = entry.LimitUSD
+		tenant.rearm()
+		return bm.crossed(LevelTenant, tenant.TenantID, tenant, at)
+	}
+
+	budget, exists := bm.budgets[entry.UserID]
+	if !exists {
+		return nil
 	}
+	tenant := bm.tenants[budget.TenantID]
+	switch entry.Type {
+	case EntryReset:
+		budget.CurrentSpendUSD = 0
+		budget.ResetAt = entry.ResetAt
+		budget.alerted = nil
+	case EntryCharge:
+		budget.UpdateSpend(entry.AmountUSD)
+		alerts := bm.crossed(LevelUser, budget.UserID, budget, at)
+		if tenant != nil {
+			tenant.UpdateSpend(entry.AmountUSD)
+			alerts = append(alerts, bm.crossed(LevelTenant, tenant.TenantID, tenant, at)...)
+		}
+		return alerts
+	case EntryRefund:
+		budget.CurrentSpendUSD = max(budget.CurrentSpendUSD-entry.AmountUSD, 0)
+		if tenant != nil {
+			tenant.CurrentSpendUSD = max(tenant.CurrentSpendUSD-entry.AmountUSD, 0)
+		}
+	}
+	return nil
 }
 
-// SetBudget sets a user's budget
+// SetBudget sets a user's budget, keeping the tenant it belongs to
 func (bm *BudgetManager) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error {
+	bm.mu.Lock()
+	tenantID := ""
+	if budget, exists := bm.budgets[userID]; exists {
+		tenantID = budget.TenantID
+	}
+	bm.mu.Unlock()
+	return bm.SetUserBudget(ctx, tenantID, userID, monthlyLimitUSD)
+}
+
+// SetUserBudget sets a user's budget as a sub-budget of the tenant's, or as a standalone
+// budget when tenantID is empty, resetting its spend
+func (bm *BudgetManager) SetUserBudget(ctx context.Context, tenantID, userID string, monthlyLimitUSD float64) error {
 	bm.mu.Lock()
 	defer bm.mu.Unlock()
 
-	bm.budgets[userID] = &Budget{
-		UserID:          userID,
-		MonthlyLimitUSD: monthlyLimitUSD,
-		CurrentSpendUSD: 0,
-		ResetAt:         time.Now().AddDate(0, 1, 0),
+	entry := JournalEntry{Type: EntryBudget, UserID: userID, TenantID: tenantID, LimitUSD: monthlyLimitUSD, ResetAt: time.Now().AddDate(0, 1, 0)}
+	if err := bm.record(ctx, entry); err != nil {
+		return err
 	}
+	bm.apply(entry)
+	return nil
+}
+
+// SetTenantBudget sets the limit of a tenant's budget, which caps the combined spend of its
+// users. Unlike a user's, the tenant's current spend is kept: it is the sum of its users'.
+func (bm *BudgetManager) SetTenantBudget(ctx context.Context, tenantID string, monthlyLimitUSD float64) error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
 
+	entry := JournalEntry{Type: EntryTenantBudget, TenantID: tenantID, LimitUSD: monthlyLimitUSD, ResetAt: time.Now().AddDate(0, 1, 0)}
+	if err := bm.record(ctx, entry); err != nil {
+		return err
+	}
+	bm.notify(ctx, bm.apply(entry))
 	return nil
 }
 
@@ -168,22 +426,126 @@ func (bm *BudgetManager) GetBudget(ctx context.Context, userID string) (*Budget,
 	return budget, nil
 }
 
+// GetTenantBudget retrieves a tenant's budget
+func (bm *BudgetManager) GetTenantBudget(ctx context.Context, tenantID string) (*Budget, error) {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+
+	tenant, exists := bm.tenants[tenantID]
+	if !exists {
+		return nil, fmt.Errorf("budget for tenant %s not found", tenantID)
+	}
+	return tenant, nil
+}
+
 // CheckAndUpdate checks if cost is within budget and updates if allowed
 func (bm *BudgetManager) CheckAndUpdate(ctx context.Context, userID string, costUSD float64) (bool, error) {
+	return bm.Charge(ctx, userID, "", costUSD)
+}
+
+// Charge checks if the cost of a task is within the user's budget and its tenant's and
+// charges it if allowed. The task ID lets Recover match the charge with the task's usage
+// records.
+func (bm *BudgetManager) Charge(ctx context.Context, userID, taskID string, costUSD float64) (bool, error) {
+	err := bm.ChargeTask(ctx, userID, taskID, costUSD, 0)
+	var exceeded *BudgetExceededError
+	if errors.As(err, &exceeded) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ChargeTask charges the cost of a task like Charge, but reports the level whose budget
+// it would exceed as a *BudgetExceededError. The levels are checked in order: the
+// tenant's budget, the user's, then the task's own cost cap maxCostUSD (zero for none).
+func (bm *BudgetManager) ChargeTask(ctx context.Context, userID, taskID string, costUSD, maxCostUSD float64) error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if err := bm.checkTask(userID, taskID, costUSD, maxCostUSD); err != nil {
+		return err
+	}
+
+	entry := JournalEntry{Type: EntryCharge, UserID: userID, TaskID: taskID, AmountUSD: costUSD}
+	if err := bm.record(ctx, entry); err != nil {
+		return err
+	}
+	bm.notify(ctx, bm.apply(entry))
+	return nil
+}
+
+// forceCharge charges a budget even beyond its limit, for costs already incurred
+func (bm *BudgetManager) forceCharge(ctx context.Context, userID, taskID string, costUSD float64) error {
 	bm.mu.Lock()
 	defer bm.mu.Unlock()
 
+	if _, exists := bm.budgets[userID]; !exists {
+		return fmt.Errorf("budget for user %s not found", userID)
+	}
+	entry := JournalEntry{Type: EntryCharge, UserID: userID, TaskID: taskID, AmountUSD: costUSD}
+	if err := bm.record(ctx, entry); err != nil {
+		return err
+	}
+	bm.notify(ctx, bm.apply(entry))
+	return nil
+}
+
+// Check reports the first level, tenant then user, whose budget costUSD more would exceed,
+// without charging anything. A zero cost checks that neither budget is already overspent.
+func (bm *BudgetManager) Check(ctx context.Context, userID string, costUSD float64) error {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.check(userID, costUSD)
+}
+
+// CheckTask reports whether ChargeTask would charge a task, without charging it
+func (bm *BudgetManager) CheckTask(ctx context.Context, userID, taskID string, costUSD, maxCostUSD float64) error {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.checkTask(userID, taskID, costUSD, maxCostUSD)
+}
+
+// checkTask implements CheckTask; the caller holds bm.mu
+func (bm *BudgetManager) checkTask(userID, taskID string, costUSD, maxCostUSD float64) error {
+	if err := bm.check(userID, costUSD); err != nil {
+		return err
+	}
+	if maxCostUSD > 0 && costUSD > maxCostUSD {
+		return &BudgetExceededError{Level: LevelTask, ID: taskID, LimitUSD: maxCostUSD, CostUSD: costUSD}
+	}
+	return nil
+}
+
+// check implements Check; the caller holds bm.mu
+func (bm *BudgetManager) check(userID string, costUSD float64) error {
 	budget, exists := bm.budgets[userID]
 	if !exists {
-		return false, fmt.Errorf("budget for user %s not found", userID)
+		return fmt.Errorf("budget for user %s not found", userID)
+	}
+	if tenant, exists := bm.tenants[budget.TenantID]; exists && !tenant.CheckBudget(costUSD) {
+		return exceeded(LevelTenant, tenant.TenantID, tenant, costUSD)
 	}
-
 	if !budget.CheckBudget(costUSD) {
-		return false, nil
+		return exceeded(LevelUser, userID, budget, costUSD)
+	}
+	return nil
+}
+
+// Refund returns a task's charge to the user's budget and its tenant's
+func (bm *BudgetManager) Refund(ctx context.Context, userID, taskID string, costUSD float64) error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if _, exists := bm.budgets[userID]; !exists {
+		return fmt.Errorf("budget for user %s not found", userID)
 	}
 
-	budget.UpdateSpend(costUSD)
-	return true, nil
+	entry := JournalEntry{Type: EntryRefund, UserID: userID, TaskID: taskID, AmountUSD: costUSD}
+	if err := bm.record(ctx, entry); err != nil {
+		return err
+	}
+	bm.apply(entry)
+	return nil
 }
 
 // ResetBudget resets a user's current spend
@@ -191,53 +553,20 @@ func (bm *BudgetManager) ResetBudget(ctx context.Context, userID string) error {
 	bm.mu.Lock()
 	defer bm.mu.Unlock()
 
-	budget, exists := bm.budgets[userID]
-	if !exists {
+	if _, exists := bm.budgets[userID]; !exists {
 		return fmt.Errorf("budget for user %s not found", userID)
 	}
 
-	budget.CurrentSpendUSD = 0
-	budget.ResetAt = time.Now().AddDate(0, 1, 0)
+	entry := JournalEntry{Type: EntryReset, UserID: userID, ResetAt: time.Now().AddDate(0, 1, 0)}
+	if err := bm.record(ctx, entry); err != nil {
+		return err
+	}
+	bm.apply(entry)
 	return nil
 }
 
-// Model pricing (per 1K tokens) - based on OpenAI pricing as of 2024
-var modelPricing = map[string]struct {
-	PromptCost     float64
-	CompletionCost float64
-}{
-	"gpt-4": {
-		PromptCost:     0.03,
-		CompletionCost: 0.06,
-	},
-	"gpt-4-turbo": {
-		PromptCost:     0.01,
-		CompletionCost: 0.03,
-	},
-	"gpt-3.5-turbo": {
-		PromptCost:     0.0015,
-		CompletionCost: 0.002,
-	},
-	"claude-3-opus": {
-		PromptCost:     0.015,
-		CompletionCost: 0.075,
-	},
-	"claude-3-sonnet": {
-		PromptCost:     0.003,
-		CompletionCost: 0.015,
-	},
-}
-
-// CalculateCost calculates the cost based on model and token usage
+// CalculateCost calculates the cost based on model and token usage with the built-in
+// prices; usage billed through a PricingRegistry uses its prices instead
 func CalculateCost(model string, promptTokens, completionTokens int) float64 {
-	pricing, exists := modelPricing[model]
-	if !exists {
-		// Default to gpt-3.5-turbo pricing
-		pricing = modelPricing["gpt-3.5-turbo"]
-	}
-
-	promptCost := float64(promptTokens) * pricing.PromptCost / 1000.0
-	completionCost := float64(completionTokens) * pricing.CompletionCost / 1000.0
-
-	return promptCost + completionCost
+	return builtinPrices.Cost(model, promptTokens, completionTokens)
 }
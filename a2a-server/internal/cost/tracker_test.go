@@ -9,15 +9,15 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestNewTracker(t *testing.T) {
-	tracker := NewTracker()
+func TestNewMemoryTracker(t *testing.T) {
+	tracker := NewMemoryTracker()
 
 	assert.NotNil(t, tracker)
 	assert.NotNil(t, tracker.usage)
 }
 
 func TestTracker_RecordUsage(t *testing.T) {
-	tracker := NewTracker()
+	tracker := NewMemoryTracker()
 	ctx := context.Background()
 
 	usage := Usage{
@@ -41,7 +41,7 @@ func TestTracker_RecordUsage(t *testing.T) {
 }
 
 func TestTracker_GetUsage(t *testing.T) {
-	tracker := NewTracker()
+	tracker := NewMemoryTracker()
 	ctx := context.Background()
 
 	now := time.Now()
@@ -93,7 +93,7 @@ func TestTracker_GetUsage(t *testing.T) {
 }
 
 func TestTracker_GetTotalCost(t *testing.T) {
-	tracker := NewTracker()
+	tracker := NewMemoryTracker()
 	ctx := context.Background()
 
 	now := time.Now()
@@ -127,7 +127,7 @@ func TestTracker_GetTotalCost(t *testing.T) {
 }
 
 func TestTracker_GetTotalTokens(t *testing.T) {
-	tracker := NewTracker()
+	tracker := NewMemoryTracker()
 	ctx := context.Background()
 
 	now := time.Now()
@@ -323,3 +323,43 @@ func TestCalculateCost(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryTracker_CostBy(t *testing.T) {
+	tracker := NewMemoryTracker()
+	ctx := context.Background()
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	records := []Usage{
+		{UserID: "user-1", Capability: "search_papers", Model: "gpt-4", TotalTokens: 100, CostUSD: 0.01, Timestamp: day},
+		{UserID: "user-1", Capability: "summarize_document", Model: "gpt-4", TotalTokens: 50, CostUSD: 0.02, Timestamp: day.Add(time.Hour)},
+		{UserID: "user-2", Capability: "search_papers", Model: "claude-3-sonnet", TotalTokens: 10, CostUSD: 0.04, Timestamp: day.Add(24 * time.Hour)},
+		{UserID: "user-1", Capability: "search_papers", Model: "gpt-4", TotalTokens: 1, CostUSD: 1, Timestamp: day.Add(-48 * time.Hour)},
+	}
+	for _, usage := range records {
+		require.NoError(t, tracker.RecordUsage(ctx, usage))
+	}
+	start, end := day.Add(-time.Hour), day.Add(48*time.Hour)
+
+	byDay, err := tracker.CostBy(ctx, "", start, end, ByDay)
+	require.NoError(t, err)
+	require.Len(t, byDay, 2)
+	assert.Equal(t, "2025-03-10", byDay[0].Key)
+	assert.Equal(t, 2, byDay[0].Records)
+	assert.Equal(t, 150, byDay[0].TotalTokens)
+	assert.InDelta(t, 0.03, byDay[0].CostUSD, 1e-9)
+	assert.Equal(t, "2025-03-11", byDay[1].Key)
+
+	byModel, err := tracker.CostBy(ctx, "user-1", start, end, ByModel)
+	require.NoError(t, err)
+	require.Len(t, byModel, 1)
+	assert.Equal(t, CostBucket{Key: "gpt-4", Records: 2, TotalTokens: 150, CostUSD: 0.03}, byModel[0])
+
+	byCapability, err := tracker.CostBy(ctx, "", start, end, ByCapability)
+	require.NoError(t, err)
+	require.Len(t, byCapability, 2)
+	assert.Equal(t, "search_papers", byCapability[0].Key)
+	assert.InDelta(t, 0.05, byCapability[0].CostUSD, 1e-9)
+
+	_, err = tracker.CostBy(ctx, "", start, end, Grouping("week"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,377 @@
+// Package events publishes task lifecycle events to a message broker, NATS JetStream or
+// Kafka, so billing, analytics and other systems can follow tasks as a stream instead of
+// polling the REST API. The event schema is documented in docs/events.md.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the Event schema; fields may be added within a
+// version, but none are removed or change meaning
+const SchemaVersion = "1"
+
+// Event types, one per lifecycle change
+const (
+	TypeCreated       = "task.created"
+	TypeStarted       = "task.started"
+	TypeInputRequired = "task.input_required"
+	TypeCompleted     = "task.completed"
+	TypeFailed        = "task.failed"
+	TypeCancelled     = "task.cancelled"
+)
+
+// Brokers events can be published to
+const (
+	BackendNATS  = "nats"
+	BackendKafka = "kafka"
+)
+
+// Publish outcomes, as recorded in metrics
+const (
+	StatusPublished = "published"
+	StatusFailed    = "failed"
+	// StatusDropped counts events discarded because the queue was full or closed
+	StatusDropped = "dropped"
+)
+
+// ErrInvalidConfig is returned for event configs no publisher can be created for
+var ErrInvalidConfig = errors.New("invalid event config")
+
+// Event is the JSON message published for each lifecycle change of a task
+type Event struct {
+	// ID is unique per event, so consumers can drop redeliveries
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+
+	TaskID            string                `json:"task_id"`
+	AgentID           string                `json:"agent_id,omitempty"`
+	Capability        string                `json:"capability,omitempty"`
+	CapabilityVersion string                `json:"capability_version,omitempty"`
+	UserID            string                `json:"user_id,omitempty"`
+	Priority          protocol.TaskPriority `json:"priority,omitempty"`
+	State             protocol.TaskState    `json:"state"`
+	Message           string                `json:"message,omitempty"`
+	// Error is the failure reason of task.failed events
+	Error string `json:"error,omitempty"`
+	// Attempts is how many times the task has been started
+	Attempts int `json:"attempts,omitempty"`
+	// WorkerID identifies the processor that moved the task to State, if one did
+	WorkerID  string    `json:"worker_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// DurationMs is the time from the task's creation to a terminal event
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Data carries the details of the transition, such as the speculation decision
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Publisher sends encoded events to a broker
+type Publisher interface {
+	Publish(ctx context.Context, event Event, body []byte) error
+	Close() error
+}
+
+// Recorder records publish outcomes
+type Recorder interface {
+	RecordEventPublish(ctx context.Context, eventType, status string)
+}
+
+// Config selects the broker and controls publishing
+type Config struct {
+	// Backend is BackendNATS or BackendKafka
+	Backend string
+	// URL is the NATS server URL, or the base URL of the Kafka REST Proxy
+	URL string
+	// Topic is the Kafka topic, or the prefix of the NATS subjects: each event type is
+	// published to "<topic>.<type without task.>", e.g. a2a.tasks.completed
+	Topic string
+	// Stream is the JetStream stream keeping the events, created if it does not exist
+	Stream string
+	// BufferSize bounds the events waiting to be published; more are dropped
+	BufferSize int
+	// MaxAttempts is how many times an event is tried before it is dropped
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds each attempt
+	Timeout time.Duration
+}
+
+// DefaultConfig returns the default publishing settings
+func DefaultConfig() Config {
+	return Config{
+		Topic:          "a2a.tasks",
+		Stream:         "A2A_TASKS",
+		BufferSize:     1000,
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Timeout:        5 * time.Second,
+	}
+}
+
+// withDefaults fills the unset fields of c from DefaultConfig
+func (c Config) withDefaults() Config {
+	defaults := DefaultConfig()
+	if c.Topic == "" {
+		c.Topic = defaults.Topic
+	}
+	if c.Stream == "" {
+		c.Stream = defaults.Stream
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaults.BufferSize
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaults.MaxAttempts
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = defaults.InitialBackoff
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		c.MaxBackoff = c.InitialBackoff
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	return c
+}
+
+// NewPublisher connects to the broker the config selects
+func NewPublisher(ctx context.Context, config Config) (Publisher, error) {
+	config = config.withDefaults()
+	if config.URL == "" {
+		return nil, fmt.Errorf("%w: a broker URL is required", ErrInvalidConfig)
+	}
+	switch config.Backend {
+	case BackendNATS:
+		return NewNATSPublisher(ctx, config)
+	case BackendKafka:
+		return NewKafkaPublisher(config), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown backend %q, want %q or %q", ErrInvalidConfig, config.Backend, BackendNATS, BackendKafka)
+	}
+}
+
+// Store wraps a task store, publishing an event when a task is created and for each state
+// transition published on the store. Events are published in the background, in order,
+// and retried with exponential backoff, so a slow or unreachable broker never holds up
+// tasks; events that cannot be queued or published are dropped and counted.
+type Store struct {
+	tasks.Store
+	publisher Publisher
+	config    Config
+	metrics   Recorder
+
+	mu     sync.Mutex
+	queue  chan Event
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewStore wraps store, publishing its tasks' events with publisher
+func NewStore(store tasks.Store, publisher Publisher, config Config) *Store {
+	config = config.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Store{
+		Store:     store,
+		publisher: publisher,
+		config:    config,
+		queue:     make(chan Event, config.BufferSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// SetMetrics records every publish outcome
+func (s *Store) SetMetrics(metrics Recorder) {
+	s.metrics = metrics
+}
+
+// Create stores a task and publishes its task.created event
+func (s *Store) Create(ctx context.Context, task *protocol.Task) error {
+	if err := s.Store.Create(ctx, task); err != nil {
+		return err
+	}
+	event := newEvent(TypeCreated, task.CreatedAt)
+	event.State = task.State
+	event.Message = "Task created"
+	event.describe(task)
+	s.emit(event)
+	return nil
+}
+
+// PublishEvent publishes a transition to the task's subscribers, then queues its event.
+// Artifact updates and transitions back to pending, such as retries, have none. The task
+// is read here rather than when the event is sent, so its attempts, error and duration
+// are those of this transition even if the task is retried before the broker gets it.
+func (s *Store) PublishEvent(ctx context.Context, event protocol.TaskEvent) {
+	s.Store.PublishEvent(ctx, event)
+
+	eventType, ok := transitionType(event)
+	if !ok {
+		return
+	}
+	published := newEvent(eventType, event.Timestamp)
+	published.TaskID = event.TaskID
+	published.State = event.State
+	published.Message = event.Message
+	published.WorkerID = event.WorkerID
+	published.Data = event.Data
+	if task, err := s.Store.Get(context.WithoutCancel(ctx), event.TaskID); err == nil {
+		published.describe(task)
+	}
+	s.emit(published)
+}
+
+// Close publishes the queued events, giving up on those still failing after Timeout, and
+// closes the publisher
+func (s *Store) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(s.config.Timeout):
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+	return s.publisher.Close()
+}
+
+// transitionType returns the event type of a state transition
+func transitionType(event protocol.TaskEvent) (string, bool) {
+	if !event.Transition() {
+		return "", false
+	}
+	switch event.State {
+	case protocol.TaskStateRunning:
+		return TypeStarted, true
+	case protocol.TaskStateInputRequired:
+		return TypeInputRequired, true
+	case protocol.TaskStateCompleted:
+		return TypeCompleted, true
+	case protocol.TaskStateFailed:
+		return TypeFailed, true
+	case protocol.TaskStateCancelled:
+		return TypeCancelled, true
+	default:
+		return "", false
+	}
+}
+
+// newEvent returns an event of the given type with a new ID
+func newEvent(eventType string, at time.Time) Event {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return Event{ID: uuid.NewString(), Type: eventType, Version: SchemaVersion, Time: at.UTC()}
+}
+
+// describe fills the event's task fields from the task as of the event
+func (e *Event) describe(task *protocol.Task) {
+	e.TaskID = task.ID
+	e.AgentID = task.AgentID
+	e.Capability = task.Capability
+	e.CapabilityVersion = task.CapabilityVersion
+	e.UserID = task.UserID
+	e.Priority = task.Priority
+	e.Attempts = task.Attempts
+	e.CreatedAt = task.CreatedAt.UTC()
+	if e.State == protocol.TaskStateFailed {
+		e.Error = task.Error
+	}
+	if e.State.IsTerminal() && !task.CreatedAt.IsZero() {
+		e.DurationMs = e.Time.Sub(task.CreatedAt).Milliseconds()
+	}
+}
+
+// emit queues an event, dropping it when the queue is full or closed
+func (s *Store) emit(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		s.record(event.Type, StatusDropped)
+		return
+	}
+	select {
+	case s.queue <- event:
+	default:
+		s.record(event.Type, StatusDropped)
+		slog.Warn("Task event queue full, dropping event", "task_id", event.TaskID, "type", event.Type)
+	}
+}
+
+// run publishes queued events until the queue is closed
+func (s *Store) run() {
+	defer close(s.done)
+	for event := range s.queue {
+		s.publish(event)
+	}
+}
+
+// publish sends an event, retrying with exponential backoff until it is accepted or the
+// attempts run out
+func (s *Store) publish(event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to encode task event", "task_id", event.TaskID, "type", event.Type, "error", err)
+		return
+	}
+
+	backoff := s.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(s.ctx, s.config.Timeout)
+		err := s.publisher.Publish(ctx, event, body)
+		cancel()
+		if err == nil {
+			s.record(event.Type, StatusPublished)
+			return
+		}
+		if attempt >= s.config.MaxAttempts || s.ctx.Err() != nil {
+			s.record(event.Type, StatusFailed)
+			slog.Warn("Task event publish failed", "task_id", event.TaskID, "type", event.Type,
+				"attempts", attempt, "error", err)
+			return
+		}
+
+		slog.Debug("Retrying task event publish", "task_id", event.TaskID, "type", event.Type,
+			"attempt", attempt, "backoff", backoff.String(), "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+		}
+		backoff = min(backoff*2, s.config.MaxBackoff)
+	}
+}
+
+// record records a publish outcome if metrics are set
+func (s *Store) record(eventType, status string) {
+	if s.metrics != nil {
+		s.metrics.RecordEventPublish(context.Background(), eventType, status)
+	}
+}
@@ -0,0 +1,246 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// broker records published events, failing the first failures attempts; with a gate,
+// publishing waits until it is closed
+type broker struct {
+	mu       sync.Mutex
+	failures int
+	events   []Event
+	closed   bool
+	gate     chan struct{}
+}
+
+func (b *broker) Publish(ctx context.Context, event Event, body []byte) error {
+	if b.gate != nil {
+		<-b.gate
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("broker unavailable")
+	}
+	var decoded Event
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return err
+	}
+	b.events = append(b.events, decoded)
+	return nil
+}
+
+func (b *broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *broker) get() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Event(nil), b.events...)
+}
+
+// outcomes records publish metrics
+type outcomes struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (o *outcomes) RecordEventPublish(ctx context.Context, eventType, status string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results = append(o.results, eventType+":"+status)
+}
+
+func (o *outcomes) get() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.results...)
+}
+
+func fastConfig() Config {
+	return Config{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Timeout: time.Second}
+}
+
+func TestStore_PublishesLifecycle(t *testing.T) {
+	ctx := context.Background()
+	b := &broker{failures: 1}
+	metrics := &outcomes{}
+	store := NewStore(tasks.NewMemoryStore(), b, fastConfig())
+	store.SetMetrics(metrics)
+
+	task := protocol.NewTask("agent-1", "search", map[string]interface{}{"query": "go"})
+	task.UserID = "user-1"
+	task.Priority = protocol.PriorityHigh
+	require.NoError(t, store.Create(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStatePending, Message: "Task created"})
+
+	task.UpdateState(protocol.TaskStateRunning)
+	task.Attempts = 1
+	require.NoError(t, store.Update(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, Message: "Task started", WorkerID: "worker-1"})
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning,
+		Artifact: &protocol.Artifact{ArtifactID: "a-1"}})
+
+	task.SetError("upstream timeout")
+	require.NoError(t, store.Update(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateFailed, Message: "Task failed",
+		Data: map[string]interface{}{"speculative": false}})
+	require.NoError(t, store.Close())
+
+	// The pending transition and the artifact update are not published
+	published := b.get()
+	require.Len(t, published, 3)
+	assert.Equal(t, []string{TypeCreated, TypeStarted, TypeFailed},
+		[]string{published[0].Type, published[1].Type, published[2].Type})
+	for _, event := range published {
+		assert.NotEmpty(t, event.ID)
+		assert.Equal(t, SchemaVersion, event.Version)
+		assert.Equal(t, task.ID, event.TaskID)
+		assert.Equal(t, "agent-1", event.AgentID)
+		assert.Equal(t, "search", event.Capability)
+		assert.Equal(t, "user-1", event.UserID)
+		assert.Equal(t, protocol.PriorityHigh, event.Priority)
+	}
+	assert.Equal(t, protocol.TaskStatePending, published[0].State)
+	assert.Equal(t, "worker-1", published[1].WorkerID)
+	failed := published[2]
+	assert.Equal(t, protocol.TaskStateFailed, failed.State)
+	assert.Equal(t, "upstream timeout", failed.Error)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, false, failed.Data["speculative"])
+
+	assert.Equal(t, []string{"task.created:published", "task.started:published", "task.failed:published"}, metrics.get())
+	assert.True(t, b.closed)
+}
+
+func TestStore_DescribesTransitionsAsPublished(t *testing.T) {
+	ctx := context.Background()
+	b := &broker{gate: make(chan struct{})}
+	store := NewStore(tasks.NewMemoryStore(), b, fastConfig())
+
+	// The broker holds the events while the task is requeued, retried and fails
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	task.UpdateState(protocol.TaskStateRunning)
+	task.Attempts = 1
+	require.NoError(t, store.Update(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, WorkerID: "worker-1"})
+	task.UpdateState(protocol.TaskStatePending)
+	require.NoError(t, store.Update(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStatePending})
+
+	task.UpdateState(protocol.TaskStateRunning)
+	task.Attempts = 2
+	require.NoError(t, store.Update(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, WorkerID: "worker-2"})
+	task.SetError("upstream timeout")
+	require.NoError(t, store.Update(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateFailed,
+		Timestamp: task.CreatedAt.Add(2 * time.Second)})
+
+	close(b.gate)
+	require.NoError(t, store.Close())
+	published := b.get()
+	require.Len(t, published, 4)
+	assert.Equal(t, []string{TypeCreated, TypeStarted, TypeStarted, TypeFailed},
+		[]string{published[0].Type, published[1].Type, published[2].Type, published[3].Type})
+	assert.Equal(t, []int{0, 1, 2, 2},
+		[]int{published[0].Attempts, published[1].Attempts, published[2].Attempts, published[3].Attempts})
+	failed := published[3]
+	assert.Equal(t, "upstream timeout", failed.Error)
+	assert.EqualValues(t, 2000, failed.DurationMs)
+}
+
+func TestStore_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	b := &broker{failures: 10}
+	metrics := &outcomes{}
+	store := NewStore(tasks.NewMemoryStore(), b, fastConfig())
+	store.SetMetrics(metrics)
+
+	require.NoError(t, store.Create(ctx, protocol.NewTask("agent-1", "search", nil)))
+	require.NoError(t, store.Close())
+
+	assert.Empty(t, b.get())
+	assert.Equal(t, 7, b.failures)
+	assert.Equal(t, []string{"task.created:failed"}, metrics.get())
+
+	// Events after Close are dropped
+	require.NoError(t, store.Create(ctx, protocol.NewTask("agent-1", "search", nil)))
+	assert.Equal(t, []string{"task.created:failed", "task.created:dropped"}, metrics.get())
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var body []byte
+	var contentType, path string
+	status, response := http.StatusOK, `{"offsets":[{"partition":0,"offset":7}]}`
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType, path = r.Header.Get("Content-Type"), r.URL.Path
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer proxy.Close()
+
+	config := fastConfig()
+	config.URL = proxy.URL + "/"
+	config.Topic = "a2a-tasks"
+	publisher := NewKafkaPublisher(config)
+	defer publisher.Close()
+
+	event := newEvent(TypeCompleted, time.Now())
+	event.TaskID = "task-1"
+	encoded, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), event, encoded))
+	assert.Equal(t, "/topics/a2a-tasks", path)
+	assert.Equal(t, kafkaContentType, contentType)
+	var request struct {
+		Records []struct {
+			Key   string `json:"key"`
+			Value Event  `json:"value"`
+		} `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(body, &request))
+	require.Len(t, request.Records, 1)
+	assert.Equal(t, "task-1", request.Records[0].Key)
+	assert.Equal(t, event.ID, request.Records[0].Value.ID)
+
+	// Records rejected by Kafka are reported even though the request succeeded
+	response = `{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"timeout"}]}`
+	assert.ErrorContains(t, publisher.Publish(context.Background(), event, encoded), "50003")
+
+	status, response = http.StatusNotFound, `{"error_code":40401,"message":"Topic not found"}`
+	assert.ErrorContains(t, publisher.Publish(context.Background(), event, encoded), "404")
+}
+
+func TestNewPublisher_InvalidConfig(t *testing.T) {
+	_, err := NewPublisher(context.Background(), Config{Backend: BackendKafka})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = NewPublisher(context.Background(), Config{Backend: "rabbitmq", URL: "amqp://localhost"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestSubject(t *testing.T) {
+	assert.Equal(t, "a2a.tasks.completed", Subject("a2a.tasks", TypeCompleted))
+	assert.Equal(t, "a2a.tasks.input_required", Subject("a2a.tasks", TypeInputRequired))
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// kafkaContentType is the Kafka REST Proxy v2 content type for JSON records
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaPublisher publishes events to a Kafka topic through the Confluent REST Proxy,
+// keyed by task ID so each task's events stay in order on one partition
+type KafkaPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaPublisher publishes to config.Topic through the REST Proxy at config.URL
+func NewKafkaPublisher(config Config) *KafkaPublisher {
+	config = config.withDefaults()
+	return &KafkaPublisher{
+		endpoint: strings.TrimRight(config.URL, "/") + "/topics/" + url.PathEscape(config.Topic),
+		client:   &http.Client{Timeout: config.Timeout},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces an event as one record
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event, body []byte) error {
+	payload, err := json.Marshal(kafkaRequest{Records: []kafkaRecord{{Key: event.TaskID, Value: body}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	// Records are accepted per partition; a failed write is reported in its offset
+	var produced kafkaResponse
+	if err := json.Unmarshal(data, &produced); err != nil {
+		return fmt.Errorf("failed to decode kafka rest proxy response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rejected record: %d %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections
+func (p *KafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSPublisher publishes events to a JetStream stream, one subject per event type
+type NATSPublisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	prefix string
+}
+
+// NewNATSPublisher connects to the NATS server at config.URL and creates the stream
+// config.Stream, covering every subject under config.Topic, if it does not exist
+func NewNATSPublisher(ctx context.Context, config Config) (*NATSPublisher, error) {
+	config = config.withDefaults()
+	conn, err := nats.Connect(config.URL, nats.Name("a2a-server"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     config.Stream,
+		Subjects: []string{config.Topic + ".>"},
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", config.Stream, err)
+	}
+	return &NATSPublisher{conn: conn, js: js, prefix: config.Topic}, nil
+}
+
+// Subject returns the subject events of a type are published to
+func Subject(prefix, eventType string) string {
+	return prefix + "." + strings.TrimPrefix(eventType, "task.")
+}
+
+// Publish publishes an event and waits for the stream to store it; the event ID is the
+// message ID, so JetStream drops the copies a retry may send
+func (p *NATSPublisher) Publish(ctx context.Context, event Event, body []byte) error {
+	_, err := p.js.Publish(ctx, Subject(p.prefix, event.Type), body, jetstream.WithMsgID(event.ID))
+	return err
+}
+
+// Close flushes pending messages and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,26 @@
+// Package migrations versions the A2A server's Postgres tables (tasks, usage_records)
+// with the shared migration runner. Its migrations are embedded from the sql directory
+// and recorded in the a2a_schema_migrations table.
+//
+// The MCP server versions the rest of the shared database with its own version table.
+package migrations
+
+import (
+	"embed"
+
+	sharedmigrations "github.com/bhatti/mcp-a2a-go/shared/migrations"
+)
+
+// VersionTable records the applied migrations
+const VersionTable = "a2a_schema_migrations"
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Migrator applies and reverts migrations on one database
+type Migrator = sharedmigrations.Migrator
+
+// New returns a migrator for the embedded migrations
+func New(conn sharedmigrations.Conn) (*Migrator, error) {
+	return sharedmigrations.New(conn, VersionTable, embedded)
+}
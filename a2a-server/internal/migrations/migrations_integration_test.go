@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests for the migrator; they need the schema owner, not app_user
+// Run with: go test -tags=integration -v ./internal/migrations/
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+	connString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		getEnvOrDefault("DB_HOST", "localhost"), getEnvOrDefault("DB_PORT", "5432"),
+		getEnvOrDefault("DB_MIGRATION_USER", "mcp_user"), getEnvOrDefault("DB_PASSWORD", "mcp_password"),
+		getEnvOrDefault("DB_NAME", "mcp_db"))
+	conn, err := pgx.Connect(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(context.Background()) })
+	return conn
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestMigrator_Embedded(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(connect(t))
+	require.NoError(t, err)
+
+	// The baseline adopts a database created from init-db.sql, and running again is a no-op
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, status.Name)
+	}
+}
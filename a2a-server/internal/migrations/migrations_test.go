@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"testing"
+
+	sharedmigrations "github.com/bhatti/mcp-a2a-go/shared/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := sharedmigrations.Load(embedded)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	// Versions are contiguous from 1, so a missing file is caught before release
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, m.Name)
+		assert.NotEmpty(t, m.Up)
+	}
+	assert.Equal(t, "initial_schema", migrations[0].Name)
+	assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS tasks")
+	assert.NotContains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS documents", "documents belong to the MCP server's migrations")
+
+	m, err := New(nil)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), m.Latest())
+}
@@ -0,0 +1,63 @@
+-- Baseline schema of the A2A server's Postgres task store and cost tracker, matching
+-- scripts/init-db.sql. Every statement is idempotent so databases created from init-db.sql
+-- (brought up to date with the scripts/apply-a2a-*.sql scripts) adopt it without changes.
+
+-- Tasks of the A2A server when it runs with TASK_STORE=postgres. Not tenant scoped:
+-- the A2A server authorizes by user and agent itself.
+CREATE TABLE IF NOT EXISTS tasks (
+    id VARCHAR(64) PRIMARY KEY,
+    agent_id VARCHAR(255) NOT NULL,
+    context_id VARCHAR(255) NOT NULL DEFAULT '',
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    capability VARCHAR(255) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    input JSONB,
+    result JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    input_hash VARCHAR(100) NOT NULL DEFAULT '',
+    result_hash VARCHAR(100) NOT NULL DEFAULT '',
+    speculative BOOLEAN NOT NULL DEFAULT false,
+    speculation JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    trace_context JSONB,
+    priority VARCHAR(10) NOT NULL DEFAULT 'normal',
+    depends_on TEXT[],
+    max_cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    artifacts JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_state ON tasks(state, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_pending_priority ON tasks(priority, created_at) WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS idx_tasks_user ON tasks(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_agent ON tasks(agent_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_depends_on ON tasks USING GIN (depends_on);
+
+-- Usage records of the A2A server's cost tracker when it runs with COST_STORE=postgres
+CREATE TABLE IF NOT EXISTS usage_records (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    task_id VARCHAR(64) NOT NULL DEFAULT '',
+    capability VARCHAR(255) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    price_version VARCHAR(100) NOT NULL DEFAULT '',
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_user ON usage_records(user_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_recorded_at ON usage_records(recorded_at);
+
+-- The server connects as app_user where it exists
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'app_user') THEN
+        GRANT ALL PRIVILEGES ON tasks, usage_records TO app_user;
+        GRANT USAGE, SELECT ON SEQUENCE usage_records_id_seq TO app_user;
+    END IF;
+END
+$$;
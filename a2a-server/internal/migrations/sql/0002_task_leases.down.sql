@@ -0,0 +1 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS lease, DROP COLUMN IF EXISTS attempts;
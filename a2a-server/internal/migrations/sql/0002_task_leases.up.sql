@@ -0,0 +1,5 @@
+-- Leases of running tasks: the processor executing a task renews its lease while the task
+-- runs, and tasks whose lease expired, e.g. because their processor crashed, are
+-- requeued or failed. attempts counts the executions started.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS lease JSONB;
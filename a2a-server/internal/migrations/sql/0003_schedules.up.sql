@@ -0,0 +1,35 @@
+-- Schedules of recurring tasks: each creates a task for its user on a cron expression or
+-- RRULE. next_run_at is NULL once a schedule has no more runs; runners claim a due run by
+-- moving it, so each run is materialized by one replica.
+CREATE TABLE IF NOT EXISTS schedules (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    agent_id VARCHAR(255) NOT NULL,
+    capability VARCHAR(255) NOT NULL,
+    input JSONB,
+    priority VARCHAR(10) NOT NULL DEFAULT '',
+    max_cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    cron VARCHAR(255) NOT NULL DEFAULT '',
+    rrule VARCHAR(1024) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    paused BOOLEAN NOT NULL DEFAULT false,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_task_id VARCHAR(64) NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    runs INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(next_run_at) WHERE NOT paused;
+CREATE INDEX IF NOT EXISTS idx_schedules_user ON schedules(user_id, created_at);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'app_user') THEN
+        GRANT ALL PRIVILEGES ON schedules TO app_user;
+    END IF;
+END
+$$;
@@ -0,0 +1,6 @@
+-- Task retention: terminal tasks are garbage collected once their ttl_seconds, or the
+-- server's retention when it is zero, elapsed after they finished.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS ttl_seconds INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_finished ON tasks(COALESCE(completed_at, updated_at), id)
+    WHERE state IN ('completed', 'failed', 'cancelled');
@@ -0,0 +1,3 @@
+-- Listing every task newest or oldest first, and paging through them from a cursor on
+-- (created_at, id); filtered lists use the agent, user and state indexes
+CREATE INDEX IF NOT EXISTS idx_tasks_created ON tasks(created_at, id);
@@ -0,0 +1,22 @@
+-- History of every task's state transitions, written by the same publisher that feeds
+-- its event streams. Entries are deleted with their task.
+CREATE TABLE IF NOT EXISTS task_history (
+    id BIGSERIAL PRIMARY KEY,
+    task_id VARCHAR(64) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    state VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    worker_id VARCHAR(255) NOT NULL DEFAULT '',
+    data JSONB,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_history_task ON task_history(task_id, id);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'app_user') THEN
+        GRANT ALL PRIVILEGES ON task_history TO app_user;
+        GRANT USAGE, SELECT ON SEQUENCE task_history_id_seq TO app_user;
+    END IF;
+END
+$$;
@@ -0,0 +1,3 @@
+-- Versioned capabilities: the version of its capability a task runs, fixed when it is
+-- created; empty for unversioned capabilities.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS capability_version VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Task messages: the A2A message a task was requested with and the agent message it
+-- completed with. The input and result columns keep the maps converted from them.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS message JSONB;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS output JSONB;
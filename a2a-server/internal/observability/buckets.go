@@ -0,0 +1,22 @@
+package observability
+
+// Default bucket boundaries in milliseconds. The SDK default tops out its fine buckets at
+// 5ms and 10ms, while A2A requests take milliseconds and tasks up to minutes.
+var (
+	// RequestDurationBuckets cover A2A requests, from task lookups to task creation
+	RequestDurationBuckets = []float64{0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+	// CapabilityDurationBuckets cover capability executions, up to the capability timeout
+	CapabilityDurationBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+	// TaskDurationBuckets cover tasks from creation to completion, including queueing and retries
+	TaskDurationBuckets = []float64{10, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 120000, 300000}
+)
+
+// DefaultHistogramBuckets returns the bucket boundaries of each duration histogram
+func DefaultHistogramBuckets() map[string][]float64 {
+	return map[string][]float64{
+		"a2a.request.duration":              RequestDurationBuckets,
+		"a2a.capability.execution.duration": CapabilityDurationBuckets,
+		"a2a.task.duration":                 TaskDurationBuckets,
+		"a2a.webhook.delivery.duration":     TaskDurationBuckets,
+	}
+}
@@ -35,6 +35,31 @@ type Metrics struct {
 	CapabilityExecutionCount    metric.Int64Counter
 	CapabilityExecutionDuration metric.Float64Histogram
 
+	// Shutdown metrics
+	DrainCancelled metric.Int64Counter
+
+	// Webhook metrics
+	WebhookDeliveryCount    metric.Int64Counter
+	WebhookDeliveryAttempts metric.Int64Histogram
+	WebhookDeliveryDuration metric.Float64Histogram
+
+	// Event publishing metrics
+	EventsPublished metric.Int64Counter
+
+	// Task admission metrics
+	TaskQueueWait  metric.Float64Histogram
+	TaskRejections metric.Int64Counter
+
+	// Task lease metrics
+	TaskLeaseReleases metric.Int64Counter
+	StuckTasks        metric.Int64Gauge
+
+	// Schedule metrics
+	ScheduleRuns metric.Int64Counter
+
+	// Task retention metrics
+	TasksReclaimed metric.Int64Counter
+
 	// Error metrics
 	ErrorCount metric.Int64Counter
 }
@@ -184,6 +209,112 @@ func NewMetrics(meter metric.Meter) (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create capability execution duration metric: %w", err)
 	}
 
+	// Shutdown metrics
+	m.DrainCancelled, err = meter.Int64Counter(
+		"a2a.shutdown.cancelled",
+		metric.WithDescription("In-flight work forcibly cancelled when the shutdown drain period expired"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create drain cancelled metric: %w", err)
+	}
+
+	// Webhook metrics
+	m.WebhookDeliveryCount, err = meter.Int64Counter(
+		"a2a.webhook.delivery.count",
+		metric.WithDescription("Push notification deliveries by outcome"),
+		metric.WithUnit("{delivery}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery count metric: %w", err)
+	}
+
+	m.WebhookDeliveryAttempts, err = meter.Int64Histogram(
+		"a2a.webhook.delivery.attempts",
+		metric.WithDescription("Attempts made per push notification delivery"),
+		metric.WithUnit("{attempt}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery attempts metric: %w", err)
+	}
+
+	m.WebhookDeliveryDuration, err = meter.Float64Histogram(
+		"a2a.webhook.delivery.duration",
+		metric.WithDescription("Time from the first attempt to the outcome of a push notification delivery, including retries"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery duration metric: %w", err)
+	}
+
+	// Event publishing metrics
+	m.EventsPublished, err = meter.Int64Counter(
+		"a2a.events.published",
+		metric.WithDescription("Task lifecycle events sent to the event broker, by type and outcome"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create events published metric: %w", err)
+	}
+
+	// Task admission metrics
+	m.TaskQueueWait, err = meter.Float64Histogram(
+		"a2a.task.queue.wait",
+		metric.WithDescription("Time tasks waited to start since they became pending, in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task queue wait metric: %w", err)
+	}
+
+	m.TaskRejections, err = meter.Int64Counter(
+		"a2a.task.rejected",
+		metric.WithDescription("Tasks refused at creation because the backlog was full"),
+		metric.WithUnit("{task}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task rejected metric: %w", err)
+	}
+
+	// Task lease metrics
+	m.TaskLeaseReleases, err = meter.Int64Counter(
+		"a2a.task.lease.released",
+		metric.WithDescription("Running tasks taken from their processor, by trigger (expired or admin) and outcome (requeued or failed)"),
+		metric.WithUnit("{task}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task lease released metric: %w", err)
+	}
+
+	m.StuckTasks, err = meter.Int64Gauge(
+		"a2a.task.stuck",
+		metric.WithDescription("Running tasks whose lease had expired at the last check"),
+		metric.WithUnit("{task}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stuck tasks metric: %w", err)
+	}
+
+	// Schedule metrics
+	m.ScheduleRuns, err = meter.Int64Counter(
+		"a2a.schedule.runs",
+		metric.WithDescription("Due schedule runs, by outcome (created, budget_exceeded or failed)"),
+		metric.WithUnit("{run}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schedule runs metric: %w", err)
+	}
+
+	// Task retention metrics
+	m.TasksReclaimed, err = meter.Int64Counter(
+		"a2a.task.reclaimed",
+		metric.WithDescription("Finished tasks garbage collected once their retention elapsed, by state and whether they were archived"),
+		metric.WithUnit("{task}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reclaimed tasks metric: %w", err)
+	}
+
 	// Error metrics
 	m.ErrorCount, err = meter.Int64Counter(
 		"a2a.error.count",
@@ -252,6 +383,11 @@ func (m *Metrics) RecordBudgetRemaining(ctx context.Context, tier string, remain
 	m.BudgetRemaining.Record(ctx, remaining, attrs)
 }
 
+// RecordTaskQueueDepth records tasks entering (delta > 0) or leaving the dispatch queue
+func (m *Metrics) RecordTaskQueueDepth(ctx context.Context, priority string, delta int64) {
+	m.TaskQueueDepth.Add(ctx, delta, metric.WithAttributes(attribute.String("priority", priority)))
+}
+
 // RecordSSEConnection records SSE connection metrics
 func (m *Metrics) RecordSSEConnection(ctx context.Context, delta int64) {
 	m.SSEConnections.Add(ctx, delta)
@@ -266,6 +402,75 @@ func (m *Metrics) RecordSSEEvent(ctx context.Context, eventType string) {
 	m.SSEEventsSent.Add(ctx, 1, attrs)
 }
 
+// RecordDrainCancelled records in-flight work of a kind cancelled at shutdown
+func (m *Metrics) RecordDrainCancelled(ctx context.Context, kind string, count int64) {
+	m.DrainCancelled.Add(ctx, count, metric.WithAttributes(
+		attribute.String("kind", kind),
+	))
+}
+
+// RecordWebhookDelivery records the outcome of a push notification delivery
+func (m *Metrics) RecordWebhookDelivery(ctx context.Context, status string, attempts int, durationMs float64) {
+	attrs := metric.WithAttributes(
+		attribute.String("status", status),
+	)
+
+	m.WebhookDeliveryCount.Add(ctx, 1, attrs)
+	m.WebhookDeliveryAttempts.Record(ctx, int64(attempts), attrs)
+	m.WebhookDeliveryDuration.Record(ctx, durationMs, attrs)
+}
+
+// RecordEventPublish records the outcome of publishing a task lifecycle event
+func (m *Metrics) RecordEventPublish(ctx context.Context, eventType, status string) {
+	m.EventsPublished.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("type", eventType),
+		attribute.String("status", status),
+	))
+}
+
+// RecordTaskQueueWait records how long a task waited before it started
+func (m *Metrics) RecordTaskQueueWait(ctx context.Context, priority string, capability string, waitMs float64) {
+	m.TaskQueueWait.Record(ctx, waitMs, metric.WithAttributes(
+		attribute.String("priority", priority),
+		attribute.String("capability", capability),
+	))
+}
+
+// RecordTaskRejected records a task refused at creation
+func (m *Metrics) RecordTaskRejected(ctx context.Context, reason string) {
+	m.TaskRejections.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("reason", reason),
+	))
+}
+
+// RecordTaskLeaseReleased records a running task taken from its processor
+func (m *Metrics) RecordTaskLeaseReleased(ctx context.Context, trigger string, outcome string) {
+	m.TaskLeaseReleases.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("trigger", trigger),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// RecordStuckTasks records how many running tasks had an expired lease
+func (m *Metrics) RecordStuckTasks(ctx context.Context, count int64) {
+	m.StuckTasks.Record(ctx, count)
+}
+
+// RecordScheduleRun records a due schedule run and whether its task was created
+func (m *Metrics) RecordScheduleRun(ctx context.Context, outcome string) {
+	m.ScheduleRuns.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("outcome", outcome),
+	))
+}
+
+// RecordTaskReclaimed records a finished task garbage collected from the task store
+func (m *Metrics) RecordTaskReclaimed(ctx context.Context, state string, archived bool) {
+	m.TasksReclaimed.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("state", state),
+		attribute.Bool("archived", archived),
+	))
+}
+
 // RecordError records an error occurrence
 func (m *Metrics) RecordError(ctx context.Context, errorType string, operation string) {
 	attrs := metric.WithAttributes(
@@ -0,0 +1,329 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kinds of A2A objects, carried in their "kind" field
+const (
+	KindTask    = "task"
+	KindMessage = "message"
+	KindText    = "text"
+	KindData    = "data"
+	KindFile    = "file"
+
+	KindStatusUpdate   = "status-update"
+	KindArtifactUpdate = "artifact-update"
+)
+
+// A2A message roles
+const (
+	RoleUser  = "user"
+	RoleAgent = "agent"
+)
+
+// A2A task states, as reported on the JSON-RPC endpoint
+const (
+	A2AStateSubmitted = "submitted"
+	A2AStateWorking   = "working"
+	A2AStateCompleted = "completed"
+	A2AStateFailed    = "failed"
+	A2AStateCanceled  = "canceled"
+	A2AStateUnknown   = "unknown"
+	// A2AStateInputRequired is a task waiting for the user to answer its status message
+	A2AStateInputRequired = "input-required"
+)
+
+// Part is one piece of A2A message or artifact content; Kind selects the populated field
+type Part struct {
+	Kind string                 `json:"kind"`
+	Text string                 `json:"text,omitempty"`
+	Data map[string]interface{} `json:"data,omitempty"`
+	File *FileContent           `json:"file,omitempty"`
+}
+
+// FileContent is the content of a file part: inline as Bytes, or at URI when it is too
+// large to inline
+type FileContent struct {
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Bytes    []byte `json:"bytes,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+// Validate checks the part has the content its kind calls for
+func (p Part) Validate() error {
+	switch p.Kind {
+	case KindText, KindData:
+		if p.File != nil {
+			return fmt.Errorf("%s part must not have a file", p.Kind)
+		}
+	case KindFile:
+		if p.File == nil {
+			return errors.New("file part requires a file")
+		}
+		if (p.File.Bytes == nil) == (p.File.URI == "") {
+			return errors.New("file part requires exactly one of bytes and uri")
+		}
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedKind, p.Kind)
+	}
+	return nil
+}
+
+// MimeType returns the media type of the part's content
+func (p Part) MimeType() string {
+	switch {
+	case p.Kind == KindData:
+		return "application/json"
+	case p.Kind == KindFile && p.File.MimeType != "":
+		return p.File.MimeType
+	case p.Kind == KindFile:
+		return "application/octet-stream"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Message is an A2A message exchanged between a client and the agent
+type Message struct {
+	Kind      string                 `json:"kind"`
+	Role      string                 `json:"role"`
+	Parts     []Part                 `json:"parts"`
+	MessageID string                 `json:"messageId"`
+	TaskID    string                 `json:"taskId,omitempty"`
+	ContextID string                 `json:"contextId,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MessageSendParams are the params of message/send
+type MessageSendParams struct {
+	Message       Message                   `json:"message"`
+	Configuration *MessageSendConfiguration `json:"configuration,omitempty"`
+	Metadata      map[string]interface{}    `json:"metadata,omitempty"`
+}
+
+// MessageSendConfiguration configures how message/send runs the task
+type MessageSendConfiguration struct {
+	// PushNotificationConfig has the task's state transitions POSTed to a webhook
+	PushNotificationConfig *PushNotificationConfig `json:"pushNotificationConfig,omitempty"`
+}
+
+// PushNotificationConfig is a webhook that receives a task's state transitions and final result
+type PushNotificationConfig struct {
+	URL string `json:"url"`
+	// Token is echoed in the X-A2A-Notification-Token header so the receiver can match the task
+	Token string `json:"token,omitempty"`
+	// Secret signs each delivery with HMAC-SHA256 in the X-A2A-Signature header; it is
+	// never returned by the server
+	Secret string `json:"secret,omitempty"`
+}
+
+// TaskPushNotificationConfig is the params and result of tasks/pushNotificationConfig/set
+// and the result of tasks/pushNotificationConfig/get
+type TaskPushNotificationConfig struct {
+	TaskID                 string                 `json:"taskId"`
+	PushNotificationConfig PushNotificationConfig `json:"pushNotificationConfig"`
+}
+
+// Validate checks the fields the server relies on
+func (p *MessageSendParams) Validate() error {
+	if p.Message.Role != RoleUser {
+		return fmt.Errorf("message.role must be %q", RoleUser)
+	}
+	if err := p.Message.Validate(); err != nil {
+		return fmt.Errorf("message.%w", err)
+	}
+	return nil
+}
+
+// TaskQueryParams are the params of tasks/get
+type TaskQueryParams struct {
+	ID            string `json:"id"`
+	HistoryLength int    `json:"historyLength,omitempty"`
+}
+
+// TaskIDParams are the params of tasks/cancel
+type TaskIDParams struct {
+	ID string `json:"id"`
+}
+
+// A2ATaskStatus is the status of an A2A task
+type A2ATaskStatus struct {
+	State     string   `json:"state"`
+	Message   *Message `json:"message,omitempty"`
+	Timestamp string   `json:"timestamp"`
+}
+
+// Artifact is an output produced by an A2A task
+type Artifact struct {
+	ArtifactID  string `json:"artifactId"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Parts       []Part `json:"parts"`
+}
+
+// Validate checks the artifact is identified and each of its parts is valid
+func (a Artifact) Validate() error {
+	if a.ArtifactID == "" {
+		return errors.New("artifactId is required")
+	}
+	if len(a.Parts) == 0 {
+		return fmt.Errorf("artifact %s has no parts", a.ArtifactID)
+	}
+	for i, part := range a.Parts {
+		if err := part.Validate(); err != nil {
+			return fmt.Errorf("artifact %s part %d: %w", a.ArtifactID, i, err)
+		}
+	}
+	return nil
+}
+
+// A2ATask is a task as represented on the A2A JSON-RPC endpoint
+type A2ATask struct {
+	Kind      string                 `json:"kind"`
+	ID        string                 `json:"id"`
+	ContextID string                 `json:"contextId"`
+	Status    A2ATaskStatus          `json:"status"`
+	Artifacts []Artifact             `json:"artifacts,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// History is the task's messages, oldest first; only tasks/get returns it
+	History []Message `json:"history,omitempty"`
+}
+
+// TaskStatusUpdateEvent reports a task state change on a stream; Final marks the last event
+type TaskStatusUpdateEvent struct {
+	Kind      string                 `json:"kind"`
+	TaskID    string                 `json:"taskId"`
+	ContextID string                 `json:"contextId"`
+	Status    A2ATaskStatus          `json:"status"`
+	Final     bool                   `json:"final"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskArtifactUpdateEvent carries an artifact, or a chunk of one, on a stream
+type TaskArtifactUpdateEvent struct {
+	Kind      string   `json:"kind"`
+	TaskID    string   `json:"taskId"`
+	ContextID string   `json:"contextId"`
+	Artifact  Artifact `json:"artifact"`
+	Append    bool     `json:"append,omitempty"`
+	LastChunk bool     `json:"lastChunk,omitempty"`
+}
+
+// A2AState maps a task state to its A2A name
+func A2AState(state TaskState) string {
+	switch state {
+	case TaskStatePending:
+		return A2AStateSubmitted
+	case TaskStateRunning:
+		return A2AStateWorking
+	case TaskStateCompleted:
+		return A2AStateCompleted
+	case TaskStateFailed:
+		return A2AStateFailed
+	case TaskStateCancelled:
+		return A2AStateCanceled
+	case TaskStateInputRequired:
+		return A2AStateInputRequired
+	default:
+		return A2AStateUnknown
+	}
+}
+
+// ToA2AEvent converts a task event to a TaskStatusUpdateEvent or TaskArtifactUpdateEvent
+func ToA2AEvent(task *Task, event TaskEvent) interface{} {
+	contextID := a2aContextID(task)
+	if event.Artifact != nil {
+		return TaskArtifactUpdateEvent{
+			Kind:      KindArtifactUpdate,
+			TaskID:    event.TaskID,
+			ContextID: contextID,
+			Artifact:  *event.Artifact,
+			Append:    event.Append,
+			LastChunk: event.LastChunk,
+		}
+	}
+
+	update := TaskStatusUpdateEvent{
+		Kind:      KindStatusUpdate,
+		TaskID:    event.TaskID,
+		ContextID: contextID,
+		Status: A2ATaskStatus{
+			State:     A2AState(event.State),
+			Timestamp: event.Timestamp.UTC().Format(time.RFC3339Nano),
+		},
+		Final:    event.Final(),
+		Metadata: event.Data,
+	}
+	if event.Message != "" {
+		update.Status.Message = &Message{
+			Kind:      KindMessage,
+			Role:      RoleAgent,
+			Parts:     []Part{{Kind: KindText, Text: event.Message}},
+			MessageID: fmt.Sprintf("%s-event-%d", event.TaskID, event.ID),
+			TaskID:    event.TaskID,
+			ContextID: contextID,
+		}
+	}
+	return update
+}
+
+// a2aContextID returns the task's context, defaulting to the task ID
+func a2aContextID(task *Task) string {
+	if task.ContextID == "" {
+		return task.ID
+	}
+	return task.ContextID
+}
+
+// ToA2ATask converts a task to its A2A representation. The result becomes a data
+// artifact ahead of the task's own artifacts and a failure or cancellation reason, or the
+// question of a task waiting for input, becomes the status message.
+func ToA2ATask(task *Task) A2ATask {
+	a2aTask := A2ATask{
+		Kind:      KindTask,
+		ID:        task.ID,
+		ContextID: a2aContextID(task),
+		Status: A2ATaskStatus{
+			State:     A2AState(task.State),
+			Timestamp: task.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		},
+		Metadata: map[string]interface{}{
+			"agent_id":   task.AgentID,
+			"capability": task.Capability,
+		},
+	}
+	if task.CapabilityVersion != "" {
+		a2aTask.Metadata["capability_version"] = task.CapabilityVersion
+	}
+	if task.Speculation != nil {
+		a2aTask.Metadata["speculation"] = task.Speculation
+	}
+
+	text := task.Error
+	if prompt := task.InputPrompt(); prompt != "" {
+		text = prompt
+	}
+	if text != "" {
+		a2aTask.Status.Message = &Message{
+			Kind:      KindMessage,
+			Role:      RoleAgent,
+			Parts:     []Part{{Kind: KindText, Text: text}},
+			MessageID: task.ID + "-status",
+			TaskID:    task.ID,
+			ContextID: a2aTask.ContextID,
+		}
+	}
+	if task.Result != nil {
+		a2aTask.Artifacts = []Artifact{{
+			ArtifactID: task.ID + "-result",
+			Name:       "result",
+			Parts:      []Part{{Kind: KindData, Data: task.Result}},
+		}}
+	}
+	a2aTask.Artifacts = append(a2aTask.Artifacts, task.Artifacts...)
+	return a2aTask
+}
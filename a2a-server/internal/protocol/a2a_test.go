@@ -0,0 +1,136 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestA2AState(t *testing.T) {
+	tests := []struct {
+		state TaskState
+		want  string
+	}{
+		{TaskStatePending, A2AStateSubmitted},
+		{TaskStateRunning, A2AStateWorking},
+		{TaskStateCompleted, A2AStateCompleted},
+		{TaskStateFailed, A2AStateFailed},
+		{TaskStateCancelled, A2AStateCanceled},
+		{TaskState("paused"), A2AStateUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			assert.Equal(t, tt.want, A2AState(tt.state))
+		})
+	}
+}
+
+func TestMessageSendParams_Validate(t *testing.T) {
+	valid := MessageSendParams{Message: Message{
+		Role:      RoleUser,
+		MessageID: "msg-1",
+		Parts:     []Part{{Kind: KindText, Text: "hi"}},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	noID := valid
+	noID.Message.MessageID = ""
+	assert.Error(t, noID.Validate())
+
+	agentRole := valid
+	agentRole.Message.Role = RoleAgent
+	assert.Error(t, agentRole.Validate())
+
+	noParts := valid
+	noParts.Message.Parts = nil
+	assert.Error(t, noParts.Validate())
+}
+
+func TestToA2ATask(t *testing.T) {
+	task := NewTask("agent-1", "search_papers", nil)
+	task.ContextID = "ctx-1"
+	task.SetResult(map[string]interface{}{"status": "success"})
+
+	a2aTask := ToA2ATask(task)
+	assert.Equal(t, KindTask, a2aTask.Kind)
+	assert.Equal(t, task.ID, a2aTask.ID)
+	assert.Equal(t, "ctx-1", a2aTask.ContextID)
+	assert.Equal(t, A2AStateCompleted, a2aTask.Status.State)
+	assert.Nil(t, a2aTask.Status.Message)
+	require.Len(t, a2aTask.Artifacts, 1)
+	assert.Equal(t, "success", a2aTask.Artifacts[0].Parts[0].Data["status"])
+	assert.Equal(t, "search_papers", a2aTask.Metadata["capability"])
+
+	data, err := json.Marshal(a2aTask)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"contextId":"ctx-1"`)
+	assert.Contains(t, string(data), `"kind":"task"`)
+}
+
+func TestToA2ATask_Failed(t *testing.T) {
+	task := NewTask("agent-1", "search_papers", nil)
+	task.SetError("boom")
+
+	a2aTask := ToA2ATask(task)
+	assert.Equal(t, task.ID, a2aTask.ContextID)
+	assert.Equal(t, A2AStateFailed, a2aTask.Status.State)
+	require.NotNil(t, a2aTask.Status.Message)
+	assert.Equal(t, RoleAgent, a2aTask.Status.Message.Role)
+	assert.Equal(t, "boom", a2aTask.Status.Message.Parts[0].Text)
+	assert.Empty(t, a2aTask.Artifacts)
+}
+
+func TestToA2ATask_Artifacts(t *testing.T) {
+	task := NewTask("agent-1", "render", nil)
+	task.Artifacts = []Artifact{{ArtifactID: "chart", Parts: []Part{
+		{Kind: KindFile, File: &FileContent{Name: "chart.png", MimeType: "image/png", Bytes: []byte{0x89, 'P', 'N', 'G'}}},
+	}}}
+	task.SetResult(map[string]interface{}{"status": "success"})
+
+	a2aTask := ToA2ATask(task)
+	require.Len(t, a2aTask.Artifacts, 2)
+	assert.Equal(t, task.ID+"-result", a2aTask.Artifacts[0].ArtifactID)
+	assert.Equal(t, "chart", a2aTask.Artifacts[1].ArtifactID)
+
+	data, err := json.Marshal(a2aTask.Artifacts[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"artifactId":"chart","parts":[{"kind":"file","file":{"name":"chart.png","mimeType":"image/png","bytes":"iVBORw=="}}]}`, string(data))
+}
+
+func TestArtifact_Validate(t *testing.T) {
+	file := func(content FileContent) Part { return Part{Kind: KindFile, File: &content} }
+	tests := []struct {
+		artifact Artifact
+		err      string
+	}{
+		{Artifact{ArtifactID: "a", Parts: []Part{{Kind: KindText, Text: "hi"}, file(FileContent{URI: "https://example.com/a.pdf"})}}, ""},
+		{Artifact{Parts: []Part{{Kind: KindText}}}, "artifactId is required"},
+		{Artifact{ArtifactID: "a"}, "artifact a has no parts"},
+		{Artifact{ArtifactID: "a", Parts: []Part{{Kind: "image"}}}, `artifact a part 0: unsupported kind "image"`},
+		{Artifact{ArtifactID: "a", Parts: []Part{file(FileContent{})}}, "artifact a part 0: file part requires exactly one of bytes and uri"},
+		{Artifact{ArtifactID: "a", Parts: []Part{file(FileContent{Bytes: []byte("x"), URI: "/x"})}}, "exactly one of bytes and uri"},
+		{Artifact{ArtifactID: "a", Parts: []Part{{Kind: KindData, File: &FileContent{}}}}, "data part must not have a file"},
+	}
+	for _, tt := range tests {
+		err := tt.artifact.Validate()
+		if tt.err == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.err)
+	}
+
+	assert.Equal(t, "application/json", Part{Kind: KindData}.MimeType())
+	assert.Equal(t, "image/png", file(FileContent{MimeType: "image/png"}).MimeType())
+	assert.Equal(t, "application/octet-stream", file(FileContent{}).MimeType())
+}
+
+func TestNewJSONRPCError_NullID(t *testing.T) {
+	data, err := json.Marshal(NewJSONRPCError(nil, ParseError, "Parse error", nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`, string(data))
+}
@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ConversationKey is the input key a multi-turn task keeps its conversation under: the
+// questions its executor asked and the user's answers, oldest first
+const ConversationKey = "conversation"
+
+// Roles of conversation turns, matching the A2A message roles
+const (
+	TurnAgent = RoleAgent
+	TurnUser  = RoleUser
+)
+
+// Turn is one message of a multi-turn task's conversation
+type Turn struct {
+	Role string                 `json:"role"`
+	Text string                 `json:"text,omitempty"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Conversation returns the turns kept in a task's input, oldest first
+func Conversation(input map[string]interface{}) []Turn {
+	value, ok := input[ConversationKey]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var turns []Turn
+	if err := json.Unmarshal(data, &turns); err != nil {
+		return nil
+	}
+	return turns
+}
+
+// addTurn appends a turn to the conversation in the task's input. The conversation is
+// stored as decoded JSON, the form it is read back from a database in, so the input
+// hashes the same wherever the task is kept.
+func (t *Task) addTurn(turn Turn) {
+	data, _ := json.Marshal(append(Conversation(t.Input), turn))
+	var conversation []interface{}
+	json.Unmarshal(data, &conversation)
+
+	input := make(map[string]interface{}, len(t.Input)+1)
+	for key, value := range t.Input {
+		input[key] = value
+	}
+	input[ConversationKey] = conversation
+	t.Input = input
+}
+
+// RequestInput pauses a running task until the user answers prompt
+func (t *Task) RequestInput(prompt string) {
+	t.addTurn(Turn{Role: TurnAgent, Text: prompt})
+	t.State = TaskStateInputRequired
+	t.UpdatedAt = time.Now()
+}
+
+// InputPrompt returns the question a task waiting for input asked, or "" when it is not
+// waiting
+func (t *Task) InputPrompt() string {
+	if t.State != TaskStateInputRequired {
+		return ""
+	}
+	turns := Conversation(t.Input)
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == TurnAgent {
+			return turns[i].Text
+		}
+	}
+	return ""
+}
+
+// Resume adds the user's answer to a task waiting for input and makes it pending again.
+// The answer's data is also merged into the input, so executors that only read their
+// input fields see it.
+func (t *Task) Resume(answer Turn) {
+	answer.Role = TurnUser
+	t.addTurn(answer)
+	for key, value := range answer.Data {
+		if key != ConversationKey {
+			t.Input[key] = value
+		}
+	}
+	t.State = TaskStatePending
+	t.UpdatedAt = time.Now()
+}
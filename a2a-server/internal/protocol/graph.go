@@ -0,0 +1,25 @@
+package protocol
+
+// DependencyResultsKey is the input key a task receives its dependencies' results under,
+// keyed by dependency task ID. It is set when the task starts, replacing any value the
+// creator supplied.
+const DependencyResultsKey = "dependency_results"
+
+// TaskGraph is the dependency graph around a task: the tasks it depends on, directly or
+// transitively, the tasks that depend on it, and the task itself
+type TaskGraph struct {
+	TaskID string `json:"task_id"`
+	// Nodes are ordered by creation time; edges are given by each node's depends_on
+	Nodes []TaskGraphNode `json:"nodes"`
+	// Truncated is set when the graph has more nodes than are returned
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TaskGraphNode is a task in a dependency graph
+type TaskGraphNode struct {
+	ID         string    `json:"id"`
+	Capability string    `json:"capability"`
+	State      TaskState `json:"state"`
+	DependsOn  []string  `json:"depends_on,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
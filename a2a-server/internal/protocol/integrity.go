@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// HashAlgorithm is the prefix used for task content hashes
+const HashAlgorithm = "sha256"
+
+// IntegrityError is returned when a task's stored hashes do not match its content
+type IntegrityError struct {
+	TaskID   string
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// Error implements the error interface
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("integrity check failed for task %s: %s hash mismatch (expected %s, got %s)",
+		e.TaskID, e.Field, e.Expected, e.Actual)
+}
+
+// HashPayload computes a content hash of a task payload.
+// encoding/json sorts map keys, so equal payloads always produce the same hash.
+// An empty payload hashes to the empty string.
+func HashPayload(payload map[string]interface{}) (string, error) {
+	if len(payload) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return HashAlgorithm + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// Seal computes and stores the input and result hashes of the task
+func (t *Task) Seal() error {
+	inputHash, err := HashPayload(t.Input)
+	if err != nil {
+		return fmt.Errorf("failed to hash task input: %w", err)
+	}
+
+	resultHash, err := HashPayload(t.Result)
+	if err != nil {
+		return fmt.Errorf("failed to hash task result: %w", err)
+	}
+
+	t.InputHash = inputHash
+	t.ResultHash = resultHash
+	return nil
+}
+
+// VerifyIntegrity recomputes the task hashes and compares them with the stored ones
+func (t *Task) VerifyIntegrity() error {
+	inputHash, err := HashPayload(t.Input)
+	if err != nil {
+		return fmt.Errorf("failed to hash task input: %w", err)
+	}
+	if inputHash != t.InputHash {
+		return &IntegrityError{TaskID: t.ID, Field: "input", Expected: t.InputHash, Actual: inputHash}
+	}
+
+	resultHash, err := HashPayload(t.Result)
+	if err != nil {
+		return fmt.Errorf("failed to hash task result: %w", err)
+	}
+	if resultHash != t.ResultHash {
+		return &IntegrityError{TaskID: t.ID, Field: "result", Expected: t.ResultHash, Actual: resultHash}
+	}
+
+	return nil
+}
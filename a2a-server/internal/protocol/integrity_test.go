@@ -0,0 +1,83 @@
+package protocol
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPayload(t *testing.T) {
+	h1, err := HashPayload(map[string]interface{}{"query": "test", "limit": 10})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(h1, HashAlgorithm+":"))
+
+	// Key order must not affect the hash
+	h2, err := HashPayload(map[string]interface{}{"limit": 10, "query": "test"})
+	require.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	h3, err := HashPayload(map[string]interface{}{"query": "other"})
+	require.NoError(t, err)
+	assert.NotEqual(t, h1, h3)
+
+	empty, err := HashPayload(nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestTask_SealAndVerify(t *testing.T) {
+	task := NewTask("agent-1", "search", map[string]interface{}{"query": "test"})
+	assert.NotEmpty(t, task.InputHash)
+	require.NoError(t, task.VerifyIntegrity())
+
+	task.SetResult(map[string]interface{}{"status": "success"})
+	assert.NotEmpty(t, task.ResultHash)
+	require.NoError(t, task.VerifyIntegrity())
+
+	require.NoError(t, task.Seal())
+	require.NoError(t, task.VerifyIntegrity())
+}
+
+func TestTask_VerifyIntegrity_Tampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(task *Task)
+		field  string
+	}{
+		{
+			name:   "input modified",
+			tamper: func(task *Task) { task.Input["query"] = "injected" },
+			field:  "input",
+		},
+		{
+			name:   "result modified",
+			tamper: func(task *Task) { task.Result["status"] = "forged" },
+			field:  "result",
+		},
+		{
+			name:   "result hash removed",
+			tamper: func(task *Task) { task.ResultHash = "" },
+			field:  "result",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := NewTask("agent-1", "search", map[string]interface{}{"query": "test"})
+			task.SetResult(map[string]interface{}{"status": "success"})
+
+			tt.tamper(task)
+
+			err := task.VerifyIntegrity()
+			require.Error(t, err)
+
+			var integrityErr *IntegrityError
+			require.True(t, errors.As(err, &integrityErr))
+			assert.Equal(t, task.ID, integrityErr.TaskID)
+			assert.Equal(t, tt.field, integrityErr.Field)
+		})
+	}
+}
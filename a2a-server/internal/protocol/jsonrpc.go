@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"encoding/json"
+
+	"github.com/bhatti/mcp-a2a-go/shared/jsonrpc"
+)
+
+// JSONRPCVersion is the only JSON-RPC version accepted on the A2A endpoint
+const JSONRPCVersion = jsonrpc.Version
+
+// A2A JSON-RPC method names
+const (
+	MethodMessageSend      = "message/send"
+	MethodMessageStream    = "message/stream"
+	MethodTasksGet         = "tasks/get"
+	MethodTasksCancel      = "tasks/cancel"
+	MethodTasksResubscribe = "tasks/resubscribe"
+	MethodPushConfigSet    = "tasks/pushNotificationConfig/set"
+	MethodPushConfigGet    = "tasks/pushNotificationConfig/get"
+)
+
+// Standard JSON-RPC error codes
+const (
+	ParseError     = jsonrpc.ParseError
+	InvalidRequest = jsonrpc.InvalidRequest
+	MethodNotFound = jsonrpc.MethodNotFound
+	InvalidParams  = jsonrpc.InvalidParams
+	InternalError  = jsonrpc.InternalError
+)
+
+// A2A error codes
+const (
+	TaskNotFound                 = -32001
+	TaskNotCancelable            = -32002
+	PushNotificationNotSupported = -32003
+	UnsupportedOperation         = -32004
+	ContentTypeNotSupported      = -32005
+)
+
+// Server-defined error codes
+const (
+	// BudgetExceeded is returned when the caller's budget cannot cover the task
+	BudgetExceeded = -32010
+	// RequestTooLarge is returned when the request body exceeds the server's size limit
+	RequestTooLarge = -32011
+	// ServerBusy is returned when the task backlog is full; the client should retry later
+	ServerBusy = -32012
+	// TaskNotAwaitingInput is returned when a message continues a task that is not waiting
+	// for input
+	TaskNotAwaitingInput = -32013
+)
+
+// JSONRPCRequest is a JSON-RPC 2.0 request
+type JSONRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCResponse is a JSON-RPC 2.0 response
+type JSONRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// JSONRPCError is a JSON-RPC 2.0 error object
+type JSONRPCError = jsonrpc.Error
+
+// NewJSONRPCResult creates a successful response
+func NewJSONRPCResult(id json.RawMessage, result interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: responseID(id), Result: result}
+}
+
+// NewJSONRPCError creates an error response
+func NewJSONRPCError(id json.RawMessage, code int, message string, data interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      responseID(id),
+		Error:   &JSONRPCError{Code: code, Message: message, Data: data},
+	}
+}
+
+// responseID echoes the request ID, or null when the request had none
+func responseID(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
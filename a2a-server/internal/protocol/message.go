@@ -0,0 +1,244 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxInlineFileBytes is the largest file a message may carry inline as bytes;
+// larger files are passed by URI
+const DefaultMaxInlineFileBytes = 1 << 20
+
+// Input keys a message's parts are converted to
+const (
+	// TextKey holds the message's text parts, joined by newlines
+	TextKey = "text"
+	// FilesKey holds the message's file parts, in order
+	FilesKey = "files"
+)
+
+// Errors returned validating and converting messages
+var (
+	ErrUnsupportedKind    = errors.New("unsupported kind")
+	ErrInlineFileTooLarge = errors.New("inline file too large")
+)
+
+// TextPart returns a text part
+func TextPart(text string) Part {
+	return Part{Kind: KindText, Text: text}
+}
+
+// DataPart returns a structured data part
+func DataPart(data map[string]interface{}) Part {
+	return Part{Kind: KindData, Data: data}
+}
+
+// FilePart returns a file part carrying content inline
+func FilePart(name, mimeType string, content []byte) Part {
+	if content == nil {
+		content = []byte{}
+	}
+	return Part{Kind: KindFile, File: &FileContent{Name: name, MimeType: mimeType, Bytes: content}}
+}
+
+// FileURIPart returns a file part referring to content at uri
+func FileURIPart(name, mimeType, uri string) Part {
+	return Part{Kind: KindFile, File: &FileContent{Name: name, MimeType: mimeType, URI: uri}}
+}
+
+// NewUserMessage returns a user message with the given parts and ID
+func NewUserMessage(messageID string, parts ...Part) Message {
+	return Message{Kind: KindMessage, Role: RoleUser, Parts: parts, MessageID: messageID}
+}
+
+// Validate checks the message is an identified user or agent message with valid parts
+func (m *Message) Validate() error {
+	if m.Kind != "" && m.Kind != KindMessage {
+		return fmt.Errorf("kind must be %q", KindMessage)
+	}
+	if m.Role != RoleUser && m.Role != RoleAgent {
+		return fmt.Errorf("role must be %q or %q", RoleUser, RoleAgent)
+	}
+	if m.MessageID == "" {
+		return errors.New("messageId is required")
+	}
+	if len(m.Parts) == 0 {
+		return errors.New("parts must not be empty")
+	}
+	for i, part := range m.Parts {
+		if err := part.Validate(); err != nil {
+			return fmt.Errorf("parts[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// CheckInlineFiles returns ErrInlineFileTooLarge when one of the message's file parts
+// carries more than limit bytes inline; zero is no limit
+func (m *Message) CheckInlineFiles(limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	for i, part := range m.Parts {
+		if part.Kind == KindFile && part.File != nil && len(part.File.Bytes) > limit {
+			return fmt.Errorf("parts[%d]: %w: %d bytes, the limit is %d; pass it by uri instead",
+				i, ErrInlineFileTooLarge, len(part.File.Bytes), limit)
+		}
+	}
+	return nil
+}
+
+// Input converts the message parts into task input: data parts are merged into the
+// input, text parts are joined under TextKey and file parts are listed under FilesKey,
+// read back with Files. Data parts may not use the key of the text or file parts the
+// message has.
+func (m *Message) Input() (map[string]interface{}, error) {
+	input := make(map[string]interface{})
+	var texts []string
+	var files []FileContent
+	for i, part := range m.Parts {
+		switch part.Kind {
+		case KindText:
+			texts = append(texts, part.Text)
+		case KindData:
+			for key, value := range part.Data {
+				input[key] = value
+			}
+		case KindFile:
+			if part.File == nil {
+				return nil, fmt.Errorf("part %d: file part requires a file", i)
+			}
+			files = append(files, *part.File)
+		default:
+			return nil, fmt.Errorf("part %d: %w %q", i, ErrUnsupportedKind, part.Kind)
+		}
+	}
+	if len(texts) > 0 {
+		if _, ok := input[TextKey]; ok {
+			return nil, fmt.Errorf("data key %q is reserved for text parts", TextKey)
+		}
+		input[TextKey] = strings.Join(texts, "\n")
+	}
+	if len(files) > 0 {
+		if _, ok := input[FilesKey]; ok {
+			return nil, fmt.Errorf("data key %q is reserved for file parts", FilesKey)
+		}
+		// Stored as decoded JSON, the form the input is read back from a database in
+		data, err := json.Marshal(files)
+		if err != nil {
+			return nil, err
+		}
+		var decoded []interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		input[FilesKey] = decoded
+	}
+	return input, nil
+}
+
+// Files returns the files of a task input converted from a message, or nil when the
+// input has none
+func Files(input map[string]interface{}) []FileContent {
+	value, ok := input[FilesKey]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var files []FileContent
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil
+	}
+	for _, file := range files {
+		if (file.Bytes == nil) == (file.URI == "") {
+			return nil
+		}
+	}
+	return files
+}
+
+// InputMessage converts task input back into the user message it was created from:
+// TextKey becomes a text part, the files under FilesKey file parts and the other keys
+// a data part. A multi-turn task's conversation is left out; see A2AHistory.
+func InputMessage(messageID string, input map[string]interface{}) Message {
+	return valuesMessage(RoleUser, messageID, input)
+}
+
+// OutputMessage converts a task result into the agent message replying with it, the
+// way InputMessage converts input
+func OutputMessage(messageID string, result map[string]interface{}) Message {
+	return valuesMessage(RoleAgent, messageID, result)
+}
+
+// valuesMessage converts task input or a result into a message with role
+func valuesMessage(role, messageID string, values map[string]interface{}) Message {
+	message := Message{Kind: KindMessage, Role: role, MessageID: messageID}
+	data := make(map[string]interface{})
+	files := Files(values)
+	for key, value := range values {
+		switch {
+		case key == ConversationKey:
+		case key == FilesKey && files != nil:
+		case key == TextKey:
+			if text, ok := value.(string); ok {
+				message.Parts = append(message.Parts, TextPart(text))
+				continue
+			}
+			data[key] = value
+		default:
+			data[key] = value
+		}
+	}
+	if len(data) > 0 {
+		message.Parts = append(message.Parts, DataPart(data))
+	}
+	for _, file := range files {
+		message.Parts = append(message.Parts, Part{Kind: KindFile, File: &file})
+	}
+	return message
+}
+
+// A2AHistory returns a task's messages, oldest first: the message it was requested with,
+// the questions it asked and the answers it was given, then its output. A positive
+// length keeps only the last length messages.
+func A2AHistory(task *Task, length int) []Message {
+	contextID := a2aContextID(task)
+	var input Message
+	if task.Message != nil {
+		input = *task.Message.clone()
+	} else {
+		// Stored before tasks kept their message
+		input = InputMessage(task.ID+"-input", task.Input)
+	}
+	history := []Message{input}
+	for i, turn := range Conversation(task.Input) {
+		message := Message{
+			Kind:      KindMessage,
+			Role:      turn.Role,
+			MessageID: fmt.Sprintf("%s-turn-%d", task.ID, i+1),
+		}
+		if turn.Text != "" {
+			message.Parts = append(message.Parts, TextPart(turn.Text))
+		}
+		if len(turn.Data) > 0 {
+			message.Parts = append(message.Parts, DataPart(turn.Data))
+		}
+		history = append(history, message)
+	}
+	if task.Output != nil {
+		history = append(history, *task.Output.clone())
+	}
+	for i := range history {
+		history[i].TaskID = task.ID
+		history[i].ContextID = contextID
+	}
+	if length > 0 && len(history) > length {
+		history = history[len(history)-length:]
+	}
+	return history
+}
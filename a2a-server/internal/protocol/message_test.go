@@ -0,0 +1,178 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_Validate(t *testing.T) {
+	valid := NewUserMessage("msg-1", TextPart("hi"), FilePart("notes.txt", "text/plain", []byte("notes")))
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(m *Message)
+	}{
+		{"wrong kind", func(m *Message) { m.Kind = KindTask }},
+		{"no role", func(m *Message) { m.Role = "" }},
+		{"no ID", func(m *Message) { m.MessageID = "" }},
+		{"no parts", func(m *Message) { m.Parts = nil }},
+		{"file without content", func(m *Message) { m.Parts[1].File.Bytes = nil }},
+		{"file with bytes and uri", func(m *Message) { m.Parts[1].File.URI = "https://example.com/notes.txt" }},
+		{"text with a file", func(m *Message) { m.Parts[0].File = m.Parts[1].File }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := NewUserMessage("msg-1", TextPart("hi"), FilePart("notes.txt", "text/plain", []byte("notes")))
+			tt.modify(&message)
+			assert.Error(t, message.Validate())
+		})
+	}
+
+	unsupported := NewUserMessage("msg-1", Part{Kind: "video"})
+	assert.True(t, errors.Is(unsupported.Validate(), ErrUnsupportedKind))
+}
+
+func TestMessage_CheckInlineFiles(t *testing.T) {
+	message := NewUserMessage("msg-1",
+		FilePart("small.txt", "text/plain", make([]byte, 10)),
+		FileURIPart("large.bin", "application/octet-stream", "https://example.com/large.bin"),
+	)
+	assert.NoError(t, message.CheckInlineFiles(10))
+	assert.NoError(t, message.CheckInlineFiles(0), "zero is no limit")
+	assert.ErrorIs(t, message.CheckInlineFiles(9), ErrInlineFileTooLarge)
+}
+
+func TestMessage_Input(t *testing.T) {
+	msg := Message{Parts: []Part{
+		{Kind: KindText, Text: "Summarize this"},
+		{Kind: KindData, Data: map[string]interface{}{"max_length": 50.0}},
+		{Kind: KindText, Text: "please"},
+	}}
+
+	input, err := msg.Input()
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize this\nplease", input["text"])
+	assert.Equal(t, 50.0, input["max_length"])
+	assert.Nil(t, Files(input))
+
+	msg.Parts = append(msg.Parts, Part{Kind: "file"})
+	_, err = msg.Input()
+	assert.Error(t, err)
+	msg.Parts[3] = Part{Kind: "video"}
+	_, err = msg.Input()
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+}
+
+func TestMessage_InputTextKey(t *testing.T) {
+	// A data part may set the text when the message has no text parts
+	msg := NewUserMessage("msg-1", DataPart(map[string]interface{}{TextKey: "from data"}))
+	input, err := msg.Input()
+	require.NoError(t, err)
+	assert.Equal(t, "from data", input[TextKey])
+
+	// but not shadow its text parts
+	msg.Parts = append(msg.Parts, TextPart("from text"))
+	_, err = msg.Input()
+	assert.ErrorContains(t, err, `data key "text" is reserved for text parts`)
+}
+
+func TestMessage_InputFiles(t *testing.T) {
+	msg := NewUserMessage("msg-1",
+		TextPart("Summarize the attached notes"),
+		FilePart("notes.txt", "text/plain", []byte("Embeddings capture meaning.")),
+		FileURIPart("paper.pdf", "application/pdf", "https://example.com/paper.pdf"),
+	)
+	input, err := msg.Input()
+	require.NoError(t, err)
+
+	files := Files(input)
+	require.Len(t, files, 2)
+	assert.Equal(t, FileContent{Name: "notes.txt", MimeType: "text/plain", Bytes: []byte("Embeddings capture meaning.")}, files[0])
+	assert.Equal(t, "https://example.com/paper.pdf", files[1].URI)
+
+	// The input reads back the same from JSON, as it is from a database
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, input, decoded)
+	assert.Equal(t, files, Files(decoded))
+
+	// Data parts cannot shadow the files
+	msg.Parts = append(msg.Parts, DataPart(map[string]interface{}{FilesKey: "mine"}))
+	_, err = msg.Input()
+	assert.Error(t, err)
+}
+
+func TestInputMessage(t *testing.T) {
+	original := NewUserMessage("msg-1",
+		TextPart("Summarize the attached notes"),
+		DataPart(map[string]interface{}{"max_length": 50.0}),
+		FilePart("notes.txt", "text/plain", []byte("Embeddings capture meaning.")),
+	)
+	input, err := original.Input()
+	require.NoError(t, err)
+
+	message := InputMessage("msg-1", input)
+	assert.NoError(t, message.Validate())
+	assert.Equal(t, original, message)
+}
+
+func TestA2AHistory(t *testing.T) {
+	task := NewTask("agent-1", "book_flight", map[string]interface{}{"text": "Book a flight to Paris"})
+	task.ContextID = "ctx-1"
+	task.UpdateState(TaskStateRunning)
+	task.RequestInput("Which date?")
+	task.Resume(Turn{Text: "Friday"})
+
+	history := A2AHistory(task, 0)
+	require.Len(t, history, 3)
+	for _, message := range history {
+		assert.NoError(t, message.Validate())
+		assert.Equal(t, task.ID, message.TaskID)
+		assert.Equal(t, "ctx-1", message.ContextID)
+	}
+	assert.Equal(t, []Part{TextPart("Book a flight to Paris")}, history[0].Parts, "the conversation is not part of the input message")
+	assert.Equal(t, RoleAgent, history[1].Role)
+	assert.Equal(t, "Which date?", history[1].Parts[0].Text)
+	assert.Equal(t, RoleUser, history[2].Role)
+	assert.Equal(t, "Friday", history[2].Parts[0].Text)
+
+	assert.Equal(t, history[1:], A2AHistory(task, 2))
+	assert.Equal(t, history, A2AHistory(task, 10))
+}
+
+func TestA2AHistory_Messages(t *testing.T) {
+	message := NewUserMessage("msg-1", TextPart("Summarize the notes"), FileURIPart("notes.txt", "text/plain", "https://example.com/notes.txt"))
+	input, err := message.Input()
+	require.NoError(t, err)
+	task := NewTask("agent-1", "summarize", input)
+	task.Message = &message
+	task.UpdateState(TaskStateRunning)
+	task.SetResult(map[string]interface{}{TextKey: "Embeddings capture meaning.", "words": 3.0})
+
+	require.NotNil(t, task.Output)
+	assert.NoError(t, task.Output.Validate())
+	assert.Equal(t, RoleAgent, task.Output.Role)
+	assert.Equal(t, []Part{TextPart("Embeddings capture meaning."), DataPart(map[string]interface{}{"words": 3.0})}, task.Output.Parts)
+
+	history := A2AHistory(task, 0)
+	require.Len(t, history, 2)
+	assert.Equal(t, "msg-1", history[0].MessageID, "the task's own message is its input")
+	assert.Equal(t, message.Parts, history[0].Parts)
+	assert.Equal(t, task.Output.Parts, history[1].Parts)
+	assert.Equal(t, task.ID, history[1].TaskID)
+	assert.Empty(t, message.TaskID, "history copies the task's messages")
+
+	// Clones do not share messages
+	clone := task.Clone()
+	clone.Message.Parts[0].Text = "changed"
+	clone.Output.Parts[1].Data["words"] = 4.0
+	assert.Equal(t, "Summarize the notes", task.Message.Parts[0].Text)
+	assert.Equal(t, 3.0, task.Output.Parts[1].Data["words"])
+}
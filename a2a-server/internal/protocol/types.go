@@ -1,6 +1,8 @@
 package protocol
 
 import (
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +17,8 @@ const (
 	TaskStateCompleted TaskState = "completed"
 	TaskStateFailed    TaskState = "failed"
 	TaskStateCancelled TaskState = "cancelled"
+	// TaskStateInputRequired pauses a task until the user answers its executor's question
+	TaskStateInputRequired TaskState = "input_required"
 )
 
 // String returns the string representation of the task state
@@ -22,6 +26,41 @@ func (ts TaskState) String() string {
 	return string(ts)
 }
 
+// Valid reports whether the state is one tasks can be in
+func (ts TaskState) Valid() bool {
+	_, ok := transitions[ts]
+	return ok
+}
+
+// transitions lists the states a task in each state may move to. A running task moves
+// back to pending when it is requeued and to running again when its expired lease is
+// claimed; terminal states are final.
+var transitions = map[TaskState][]TaskState{
+	TaskStatePending:       {TaskStateRunning, TaskStateFailed, TaskStateCancelled},
+	TaskStateRunning:       {TaskStateRunning, TaskStatePending, TaskStateInputRequired, TaskStateCompleted, TaskStateFailed, TaskStateCancelled},
+	TaskStateInputRequired: {TaskStatePending, TaskStateFailed, TaskStateCancelled},
+	TaskStateCompleted:     nil,
+	TaskStateFailed:        nil,
+	TaskStateCancelled:     nil,
+}
+
+// CanTransitionTo reports whether a task may move from this state to next. Staying in the
+// same state, e.g. to update a finished task's TTL, is always allowed.
+func (ts TaskState) CanTransitionTo(next TaskState) bool {
+	return ts == next || slices.Contains(transitions[ts], next)
+}
+
+// PreviousStates lists the states a task may move to this state from, itself included
+func (ts TaskState) PreviousStates() []TaskState {
+	previous := []TaskState{ts}
+	for from, to := range transitions {
+		if from != ts && slices.Contains(to, ts) {
+			previous = append(previous, from)
+		}
+	}
+	return previous
+}
+
 // IsTerminal returns true if the task state is terminal (completed, failed, or cancelled)
 func (ts TaskState) IsTerminal() bool {
 	return ts == TaskStateCompleted || ts == TaskStateFailed || ts == TaskStateCancelled
@@ -31,28 +70,232 @@ func (ts TaskState) IsTerminal() bool {
 type Task struct {
 	ID          string                 `json:"id"`
 	AgentID     string                 `json:"agent_id"`
+	ContextID   string                 `json:"context_id,omitempty"`
+	UserID      string                 `json:"user_id,omitempty"`
 	Capability  string                 `json:"capability"`
+	Priority    TaskPriority           `json:"priority,omitempty"`
 	Input       map[string]interface{} `json:"input,omitempty"`
 	State       TaskState              `json:"state"`
 	Result      map[string]interface{} `json:"result,omitempty"`
+	Artifacts   []Artifact             `json:"artifacts,omitempty"`
 	Error       string                 `json:"error,omitempty"`
+	InputHash   string                 `json:"input_hash,omitempty"`
+	ResultHash  string                 `json:"result_hash,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
 	CompletedAt time.Time              `json:"completed_at,omitempty"`
+	// Message is the user message the task was requested with, or the one converted from
+	// the input it was requested with. Input is derived from it for capabilities, which
+	// read maps, and gains dependency results and the conversation as the task runs.
+	Message *Message `json:"message,omitempty"`
+	// Output is the agent message the task completed with, converted from Result
+	Output *Message `json:"output,omitempty"`
+	// Speculative lets the executor race substitutable capabilities for lower latency
+	Speculative bool                 `json:"speculative,omitempty"`
+	Speculation *SpeculationDecision `json:"speculation,omitempty"`
+	// DependsOn lists the tasks that must complete before this one starts; their results
+	// are added to the input under DependencyResultsKey
+	DependsOn []string `json:"depends_on,omitempty"`
+	// MaxCostUSD caps what one execution of the task may cost; zero for no cap
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty"`
+	// TraceContext holds the W3C trace context of the request that created the task, so its
+	// execution and the services it calls join the creator's trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// Attempts counts the executions started; Lease is held by the processor running the
+	// task, which renews it until the task finishes
+	Attempts int        `json:"attempts,omitempty"`
+	Lease    *TaskLease `json:"lease,omitempty"`
+	// TTLSeconds is how long the task is kept once it finished, overriding the server's
+	// retention; zero for the server's
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+	// CapabilityVersion is the version of the capability the task runs, fixed when it is
+	// created; empty for unversioned capabilities
+	CapabilityVersion string `json:"capability_version,omitempty"`
+	// AuthToken is the bearer token the task was created with, forwarded to services the
+	// capability calls on the caller's behalf. It is never serialized, so only the memory
+	// store keeps it.
+	AuthToken string `json:"-"`
+}
+
+// FinishedAt returns when a terminal task finished
+func (t *Task) FinishedAt() time.Time {
+	if t.CompletedAt.IsZero() {
+		return t.UpdatedAt
+	}
+	return t.CompletedAt
+}
+
+// RetentionExpired reports whether a terminal task outlived its retention at now: its
+// TTL, or retention when it has none. Tasks without either are kept.
+func (t *Task) RetentionExpired(now time.Time, retention time.Duration) bool {
+	if t.TTLSeconds > 0 {
+		retention = time.Duration(t.TTLSeconds) * time.Second
+	}
+	return t.State.IsTerminal() && retention > 0 && !now.Before(t.FinishedAt().Add(retention))
+}
+
+// Clone returns a deep copy of the task, so stores can keep and hand out tasks that no
+// caller changes under them
+func (t *Task) Clone() *Task {
+	clone := *t
+	clone.Input = cloneMap(t.Input)
+	clone.Result = cloneMap(t.Result)
+	clone.Message = t.Message.clone()
+	clone.Output = t.Output.clone()
+	clone.DependsOn = slices.Clone(t.DependsOn)
+	clone.TraceContext = maps.Clone(t.TraceContext)
+	if t.Artifacts != nil {
+		clone.Artifacts = make([]Artifact, len(t.Artifacts))
+		for i, artifact := range t.Artifacts {
+			clone.Artifacts[i] = artifact.clone()
+		}
+	}
+	if t.Speculation != nil {
+		speculation := *t.Speculation
+		speculation.Launched = slices.Clone(speculation.Launched)
+		speculation.Cancelled = slices.Clone(speculation.Cancelled)
+		speculation.Rejected = maps.Clone(speculation.Rejected)
+		clone.Speculation = &speculation
+	}
+	if t.Lease != nil {
+		lease := *t.Lease
+		clone.Lease = &lease
+	}
+	return &clone
+}
+
+// clone returns a deep copy of the artifact
+func (a Artifact) clone() Artifact {
+	a.Parts = cloneParts(a.Parts)
+	return a
+}
+
+// clone returns a deep copy of the message, nil for nil
+func (m *Message) clone() *Message {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Parts = cloneParts(m.Parts)
+	clone.Metadata = cloneMap(m.Metadata)
+	return &clone
+}
+
+// cloneParts deep copies message or artifact parts
+func cloneParts(parts []Part) []Part {
+	if parts == nil {
+		return nil
+	}
+	clone := make([]Part, len(parts))
+	for i, part := range parts {
+		part.Data = cloneMap(part.Data)
+		if part.File != nil {
+			file := *part.File
+			file.Bytes = slices.Clone(file.Bytes)
+			part.File = &file
+		}
+		clone[i] = part
+	}
+	return clone
+}
+
+// cloneMap deep copies a decoded JSON object
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		clone[key] = cloneValue(value)
+	}
+	return clone
+}
+
+// cloneValue deep copies the objects and arrays of a decoded JSON value
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	default:
+		return v
+	}
 }
 
-// NewTask creates a new task with pending state
+// TaskLease is a processor's claim on a running task. A processor that stops renewing it,
+// e.g. because it crashed, loses the task once the lease expires.
+type TaskLease struct {
+	// Owner identifies the processor holding the lease
+	Owner       string    `json:"owner"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the lease expired at now
+func (l *TaskLease) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// TaskPriority orders pending tasks for dispatch
+type TaskPriority string
+
+// Task priorities, highest first
+const (
+	PriorityHigh   TaskPriority = "high"
+	PriorityNormal TaskPriority = "normal"
+	PriorityLow    TaskPriority = "low"
+)
+
+// Priorities lists the task priorities, highest first
+var Priorities = []TaskPriority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// Valid reports whether p is a known priority
+func (p TaskPriority) Valid() bool {
+	switch p {
+	case PriorityHigh, PriorityNormal, PriorityLow:
+		return true
+	}
+	return false
+}
+
+// Rank orders priorities for dispatch: 0 for high, 1 for normal and 2 for low. Tasks
+// without a priority, e.g. created before priorities existed, rank as normal.
+func (p TaskPriority) Rank() int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	}
+	return 1
+}
+
+// NewTask creates a new task with pending state and normal priority, requested with the
+// message converted from input
 func NewTask(agentID, capability string, input map[string]interface{}) *Task {
 	now := time.Now()
-	return &Task{
+	inputHash, _ := HashPayload(input)
+	task := &Task{
 		ID:         uuid.New().String(),
 		AgentID:    agentID,
 		Capability: capability,
 		Input:      input,
+		Priority:   PriorityNormal,
 		State:      TaskStatePending,
+		InputHash:  inputHash,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
+	if len(input) > 0 {
+		message := InputMessage(task.ID+"-input", input)
+		task.Message = &message
+	}
+	return task
 }
 
 // UpdateState updates the task state and timestamp
@@ -61,9 +304,16 @@ func (t *Task) UpdateState(state TaskState) {
 	t.UpdatedAt = time.Now()
 }
 
-// SetResult sets the task result and marks it as completed
+// SetResult sets the task result, and the output message converted from it, and marks
+// the task as completed
 func (t *Task) SetResult(result map[string]interface{}) {
 	t.Result = result
+	t.ResultHash, _ = HashPayload(result)
+	t.Output = nil
+	if len(result) > 0 {
+		output := OutputMessage(t.ID+"-output", result)
+		t.Output = &output
+	}
 	t.State = TaskStateCompleted
 	t.CompletedAt = time.Now()
 	t.UpdatedAt = t.CompletedAt
@@ -91,6 +341,75 @@ type Capability struct {
 	Description  string                 `json:"description"`
 	InputSchema  map[string]interface{} `json:"input_schema,omitempty"`
 	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+	// SubstituteGroup marks capabilities that can stand in for each other, e.g. two
+	// summarizers with different cost and quality
+	SubstituteGroup  string  `json:"substitute_group,omitempty"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd,omitempty"`
+	// Version tells apart the versions of a capability offered side by side, such as "1"
+	// and "2"; empty for an unversioned capability
+	Version string `json:"version,omitempty"`
+	// Deprecated versions still run, but callers are warned to move off them;
+	// DeprecationMessage tells them what to use instead
+	Deprecated         bool   `json:"deprecated,omitempty"`
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
+}
+
+// SpeculationDecision records how a speculative task was executed
+type SpeculationDecision struct {
+	// Launched lists the capabilities run in parallel, requested capability first
+	Launched []string `json:"launched"`
+	// Winner is the capability whose result was accepted; empty if none was
+	Winner string `json:"winner,omitempty"`
+	// Cancelled lists launched capabilities stopped once a result was accepted
+	Cancelled []string `json:"cancelled,omitempty"`
+	// Rejected maps capabilities that failed or returned an unacceptable result to the reason
+	Rejected         map[string]string `json:"rejected,omitempty"`
+	CostCapUSD       float64           `json:"cost_cap_usd"`
+	EstimatedCostUSD float64           `json:"estimated_cost_usd"`
+	LatencyMs        int64             `json:"latency_ms"`
+}
+
+// Substitutes returns the other capabilities in the same substitute group as the named
+// one, each at its default version
+func (ac *AgentCard) Substitutes(name string) []Capability {
+	requested, ok := ac.Capability(name)
+	if !ok || requested.SubstituteGroup == "" {
+		return nil
+	}
+
+	var substitutes []Capability
+	for _, c := range ac.Capabilities {
+		if c.Name == name || c.SubstituteGroup != requested.SubstituteGroup || slices.ContainsFunc(substitutes,
+			func(s Capability) bool { return s.Name == c.Name }) {
+			continue
+		}
+		if substitute, ok := ac.Capability(c.Name); ok && substitute.SubstituteGroup == requested.SubstituteGroup {
+			substitutes = append(substitutes, substitute)
+		}
+	}
+	return substitutes
+}
+
+// Capability returns the named capability at its default version; see SelectVersion
+func (ac *AgentCard) Capability(name string) (Capability, bool) {
+	return SelectVersion(ac.Versions(name), "")
+}
+
+// CapabilityVersion returns the named capability at version, or at its default version
+// when version is empty
+func (ac *AgentCard) CapabilityVersion(name, version string) (Capability, bool) {
+	return SelectVersion(ac.Versions(name), version)
+}
+
+// Versions returns every version of the named capability the card offers
+func (ac *AgentCard) Versions(name string) []Capability {
+	var versions []Capability
+	for _, c := range ac.Capabilities {
+		if c.Name == name {
+			versions = append(versions, c)
+		}
+	}
+	return versions
 }
 
 // AgentCard represents an agent's capabilities and metadata
@@ -120,9 +439,30 @@ func (ac *AgentCard) AddCapability(cap Capability) {
 
 // TaskEvent represents a real-time event for task updates (SSE)
 type TaskEvent struct {
+	// ID orders a task's events, starting at 1; streams send it as the SSE event ID so
+	// clients can resume with Last-Event-ID
+	ID        int64                  `json:"id,omitempty"`
 	TaskID    string                 `json:"task_id"`
 	State     TaskState              `json:"state"`
 	Message   string                 `json:"message,omitempty"`
 	Data      map[string]interface{} `json:"data,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
+	// WorkerID identifies the processor that moved the task to State, if one did
+	WorkerID string `json:"worker_id,omitempty"`
+	// Artifact makes this an artifact update carrying a whole artifact or, with Append,
+	// a chunk to add to an earlier one; LastChunk marks the artifact complete
+	Artifact  *Artifact `json:"artifact,omitempty"`
+	Append    bool      `json:"append,omitempty"`
+	LastChunk bool      `json:"last_chunk,omitempty"`
+}
+
+// Transition reports whether the event reports the task's state rather than an
+// artifact; transitions make up the task's history
+func (e TaskEvent) Transition() bool {
+	return e.Artifact == nil
+}
+
+// Final reports whether this is the last event of the task: a terminal state change
+func (e TaskEvent) Final() bool {
+	return e.Transition() && e.State.IsTerminal()
 }
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/json"
+	"slices"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ func TestTaskState_String(t *testing.T) {
 	}{
 		{"pending", TaskStatePending, "pending"},
 		{"running", TaskStateRunning, "running"},
+		{"input required", TaskStateInputRequired, "input_required"},
 		{"completed", TaskStateCompleted, "completed"},
 		{"failed", TaskStateFailed, "failed"},
 		{"cancelled", TaskStateCancelled, "cancelled"},
@@ -37,6 +39,7 @@ func TestTaskState_IsTerminal(t *testing.T) {
 	}{
 		{"pending is not terminal", TaskStatePending, false},
 		{"running is not terminal", TaskStateRunning, false},
+		{"input required is not terminal", TaskStateInputRequired, false},
 		{"completed is terminal", TaskStateCompleted, true},
 		{"failed is terminal", TaskStateFailed, true},
 		{"cancelled is terminal", TaskStateCancelled, true},
@@ -49,6 +52,65 @@ func TestTaskState_IsTerminal(t *testing.T) {
 	}
 }
 
+func TestTaskState_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to TaskState
+		want     bool
+	}{
+		{TaskStatePending, TaskStateRunning, true},
+		{TaskStatePending, TaskStateCancelled, true},
+		{TaskStatePending, TaskStateCompleted, false},
+		{TaskStatePending, TaskStateInputRequired, false},
+		{TaskStateRunning, TaskStateInputRequired, true},
+		{TaskStateRunning, TaskStateCompleted, true},
+		{TaskStateRunning, TaskStatePending, true},
+		{TaskStateInputRequired, TaskStatePending, true},
+		{TaskStateInputRequired, TaskStateCancelled, true},
+		{TaskStateInputRequired, TaskStateRunning, false},
+		{TaskStateInputRequired, TaskStateCompleted, false},
+		{TaskStateCompleted, TaskStateCompleted, true},
+		{TaskStateCompleted, TaskStateRunning, false},
+		{TaskStateCancelled, TaskStateFailed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+			assert.Equal(t, tt.want, slices.Contains(tt.to.PreviousStates(), tt.from))
+		})
+	}
+}
+
+func TestTask_RequestInputAndResume(t *testing.T) {
+	task := NewTask("agent-1", "book_flight", map[string]interface{}{"to": "Paris"})
+	task.UpdateState(TaskStateRunning)
+
+	task.RequestInput("Which date?")
+	assert.Equal(t, TaskStateInputRequired, task.State)
+	assert.Equal(t, "Which date?", task.InputPrompt())
+
+	task.Resume(Turn{Text: "Friday", Data: map[string]interface{}{"date": "2026-10-23"}})
+	assert.Equal(t, TaskStatePending, task.State)
+	assert.Empty(t, task.InputPrompt())
+	assert.Equal(t, "Paris", task.Input["to"])
+	assert.Equal(t, "2026-10-23", task.Input["date"], "the answer's data is merged into the input")
+	assert.Equal(t, []Turn{
+		{Role: TurnAgent, Text: "Which date?"},
+		{Role: TurnUser, Text: "Friday", Data: map[string]interface{}{"date": "2026-10-23"}},
+	}, Conversation(task.Input))
+
+	// The conversation survives a JSON round trip unchanged, so the input hash does too
+	hash, err := HashPayload(task.Input)
+	require.NoError(t, err)
+	data, err := json.Marshal(task.Input)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	decodedHash, err := HashPayload(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, hash, decodedHash)
+}
+
 func TestNewTask(t *testing.T) {
 	task := NewTask("agent-1", "test_capability", map[string]interface{}{
 		"query": "test",
@@ -112,6 +174,22 @@ func TestTask_Cancel(t *testing.T) {
 	assert.NotZero(t, task.CompletedAt)
 }
 
+func TestTask_RetentionExpired(t *testing.T) {
+	task := NewTask("agent-1", "test", nil)
+	assert.False(t, task.RetentionExpired(time.Now().Add(time.Hour), time.Minute), "pending tasks are kept")
+
+	task.SetResult(nil)
+	finished := task.FinishedAt()
+	assert.False(t, task.RetentionExpired(finished.Add(59*time.Second), time.Minute))
+	assert.True(t, task.RetentionExpired(finished.Add(time.Minute), time.Minute))
+	assert.False(t, task.RetentionExpired(finished.Add(24*time.Hour), 0), "without retention tasks are kept")
+
+	// The task's TTL overrides the retention
+	task.TTLSeconds = 3600
+	assert.False(t, task.RetentionExpired(finished.Add(time.Minute), time.Minute))
+	assert.True(t, task.RetentionExpired(finished.Add(time.Hour), 0))
+}
+
 func TestTask_JSON(t *testing.T) {
 	task := NewTask("agent-1", "search", map[string]interface{}{
 		"query": "test query",
@@ -174,6 +252,56 @@ func TestAgentCard_AddCapability(t *testing.T) {
 	assert.Equal(t, "search_papers", card.Capabilities[0].Name)
 }
 
+func TestAgentCard_CapabilityVersions(t *testing.T) {
+	card := NewAgentCard("agent-1", "Test Agent", "1.0.0", "Test")
+	card.AddCapability(Capability{Name: "summarize", Version: "1", Deprecated: true, DeprecationMessage: "use version 2",
+		SubstituteGroup: "summarizers"})
+	card.AddCapability(Capability{Name: "summarize", Version: "2", SubstituteGroup: "summarizers"})
+	card.AddCapability(Capability{Name: "summarize", Version: "3-beta", Deprecated: true, SubstituteGroup: "summarizers"})
+	card.AddCapability(Capability{Name: "summarize_fast", Version: "1.9", SubstituteGroup: "summarizers"})
+	card.AddCapability(Capability{Name: "summarize_fast", Version: "1.10", SubstituteGroup: "summarizers"})
+
+	// The default version is the latest one not deprecated
+	capability, ok := card.Capability("summarize")
+	require.True(t, ok)
+	assert.Equal(t, "2", capability.Version)
+	assert.Empty(t, capability.DeprecationWarning())
+
+	capability, ok = card.CapabilityVersion("summarize", "1")
+	require.True(t, ok)
+	assert.Equal(t, "capability summarize version 1 is deprecated: use version 2", capability.DeprecationWarning())
+	_, ok = card.CapabilityVersion("summarize", "4")
+	assert.False(t, ok)
+	assert.Len(t, card.Versions("summarize"), 3)
+
+	// Each substitute is listed once, at its default version
+	substitutes := card.Substitutes("summarize")
+	require.Len(t, substitutes, 1)
+	assert.Equal(t, "1.10", substitutes[0].Version)
+
+	// Without a current version the latest deprecated one is the default
+	deprecated, ok := SelectVersion([]Capability{{Name: "old", Version: "2", Deprecated: true},
+		{Name: "old", Version: "1", Deprecated: true}}, "")
+	require.True(t, ok)
+	assert.Equal(t, "2", deprecated.Version)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "2", -1},
+		{"1.10", "1.9", 1},
+		{"v2", "2.0", 0},
+		{"", "1", -1},
+		{"1.0-beta", "1.0-alpha", 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CompareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
 func TestAgentCard_JSON(t *testing.T) {
 	card := NewAgentCard("agent-1", "Test Agent", "1.0.0", "Test agent")
 	card.AddCapability(Capability{
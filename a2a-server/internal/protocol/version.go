@@ -0,0 +1,83 @@
+package protocol
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SelectVersion picks a version among the versions of one capability: the one named, or
+// when version is empty the default, the latest version that is not deprecated or the
+// latest of all when every version is
+func SelectVersion(versions []Capability, version string) (Capability, bool) {
+	if version != "" {
+		for _, c := range versions {
+			if c.Version == version {
+				return c, true
+			}
+		}
+		return Capability{}, false
+	}
+
+	var selected Capability
+	found := false
+	for _, c := range versions {
+		switch {
+		case !found:
+		case selected.Deprecated != c.Deprecated:
+			if c.Deprecated {
+				continue
+			}
+		case CompareVersions(c.Version, selected.Version) <= 0:
+			continue
+		}
+		selected, found = c, true
+	}
+	return selected, found
+}
+
+// CompareVersions orders two capability versions, returning -1, 0 or 1. Versions are
+// compared by their dot-separated parts, numerically where both parts are numbers, so
+// "1.10" follows "1.9"; a leading "v" is ignored.
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		// Missing parts count as zero, so "1" and "1.0" are the same version
+		ap, bp := "0", "0"
+		if i < len(as) {
+			ap = as[i]
+		}
+		if i < len(bs) {
+			bp = bs[i]
+		}
+		an, aErr := strconv.Atoi(ap)
+		bn, bErr := strconv.Atoi(bp)
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmp.Compare(an, bn)
+			}
+		case ap != bp:
+			return strings.Compare(ap, bp)
+		}
+	}
+	return 0
+}
+
+// DeprecationWarning returns the warning for callers of a deprecated capability version,
+// or "" when the version is current
+func (c Capability) DeprecationWarning() string {
+	if !c.Deprecated {
+		return ""
+	}
+	warning := fmt.Sprintf("capability %s is deprecated", c.Name)
+	if c.Version != "" {
+		warning = fmt.Sprintf("capability %s version %s is deprecated", c.Name, c.Version)
+	}
+	if c.DeprecationMessage != "" {
+		warning += ": " + c.DeprecationMessage
+	}
+	return warning
+}
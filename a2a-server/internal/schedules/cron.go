@@ -0,0 +1,158 @@
+package schedules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the shorthands accepted for common cron expressions
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames   = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// cronSpec is a parsed five-field cron expression: minute, hour, day of month, month and
+// day of week
+type cronSpec struct {
+	minutes, hours, days, months, weekdays bits
+	// anyDay and anyWeekday are set when the day fields start with *. As in Vixie cron,
+	// a day matches either day field when both are restricted, and the other otherwise.
+	anyDay, anyWeekday bool
+	loc                *time.Location
+}
+
+// parseCron parses a five-field cron expression, with names for months and days of the
+// week (0 or 7 is Sunday), or one of the @daily style macros
+func parseCron(expr string, loc *time.Location) (*cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields: minute hour day-of-month month day-of-week", expr)
+	}
+
+	spec := &cronSpec{
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+		loc:        loc,
+	}
+	var err error
+	if spec.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if spec.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if spec.days, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if spec.months, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if spec.weekdays, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if spec.weekdays.has(7) {
+		spec.weekdays = spec.weekdays&^(1<<7) | 1
+	}
+	return spec, nil
+}
+
+// parseCronField parses a comma-separated list of *, values and ranges, each with an
+// optional /step, of values between lo and hi. names, when given, name the values from lo.
+func parseCronField(field string, lo, hi int, names []string) (bits, error) {
+	var set bits
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		first, last := lo, hi
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if first, err = parseCronValue(from, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if last, err = parseCronValue(to, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if first > last {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := parseCronValue(rangePart, lo, hi, names)
+			if err != nil {
+				return 0, err
+			}
+			first = value
+			if !hasStep {
+				last = value
+			}
+		}
+		for i := first; i <= last; i += step {
+			set |= 1 << uint(i)
+		}
+	}
+	return set, nil
+}
+
+// parseCronValue parses a number or name between lo and hi
+func parseCronValue(s string, lo, hi int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return lo + i, nil
+		}
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil || value < lo || value > hi {
+		return 0, fmt.Errorf("%q is not a value from %d to %d", s, lo, hi)
+	}
+	return value, nil
+}
+
+// Next returns the first time after t the expression matches
+func (c *cronSpec) Next(t time.Time) time.Time {
+	return next(c, t, c.loc)
+}
+
+func (c *cronSpec) day(date time.Time) bool {
+	if !c.months.has(int(date.Month())) {
+		return false
+	}
+	day, weekday := c.days.has(date.Day()), c.weekdays.has(int(date.Weekday()))
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+func (c *cronSpec) clock(_ time.Time, hour, minute int) bool {
+	return c.hours.has(hour) && c.minutes.has(minute)
+}
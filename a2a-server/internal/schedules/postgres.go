@@ -0,0 +1,206 @@
+package schedules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps schedules in the Postgres schedules table, so several replicas
+// share them and each run is claimed by one of them
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// scheduleColumns lists the schedules table columns in the order scanSchedule reads them
+const scheduleColumns = `id, user_id, agent_id, capability, input, priority, max_cost_usd::float8, cron, rrule,
+	timezone, start_at, paused, next_run_at, last_run_at, last_task_id, last_error, runs, created_at, updated_at`
+
+// NewPostgresStore returns a schedule store backed by the database pool connects to
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// Create stores a new schedule
+func (s *PostgresStore) Create(ctx context.Context, schedule *Schedule) error {
+	input, err := marshalInput(schedule.Input)
+	if err != nil {
+		return err
+	}
+	var lastRunAt *time.Time
+	if !schedule.LastRunAt.IsZero() {
+		lastRunAt = &schedule.LastRunAt
+	}
+
+	_, err = s.pool.Exec(ctx, `INSERT INTO schedules (`+scheduleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+		schedule.ID, schedule.UserID, schedule.AgentID, schedule.Capability, input, string(schedule.Priority),
+		schedule.MaxCostUSD, schedule.Cron, schedule.RRule, schedule.Timezone, schedule.StartAt, schedule.Paused,
+		schedule.NextRunAt, lastRunAt, schedule.LastTaskID, schedule.LastError, schedule.Runs,
+		schedule.CreatedAt, schedule.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+		return fmt.Errorf("schedule %s already exists", schedule.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert schedule: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a schedule by ID
+func (s *PostgresStore) Get(ctx context.Context, id string) (*Schedule, error) {
+	schedule, err := scanSchedule(s.pool.QueryRow(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return schedule, err
+}
+
+// Update replaces a schedule's definition and next run
+func (s *PostgresStore) Update(ctx context.Context, schedule *Schedule) error {
+	input, err := marshalInput(schedule.Input)
+	if err != nil {
+		return err
+	}
+	tag, err := s.pool.Exec(ctx, `UPDATE schedules SET
+		user_id = $2, agent_id = $3, capability = $4, input = $5, priority = $6, max_cost_usd = $7,
+		cron = $8, rrule = $9, timezone = $10, start_at = $11, paused = $12, next_run_at = $13, updated_at = now()
+		WHERE id = $1`,
+		schedule.ID, schedule.UserID, schedule.AgentID, schedule.Capability, input, string(schedule.Priority),
+		schedule.MaxCostUSD, schedule.Cron, schedule.RRule, schedule.Timezone, schedule.StartAt, schedule.Paused,
+		schedule.NextRunAt)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, schedule.ID)
+	}
+	return nil
+}
+
+// Delete deletes a schedule
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return nil
+}
+
+// List returns the user's schedules, or every schedule, oldest first
+func (s *PostgresStore) List(ctx context.Context, userID string, limit, offset int) ([]*Schedule, error) {
+	return s.query(ctx, `SELECT `+scheduleColumns+` FROM schedules
+		WHERE $1 = '' OR user_id = $1
+		ORDER BY created_at, id LIMIT $2 OFFSET $3`, userID, limit, offset)
+}
+
+// Due returns the schedules whose next run is due, earliest first
+func (s *PostgresStore) Due(ctx context.Context, now time.Time, limit int) ([]*Schedule, error) {
+	return s.query(ctx, `SELECT `+scheduleColumns+` FROM schedules
+		WHERE NOT paused AND next_run_at <= $1
+		ORDER BY next_run_at, id LIMIT $2`, now, limit)
+}
+
+// query returns the schedules a query selects with scheduleColumns
+func (s *PostgresStore) query(ctx context.Context, query string, args ...interface{}) ([]*Schedule, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	return list, nil
+}
+
+// Claim moves a due run of a schedule to next
+func (s *PostgresStore) Claim(ctx context.Context, id string, due time.Time, next *time.Time) error {
+	tag, err := s.pool.Exec(ctx, `UPDATE schedules SET next_run_at = $3, updated_at = now()
+		WHERE id = $1 AND next_run_at = $2 AND NOT paused`, id, due, next)
+	if err != nil {
+		return fmt.Errorf("failed to claim schedule run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRunClaimed
+	}
+	return nil
+}
+
+// RecordRun records the outcome of a run
+func (s *PostgresStore) RecordRun(ctx context.Context, id string, run Run) error {
+	tag, err := s.pool.Exec(ctx, `UPDATE schedules SET
+		last_run_at = $2, last_task_id = $3, last_error = $4, runs = runs + CASE WHEN $3 = '' THEN 0 ELSE 1 END
+		WHERE id = $1`, id, run.At, run.TaskID, run.Error)
+	if err != nil {
+		return fmt.Errorf("failed to record schedule run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return nil
+}
+
+// marshalInput encodes a schedule's task input for its JSONB column
+func marshalInput(input map[string]interface{}) ([]byte, error) {
+	if input == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schedule input: %w", err)
+	}
+	return data, nil
+}
+
+// scanSchedule reads a schedule selected with scheduleColumns
+func scanSchedule(row pgx.Row) (*Schedule, error) {
+	var schedule Schedule
+	var priority string
+	var input []byte
+	var lastRunAt *time.Time
+
+	err := row.Scan(&schedule.ID, &schedule.UserID, &schedule.AgentID, &schedule.Capability, &input, &priority,
+		&schedule.MaxCostUSD, &schedule.Cron, &schedule.RRule, &schedule.Timezone, &schedule.StartAt, &schedule.Paused,
+		&schedule.NextRunAt, &lastRunAt, &schedule.LastTaskID, &schedule.LastError, &schedule.Runs,
+		&schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan schedule: %w", err)
+	}
+
+	schedule.Priority = protocol.TaskPriority(priority)
+	if lastRunAt != nil {
+		schedule.LastRunAt = *lastRunAt
+	}
+	if input != nil {
+		if err := json.Unmarshal(input, &schedule.Input); err != nil {
+			return nil, fmt.Errorf("failed to decode schedule input: %w", err)
+		}
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,100 @@
+//go:build integration
+// +build integration
+
+package schedules
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests for the Postgres schedule store; the schedules table comes from the
+// server's migrations
+// Run with: go test -tags=integration -v ./internal/schedules/
+
+func setupPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	port, _ := strconv.Atoi(getEnvOrDefault("DB_PORT", "5432"))
+	pool, err := tasks.Connect(context.Background(), tasks.PostgresConfig{
+		Host:     getEnvOrDefault("DB_HOST", "localhost"),
+		Port:     port,
+		User:     getEnvOrDefault("DB_USER", "app_user"),
+		Password: getEnvOrDefault("DB_PASSWORD", "mcp_password"),
+		DBName:   getEnvOrDefault("DB_NAME", "mcp_db"),
+		SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
+		MaxConns: 5,
+	})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return NewPostgresStore(pool)
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestPostgresStore_Schedules(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+	userID := "pg-schedule-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	t.Cleanup(func() { store.pool.Exec(ctx, `DELETE FROM schedules WHERE user_id = $1`, userID) })
+
+	now := time.Now().Truncate(time.Minute).Add(-time.Hour)
+	schedule := NewSchedule(userID, "agent-1", "search")
+	schedule.Input = map[string]interface{}{"query": "go"}
+	schedule.RRule = "FREQ=HOURLY"
+	schedule.Timezone = "Europe/Paris"
+	schedule.NextRunAt = &now
+	require.NoError(t, store.Create(ctx, schedule))
+	assert.Error(t, store.Create(ctx, schedule))
+
+	got, err := store.Get(ctx, schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "go", got.Input["query"])
+	assert.Equal(t, "FREQ=HOURLY", got.RRule)
+	assert.True(t, now.Equal(*got.NextRunAt))
+
+	list, err := store.List(ctx, userID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	due, err := store.Due(ctx, time.Now(), 1000)
+	require.NoError(t, err)
+	assert.Contains(t, scheduleIDs(due), schedule.ID)
+
+	next := now.Add(time.Hour)
+	require.NoError(t, store.Claim(ctx, schedule.ID, now, &next))
+	assert.ErrorIs(t, store.Claim(ctx, schedule.ID, now, &next), ErrRunClaimed)
+	require.NoError(t, store.RecordRun(ctx, schedule.ID, Run{At: now, TaskID: "task-1"}))
+	require.NoError(t, store.RecordRun(ctx, schedule.ID, Run{At: next, Error: "budget exceeded"}))
+
+	got.Paused = true
+	require.NoError(t, store.Update(ctx, got))
+	got, err = store.Get(ctx, schedule.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Paused)
+	assert.Equal(t, 1, got.Runs)
+	assert.Equal(t, "budget exceeded", got.LastError)
+	assert.ErrorIs(t, store.Claim(ctx, schedule.ID, now, nil), ErrRunClaimed)
+
+	require.NoError(t, store.Delete(ctx, schedule.ID))
+	_, err = store.Get(ctx, schedule.ID)
+	assert.ErrorIs(t, err, ErrScheduleNotFound)
+}
+
+func scheduleIDs(schedules []*Schedule) []string {
+	ids := make([]string, len(schedules))
+	for i, schedule := range schedules {
+		ids[i] = schedule.ID
+	}
+	return ids
+}
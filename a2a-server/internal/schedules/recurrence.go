@@ -0,0 +1,110 @@
+// Package schedules keeps the schedules recurring tasks are created on: cron expressions
+// or RFC 5545 recurrence rules evaluated in a time zone, and the stores tracking each
+// schedule's last and next run.
+package schedules
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxSearchDays bounds how far ahead the next occurrence of a recurrence is searched for
+const maxSearchDays = 5 * 366
+
+// Recurrence computes the times a schedule runs at, to the minute
+type Recurrence interface {
+	// Next returns the first occurrence after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// Parse returns the recurrence of a cron expression or, when cron is empty, of an RRULE,
+// evaluated in loc. RRULE occurrences start at start, which also gives them the time of
+// day and the days they default to; cron expressions ignore it.
+func Parse(cron, rrule string, start time.Time, loc *time.Location) (Recurrence, error) {
+	switch {
+	case cron != "" && rrule != "":
+		return nil, fmt.Errorf("only one of cron and rrule may be set")
+	case cron != "":
+		return parseCron(cron, loc)
+	case rrule != "":
+		return parseRRule(rrule, start.In(loc))
+	}
+	return nil, fmt.Errorf("one of cron and rrule is required")
+}
+
+// bits is a set of small non-negative integers, such as the minutes of an hour
+type bits uint64
+
+func (b bits) has(i int) bool {
+	return i >= 0 && i < 64 && b&(1<<uint(i)) != 0
+}
+
+// span returns the set of lo through hi
+func span(lo, hi int) bits {
+	var b bits
+	for i := lo; i <= hi; i++ {
+		b |= 1 << uint(i)
+	}
+	return b
+}
+
+// matcher selects the days and the times of day a recurrence occurs at
+type matcher interface {
+	// day reports whether the recurrence may occur on the day starting at date
+	day(date time.Time) bool
+	// clock reports whether it occurs at hour:minute of a day day accepted
+	clock(date time.Time, hour, minute int) bool
+}
+
+// next returns the first time after t, in loc, that m matches, or the zero time if there
+// is none within maxSearchDays
+func next(m matcher, t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	for i := 0; i < maxSearchDays; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		if !m.day(date) {
+			continue
+		}
+		hour, minute := 0, 0
+		if i == 0 {
+			hour, minute = t.Hour(), t.Minute()
+		}
+		for ; hour < 24; hour, minute = hour+1, 0 {
+			for ; minute < 60; minute++ {
+				if !m.clock(date, hour, minute) {
+					continue
+				}
+				if occurrence := at(date, hour, minute); occurrence.After(t) {
+					return occurrence
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// at returns hour:minute on the day of date. A time skipped by a daylight saving
+// transition is taken at the offset before it, so 02:30 on a day clocks spring forward
+// from 02:00 to 03:00 is 03:30.
+func at(date time.Time, hour, minute int) time.Time {
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+	if t.Hour() == hour && t.Minute() == minute {
+		return t
+	}
+	_, offset := t.Add(-3 * time.Hour).Zone()
+	wall := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, time.UTC)
+	return wall.Add(-time.Duration(offset) * time.Second).In(date.Location())
+}
+
+// daysIn returns the number of days in the month of date
+func daysIn(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// civilDay numbers the calendar day of date, whatever its location, so days can be
+// counted across daylight saving transitions
+func civilDay(date time.Time) int {
+	year, month, day := date.Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
@@ -0,0 +1,126 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// occurrences returns the first n occurrences of r after t, formatted in its location
+func occurrences(t *testing.T, r Recurrence, after time.Time, n int) []string {
+	t.Helper()
+	var result []string
+	for i := 0; i < n; i++ {
+		after = r.Next(after)
+		if after.IsZero() {
+			break
+		}
+		result = append(result, after.Format("2006-01-02 15:04 Mon"))
+	}
+	return result
+}
+
+func TestParse_Cron(t *testing.T) {
+	// Wednesday
+	start := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"*/15 * * * *", []string{"2025-01-15 10:45 Wed", "2025-01-15 11:00 Wed", "2025-01-15 11:15 Wed"}},
+		{"0 9 * * 1-5", []string{"2025-01-16 09:00 Thu", "2025-01-17 09:00 Fri", "2025-01-20 09:00 Mon"}},
+		{"30 8 1,15 * *", []string{"2025-02-01 08:30 Sat", "2025-02-15 08:30 Sat", "2025-03-01 08:30 Sat"}},
+		{"0 0 * feb sun", []string{"2025-02-02 00:00 Sun", "2025-02-09 00:00 Sun", "2025-02-16 00:00 Sun"}},
+		{"0 12 13 * 5", []string{"2025-01-17 12:00 Fri", "2025-01-24 12:00 Fri", "2025-01-31 12:00 Fri"}},
+		{"0 0 * * 7", []string{"2025-01-19 00:00 Sun", "2025-01-26 00:00 Sun"}},
+		{"5/20 10 * * *", []string{"2025-01-15 10:45 Wed", "2025-01-16 10:05 Thu"}},
+		{"@daily", []string{"2025-01-16 00:00 Thu", "2025-01-17 00:00 Fri"}},
+		{"@monthly", []string{"2025-02-01 00:00 Sat", "2025-03-01 00:00 Sat"}},
+		{"0 0 29 2 *", []string{"2028-02-29 00:00 Tue"}},
+		{"0 0 30 2 *", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			r, err := Parse(tt.expr, "", start, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, occurrences(t, r, start, len(tt.want)+1)[:len(tt.want)])
+		})
+	}
+}
+
+func TestParse_CronInvalid(t *testing.T) {
+	for _, expr := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday", "@weekdays"} {
+		_, err := Parse(expr, "", time.Now(), time.UTC)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestParse_CronTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	r, err := Parse("30 2 * * *", "", time.Time{}, newYork)
+	require.NoError(t, err)
+
+	// 02:30 does not exist on the day clocks spring forward and runs an hour later
+	after := time.Date(2025, 3, 8, 12, 0, 0, 0, newYork)
+	assert.Equal(t, []string{"2025-03-09 03:30 Sun", "2025-03-10 02:30 Mon"}, occurrences(t, r, after, 2))
+	assert.Equal(t, time.Date(2025, 3, 10, 6, 30, 0, 0, time.UTC), r.Next(after.AddDate(0, 0, 1)).UTC())
+}
+
+func TestParse_RRule(t *testing.T) {
+	// Wednesday
+	start := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"FREQ=DAILY", []string{"2025-01-15 09:30 Wed", "2025-01-16 09:30 Thu", "2025-01-17 09:30 Fri"}},
+		{"RRULE:FREQ=DAILY;INTERVAL=3;BYHOUR=8,17;BYMINUTE=0", []string{"2025-01-15 17:00 Wed", "2025-01-18 08:00 Sat", "2025-01-18 17:00 Sat"}},
+		{"FREQ=WEEKLY", []string{"2025-01-15 09:30 Wed", "2025-01-22 09:30 Wed"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", []string{"2025-01-17 09:30 Fri", "2025-01-27 09:30 Mon", "2025-01-31 09:30 Fri", "2025-02-10 09:30 Mon"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", []string{"2025-01-31 09:30 Fri", "2025-02-28 09:30 Fri", "2025-03-28 09:30 Fri"}},
+		{"FREQ=MONTHLY;BYDAY=2TU;BYHOUR=10;BYMINUTE=0", []string{"2025-02-11 10:00 Tue", "2025-03-11 10:00 Tue"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", []string{"2025-01-31 09:30 Fri", "2025-02-28 09:30 Fri", "2025-03-31 09:30 Mon"}},
+		{"FREQ=MONTHLY;INTERVAL=2", []string{"2025-01-15 09:30 Wed", "2025-03-15 09:30 Sat"}},
+		{"FREQ=YEARLY", []string{"2025-01-15 09:30 Wed", "2026-01-15 09:30 Thu"}},
+		{"FREQ=YEARLY;BYMONTH=3,9;BYMONTHDAY=1", []string{"2025-03-01 09:30 Sat", "2025-09-01 09:30 Mon", "2026-03-01 09:30 Sun"}},
+		{"FREQ=HOURLY;INTERVAL=5", []string{"2025-01-15 09:30 Wed", "2025-01-15 14:30 Wed", "2025-01-15 19:30 Wed", "2025-01-16 00:30 Thu"}},
+		{"FREQ=MINUTELY;INTERVAL=45;BYHOUR=9,10", []string{"2025-01-15 09:30 Wed", "2025-01-15 10:15 Wed", "2025-01-16 09:30 Thu"}},
+		{"FREQ=DAILY;COUNT=2", []string{"2025-01-15 09:30 Wed", "2025-01-16 09:30 Thu"}},
+		{"FREQ=DAILY;UNTIL=20250116", []string{"2025-01-15 09:30 Wed", "2025-01-16 09:30 Thu"}},
+		{"FREQ=DAILY;UNTIL=20250116T090000Z", []string{"2025-01-15 09:30 Wed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := Parse("", tt.rule, start, time.UTC)
+			require.NoError(t, err)
+			got := occurrences(t, r, start.Add(-time.Hour), len(tt.want)+1)
+			if len(got) > len(tt.want) {
+				got = got[:len(tt.want)]
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_RRuleCountsFromStart(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+	r, err := Parse("", "FREQ=DAILY;COUNT=3", start, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-01-17 09:30 Fri"}, occurrences(t, r, start.AddDate(0, 0, 1), 2))
+	assert.True(t, r.Next(start.AddDate(0, 0, 3)).IsZero())
+}
+
+func TestParse_RRuleInvalid(t *testing.T) {
+	for _, rule := range []string{"", "INTERVAL=2", "FREQ=SECONDLY", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYDAY=XX", "FREQ=DAILY;BYHOUR=24", "FREQ=DAILY;BYSETPOS=1", "FREQ=DAILY;UNTIL=tomorrow", "FREQ"} {
+		_, err := Parse("", rule, time.Now(), time.UTC)
+		assert.Error(t, err, rule)
+	}
+	_, err := Parse("@daily", "FREQ=DAILY", time.Now(), time.UTC)
+	assert.ErrorContains(t, err, "only one")
+}
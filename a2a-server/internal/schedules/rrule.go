@@ -0,0 +1,313 @@
+package schedules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies of an RRULE
+const (
+	FreqMinutely = "MINUTELY"
+	FreqHourly   = "HOURLY"
+	FreqDaily    = "DAILY"
+	FreqWeekly   = "WEEKLY"
+	FreqMonthly  = "MONTHLY"
+	FreqYearly   = "YEARLY"
+)
+
+// maxCount bounds the COUNT of an RRULE, whose occurrences are counted from its start
+const maxCount = 10000
+
+// weekdayCodes are the RRULE names of the days of the week
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// byDay is a BYDAY entry: a day of the week, and with MONTHLY, which one of the month
+// (1 the first, -1 the last; 0 every one)
+type byDay struct {
+	weekday time.Weekday
+	n       int
+}
+
+// rrule is a parsed RFC 5545 recurrence rule. It supports FREQ from MINUTELY to YEARLY,
+// INTERVAL, COUNT, UNTIL, WKST and BYMONTH, BYMONTHDAY, BYDAY, BYHOUR and BYMINUTE, which
+// all narrow the occurrences; BYSETPOS, BYWEEKNO, BYYEARDAY and BYSECOND are not supported.
+type rrule struct {
+	freq      string
+	interval  int
+	count     int
+	until     time.Time
+	weekStart time.Weekday
+	start     time.Time
+
+	minutes, hours, months bits
+	// monthDays holds positive BYMONTHDAY values and lastDays negative ones, counted
+	// from the end of the month
+	monthDays, lastDays bits
+	weekdays            []byDay
+}
+
+// parseRRule parses an RRULE, with or without its "RRULE:" prefix, whose occurrences
+// start at start
+func parseRRule(rule string, start time.Time) (*rrule, error) {
+	r := &rrule{interval: 1, weekStart: time.Monday, start: start.Truncate(time.Minute)}
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	var hasMinutes, hasHours bool
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(value)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && (r.count <= 0 || r.count > maxCount) {
+				err = fmt.Errorf("must be from 1 to %d", maxCount)
+			}
+		case "UNTIL":
+			r.until, err = parseUntil(value, start.Location())
+		case "WKST":
+			var ok bool
+			if r.weekStart, ok = weekdayCodes[strings.ToUpper(value)]; !ok {
+				err = fmt.Errorf("unknown day %q", value)
+			}
+		case "BYMINUTE":
+			hasMinutes = true
+			r.minutes, err = parseInts(value, 0, 59)
+		case "BYHOUR":
+			hasHours = true
+			r.hours, err = parseInts(value, 0, 23)
+		case "BYMONTH":
+			r.months, err = parseInts(value, 1, 12)
+		case "BYMONTHDAY":
+			r.monthDays, r.lastDays, err = parseMonthDays(value)
+		case "BYDAY":
+			r.weekdays, err = parseByDay(value)
+		default:
+			return nil, fmt.Errorf("rrule part %s is not supported", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rrule %s: %w", key, err)
+		}
+	}
+
+	switch r.freq {
+	case FreqMinutely, FreqHourly, FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+	case "":
+		return nil, fmt.Errorf("rrule FREQ is required")
+	default:
+		return nil, fmt.Errorf("rrule FREQ %s is not supported", r.freq)
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, fmt.Errorf("rrule COUNT and UNTIL cannot both be set")
+	}
+	for _, day := range r.weekdays {
+		if day.n != 0 && r.freq != FreqMonthly {
+			return nil, fmt.Errorf("rrule BYDAY ordinals such as %d%s need FREQ=MONTHLY", day.n, day.weekday)
+		}
+	}
+
+	// Parts left out default to the start's time and, for the longer frequencies, its day
+	if !hasMinutes && r.freq != FreqMinutely {
+		r.minutes = 1 << uint(r.start.Minute())
+	}
+	if !hasMinutes && r.freq == FreqMinutely {
+		r.minutes = span(0, 59)
+	}
+	if !hasHours && (r.freq == FreqMinutely || r.freq == FreqHourly) {
+		r.hours = span(0, 23)
+	} else if !hasHours {
+		r.hours = 1 << uint(r.start.Hour())
+	}
+	if len(r.weekdays) == 0 && r.monthDays == 0 && r.lastDays == 0 {
+		switch r.freq {
+		case FreqWeekly:
+			r.weekdays = []byDay{{weekday: r.start.Weekday()}}
+		case FreqMonthly:
+			r.monthDays = 1 << uint(r.start.Day())
+		case FreqYearly:
+			r.monthDays = 1 << uint(r.start.Day())
+			if r.months == 0 {
+				r.months = 1 << uint(r.start.Month())
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseUntil parses an UNTIL date-time in UTC (20250131T170000Z) or loc
+// (20250131T170000), or a date, which includes the whole day
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("20060102", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// parseInts parses a comma-separated list of values from lo to hi
+func parseInts(value string, lo, hi int) (bits, error) {
+	var set bits
+	for _, s := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < lo || i > hi {
+			return 0, fmt.Errorf("%q is not a value from %d to %d", s, lo, hi)
+		}
+		set |= 1 << uint(i)
+	}
+	return set, nil
+}
+
+// parseMonthDays parses BYMONTHDAY, whose negative values count from the end of the month
+func parseMonthDays(value string) (bits, bits, error) {
+	var days, lastDays bits
+	for _, s := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(s)
+		switch {
+		case err != nil || i == 0 || i < -31 || i > 31:
+			return 0, 0, fmt.Errorf("%q is not a day of the month", s)
+		case i > 0:
+			days |= 1 << uint(i)
+		default:
+			lastDays |= 1 << uint(-i)
+		}
+	}
+	return days, lastDays, nil
+}
+
+// parseByDay parses BYDAY days of the week, each with an optional ordinal, e.g. MO,-1FR
+func parseByDay(value string) ([]byDay, error) {
+	var days []byDay
+	for _, s := range strings.Split(value, ",") {
+		s = strings.ToUpper(s)
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid day %q", s)
+		}
+		weekday, ok := weekdayCodes[s[len(s)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", s)
+		}
+		day := byDay{weekday: weekday}
+		if ordinal := s[:len(s)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid day %q", s)
+			}
+			day.n = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Next returns the first occurrence of the rule after t
+func (r *rrule) Next(t time.Time) time.Time {
+	if t.Before(r.start) {
+		t = r.start.Add(-time.Nanosecond)
+	}
+	if r.count == 0 {
+		return r.bounded(next(r, t, r.start.Location()))
+	}
+
+	// Count the occurrences from the start
+	occurrence := r.start.Add(-time.Nanosecond)
+	for i := 0; i < r.count; i++ {
+		occurrence = r.bounded(next(r, occurrence, r.start.Location()))
+		if occurrence.IsZero() || occurrence.After(t) {
+			return occurrence
+		}
+	}
+	return time.Time{}
+}
+
+// bounded returns the zero time for occurrences after UNTIL
+func (r *rrule) bounded(t time.Time) time.Time {
+	if !r.until.IsZero() && t.After(r.until) {
+		return time.Time{}
+	}
+	return t
+}
+
+func (r *rrule) day(date time.Time) bool {
+	days := civilDay(date) - civilDay(r.start)
+	if days < 0 || (r.months != 0 && !r.months.has(int(date.Month()))) {
+		return false
+	}
+	if (r.monthDays != 0 || r.lastDays != 0) &&
+		!r.monthDays.has(date.Day()) && !r.lastDays.has(daysIn(date)-date.Day()+1) {
+		return false
+	}
+	if len(r.weekdays) > 0 && !r.matchesWeekday(date) {
+		return false
+	}
+
+	switch r.freq {
+	case FreqDaily:
+		return days%r.interval == 0
+	case FreqWeekly:
+		return (r.weekOf(date)-r.weekOf(r.start))/7%r.interval == 0
+	case FreqMonthly:
+		return monthsBetween(r.start, date)%r.interval == 0
+	case FreqYearly:
+		return (date.Year()-r.start.Year())%r.interval == 0
+	}
+	return true
+}
+
+// matchesWeekday reports whether date is one of the BYDAY days
+func (r *rrule) matchesWeekday(date time.Time) bool {
+	for _, day := range r.weekdays {
+		if day.weekday != date.Weekday() {
+			continue
+		}
+		switch {
+		case day.n == 0,
+			day.n > 0 && (date.Day()-1)/7+1 == day.n,
+			day.n < 0 && (daysIn(date)-date.Day())/7+1 == -day.n:
+			return true
+		}
+	}
+	return false
+}
+
+// weekOf returns the civil day the week of date starts on
+func (r *rrule) weekOf(date time.Time) int {
+	return civilDay(date) - (int(date.Weekday())-int(r.weekStart)+7)%7
+}
+
+func (r *rrule) clock(date time.Time, hour, minute int) bool {
+	if !r.hours.has(hour) || !r.minutes.has(minute) {
+		return false
+	}
+	hours := (civilDay(date)-civilDay(r.start))*24 + hour - r.start.Hour()
+	switch r.freq {
+	case FreqHourly:
+		return hours%r.interval == 0
+	case FreqMinutely:
+		return (hours*60+minute-r.start.Minute())%r.interval == 0
+	}
+	return true
+}
+
+// monthsBetween counts the calendar months from the month of from to that of to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
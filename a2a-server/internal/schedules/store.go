@@ -0,0 +1,282 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/google/uuid"
+)
+
+// ErrScheduleNotFound is returned when no schedule has the requested ID
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// ErrRunClaimed is returned by Claim when the run is no longer due, because another
+// runner materialized it or the schedule was changed meanwhile
+var ErrRunClaimed = errors.New("schedule run already claimed")
+
+// Schedule creates a task for a user on a cron expression or RRULE
+type Schedule struct {
+	ID         string                 `json:"id"`
+	UserID     string                 `json:"user_id"`
+	AgentID    string                 `json:"agent_id"`
+	Capability string                 `json:"capability"`
+	Input      map[string]interface{} `json:"input,omitempty"`
+	Priority   protocol.TaskPriority  `json:"priority,omitempty"`
+	MaxCostUSD float64                `json:"max_cost_usd,omitempty"`
+	// Cron is a five-field cron expression, e.g. "0 9 * * 1-5", or a macro such as
+	// @daily; exactly one of Cron and RRule is set
+	Cron string `json:"cron,omitempty"`
+	// RRule is an RFC 5545 recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9"
+	RRule string `json:"rrule,omitempty"`
+	// Timezone is the IANA time zone the schedule is evaluated in (default UTC)
+	Timezone string `json:"timezone,omitempty"`
+	// StartAt is when the schedule starts; RRULE occurrences count from it and default
+	// their time of day to it
+	StartAt time.Time `json:"start_at"`
+	Paused  bool      `json:"paused,omitempty"`
+
+	// NextRunAt is when the next task is created; nil once the schedule has no more runs
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	// LastRunAt is when a task was last due, and LastTaskID the task created then, or
+	// LastError why none was, e.g. an exhausted budget
+	LastRunAt  time.Time `json:"last_run_at,omitempty"`
+	LastTaskID string    `json:"last_task_id,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	// Runs counts the tasks created
+	Runs int `json:"runs"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewSchedule creates a schedule with a new ID
+func NewSchedule(userID, agentID, capability string) *Schedule {
+	now := time.Now()
+	return &Schedule{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		AgentID:    agentID,
+		Capability: capability,
+		StartAt:    now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Recurrence parses the schedule's cron expression or RRULE in its time zone
+func (s *Schedule) Recurrence() (Recurrence, error) {
+	loc := time.UTC
+	if s.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", s.Timezone)
+		}
+	}
+	return Parse(s.Cron, s.RRule, s.StartAt, loc)
+}
+
+// Plan sets the schedule's next run to its first occurrence after now, or after its start
+// if that is later. It fails if the recurrence is invalid.
+func (s *Schedule) Plan(now time.Time) error {
+	recurrence, err := s.Recurrence()
+	if err != nil {
+		return err
+	}
+	if s.StartAt.After(now) {
+		now = s.StartAt.Add(-time.Nanosecond)
+	}
+	s.NextRunAt = nil
+	if next := recurrence.Next(now); !next.IsZero() {
+		s.NextRunAt = &next
+	}
+	return nil
+}
+
+// Run is the outcome of a run of a schedule
+type Run struct {
+	// At is when the run was due
+	At time.Time
+	// TaskID is the task created, empty if Error prevented it
+	TaskID string
+	Error  string
+}
+
+// Store keeps schedules and tracks their runs
+type Store interface {
+	Create(ctx context.Context, schedule *Schedule) error
+	Get(ctx context.Context, id string) (*Schedule, error)
+	// Update replaces the definition of a schedule and its next run, keeping its run history
+	Update(ctx context.Context, schedule *Schedule) error
+	Delete(ctx context.Context, id string) error
+	// List returns the user's schedules, or every schedule for an empty userID, oldest first
+	List(ctx context.Context, userID string, limit, offset int) ([]*Schedule, error)
+	// Due returns up to limit schedules that are not paused and whose next run is at or
+	// before now, earliest first
+	Due(ctx context.Context, now time.Time, limit int) ([]*Schedule, error)
+	// Claim moves the next run of a schedule from due to next, nil when it has no more
+	// runs, so only one runner materializes the run. It returns ErrRunClaimed if the
+	// schedule's next run is no longer due or it was paused.
+	Claim(ctx context.Context, id string, due time.Time, next *time.Time) error
+	// RecordRun records the outcome of a claimed run
+	RecordRun(ctx context.Context, id string, run Run) error
+}
+
+// MemoryStore keeps schedules in memory. It hands out copies, so callers may change the
+// schedules they get.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	schedules map[string]*Schedule
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory schedule store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{schedules: make(map[string]*Schedule)}
+}
+
+// clone copies a schedule and what its fields point to
+func clone(schedule *Schedule) *Schedule {
+	c := *schedule
+	c.Input = maps.Clone(schedule.Input)
+	if schedule.NextRunAt != nil {
+		next := *schedule.NextRunAt
+		c.NextRunAt = &next
+	}
+	return &c
+}
+
+// Create stores a new schedule
+func (s *MemoryStore) Create(ctx context.Context, schedule *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.schedules[schedule.ID]; exists {
+		return fmt.Errorf("schedule %s already exists", schedule.ID)
+	}
+	s.schedules[schedule.ID] = clone(schedule)
+	return nil
+}
+
+// Get retrieves a schedule by ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schedule, exists := s.schedules[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return clone(schedule), nil
+}
+
+// Update replaces a schedule's definition and next run
+func (s *MemoryStore) Update(ctx context.Context, schedule *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.schedules[schedule.ID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, schedule.ID)
+	}
+	updated := clone(schedule)
+	updated.LastRunAt, updated.LastTaskID, updated.LastError, updated.Runs =
+		stored.LastRunAt, stored.LastTaskID, stored.LastError, stored.Runs
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = time.Now()
+	s.schedules[schedule.ID] = updated
+	return nil
+}
+
+// Delete deletes a schedule
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.schedules[id]; !exists {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	delete(s.schedules, id)
+	return nil
+}
+
+// List returns the user's schedules, or every schedule, oldest first
+func (s *MemoryStore) List(ctx context.Context, userID string, limit, offset int) ([]*Schedule, error) {
+	return s.sorted(func(schedule *Schedule) bool {
+		return userID == "" || schedule.UserID == userID
+	}, func(a, b *Schedule) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}, limit, offset), nil
+}
+
+// Due returns the schedules whose next run is due, earliest first
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]*Schedule, error) {
+	return s.sorted(func(schedule *Schedule) bool {
+		return !schedule.Paused && schedule.NextRunAt != nil && !schedule.NextRunAt.After(now)
+	}, func(a, b *Schedule) bool {
+		return a.NextRunAt.Before(*b.NextRunAt)
+	}, limit, 0), nil
+}
+
+// sorted returns copies of the page of schedules that match, in order
+func (s *MemoryStore) sorted(match func(*Schedule) bool, less func(a, b *Schedule) bool, limit, offset int) []*Schedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*Schedule
+	for _, schedule := range s.schedules {
+		if match(schedule) {
+			result = append(result, schedule)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+
+	if offset >= len(result) {
+		return []*Schedule{}
+	}
+	result = result[offset:]
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	page := make([]*Schedule, len(result))
+	for i, schedule := range result {
+		page[i] = clone(schedule)
+	}
+	return page
+}
+
+// Claim moves a due run of a schedule to next
+func (s *MemoryStore) Claim(ctx context.Context, id string, due time.Time, next *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedule, exists := s.schedules[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	if schedule.Paused || schedule.NextRunAt == nil || !schedule.NextRunAt.Equal(due) {
+		return ErrRunClaimed
+	}
+	schedule.NextRunAt = nil
+	if next != nil {
+		t := *next
+		schedule.NextRunAt = &t
+	}
+	schedule.UpdatedAt = time.Now()
+	return nil
+}
+
+// RecordRun records the outcome of a run
+func (s *MemoryStore) RecordRun(ctx context.Context, id string, run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedule, exists := s.schedules[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	schedule.LastRunAt, schedule.LastTaskID, schedule.LastError = run.At, run.TaskID, run.Error
+	if run.TaskID != "" {
+		schedule.Runs++
+	}
+	return nil
+}
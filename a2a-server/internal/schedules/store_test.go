@@ -0,0 +1,111 @@
+package schedules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Plan(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	schedule := NewSchedule("user-1", "agent-1", "search")
+	schedule.Cron = "0 9 * * *"
+	schedule.Timezone = "Asia/Tokyo"
+	schedule.StartAt = now
+	require.NoError(t, schedule.Plan(now))
+	require.NotNil(t, schedule.NextRunAt)
+	assert.Equal(t, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), schedule.NextRunAt.UTC())
+
+	// A schedule starting later runs from its start
+	schedule.StartAt = now.AddDate(0, 1, 0)
+	require.NoError(t, schedule.Plan(now))
+	assert.Equal(t, time.Date(2025, 2, 16, 0, 0, 0, 0, time.UTC), schedule.NextRunAt.UTC())
+
+	// One whose rule ended has no next run
+	schedule.Cron, schedule.RRule = "", "FREQ=DAILY;COUNT=1"
+	require.NoError(t, schedule.Plan(schedule.StartAt.Add(time.Minute)))
+	assert.Nil(t, schedule.NextRunAt)
+
+	schedule.Timezone = "Mars/Olympus_Mons"
+	assert.ErrorContains(t, schedule.Plan(now), "unknown timezone")
+}
+
+func TestMemoryStore_Schedules(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Now().Truncate(time.Minute)
+
+	due := NewSchedule("alice", "agent-1", "search")
+	due.Cron = "* * * * *"
+	due.Input = map[string]interface{}{"query": "go"}
+	due.NextRunAt = &now
+	later := NewSchedule("bob", "agent-1", "search")
+	later.CreatedAt = due.CreatedAt.Add(time.Second)
+	nextHour := now.Add(time.Hour)
+	later.NextRunAt = &nextHour
+	for _, schedule := range []*Schedule{due, later} {
+		require.NoError(t, store.Create(ctx, schedule))
+	}
+	assert.Error(t, store.Create(ctx, due))
+
+	// Stored schedules are copies
+	got, err := store.Get(ctx, due.ID)
+	require.NoError(t, err)
+	got.Input["query"] = "changed"
+	got, err = store.Get(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "go", got.Input["query"])
+
+	all, err := store.List(ctx, "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, due.ID, all[0].ID)
+	bobs, err := store.List(ctx, "bob", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, bobs, 1)
+	assert.Equal(t, later.ID, bobs[0].ID)
+
+	list, err := store.Due(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, due.ID, list[0].ID)
+
+	// Only one claim of a run succeeds
+	next := now.Add(time.Minute)
+	require.NoError(t, store.Claim(ctx, due.ID, now, &next))
+	assert.ErrorIs(t, store.Claim(ctx, due.ID, now, &next), ErrRunClaimed)
+	list, err = store.Due(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, store.RecordRun(ctx, due.ID, Run{At: now, TaskID: "task-1"}))
+	require.NoError(t, store.RecordRun(ctx, due.ID, Run{At: next, Error: "budget exceeded"}))
+	got, err = store.Get(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Runs)
+	assert.Equal(t, next, got.LastRunAt)
+	assert.Empty(t, got.LastTaskID)
+	assert.Equal(t, "budget exceeded", got.LastError)
+
+	// Updates keep the run history; paused schedules are not due or claimed
+	got.Paused = true
+	got.Runs = 0
+	require.NoError(t, store.Update(ctx, got))
+	got, err = store.Get(ctx, due.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Paused)
+	assert.Equal(t, 1, got.Runs)
+	list, err = store.Due(ctx, next, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	assert.ErrorIs(t, store.Claim(ctx, due.ID, next, nil), ErrRunClaimed)
+
+	require.NoError(t, store.Delete(ctx, due.ID))
+	_, err = store.Get(ctx, due.ID)
+	assert.ErrorIs(t, err, ErrScheduleNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, due.ID), ErrScheduleNotFound)
+	assert.ErrorIs(t, store.Update(ctx, due), ErrScheduleNotFound)
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+)
+
+// AdminBudgetsPath is the endpoint prefix for provisioning user budgets
+const AdminBudgetsPath = "/admin/budgets/"
+
+// AdminTenantBudgetsPath is the endpoint prefix for provisioning tenant budgets
+const AdminTenantBudgetsPath = "/admin/tenant-budgets/"
+
+// AdminBudgetSimulationPath is the endpoint for dry runs of budget policy changes
+const AdminBudgetSimulationPath = "/admin/simulations/budgets"
+
+// AdminCostsPath is the endpoint aggregating recorded usage for reporting and billing
+const AdminCostsPath = "/admin/costs"
+
+// SetBudgetRequest is the request body of PUT /admin/budgets/{user_id}
+type SetBudgetRequest struct {
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+	// TenantID, when set, makes the budget a sub-budget of the tenant's; otherwise the
+	// user keeps the tenant it belongs to
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// SetTenantBudgetRequest is the request body of PUT /admin/tenant-budgets/{tenant_id}
+type SetTenantBudgetRequest struct {
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+}
+
+// SetAdminToken enables the admin endpoints for callers that present token as a bearer
+// token, such as the MCP server's tenant onboarding. Empty disables them.
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// SetBillingToken lets callers that present token as a bearer token read budgets and
+// usage without the admin token, such as a billing system or the demo UI. While neither
+// token is configured, budgets and usage can be read by anyone.
+func (s *Server) SetBillingToken(token string) {
+	s.billingToken = token
+}
+
+// authorizeBilling checks the caller presents the billing or admin token, writing the
+// error response if not
+func (s *Server) authorizeBilling(w http.ResponseWriter, r *http.Request) bool {
+	if s.adminToken == "" && s.billingToken == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	for _, want := range []string{s.adminToken, s.billingToken} {
+		if ok && want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return true
+		}
+	}
+	http.Error(w, "Invalid billing token", http.StatusUnauthorized)
+	return false
+}
+
+// authorizeAdmin checks the caller presents the admin token, writing the error response
+// if not. The admin endpoints are not found while no token is configured.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.adminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// handleSetBudget handles PUT /admin/budgets/{user_id}, which sets a user's monthly
+// budget and resets its current spend
+func (s *Server) handleSetBudget(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := strings.Trim(strings.TrimPrefix(r.URL.Path, AdminBudgetsPath), "/")
+	if userID == "" || strings.Contains(userID, "/") {
+		http.Error(w, "User ID required", http.StatusBadRequest)
+		return
+	}
+	s.setBudget(w, r, userID)
+}
+
+// setBudget sets a user's budget from a SetBudgetRequest body and responds with it
+func (s *Server) setBudget(w http.ResponseWriter, r *http.Request, userID string) {
+	var req SetBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.MonthlyLimitUSD < 0 {
+		http.Error(w, "monthly_limit_usd must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	var err error
+	if req.TenantID != "" {
+		err = s.budgetManager.SetUserBudget(ctx, req.TenantID, userID, req.MonthlyLimitUSD)
+	} else {
+		err = s.budgetManager.SetBudget(ctx, userID, req.MonthlyLimitUSD)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	budget, err := s.budgetManager.GetBudget(ctx, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// handleSetTenantBudget handles PUT /admin/tenant-budgets/{tenant_id}, which sets the
+// monthly limit on the combined spend of a tenant's users. Lowering it below the current
+// spend also fails the tenant's pending tasks when they come up.
+func (s *Server) handleSetTenantBudget(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID := strings.Trim(strings.TrimPrefix(r.URL.Path, AdminTenantBudgetsPath), "/")
+	if tenantID == "" || strings.Contains(tenantID, "/") {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req SetTenantBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.MonthlyLimitUSD < 0 {
+		http.Error(w, "monthly_limit_usd must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if err := s.budgetManager.SetTenantBudget(ctx, tenantID, req.MonthlyLimitUSD); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	budget, err := s.budgetManager.GetTenantBudget(ctx, tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// SimulateBudgetsRequest is the request body of POST /admin/simulations/budgets
+type SimulateBudgetsRequest struct {
+	cost.BudgetPolicy
+	// Window of recorded charges to replay; defaults to the last 24 hours
+	Since time.Time `json:"since,omitempty"`
+	Until time.Time `json:"until,omitempty"`
+}
+
+// SetUsageJournal enables budget simulations, which replay the journal's recorded charges
+func (s *Server) SetUsageJournal(journal cost.Journal) {
+	s.usageJournal = journal
+}
+
+// handleSimulateBudgets handles POST /admin/simulations/budgets, which reports how many
+// recorded task charges proposed budget limits would have rejected, without changing any
+// budget
+func (s *Server) handleSimulateBudgets(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.usageJournal == nil {
+		http.Error(w, "Budget simulation requires USAGE_JOURNAL_PATH", http.StatusNotImplemented)
+		return
+	}
+
+	var req SimulateBudgetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.LimitsUSD) == 0 && req.DefaultLimitUSD == nil {
+		http.Error(w, "limits_usd or default_limit_usd is required", http.StatusBadRequest)
+		return
+	}
+	for _, limit := range req.LimitsUSD {
+		if limit < 0 {
+			http.Error(w, "Limits must not be negative", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.DefaultLimitUSD != nil && *req.DefaultLimitUSD < 0 {
+		http.Error(w, "Limits must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.Until.IsZero() {
+		req.Until = time.Now()
+	}
+	if req.Since.IsZero() {
+		req.Since = req.Until.Add(-24 * time.Hour)
+	}
+	if !req.Since.Before(req.Until) {
+		http.Error(w, "since must be before until", http.StatusBadRequest)
+		return
+	}
+
+	sim, err := cost.SimulateBudgets(r.Context(), s.usageJournal, req.BudgetPolicy, req.Since, req.Until)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sim)
+}
+
+// CostReport is the response of GET /admin/costs
+type CostReport struct {
+	GroupBy cost.Grouping     `json:"group_by"`
+	UserID  string            `json:"user_id,omitempty"`
+	Since   time.Time         `json:"since"`
+	Until   time.Time         `json:"until"`
+	Buckets []cost.CostBucket `json:"buckets"`
+}
+
+// handleCosts handles GET /admin/costs?group_by=day|model|capability, which aggregates the
+// usage recorded between since and until (RFC 3339; the last 30 days by default) for
+// user_id, or for all users without it
+func (s *Server) handleCosts(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	report := CostReport{GroupBy: cost.Grouping(query.Get("group_by")), UserID: query.Get("user_id")}
+	if report.GroupBy == "" {
+		report.GroupBy = cost.ByDay
+	}
+	if !report.GroupBy.Valid() {
+		http.Error(w, `group_by must be "day", "model" or "capability"`, http.StatusBadRequest)
+		return
+	}
+	var err error
+	if report.Since, report.Until, err = timeRange(query, "since", "until"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	buckets, err := s.costTracker.CostBy(r.Context(), report.UserID, report.Since, report.Until, report.GroupBy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	report.Buckets = buckets
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// timeRange parses the RFC 3339 times of the from and to query parameters, defaulting to
+// the last 30 days
+func timeRange(query url.Values, from, to string) (time.Time, time.Time, error) {
+	start, end := time.Time{}, time.Now()
+	for name, value := range map[string]*time.Time{from: &start, to: &end} {
+		if raw := query.Get(name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("%s must be an RFC 3339 time", name)
+			}
+			*value = parsed
+		}
+	}
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s must be before %s", from, to)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_SetBudget(t *testing.T) {
+	server := setupTestServer()
+
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		server.handleSetBudget(rr, req)
+		return rr
+	}
+	body := `{"monthly_limit_usd": 25}`
+
+	// Disabled until an admin token is configured
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "/admin/budgets/alice", "", body).Code)
+
+	server.SetAdminToken("secret")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "/admin/budgets/alice", "", body).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "/admin/budgets/alice", "wrong", body).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/admin/budgets/alice", "secret", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/admin/budgets/", "secret", body).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/admin/budgets/alice", "secret", `{"monthly_limit_usd": -1}`).Code)
+
+	rr := serve(http.MethodPut, "/admin/budgets/alice", "secret", body)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var budget cost.Budget
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&budget))
+	assert.Equal(t, "alice", budget.UserID)
+	assert.Equal(t, 25.0, budget.MonthlyLimitUSD)
+
+	stored, err := server.budgetManager.GetBudget(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, stored.MonthlyLimitUSD)
+}
+
+func TestServer_SetTenantBudget(t *testing.T) {
+	server := setupTestServer()
+	server.SetAdminToken("secret")
+	ctx := context.Background()
+
+	serve := func(handler http.HandlerFunc, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve(server.handleSetTenantBudget, AdminTenantBudgetsPath, `{"monthly_limit_usd": 5}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(server.handleSetTenantBudget, AdminTenantBudgetsPath+"acme", `{"monthly_limit_usd": -5}`).Code)
+
+	rr := serve(server.handleSetTenantBudget, AdminTenantBudgetsPath+"acme", `{"monthly_limit_usd": 5}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var tenant cost.Budget
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&tenant))
+	assert.Equal(t, "acme", tenant.TenantID)
+	assert.Equal(t, 5.0, tenant.MonthlyLimitUSD)
+
+	rr = serve(server.handleSetBudget, AdminBudgetsPath+"alice", `{"monthly_limit_usd": 2, "tenant_id": "acme"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	budget, err := server.budgetManager.GetBudget(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", budget.TenantID)
+}
+
+func TestServer_SimulateBudgets(t *testing.T) {
+	server := setupTestServer()
+	server.SetAdminToken("secret")
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, AdminBudgetSimulationPath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		server.handleSimulateBudgets(rr, req)
+		return rr
+	}
+	body := `{"limits_usd": {"alice": 1}}`
+
+	// Simulations replay the usage journal
+	assert.Equal(t, http.StatusNotImplemented, serve(body).Code)
+
+	ctx := context.Background()
+	journal, err := cost.OpenFileJournal(filepath.Join(t.TempDir(), "usage.journal"))
+	require.NoError(t, err)
+	defer journal.Close()
+	now := time.Now()
+	require.NoError(t, journal.Append(ctx, cost.JournalEntry{Type: cost.EntryBudget, UserID: "alice", LimitUSD: 10, Time: now.Add(-2 * time.Hour)}))
+	require.NoError(t, journal.Append(ctx, cost.JournalEntry{Type: cost.EntryCharge, UserID: "alice", TaskID: "task-1", AmountUSD: 0.8, Time: now.Add(-time.Hour)}))
+	require.NoError(t, journal.Append(ctx, cost.JournalEntry{Type: cost.EntryCharge, UserID: "alice", TaskID: "task-2", AmountUSD: 0.8, Time: now.Add(-time.Minute)}))
+	server.SetUsageJournal(journal)
+
+	assert.Equal(t, http.StatusBadRequest, serve(`{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(`{"default_limit_usd": -1}`).Code)
+
+	rr := serve(body)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var sim cost.BudgetSimulation
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&sim))
+	assert.Equal(t, 2, sim.Charges)
+	assert.Equal(t, 1, sim.Rejected)
+
+	// The simulation leaves budgets untouched
+	_, err = server.budgetManager.GetBudget(ctx, "alice")
+	assert.Error(t, err)
+}
+
+func TestServer_Costs(t *testing.T) {
+	server := setupTestServer()
+	server.SetAdminToken("secret")
+	serve := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, AdminCostsPath+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		server.handleCosts(rr, req)
+		return rr
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, server.costTracker.RecordUsage(ctx, cost.Usage{UserID: "alice", Capability: "search_papers", Model: "gpt-4", CostUSD: 0.5, Timestamp: now.Add(-time.Hour)}))
+	require.NoError(t, server.costTracker.RecordUsage(ctx, cost.Usage{UserID: "bob", Capability: "search_papers", Model: "gpt-4", CostUSD: 0.25, Timestamp: now.Add(-time.Hour)}))
+	require.NoError(t, server.costTracker.RecordUsage(ctx, cost.Usage{UserID: "alice", Capability: "analyze_code", Model: "gpt-4", CostUSD: 1, Timestamp: now.AddDate(0, -2, 0)}))
+
+	assert.Equal(t, http.StatusBadRequest, serve("?group_by=week").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("?since=yesterday").Code)
+
+	rr := serve("?group_by=capability")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var report CostReport
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	require.Len(t, report.Buckets, 1, "usage older than 30 days is left out by default")
+	assert.Equal(t, "search_papers", report.Buckets[0].Key)
+	assert.Equal(t, 0.75, report.Buckets[0].CostUSD)
+
+	since := now.AddDate(0, -3, 0).UTC().Format(time.RFC3339)
+	rr = serve("?group_by=capability&user_id=alice&since=" + since)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	report = CostReport{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	require.Len(t, report.Buckets, 2)
+	assert.Equal(t, "analyze_code", report.Buckets[0].Key)
+	assert.Equal(t, 0.5, report.Buckets[1].CostUSD)
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+)
+
+// DefaultArtifactInlineLimit is the size above which file parts are moved to the blob store
+const DefaultArtifactInlineLimit = 64 << 10
+
+// artifactsKey carries an execution's artifacts in its result map until the task is
+// completed; it never reaches the stored result
+const artifactsKey = "_artifacts"
+
+// artifactPartURI is where a file part moved to the blob store is downloaded from
+func artifactPartURI(taskID, artifactID string, index int) string {
+	return fmt.Sprintf("/tasks/%s/artifacts/%s/parts/%d", url.PathEscape(taskID), url.PathEscape(artifactID), index)
+}
+
+// artifactBlobKey is the blob store key of a file part
+func artifactBlobKey(taskID, artifactID string, index int) string {
+	return fmt.Sprintf("tasks/%s/%s/%d", taskID, artifactID, index)
+}
+
+// SetArtifactStore moves file parts larger than inlineLimit bytes to store when tasks
+// complete, keeping their download URI in the task instead. Without a store every part
+// stays inline.
+func (p *TaskProcessor) SetArtifactStore(store blobs.Store, inlineLimit int) {
+	p.blobs = store
+	p.inlineLimit = inlineLimit
+}
+
+// popArtifacts removes the artifacts an execution reported from its result
+func popArtifacts(result map[string]interface{}) []protocol.Artifact {
+	artifacts, _ := result[artifactsKey].([]protocol.Artifact)
+	delete(result, artifactsKey)
+	return artifacts
+}
+
+// storeArtifacts validates the artifacts of a task, naming those without an ID, and moves
+// their large file parts to the blob store
+func (p *TaskProcessor) storeArtifacts(ctx context.Context, task *protocol.Task, artifacts []protocol.Artifact) ([]protocol.Artifact, error) {
+	stored := make([]protocol.Artifact, 0, len(artifacts))
+	seen := make(map[string]bool)
+	for i, artifact := range artifacts {
+		if artifact.ArtifactID == "" {
+			artifact.ArtifactID = fmt.Sprintf("%s-artifact-%d", task.ID, i)
+		}
+		if strings.Contains(artifact.ArtifactID, "/") || seen[artifact.ArtifactID] {
+			return nil, fmt.Errorf("invalid artifact: artifactId %q must be unique and must not contain '/'", artifact.ArtifactID)
+		}
+		seen[artifact.ArtifactID] = true
+		if err := artifact.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid artifact: %w", err)
+		}
+
+		parts := make([]protocol.Part, len(artifact.Parts))
+		for j, part := range artifact.Parts {
+			if part.Kind == protocol.KindFile && p.blobs != nil && len(part.File.Bytes) > p.inlineLimit {
+				data := part.File.Bytes
+				key := artifactBlobKey(task.ID, artifact.ArtifactID, j)
+				if err := p.blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data))); err != nil {
+					return nil, fmt.Errorf("failed to store artifact %s: %w", artifact.ArtifactID, err)
+				}
+				file := *part.File
+				file.Bytes = nil
+				file.URI = artifactPartURI(task.ID, artifact.ArtifactID, j)
+				part.File = &file
+				slog.DebugContext(ctx, "Artifact part stored", "task_id", task.ID, "artifact_id", artifact.ArtifactID,
+					"part", j, "bytes", len(data))
+			}
+			parts[j] = part
+		}
+		artifact.Parts = parts
+		stored = append(stored, artifact)
+	}
+	return stored, nil
+}
+
+// SetArtifactStore serves the file parts the task processor moved to store
+func (s *Server) SetArtifactStore(store blobs.Store) {
+	s.blobs = store
+}
+
+// handleTaskArtifacts handles GET /tasks/{id}/artifacts, GET /tasks/{id}/artifacts/{artifact_id}
+// and GET /tasks/{id}/artifacts/{artifact_id}/parts/{index}. Artifacts are listed as in
+// A2A, with the task's result first; a part is returned as its raw content.
+func (s *Server) handleTaskArtifacts(w http.ResponseWriter, r *http.Request, taskID string, path []string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	task, err := s.taskStore.Get(r.Context(), taskID)
+	if err != nil {
+		writeTaskLookupError(w, err)
+		return
+	}
+	artifacts := protocol.ToA2ATask(task).Artifacts
+	if artifacts == nil {
+		artifacts = []protocol.Artifact{}
+	}
+
+	if len(path) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(artifacts)
+		return
+	}
+
+	var artifact *protocol.Artifact
+	for i := range artifacts {
+		if artifacts[i].ArtifactID == path[0] {
+			artifact = &artifacts[i]
+		}
+	}
+	if artifact == nil {
+		http.Error(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(path) == 1:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(artifact)
+	case len(path) == 3 && path[1] == "parts":
+		index, err := strconv.Atoi(path[2])
+		if err != nil || index < 0 || index >= len(artifact.Parts) {
+			http.Error(w, "Part not found", http.StatusNotFound)
+			return
+		}
+		s.writeArtifactPart(w, r, task.ID, artifact.ArtifactID, index, artifact.Parts[index])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeArtifactPart writes a part's content, streaming file parts kept in the blob store
+func (s *Server) writeArtifactPart(w http.ResponseWriter, r *http.Request, taskID, artifactID string, index int, part protocol.Part) {
+	w.Header().Set("Content-Type", part.MimeType())
+	switch part.Kind {
+	case protocol.KindText:
+		io.WriteString(w, part.Text)
+		return
+	case protocol.KindData:
+		json.NewEncoder(w).Encode(part.Data)
+		return
+	}
+
+	if part.File.Name != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", part.File.Name))
+	}
+	if part.File.Bytes != nil {
+		w.Header().Set("Content-Length", strconv.Itoa(len(part.File.Bytes)))
+		w.Write(part.File.Bytes)
+		return
+	}
+	if part.File.URI != artifactPartURI(taskID, artifactID, index) {
+		// Content an executor referenced elsewhere
+		http.Redirect(w, r, part.File.URI, http.StatusFound)
+		return
+	}
+	if s.blobs == nil {
+		http.Error(w, "Artifact storage is not configured", http.StatusNotFound)
+		return
+	}
+
+	blob, err := s.blobs.Get(r.Context(), artifactBlobKey(taskID, artifactID, index))
+	if errors.Is(err, blobs.ErrNotFound) {
+		http.Error(w, "Artifact content not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer blob.Close()
+	if _, err := io.Copy(w, blob); err != nil {
+		slog.WarnContext(r.Context(), "Artifact download interrupted", "task_id", taskID, "artifact_id", artifactID, "error", err)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskProcessor_StoresArtifacts(t *testing.T) {
+	ctx := context.Background()
+	server := setupTestServer()
+	store, err := blobs.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	server.SetArtifactStore(store)
+
+	large := bytes.Repeat([]byte{0xff, 0x00}, 100)
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{Name: "render"}, capabilities.ExecutorFunc(
+		func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+			return &capabilities.Result{
+				Output: map[string]interface{}{"pages": 2},
+				Artifacts: []protocol.Artifact{
+					{ArtifactID: "report", Name: "report", Parts: []protocol.Part{
+						{Kind: protocol.KindText, Text: "Summary"},
+						{Kind: protocol.KindFile, File: &protocol.FileContent{Name: "report.pdf", MimeType: "application/pdf", Bytes: large}},
+						{Kind: protocol.KindFile, File: &protocol.FileContent{Name: "notes.txt", Bytes: []byte("small")}},
+					}},
+					{Name: "stats", Parts: []protocol.Part{{Kind: protocol.KindData, Data: map[string]interface{}{"words": 42}}}},
+				},
+			}, nil
+		}))
+	processor := NewTaskProcessor(server.taskStore, time.Hour)
+	processor.SetExecutors(executors, cost.NewMemoryTracker())
+	processor.SetArtifactStore(store, 64)
+
+	task := protocol.NewTask("agent-1", "render", nil)
+	require.NoError(t, server.taskStore.Create(ctx, task))
+	processor.processTask(ctx, task)
+	require.Equal(t, protocol.TaskStateCompleted, task.State, task.Error)
+	assert.NotContains(t, task.Result, artifactsKey)
+	require.Len(t, task.Artifacts, 2)
+	assert.Equal(t, task.ID+"-artifact-1", task.Artifacts[1].ArtifactID, "artifacts without an ID are named")
+
+	file := task.Artifacts[0].Parts[1].File
+	assert.Nil(t, file.Bytes, "large parts move to the blob store")
+	assert.Equal(t, "/tasks/"+task.ID+"/artifacts/report/parts/1", file.URI)
+	assert.Equal(t, []byte("small"), task.Artifacts[0].Parts[2].File.Bytes)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux := http.NewServeMux()
+		server.RegisterRoutes(mux)
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+	base := "/tasks/" + task.ID + "/artifacts"
+
+	rr := get(base)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var artifacts []protocol.Artifact
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&artifacts))
+	require.Len(t, artifacts, 3)
+	assert.Equal(t, task.ID+"-result", artifacts[0].ArtifactID, "the result comes first")
+	assert.Equal(t, "report", artifacts[1].ArtifactID)
+
+	rr = get(base + "/report")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var artifact protocol.Artifact
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&artifact))
+	assert.Len(t, artifact.Parts, 3)
+
+	rr = get(base + "/report/parts/1")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="report.pdf"`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, large, rr.Body.Bytes())
+
+	rr = get(base + "/report/parts/2")
+	assert.Equal(t, "application/octet-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "small", rr.Body.String())
+	rr = get(base + "/report/parts/0")
+	assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Summary", rr.Body.String())
+	rr = get(base + "/" + task.ID + "-artifact-1/parts/0")
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"words": 42}`, rr.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get(base+"/missing").Code)
+	assert.Equal(t, http.StatusNotFound, get(base+"/report/parts/3").Code)
+	assert.Equal(t, http.StatusNotFound, get("/tasks/missing/artifacts").Code)
+
+	// Without the blob store the part cannot be served
+	server.SetArtifactStore(nil)
+	assert.Equal(t, http.StatusNotFound, get(base+"/report/parts/1").Code)
+}
+
+func TestTaskProcessor_RejectsInvalidArtifacts(t *testing.T) {
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{Name: "render"}, capabilities.ExecutorFunc(
+		func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+			return &capabilities.Result{Artifacts: []protocol.Artifact{
+				{ArtifactID: "report", Parts: []protocol.Part{{Kind: protocol.KindFile}}},
+			}}, nil
+		}))
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetExecutors(executors, nil)
+
+	task := protocol.NewTask("agent-1", "render", nil)
+	require.NoError(t, store.Create(ctx, task))
+	processor.processTask(ctx, task)
+	assert.Equal(t, protocol.TaskStateFailed, task.State)
+	assert.Contains(t, task.Error, "invalid artifact: artifact report part 0: file part requires a file")
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/shared/auth"
+	"github.com/bhatti/mcp-a2a-go/shared/ratelimit"
+)
+
+// SetAuth requires a valid JWT on the agent card, task, schedule and JSON-RPC routes.
+// Tasks and schedules are created for, and their cost charged to the budget of, the
+// token's user instead of the user_id the request names.
+func (s *Server) SetAuth(authenticator *auth.Middleware) {
+	s.auth = authenticator
+}
+
+// SetRateLimiter limits the requests each authenticated user makes to the routes SetAuth
+// protects; requests without a token are not limited
+func (s *Server) SetRateLimiter(limiter *ratelimit.Limiter) {
+	s.rateLimiter = limiter
+}
+
+// protect wraps the handler of a route callers authenticate on and are rate limited on
+func (s *Server) protect(handler http.HandlerFunc) http.Handler {
+	var h http.Handler = handler
+	if s.rateLimiter != nil {
+		h = s.rateLimiter.Handler(authenticatedUser, h)
+	}
+	if s.auth != nil {
+		h = s.auth.Handler(requireUser(h))
+	}
+	return h
+}
+
+// authenticatedUser returns the user of the request's token, or "" without one
+func authenticatedUser(r *http.Request) string {
+	userID, _ := auth.ExtractUserID(r.Context())
+	return userID
+}
+
+// requireUser refuses tokens without a user_id claim: tasks and budgets belong to users
+func requireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authenticatedUser(r) == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Token has no user_id claim", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// resolveUser returns the user a request acts for: the authenticated user when the
+// request carries a token, the user it names otherwise. Naming a user other than the
+// authenticated one is refused with errUserMismatch.
+func resolveUser(ctx context.Context, requested string) (string, error) {
+	userID, err := auth.ExtractUserID(ctx)
+	if err != nil {
+		return requested, nil
+	}
+	if requested != "" && requested != userID {
+		return "", errUserMismatch
+	}
+	return userID, nil
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
+	"github.com/bhatti/mcp-a2a-go/shared/auth"
+	"github.com/bhatti/mcp-a2a-go/shared/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTenantID = "11111111-1111-1111-1111-111111111111"
+
+// setupAuthServer requires JWTs signed with the returned key; user-1 and user-2 have budgets
+func setupAuthServer(t *testing.T) (*Server, *rsa.PrivateKey) {
+	server := setupJSONRPCServer(t)
+	require.NoError(t, server.budgetManager.SetBudget(context.Background(), "user-2", 10.0))
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	validator, err := auth.NewJWTValidator(auth.Config{
+		PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+		Issuer:       "mcp-server-demo",
+		Audience:     "mcp-server",
+	})
+	require.NoError(t, err)
+	server.SetAuth(auth.NewMiddleware(validator))
+	return server, privateKey
+}
+
+// serve sends a request through the server's routes, with a token for user when it is set
+func serve(t *testing.T, server *Server, privateKey *rsa.PrivateKey, user, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if user != "" {
+		token, err := auth.GenerateDemoToken(testTenantID, user, nil, privateKey)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestServer_Auth(t *testing.T) {
+	server, privateKey := setupAuthServer(t)
+	ctx := context.Background()
+
+	rr := serve(t, server, privateKey, "", http.MethodPost, "/tasks", `{"user_id":"user-1","agent_id":"test-agent","capability":"search"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+
+	// Tokens must name a user
+	token, err := auth.GenerateDemoToken(testTenantID, "", nil, privateKey)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr = httptest.NewRecorder()
+	server.protect(server.handleListTasks).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// The task is created for, and charged to, the token's user
+	rr = serve(t, server, privateKey, "user-2", http.MethodPost, "/tasks", `{"agent_id":"test-agent","capability":"search"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var task protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+	assert.Equal(t, "user-2", task.UserID)
+
+	// Naming another user is refused
+	rr = serve(t, server, privateKey, "user-2", http.MethodPost, "/tasks", `{"user_id":"user-1","agent_id":"test-agent","capability":"search"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = serve(t, server, privateKey, "user-2", http.MethodPost, TaskEstimatePath, `{"user_id":"user-1","agent_id":"test-agent","capability":"search"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Users list their own tasks only
+	other := protocol.NewTask("test-agent", "search", nil)
+	other.UserID = "user-1"
+	require.NoError(t, server.taskStore.Create(ctx, other))
+	rr = serve(t, server, privateKey, "user-2", http.MethodGet, "/tasks", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list []protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.Equal(t, task.ID, list[0].ID)
+	rr = serve(t, server, privateKey, "user-2", http.MethodGet, "/tasks?user_id=user-1", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Schedules are created for the token's user too
+	server.SetSchedules(schedules.NewMemoryStore())
+	schedule := `{"agent_id":"test-agent","capability":"search","cron":"@daily"`
+	rr = serve(t, server, privateKey, "", http.MethodPost, SchedulesPath, schedule+`,"user_id":"user-1"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	rr = serve(t, server, privateKey, "user-2", http.MethodPost, SchedulesPath, schedule+`,"user_id":"user-1"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = serve(t, server, privateKey, "user-2", http.MethodPost, SchedulesPath, schedule+`}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created schedules.Schedule
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Equal(t, "user-2", created.UserID)
+	rr = serve(t, server, privateKey, "user-2", http.MethodGet, SchedulesPath+"?user_id=user-1", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Users reach their own schedules only
+	otherSchedule := schedules.NewSchedule("user-1", "test-agent", "search")
+	require.NoError(t, server.schedules.Create(ctx, otherSchedule))
+	rr = serve(t, server, privateKey, "user-2", http.MethodGet, SchedulesPath, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var ownSchedules []schedules.Schedule
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&ownSchedules))
+	require.Len(t, ownSchedules, 1)
+	assert.Equal(t, created.ID, ownSchedules[0].ID)
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		rr = serve(t, server, privateKey, "user-2", method, SchedulesPath+"/"+otherSchedule.ID, schedule+`}`)
+		assert.Equal(t, http.StatusNotFound, rr.Code, method)
+	}
+	_, err = server.schedules.Get(ctx, otherSchedule.ID)
+	assert.NoError(t, err, "another user's schedule is kept")
+	rr = serve(t, server, privateKey, "user-1", http.MethodGet, SchedulesPath+"/"+otherSchedule.ID, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = serve(t, server, privateKey, "user-2", http.MethodDelete, SchedulesPath+"/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// Health probes need no token
+	rr = serve(t, server, privateKey, "", http.MethodGet, "/health", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestServer_Auth_JSONRPC(t *testing.T) {
+	server, privateKey := setupAuthServer(t)
+
+	send := func(user, metadataUser string) rpcResponse {
+		rr := serve(t, server, privateKey, user, http.MethodPost, JSONRPCPath, `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{
+			"message":{"kind":"message","role":"user","messageId":"m-1","parts":[{"kind":"text","text":"report"}]},
+			"metadata":{"capability":"search","user_id":"`+metadataUser+`"}}}`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var resp rpcResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		return resp
+	}
+
+	resp := send("user-2", "")
+	require.Nil(t, resp.Error)
+	var task protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	stored, err := server.taskStore.Get(context.Background(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", stored.UserID)
+
+	resp = send("user-2", "user-1")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "authenticated user")
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	server, privateKey := setupAuthServer(t)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	server.SetRateLimiter(ratelimit.NewLimiter(client, "a2a:ratelimit", 2))
+
+	for i := 0; i < 2; i++ {
+		rr := serve(t, server, privateKey, "user-1", http.MethodGet, "/tasks", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	rr := serve(t, server, privateKey, "user-1", http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// Each user has their own limit
+	rr = serve(t, server, privateKey, "user-2", http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+)
+
+// BudgetsPath is the endpoint prefix for reading and setting user budgets
+const BudgetsPath = "/budgets/"
+
+// BudgetStatus is the response of GET /budgets/{user_id}
+type BudgetStatus struct {
+	cost.Budget
+	RemainingUSD float64 `json:"remaining_usd"`
+	PercentUsed  float64 `json:"percent_used"`
+	// Tenant is the budget the user's is a sub-budget of, if any
+	Tenant *cost.Budget `json:"tenant,omitempty"`
+	// Alerts are the user's alert thresholds and whether each was crossed this period
+	Alerts []cost.AlertState `json:"alerts"`
+}
+
+// handleBudget routes /budgets/{user_id}: anyone with the billing token may read a
+// budget, but setting one takes the admin token like PUT /admin/budgets/{user_id}
+func (s *Server) handleBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		s.handlePutBudget(w, r)
+		return
+	}
+	s.handleGetBudget(w, r)
+}
+
+// handlePutBudget handles PUT /budgets/{user_id}
+func (s *Server) handlePutBudget(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	userID := strings.Trim(strings.TrimPrefix(r.URL.Path, BudgetsPath), "/")
+	if userID == "" || strings.Contains(userID, "/") {
+		http.Error(w, "User ID required", http.StatusBadRequest)
+		return
+	}
+	s.setBudget(w, r, userID)
+}
+
+// handleGetBudget handles GET /budgets/{user_id}
+func (s *Server) handleGetBudget(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeBilling(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID := strings.Trim(strings.TrimPrefix(r.URL.Path, BudgetsPath), "/")
+	if userID == "" || strings.Contains(userID, "/") {
+		http.Error(w, "User ID required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	budget, err := s.budgetManager.GetBudget(ctx, userID)
+	if err != nil {
+		http.Error(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+	alerts, err := s.budgetManager.GetAlerts(ctx, userID)
+	if err != nil {
+		http.Error(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+	status := BudgetStatus{
+		Budget:       *budget,
+		RemainingUSD: budget.RemainingBudget(),
+		PercentUsed:  budget.PercentUsed(),
+		Alerts:       alerts,
+	}
+	if budget.TenantID != "" {
+		if tenant, err := s.budgetManager.GetTenantBudget(ctx, budget.TenantID); err == nil {
+			status.Tenant = tenant
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// discardNotifier drops budget alerts
+type discardNotifier struct{}
+
+func (discardNotifier) NotifyBudgetAlert(ctx context.Context, alert cost.Alert) error { return nil }
+
+func TestServer_GetBudget(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+	server.budgetManager.SetAlerts(cost.DefaultAlertThresholds, discardNotifier{})
+	require.NoError(t, server.budgetManager.SetTenantBudget(ctx, "acme", 100))
+	require.NoError(t, server.budgetManager.SetUserBudget(ctx, "acme", "alice", 10))
+	require.NoError(t, server.budgetManager.ChargeTask(ctx, "alice", "task-1", 6, 0))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleGetBudget(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/budgets/alice").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/budgets/").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/budgets/nobody").Code)
+
+	rr := serve(http.MethodGet, "/budgets/alice")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var status BudgetStatus
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&status))
+	assert.Equal(t, "alice", status.UserID)
+	assert.Equal(t, 60.0, status.PercentUsed)
+	assert.Equal(t, 4.0, status.RemainingUSD)
+	require.NotNil(t, status.Tenant)
+	assert.Equal(t, 6.0, status.Tenant.CurrentSpendUSD)
+
+	require.Len(t, status.Alerts, 3)
+	assert.True(t, status.Alerts[0].Triggered)
+	assert.Equal(t, 50.0, status.Alerts[0].ThresholdPercent)
+	assert.False(t, status.Alerts[1].Triggered)
+	assert.Nil(t, status.Alerts[1].TriggeredAt)
+}
+
+func TestServer_BudgetAuth(t *testing.T) {
+	server := setupTestServer()
+	require.NoError(t, server.budgetManager.SetBudget(context.Background(), "alice", 10))
+
+	serve := func(method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/budgets/alice", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		server.handleBudget(rr, req)
+		return rr
+	}
+	body := `{"monthly_limit_usd": 25}`
+
+	// Budgets can be read by anyone, but not set, until a token is configured
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "", body).Code)
+
+	server.SetAdminToken("admin")
+	server.SetBillingToken("billing")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "wrong", "").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "billing", "").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "admin", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "billing", body).Code, "setting budgets takes the admin token")
+
+	rr := serve(http.MethodPut, "admin", body)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var budget cost.Budget
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&budget))
+	assert.Equal(t, 25.0, budget.MonthlyLimitUSD)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "admin", `{"monthly_limit_usd": -1}`).Code)
+}
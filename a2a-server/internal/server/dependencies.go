@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sort"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+const (
+	// maxDependencies bounds the tasks one task may depend on
+	maxDependencies = 32
+	// maxGraphNodes bounds the tasks visited checking or returning a dependency graph
+	maxGraphNodes = 1000
+	// dependentsPageSize is how many dependents are listed at a time
+	dependentsPageSize = 100
+)
+
+// Errors returned by checkDependencies
+var (
+	errDependencyNotFound  = errors.New("dependency not found")
+	errDependencyCycle     = errors.New("dependency cycle")
+	errTooManyDependencies = fmt.Errorf("a task may depend on at most %d tasks", maxDependencies)
+	errDependencyGraphSize = fmt.Errorf("dependency graph has more than %d unfinished tasks", maxGraphNodes)
+)
+
+// isDependencyError reports whether err rejects the dependencies of a new task
+func isDependencyError(err error) bool {
+	return errors.Is(err, errDependencyNotFound) || errors.Is(err, errDependencyCycle) ||
+		errors.Is(err, errTooManyDependencies) || errors.Is(err, errDependencyGraphSize)
+}
+
+// errDependenciesPending is returned while some of a task's dependencies are unfinished
+var errDependenciesPending = errors.New("dependencies have not finished")
+
+// dependencyFailedError reports a dependency that will never complete, failing the tasks
+// that depend on it
+type dependencyFailedError struct {
+	TaskID string
+	State  protocol.TaskState
+}
+
+func (e *dependencyFailedError) Error() string {
+	if e.State == "" {
+		return fmt.Sprintf("Dependency %s no longer exists", e.TaskID)
+	}
+	return fmt.Sprintf("Dependency %s %s", e.TaskID, e.State)
+}
+
+// checkDependencies validates the dependencies of a new task of the user and returns them
+// without duplicates. Every dependency must be a task of the same user, since its result
+// becomes part of the new task's input, and the unfinished tasks they lead to must not
+// form a cycle, which would leave them waiting for each other forever.
+func (s *Server) checkDependencies(ctx context.Context, userID string, dependsOn []string) ([]string, error) {
+	if len(dependsOn) == 0 {
+		return nil, nil
+	}
+
+	unique := make([]string, 0, len(dependsOn))
+	seen := make(map[string]bool, len(dependsOn))
+	for _, id := range dependsOn {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > maxDependencies {
+		return nil, errTooManyDependencies
+	}
+
+	for _, id := range unique {
+		task, err := s.taskStore.Get(ctx, id)
+		if errors.Is(err, tasks.ErrTaskNotFound) || (err == nil && task.UserID != userID) {
+			return nil, fmt.Errorf("%w: %s", errDependencyNotFound, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkAcyclic(ctx, unique); err != nil {
+		return nil, err
+	}
+	return unique, nil
+}
+
+// checkAcyclic walks the unfinished tasks reachable from roots through their dependencies
+// and reports a cycle among them. Finished tasks never wait, so the walk stops at them.
+func (s *Server) checkAcyclic(ctx context.Context, roots []string) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	marks := make(map[string]int)
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch marks[id] {
+		case visiting:
+			return fmt.Errorf("%w through task %s", errDependencyCycle, id)
+		case done:
+			return nil
+		}
+		if len(marks) >= maxGraphNodes {
+			return errDependencyGraphSize
+		}
+
+		task, err := s.taskStore.Get(ctx, id)
+		if errors.Is(err, tasks.ErrTaskNotFound) {
+			marks[id] = done
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		marks[id] = visiting
+		if !task.State.IsTerminal() {
+			for _, dep := range task.DependsOn {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		marks[id] = done
+		return nil
+	}
+
+	for _, id := range roots {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencyResults returns the results of a task's dependencies by task ID once they
+// have all completed. It returns errDependenciesPending while some are unfinished and a
+// *dependencyFailedError when one failed, was cancelled or was deleted.
+func dependencyResults(ctx context.Context, store tasks.Store, task *protocol.Task) (map[string]interface{}, error) {
+	if len(task.DependsOn) == 0 {
+		return nil, nil
+	}
+
+	results := make(map[string]interface{}, len(task.DependsOn))
+	pending := false
+	for _, id := range task.DependsOn {
+		dep, err := store.Get(ctx, id)
+		if errors.Is(err, tasks.ErrTaskNotFound) {
+			return nil, &dependencyFailedError{TaskID: id}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch dep.State {
+		case protocol.TaskStateCompleted:
+			results[id] = dep.Result
+		case protocol.TaskStateFailed, protocol.TaskStateCancelled:
+			return nil, &dependencyFailedError{TaskID: id, State: dep.State}
+		default:
+			pending = true
+		}
+	}
+	if pending {
+		return nil, errDependenciesPending
+	}
+	return results, nil
+}
+
+// withDependencyResults returns a copy of input with the dependency results added
+func withDependencyResults(input map[string]interface{}, results map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(input)+1)
+	maps.Copy(merged, input)
+	merged[protocol.DependencyResultsKey] = results
+	return merged
+}
+
+// enqueueDependents queues the pending tasks depending on a task that just finished, so
+// processors check them again: they start once all their dependencies have completed and
+// fail if this one did not
+func enqueueDependents(ctx context.Context, store tasks.Store, queue tasks.Queue, taskID string) {
+	filter := tasks.ListFilter{State: protocol.TaskStatePending, DependsOn: taskID}
+	for offset := 0; ; offset += dependentsPageSize {
+		dependents, err := store.List(ctx, filter, dependentsPageSize, offset)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error listing dependent tasks", "task_id", taskID, "error", err)
+			return
+		}
+		for _, dependent := range dependents {
+			if err := queue.Enqueue(ctx, dependent.ID); err != nil {
+				slog.ErrorContext(ctx, "Error queueing dependent task", "task_id", dependent.ID, "error", err)
+			}
+		}
+		if len(dependents) < dependentsPageSize {
+			return
+		}
+	}
+}
+
+// taskGraph collects the tasks a task depends on, directly or transitively, and the tasks
+// depending on it, up to maxGraphNodes
+func taskGraph(ctx context.Context, store tasks.Store, root *protocol.Task) (*protocol.TaskGraph, error) {
+	graph := &protocol.TaskGraph{TaskID: root.ID}
+	found := map[string]*protocol.Task{root.ID: root}
+	add := func(task *protocol.Task) bool {
+		if _, ok := found[task.ID]; ok {
+			return false
+		}
+		if len(found) >= maxGraphNodes {
+			graph.Truncated = true
+			return false
+		}
+		found[task.ID] = task
+		return true
+	}
+
+	// Upstream: the dependencies; deleted ones stay referenced by depends_on only
+	queue := []*protocol.Task{root}
+	for len(queue) > 0 && !graph.Truncated {
+		task := queue[0]
+		queue = queue[1:]
+		for _, id := range task.DependsOn {
+			if _, ok := found[id]; ok {
+				continue
+			}
+			dep, err := store.Get(ctx, id)
+			if errors.Is(err, tasks.ErrTaskNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if add(dep) {
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	// Downstream: the dependents
+	queue = []*protocol.Task{root}
+	for len(queue) > 0 && !graph.Truncated {
+		task := queue[0]
+		queue = queue[1:]
+		for offset := 0; ; offset += dependentsPageSize {
+			dependents, err := store.List(ctx, tasks.ListFilter{DependsOn: task.ID}, dependentsPageSize, offset)
+			if err != nil {
+				return nil, err
+			}
+			for _, dependent := range dependents {
+				if add(dependent) {
+					queue = append(queue, dependent)
+				}
+			}
+			if len(dependents) < dependentsPageSize || graph.Truncated {
+				break
+			}
+		}
+	}
+
+	graph.Nodes = make([]protocol.TaskGraphNode, 0, len(found))
+	for _, task := range found {
+		graph.Nodes = append(graph.Nodes, protocol.TaskGraphNode{
+			ID:         task.ID,
+			Capability: task.Capability,
+			State:      task.State,
+			DependsOn:  task.DependsOn,
+			Error:      task.Error,
+		})
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		a, b := found[graph.Nodes[i].ID], found[graph.Nodes[j].ID]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return graph, nil
+}
+
+// handleTaskGraph handles GET /tasks/{id}/graph requests
+func (s *Server) handleTaskGraph(w http.ResponseWriter, r *http.Request, taskID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+
+	task, err := s.taskStore.Get(ctx, taskID)
+	if err != nil {
+		writeTaskLookupError(w, err)
+		return
+	}
+	graph, err := taskGraph(ctx, s.taskStore, task)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDependencyServer returns a server with an agent and a budget for user-1
+func setupDependencyServer(t *testing.T) *Server {
+	server := setupTestServer()
+	ctx := context.Background()
+	server.agentStore.Register(ctx, protocol.NewAgentCard("agent-1", "Agent", "1.0.0", "test"))
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10))
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-2", 10))
+	return server
+}
+
+func TestServer_CreateTask_Dependencies(t *testing.T) {
+	server := setupDependencyServer(t)
+	ctx := context.Background()
+	create := func(userID string, dependsOn ...string) (*protocol.Task, error) {
+		return server.createTask(ctx, CreateTaskRequest{UserID: userID, AgentID: "agent-1", Capability: "search", DependsOn: dependsOn}, "")
+	}
+
+	first, err := create("user-1")
+	require.NoError(t, err)
+	second, err := create("user-1", first.ID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID}, second.DependsOn, "duplicates are dropped")
+
+	_, err = create("user-1", "missing")
+	assert.ErrorIs(t, err, errDependencyNotFound)
+	_, err = create("user-2", first.ID)
+	assert.ErrorIs(t, err, errDependencyNotFound, "other users' tasks cannot be depended on")
+
+	// Unfinished tasks waiting for each other are rejected as dependencies
+	a := protocol.NewTask("agent-1", "search", nil)
+	b := protocol.NewTask("agent-1", "search", nil)
+	a.UserID, b.UserID = "user-1", "user-1"
+	a.DependsOn, b.DependsOn = []string{b.ID}, []string{a.ID}
+	require.NoError(t, server.taskStore.Create(ctx, a))
+	require.NoError(t, server.taskStore.Create(ctx, b))
+	_, err = create("user-1", a.ID)
+	assert.ErrorIs(t, err, errDependencyCycle)
+
+	// Over HTTP the errors are bad requests
+	rr := httptest.NewRecorder()
+	body := `{"user_id":"user-1","agent_id":"agent-1","capability":"search","depends_on":["missing"]}`
+	server.handleCreateTask(rr, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestTaskProcessor_Dependencies(t *testing.T) {
+	step := simulatedStep
+	simulatedStep = time.Millisecond
+	defer func() { simulatedStep = step }()
+
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	queue := &recordingQueue{}
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetQueue(queue, 1, 0)
+
+	// Task IDs starting with "a" complete in the simulation
+	upstream := &protocol.Task{ID: "a-upstream", Capability: "search", State: protocol.TaskStatePending}
+	dependent := &protocol.Task{ID: "a-dependent", Capability: "search", State: protocol.TaskStatePending,
+		DependsOn: []string{upstream.ID}, Input: map[string]interface{}{"query": "go"}}
+	require.NoError(t, store.Create(ctx, upstream))
+	require.NoError(t, store.Create(ctx, dependent))
+
+	// A task whose dependencies are unfinished is acknowledged and left pending
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "1-0", TaskID: dependent.ID, Attempt: 1})
+	waiting, err := store.Get(ctx, dependent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStatePending, waiting.State)
+	assert.Equal(t, []string{dependent.ID}, queue.acked)
+
+	// Finishing the dependency queues the dependent again, which then runs with its result
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "2-0", TaskID: upstream.ID, Attempt: 1})
+	assert.Equal(t, []string{dependent.ID}, queue.enqueued)
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "3-0", TaskID: dependent.ID, Attempt: 1})
+
+	done, err := store.Get(ctx, dependent.ID)
+	require.NoError(t, err)
+	require.Equal(t, protocol.TaskStateCompleted, done.State, done.Error)
+	assert.Equal(t, "go", done.Input["query"])
+	results := done.Input[protocol.DependencyResultsKey].(map[string]interface{})
+	assert.Equal(t, "success", results[upstream.ID].(map[string]interface{})["status"])
+
+	// A dependent of a cancelled task fails
+	cancelled := &protocol.Task{ID: "a-cancelled", Capability: "search", State: protocol.TaskStateCancelled}
+	orphan := &protocol.Task{ID: "a-orphan", Capability: "search", State: protocol.TaskStatePending, DependsOn: []string{cancelled.ID}}
+	require.NoError(t, store.Create(ctx, cancelled))
+	require.NoError(t, store.Create(ctx, orphan))
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "4-0", TaskID: orphan.ID, Attempt: 1})
+
+	failed, err := store.Get(ctx, orphan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, failed.State)
+	assert.Equal(t, "Dependency a-cancelled cancelled", failed.Error)
+}
+
+func TestServer_TaskGraph(t *testing.T) {
+	server := setupDependencyServer(t)
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		task, err := server.createTask(ctx, CreateTaskRequest{UserID: "user-1", AgentID: "agent-1", Capability: "search", DependsOn: ids}, "")
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+	unrelated, err := server.createTask(ctx, CreateTaskRequest{UserID: "user-1", AgentID: "agent-1", Capability: "search"}, "")
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tasks/"+ids[1]+"/graph", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var graph protocol.TaskGraph
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&graph))
+	assert.Equal(t, ids[1], graph.TaskID)
+	// The task's dependency and its dependent, not unrelated tasks
+	nodes := make(map[string]protocol.TaskGraphNode)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	assert.Len(t, nodes, 3)
+	assert.NotContains(t, nodes, unrelated.ID)
+	assert.Equal(t, ids[:2], nodes[ids[2]].DependsOn)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tasks/missing/graph", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
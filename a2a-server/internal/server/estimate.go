@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/speculative"
+)
+
+// TaskEstimatePath is the cost estimation preflight endpoint
+const TaskEstimatePath = "/tasks/estimate"
+
+// defaultTaskCostUSD is charged for tasks whose cost cannot be projected
+const defaultTaskCostUSD = 0.01
+
+// Where a task's cost estimate comes from
+const (
+	EstimateSourceCostModel   = "cost_model"  // the capability's cost model, from the input size
+	EstimateSourceCapability  = "capability"  // the estimated cost on the agent card
+	EstimateSourceSpeculation = "speculation" // every capability a speculative task may race
+	EstimateSourceDefault     = "default"     // a flat default
+)
+
+// TaskEstimate is the response of POST /tasks/estimate
+type TaskEstimate struct {
+	Capability string `json:"capability"`
+	capabilities.Estimate
+	Source string `json:"source"`
+	// RemainingBudgetUSD is what the user may still spend, within its tenant's budget
+	RemainingBudgetUSD float64 `json:"remaining_budget_usd"`
+	// Admitted reports whether creating the task now would be accepted; Exceeded names
+	// the budget level that would reject it
+	Admitted bool                      `json:"admitted"`
+	Exceeded *cost.BudgetExceededError `json:"exceeded,omitempty"`
+}
+
+// SetExecutors projects the cost of new tasks with the cost models of the capabilities
+// registered in executors
+func (s *Server) SetExecutors(executors *capabilities.Registry) {
+	s.executors = executors
+}
+
+// estimateTask projects the cost of a task, which is charged to the user's budget when it
+// is created
+func (s *Server) estimateTask(card *protocol.AgentCard, req CreateTaskRequest) (capabilities.Estimate, string) {
+	if req.Speculative && s.speculationCostCapUSD > 0 {
+		// Reserve budget for every alternative that may be launched alongside the request
+		if planned := speculative.EstimatedCost(speculative.Plan(card, req.Capability, s.speculationCostCapUSD)); planned > 0 {
+			return capabilities.Estimate{CostUSD: planned}, EstimateSourceSpeculation
+		}
+	}
+	if s.executors != nil {
+		if estimate, ok := s.executors.EstimateVersion(req.Capability, req.CapabilityVersion, req.Input); ok {
+			return estimate, EstimateSourceCostModel
+		}
+	}
+	if capability, ok := card.CapabilityVersion(req.Capability, req.CapabilityVersion); ok && capability.EstimatedCostUSD > 0 {
+		return capabilities.Estimate{CostUSD: capability.EstimatedCostUSD}, EstimateSourceCapability
+	}
+	return capabilities.Estimate{CostUSD: defaultTaskCostUSD}, EstimateSourceDefault
+}
+
+// handleEstimateTask handles POST /tasks/estimate, which takes the body of POST /tasks and
+// reports the task's projected cost and whether it would be admitted, without creating it
+func (s *Server) handleEstimateTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+
+	var req CreateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.MaxCostUSD < 0 {
+		http.Error(w, errInvalidMaxCost.Error(), http.StatusBadRequest)
+		return
+	}
+	user, err := resolveUser(ctx, req.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	req.UserID = user
+	if err := s.resolveMessage(&req); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, protocol.ErrInlineFileTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	card, err := s.agentStore.Get(ctx, req.AgentID)
+	if err != nil {
+		http.Error(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+	budget, err := s.budgetManager.GetBudget(ctx, req.UserID)
+	if err != nil {
+		http.Error(w, "Budget not configured", http.StatusBadRequest)
+		return
+	}
+
+	estimate, source := s.estimateTask(card, req)
+	response := TaskEstimate{
+		Capability:         req.Capability,
+		Estimate:           estimate,
+		Source:             source,
+		RemainingBudgetUSD: budget.RemainingBudget(),
+	}
+	if tenant, err := s.budgetManager.GetTenantBudget(ctx, budget.TenantID); err == nil {
+		response.RemainingBudgetUSD = min(response.RemainingBudgetUSD, tenant.RemainingBudget())
+	}
+	err = s.budgetManager.CheckTask(ctx, req.UserID, "", estimate.CostUSD, req.MaxCostUSD)
+	response.Admitted = err == nil
+	if err != nil && !errors.As(err, &response.Exceeded) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
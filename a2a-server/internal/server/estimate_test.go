@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_EstimateTask(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "summarize_document"})
+	card.AddCapability(protocol.Capability{Name: "translate", EstimatedCostUSD: 0.05})
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(ctx, card)
+	executors := capabilities.NewRegistry()
+	capabilities.RegisterBuiltins(executors, card)
+	server.SetExecutors(executors)
+	require.NoError(t, server.budgetManager.SetTenantBudget(ctx, "acme", 0.5))
+	require.NoError(t, server.budgetManager.SetUserBudget(ctx, "acme", "user-1", 1))
+
+	estimate := func(body map[string]interface{}) (*httptest.ResponseRecorder, TaskEstimate) {
+		data, _ := json.Marshal(body)
+		rr := httptest.NewRecorder()
+		server.handleEstimateTask(rr, httptest.NewRequest(http.MethodPost, TaskEstimatePath, bytes.NewReader(data)))
+		var response TaskEstimate
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		}
+		return rr, response
+	}
+	request := func(capability string, input map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"user_id": "user-1", "agent_id": "test-agent", "capability": capability, "input": input}
+	}
+
+	rr, got := estimate(request("summarize_document", map[string]interface{}{"document": "Embeddings capture meaning."}))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, EstimateSourceCostModel, got.Source)
+	assert.Equal(t, "gpt-4-turbo", got.Model)
+	assert.Greater(t, got.PromptTokens, 0)
+	assert.Greater(t, got.CostUSD, 0.0)
+	assert.True(t, got.Admitted)
+	assert.Equal(t, 0.5, got.RemainingBudgetUSD, "the tenant has less left than the user")
+
+	_, got = estimate(request("translate", nil))
+	assert.Equal(t, EstimateSourceCapability, got.Source)
+	assert.Equal(t, 0.05, got.CostUSD)
+
+	_, got = estimate(request("search", nil))
+	assert.Equal(t, EstimateSourceDefault, got.Source)
+	assert.Equal(t, defaultTaskCostUSD, got.CostUSD)
+
+	// A task over its own cost cap would be rejected
+	capped := request("translate", nil)
+	capped["max_cost_usd"] = 0.01
+	_, got = estimate(capped)
+	assert.False(t, got.Admitted)
+	require.NotNil(t, got.Exceeded)
+	assert.Equal(t, cost.LevelTask, got.Exceeded.Level)
+
+	// Nothing is charged
+	budget, err := server.budgetManager.GetBudget(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Zero(t, budget.CurrentSpendUSD)
+
+	require.NoError(t, server.budgetManager.ChargeTask(ctx, "user-1", "task-1", 0.48, 0))
+	_, got = estimate(request("translate", nil))
+	assert.False(t, got.Admitted)
+	assert.Equal(t, cost.LevelTenant, got.Exceeded.Level)
+	assert.InDelta(t, 0.02, got.RemainingBudgetUSD, 1e-9)
+
+	rr, _ = estimate(map[string]interface{}{"user_id": "user-1", "agent_id": "nope", "capability": "search"})
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr, _ = estimate(map[string]interface{}{"user_id": "nobody", "agent_id": "test-agent", "capability": "search"})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	server.handleEstimateTask(rr, httptest.NewRequest(http.MethodGet, TaskEstimatePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestServer_CreateTask_ChargesCostModelEstimate(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "summarize_document"})
+	server.agentStore.Register(ctx, card)
+	executors := capabilities.NewRegistry()
+	capabilities.RegisterBuiltins(executors, card)
+	server.SetExecutors(executors)
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10))
+
+	req := CreateTaskRequest{UserID: "user-1", AgentID: "test-agent", Capability: "summarize_document",
+		Input: map[string]interface{}{"document": "Embeddings capture meaning. Search uses embeddings."}}
+	expected, source := server.estimateTask(card, req)
+	require.Equal(t, EstimateSourceCostModel, source)
+	_, err := server.createTask(ctx, req, "")
+	require.NoError(t, err)
+
+	budget, err := server.budgetManager.GetBudget(ctx, "user-1")
+	require.NoError(t, err)
+	assert.InDelta(t, expected.CostUSD, budget.CurrentSpendUSD, 1e-12)
+}
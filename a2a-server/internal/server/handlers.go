@@ -1,12 +1,26 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // CreateTaskRequest represents a request to create a task
@@ -15,6 +29,28 @@ type CreateTaskRequest struct {
 	AgentID    string                 `json:"agent_id"`
 	Capability string                 `json:"capability"`
 	Input      map[string]interface{} `json:"input"`
+	// CapabilityVersion selects a version of a versioned capability; the latest version
+	// that is not deprecated runs when it is empty
+	CapabilityVersion string `json:"capability_version,omitempty"`
+	// Message gives the input as an A2A user message instead: its text parts are joined
+	// under "text", its data parts merged and its file parts listed under "files". The
+	// role defaults to user and the message ID is generated when empty.
+	Message *protocol.Message `json:"message,omitempty"`
+	// Priority orders the task for dispatch: "high", "normal" (the default) or "low"
+	Priority protocol.TaskPriority `json:"priority,omitempty"`
+	// DependsOn lists tasks of the same user that must complete before this one starts;
+	// their results are added to the input under "dependency_results"
+	DependsOn []string `json:"depends_on,omitempty"`
+	// MaxCostUSD caps the task's cost: its estimate at creation and what an execution may
+	// cost; zero for no cap
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty"`
+	// TTLSeconds is how long the task is kept once it finished, overriding the server's
+	// TASK_RETENTION; zero for the server's
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+	// Speculative races substitutable capabilities and keeps the first acceptable result
+	Speculative bool `json:"speculative,omitempty"`
+	// Webhook receives the task's state transitions and final result
+	Webhook *protocol.PushNotificationConfig `json:"webhook,omitempty"`
 }
 
 // handleGetAgentCard handles GET /agent requests
@@ -32,56 +68,328 @@ func (s *Server) handleGetAgentCard(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(cards[0])
 }
 
+// Errors returned by createTask, cancelTask and submitInput, mapped to HTTP and JSON-RPC errors by the handlers
+var (
+	errAgentNotFound       = errors.New("agent not found")
+	errBudgetNotConfigured = errors.New("budget not configured")
+	errBudgetExceeded      = errors.New("budget exceeded")
+	errTaskTerminal        = errors.New("task already in terminal state")
+	errPushNotSupported    = errors.New("push notifications are not enabled")
+	errInvalidPriority     = errors.New(`priority must be "high", "normal" or "low"`)
+	errInvalidMaxCost      = errors.New("max_cost_usd must not be negative")
+	errInvalidTTL          = errors.New("ttl_seconds must not be negative")
+	errBacklogFull         = errors.New("task backlog is full, retry later")
+	errNotAwaitingInput    = errors.New("task is not waiting for input")
+	errInvalidMessage      = errors.New("invalid message")
+	errInputAndMessage     = errors.New("input and message cannot be combined")
+	errUnknownVersion      = errors.New("unknown capability version")
+	errUserMismatch        = errors.New("user_id does not match the authenticated user")
+)
+
+// backlogRetryAfter is the Retry-After of tasks refused because the backlog is full
+const backlogRetryAfter = 5 * time.Second
+
+// BudgetExceededResponse is the body of a 402 response, naming the budget level exceeded
+type BudgetExceededResponse struct {
+	Error    string                    `json:"error"`
+	Exceeded *cost.BudgetExceededError `json:"exceeded,omitempty"`
+}
+
+// InputValidationResponse is the body of a 422 response, listing each way the task's
+// input fails to match the capability's input schema
+type InputValidationResponse struct {
+	Error  string                    `json:"error"`
+	Errors []capabilities.FieldError `json:"errors"`
+}
+
 // handleCreateTask handles POST /tasks requests
 func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := withCallerToken(r)
 
 	var req CreateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Validate agent exists
-	_, err := s.agentStore.Get(ctx, req.AgentID)
-	if err != nil {
+	task, err := s.createTask(ctx, req, "")
+	var invalid *capabilities.ValidationError
+	switch {
+	case errors.Is(err, errAgentNotFound):
 		http.Error(w, "Agent not found", http.StatusNotFound)
 		return
-	}
-
-	// Estimate cost (simplified - use fixed estimate for demo)
-	estimatedCost := 0.01 // $0.01 per task
-
-	// Check budget
-	allowed, err := s.budgetManager.CheckAndUpdate(ctx, req.UserID, estimatedCost)
-	if err != nil {
+	case errors.Is(err, errUserMismatch):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, errBudgetNotConfigured):
 		http.Error(w, "Budget not configured", http.StatusBadRequest)
 		return
-	}
-	if !allowed {
-		http.Error(w, "Budget exceeded", http.StatusPaymentRequired)
+	case errors.Is(err, errInvalidPriority), errors.Is(err, errInvalidMaxCost), errors.Is(err, errInvalidTTL),
+		errors.Is(err, errUnknownVersion), isDependencyError(err), isMessageError(err):
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
-	}
-
-	// Create task
-	task := protocol.NewTask(req.AgentID, req.Capability, req.Input)
-	if err := s.taskStore.Create(ctx, task); err != nil {
+	case errors.Is(err, protocol.ErrInlineFileTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case errors.As(err, &invalid):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(InputValidationResponse{Error: invalid.Error(), Errors: invalid.Errors})
+		return
+	case errors.Is(err, errBudgetExceeded):
+		response := BudgetExceededResponse{Error: "Budget exceeded"}
+		if errors.As(err, &response.Exceeded) {
+			response.Error = response.Exceeded.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPaymentRequired)
+		json.NewEncoder(w).Encode(response)
+		return
+	case errors.Is(err, errBacklogFull):
+		w.Header().Set("Retry-After", strconv.Itoa(int(backlogRetryAfter.Seconds())))
+		http.Error(w, "Task backlog is full, retry later", http.StatusTooManyRequests)
+		return
+	case errors.Is(err, errPushNotSupported):
+		http.Error(w, "Webhooks are not enabled", http.StatusNotImplemented)
+		return
+	case errors.Is(err, webhook.ErrInvalidConfig):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if warning := s.deprecationWarning(ctx, task); warning != "" {
+		writeDeprecationWarning(w, warning)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(task)
 }
 
+// resolveMessage sets the input of a task requested with a message, converted from it
+func (s *Server) resolveMessage(req *CreateTaskRequest) error {
+	if req.Message == nil {
+		return nil
+	}
+	if req.Input != nil {
+		return errInputAndMessage
+	}
+	message := *req.Message
+	if message.Role == "" {
+		message.Role = protocol.RoleUser
+	}
+	if message.MessageID == "" {
+		message.MessageID = uuid.New().String()
+	}
+	if message.Role != protocol.RoleUser {
+		return fmt.Errorf("%w: role must be %q", errInvalidMessage, protocol.RoleUser)
+	}
+	input, err := s.messageInput(&message)
+	if err != nil {
+		return err
+	}
+	req.Input, req.Message = input, &message
+	return nil
+}
+
+// messageInput converts a message a task is created or answered with into task input,
+// checking its parts and the size of its inline files
+func (s *Server) messageInput(message *protocol.Message) (map[string]interface{}, error) {
+	if err := message.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidMessage, err)
+	}
+	if err := message.CheckInlineFiles(s.maxInlineFileBytes); err != nil {
+		return nil, err
+	}
+	input, err := message.Input()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidMessage, err)
+	}
+	return input, nil
+}
+
+// isMessageError reports whether err rejects the message a task was requested with
+func isMessageError(err error) bool {
+	return errors.Is(err, errInvalidMessage) || errors.Is(err, errInputAndMessage)
+}
+
+// validateInput returns a *capabilities.ValidationError if the task's input does not match
+// the capability's input schema. A schema that cannot be compiled is logged and skipped,
+// since the caller is not at fault.
+func validateInput(ctx context.Context, card *protocol.AgentCard, req CreateTaskRequest) error {
+	capability, ok := card.CapabilityVersion(req.Capability, req.CapabilityVersion)
+	if !ok {
+		return nil
+	}
+	problems, err := capabilities.Validate(capability.InputSchema, req.Input)
+	if err != nil {
+		slog.WarnContext(ctx, "Capability input schema is invalid", "agent_id", card.ID,
+			"capability", req.Capability, "error", err)
+		return nil
+	}
+	if len(problems) > 0 {
+		return &capabilities.ValidationError{Capability: req.Capability, Errors: problems}
+	}
+	return nil
+}
+
+// withCallerToken returns the request context carrying the caller's bearer token, which
+// tasks created with it forward to the services their capabilities call
+func withCallerToken(r *http.Request) context.Context {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return capabilities.WithAuthToken(r.Context(), token)
+}
+
+// admit returns errBacklogFull while maxPending tasks wait to start
+func (s *Server) admit(ctx context.Context) error {
+	if s.maxPending <= 0 {
+		return nil
+	}
+	pending, err := s.taskStore.Count(ctx, tasks.ListFilter{State: protocol.TaskStatePending})
+	if err != nil {
+		return err
+	}
+	if pending < s.maxPending {
+		return nil
+	}
+	slog.WarnContext(ctx, "Task refused, backlog is full", "pending", pending, "max_pending", s.maxPending)
+	if s.telemetry != nil && s.telemetry.Metrics != nil {
+		s.telemetry.Metrics.RecordTaskRejected(ctx, "backlog_full")
+	}
+	return errBacklogFull
+}
+
+// createTask checks the agent, webhook, capability version, the input against the
+// version's input schema, the backlog and the caller's budget, stores a new pending task
+// and registers its webhook
+func (s *Server) createTask(ctx context.Context, req CreateTaskRequest, contextID string) (*protocol.Task, error) {
+	userID, err := resolveUser(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	req.UserID = userID
+	logging.AddAttrs(ctx, slog.String(logging.UserIDKey, req.UserID))
+
+	if req.Priority != "" && !req.Priority.Valid() {
+		return nil, errInvalidPriority
+	}
+	if req.MaxCostUSD < 0 {
+		return nil, errInvalidMaxCost
+	}
+	if req.TTLSeconds < 0 {
+		return nil, errInvalidTTL
+	}
+	if err := s.resolveMessage(&req); err != nil {
+		return nil, err
+	}
+
+	dependsOn, err := s.checkDependencies(ctx, req.UserID, req.DependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Webhook != nil {
+		if s.notifier == nil {
+			return nil, errPushNotSupported
+		}
+		if err := s.notifier.Validate(*req.Webhook); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate agent exists
+	card, err := s.agentStore.Get(ctx, req.AgentID)
+	if err != nil {
+		return nil, errAgentNotFound
+	}
+	if req.CapabilityVersion, err = resolveVersion(ctx, card, req); err != nil {
+		return nil, err
+	}
+	if err := validateInput(ctx, card, req); err != nil {
+		return nil, err
+	}
+	if err := s.admit(ctx); err != nil {
+		return nil, err
+	}
+
+	estimate, _ := s.estimateTask(card, req)
+	speculate := req.Speculative && s.speculationCostCapUSD > 0
+
+	// Check the tenant's and user's budgets and the task's cost cap, charging the estimate
+	// to the task so the charge can be reconciled with its usage
+	task := protocol.NewTask(req.AgentID, req.Capability, req.Input)
+	if req.Message != nil {
+		task.Message = req.Message
+	}
+	err = s.budgetManager.ChargeTask(ctx, req.UserID, task.ID, estimate.CostUSD, req.MaxCostUSD)
+	var exceeded *cost.BudgetExceededError
+	if errors.As(err, &exceeded) {
+		return nil, fmt.Errorf("%w: %w", errBudgetExceeded, exceeded)
+	}
+	if err != nil {
+		return nil, errBudgetNotConfigured
+	}
+
+	// Create task
+	task.ContextID = contextID
+	task.UserID = req.UserID
+	task.Speculative = speculate
+	if req.Priority != "" {
+		task.Priority = req.Priority
+	}
+	task.DependsOn = dependsOn
+	task.MaxCostUSD = req.MaxCostUSD
+	task.TTLSeconds = req.TTLSeconds
+	task.CapabilityVersion = req.CapabilityVersion
+	task.AuthToken = capabilities.AuthToken(ctx)
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		task.TraceContext = carrier
+	}
+	if err := s.taskStore.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	s.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+		TaskID:    task.ID,
+		State:     protocol.TaskStatePending,
+		Message:   "Task created",
+		Timestamp: task.CreatedAt,
+	})
+	if s.queue != nil {
+		if err := s.queue.Enqueue(ctx, task.ID); err != nil {
+			// Fail the task rather than leave it pending with no processor to pick it up
+			task.SetError("Task could not be queued")
+			if updateErr := s.taskStore.Update(ctx, task); updateErr != nil {
+				slog.ErrorContext(ctx, "Error updating unqueued task", "task_id", task.ID, "error", updateErr)
+			} else {
+				s.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateFailed, Message: task.Error})
+			}
+			return nil, err
+		}
+	}
+	if req.Webhook != nil {
+		if err := s.notifier.Register(task.ID, *req.Webhook); err != nil {
+			return nil, err
+		}
+	}
+	return task, nil
+}
+
 // handleGetTask handles GET /tasks/{id} requests
 func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request, taskID string) {
 	ctx := r.Context()
 
 	task, err := s.taskStore.Get(ctx, taskID)
 	if err != nil {
-		http.Error(w, "Task not found", http.StatusNotFound)
+		writeTaskLookupError(w, err)
 		return
 	}
 
@@ -89,60 +397,165 @@ func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request, taskID st
 	json.NewEncoder(w).Encode(task)
 }
 
-// handleListTasks handles GET /tasks requests
+// handleTaskHistory handles GET /tasks/{id}/history requests, returning the task's state
+// transitions oldest first with when they happened and which processor made them
+func (s *Server) handleTaskHistory(w http.ResponseWriter, r *http.Request, taskID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+
+	if _, err := s.taskStore.Get(ctx, taskID); err != nil {
+		writeTaskLookupError(w, err)
+		return
+	}
+	history, err := s.taskStore.History(ctx, taskID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// Page sizes of GET /tasks
+const (
+	defaultTaskPageSize = 100
+	maxTaskPageSize     = 1000
+)
+
+// handleListTasks handles GET /tasks requests. Tasks are filtered by agent_id, user_id,
+// state and created_after/created_before (RFC 3339), and listed oldest first, or newest
+// first with order=desc. A full page links to the next with a cursor in its Link header
+// (rel="next"); offset skips tasks instead. An authenticated user only lists their own tasks.
 func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Parse query parameters
-	agentID := r.URL.Query().Get("agent_id")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	filter, limit, offset, err := parseTaskListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.UserID, err = resolveUser(ctx, filter.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// Read one more task than the page holds to tell whether there is a next page
+	list, err := s.taskStore.List(ctx, filter, limit+1, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(list) > limit {
+		list = list[:limit]
+		query := r.URL.Query()
+		query.Del("offset")
+		query.Set("cursor", tasks.CursorOf(list[limit-1]).String())
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+	}
 
-	limit := 100
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil {
-			limit = l
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// parseTaskListQuery reads the filter, order and page of GET /tasks
+func parseTaskListQuery(query url.Values) (tasks.ListFilter, int, int, error) {
+	filter := tasks.ListFilter{
+		AgentID: query.Get("agent_id"),
+		State:   protocol.TaskState(query.Get("state")),
+		UserID:  query.Get("user_id"),
+	}
+	if filter.State != "" && !filter.State.Valid() {
+		return filter, 0, 0, errors.New("state must be pending, running, input_required, completed, failed or cancelled")
+	}
+	for param, bound := range map[string]*time.Time{"created_after": &filter.CreatedAfter, "created_before": &filter.CreatedBefore} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, 0, 0, fmt.Errorf("%s must be an RFC 3339 time", param)
+			}
+			*bound = t
 		}
 	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, 0, 0, errors.New(`order must be "asc" or "desc"`)
+	}
 
+	limit := defaultTaskPageSize
+	if value := query.Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l < 1 || l > maxTaskPageSize {
+			return filter, 0, 0, fmt.Errorf("limit must be between 1 and %d", maxTaskPageSize)
+		}
+		limit = l
+	}
 	offset := 0
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil {
-			offset = o
+	if value := query.Get("offset"); value != "" {
+		o, err := strconv.Atoi(value)
+		if err != nil || o < 0 {
+			return filter, 0, 0, errors.New("offset must not be negative")
+		}
+		offset = o
+	}
+	if value := query.Get("cursor"); value != "" {
+		if offset > 0 {
+			return filter, 0, 0, errors.New("cursor and offset cannot be combined")
+		}
+		cursor, err := tasks.ParseCursor(value)
+		if err != nil {
+			return filter, 0, 0, err
 		}
+		filter.After = cursor
 	}
+	return filter, limit, offset, nil
+}
 
-	tasks, err := s.taskStore.List(ctx, agentID, limit, offset)
-	if err != nil {
+// handleCancelTask handles DELETE /tasks/{id} requests
+func (s *Server) handleCancelTask(w http.ResponseWriter, r *http.Request, taskID string) {
+	task, err := s.cancelTask(r.Context(), taskID)
+	switch {
+	case errors.Is(err, errTaskTerminal):
+		http.Error(w, "Task already in terminal state", http.StatusConflict)
+		return
+	case errors.Is(err, tasks.ErrTaskNotFound), isIntegrityError(err):
+		writeTaskLookupError(w, err)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(tasks)
+	json.NewEncoder(w).Encode(task)
 }
 
-// handleCancelTask handles DELETE /tasks/{id} requests
-func (s *Server) handleCancelTask(w http.ResponseWriter, r *http.Request, taskID string) {
-	ctx := r.Context()
-
+// cancelTask cancels a task that has not finished yet and publishes the cancellation
+func (s *Server) cancelTask(ctx context.Context, taskID string) (*protocol.Task, error) {
 	task, err := s.taskStore.Get(ctx, taskID)
 	if err != nil {
-		http.Error(w, "Task not found", http.StatusNotFound)
-		return
+		return nil, err
 	}
 
 	// Check if task is already in terminal state
 	if task.State.IsTerminal() {
-		http.Error(w, "Task already in terminal state", http.StatusConflict)
-		return
+		return nil, errTaskTerminal
 	}
 
 	// Cancel the task
 	task.Cancel("Cancelled by user")
 	if err := s.taskStore.Update(ctx, task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		if errors.Is(err, tasks.ErrInvalidTransition) {
+			// The task finished since it was loaded
+			return nil, errTaskTerminal
+		}
+		return nil, err
 	}
 
 	// Publish cancellation event
@@ -151,9 +564,27 @@ func (s *Server) handleCancelTask(w http.ResponseWriter, r *http.Request, taskID
 		State:   protocol.TaskStateCancelled,
 		Message: "Task cancelled",
 	})
+	if s.queue != nil {
+		// Dependents waiting for the task fail rather than wait forever
+		enqueueDependents(ctx, s.taskStore, s.queue, taskID)
+	}
+	return task, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(task)
+// writeTaskLookupError maps a task store lookup failure to an HTTP error.
+// Integrity failures are reported separately so they are not mistaken for missing tasks.
+func writeTaskLookupError(w http.ResponseWriter, err error) {
+	if isIntegrityError(err) {
+		http.Error(w, "Task integrity check failed", http.StatusInternalServerError)
+		return
+	}
+	http.Error(w, "Task not found", http.StatusNotFound)
+}
+
+// isIntegrityError reports whether err is a failed task integrity check
+func isIntegrityError(err error) bool {
+	var integrityErr *protocol.IntegrityError
+	return errors.As(err, &integrityErr)
 }
 
 // handleHealth handles GET /health requests
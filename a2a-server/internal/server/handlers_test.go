@@ -6,12 +6,17 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/agentcard"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,7 +25,7 @@ func setupTestServer() *Server {
 	return &Server{
 		taskStore:     tasks.NewMemoryStore(),
 		agentStore:    agentcard.NewStore(),
-		costTracker:   cost.NewTracker(),
+		costTracker:   cost.NewMemoryTracker(),
 		budgetManager: cost.NewBudgetManager(),
 	}
 }
@@ -90,6 +95,129 @@ func TestServer_CreateTask(t *testing.T) {
 	assert.Equal(t, "test-agent", response.AgentID)
 	assert.Equal(t, "search", response.Capability)
 	assert.Equal(t, protocol.TaskStatePending, response.State)
+	assert.Equal(t, protocol.PriorityNormal, response.Priority)
+}
+
+func TestServer_CreateTask_Priority(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+	server.agentStore.Register(ctx, protocol.NewAgentCard("test-agent", "Test Agent", "1.0.0", "Test"))
+	server.budgetManager.SetBudget(ctx, "user-1", 10.0)
+
+	for _, tt := range []struct {
+		priority string
+		status   int
+	}{
+		{"high", http.StatusCreated},
+		{"low", http.StatusCreated},
+		{"urgent", http.StatusBadRequest},
+	} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"user_id":    "user-1",
+			"agent_id":   "test-agent",
+			"capability": "search",
+			"priority":   tt.priority,
+		})
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(body)))
+		require.Equal(t, tt.status, rr.Code, tt.priority)
+		if tt.status != http.StatusCreated {
+			continue
+		}
+
+		var response protocol.Task
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, protocol.TaskPriority(tt.priority), response.Priority)
+	}
+}
+
+func TestServer_CreateTask_SpeculativeReservesPlannedCost(t *testing.T) {
+	server := setupTestServer()
+	server.SetSpeculationCostCap(0.02)
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "summarize", SubstituteGroup: "summary", EstimatedCostUSD: 0.01})
+	card.AddCapability(protocol.Capability{Name: "summarize_fast", SubstituteGroup: "summary", EstimatedCostUSD: 0.004})
+	server.agentStore.Register(ctx, card)
+	server.budgetManager.SetBudget(ctx, "user-1", 10.0)
+
+	reqBody := map[string]interface{}{
+		"user_id":     "user-1",
+		"agent_id":    "test-agent",
+		"capability":  "summarize",
+		"input":       map[string]interface{}{"document": "text"},
+		"speculative": true,
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	server.handleCreateTask(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var response protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.True(t, response.Speculative)
+
+	budget, err := server.budgetManager.GetBudget(ctx, "user-1")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.014, budget.CurrentSpendUSD, 1e-9)
+}
+
+func TestServer_CreateTask_Message(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+	server.SetMaxInlineFileBytes(16)
+
+	card := protocol.NewAgentCard("test-agent", "Test Agent", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "summarize"})
+	server.agentStore.Register(ctx, card)
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+
+	create := func(fields map[string]interface{}) *httptest.ResponseRecorder {
+		reqBody := map[string]interface{}{"user_id": "user-1", "agent_id": "test-agent", "capability": "summarize"}
+		for key, value := range fields {
+			reqBody[key] = value
+		}
+		body, _ := json.Marshal(reqBody)
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(body)))
+		return rr
+	}
+	message := func(parts ...protocol.Part) map[string]interface{} {
+		return map[string]interface{}{"message": protocol.Message{Parts: parts}}
+	}
+
+	rr := create(message(protocol.TextPart("Summarize the notes"), protocol.FilePart("notes.txt", "text/plain", []byte("Short notes."))))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var task protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+	assert.Equal(t, "Summarize the notes", task.Input["text"])
+	files := protocol.Files(task.Input)
+	require.Len(t, files, 1)
+	assert.Equal(t, []byte("Short notes."), files[0].Bytes)
+	require.NotNil(t, task.Message, "the task keeps the message it was requested with")
+	assert.Equal(t, protocol.RoleUser, task.Message.Role)
+	assert.NotEmpty(t, task.Message.MessageID)
+	assert.Len(t, task.Message.Parts, 2)
+
+	rr = create(message(protocol.FilePart("notes.txt", "text/plain", []byte("Notes longer than the limit."))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	rr = create(message(protocol.FileURIPart("notes.txt", "text/plain", "https://example.com/notes.txt")))
+	assert.Equal(t, http.StatusCreated, rr.Code, "files passed by URI are not limited")
+
+	rr = create(message(protocol.Part{Kind: protocol.KindFile}))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = create(message())
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	fields := message(protocol.TextPart("Summarize"))
+	fields["input"] = map[string]interface{}{"document": "..."}
+	rr = create(fields)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "input and message cannot be combined")
 }
 
 func TestServer_CreateTask_InvalidJSON(t *testing.T) {
@@ -104,6 +232,47 @@ func TestServer_CreateTask_InvalidJSON(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
+func TestServer_CreateTask_InvalidInput(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test Agent", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search", InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+			"limit": map[string]interface{}{"type": "integer", "maximum": 50},
+		},
+		"required": []string{"query"},
+	}})
+	server.agentStore.Register(ctx, card)
+	server.budgetManager.SetBudget(ctx, "user-1", 10.0)
+
+	create := func(input string) *httptest.ResponseRecorder {
+		body := `{"user_id":"user-1","agent_id":"test-agent","capability":"search","input":` + input + `}`
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(body)))
+		return rr
+	}
+
+	rr := create(`{"limit": 100}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	var response InputValidationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, []capabilities.FieldError{
+		{Field: "/limit", Message: "maximum: got 100, want 50"},
+		{Field: "/query", Message: "is required"},
+	}, response.Errors)
+	assert.Contains(t, response.Error, "invalid input for search")
+
+	created, _ := server.taskStore.List(ctx, tasks.ListFilter{}, 10, 0)
+	assert.Empty(t, created, "no task is created")
+	budget, _ := server.budgetManager.GetBudget(ctx, "user-1")
+	assert.Zero(t, budget.CurrentSpendUSD, "nothing is charged")
+
+	assert.Equal(t, http.StatusCreated, create(`{"query": "test", "limit": 5}`).Code)
+}
+
 func TestServer_CreateTask_BudgetExceeded(t *testing.T) {
 	server := setupTestServer()
 	ctx := context.Background()
@@ -131,6 +300,137 @@ func TestServer_CreateTask_BudgetExceeded(t *testing.T) {
 	server.handleCreateTask(rr, req)
 
 	assert.Equal(t, http.StatusPaymentRequired, rr.Code)
+	var resp BudgetExceededResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.NotNil(t, resp.Exceeded)
+	assert.Equal(t, cost.LevelUser, resp.Exceeded.Level)
+	assert.Equal(t, "user-1", resp.Exceeded.ID)
+}
+
+func TestServer_CreateTask_BacklogFull(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(ctx, card)
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+	server.SetMaxPending(1)
+
+	create := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"user_id":    "user-1",
+			"agent_id":   "test-agent",
+			"capability": "search",
+		})
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(body)))
+		return rr
+	}
+
+	rr := create()
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	// The first task is still pending, so the next is refused until it starts
+	rr = create()
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+
+	pending, err := server.taskStore.List(ctx, tasks.ListFilter{State: protocol.TaskStatePending}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	pending[0].UpdateState(protocol.TaskStateRunning)
+	require.NoError(t, server.taskStore.Update(ctx, pending[0]))
+	rr = create()
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+}
+
+func TestServer_CreateTask_BudgetHierarchy(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(ctx, card)
+	require.NoError(t, server.budgetManager.SetTenantBudget(ctx, "acme", 0.001))
+	require.NoError(t, server.budgetManager.SetUserBudget(ctx, "acme", "user-1", 10.0))
+
+	create := func(maxCostUSD float64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"user_id":      "user-1",
+			"agent_id":     "test-agent",
+			"capability":   "search",
+			"max_cost_usd": maxCostUSD,
+		})
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(body)))
+		return rr
+	}
+	level := func(rr *httptest.ResponseRecorder) string {
+		var resp BudgetExceededResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.NotNil(t, resp.Exceeded, resp.Error)
+		return resp.Exceeded.Level
+	}
+
+	assert.Equal(t, http.StatusBadRequest, create(-1).Code)
+
+	rr := create(0)
+	require.Equal(t, http.StatusPaymentRequired, rr.Code)
+	assert.Equal(t, cost.LevelTenant, level(rr))
+
+	require.NoError(t, server.budgetManager.SetTenantBudget(ctx, "acme", 10.0))
+	rr = create(0.0001)
+	require.Equal(t, http.StatusPaymentRequired, rr.Code)
+	assert.Equal(t, cost.LevelTask, level(rr))
+
+	rr = create(1)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var task protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+	assert.Equal(t, 1.0, task.MaxCostUSD)
+	tenant, err := server.budgetManager.GetTenantBudget(ctx, "acme")
+	require.NoError(t, err)
+	assert.Greater(t, tenant.CurrentSpendUSD, 0.0)
+}
+
+func TestServer_CreateTask_Webhook(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(ctx, card)
+	server.budgetManager.SetBudget(ctx, "user-1", 10.0)
+
+	create := func(webhookURL string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"user_id":    "user-1",
+			"agent_id":   "test-agent",
+			"capability": "search",
+			"webhook":    map[string]interface{}{"url": webhookURL, "secret": "s3cret"},
+		})
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(body)))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNotImplemented, create("https://example.com/hook").Code)
+
+	notifier := webhook.NewNotifier(server.taskStore, webhook.Config{})
+	defer notifier.Close()
+	server.SetNotifier(notifier)
+	assert.Equal(t, http.StatusBadRequest, create("not a url").Code)
+	assert.Equal(t, http.StatusBadRequest, create("http://169.254.169.254/latest/meta-data").Code)
+
+	rr := create("https://example.com/hook")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "s3cret")
+	var task protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+	config, ok := notifier.Config(task.ID)
+	require.True(t, ok)
+	assert.Equal(t, "s3cret", config.Secret)
 }
 
 func TestServer_GetTask(t *testing.T) {
@@ -165,6 +465,81 @@ func TestServer_GetTask_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, rr.Code)
 }
 
+func TestServer_TaskHistory(t *testing.T) {
+	step := simulatedStep
+	simulatedStep = time.Millisecond
+	defer func() { simulatedStep = step }()
+
+	server := setupTestServer()
+	ctx := context.Background()
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(ctx, card)
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+
+	task, err := server.createTask(ctx, CreateTaskRequest{UserID: "user-1", AgentID: "test-agent", Capability: "search",
+		Input: map[string]interface{}{"query": "test"}}, "")
+	require.NoError(t, err)
+	processor := NewTaskProcessor(server.taskStore, time.Hour)
+	running := *task
+	processor.processTask(ctx, &running)
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tasks/"+task.ID+"/history", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var history []protocol.TaskEvent
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&history))
+
+	// Creation, start and outcome, but not the progress artifacts streamed meanwhile
+	require.Len(t, history, 3)
+	assert.Equal(t, protocol.TaskStatePending, history[0].State)
+	assert.Empty(t, history[0].WorkerID)
+	assert.True(t, history[0].Timestamp.Equal(task.CreatedAt))
+	assert.Equal(t, protocol.TaskStateRunning, history[1].State)
+	assert.Equal(t, processor.owner, history[1].WorkerID)
+	assert.Equal(t, running.State, history[2].State)
+	assert.Equal(t, processor.owner, history[2].WorkerID)
+	assert.False(t, history[2].Timestamp.Before(history[1].Timestamp))
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tasks/missing/history", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+// tamperingStore changes the input of the tasks it loads after they were sealed, as a
+// corrupted store would
+type tamperingStore struct {
+	tasks.Store
+}
+
+func (s tamperingStore) Get(ctx context.Context, id string) (*protocol.Task, error) {
+	task, err := s.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task.Input["query"] = "tampered"
+	return task, task.VerifyIntegrity()
+}
+
+func TestServer_GetTask_IntegrityFailure(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", map[string]interface{}{"query": "test"})
+	server.taskStore.Create(ctx, task)
+	server.taskStore = tamperingStore{server.taskStore}
+
+	req := httptest.NewRequest("GET", "/tasks/"+task.ID, nil)
+	rr := httptest.NewRecorder()
+
+	server.handleGetTask(rr, req, task.ID)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Contains(t, rr.Body.String(), "integrity")
+}
+
 func TestServer_ListTasks(t *testing.T) {
 	server := setupTestServer()
 	ctx := context.Background()
@@ -212,6 +587,85 @@ func TestServer_ListTasks_WithAgentFilter(t *testing.T) {
 	assert.Equal(t, "agent-1", response[0].AgentID)
 }
 
+func TestServer_ListTasks_FiltersAndPages(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var created []*protocol.Task
+	for i := 0; i < 5; i++ {
+		task := protocol.NewTask("agent-1", "search", nil)
+		task.UserID = "user-1"
+		task.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		if i%2 == 1 {
+			task.SetResult(nil)
+		}
+		require.NoError(t, server.taskStore.Create(ctx, task))
+		created = append(created, task)
+	}
+	other := protocol.NewTask("agent-1", "search", nil)
+	other.UserID = "user-2"
+	other.CreatedAt = start
+	require.NoError(t, server.taskStore.Create(ctx, other))
+
+	list := func(target string) ([]protocol.Task, string) {
+		rr := httptest.NewRecorder()
+		server.handleListTasks(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var response []protocol.Task
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response, rr.Header().Get("Link")
+	}
+	ids := func(list []protocol.Task) []string {
+		var ids []string
+		for _, task := range list {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	got, link := list("/tasks?user_id=user-1&state=completed")
+	assert.Equal(t, []string{created[1].ID, created[3].ID}, ids(got))
+	assert.Empty(t, link)
+
+	got, _ = list("/tasks?user_id=user-1&order=desc&created_after=" +
+		url.QueryEscape(start.Format(time.RFC3339)) + "&created_before=" + url.QueryEscape(start.Add(4*time.Minute).Format(time.RFC3339)))
+	assert.Equal(t, []string{created[3].ID, created[2].ID, created[1].ID}, ids(got))
+
+	// Full pages link to the next one, newest first
+	var paged []string
+	target := "/tasks?user_id=user-1&order=desc&limit=2"
+	for pages := 0; target != ""; pages++ {
+		require.Less(t, pages, 3)
+		got, link = list(target)
+		paged = append(paged, ids(got)...)
+		target = ""
+		if link != "" {
+			require.True(t, strings.HasSuffix(link, `>; rel="next"`), link)
+			target = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+			assert.Contains(t, target, "order=desc")
+		}
+	}
+	assert.Equal(t, []string{created[4].ID, created[3].ID, created[2].ID, created[1].ID, created[0].ID}, paged)
+
+	for _, query := range []string{
+		"state=done",
+		"created_after=yesterday",
+		"order=newest",
+		"limit=0",
+		"limit=1001",
+		"offset=-1",
+		"cursor=not-a-cursor",
+		"cursor=" + tasks.CursorOf(created[0]).String() + "&offset=1",
+	} {
+		t.Run(query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.handleListTasks(rr, httptest.NewRequest(http.MethodGet, "/tasks?"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
 func TestServer_CancelTask(t *testing.T) {
 	server := setupTestServer()
 	ctx := context.Background()
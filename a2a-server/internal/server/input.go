@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+// inputRequestKey carries the question an execution asked in its result map until the
+// task is paused; it never reaches the stored task
+const inputRequestKey = "_input_required"
+
+// TaskInputRequest is the body of POST /tasks/{id}/messages: the user's answer to the
+// question of a task waiting for input
+type TaskInputRequest struct {
+	Text string                 `json:"text,omitempty"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// popInputRequest removes the question an execution asked from its result
+func popInputRequest(result map[string]interface{}) string {
+	prompt, _ := result[inputRequestKey].(string)
+	delete(result, inputRequestKey)
+	return prompt
+}
+
+// requestInput pauses a task whose executor asked a question until the user answers it.
+// Dependents keep waiting: the task has not finished.
+func (p *TaskProcessor) requestInput(ctx context.Context, task *protocol.Task, prompt string, decision *protocol.SpeculationDecision) {
+	task.RequestInput(prompt)
+	if err := p.saveOutcome(ctx, task); err != nil {
+		slog.ErrorContext(ctx, "Error updating task to input required", "task_id", task.ID, "error", err)
+		return
+	}
+
+	p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+		TaskID:   task.ID,
+		State:    protocol.TaskStateInputRequired,
+		Message:  prompt,
+		Data:     speculationData(decision),
+		WorkerID: p.owner,
+	})
+
+	slog.InfoContext(ctx, "Task waiting for input", "task_id", task.ID)
+}
+
+// handleTaskMessages handles POST /tasks/{id}/messages requests, answering a task waiting
+// for input
+func (s *Server) handleTaskMessages(w http.ResponseWriter, r *http.Request, taskID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TaskInputRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Text == "" && len(req.Data) == 0 {
+		http.Error(w, "text or data is required", http.StatusBadRequest)
+		return
+	}
+
+	task, err := s.submitInput(r.Context(), taskID, protocol.Turn{Text: req.Text, Data: req.Data})
+	switch {
+	case errors.Is(err, errNotAwaitingInput):
+		http.Error(w, "Task is not waiting for input", http.StatusConflict)
+		return
+	case errors.Is(err, tasks.ErrTaskNotFound), isIntegrityError(err):
+		writeTaskLookupError(w, err)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// submitInput adds the user's answer to a task waiting for input and queues it to run
+// again with its conversation
+func (s *Server) submitInput(ctx context.Context, taskID string, answer protocol.Turn) (*protocol.Task, error) {
+	stored, err := s.taskStore.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.State != protocol.TaskStateInputRequired {
+		return nil, errNotAwaitingInput
+	}
+
+	task := *stored
+	task.Resume(answer)
+	if err := s.taskStore.Update(ctx, &task); err != nil {
+		if errors.Is(err, tasks.ErrInvalidTransition) {
+			// The task was cancelled since it was loaded
+			return nil, errNotAwaitingInput
+		}
+		return nil, err
+	}
+
+	s.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+		TaskID:  task.ID,
+		State:   protocol.TaskStatePending,
+		Message: "Input received",
+	})
+	// Without a queue the next poll picks it up
+	if s.queue != nil {
+		if err := s.queue.Enqueue(ctx, task.ID); err != nil {
+			slog.ErrorContext(ctx, "Error queueing answered task", "task_id", task.ID, "error", err)
+		}
+	}
+	return &task, nil
+}
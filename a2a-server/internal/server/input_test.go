@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bookFlight asks for the date until the conversation has one
+func bookFlight(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+	date, _ := input["date"].(string)
+	if date == "" {
+		return capabilities.RequestInput("Which date?"), nil
+	}
+	return &capabilities.Result{Output: map[string]interface{}{"booked": input["to"].(string) + " on " + date}}, nil
+}
+
+func TestServer_TaskInput(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{Name: "book_flight"}, capabilities.ExecutorFunc(bookFlight))
+	processor := NewTaskProcessor(server.taskStore, time.Hour)
+	processor.SetExecutors(executors, nil)
+
+	task := protocol.NewTask("agent-1", "book_flight", map[string]interface{}{"to": "Paris"})
+	require.NoError(t, server.taskStore.Create(ctx, task))
+	processor.processTask(ctx, task)
+	require.Equal(t, protocol.TaskStateInputRequired, task.State, task.Error)
+	assert.Equal(t, "Which date?", task.InputPrompt())
+	assert.Nil(t, task.Result)
+
+	answer := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleTaskMessages(rr, httptest.NewRequest(http.MethodPost, "/tasks/"+task.ID+"/messages", strings.NewReader(body)), task.ID)
+		return rr
+	}
+	assert.Equal(t, http.StatusBadRequest, answer(`{}`).Code)
+	rr := answer(`{"text":"Next Friday","data":{"date":"2026-10-23"}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resumed protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resumed))
+	assert.Equal(t, protocol.TaskStatePending, resumed.State)
+
+	// The task runs again with the answer and its conversation
+	stored, err := server.taskStore.Get(ctx, task.ID)
+	require.NoError(t, err)
+	processor.processTask(ctx, stored)
+	require.Equal(t, protocol.TaskStateCompleted, stored.State, stored.Error)
+	assert.Equal(t, "Paris on 2026-10-23", stored.Result["output"].(map[string]interface{})["booked"])
+	assert.Equal(t, []protocol.Turn{
+		{Role: protocol.TurnAgent, Text: "Which date?"},
+		{Role: protocol.TurnUser, Text: "Next Friday", Data: map[string]interface{}{"date": "2026-10-23"}},
+	}, protocol.Conversation(stored.Input))
+
+	history, err := server.taskStore.History(ctx, task.ID)
+	require.NoError(t, err)
+	var states []protocol.TaskState
+	for _, event := range history {
+		states = append(states, event.State)
+	}
+	assert.Contains(t, states, protocol.TaskStateInputRequired)
+
+	// Only a task waiting for input takes an answer
+	assert.Equal(t, http.StatusConflict, answer(`{"text":"Saturday"}`).Code)
+	rr = httptest.NewRecorder()
+	server.handleTaskMessages(rr, httptest.NewRequest(http.MethodPost, "/tasks/missing/messages", strings.NewReader(`{"text":"hi"}`)), "missing")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestJSONRPC_ContinueTask(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	ctx := context.Background()
+
+	resp := callJSONRPC(t, server, messageSend)
+	require.Nil(t, resp.Error)
+	var created protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &created))
+	task, err := server.taskStore.Get(ctx, created.ID)
+	require.NoError(t, err)
+	waiting := *task
+	waiting.UpdateState(protocol.TaskStateRunning)
+	require.NoError(t, server.taskStore.Update(ctx, &waiting))
+	waiting.RequestInput("Which year?")
+	require.NoError(t, server.taskStore.Update(ctx, &waiting))
+
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"`+task.ID+`"}}`)
+	require.Nil(t, resp.Error)
+	var a2aTask protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &a2aTask))
+	assert.Equal(t, protocol.A2AStateInputRequired, a2aTask.Status.State)
+	require.NotNil(t, a2aTask.Status.Message)
+	assert.Equal(t, "Which year?", a2aTask.Status.Message.Parts[0].Text)
+
+	reply := func(contextID string) rpcResponse {
+		return callJSONRPC(t, server, `{"jsonrpc":"2.0","id":2,"method":"message/send","params":{
+			"message":{"kind":"message","role":"user","messageId":"m-2","taskId":"`+task.ID+`","contextId":"`+contextID+`",
+				"parts":[{"kind":"text","text":"2017"},{"kind":"data","data":{"year":2017}}]}}}`)
+	}
+	resp = reply("other-context")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+
+	resp = reply("ctx-1")
+	require.Nil(t, resp.Error)
+	require.NoError(t, json.Unmarshal(resp.Result, &a2aTask))
+	assert.Equal(t, task.ID, a2aTask.ID)
+	assert.Equal(t, protocol.A2AStateSubmitted, a2aTask.Status.State)
+	stored, err := server.taskStore.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2017.0, stored.Input["year"])
+	assert.Equal(t, "transformers", stored.Input["text"], "the original input is kept")
+	turns := protocol.Conversation(stored.Input)
+	require.Len(t, turns, 2)
+	assert.Equal(t, protocol.Turn{Role: protocol.TurnUser, Text: "2017", Data: map[string]interface{}{"year": 2017.0}}, turns[1])
+
+	// The task no longer waits for input
+	resp = reply("ctx-1")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.TaskNotAwaitingInput, resp.Error.Code)
+}
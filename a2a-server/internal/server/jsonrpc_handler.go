@@ -0,0 +1,439 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/google/uuid"
+)
+
+// JSONRPCPath is the A2A JSON-RPC endpoint
+const JSONRPCPath = "/a2a"
+
+// handleJSONRPC handles POST /a2a requests using the A2A JSON-RPC methods
+// (message/send, tasks/get, tasks/cancel, tasks/pushNotificationConfig/set and get)
+func (s *Server) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req protocol.JSONRPCRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSONRPC(w, r, protocol.NewJSONRPCError(nil, protocol.RequestTooLarge,
+			fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit), map[string]interface{}{"max_bytes": tooLarge.Limit}))
+		return
+	}
+	if err != nil {
+		writeJSONRPC(w, r, protocol.NewJSONRPCError(nil, protocol.ParseError, "Parse error", nil))
+		return
+	}
+	if req.JSONRPC != protocol.JSONRPCVersion || req.Method == "" || len(req.ID) == 0 {
+		writeJSONRPC(w, r, protocol.NewJSONRPCError(req.ID, protocol.InvalidRequest,
+			"Invalid request: jsonrpc must be \"2.0\" and id and method are required", nil))
+		return
+	}
+	if req.Method == protocol.MethodMessageStream || req.Method == protocol.MethodTasksResubscribe {
+		s.handleJSONRPCStream(w, r, &req)
+		return
+	}
+
+	result, rpcErr := s.dispatchJSONRPC(withCallerToken(r), &req)
+	if rpcErr != nil {
+		writeJSONRPC(w, r, &protocol.JSONRPCResponse{JSONRPC: protocol.JSONRPCVersion, ID: req.ID, Error: rpcErr})
+		return
+	}
+	writeJSONRPC(w, r, protocol.NewJSONRPCResult(req.ID, result))
+}
+
+// dispatchJSONRPC runs a JSON-RPC method
+func (s *Server) dispatchJSONRPC(ctx context.Context, req *protocol.JSONRPCRequest) (interface{}, *protocol.JSONRPCError) {
+	switch req.Method {
+	case protocol.MethodMessageSend:
+		var params protocol.MessageSendParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		task, rpcErr := s.rpcMessageSend(ctx, &params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return s.a2aTask(ctx, task), nil
+	case protocol.MethodTasksGet:
+		var params protocol.TaskQueryParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		task, err := s.taskStore.Get(ctx, params.ID)
+		if err != nil {
+			return nil, taskLookupRPCError(err, params.ID)
+		}
+		a2aTask := protocol.ToA2ATask(task)
+		a2aTask.History = protocol.A2AHistory(task, params.HistoryLength)
+		return a2aTask, nil
+	case protocol.MethodTasksCancel:
+		var params protocol.TaskIDParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		task, err := s.cancelTask(ctx, params.ID)
+		switch {
+		case errors.Is(err, errTaskTerminal):
+			return nil, &protocol.JSONRPCError{Code: protocol.TaskNotCancelable, Message: "Task cannot be canceled",
+				Data: map[string]interface{}{"id": params.ID}}
+		case err != nil:
+			return nil, taskLookupRPCError(err, params.ID)
+		}
+		return protocol.ToA2ATask(task), nil
+	case protocol.MethodPushConfigSet, protocol.MethodPushConfigGet:
+		if s.notifier == nil {
+			return nil, pushNotSupported()
+		}
+		return s.rpcPushConfig(ctx, req)
+	default:
+		return nil, &protocol.JSONRPCError{Code: protocol.MethodNotFound, Message: "Method not found",
+			Data: map[string]interface{}{"method": req.Method}}
+	}
+}
+
+// rpcMessageSend starts a task from a user message. The capability, user and optional
+// capability version, agent and speculative flag come from the params or message metadata
+// ("capability", "user_id", "capability_version", "agent_id", "speculative").
+func (s *Server) rpcMessageSend(ctx context.Context, params *protocol.MessageSendParams) (*protocol.Task, *protocol.JSONRPCError) {
+	if err := params.Validate(); err != nil {
+		return nil, messageRPCError(err)
+	}
+	if params.Message.TaskID != "" {
+		return s.rpcContinueTask(ctx, &params.Message)
+	}
+
+	if _, err := s.messageInput(&params.Message); err != nil {
+		return nil, messageRPCError(err)
+	}
+
+	metadata := []map[string]interface{}{params.Metadata, params.Message.Metadata}
+	card, err := s.rpcAgentCard(ctx, metadataString("agent_id", metadata...))
+	if err != nil {
+		return nil, invalidParams("Agent not found")
+	}
+
+	capability := metadataString("capability", metadata...)
+	if capability == "" && len(card.Capabilities) == 1 {
+		capability = card.Capabilities[0].Name
+	}
+	if _, ok := card.Capability(capability); !ok && (s.delegator == nil || !s.delegator.Offers(ctx, capability)) {
+		return nil, invalidParams("metadata.capability must name one of the agent's capabilities")
+	}
+	version := metadataString("capability_version", metadata...)
+
+	contextID := params.Message.ContextID
+	if contextID == "" {
+		contextID = uuid.New().String()
+	}
+	speculative, _ := metadataValue("speculative", metadata...).(bool)
+	priority := protocol.TaskPriority(metadataString("priority", metadata...))
+	dependsOn, ok := metadataStrings("depends_on", metadata...)
+	if !ok {
+		return nil, invalidParams("metadata.depends_on must be an array of task IDs")
+	}
+	maxCostUSD, ok := metadataValue("max_cost_usd", metadata...).(float64)
+	if !ok && metadataValue("max_cost_usd", metadata...) != nil {
+		return nil, invalidParams("metadata.max_cost_usd must be a number")
+	}
+	ttlSeconds, ok := metadataValue("ttl_seconds", metadata...).(float64)
+	if (!ok && metadataValue("ttl_seconds", metadata...) != nil) || ttlSeconds != float64(int(ttlSeconds)) {
+		return nil, invalidParams("metadata.ttl_seconds must be a whole number of seconds")
+	}
+	var pushConfig *protocol.PushNotificationConfig
+	if params.Configuration != nil {
+		pushConfig = params.Configuration.PushNotificationConfig
+	}
+
+	task, err := s.createTask(ctx, CreateTaskRequest{
+		UserID:            metadataString("user_id", metadata...),
+		AgentID:           card.ID,
+		Capability:        capability,
+		Message:           &params.Message,
+		CapabilityVersion: version,
+		Priority:          priority,
+		DependsOn:         dependsOn,
+		MaxCostUSD:        maxCostUSD,
+		TTLSeconds:        int(ttlSeconds),
+		Speculative:       speculative,
+		Webhook:           pushConfig,
+	}, contextID)
+	var invalid *capabilities.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return nil, &protocol.JSONRPCError{Code: protocol.InvalidParams, Message: invalid.Error(),
+			Data: map[string]interface{}{"errors": invalid.Errors}}
+	case errors.Is(err, errPushNotSupported):
+		return nil, pushNotSupported()
+	case errors.Is(err, webhook.ErrInvalidConfig):
+		return nil, invalidParams(err.Error())
+	case errors.Is(err, errInvalidPriority):
+		return nil, invalidParams("metadata.priority must be high, normal or low")
+	case errors.Is(err, errInvalidMaxCost):
+		return nil, invalidParams("metadata.max_cost_usd must not be negative")
+	case errors.Is(err, errInvalidTTL):
+		return nil, invalidParams("metadata.ttl_seconds must not be negative")
+	case errors.Is(err, errUnknownVersion):
+		return nil, invalidParams("metadata.capability_version: " + err.Error())
+	case isDependencyError(err):
+		return nil, invalidParams("metadata.depends_on: " + err.Error())
+	case errors.Is(err, errUserMismatch):
+		return nil, invalidParams("metadata.user_id does not match the authenticated user")
+	case errors.Is(err, errBudgetNotConfigured):
+		return nil, invalidParams("No budget is configured for metadata.user_id")
+	case errors.Is(err, errBacklogFull):
+		return nil, &protocol.JSONRPCError{Code: protocol.ServerBusy, Message: "Task backlog is full, retry later",
+			Data: map[string]interface{}{"retry_after_seconds": int(backlogRetryAfter.Seconds())}}
+	case errors.Is(err, errBudgetExceeded):
+		rpcErr := &protocol.JSONRPCError{Code: protocol.BudgetExceeded, Message: "Budget exceeded"}
+		var exceeded *cost.BudgetExceededError
+		if errors.As(err, &exceeded) {
+			rpcErr.Message = exceeded.Error()
+			rpcErr.Data = exceeded
+		}
+		return nil, rpcErr
+	case err != nil:
+		return nil, &protocol.JSONRPCError{Code: protocol.InternalError, Message: err.Error()}
+	}
+	return task, nil
+}
+
+// rpcContinueTask answers the question of the task waiting for input that a message
+// continues. The message's text and data parts are the answer.
+func (s *Server) rpcContinueTask(ctx context.Context, message *protocol.Message) (*protocol.Task, *protocol.JSONRPCError) {
+	input, err := s.messageInput(message)
+	if err != nil {
+		return nil, messageRPCError(err)
+	}
+	task, err := s.taskStore.Get(ctx, message.TaskID)
+	if err != nil {
+		return nil, taskLookupRPCError(err, message.TaskID)
+	}
+	if message.ContextID != "" && message.ContextID != task.ContextID {
+		return nil, invalidParams("message.contextId does not match the task's context")
+	}
+
+	answer := protocol.Turn{Data: input}
+	answer.Text, _ = input[protocol.TextKey].(string)
+	delete(input, protocol.TextKey)
+	task, err = s.submitInput(ctx, message.TaskID, answer)
+	switch {
+	case errors.Is(err, errNotAwaitingInput):
+		return nil, &protocol.JSONRPCError{Code: protocol.TaskNotAwaitingInput, Message: "Task is not waiting for input",
+			Data: map[string]interface{}{"taskId": message.TaskID}}
+	case err != nil:
+		return nil, taskLookupRPCError(err, message.TaskID)
+	}
+	return task, nil
+}
+
+// messageRPCError maps a rejected message to a JSON-RPC error: parts of a kind the server
+// does not take, inline files over the limit, or otherwise invalid params
+func messageRPCError(err error) *protocol.JSONRPCError {
+	switch {
+	case errors.Is(err, protocol.ErrUnsupportedKind):
+		return &protocol.JSONRPCError{Code: protocol.ContentTypeNotSupported, Message: err.Error()}
+	case errors.Is(err, protocol.ErrInlineFileTooLarge):
+		return &protocol.JSONRPCError{Code: protocol.RequestTooLarge, Message: err.Error()}
+	default:
+		return invalidParams(err.Error())
+	}
+}
+
+// rpcPushConfig handles tasks/pushNotificationConfig/set and get. The secret is never
+// returned.
+func (s *Server) rpcPushConfig(ctx context.Context, req *protocol.JSONRPCRequest) (interface{}, *protocol.JSONRPCError) {
+	var taskID string
+	var config protocol.PushNotificationConfig
+	if req.Method == protocol.MethodPushConfigSet {
+		var params protocol.TaskPushNotificationConfig
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		taskID, config = params.TaskID, params.PushNotificationConfig
+	} else {
+		var params protocol.TaskIDParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		taskID = params.ID
+	}
+
+	if _, err := s.taskStore.Get(ctx, taskID); err != nil {
+		return nil, taskLookupRPCError(err, taskID)
+	}
+
+	if req.Method == protocol.MethodPushConfigSet {
+		if err := s.notifier.Register(taskID, config); err != nil {
+			return nil, invalidParams(err.Error())
+		}
+	} else {
+		var ok bool
+		if config, ok = s.notifier.Config(taskID); !ok {
+			return nil, invalidParams("No push notification config is set for the task")
+		}
+	}
+
+	config.Secret = ""
+	return protocol.TaskPushNotificationConfig{TaskID: taskID, PushNotificationConfig: config}, nil
+}
+
+// handleJSONRPCStream answers message/stream and tasks/resubscribe with an SSE stream of
+// JSON-RPC responses: the task, then its status-update and artifact-update events until
+// the final one. Each message carries the task event ID so a client reconnecting with
+// tasks/resubscribe and Last-Event-ID resumes where it left off. Errors raised before the
+// stream starts are returned as a plain JSON-RPC response.
+func (s *Server) handleJSONRPCStream(w http.ResponseWriter, r *http.Request, req *protocol.JSONRPCRequest) {
+	ctx := withCallerToken(r)
+
+	var task *protocol.Task
+	var rpcErr *protocol.JSONRPCError
+	switch req.Method {
+	case protocol.MethodMessageStream:
+		var params protocol.MessageSendParams
+		if rpcErr = decodeParams(req.Params, &params); rpcErr == nil {
+			task, rpcErr = s.rpcMessageSend(ctx, &params)
+		}
+	default:
+		var params protocol.TaskIDParams
+		if rpcErr = decodeParams(req.Params, &params); rpcErr == nil {
+			var err error
+			if task, err = s.taskStore.Get(ctx, params.ID); err != nil {
+				rpcErr = taskLookupRPCError(err, params.ID)
+			}
+		}
+	}
+	if rpcErr != nil {
+		writeJSONRPC(w, r, &protocol.JSONRPCResponse{JSONRPC: protocol.JSONRPCVersion, ID: req.ID, Error: rpcErr})
+		return
+	}
+
+	flusher, ok := startSSE(w)
+	if !ok {
+		writeJSONRPC(w, r, protocol.NewJSONRPCError(req.ID, protocol.UnsupportedOperation, "Streaming unsupported", nil))
+		return
+	}
+
+	// A resuming client already has the task; everyone else starts from a snapshot
+	afterID := lastEventID(r)
+	if afterID == 0 {
+		if err := writeSSE(w, flusher, 0, protocol.NewJSONRPCResult(req.ID, s.a2aTask(ctx, task))); err != nil {
+			return
+		}
+	}
+
+	s.streamTaskEvents(ctx, w, flusher, task.ID, afterID, func(event protocol.TaskEvent) interface{} {
+		return protocol.NewJSONRPCResult(req.ID, protocol.ToA2AEvent(task, event))
+	})
+}
+
+// rpcAgentCard returns the named agent, or the agent this server was started for
+func (s *Server) rpcAgentCard(ctx context.Context, agentID string) (*protocol.AgentCard, error) {
+	if agentID != "" {
+		return s.agentStore.Get(ctx, agentID)
+	}
+	if s.agentCard != nil {
+		return s.agentCard, nil
+	}
+	cards := s.agentStore.List(ctx)
+	if len(cards) == 0 {
+		return nil, errAgentNotFound
+	}
+	return cards[0], nil
+}
+
+// decodeParams decodes JSON-RPC params, reporting failures as invalid params
+func decodeParams(raw json.RawMessage, v interface{}) *protocol.JSONRPCError {
+	if len(raw) == 0 {
+		return invalidParams("params are required")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return invalidParams("Invalid params: " + err.Error())
+	}
+	return nil
+}
+
+// pushNotSupported creates the error for push notification requests when they are disabled
+func pushNotSupported() *protocol.JSONRPCError {
+	return &protocol.JSONRPCError{Code: protocol.PushNotificationNotSupported, Message: "Push Notification is not supported"}
+}
+
+// invalidParams creates an invalid params error
+func invalidParams(message string) *protocol.JSONRPCError {
+	return &protocol.JSONRPCError{Code: protocol.InvalidParams, Message: message}
+}
+
+// taskLookupRPCError maps a task store failure to a JSON-RPC error
+func taskLookupRPCError(err error, taskID string) *protocol.JSONRPCError {
+	data := map[string]interface{}{"id": taskID}
+	switch {
+	case isIntegrityError(err):
+		return &protocol.JSONRPCError{Code: protocol.InternalError, Message: "Task integrity check failed", Data: data}
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		return &protocol.JSONRPCError{Code: protocol.TaskNotFound, Message: "Task not found", Data: data}
+	default:
+		return &protocol.JSONRPCError{Code: protocol.InternalError, Message: err.Error(), Data: data}
+	}
+}
+
+// metadataValue returns the first value stored under key in the metadata maps
+func metadataValue(key string, metadata ...map[string]interface{}) interface{} {
+	for _, m := range metadata {
+		if value, ok := m[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// metadataString returns the first string stored under key in the metadata maps
+func metadataString(key string, metadata ...map[string]interface{}) string {
+	value, _ := metadataValue(key, metadata...).(string)
+	return value
+}
+
+// metadataStrings returns the first string array stored under key in the metadata maps;
+// ok is false if the value is not an array of strings
+func metadataStrings(key string, metadata ...map[string]interface{}) (values []string, ok bool) {
+	value := metadataValue(key, metadata...)
+	if value == nil {
+		return nil, true
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, s)
+	}
+	return values, true
+}
+
+// writeJSONRPC writes a JSON-RPC response; protocol errors are reported in the body
+// with HTTP 200 as JSON-RPC over HTTP expects, and carry the request ID
+func writeJSONRPC(w http.ResponseWriter, r *http.Request, resp *protocol.JSONRPCResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Error != nil {
+		resp.Error.SetRequestID(logging.RequestID(r.Context()))
+	}
+	json.NewEncoder(w).Encode(resp)
+}
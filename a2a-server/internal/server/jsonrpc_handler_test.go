@@ -0,0 +1,313 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+	"github.com/bhatti/mcp-a2a-go/shared/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcResponse is a JSON-RPC response with the result left undecoded
+type rpcResponse struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      json.RawMessage        `json:"id"`
+	Result  json.RawMessage        `json:"result"`
+	Error   *protocol.JSONRPCError `json:"error"`
+}
+
+func callJSONRPC(t *testing.T, server *Server, body string) rpcResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, JSONRPCPath, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleJSONRPC(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp rpcResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "2.0", resp.JSONRPC)
+	return resp
+}
+
+func setupJSONRPCServer(t *testing.T) *Server {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	card.AddCapability(protocol.Capability{Name: "summarize"})
+	require.NoError(t, server.agentStore.Register(ctx, card))
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+	return server
+}
+
+const messageSend = `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{
+	"message":{"kind":"message","role":"user","messageId":"m-1","contextId":"ctx-1",
+		"parts":[{"kind":"text","text":"transformers"},{"kind":"data","data":{"limit":5}}]},
+	"metadata":{"capability":"search","user_id":"user-1"}}}`
+
+func TestJSONRPC_MessageSendGetCancel(t *testing.T) {
+	server := setupJSONRPCServer(t)
+
+	resp := callJSONRPC(t, server, messageSend)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "1", string(resp.ID))
+
+	var task protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	assert.Equal(t, protocol.KindTask, task.Kind)
+	assert.Equal(t, "ctx-1", task.ContextID)
+	assert.Equal(t, protocol.A2AStateSubmitted, task.Status.State)
+
+	stored, err := server.taskStore.Get(context.Background(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "search", stored.Capability)
+	assert.Equal(t, "transformers", stored.Input["text"])
+	assert.Equal(t, 5.0, stored.Input["limit"])
+	require.NotNil(t, stored.Message, "the task keeps the message it was sent with")
+	assert.Equal(t, "m-1", stored.Message.MessageID)
+	assert.Equal(t, []protocol.Part{protocol.TextPart("transformers"), protocol.DataPart(map[string]interface{}{"limit": 5.0})}, stored.Message.Parts)
+
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":"get-1","method":"tasks/get","params":{"id":"`+task.ID+`"}}`)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, `"get-1"`, string(resp.ID))
+
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":2,"method":"tasks/cancel","params":{"id":"`+task.ID+`"}}`)
+	require.Nil(t, resp.Error)
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	assert.Equal(t, protocol.A2AStateCanceled, task.Status.State)
+
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":3,"method":"tasks/cancel","params":{"id":"`+task.ID+`"}}`)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.TaskNotCancelable, resp.Error.Code)
+}
+
+func TestJSONRPC_MessageSendFiles(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	server.SetMaxInlineFileBytes(16)
+
+	send := func(file string) rpcResponse {
+		return callJSONRPC(t, server, `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{
+			"message":{"kind":"message","role":"user","messageId":"m-1","contextId":"ctx-1",
+				"parts":[{"kind":"text","text":"Summarize the notes"},{"kind":"file","file":`+file+`}]},
+			"metadata":{"capability":"summarize","user_id":"user-1"}}}`)
+	}
+
+	// "U2hvcnQgbm90ZXMu" is "Short notes." in base64
+	resp := send(`{"name":"notes.txt","mimeType":"text/plain","bytes":"U2hvcnQgbm90ZXMu"}`)
+	require.Nil(t, resp.Error)
+	var task protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	stored, err := server.taskStore.Get(context.Background(), task.ID)
+	require.NoError(t, err)
+	files := protocol.Files(stored.Input)
+	require.Len(t, files, 1)
+	assert.Equal(t, []byte("Short notes."), files[0].Bytes)
+
+	// tasks/get returns the input message, files and all
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"id":"`+task.ID+`"}}`)
+	require.Nil(t, resp.Error)
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	require.Len(t, task.History, 1)
+	assert.Equal(t, []protocol.Part{
+		protocol.TextPart("Summarize the notes"),
+		protocol.FilePart("notes.txt", "text/plain", []byte("Short notes.")),
+	}, task.History[0].Parts)
+
+	// "Tm90ZXMgbG9uZ2VyIHRoYW4gdGhlIGxpbWl0Lg==" is 28 bytes, over the limit
+	resp = send(`{"name":"notes.txt","bytes":"Tm90ZXMgbG9uZ2VyIHRoYW4gdGhlIGxpbWl0Lg=="}`)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.RequestTooLarge, resp.Error.Code)
+	resp = send(`{"name":"notes.txt","uri":"https://example.com/notes.txt"}`)
+	assert.Nil(t, resp.Error)
+}
+
+func TestJSONRPC_MessageSendInvalidInput(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	card, err := server.agentStore.Get(context.Background(), "test-agent")
+	require.NoError(t, err)
+	card.Capabilities[0].InputSchema = map[string]interface{}{
+		"properties": map[string]interface{}{"limit": map[string]interface{}{"type": "integer", "maximum": 3}},
+	}
+
+	resp := callJSONRPC(t, server, messageSend)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+	data, _ := json.Marshal(resp.Error.Data)
+	assert.JSONEq(t, `{"errors":[{"field":"/limit","message":"maximum: got 5, want 3"}]}`, string(data))
+}
+
+func TestJSONRPC_PushNotificationConfig(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	notifier := webhook.NewNotifier(server.taskStore, webhook.Config{})
+	defer notifier.Close()
+	server.SetNotifier(notifier)
+
+	withConfig := strings.Replace(messageSend, `"metadata":{`,
+		`"configuration":{"pushNotificationConfig":{"url":"https://example.com/first-hook","secret":"s3cret"}},"metadata":{`, 1)
+	resp := callJSONRPC(t, server, withConfig)
+	require.Nil(t, resp.Error)
+	var task protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	config, ok := notifier.Config(task.ID)
+	require.True(t, ok)
+	assert.Equal(t, "s3cret", config.Secret)
+
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":2,"method":"tasks/pushNotificationConfig/set","params":{
+		"taskId":"`+task.ID+`","pushNotificationConfig":{"url":"https://example.com/hook","token":"tok","secret":"other"}}}`)
+	require.Nil(t, resp.Error)
+	var set protocol.TaskPushNotificationConfig
+	require.NoError(t, json.Unmarshal(resp.Result, &set))
+	assert.Equal(t, protocol.TaskPushNotificationConfig{
+		TaskID:                 task.ID,
+		PushNotificationConfig: protocol.PushNotificationConfig{URL: "https://example.com/hook", Token: "tok"},
+	}, set, "the secret is not returned")
+
+	resp = callJSONRPC(t, server, `{"jsonrpc":"2.0","id":3,"method":"tasks/pushNotificationConfig/get","params":{"id":"`+task.ID+`"}}`)
+	require.Nil(t, resp.Error)
+	var got protocol.TaskPushNotificationConfig
+	require.NoError(t, json.Unmarshal(resp.Result, &got))
+	assert.Equal(t, set, got)
+	config, _ = notifier.Config(task.ID)
+	assert.Equal(t, "other", config.Secret)
+
+	failures := map[string]int{
+		`{"jsonrpc":"2.0","id":4,"method":"tasks/pushNotificationConfig/set","params":{"taskId":"missing","pushNotificationConfig":{"url":"https://example.com"}}}`: protocol.TaskNotFound,
+		`{"jsonrpc":"2.0","id":5,"method":"tasks/pushNotificationConfig/set","params":{"taskId":"` + task.ID + `","pushNotificationConfig":{"url":"example.com"}}}`: protocol.InvalidParams,
+		strings.Replace(withConfig, `https://example.com/first-hook`, `file:///etc/passwd`, 1):                                                                      protocol.InvalidParams,
+		strings.Replace(withConfig, `https://example.com/first-hook`, `http://127.0.0.1:1/hook`, 1):                                                                 protocol.InvalidParams,
+	}
+	for body, code := range failures {
+		resp = callJSONRPC(t, server, body)
+		require.NotNil(t, resp.Error, body)
+		assert.Equal(t, code, resp.Error.Code, body)
+	}
+}
+
+func TestJSONRPC_Errors(t *testing.T) {
+	server := setupJSONRPCServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"parse error", `{`, protocol.ParseError},
+		{"wrong version", `{"jsonrpc":"1.0","id":1,"method":"tasks/get"}`, protocol.InvalidRequest},
+		{"missing id", `{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"x"}}`, protocol.InvalidRequest},
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"tasks/list","params":{}}`, protocol.MethodNotFound},
+		{"missing params", `{"jsonrpc":"2.0","id":1,"method":"tasks/get"}`, protocol.InvalidParams},
+		{"task not found", `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"missing"}}`, protocol.TaskNotFound},
+		{"stream invalid message", `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{}}`, protocol.InvalidParams},
+		{"resubscribe unknown task", `{"jsonrpc":"2.0","id":1,"method":"tasks/resubscribe","params":{"id":"missing"}}`, protocol.TaskNotFound},
+		{"push notifications", `{"jsonrpc":"2.0","id":1,"method":"tasks/pushNotificationConfig/set","params":{}}`, protocol.PushNotificationNotSupported},
+		{"ambiguous capability", strings.Replace(messageSend, `"capability":"search",`, ``, 1), protocol.InvalidParams},
+		{"unknown user", strings.Replace(messageSend, `"user-1"`, `"nobody"`, 1), protocol.InvalidParams},
+		{"continue unknown task", strings.Replace(messageSend, `"messageId":"m-1"`, `"messageId":"m-1","taskId":"t-1"`, 1), protocol.TaskNotFound},
+		{"unsupported part", strings.Replace(messageSend, `"kind":"text"`, `"kind":"video"`, 1), protocol.ContentTypeNotSupported},
+		{"file part without a file", strings.Replace(messageSend, `"kind":"text"`, `"kind":"file"`, 1), protocol.InvalidParams},
+		{"fractional ttl", strings.Replace(messageSend, `"user_id":"user-1"`, `"user_id":"user-1","ttl_seconds":1.5`, 1), protocol.InvalidParams},
+		{"negative ttl", strings.Replace(messageSend, `"user_id":"user-1"`, `"user_id":"user-1","ttl_seconds":-60`, 1), protocol.InvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := callJSONRPC(t, server, tt.body)
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tt.code, resp.Error.Code, resp.Error.Message)
+		})
+	}
+}
+
+func TestJSONRPC_ErrorCarriesRequestID(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	handler := httpserver.NewLoggingMiddleware(nil).Handler(http.HandlerFunc(server.handleJSONRPC))
+
+	req := httptest.NewRequest(http.MethodPost, JSONRPCPath,
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"missing"}}`))
+	req.Header.Set(httpserver.RequestIDHeader, "req-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "req-123", rr.Header().Get(httpserver.RequestIDHeader))
+	var resp rpcResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.TaskNotFound, resp.Error.Code)
+	data := resp.Error.Data.(map[string]interface{})
+	assert.Equal(t, "req-123", data["request_id"])
+	assert.Equal(t, "missing", data["id"], "existing error data is kept")
+}
+
+func TestJSONRPC_BudgetExceeded(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	require.NoError(t, server.budgetManager.SetBudget(context.Background(), "user-1", 0.001))
+
+	resp := callJSONRPC(t, server, messageSend)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.BudgetExceeded, resp.Error.Code)
+	data, ok := resp.Error.Data.(map[string]interface{})
+	require.True(t, ok, "error data names the exceeded budget")
+	assert.Equal(t, cost.LevelUser, data["level"])
+
+	// A task cost cap below the estimate
+	require.NoError(t, server.budgetManager.SetBudget(context.Background(), "user-1", 10))
+	resp = callJSONRPC(t, server, strings.Replace(messageSend, `"user_id":"user-1"`, `"user_id":"user-1","max_cost_usd":0.0001`, 1))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.BudgetExceeded, resp.Error.Code)
+	assert.Equal(t, cost.LevelTask, resp.Error.Data.(map[string]interface{})["level"])
+
+	resp = callJSONRPC(t, server, strings.Replace(messageSend, `"user_id":"user-1"`, `"user_id":"user-1","max_cost_usd":"cheap"`, 1))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+}
+
+func TestJSONRPC_MethodNotAllowed(t *testing.T) {
+	server := setupTestServer()
+
+	rr := httptest.NewRecorder()
+	server.handleJSONRPC(rr, httptest.NewRequest(http.MethodGet, JSONRPCPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestJSONRPC_RequestTooLarge(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	server.SetBodyLimits(httpserver.BodyConfig{MaxRequestBytes: 64})
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	handler := server.body.Handler(mux)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, JSONRPCPath, strings.NewReader(messageSend)))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp rpcResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.RequestTooLarge, resp.Error.Code)
+	assert.Equal(t, "Request body exceeds 64 bytes", resp.Error.Message)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(strings.Repeat(" ", 100))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func TestJSONRPC_BacklogFull(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	server.SetMaxPending(1)
+
+	resp := callJSONRPC(t, server, messageSend)
+	require.Nil(t, resp.Error)
+
+	resp = callJSONRPC(t, server, messageSend)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.ServerBusy, resp.Error.Code)
+	assert.EqualValues(t, 5, resp.Error.Data.(map[string]interface{})["retry_after_seconds"])
+}
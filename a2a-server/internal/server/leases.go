@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/google/uuid"
+)
+
+// AdminTasksPath is the endpoint prefix for operating on tasks, e.g. DELETE
+// /admin/tasks/{task_id}/lease to force-release a running task's lease
+const AdminTasksPath = "/admin/tasks/"
+
+// DefaultLeaseTTL is how long a running task's lease lasts without a heartbeat
+const DefaultLeaseTTL = 30 * time.Second
+
+// Lease release triggers and outcomes, as recorded in metrics
+const (
+	LeaseTriggerExpired = "expired"
+	LeaseTriggerAdmin   = "admin"
+
+	LeaseRequeued = "requeued"
+	LeaseFailed   = "failed"
+)
+
+// leasePageSize is how many running tasks are checked per store query
+const leasePageSize = 100
+
+// errLeaseLost is the cause of the execution context of a task whose lease was lost
+var errLeaseLost = errors.New("task lease lost")
+
+// leaseOwner identifies a processor on the leases it holds
+func leaseOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+}
+
+// SetLease makes the processor hold a lease of ttl on each task it runs, renewed every
+// third of it, and recover the tasks of processors that stopped renewing theirs, e.g.
+// because they crashed: they are requeued, or failed once they were started maxAttempts
+// times (zero requeues them indefinitely). Zero ttl disables leases.
+func (p *TaskProcessor) SetLease(ttl time.Duration, maxAttempts int) {
+	p.leaseTTL = ttl
+	p.maxAttempts = maxAttempts
+}
+
+// acquireLease counts a new execution of the task and gives the processor its lease
+func (p *TaskProcessor) acquireLease(task *protocol.Task) {
+	task.Attempts++
+	if p.leaseTTL > 0 {
+		now := time.Now()
+		task.Lease = &protocol.TaskLease{Owner: p.owner, HeartbeatAt: now, ExpiresAt: now.Add(p.leaseTTL)}
+	}
+}
+
+// heartbeat renews the task's lease every third of its TTL until the returned function
+// is first called. The returned context is cancelled with errLeaseLost when the task is
+// no longer running under the lease, e.g. because it was released or cancelled, so the
+// execution stops.
+func (p *TaskProcessor) heartbeat(ctx context.Context, taskID string) (context.Context, func()) {
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	if p.leaseTTL <= 0 {
+		return leaseCtx, func() { cancel(nil) }
+	}
+
+	var once sync.Once
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := p.taskStore.RenewLease(ctx, taskID, p.owner, time.Now().Add(p.leaseTTL))
+				if errors.Is(err, tasks.ErrLeaseLost) || errors.Is(err, tasks.ErrTaskNotFound) {
+					slog.WarnContext(ctx, "Task lease lost, stopping execution", "task_id", taskID)
+					cancel(errLeaseLost)
+					return
+				}
+				if err != nil {
+					slog.WarnContext(ctx, "Error renewing task lease", "task_id", taskID, "error", err)
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return leaseCtx, func() {
+		once.Do(func() { close(done) })
+		<-stopped
+		cancel(nil)
+	}
+}
+
+// saveOutcome stores a finished task, releasing its lease. It fails with
+// tasks.ErrLeaseLost, and the outcome is dropped, if the task was released or cancelled
+// while it ran.
+func (p *TaskProcessor) saveOutcome(ctx context.Context, task *protocol.Task) error {
+	if p.leaseTTL <= 0 {
+		return p.taskStore.Update(ctx, task)
+	}
+	task.Lease = nil
+	return p.taskStore.UpdateLeased(ctx, task, p.owner)
+}
+
+// reapLeases recovers the tasks whose lease expired every half lease TTL until the
+// processor stops
+func (p *TaskProcessor) reapLeases(ctx context.Context) {
+	ticker := time.NewTicker(p.leaseTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.recoverExpiredLeases(ctx)
+		case <-p.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recoverExpiredLeases requeues the running tasks whose lease expired, failing those
+// started maxAttempts times, and returns how many it found. Tasks running without a
+// lease, started while leases were disabled, are left alone.
+func (p *TaskProcessor) recoverExpiredLeases(ctx context.Context) int {
+	now := time.Now()
+	var expired []*protocol.Task
+	for offset := 0; ; offset += leasePageSize {
+		running, err := p.taskStore.List(ctx, tasks.ListFilter{State: protocol.TaskStateRunning}, leasePageSize, offset)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error listing running tasks", "error", err)
+			return 0
+		}
+		for _, task := range running {
+			if task.Lease != nil && task.Lease.Expired(now) {
+				expired = append(expired, task)
+			}
+		}
+		if len(running) < leasePageSize {
+			break
+		}
+	}
+	if p.metrics != nil {
+		p.metrics.RecordStuckTasks(ctx, int64(len(expired)))
+	}
+
+	for _, task := range expired {
+		slog.WarnContext(ctx, "Task lease expired", "task_id", task.ID, "owner", task.Lease.Owner,
+			"heartbeat_at", task.Lease.HeartbeatAt, "attempts", task.Attempts)
+		fail := p.maxAttempts > 0 && task.Attempts >= p.maxAttempts
+		outcome, err := releaseLease(ctx, p.taskStore, p.queue, task, fail, "lease expired")
+		if errors.Is(err, tasks.ErrLeaseLost) || errors.Is(err, tasks.ErrTaskNotFound) {
+			// Finished, or deleted, since it was listed
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Error releasing expired task lease", "task_id", task.ID, "error", err)
+			continue
+		}
+		recordLeaseReleased(ctx, p.metrics, LeaseTriggerExpired, outcome)
+	}
+	return len(expired)
+}
+
+// releaseLease takes a running task from the processor holding its lease and requeues
+// it, or fails it when fail is set. It returns tasks.ErrLeaseLost if the task finished or
+// changed hands meanwhile.
+func releaseLease(ctx context.Context, store tasks.Store, queue tasks.Queue, task *protocol.Task, fail bool, reason string) (string, error) {
+	owner := task.Lease.Owner
+	task.Lease = nil
+
+	if fail {
+		message := fmt.Sprintf("Task failed after %d attempts: %s", task.Attempts, reason)
+		task.SetError(message)
+		if err := store.UpdateLeased(ctx, task, owner); err != nil {
+			return "", err
+		}
+		store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateFailed, Message: message,
+			Data: map[string]interface{}{"lease_owner": owner}})
+		slog.WarnContext(ctx, "Task failed after losing its lease", "task_id", task.ID, "owner", owner, "reason", reason)
+		if queue != nil {
+			enqueueDependents(ctx, store, queue, task.ID)
+		}
+		return LeaseFailed, nil
+	}
+
+	task.UpdateState(protocol.TaskStatePending)
+	if err := store.UpdateLeased(ctx, task, owner); err != nil {
+		return "", err
+	}
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStatePending, Message: "Task requeued: " + reason,
+		Data: map[string]interface{}{"lease_owner": owner}})
+	slog.WarnContext(ctx, "Task requeued after losing its lease", "task_id", task.ID, "owner", owner, "reason", reason)
+	// Without a queue the next poll picks it up
+	if queue != nil {
+		if err := queue.Enqueue(ctx, task.ID); err != nil {
+			slog.ErrorContext(ctx, "Error queueing released task", "task_id", task.ID, "error", err)
+		}
+	}
+	return LeaseRequeued, nil
+}
+
+// recordLeaseReleased records a released lease when metrics are enabled
+func recordLeaseReleased(ctx context.Context, metrics *observability.Metrics, trigger, outcome string) {
+	if metrics != nil {
+		metrics.RecordTaskLeaseReleased(ctx, trigger, outcome)
+	}
+}
+
+// handleAdminTask handles DELETE /admin/tasks/{task_id}/lease, which takes a running task
+// from the processor holding its lease, e.g. one stuck in a hung capability, and requeues
+// it, or fails it with ?fail=true. The processor stops the execution at its next
+// heartbeat and drops its outcome.
+func (s *Server) handleAdminTask(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	taskID, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, AdminTasksPath), "/")
+	if taskID == "" || resource != "lease" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	task, err := s.taskStore.Get(ctx, taskID)
+	if errors.Is(err, tasks.ErrTaskNotFound) {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load task", http.StatusInternalServerError)
+		return
+	}
+	if task.State != protocol.TaskStateRunning || task.Lease == nil {
+		http.Error(w, "Task holds no lease", http.StatusConflict)
+		return
+	}
+
+	outcome, err := releaseLease(ctx, s.taskStore, s.queue, task, r.URL.Query().Get("fail") == "true", "lease released by an administrator")
+	if errors.Is(err, tasks.ErrLeaseLost) || errors.Is(err, tasks.ErrTaskNotFound) {
+		http.Error(w, "Task holds no lease", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error releasing task lease", "task_id", taskID, "error", err)
+		http.Error(w, "Failed to release lease", http.StatusInternalServerError)
+		return
+	}
+	if s.telemetry != nil {
+		recordLeaseReleased(ctx, s.telemetry.Metrics, LeaseTriggerAdmin, outcome)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRunning stores a task running under a lease of owner expiring at expiresAt
+func createRunning(t *testing.T, store tasks.Store, owner string, expiresAt time.Time, attempts int) *protocol.Task {
+	t.Helper()
+	task := protocol.NewTask("agent-1", "search", nil)
+	task.UpdateState(protocol.TaskStateRunning)
+	task.Attempts = attempts
+	task.Lease = &protocol.TaskLease{Owner: owner, HeartbeatAt: expiresAt.Add(-time.Minute), ExpiresAt: expiresAt}
+	require.NoError(t, store.Create(context.Background(), task))
+	return task
+}
+
+func TestTaskProcessor_RecoversExpiredLeases(t *testing.T) {
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	queue := &recordingQueue{}
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetQueue(queue, 1, 0)
+	processor.SetLease(time.Minute, 3)
+
+	expired := time.Now().Add(-time.Second)
+	crashed := createRunning(t, store, "replica-1", expired, 1)
+	exhausted := createRunning(t, store, "replica-1", expired, 3)
+	live := createRunning(t, store, "replica-2", time.Now().Add(time.Minute), 1)
+	unleased := protocol.NewTask("agent-1", "search", nil)
+	unleased.UpdateState(protocol.TaskStateRunning)
+	require.NoError(t, store.Create(ctx, unleased))
+
+	assert.Equal(t, 2, processor.recoverExpiredLeases(ctx))
+
+	requeued, err := store.Get(ctx, crashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStatePending, requeued.State)
+	assert.Nil(t, requeued.Lease)
+	assert.Equal(t, []string{crashed.ID}, queue.enqueued)
+
+	failed, err := store.Get(ctx, exhausted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, failed.State)
+	assert.Contains(t, failed.Error, "after 3 attempts")
+	events := store.Events(ctx, exhausted.ID, 0)
+	require.NotEmpty(t, events)
+	assert.True(t, events[len(events)-1].Final())
+
+	for _, task := range []*protocol.Task{live, unleased} {
+		current, err := store.Get(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateRunning, current.State)
+	}
+	assert.Zero(t, processor.recoverExpiredLeases(ctx))
+
+	// A delivery of a task running under a live lease is a duplicate
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "1-0", TaskID: live.ID, Attempt: 2})
+	assert.Equal(t, []string{live.ID}, queue.acked)
+}
+
+func TestTaskProcessor_HeartbeatsUntilLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{Name: "hang"}, capabilities.ExecutorFunc(
+		func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+			close(started)
+			<-ctx.Done()
+			stopped <- context.Cause(ctx)
+			return nil, ctx.Err()
+		}))
+
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetExecutors(executors, cost.NewMemoryTracker())
+	processor.SetLease(30*time.Millisecond, 0)
+
+	task := protocol.NewTask("agent-1", "hang", nil)
+	require.NoError(t, store.Create(ctx, task))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		processor.processTask(ctx, task)
+	}()
+	<-started
+
+	// The lease stays live while the processor heartbeats
+	time.Sleep(100 * time.Millisecond)
+	running, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, running.Lease)
+	assert.Equal(t, processor.owner, running.Lease.Owner)
+	assert.False(t, running.Lease.Expired(time.Now()))
+	assert.Equal(t, 1, running.Attempts)
+	assert.Zero(t, processor.recoverExpiredLeases(ctx))
+
+	// Releasing it stops the execution, whose outcome is dropped
+	released := *running
+	outcome, err := releaseLease(ctx, store, nil, &released, false, "test")
+	require.NoError(t, err)
+	assert.Equal(t, LeaseRequeued, outcome)
+	select {
+	case cause := <-stopped:
+		assert.ErrorIs(t, cause, errLeaseLost)
+	case <-time.After(time.Second):
+		t.Fatal("execution was not stopped")
+	}
+	<-done
+
+	current, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStatePending, current.State)
+	assert.Empty(t, current.Error)
+}
+
+func TestServer_ReleaseTaskLease(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		server.handleAdminTask(rr, req)
+		return rr
+	}
+
+	stuck := createRunning(t, server.taskStore, "replica-1", time.Now().Add(time.Minute), 1)
+	path := AdminTasksPath + stuck.ID + "/lease"
+
+	// Disabled until an admin token is configured
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, path, "").Code)
+
+	server.SetAdminToken("secret")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodDelete, path, "wrong").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, path, "secret").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, AdminTasksPath+stuck.ID, "secret").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, AdminTasksPath+"missing/lease", "secret").Code)
+
+	rr := serve(http.MethodDelete, path, "secret")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var released protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&released))
+	assert.Equal(t, protocol.TaskStatePending, released.State)
+	assert.Nil(t, released.Lease)
+
+	// A pending task holds no lease
+	assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, path, "secret").Code)
+
+	// Or fail it instead
+	stuck = createRunning(t, server.taskStore, "replica-1", time.Now().Add(time.Minute), 1)
+	rr = serve(http.MethodDelete, AdminTasksPath+stuck.ID+"/lease?fail=true", "secret")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	failed, err := server.taskStore.Get(ctx, stuck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, failed.State)
+	assert.Contains(t, failed.Error, "released by an administrator")
+}
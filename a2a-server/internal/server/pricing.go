@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+)
+
+// AdminPricingPath is the endpoint reading and replacing the model price table
+const AdminPricingPath = "/admin/pricing"
+
+// handlePricing handles GET /admin/pricing, which returns the current price table, and
+// PUT /admin/pricing, which replaces it. Usage recorded from then on carries the new
+// table's version. A replaced table lasts until the pricing file, if any, next changes.
+func (s *Server) handlePricing(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if s.executors == nil {
+		http.Error(w, "Pricing is not configured", http.StatusNotFound)
+		return
+	}
+	pricing := s.executors.Pricing()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var table cost.PriceTable
+		if err := json.NewDecoder(r.Body).Decode(&table); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := pricing.Set(table); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Price table replaced", "version", pricing.Version(), "models", len(table.Models))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pricing.Table())
+}
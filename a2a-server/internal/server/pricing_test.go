@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Pricing(t *testing.T) {
+	server := setupTestServer()
+	server.SetAdminToken("secret")
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, AdminPricingPath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		server.handlePricing(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder) cost.PriceTable {
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var table cost.PriceTable
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&table))
+		return table
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "").Code, "no executors to price")
+
+	executors := capabilities.NewRegistry()
+	server.SetExecutors(executors)
+	assert.Equal(t, "builtin-2024", decode(serve(http.MethodGet, "")).Version)
+
+	table := decode(serve(http.MethodPut, `{"version": "2025-01", "default_model": "gpt-4o",
+		"models": {"gpt-4o": {"prompt_usd": 2.5, "completion_usd": 10, "unit": "per_1m"}}}`))
+	assert.Equal(t, "2025-01", table.Version)
+	costUSD, version := executors.Pricing().Cost("anything", 1_000_000, 0)
+	assert.Equal(t, 2.5, costUSD)
+	assert.Equal(t, "2025-01", version)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, `{"models": {"gpt-4o": {"unit": "per_token"}}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, `{`).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodDelete, "").Code)
+	assert.Equal(t, "2025-01", executors.Pricing().Version())
+}
@@ -2,18 +2,78 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/a2aclient"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/agentcard"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/observability"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/speculative"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracerName names the spans of task executions
+const tracerName = "github.com/bhatti/mcp-a2a-go/a2a-server/internal/server"
+
+// errSimulatedFailure is the error returned by the simulated executor
+var errSimulatedFailure = errors.New("Simulated task failure")
+
+// simulatedStep is how long each step of simulated execution takes
+var simulatedStep = time.Second
+
 // TaskProcessor processes tasks in the background (demo implementation)
 type TaskProcessor struct {
 	taskStore tasks.Store
 	interval  time.Duration
 	stopCh    chan struct{}
+
+	// agentStore and costCapUSD enable speculative execution; nil disables it
+	agentStore *agentcard.Store
+	costCapUSD float64
+
+	// queue, when set, replaces polling the store: workers claim tasks from it so several
+	// replicas can share the work
+	queue         tasks.Queue
+	workers       int
+	maxDeliveries int64
+
+	// executors run the capabilities registered with them; the others are simulated.
+	// costTracker, when set, records the usage each execution reports.
+	executors   *capabilities.Registry
+	costTracker cost.Tracker
+	// delegator, when set, runs capabilities without an executor on remote agents that
+	// offer them before falling back to simulation
+	delegator *a2aclient.Delegator
+
+	// budgets, when set, are checked again before a task starts
+	budgets *cost.BudgetManager
+
+	// blobs keep the artifact file parts larger than inlineLimit; nil keeps them inline
+	blobs       blobs.Store
+	inlineLimit int
+
+	// policy orders and limits task execution; the scheduler applying it is created by Start
+	policy    SchedulingPolicy
+	metrics   *observability.Metrics
+	scheduler *scheduler
+
+	// owner identifies the processor on the leases of the tasks it runs; leaseTTL zero
+	// runs tasks without leases
+	owner       string
+	leaseTTL    time.Duration
+	maxAttempts int
 }
 
 // NewTaskProcessor creates a new task processor
@@ -22,11 +82,71 @@ func NewTaskProcessor(taskStore tasks.Store, interval time.Duration) *TaskProces
 		taskStore: taskStore,
 		interval:  interval,
 		stopCh:    make(chan struct{}),
+		owner:     leaseOwner(),
 	}
 }
 
+// SetSpeculation enables speculative execution of substitutable capabilities for tasks
+// that request it, launching at most costCapUSD worth of alternatives
+func (p *TaskProcessor) SetSpeculation(agentStore *agentcard.Store, costCapUSD float64) {
+	p.agentStore = agentStore
+	p.costCapUSD = costCapUSD
+}
+
+// SetQueue processes the tasks claimed from queue with the given number of workers
+// instead of polling the store. A task delivered more than maxDeliveries times without
+// being acknowledged, e.g. because it keeps crashing its processor, is dead-lettered and
+// failed; zero redelivers it indefinitely.
+func (p *TaskProcessor) SetQueue(queue tasks.Queue, workers, maxDeliveries int) {
+	p.queue = queue
+	p.workers = max(workers, 1)
+	p.maxDeliveries = int64(maxDeliveries)
+}
+
+// SetExecutors runs the capabilities registered with executors instead of simulating
+// them, recording the usage of every execution, including losing speculative attempts
+// that finished, with costTracker when it is not nil
+func (p *TaskProcessor) SetExecutors(executors *capabilities.Registry, costTracker cost.Tracker) {
+	p.executors = executors
+	p.costTracker = costTracker
+}
+
+// SetDelegator delegates capabilities the local agent cannot execute to remote agents
+func (p *TaskProcessor) SetDelegator(delegator *a2aclient.Delegator) {
+	p.delegator = delegator
+}
+
+// SetBudgets fails tasks whose tenant or user budget was exhausted while they waited,
+// e.g. because a tenant's limit was lowered
+func (p *TaskProcessor) SetBudgets(budgets *cost.BudgetManager) {
+	p.budgets = budgets
+}
+
+// SetScheduling sets the dispatch order and concurrency limits of task execution
+func (p *TaskProcessor) SetScheduling(policy SchedulingPolicy) {
+	p.policy = policy
+}
+
+// SetMetrics records the depth of the dispatch queue by priority
+func (p *TaskProcessor) SetMetrics(metrics *observability.Metrics) {
+	p.metrics = metrics
+}
+
 // Start starts the task processor
 func (p *TaskProcessor) Start(ctx context.Context) {
+	policy := p.policy
+	if p.queue != nil && (policy.MaxConcurrent <= 0 || policy.MaxConcurrent > p.workers) {
+		policy.MaxConcurrent = p.workers
+	}
+	p.scheduler = newScheduler(policy, p.metrics)
+	if p.leaseTTL > 0 {
+		go p.reapLeases(ctx)
+	}
+
+	if p.queue != nil {
+		go p.runQueue(ctx)
+		return
+	}
 	go p.run(ctx)
 }
 
@@ -35,106 +155,585 @@ func (p *TaskProcessor) Stop() {
 	close(p.stopCh)
 }
 
-// run is the main processing loop
+// run is the main processing loop. Tasks still running when it stops finish; pending
+// tasks waiting for a slot are picked up again after a restart.
 func (p *TaskProcessor) run(ctx context.Context) {
 	ticker := time.NewTicker(p.interval)
 	defer ticker.Stop()
+	defer p.scheduler.close()
 
-	log.Println("Task processor started")
+	slog.InfoContext(ctx, "Task processor started")
 
 	for {
 		select {
 		case <-ticker.C:
 			p.processPendingTasks(ctx)
 		case <-p.stopCh:
-			log.Println("Task processor stopped")
+			slog.InfoContext(ctx, "Task processor stopped")
 			return
 		case <-ctx.Done():
-			log.Println("Task processor stopped (context cancelled)")
+			slog.InfoContext(ctx, "Task processor stopped (context cancelled)")
 			return
 		}
 	}
 }
 
-// processPendingTasks finds and processes pending tasks
+// runQueue claims tasks from the queue until the processor is stopped, keeping up to one
+// task per worker waiting for dispatch so the scheduler can order them by priority. Tasks
+// waiting or being processed when it stops are not acknowledged and are redelivered after
+// the visibility timeout.
+func (p *TaskProcessor) runQueue(ctx context.Context) {
+	slog.InfoContext(ctx, "Task processor started", "workers", p.workers, "max_deliveries", p.maxDeliveries)
+	defer slog.InfoContext(ctx, "Task processor stopped")
+	defer p.scheduler.close()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if p.scheduler.waitingCount() >= p.workers {
+			select {
+			case <-p.scheduler.changes():
+			case <-p.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		delivery, err := p.queue.Claim(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error claiming task", "error", err)
+			select {
+			case <-time.After(p.interval):
+			case <-p.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if delivery != nil {
+			p.scheduleDelivery(ctx, delivery)
+		}
+	}
+}
+
+// scheduleDelivery hands a claimed task to the scheduler, keeping it invisible to other
+// workers while it waits and runs, and acknowledges it once the task is finished
+func (p *TaskProcessor) scheduleDelivery(ctx context.Context, delivery *tasks.Delivery) {
+	task, ok := p.admit(ctx, delivery)
+	if !ok {
+		return
+	}
+	if !p.dependenciesMet(ctx, task) {
+		// Queued again when the dependencies finish
+		p.ack(ctx, delivery)
+		return
+	}
+
+	stopExtending := p.extend(ctx, delivery)
+	run := func() {
+		if current, ok := p.reload(ctx, task.ID); ok {
+			p.processTask(ctx, current)
+		}
+		stopExtending()
+		p.ack(ctx, delivery)
+	}
+	if !p.scheduler.submit(task, run, stopExtending) {
+		// Already waiting or running from an earlier delivery, which acknowledges it
+		stopExtending()
+	}
+}
+
+// processDelivery processes a claimed task right away, keeping it invisible to other
+// workers while it runs, and acknowledges it once the task is finished
+func (p *TaskProcessor) processDelivery(ctx context.Context, delivery *tasks.Delivery) {
+	task, ok := p.admit(ctx, delivery)
+	if !ok {
+		return
+	}
+	if !p.dependenciesMet(ctx, task) {
+		p.ack(ctx, delivery)
+		return
+	}
+	stopExtending := p.extend(ctx, delivery)
+	p.processTask(ctx, task)
+	stopExtending()
+	p.ack(ctx, delivery)
+}
+
+// admit loads a claimed task and returns a copy to process. Deliveries of finished,
+// deleted and poison tasks are settled instead and false is returned.
+func (p *TaskProcessor) admit(ctx context.Context, delivery *tasks.Delivery) (*protocol.Task, bool) {
+	task, err := p.taskStore.Get(ctx, delivery.TaskID)
+	if err != nil && !errors.Is(err, tasks.ErrTaskNotFound) {
+		// Left unacknowledged, the task is redelivered after the visibility timeout
+		slog.ErrorContext(ctx, "Error loading queued task", "task_id", delivery.TaskID, "error", err)
+		return nil, false
+	}
+	// Deleted tasks, and tasks finished by an earlier delivery or cancelled, are done
+	if err != nil || task.State.IsTerminal() {
+		p.ack(ctx, delivery)
+		return nil, false
+	}
+	// A task waiting for input is queued again once it is answered
+	if task.State == protocol.TaskStateInputRequired {
+		p.ack(ctx, delivery)
+		return nil, false
+	}
+	// A task running under a live lease has a processor; this delivery is a duplicate,
+	// e.g. one redelivered after the visibility timeout while the task kept running
+	if task.State == protocol.TaskStateRunning && task.Lease != nil && !task.Lease.Expired(time.Now()) {
+		slog.InfoContext(ctx, "Skipping delivery of leased task", "task_id", task.ID, "owner", task.Lease.Owner)
+		p.ack(ctx, delivery)
+		return nil, false
+	}
+
+	if p.maxDeliveries > 0 && delivery.Attempt > p.maxDeliveries {
+		p.deadLetter(ctx, task, delivery)
+		return nil, false
+	}
+	if delivery.Attempt > 1 {
+		slog.WarnContext(ctx, "Redelivered task", "task_id", task.ID, "attempt", delivery.Attempt)
+	}
+
+	return task, true
+}
+
+// extend restarts the delivery's visibility timeout every third of it until the
+// returned function is first called
+func (p *TaskProcessor) extend(ctx context.Context, delivery *tasks.Delivery) func() {
+	var once sync.Once
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.queue.VisibilityTimeout() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.queue.Extend(ctx, delivery); err != nil {
+					slog.WarnContext(ctx, "Error extending task visibility", "task_id", delivery.TaskID, "error", err)
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
+
+// ack acknowledges a delivery, logging failures; the task is then redelivered and
+// skipped because it is finished
+func (p *TaskProcessor) ack(ctx context.Context, delivery *tasks.Delivery) {
+	if err := p.queue.Ack(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "Error acknowledging task", "task_id", delivery.TaskID, "error", err)
+	}
+}
+
+// deadLetter moves a poison task to the dead-letter queue and fails it so its clients
+// stop waiting
+func (p *TaskProcessor) deadLetter(ctx context.Context, task *protocol.Task, delivery *tasks.Delivery) {
+	reason := fmt.Sprintf("Task abandoned after %d delivery attempts", delivery.Attempt-1)
+	if err := p.queue.DeadLetter(ctx, delivery, reason); err != nil {
+		slog.ErrorContext(ctx, "Error dead-lettering task", "task_id", task.ID, "error", err)
+		return
+	}
+	slog.ErrorContext(ctx, "Task dead-lettered", "task_id", task.ID, "attempts", delivery.Attempt-1)
+	p.failTask(ctx, task, reason)
+}
+
+// failTask fails a task that cannot run so its clients stop waiting
+func (p *TaskProcessor) failTask(ctx context.Context, task *protocol.Task, reason string) {
+	task.SetError(reason)
+	if err := p.taskStore.Update(ctx, task); err != nil {
+		slog.ErrorContext(ctx, "Error updating task to failed", "task_id", task.ID, "error", err)
+		return
+	}
+	p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+		TaskID:   task.ID,
+		State:    protocol.TaskStateFailed,
+		Message:  reason,
+		WorkerID: p.owner,
+	})
+	p.releaseDependents(ctx, task.ID)
+}
+
+// releaseDependents hands the tasks waiting for a finished task back to the processors.
+// Without a queue they are found by the next poll.
+func (p *TaskProcessor) releaseDependents(ctx context.Context, taskID string) {
+	if p.queue != nil {
+		enqueueDependents(ctx, p.taskStore, p.queue, taskID)
+	}
+}
+
+// dependenciesMet reports whether a task's dependencies have all completed, adding their
+// results to its input. A task whose dependency failed, was cancelled or was deleted is
+// failed; one with unfinished dependencies is left pending.
+func (p *TaskProcessor) dependenciesMet(ctx context.Context, task *protocol.Task) bool {
+	results, err := dependencyResults(ctx, p.taskStore, task)
+	var failed *dependencyFailedError
+	switch {
+	case err == nil:
+		if results != nil {
+			task.Input = withDependencyResults(task.Input, results)
+		}
+		return true
+	case errors.As(err, &failed):
+		slog.WarnContext(ctx, "Task dependency did not complete", "task_id", task.ID, "dependency", failed.TaskID)
+		p.failTask(ctx, task, failed.Error())
+	case !errors.Is(err, errDependenciesPending):
+		slog.ErrorContext(ctx, "Error checking task dependencies", "task_id", task.ID, "error", err)
+	}
+	return false
+}
+
+// withinBudget reports whether the task's tenant and user budgets are not overspent, failing
+// the task if one is. The task's estimate was charged when it was created.
+func (p *TaskProcessor) withinBudget(ctx context.Context, task *protocol.Task) bool {
+	if p.budgets == nil {
+		return true
+	}
+	var exceeded *cost.BudgetExceededError
+	if err := p.budgets.Check(ctx, task.UserID, 0); errors.As(err, &exceeded) {
+		slog.WarnContext(ctx, "Task budget exhausted", "task_id", task.ID, "level", exceeded.Level, "budget_id", exceeded.ID)
+		p.failTask(ctx, task, exceeded.Error())
+		return false
+	}
+	return true
+}
+
+// processPendingTasks hands the pending tasks to the scheduler; tasks already waiting
+// for a slot or running are skipped
 func (p *TaskProcessor) processPendingTasks(ctx context.Context) {
-	// Get all tasks (in production, query only pending tasks)
-	allTasks, err := p.taskStore.List(ctx, "", 100, 0)
+	pending, err := p.taskStore.List(ctx, tasks.ListFilter{State: protocol.TaskStatePending}, 100, 0)
 	if err != nil {
-		log.Printf("Error listing tasks: %v", err)
+		slog.ErrorContext(ctx, "Error listing tasks", "error", err)
 		return
 	}
 
-	for _, task := range allTasks {
-		// Only process pending tasks
-		if task.State == protocol.TaskStatePending {
-			go p.processTask(ctx, task)
+	for _, task := range pending {
+		if !p.dependenciesMet(ctx, task) {
+			continue
+		}
+		p.scheduler.submit(task, func() {
+			if current, ok := p.reload(ctx, task.ID); ok {
+				p.processTask(ctx, current)
+			}
+		}, nil)
+	}
+}
+
+// reload returns a task dispatched after waiting for a slot, unless it was
+// cancelled or deleted while it waited
+func (p *TaskProcessor) reload(ctx context.Context, taskID string) (*protocol.Task, bool) {
+	task, err := p.taskStore.Get(ctx, taskID)
+	if err != nil {
+		if !errors.Is(err, tasks.ErrTaskNotFound) {
+			slog.ErrorContext(ctx, "Error loading scheduled task", "task_id", taskID, "error", err)
 		}
+		return nil, false
+	}
+	if task.State.IsTerminal() {
+		return nil, false
 	}
+	return task, true
 }
 
-// processTask simulates task execution
+// processTask executes a task and records its outcome. The execution joins the trace of
+// the request that created the task and runs with the creator's bearer token.
 func (p *TaskProcessor) processTask(ctx context.Context, task *protocol.Task) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(task.TraceContext))
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "task.process",
+		trace.WithAttributes(attribute.String("task.id", task.ID), attribute.String("task.capability", task.Capability)))
+	defer span.End()
+	ctx = capabilities.WithAuthToken(ctx, task.AuthToken)
+
+	// Dependencies were checked before the task was scheduled, but the task is reloaded
+	// when dispatched; this adds their results to its input
+	if !p.dependenciesMet(ctx, task) {
+		return
+	}
+	if !p.withinBudget(ctx, task) {
+		return
+	}
+
 	// Transition to running
 	task.UpdateState(protocol.TaskStateRunning)
+	p.acquireLease(task)
 	if err := p.taskStore.Update(ctx, task); err != nil {
-		log.Printf("Error updating task %s to running: %v", task.ID, err)
+		slog.ErrorContext(ctx, "Error updating task to running", "task_id", task.ID, "error", err)
 		return
 	}
 
 	// Publish running event
 	p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
-		TaskID:  task.ID,
-		State:   protocol.TaskStateRunning,
-		Message: "Task started",
+		TaskID:   task.ID,
+		State:    protocol.TaskStateRunning,
+		Message:  "Task started",
+		WorkerID: p.owner,
 	})
 
-	log.Printf("Task %s started (simulating execution)", task.ID[:8])
+	slog.InfoContext(ctx, "Task started", "task_id", task.ID, "capability", task.Capability)
 
-	// Simulate task execution (2-5 seconds)
-	executionTime := 2*time.Second + time.Duration(task.ID[0]%3)*time.Second
-	time.Sleep(executionTime)
+	execCtx, stopHeartbeat := p.heartbeat(ctx, task.ID)
+	result, decision, err := p.execute(execCtx, task)
+	stopHeartbeat()
+	if errors.Is(context.Cause(execCtx), errLeaseLost) {
+		slog.WarnContext(ctx, "Task lease lost, discarding its outcome", "task_id", task.ID)
+		return
+	}
+	if decision != nil {
+		task.Speculation = decision
+		slog.InfoContext(ctx, "Speculative execution finished",
+			"task_id", task.ID,
+			"launched", decision.Launched,
+			"winner", decision.Winner,
+			"cancelled", decision.Cancelled,
+			"estimated_cost_usd", decision.EstimatedCostUSD,
+			"latency_ms", decision.LatencyMs)
+	}
 
-	// Simulate 90% success, 10% failure
-	success := task.ID[0]%10 != 0
+	if prompt := popInputRequest(result); err == nil && prompt != "" {
+		p.requestInput(ctx, task, prompt, decision)
+		return
+	}
 
-	if success {
-		// Complete successfully
-		result := map[string]interface{}{
-			"status":     "success",
-			"capability": task.Capability,
-			"message":    "Task completed successfully",
-			"timestamp":  time.Now().Format(time.RFC3339),
-			"cost":       0.01, // $0.01 cost
+	var artifacts []protocol.Artifact
+	if err == nil {
+		artifacts, err = p.storeArtifacts(ctx, task, popArtifacts(result))
+	}
+
+	if err == nil {
+		for i := range artifacts {
+			p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+				TaskID:    task.ID,
+				State:     protocol.TaskStateRunning,
+				Artifact:  &artifacts[i],
+				LastChunk: true,
+			})
 		}
+		// Stream the result before the terminal event so it is the last artifact clients see
+		p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+			TaskID:    task.ID,
+			State:     protocol.TaskStateRunning,
+			Artifact:  &protocol.Artifact{ArtifactID: task.ID + "-result", Name: "result", Parts: []protocol.Part{{Kind: protocol.KindData, Data: result}}},
+			LastChunk: true,
+		})
 
+		// Complete successfully
+		task.Artifacts = artifacts
 		task.SetResult(result)
-		if err := p.taskStore.Update(ctx, task); err != nil {
-			log.Printf("Error updating task %s to completed: %v", task.ID, err)
+		if err := p.saveOutcome(ctx, task); err != nil {
+			slog.ErrorContext(ctx, "Error updating task to completed", "task_id", task.ID, "error", err)
 			return
 		}
 
 		p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
-			TaskID:  task.ID,
-			State:   protocol.TaskStateCompleted,
-			Message: "Task completed successfully",
+			TaskID:   task.ID,
+			State:    protocol.TaskStateCompleted,
+			Message:  "Task completed successfully",
+			Data:     speculationData(decision),
+			WorkerID: p.owner,
 		})
 
-		log.Printf("Task %s completed successfully", task.ID[:8])
+		slog.InfoContext(ctx, "Task completed successfully", "task_id", task.ID)
+		p.releaseDependents(ctx, task.ID)
 	} else {
 		// Fail with error
-		task.SetError("Simulated task failure")
-		if err := p.taskStore.Update(ctx, task); err != nil {
-			log.Printf("Error updating task %s to failed: %v", task.ID, err)
+		task.SetError(err.Error())
+		if err := p.saveOutcome(ctx, task); err != nil {
+			slog.ErrorContext(ctx, "Error updating task to failed", "task_id", task.ID, "error", err)
 			return
 		}
 
 		p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
-			TaskID:  task.ID,
-			State:   protocol.TaskStateFailed,
-			Message: "Task failed",
+			TaskID:   task.ID,
+			State:    protocol.TaskStateFailed,
+			Message:  "Task failed",
+			Data:     speculationData(decision),
+			WorkerID: p.owner,
+		})
+
+		slog.WarnContext(ctx, "Task failed", "task_id", task.ID)
+		p.releaseDependents(ctx, task.ID)
+	}
+}
+
+// execute runs the task's capability, racing its substitutes when the task is speculative
+// and a cheaper alternative fits under the cost cap
+func (p *TaskProcessor) execute(ctx context.Context, task *protocol.Task) (map[string]interface{}, *protocol.SpeculationDecision, error) {
+	// Partial output is only streamed from a single attempt; racing attempts would interleave
+	progress := func(step, steps int) { p.publishProgress(ctx, task, step, steps) }
+	if !task.Speculative || p.agentStore == nil {
+		result, err := p.attempt(ctx, task, task.Capability, progress)
+		return result, nil, err
+	}
+
+	card, err := p.agentStore.Get(ctx, task.AgentID)
+	if err != nil {
+		result, err := p.attempt(ctx, task, task.Capability, progress)
+		return result, nil, err
+	}
+
+	plan := speculative.Plan(card, task.Capability, p.costCapUSD)
+	if len(plan) < 2 {
+		result, err := p.attempt(ctx, task, task.Capability, progress)
+		return result, nil, err
+	}
+
+	return speculative.Run(ctx, plan, p.costCapUSD,
+		func(ctx context.Context, capability string) (map[string]interface{}, error) {
+			return p.attempt(ctx, task, capability, nil)
+		},
+		func(result map[string]interface{}) bool {
+			// A question is an outcome too: the task waits for its answer
+			return result["status"] == "success" || result["status"] == "input_required"
 		})
+}
+
+// attempt runs one capability for the task with its registered executor, falling back to
+// simulating capabilities that have none. The requested capability runs at the task's
+// version, substitutes at their default version.
+func (p *TaskProcessor) attempt(ctx context.Context, task *protocol.Task, capability string, progress func(step, steps int)) (map[string]interface{}, error) {
+	start := time.Now()
+	var result *capabilities.Result
+	var err error
+	switch {
+	case p.executors != nil && p.executors.Has(capability):
+		var version string
+		if capability == task.Capability {
+			version = task.CapabilityVersion
+		}
+		result, err = p.executors.ExecuteVersion(ctx, capability, version, task.Input)
+	case p.delegator != nil && p.delegator.Offers(ctx, capability):
+		result, err = p.delegator.Delegate(ctx, capability, task.Input)
+	default:
+		return p.simulate(ctx, task, capability, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(1, 1)
+	}
+
+	usage := result.Usage(task.UserID, task.ID)
+	usage.Capability = capability
+	if p.costTracker != nil {
+		if err := p.costTracker.RecordUsage(ctx, usage); err != nil {
+			slog.WarnContext(ctx, "Error recording task usage", "task_id", task.ID, "error", err)
+		}
+	}
+	slog.InfoContext(ctx, "Capability executed",
+		"task_id", task.ID,
+		"capability", capability,
+		"model", usage.Model,
+		"total_tokens", usage.TotalTokens,
+		"cost_usd", usage.CostUSD,
+		"latency_ms", time.Since(start).Milliseconds())
+
+	// The cost is incurred and recorded either way, but a task over its cap does not get the result
+	if task.MaxCostUSD > 0 && usage.CostUSD > task.MaxCostUSD {
+		return nil, &cost.BudgetExceededError{Level: cost.LevelTask, ID: task.ID, LimitUSD: task.MaxCostUSD, CostUSD: usage.CostUSD}
+	}
+
+	if result.InputRequired != "" {
+		return map[string]interface{}{
+			"status":        "input_required",
+			"capability":    capability,
+			inputRequestKey: result.InputRequired,
+		}, nil
+	}
+
+	output := map[string]interface{}{
+		"status":     "success",
+		"capability": capability,
+		"output":     result.Output,
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"cost":       usage.CostUSD,
+		"usage": map[string]interface{}{
+			"model":             usage.Model,
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+			"total_tokens":      usage.TotalTokens,
+		},
+	}
+	if len(result.Artifacts) > 0 {
+		output[artifactsKey] = result.Artifacts
+	}
+	return output, nil
+}
+
+// simulate fakes execution of one capability (2-4 one-second steps, 90% success). The
+// requested capability is keyed on the task ID as before; substitutes hash the capability
+// name in so each alternative gets its own latency and outcome. progress, when set, is
+// called after each step.
+func (p *TaskProcessor) simulate(ctx context.Context, task *protocol.Task, capability string, progress func(step, steps int)) (map[string]interface{}, error) {
+	seed := uint32(task.ID[0])
+	if capability != task.Capability {
+		h := fnv.New32a()
+		h.Write([]byte(task.ID + capability))
+		seed = h.Sum32()
+	}
+
+	steps := 2 + int(seed%3)
+	for step := 1; step <= steps; step++ {
+		select {
+		case <-time.After(simulatedStep):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if progress != nil {
+			progress(step, steps)
+		}
+	}
+
+	if seed%10 == 0 {
+		return nil, errSimulatedFailure
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"capability": capability,
+		"message":    "Task completed successfully",
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"cost":       0.01, // $0.01 cost
+	}, nil
+}
+
+// publishProgress streams a step as a chunk of the task's progress artifact
+func (p *TaskProcessor) publishProgress(ctx context.Context, task *protocol.Task, step, steps int) {
+	p.taskStore.PublishEvent(ctx, protocol.TaskEvent{
+		TaskID: task.ID,
+		State:  protocol.TaskStateRunning,
+		Artifact: &protocol.Artifact{
+			ArtifactID: task.ID + "-progress",
+			Name:       "progress",
+			Parts:      []protocol.Part{{Kind: protocol.KindText, Text: fmt.Sprintf("Step %d of %d complete\n", step, steps)}},
+		},
+		Append:    step > 1,
+		LastChunk: step == steps,
+	})
+}
 
-		log.Printf("Task %s failed", task.ID[:8])
+// speculationData exposes a speculation decision on the task event
+func speculationData(decision *protocol.SpeculationDecision) map[string]interface{} {
+	if decision == nil {
+		return nil
 	}
+	return map[string]interface{}{"speculation": decision}
 }
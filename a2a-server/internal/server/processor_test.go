@@ -0,0 +1,277 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// recordingQueue records the tasks queued and the acknowledgements and dead letters of
+// deliveries handed to it
+type recordingQueue struct {
+	tasks.Queue
+	mu       sync.Mutex
+	enqueued []string
+	acked    []string
+	dead     []string
+	reason   string
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.enqueued = append(q.enqueued, taskID)
+	return nil
+}
+
+func (q *recordingQueue) Ack(ctx context.Context, delivery *tasks.Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.acked = append(q.acked, delivery.TaskID)
+	return nil
+}
+
+func (q *recordingQueue) DeadLetter(ctx context.Context, delivery *tasks.Delivery, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dead = append(q.dead, delivery.TaskID)
+	q.reason = reason
+	return nil
+}
+
+func (q *recordingQueue) Extend(ctx context.Context, delivery *tasks.Delivery) error {
+	return nil
+}
+
+func (q *recordingQueue) VisibilityTimeout() time.Duration {
+	return time.Second
+}
+
+func TestTaskProcessor_QueueSharedByReplicas(t *testing.T) {
+	step := simulatedStep
+	simulatedStep = time.Millisecond
+	defer func() { simulatedStep = step }()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+
+	// Two replicas sharing one store and one stream
+	for _, consumer := range []string{"replica-1", "replica-2"} {
+		queue, err := tasks.NewRedisQueue(ctx, client, tasks.RedisQueueConfig{Consumer: consumer, Block: 10 * time.Millisecond})
+		require.NoError(t, err)
+		processor := NewTaskProcessor(store, time.Hour)
+		processor.SetQueue(queue, 2, 3)
+		processor.Start(ctx)
+		defer processor.Stop()
+	}
+
+	server := setupTestServer()
+	server.taskStore = store
+	queue, err := tasks.NewRedisQueue(ctx, client, tasks.RedisQueueConfig{Consumer: "api"})
+	require.NoError(t, err)
+	server.SetQueue(queue)
+	server.agentStore.Register(ctx, protocol.NewAgentCard("agent-1", "Agent", "1.0.0", "test"))
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10))
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		task, err := server.createTask(ctx, CreateTaskRequest{UserID: "user-1", AgentID: "agent-1", Capability: "search"}, "")
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+
+	// Watch the events; the memory store hands out the tasks the processors update
+	require.Eventually(t, func() bool {
+		for _, id := range ids {
+			events := store.Events(ctx, id, 0)
+			if len(events) == 0 || !events[len(events)-1].Final() {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return client.XLen(ctx, "a2a:tasks").Val() == 0
+	}, time.Second, 10*time.Millisecond, "processed tasks are acknowledged")
+}
+
+func TestTaskProcessor_DeadLettersPoisonTasks(t *testing.T) {
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	queue := &recordingQueue{}
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetQueue(queue, 1, 3)
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "1-0", TaskID: task.ID, Attempt: 4})
+
+	assert.Equal(t, []string{task.ID}, queue.dead)
+	assert.Contains(t, queue.reason, "3 delivery attempts")
+	failed, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, failed.State)
+	events := store.Events(ctx, task.ID, 0)
+	require.NotEmpty(t, events)
+	assert.True(t, events[len(events)-1].Final())
+
+	// Finished and deleted tasks are acknowledged without running again
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "2-0", TaskID: task.ID, Attempt: 1})
+	processor.processDelivery(ctx, &tasks.Delivery{ID: "3-0", TaskID: "missing", Attempt: 1})
+	assert.Equal(t, []string{task.ID, "missing"}, queue.acked)
+}
+
+func TestTaskProcessor_RunsRegisteredExecutors(t *testing.T) {
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	tracker := cost.NewMemoryTracker()
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{
+		Name:        "summarize_document",
+		InputSchema: map[string]interface{}{"required": []string{"document"}},
+	}, capabilities.ExecutorFunc(capabilities.Summarize))
+
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetExecutors(executors, tracker)
+
+	task := protocol.NewTask("agent-1", "summarize_document", map[string]interface{}{"document": "Embeddings capture meaning. Search uses embeddings."})
+	task.UserID = "user-1"
+	require.NoError(t, store.Create(ctx, task))
+	processor.processTask(ctx, task)
+
+	require.Equal(t, protocol.TaskStateCompleted, task.State, task.Error)
+	output := task.Result["output"].(map[string]interface{})
+	assert.Equal(t, "Embeddings capture meaning. Search uses embeddings.", output["summary"])
+	usage, err := tracker.GetUsage(ctx, "user-1", time.Now().Add(-time.Minute), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, task.ID, usage[0].TaskID)
+	assert.Equal(t, "summarize_document", usage[0].Capability)
+	assert.Greater(t, usage[0].CostUSD, 0.0)
+	assert.Equal(t, usage[0].CostUSD, task.Result["cost"])
+
+	// Input that does not match the schema fails the task without running it
+	invalid := protocol.NewTask("agent-1", "summarize_document", map[string]interface{}{})
+	require.NoError(t, store.Create(ctx, invalid))
+	processor.processTask(ctx, invalid)
+	assert.Equal(t, protocol.TaskStateFailed, invalid.State)
+	assert.Contains(t, invalid.Error, "document is required")
+}
+
+func TestTaskProcessor_EnforcesBudgets(t *testing.T) {
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	budgets := cost.NewBudgetManager()
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{Name: "summarize_document"}, capabilities.ExecutorFunc(capabilities.Summarize))
+
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetExecutors(executors, cost.NewMemoryTracker())
+	processor.SetBudgets(budgets)
+	require.NoError(t, budgets.SetTenantBudget(ctx, "acme", 1))
+	require.NoError(t, budgets.SetUserBudget(ctx, "acme", "user-1", 1))
+
+	newTask := func(maxCostUSD float64) *protocol.Task {
+		task := protocol.NewTask("agent-1", "summarize_document", map[string]interface{}{"document": "Embeddings capture meaning."})
+		task.UserID = "user-1"
+		task.MaxCostUSD = maxCostUSD
+		require.NoError(t, store.Create(ctx, task))
+		return task
+	}
+
+	// A task whose actual cost is over its cap fails
+	capped := newTask(1e-9)
+	processor.processTask(ctx, capped)
+	assert.Equal(t, protocol.TaskStateFailed, capped.State)
+	assert.Contains(t, capped.Error, "task cost cap exceeded")
+
+	// A task waiting while its tenant's budget is used up fails before it runs
+	require.NoError(t, budgets.ChargeTask(ctx, "user-1", "other", 0.5, 0))
+	require.NoError(t, budgets.SetTenantBudget(ctx, "acme", 0.1))
+	waiting := newTask(0)
+	processor.processTask(ctx, waiting)
+	assert.Equal(t, protocol.TaskStateFailed, waiting.State)
+	assert.Contains(t, waiting.Error, "tenant budget exceeded")
+	assert.Nil(t, waiting.Result)
+}
+
+func TestTaskProcessor_BridgesToMCPWithCallerContext(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	provider := otel.GetTracerProvider()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	defer func() {
+		otel.SetTextMapPropagator(propagator)
+		otel.SetTracerProvider(provider)
+	}()
+
+	// The MCP server records the credentials and trace of the tool call
+	var authorization, traceparent string
+	mcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := map[string]interface{}{"protocolVersion": capabilities.MCPProtocolVersion}
+		if req.Method == "tools/call" {
+			authorization, traceparent = r.Header.Get("Authorization"), r.Header.Get("traceparent")
+			result = map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": `[{"doc_id":"doc-1","title":"RAG"}]`}}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer mcp.Close()
+
+	ctx := context.Background()
+	server := setupTestServer()
+	server.agentStore.Register(ctx, protocol.NewAgentCard("agent-1", "Agent", "1.0.0", "test"))
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10))
+
+	const callerTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	body, _ := json.Marshal(CreateTaskRequest{UserID: "user-1", AgentID: "agent-1", Capability: "search_papers", Input: map[string]interface{}{"query": "rag"}})
+	req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer caller-jwt")
+	req.Header.Set("traceparent", "00-"+callerTrace+"-00f067aa0ba902b7-01")
+	// The tracing middleware makes the incoming trace context current
+	req = req.WithContext(propagation.TraceContext{}.Extract(req.Context(), propagation.HeaderCarrier(req.Header)))
+	rr := httptest.NewRecorder()
+	server.handleCreateTask(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var created protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.NotEmpty(t, created.TraceContext["traceparent"])
+	task, err := server.taskStore.Get(ctx, created.ID)
+	require.NoError(t, err)
+
+	executors := capabilities.NewRegistry()
+	bridge := capabilities.NewMCPBridge(capabilities.MCPBridgeConfig{URL: mcp.URL, Token: "service-token"})
+	executors.Register(protocol.Capability{Name: "search_papers"}, bridge.SearchPapers("hybrid_search"))
+	processor := NewTaskProcessor(server.taskStore, time.Hour)
+	processor.SetExecutors(executors, nil)
+	processor.processTask(ctx, task)
+
+	require.Equal(t, protocol.TaskStateCompleted, task.State, task.Error)
+	assert.Equal(t, "Bearer caller-jwt", authorization)
+	assert.Contains(t, traceparent, callerTrace)
+}
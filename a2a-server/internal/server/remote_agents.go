@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/a2aclient"
+)
+
+// AdminRemoteAgentsPath is the endpoint reporting the remote agents tasks are delegated to
+const AdminRemoteAgentsPath = "/admin/remote-agents"
+
+// SetDelegator accepts tasks for capabilities the agent lacks when a remote agent offers
+// them; the task processor delegates them
+func (s *Server) SetDelegator(delegator *a2aclient.Delegator) {
+	s.delegator = delegator
+}
+
+// handleRemoteAgents handles GET /admin/remote-agents, which reports each remote agent's
+// circuit, budget and last known capabilities
+func (s *Server) handleRemoteAgents(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses := []a2aclient.RemoteStatus{}
+	if s.delegator != nil {
+		statuses = s.delegator.Status(r.Context())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/a2aclient"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRemoteAgent runs an agent offering translate, which it executes for user
+// "delegator", and returns a delegator that delegates to it
+func startRemoteAgent(t *testing.T) *a2aclient.Delegator {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	remote := setupTestServer()
+	translate := protocol.Capability{Name: "translate", EstimatedCostUSD: 0.02}
+	card := protocol.NewAgentCard("translator", "Translator", "1.0.0", "Translates text")
+	card.AddCapability(translate)
+	require.NoError(t, remote.agentStore.Register(ctx, card))
+	require.NoError(t, remote.budgetManager.SetBudget(ctx, "delegator", 10.0))
+
+	executors := capabilities.NewRegistry()
+	executors.Register(translate, capabilities.ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+		return &capabilities.Result{Output: map[string]interface{}{"translation": "hello"}, CostUSD: 0.015}, nil
+	}))
+	processor := NewTaskProcessor(remote.taskStore, 5*time.Millisecond)
+	processor.SetExecutors(executors, nil)
+	processor.Start(ctx)
+	t.Cleanup(processor.Stop)
+
+	mux := http.NewServeMux()
+	remote.RegisterRoutes(mux)
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	delegator := a2aclient.NewDelegator(a2aclient.DelegatorConfig{FailureThreshold: 3, Cooldown: time.Minute})
+	client := a2aclient.New(a2aclient.Config{URL: httpServer.URL, UserID: "delegator", CardTTL: time.Minute, PollInterval: 5 * time.Millisecond})
+	require.NoError(t, delegator.Add(ctx, "translator", client, 1.0))
+	return delegator
+}
+
+func TestTaskProcessor_DelegatesToRemoteAgent(t *testing.T) {
+	ctx := context.Background()
+	store := setupTestServer().taskStore
+	processor := NewTaskProcessor(store, time.Hour)
+	processor.SetDelegator(startRemoteAgent(t))
+
+	task := protocol.NewTask("agent-1", "translate", map[string]interface{}{"text": "hallo"})
+	task.UserID = "user-1"
+	require.NoError(t, store.Create(ctx, task))
+	processor.processTask(ctx, task)
+
+	require.Equal(t, protocol.TaskStateCompleted, task.State, task.Error)
+	assert.Equal(t, 0.015, task.Result["cost"])
+	output := task.Result["output"].(map[string]interface{})
+	assert.Equal(t, "translator", output["remote_agent"])
+	remoteResult := output["result"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"translation": "hello"}, remoteResult["output"])
+}
+
+func TestJSONRPC_AcceptsRemoteCapabilities(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	body := strings.Replace(messageSend, `"capability":"search"`, `"capability":"translate"`, 1)
+
+	resp := callJSONRPC(t, server, body)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+
+	server.SetDelegator(startRemoteAgent(t))
+	resp = callJSONRPC(t, server, body)
+	require.Nil(t, resp.Error)
+	var task protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	assert.Equal(t, "translate", task.Metadata["capability"])
+}
+
+func TestServer_RemoteAgents(t *testing.T) {
+	server := setupTestServer()
+	server.SetAdminToken("secret")
+	serve := func() []a2aclient.RemoteStatus {
+		req := httptest.NewRequest(http.MethodGet, AdminRemoteAgentsPath, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		server.handleRemoteAgents(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var statuses []a2aclient.RemoteStatus
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&statuses))
+		return statuses
+	}
+	assert.Empty(t, serve())
+
+	delegator := startRemoteAgent(t)
+	require.True(t, delegator.Offers(context.Background(), "translate"))
+	server.SetDelegator(delegator)
+	statuses := serve()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "translator", statuses[0].Name)
+	assert.Equal(t, a2aclient.CircuitClosed, statuses[0].Circuit)
+	assert.Equal(t, []string{"translate"}, statuses[0].Capabilities)
+	assert.Equal(t, 1.0, statuses[0].BudgetUSD)
+
+	req := httptest.NewRequest(http.MethodGet, AdminRemoteAgentsPath, nil)
+	rr := httptest.NewRecorder()
+	server.handleRemoteAgents(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
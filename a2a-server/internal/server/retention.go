@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+// DefaultTaskGCInterval is how often finished tasks past their retention are collected
+const DefaultTaskGCInterval = time.Minute
+
+// gcBatchSize is how many expired tasks are collected per store query
+const gcBatchSize = 100
+
+// SetRetention garbage collects finished tasks once retention elapsed after they
+// finished, or their own TTL when they have one; zero retention keeps tasks without a
+// TTL. With an archive each task is archived before it is deleted.
+func (s *Server) SetRetention(retention time.Duration, archive tasks.Archive) {
+	s.retention = retention
+	s.archive = archive
+}
+
+// RunTaskGC collects the finished tasks past their retention every interval until ctx is
+// cancelled
+func (s *Server) RunTaskGC(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if n := s.collectTasks(ctx, time.Now()); n > 0 {
+				slog.InfoContext(ctx, "Expired tasks collected", "tasks", n, "archived", s.archive != nil)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectTasks archives and deletes the finished tasks whose retention elapsed at now and
+// returns how many it collected. Tasks that unfinished tasks depend on are kept until
+// those finish, so their results can still be passed on.
+func (s *Server) collectTasks(ctx context.Context, now time.Time) int {
+	collected, kept := 0, 0
+	for {
+		expired, err := s.taskStore.Expired(ctx, now, s.retention, gcBatchSize, kept)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error listing expired tasks", "error", err)
+			return collected
+		}
+		for _, task := range expired {
+			ok, err := s.collectTask(ctx, task)
+			if err != nil {
+				slog.ErrorContext(ctx, "Error collecting expired task", "task_id", task.ID, "error", err)
+			}
+			if !ok {
+				// Collected tasks are no longer listed, so skip past the others
+				kept++
+				continue
+			}
+			collected++
+		}
+		if len(expired) < gcBatchSize {
+			return collected
+		}
+	}
+}
+
+// collectTask archives and deletes an expired task along with its artifact blobs,
+// reporting whether it was collected
+func (s *Server) collectTask(ctx context.Context, task *protocol.Task) (bool, error) {
+	for offset := 0; ; offset += gcBatchSize {
+		dependents, err := s.taskStore.List(ctx, tasks.ListFilter{DependsOn: task.ID}, gcBatchSize, offset)
+		if err != nil {
+			return false, fmt.Errorf("failed to list dependents: %w", err)
+		}
+		for _, dependent := range dependents {
+			if !dependent.State.IsTerminal() {
+				return false, nil
+			}
+		}
+		if len(dependents) < gcBatchSize {
+			break
+		}
+	}
+
+	if s.archive != nil {
+		if err := s.archive.Archive(ctx, task); err != nil {
+			return false, err
+		}
+	}
+	// Journal the collection first, so recovering the journal keeps the task's charge
+	// rather than refund it as a task lost before it ran
+	if s.usageJournal != nil {
+		entry := cost.JournalEntry{Type: cost.EntryCollected, UserID: task.UserID, TaskID: task.ID}
+		if err := s.usageJournal.Append(ctx, entry); err != nil {
+			return false, err
+		}
+	}
+	if err := s.taskStore.Delete(ctx, task.ID); err != nil && !errors.Is(err, tasks.ErrTaskNotFound) {
+		return false, err
+	}
+	s.deleteArtifactBlobs(ctx, task)
+
+	slog.DebugContext(ctx, "Expired task collected", "task_id", task.ID, "state", task.State,
+		"finished_at", task.FinishedAt())
+	if s.telemetry != nil && s.telemetry.Metrics != nil {
+		s.telemetry.Metrics.RecordTaskReclaimed(ctx, string(task.State), s.archive != nil)
+	}
+	return true, nil
+}
+
+// deleteArtifactBlobs deletes the file parts of a task's artifacts kept in the blob store
+func (s *Server) deleteArtifactBlobs(ctx context.Context, task *protocol.Task) {
+	if s.blobs == nil {
+		return
+	}
+	for _, artifact := range task.Artifacts {
+		for i, part := range artifact.Parts {
+			// Parts moved to the blob store are downloaded from the server
+			if part.File == nil || part.File.URI != artifactPartURI(task.ID, artifact.ArtifactID, i) {
+				continue
+			}
+			key := artifactBlobKey(task.ID, artifact.ArtifactID, i)
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				slog.WarnContext(ctx, "Error deleting artifact blob", "task_id", task.ID, "key", key, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_CollectTasks(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+	dir := t.TempDir()
+	archive, err := tasks.OpenFileArchive(filepath.Join(dir, "tasks.jsonl"))
+	require.NoError(t, err)
+	defer archive.Close()
+	journal, err := cost.OpenFileJournal(filepath.Join(dir, "usage.journal"))
+	require.NoError(t, err)
+	defer journal.Close()
+	store, err := blobs.NewFileStore(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+	server.SetRetention(time.Hour, archive)
+	server.SetUsageJournal(journal)
+	server.SetArtifactStore(store)
+
+	now := time.Now()
+	finished := func(age time.Duration, ttlSeconds int) *protocol.Task {
+		task := protocol.NewTask("test-agent", "search", nil)
+		task.UserID = "user-1"
+		task.SetResult(map[string]interface{}{"answer": 42})
+		task.CompletedAt = now.Add(-age)
+		task.TTLSeconds = ttlSeconds
+		require.NoError(t, server.taskStore.Create(ctx, task))
+		return task
+	}
+	expired := finished(2*time.Hour, 0)
+	recent := finished(time.Minute, 0)
+	shortTTL := finished(time.Minute, 30)
+	withDependent := finished(2*time.Hour, 0)
+	dependent := protocol.NewTask("test-agent", "search", nil)
+	dependent.DependsOn = []string{withDependent.ID}
+	require.NoError(t, server.taskStore.Create(ctx, dependent))
+
+	// The expired task's large file part is in the blob store
+	key := artifactBlobKey(expired.ID, "report", 0)
+	require.NoError(t, store.Put(ctx, key, bytes.NewReader([]byte("report")), 6))
+	expired.Artifacts = []protocol.Artifact{{ArtifactID: "report", Parts: []protocol.Part{{Kind: protocol.KindFile,
+		File: &protocol.FileContent{Name: "report.pdf", URI: artifactPartURI(expired.ID, "report", 0)}}}}}
+	require.NoError(t, server.taskStore.Update(ctx, expired))
+
+	assert.Equal(t, 2, server.collectTasks(ctx, now))
+	for _, task := range []*protocol.Task{expired, shortTTL} {
+		_, err := server.taskStore.Get(ctx, task.ID)
+		assert.ErrorIs(t, err, tasks.ErrTaskNotFound)
+	}
+	for _, task := range []*protocol.Task{recent, withDependent, dependent} {
+		_, err := server.taskStore.Get(ctx, task.ID)
+		assert.NoError(t, err)
+	}
+	_, err = store.Get(ctx, key)
+	assert.Error(t, err, "the artifact blob is deleted with its task")
+
+	// Collected tasks are archived as JSON lines, and their collection journaled
+	file, err := os.Open(filepath.Join(dir, "tasks.jsonl"))
+	require.NoError(t, err)
+	defer file.Close()
+	var archived []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &task))
+		archived = append(archived, task.ID)
+	}
+	assert.ElementsMatch(t, []string{expired.ID, shortTTL.ID}, archived)
+	var collected []string
+	require.NoError(t, journal.Replay(ctx, func(entry cost.JournalEntry) error {
+		if entry.Type == cost.EntryCollected {
+			collected = append(collected, entry.TaskID)
+		}
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{expired.ID, shortTTL.ID}, collected)
+
+	// Once its dependent finished the task is collected too
+	dependent.SetError("dependency failed")
+	require.NoError(t, server.taskStore.Update(ctx, dependent))
+	assert.Equal(t, 1, server.collectTasks(ctx, now))
+	_, err = server.taskStore.Get(ctx, withDependent.ID)
+	assert.ErrorIs(t, err, tasks.ErrTaskNotFound)
+}
+
+func TestServer_CollectTasks_WithoutRetention(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	task := protocol.NewTask("test-agent", "search", nil)
+	task.SetResult(nil)
+	require.NoError(t, server.taskStore.Create(ctx, task))
+
+	// Without a retention only tasks with a TTL are collected, and deleted unarchived
+	assert.Zero(t, server.collectTasks(ctx, time.Now().AddDate(1, 0, 0)))
+	task.TTLSeconds = 60
+	require.NoError(t, server.taskStore.Update(ctx, task))
+	assert.Equal(t, 1, server.collectTasks(ctx, time.Now().Add(time.Minute)))
+}
+
+func TestServer_CreateTask_TTL(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(ctx, card)
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+
+	create := func(ttlSeconds int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"user_id":     "user-1",
+			"agent_id":    "test-agent",
+			"capability":  "search",
+			"ttl_seconds": ttlSeconds,
+		})
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(body)))
+		return rr
+	}
+
+	rr := create(3600)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var task protocol.Task
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+	assert.Equal(t, 3600, task.TTLSeconds)
+
+	rr = create(-1)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// SchedulingPolicy controls the order pending tasks are dispatched in and how many run at
+// once. Tasks are dispatched by effective priority, oldest first within a priority.
+type SchedulingPolicy struct {
+	// MaxConcurrent bounds the tasks running at once; zero means no limit. With a task
+	// queue the number of queue workers also bounds it.
+	MaxConcurrent int
+	// Concurrency bounds the running tasks of each priority; priorities without a positive
+	// limit are only bound by MaxConcurrent
+	Concurrency map[protocol.TaskPriority]int
+	// CapabilityConcurrency bounds the running tasks of each capability, e.g. to protect a
+	// rate-limited model or service; capabilities without a positive limit are only bound
+	// by the other limits. With a task queue, tasks held back by their capability's limit
+	// take up queue workers while they wait.
+	CapabilityConcurrency map[string]int
+	// AgingInterval raises the effective priority of a waiting task one level for every
+	// interval it has waited since it was created, so a steady stream of high priority
+	// work cannot starve low priority tasks; zero disables aging
+	AgingInterval time.Duration
+}
+
+// scheduledTask is a task waiting for or holding an execution slot
+type scheduledTask struct {
+	task *protocol.Task
+	// run executes the task once it is dispatched
+	run func()
+	// release, when set, is called for a task dropped without being dispatched
+	release func()
+}
+
+// scheduler dispatches submitted tasks as the policy's concurrency limits allow
+type scheduler struct {
+	policy  SchedulingPolicy
+	metrics *observability.Metrics
+	now     func() time.Time
+
+	mu      sync.Mutex
+	waiting []*scheduledTask
+	known   map[string]bool // IDs of waiting and running tasks
+	running map[protocol.TaskPriority]int
+	total   int
+	closed  bool
+	// changed is signalled whenever a task is dispatched or finishes
+	changed chan struct{}
+	// runningCapabilities counts the running tasks of each capability
+	runningCapabilities map[string]int
+
+	wg sync.WaitGroup
+}
+
+// newScheduler creates a scheduler; metrics may be nil
+func newScheduler(policy SchedulingPolicy, metrics *observability.Metrics) *scheduler {
+	return &scheduler{
+		policy:  policy,
+		metrics: metrics,
+		now:     time.Now,
+		known:   make(map[string]bool),
+		running: make(map[protocol.TaskPriority]int),
+		changed: make(chan struct{}, 1),
+
+		runningCapabilities: make(map[string]int),
+	}
+}
+
+// submit queues a task for dispatch. It returns false, without calling release, if the
+// task is already waiting or running, or the scheduler is closed.
+func (s *scheduler) submit(task *protocol.Task, run, release func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.known[task.ID] {
+		return false
+	}
+	if !task.Priority.Valid() {
+		task.Priority = protocol.PriorityNormal
+	}
+	s.known[task.ID] = true
+	s.waiting = append(s.waiting, &scheduledTask{task: task, run: run, release: release})
+	s.recordDepth(task.Priority, 1)
+	s.dispatchLocked()
+	return true
+}
+
+// waitingCount returns the number of tasks waiting for a slot
+func (s *scheduler) waitingCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiting)
+}
+
+// effectiveRank is the task's priority rank raised by aging
+func (s *scheduler) effectiveRank(task *protocol.Task, now time.Time) int {
+	rank := task.Priority.Rank()
+	if s.policy.AgingInterval > 0 {
+		rank -= int(now.Sub(task.CreatedAt) / s.policy.AgingInterval)
+	}
+	return max(rank, 0)
+}
+
+// dispatchLocked starts every waiting task that fits the concurrency limits, in order of
+// effective priority. A priority or capability at its limit does not hold back the others.
+func (s *scheduler) dispatchLocked() {
+	if s.closed || len(s.waiting) == 0 {
+		return
+	}
+
+	now := s.now()
+	sort.SliceStable(s.waiting, func(i, j int) bool {
+		a, b := s.waiting[i].task, s.waiting[j].task
+		if ra, rb := s.effectiveRank(a, now), s.effectiveRank(b, now); ra != rb {
+			return ra < rb
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	remaining := s.waiting[:0]
+	for _, item := range s.waiting {
+		if !s.canRunLocked(item.task) {
+			remaining = append(remaining, item)
+			continue
+		}
+		s.startLocked(item)
+	}
+	clear(s.waiting[len(remaining):])
+	s.waiting = remaining
+}
+
+// canRunLocked reports whether the task fits the concurrency limits
+func (s *scheduler) canRunLocked(task *protocol.Task) bool {
+	if s.policy.MaxConcurrent > 0 && s.total >= s.policy.MaxConcurrent {
+		return false
+	}
+	if limit := s.policy.CapabilityConcurrency[task.Capability]; limit > 0 && s.runningCapabilities[task.Capability] >= limit {
+		return false
+	}
+	limit := s.policy.Concurrency[task.Priority]
+	return limit <= 0 || s.running[task.Priority] < limit
+}
+
+// startLocked runs a dispatched task in its own goroutine
+func (s *scheduler) startLocked(item *scheduledTask) {
+	s.total++
+	s.running[item.task.Priority]++
+	s.runningCapabilities[item.task.Capability]++
+	s.recordDepth(item.task.Priority, -1)
+	s.recordWait(item.task)
+	s.signal()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.finish(item)
+		item.run()
+	}()
+}
+
+// finish frees the task's slot and dispatches the tasks waiting for it
+func (s *scheduler) finish(item *scheduledTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total--
+	s.running[item.task.Priority]--
+	if s.runningCapabilities[item.task.Capability]--; s.runningCapabilities[item.task.Capability] == 0 {
+		delete(s.runningCapabilities, item.task.Capability)
+	}
+	delete(s.known, item.task.ID)
+	s.signal()
+	s.dispatchLocked()
+}
+
+// signal wakes a goroutine waiting in changes without blocking
+func (s *scheduler) signal() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// changes is signalled whenever a task is dispatched or finishes
+func (s *scheduler) changes() <-chan struct{} {
+	return s.changed
+}
+
+// close stops dispatching, releases the waiting tasks and waits for the running ones
+func (s *scheduler) close() {
+	s.mu.Lock()
+	s.closed = true
+	waiting := s.waiting
+	s.waiting = nil
+	for _, item := range waiting {
+		delete(s.known, item.task.ID)
+		s.recordDepth(item.task.Priority, -1)
+	}
+	s.mu.Unlock()
+
+	for _, item := range waiting {
+		if item.release != nil {
+			item.release()
+		}
+	}
+	s.wg.Wait()
+}
+
+// recordDepth updates the queue depth metric
+func (s *scheduler) recordDepth(priority protocol.TaskPriority, delta int64) {
+	if s.metrics != nil {
+		s.metrics.RecordTaskQueueDepth(context.Background(), string(priority), delta)
+	}
+}
+
+// recordWait records how long a dispatched task waited since it became pending
+func (s *scheduler) recordWait(task *protocol.Task) {
+	if s.metrics != nil {
+		since := task.UpdatedAt
+		if since.IsZero() {
+			since = task.CreatedAt
+		}
+		waited := s.now().Sub(since)
+		s.metrics.RecordTaskQueueWait(context.Background(), string(task.Priority), task.Capability, float64(waited.Milliseconds()))
+	}
+}
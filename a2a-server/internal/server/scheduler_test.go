@@ -0,0 +1,157 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedRuns records the order tasks start in and holds them until released
+type gatedRuns struct {
+	mu      sync.Mutex
+	started []string
+	release chan struct{}
+}
+
+func newGatedRuns() *gatedRuns {
+	return &gatedRuns{release: make(chan struct{})}
+}
+
+func (g *gatedRuns) run(id string) func() {
+	return func() {
+		g.mu.Lock()
+		g.started = append(g.started, id)
+		g.mu.Unlock()
+		<-g.release
+	}
+}
+
+// waitFor waits until n tasks have started
+func (g *gatedRuns) waitFor(t *testing.T, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return len(g.order()) == n }, time.Second, time.Millisecond)
+}
+
+func (g *gatedRuns) order() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.started...)
+}
+
+// scheduledTaskAt returns a task of the priority created at the time
+func scheduledTaskAt(id string, priority protocol.TaskPriority, createdAt time.Time) *protocol.Task {
+	return &protocol.Task{ID: id, Priority: priority, State: protocol.TaskStatePending, CreatedAt: createdAt}
+}
+
+func TestScheduler_DispatchesByPriority(t *testing.T) {
+	now := time.Now()
+	runs := newGatedRuns()
+	s := newScheduler(SchedulingPolicy{MaxConcurrent: 1}, nil)
+
+	// The first task takes the only slot; the others wait and are dispatched one at a time
+	require.True(t, s.submit(scheduledTaskAt("first", protocol.PriorityLow, now), runs.run("first"), nil))
+	runs.waitFor(t, 1)
+
+	s.submit(scheduledTaskAt("low", protocol.PriorityLow, now.Add(1*time.Second)), runs.run("low"), nil)
+	s.submit(scheduledTaskAt("normal-late", protocol.PriorityNormal, now.Add(3*time.Second)), runs.run("normal-late"), nil)
+	s.submit(scheduledTaskAt("normal-early", protocol.PriorityNormal, now.Add(2*time.Second)), runs.run("normal-early"), nil)
+	s.submit(scheduledTaskAt("high", protocol.PriorityHigh, now.Add(4*time.Second)), runs.run("high"), nil)
+	assert.False(t, s.submit(scheduledTaskAt("high", protocol.PriorityHigh, now), runs.run("high"), nil),
+		"tasks already waiting are not submitted twice")
+	assert.Equal(t, 4, s.waitingCount())
+
+	close(runs.release)
+	runs.waitFor(t, 5)
+	s.close()
+	assert.Equal(t, []string{"first", "high", "normal-early", "normal-late", "low"}, runs.order())
+}
+
+func TestScheduler_PerPriorityConcurrency(t *testing.T) {
+	now := time.Now()
+	runs := newGatedRuns()
+	s := newScheduler(SchedulingPolicy{Concurrency: map[protocol.TaskPriority]int{protocol.PriorityLow: 1}}, nil)
+
+	s.submit(scheduledTaskAt("low-1", protocol.PriorityLow, now), runs.run("low-1"), nil)
+	s.submit(scheduledTaskAt("low-2", protocol.PriorityLow, now.Add(time.Second)), runs.run("low-2"), nil)
+	s.submit(scheduledTaskAt("normal", protocol.PriorityNormal, now.Add(2*time.Second)), runs.run("normal"), nil)
+
+	// A priority at its limit does not hold back the others
+	runs.waitFor(t, 2)
+	assert.ElementsMatch(t, []string{"low-1", "normal"}, runs.order())
+	assert.Equal(t, 1, s.waitingCount())
+
+	close(runs.release)
+	runs.waitFor(t, 3)
+	s.close()
+	assert.Equal(t, "low-2", runs.order()[2])
+}
+
+func TestScheduler_PerCapabilityConcurrency(t *testing.T) {
+	now := time.Now()
+	runs := newGatedRuns()
+	s := newScheduler(SchedulingPolicy{CapabilityConcurrency: map[string]int{"summarize": 1}}, nil)
+
+	task := func(id, capability string, createdAt time.Time) *protocol.Task {
+		task := scheduledTaskAt(id, protocol.PriorityNormal, createdAt)
+		task.Capability = capability
+		return task
+	}
+	s.submit(task("summarize-1", "summarize", now), runs.run("summarize-1"), nil)
+	s.submit(task("summarize-2", "summarize", now.Add(time.Second)), runs.run("summarize-2"), nil)
+	s.submit(task("search", "search", now.Add(2*time.Second)), runs.run("search"), nil)
+
+	// A capability at its limit does not hold back the others
+	runs.waitFor(t, 2)
+	assert.ElementsMatch(t, []string{"summarize-1", "search"}, runs.order())
+	assert.Equal(t, 1, s.waitingCount())
+
+	close(runs.release)
+	runs.waitFor(t, 3)
+	s.close()
+	assert.Equal(t, "summarize-2", runs.order()[2])
+	assert.Empty(t, s.runningCapabilities)
+}
+
+func TestScheduler_AgingPreventsStarvation(t *testing.T) {
+	now := time.Now()
+	runs := newGatedRuns()
+	s := newScheduler(SchedulingPolicy{MaxConcurrent: 1, AgingInterval: time.Minute}, nil)
+	s.now = func() time.Time { return now }
+
+	require.True(t, s.submit(scheduledTaskAt("running", protocol.PriorityHigh, now), runs.run("running"), nil))
+	runs.waitFor(t, 1)
+
+	// A low priority task waiting two intervals ranks with fresh high priority work and,
+	// being older, goes first; one waiting a single interval only ranks as normal
+	s.submit(scheduledTaskAt("fresh-high", protocol.PriorityHigh, now), runs.run("fresh-high"), nil)
+	s.submit(scheduledTaskAt("aged-low", protocol.PriorityLow, now.Add(-2*time.Minute)), runs.run("aged-low"), nil)
+	s.submit(scheduledTaskAt("older-low", protocol.PriorityLow, now.Add(-90*time.Second)), runs.run("older-low"), nil)
+
+	close(runs.release)
+	runs.waitFor(t, 4)
+	s.close()
+	assert.Equal(t, []string{"running", "aged-low", "fresh-high", "older-low"}, runs.order())
+}
+
+func TestScheduler_CloseReleasesWaitingTasks(t *testing.T) {
+	runs := newGatedRuns()
+	s := newScheduler(SchedulingPolicy{MaxConcurrent: 1}, nil)
+
+	s.submit(scheduledTaskAt("running", protocol.PriorityNormal, time.Now()), runs.run("running"), nil)
+	released := false
+	s.submit(scheduledTaskAt("waiting", protocol.PriorityNormal, time.Now()), runs.run("waiting"), func() { released = true })
+	runs.waitFor(t, 1)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(runs.release)
+	}()
+	s.close()
+	assert.True(t, released)
+	assert.Equal(t, []string{"running"}, runs.order(), "waiting tasks are not dispatched after close")
+	assert.False(t, s.submit(scheduledTaskAt("late", protocol.PriorityNormal, time.Now()), runs.run("late"), nil))
+}
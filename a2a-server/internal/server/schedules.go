@@ -0,0 +1,396 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
+	"github.com/bhatti/mcp-a2a-go/shared/auth"
+)
+
+// SchedulesPath is the endpoint for creating and listing schedules; /schedules/{id}
+// reads, replaces or deletes one
+const SchedulesPath = "/schedules"
+
+// DefaultScheduleInterval is how often due schedules are checked for
+const DefaultScheduleInterval = 10 * time.Second
+
+// scheduleBatchSize is how many due schedules are materialized per store query
+const scheduleBatchSize = 100
+
+// Schedule run outcomes, as recorded in metrics
+const (
+	ScheduleRunCreated        = "created"
+	ScheduleRunBudgetExceeded = "budget_exceeded"
+	ScheduleRunFailed         = "failed"
+)
+
+// Errors returned by buildSchedule, mapped to HTTP errors by the handlers
+var (
+	errScheduleNoRuns      = errors.New("schedule has no runs")
+	errScheduleUserChanged = errors.New("user_id of a schedule cannot change")
+)
+
+// ScheduleRequest creates or replaces a schedule, which creates a task for the user on
+// a cron expression or RRULE
+type ScheduleRequest struct {
+	UserID     string                 `json:"user_id"`
+	AgentID    string                 `json:"agent_id"`
+	Capability string                 `json:"capability"`
+	Input      map[string]interface{} `json:"input"`
+	Priority   protocol.TaskPriority  `json:"priority,omitempty"`
+	MaxCostUSD float64                `json:"max_cost_usd,omitempty"`
+	// Cron is a five-field cron expression or macro such as @daily; exactly one of Cron
+	// and RRule is required
+	Cron string `json:"cron,omitempty"`
+	// RRule is an RFC 5545 recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9;BYMINUTE=0"
+	RRule string `json:"rrule,omitempty"`
+	// Timezone is the IANA time zone the schedule is evaluated in (default UTC)
+	Timezone string `json:"timezone,omitempty"`
+	// StartAt is when the schedule starts (default now)
+	StartAt *time.Time `json:"start_at,omitempty"`
+	Paused  bool       `json:"paused,omitempty"`
+}
+
+// SetSchedules serves the /schedules endpoints from store; RunSchedules creates their tasks
+func (s *Server) SetSchedules(store schedules.Store) {
+	s.schedules = store
+}
+
+// handleSchedules handles /schedules: POST creates a schedule and GET lists them, for a
+// user with ?user_id=
+func (s *Server) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	if s.schedules == nil {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		s.handleCreateSchedule(w, r)
+	case http.MethodGet:
+		s.handleListSchedules(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSchedule handles /schedules/{id}: GET reads a schedule, PUT replaces it and
+// DELETE deletes it, for its own user only when the request carries a token
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	if s.schedules == nil {
+		http.NotFound(w, r)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, SchedulesPath+"/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	schedule, err := s.schedules.Get(ctx, id)
+	if err == nil && !ownsSchedule(ctx, schedule) {
+		err = schedules.ErrScheduleNotFound
+	}
+	if errors.Is(err, schedules.ErrScheduleNotFound) {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(schedule)
+	case http.MethodPut:
+		var req ScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.UserID != "" && req.UserID != schedule.UserID {
+			http.Error(w, errScheduleUserChanged.Error(), http.StatusBadRequest)
+			return
+		}
+		req.UserID = schedule.UserID
+		if err := s.buildSchedule(ctx, schedule, req); err != nil {
+			writeScheduleError(w, err)
+			return
+		}
+		if err := s.schedules.Update(ctx, schedule); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		updated, err := s.schedules.Get(ctx, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+	case http.MethodDelete:
+		if err := s.schedules.Delete(ctx, id); err != nil && !errors.Is(err, schedules.ErrScheduleNotFound) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ownsSchedule tells whether the request's authenticated user owns the schedule; any
+// request does without a token. Other users' schedules are answered as not found, so
+// their IDs are not disclosed.
+func ownsSchedule(ctx context.Context, schedule *schedules.Schedule) bool {
+	userID, err := auth.ExtractUserID(ctx)
+	return err != nil || userID == schedule.UserID
+}
+
+// handleCreateSchedule handles POST /schedules, creating the schedule for the
+// authenticated user when the request carries a token
+func (s *Server) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	userID, err := resolveUser(ctx, req.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	req.UserID = userID
+
+	schedule := schedules.NewSchedule(req.UserID, req.AgentID, req.Capability)
+	if err := s.buildSchedule(ctx, schedule, req); err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	if err := s.schedules.Create(ctx, schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(ctx, "Schedule created", "schedule_id", schedule.ID, "user_id", schedule.UserID,
+		"capability", schedule.Capability, "next_run_at", schedule.NextRunAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// handleListSchedules handles GET /schedules, listing the authenticated user's schedules
+// when the request carries a token
+func (s *Server) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	userID, err := resolveUser(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	limit, offset := 100, 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil {
+		offset = o
+	}
+
+	list, err := s.schedules.List(r.Context(), userID, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// buildSchedule checks a schedule request like createTask checks a task, requiring the
+// user to have a budget, sets the schedule's definition from it and plans its next run.
+// A schedule replaced without a start_at keeps its start.
+func (s *Server) buildSchedule(ctx context.Context, schedule *schedules.Schedule, req ScheduleRequest) error {
+	if req.Priority != "" && !req.Priority.Valid() {
+		return errInvalidPriority
+	}
+	if req.MaxCostUSD < 0 {
+		return errInvalidMaxCost
+	}
+	card, err := s.agentStore.Get(ctx, req.AgentID)
+	if err != nil {
+		return errAgentNotFound
+	}
+	if err := validateInput(ctx, card, CreateTaskRequest{Capability: req.Capability, Input: req.Input}); err != nil {
+		return err
+	}
+	if _, err := s.budgetManager.GetBudget(ctx, req.UserID); err != nil {
+		return errBudgetNotConfigured
+	}
+
+	schedule.AgentID, schedule.Capability, schedule.Input = req.AgentID, req.Capability, req.Input
+	schedule.Priority, schedule.MaxCostUSD = req.Priority, req.MaxCostUSD
+	schedule.Cron, schedule.RRule, schedule.Timezone = req.Cron, req.RRule, req.Timezone
+	schedule.Paused = req.Paused
+	if req.StartAt != nil {
+		schedule.StartAt = *req.StartAt
+	}
+	if err := schedule.Plan(time.Now()); err != nil {
+		return &scheduleError{err}
+	}
+	if schedule.NextRunAt == nil {
+		return errScheduleNoRuns
+	}
+	return nil
+}
+
+// scheduleError is an invalid cron expression, RRULE or time zone
+type scheduleError struct {
+	err error
+}
+
+func (e *scheduleError) Error() string {
+	return e.err.Error()
+}
+
+func (e *scheduleError) Unwrap() error {
+	return e.err
+}
+
+// writeScheduleError maps a buildSchedule failure to an HTTP error
+func writeScheduleError(w http.ResponseWriter, err error) {
+	var invalidSchedule *scheduleError
+	var invalidInput *capabilities.ValidationError
+	switch {
+	case errors.Is(err, errAgentNotFound):
+		http.Error(w, "Agent not found", http.StatusNotFound)
+	case errors.Is(err, errBudgetNotConfigured):
+		http.Error(w, "Budget not configured", http.StatusBadRequest)
+	case errors.As(err, &invalidInput):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(InputValidationResponse{Error: invalidInput.Error(), Errors: invalidInput.Errors})
+	case errors.As(err, &invalidSchedule), errors.Is(err, errScheduleNoRuns),
+		errors.Is(err, errInvalidPriority), errors.Is(err, errInvalidMaxCost):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RunSchedules creates the tasks of due schedules every interval until ctx is cancelled.
+// Replicas sharing a schedule store claim each run, so it creates one task.
+func (s *Server) RunSchedules(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.materializeSchedules(ctx, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// materializeSchedules creates the tasks of the schedules due at now and returns how
+// many it created
+func (s *Server) materializeSchedules(ctx context.Context, now time.Time) int {
+	created := 0
+	for {
+		due, err := s.schedules.Due(ctx, now, scheduleBatchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error listing due schedules", "error", err)
+			return created
+		}
+		for _, schedule := range due {
+			ok, err := s.materialize(ctx, schedule, now)
+			if err != nil {
+				slog.ErrorContext(ctx, "Error claiming schedule run", "schedule_id", schedule.ID, "error", err)
+				return created
+			}
+			if ok {
+				created++
+			}
+		}
+		// Claimed schedules are no longer due, so the next query returns the others
+		if len(due) < scheduleBatchSize {
+			return created
+		}
+	}
+}
+
+// materialize claims the due run of a schedule and creates its task, checking the user's
+// budget at that time like any other task. Runs missed while no server was running are
+// skipped but one, so a schedule does not flood its user with tasks after an outage. A
+// schedule whose recurrence no longer parses has no next run.
+func (s *Server) materialize(ctx context.Context, schedule *schedules.Schedule, now time.Time) (bool, error) {
+	due := *schedule.NextRunAt
+	var next *time.Time
+	recurrence, invalid := schedule.Recurrence()
+	if invalid == nil {
+		if t := recurrence.Next(now); !t.IsZero() {
+			next = &t
+		}
+	}
+	err := s.schedules.Claim(ctx, schedule.ID, due, next)
+	if errors.Is(err, schedules.ErrRunClaimed) || errors.Is(err, schedules.ErrScheduleNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if invalid != nil {
+		slog.ErrorContext(ctx, "Schedule is invalid", "schedule_id", schedule.ID, "error", invalid)
+		s.recordScheduleRun(ctx, schedule.ID, schedules.Run{At: due, Error: invalid.Error()}, ScheduleRunFailed)
+		return false, nil
+	}
+
+	task, err := s.createTask(ctx, CreateTaskRequest{
+		UserID:     schedule.UserID,
+		AgentID:    schedule.AgentID,
+		Capability: schedule.Capability,
+		Input:      schedule.Input,
+		Priority:   schedule.Priority,
+		MaxCostUSD: schedule.MaxCostUSD,
+	}, "")
+	run := schedules.Run{At: due}
+	outcome := ScheduleRunCreated
+	switch {
+	case err == nil:
+		run.TaskID = task.ID
+		slog.InfoContext(ctx, "Scheduled task created", "schedule_id", schedule.ID, "task_id", task.ID,
+			"user_id", schedule.UserID, "next_run_at", next)
+	case errors.Is(err, errBudgetExceeded):
+		outcome = ScheduleRunBudgetExceeded
+		run.Error = err.Error()
+		slog.WarnContext(ctx, "Scheduled task skipped, budget exceeded", "schedule_id", schedule.ID,
+			"user_id", schedule.UserID, "error", err)
+	default:
+		outcome = ScheduleRunFailed
+		run.Error = fmt.Sprintf("Task could not be created: %v", err)
+		slog.ErrorContext(ctx, "Error creating scheduled task", "schedule_id", schedule.ID, "error", err)
+	}
+	s.recordScheduleRun(ctx, schedule.ID, run, outcome)
+	return task != nil, nil
+}
+
+// recordScheduleRun records the outcome of a claimed schedule run
+func (s *Server) recordScheduleRun(ctx context.Context, id string, run schedules.Run, outcome string) {
+	if err := s.schedules.RecordRun(ctx, id, run); err != nil && !errors.Is(err, schedules.ErrScheduleNotFound) {
+		slog.ErrorContext(ctx, "Error recording schedule run", "schedule_id", id, "error", err)
+	}
+	if s.telemetry != nil && s.telemetry.Metrics != nil {
+		s.telemetry.Metrics.RecordScheduleRun(ctx, outcome)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupScheduleServer(t *testing.T) *Server {
+	t.Helper()
+	server := setupTestServer()
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(protocol.Capability{Name: "search"})
+	server.agentStore.Register(context.Background(), card)
+	server.SetSchedules(schedules.NewMemoryStore())
+	return server
+}
+
+func TestServer_Schedules(t *testing.T) {
+	server := setupScheduleServer(t)
+	ctx := context.Background()
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+
+	call := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		if req.URL.Path == SchedulesPath {
+			server.handleSchedules(rr, req)
+		} else {
+			server.handleSchedule(rr, req)
+		}
+		return rr
+	}
+	request := func(userID, cron string) map[string]interface{} {
+		return map[string]interface{}{
+			"user_id":    userID,
+			"agent_id":   "test-agent",
+			"capability": "search",
+			"input":      map[string]interface{}{"query": "go"},
+			"cron":       cron,
+			"timezone":   "Europe/Paris",
+		}
+	}
+
+	rr := call(http.MethodPost, SchedulesPath, request("user-1", "0 9 * * 1-5"))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created schedules.Schedule
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.NotEmpty(t, created.ID)
+	require.NotNil(t, created.NextRunAt)
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	assert.Equal(t, 9, created.NextRunAt.In(paris).Hour())
+
+	rr = call(http.MethodGet, SchedulesPath+"/"+created.ID, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = call(http.MethodGet, SchedulesPath+"?user_id=user-1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list []schedules.Schedule
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	// Replacing a schedule replans it; its user cannot change
+	update := request("user-1", "")
+	update["rrule"] = "FREQ=WEEKLY;BYDAY=MO;BYHOUR=8;BYMINUTE=0"
+	rr = call(http.MethodPut, SchedulesPath+"/"+created.ID, update)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var updated schedules.Schedule
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&updated))
+	assert.Equal(t, time.Monday, updated.NextRunAt.In(paris).Weekday())
+	assert.Equal(t, 8, updated.NextRunAt.In(paris).Hour())
+	rr = call(http.MethodPut, SchedulesPath+"/"+created.ID, request("user-2", "@daily"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	with := func(key, value string) map[string]interface{} {
+		body := request("user-1", "@daily")
+		body[key] = value
+		return body
+	}
+	for name, tt := range map[string]struct {
+		body map[string]interface{}
+		code int
+	}{
+		"invalid cron":    {request("user-1", "61 * * * *"), http.StatusBadRequest},
+		"no budget":       {request("user-2", "@daily"), http.StatusBadRequest},
+		"no runs":         {with("cron", "0 0 30 2 *"), http.StatusBadRequest},
+		"unknown zone":    {with("timezone", "Mars/Base"), http.StatusBadRequest},
+		"unknown agent":   {with("agent_id", "missing"), http.StatusNotFound},
+		"both recurrence": {with("rrule", "FREQ=DAILY"), http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rr := call(http.MethodPost, SchedulesPath, tt.body)
+			assert.Equal(t, tt.code, rr.Code, rr.Body.String())
+		})
+	}
+
+	rr = call(http.MethodDelete, SchedulesPath+"/"+created.ID, nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	rr = call(http.MethodGet, SchedulesPath+"/"+created.ID, nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_MaterializeSchedules(t *testing.T) {
+	server := setupScheduleServer(t)
+	ctx := context.Background()
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	schedule := schedules.NewSchedule("user-1", "test-agent", "search")
+	schedule.Input = map[string]interface{}{"query": "go"}
+	schedule.Cron = "0 * * * *"
+	schedule.StartAt = start
+	require.NoError(t, schedule.Plan(start.Add(-time.Minute)))
+	require.NoError(t, server.schedules.Create(ctx, schedule))
+
+	// Not due yet
+	assert.Equal(t, 0, server.materializeSchedules(ctx, start.Add(-time.Minute)))
+
+	// Runs missed while the server was down are skipped but one
+	now := start.Add(3*time.Hour + 5*time.Minute)
+	assert.Equal(t, 1, server.materializeSchedules(ctx, now))
+	assert.Equal(t, 0, server.materializeSchedules(ctx, now), "a run creates one task")
+
+	got, err := server.schedules.Get(ctx, schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Runs)
+	assert.Equal(t, start, got.LastRunAt.UTC())
+	assert.Equal(t, start.Add(4*time.Hour), got.NextRunAt.UTC())
+	assert.Empty(t, got.LastError)
+
+	task, err := server.taskStore.Get(ctx, got.LastTaskID)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", task.UserID)
+	assert.Equal(t, "search", task.Capability)
+	assert.Equal(t, "go", task.Input["query"])
+	all, err := server.taskStore.List(ctx, tasks.ListFilter{}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func TestServer_MaterializeSchedules_BudgetExceeded(t *testing.T) {
+	server := setupScheduleServer(t)
+	ctx := context.Background()
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 0.001))
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	schedule := schedules.NewSchedule("user-1", "test-agent", "search")
+	schedule.Input = map[string]interface{}{"query": "test"}
+	schedule.Cron = "* * * * *"
+	schedule.NextRunAt = &now
+	require.NoError(t, server.schedules.Create(ctx, schedule))
+
+	// The budget is checked when the run is due; the schedule stays active
+	assert.Equal(t, 0, server.materializeSchedules(ctx, now))
+	got, err := server.schedules.Get(ctx, schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.Runs)
+	assert.Equal(t, now, got.LastRunAt)
+	assert.Contains(t, got.LastError, "budget")
+	require.NotNil(t, got.NextRunAt)
+	assert.Equal(t, now.Add(time.Minute), *got.NextRunAt)
+}
@@ -2,36 +2,105 @@ package server
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"crypto/tls"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/a2aclient"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/agentcard"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
-	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/middleware"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/observability"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/webhook"
+	"github.com/bhatti/mcp-a2a-go/shared/auth"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/bhatti/mcp-a2a-go/shared/health"
+	"github.com/bhatti/mcp-a2a-go/shared/httpserver"
+	"github.com/bhatti/mcp-a2a-go/shared/lifecycle"
+	"github.com/bhatti/mcp-a2a-go/shared/mtls"
+	"github.com/bhatti/mcp-a2a-go/shared/ratelimit"
 )
 
 // Server is the A2A HTTP server
 type Server struct {
 	taskStore     tasks.Store
 	agentStore    *agentcard.Store
-	costTracker   *cost.Tracker
+	costTracker   cost.Tracker
 	budgetManager *cost.BudgetManager
 	agentCard     *protocol.AgentCard
 	telemetry     *observability.Telemetry
+
+	// speculationCostCapUSD bounds what a speculative task may spend on substitutes
+	speculationCostCapUSD float64
+
+	// lifecycle tracks in-flight requests and SSE streams for draining; nil disables it
+	lifecycle *lifecycle.Manager
+
+	// adminToken authenticates callers of the admin endpoints; empty disables them
+	adminToken string
+	// billingToken authenticates readers of budgets and usage alongside the admin token
+	billingToken string
+
+	// usageJournal is replayed by budget simulations; nil disables them
+	usageJournal cost.Journal
+
+	// notifier delivers task state transitions to webhooks; nil disables push notifications
+	notifier *webhook.Notifier
+
+	// queue hands new tasks to the task processors; nil leaves them to poll the store
+	queue tasks.Queue
+	// maxPending refuses new tasks while that many wait to start; zero admits every task
+	maxPending int
+	// maxInlineFileBytes bounds the files messages carry inline; zero for no limit
+	maxInlineFileBytes int
+
+	// schedules create recurring tasks; nil disables the /schedules endpoints
+	schedules schedules.Store
+
+	// retention is how long finished tasks without a TTL are kept; zero keeps them
+	retention time.Duration
+	// archive keeps the finished tasks garbage collected; nil deletes them
+	archive tasks.Archive
+
+	// delegator offers the capabilities of remote agents; nil accepts only local ones
+	delegator *a2aclient.Delegator
+
+	// executors project the cost of new tasks; nil charges the card's or a default estimate
+	executors *capabilities.Registry
+
+	// blobs keep the artifact file parts too large to store with their task
+	blobs blobs.Store
+
+	// body limits request bodies and compresses responses; nil leaves both as they are
+	body *httpserver.BodyMiddleware
+	// health serves the liveness and readiness probes; nil leaves them out
+	health *health.Checker
+
+	// tlsConfig serves HTTPS; nil serves plain HTTP
+	tlsConfig *tls.Config
+	// clientCerts requires and maps client certificates under mutual TLS; nil skips it
+	clientCerts *mtls.Middleware
+
+	// auth requires a JWT on the agent card, task and JSON-RPC routes; nil leaves them open
+	auth *auth.Middleware
+	// rateLimiter limits each authenticated user's requests to those routes; nil for no limit
+	rateLimiter *ratelimit.Limiter
+
+	mu         sync.Mutex
+	httpServer *http.Server
 }
 
 // NewServer creates a new A2A server
 func NewServer(
 	taskStore tasks.Store,
 	agentStore *agentcard.Store,
-	costTracker *cost.Tracker,
+	costTracker cost.Tracker,
 	budgetManager *cost.BudgetManager,
 	agentCard *protocol.AgentCard,
 	telemetry *observability.Telemetry,
@@ -46,18 +115,87 @@ func NewServer(
 	}
 }
 
+// SetSpeculationCostCap sets the cost cap used to plan speculative tasks; zero disables
+// speculation and speculative tasks run only their requested capability
+func (s *Server) SetSpeculationCostCap(costCapUSD float64) {
+	s.speculationCostCapUSD = costCapUSD
+}
+
+// SetNotifier enables push notifications, delivering task state transitions to the
+// webhook given when a task is created or set with tasks/pushNotificationConfig/set
+func (s *Server) SetNotifier(notifier *webhook.Notifier) {
+	s.notifier = notifier
+}
+
+// SetQueue adds every new task to queue for the task processors to claim
+func (s *Server) SetQueue(queue tasks.Queue) {
+	s.queue = queue
+}
+
+// SetMaxPending refuses new tasks with 429 Too Many Requests (JSON-RPC: ServerBusy) while
+// maxPending tasks, including those waiting for their dependencies, wait to start, so
+// clients back off instead of growing the backlog; zero admits every task
+func (s *Server) SetMaxPending(maxPending int) {
+	s.maxPending = maxPending
+}
+
+// SetMaxInlineFileBytes refuses messages with a file part carrying more than limit bytes
+// inline, with 413 Request Entity Too Large (JSON-RPC: RequestTooLarge); such files are
+// passed by URI instead. Zero accepts any size the request body limit allows.
+func (s *Server) SetMaxInlineFileBytes(limit int) {
+	s.maxInlineFileBytes = limit
+}
+
+// SetLifecycle tracks requests and SSE streams with m so shutdown can drain them
+func (s *Server) SetLifecycle(m *lifecycle.Manager) {
+	s.lifecycle = m
+}
+
+// SetBodyLimits limits request bodies, decompressing gzip bodies first, and compresses
+// responses as config selects
+func (s *Server) SetBodyLimits(config httpserver.BodyConfig) {
+	s.body = httpserver.NewBodyMiddleware(config)
+}
+
+// SetHealth serves the /healthz and /readyz probes of checker. They answer ahead of the
+// shutdown drain, so readiness reports it instead of being refused.
+func (s *Server) SetHealth(checker *health.Checker) {
+	s.health = checker
+}
+
+// SetTLS serves HTTPS with config. With clientCerts, requests must present a client
+// certificate, mapped to its identity, except on the health probes.
+func (s *Server) SetTLS(config *tls.Config, clientCerts *mtls.Middleware) {
+	s.tlsConfig = config
+	s.clientCerts = clientCerts
+	if clientCerts != nil {
+		clientCerts.SetExemptPaths("/health", health.LivenessPath, health.ReadinessPath)
+	}
+}
+
 // RegisterRoutes registers all HTTP routes
 func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", s.handleHealth)
 
 	// Metrics endpoint for Prometheus (no auth required)
 	if s.telemetry != nil && s.telemetry.Metrics != nil {
-		mux.Handle("/metrics", promhttp.Handler())
-		log.Println("Metrics endpoint registered at /metrics")
+		mux.Handle("/metrics", observability.MetricsHandler())
+		slog.Info("Metrics endpoint registered at /metrics")
 	}
 
-	mux.HandleFunc("/agent", s.handleGetAgentCard)
-	mux.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/agent", s.protect(s.handleGetAgentCard))
+	mux.Handle(JSONRPCPath, s.protect(s.handleJSONRPC))
+	mux.HandleFunc(BudgetsPath, s.handleBudget)
+	mux.HandleFunc(UsagePath, s.handleUsage)
+	mux.HandleFunc(AdminBudgetsPath, s.handleSetBudget)
+	mux.HandleFunc(AdminTenantBudgetsPath, s.handleSetTenantBudget)
+	mux.HandleFunc(AdminBudgetSimulationPath, s.handleSimulateBudgets)
+	mux.HandleFunc(AdminCostsPath, s.handleCosts)
+	mux.HandleFunc(AdminRemoteAgentsPath, s.handleRemoteAgents)
+	mux.HandleFunc(AdminPricingPath, s.handlePricing)
+	mux.HandleFunc(AdminTasksPath, s.handleAdminTask)
+	mux.HandleFunc(AdminUsersPath, s.handleUserData)
+	mux.Handle("/tasks", s.protect(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
 			s.handleCreateTask(w, r)
@@ -66,8 +204,11 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
-	mux.HandleFunc("/tasks/", func(w http.ResponseWriter, r *http.Request) {
+	}))
+	mux.Handle(TaskEstimatePath, s.protect(s.handleEstimateTask))
+	mux.Handle(SchedulesPath, s.protect(s.handleSchedules))
+	mux.Handle(SchedulesPath+"/", s.protect(s.handleSchedule))
+	mux.Handle("/tasks/", s.protect(func(w http.ResponseWriter, r *http.Request) {
 		// Extract task ID from path
 		path := strings.TrimPrefix(r.URL.Path, "/tasks/")
 		parts := strings.Split(path, "/")
@@ -78,6 +219,22 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 			s.handleTaskEvents(w, r, taskID)
 			return
 		}
+		if len(parts) > 1 && parts[1] == "graph" {
+			s.handleTaskGraph(w, r, taskID)
+			return
+		}
+		if len(parts) > 1 && parts[1] == "history" {
+			s.handleTaskHistory(w, r, taskID)
+			return
+		}
+		if len(parts) > 1 && parts[1] == "messages" {
+			s.handleTaskMessages(w, r, taskID)
+			return
+		}
+		if len(parts) > 1 && parts[1] == "artifacts" {
+			s.handleTaskArtifacts(w, r, taskID, parts[2:])
+			return
+		}
 
 		switch r.Method {
 		case http.MethodGet:
@@ -87,7 +244,7 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	}))
 }
 
 // Start starts the HTTP server
@@ -98,31 +255,80 @@ func (s *Server) Start(addr string) error {
 	// Register agent card if provided
 	if s.agentCard != nil {
 		if err := s.agentStore.Register(context.Background(), s.agentCard); err != nil {
-			log.Printf("Warning: Failed to register agent card: %v", err)
+			slog.Warn("Failed to register agent card", "error", err)
 		}
 	}
 
 	// Wrap handler with tracing middleware if telemetry is enabled
 	var handler http.Handler = mux
 	if s.telemetry != nil {
-		tracingMiddleware := middleware.NewTracingMiddleware(s.telemetry)
+		tracingMiddleware := httpserver.NewTracingMiddleware(s.telemetry.Tracer)
 		handler = tracingMiddleware.Handler(mux)
-		log.Println("Tracing middleware enabled")
+		slog.Info("Tracing middleware enabled")
+	}
+
+	// Track in-flight work so shutdown can drain it
+	if s.lifecycle != nil {
+		handler = s.lifecycle.Handler(handler)
+	}
+
+	if s.health != nil {
+		handler = s.health.Handler(handler)
+	}
+
+	if s.body != nil {
+		handler = s.body.Handler(handler)
+	}
+
+	if s.clientCerts != nil {
+		handler = s.clientCerts.Handler(handler)
 	}
 
+	// Every request gets an ID and a completion log entry
+	handler = httpserver.NewLoggingMiddleware(slog.Default()).Handler(handler)
+
 	server := &http.Server{
 		Addr:         addr,
 		Handler:      handler,
+		TLSConfig:    s.tlsConfig,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	log.Printf("Starting A2A server on %s", addr)
-	return server.ListenAndServe()
+	s.mu.Lock()
+	s.httpServer = server
+	s.mu.Unlock()
+
+	slog.Info("Starting A2A server", "addr", addr, "tls", s.tlsConfig != nil)
+	var err error
+	if s.tlsConfig != nil {
+		// The certificate comes from TLSConfig, so it can be reloaded
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the HTTP server started by Start, waiting for open connections until
+// ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	server := s.httpServer
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
 }
 
-// handleTaskEvents handles SSE streaming for task events
+// handleTaskEvents handles SSE streaming for task events. Each message carries the event
+// ID, and a client reconnecting with Last-Event-ID resumes after that event.
 func (s *Server) handleTaskEvents(w http.ResponseWriter, r *http.Request, taskID string) {
 	ctx := r.Context()
 
@@ -133,36 +339,13 @@ func (s *Server) handleTaskEvents(w http.ResponseWriter, r *http.Request, taskID
 		return
 	}
 
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-
-	// Subscribe to task events
-	eventCh := s.taskStore.Subscribe(ctx, taskID)
-	defer s.taskStore.Unsubscribe(ctx, taskID, eventCh)
-
-	// Send events to client
-	flusher, ok := w.(http.Flusher)
+	flusher, ok := startSSE(w)
 	if !ok {
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
 
-	for {
-		select {
-		case event, ok := <-eventCh:
-			if !ok {
-				return
-			}
-
-			// Format SSE message
-			fmt.Fprintf(w, "data: {\"task_id\":\"%s\",\"state\":\"%s\",\"message\":\"%s\"}\n\n",
-				event.TaskID, event.State, event.Message)
-			flusher.Flush()
-
-		case <-ctx.Done():
-			return
-		}
-	}
+	s.streamTaskEvents(ctx, w, flusher, taskID, lastEventID(r), func(event protocol.TaskEvent) interface{} {
+		return event
+	})
 }
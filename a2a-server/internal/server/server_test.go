@@ -19,11 +19,11 @@ import (
 func TestNewServer(t *testing.T) {
 	taskStore := tasks.NewMemoryStore()
 	agentStore := agentcard.NewStore()
-	costTracker := cost.NewTracker()
+	costTracker := cost.NewMemoryTracker()
 	budgetManager := cost.NewBudgetManager()
 	agentCard := protocol.NewAgentCard("test", "Test", "1.0.0", "Test")
 
-	server := NewServer(taskStore, agentStore, costTracker, budgetManager, agentCard)
+	server := NewServer(taskStore, agentStore, costTracker, budgetManager, agentCard, nil)
 
 	assert.NotNil(t, server)
 	assert.NotNil(t, server.taskStore)
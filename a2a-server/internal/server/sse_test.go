@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,7 +11,10 @@ import (
 	"time"
 
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/bhatti/mcp-a2a-go/shared/lifecycle"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestServer_TaskEvents_SSE(t *testing.T) {
@@ -75,6 +79,34 @@ func TestServer_TaskEvents_SSE(t *testing.T) {
 	assert.Contains(t, body, "running")
 }
 
+func TestServer_TaskEvents_EndsOnDrain(t *testing.T) {
+	server := setupTestServer()
+	manager := lifecycle.NewManager()
+	server.SetLifecycle(manager)
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	server.taskStore.Create(ctx, task)
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	handler := manager.Handler(mux)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/tasks/"+task.ID+"/events", nil))
+	}()
+	assert.Eventually(t, func() bool { return manager.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	// The stream finishes on its own, so draining completes before the deadline
+	drainCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	assert.NoError(t, manager.Drain(drainCtx))
+	wg.Wait()
+}
+
 func TestServer_TaskEvents_TaskNotFound(t *testing.T) {
 	server := setupTestServer()
 
@@ -223,3 +255,184 @@ func TestServer_Routes_GET_Agent(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
+
+// sseMessage is one parsed SSE message
+type sseMessage struct {
+	ID   string
+	Data string
+}
+
+func parseSSE(body string) []sseMessage {
+	var messages []sseMessage
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var message sseMessage
+		for _, line := range strings.Split(block, "\n") {
+			if id, ok := strings.CutPrefix(line, "id: "); ok {
+				message.ID = id
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				message.Data = data
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// serveUntilDone runs a streaming request and fails if the stream does not end by itself
+func serveUntilDone(t *testing.T, handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(rr, req)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after the final event")
+	}
+	return rr
+}
+
+func TestServer_TaskEvents_ResumesFromLastEventID(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	server.taskStore.Create(ctx, task)
+	server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning})
+	server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning,
+		Artifact: &protocol.Artifact{ArtifactID: "a-1", Parts: []protocol.Part{{Kind: protocol.KindText, Text: "partial"}}}})
+	server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateCompleted})
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	req := httptest.NewRequest("GET", "/tasks/"+task.ID+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	rr := serveUntilDone(t, mux, req)
+
+	messages := parseSSE(rr.Body.String())
+	require.Len(t, messages, 2)
+	assert.Equal(t, "2", messages[0].ID)
+	assert.Contains(t, messages[0].Data, `"artifact"`)
+	assert.Equal(t, "3", messages[1].ID)
+	assert.Contains(t, messages[1].Data, `"state":"completed"`)
+}
+
+func TestServer_TaskEvents_FinishedTaskWithoutEvents(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	task.Cancel("Stopped")
+	server.taskStore.Create(ctx, task)
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	rr := serveUntilDone(t, mux, httptest.NewRequest("GET", "/tasks/"+task.ID+"/events", nil))
+
+	messages := parseSSE(rr.Body.String())
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Data, `"state":"cancelled"`)
+}
+
+func TestJSONRPC_MessageStream(t *testing.T) {
+	server := setupJSONRPCServer(t)
+	ctx := context.Background()
+
+	// Play the executor once the stream has created the task
+	go func() {
+		var task *protocol.Task
+		for task == nil {
+			time.Sleep(time.Millisecond)
+			if tasks, _ := server.taskStore.List(ctx, tasks.ListFilter{}, 1, 0); len(tasks) == 1 {
+				task = tasks[0]
+			}
+		}
+		server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, Message: "Task started"})
+		server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning,
+			Artifact: &protocol.Artifact{ArtifactID: "progress", Parts: []protocol.Part{{Kind: protocol.KindText, Text: "half"}}}})
+		server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, Append: true, LastChunk: true,
+			Artifact: &protocol.Artifact{ArtifactID: "progress", Parts: []protocol.Part{{Kind: protocol.KindText, Text: "done"}}}})
+		completed := *task
+		completed.UpdateState(protocol.TaskStateRunning)
+		server.taskStore.Update(ctx, &completed)
+		completed.SetResult(map[string]interface{}{"status": "success"})
+		server.taskStore.Update(ctx, &completed)
+		server.taskStore.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateCompleted})
+	}()
+
+	body := strings.Replace(messageSend, `"message/send"`, `"message/stream"`, 1)
+	rr := serveUntilDone(t, http.HandlerFunc(server.handleJSONRPC),
+		httptest.NewRequest(http.MethodPost, JSONRPCPath, strings.NewReader(body)))
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+
+	var kinds []string
+	var last map[string]interface{}
+	for i, message := range parseSSE(rr.Body.String()) {
+		var resp struct {
+			ID     json.RawMessage        `json:"id"`
+			Result map[string]interface{} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(message.Data), &resp))
+		assert.Equal(t, "1", string(resp.ID))
+		if i > 0 {
+			assert.NotEmpty(t, message.ID, "events carry IDs for resubscribing")
+		}
+		kinds = append(kinds, resp.Result["kind"].(string))
+		last = resp.Result
+	}
+
+	// The task comes first, then its events from its creation up to the final status
+	assert.Equal(t, []string{protocol.KindTask, protocol.KindStatusUpdate, protocol.KindStatusUpdate,
+		protocol.KindArtifactUpdate, protocol.KindArtifactUpdate, protocol.KindStatusUpdate}, kinds)
+	assert.Equal(t, true, last["final"])
+	assert.Equal(t, "ctx-1", last["contextId"])
+
+	// Resubscribing after the last event gets only a final status
+	messages := parseSSE(rr.Body.String())
+	var first struct {
+		Result protocol.A2ATask `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(messages[0].Data), &first))
+	taskID, lastID := first.Result.ID, messages[len(messages)-1].ID
+
+	req := httptest.NewRequest(http.MethodPost, JSONRPCPath, strings.NewReader(
+		`{"jsonrpc":"2.0","id":2,"method":"tasks/resubscribe","params":{"id":"`+taskID+`"}}`))
+	req.Header.Set("Last-Event-ID", lastID)
+	rr = serveUntilDone(t, http.HandlerFunc(server.handleJSONRPC), req)
+	messages = parseSSE(rr.Body.String())
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Data, `"final":true`)
+}
+
+func TestTaskProcessor_StreamsProgressAndResult(t *testing.T) {
+	step := simulatedStep
+	simulatedStep = time.Millisecond
+	defer func() { simulatedStep = step }()
+
+	store := tasks.NewMemoryStore()
+	ctx := context.Background()
+	task := protocol.NewTask("agent-1", "search", nil)
+	task.ID = "a" + task.ID[1:] // a seed that succeeds
+	require.NoError(t, store.Create(ctx, task))
+
+	NewTaskProcessor(store, time.Hour).processTask(ctx, task)
+
+	events := store.Events(ctx, task.ID, 0)
+	require.GreaterOrEqual(t, len(events), 5)
+	assert.Equal(t, protocol.TaskStateRunning, events[0].State)
+	progress := events[1 : len(events)-2]
+	for i, event := range progress {
+		require.NotNil(t, event.Artifact)
+		assert.Equal(t, task.ID+"-progress", event.Artifact.ArtifactID)
+		assert.Equal(t, i > 0, event.Append)
+		assert.Equal(t, i == len(progress)-1, event.LastChunk)
+	}
+	result := events[len(events)-2]
+	require.NotNil(t, result.Artifact)
+	assert.Equal(t, task.ID+"-result", result.Artifact.ArtifactID)
+	assert.True(t, events[len(events)-1].Final())
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+// startSSE sets the SSE headers and lifts the server write timeout for a long-lived stream
+func startSSE(w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	// Not every ResponseWriter supports deadlines (httptest.ResponseRecorder does not)
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	return flusher, true
+}
+
+// writeSSE writes data as JSON in an SSE message; id is omitted when zero
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, id int64, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", body); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// lastEventID returns the event ID a reconnecting client last saw, from the
+// Last-Event-ID header; 0 replays every retained event
+func lastEventID(r *http.Request) int64 {
+	id, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// streamTaskEvents writes the task's events after afterID, then live events, until the
+// final event, the client disconnects or the server starts draining
+func (s *Server) streamTaskEvents(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, taskID string, afterID int64, format func(protocol.TaskEvent) interface{}) {
+	// Streams end when the server starts draining so shutdown is not held up
+	var draining <-chan struct{}
+	if s.lifecycle != nil {
+		draining = s.lifecycle.Stream(ctx)
+	}
+
+	tasks.Follow(ctx, s.taskStore, taskID, afterID, draining, func(event protocol.TaskEvent) bool {
+		if err := writeSSE(w, flusher, event.ID, format(event)); err != nil {
+			slog.DebugContext(ctx, "Task event stream closed", "task_id", taskID, "error", err)
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+)
+
+// UsagePath is the endpoint prefix for reading a user's usage records and their summary
+const UsagePath = "/usage/"
+
+// Page sizes of GET /usage/{user_id}
+const (
+	defaultUsageLimit = 100
+	maxUsageLimit     = 1000
+)
+
+// UsagePage is the response of GET /usage/{user_id}: one page of the user's usage
+// records between start and end, oldest first
+type UsagePage struct {
+	UserID  string       `json:"user_id"`
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end"`
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+	Records []cost.Usage `json:"records"`
+	// NextOffset is the offset of the next page; absent on the last page
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// UsageSummary is the response of GET /usage/{user_id}/summary: the user's usage between
+// start and end, in total and by day, model and capability
+type UsageSummary struct {
+	UserID       string            `json:"user_id"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	Total        cost.CostBucket   `json:"total"`
+	ByDay        []cost.CostBucket `json:"by_day"`
+	ByModel      []cost.CostBucket `json:"by_model"`
+	ByCapability []cost.CostBucket `json:"by_capability"`
+}
+
+// handleUsage handles GET /usage/{user_id}?start=&end=&limit=&offset= and
+// GET /usage/{user_id}/summary?start=&end=, with start and end in RFC 3339 and the last
+// 30 days by default
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeBilling(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, UsagePath), "/")
+	userID, rest, _ := strings.Cut(path, "/")
+	if userID == "" || (rest != "" && rest != "summary") {
+		http.Error(w, "Expected /usage/{user_id} or /usage/{user_id}/summary", http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	start, end, err := timeRange(query, "start", "end")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var response interface{}
+	if rest == "summary" {
+		response, err = s.usageSummary(r, userID, start, end)
+	} else {
+		limit, offset, ok := pagination(w, query.Get("limit"), query.Get("offset"))
+		if !ok {
+			return
+		}
+		response, err = s.usagePage(r, userID, start, end, limit, offset)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// pagination parses the limit and offset of a page, writing the error response if they
+// are invalid
+func pagination(w http.ResponseWriter, rawLimit, rawOffset string) (int, int, bool) {
+	limit, offset := defaultUsageLimit, 0
+	if rawLimit != "" {
+		l, err := strconv.Atoi(rawLimit)
+		if err != nil || l <= 0 || l > maxUsageLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxUsageLimit), http.StatusBadRequest)
+			return 0, 0, false
+		}
+		limit = l
+	}
+	if rawOffset != "" {
+		o, err := strconv.Atoi(rawOffset)
+		if err != nil || o < 0 {
+			http.Error(w, "offset must not be negative", http.StatusBadRequest)
+			return 0, 0, false
+		}
+		offset = o
+	}
+	return limit, offset, true
+}
+
+// usagePage returns one page of a user's usage records
+func (s *Server) usagePage(r *http.Request, userID string, start, end time.Time, limit, offset int) (UsagePage, error) {
+	records, err := s.costTracker.GetUsage(r.Context(), userID, start, end)
+	if err != nil {
+		return UsagePage{}, err
+	}
+	page := UsagePage{UserID: userID, Start: start, End: end, Total: len(records), Limit: limit, Offset: offset,
+		Records: []cost.Usage{}}
+	if offset < len(records) {
+		page.Records = records[offset:min(offset+limit, len(records))]
+	}
+	if next := offset + limit; next < len(records) {
+		page.NextOffset = &next
+	}
+	return page, nil
+}
+
+// usageSummary aggregates a user's usage
+func (s *Server) usageSummary(r *http.Request, userID string, start, end time.Time) (UsageSummary, error) {
+	summary := UsageSummary{UserID: userID, Start: start, End: end, Total: cost.CostBucket{Key: "total"}}
+	for grouping, buckets := range map[cost.Grouping]*[]cost.CostBucket{
+		cost.ByDay:        &summary.ByDay,
+		cost.ByModel:      &summary.ByModel,
+		cost.ByCapability: &summary.ByCapability,
+	} {
+		result, err := s.costTracker.CostBy(r.Context(), userID, start, end, grouping)
+		if err != nil {
+			return UsageSummary{}, err
+		}
+		*buckets = result
+	}
+	for _, bucket := range summary.ByModel {
+		summary.Total.Records += bucket.Records
+		summary.Total.PromptTokens += bucket.PromptTokens
+		summary.Total.CompletionTokens += bucket.CompletionTokens
+		summary.Total.TotalTokens += bucket.TotalTokens
+		summary.Total.CostUSD += bucket.CostUSD
+	}
+	return summary, nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Usage(t *testing.T) {
+	server := setupTestServer()
+	ctx := context.Background()
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		model := "gpt-4"
+		if i%2 == 1 {
+			model = "claude-3-sonnet"
+		}
+		require.NoError(t, server.costTracker.RecordUsage(ctx, cost.Usage{
+			UserID: "alice", TaskID: fmt.Sprintf("task-%d", i), Capability: "search_papers", Model: model,
+			PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, CostUSD: 0.5, Timestamp: day.Add(time.Duration(i) * 12 * time.Hour),
+		}))
+	}
+	require.NoError(t, server.costTracker.RecordUsage(ctx, cost.Usage{UserID: "bob", Model: "gpt-4", CostUSD: 9, Timestamp: day}))
+	window := "start=2025-03-01T00:00:00Z&end=2025-04-01T00:00:00Z"
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleUsage(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+	page := func(path string) UsagePage {
+		rr := serve(path)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var page UsagePage
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+		return page
+	}
+
+	first := page("/usage/alice?" + window + "&limit=2")
+	assert.Equal(t, 5, first.Total)
+	require.Len(t, first.Records, 2)
+	assert.Equal(t, "task-0", first.Records[0].TaskID)
+	require.NotNil(t, first.NextOffset)
+	assert.Equal(t, 2, *first.NextOffset)
+
+	last := page(fmt.Sprintf("/usage/alice?%s&limit=2&offset=%d", window, 4))
+	require.Len(t, last.Records, 1)
+	assert.Equal(t, "task-4", last.Records[0].TaskID)
+	assert.Nil(t, last.NextOffset)
+	assert.Empty(t, page("/usage/alice?"+window+"&offset=10").Records)
+	assert.Empty(t, page("/usage/alice").Records, "the last 30 days by default")
+
+	rr := serve("/usage/alice/summary?" + window)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var summary UsageSummary
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&summary))
+	assert.Equal(t, 5, summary.Total.Records)
+	assert.InDelta(t, 2.5, summary.Total.CostUSD, 1e-9)
+	assert.Equal(t, 75, summary.Total.TotalTokens)
+	assert.Len(t, summary.ByDay, 3)
+	require.Len(t, summary.ByModel, 2)
+	assert.Equal(t, "claude-3-sonnet", summary.ByModel[0].Key)
+	assert.Equal(t, 2, summary.ByModel[0].Records)
+	require.Len(t, summary.ByCapability, 1)
+
+	for _, path := range []string{
+		"/usage/",
+		"/usage/alice/other",
+		"/usage/alice?limit=0",
+		"/usage/alice?limit=5000",
+		"/usage/alice?offset=-1",
+		"/usage/alice?start=yesterday",
+		"/usage/alice?start=2025-04-01T00:00:00Z&end=2025-03-01T00:00:00Z",
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(path).Code, path)
+	}
+
+	server.SetBillingToken("billing")
+	assert.Equal(t, http.StatusUnauthorized, serve("/usage/alice").Code)
+	req := httptest.NewRequest(http.MethodGet, "/usage/alice/summary", nil)
+	req.Header.Set("Authorization", "Bearer billing")
+	rr = httptest.NewRecorder()
+	server.handleUsage(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
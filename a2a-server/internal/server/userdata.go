@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+// AdminUsersPath is the endpoint prefix for a user's data: GET /admin/users/{user_id}/data
+// exports it and DELETE erases it, for the MCP server's GDPR requests
+const AdminUsersPath = "/admin/users/"
+
+// errUnfinishedTasks is returned when erasing a user whose tasks are still running
+var errUnfinishedTasks = errors.New("user has unfinished tasks")
+
+// UserData is everything the server keeps about a user
+type UserData struct {
+	Tasks     []*protocol.Task      `json:"tasks"`
+	Usage     []cost.Usage          `json:"usage"`
+	Schedules []*schedules.Schedule `json:"schedules"`
+}
+
+// UserErasure counts what erasing a user deleted, or handed to the pseudonym
+type UserErasure struct {
+	Tasks     int `json:"tasks"`
+	Usage     int `json:"usage"`
+	Schedules int `json:"schedules"`
+}
+
+// handleUserData handles GET and DELETE /admin/users/{user_id}/data. DELETE removes the
+// user's tasks, usage records and schedules, or hands them to the pseudonym query
+// parameter when it is set; it fails with 409 while any of the user's tasks is unfinished.
+// Budgets are kept, since they limit spending rather than describe the user.
+func (s *Server) handleUserData(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	userID, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, AdminUsersPath), "/")
+	if userID == "" || resource != "data" {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	var result interface{}
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		result, err = s.exportUser(ctx, userID)
+	case http.MethodDelete:
+		result, err = s.eraseUser(ctx, userID, r.URL.Query().Get("pseudonym"))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if errors.Is(err, errUnfinishedTasks) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error handling user data", "method", r.Method, "user_id", userID, "error", err)
+		http.Error(w, "Failed to handle user data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// exportUser collects the user's tasks, usage records and schedules
+func (s *Server) exportUser(ctx context.Context, userID string) (*UserData, error) {
+	userTasks, err := s.userTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.costTracker.GetUsage(ctx, userID, time.Time{}, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	userSchedules, err := s.userSchedules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		usage = []cost.Usage{}
+	}
+	return &UserData{Tasks: userTasks, Usage: usage, Schedules: userSchedules}, nil
+}
+
+// eraseUser deletes the user's tasks, with their artifact blobs, usage records and
+// schedules, or hands them to pseudonym when it is set
+func (s *Server) eraseUser(ctx context.Context, userID, pseudonym string) (*UserErasure, error) {
+	userTasks, err := s.userTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range userTasks {
+		if !task.State.IsTerminal() {
+			return nil, fmt.Errorf("%w: task %s is %s", errUnfinishedTasks, task.ID, task.State)
+		}
+	}
+
+	erasure := &UserErasure{}
+	for _, task := range userTasks {
+		if pseudonym != "" {
+			task.UserID = pseudonym
+			if err := s.taskStore.Update(ctx, task); err != nil {
+				return nil, fmt.Errorf("failed to anonymize task %s: %w", task.ID, err)
+			}
+			erasure.Tasks++
+			continue
+		}
+		// Journal the deletion first, like collecting an expired task, so recovering
+		// the journal keeps the task's charge
+		if s.usageJournal != nil {
+			entry := cost.JournalEntry{Type: cost.EntryCollected, UserID: task.UserID, TaskID: task.ID}
+			if err := s.usageJournal.Append(ctx, entry); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.taskStore.Delete(ctx, task.ID); err != nil && !errors.Is(err, tasks.ErrTaskNotFound) {
+			return nil, fmt.Errorf("failed to delete task %s: %w", task.ID, err)
+		}
+		s.deleteArtifactBlobs(ctx, task)
+		erasure.Tasks++
+	}
+
+	if erasure.Usage, err = s.costTracker.EraseUsage(ctx, userID, pseudonym); err != nil {
+		return nil, err
+	}
+
+	userSchedules, err := s.userSchedules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range userSchedules {
+		if pseudonym != "" {
+			schedule.UserID = pseudonym
+			err = s.schedules.Update(ctx, schedule)
+		} else {
+			err = s.schedules.Delete(ctx, schedule.ID)
+		}
+		if err != nil && !errors.Is(err, schedules.ErrScheduleNotFound) {
+			return nil, fmt.Errorf("failed to erase schedule %s: %w", schedule.ID, err)
+		}
+		erasure.Schedules++
+	}
+	return erasure, nil
+}
+
+// userTasks lists all of the user's tasks
+func (s *Server) userTasks(ctx context.Context, userID string) ([]*protocol.Task, error) {
+	all := []*protocol.Task{}
+	for offset := 0; ; offset += gcBatchSize {
+		page, err := s.taskStore.List(ctx, tasks.ListFilter{UserID: userID}, gcBatchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		all = append(all, page...)
+		if len(page) < gcBatchSize {
+			return all, nil
+		}
+	}
+}
+
+// userSchedules lists all of the user's schedules, none while schedules are disabled
+func (s *Server) userSchedules(ctx context.Context, userID string) ([]*schedules.Schedule, error) {
+	all := []*schedules.Schedule{}
+	if s.schedules == nil {
+		return all, nil
+	}
+	for offset := 0; ; offset += scheduleBatchSize {
+		page, err := s.schedules.List(ctx, userID, scheduleBatchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schedules: %w", err)
+		}
+		all = append(all, page...)
+		if len(page) < scheduleBatchSize {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/schedules"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_UserData(t *testing.T) {
+	server := setupTestServer()
+	server.SetSchedules(schedules.NewMemoryStore())
+	ctx := context.Background()
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		server.handleUserData(rr, req)
+		return rr
+	}
+	export := func(userID string) UserData {
+		rr := serve(http.MethodGet, AdminUsersPath+userID+"/data", "secret")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var data UserData
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&data))
+		return data
+	}
+	erase := func(path string) UserErasure {
+		rr := serve(http.MethodDelete, path, "secret")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var erasure UserErasure
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&erasure))
+		return erasure
+	}
+
+	// Disabled until an admin token is configured
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, AdminUsersPath+"alice/data", "").Code)
+	server.SetAdminToken("secret")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, AdminUsersPath+"alice/data", "wrong").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, AdminUsersPath+"alice", "secret").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, AdminUsersPath+"alice/data", "secret").Code)
+
+	createTask := func(userID string) *protocol.Task {
+		task := protocol.NewTask("test-agent", "search", map[string]interface{}{"query": "private"})
+		task.UserID = userID
+		task.SetResult(map[string]interface{}{"answer": 42})
+		require.NoError(t, server.taskStore.Create(ctx, task))
+		require.NoError(t, server.costTracker.RecordUsage(ctx, cost.Usage{UserID: userID, TaskID: task.ID, CostUSD: 0.1}))
+		return task
+	}
+	for _, userID := range []string{"alice", "bob", "carol"} {
+		createTask(userID)
+		require.NoError(t, server.schedules.Create(ctx, schedules.NewSchedule(userID, "test-agent", "search")))
+	}
+	running := protocol.NewTask("test-agent", "search", nil)
+	running.UserID = "alice"
+	running.UpdateState(protocol.TaskStateRunning)
+	require.NoError(t, server.taskStore.Create(ctx, running))
+
+	data := export("alice")
+	assert.Len(t, data.Tasks, 2)
+	assert.Len(t, data.Usage, 1)
+	assert.Len(t, data.Schedules, 1)
+
+	// Users are not erased while their tasks run
+	assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, AdminUsersPath+"alice/data", "secret").Code)
+	running.SetResult(nil)
+	require.NoError(t, server.taskStore.Update(ctx, running))
+
+	assert.Equal(t, UserErasure{Tasks: 2, Usage: 1, Schedules: 1}, erase(AdminUsersPath+"alice/data"))
+	assert.Equal(t, UserData{Tasks: []*protocol.Task{}, Usage: []cost.Usage{}, Schedules: []*schedules.Schedule{}}, export("alice"))
+	_, err := server.taskStore.Get(ctx, running.ID)
+	assert.ErrorIs(t, err, tasks.ErrTaskNotFound)
+
+	// Anonymizing hands the user's data to the pseudonym
+	assert.Equal(t, UserErasure{Tasks: 1, Usage: 1, Schedules: 1}, erase(AdminUsersPath+"bob/data?pseudonym=erased-1"))
+	assert.Empty(t, export("bob").Tasks)
+	anonymized := export("erased-1")
+	require.Len(t, anonymized.Tasks, 1)
+	assert.Equal(t, map[string]interface{}{"query": "private"}, anonymized.Tasks[0].Input, "only the user is replaced")
+	assert.Len(t, anonymized.Usage, 1)
+	assert.Len(t, anonymized.Schedules, 1)
+
+	// Other users keep their data
+	data = export("carol")
+	assert.Len(t, data.Tasks, 1)
+	assert.Len(t, data.Usage, 1)
+	assert.Len(t, data.Schedules, 1)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// resolveVersion returns the version of the capability a task requested runs: the one
+// requested, or the capability's default version. Requesting a version the agent does not
+// offer is an error; capabilities the agent does not list run unversioned.
+func resolveVersion(ctx context.Context, card *protocol.AgentCard, req CreateTaskRequest) (string, error) {
+	capability, ok := card.CapabilityVersion(req.Capability, req.CapabilityVersion)
+	if !ok {
+		if req.CapabilityVersion != "" {
+			return "", fmt.Errorf("%w: %s has no version %q", errUnknownVersion, req.Capability, req.CapabilityVersion)
+		}
+		return "", nil
+	}
+	if capability.Deprecated {
+		slog.WarnContext(ctx, "Deprecated capability version requested", "agent_id", card.ID,
+			"capability", capability.Name, "version", capability.Version)
+	}
+	return capability.Version, nil
+}
+
+// deprecationWarning returns the warning for callers of a task running a deprecated
+// capability version, or "" when its version is current
+func (s *Server) deprecationWarning(ctx context.Context, task *protocol.Task) string {
+	card, err := s.agentStore.Get(ctx, task.AgentID)
+	if err != nil {
+		return ""
+	}
+	capability, ok := card.CapabilityVersion(task.Capability, task.CapabilityVersion)
+	if !ok {
+		return ""
+	}
+	return capability.DeprecationWarning()
+}
+
+// writeDeprecationWarning marks a response as using a deprecated capability version with
+// the Deprecation header and a Warning header carrying the warning (299, a persistent
+// warning)
+func writeDeprecationWarning(w http.ResponseWriter, warning string) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Warning", "299 - "+strconv.Quote(warning))
+}
+
+// a2aTask converts a task created or continued on the JSON-RPC endpoint, listing the
+// deprecation warning of its capability version in its metadata under "warnings"
+func (s *Server) a2aTask(ctx context.Context, task *protocol.Task) protocol.A2ATask {
+	a2aTask := protocol.ToA2ATask(task)
+	if warning := s.deprecationWarning(ctx, task); warning != "" {
+		a2aTask.Metadata["warnings"] = []string{warning}
+	}
+	return a2aTask
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupVersionedServer offers summarize at a deprecated version 1 and a current version 2
+func setupVersionedServer(t *testing.T) (*Server, *capabilities.Registry) {
+	server := setupTestServer()
+	ctx := context.Background()
+
+	v1 := protocol.Capability{Name: "summarize", Version: "1", Deprecated: true, DeprecationMessage: "use version 2"}
+	v2 := protocol.Capability{Name: "summarize", Version: "2"}
+	card := protocol.NewAgentCard("test-agent", "Test", "1.0.0", "Test")
+	card.AddCapability(v1)
+	card.AddCapability(v2)
+	require.NoError(t, server.agentStore.Register(ctx, card))
+	require.NoError(t, server.budgetManager.SetBudget(ctx, "user-1", 10.0))
+
+	executors := capabilities.NewRegistry()
+	for _, capability := range []protocol.Capability{v1, v2} {
+		version := capability.Version
+		executors.Register(capability, capabilities.ExecutorFunc(func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+			return &capabilities.Result{Output: map[string]interface{}{"version": version}}, nil
+		}))
+	}
+	return server, executors
+}
+
+func TestServer_CreateTask_CapabilityVersion(t *testing.T) {
+	server, executors := setupVersionedServer(t)
+	ctx := context.Background()
+	processor := NewTaskProcessor(server.taskStore, time.Hour)
+	processor.SetExecutors(executors, nil)
+
+	create := func(version string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"user_id":            "user-1",
+			"agent_id":           "test-agent",
+			"capability":         "summarize",
+			"capability_version": version,
+		})
+		rr := httptest.NewRecorder()
+		server.handleCreateTask(rr, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(body)))
+		return rr
+	}
+	run := func(rr *httptest.ResponseRecorder) *protocol.Task {
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var task protocol.Task
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+		stored, err := server.taskStore.Get(ctx, task.ID)
+		require.NoError(t, err)
+		processor.processTask(ctx, stored)
+		require.Equal(t, protocol.TaskStateCompleted, stored.State, stored.Error)
+		return stored
+	}
+
+	// Without a version the task runs, and stays on, the latest version
+	rr := create("")
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+	task := run(rr)
+	assert.Equal(t, "2", task.CapabilityVersion)
+	assert.Equal(t, "2", task.Result["output"].(map[string]interface{})["version"])
+
+	// A deprecated version still runs, with a warning
+	rr = create("1")
+	assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+	assert.Equal(t, `299 - "capability summarize version 1 is deprecated: use version 2"`, rr.Header().Get("Warning"))
+	task = run(rr)
+	assert.Equal(t, "1", task.CapabilityVersion)
+	assert.Equal(t, "1", task.Result["output"].(map[string]interface{})["version"])
+
+	rr = create("3")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `summarize has no version "3"`)
+}
+
+func TestJSONRPC_CapabilityVersion(t *testing.T) {
+	server, _ := setupVersionedServer(t)
+
+	send := func(version string) rpcResponse {
+		return callJSONRPC(t, server, `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{
+			"message":{"kind":"message","role":"user","messageId":"m-1","parts":[{"kind":"text","text":"report"}]},
+			"metadata":{"capability":"summarize","capability_version":"`+version+`","user_id":"user-1"}}}`)
+	}
+
+	resp := send("1")
+	require.Nil(t, resp.Error)
+	var task protocol.A2ATask
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	assert.Equal(t, "1", task.Metadata["capability_version"])
+	assert.Equal(t, []interface{}{"capability summarize version 1 is deprecated: use version 2"}, task.Metadata["warnings"])
+
+	resp = send("2")
+	require.Nil(t, resp.Error)
+	task = protocol.A2ATask{}
+	require.NoError(t, json.Unmarshal(resp.Result, &task))
+	assert.Equal(t, "2", task.Metadata["capability_version"])
+	assert.NotContains(t, task.Metadata, "warnings")
+
+	resp = send("3")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+}
@@ -0,0 +1,135 @@
+// Package speculative races substitutable capabilities against each other: the requested
+// capability and its cheapest substitutes are launched together under a cost cap, the
+// first acceptable result wins and the other attempts are cancelled.
+package speculative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// ErrNoAcceptableResult is returned when every launched attempt failed or was rejected
+var ErrNoAcceptableResult = errors.New("no speculative attempt produced an acceptable result")
+
+// Attempt runs one capability for the task; it must stop when ctx is cancelled
+type Attempt func(ctx context.Context, capability string) (map[string]interface{}, error)
+
+// Accept reports whether a result is good enough to win
+type Accept func(result map[string]interface{}) bool
+
+// Plan selects the capabilities to launch for a task: the requested capability always,
+// then its substitutes from cheapest to most expensive while the total estimated cost
+// stays within costCapUSD
+func Plan(card *protocol.AgentCard, capability string, costCapUSD float64) []protocol.Capability {
+	requested, ok := card.Capability(capability)
+	if !ok {
+		return nil
+	}
+
+	substitutes := card.Substitutes(capability)
+	sort.SliceStable(substitutes, func(i, j int) bool {
+		return substitutes[i].EstimatedCostUSD < substitutes[j].EstimatedCostUSD
+	})
+
+	plan := []protocol.Capability{requested}
+	total := requested.EstimatedCostUSD
+	for _, substitute := range substitutes {
+		if total+substitute.EstimatedCostUSD > costCapUSD {
+			break
+		}
+		plan = append(plan, substitute)
+		total += substitute.EstimatedCostUSD
+	}
+	return plan
+}
+
+// EstimatedCost returns the total estimated cost of a plan
+func EstimatedCost(plan []protocol.Capability) float64 {
+	var total float64
+	for _, c := range plan {
+		total += c.EstimatedCostUSD
+	}
+	return total
+}
+
+// attemptResult is the outcome of one launched capability
+type attemptResult struct {
+	capability string
+	result     map[string]interface{}
+	err        error
+}
+
+// Run launches every capability in the plan concurrently and returns the first result
+// accepted by accept, cancelling the attempts still running. The decision is returned
+// even when no attempt succeeds.
+func Run(ctx context.Context, plan []protocol.Capability, costCapUSD float64, attempt Attempt, accept Accept) (map[string]interface{}, *protocol.SpeculationDecision, error) {
+	start := time.Now()
+	decision := &protocol.SpeculationDecision{
+		CostCapUSD:       costCapUSD,
+		EstimatedCostUSD: EstimatedCost(plan),
+	}
+	if len(plan) == 0 {
+		return nil, decision, ErrNoAcceptableResult
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so attempts finishing after the winner never block
+	results := make(chan attemptResult, len(plan))
+	for _, c := range plan {
+		decision.Launched = append(decision.Launched, c.Name)
+		go func(capability string) {
+			result, err := attempt(ctx, capability)
+			results <- attemptResult{capability: capability, result: result, err: err}
+		}(c.Name)
+	}
+
+	pending := make(map[string]bool, len(plan))
+	for _, c := range plan {
+		pending[c.Name] = true
+	}
+
+	for range plan {
+		var r attemptResult
+		select {
+		case r = <-results:
+		case <-ctx.Done():
+			decision.LatencyMs = time.Since(start).Milliseconds()
+			return nil, decision, ctx.Err()
+		}
+		delete(pending, r.capability)
+
+		switch {
+		case r.err != nil:
+			reject(decision, r.capability, r.err.Error())
+		case !accept(r.result):
+			reject(decision, r.capability, "result not acceptable")
+		default:
+			decision.Winner = r.capability
+			for _, c := range plan {
+				if pending[c.Name] {
+					decision.Cancelled = append(decision.Cancelled, c.Name)
+				}
+			}
+			decision.LatencyMs = time.Since(start).Milliseconds()
+			return r.result, decision, nil
+		}
+	}
+
+	decision.LatencyMs = time.Since(start).Milliseconds()
+	return nil, decision, fmt.Errorf("%w (launched %d)", ErrNoAcceptableResult, len(plan))
+}
+
+// reject records why a launched capability did not win
+func reject(decision *protocol.SpeculationDecision, capability, reason string) {
+	if decision.Rejected == nil {
+		decision.Rejected = make(map[string]string)
+	}
+	decision.Rejected[capability] = reason
+}
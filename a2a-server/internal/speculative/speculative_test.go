@@ -0,0 +1,130 @@
+package speculative
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCard() *protocol.AgentCard {
+	card := protocol.NewAgentCard("agent-1", "Test Agent", "1.0.0", "A test agent")
+	card.AddCapability(protocol.Capability{Name: "summarize", SubstituteGroup: "summary", EstimatedCostUSD: 0.01})
+	card.AddCapability(protocol.Capability{Name: "summarize_premium", SubstituteGroup: "summary", EstimatedCostUSD: 0.05})
+	card.AddCapability(protocol.Capability{Name: "summarize_fast", SubstituteGroup: "summary", EstimatedCostUSD: 0.004})
+	card.AddCapability(protocol.Capability{Name: "analyze", EstimatedCostUSD: 0.01})
+	return card
+}
+
+func names(plan []protocol.Capability) []string {
+	var out []string
+	for _, c := range plan {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+func TestPlan(t *testing.T) {
+	card := newCard()
+
+	tests := []struct {
+		name       string
+		capability string
+		costCap    float64
+		want       []string
+	}{
+		{"cheapest substitute under cap", "summarize", 0.02, []string{"summarize", "summarize_fast"}},
+		{"all substitutes under cap", "summarize", 0.1, []string{"summarize", "summarize_fast", "summarize_premium"}},
+		{"requested only when cap is exhausted", "summarize", 0.01, []string{"summarize"}},
+		{"requested always included", "summarize_premium", 0.01, []string{"summarize_premium"}},
+		{"no substitute group", "analyze", 1, []string{"analyze"}},
+		{"unknown capability", "missing", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(Plan(card, tt.capability, tt.costCap)))
+		})
+	}
+}
+
+func TestRun_FirstAcceptableWinsAndCancelsRest(t *testing.T) {
+	plan := Plan(newCard(), "summarize", 0.02)
+	require.Len(t, plan, 2)
+
+	cancelled := make(chan string, 1)
+	attempt := func(ctx context.Context, capability string) (map[string]interface{}, error) {
+		if capability == "summarize_fast" {
+			return map[string]interface{}{"status": "success", "capability": capability}, nil
+		}
+		<-ctx.Done()
+		cancelled <- capability
+		return nil, ctx.Err()
+	}
+	accept := func(result map[string]interface{}) bool { return result["status"] == "success" }
+
+	result, decision, err := Run(context.Background(), plan, 0.02, attempt, accept)
+	require.NoError(t, err)
+
+	assert.Equal(t, "summarize_fast", result["capability"])
+	assert.Equal(t, []string{"summarize", "summarize_fast"}, decision.Launched)
+	assert.Equal(t, "summarize_fast", decision.Winner)
+	assert.Equal(t, []string{"summarize"}, decision.Cancelled)
+	assert.InDelta(t, 0.014, decision.EstimatedCostUSD, 1e-9)
+	assert.Equal(t, 0.02, decision.CostCapUSD)
+
+	select {
+	case name := <-cancelled:
+		assert.Equal(t, "summarize", name)
+	case <-time.After(time.Second):
+		t.Fatal("losing attempt was not cancelled")
+	}
+}
+
+func TestRun_SkipsFailedAndUnacceptableResults(t *testing.T) {
+	plan := Plan(newCard(), "summarize", 0.1)
+	require.Len(t, plan, 3)
+
+	attempt := func(ctx context.Context, capability string) (map[string]interface{}, error) {
+		switch capability {
+		case "summarize_fast":
+			return nil, errors.New("boom")
+		case "summarize":
+			return map[string]interface{}{"status": "partial"}, nil
+		default:
+			time.Sleep(20 * time.Millisecond)
+			return map[string]interface{}{"status": "success"}, nil
+		}
+	}
+	accept := func(result map[string]interface{}) bool { return result["status"] == "success" }
+
+	_, decision, err := Run(context.Background(), plan, 0.1, attempt, accept)
+	require.NoError(t, err)
+
+	assert.Equal(t, "summarize_premium", decision.Winner)
+	assert.Empty(t, decision.Cancelled)
+	assert.Equal(t, map[string]string{
+		"summarize_fast": "boom",
+		"summarize":      "result not acceptable",
+	}, decision.Rejected)
+}
+
+func TestRun_NoAcceptableResult(t *testing.T) {
+	plan := Plan(newCard(), "summarize", 0.02)
+
+	attempt := func(ctx context.Context, capability string) (map[string]interface{}, error) {
+		return nil, errors.New("unavailable")
+	}
+	accept := func(result map[string]interface{}) bool { return true }
+
+	result, decision, err := Run(context.Background(), plan, 0.02, attempt, accept)
+	require.ErrorIs(t, err, ErrNoAcceptableResult)
+
+	assert.Nil(t, result)
+	assert.Empty(t, decision.Winner)
+	assert.Len(t, decision.Rejected, 2)
+}
@@ -0,0 +1,93 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// Archive keeps the tasks garbage collected from a store. A task is archived before it
+// is deleted, so one whose deletion failed may be archived again.
+type Archive interface {
+	Archive(ctx context.Context, task *protocol.Task) error
+}
+
+// FileArchive is an Archive kept in an append-only file of JSON lines, one task per line,
+// fsynced after every task
+type FileArchive struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+var _ Archive = (*FileArchive)(nil)
+
+// OpenFileArchive opens or creates the archive at path. A partial last line, left by a
+// crash during a write, is truncated: its task was not deleted and is archived again.
+func OpenFileArchive(path string) (*FileArchive, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task archive: %w", err)
+	}
+	valid, err := completeLines(file)
+	if err == nil {
+		err = file.Truncate(valid)
+	}
+	if err == nil {
+		_, err = file.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to recover task archive: %w", err)
+	}
+	return &FileArchive{file: file}, nil
+}
+
+// completeLines returns the size of the file up to the end of its last complete line
+func completeLines(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	chunk := make([]byte, 4096)
+	for end := info.Size(); end > 0; {
+		start := max(end-int64(len(chunk)), 0)
+		n, err := file.ReadAt(chunk[:end-start], start)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Archive implements Archive
+func (a *FileArchive) Archive(ctx context.Context, task *protocol.Task) error {
+	line, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task %s: %w", task.ID, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write task archive: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync task archive: %w", err)
+	}
+	return nil
+}
+
+// Close closes the archive file
+func (a *FileArchive) Close() error {
+	return a.file.Close()
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileArchive_AppendsAndTruncatesTornWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+
+	first := protocol.NewTask("agent-1", "search", map[string]interface{}{"query": strings.Repeat("go", 3000)})
+	first.SetResult(map[string]interface{}{"answer": 42})
+	archive, err := OpenFileArchive(path)
+	require.NoError(t, err)
+	require.NoError(t, archive.Archive(ctx, first))
+	require.NoError(t, archive.Close())
+
+	// A crash in the middle of a write leaves a partial line behind
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"id":"torn","agent_id":"age`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	second := protocol.NewTask("agent-1", "search", nil)
+	second.Cancel("no longer needed")
+	archive, err = OpenFileArchive(path)
+	require.NoError(t, err)
+	require.NoError(t, archive.Archive(ctx, second))
+	require.NoError(t, archive.Close())
+
+	file, err = os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	var archived []protocol.Task
+	for scanner.Scan() {
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &task))
+		archived = append(archived, task)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, archived, 2)
+	assert.Equal(t, first.ID, archived[0].ID)
+	assert.Equal(t, float64(42), archived[0].Result["answer"])
+	assert.Equal(t, second.ID, archived[1].ID)
+	assert.Equal(t, protocol.TaskStateCancelled, archived[1].State)
+}
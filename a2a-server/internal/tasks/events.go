@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// eventHub implements the event half of Store: per-task subscribers and a bounded
+// history of recent events. Stores embed it so events work the same whatever holds
+// the tasks.
+type eventHub struct {
+	mu          sync.RWMutex
+	subscribers map[string][]chan protocol.TaskEvent
+	events      map[string][]protocol.TaskEvent // recent events per task, oldest first
+	lastEventID map[string]int64
+}
+
+func newEventHub() eventHub {
+	return eventHub{
+		subscribers: make(map[string][]chan protocol.TaskEvent),
+		events:      make(map[string][]protocol.TaskEvent),
+		lastEventID: make(map[string]int64),
+	}
+}
+
+// Subscribe subscribes to task events
+func (h *eventHub) Subscribe(ctx context.Context, taskID string) <-chan protocol.TaskEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan protocol.TaskEvent, 10)
+	h.subscribers[taskID] = append(h.subscribers[taskID], ch)
+	return ch
+}
+
+// Unsubscribe unsubscribes from task events
+func (h *eventHub) Unsubscribe(ctx context.Context, taskID string, ch <-chan protocol.TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscribers := h.subscribers[taskID]
+	for i, sub := range subscribers {
+		if sub == ch {
+			// Remove from slice
+			h.subscribers[taskID] = append(subscribers[:i], subscribers[i+1:]...)
+			close(sub)
+			break
+		}
+	}
+
+	// Clean up empty subscriber list
+	if len(h.subscribers[taskID]) == 0 {
+		delete(h.subscribers, taskID)
+	}
+}
+
+// PublishEvent publishes an event to all subscribers
+func (h *eventHub) PublishEvent(ctx context.Context, event protocol.TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastEventID[event.TaskID]++
+	event.ID = h.lastEventID[event.TaskID]
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	history := append(h.events[event.TaskID], event)
+	if len(history) > MaxEventHistory {
+		history = history[len(history)-MaxEventHistory:]
+	}
+	h.events[event.TaskID] = history
+
+	subscribers := h.subscribers[event.TaskID]
+	for _, ch := range subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Skip if channel is full; the subscriber can catch up with Events
+		}
+	}
+}
+
+// relay publishes an event another replica published to the task's subscribers. Events
+// of tasks nobody follows here are dropped rather than retained for every task.
+func (h *eventHub) relay(ctx context.Context, event protocol.TaskEvent) {
+	h.mu.RLock()
+	subscribed := len(h.subscribers[event.TaskID]) > 0
+	h.mu.RUnlock()
+	if subscribed {
+		h.PublishEvent(ctx, event)
+	}
+}
+
+// Events returns the retained events of a task after afterID
+func (h *eventHub) Events(ctx context.Context, taskID string, afterID int64) []protocol.TaskEvent {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	history := h.events[taskID]
+	i := sort.Search(len(history), func(i int) bool { return history[i].ID > afterID })
+	return append([]protocol.TaskEvent(nil), history[i:]...)
+}
+
+// forget drops the event history of a deleted task
+func (h *eventHub) forget(taskID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.events, taskID)
+	delete(h.lastEventID, taskID)
+}
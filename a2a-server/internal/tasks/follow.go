@@ -0,0 +1,71 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// Follow calls handle with the task's events after afterID, then with live events,
+// until the final event, handle returns false, stop is closed or ctx is done. Events
+// dropped by a slow subscription are filled in from the store's event history, and a
+// task that finished without a retained final event gets one built from its stored state.
+func Follow(ctx context.Context, store Store, taskID string, afterID int64, stop <-chan struct{}, handle func(protocol.TaskEvent) bool) {
+	// Subscribe before reading the history so no event falls between the two
+	eventCh := store.Subscribe(ctx, taskID)
+	defer store.Unsubscribe(ctx, taskID, eventCh)
+
+	last := afterID
+	send := func(events ...protocol.TaskEvent) (done bool) {
+		for _, event := range events {
+			if event.ID != 0 && event.ID <= last {
+				continue
+			}
+			if !handle(event) {
+				return true
+			}
+			if event.ID != 0 {
+				last = event.ID
+			}
+			if event.Final() {
+				return true
+			}
+		}
+		return false
+	}
+
+	if send(store.Events(ctx, taskID, last)...) {
+		return
+	}
+
+	// A finished task gets the events published since, then a final event built from its
+	// stored state if its own final event is not published yet or has aged out of the history
+	if task, err := store.Get(ctx, taskID); err == nil && task.State.IsTerminal() {
+		if !send(store.Events(ctx, taskID, last)...) {
+			send(protocol.TaskEvent{TaskID: taskID, State: task.State, Message: task.Error, Timestamp: task.UpdatedAt})
+		}
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			events := []protocol.TaskEvent{event}
+			if event.ID > last+1 {
+				events = store.Events(ctx, taskID, last) // includes event
+			}
+			if send(events...) {
+				return
+			}
+
+		case <-stop:
+			return
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,556 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresConfig holds the connection settings of a PostgresStore
+type PostgresConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+	MaxConns int32
+	MinConns int32
+	// MigrationUser and MigrationPassword, when set, are the schema owner's credentials
+	// that migrations run with; the application user may lack the privileges
+	MigrationUser     string
+	MigrationPassword string
+}
+
+// PostgresStore keeps tasks in the Postgres tasks table (see scripts/apply-a2a-tasks.sql),
+// so they survive restarts, and their state transitions in the task_history table. Other
+// events are not persisted: subscribers and the event history for resuming streams stay
+// in process, shared between replicas by RedisEvents, and streams of tasks that finished
+// before a restart get a final event built from the stored state.
+type PostgresStore struct {
+	eventHub
+
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// taskColumns lists the tasks table columns in the order scanTask reads them
+const taskColumns = `id, agent_id, context_id, user_id, capability, state, input, result, error,
+	input_hash, result_hash, speculative, speculation, created_at, updated_at, completed_at, trace_context, priority, depends_on, max_cost_usd::float8, artifacts,
+	attempts, lease, ttl_seconds, capability_version, message, output`
+
+// NewPostgresStore connects to Postgres and returns a task store backed by it
+func NewPostgresStore(ctx context.Context, cfg PostgresConfig) (*PostgresStore, error) {
+	pool, err := Connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &PostgresStore{eventHub: newEventHub(), pool: pool}, nil
+}
+
+// ConnectForMigrations opens a single connection for schema migrations, as the migration
+// user when one is configured
+func ConnectForMigrations(ctx context.Context, cfg PostgresConfig) (*pgx.Conn, error) {
+	user, password := cfg.User, cfg.Password
+	if cfg.MigrationUser != "" {
+		user, password = cfg.MigrationUser, cfg.MigrationPassword
+	}
+	conn, err := pgx.Connect(ctx, fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, user, password, cfg.DBName, cfg.SSLMode,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect for migrations: %w", err)
+	}
+	return conn, nil
+}
+
+// Connect opens a connection pool to the A2A server's database, which other Postgres
+// backends, such as the cost tracker's, use as well
+func Connect(ctx context.Context, cfg PostgresConfig) (*pgxpool.Pool, error) {
+	connString := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
+	)
+
+	poolConfig, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = time.Hour
+	poolConfig.MaxConnIdleTime = 30 * time.Minute
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return pool, nil
+}
+
+// Close closes the connection pool
+func (s *PostgresStore) Close() {
+	s.pool.Close()
+}
+
+// Ping checks that the database answers
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// Create creates a new task
+func (s *PostgresStore) Create(ctx context.Context, task *protocol.Task) error {
+	if err := task.Seal(); err != nil {
+		return err
+	}
+	args, err := taskArgs(task)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, `INSERT INTO tasks (`+taskColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)`, args...)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+		return fmt.Errorf("task %s already exists", task.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert task: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a task by ID
+func (s *PostgresStore) Get(ctx context.Context, id string) (*protocol.Task, error) {
+	row := s.pool.QueryRow(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = $1`, id)
+	task, err := scanTask(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := task.VerifyIntegrity(); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Update updates an existing task
+func (s *PostgresStore) Update(ctx context.Context, task *protocol.Task) error {
+	if err := task.Seal(); err != nil {
+		return err
+	}
+	args, err := taskArgs(task)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.pool.Exec(ctx, updateTask+` WHERE id = $1 AND state = ANY($28)`,
+		append(args, previousStates(task.State))...)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return s.unmatched(ctx, task.ID, fmt.Errorf("%w: task %s cannot become %s", ErrInvalidTransition, task.ID, task.State))
+	}
+	return nil
+}
+
+// previousStates lists the states a task may move to state from, as query arguments
+func previousStates(state protocol.TaskState) []string {
+	var states []string
+	for _, previous := range state.PreviousStates() {
+		states = append(states, string(previous))
+	}
+	return states
+}
+
+// updateTask sets every column but id from the arguments of taskArgs
+const updateTask = `UPDATE tasks SET
+		agent_id = $2, context_id = $3, user_id = $4, capability = $5, state = $6, input = $7,
+		result = $8, error = $9, input_hash = $10, result_hash = $11, speculative = $12,
+		speculation = $13, created_at = $14, updated_at = $15, completed_at = $16, trace_context = $17,
+		priority = $18, depends_on = $19, max_cost_usd = $20, artifacts = $21, attempts = $22, lease = $23,
+		ttl_seconds = $24, capability_version = $25, message = $26, output = $27`
+
+// UpdateLeased updates a task while it is running under owner's lease
+func (s *PostgresStore) UpdateLeased(ctx context.Context, task *protocol.Task, owner string) error {
+	if !protocol.TaskStateRunning.CanTransitionTo(task.State) {
+		return fmt.Errorf("%w: running task %s cannot become %s", ErrInvalidTransition, task.ID, task.State)
+	}
+	if err := task.Seal(); err != nil {
+		return err
+	}
+	args, err := taskArgs(task)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.pool.Exec(ctx, updateTask+` WHERE id = $1 AND state = 'running' AND lease->>'owner' = $28`,
+		append(args, owner)...)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return s.leaseLost(ctx, task.ID)
+	}
+	return nil
+}
+
+// RenewLease moves the expiry of owner's lease on a running task
+func (s *PostgresStore) RenewLease(ctx context.Context, id, owner string, expiresAt time.Time) error {
+	lease, err := json.Marshal(protocol.TaskLease{Owner: owner, HeartbeatAt: time.Now(), ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to encode task lease: %w", err)
+	}
+	tag, err := s.pool.Exec(ctx, `UPDATE tasks SET lease = $3
+		WHERE id = $1 AND state = 'running' AND lease->>'owner' = $2`, id, owner, lease)
+	if err != nil {
+		return fmt.Errorf("failed to renew task lease: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return s.leaseLost(ctx, id)
+	}
+	return nil
+}
+
+// leaseLost returns why a lease-conditioned update matched no row: the task is gone, or
+// it is no longer running under the lease
+func (s *PostgresStore) leaseLost(ctx context.Context, id string) error {
+	return s.unmatched(ctx, id, fmt.Errorf("%w: %s", ErrLeaseLost, id))
+}
+
+// unmatched returns why a conditional update matched no row: ErrTaskNotFound when the
+// task is gone, otherwise err, the condition that did not hold
+func (s *PostgresStore) unmatched(ctx context.Context, id string, err error) error {
+	var exists bool
+	if err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check task: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	return err
+}
+
+// Delete deletes a task
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM tasks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	s.forget(id)
+	return nil
+}
+
+// List lists the tasks matching filter in its order
+func (s *PostgresStore) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*protocol.Task, error) {
+	where, args := filterClause(filter)
+	query := `SELECT ` + taskColumns + ` FROM tasks` + where
+	args = append(args, limit, offset)
+	order := "created_at, id"
+	if filter.Descending {
+		order = "created_at DESC, id DESC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, len(args)-1, len(args))
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*protocol.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return list, nil
+}
+
+// PublishEvent records a state transition in the task_history table, then publishes the
+// event to the task's subscribers. A transition that cannot be recorded is still published.
+func (s *PostgresStore) PublishEvent(ctx context.Context, event protocol.TaskEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Transition() {
+		if err := s.recordTransition(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "Error recording task history", "task_id", event.TaskID, "error", err)
+		}
+	}
+	s.eventHub.PublishEvent(ctx, event)
+}
+
+// recordTransition inserts a state transition into the task_history table
+func (s *PostgresStore) recordTransition(ctx context.Context, event protocol.TaskEvent) error {
+	data, err := marshalJSONB(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, `INSERT INTO task_history (task_id, state, message, worker_id, data, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		event.TaskID, string(event.State), event.Message, event.WorkerID, data, event.Timestamp)
+	return err
+}
+
+// History returns the state transitions of a task from the task_history table
+func (s *PostgresStore) History(ctx context.Context, taskID string) ([]protocol.TaskEvent, error) {
+	rows, err := s.pool.Query(ctx, `SELECT state, message, worker_id, data, recorded_at
+		FROM task_history WHERE task_id = $1 ORDER BY id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []protocol.TaskEvent{}
+	for rows.Next() {
+		event := protocol.TaskEvent{TaskID: taskID}
+		var state string
+		var data []byte
+		if err := rows.Scan(&state, &event.Message, &event.WorkerID, &data, &event.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan task history: %w", err)
+		}
+		event.State = protocol.TaskState(state)
+		if data != nil {
+			if err := json.Unmarshal(data, &event.Data); err != nil {
+				return nil, fmt.Errorf("failed to decode task history data: %w", err)
+			}
+		}
+		history = append(history, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read task history: %w", err)
+	}
+	return history, nil
+}
+
+// Count counts the tasks matching filter
+func (s *PostgresStore) Count(ctx context.Context, filter ListFilter) (int, error) {
+	where, args := filterClause(filter)
+	var count int
+	if err := s.pool.QueryRow(ctx, `SELECT count(*) FROM tasks`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return count, nil
+}
+
+// Expired lists the terminal tasks whose retention elapsed at now, first finished first
+func (s *PostgresStore) Expired(ctx context.Context, now time.Time, retention time.Duration, limit, offset int) ([]*protocol.Task, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+taskColumns+` FROM tasks
+		WHERE state IN ('completed', 'failed', 'cancelled')
+			AND (CASE WHEN ttl_seconds > 0 THEN ttl_seconds ELSE $2::float8 END) > 0
+			AND COALESCE(completed_at, updated_at)
+				+ make_interval(secs => CASE WHEN ttl_seconds > 0 THEN ttl_seconds ELSE $2::float8 END) <= $1
+		ORDER BY COALESCE(completed_at, updated_at), id LIMIT $3 OFFSET $4`,
+		now, retention.Seconds(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired tasks: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*protocol.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list expired tasks: %w", err)
+	}
+	return list, nil
+}
+
+// filterClause returns the WHERE clause selecting the tasks matching filter, empty when
+// it matches every task, and its arguments
+func filterClause(filter ListFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	where := func(column, value string) {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+	}
+	where("agent_id", filter.AgentID)
+	where("state", string(filter.State))
+	where("user_id", filter.UserID)
+	if filter.DependsOn != "" {
+		args = append(args, filter.DependsOn)
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(depends_on)", len(args)))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		args = append(args, filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at > $%d", len(args)))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		args = append(args, filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		operator := ">"
+		if filter.Descending {
+			operator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) %s ($%d, $%d)", operator, len(args)-1, len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// taskArgs returns the column values of a task in taskColumns order
+func taskArgs(task *protocol.Task) ([]interface{}, error) {
+	input, err := marshalJSONB(task.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task input: %w", err)
+	}
+	result, err := marshalJSONB(task.Result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task result: %w", err)
+	}
+	speculation, err := marshalJSONB(task.Speculation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task speculation: %w", err)
+	}
+	traceContext, err := marshalJSONB(task.TraceContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task trace context: %w", err)
+	}
+	artifacts, err := marshalJSONB(task.Artifacts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task artifacts: %w", err)
+	}
+	lease, err := marshalJSONB(task.Lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task lease: %w", err)
+	}
+	message, err := marshalJSONB(task.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task message: %w", err)
+	}
+	output, err := marshalJSONB(task.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task output: %w", err)
+	}
+
+	var completedAt *time.Time
+	if !task.CompletedAt.IsZero() {
+		completedAt = &task.CompletedAt
+	}
+
+	return []interface{}{
+		task.ID, task.AgentID, task.ContextID, task.UserID, task.Capability, string(task.State),
+		input, result, task.Error, task.InputHash, task.ResultHash, task.Speculative, speculation,
+		task.CreatedAt, task.UpdatedAt, completedAt, traceContext, string(task.Priority),
+		task.DependsOn, task.MaxCostUSD, artifacts, task.Attempts, lease, task.TTLSeconds,
+		task.CapabilityVersion, message, output,
+	}, nil
+}
+
+// marshalJSONB encodes a value for a JSONB column, storing NULL for nil values
+func marshalJSONB[T any](v T) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
+
+// scanTask reads a task selected with taskColumns
+func scanTask(row pgx.Row) (*protocol.Task, error) {
+	var task protocol.Task
+	var state, priority string
+	var input, result, speculation, traceContext, artifacts, lease, message, output []byte
+	var completedAt *time.Time
+
+	err := row.Scan(&task.ID, &task.AgentID, &task.ContextID, &task.UserID, &task.Capability, &state,
+		&input, &result, &task.Error, &task.InputHash, &task.ResultHash, &task.Speculative, &speculation,
+		&task.CreatedAt, &task.UpdatedAt, &completedAt, &traceContext, &priority, &task.DependsOn, &task.MaxCostUSD, &artifacts,
+		&task.Attempts, &lease, &task.TTLSeconds, &task.CapabilityVersion, &message, &output)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan task: %w", err)
+	}
+
+	task.State = protocol.TaskState(state)
+	task.Priority = protocol.TaskPriority(priority)
+	if completedAt != nil {
+		task.CompletedAt = *completedAt
+	}
+	if input != nil {
+		if err := json.Unmarshal(input, &task.Input); err != nil {
+			return nil, fmt.Errorf("failed to decode task input: %w", err)
+		}
+	}
+	if result != nil {
+		if err := json.Unmarshal(result, &task.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode task result: %w", err)
+		}
+	}
+	if speculation != nil {
+		if err := json.Unmarshal(speculation, &task.Speculation); err != nil {
+			return nil, fmt.Errorf("failed to decode task speculation: %w", err)
+		}
+	}
+	if traceContext != nil {
+		if err := json.Unmarshal(traceContext, &task.TraceContext); err != nil {
+			return nil, fmt.Errorf("failed to decode task trace context: %w", err)
+		}
+	}
+	if artifacts != nil {
+		if err := json.Unmarshal(artifacts, &task.Artifacts); err != nil {
+			return nil, fmt.Errorf("failed to decode task artifacts: %w", err)
+		}
+	}
+	if lease != nil {
+		if err := json.Unmarshal(lease, &task.Lease); err != nil {
+			return nil, fmt.Errorf("failed to decode task lease: %w", err)
+		}
+	}
+	if message != nil {
+		if err := json.Unmarshal(message, &task.Message); err != nil {
+			return nil, fmt.Errorf("failed to decode task message: %w", err)
+		}
+	}
+	if output != nil {
+		if err := json.Unmarshal(output, &task.Output); err != nil {
+			return nil, fmt.Errorf("failed to decode task output: %w", err)
+		}
+	}
+	return &task, nil
+}
@@ -0,0 +1,285 @@
+//go:build integration
+// +build integration
+
+package tasks
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests for the Postgres task store; the tasks table comes from init-db.sql
+// Run with: go test -tags=integration -v ./internal/tasks/
+
+func getTestDBConfig() PostgresConfig {
+	port, _ := strconv.Atoi(getEnvOrDefault("DB_PORT", "5432"))
+	return PostgresConfig{
+		Host:     getEnvOrDefault("DB_HOST", "localhost"),
+		Port:     port,
+		User:     getEnvOrDefault("DB_USER", "app_user"),
+		Password: getEnvOrDefault("DB_PASSWORD", "mcp_password"),
+		DBName:   getEnvOrDefault("DB_NAME", "mcp_db"),
+		SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
+		MaxConns: 5,
+	}
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func setupPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	store, err := NewPostgresStore(context.Background(), getTestDBConfig())
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+	return store
+}
+
+func TestPostgresStore_CRUD(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-pg", "search", map[string]interface{}{"query": "golang", "limit": 5})
+	task.UserID = "pg-user"
+	task.TraceContext = map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	task.AuthToken = "caller-token"
+	task.Priority = protocol.PriorityHigh
+	task.MaxCostUSD = 0.25
+	task.CapabilityVersion = "2"
+	require.NoError(t, store.Create(ctx, task))
+	t.Cleanup(func() { store.Delete(ctx, task.ID) })
+	assert.Error(t, store.Create(ctx, task), "duplicate IDs are rejected")
+
+	got, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pg-user", got.UserID)
+	assert.Equal(t, protocol.TaskStatePending, got.State)
+	assert.Equal(t, "golang", got.Input["query"])
+	assert.Equal(t, task.InputHash, got.InputHash)
+	assert.True(t, got.CompletedAt.IsZero())
+	assert.Equal(t, task.TraceContext, got.TraceContext)
+	assert.Equal(t, protocol.PriorityHigh, got.Priority)
+	assert.Equal(t, 0.25, got.MaxCostUSD)
+	assert.Equal(t, "2", got.CapabilityVersion)
+	require.NotNil(t, got.Message)
+	assert.Equal(t, task.Message.MessageID, got.Message.MessageID)
+	assert.Empty(t, got.AuthToken, "tokens are not persisted")
+
+	// A pending task has not run, so it cannot complete yet
+	completed := *got
+	completed.SetResult(nil)
+	assert.ErrorIs(t, store.Update(ctx, &completed), ErrInvalidTransition)
+	got.UpdateState(protocol.TaskStateRunning)
+	require.NoError(t, store.Update(ctx, got))
+
+	got.Artifacts = []protocol.Artifact{{ArtifactID: "report", Parts: []protocol.Part{
+		{Kind: protocol.KindFile, File: &protocol.FileContent{Name: "report.csv", MimeType: "text/csv", Bytes: []byte("a,b\n")}},
+	}}}
+	got.SetResult(map[string]interface{}{"answer": "yes"})
+	require.NoError(t, store.Update(ctx, got))
+
+	done, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCompleted, done.State)
+	assert.Equal(t, "yes", done.Result["answer"])
+	assert.Equal(t, got.Artifacts, done.Artifacts)
+	require.NotNil(t, done.Output)
+	assert.Equal(t, got.Output, done.Output)
+	assert.WithinDuration(t, got.CompletedAt, done.CompletedAt, time.Millisecond)
+
+	require.NoError(t, store.Delete(ctx, task.ID))
+	_, err = store.Get(ctx, task.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	assert.ErrorIs(t, store.Update(ctx, got), ErrTaskNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, task.ID), ErrTaskNotFound)
+}
+
+func TestPostgresStore_List(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+
+	agentID := "agent-pg-list-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	var created []*protocol.Task
+	for i, user := range []string{"alice", "bob", "alice"} {
+		task := protocol.NewTask(agentID, "search", nil)
+		task.UserID = user
+		task.CreatedAt = task.CreatedAt.Add(time.Duration(i) * time.Second)
+		if i == 2 {
+			task.UpdateState(protocol.TaskStateRunning)
+		}
+		require.NoError(t, store.Create(ctx, task))
+		t.Cleanup(func() { store.Delete(ctx, task.ID) })
+		created = append(created, task)
+	}
+
+	all, err := store.List(ctx, ListFilter{AgentID: agentID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, created[0].ID, all[0].ID, "oldest first")
+
+	alice, err := store.List(ctx, ListFilter{AgentID: agentID, UserID: "alice"}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, alice, 2)
+
+	running, err := store.List(ctx, ListFilter{AgentID: agentID, State: protocol.TaskStateRunning}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, running, 1)
+	assert.Equal(t, created[2].ID, running[0].ID)
+	count, err := store.Count(ctx, ListFilter{AgentID: agentID, State: protocol.TaskStateRunning})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	created[1].DependsOn = []string{created[0].ID}
+	require.NoError(t, store.Update(ctx, created[1]))
+	dependents, err := store.List(ctx, ListFilter{AgentID: agentID, DependsOn: created[0].ID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, dependents, 1)
+	assert.Equal(t, created[1].ID, dependents[0].ID)
+	assert.Equal(t, []string{created[0].ID}, dependents[0].DependsOn)
+
+	page, err := store.List(ctx, ListFilter{AgentID: agentID}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, created[1].ID, page[0].ID)
+
+	newest, err := store.List(ctx, ListFilter{AgentID: agentID, Descending: true}, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, newest, 2)
+	assert.Equal(t, created[2].ID, newest[0].ID)
+	assert.Equal(t, created[1].ID, newest[1].ID)
+	page, err = store.List(ctx, ListFilter{AgentID: agentID, Descending: true, After: CursorOf(newest[1])}, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, created[0].ID, page[0].ID)
+	page, err = store.List(ctx, ListFilter{AgentID: agentID, After: CursorOf(all[0])}, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, created[1].ID, page[0].ID)
+
+	between, err := store.List(ctx, ListFilter{AgentID: agentID, CreatedAfter: all[0].CreatedAt, CreatedBefore: all[2].CreatedAt}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, between, 1)
+	assert.Equal(t, created[1].ID, between[0].ID)
+}
+
+func TestPostgresStore_Leases(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-pg", "search", nil)
+	task.UpdateState(protocol.TaskStateRunning)
+	task.Attempts = 1
+	task.Lease = &protocol.TaskLease{Owner: "replica-1", ExpiresAt: time.Now()}
+	require.NoError(t, store.Create(ctx, task))
+	t.Cleanup(func() { store.Delete(ctx, task.ID) })
+
+	expiresAt := time.Now().Add(time.Minute)
+	require.NoError(t, store.RenewLease(ctx, task.ID, "replica-1", expiresAt))
+	got, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Attempts)
+	require.NotNil(t, got.Lease)
+	assert.WithinDuration(t, expiresAt, got.Lease.ExpiresAt, time.Millisecond)
+	assert.False(t, got.Lease.HeartbeatAt.IsZero())
+
+	assert.ErrorIs(t, store.RenewLease(ctx, task.ID, "replica-2", expiresAt), ErrLeaseLost)
+	assert.ErrorIs(t, store.RenewLease(ctx, "missing", "replica-1", expiresAt), ErrTaskNotFound)
+
+	got.Lease = nil
+	got.UpdateState(protocol.TaskStatePending)
+	assert.ErrorIs(t, store.UpdateLeased(ctx, got, "replica-2"), ErrLeaseLost)
+	require.NoError(t, store.UpdateLeased(ctx, got, "replica-1"))
+	assert.ErrorIs(t, store.UpdateLeased(ctx, got, "replica-1"), ErrLeaseLost, "pending tasks hold no lease")
+}
+
+func TestPostgresStore_Expired(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+
+	// Far in the future, so tasks other tests left behind expire first
+	now := time.Now().AddDate(100, 0, 0)
+	finished := func(age time.Duration, ttlSeconds int) *protocol.Task {
+		task := protocol.NewTask("agent-pg", "search", nil)
+		task.SetResult(map[string]interface{}{"answer": 42})
+		task.CompletedAt = now.Add(-age)
+		task.TTLSeconds = ttlSeconds
+		require.NoError(t, store.Create(ctx, task))
+		t.Cleanup(func() { store.Delete(ctx, task.ID) })
+		return task
+	}
+	old := finished(2*time.Hour, 0)
+	shortTTL := finished(time.Minute, 30)
+	finished(time.Minute, 0)
+	finished(2*time.Hour, 86400)
+
+	got, err := store.Get(ctx, shortTTL.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, got.TTLSeconds)
+
+	expired, err := store.Expired(ctx, now, time.Hour, 1000, 0)
+	require.NoError(t, err)
+	ids := make([]string, len(expired))
+	for i, task := range expired {
+		ids[i] = task.ID
+	}
+	require.GreaterOrEqual(t, len(ids), 2)
+	assert.Equal(t, []string{old.ID, shortTTL.ID}, ids[len(ids)-2:])
+}
+
+func TestPostgresStore_Events(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-pg", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	t.Cleanup(func() { store.Delete(ctx, task.ID) })
+
+	ch := store.Subscribe(ctx, task.ID)
+	defer store.Unsubscribe(ctx, task.ID, ch)
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning})
+
+	event := <-ch
+	assert.Equal(t, int64(1), event.ID)
+	assert.Len(t, store.Events(ctx, task.ID, 0), 1)
+}
+
+func TestPostgresStore_History(t *testing.T) {
+	store := setupPostgresStore(t)
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-pg", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	t.Cleanup(func() { store.Delete(ctx, task.ID) })
+
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStatePending, Message: "Task created"})
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning,
+		Artifact: &protocol.Artifact{ArtifactID: "progress", Parts: []protocol.Part{{Kind: protocol.KindText, Text: "half"}}}})
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateCompleted, WorkerID: "worker-1",
+		Data: map[string]interface{}{"winner": "search"}})
+
+	history, err := store.History(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, protocol.TaskStatePending, history[0].State)
+	assert.Equal(t, "Task created", history[0].Message)
+	assert.Equal(t, protocol.TaskStateCompleted, history[1].State)
+	assert.Equal(t, "worker-1", history[1].WorkerID)
+	assert.Equal(t, "search", history[1].Data["winner"])
+
+	// The history is deleted with its task
+	require.NoError(t, store.Delete(ctx, task.ID))
+	history, err = store.History(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
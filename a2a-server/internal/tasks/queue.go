@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"context"
+	"time"
+)
+
+// Queue hands pending tasks to task processors, possibly running on several replicas.
+// Delivery is at least once: a claimed task that is neither acknowledged nor extended
+// within the visibility timeout is handed to another processor.
+type Queue interface {
+	// Enqueue adds a task to the queue
+	Enqueue(ctx context.Context, taskID string) error
+	// Claim waits a short while for a task to process; it returns nil when none is ready
+	Claim(ctx context.Context) (*Delivery, error)
+	// Extend restarts the visibility timeout of a claimed task that is still being processed
+	Extend(ctx context.Context, delivery *Delivery) error
+	// Ack removes a processed task from the queue
+	Ack(ctx context.Context, delivery *Delivery) error
+	// DeadLetter moves a task that cannot be processed to the dead-letter queue
+	DeadLetter(ctx context.Context, delivery *Delivery, reason string) error
+	// VisibilityTimeout is how long a claimed task stays invisible to other processors
+	VisibilityTimeout() time.Duration
+}
+
+// Delivery is a task claimed from a queue
+type Delivery struct {
+	// ID identifies the delivery in the queue
+	ID     string
+	TaskID string
+	// Attempt counts the deliveries of the task, starting at 1
+	Attempt int64
+}
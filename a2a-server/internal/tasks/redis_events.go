@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// relayer is implemented by the stores embedding an eventHub
+type relayer interface {
+	relay(ctx context.Context, event protocol.TaskEvent)
+	forget(taskID string)
+}
+
+// redisEventMessage is an event, or the deletion of a task's events, sent between replicas
+type redisEventMessage struct {
+	Origin string              `json:"origin"`
+	Event  *protocol.TaskEvent `json:"event,omitempty"`
+	Forget string              `json:"forget,omitempty"`
+}
+
+// RedisEvents shares task events between replicas through a Redis pub/sub channel, so a
+// stream or webhook on one replica follows a task another replica processes. Each replica
+// numbers the events it receives itself, so a stream resumes with Last-Event-ID on the
+// replica it was opened on. Events published while a replica is disconnected from Redis
+// do not reach it; its streams still end with the final event built from the stored task.
+type RedisEvents struct {
+	Store
+
+	hub     relayer
+	client  *redis.Client
+	channel string
+	origin  string
+	pubsub  *redis.PubSub
+	done    chan struct{}
+}
+
+// NewRedisEvents subscribes to channel and returns store, sharing its events on it.
+// store must be a MemoryStore or PostgresStore.
+func NewRedisEvents(ctx context.Context, store Store, client *redis.Client, channel string) (*RedisEvents, error) {
+	hub, ok := store.(relayer)
+	if !ok {
+		return nil, fmt.Errorf("task store %T cannot share events", store)
+	}
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to task events: %w", err)
+	}
+
+	s := &RedisEvents{
+		Store:   store,
+		hub:     hub,
+		client:  client,
+		channel: channel,
+		origin:  uuid.New().String(),
+		pubsub:  pubsub,
+		done:    make(chan struct{}),
+	}
+	go s.receive()
+	return s, nil
+}
+
+// PublishEvent publishes the event like the store, then sends it to the other replicas
+func (s *RedisEvents) PublishEvent(ctx context.Context, event protocol.TaskEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	s.Store.PublishEvent(ctx, event)
+	event.ID = 0
+	s.send(ctx, redisEventMessage{Event: &event})
+}
+
+// Delete deletes the task like the store, then has the other replicas drop its events
+func (s *RedisEvents) Delete(ctx context.Context, id string) error {
+	if err := s.Store.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.send(ctx, redisEventMessage{Forget: id})
+	return nil
+}
+
+// Close stops receiving the other replicas' events
+func (s *RedisEvents) Close() error {
+	err := s.pubsub.Close()
+	<-s.done
+	return err
+}
+
+// send publishes a message for the other replicas; a failure only costs them live events
+func (s *RedisEvents) send(ctx context.Context, message redisEventMessage) {
+	message.Origin = s.origin
+	data, err := json.Marshal(message)
+	if err == nil {
+		err = s.client.Publish(context.WithoutCancel(ctx), s.channel, data).Err()
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error sharing task event", "channel", s.channel, "error", err)
+	}
+}
+
+// receive relays the other replicas' events to this replica's subscribers until Close
+func (s *RedisEvents) receive() {
+	defer close(s.done)
+	ctx := context.Background()
+	for msg := range s.pubsub.Channel() {
+		var message redisEventMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+			slog.Error("Error decoding shared task event", "channel", s.channel, "error", err)
+			continue
+		}
+		switch {
+		case message.Origin == s.origin:
+		case message.Event != nil:
+			s.hub.relay(ctx, *message.Event)
+		case message.Forget != "":
+			s.hub.forget(message.Forget)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisEvents(t *testing.T, mr *miniredis.Miniredis, store Store) *RedisEvents {
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	events, err := NewRedisEvents(context.Background(), store, client, "a2a:tasks:events")
+	require.NoError(t, err)
+	t.Cleanup(func() { events.Close() })
+	return events
+}
+
+func TestRedisEvents(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	// Two replicas sharing tasks through Redis; the first processes the task
+	processing := newTestRedisEvents(t, mr, NewMemoryStore())
+	following := newTestRedisEvents(t, mr, NewMemoryStore())
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, processing.Create(ctx, task))
+	require.NoError(t, following.Create(ctx, task))
+	local := processing.Subscribe(ctx, task.ID)
+	defer processing.Unsubscribe(ctx, task.ID, local)
+
+	received := make(chan protocol.TaskEvent, 10)
+	go Follow(ctx, following, task.ID, 0, nil, func(event protocol.TaskEvent) bool {
+		received <- event
+		return true
+	})
+	hub := &following.Store.(*MemoryStore).eventHub
+	require.Eventually(t, func() bool {
+		hub.mu.RLock()
+		defer hub.mu.RUnlock()
+		return len(hub.subscribers[task.ID]) > 0
+	}, time.Second, 5*time.Millisecond)
+
+	processing.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, WorkerID: "worker-1"})
+	processing.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateCompleted})
+
+	var events []protocol.TaskEvent
+	for len(events) < 2 {
+		select {
+		case event := <-received:
+			events = append(events, event)
+		case <-time.After(time.Second):
+			t.Fatalf("got %d events from the other replica", len(events))
+		}
+	}
+	assert.Equal(t, protocol.TaskStateRunning, events[0].State)
+	assert.Equal(t, "worker-1", events[0].WorkerID)
+	assert.Equal(t, protocol.TaskStateCompleted, events[1].State)
+	assert.Equal(t, []int64{1, 2}, []int64{events[0].ID, events[1].ID}, "the following replica numbers the events")
+
+	// The publishing replica delivers its own events once
+	assert.Len(t, local, 2)
+	assert.Len(t, following.Events(ctx, task.ID, 0), 2)
+
+	// Events of tasks nobody follows are not retained
+	other := protocol.NewTask("agent-1", "search", nil)
+	processing.PublishEvent(ctx, protocol.TaskEvent{TaskID: other.ID, State: protocol.TaskStateRunning})
+
+	// Deleting a task drops its events on every replica
+	require.NoError(t, processing.Delete(ctx, task.ID))
+	assert.Eventually(t, func() bool {
+		return len(following.Events(ctx, task.ID, 0)) == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, following.Events(ctx, other.ID, 0))
+}
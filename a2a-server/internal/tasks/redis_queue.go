@@ -0,0 +1,199 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisQueueConfig configures a Redis Streams task queue
+type RedisQueueConfig struct {
+	// Stream is the stream tasks are added to; dead-lettered tasks go to "<Stream>:dead"
+	Stream string
+	// Group is the consumer group shared by all processors
+	Group string
+	// Consumer names this processor within the group; it must be unique per replica
+	Consumer string
+	// VisibilityTimeout is how long a claimed task may go unacknowledged before it is redelivered
+	VisibilityTimeout time.Duration
+	// Block is how long Claim waits for a new task
+	Block time.Duration
+}
+
+// DefaultRedisQueueConfig returns the default queue settings, with a consumer named
+// after the host and process
+func DefaultRedisQueueConfig() RedisQueueConfig {
+	host, _ := os.Hostname()
+	return RedisQueueConfig{
+		Stream:            "a2a:tasks",
+		Group:             "a2a-processors",
+		Consumer:          fmt.Sprintf("%s-%d", host, os.Getpid()),
+		VisibilityTimeout: 30 * time.Second,
+		Block:             2 * time.Second,
+	}
+}
+
+// RedisQueue is a Queue backed by a Redis stream and consumer group. Claimed tasks stay
+// in the group's pending entries list until they are acknowledged; entries idle for
+// longer than the visibility timeout are claimed again by the next processor asking.
+type RedisQueue struct {
+	client *redis.Client
+	config RedisQueueConfig
+}
+
+var _ Queue = (*RedisQueue)(nil)
+
+// NewRedisQueue creates the consumer group if it does not exist yet
+func NewRedisQueue(ctx context.Context, client *redis.Client, config RedisQueueConfig) (*RedisQueue, error) {
+	defaults := DefaultRedisQueueConfig()
+	if config.Stream == "" {
+		config.Stream = defaults.Stream
+	}
+	if config.Group == "" {
+		config.Group = defaults.Group
+	}
+	if config.Consumer == "" {
+		config.Consumer = defaults.Consumer
+	}
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = defaults.VisibilityTimeout
+	}
+	if config.Block <= 0 {
+		config.Block = defaults.Block
+	}
+
+	// Start at the beginning of the stream so tasks added before the group existed are processed
+	err := client.XGroupCreateMkStream(ctx, config.Stream, config.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return &RedisQueue{client: client, config: config}, nil
+}
+
+// DeadLetterStream returns the stream dead-lettered tasks are added to
+func (q *RedisQueue) DeadLetterStream() string {
+	return q.config.Stream + ":dead"
+}
+
+// VisibilityTimeout returns how long a claimed task stays invisible to other processors
+func (q *RedisQueue) VisibilityTimeout() time.Duration {
+	return q.config.VisibilityTimeout
+}
+
+// Enqueue adds a task to the stream
+func (q *RedisQueue) Enqueue(ctx context.Context, taskID string) error {
+	err := q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.config.Stream,
+		Values: map[string]interface{}{"task_id": taskID},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	return nil
+}
+
+// Claim returns a task whose visibility timeout expired, or else waits up to the block
+// time for a new one
+func (q *RedisQueue) Claim(ctx context.Context) (*Delivery, error) {
+	expired, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.config.Stream,
+		Group:    q.config.Group,
+		Consumer: q.config.Consumer,
+		MinIdle:  q.config.VisibilityTimeout,
+		Start:    "0-0",
+		Count:    1,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim expired tasks: %w", err)
+	}
+	if len(expired) > 0 {
+		pending, err := q.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: q.config.Stream,
+			Group:  q.config.Group,
+			Start:  expired[0].ID,
+			End:    expired[0].ID,
+			Count:  1,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read delivery count: %w", err)
+		}
+		delivery := q.delivery(expired[0])
+		if len(pending) > 0 {
+			delivery.Attempt = pending[0].RetryCount
+		}
+		return delivery, nil
+	}
+
+	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.config.Group,
+		Consumer: q.config.Consumer,
+		Streams:  []string{q.config.Stream, ">"},
+		Count:    1,
+		Block:    q.config.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+	if len(streams) == 0 || len(streams[0].Messages) == 0 {
+		return nil, nil
+	}
+	return q.delivery(streams[0].Messages[0]), nil
+}
+
+// delivery converts a stream message into a first delivery
+func (q *RedisQueue) delivery(message redis.XMessage) *Delivery {
+	taskID, _ := message.Values["task_id"].(string)
+	return &Delivery{ID: message.ID, TaskID: taskID, Attempt: 1}
+}
+
+// Extend resets the idle time of a claimed task. The delivery count is passed back
+// explicitly so extending does not count as a redelivery.
+func (q *RedisQueue) Extend(ctx context.Context, delivery *Delivery) error {
+	claimed, err := q.client.Do(ctx, "XCLAIM", q.config.Stream, q.config.Group, q.config.Consumer, 0,
+		delivery.ID, "RETRYCOUNT", delivery.Attempt, "JUSTID").Slice()
+	if err != nil {
+		return fmt.Errorf("failed to extend task: %w", err)
+	}
+	if len(claimed) == 0 {
+		return fmt.Errorf("task %s is no longer queued", delivery.TaskID)
+	}
+	return nil
+}
+
+// Ack acknowledges a task and removes it from the stream
+func (q *RedisQueue) Ack(ctx context.Context, delivery *Delivery) error {
+	pipe := q.client.TxPipeline()
+	pipe.XAck(ctx, q.config.Stream, q.config.Group, delivery.ID)
+	pipe.XDel(ctx, q.config.Stream, delivery.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to acknowledge task: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter adds the task to the dead-letter stream with the reason, then acknowledges it
+func (q *RedisQueue) DeadLetter(ctx context.Context, delivery *Delivery, reason string) error {
+	pipe := q.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.DeadLetterStream(),
+		Values: map[string]interface{}{
+			"task_id":  delivery.TaskID,
+			"attempts": delivery.Attempt,
+			"reason":   reason,
+		},
+	})
+	pipe.XAck(ctx, q.config.Stream, q.config.Group, delivery.ID)
+	pipe.XDel(ctx, q.config.Stream, delivery.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to dead-letter task: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(t *testing.T, mr *miniredis.Miniredis, consumer string) *RedisQueue {
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	queue, err := NewRedisQueue(context.Background(), client, RedisQueueConfig{
+		Consumer:          consumer,
+		VisibilityTimeout: 50 * time.Millisecond,
+		Block:             10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return queue
+}
+
+func TestRedisQueue_ClaimAndAck(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	first := newTestQueue(t, mr, "replica-1")
+	second := newTestQueue(t, mr, "replica-2")
+
+	require.NoError(t, first.Enqueue(ctx, "task-1"))
+	require.NoError(t, first.Enqueue(ctx, "task-2"))
+
+	// Each task goes to one replica
+	a, err := first.Claim(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	b, err := second.Claim(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.ElementsMatch(t, []string{"task-1", "task-2"}, []string{a.TaskID, b.TaskID})
+	assert.Equal(t, int64(1), a.Attempt)
+
+	none, err := second.Claim(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	require.NoError(t, first.Ack(ctx, a))
+	require.NoError(t, second.Ack(ctx, b))
+	time.Sleep(60 * time.Millisecond)
+	none, err = first.Claim(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, none, "acknowledged tasks are not redelivered")
+}
+
+func TestRedisQueue_RedeliversAfterVisibilityTimeout(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	crashed := newTestQueue(t, mr, "replica-1")
+	survivor := newTestQueue(t, mr, "replica-2")
+
+	require.NoError(t, crashed.Enqueue(ctx, "task-1"))
+	claimed, err := crashed.Claim(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	// Extending keeps the task away from other replicas without counting a delivery
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, crashed.Extend(ctx, claimed))
+	time.Sleep(30 * time.Millisecond)
+	none, err := survivor.Claim(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	time.Sleep(60 * time.Millisecond)
+	redelivered, err := survivor.Claim(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, redelivered)
+	assert.Equal(t, "task-1", redelivered.TaskID)
+	assert.Equal(t, int64(2), redelivered.Attempt)
+
+	// The first replica lost the task
+	assert.Error(t, crashed.Extend(ctx, &Delivery{ID: "0-1", TaskID: "missing", Attempt: 1}))
+}
+
+func TestRedisQueue_DeadLetter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	queue := newTestQueue(t, mr, "replica-1")
+
+	require.NoError(t, queue.Enqueue(ctx, "poison"))
+	claimed, err := queue.Claim(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.NoError(t, queue.DeadLetter(ctx, claimed, "too many attempts"))
+
+	dead, err := queue.client.XRange(ctx, queue.DeadLetterStream(), "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "poison", dead[0].Values["task_id"])
+	assert.Equal(t, "too many attempts", dead[0].Values["reason"])
+
+	time.Sleep(60 * time.Millisecond)
+	none, err := queue.Claim(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
@@ -2,36 +2,163 @@ package tasks
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
 )
 
+// ErrTaskNotFound is returned when no task has the requested ID
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrLeaseLost is returned when a task is no longer running under the caller's lease,
+// because it finished, was cancelled or its lease was released
+var ErrLeaseLost = errors.New("task lease lost")
+
+// ErrInvalidTransition is returned when an update would move a task to a state it cannot
+// reach from its stored one, e.g. because it was cancelled meanwhile
+var ErrInvalidTransition = errors.New("invalid task state transition")
+
 // Store defines the interface for task storage
 type Store interface {
 	Create(ctx context.Context, task *protocol.Task) error
 	Get(ctx context.Context, id string) (*protocol.Task, error)
+	// Update replaces a stored task, returning ErrInvalidTransition if its stored state
+	// cannot move to the task's
 	Update(ctx context.Context, task *protocol.Task) error
+	// UpdateLeased updates a task like Update, but only while the stored task is running
+	// under owner's lease; otherwise it returns ErrLeaseLost
+	UpdateLeased(ctx context.Context, task *protocol.Task, owner string) error
+	// RenewLease moves the heartbeat and expiry of owner's lease on a running task,
+	// returning ErrLeaseLost if the task is no longer running under it
+	RenewLease(ctx context.Context, id, owner string, expiresAt time.Time) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, agentID string, limit, offset int) ([]*protocol.Task, error)
+	// List returns the tasks matching filter, oldest first unless it is Descending
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]*protocol.Task, error)
+	// Count returns the number of tasks matching filter
+	Count(ctx context.Context, filter ListFilter) (int, error)
+	// Expired returns the terminal tasks whose retention elapsed at now, see
+	// protocol.Task.RetentionExpired, first finished first
+	Expired(ctx context.Context, now time.Time, retention time.Duration, limit, offset int) ([]*protocol.Task, error)
 	Subscribe(ctx context.Context, taskID string) <-chan protocol.TaskEvent
 	Unsubscribe(ctx context.Context, taskID string, ch <-chan protocol.TaskEvent)
+	// PublishEvent records a state transition in the task's history, then assigns the
+	// event the task's next event ID and delivers it to subscribers
 	PublishEvent(ctx context.Context, event protocol.TaskEvent)
+	// History returns the state transitions published for a task, oldest first; unlike
+	// Events it keeps all of them for as long as the task is stored
+	History(ctx context.Context, taskID string) ([]protocol.TaskEvent, error)
+	// Events returns the task's retained events with IDs after afterID, oldest first,
+	// so streams can resume and fill gaps left by dropped deliveries
+	Events(ctx context.Context, taskID string, afterID int64) []protocol.TaskEvent
+}
+
+// ListFilter narrows and orders List; empty fields match every task
+type ListFilter struct {
+	AgentID string
+	State   protocol.TaskState
+	UserID  string
+	// DependsOn matches the tasks that depend on the task with this ID
+	DependsOn string
+	// CreatedAfter and CreatedBefore match the tasks created strictly between them
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Descending lists the newest tasks first
+	Descending bool
+	// After matches the tasks listed after the one at this position, so pages can be
+	// read from a cursor however many tasks were added or deleted before it
+	After *Cursor
+}
+
+// Matches reports whether a task passes the filter
+func (f ListFilter) Matches(task *protocol.Task) bool {
+	return (f.AgentID == "" || task.AgentID == f.AgentID) &&
+		(f.State == "" || task.State == f.State) &&
+		(f.UserID == "" || task.UserID == f.UserID) &&
+		(f.DependsOn == "" || slices.Contains(task.DependsOn, f.DependsOn)) &&
+		(f.CreatedAfter.IsZero() || task.CreatedAt.After(f.CreatedAfter)) &&
+		(f.CreatedBefore.IsZero() || task.CreatedAt.Before(f.CreatedBefore)) &&
+		f.listedAfter(task)
+}
+
+// listedAfter reports whether a task is listed after the filter's cursor
+func (f ListFilter) listedAfter(task *protocol.Task) bool {
+	if f.After == nil {
+		return true
+	}
+	if f.Descending {
+		return f.After.compare(task) > 0
+	}
+	return f.After.compare(task) < 0
+}
+
+// Cursor is the position of a task in a list of tasks ordered by creation time and ID
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorOf returns the position of a task
+func CursorOf(task *protocol.Task) *Cursor {
+	return &Cursor{CreatedAt: task.CreatedAt, ID: task.ID}
+}
+
+// compare returns -1 when the cursor is before the task, 0 when it is the task's
+// position and +1 when it is after it, in ascending order
+func (c *Cursor) compare(task *protocol.Task) int {
+	if cmp := c.CreatedAt.Compare(task.CreatedAt); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(c.ID, task.ID)
+}
+
+// String encodes the cursor as an opaque, URL-safe token
+func (c *Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + " " + c.ID))
+}
+
+// ParseCursor decodes a cursor encoded by Cursor.String
+func ParseCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	createdAt, id, ok := strings.Cut(string(data), " ")
+	if !ok || id == "" {
+		return nil, errors.New("invalid cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return &Cursor{CreatedAt: t, ID: id}, nil
 }
 
-// MemoryStore implements in-memory task storage
+// MaxEventHistory is how many of a task's most recent events are retained for resuming streams
+const MaxEventHistory = 256
+
+// MemoryStore implements in-memory task storage. Like the other stores it keeps and hands
+// out copies, so callers never share a task with each other or with the store.
 type MemoryStore struct {
-	mu          sync.RWMutex
-	tasks       map[string]*protocol.Task
-	subscribers map[string][]chan protocol.TaskEvent
+	eventHub
+
+	mu      sync.RWMutex
+	tasks   map[string]*protocol.Task
+	history map[string][]protocol.TaskEvent
 }
 
 // NewMemoryStore creates a new in-memory task store
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		tasks:       make(map[string]*protocol.Task),
-		subscribers: make(map[string][]chan protocol.TaskEvent),
+		eventHub: newEventHub(),
+		tasks:    make(map[string]*protocol.Task),
+		history:  make(map[string][]protocol.TaskEvent),
 	}
 }
 
@@ -44,7 +171,11 @@ func (s *MemoryStore) Create(ctx context.Context, task *protocol.Task) error {
 		return fmt.Errorf("task %s already exists", task.ID)
 	}
 
-	s.tasks[task.ID] = task
+	if err := task.Seal(); err != nil {
+		return err
+	}
+
+	s.tasks[task.ID] = task.Clone()
 	return nil
 }
 
@@ -55,10 +186,14 @@ func (s *MemoryStore) Get(ctx context.Context, id string) (*protocol.Task, error
 
 	task, exists := s.tasks[id]
 	if !exists {
-		return nil, fmt.Errorf("task %s not found", id)
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+
+	if err := task.VerifyIntegrity(); err != nil {
+		return nil, err
 	}
 
-	return task, nil
+	return task.Clone(), nil
 }
 
 // Update updates an existing task
@@ -66,11 +201,72 @@ func (s *MemoryStore) Update(ctx context.Context, task *protocol.Task) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.tasks[task.ID]; !exists {
-		return fmt.Errorf("task %s not found", task.ID)
+	stored, exists := s.tasks[task.ID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, task.ID)
+	}
+	if err := checkTransition(stored, task); err != nil {
+		return err
+	}
+
+	if err := task.Seal(); err != nil {
+		return err
+	}
+
+	s.tasks[task.ID] = task.Clone()
+	return nil
+}
+
+// UpdateLeased updates a task while it is running under owner's lease
+func (s *MemoryStore) UpdateLeased(ctx context.Context, task *protocol.Task, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkLease(task.ID, owner); err != nil {
+		return err
+	}
+	if err := checkTransition(s.tasks[task.ID], task); err != nil {
+		return err
+	}
+	if err := task.Seal(); err != nil {
+		return err
+	}
+
+	s.tasks[task.ID] = task.Clone()
+	return nil
+}
+
+// RenewLease moves the expiry of owner's lease on a running task
+func (s *MemoryStore) RenewLease(ctx context.Context, id, owner string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkLease(id, owner); err != nil {
+		return err
+	}
+
+	s.tasks[id].Lease = &protocol.TaskLease{Owner: owner, HeartbeatAt: time.Now(), ExpiresAt: expiresAt}
+	return nil
+}
+
+// checkTransition returns ErrInvalidTransition unless the stored task may move to the
+// updated task's state
+func checkTransition(stored, updated *protocol.Task) error {
+	if !stored.State.CanTransitionTo(updated.State) {
+		return fmt.Errorf("%w: task %s is %s, not %s", ErrInvalidTransition, updated.ID, stored.State, updated.State)
 	}
+	return nil
+}
 
-	s.tasks[task.ID] = task
+// checkLease returns an error unless the task is running under owner's lease
+func (s *MemoryStore) checkLease(id, owner string) error {
+	stored, exists := s.tasks[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	if stored.State != protocol.TaskStateRunning || stored.Lease == nil || stored.Lease.Owner != owner {
+		return fmt.Errorf("%w: %s", ErrLeaseLost, id)
+	}
 	return nil
 }
 
@@ -80,24 +276,56 @@ func (s *MemoryStore) Delete(ctx context.Context, id string) error {
 	defer s.mu.Unlock()
 
 	if _, exists := s.tasks[id]; !exists {
-		return fmt.Errorf("task %s not found", id)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
 
 	delete(s.tasks, id)
+	delete(s.history, id)
+	s.forget(id)
 	return nil
 }
 
-// List lists tasks with optional filtering by agent ID
-func (s *MemoryStore) List(ctx context.Context, agentID string, limit, offset int) ([]*protocol.Task, error) {
+// PublishEvent records a state transition in the task's history, then publishes the event
+// to the task's subscribers
+func (s *MemoryStore) PublishEvent(ctx context.Context, event protocol.TaskEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Transition() {
+		s.mu.Lock()
+		if _, exists := s.tasks[event.TaskID]; exists {
+			s.history[event.TaskID] = append(s.history[event.TaskID], event)
+		}
+		s.mu.Unlock()
+	}
+	s.eventHub.PublishEvent(ctx, event)
+}
+
+// History returns the state transitions of a task
+func (s *MemoryStore) History(ctx context.Context, taskID string) ([]protocol.TaskEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]protocol.TaskEvent{}, s.history[taskID]...), nil
+}
+
+// List lists the tasks matching filter in its order
+func (s *MemoryStore) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*protocol.Task, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var tasks []*protocol.Task
 	for _, task := range s.tasks {
-		if agentID == "" || task.AgentID == agentID {
+		if filter.Matches(task) {
 			tasks = append(tasks, task)
 		}
 	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) != filter.Descending
+		}
+		return tasks[i].ID < tasks[j].ID != filter.Descending
+	})
 
 	// Apply offset and limit
 	start := offset
@@ -110,51 +338,51 @@ func (s *MemoryStore) List(ctx context.Context, agentID string, limit, offset in
 		end = len(tasks)
 	}
 
-	return tasks[start:end], nil
+	return cloneTasks(tasks[start:end]), nil
 }
 
-// Subscribe subscribes to task events
-func (s *MemoryStore) Subscribe(ctx context.Context, taskID string) <-chan protocol.TaskEvent {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Count counts the tasks matching filter
+func (s *MemoryStore) Count(ctx context.Context, filter ListFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	ch := make(chan protocol.TaskEvent, 10)
-	s.subscribers[taskID] = append(s.subscribers[taskID], ch)
-	return ch
+	count := 0
+	for _, task := range s.tasks {
+		if filter.Matches(task) {
+			count++
+		}
+	}
+	return count, nil
 }
 
-// Unsubscribe unsubscribes from task events
-func (s *MemoryStore) Unsubscribe(ctx context.Context, taskID string, ch <-chan protocol.TaskEvent) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Expired lists the terminal tasks whose retention elapsed at now, first finished first
+func (s *MemoryStore) Expired(ctx context.Context, now time.Time, retention time.Duration, limit, offset int) ([]*protocol.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	subscribers := s.subscribers[taskID]
-	for i, sub := range subscribers {
-		if sub == ch {
-			// Remove from slice
-			s.subscribers[taskID] = append(subscribers[:i], subscribers[i+1:]...)
-			close(sub)
-			break
+	var tasks []*protocol.Task
+	for _, task := range s.tasks {
+		if task.RetentionExpired(now, retention) {
+			tasks = append(tasks, task)
 		}
 	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].FinishedAt().Equal(tasks[j].FinishedAt()) {
+			return tasks[i].FinishedAt().Before(tasks[j].FinishedAt())
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
 
-	// Clean up empty subscriber list
-	if len(s.subscribers[taskID]) == 0 {
-		delete(s.subscribers, taskID)
+	if offset > len(tasks) {
+		return []*protocol.Task{}, nil
 	}
+	return cloneTasks(tasks[offset:min(offset+limit, len(tasks))]), nil
 }
 
-// PublishEvent publishes an event to all subscribers
-func (s *MemoryStore) PublishEvent(ctx context.Context, event protocol.TaskEvent) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	subscribers := s.subscribers[event.TaskID]
-	for _, ch := range subscribers {
-		select {
-		case ch <- event:
-		default:
-			// Skip if channel is full
-		}
+// cloneTasks replaces the stored tasks of a page with copies
+func cloneTasks(tasks []*protocol.Task) []*protocol.Task {
+	for i, task := range tasks {
+		tasks[i] = task.Clone()
 	}
+	return tasks
 }
@@ -88,6 +88,89 @@ func TestMemoryStore_Update(t *testing.T) {
 	assert.Equal(t, protocol.TaskStateRunning, retrieved.State)
 }
 
+func TestMemoryStore_Update_InvalidTransition(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+
+	// A pending task has not run, so it cannot complete or wait for input
+	completed := *task
+	completed.SetResult(nil)
+	assert.ErrorIs(t, store.Update(ctx, &completed), ErrInvalidTransition)
+	waiting := *task
+	waiting.RequestInput("Which date?")
+	assert.ErrorIs(t, store.Update(ctx, &waiting), ErrInvalidTransition)
+
+	cancelled := *task
+	cancelled.Cancel("no longer needed")
+	require.NoError(t, store.Update(ctx, &cancelled))
+	running := cancelled
+	running.UpdateState(protocol.TaskStateRunning)
+	assert.ErrorIs(t, store.Update(ctx, &running), ErrInvalidTransition)
+
+	stored, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCancelled, stored.State)
+}
+
+func TestMemoryStore_KeepsCopies(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", map[string]interface{}{"filters": map[string]interface{}{"year": 2024}})
+	require.NoError(t, store.Create(ctx, task))
+	task.Input["query"] = "changed after Create"
+
+	got, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	got.Input["filters"].(map[string]interface{})["year"] = 2025
+	got.UpdateState(protocol.TaskStateRunning)
+	listed, err := store.List(ctx, ListFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	listed[0].Cancel("changed after List")
+
+	stored, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStatePending, stored.State)
+	assert.Equal(t, map[string]interface{}{"filters": map[string]interface{}{"year": 2024}}, stored.Input)
+}
+
+func TestMemoryStore_Get_IntegrityViolation(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", map[string]interface{}{"query": "test"})
+	require.NoError(t, store.Create(ctx, task))
+	assert.NotEmpty(t, task.InputHash)
+
+	// Tamper with the stored task without going through Update
+	store.tasks[task.ID].Input["query"] = "tampered"
+
+	_, err := store.Get(ctx, task.ID)
+	require.Error(t, err)
+
+	var integrityErr *protocol.IntegrityError
+	assert.ErrorAs(t, err, &integrityErr)
+}
+
+func TestMemoryStore_Update_ResealsTask(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+
+	task.Result = map[string]interface{}{"status": "success"}
+	require.NoError(t, store.Update(ctx, task))
+	assert.NotEmpty(t, task.ResultHash)
+
+	_, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+}
+
 func TestMemoryStore_Update_NotFound(t *testing.T) {
 	store := NewMemoryStore()
 	ctx := context.Background()
@@ -99,6 +182,37 @@ func TestMemoryStore_Update_NotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+func TestMemoryStore_Leases(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	assert.ErrorIs(t, store.RenewLease(ctx, task.ID, "replica-1", time.Now()), ErrLeaseLost, "pending tasks hold no lease")
+
+	running := *task
+	running.UpdateState(protocol.TaskStateRunning)
+	running.Lease = &protocol.TaskLease{Owner: "replica-1", ExpiresAt: time.Now()}
+	require.NoError(t, store.Update(ctx, &running))
+
+	expiresAt := time.Now().Add(time.Minute)
+	require.NoError(t, store.RenewLease(ctx, task.ID, "replica-1", expiresAt))
+	renewed, err := store.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt, renewed.Lease.ExpiresAt)
+	assert.False(t, renewed.Lease.HeartbeatAt.IsZero())
+	assert.Equal(t, time.Time{}, running.Lease.HeartbeatAt, "the caller's task is left alone")
+
+	assert.ErrorIs(t, store.RenewLease(ctx, task.ID, "replica-2", expiresAt), ErrLeaseLost)
+	assert.ErrorIs(t, store.RenewLease(ctx, "missing", "replica-1", expiresAt), ErrTaskNotFound)
+
+	completed := *renewed
+	completed.SetResult(map[string]interface{}{"status": "success"})
+	assert.ErrorIs(t, store.UpdateLeased(ctx, &completed, "replica-2"), ErrLeaseLost)
+	require.NoError(t, store.UpdateLeased(ctx, &completed, "replica-1"))
+	assert.ErrorIs(t, store.UpdateLeased(ctx, &completed, "replica-1"), ErrLeaseLost, "finished tasks hold no lease")
+}
+
 func TestMemoryStore_List(t *testing.T) {
 	store := NewMemoryStore()
 	ctx := context.Background()
@@ -107,32 +221,173 @@ func TestMemoryStore_List(t *testing.T) {
 	task1 := protocol.NewTask("agent-1", "search", nil)
 	task2 := protocol.NewTask("agent-1", "analyze", nil)
 	task3 := protocol.NewTask("agent-2", "summarize", nil)
+	task3.UserID = "alice"
+	task3.UpdateState(protocol.TaskStateRunning)
 
 	store.Create(ctx, task1)
 	store.Create(ctx, task2)
 	store.Create(ctx, task3)
 
 	// List all tasks
-	tasks, err := store.List(ctx, "", 10, 0)
+	tasks, err := store.List(ctx, ListFilter{}, 10, 0)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 3)
 
 	// List tasks for specific agent
-	tasks, err = store.List(ctx, "agent-1", 10, 0)
+	tasks, err = store.List(ctx, ListFilter{AgentID: "agent-1"}, 10, 0)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 2)
 
+	// List tasks by state and user
+	tasks, err = store.List(ctx, ListFilter{State: protocol.TaskStateRunning}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, task3.ID, tasks[0].ID)
+
+	tasks, err = store.List(ctx, ListFilter{AgentID: "agent-1", UserID: "alice"}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	// List the tasks depending on another
+	task2.DependsOn = []string{task1.ID}
+	require.NoError(t, store.Update(ctx, task2))
+	tasks, err = store.List(ctx, ListFilter{DependsOn: task1.ID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, task2.ID, tasks[0].ID)
+
 	// List with limit
-	tasks, err = store.List(ctx, "", 2, 0)
+	tasks, err = store.List(ctx, ListFilter{}, 2, 0)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 2)
 
 	// List with offset
-	tasks, err = store.List(ctx, "", 10, 2)
+	tasks, err = store.List(ctx, ListFilter{}, 10, 2)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 }
 
+func TestMemoryStore_List_OrderAndCursor(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	start := time.Now().Truncate(time.Second)
+	var created []*protocol.Task
+	for i := 0; i < 5; i++ {
+		task := protocol.NewTask("agent-1", "search", nil)
+		task.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.Create(ctx, task))
+		created = append(created, task)
+	}
+	ids := func(tasks []*protocol.Task) []string {
+		result := make([]string, len(tasks))
+		for i, task := range tasks {
+			result[i] = task.ID
+		}
+		return result
+	}
+
+	newest, err := store.List(ctx, ListFilter{Descending: true}, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{created[4].ID, created[3].ID}, ids(newest))
+
+	// Created strictly between the bounds
+	between, err := store.List(ctx, ListFilter{CreatedAfter: created[0].CreatedAt, CreatedBefore: created[3].CreatedAt}, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{created[1].ID, created[2].ID}, ids(between))
+
+	// Pages follow a cursor in either order, even when earlier tasks are deleted
+	page, err := store.List(ctx, ListFilter{After: CursorOf(created[1])}, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{created[2].ID, created[3].ID}, ids(page))
+	require.NoError(t, store.Delete(ctx, created[0].ID))
+	page, err = store.List(ctx, ListFilter{After: CursorOf(page[1])}, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{created[4].ID}, ids(page))
+	page, err = store.List(ctx, ListFilter{After: CursorOf(created[3]), Descending: true}, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{created[2].ID, created[1].ID}, ids(page))
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := &Cursor{CreatedAt: time.Date(2025, 1, 15, 9, 30, 0, 123456789, time.UTC), ID: "task-1"}
+	parsed, err := ParseCursor(cursor.String())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, "task-1", parsed.ID)
+
+	for _, token := range []string{"", "not base64!", "bm8tc3BhY2U", "MjAyNS0wMS0xNSB0YXNr"} {
+		_, err := ParseCursor(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func TestMemoryStore_Count(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	running := protocol.NewTask("agent-1", "search", nil)
+	running.UpdateState(protocol.TaskStateRunning)
+	for _, task := range []*protocol.Task{protocol.NewTask("agent-1", "search", nil), protocol.NewTask("agent-2", "search", nil), running} {
+		require.NoError(t, store.Create(ctx, task))
+	}
+
+	count, err := store.Count(ctx, ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = store.Count(ctx, ListFilter{State: protocol.TaskStatePending})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = store.Count(ctx, ListFilter{AgentID: "agent-1", State: protocol.TaskStatePending})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMemoryStore_Expired(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	finished := func(age time.Duration, ttlSeconds int) *protocol.Task {
+		task := protocol.NewTask("agent-1", "search", nil)
+		task.SetResult(nil)
+		task.CompletedAt = now.Add(-age)
+		task.TTLSeconds = ttlSeconds
+		require.NoError(t, store.Create(ctx, task))
+		return task
+	}
+	old := finished(2*time.Hour, 0)
+	recent := finished(time.Minute, 0)
+	shortTTL := finished(time.Minute, 30)
+	longTTL := finished(2*time.Hour, 86400)
+	require.NoError(t, store.Create(ctx, protocol.NewTask("agent-1", "search", nil)))
+
+	expired, err := store.Expired(ctx, now, time.Hour, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+	assert.Equal(t, old.ID, expired[0].ID)
+	assert.Equal(t, shortTTL.ID, expired[1].ID)
+
+	expired, err = store.Expired(ctx, now, time.Hour, 10, 1)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, shortTTL.ID, expired[0].ID)
+
+	// Without a retention only tasks with a TTL expire
+	expired, err = store.Expired(ctx, now, 0, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, shortTTL.ID, expired[0].ID)
+
+	expired, err = store.Expired(ctx, now.Add(48*time.Hour), 0, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, expired, 2)
+	assert.NotContains(t, []string{expired[0].ID, expired[1].ID}, recent.ID)
+	assert.Contains(t, []string{expired[0].ID, expired[1].ID}, longTTL.ID)
+}
+
 func TestMemoryStore_Delete(t *testing.T) {
 	store := NewMemoryStore()
 	ctx := context.Background()
@@ -228,6 +483,35 @@ func TestMemoryStore_PublishEvent_NoSubscribers(t *testing.T) {
 	})
 }
 
+func TestMemoryStore_History(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task := protocol.NewTask("agent-1", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStatePending, Message: "Task created"})
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, Message: "Task started", WorkerID: "worker-1"})
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning,
+		Artifact: &protocol.Artifact{ArtifactID: "progress", Parts: []protocol.Part{{Kind: protocol.KindText, Text: "half"}}}})
+	for i := 0; i < MaxEventHistory; i++ {
+		store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, Message: "still running"})
+	}
+
+	// Artifacts are not transitions, and the history outlives the retained events
+	history, err := store.History(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, history, MaxEventHistory+2)
+	assert.Equal(t, protocol.TaskStatePending, history[0].State)
+	assert.False(t, history[0].Timestamp.IsZero())
+	assert.Equal(t, "worker-1", history[1].WorkerID)
+	assert.Len(t, store.Events(ctx, task.ID, 0), MaxEventHistory)
+
+	require.NoError(t, store.Delete(ctx, task.ID))
+	history, err = store.History(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
 func TestMemoryStore_ConcurrentAccess(t *testing.T) {
 	store := NewMemoryStore()
 	ctx := context.Background()
@@ -251,7 +535,7 @@ func TestMemoryStore_ConcurrentAccess(t *testing.T) {
 	}
 
 	// Verify all tasks were created
-	tasks, err := store.List(ctx, "agent-1", 20, 0)
+	tasks, err := store.List(ctx, ListFilter{AgentID: "agent-1"}, 20, 0)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 10)
 }
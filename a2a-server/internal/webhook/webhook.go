@@ -0,0 +1,353 @@
+// Package webhook delivers task state transitions to push notification URLs, so clients
+// that cannot hold an SSE connection open still learn when their tasks finish.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+)
+
+// Headers set on every delivery
+const (
+	HeaderEventID   = "X-A2A-Event-ID"
+	HeaderTaskID    = "X-A2A-Task-ID"
+	HeaderTimestamp = "X-A2A-Timestamp"
+	HeaderSignature = "X-A2A-Signature"
+	HeaderToken     = "X-A2A-Notification-Token"
+)
+
+// Delivery outcomes, as recorded in metrics
+const (
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// ErrInvalidConfig is returned for push notification configs the notifier cannot deliver to
+var ErrInvalidConfig = errors.New("invalid push notification config")
+
+// errNotPublic is returned when a webhook resolves to an address that is not public
+var errNotPublic = errors.New("webhook address is not public")
+
+// Config controls delivery retries and the addresses webhooks may be delivered to
+type Config struct {
+	// MaxAttempts is how many times a delivery is tried before it is dropped
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds each attempt
+	Timeout time.Duration
+	// AllowedNetworks are non-public networks webhooks may still be delivered to, e.g.
+	// 10.0.0.0/8 for internal callbacks. Loopback, link-local, private and other
+	// non-public addresses are refused unless they are in one of these networks.
+	AllowedNetworks []netip.Prefix
+}
+
+// DefaultConfig returns the default delivery settings
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// Payload is the JSON body POSTed for each state transition
+type Payload struct {
+	Event protocol.TaskEvent `json:"event"`
+	// Task is the finished task, with its result or error, on the final delivery
+	Task *protocol.Task `json:"task,omitempty"`
+	// InputHash and ResultHash are the task's content hashes on the final delivery, see
+	// protocol.HashPayload. They are part of the signed body, so a receiver that checks the
+	// signature can rely on them to verify the input and result it holds.
+	InputHash  string `json:"input_hash,omitempty"`
+	ResultHash string `json:"result_hash,omitempty"`
+}
+
+// Recorder records delivery outcomes
+type Recorder interface {
+	RecordWebhookDelivery(ctx context.Context, status string, attempts int, durationMs float64)
+}
+
+// Validate checks that a push notification config has an absolute http or https URL
+// whose host, when it is an IP address, is one the notifier delivers to. Host names are
+// checked when a delivery connects, so a name resolving to a non-public address later
+// is refused too.
+func (n *Notifier) Validate(config protocol.PushNotificationConfig) error {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidConfig)
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !n.allowed(addr) {
+		return fmt.Errorf("%w: url must point to a public address", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Sign returns the X-A2A-Signature value for a delivery: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the secret, prefixed with "sha256="
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notifier POSTs the state transitions of registered tasks to their webhooks. Each
+// task's deliveries are made in order, and failed ones are retried with exponential
+// backoff. Deliveries only connect to public addresses and those in
+// Config.AllowedNetworks, are not made through a proxy and do not follow redirects. Configs are kept in memory, so webhooks are per replica: the replica a task's
+// webhook was registered with delivers it, from the events it gets from its task store,
+// and a webhook is lost if that replica stops. With several replicas the store must
+// share events between them, see tasks.RedisEvents.
+type Notifier struct {
+	store   tasks.Store
+	config  Config
+	client  *http.Client
+	metrics Recorder
+
+	mu       sync.Mutex
+	configs  map[string]protocol.PushNotificationConfig
+	watching map[string]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewNotifier creates a notifier for the tasks in store
+func NewNotifier(store tasks.Store, config Config) *Notifier {
+	defaults := DefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		store:    store,
+		config:   config,
+		configs:  make(map[string]protocol.PushNotificationConfig),
+		watching: make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	// Check the address every connection is made to, after DNS resolution, so a webhook
+	// cannot reach internal services by resolving to them or redirecting to them
+	dialer := &net.Dialer{Timeout: config.Timeout, KeepAlive: 30 * time.Second, Control: n.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	n.client = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return n
+}
+
+// allowed reports whether deliveries may connect to addr
+func (n *Notifier) allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, network := range n.config.AllowedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+// control refuses connections to addresses deliveries may not connect to
+func (n *Notifier) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !n.allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errNotPublic, addrPort.Addr())
+	}
+	return nil
+}
+
+// SetMetrics records every delivery outcome
+func (n *Notifier) SetMetrics(metrics Recorder) {
+	n.metrics = metrics
+}
+
+// Register sets the webhook of a task, replacing any earlier one, and starts delivering
+// its state transitions. Transitions the task already went through are delivered too.
+func (n *Notifier) Register(taskID string, config protocol.PushNotificationConfig) error {
+	if err := n.Validate(config); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ctx.Err() != nil {
+		return errors.New("notifier is closed")
+	}
+	n.configs[taskID] = config
+	if !n.watching[taskID] {
+		n.watching[taskID] = true
+		n.wg.Add(1)
+		go n.watch(taskID)
+	}
+	return nil
+}
+
+// Config returns the webhook registered for a task
+func (n *Notifier) Config(taskID string) (protocol.PushNotificationConfig, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	config, ok := n.configs[taskID]
+	return config, ok
+}
+
+// Close stops delivering and waits for pending deliveries to give up
+func (n *Notifier) Close() {
+	n.cancel()
+	n.wg.Wait()
+}
+
+// watch delivers a task's state transitions until its final one
+func (n *Notifier) watch(taskID string) {
+	defer n.wg.Done()
+	defer func() {
+		n.mu.Lock()
+		delete(n.watching, taskID)
+		n.mu.Unlock()
+	}()
+
+	tasks.Follow(n.ctx, n.store, taskID, 0, nil, func(event protocol.TaskEvent) bool {
+		// Artifacts are streamed over SSE; webhooks get the result with the final event
+		if event.Artifact != nil {
+			return true
+		}
+		payload := Payload{Event: event}
+		if event.Final() {
+			// The store verifies the hashes on Get, so a task failing the check is not sent
+			if task, err := n.store.Get(n.ctx, taskID); err == nil {
+				payload.Task = task
+				payload.InputHash, payload.ResultHash = task.InputHash, task.ResultHash
+			}
+		}
+		n.deliver(taskID, payload)
+		return n.ctx.Err() == nil
+	})
+}
+
+// deliver POSTs a payload, retrying with exponential backoff until it is accepted, the
+// attempts run out or the failure is permanent
+func (n *Notifier) deliver(taskID string, payload Payload) {
+	ctx := n.ctx
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "task_id", taskID, "error", err)
+		return
+	}
+
+	start := time.Now()
+	backoff := n.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		// Read the config on every attempt so a replaced webhook takes effect right away
+		config, _ := n.Config(taskID)
+		retry, err := n.post(ctx, config, payload.Event, body)
+		if err == nil {
+			n.record(StatusDelivered, attempt, start)
+			slog.Debug("Webhook delivered", "task_id", taskID, "event_id", payload.Event.ID, "attempts", attempt)
+			return
+		}
+		if !retry || attempt >= n.config.MaxAttempts {
+			n.record(StatusFailed, attempt, start)
+			slog.Warn("Webhook delivery failed", "task_id", taskID, "event_id", payload.Event.ID,
+				"attempts", attempt, "error", err)
+			return
+		}
+
+		slog.Debug("Retrying webhook delivery", "task_id", taskID, "event_id", payload.Event.ID,
+			"attempt", attempt, "backoff", backoff.String(), "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			n.record(StatusFailed, attempt, start)
+			return
+		}
+		backoff = min(backoff*2, n.config.MaxBackoff)
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying:
+// network errors, timeouts, 429 and 5xx responses are; refused addresses, redirects and
+// other responses are not
+func (n *Notifier) post(ctx context.Context, config protocol.PushNotificationConfig, event protocol.TaskEvent, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(HeaderTaskID, event.TaskID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if config.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(config.Secret, timestamp, body))
+	}
+	if config.Token != "" {
+		req.Header.Set(HeaderToken, config.Token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return !errors.Is(err, errNotPublic), err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+// record records a delivery outcome if metrics are set
+func (n *Notifier) record(status string, attempts int, start time.Time) {
+	if n.metrics != nil {
+		n.metrics.RecordWebhookDelivery(context.Background(), status, attempts, float64(time.Since(start).Milliseconds()))
+	}
+}
@@ -0,0 +1,260 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records deliveries and answers with the queued status codes, then 200
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// outcomes records delivery metrics
+type outcomes struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (o *outcomes) RecordWebhookDelivery(ctx context.Context, status string, attempts int, durationMs float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results = append(o.results, status+":"+strconv.Itoa(attempts))
+}
+
+func (o *outcomes) get() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.results...)
+}
+
+// fastConfig retries quickly and allows the loopback receivers of the tests
+func fastConfig() Config {
+	return Config{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Timeout: time.Second,
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}}
+}
+
+// complete stores a completed copy of the task, which runs first, and publishes its final
+// event
+func complete(t *testing.T, store tasks.Store, task *protocol.Task) {
+	t.Helper()
+	ctx := context.Background()
+	running := *task
+	running.UpdateState(protocol.TaskStateRunning)
+	require.NoError(t, store.Update(ctx, &running))
+	done := running
+	done.SetResult(map[string]interface{}{"status": "success"})
+	require.NoError(t, store.Update(ctx, &done))
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateCompleted, Message: "Task completed"})
+}
+
+func TestNotifier_DeliversTransitions(t *testing.T) {
+	rcv := &receiver{}
+	target := httptest.NewServer(rcv)
+	defer target.Close()
+
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	task := protocol.NewTask("agent", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+
+	metrics := &outcomes{}
+	notifier := NewNotifier(store, fastConfig())
+	notifier.SetMetrics(metrics)
+	defer notifier.Close()
+	require.NoError(t, notifier.Register(task.ID, protocol.PushNotificationConfig{URL: target.URL, Token: "tok", Secret: "s3cret"}))
+
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning, Message: "Task started"})
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning,
+		Artifact: &protocol.Artifact{ArtifactID: "a", Parts: []protocol.Part{{Kind: protocol.KindText, Text: "chunk"}}}})
+	complete(t, store, task)
+
+	require.Eventually(t, func() bool { return rcv.count() == 2 }, 2*time.Second, 5*time.Millisecond, "artifacts are not delivered")
+	assert.Equal(t, []string{"delivered:1", "delivered:1"}, metrics.get())
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	var first, last Payload
+	require.NoError(t, json.Unmarshal(rcv.bodies[0], &first))
+	require.NoError(t, json.Unmarshal(rcv.bodies[1], &last))
+	assert.Equal(t, protocol.TaskStateRunning, first.Event.State)
+	assert.Nil(t, first.Task)
+	assert.Equal(t, protocol.TaskStateCompleted, last.Event.State)
+	require.NotNil(t, last.Task)
+	assert.Equal(t, "success", last.Task.Result["status"])
+	assert.Empty(t, first.InputHash)
+	assert.Empty(t, first.ResultHash)
+
+	// The final delivery carries the content hashes, which match the task it carries
+	inputHash, err := protocol.HashPayload(last.Task.Input)
+	require.NoError(t, err)
+	resultHash, err := protocol.HashPayload(last.Task.Result)
+	require.NoError(t, err)
+	assert.Equal(t, inputHash, last.InputHash)
+	assert.Equal(t, resultHash, last.ResultHash)
+	assert.NoError(t, last.Task.VerifyIntegrity())
+
+	req := rcv.requests[1]
+	assert.Equal(t, "3", req.Header.Get(HeaderEventID))
+	assert.Equal(t, task.ID, req.Header.Get(HeaderTaskID))
+	assert.Equal(t, "tok", req.Header.Get(HeaderToken))
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("s3cret", timestamp, rcv.bodies[1]), req.Header.Get(HeaderSignature))
+
+	// The signature covers the hashes
+	tampered := bytes.Replace(rcv.bodies[1], []byte(last.ResultHash), []byte(inputHash), 1)
+	assert.NotEqual(t, Sign("s3cret", timestamp, tampered), req.Header.Get(HeaderSignature))
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK, http.StatusBadRequest}}
+	target := httptest.NewServer(rcv)
+	defer target.Close()
+
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	task := protocol.NewTask("agent", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+
+	metrics := &outcomes{}
+	notifier := NewNotifier(store, fastConfig())
+	notifier.SetMetrics(metrics)
+	defer notifier.Close()
+	require.NoError(t, notifier.Register(task.ID, protocol.PushNotificationConfig{URL: target.URL}))
+
+	// The first event succeeds on the third attempt; a 400 is not retried
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning})
+	complete(t, store, task)
+
+	require.Eventually(t, func() bool { return len(metrics.get()) == 2 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"delivered:3", "failed:1"}, metrics.get())
+	assert.Equal(t, 4, rcv.count())
+	assert.Empty(t, rcv.requests[0].Header.Get(HeaderSignature), "unsigned without a secret")
+}
+
+func TestNotifier_RegisterFinishedTask(t *testing.T) {
+	rcv := &receiver{}
+	target := httptest.NewServer(rcv)
+	defer target.Close()
+
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	task := protocol.NewTask("agent", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+	complete(t, store, task)
+
+	notifier := NewNotifier(store, fastConfig())
+	defer notifier.Close()
+	require.NoError(t, notifier.Register(task.ID, protocol.PushNotificationConfig{URL: target.URL}))
+
+	require.Eventually(t, func() bool { return rcv.count() == 1 }, 2*time.Second, 5*time.Millisecond)
+	config, ok := notifier.Config(task.ID)
+	assert.True(t, ok)
+	assert.Equal(t, target.URL, config.URL)
+}
+
+func TestNotifier_Validate(t *testing.T) {
+	notifier := NewNotifier(tasks.NewMemoryStore(), Config{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}})
+	defer notifier.Close()
+	for _, url := range []string{"https://example.com/hook", "http://93.184.216.34/hook", "http://[2606:4700::1]/hook",
+		"http://10.1.2.3:8080/hook", "http://localhost/hook"} {
+		assert.NoError(t, notifier.Validate(protocol.PushNotificationConfig{URL: url}), url)
+	}
+	for _, url := range []string{"", "example.com/hook", "ftp://example.com", "http://", ":",
+		"http://127.0.0.1/hook", "http://[::1]/hook", "http://169.254.169.254/latest/meta-data", "http://10.2.0.1/hook",
+		"http://192.168.1.1/hook", "http://0.0.0.0/hook", "http://[::ffff:127.0.0.1]/hook", "http://[fe80::1]/hook"} {
+		assert.ErrorIs(t, notifier.Validate(protocol.PushNotificationConfig{URL: url}), ErrInvalidConfig, url)
+	}
+
+	assert.ErrorIs(t, notifier.Register("task", protocol.PushNotificationConfig{URL: "nope"}), ErrInvalidConfig)
+	_, ok := notifier.Config("task")
+	assert.False(t, ok)
+}
+
+func TestNotifier_RefusesNonPublicAddresses(t *testing.T) {
+	rcv := &receiver{}
+	target := httptest.NewServer(rcv)
+	defer target.Close()
+
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	task := protocol.NewTask("agent", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+
+	// A host name is checked once it resolves, and a refused address is not retried
+	config := fastConfig()
+	config.AllowedNetworks = nil
+	metrics := &outcomes{}
+	notifier := NewNotifier(store, config)
+	notifier.SetMetrics(metrics)
+	defer notifier.Close()
+	hook := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+	require.NoError(t, notifier.Register(task.ID, protocol.PushNotificationConfig{URL: hook}))
+
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning})
+	require.Eventually(t, func() bool { return len(metrics.get()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"failed:1"}, metrics.get())
+	assert.Zero(t, rcv.count())
+}
+
+func TestNotifier_DoesNotFollowRedirects(t *testing.T) {
+	rcv := &receiver{}
+	internal := httptest.NewServer(rcv)
+	defer internal.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(internal.URL+"/admin", http.StatusTemporaryRedirect))
+	defer redirect.Close()
+
+	ctx := context.Background()
+	store := tasks.NewMemoryStore()
+	task := protocol.NewTask("agent", "search", nil)
+	require.NoError(t, store.Create(ctx, task))
+
+	metrics := &outcomes{}
+	notifier := NewNotifier(store, fastConfig())
+	notifier.SetMetrics(metrics)
+	defer notifier.Close()
+	require.NoError(t, notifier.Register(task.ID, protocol.PushNotificationConfig{URL: redirect.URL}))
+
+	store.PublishEvent(ctx, protocol.TaskEvent{TaskID: task.ID, State: protocol.TaskStateRunning})
+	require.Eventually(t, func() bool { return len(metrics.get()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"failed:1"}, metrics.get())
+	assert.Zero(t, rcv.count())
+}
@@ -0,0 +1,244 @@
+package a2aclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/google/uuid"
+)
+
+// A2A types, shared with the server so both sides stay in step
+type (
+	AgentCard               = protocol.AgentCard
+	Capability              = protocol.Capability
+	Task                    = protocol.A2ATask
+	TaskStatus              = protocol.A2ATaskStatus
+	Message                 = protocol.Message
+	Part                    = protocol.Part
+	FileContent             = protocol.FileContent
+	Artifact                = protocol.Artifact
+	MessageSendParams       = protocol.MessageSendParams
+	TaskStatusUpdateEvent   = protocol.TaskStatusUpdateEvent
+	TaskArtifactUpdateEvent = protocol.TaskArtifactUpdateEvent
+)
+
+// Part constructors, for messages carrying text, files or structured data
+var (
+	TextPart    = protocol.TextPart
+	DataPart    = protocol.DataPart
+	FilePart    = protocol.FilePart
+	FileURIPart = protocol.FileURIPart
+)
+
+// Task states
+const (
+	StateSubmitted     = protocol.A2AStateSubmitted
+	StateWorking       = protocol.A2AStateWorking
+	StateInputRequired = protocol.A2AStateInputRequired
+	StateCompleted     = protocol.A2AStateCompleted
+	StateFailed        = protocol.A2AStateFailed
+	StateCanceled      = protocol.A2AStateCanceled
+)
+
+// JSON-RPC error codes the server returns
+const (
+	CodeInvalidParams           = protocol.InvalidParams
+	CodeMethodNotFound          = protocol.MethodNotFound
+	CodeTaskNotFound            = protocol.TaskNotFound
+	CodeTaskNotCancelable       = protocol.TaskNotCancelable
+	CodeUnsupportedOperation    = protocol.UnsupportedOperation
+	CodeContentTypeNotSupported = protocol.ContentTypeNotSupported
+	CodeBudgetExceeded          = protocol.BudgetExceeded
+	CodeRequestTooLarge         = protocol.RequestTooLarge
+	CodeTaskNotAwaitingInput    = protocol.TaskNotAwaitingInput
+)
+
+// AgentCard fetches the agent's card, which lists its capabilities
+func (c *Client) AgentCard(ctx context.Context) (*AgentCard, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.URL+CardPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(ctx, req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent card: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch agent card: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(data))})
+	}
+
+	var card AgentCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("invalid agent card: %w", err)
+	}
+	return &card, nil
+}
+
+// NewMessageParams returns message/send params that run capability on input for the
+// configured user and agent. Callers may add metadata such as "priority" or
+// "max_cost_usd" before passing them to Send or Stream.
+func (c *Client) NewMessageParams(capability string, input map[string]interface{}) MessageSendParams {
+	params := MessageSendParams{
+		Message: Message{
+			Kind:      protocol.KindMessage,
+			Role:      protocol.RoleUser,
+			Parts:     []Part{{Kind: protocol.KindData, Data: input}},
+			MessageID: uuid.New().String(),
+		},
+		Metadata: map[string]interface{}{"capability": capability},
+	}
+	if c.config.UserID != "" {
+		params.Metadata["user_id"] = c.config.UserID
+	}
+	if c.config.AgentID != "" {
+		params.Metadata["agent_id"] = c.config.AgentID
+	}
+	return params
+}
+
+// Send creates a task with message/send and returns it as submitted
+func (c *Client) Send(ctx context.Context, params MessageSendParams) (*Task, error) {
+	var task Task
+	if err := c.Call(ctx, protocol.MethodMessageSend, params, &task); err != nil {
+		return nil, fmt.Errorf("A2A message/send: %w", err)
+	}
+	return &task, nil
+}
+
+// SendMessage creates a task running capability on input and returns it as submitted
+func (c *Client) SendMessage(ctx context.Context, capability string, input map[string]interface{}) (*Task, error) {
+	return c.Send(ctx, c.NewMessageParams(capability, input))
+}
+
+// Reply answers the question of a task waiting for input with text and data, either of
+// which may be empty, and returns the task as submitted again. A task that is not
+// waiting returns an *Error with CodeTaskNotAwaitingInput.
+func (c *Client) Reply(ctx context.Context, task *Task, text string, data map[string]interface{}) (*Task, error) {
+	message := Message{
+		Kind:      protocol.KindMessage,
+		Role:      protocol.RoleUser,
+		MessageID: uuid.New().String(),
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+	}
+	if text != "" {
+		message.Parts = append(message.Parts, Part{Kind: protocol.KindText, Text: text})
+	}
+	if len(data) > 0 {
+		message.Parts = append(message.Parts, Part{Kind: protocol.KindData, Data: data})
+	}
+	return c.Send(ctx, MessageSendParams{Message: message})
+}
+
+// GetTask returns the current state of a task
+func (c *Client) GetTask(ctx context.Context, taskID string) (*Task, error) {
+	var task Task
+	if err := c.Call(ctx, protocol.MethodTasksGet, protocol.TaskQueryParams{ID: taskID}, &task); err != nil {
+		return nil, fmt.Errorf("A2A tasks/get %s: %w", taskID, err)
+	}
+	return &task, nil
+}
+
+// CancelTask cancels a task and returns it as canceled. A task that already finished
+// returns an *Error with CodeTaskNotCancelable.
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*Task, error) {
+	var task Task
+	if err := c.Call(ctx, protocol.MethodTasksCancel, protocol.TaskIDParams{ID: taskID}, &task); err != nil {
+		return nil, fmt.Errorf("A2A tasks/cancel %s: %w", taskID, err)
+	}
+	return &task, nil
+}
+
+// Wait polls a task every PollInterval until it finishes or asks for input and returns
+// it in that state, or ctx's error once ctx is done. The task keeps running when Wait
+// gives up.
+func (c *Client) Wait(ctx context.Context, task *Task) (*Task, error) {
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+	for !Finished(task) && task.Status.State != StateInputRequired {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		next, err := c.GetTask(ctx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		task = next
+	}
+	return task, nil
+}
+
+// Run creates a task and waits for it to finish or ask for input. If ctx is done first, the task is
+// cancelled so it does not run on unobserved.
+func (c *Client) Run(ctx context.Context, capability string, input map[string]interface{}) (*Task, error) {
+	task, err := c.SendMessage(ctx, capability, input)
+	if err != nil {
+		return nil, err
+	}
+	finished, err := c.Wait(ctx, task)
+	if ctx.Err() != nil {
+		c.abandon(ctx, task.ID)
+	}
+	return finished, err
+}
+
+// abandon cancels a task the caller gave up waiting for, without waiting on its ctx
+func (c *Client) abandon(ctx context.Context, taskID string) {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	c.CancelTask(cancelCtx, taskID)
+}
+
+// Finished reports whether a task reached a final state
+func Finished(task *Task) bool {
+	switch task.Status.State {
+	case StateCompleted, StateFailed, StateCanceled:
+		return true
+	}
+	return false
+}
+
+// Result returns the data of the task's result artifact, or nil before the task completed
+func Result(task *Task) map[string]interface{} {
+	for _, artifact := range task.Artifacts {
+		if artifact.ArtifactID != task.ID+"-result" {
+			continue
+		}
+		for _, part := range artifact.Parts {
+			if part.Kind == protocol.KindData {
+				return part.Data
+			}
+		}
+	}
+	return nil
+}
+
+// StatusText returns the text of the task's status message, such as the reason it failed
+// or the question it asks
+func StatusText(task *Task) string {
+	if task.Status.Message == nil {
+		return ""
+	}
+	var text bytes.Buffer
+	for _, part := range task.Status.Message.Parts {
+		if part.Kind == protocol.KindText {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,198 @@
+// Package a2aclient is a Go client for A2A agents that speak JSON-RPC over HTTP, such as
+// this repository's a2a-server. It discovers the agent from its card, creates tasks and
+// polls or streams them until they finish, reconnecting dropped streams where they left off.
+//
+//	client := a2aclient.New(a2aclient.Config{URL: "http://localhost:8081", UserID: "user-1"})
+//	task, err := client.Run(ctx, "search_papers", map[string]interface{}{"query": "mTLS"})
+package a2aclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Paths of an agent's endpoints, relative to its base URL
+const (
+	CardPath = "/agent"
+	RPCPath  = "/a2a"
+)
+
+// Client defaults
+const (
+	DefaultPollInterval   = time.Second
+	DefaultMaxReconnects  = 5
+	DefaultReconnectDelay = 500 * time.Millisecond
+)
+
+// maxResponseBytes bounds the responses and stream events read from the agent
+const maxResponseBytes = 16 << 20
+
+// maxReconnectDelay caps the doubling wait between stream reconnects
+const maxReconnectDelay = 30 * time.Second
+
+// Config configures a client for one agent
+type Config struct {
+	// URL is the agent's base URL, e.g. http://a2a-server:8081
+	URL string
+	// Token, when set, is sent as a bearer token
+	Token string
+	// UserID is sent as metadata.user_id, the user whose budget pays for the tasks
+	UserID string
+	// AgentID is sent as metadata.agent_id; empty runs tasks on the server's own agent
+	AgentID string
+	// Timeout bounds each HTTP request, except streams; zero means no limit
+	Timeout time.Duration
+	// PollInterval is how often Wait checks a task; zero uses DefaultPollInterval
+	PollInterval time.Duration
+	// MaxReconnects is how often a stream that ends before the task's final event is
+	// reopened with tasks/resubscribe; the count resets whenever an event arrives. Zero
+	// uses DefaultMaxReconnects and a negative value disables reconnects.
+	MaxReconnects int
+	// ReconnectDelay is the wait before the first reconnect, doubled for each later one
+	ReconnectDelay time.Duration
+	// HTTPClient sends the requests; nil uses a client with Timeout
+	HTTPClient *http.Client
+}
+
+// Error is a JSON-RPC error returned by the agent
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("A2A error %d: %s", e.Code, e.Message)
+}
+
+// StatusError is returned when the agent answers without a JSON-RPC response
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("A2A agent returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// Client calls an A2A agent. It is safe for concurrent use.
+type Client struct {
+	config Config
+	client *http.Client
+	nextID atomic.Int64
+}
+
+// New creates a client for the agent at config.URL
+func New(config Config) *Client {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxReconnects == 0 {
+		config.MaxReconnects = DefaultMaxReconnects
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = DefaultReconnectDelay
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+	return &Client{config: config, client: client}
+}
+
+// URL returns the agent's base URL
+func (c *Client) URL() string {
+	return c.config.URL
+}
+
+// Call sends a JSON-RPC request and decodes its result into out, which may be nil.
+// JSON-RPC errors are returned as *Error, other failed responses as *StatusError.
+func (c *Client) Call(ctx context.Context, method string, params, out interface{}) error {
+	id := c.nextID.Add(1)
+	req, err := c.newRPCRequest(ctx, id, method, params)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("A2A %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read A2A %s response: %w", method, err)
+	}
+	rpc, err := decodeResponse(resp.StatusCode, data)
+	if err != nil {
+		return err
+	}
+	if string(rpc.ID) != strconv.FormatInt(id, 10) {
+		return fmt.Errorf("A2A response id %s does not match request id %d", rpc.ID, id)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rpc.Result, out); err != nil {
+			return fmt.Errorf("invalid A2A %s result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// rpcResponse is a JSON-RPC response with its result left undecoded
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// decodeResponse parses a JSON-RPC response, returning its error as *Error and a body
+// that is no JSON-RPC response as *StatusError
+func decodeResponse(statusCode int, data []byte) (*rpcResponse, error) {
+	var rpc rpcResponse
+	if err := json.Unmarshal(data, &rpc); err != nil || (rpc.Error == nil && rpc.Result == nil) {
+		return nil, &StatusError{StatusCode: statusCode, Body: string(bytes.TrimSpace(data))}
+	}
+	if rpc.Error != nil {
+		return nil, rpc.Error
+	}
+	return &rpc, nil
+}
+
+// newRPCRequest builds the POST of one JSON-RPC request
+func (c *Client) newRPCRequest(ctx context.Context, id int64, method string, params interface{}) (*http.Request, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": protocol.JSONRPCVersion,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL+RPCPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(ctx, req)
+	return req, nil
+}
+
+// setHeaders adds the bearer token and trace context to a request
+func (c *Client) setHeaders(ctx context.Context, req *http.Request) {
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
@@ -0,0 +1,291 @@
+package a2aclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/agentcard"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/capabilities"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/cost"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/server"
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newA2AServer serves the real A2A routes and task processor. The "echo" capability
+// returns its input; "block" runs until the test ends. user-1 has a budget, and the admin
+// token is testAdminToken.
+func newA2AServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	card := protocol.NewAgentCard("agent-1", "Test Agent", "1.0.0", "Test agent")
+	card.AddCapability(protocol.Capability{Name: "echo"})
+	card.AddCapability(protocol.Capability{Name: "block"})
+	agents := agentcard.NewStore()
+	require.NoError(t, agents.Register(ctx, card))
+	budgets := cost.NewBudgetManager()
+	require.NoError(t, budgets.SetBudget(ctx, "user-1", 10))
+
+	executors := capabilities.NewRegistry()
+	executors.Register(protocol.Capability{Name: "echo"}, capabilities.ExecutorFunc(
+		func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+			return &capabilities.Result{Output: input, CostUSD: 0.001}, nil
+		}))
+	executors.Register(protocol.Capability{Name: "block"}, capabilities.ExecutorFunc(
+		func(ctx context.Context, input map[string]interface{}) (*capabilities.Result, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+
+	store := tasks.NewMemoryStore()
+	srv := server.NewServer(store, agents, cost.NewMemoryTracker(), budgets, card, nil)
+	srv.SetExecutors(executors)
+	srv.SetAdminToken(testAdminToken)
+	processor := server.NewTaskProcessor(store, 10*time.Millisecond)
+	processor.SetExecutors(executors, nil)
+	processor.Start(ctx)
+
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		cancel()
+		httpServer.Close()
+	})
+	return httpServer
+}
+
+// testAdminToken is the admin token of the test server
+const testAdminToken = "admin-token"
+
+func newTestClient(url string) *Client {
+	return New(Config{URL: url, UserID: "user-1", Timeout: 5 * time.Second, PollInterval: 10 * time.Millisecond})
+}
+
+func TestClient(t *testing.T) {
+	client := newTestClient(newA2AServer(t).URL)
+	ctx := context.Background()
+
+	card, err := client.AgentCard(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", card.ID)
+	_, ok := card.Capability("echo")
+	assert.True(t, ok)
+
+	task, err := client.Run(ctx, "echo", map[string]interface{}{"query": "mTLS"})
+	require.NoError(t, err)
+	assert.Equal(t, StateCompleted, task.Status.State, StatusText(task))
+	assert.Equal(t, "mTLS", Result(task)["output"].(map[string]interface{})["query"])
+
+	fetched, err := client.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, fetched.ID)
+	assert.True(t, Finished(fetched))
+
+	_, err = client.CancelTask(ctx, task.ID)
+	var rpcErr *Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeTaskNotCancelable, rpcErr.Code)
+}
+
+func TestClient_ListTasksAndSetBudget(t *testing.T) {
+	client := newTestClient(newA2AServer(t).URL)
+	ctx := context.Background()
+
+	done, err := client.Run(ctx, "echo", map[string]interface{}{"query": "mTLS"})
+	require.NoError(t, err)
+	blocked, err := client.SendMessage(ctx, "block", nil)
+	require.NoError(t, err)
+	defer client.CancelTask(ctx, blocked.ID)
+
+	all, err := client.ListTasks(ctx, TaskFilter{UserID: "user-1"})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, done.ID, all[0].ID)
+	assert.Equal(t, StateCompleted, all[0].Status.State)
+
+	completed, err := client.ListTasks(ctx, TaskFilter{State: StateCompleted})
+	require.NoError(t, err)
+	require.Len(t, completed, 1)
+	assert.Equal(t, done.ID, completed[0].ID)
+	_, err = client.ListTasks(ctx, TaskFilter{State: "done"})
+	assert.Error(t, err)
+
+	page, err := client.ListTaskPage(ctx, TaskFilter{UserID: "user-1", NewestFirst: true, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, blocked.ID, page.Tasks[0].ID)
+	require.NotEmpty(t, page.NextCursor)
+	page, err = client.ListTaskPage(ctx, TaskFilter{UserID: "user-1", NewestFirst: true, Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, done.ID, page.Tasks[0].ID)
+	assert.Empty(t, page.NextCursor)
+
+	admin := New(Config{URL: client.URL(), Token: testAdminToken})
+	budget, err := admin.SetBudget(ctx, "user-2", 25, "")
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, budget.MonthlyLimitUSD)
+	assert.Zero(t, budget.CurrentSpendUSD)
+
+	var statusErr *StatusError
+	_, err = client.SetBudget(ctx, "user-2", 25, "")
+	require.ErrorAs(t, err, &statusErr, "setting budgets takes the admin token")
+	assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := newTestClient(newA2AServer(t).URL)
+	ctx := context.Background()
+	var rpcErr *Error
+
+	_, err := client.SendMessage(ctx, "missing", nil)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+
+	_, err = client.GetTask(ctx, "missing")
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeTaskNotFound, rpcErr.Code)
+
+	_, err = client.Resubscribe(ctx, "missing")
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeTaskNotFound, rpcErr.Code)
+
+	// A user without a budget is refused before a task is created
+	other := New(Config{URL: client.URL(), UserID: "user-2"})
+	_, err = other.SendMessage(ctx, "echo", nil)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+
+	var statusErr *StatusError
+	_, err = New(Config{URL: client.URL() + "/missing"}).GetTask(ctx, "task-1")
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestClient_CancelAndTimeout(t *testing.T) {
+	client := newTestClient(newA2AServer(t).URL)
+	ctx := context.Background()
+
+	task, err := client.SendMessage(ctx, "block", nil)
+	require.NoError(t, err)
+	canceled, err := client.CancelTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateCanceled, canceled.Status.State)
+
+	// Run gives up when ctx expires and cancels the task it started
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = client.Run(timeoutCtx, "block", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_Stream(t *testing.T) {
+	client := newTestClient(newA2AServer(t).URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Stream(ctx, client.NewMessageParams("echo", map[string]interface{}{"query": "mTLS"}))
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var events []Event
+	for event := range stream.Events() {
+		events = append(events, event)
+	}
+	require.NoError(t, stream.Err())
+	require.NotEmpty(t, events)
+	require.NotNil(t, events[0].Task)
+	assert.Equal(t, StateSubmitted, events[0].Task.Status.State)
+	last := events[len(events)-1]
+	assert.True(t, last.Final())
+	assert.Equal(t, StateCompleted, last.Status.Status.State)
+	assert.Equal(t, events[0].Task.ID, last.Status.TaskID)
+
+	// Resubscribing to the finished task replays it up to the final event
+	stream, err = client.Resubscribe(ctx, events[0].Task.ID)
+	require.NoError(t, err)
+	var replayed []Event
+	for event := range stream.Events() {
+		replayed = append(replayed, event)
+	}
+	require.NoError(t, stream.Err())
+	assert.True(t, replayed[len(replayed)-1].Final())
+}
+
+// droppingAgent streams a task's events but drops the first connection after the first
+// event, so the client has to resume with tasks/resubscribe
+type droppingAgent struct {
+	mu          sync.Mutex
+	methods     []string
+	lastEventID []string
+}
+
+func (a *droppingAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	a.mu.Lock()
+	a.methods = append(a.methods, req.Method)
+	a.lastEventID = append(a.lastEventID, r.Header.Get("Last-Event-ID"))
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	write := func(id int64, result interface{}) {
+		body, _ := json.Marshal(protocol.NewJSONRPCResult(req.ID, result))
+		if id > 0 {
+			fmt.Fprintf(w, "id: %d\n", id)
+		}
+		fmt.Fprintf(w, "data: %s\n\n", body)
+		w.(http.Flusher).Flush()
+	}
+	status := func(state string, final bool) protocol.TaskStatusUpdateEvent {
+		return protocol.TaskStatusUpdateEvent{Kind: protocol.KindStatusUpdate, TaskID: "task-1",
+			Status: protocol.A2ATaskStatus{State: state}, Final: final}
+	}
+
+	if req.Method == protocol.MethodMessageStream {
+		write(0, protocol.A2ATask{Kind: protocol.KindTask, ID: "task-1", Status: protocol.A2ATaskStatus{State: StateSubmitted}})
+		write(1, status(StateWorking, false))
+		return
+	}
+	write(2, status(StateCompleted, true))
+}
+
+func TestClient_StreamReconnects(t *testing.T) {
+	agent := &droppingAgent{}
+	srv := httptest.NewServer(agent)
+	defer srv.Close()
+	client := New(Config{URL: srv.URL, ReconnectDelay: time.Millisecond})
+
+	stream, err := client.Stream(context.Background(), client.NewMessageParams("echo", nil))
+	require.NoError(t, err)
+	var ids []int64
+	for event := range stream.Events() {
+		ids = append(ids, event.ID)
+	}
+	require.NoError(t, stream.Err())
+	assert.Equal(t, []int64{0, 1, 2}, ids)
+	assert.Equal(t, []string{protocol.MethodMessageStream, protocol.MethodTasksResubscribe}, agent.methods)
+	assert.Equal(t, []string{"", "1"}, agent.lastEventID)
+
+	// Without reconnects the dropped stream reports why it ended
+	client = New(Config{URL: srv.URL, MaxReconnects: -1})
+	stream, err = client.Stream(context.Background(), client.NewMessageParams("echo", nil))
+	require.NoError(t, err)
+	for range stream.Events() {
+	}
+	assert.True(t, errors.Is(stream.Err(), ErrStreamClosed))
+}
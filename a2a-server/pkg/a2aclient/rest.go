@@ -0,0 +1,200 @@
+package a2aclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// Paths of the agent's REST endpoints, relative to its base URL
+const (
+	TasksPath        = "/tasks"
+	AdminBudgetsPath = "/admin/budgets/"
+)
+
+// TaskFilter selects the tasks ListTasks returns; empty fields match every task
+type TaskFilter struct {
+	AgentID string
+	UserID  string
+	// State is an A2A task state such as StateWorking
+	State string
+	// CreatedAfter and CreatedBefore bound when tasks were created, exclusively
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// NewestFirst lists the most recently created tasks first
+	NewestFirst bool
+	// Limit defaults to the server's page size of 100
+	Limit  int
+	Offset int
+	// Cursor continues a listing after the page whose NextCursor it is
+	Cursor string
+}
+
+// TaskPage is a page of listed tasks
+type TaskPage struct {
+	Tasks []Task
+	// NextCursor continues the listing in TaskFilter.Cursor; it is empty after the last page
+	NextCursor string
+}
+
+// taskStates maps A2A task states to the states the server stores tasks in
+var taskStates = map[string]protocol.TaskState{
+	StateSubmitted:     protocol.TaskStatePending,
+	StateWorking:       protocol.TaskStateRunning,
+	StateInputRequired: protocol.TaskStateInputRequired,
+	StateCompleted:     protocol.TaskStateCompleted,
+	StateFailed:        protocol.TaskStateFailed,
+	StateCanceled:      protocol.TaskStateCancelled,
+}
+
+// Budget is a user's monthly budget and its spend in the current period
+type Budget struct {
+	UserID          string    `json:"user_id,omitempty"`
+	TenantID        string    `json:"tenant_id,omitempty"`
+	MonthlyLimitUSD float64   `json:"monthly_limit_usd"`
+	CurrentSpendUSD float64   `json:"current_spend_usd"`
+	ResetAt         time.Time `json:"reset_at"`
+}
+
+// ListTasks returns the agent's tasks matching filter, oldest first unless
+// filter.NewestFirst. JSON-RPC has no method listing tasks, so they are read from the REST
+// endpoint.
+func (c *Client) ListTasks(ctx context.Context, filter TaskFilter) ([]Task, error) {
+	page, err := c.ListTaskPage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return page.Tasks, nil
+}
+
+// ListTaskPage returns a page of the agent's tasks matching filter along with the cursor
+// of the next page
+func (c *Client) ListTaskPage(ctx context.Context, filter TaskFilter) (*TaskPage, error) {
+	query := url.Values{}
+	if filter.AgentID != "" {
+		query.Set("agent_id", filter.AgentID)
+	}
+	if filter.UserID != "" {
+		query.Set("user_id", filter.UserID)
+	}
+	if filter.State != "" {
+		state, ok := taskStates[filter.State]
+		if !ok {
+			return nil, fmt.Errorf("unknown task state %q", filter.State)
+		}
+		query.Set("state", string(state))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query.Set("created_after", filter.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query.Set("created_before", filter.CreatedBefore.Format(time.RFC3339Nano))
+	}
+	if filter.NewestFirst {
+		query.Set("order", "desc")
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		query.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	if filter.Cursor != "" {
+		query.Set("cursor", filter.Cursor)
+	}
+
+	var stored []*protocol.Task
+	header, err := c.send(ctx, http.MethodGet, TasksPath+"?"+query.Encode(), nil, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	page := &TaskPage{Tasks: make([]Task, len(stored)), NextCursor: nextCursor(header.Get("Link"))}
+	for i, task := range stored {
+		page.Tasks[i] = protocol.ToA2ATask(task)
+	}
+	return page, nil
+}
+
+// nextCursor returns the cursor of the rel="next" target of a Link header, or "" when the
+// header links to no next page
+func nextCursor(link string) string {
+	for _, value := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(value), ";")
+		if !ok || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+		next, err := url.Parse(strings.Trim(target, "<>"))
+		if err != nil {
+			return ""
+		}
+		return next.Query().Get("cursor")
+	}
+	return ""
+}
+
+// SetBudget sets a user's monthly budget and resets its spend. A non-empty tenantID makes
+// it a sub-budget of the tenant's. The client's Token must be the server's admin token.
+func (c *Client) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64, tenantID string) (*Budget, error) {
+	body := map[string]interface{}{"monthly_limit_usd": monthlyLimitUSD}
+	if tenantID != "" {
+		body["tenant_id"] = tenantID
+	}
+	var budget Budget
+	if err := c.rest(ctx, http.MethodPut, AdminBudgetsPath+url.PathEscape(userID), body, &budget); err != nil {
+		return nil, fmt.Errorf("failed to set budget of %s: %w", userID, err)
+	}
+	return &budget, nil
+}
+
+// rest sends a request to one of the agent's REST endpoints and decodes the JSON response
+// into out. Responses other than 200 are returned as *StatusError.
+func (c *Client) rest(ctx context.Context, method, path string, body, out interface{}) error {
+	_, err := c.send(ctx, method, path, body, out)
+	return err
+}
+
+// send is rest returning the response headers as well
+func (c *Client) send(ctx context.Context, method, path string, body, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setHeaders(ctx, req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(data))}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,303 @@
+package a2aclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/protocol"
+)
+
+// ErrStreamClosed is the Err of a stream the agent ended before the task's final event
+// and that could not be resumed
+var ErrStreamClosed = errors.New("A2A stream closed before the task finished")
+
+// Event is one message of a task stream; exactly one of Task, Status and Artifact is set
+type Event struct {
+	// ID is the agent's event ID, zero for the task snapshot that opens a stream
+	ID       int64
+	Task     *Task
+	Status   *TaskStatusUpdateEvent
+	Artifact *TaskArtifactUpdateEvent
+}
+
+// Final reports whether the event is the task's last
+func (e Event) Final() bool {
+	return e.Status != nil && e.Status.Final
+}
+
+// Stream receives the events of one task. When the connection ends before the final
+// event, e.g. because the agent restarted, the stream resumes after the last event
+// received with tasks/resubscribe.
+type Stream struct {
+	client *Client
+	events chan Event
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// taskID and lastID are only used by the goroutine reading the stream
+	taskID string
+	lastID int64
+
+	mu  sync.Mutex
+	err error
+}
+
+// Stream creates a task with message/stream and returns the stream of its events,
+// starting with the task as submitted. The stream ends after the final event, when ctx
+// is done or Close is called.
+func (c *Client) Stream(ctx context.Context, params MessageSendParams) (*Stream, error) {
+	return c.openStream(ctx, protocol.MethodMessageStream, params, "")
+}
+
+// Resubscribe streams the events of an existing task, starting with its current state
+func (c *Client) Resubscribe(ctx context.Context, taskID string) (*Stream, error) {
+	return c.openStream(ctx, protocol.MethodTasksResubscribe, protocol.TaskIDParams{ID: taskID}, taskID)
+}
+
+func (c *Client) openStream(ctx context.Context, method string, params interface{}, taskID string) (*Stream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	body, err := c.connect(ctx, method, params, 0)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("A2A %s: %w", method, err)
+	}
+
+	stream := &Stream{
+		client: c,
+		events: make(chan Event),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		taskID: taskID,
+	}
+	go stream.run(ctx, body)
+	return stream, nil
+}
+
+// connect sends a streaming request and returns the SSE body. A JSON-RPC error the agent
+// answers instead of a stream is returned as *Error.
+func (c *Client) connect(ctx context.Context, method string, params interface{}, lastID int64) (io.ReadCloser, error) {
+	req, err := c.newRPCRequest(ctx, c.nextID.Add(1), method, params)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(lastID, 10))
+	}
+
+	// The stream outlives any request timeout
+	client := *c.client
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return resp.Body, nil
+	}
+
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decodeResponse(resp.StatusCode, data); err != nil {
+		return nil, err
+	}
+	return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(data))}
+}
+
+// Events returns the channel events arrive on; it is closed when the stream ends
+func (s *Stream) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the stream ended early, or nil if it ended with the final event, was
+// closed by the client or still runs
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close ends the stream and waits for it to stop
+func (s *Stream) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// run delivers the events read from body, reconnecting while the task has not finished
+func (s *Stream) run(ctx context.Context, body io.ReadCloser) {
+	defer close(s.done)
+	defer close(s.events)
+	defer s.cancel()
+
+	failures := 0
+	for {
+		received, stop, err := s.read(ctx, body)
+		body.Close()
+		if stop || ctx.Err() != nil {
+			return
+		}
+		if received {
+			failures = 0
+		}
+		if err == nil {
+			err = ErrStreamClosed
+		}
+
+		for body = nil; body == nil; {
+			if s.taskID == "" || failures >= s.client.config.MaxReconnects {
+				s.fail(err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(min(s.client.config.ReconnectDelay<<failures, maxReconnectDelay)):
+			}
+			failures++
+
+			var reconnectErr error
+			body, reconnectErr = s.client.connect(ctx, protocol.MethodTasksResubscribe, protocol.TaskIDParams{ID: s.taskID}, s.lastID)
+			if reconnectErr != nil && !retryable(reconnectErr) {
+				s.fail(fmt.Errorf("A2A tasks/resubscribe: %w", reconnectErr))
+				return
+			}
+			if reconnectErr != nil {
+				err = reconnectErr
+			}
+		}
+	}
+}
+
+// read delivers the SSE events of one connection. It reports whether any event arrived
+// and stops once the final event was delivered or the stream failed for good; err is
+// why the connection ended otherwise. Comments such as keep-alives are skipped.
+func (s *Stream) read(ctx context.Context, body io.Reader) (received, stop bool, err error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), maxResponseBytes)
+	var id int64
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "id:"); ok {
+			id, _ = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		event, err := decodeEvent(id, []byte(data.String()))
+		data.Reset()
+		if err != nil {
+			s.fail(err)
+			return received, true, nil
+		}
+		if s.taskID == "" {
+			s.taskID = event.taskID()
+		}
+		if id > 0 {
+			s.lastID = id
+		}
+		id = 0
+
+		select {
+		case s.events <- event:
+			received = true
+		case <-ctx.Done():
+			return received, true, nil
+		}
+		if event.Final() {
+			return received, true, nil
+		}
+	}
+	return received, false, scanner.Err()
+}
+
+// decodeEvent parses the JSON-RPC response carried by one SSE message
+func decodeEvent(id int64, data []byte) (Event, error) {
+	rpc, err := decodeResponse(http.StatusOK, data)
+	if err != nil {
+		return Event{}, fmt.Errorf("A2A stream: %w", err)
+	}
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(rpc.Result, &kind); err != nil {
+		return Event{}, fmt.Errorf("invalid A2A stream event: %w", err)
+	}
+
+	event := Event{ID: id}
+	var target interface{}
+	switch kind.Kind {
+	case protocol.KindTask:
+		event.Task = &Task{}
+		target = event.Task
+	case protocol.KindStatusUpdate:
+		event.Status = &TaskStatusUpdateEvent{}
+		target = event.Status
+	case protocol.KindArtifactUpdate:
+		event.Artifact = &TaskArtifactUpdateEvent{}
+		target = event.Artifact
+	default:
+		return Event{}, fmt.Errorf("invalid A2A stream event: unknown kind %q", kind.Kind)
+	}
+	if err := json.Unmarshal(rpc.Result, target); err != nil {
+		return Event{}, fmt.Errorf("invalid A2A stream event: %w", err)
+	}
+	return event, nil
+}
+
+// taskID returns the ID of the task the event belongs to
+func (e Event) taskID() string {
+	switch {
+	case e.Task != nil:
+		return e.Task.ID
+	case e.Status != nil:
+		return e.Status.TaskID
+	case e.Artifact != nil:
+		return e.Artifact.TaskID
+	}
+	return ""
+}
+
+// retryable reports whether reconnecting may succeed after err: connection errors and
+// overloaded or restarting agents clear up, JSON-RPC errors such as an unknown task do not
+func retryable(err error) bool {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func (s *Stream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
@@ -0,0 +1,323 @@
+// Code generated by a2a-server/cmd/tsgen. DO NOT EDIT.
+// A2A types: the REST API (/agent, /tasks, /schedules) and the JSON-RPC endpoint (/a2a).
+
+export const JSONRPCVersion = "2.0";
+export const MethodMessageSend = "message/send";
+export const MethodMessageStream = "message/stream";
+export const MethodTasksGet = "tasks/get";
+export const MethodTasksCancel = "tasks/cancel";
+export const MethodTasksResubscribe = "tasks/resubscribe";
+export const MethodPushConfigSet = "tasks/pushNotificationConfig/set";
+export const MethodPushConfigGet = "tasks/pushNotificationConfig/get";
+export const ParseError = -32700;
+export const InvalidRequest = -32600;
+export const MethodNotFound = -32601;
+export const InvalidParams = -32602;
+export const InternalError = -32603;
+export const TaskNotFound = -32001;
+export const TaskNotCancelable = -32002;
+export const PushNotificationNotSupported = -32003;
+export const UnsupportedOperation = -32004;
+export const ContentTypeNotSupported = -32005;
+export const BudgetExceeded = -32010;
+export const RequestTooLarge = -32011;
+export const ServerBusy = -32012;
+export const TaskNotAwaitingInput = -32013;
+
+export type TaskState = "pending" | "running" | "input_required" | "completed" | "failed" | "cancelled";
+
+export type TaskPriority = "high" | "normal" | "low";
+
+export interface AgentCard {
+  id: string;
+  name: string;
+  version: string;
+  description: string;
+  capabilities: Capability[];
+}
+
+export interface CreateTaskRequest {
+  user_id: string;
+  agent_id: string;
+  capability: string;
+  input: Record<string, unknown>;
+  capability_version?: string;
+  message?: Message;
+  priority?: TaskPriority;
+  depends_on?: string[];
+  max_cost_usd?: number;
+  ttl_seconds?: number;
+  speculative?: boolean;
+  webhook?: PushNotificationConfig;
+}
+
+export interface InputValidationResponse {
+  error: string;
+  errors: FieldError[];
+}
+
+export interface TaskEstimate {
+  capability: string;
+  model?: string;
+  prompt_tokens: number;
+  completion_tokens: number;
+  cost_usd: number;
+  source: string;
+  remaining_budget_usd: number;
+  admitted: boolean;
+  exceeded?: BudgetExceededError;
+}
+
+export interface Task {
+  id: string;
+  agent_id: string;
+  context_id?: string;
+  user_id?: string;
+  capability: string;
+  priority?: TaskPriority;
+  input?: Record<string, unknown>;
+  state: TaskState;
+  result?: Record<string, unknown>;
+  artifacts?: Artifact[];
+  error?: string;
+  input_hash?: string;
+  result_hash?: string;
+  created_at: string;
+  updated_at: string;
+  completed_at?: string;
+  message?: Message;
+  output?: Message;
+  speculative?: boolean;
+  speculation?: SpeculationDecision;
+  depends_on?: string[];
+  max_cost_usd?: number;
+  trace_context?: Record<string, string>;
+  attempts?: number;
+  lease?: TaskLease;
+  ttl_seconds?: number;
+  capability_version?: string;
+}
+
+export interface TaskEvent {
+  id?: number;
+  task_id: string;
+  state: TaskState;
+  message?: string;
+  data?: Record<string, unknown>;
+  timestamp: string;
+  worker_id?: string;
+  artifact?: Artifact;
+  append?: boolean;
+  last_chunk?: boolean;
+}
+
+export interface TaskGraph {
+  task_id: string;
+  nodes: TaskGraphNode[];
+  truncated?: boolean;
+}
+
+export interface TaskInputRequest {
+  text?: string;
+  data?: Record<string, unknown>;
+}
+
+export interface ScheduleRequest {
+  user_id: string;
+  agent_id: string;
+  capability: string;
+  input: Record<string, unknown>;
+  priority?: TaskPriority;
+  max_cost_usd?: number;
+  cron?: string;
+  rrule?: string;
+  timezone?: string;
+  start_at?: string;
+  paused?: boolean;
+}
+
+export interface Schedule {
+  id: string;
+  user_id: string;
+  agent_id: string;
+  capability: string;
+  input?: Record<string, unknown>;
+  priority?: TaskPriority;
+  max_cost_usd?: number;
+  cron?: string;
+  rrule?: string;
+  timezone?: string;
+  start_at: string;
+  paused?: boolean;
+  next_run_at?: string;
+  last_run_at?: string;
+  last_task_id?: string;
+  last_error?: string;
+  runs: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface JSONRPCError {
+  code: number;
+  message: string;
+  data?: unknown;
+}
+
+export interface JSONRPCRequest {
+  jsonrpc: string;
+  id?: unknown;
+  method: string;
+  params?: unknown;
+}
+
+export interface JSONRPCResponse {
+  jsonrpc: string;
+  id: unknown;
+  result?: unknown;
+  error?: JSONRPCError;
+}
+
+export interface MessageSendParams {
+  message: Message;
+  configuration?: MessageSendConfiguration;
+  metadata?: Record<string, unknown>;
+}
+
+export interface TaskPushNotificationConfig {
+  taskId: string;
+  pushNotificationConfig: PushNotificationConfig;
+}
+
+export interface TaskQueryParams {
+  id: string;
+  historyLength?: number;
+}
+
+export interface TaskIDParams {
+  id: string;
+}
+
+export interface A2ATask {
+  kind: string;
+  id: string;
+  contextId: string;
+  status: A2ATaskStatus;
+  artifacts?: Artifact[];
+  metadata?: Record<string, unknown>;
+  history?: Message[];
+}
+
+export interface TaskStatusUpdateEvent {
+  kind: string;
+  taskId: string;
+  contextId: string;
+  status: A2ATaskStatus;
+  final: boolean;
+  metadata?: Record<string, unknown>;
+}
+
+export interface TaskArtifactUpdateEvent {
+  kind: string;
+  taskId: string;
+  contextId: string;
+  artifact: Artifact;
+  append?: boolean;
+  lastChunk?: boolean;
+}
+
+export interface Capability {
+  name: string;
+  description: string;
+  input_schema?: Record<string, unknown>;
+  output_schema?: Record<string, unknown>;
+  substitute_group?: string;
+  estimated_cost_usd?: number;
+  version?: string;
+  deprecated?: boolean;
+  deprecation_message?: string;
+}
+
+export interface Message {
+  kind: string;
+  role: string;
+  parts: Part[];
+  messageId: string;
+  taskId?: string;
+  contextId?: string;
+  metadata?: Record<string, unknown>;
+}
+
+export interface PushNotificationConfig {
+  url: string;
+  token?: string;
+  secret?: string;
+}
+
+export interface FieldError {
+  field: string;
+  message: string;
+}
+
+export interface BudgetExceededError {
+  level: string;
+  id: string;
+  limit_usd: number;
+  spend_usd: number;
+  cost_usd: number;
+}
+
+export interface Artifact {
+  artifactId: string;
+  name?: string;
+  description?: string;
+  parts: Part[];
+}
+
+export interface SpeculationDecision {
+  launched: string[];
+  winner?: string;
+  cancelled?: string[];
+  rejected?: Record<string, string>;
+  cost_cap_usd: number;
+  estimated_cost_usd: number;
+  latency_ms: number;
+}
+
+export interface TaskLease {
+  owner: string;
+  heartbeat_at: string;
+  expires_at: string;
+}
+
+export interface TaskGraphNode {
+  id: string;
+  capability: string;
+  state: TaskState;
+  depends_on?: string[];
+  error?: string;
+}
+
+export interface MessageSendConfiguration {
+  pushNotificationConfig?: PushNotificationConfig;
+}
+
+export interface A2ATaskStatus {
+  state: string;
+  message?: Message;
+  timestamp: string;
+}
+
+export interface Part {
+  kind: string;
+  text?: string;
+  data?: Record<string, unknown>;
+  file?: FileContent;
+}
+
+export interface FileContent {
+  name?: string;
+  mimeType?: string;
+  bytes?: string;
+  uri?: string;
+}
@@ -0,0 +1,260 @@
+// Code generated by mcp-server/cmd/tsgen. DO NOT EDIT.
+// MCP protocol types as sent over JSON-RPC by the MCP server.
+
+export const JSONRPCVersion = "2.0";
+export const MethodInitialize = "initialize";
+export const MethodInitialized = "notifications/initialized";
+export const MethodToolsList = "tools/list";
+export const MethodToolsCall = "tools/call";
+export const MethodResourcesList = "resources/list";
+export const MethodResourcesRead = "resources/read";
+export const MethodPromptsList = "prompts/list";
+export const MethodPromptsGet = "prompts/get";
+export const MethodProgress = "notifications/progress";
+export const MethodSamplingCreateMessage = "sampling/createMessage";
+export const ParseError = -32700;
+export const InvalidRequest = -32600;
+export const MethodNotFound = -32601;
+export const InvalidParams = -32602;
+export const InternalError = -32603;
+export const ServerError = -32000;
+export const AuthenticationRequired = -32001;
+export const AuthorizationFailed = -32002;
+export const RateLimitExceeded = -32003;
+export const ResourceNotFound = -32004;
+export const ValidationError = -32005;
+export const RequestTimeout = -32006;
+export const SafeModeActive = -32007;
+export const RequestTooLarge = -32008;
+export const QuotaExceeded = -32009;
+export const WarningDeprecated = "deprecated";
+export const ContentTypeText = "text";
+export const ContentTypeImage = "image";
+export const ContentTypeResource = "resource";
+export const ContentTypeResourceLink = "resource_link";
+
+export interface JSONRPCRequest {
+  jsonrpc: string;
+  id?: unknown;
+  method: string;
+  params?: unknown;
+}
+
+export interface JSONRPCResponse {
+  jsonrpc: string;
+  id?: unknown;
+  result?: unknown;
+  error?: JSONRPCError;
+  warnings?: Warning[];
+}
+
+export interface JSONRPCError {
+  code: number;
+  message: string;
+  data?: unknown;
+}
+
+export interface InitializeRequest {
+  protocolVersion: string;
+  capabilities: ClientCapabilities;
+  clientInfo: ClientInfo;
+  metadata?: Record<string, unknown>;
+}
+
+export interface InitializeResult {
+  protocolVersion: string;
+  capabilities: ServerCapabilities;
+  serverInfo: ServerInfo;
+}
+
+export interface ToolsListResult {
+  tools: Tool[];
+}
+
+export interface ToolCallRequest {
+  name: string;
+  arguments?: Record<string, unknown>;
+}
+
+export interface ToolCallResult {
+  content: ContentBlock[];
+  isError?: boolean;
+  is_truncated?: boolean;
+  continuation_token?: string;
+}
+
+export interface ResourcesListResult {
+  resources: Resource[];
+}
+
+export interface ResourceReadRequest {
+  uri: string;
+}
+
+export interface ResourceReadResult {
+  contents: ResourceContents[];
+}
+
+export interface PromptsListResult {
+  prompts: Prompt[];
+}
+
+export interface PromptGetRequest {
+  name: string;
+  arguments?: Record<string, unknown>;
+}
+
+export interface PromptGetResult {
+  messages: PromptMessage[];
+}
+
+export interface ProgressNotification {
+  progressToken: string;
+  progress: number;
+  total?: number;
+}
+
+export interface CreateMessageRequest {
+  messages: SamplingMessage[];
+  modelPreferences?: ModelPreferences;
+  systemPrompt?: string;
+  includeContext?: string;
+  temperature?: number;
+  maxTokens: number;
+  stopSequences?: string[];
+}
+
+export interface CreateMessageResult {
+  role: string;
+  content: ContentBlock;
+  model: string;
+  stopReason?: string;
+}
+
+export interface Warning {
+  code: string;
+  message: string;
+  sunset?: string;
+  replacement?: string;
+}
+
+export interface ClientCapabilities {
+  tools?: ToolCapabilities;
+  resources?: ResourceCapabilities;
+  prompts?: PromptCapabilities;
+  sampling?: SamplingCapability;
+}
+
+export interface ClientInfo {
+  name: string;
+  version: string;
+}
+
+export interface ServerCapabilities {
+  tools?: ToolsCapability;
+  resources?: ResourcesCapability;
+  prompts?: PromptsCapability;
+}
+
+export interface ServerInfo {
+  name: string;
+  version: string;
+}
+
+export interface Tool {
+  name: string;
+  description: string;
+  inputSchema: Record<string, unknown>;
+  annotations?: ToolAnnotations;
+}
+
+export interface ContentBlock {
+  type: string;
+  text?: string;
+  data?: string;
+  mimeType?: string;
+  uri?: string;
+  name?: string;
+  size?: number;
+}
+
+export interface Resource {
+  uri: string;
+  name: string;
+  description?: string;
+  mimeType?: string;
+  metadata?: Record<string, unknown>;
+}
+
+export interface ResourceContents {
+  uri: string;
+  mimeType?: string;
+  text?: string;
+  blob?: string;
+}
+
+export interface Prompt {
+  name: string;
+  description?: string;
+  arguments?: PromptArgument[];
+}
+
+export interface PromptMessage {
+  role: string;
+  content: ContentBlock;
+}
+
+export interface SamplingMessage {
+  role: string;
+  content: ContentBlock;
+}
+
+export interface ModelPreferences {
+  hints?: ModelHint[];
+  costPriority?: number;
+  speedPriority?: number;
+  intelligencePriority?: number;
+}
+
+export interface ToolCapabilities {
+  supportsProgress?: boolean;
+}
+
+export interface ResourceCapabilities {
+  supportsSubscribe?: boolean;
+}
+
+export interface PromptCapabilities {
+  supportsTemplates?: boolean;
+}
+
+export interface SamplingCapability {
+}
+
+export interface ToolsCapability {
+  listChanged?: boolean;
+}
+
+export interface ResourcesCapability {
+  listChanged?: boolean;
+  subscribe?: boolean;
+}
+
+export interface PromptsCapability {
+  listChanged?: boolean;
+}
+
+export interface ToolAnnotations {
+  disabled?: boolean;
+  disabledReason?: string;
+}
+
+export interface PromptArgument {
+  name: string;
+  description?: string;
+  required?: boolean;
+}
+
+export interface ModelHint {
+  name?: string;
+}
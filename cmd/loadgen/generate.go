@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// maxErrorSamples bounds the distinct error messages kept per operation
+const maxErrorSamples = 5
+
+// schedule is the shape of the load
+type schedule struct {
+	rps         float64
+	duration    time.Duration
+	concurrency int
+	timeout     time.Duration
+}
+
+// results are the outcomes of every request of a run
+type results struct {
+	elapsed    time.Duration
+	operations map[string]*opResults
+}
+
+// opResults are the outcomes of one operation's requests
+type opResults struct {
+	latencies []time.Duration // of successful requests
+	errors    int
+	dropped   int
+	// errorSamples counts the first distinct error messages
+	errorSamples map[string]int
+}
+
+// generate sends requests open loop: one is due every 1/rps regardless of how long
+// earlier ones take, so a slow server shows up as latency and dropped requests rather
+// than as a lower request rate. A request due while concurrency requests are in flight
+// is dropped.
+func generate(ctx context.Context, ops map[string]operation, m mix, s schedule, rng *rand.Rand) *results {
+	res := &results{operations: make(map[string]*opResults)}
+	for _, name := range m.names {
+		res.operations[name] = &opResults{errorSamples: make(map[string]int)}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, s.concurrency)
+	interval := time.Duration(float64(time.Second) / s.rps)
+	start := time.Now()
+	deadline := start.Add(s.duration)
+
+	for next := start; next.Before(deadline); next = next.Add(interval) {
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		name := m.pick(rng)
+		stats := res.operations[name]
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			mu.Lock()
+			stats.dropped++
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(op operation) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			reqCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			began := time.Now()
+			err := op(reqCtx)
+			latency := time.Since(began)
+			cancel()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				stats.errors++
+				msg := err.Error()
+				if _, ok := stats.errorSamples[msg]; ok || len(stats.errorSamples) < maxErrorSamples {
+					stats.errorSamples[msg]++
+				}
+				return
+			}
+			stats.latencies = append(stats.latencies, latency)
+		}(ops[name])
+	}
+	wg.Wait()
+	// The last request is due one interval before the deadline
+	res.elapsed = time.Since(start)
+	if res.elapsed < s.duration && ctx.Err() == nil {
+		res.elapsed = s.duration
+	}
+	return res
+}
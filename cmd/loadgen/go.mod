@@ -0,0 +1,29 @@
+module github.com/bhatti/mcp-a2a-go/cmd/loadgen
+
+go 1.25.0
+
+require (
+	github.com/bhatti/mcp-a2a-go/a2a-server v0.0.0
+	github.com/bhatti/mcp-a2a-go/mcp-server v0.0.0
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/bhatti/mcp-a2a-go/shared v0.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel v1.38.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	go.opentelemetry.io/otel/trace v1.38.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/bhatti/mcp-a2a-go/a2a-server => ../../a2a-server
+	github.com/bhatti/mcp-a2a-go/mcp-server => ../../mcp-server
+	github.com/bhatti/mcp-a2a-go/shared => ../../shared
+)
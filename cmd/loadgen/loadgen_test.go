@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	m, err := parseMix("tools_list, search=3 ,hybrid_search=0.5,a2a_task=0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tools_list", "search", "hybrid_search"}, m.names)
+	assert.Equal(t, 4.5, m.total)
+	assert.True(t, m.needsMCP())
+	assert.False(t, m.has(opA2ATask))
+
+	for _, spec := range []string{"", "search=0", "list_documents=1", "search=-1", "search=x", "search,search=2"} {
+		_, err := parseMix(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMixPick(t *testing.T) {
+	m, err := parseMix("tools_list=1,search=3")
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 4000; i++ {
+		counts[m.pick(rng)]++
+	}
+	assert.InDelta(t, 1000, counts[opToolsList], 100)
+	assert.InDelta(t, 3000, counts[opSearch], 100)
+}
+
+func TestSummarize(t *testing.T) {
+	r := &opResults{errors: 1, dropped: 1, errorSamples: map[string]int{"boom": 1}}
+	for i := 1; i <= 98; i++ {
+		r.latencies = append(r.latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	op := summarize(opSearch, r)
+	assert.Equal(t, 100, op.Requests)
+	assert.Equal(t, 0.02, op.ErrorRate)
+	assert.Equal(t, 1.0, op.Latency.Min)
+	assert.Equal(t, 49.0, op.Latency.P50)
+	assert.Equal(t, 98.0, op.Latency.P99)
+	assert.Equal(t, 98.0, op.Latency.Max)
+	assert.Equal(t, []bucket{
+		{LEMS: 1, Count: 1}, {LEMS: 2.5, Count: 1}, {LEMS: 5, Count: 3}, {LEMS: 10, Count: 5},
+		{LEMS: 25, Count: 15}, {LEMS: 50, Count: 25}, {LEMS: 100, Count: 48},
+	}, op.Histogram)
+
+	empty := summarize(opToolsList, &opResults{})
+	assert.Zero(t, empty.Requests)
+	assert.Empty(t, empty.Histogram)
+}
+
+func TestNewReport_Budgets(t *testing.T) {
+	cfg := &config{rps: 10, maxErrorRate: 0.05, maxP99: 50 * time.Millisecond}
+	res := &results{elapsed: time.Second, operations: map[string]*opResults{
+		opSearch:    {latencies: []time.Duration{10 * time.Millisecond, 80 * time.Millisecond}},
+		opToolsList: {latencies: []time.Duration{time.Millisecond}, errors: 1},
+	}}
+
+	rep := newReport(cfg, res)
+	assert.False(t, rep.Passed)
+	assert.Equal(t, []string{
+		"tools_list: error rate 50.00% exceeds the budget of 5.00%",
+		"search: p99 latency 80.0ms exceeds the budget of 50ms",
+	}, rep.Violations)
+	assert.Equal(t, 4, rep.Total.Requests)
+	assert.Equal(t, 4.0, rep.AchievedRPS)
+
+	cfg.maxErrorRate, cfg.maxP99 = 0.5, 0
+	assert.True(t, newReport(cfg, res).Passed)
+}
+
+func TestGenerate(t *testing.T) {
+	m, err := parseMix("tools_list=1,search=1")
+	require.NoError(t, err)
+	var calls atomic.Int32
+	ops := map[string]operation{
+		opToolsList: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+		opSearch: func(ctx context.Context) error {
+			calls.Add(1)
+			return errors.New("search failed")
+		},
+	}
+
+	res := generate(context.Background(), ops, m, schedule{rps: 200, duration: 250 * time.Millisecond, concurrency: 10, timeout: time.Second},
+		rand.New(rand.NewSource(1)))
+	list, search := res.operations[opToolsList], res.operations[opSearch]
+	assert.Equal(t, 50, len(list.latencies)+len(search.latencies)+list.errors+search.errors)
+	assert.EqualValues(t, 50, calls.Load())
+	assert.Empty(t, search.latencies)
+	assert.Equal(t, map[string]int{"search failed": search.errors}, search.errorSamples)
+}
+
+func TestGenerate_DropsBeyondConcurrency(t *testing.T) {
+	m, err := parseMix("search")
+	require.NoError(t, err)
+	release := make(chan struct{})
+	ops := map[string]operation{opSearch: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}
+	time.AfterFunc(200*time.Millisecond, func() { close(release) })
+
+	res := generate(context.Background(), ops, m, schedule{rps: 100, duration: 100 * time.Millisecond, concurrency: 2, timeout: time.Second},
+		rand.New(rand.NewSource(1)))
+	assert.Len(t, res.operations[opSearch].latencies, 2)
+	assert.Equal(t, 8, res.operations[opSearch].dropped)
+}
+
+// newFakeServers serves just enough MCP and A2A to run every operation
+func newFakeServers(t *testing.T) (mcp, a2a *httptest.Server) {
+	t.Helper()
+	rpc := func(result func(method string) interface{}) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				ID     int64  `json:"id"`
+				Method string `json:"method"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result(req.Method)})
+		}
+	}
+
+	mcp = httptest.NewServer(rpc(func(method string) interface{} {
+		switch method {
+		case "initialize":
+			return map[string]interface{}{"protocolVersion": "2024-11-05", "serverInfo": map[string]string{"name": "fake"}}
+		case "tools/list":
+			return map[string]interface{}{"tools": []interface{}{}}
+		default:
+			return map[string]interface{}{"content": []map[string]string{{"type": "text", "text": "ok"}}}
+		}
+	}))
+	t.Cleanup(mcp.Close)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/agent", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "agent-1"})
+	})
+	mux.Handle("/a2a", rpc(func(method string) interface{} {
+		return map[string]interface{}{"kind": "task", "id": "task-1", "status": map[string]string{"state": "submitted"}}
+	}))
+	a2a = httptest.NewServer(mux)
+	t.Cleanup(a2a.Close)
+	return mcp, a2a
+}
+
+func TestRun(t *testing.T) {
+	mcp, a2a := newFakeServers(t)
+	cfg, err := parseFlags([]string{
+		"-mcp-url", mcp.URL, "-a2a-url", a2a.URL, "-token", "token",
+		"-mix", "tools_list,search,hybrid_search,list,retrieve,a2a_task", "-embedding-dims", "8", "-document-id", "doc-1",
+		"-rps", "100", "-duration", "200ms", "-max-p99", "5s", "-json",
+	})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	passed, err := run(context.Background(), cfg, &out)
+	require.NoError(t, err)
+	assert.True(t, passed, out.String())
+
+	var rep report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &rep))
+	assert.Equal(t, 20, rep.Total.Requests)
+	assert.Zero(t, rep.Total.Errors)
+	assert.Len(t, rep.Operations, 6)
+
+	cfg.jsonOutput = false
+	out.Reset()
+	_, err = run(context.Background(), cfg, &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "OPERATION")
+	assert.Contains(t, out.String(), "PASS: all operations are within their budgets")
+}
+
+func TestParseFlags_Invalid(t *testing.T) {
+	t.Setenv("MCP_TOKEN", "")
+	for _, args := range [][]string{
+		{"-mix", "search"},
+		{"-token", "t", "-rps", "0"},
+		{"-token", "t", "-max-error-rate", "2"},
+		{"-mix", "unknown"},
+		{"-token", "t", "-mix", "retrieve"},
+	} {
+		_, err := parseFlags(args)
+		assert.Error(t, err, args)
+	}
+
+	cfg, err := parseFlags([]string{"-mix", "a2a_task"})
+	require.NoError(t, err)
+	assert.True(t, cfg.mix.has(opA2ATask))
+}
@@ -0,0 +1,156 @@
+// Command loadgen drives a running MCP server and A2A agent with a weighted mix of
+// operations at a fixed request rate, then prints per-operation latency percentiles and
+// histograms and checks them against error and latency budgets. It exits non-zero when
+// a budget is exceeded, so a release pipeline can catch performance regressions in the
+// handlers and the database layer.
+//
+//	export MCP_TOKEN=$(mcpctl token --key /tmp/demo-keys/private_key.pem)
+//	loadgen -rps 50 -duration 1m -mix tools_list=1,search=3,hybrid_search=2,a2a_task=1 \
+//	    -max-error-rate 0.01 -max-p99 250ms
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// Defaults of the flags, matching the servers' default ports
+const (
+	defaultMCPURL = "http://localhost:8080/mcp"
+	defaultA2AURL = "http://localhost:8081"
+	defaultMix    = "tools_list=1,search=3,hybrid_search=2"
+)
+
+// config holds the flags of one run
+type config struct {
+	mcpURL        string
+	a2aURL        string
+	token         string
+	userID        string
+	capability    string
+	query         string
+	documentID    string
+	embeddingDims int
+	cancelTasks   bool
+
+	mix         mix
+	rps         float64
+	duration    time.Duration
+	concurrency int
+	timeout     time.Duration
+	seed        int64
+
+	maxErrorRate float64
+	maxP99       time.Duration
+	jsonOutput   bool
+}
+
+func main() {
+	cfg, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	passed, err := run(ctx, cfg, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		os.Exit(2)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+// parseFlags parses the command line into a config
+func parseFlags(args []string) (*config, error) {
+	cfg := &config{}
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.StringVar(&cfg.mcpURL, "mcp-url", getEnv("MCP_URL", defaultMCPURL), "MCP server JSON-RPC endpoint (env MCP_URL)")
+	fs.StringVar(&cfg.a2aURL, "a2a-url", getEnv("A2A_URL", defaultA2AURL), "A2A agent base URL (env A2A_URL)")
+	fs.StringVar(&cfg.token, "token", os.Getenv("MCP_TOKEN"), "bearer token for the MCP server (env MCP_TOKEN)")
+	fs.StringVar(&cfg.userID, "user", "loadgen", "user_id of the A2A tasks, whose budget pays for them")
+	fs.StringVar(&cfg.capability, "capability", "search_papers", "capability of the A2A tasks")
+	fs.StringVar(&cfg.query, "query", "security policy", "query of the searches and A2A tasks")
+	fs.StringVar(&cfg.documentID, "document-id", "", "document the retrieve operation fetches")
+	fs.IntVar(&cfg.embeddingDims, "embedding-dims", 0, "send a random query embedding of this size with hybrid_search (0: none)")
+	fs.BoolVar(&cfg.cancelTasks, "cancel-tasks", true, "cancel each A2A task after creating it so tasks do not pile up")
+	mixSpec := fs.String("mix", defaultMix, "weighted operations: "+operationList())
+	fs.Float64Var(&cfg.rps, "rps", 20, "target requests per second")
+	fs.DurationVar(&cfg.duration, "duration", 30*time.Second, "how long to send requests")
+	fs.IntVar(&cfg.concurrency, "concurrency", 64, "maximum requests in flight; requests due beyond it are dropped")
+	fs.DurationVar(&cfg.timeout, "timeout", 10*time.Second, "timeout of each request")
+	fs.Int64Var(&cfg.seed, "seed", 0, "seed of the operation mix (0: random)")
+	fs.Float64Var(&cfg.maxErrorRate, "max-error-rate", 0.01, "error budget: highest share of failed or dropped requests per operation")
+	fs.DurationVar(&cfg.maxP99, "max-p99", 0, "latency budget: highest p99 latency per operation (0: none)")
+	fs.BoolVar(&cfg.jsonOutput, "json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	m, err := parseMix(*mixSpec)
+	if err != nil {
+		return nil, err
+	}
+	cfg.mix = m
+	switch {
+	case cfg.rps <= 0:
+		return nil, fmt.Errorf("-rps must be positive")
+	case cfg.duration <= 0:
+		return nil, fmt.Errorf("-duration must be positive")
+	case cfg.concurrency <= 0:
+		return nil, fmt.Errorf("-concurrency must be positive")
+	case cfg.maxErrorRate < 0 || cfg.maxErrorRate > 1:
+		return nil, fmt.Errorf("-max-error-rate must be between 0 and 1")
+	case cfg.mix.needsMCP() && cfg.token == "":
+		return nil, fmt.Errorf("a token is required for MCP operations: pass -token or set MCP_TOKEN")
+	case cfg.mix.has(opRetrieve) && cfg.documentID == "":
+		return nil, fmt.Errorf("-document-id is required for the retrieve operation")
+	}
+	if cfg.seed == 0 {
+		cfg.seed = time.Now().UnixNano()
+	}
+	return cfg, nil
+}
+
+// run connects to the services, generates the load and writes the report. It returns
+// whether every operation stayed within its budgets.
+func run(ctx context.Context, cfg *config, out io.Writer) (bool, error) {
+	ops, closeOps, err := newOperations(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	defer closeOps()
+
+	results := generate(ctx, ops, cfg.mix, schedule{
+		rps:         cfg.rps,
+		duration:    cfg.duration,
+		concurrency: cfg.concurrency,
+		timeout:     cfg.timeout,
+	}, rand.New(rand.NewSource(cfg.seed)))
+
+	rep := newReport(cfg, results)
+	if cfg.jsonOutput {
+		err = rep.writeJSON(out)
+	} else {
+		err = rep.writeText(out)
+	}
+	return rep.Passed, err
+}
+
+// getEnv returns an environment variable or a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
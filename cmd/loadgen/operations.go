@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/pkg/a2aclient"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/pkg/mcpclient"
+)
+
+// Operations the load mix can contain
+const (
+	opToolsList    = "tools_list"
+	opSearch       = "search"
+	opHybridSearch = "hybrid_search"
+	opList         = "list"
+	opRetrieve     = "retrieve"
+	opA2ATask      = "a2a_task"
+)
+
+// operationNames lists the operations in report order
+var operationNames = []string{opToolsList, opSearch, opHybridSearch, opList, opRetrieve, opA2ATask}
+
+// operation sends one request and returns its error
+type operation func(ctx context.Context) error
+
+// operationList describes the operations for the -mix flag
+func operationList() string {
+	return strings.Join(operationNames, ", ")
+}
+
+// mix is a weighted set of operations
+type mix struct {
+	names   []string
+	weights []float64
+	total   float64
+}
+
+// parseMix parses "name=weight,..." where a bare name has weight 1
+func parseMix(spec string) (mix, error) {
+	var m mix
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, weightText, hasWeight := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		weight := 1.0
+		if hasWeight {
+			w, err := strconv.ParseFloat(strings.TrimSpace(weightText), 64)
+			if err != nil || w < 0 {
+				return mix{}, fmt.Errorf("invalid weight in mix entry %q", entry)
+			}
+			weight = w
+		}
+		if !isOperation(name) {
+			return mix{}, fmt.Errorf("unknown operation %q in mix: expected one of %s", name, operationList())
+		}
+		if seen[name] {
+			return mix{}, fmt.Errorf("operation %q appears twice in mix", name)
+		}
+		seen[name] = true
+		if weight == 0 {
+			continue
+		}
+		m.names = append(m.names, name)
+		m.weights = append(m.weights, weight)
+		m.total += weight
+	}
+	if m.total == 0 {
+		return mix{}, fmt.Errorf("the mix has no operation with a positive weight")
+	}
+	return m, nil
+}
+
+// pick chooses an operation with probability proportional to its weight
+func (m mix) pick(rng *rand.Rand) string {
+	r := rng.Float64() * m.total
+	for i, w := range m.weights {
+		if r < w {
+			return m.names[i]
+		}
+		r -= w
+	}
+	return m.names[len(m.names)-1]
+}
+
+// has reports whether the mix contains an operation
+func (m mix) has(name string) bool {
+	for _, n := range m.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// needsMCP reports whether the mix contains an MCP operation
+func (m mix) needsMCP() bool {
+	return m.has(opToolsList) || m.has(opSearch) || m.has(opHybridSearch) || m.has(opList) || m.has(opRetrieve)
+}
+
+func isOperation(name string) bool {
+	for _, n := range operationNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newOperations connects to the services the mix needs and returns its operations and
+// a function that closes the connections
+func newOperations(ctx context.Context, cfg *config) (map[string]operation, func(), error) {
+	ops := make(map[string]operation)
+	closeOps := func() {}
+
+	if cfg.mix.needsMCP() {
+		// Retries would hide rate limiting and overload and skew the latencies
+		client := mcpclient.New(mcpclient.Config{
+			URL:           cfg.mcpURL,
+			Token:         cfg.token,
+			Timeout:       cfg.timeout,
+			MaxRetries:    -1,
+			ClientName:    "loadgen",
+			ClientVersion: "1.0.0",
+		})
+		if _, err := client.Initialize(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize the MCP session: %w", err)
+		}
+		closeOps = func() {
+			closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			client.Close(closeCtx)
+		}
+
+		ops[opToolsList] = func(ctx context.Context) error {
+			_, err := client.ListTools(ctx)
+			return err
+		}
+		ops[opSearch] = callTool(client, "search_documents", func() map[string]interface{} {
+			return map[string]interface{}{"query": cfg.query, "limit": 10}
+		})
+		ops[opHybridSearch] = callTool(client, "hybrid_search", func() map[string]interface{} {
+			args := map[string]interface{}{"query": cfg.query, "limit": 10}
+			if cfg.embeddingDims > 0 {
+				args["embedding"] = randomEmbedding(cfg.embeddingDims)
+			}
+			return args
+		})
+		ops[opList] = callTool(client, "list_documents", func() map[string]interface{} {
+			return map[string]interface{}{"limit": 10}
+		})
+		ops[opRetrieve] = callTool(client, "retrieve_document", func() map[string]interface{} {
+			return map[string]interface{}{"document_id": cfg.documentID}
+		})
+	}
+
+	if cfg.mix.has(opA2ATask) {
+		client := a2aclient.New(a2aclient.Config{URL: cfg.a2aURL, UserID: cfg.userID, Timeout: cfg.timeout})
+		if _, err := client.AgentCard(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch the agent card: %w", err)
+		}
+		input := map[string]interface{}{"query": cfg.query}
+		ops[opA2ATask] = func(ctx context.Context) error {
+			task, err := client.SendMessage(ctx, cfg.capability, input)
+			if err != nil {
+				return err
+			}
+			if cfg.cancelTasks && !a2aclient.Finished(task) {
+				// Not timed: only task creation is measured
+				go client.CancelTask(context.WithoutCancel(ctx), task.ID)
+			}
+			return nil
+		}
+	}
+	return ops, closeOps, nil
+}
+
+// callTool returns an operation calling an MCP tool, failing on tool errors too
+func callTool(client *mcpclient.Client, name string, args func() map[string]interface{}) operation {
+	return func(ctx context.Context) error {
+		result, err := client.CallTool(ctx, name, args())
+		if err != nil {
+			return err
+		}
+		if result.IsError {
+			return fmt.Errorf("%s failed: %s", name, mcpclient.Text(result))
+		}
+		return nil
+	}
+}
+
+// randomEmbedding returns a random unit vector, so vector searches do not hit a cache
+func randomEmbedding(dims int) []float32 {
+	v := make([]float32, dims)
+	var norm float64
+	for i := range v {
+		x := rand.NormFloat64()
+		v[i] = float32(x)
+		norm += x * x
+	}
+	if norm == 0 {
+		return v
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range v {
+		v[i] *= scale
+	}
+	return v
+}
+
+// sortedNames returns the operations of results in report order
+func sortedNames(names []string) []string {
+	order := make(map[string]int, len(operationNames))
+	for i, n := range operationNames {
+		order[n] = i
+	}
+	sorted := append([]string(nil), names...)
+	sort.Slice(sorted, func(i, j int) bool { return order[sorted[i]] < order[sorted[j]] })
+	return sorted
+}
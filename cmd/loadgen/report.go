@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// histogramBounds are the upper bounds of the latency histogram buckets; a last,
+// unbounded bucket holds slower requests
+var histogramBounds = []time.Duration{
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// histogramWidth is the length of the longest bar of a text histogram
+const histogramWidth = 40
+
+// report summarizes a run and its budgets
+type report struct {
+	TargetRPS    float64    `json:"target_rps"`
+	AchievedRPS  float64    `json:"achieved_rps"`
+	DurationMS   float64    `json:"duration_ms"`
+	MaxErrorRate float64    `json:"max_error_rate"`
+	MaxP99MS     float64    `json:"max_p99_ms,omitempty"`
+	Operations   []opReport `json:"operations"`
+	Violations   []string   `json:"violations,omitempty"`
+	Passed       bool       `json:"passed"`
+	Total        opReport   `json:"total"`
+	config       *config
+}
+
+// opReport summarizes one operation
+type opReport struct {
+	Operation string         `json:"operation"`
+	Requests  int            `json:"requests"`
+	Errors    int            `json:"errors"`
+	Dropped   int            `json:"dropped"`
+	ErrorRate float64        `json:"error_rate"`
+	Latency   latency        `json:"latency_ms"`
+	Histogram []bucket       `json:"histogram,omitempty"`
+	ErrorLog  map[string]int `json:"error_samples,omitempty"`
+}
+
+// latency holds latency statistics of successful requests, in milliseconds
+type latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// bucket counts the requests at most LEMS milliseconds long; LEMS is 0 for the last,
+// unbounded bucket
+type bucket struct {
+	LEMS  float64 `json:"le_ms,omitempty"`
+	Count int     `json:"count"`
+}
+
+// newReport summarizes results and checks them against the budgets of cfg
+func newReport(cfg *config, res *results) *report {
+	rep := &report{
+		TargetRPS:    cfg.rps,
+		DurationMS:   millis(res.elapsed),
+		MaxErrorRate: cfg.maxErrorRate,
+		MaxP99MS:     millis(cfg.maxP99),
+		Passed:       true,
+		config:       cfg,
+	}
+
+	names := make([]string, 0, len(res.operations))
+	for name := range res.operations {
+		names = append(names, name)
+	}
+	all := &opResults{errorSamples: make(map[string]int)}
+	for _, name := range sortedNames(names) {
+		r := res.operations[name]
+		rep.Operations = append(rep.Operations, summarize(name, r))
+		all.latencies = append(all.latencies, r.latencies...)
+		all.errors += r.errors
+		all.dropped += r.dropped
+	}
+	rep.Total = summarize("total", all)
+	if res.elapsed > 0 {
+		rep.AchievedRPS = float64(rep.Total.Requests-rep.Total.Dropped) / res.elapsed.Seconds()
+	}
+
+	for _, op := range rep.Operations {
+		if op.Requests > 0 && op.ErrorRate > cfg.maxErrorRate {
+			rep.Violations = append(rep.Violations, fmt.Sprintf("%s: error rate %s exceeds the budget of %s",
+				op.Operation, formatRate(op.ErrorRate), formatRate(cfg.maxErrorRate)))
+		}
+		if cfg.maxP99 > 0 && op.Latency.P99 > rep.MaxP99MS {
+			rep.Violations = append(rep.Violations, fmt.Sprintf("%s: p99 latency %.1fms exceeds the budget of %s",
+				op.Operation, op.Latency.P99, cfg.maxP99))
+		}
+	}
+	rep.Passed = len(rep.Violations) == 0
+	return rep
+}
+
+// summarize computes the statistics of one operation. Dropped requests count against
+// the error budget: they mean the server could not keep up with the target rate.
+func summarize(name string, r *opResults) opReport {
+	op := opReport{
+		Operation: name,
+		Requests:  len(r.latencies) + r.errors + r.dropped,
+		Errors:    r.errors,
+		Dropped:   r.dropped,
+	}
+	if len(r.errorSamples) > 0 {
+		op.ErrorLog = r.errorSamples
+	}
+	if op.Requests > 0 {
+		op.ErrorRate = float64(r.errors+r.dropped) / float64(op.Requests)
+	}
+	if len(r.latencies) == 0 {
+		return op
+	}
+
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, l := range sorted {
+		sum += l
+	}
+	op.Latency = latency{
+		Min:  millis(sorted[0]),
+		Mean: millis(sum / time.Duration(len(sorted))),
+		P50:  millis(percentile(sorted, 50)),
+		P90:  millis(percentile(sorted, 90)),
+		P95:  millis(percentile(sorted, 95)),
+		P99:  millis(percentile(sorted, 99)),
+		Max:  millis(sorted[len(sorted)-1]),
+	}
+	op.Histogram = histogram(sorted)
+	return op
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// histogram counts sorted latencies into histogramBounds, trimming empty buckets
+// below the fastest and above the slowest request
+func histogram(sorted []time.Duration) []bucket {
+	buckets := make([]bucket, len(histogramBounds)+1)
+	for i, bound := range histogramBounds {
+		buckets[i].LEMS = millis(bound)
+	}
+	i := 0
+	for _, l := range sorted {
+		for i < len(histogramBounds) && l > histogramBounds[i] {
+			i++
+		}
+		buckets[i].Count++
+	}
+
+	first, last := 0, len(buckets)-1
+	for first < last && buckets[first].Count == 0 {
+		first++
+	}
+	for last > first && buckets[last].Count == 0 {
+		last--
+	}
+	return buckets[first : last+1]
+}
+
+// writeJSON writes the report as indented JSON
+func (r *report) writeJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// writeText writes the report as tables and histograms
+func (r *report) writeText(out io.Writer) error {
+	fmt.Fprintf(out, "Target %.1f req/s for %s, achieved %.1f req/s (mix %s)\n\n",
+		r.TargetRPS, time.Duration(r.DurationMS*float64(time.Millisecond)).Round(time.Millisecond), r.AchievedRPS, r.config.mixString())
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "OPERATION\tREQUESTS\tERRORS\tDROPPED\tERROR RATE\tP50 MS\tP90 MS\tP99 MS\tMAX MS\t")
+	for _, op := range append(r.Operations, r.Total) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%.1f\t%.1f\t%.1f\t%.1f\t\n", op.Operation, op.Requests, op.Errors,
+			op.Dropped, formatRate(op.ErrorRate), op.Latency.P50, op.Latency.P90, op.Latency.P99, op.Latency.Max)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, op := range r.Operations {
+		writeHistogram(out, op)
+	}
+
+	fmt.Fprintln(out)
+	if r.Passed {
+		fmt.Fprintln(out, "PASS: all operations are within their budgets")
+		return nil
+	}
+	for _, v := range r.Violations {
+		fmt.Fprintln(out, "FAIL:", v)
+	}
+	return nil
+}
+
+// writeHistogram writes an operation's latency histogram and error samples
+func writeHistogram(out io.Writer, op opReport) {
+	if len(op.Histogram) == 0 && len(op.ErrorLog) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s\n", op.Operation)
+	peak := 0
+	for _, b := range op.Histogram {
+		if b.Count > peak {
+			peak = b.Count
+		}
+	}
+	for _, b := range op.Histogram {
+		label := "> " + formatMillis(histogramBounds[len(histogramBounds)-1])
+		if b.LEMS > 0 {
+			label = "<= " + formatMillis(time.Duration(b.LEMS*float64(time.Millisecond)))
+		}
+		bar := strings.Repeat("#", (b.Count*histogramWidth+peak-1)/peak)
+		fmt.Fprintf(out, "  %9s %7d %s\n", label, b.Count, bar)
+	}
+
+	messages := make([]string, 0, len(op.ErrorLog))
+	for msg := range op.ErrorLog {
+		messages = append(messages, msg)
+	}
+	sort.Strings(messages)
+	for _, msg := range messages {
+		fmt.Fprintf(out, "  error x%d: %s\n", op.ErrorLog[msg], msg)
+	}
+}
+
+// mixString formats the mix as it is given to -mix
+func (c *config) mixString() string {
+	parts := make([]string, len(c.mix.names))
+	for i, name := range c.mix.names {
+		parts[i] = fmt.Sprintf("%s=%g", name, c.mix.weights[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+// millis converts a duration to fractional milliseconds
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// formatMillis formats a histogram bound
+func formatMillis(d time.Duration) string {
+	if d >= time.Second {
+		return fmt.Sprintf("%gs", d.Seconds())
+	}
+	return fmt.Sprintf("%gms", millis(d))
+}
+
+// formatRate formats a share as a percentage
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.2f%%", rate*100)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newBudgetCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Manage A2A budgets",
+	}
+
+	var tenantID string
+	set := &cobra.Command{
+		Use:   "set USER_ID MONTHLY_LIMIT_USD",
+		Short: "Set a user's monthly budget and reset its spend",
+		Long:  "Set a user's monthly budget on the A2A agent. Needs the agent's admin token (A2A_ADMIN_TOKEN).",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.adminToken == "" {
+				return fmt.Errorf("the admin token is required: pass --admin-token or set A2A_ADMIN_TOKEN")
+			}
+			limit, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || limit < 0 {
+				return fmt.Errorf("invalid monthly limit %q: expected a non-negative amount in USD", args[1])
+			}
+			budget, err := opts.a2aClient(opts.a2aURL, opts.adminToken).SetBudget(cmd.Context(), args[0], limit, tenantID)
+			if err != nil {
+				return err
+			}
+			return opts.printJSON(budget)
+		},
+	}
+	set.Flags().StringVar(&tenantID, "tenant", "", "make the budget a sub-budget of this tenant's")
+	cmd.AddCommand(set)
+	return cmd
+}
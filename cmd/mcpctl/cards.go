@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/bhatti/mcp-a2a-go/a2a-server/pkg/a2aclient"
+	"github.com/spf13/cobra"
+)
+
+func newCardsCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cards [AGENT_URL...]",
+		Short: "Dump agent cards as JSON",
+		Long:  "Fetch and print the cards of the agents at the given base URLs, or of the --a2a-url agent.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			urls := args
+			if len(urls) == 0 {
+				urls = []string{opts.a2aURL}
+			}
+			cards := make([]*a2aclient.AgentCard, 0, len(urls))
+			for _, url := range urls {
+				card, err := opts.a2aClient(url, "").AgentCard(cmd.Context())
+				if err != nil {
+					return err
+				}
+				cards = append(cards, card)
+			}
+			if len(cards) == 1 {
+				return opts.printJSON(cards[0])
+			}
+			return opts.printJSON(cards)
+		},
+	}
+}
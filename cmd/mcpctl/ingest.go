@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/pkg/mcpclient"
+	"github.com/spf13/cobra"
+)
+
+// document is a file to ingest
+type document struct {
+	path    string
+	title   string
+	content string
+}
+
+func newIngestCommand(opts *options) *cobra.Command {
+	var collection string
+	var extensions []string
+	cmd := &cobra.Command{
+		Use:   "ingest DIR",
+		Short: "Ingest the text and Markdown files of a directory",
+		Long: "Ingest every file with one of the extensions under DIR as a document of the token's tenant, " +
+			"with the file's first Markdown heading, or else its name, as the title and its path as metadata.source. " +
+			"Needs the write scope.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			docs, err := readDocuments(args[0], extensions)
+			if err != nil {
+				return err
+			}
+			if len(docs) == 0 {
+				return fmt.Errorf("no files with extensions %s under %s", strings.Join(extensions, ", "), args[0])
+			}
+
+			ctx := cmd.Context()
+			client, err := opts.mcpClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close(context.WithoutCancel(ctx))
+
+			failed := 0
+			for i, doc := range docs {
+				args := map[string]interface{}{
+					"title":    doc.title,
+					"content":  doc.content,
+					"metadata": map[string]interface{}{"source": doc.path},
+				}
+				if collection != "" {
+					args["collection"] = collection
+				}
+				result, err := client.CallTool(ctx, "ingest_document", args)
+				if err == nil && result.IsError {
+					err = fmt.Errorf("%s", mcpclient.Text(result))
+				}
+				if err != nil {
+					failed++
+					fmt.Fprintf(opts.out, "FAILED %s: %v\n", doc.path, err)
+					// The remaining documents would be rejected as well
+					var rpcErr *mcpclient.Error
+					if errors.As(err, &rpcErr) && rpcErr.Code == mcpclient.CodeQuotaExceeded {
+						return fmt.Errorf("quota exceeded: %d of %d documents ingested", i+1-failed, len(docs))
+					}
+					continue
+				}
+				fmt.Fprintf(opts.out, "%s: %s\n", doc.path, mcpclient.Text(result))
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d documents failed", failed, len(docs))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&collection, "collection", "", "collection to add the documents to (default: default)")
+	cmd.Flags().StringSliceVar(&extensions, "ext", []string{".md", ".markdown", ".txt"}, "extensions of the files to ingest")
+	return cmd
+}
+
+// readDocuments reads the files under dir with one of the extensions, in path order
+func readDocuments(dir string, extensions []string) ([]document, error) {
+	var docs []document
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !slices.Contains(extensions, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content := string(data)
+		if strings.TrimSpace(content) == "" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		docs = append(docs, document{path: filepath.ToSlash(rel), title: documentTitle(path, content), content: content})
+		return nil
+	})
+	return docs, err
+}
+
+// documentTitle returns the first Markdown heading of content, or the file name without
+// its extension
+func documentTitle(path, content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if heading, ok := strings.CutPrefix(line, "#"); ok {
+			if title := strings.TrimSpace(strings.TrimLeft(heading, "#")); title != "" {
+				return title
+			}
+		}
+	}
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
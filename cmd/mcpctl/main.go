@@ -0,0 +1,115 @@
+// Command mcpctl administers a running MCP server and A2A agent: it mints demo tokens,
+// ingests documents, runs searches, lists and cancels tasks, sets budgets and dumps
+// agent cards, using the mcpclient and a2aclient packages.
+//
+//	export MCP_TOKEN=$(mcpctl token --key /tmp/demo-keys/private_key.pem --scopes read,write)
+//	mcpctl ingest ./docs --collection handbook
+//	mcpctl search "password rotation"
+//	mcpctl tasks list --state working
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/pkg/a2aclient"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/pkg/mcpclient"
+	"github.com/spf13/cobra"
+)
+
+// Defaults of the global flags, matching the servers' default ports
+const (
+	defaultMCPURL = "http://localhost:8080/mcp"
+	defaultA2AURL = "http://localhost:8081"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// options are the global flags
+type options struct {
+	mcpURL     string
+	a2aURL     string
+	token      string
+	adminToken string
+	timeout    time.Duration
+	out        io.Writer
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the command tree
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+	root := &cobra.Command{
+		Use:          "mcpctl",
+		Short:        "Administer the MCP server and A2A agent",
+		Version:      version,
+		SilenceUsage: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+		},
+	}
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.mcpURL, "mcp-url", getEnv("MCP_URL", defaultMCPURL), "MCP server JSON-RPC endpoint (env MCP_URL)")
+	flags.StringVar(&opts.a2aURL, "a2a-url", getEnv("A2A_URL", defaultA2AURL), "A2A agent base URL (env A2A_URL)")
+	flags.StringVar(&opts.token, "token", os.Getenv("MCP_TOKEN"), "bearer token for the MCP server (env MCP_TOKEN)")
+	flags.StringVar(&opts.adminToken, "admin-token", os.Getenv("A2A_ADMIN_TOKEN"), "A2A admin token, for budgets (env A2A_ADMIN_TOKEN)")
+	flags.DurationVar(&opts.timeout, "timeout", 30*time.Second, "timeout of each request")
+
+	root.AddCommand(
+		newTokenCommand(opts),
+		newIngestCommand(opts),
+		newSearchCommand(opts),
+		newTasksCommand(opts),
+		newBudgetCommand(opts),
+		newCardsCommand(opts),
+	)
+	return root
+}
+
+// mcpClient connects to the MCP server and runs the initialize handshake
+func (o *options) mcpClient(ctx context.Context) (*mcpclient.Client, error) {
+	if o.token == "" {
+		return nil, fmt.Errorf("a token is required: pass --token or set MCP_TOKEN, e.g. from mcpctl token")
+	}
+	client := mcpclient.New(mcpclient.Config{
+		URL:           o.mcpURL,
+		Token:         o.token,
+		Timeout:       o.timeout,
+		ClientName:    "mcpctl",
+		ClientVersion: version,
+	})
+	if _, err := client.Initialize(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// a2aClient returns a client for the agent at url, authenticating with token
+func (o *options) a2aClient(url, token string) *a2aclient.Client {
+	return a2aclient.New(a2aclient.Config{URL: url, Token: token, Timeout: o.timeout})
+}
+
+// printJSON writes v as indented JSON
+func (o *options) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(o.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// getEnv returns an environment variable or a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run executes mcpctl with args and returns its output
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newRootCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestTokenCommand(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "private_key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600))
+
+	out, err := run(t, "token", "--key", keyFile, "--tenant", "acme", "--user", "alice", "--scopes", "read,admin", "--ttl", "1h")
+	require.NoError(t, err)
+
+	var claims tokenClaims
+	_, err = jwt.ParseWithClaims(string(bytes.TrimSpace([]byte(out))), &claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithIssuer("mcp-server-demo"), jwt.WithAudience("mcp-server"), jwt.WithValidMethods([]string{"RS256"}))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", claims.TenantID)
+	assert.Equal(t, "alice", claims.UserID)
+	assert.Equal(t, []string{"read", "admin"}, claims.Scopes)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt.Time, time.Minute)
+
+	_, err = run(t, "token", "--key", filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestMintToken_ECKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	signer, err := parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	token, err := mintToken(signer, tokenOptions{tenantID: "acme", userID: "bob", keyID: "key-1", ttl: time.Hour}, time.Now())
+	require.NoError(t, err)
+	parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "ES256", parsed.Method.Alg())
+	assert.Equal(t, "key-1", parsed.Header["kid"])
+
+	_, err = parsePrivateKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestReadDocuments(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "policies"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policies", "security.md"), []byte("Intro\n\n## Security Policy\n\nUse MFA."), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("Support answers on weekdays."), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.md"), []byte("  \n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.png"), []byte{0x89}, 0644))
+
+	docs, err := readDocuments(dir, []string{".md", ".txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []document{
+		{path: "notes.txt", title: "notes", content: "Support answers on weekdays."},
+		{path: "policies/security.md", title: "Security Policy", content: "Intro\n\n## Security Policy\n\nUse MFA."},
+	}, docs)
+}
+
+// newFakeAgent serves an agent card and records budget updates
+func newFakeAgent(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/agent", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "agent-1", "name": "Research Agent"})
+	})
+	mux.HandleFunc("/admin/budgets/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-token" {
+			http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{"user_id": "alice", "monthly_limit_usd": body["monthly_limit_usd"]})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestAgentCommands(t *testing.T) {
+	agent, requests := newFakeAgent(t)
+
+	out, err := run(t, "cards", "--a2a-url", agent.URL)
+	require.NoError(t, err)
+	assert.Contains(t, out, `"id": "agent-1"`)
+
+	out, err = run(t, "cards", agent.URL, agent.URL)
+	require.NoError(t, err)
+	var cards []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &cards))
+	assert.Len(t, cards, 2)
+
+	out, err = run(t, "budget", "set", "alice", "25", "--a2a-url", agent.URL, "--admin-token", "admin-token")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"monthly_limit_usd": 25`)
+	assert.Equal(t, []string{"PUT /admin/budgets/alice"}, *requests)
+
+	_, err = run(t, "budget", "set", "alice", "-5", "--a2a-url", agent.URL, "--admin-token", "admin-token")
+	assert.Error(t, err)
+	_, err = run(t, "budget", "set", "alice", "25", "--a2a-url", agent.URL, "--admin-token", "wrong")
+	assert.ErrorContains(t, err, "401")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/pkg/mcpclient"
+	"github.com/spf13/cobra"
+)
+
+func newSearchCommand(opts *options) *cobra.Command {
+	var mode, collection, profile string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "search QUERY",
+		Short: "Search the token's tenant's documents",
+		Long: "Search with the search_documents tool. Semantic and hybrid mode need an embeddings " +
+			"provider on the server to embed the query.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			client, err := opts.mcpClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close(context.WithoutCancel(ctx))
+
+			toolArgs := map[string]interface{}{
+				"query": strings.Join(args, " "),
+				"limit": limit,
+				"mode":  mode,
+			}
+			if collection != "" {
+				toolArgs["collection"] = collection
+			}
+			if profile != "" {
+				toolArgs["profile"] = profile
+			}
+			result, err := client.CallTool(ctx, "search_documents", toolArgs)
+			if err != nil {
+				return err
+			}
+			if result.IsError {
+				return fmt.Errorf("search failed: %s", mcpclient.Text(result))
+			}
+			fmt.Fprintln(opts.out, mcpclient.Text(result))
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&mode, "mode", "lexical", "lexical, semantic or hybrid")
+	flags.IntVar(&limit, "limit", 10, "maximum number of results")
+	flags.StringVar(&collection, "collection", "", "collection to search (default: default)")
+	flags.StringVar(&profile, "profile", "", "named search profile of the tenant")
+	return cmd
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/a2a-server/pkg/a2aclient"
+	"github.com/spf13/cobra"
+)
+
+func newTasksCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "List and cancel A2A tasks",
+	}
+	cmd.AddCommand(newTasksListCommand(opts), newTasksCancelCommand(opts))
+	return cmd
+}
+
+func newTasksListCommand(opts *options) *cobra.Command {
+	var filter a2aclient.TaskFilter
+	var createdAfter, createdBefore string
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the agent's tasks, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if filter.CreatedAfter, err = parseTime(createdAfter); err != nil {
+				return fmt.Errorf("invalid --created-after: %w", err)
+			}
+			if filter.CreatedBefore, err = parseTime(createdBefore); err != nil {
+				return fmt.Errorf("invalid --created-before: %w", err)
+			}
+			page, err := opts.a2aClient(opts.a2aURL, "").ListTaskPage(cmd.Context(), filter)
+			if err != nil {
+				return err
+			}
+			tasks := page.Tasks
+			if asJSON {
+				return opts.printJSON(tasks)
+			}
+
+			w := tabwriter.NewWriter(opts.out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCAPABILITY\tSTATE\tUPDATED\tMESSAGE")
+			for _, task := range tasks {
+				capability, _ := task.Metadata["capability"].(string)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", task.ID, capability, task.Status.State,
+					task.Status.Timestamp, a2aclient.StatusText(&task))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if page.NextCursor != "" {
+				fmt.Fprintf(opts.out, "\nMore tasks: --cursor %s\n", page.NextCursor)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&filter.UserID, "user", "", "only tasks of this user")
+	flags.StringVar(&filter.AgentID, "agent", "", "only tasks of this agent")
+	flags.StringVar(&filter.State, "state", "", "only tasks in this state: submitted, working, input-required, completed, failed or canceled")
+	flags.IntVar(&filter.Limit, "limit", 100, "maximum number of tasks")
+	flags.IntVar(&filter.Offset, "offset", 0, "number of tasks to skip")
+	flags.StringVar(&createdAfter, "created-after", "", "only tasks created after this RFC 3339 time")
+	flags.StringVar(&createdBefore, "created-before", "", "only tasks created before this RFC 3339 time")
+	flags.BoolVar(&filter.NewestFirst, "newest-first", false, "list the most recently created tasks first")
+	flags.StringVar(&filter.Cursor, "cursor", "", "continue a listing from the cursor printed after its previous page")
+	flags.BoolVar(&asJSON, "json", false, "print the tasks as JSON")
+	return cmd
+}
+
+// parseTime parses an RFC 3339 time flag, which is the zero time when it is not set
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func newTasksCancelCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel TASK_ID...",
+		Short: "Cancel tasks",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := opts.a2aClient(opts.a2aURL, "")
+			for _, taskID := range args {
+				task, err := client.CancelTask(cmd.Context(), taskID)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(opts.out, "%s %s\n", task.ID, task.Status.State)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cobra"
+)
+
+// demoTenantID is the acme-corp tenant of the demo data
+const demoTenantID = "11111111-1111-1111-1111-111111111111"
+
+// tokenClaims are the claims the MCP server reads from its bearer tokens
+type tokenClaims struct {
+	TenantID string   `json:"tenant_id"`
+	UserID   string   `json:"user_id"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Role     string   `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// tokenOptions are the flags of mcpctl token
+type tokenOptions struct {
+	keyFile  string
+	keyID    string
+	tenantID string
+	userID   string
+	scopes   []string
+	role     string
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+func newTokenCommand(opts *options) *cobra.Command {
+	tokenOpts := &tokenOptions{}
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Mint a demo token signed with the server's private key",
+		Long: "Mint a JWT for the MCP server, signed with the private key DEV_MODE saves in DEMO_KEYS_DIR " +
+			"or any key whose public half the server trusts. For development only.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(tokenOpts.keyFile)
+			if err != nil {
+				return err
+			}
+			key, err := parsePrivateKey(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", tokenOpts.keyFile, err)
+			}
+			token, err := mintToken(key, *tokenOpts, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(opts.out, token)
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&tokenOpts.keyFile, "key", "/tmp/demo-keys/private_key.pem", "PEM private key to sign with")
+	flags.StringVar(&tokenOpts.keyID, "kid", "", "key ID header, for servers that trust several keys")
+	flags.StringVar(&tokenOpts.tenantID, "tenant", demoTenantID, "tenant ID")
+	flags.StringVar(&tokenOpts.userID, "user", "demo-user", "user ID")
+	flags.StringSliceVar(&tokenOpts.scopes, "scopes", []string{"read", "write"}, "scopes")
+	flags.StringVar(&tokenOpts.role, "role", "", "role granting its scopes: viewer, editor or admin")
+	flags.StringVar(&tokenOpts.issuer, "issuer", "mcp-server-demo", "issuer the server expects (JWT_ISSUER)")
+	flags.StringVar(&tokenOpts.audience, "audience", "mcp-server", "audience the server expects (JWT_AUDIENCE)")
+	flags.DurationVar(&tokenOpts.ttl, "ttl", 24*time.Hour, "how long the token is valid")
+	return cmd
+}
+
+// mintToken signs a token with opts' claims, issued at now
+func mintToken(key crypto.Signer, opts tokenOptions, now time.Time) (string, error) {
+	var method jwt.SigningMethod
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			method = jwt.SigningMethodES256
+		case 384:
+			method = jwt.SigningMethodES384
+		default:
+			return "", fmt.Errorf("unsupported EC curve %s", k.Curve.Params().Name)
+		}
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+
+	token := jwt.NewWithClaims(method, tokenClaims{
+		TenantID: opts.tenantID,
+		UserID:   opts.userID,
+		Scopes:   opts.scopes,
+		Role:     opts.role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    opts.issuer,
+			Subject:   opts.userID,
+			Audience:  jwt.ClaimStrings{opts.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(opts.ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	})
+	if opts.keyID != "" {
+		token.Header["kid"] = opts.keyID
+	}
+	return token.SignedString(key)
+}
+
+// parsePrivateKey decodes a PKCS#1, SEC 1 or PKCS#8 PEM private key
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+	switch strings.TrimSpace(block.Type) {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q: expected a private key", block.Type)
+	}
+}
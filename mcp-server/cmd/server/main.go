@@ -6,49 +6,102 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/redis/go-redis/v9"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/accesslog"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/audit"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/cache"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/config"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/consistency"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/cost"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/dedup"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/deprecation"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/embeddings"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/gdpr"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/llm"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/middleware"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/outbox"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/quota"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/redaction"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/residency"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resilience"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resources"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/server"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/sessions"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage/sqlite"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
-)
-
-const (
-	defaultPort      = "8080"
-	defaultDBHost    = "localhost"
-	defaultDBPort    = 5432
-	defaultRedisAddr = "localhost:6379"
-	defaultRateLimit = 100 // requests per minute
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/bhatti/mcp-a2a-go/shared/health"
+	"github.com/bhatti/mcp-a2a-go/shared/httpserver"
+	"github.com/bhatti/mcp-a2a-go/shared/lifecycle"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/mtls"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
-	ctx := context.Background()
+	// Subcommands run instead of the server
+	subcommands := map[string]func(config.Config, []string) error{
+		"gen-otel-config": genOTelConfig,
+		"migrate":         migrateCommand,
+	}
+	if len(os.Args) > 1 && subcommands[os.Args[1]] != nil {
+		cfg, err := config.Load(nil)
+		if err == nil {
+			err = subcommands[os.Args[1]](cfg, os.Args[2:])
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Load configuration from environment
-	cfg := loadConfig()
+	ctx := context.Background()
 
-	// Initialize database
-	log.Println("Connecting to database...")
-	db, err := database.NewDB(ctx, cfg.Database)
+	// Load configuration from the config file, environment and flags
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Structured logging; the standard log package writes through it too
+	if _, err := logging.Setup(os.Stderr, cfg.Logging); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid logging config: %v\n", err)
+		os.Exit(1)
 	}
-	defer db.Close()
-	log.Println("Database connected successfully")
+
+	// Readiness probes ping each dependency as it is set up
+	probes := health.NewChecker(cfg.HealthCheckTimeout)
 
 	// Initialize Redis
-	log.Println("Connecting to Redis...")
+	slog.Info("Connecting to Redis", "addr", cfg.RedisAddr)
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.RedisAddr,
 		Password: "",
@@ -56,100 +109,853 @@ func main() {
 	})
 	defer redisClient.Close()
 
+	// Local drivers can run without Redis; rate limiting and the search cache are then disabled
+	redisAvailable := true
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+		if cfg.DBDriver == config.DriverPostgres {
+			logging.Fatal("Failed to connect to Redis", "error", err)
+		}
+		slog.Warn("Redis unavailable; rate limiting and search cache disabled", "error", err)
+		redisAvailable = false
+	} else {
+		slog.Info("Redis connected successfully")
+		pingRedis := func(ctx context.Context) error { return redisClient.Ping(ctx).Err() }
+		if cfg.DBDriver == config.DriverPostgres {
+			probes.Add("redis", pingRedis)
+		} else {
+			probes.AddOptional("redis", pingRedis)
+		}
 	}
-	log.Println("Redis connected successfully")
 
 	// Initialize observability
-	log.Println("Setting up OpenTelemetry...")
+	slog.Info("Setting up OpenTelemetry")
 	telemetry, err := observability.NewTelemetry(ctx, observability.Config{
-		ServiceName:    "mcp-server",
-		ServiceVersion: "1.0.0",
-		Environment:    cfg.Environment,
-		OTLPEndpoint:   cfg.OTLPEndpoint,
-		SamplingRate:   cfg.SamplingRate,
-		EnableTracing:  cfg.EnableTracing,
-		EnableMetrics:  cfg.EnableMetrics,
+		ServiceName:      "mcp-server",
+		ServiceVersion:   "1.0.0",
+		Environment:      cfg.Environment,
+		OTLPEndpoint:     cfg.OTLPEndpoint,
+		SamplingRate:     cfg.SamplingRate,
+		EnableTracing:    cfg.EnableTracing,
+		EnableMetrics:    cfg.EnableMetrics,
+		HistogramBuckets: cfg.HistogramBuckets,
+		Exemplars:        cfg.Exemplars,
 	})
 	if err != nil {
-		log.Fatalf("Failed to initialize telemetry: %v", err)
+		logging.Fatal("Failed to initialize telemetry", "error", err)
 	}
 	defer func() {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := telemetry.Shutdown(shutdownCtx); err != nil {
-			log.Printf("Error shutting down telemetry: %v", err)
+			slog.Error("Error shutting down telemetry", "error", err)
 		}
 	}()
-	log.Println("OpenTelemetry initialized successfully")
+	if cfg.EnableTracing {
+		probes.AddOptional("otlp_exporter", telemetry.CheckExporter)
+	}
+	slog.Info("OpenTelemetry initialized successfully")
+
+	// Circuit breakers fail Postgres and Redis calls fast while the dependency is down,
+	// instead of holding requests until connection timeouts
+	newBreaker := func(name string, isFailure func(error) bool) *resilience.Breaker {
+		if !cfg.CircuitBreaker.Enabled {
+			return nil
+		}
+		breaker := resilience.NewBreaker(name, cfg.CircuitBreaker, isFailure)
+		if telemetry.Metrics != nil {
+			breaker.SetRecorder(telemetry.Metrics)
+		}
+		return breaker
+	}
+	if breaker := newBreaker("redis", resilience.IsRedisFailure); breaker != nil {
+		redisClient.AddHook(resilience.RedisHook(breaker))
+	}
 
 	// Initialize JWT validator
-	log.Println("Setting up authentication...")
-	jwtValidator, publicKeyPEM, err := setupAuth()
+	slog.Info("Setting up authentication")
+	keyManager, tokenIssuer, err := setupAuth(ctx, cfg)
+	if err != nil {
+		logging.Fatal("Failed to setup auth", "error", err)
+	}
+	jwtValidator, err := auth.NewJWTValidator(auth.Config{
+		Keys:     keyManager,
+		Issuer:   cfg.JWTIssuer,
+		Audience: cfg.JWTAudience,
+	})
 	if err != nil {
-		log.Fatalf("Failed to setup auth: %v", err)
+		logging.Fatal("Failed to create JWT validator", "error", err)
+	}
+	keyRefreshCtx, stopKeyRefresh := context.WithCancel(ctx)
+	defer stopKeyRefresh()
+	if cfg.JWTKeysRefresh > 0 && len(keyManager.Keys()) > 0 {
+		keyManager.Start(keyRefreshCtx, cfg.JWTKeysRefresh)
+	}
+
+	// Tokens of an external OpenID Connect provider, verified against its JWKS or by
+	// introspection
+	var oidcVerifier *auth.OIDCVerifier
+	if cfg.OIDC.Enabled() {
+		oidcVerifier, err = auth.NewOIDCVerifier(ctx, cfg.OIDC)
+		if err != nil {
+			logging.Fatal("Failed to set up OIDC", "issuer", cfg.OIDC.Issuer, "error", err)
+		}
+		oidcVerifier.Start(keyRefreshCtx)
+		slog.Info("OIDC enabled", "issuer", cfg.OIDC.Issuer, "resource", cfg.OIDC.Resource)
+	}
+	slog.Info("Authentication setup complete", "keys", keyManager.KeyIDs())
+
+	// TLS certificate of the listener and of calls to the A2A server, reloaded on rotation
+	var certificates *mtls.Certificates
+	if cfg.TLS.Enabled() {
+		certificates, err = mtls.Load(cfg.TLS)
+		if err != nil {
+			logging.Fatal("Failed to load TLS certificate", "cert_file", cfg.TLS.CertFile, "error", err)
+		}
+		certificates.Start(keyRefreshCtx)
+		slog.Info("TLS enabled", "mutual", cfg.TLS.CAFile != "", "identities", len(cfg.TLS.Identities))
+	}
+
+	// Initialize document storage (after telemetry so every statement is traced).
+	// Access logging, GDPR endpoints and data residency need the Postgres driver.
+	var store storage.Store
+	var profileStore profiles.Store
+	var synonymStore synonyms.Store
+	var roleStore auth.RoleStore
+	var dataStore residency.Backend
+	var regions database.RegionResolver
+	var databases []*database.DB
+	var documentWriter storage.Writer
+	var tenantStore onboarding.TenantStore
+	var tenantLimits middleware.TenantLimits
+	regionNames := []string{database.DefaultRegion}
+	switch cfg.DBDriver {
+	case config.DriverPostgres:
+		if cfg.Database.AutoMigrate {
+			if err := autoMigrate(ctx, cfg); err != nil {
+				logging.Fatal("Failed to migrate the database schema", "error", err)
+			}
+		}
+		slog.Info("Connecting to database", "host", cfg.Database.Host, "database", cfg.Database.DBName, "replicas", len(cfg.Database.Replicas))
+		cfg.Database.Tracer = database.NewQueryTracer(telemetry)
+		cfg.Database.Tracer.SetSlowQueryThreshold(cfg.Database.SlowQueryThreshold)
+		db, err := database.NewDB(ctx, cfg.Database)
+		if err != nil {
+			logging.Fatal("Failed to connect to database", "error", err)
+		}
+		defer db.Close()
+		probes.Add("postgres", db.Ping)
+		db.SetBreaker(newBreaker("postgres", nil))
+		slog.Info("Database connected successfully")
+
+		// Route tenant data to regional databases when data residency is configured.
+		// The primary database is the control plane and also serves the default region.
+		dataStore = db
+		profileStore = db // tenant settings live in the control plane
+		synonymStore = db
+		roleStore = db
+		databases = []*database.DB{db}
+		if len(cfg.DataRegions) > 0 {
+			backends := map[string]residency.Backend{database.DefaultRegion: db}
+			for region, regionCfg := range cfg.DataRegions {
+				slog.Info("Connecting to region database", "region", region)
+				regionCfg.Tracer = cfg.Database.Tracer
+				regionDB, err := database.NewDB(ctx, regionCfg)
+				if err != nil {
+					logging.Fatal("Failed to connect to region database", "region", region, "error", err)
+				}
+				defer regionDB.Close()
+				probes.Add("postgres_"+region, regionDB.Ping)
+				regionDB.SetBreaker(newBreaker("postgres_"+region, nil))
+				backends[region] = regionDB
+				databases = append(databases, regionDB)
+				regionNames = append(regionNames, region)
+			}
+
+			router := residency.NewRouter(db, backends, residency.Config{})
+			dataStore = router
+			regions = router
+			slog.Info("Data residency enabled", "regions", len(backends))
+		}
+		store = dataStore
+		documentWriter = dataStore
+		tenantStore = dataStore // new tenants are copied into their region's database
+		tenantLimits = db
+
+	case config.DriverSQLite:
+		slog.Info("Opening SQLite database", "path", cfg.SQLitePath)
+		sqliteStore, err := sqlite.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			logging.Fatal("Failed to open SQLite database", "error", err)
+		}
+		defer sqliteStore.Close()
+		probes.Add("sqlite", sqliteStore.Ping)
+		if err := seedDemoDocuments(ctx, sqliteStore); err != nil {
+			logging.Fatal("Failed to seed demo documents", "error", err)
+		}
+		store = sqliteStore
+		documentWriter = sqliteStore
+		profileStore = profiles.NewMemoryStore()
+		synonymStore = synonyms.NewMemoryStore()
+		roleStore = auth.NewMemoryRoleStore()
+		memoryTenants := onboarding.NewMemoryTenantStore()
+		tenantStore, tenantLimits = memoryTenants, memoryTenants
+		slog.Info("SQLite store ready (access log, GDPR and data residency disabled)")
+
+	case config.DriverMemory:
+		memoryStore := storage.NewMemoryStore()
+		if err := seedDemoDocuments(ctx, memoryStore); err != nil {
+			logging.Fatal("Failed to seed demo documents", "error", err)
+		}
+		store = memoryStore
+		documentWriter = memoryStore
+		profileStore = profiles.NewMemoryStore()
+		synonymStore = synonyms.NewMemoryStore()
+		roleStore = auth.NewMemoryRoleStore()
+		memoryTenants := onboarding.NewMemoryTenantStore()
+		tenantStore, tenantLimits = memoryTenants, memoryTenants
+		slog.Info("In-memory store ready (access log, GDPR and data residency disabled)")
+
+	default:
+		logging.Fatal(fmt.Sprintf("Unknown DB_DRIVER %q (expected %s, %s or %s)", cfg.DBDriver, config.DriverPostgres, config.DriverSQLite, config.DriverMemory))
+	}
+
+	// Initialize search result cache
+	var gdprSources []gdpr.Source
+	if dataStore != nil {
+		gdprSources = append(gdprSources, gdpr.NewPostgresSource(dataStore))
+	}
+	if cfg.SearchCacheEnabled && redisAvailable {
+		searchCache := cache.NewSearchCache(store, redisClient, cache.Config{
+			TTL:     cfg.SearchCacheTTL,
+			Regions: regions,
+		}, telemetry.Metrics)
+		for _, d := range databases {
+			d.AddDocumentChangeHook(searchCache.InvalidateTenant)
+		}
+		store = searchCache
+		gdprSources = append(gdprSources, gdpr.NewCacheSource(searchCache))
+		slog.Info("Search cache enabled", "ttl", cfg.SearchCacheTTL.String())
 	}
-	log.Println("Authentication setup complete")
-	log.Printf("Demo Public Key:\n%s", publicKeyPEM)
 
 	// Initialize tool registry
-	log.Println("Registering MCP tools...")
+	slog.Info("Registering MCP tools")
 	toolRegistry := tools.NewRegistry()
-	toolRegistry.Register(tools.NewSearchTool(db))
-	toolRegistry.Register(tools.NewRetrieveTool(db))
-	toolRegistry.Register(tools.NewListTool(db))
-	toolRegistry.Register(tools.NewHybridSearchTool(db))
-	log.Printf("Registered %d tools", len(toolRegistry.List()))
+	searchTool := tools.NewSearchTool(store)
+	// Embedding and LLM calls are priced and attributed to the tenant and tool they serve
+	costTracker := cost.NewTracker(cost.DefaultPriceTable(), cost.DefaultRetention)
+	if telemetry.Metrics != nil {
+		costTracker.SetRecorder(telemetry.Metrics)
+	}
+	embedder, err := embeddings.New(cfg.Embeddings)
+	if err != nil {
+		logging.Fatal("Invalid embeddings config", "error", err)
+	}
+	if embedder != nil {
+		embedder = costTracker.MeterEmbedder(embedder, cfg.Embeddings.Model)
+		searchTool.SetEmbedder(embedder)
+		slog.Info("Server-side query embeddings enabled", "provider", cfg.Embeddings.Provider, "model", cfg.Embeddings.Model)
+	}
+	toolRegistry.Register(searchTool)
+	toolRegistry.Register(tools.NewRetrieveTool(store))
+	toolRegistry.Register(tools.NewListTool(store))
+	toolRegistry.Register(tools.NewSummarizeTool(store, resources.Summarize))
+	if cfg.TokenizerBPEFile != "" {
+		encoding, err := tokens.LoadBPEFile(cfg.TokenizerBPEFile)
+		if err != nil {
+			logging.Fatal("Failed to load tokenizer", "error", err)
+		}
+		tokens.SetBPE(encoding)
+	}
+	generator, err := llm.New(cfg.LLM)
+	if err != nil {
+		logging.Fatal("Invalid LLM config", "error", err)
+	}
+	if generator != nil {
+		model := cfg.LLM.Model
+		if model == "" {
+			model = llm.DefaultModel(cfg.LLM.Provider)
+		}
+		generator = costTracker.MeterLLM(generator, model)
+		answerTool := tools.NewAnswerTool(store, generator)
+		answerTool.SetModel(model)
+		if embedder != nil {
+			answerTool.SetEmbedder(embedder)
+		}
+		toolRegistry.Register(answerTool)
+		slog.Info("Question answering enabled", "provider", cfg.LLM.Provider, "model", model)
+	}
+	hybridSearchTool := tools.NewHybridSearchTool(store)
+	if err := hybridSearchTool.SetDefaultFusion(cfg.HybridFusion, cfg.HybridRRFK); err != nil {
+		logging.Fatal("Invalid hybrid search fusion config", "error", err)
+	}
+	toolRegistry.Register(hybridSearchTool)
+	toolRegistry.Register(tools.NewSimilarDocumentsTool(store))
+	federatedSearchTool := tools.NewFederatedSearchTool(store, cfg.Federation.Timeout)
+	for _, source := range cfg.Federation.Sources {
+		if err := federatedSearchTool.AddRemoteSource(source); err != nil {
+			logging.Fatal("Invalid federated source", "source", source.Name, "error", err)
+		}
+	}
+	toolRegistry.Register(federatedSearchTool)
+	toolRegistry.SetRestricted(federatedSearchTool.Definition().Name) // fans out to remote servers
+	toolRegistry.SetProfileLookup(profileStore)
+	toolRegistry.SetSynonymLookup(synonymStore)
+	for _, spec := range cfg.SQLTools {
+		sqlTool, err := tools.NewSQLTool(spec, dataStore)
+		if err != nil {
+			logging.Fatal("Invalid SQL tool", "error", err)
+		}
+		if _, exists := toolRegistry.Get(spec.Name); exists {
+			logging.Fatal("SQL tool name is already used by a built-in tool", "tool", spec.Name)
+		}
+		toolRegistry.Register(sqlTool)
+		toolRegistry.SetRestricted(spec.Name) // operator queries can be expensive
+		slog.Info("Registered SQL tool", "tool", spec.Name)
+	}
+	for _, tool := range toolRegistry.List() {
+		toolRegistry.SetRequiredScope(tool.Name, auth.ScopeRead) // built-in and SQL tools only read
+	}
+	// Mask PII in ingested documents and tool output as each tenant's policy asks; tenants
+	// override the configured policy in their settings, read from the control plane
+	var redactor *redaction.Redactor
+	if cfg.RedactionEnabled {
+		redactor = redaction.NewRedactor(cfg.Redaction)
+		if len(databases) > 0 {
+			redactor.SetSettings(databases[0])
+		}
+		if telemetry.Metrics != nil {
+			redactor.SetRecorder(telemetry.Metrics)
+		}
+		slog.Info("PII redaction enabled", "ingest", cfg.Redaction.Ingest, "output", cfg.Redaction.Output)
+	}
+	if documentWriter != nil {
+		ingestTool := tools.NewIngestTool(documentWriter)
+		if embedder != nil {
+			ingestTool.SetEmbedder(embedder)
+		}
+		if redactor != nil {
+			ingestTool.SetRedactor(redactor)
+		}
+		toolRegistry.Register(ingestTool)
+		toolRegistry.SetRequiredScope(ingestTool.Definition().Name, auth.ScopeWrite)
+	}
+	// Tool results over the size limit are truncated; read_continuation returns the rest,
+	// kept in Redis so any replica can return it
+	var truncator *tools.Truncator
+	if cfg.MaxToolResponseBytes > 0 {
+		truncator = tools.NewTruncator(cfg.MaxToolResponseBytes, cfg.ContinuationTTL)
+		if redisAvailable {
+			truncator.SetRedis(redisClient)
+		}
+		toolRegistry.Register(tools.NewContinuationTool(truncator))
+	}
+	toolRegistry.SetTimeouts(cfg.ToolTimeout, cfg.ToolTimeouts)
+	slog.Info("Registered tools", "count", len(toolRegistry.List()))
+
+	// Safe mode disables expensive and destructive operations during incidents
+	var safeModeStore safemode.Store = safemode.NewMemoryStore()
+	if cfg.SafeModeStateFile != "" {
+		safeModeStore = safemode.NewFileStore(cfg.SafeModeStateFile)
+	}
+	safeMode, err := safemode.New(ctx, safeModeStore)
+	if err != nil {
+		logging.Fatal("Failed to load safe mode state", "error", err)
+	}
+	toolRegistry.SetSafeMode(safeMode)
+	if state := safeMode.State(); state.Enabled {
+		slog.Warn("Starting in safe mode", "reason", state.Reason, "since", state.UpdatedAt)
+	}
+
+	// Tenant-uploaded WASM tools run sandboxed next to the built-in tools
+	var wasmTools *tools.WASMTools
+	if cfg.WASMToolsEnabled {
+		wasmRuntime, err := tools.NewWASMRuntime(ctx, cfg.WASM)
+		switch {
+		case errors.Is(err, tools.ErrWASMUnavailable):
+			slog.Info("WASM tools disabled", "reason", err.Error())
+		case err != nil:
+			logging.Fatal("Failed to create WASM runtime", "error", err)
+		default:
+			wasmTools = tools.NewWASMTools(wasmRuntime, cfg.WASM)
+			defer wasmTools.Close(context.Background())
+			toolRegistry.SetTenantTools(wasmTools)
+			slog.Info("WASM tools enabled", "max_memory_pages", cfg.WASM.MaxMemoryPages, "timeout", cfg.WASM.Timeout.String())
+		}
+	}
+
+	// Initialize resource registry
+	resourceRegistry := resources.NewRegistry()
+	resourceRegistry.Register(resources.NewSummaryProvider(store))
+	resourceRegistry.Register(resources.NewDocumentProvider(store))
+
+	// Initialize document access log
+	if cfg.AccessLogEnabled && dataStore != nil {
+		accessRecorder := accesslog.NewRecorder(dataStore, accesslog.Config{
+			SampleRate: cfg.AccessLogSampleRate,
+		})
+		accessRecorder.Start()
+		defer accessRecorder.Close()
+		toolRegistry.SetAccessRecorder(accessRecorder)
+		resourceRegistry.SetAccessRecorder(accessRecorder)
+		slog.Info("Document access log enabled", "sample_rate", cfg.AccessLogSampleRate)
+	}
+
+	// Flag documents whose embeddings were generated from outdated content.
+	// Tenants are listed from the primary database, the control plane.
+	if cfg.EmbeddingCheckEnabled && dataStore != nil {
+		embeddingChecker := consistency.NewChecker(databases[0], dataStore, telemetry.Metrics, consistency.Config{
+			Interval: cfg.EmbeddingCheckInterval,
+		})
+		embeddingChecker.SetSafeMode(safeMode)
+		embeddingChecker.Start()
+		defer embeddingChecker.Close()
+		slog.Info("Embedding consistency checker enabled", "interval", cfg.EmbeddingCheckInterval.String())
+	}
+
+	// Report exact and near-duplicate documents for review, resolving them automatically
+	// when configured
+	var duplicateDetector *dedup.Detector
+	if cfg.DedupEnabled && dataStore != nil {
+		duplicateDetector = dedup.NewDetector(databases[0], dataStore, telemetry.Metrics, cfg.Dedup)
+		duplicateDetector.SetSafeMode(safeMode)
+		duplicateDetector.Start()
+		defer duplicateDetector.Close()
+		slog.Info("Duplicate detector enabled",
+			"interval", cfg.Dedup.Interval.String(), "threshold", cfg.Dedup.Threshold, "auto_action", cfg.Dedup.AutoAction)
+	}
+
+	// Publish each tenant's usage of its document and storage quotas, which inserts enforce
+	if telemetry.Metrics != nil && dataStore != nil {
+		quotaReporter := quota.NewReporter(databases[0], dataStore, telemetry.Metrics, quota.Config{
+			Interval: cfg.QuotaReportInterval,
+		})
+		quotaReporter.Start()
+		defer quotaReporter.Close()
+	}
+
+	// Sample the Postgres and Redis connection pools for the pool gauges, so operators can
+	// alert on exhaustion
+	if telemetry.Metrics != nil {
+		poolReporter := observability.NewPoolReporter(telemetry.Metrics, cfg.PoolStatsInterval)
+		for i, db := range databases {
+			region := ""
+			if len(databases) > 1 {
+				region = regionNames[i]
+			}
+			poolReporter.Add(func() []observability.PoolSample { return db.PoolSamples(region) })
+		}
+		if redisAvailable {
+			poolReporter.Add(func() []observability.PoolSample {
+				stats := redisClient.PoolStats()
+				return []observability.PoolSample{{
+					System:   "redis",
+					Pool:     "default",
+					Max:      int64(redisClient.Options().PoolSize),
+					Total:    int64(stats.TotalConns),
+					Acquired: int64(stats.TotalConns) - int64(stats.IdleConns),
+					Idle:     int64(stats.IdleConns),
+					Timeouts: int64(stats.Timeouts),
+				}}
+			})
+		}
+		poolReporter.Start()
+		defer poolReporter.Close()
+	}
 
 	// Create MCP handler with telemetry
 	mcpHandler := server.NewMCPHandler(toolRegistry, telemetry)
+	mcpHandler.SetResourceRegistry(resourceRegistry)
+	if redactor != nil {
+		mcpHandler.SetRedactor(redactor)
+	}
+	if truncator != nil {
+		mcpHandler.SetTruncator(truncator)
+	}
+
+	// Images too large to inline in tool results are stored by content hash and linked to,
+	// in the store of the tenant's data region when data residency is on
+	var blobStore *blobs.ContentStore
+	if cfg.Blobs.Enabled() {
+		store, err := blobs.Open(cfg.Blobs.Store)
+		if err != nil {
+			logging.Fatal("Failed to open blob store", "backend", cfg.Blobs.Store.Backend, "error", err)
+		}
+		blobStore = blobs.NewContentStore(store)
+		if regions != nil {
+			stores := map[string]blobs.Store{database.DefaultRegion: store}
+			for region := range cfg.DataRegions {
+				if stores[region], err = blobs.Open(cfg.Blobs.RegionStore(region)); err != nil {
+					logging.Fatal("Failed to open region blob store", "region", region, "error", err)
+				}
+			}
+			blobStore = blobs.NewRegionalContentStore(regions, stores)
+		}
+		mcpHandler.SetBlobs(tools.NewBlobs(blobStore, cfg.Blobs.BaseURL, cfg.Blobs.MaxInlineBytes))
+		gdprSources = append(gdprSources, gdpr.NewBlobSource(blobStore))
+		slog.Info("Blob storage enabled", "backend", cfg.Blobs.Store.Backend, "max_inline_bytes", cfg.Blobs.MaxInlineBytes)
+	}
+
+	// Deprecated endpoints, methods and tools get Deprecation/Sunset headers and JSON-RPC
+	// warnings, and every use is counted in mcp_deprecated_usage_total. Declare them here:
+	//   deprecations.Deprecate(deprecation.KindTool, "old_tool", deprecation.Notice{
+	//       Since: ..., Sunset: ..., Replacement: "new_tool"})
+	deprecations := deprecation.NewRegistry()
+	if telemetry.Metrics != nil {
+		deprecations.SetRecorder(telemetry.Metrics)
+	}
+	mcpHandler.SetDeprecations(deprecations)
+
+	// Record every tool call in the audit log and purge entries past their retention.
+	// Tenants and retention overrides are read from the primary database, the control plane.
+	if cfg.AuditLogEnabled && dataStore != nil {
+		auditRecorder := audit.NewRecorder(dataStore, audit.Config{})
+		auditRecorder.Start()
+		defer auditRecorder.Close()
+		mcpHandler.SetAuditor(auditRecorder)
+
+		auditPurger := audit.NewPurger(databases[0], databases[0], dataStore, audit.RetentionConfig{
+			Retention: cfg.AuditRetention,
+		})
+		auditPurger.Start()
+		defer auditPurger.Close()
+		slog.Info("Tool call audit log enabled", "retention", cfg.AuditRetention.String())
+	}
+
+	// Relay document lifecycle events from the outbox to Redis Pub/Sub
+	if cfg.Database.Outbox && dataStore != nil && redisAvailable {
+		publisher := outbox.NewRedisPublisher(redisClient, cfg.OutboxChannelPrefix)
+		relay := outbox.NewRelay(databases[0], dataStore, publisher, outbox.Config{
+			Interval:  cfg.OutboxRelayInterval,
+			Retention: cfg.OutboxRetention,
+		})
+		relay.Start()
+		defer relay.Close()
+		slog.Info("Document event outbox enabled", "channels", publisher.Channel("*"))
+	}
+
+	// Sessions let clients subscribe to resources and receive notifications/resources/updated,
+	// and notifications/tools/list_changed when tools change, on their GET /mcp stream. With the outbox the notifications follow the Redis event
+	// stream, so they cover writes on every server instance; otherwise only local writes.
+	// Tools such as summarize_document send sampling/createMessage to clients over the same stream.
+	sessionHub := sessions.NewHub(sessions.Config{IdleTimeout: cfg.SessionIdleTimeout, RequestTimeout: cfg.SamplingTimeout})
+	mcpHandler.SetSessions(sessionHub)
+	toolRegistry.OnListChanged(func(tenantID string) {
+		sessionHub.NotifyToolsListChanged(tenantID)
+	})
+	notifyDocument := func(tenantID, docID string) {
+		sessionHub.NotifyResourceUpdated(tenantID, resources.DocumentURIs(docID)...)
+	}
+	if cfg.Database.Outbox && dataStore != nil && redisAvailable {
+		subscriber := outbox.NewSubscriber(redisClient, cfg.OutboxChannelPrefix, func(event database.DocumentEvent) {
+			notifyDocument(event.TenantID, event.DocumentID)
+		})
+		if err := subscriber.Start(ctx); err != nil {
+			logging.Fatal("Failed to subscribe to document events", "error", err)
+		}
+		defer subscriber.Close()
+	} else {
+		for _, d := range databases {
+			d.AddDocumentChangeHook(func(ctx context.Context, tenantID, docID string) {
+				notifyDocument(tenantID, docID)
+			})
+		}
+	}
 
 	// Setup middleware
 	authMiddleware := middleware.NewAuthMiddleware(jwtValidator)
+	roleResolver := auth.NewRoleResolver(roleStore, cfg.RoleCacheTTL)
+	authMiddleware.SetRoleResolver(roleResolver)
+	if oidcVerifier != nil {
+		authMiddleware.SetTokenVerifier(oidcVerifier)
+		authMiddleware.SetResourceMetadataURL(auth.ResourceMetadataURL(cfg.OIDC.Resource))
+	}
 	rateLimiter := middleware.NewRateLimiter(redisClient, cfg.RateLimit)
-	tracingMiddleware := middleware.NewTracingMiddleware(telemetry)
+	rateLimiter.SetTenantLimits(tenantLimits, 0) // limits recorded at onboarding override RATE_LIMIT
+	rateLimiter.SetFailurePolicy(cfg.RateLimitFailurePolicy, cfg.RateLimitLocalFallback)
+	if telemetry.Metrics != nil {
+		rateLimiter.SetRecorder(telemetry.Metrics)
+	}
+	tracingMiddleware := httpserver.NewTracingMiddleware(telemetry.Tracer)
+
+	// SIGHUP reloads the config; rate limit, log level and tool timeouts apply right away
+	reloader := config.NewReloader(cfg, os.Args[1:])
+	reloader.OnReload(func(cfg config.Config) {
+		if err := logging.SetLevel(cfg.Logging.Level); err != nil {
+			slog.Error("Failed to apply log level", "error", err)
+		}
+		rateLimiter.SetLimit(cfg.RateLimit)
+		toolRegistry.SetTimeouts(cfg.ToolTimeout, cfg.ToolTimeouts)
+	})
+	reloader.Start()
+	defer reloader.Close()
 
 	// Create HTTP server with middleware stack
 	mux := http.NewServeMux()
 
-	// Health check endpoint (no auth required)
+	// Plain health check kept for existing monitors (no auth required); it does not look at
+	// dependencies, the /healthz and /readyz probes below do
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
+	// Liveness (/healthz) and readiness (/readyz) probes, no auth required. Safe mode keeps
+	// reads available, so it does not affect readiness; the state is reported for operators
+	// and dashboards.
+	probes.AddDetail("safe_mode", func() interface{} { return safeMode.State() })
+	if len(databases) > 0 {
+		// Connection stats of the primary and read replica pools, per region
+		probes.AddDetail("database_pools", func() interface{} {
+			pools := make(map[string][]database.PoolStats, len(databases))
+			for i, db := range databases {
+				pools[regionNames[i]] = db.PoolStats()
+			}
+			return pools
+		})
+	}
+
 	// Metrics endpoint for Prometheus (no auth required)
 	if cfg.EnableMetrics {
-		mux.Handle("/metrics", promhttp.Handler())
-		log.Printf("Metrics endpoint: http://localhost:%s/metrics", cfg.Port)
+		mux.Handle("/metrics", observability.MetricsHandler())
+		slog.Info("Metrics endpoint enabled", "url", "http://localhost:"+cfg.Port+"/metrics")
 	}
 
 	// MCP endpoint with full middleware stack (tracing -> auth -> rate limiting -> handler)
+	var mcpEndpoint http.Handler = mcpHandler
+	if redisAvailable {
+		mcpEndpoint = rateLimiter.Handler(mcpHandler)
+	}
+	mcpAuth := authMiddleware.OptionalHandler
+	if oidcVerifier != nil {
+		// A 401 on the first request starts the OAuth handshake of MCP clients
+		mcpAuth = authMiddleware.Handler
+	}
 	mux.Handle("/mcp",
 		tracingMiddleware.Handler(
-			authMiddleware.OptionalHandler(
-				rateLimiter.Handler(mcpHandler),
-			),
+			mcpAuth(mcpEndpoint),
+		),
+	)
+
+	// OAuth protected resource and authorization server metadata (no auth required), so
+	// MCP clients can discover where to get a token for this server
+	if oidcVerifier != nil {
+		oauthMetadata := server.NewOAuthMetadataHandler(oidcVerifier.ResourceMetadata(), oidcVerifier.Metadata())
+		mux.Handle(auth.ProtectedResourceMetadataPath, oauthMetadata)
+		if path := auth.ResourceMetadataPath(cfg.OIDC.Resource); path != auth.ProtectedResourceMetadataPath {
+			mux.Handle(path, oauthMetadata)
+		}
+		mux.Handle(auth.AuthorizationServerMetadataPath, oauthMetadata)
+	}
+
+	// Search profile management endpoints (require admin scope)
+	searchProfilesHandler := tracingMiddleware.Handler(
+		authMiddleware.Handler(server.NewSearchProfilesHandler(profileStore)),
+	)
+	mux.Handle(server.SearchProfilesPath, searchProfilesHandler)
+	mux.Handle(server.SearchProfilesPath+"/", searchProfilesHandler)
+
+	// Synonym dictionary endpoint (requires admin scope)
+	mux.Handle(server.SynonymsPath, tracingMiddleware.Handler(
+		authMiddleware.Handler(server.NewSynonymsHandler(synonymStore)),
+	))
+
+	// Tenant role assignment endpoints (require admin scope)
+	rolesHandler := server.NewRolesHandler(roleStore)
+	rolesHandler.SetRoleResolver(roleResolver)
+	rolesEndpoint := tracingMiddleware.Handler(
+		authMiddleware.Handler(authMiddleware.RequireScope(auth.ScopeAdmin, rolesHandler)),
+	)
+	mux.Handle(server.RolesPath, rolesEndpoint)
+	mux.Handle(server.RolesPath+"/", rolesEndpoint)
+
+	// Estimated embedding and LLM cost of the caller's tenant (requires admin scope)
+	mux.Handle(server.CostsPath,
+		tracingMiddleware.Handler(
+			authMiddleware.Handler(server.NewCostsHandler(costTracker)),
 		),
 	)
 
+	// Safe mode state and toggle (read needs admin scope, changes the operator scope)
+	mux.Handle(server.SafeModePath,
+		tracingMiddleware.Handler(
+			authMiddleware.Handler(server.NewSafeModeHandler(safeMode)),
+		),
+	)
+
+	// Built-in tool withdrawal and restore (listing needs admin scope, changes the operator scope)
+	toolsEndpoint := tracingMiddleware.Handler(
+		authMiddleware.Handler(server.NewToolsHandler(toolRegistry)),
+	)
+	mux.Handle(server.ToolsPath, toolsEndpoint)
+	mux.Handle(server.ToolsPath+"/", toolsEndpoint)
+
+	// Tenant WASM tool uploads (require admin scope); uploads and deletions are refused in safe mode
+	if wasmTools != nil {
+		wasmToolsEndpoint := tracingMiddleware.Handler(
+			authMiddleware.Handler(safeMode.Guard("WASM tool changes", server.NewWASMToolsHandler(wasmTools, toolRegistry))),
+		)
+		mux.Handle(server.WASMToolsPath, wasmToolsEndpoint)
+		mux.Handle(server.WASMToolsPath+"/", wasmToolsEndpoint)
+	}
+
+	// Binary tool output linked from tool results (requires authentication)
+	if blobStore != nil {
+		mux.Handle(server.BlobsPath,
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(server.NewBlobsHandler(blobStore)),
+			),
+		)
+	}
+
+	// Tenant onboarding endpoint (requires the provision scope)
+	if cfg.OnboardingEnabled {
+		onboardingService := onboarding.NewService(tenantStore, roleStore, cfg.Onboarding)
+		onboardingService.SetRegions(regionNames)
+		onboardingService.SetDocumentStore(documentWriter)
+		if tokenIssuer != nil {
+			onboardingService.SetTokenIssuer(tokenIssuer)
+		}
+		if cfg.Onboarding.A2AURL != "" {
+			budgets := onboarding.NewA2ABudgetClient(cfg.Onboarding.A2AURL, cfg.Onboarding.A2AAdminToken)
+			if certificates != nil {
+				budgets.SetTLSConfig(certificates.ClientConfig())
+			}
+			onboardingService.SetBudgets(budgets)
+		}
+		mux.Handle(server.TenantsPath,
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(safeMode.Guard("tenant onboarding", server.NewOnboardingHandler(onboardingService))),
+			),
+		)
+		slog.Info("Tenant onboarding enabled", "api_keys", tokenIssuer != nil, "a2a_budgets", cfg.Onboarding.A2AURL != "")
+	}
+
+	// Admin endpoints read user data from Postgres
+	if dataStore != nil {
+		// Access log reporting endpoint (requires admin scope)
+		mux.Handle("/admin/access-log",
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(server.NewAccessLogHandler(dataStore)),
+			),
+		)
+
+		// Tool call audit log query endpoint (requires admin scope)
+		mux.Handle("/admin/audit",
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(server.NewAuditHandler(dataStore)),
+			),
+		)
+
+		// Rate limit dry runs over the tool call audit log (require admin scope)
+		mux.Handle(server.RateLimitSimulationPath,
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(server.NewRateLimitSimulationHandler(dataStore, rateLimiter.TenantLimit)),
+			),
+		)
+
+		// Collection registry with per-collection embedding settings (requires admin scope)
+		collectionsEndpoint := tracingMiddleware.Handler(
+			authMiddleware.Handler(server.NewCollectionsHandler(dataStore)),
+		)
+		mux.Handle(server.CollectionsPath, collectionsEndpoint)
+		mux.Handle(server.CollectionsPath+"/", collectionsEndpoint)
+
+		// Per-collection vector index management (requires admin scope); builds are refused in safe mode
+		vectorIndexesEndpoint := tracingMiddleware.Handler(
+			authMiddleware.Handler(safeMode.Guard("vector index changes", server.NewVectorIndexesHandler(dataStore))),
+		)
+		mux.Handle(server.VectorIndexesPath, vectorIndexesEndpoint)
+		mux.Handle(server.VectorIndexesPath+"/", vectorIndexesEndpoint)
+
+		// Duplicate document review and resolution (requires admin scope); resolution is refused in safe mode
+		if duplicateDetector != nil {
+			duplicatesEndpoint := tracingMiddleware.Handler(
+				authMiddleware.Handler(safeMode.Guard("duplicate resolution", server.NewDuplicatesHandler(duplicateDetector))),
+			)
+			mux.Handle(server.DuplicatesPath, duplicatesEndpoint)
+			mux.Handle(server.DuplicatesPath+"/", duplicatesEndpoint)
+		}
+
+		// GDPR export and erasure endpoints (require admin scope). They also cover the A2A
+		// server's tasks, usage and schedules when it is configured.
+		gdprSources = append(gdprSources, gdpr.NewCostSource())
+		if cfg.Onboarding.A2AURL != "" {
+			a2aSource := gdpr.NewA2ASource(cfg.Onboarding.A2AURL, cfg.Onboarding.A2AAdminToken)
+			if certificates != nil {
+				a2aSource.SetTLSConfig(certificates.ClientConfig())
+			}
+			gdprSources = append(gdprSources, a2aSource)
+		}
+		gdprHandler := server.NewGDPRHandler(gdpr.NewService(gdprSources...))
+		mux.Handle("/admin/gdpr/export",
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(http.HandlerFunc(gdprHandler.HandleExport)),
+			),
+		)
+		mux.Handle("/admin/gdpr/erase",
+			tracingMiddleware.Handler(
+				authMiddleware.Handler(safeMode.Guard("GDPR erasure", http.HandlerFunc(gdprHandler.HandleErase))),
+			),
+		)
+	}
+
+	// Every request gets an ID and a completion log entry
+	loggingMiddleware := httpserver.NewLoggingMiddleware(slog.Default())
+
+	// Track in-flight requests so shutdown can drain them
+	lifecycleManager := lifecycle.NewManager()
+	if telemetry.Metrics != nil {
+		lifecycleManager.SetRecorder(telemetry.Metrics)
+	}
+	mcpHandler.SetLifecycle(lifecycleManager)
+	probes.SetDraining(lifecycleManager.Draining())
+
+	// Request bodies are limited after gzip decompression; large responses are compressed
+	bodyMiddleware := httpserver.NewBodyMiddleware(httpserver.BodyConfig{
+		MaxRequestBytes: int64(cfg.MaxRequestBytes),
+		Gzip:            cfg.GzipResponses,
+		GzipMinBytes:    cfg.GzipMinBytes,
+	})
+
 	// Create HTTP server
+	var handler http.Handler = bodyMiddleware.Handler(probes.Handler(lifecycleManager.Handler(deprecations.Handler(mux))))
+	if certificates != nil && cfg.TLS.CAFile != "" {
+		// Mutual TLS: every request but the probes needs a client certificate, mapped to
+		// its tenant or service identity
+		clientCerts := mtls.NewMiddleware(cfg.TLS.Identities)
+		clientCerts.SetExemptPaths("/health", health.LivenessPath, health.ReadinessPath)
+		handler = clientCerts.Handler(handler)
+	}
 	httpServer := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      mux,
+		Handler:      loggingMiddleware.Handler(handler),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	scheme := "http"
+	if certificates != nil {
+		httpServer.TLSConfig = certificates.ServerConfig()
+		scheme = "https"
+	}
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting MCP server on port %s...", cfg.Port)
-		log.Printf("MCP endpoint: http://localhost:%s/mcp", cfg.Port)
-		log.Printf("Health check: http://localhost:%s/health", cfg.Port)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+		slog.Info("Starting MCP server",
+			"port", cfg.Port,
+			"mcp_endpoint", scheme+"://localhost:"+cfg.Port+"/mcp",
+			"health_check", scheme+"://localhost:"+cfg.Port+health.LivenessPath,
+			"readiness_check", scheme+"://localhost:"+cfg.Port+health.ReadinessPath,
+		)
+		var err error
+		if certificates != nil {
+			// The certificate comes from TLSConfig, so it can be reloaded
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logging.Fatal("Server error", "error", err)
 		}
 	}()
 
@@ -158,71 +964,128 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
+
+	// Refuse new requests and wait for in-flight tool executions; whatever is still
+	// running after the drain period is cancelled
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.DrainTimeout)
+	defer cancelDrain()
+	if err := lifecycleManager.Drain(drainCtx); err != nil {
+		slog.Warn("Shutdown drain incomplete", "error", err)
+	}
 
-	// Graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Graceful shutdown; cancelled handlers only need a moment to return
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logging.Fatal("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exited")
+	slog.Info("Server exited")
 }
 
-// Config holds application configuration
-type Config struct {
-	Port          string
-	Database      database.Config
-	RedisAddr     string
-	RateLimit     int
-	Environment   string
-	OTLPEndpoint  string
-	SamplingRate  float64
-	EnableTracing bool
-	EnableMetrics bool
+// demoTenantID is the acme-corp tenant that the demo token is issued for
+const demoTenantID = "11111111-1111-1111-1111-111111111111"
+
+// seedDemoDocuments loads the onboarding sample documents, a few of the init-db.sql ones,
+// into a local store so the demo token has something to search. Stores that already hold
+// documents are left alone.
+func seedDemoDocuments(ctx context.Context, store storage.ReadWriter) error {
+	existing, err := store.ListDocuments(ctx, demoTenantID, "", 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	count, err := onboarding.SeedSampleDocuments(ctx, store, demoTenantID)
+	if err != nil {
+		return err
+	}
+	slog.Info("Seeded demo documents", "count", count, "tenant_id", demoTenantID)
+	return nil
 }
 
-// loadConfig loads configuration from environment variables
-func loadConfig() Config {
-	return Config{
-		Port: getEnv("PORT", defaultPort),
-		Database: database.Config{
-			Host:     getEnv("DB_HOST", defaultDBHost),
-			Port:     getEnvInt("DB_PORT", defaultDBPort),
-			User:     getEnv("DB_USER", "mcp_user"),
-			Password: getEnv("DB_PASSWORD", "mcp_password"),
-			DBName:   getEnv("DB_NAME", "mcp_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-			MaxConns: int32(getEnvInt("DB_MAX_CONNS", 25)),
-			MinConns: int32(getEnvInt("DB_MIN_CONNS", 5)),
-		},
-		RedisAddr:     getEnv("REDIS_ADDR", defaultRedisAddr),
-		RateLimit:     getEnvInt("RATE_LIMIT", defaultRateLimit),
-		Environment:   getEnv("ENVIRONMENT", "development"),
-		OTLPEndpoint:  getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "jaeger:4318"),
-		SamplingRate:  getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
-		EnableTracing: getEnvBool("OTEL_ENABLE_TRACING", true),
-		EnableMetrics: getEnvBool("OTEL_ENABLE_METRICS", true),
+// setupAuth loads the JWT verification keys from the configured sources: PEM in
+// JWT_PUBLIC_KEYS, JWT_PUBLIC_KEY_FILES, JWT_KEYS_DIR (e.g. a mounted Kubernetes secret)
+// and Vault. In DEV_MODE an ephemeral demo key pair is also generated and a demo token printed.
+// The returned issuer signs onboarding API keys with JWT_SIGNING_KEY_FILE, or the demo key
+// in DEV_MODE; it is nil when neither is available.
+func setupAuth(ctx context.Context, cfg config.Config) (*auth.KeyManager, *auth.TokenIssuer, error) {
+	var sources []auth.KeySource
+	var issuer *auth.TokenIssuer
+	if cfg.JWTPublicKeys != "" {
+		source, err := auth.NewPEMKeySource("JWT_PUBLIC_KEYS", "env", []byte(cfg.JWTPublicKeys))
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, source)
+	}
+	for _, path := range cfg.JWTPublicKeyFiles {
+		sources = append(sources, auth.NewFileKeySource(path))
+	}
+	if cfg.JWTKeysDir != "" {
+		sources = append(sources, auth.NewDirKeySource(cfg.JWTKeysDir))
+	}
+	if cfg.Vault.Path != "" {
+		source, err := auth.NewVaultKeySource(cfg.Vault)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, source)
+	}
+	if cfg.JWTSigningKeyFile != "" {
+		var err error
+		if issuer, err = auth.LoadTokenIssuer(cfg.JWTSigningKeyFile, cfg.JWTIssuer, cfg.JWTAudience); err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, auth.NewStaticKeySource("signing key", issuer.VerificationKey()))
+	}
+
+	if cfg.DevMode {
+		source, demoIssuer, err := setupDemoKeys(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, source)
+		if issuer == nil {
+			issuer = demoIssuer
+		}
+	}
+
+	if len(sources) == 0 {
+		if cfg.OIDC.Enabled() {
+			// Only the OIDC provider's tokens are accepted
+			return auth.NewKeyManager(), issuer, nil
+		}
+		return nil, nil, fmt.Errorf("no JWT verification keys configured: set JWT_PUBLIC_KEYS, " +
+			"JWT_PUBLIC_KEY_FILES, JWT_KEYS_DIR, JWT_VAULT_PATH or OIDC_ISSUER, or DEV_MODE=true for demo keys")
+	}
+
+	keyManager := auth.NewKeyManager(sources...)
+	if err := keyManager.Reload(ctx); err != nil {
+		return nil, nil, err
 	}
+	return keyManager, issuer, nil
 }
 
-// setupAuth sets up authentication with demo keys for development
-func setupAuth() (*auth.JWTValidator, string, error) {
-	// In production, load keys from secure storage (e.g., vault, k8s secrets)
-	// For demo, generate RSA key pair
-	log.Println("Generating demo RSA key pair (DO NOT USE IN PRODUCTION)...")
+// setupDemoKeys generates an ephemeral RSA key pair for development, saves it for the UI
+// and prints a demo token. Tokens signed with it stop validating when the server restarts.
+func setupDemoKeys(cfg config.Config) (auth.KeySource, *auth.TokenIssuer, error) {
+	keysDir := cfg.DemoKeysDir
+	slog.Warn("DEV_MODE: generating demo RSA key pair (DO NOT USE IN PRODUCTION)")
 
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate private key: %w", err)
+		return nil, nil, fmt.Errorf("failed to generate private key: %w", err)
 	}
 
 	// Export public key to PEM
 	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to marshal public key: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
 	}
 
 	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
@@ -237,92 +1100,95 @@ func setupAuth() (*auth.JWTValidator, string, error) {
 	})
 
 	// Save keys to shared directory for UI access (demo only!)
-	keysDir := getEnv("DEMO_KEYS_DIR", "/tmp/demo-keys")
 	if err := os.MkdirAll(keysDir, 0755); err != nil {
-		log.Printf("Warning: Failed to create keys directory: %v", err)
+		slog.Warn("Failed to create keys directory", "error", err)
 	} else {
 		// Save public key
 		if err := os.WriteFile(keysDir+"/public_key.pem", publicKeyPEM, 0644); err != nil {
-			log.Printf("Warning: Failed to save public key: %v", err)
+			slog.Warn("Failed to save public key", "error", err)
 		} else {
-			log.Printf("Public key saved to %s/public_key.pem", keysDir)
+			slog.Info("Public key saved", "path", keysDir+"/public_key.pem")
 		}
 
 		// Save private key
 		if err := os.WriteFile(keysDir+"/private_key.pem", privateKeyPEM, 0600); err != nil {
-			log.Printf("Warning: Failed to save private key: %v", err)
+			slog.Warn("Failed to save private key", "error", err)
 		} else {
-			log.Printf("Private key saved to %s/private_key.pem", keysDir)
+			slog.Info("Private key saved", "path", keysDir+"/private_key.pem")
 		}
 	}
-
-	// Create JWT validator
-	validator, err := auth.NewJWTValidator(auth.Config{
-		PublicKeyPEM: string(publicKeyPEM),
-		Issuer:       "mcp-server-demo",
-		Audience:     "mcp-server",
-	})
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create JWT validator: %w", err)
-	}
+	slog.Info("Demo public key", "pem", publicKeyPEM)
 
 	// Generate a demo token for testing
 	demoToken, err := auth.GenerateDemoToken(
-		"11111111-1111-1111-1111-111111111111", // acme-corp tenant
+		demoTenantID, // acme-corp tenant
 		"demo-user",
 		[]string{"read", "write"},
 		privateKey,
 	)
 	if err != nil {
-		log.Printf("Warning: Failed to generate demo token: %v", err)
+		slog.Warn("Failed to generate demo token", "error", err)
 	} else {
-		log.Printf("\n=== DEMO TOKEN (Valid for 24 hours) ===\n%s\n", demoToken)
-		log.Println("Use this token in the Authorization header: Bearer <token>")
-		log.Println("=========================================")
+		slog.Info("Demo token (valid for 24 hours); send it in the Authorization header as Bearer <token>",
+			"token", demoToken)
 	}
 
-	return validator, string(publicKeyPEM), nil
-}
-
-// getEnv retrieves an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	issuer, err := auth.NewTokenIssuer(privateKey, "demo", cfg.JWTIssuer, cfg.JWTAudience)
+	if err != nil {
+		return nil, nil, err
 	}
-	return defaultValue
+	return auth.NewStaticKeySource("demo key", issuer.VerificationKey()), issuer, nil
 }
 
-// getEnvInt retrieves an integer environment variable or returns a default value
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		var intValue int
-		if _, err := fmt.Sscanf(value, "%d", &intValue); err == nil {
-			return intValue
+// genOTelConfig writes an OpenTelemetry Collector configuration matching the signals,
+// OTLP endpoint and service name the server is configured with (the same OTEL_*
+// environment variables), so the collector config cannot drift from the server's.
+func genOTelConfig(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("gen-otel-config", flag.ContinueOnError)
+	output := flags.String("o", "", "write the configuration to this file instead of stdout")
+	host := flags.String("host", "mcp-server", "host name the collector uses to reach this server")
+	tracesEndpoint := flags.String("traces-endpoint", "http://jaeger:4318", "OTLP HTTP endpoint the collector forwards traces to")
+	prometheusEndpoint := flags.String("prometheus-endpoint", "0.0.0.0:8889", "address the collector exposes scraped metrics on")
+	var extra []observability.CollectorService
+	flags.Func("service", "another service with the same signals, as name=host:port (repeatable)", func(value string) error {
+		name, target, ok := strings.Cut(value, "=")
+		if !ok || name == "" || target == "" {
+			return fmt.Errorf("expected name=host:port, got %q", value)
 		}
+		extra = append(extra, observability.CollectorService{Name: name, MetricsTarget: target})
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
 	}
-	return defaultValue
-}
 
-// getEnvFloat retrieves a float environment variable or returns a default value
-func getEnvFloat(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
-		var floatValue float64
-		if _, err := fmt.Sscanf(value, "%f", &floatValue); err == nil {
-			return floatValue
-		}
+	// The OTLP receiver listens on the port the server exports to
+	otlpPort := "4318"
+	if _, port, err := net.SplitHostPort(strings.TrimPrefix(strings.TrimPrefix(cfg.OTLPEndpoint, "http://"), "https://")); err == nil {
+		otlpPort = port
 	}
-	return defaultValue
-}
 
-// getEnvBool retrieves a boolean environment variable or returns a default value
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if value == "true" || value == "1" || value == "yes" {
-			return true
-		}
-		if value == "false" || value == "0" || value == "no" {
-			return false
+	services := append([]observability.CollectorService{{Name: "mcp-server", MetricsTarget: net.JoinHostPort(*host, cfg.Port)}}, extra...)
+	for i := range services {
+		services[i].Tracing = cfg.EnableTracing
+		if !cfg.EnableMetrics {
+			services[i].MetricsTarget = ""
 		}
 	}
-	return defaultValue
+
+	data, err := observability.GenerateCollectorConfig(observability.CollectorOptions{
+		Services:           services,
+		OTLPEndpoint:       net.JoinHostPort("0.0.0.0", otlpPort),
+		TracesEndpoint:     *tracesEndpoint,
+		PrometheusEndpoint: *prometheusEndpoint,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0644)
 }
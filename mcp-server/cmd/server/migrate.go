@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/config"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/migrations"
+)
+
+// migrationTarget is a database whose schema is migrated
+type migrationTarget struct {
+	region string
+	cfg    database.Config
+}
+
+// migrationTargets returns the primary database followed by the data region databases,
+// which have the same schema
+func migrationTargets(cfg config.Config) []migrationTarget {
+	targets := []migrationTarget{{region: database.DefaultRegion, cfg: cfg.Database}}
+	regions := make([]string, 0, len(cfg.DataRegions))
+	for region := range cfg.DataRegions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		targets = append(targets, migrationTarget{region: region, cfg: cfg.DataRegions[region]})
+	}
+	return targets
+}
+
+// withMigrator runs fn with a migrator on its own connection to the database of target
+func withMigrator(ctx context.Context, target migrationTarget, fn func(*migrations.Migrator) error) error {
+	conn, err := database.ConnectForMigrations(ctx, target.cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", target.region, err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	migrator, err := migrations.New(conn)
+	if err != nil {
+		return err
+	}
+	if err := fn(migrator); err != nil {
+		return fmt.Errorf("%s: %w", target.region, err)
+	}
+	return nil
+}
+
+// autoMigrate applies the pending migrations of every database before the server uses them
+func autoMigrate(ctx context.Context, cfg config.Config) error {
+	for _, target := range migrationTargets(cfg) {
+		err := withMigrator(ctx, target, func(m *migrations.Migrator) error {
+			applied, err := m.Up(ctx)
+			for _, migration := range applied {
+				slog.Info("Applied schema migration", "region", target.region, "version", migration.Version, "name", migration.Name)
+			}
+			if err == nil {
+				slog.Info("Database schema up to date", "region", target.region, "version", m.Latest())
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCommand applies, reverts or lists the schema migrations of the primary database
+// and the data region databases, as the migration user when one is configured:
+//
+//	mcp-server migrate [-region name] [up|down|status]
+func migrateCommand(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	region := flags.String("region", "", "only migrate this data region's database (default: all)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: mcp-server migrate [-region name] [up|down|status]")
+		fmt.Fprintln(flags.Output(), "  up      apply the pending migrations (default)")
+		fmt.Fprintln(flags.Output(), "  down    revert the newest applied migration")
+		fmt.Fprintln(flags.Output(), "  status  list the migrations and when they were applied")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	action := "up"
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		action = flags.Arg(0)
+	default:
+		return fmt.Errorf("expected one action, got %v", flags.Args())
+	}
+	if cfg.DBDriver != config.DriverPostgres {
+		return fmt.Errorf("migrations need the postgres driver, not %s", cfg.DBDriver)
+	}
+
+	var run func(io.Writer, string, *migrations.Migrator) error
+	switch action {
+	case "up":
+		run = migrateUp
+	case "down":
+		run = migrateDown
+	case "status":
+		run = migrateStatus
+	default:
+		return fmt.Errorf("unknown action %q: expected up, down or status", action)
+	}
+
+	ctx := context.Background()
+	found := false
+	for _, target := range migrationTargets(cfg) {
+		if *region != "" && target.region != *region {
+			continue
+		}
+		found = true
+		if err := withMigrator(ctx, target, func(m *migrations.Migrator) error {
+			return run(os.Stdout, target.region, m)
+		}); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown data region %q", *region)
+	}
+	return nil
+}
+
+func migrateUp(out io.Writer, region string, m *migrations.Migrator) error {
+	applied, err := m.Up(context.Background())
+	for _, migration := range applied {
+		fmt.Fprintf(out, "%s: applied %04d_%s\n", region, migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Fprintf(out, "%s: up to date at version %d\n", region, m.Latest())
+	}
+	return nil
+}
+
+func migrateDown(out io.Writer, region string, m *migrations.Migrator) error {
+	reverted, err := m.Down(context.Background())
+	if err != nil {
+		return err
+	}
+	if reverted == nil {
+		fmt.Fprintf(out, "%s: no migration to revert\n", region)
+		return nil
+	}
+	fmt.Fprintf(out, "%s: reverted %04d_%s\n", region, reverted.Version, reverted.Name)
+	return nil
+}
+
+func migrateStatus(out io.Writer, region string, m *migrations.Migrator) error {
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		state := "pending"
+		if status.AppliedAt != nil {
+			state = "applied " + status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Version > m.Latest() {
+			state += " (unknown to this binary)"
+		}
+		fmt.Fprintf(out, "%s: %04d_%s %s\n", region, status.Version, status.Name, state)
+	}
+	return nil
+}
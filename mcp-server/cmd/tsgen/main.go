@@ -0,0 +1,100 @@
+// Command tsgen writes TypeScript declarations for the MCP protocol types so JavaScript
+// clients stay in lockstep with the wire format. Regenerate after changing the types:
+//
+//	go generate ./cmd/tsgen
+package main
+
+//go:generate go run . -out ../../../clients/typescript/mcp.ts
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/tsgen"
+)
+
+const header = `Code generated by mcp-server/cmd/tsgen. DO NOT EDIT.
+MCP protocol types as sent over JSON-RPC by the MCP server.`
+
+func main() {
+	out := flag.String("out", "", "file to write (default stdout)")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	if err := generate(&buf); err != nil {
+		logging.Fatal("Failed to generate TypeScript", "error", err)
+	}
+	if *out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		logging.Fatal("Failed to write TypeScript", "path", *out, "error", err)
+	}
+}
+
+// generate writes the TypeScript declarations for the MCP protocol
+func generate(w io.Writer) error {
+	g := tsgen.New()
+
+	g.Const("JSONRPCVersion", protocol.JSONRPCVersion)
+	g.Const("MethodInitialize", protocol.MethodInitialize)
+	g.Const("MethodInitialized", protocol.MethodInitialized)
+	g.Const("MethodToolsList", protocol.MethodToolsList)
+	g.Const("MethodToolsCall", protocol.MethodToolsCall)
+	g.Const("MethodResourcesList", protocol.MethodResourcesList)
+	g.Const("MethodResourcesRead", protocol.MethodResourcesRead)
+	g.Const("MethodPromptsList", protocol.MethodPromptsList)
+	g.Const("MethodPromptsGet", protocol.MethodPromptsGet)
+	g.Const("MethodProgress", protocol.MethodProgress)
+	g.Const("MethodSamplingCreateMessage", protocol.MethodSamplingCreateMessage)
+
+	g.Const("ParseError", protocol.ParseError)
+	g.Const("InvalidRequest", protocol.InvalidRequest)
+	g.Const("MethodNotFound", protocol.MethodNotFound)
+	g.Const("InvalidParams", protocol.InvalidParams)
+	g.Const("InternalError", protocol.InternalError)
+	g.Const("ServerError", protocol.ServerError)
+	g.Const("AuthenticationRequired", protocol.AuthenticationRequired)
+	g.Const("AuthorizationFailed", protocol.AuthorizationFailed)
+	g.Const("RateLimitExceeded", protocol.RateLimitExceeded)
+	g.Const("ResourceNotFound", protocol.ResourceNotFound)
+	g.Const("ValidationError", protocol.ValidationError)
+	g.Const("RequestTimeout", protocol.RequestTimeout)
+	g.Const("SafeModeActive", protocol.SafeModeActive)
+	g.Const("RequestTooLarge", protocol.RequestTooLarge)
+	g.Const("QuotaExceeded", protocol.QuotaExceeded)
+	g.Const("WarningDeprecated", protocol.WarningDeprecated)
+
+	g.Const("ContentTypeText", protocol.ContentTypeText)
+	g.Const("ContentTypeImage", protocol.ContentTypeImage)
+	g.Const("ContentTypeResource", protocol.ContentTypeResource)
+	g.Const("ContentTypeResourceLink", protocol.ContentTypeResourceLink)
+
+	// The JSON-RPC envelope; Error would shadow the JavaScript global
+	g.Name(protocol.Request{}, "JSONRPCRequest")
+	g.Name(protocol.Response{}, "JSONRPCResponse")
+	g.Name(protocol.Error{}, "JSONRPCError")
+
+	g.Add(
+		protocol.InitializeRequest{},
+		protocol.InitializeResult{},
+		protocol.ToolsListResult{},
+		protocol.ToolCallRequest{},
+		protocol.ToolCallResult{},
+		protocol.ResourcesListResult{},
+		protocol.ResourceReadRequest{},
+		protocol.ResourceReadResult{},
+		protocol.PromptsListResult{},
+		protocol.PromptGetRequest{},
+		protocol.PromptGetResult{},
+		protocol.ProgressNotification{},
+		protocol.CreateMessageRequest{},
+		protocol.CreateMessageResult{},
+	)
+	return g.Write(w, header)
+}
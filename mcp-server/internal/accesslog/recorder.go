@@ -0,0 +1,169 @@
+package accesslog
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// Config holds access log configuration
+type Config struct {
+	// SampleRate is the fraction of reads recorded (0.0 to 1.0, default 1.0)
+	SampleRate float64
+	// ToolSampleRates overrides SampleRate for specific tools
+	ToolSampleRates map[string]float64
+	// BufferSize is the number of entries queued before new entries are dropped
+	BufferSize int
+	// FlushInterval is how often queued entries are written to the store
+	FlushInterval time.Duration
+}
+
+// Recorder records document reads asynchronously so tool calls are not slowed down
+type Recorder struct {
+	store   database.AccessLogStore
+	config  Config
+	entries chan database.DocumentAccess
+	random  func() float64
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewRecorder creates a new access log recorder
+func NewRecorder(store database.AccessLogStore, cfg Config) *Recorder {
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = 1.0
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	return &Recorder{
+		store:   store,
+		config:  cfg,
+		entries: make(chan database.DocumentAccess, cfg.BufferSize),
+		random:  rand.Float64,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start starts the background writer
+func (r *Recorder) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Close flushes queued entries and stops the background writer
+func (r *Recorder) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+// RecordAccess records that the caller read the given documents through a tool.
+// Requests without tenant context are ignored.
+func (r *Recorder) RecordAccess(ctx context.Context, tool string, documentIDs ...string) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil || len(documentIDs) == 0 {
+		return
+	}
+	if !r.sampled(tool) {
+		return
+	}
+
+	userID, _ := auth.ExtractUserID(ctx)
+	now := time.Now()
+
+	for _, docID := range documentIDs {
+		entry := database.DocumentAccess{
+			TenantID:   tenantID,
+			DocumentID: docID,
+			UserID:     userID,
+			Tool:       tool,
+			AccessedAt: now,
+		}
+
+		select {
+		case r.entries <- entry:
+		default:
+			slog.WarnContext(ctx, "Access log buffer full, dropping entry", "document_id", docID)
+		}
+	}
+}
+
+// sampled decides whether a read through the given tool should be recorded
+func (r *Recorder) sampled(tool string) bool {
+	rate := r.config.SampleRate
+	if toolRate, ok := r.config.ToolSampleRates[tool]; ok {
+		rate = toolRate
+	}
+
+	if rate >= 1.0 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return r.random() < rate
+}
+
+// run batches queued entries and writes them on every flush interval
+func (r *Recorder) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []database.DocumentAccess
+	for {
+		select {
+		case entry := <-r.entries:
+			batch = append(batch, entry)
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-r.stopCh:
+			for {
+				select {
+				case entry := <-r.entries:
+					batch = append(batch, entry)
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes the batch grouped by tenant and returns an empty batch
+func (r *Recorder) flush(batch []database.DocumentAccess) []database.DocumentAccess {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	byTenant := make(map[string][]database.DocumentAccess)
+	for _, entry := range batch {
+		byTenant[entry.TenantID] = append(byTenant[entry.TenantID], entry)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for tenantID, entries := range byTenant {
+		if err := r.store.RecordDocumentAccess(ctx, tenantID, entries); err != nil {
+			slog.Error("Failed to write access log entries", "count", len(entries), "tenant_id", tenantID, "error", err)
+		}
+	}
+
+	return batch[:0]
+}
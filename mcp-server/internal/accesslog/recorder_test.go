@@ -0,0 +1,105 @@
+package accesslog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccessLogStore is an in-memory AccessLogStore for testing
+type fakeAccessLogStore struct {
+	mu      sync.Mutex
+	entries []database.DocumentAccess
+}
+
+func (f *fakeAccessLogStore) RecordDocumentAccess(ctx context.Context, tenantID string, entries []database.DocumentAccess) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeAccessLogStore) ListAccessByDocument(ctx context.Context, tenantID, docID string, limit, offset int) ([]database.DocumentAccess, error) {
+	return nil, nil
+}
+
+func (f *fakeAccessLogStore) ListAccessByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]database.DocumentAccess, error) {
+	return nil, nil
+}
+
+func (f *fakeAccessLogStore) recorded() []database.DocumentAccess {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]database.DocumentAccess(nil), f.entries...)
+}
+
+func authContext() context.Context {
+	return auth.WithAuth(context.Background(), &auth.Claims{
+		TenantID: "tenant-123",
+		UserID:   "user-1",
+	})
+}
+
+func TestRecorder_RecordAccess(t *testing.T) {
+	store := &fakeAccessLogStore{}
+	recorder := NewRecorder(store, Config{FlushInterval: 10 * time.Millisecond})
+	recorder.Start()
+
+	recorder.RecordAccess(authContext(), "retrieve_document", "doc-1", "doc-2")
+	recorder.Close()
+
+	entries := store.recorded()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "tenant-123", entries[0].TenantID)
+	assert.Equal(t, "user-1", entries[0].UserID)
+	assert.Equal(t, "retrieve_document", entries[0].Tool)
+	assert.Equal(t, "doc-1", entries[0].DocumentID)
+	assert.False(t, entries[0].AccessedAt.IsZero())
+}
+
+func TestRecorder_IgnoresUnauthenticated(t *testing.T) {
+	store := &fakeAccessLogStore{}
+	recorder := NewRecorder(store, Config{})
+	recorder.Start()
+
+	recorder.RecordAccess(context.Background(), "retrieve_document", "doc-1")
+	recorder.Close()
+
+	assert.Empty(t, store.recorded())
+}
+
+func TestRecorder_Sampling(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		tool      string
+		random    float64
+		wantCount int
+	}{
+		{"default records everything", Config{}, "search_documents", 0.99, 1},
+		{"sampled in", Config{SampleRate: 0.5}, "search_documents", 0.2, 1},
+		{"sampled out", Config{SampleRate: 0.5}, "search_documents", 0.7, 0},
+		{"tool override wins", Config{SampleRate: 0.1, ToolSampleRates: map[string]float64{"retrieve_document": 1.0}}, "retrieve_document", 0.9, 1},
+		{"tool disabled", Config{ToolSampleRates: map[string]float64{"list_documents": 0}}, "list_documents", 0.0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeAccessLogStore{}
+			recorder := NewRecorder(store, tt.config)
+			recorder.random = func() float64 { return tt.random }
+			recorder.Start()
+
+			recorder.RecordAccess(authContext(), tt.tool, "doc-1")
+			recorder.Close()
+
+			assert.Len(t, store.recorded(), tt.wantCount)
+		})
+	}
+}
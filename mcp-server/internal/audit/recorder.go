@@ -0,0 +1,166 @@
+// Package audit records every MCP tool call in a tenant's audit log and enforces its
+// retention, providing evidence of who ran which tool, with what outcome, and when.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// Tool call outcomes recorded in the audit log
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+	StatusTimeout = "timeout"
+	StatusDenied  = "denied"
+)
+
+// Config holds audit recorder configuration
+type Config struct {
+	// BufferSize is the number of entries queued before new entries are dropped
+	BufferSize int
+	// FlushInterval is how often queued entries are written to the store
+	FlushInterval time.Duration
+}
+
+// Recorder records tool calls asynchronously so they are not slowed down by the audit log
+type Recorder struct {
+	store   database.AuditLogStore
+	config  Config
+	entries chan database.AuditEntry
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewRecorder creates a new audit recorder
+func NewRecorder(store database.AuditLogStore, cfg Config) *Recorder {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 4096
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	return &Recorder{
+		store:   store,
+		config:  cfg,
+		entries: make(chan database.AuditEntry, cfg.BufferSize),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start starts the background writer
+func (r *Recorder) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Close flushes queued entries and stops the background writer
+func (r *Recorder) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+// RecordToolCall records a tool call made by the caller. Arguments are stored as a
+// digest so the audit log never holds query text or other user content.
+// Requests without tenant context are ignored.
+func (r *Recorder) RecordToolCall(ctx context.Context, tool string, args map[string]interface{}, status string, latency time.Duration) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return
+	}
+	userID, _ := auth.ExtractUserID(ctx)
+
+	entry := database.AuditEntry{
+		TenantID:   tenantID,
+		UserID:     userID,
+		Tool:       tool,
+		ArgsDigest: Digest(args),
+		Status:     status,
+		LatencyMs:  latency.Milliseconds(),
+		CreatedAt:  time.Now(),
+	}
+
+	select {
+	case r.entries <- entry:
+	default:
+		slog.WarnContext(ctx, "Audit log buffer full, dropping entry", "tool", tool, "tenant_id", tenantID)
+	}
+}
+
+// Digest returns a stable SHA-256 digest of tool arguments. Map keys are encoded in
+// sorted order, so equal arguments always produce the same digest.
+func Digest(args map[string]interface{}) string {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "unencodable"
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// run batches queued entries and writes them on every flush interval
+func (r *Recorder) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []database.AuditEntry
+	for {
+		select {
+		case entry := <-r.entries:
+			batch = append(batch, entry)
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-r.stopCh:
+			for {
+				select {
+				case entry := <-r.entries:
+					batch = append(batch, entry)
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes the batch grouped by tenant and returns an empty batch
+func (r *Recorder) flush(batch []database.AuditEntry) []database.AuditEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	byTenant := make(map[string][]database.AuditEntry)
+	for _, entry := range batch {
+		byTenant[entry.TenantID] = append(byTenant[entry.TenantID], entry)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for tenantID, entries := range byTenant {
+		if err := r.store.RecordAuditEntries(ctx, tenantID, entries); err != nil {
+			slog.Error("Failed to write audit log entries", "count", len(entries), "tenant_id", tenantID, "error", err)
+		}
+	}
+
+	return batch[:0]
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditLogStore is an in-memory AuditLogStore for testing
+type fakeAuditLogStore struct {
+	mu      sync.Mutex
+	entries []database.AuditEntry
+	purged  map[string]time.Time
+}
+
+func (f *fakeAuditLogStore) RecordAuditEntries(ctx context.Context, tenantID string, entries []database.AuditEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeAuditLogStore) ListAuditEntries(ctx context.Context, tenantID string, filter database.AuditFilter) ([]database.AuditEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeAuditLogStore) PurgeAuditEntries(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.purged == nil {
+		f.purged = make(map[string]time.Time)
+	}
+	f.purged[tenantID] = before
+	return 1, nil
+}
+
+func (f *fakeAuditLogStore) recorded() []database.AuditEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]database.AuditEntry(nil), f.entries...)
+}
+
+func authContext() context.Context {
+	return auth.WithAuth(context.Background(), &auth.Claims{
+		TenantID: "tenant-123",
+		UserID:   "user-1",
+	})
+}
+
+func TestRecorder_RecordToolCall(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	recorder := NewRecorder(store, Config{FlushInterval: 10 * time.Millisecond})
+	recorder.Start()
+
+	args := map[string]interface{}{"query": "salary bands", "limit": 5}
+	recorder.RecordToolCall(authContext(), "search_documents", args, StatusSuccess, 42*time.Millisecond)
+	recorder.Close()
+
+	entries := store.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "tenant-123", entries[0].TenantID)
+	assert.Equal(t, "user-1", entries[0].UserID)
+	assert.Equal(t, "search_documents", entries[0].Tool)
+	assert.Equal(t, StatusSuccess, entries[0].Status)
+	assert.Equal(t, int64(42), entries[0].LatencyMs)
+	assert.Equal(t, Digest(args), entries[0].ArgsDigest)
+	assert.NotContains(t, entries[0].ArgsDigest, "salary")
+	assert.False(t, entries[0].CreatedAt.IsZero())
+}
+
+func TestRecorder_IgnoresUnauthenticated(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	recorder := NewRecorder(store, Config{})
+	recorder.Start()
+	recorder.RecordToolCall(context.Background(), "search_documents", nil, StatusError, time.Millisecond)
+	recorder.Close()
+
+	assert.Empty(t, store.recorded())
+}
+
+func TestRecorder_DropsWhenBufferFull(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	recorder := NewRecorder(store, Config{BufferSize: 1})
+
+	// Not started, so the second entry cannot be queued
+	recorder.RecordToolCall(authContext(), "search_documents", nil, StatusSuccess, 0)
+	recorder.RecordToolCall(authContext(), "search_documents", nil, StatusSuccess, 0)
+
+	recorder.Start()
+	recorder.Close()
+	assert.Len(t, store.recorded(), 1)
+}
+
+func TestDigest(t *testing.T) {
+	a := Digest(map[string]interface{}{"query": "mfa", "limit": 5})
+	b := Digest(map[string]interface{}{"limit": 5, "query": "mfa"})
+	c := Digest(map[string]interface{}{"query": "vpn", "limit": 5})
+
+	assert.Equal(t, a, b, "key order does not change the digest")
+	assert.NotEqual(t, a, c)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, a)
+	assert.Equal(t, Digest(map[string]interface{}{}), Digest(nil))
+}
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// RetentionSettingKey is the tenant setting that overrides the default retention, in days
+const RetentionSettingKey = "audit_retention_days"
+
+// SettingsLookup returns a tenant's settings
+type SettingsLookup interface {
+	GetTenantSettings(ctx context.Context, tenantID string) (map[string]interface{}, error)
+}
+
+// RetentionConfig holds audit log retention configuration
+type RetentionConfig struct {
+	// Retention is how long entries are kept unless a tenant overrides it (default 365 days)
+	Retention time.Duration
+	// Interval is how often expired entries are purged (default 24h)
+	Interval time.Duration
+	// TenantTimeout bounds the purge of a single tenant (default 1m)
+	TenantTimeout time.Duration
+}
+
+// Purger periodically deletes audit entries older than each tenant's retention period
+type Purger struct {
+	tenants  database.TenantLister
+	settings SettingsLookup
+	store    database.AuditLogStore
+	config   RetentionConfig
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewPurger creates a new audit log retention job. Tenants and their settings are read
+// from the control plane; settings may be nil to apply the default retention to everyone.
+func NewPurger(tenants database.TenantLister, settings SettingsLookup, store database.AuditLogStore, cfg RetentionConfig) *Purger {
+	if cfg.Retention <= 0 {
+		cfg.Retention = 365 * 24 * time.Hour
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.TenantTimeout <= 0 {
+		cfg.TenantTimeout = time.Minute
+	}
+
+	return &Purger{
+		tenants:  tenants,
+		settings: settings,
+		store:    store,
+		config:   cfg,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start runs a purge right away and then on every interval
+func (p *Purger) Start() {
+	p.wg.Add(1)
+	go p.run()
+}
+
+// Close stops the background purges and waits for a running purge to finish
+func (p *Purger) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	p.wg.Wait()
+}
+
+// run purges all tenants on every interval until the purger is closed
+func (p *Purger) run() {
+	defer p.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.PurgeAll(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Audit log retention failed", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// PurgeAll deletes expired entries of every active tenant and returns how many were
+// deleted. A failing tenant is logged and skipped so it cannot block the others.
+func (p *Purger) PurgeAll(ctx context.Context) (int64, error) {
+	tenantIDs, err := p.tenants.ListActiveTenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+
+		deleted, err := p.purgeTenant(ctx, tenantID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Audit log retention failed for tenant", "tenant_id", tenantID, "error", err)
+			continue
+		}
+		if deleted > 0 {
+			slog.InfoContext(ctx, "Purged expired audit log entries", "tenant_id", tenantID, "deleted", deleted)
+		}
+		total += deleted
+	}
+	return total, nil
+}
+
+// Retention returns the retention period of a tenant
+func (p *Purger) Retention(ctx context.Context, tenantID string) time.Duration {
+	if p.settings == nil {
+		return p.config.Retention
+	}
+
+	settings, err := p.settings.GetTenantSettings(ctx, tenantID)
+	if err != nil {
+		return p.config.Retention
+	}
+	if days, ok := settings[RetentionSettingKey].(float64); ok && days > 0 {
+		return time.Duration(days * float64(24*time.Hour))
+	}
+	return p.config.Retention
+}
+
+// purgeTenant purges a single tenant within the tenant timeout
+func (p *Purger) purgeTenant(ctx context.Context, tenantID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.TenantTimeout)
+	defer cancel()
+
+	cutoff := time.Now().Add(-p.Retention(ctx, tenantID))
+	return p.store.PurgeAuditEntries(ctx, tenantID, cutoff)
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTenants lists fixed tenants and serves their settings
+type fakeTenants struct {
+	ids      []string
+	settings map[string]map[string]interface{}
+}
+
+func (f *fakeTenants) ListActiveTenants(ctx context.Context) ([]string, error) {
+	return f.ids, nil
+}
+
+func (f *fakeTenants) GetTenantSettings(ctx context.Context, tenantID string) (map[string]interface{}, error) {
+	settings, ok := f.settings[tenantID]
+	if !ok {
+		return nil, errors.New("tenant not found or inactive")
+	}
+	return settings, nil
+}
+
+func TestPurger_PurgeAll(t *testing.T) {
+	tenants := &fakeTenants{
+		ids: []string{"tenant-a", "tenant-b", "tenant-c"},
+		settings: map[string]map[string]interface{}{
+			"tenant-a": {RetentionSettingKey: float64(30)},
+			"tenant-b": {"monthly_budget_usd": float64(500)},
+		},
+	}
+	store := &fakeAuditLogStore{}
+	purger := NewPurger(tenants, tenants, store, RetentionConfig{Retention: 90 * 24 * time.Hour})
+
+	before := time.Now()
+	deleted, err := purger.PurgeAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	// tenant-a overrides the retention; tenant-b and tenant-c (no settings) use the default
+	assert.WithinDuration(t, before.Add(-30*24*time.Hour), store.purged["tenant-a"], time.Minute)
+	assert.WithinDuration(t, before.Add(-90*24*time.Hour), store.purged["tenant-b"], time.Minute)
+	assert.WithinDuration(t, before.Add(-90*24*time.Hour), store.purged["tenant-c"], time.Minute)
+}
+
+func TestPurger_DefaultRetention(t *testing.T) {
+	purger := NewPurger(&fakeTenants{}, nil, &fakeAuditLogStore{}, RetentionConfig{})
+	assert.Equal(t, 365*24*time.Hour, purger.Retention(context.Background(), "tenant-a"))
+}
+
+func TestPurger_StartAndClose(t *testing.T) {
+	tenants := &fakeTenants{ids: []string{"tenant-a"}}
+	store := &fakeAuditLogStore{}
+	purger := NewPurger(tenants, nil, store, RetentionConfig{Interval: time.Hour})
+
+	purger.Start()
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		_, ok := store.purged["tenant-a"]
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	purger.Close()
+	purger.Close()
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySource loads verification keys from one location
+type KeySource interface {
+	// Name describes the source in logs and errors
+	Name() string
+	// LoadKeys returns the keys currently published by the source
+	LoadKeys(ctx context.Context) ([]VerificationKey, error)
+}
+
+// StaticKeySource serves a fixed set of keys, e.g. PEM from an environment variable
+type StaticKeySource struct {
+	name string
+	keys []VerificationKey
+}
+
+// NewStaticKeySource creates a source that always returns keys
+func NewStaticKeySource(name string, keys ...VerificationKey) *StaticKeySource {
+	return &StaticKeySource{name: name, keys: keys}
+}
+
+// NewPEMKeySource parses PEM data, such as the value of an environment variable, into a static source
+func NewPEMKeySource(name, id string, data []byte) (*StaticKeySource, error) {
+	keys, err := ParsePublicKeysPEM(id, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return NewStaticKeySource(name, keys...), nil
+}
+
+// Name implements KeySource
+func (s *StaticKeySource) Name() string { return s.name }
+
+// LoadKeys implements KeySource
+func (s *StaticKeySource) LoadKeys(ctx context.Context) ([]VerificationKey, error) {
+	return s.keys, nil
+}
+
+// FileKeySource loads keys from a PEM file. The key ID is the file name without its extension.
+type FileKeySource struct {
+	path string
+}
+
+// NewFileKeySource creates a source for a PEM file
+func NewFileKeySource(path string) *FileKeySource {
+	return &FileKeySource{path: path}
+}
+
+// Name implements KeySource
+func (s *FileKeySource) Name() string { return "file " + s.path }
+
+// LoadKeys implements KeySource
+func (s *FileKeySource) LoadKeys(ctx context.Context) ([]VerificationKey, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return ParsePublicKeysPEM(keyIDFromFileName(s.path), data)
+}
+
+// DirKeySource loads keys from every PEM file in a directory, such as a mounted
+// Kubernetes secret. Hidden entries (including the secret's ..data links) and files
+// without PEM keys are skipped. The key ID is the file name without its extension.
+type DirKeySource struct {
+	dir string
+}
+
+// NewDirKeySource creates a source for a directory of PEM files
+func NewDirKeySource(dir string) *DirKeySource {
+	return &DirKeySource{dir: dir}
+}
+
+// Name implements KeySource
+func (s *DirKeySource) Name() string { return "directory " + s.dir }
+
+// LoadKeys implements KeySource
+func (s *DirKeySource) LoadKeys(ctx context.Context) ([]VerificationKey, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	var keys []VerificationKey
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		// Secret volumes expose files as symlinks, so stat the target
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+		}
+		fileKeys, err := ParsePublicKeysPEM(keyIDFromFileName(path), data)
+		if errors.Is(err, ErrNoPEMKeys) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	return keys, nil
+}
+
+// keyIDFromFileName returns the base name of path without its extension
+func keyIDFromFileName(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// KeyManager aggregates verification keys from several sources. Reload picks up keys
+// added or removed at the sources, so a new signing key can be published alongside
+// the old one and the old key retired once its tokens have expired.
+type KeyManager struct {
+	sources []KeySource
+
+	mu   sync.RWMutex
+	keys []VerificationKey
+}
+
+var _ KeyProvider = (*KeyManager)(nil)
+
+// NewKeyManager creates a key manager. Call Reload to load the keys.
+func NewKeyManager(sources ...KeySource) *KeyManager {
+	return &KeyManager{sources: sources}
+}
+
+// Reload loads the keys from every source. If any source fails or no keys are found,
+// the previously loaded keys are kept and an error is returned.
+func (m *KeyManager) Reload(ctx context.Context) error {
+	var keys []VerificationKey
+	for _, source := range m.sources {
+		loaded, err := source.LoadKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load keys from %s: %w", source.Name(), err)
+		}
+		keys = append(keys, loaded...)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no verification keys found in %d source(s)", len(m.sources))
+	}
+
+	// Stable order keeps key selection deterministic between reloads
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	m.mu.Lock()
+	m.keys = keys
+	m.mu.Unlock()
+	return nil
+}
+
+// Keys implements KeyProvider
+func (m *KeyManager) Keys() []VerificationKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.keys
+}
+
+// KeyIDs returns the IDs of the loaded keys
+func (m *KeyManager) KeyIDs() []string {
+	keys := m.Keys()
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	return ids
+}
+
+// Start reloads the keys every interval until ctx is cancelled. Failed reloads are
+// logged and the previous keys stay in use.
+func (m *KeyManager) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Reload(ctx); err != nil {
+					slog.WarnContext(ctx, "JWT key reload failed, keeping current keys", "error", err)
+				}
+			}
+		}
+	}()
+}
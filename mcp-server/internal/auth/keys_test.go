@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to generate test RSA key pair
+func generateTestKeyPair(t *testing.T) (*rsa.PrivateKey, string) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicKeyBytes,
+	})
+
+	return privateKey, string(publicKeyPEM)
+}
+
+func generateTestECKeyPair(t *testing.T) (*ecdsa.PrivateKey, string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return privateKey, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}))
+}
+
+// signTestToken signs valid demo claims with method and key, setting the kid header when given
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) string {
+	now := time.Now()
+	token := jwt.NewWithClaims(method, Claims{
+		TenantID: "11111111-1111-1111-1111-111111111111",
+		UserID:   "user-456",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "mcp-server-demo",
+			Audience:  jwt.ClaimStrings{"mcp-server"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestFileAndDirKeySources(t *testing.T) {
+	_, currentPEM := generateTestKeyPair(t)
+	_, previousPEM := generateTestECKeyPair(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "current.pem"), []byte(currentPEM), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "previous.pem"), []byte(previousPEM), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0700))
+
+	keys, err := NewFileKeySource(filepath.Join(dir, "current.pem")).LoadKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "current", keys[0].ID)
+
+	_, err = NewFileKeySource(filepath.Join(dir, "missing.pem")).LoadKeys(context.Background())
+	assert.Error(t, err)
+
+	keys, err = NewDirKeySource(dir).LoadKeys(context.Background())
+	require.NoError(t, err)
+	ids := []string{}
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	assert.ElementsMatch(t, []string{"current", "previous"}, ids)
+}
+
+func TestKeyManager_Reload(t *testing.T) {
+	_, firstPEM := generateTestKeyPair(t)
+	_, secondPEM := generateTestKeyPair(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "signing.pem")
+	require.NoError(t, os.WriteFile(path, []byte(firstPEM), 0600))
+
+	manager := NewKeyManager(NewFileKeySource(path))
+	require.NoError(t, manager.Reload(context.Background()))
+	first := manager.Keys()
+	require.Len(t, first, 1)
+
+	// A rotated key is picked up on reload
+	require.NoError(t, os.WriteFile(path, []byte(secondPEM), 0600))
+	require.NoError(t, manager.Reload(context.Background()))
+	require.Len(t, manager.Keys(), 1)
+	assert.NotEqual(t, first[0].Key, manager.Keys()[0].Key)
+
+	// A failing source keeps the current keys
+	current := manager.Keys()
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, manager.Reload(context.Background()))
+	assert.Equal(t, current, manager.Keys())
+
+	// No keys at all is an error
+	assert.Error(t, NewKeyManager(NewDirKeySource(t.TempDir())).Reload(context.Background()))
+}
+
+func TestValidateToken_MultipleKeys(t *testing.T) {
+	oldKey, oldPEM := generateTestKeyPair(t)
+	newKey, newPEM := generateTestECKeyPair(t)
+	otherKey, _ := generateTestKeyPair(t)
+
+	oldSource, err := NewPEMKeySource("old", "old", []byte(oldPEM))
+	require.NoError(t, err)
+	newSource, err := NewPEMKeySource("new", "new", []byte(newPEM))
+	require.NoError(t, err)
+	manager := NewKeyManager(oldSource, newSource)
+	require.NoError(t, manager.Reload(context.Background()))
+
+	validator, err := NewJWTValidator(Config{Keys: manager, Issuer: "mcp-server-demo", Audience: "mcp-server"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"old RSA key without kid", signTestToken(t, jwt.SigningMethodRS256, oldKey, ""), false},
+		{"new ECDSA key with kid", signTestToken(t, jwt.SigningMethodES256, newKey, "new"), false},
+		{"unknown kid falls back to all keys", signTestToken(t, jwt.SigningMethodRS256, oldKey, "retired"), false},
+		{"RSA-PSS", signTestToken(t, jwt.SigningMethodPS256, oldKey, "old"), false},
+		{"unknown signer", signTestToken(t, jwt.SigningMethodRS256, otherKey, ""), true},
+		{"HMAC rejected", signTestToken(t, jwt.SigningMethodHS256, []byte("secret"), ""), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := validator.ValidateToken(tt.token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "11111111-1111-1111-1111-111111111111", claims.TenantID)
+		})
+	}
+}
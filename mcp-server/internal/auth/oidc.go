@@ -0,0 +1,462 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Well-known paths of OAuth metadata documents
+const (
+	// OpenIDConfigurationPath is where an OpenID provider publishes its discovery document
+	OpenIDConfigurationPath = "/.well-known/openid-configuration"
+	// AuthorizationServerMetadataPath is where an OAuth authorization server publishes its
+	// metadata (RFC 8414)
+	AuthorizationServerMetadataPath = "/.well-known/oauth-authorization-server"
+	// ProtectedResourceMetadataPath is where a resource server publishes its metadata (RFC 9728)
+	ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+)
+
+// DefaultTenantClaim is the claim holding the tenant ID of OIDC tokens
+const DefaultTenantClaim = "tenant_id"
+
+// maxCachedIntrospections bounds the introspection cache; expired entries are dropped
+// when it fills up, and the whole cache if that is not enough
+const maxCachedIntrospections = 10000
+
+// OIDCConfig configures access tokens issued by an OpenID Connect provider, alongside
+// the server's own JWTs
+type OIDCConfig struct {
+	Issuer   string `yaml:"issuer"`   // Provider issuer URL, e.g. https://login.example.com/realms/mcp; empty disables OIDC
+	Resource string `yaml:"resource"` // Canonical URL of the MCP endpoint, e.g. https://mcp.example.com/mcp
+	Audience string `yaml:"audience"` // Expected "aud" of access tokens (default: Resource)
+	// Client credentials for token introspection (RFC 7662). Without them only JWT access
+	// tokens are accepted.
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// IntrospectAll also introspects JWT access tokens, so revoked tokens are refused
+	// before they expire, at the cost of a provider round trip per uncached token
+	IntrospectAll bool `yaml:"introspect_all"`
+	// IntrospectionCacheTTL is how long an introspection result is reused (default 1m)
+	IntrospectionCacheTTL time.Duration `yaml:"introspection_cache_ttl"`
+	// TenantClaim is the claim holding the tenant ID (default tenant_id)
+	TenantClaim string `yaml:"tenant_claim"`
+	// ScopesSupported is advertised in the protected resource metadata (default read, write, admin)
+	ScopesSupported []string `yaml:"scopes_supported"`
+	// JWKSRefresh is how often the provider's signing keys are reloaded (default 15m)
+	JWKSRefresh time.Duration `yaml:"jwks_refresh"`
+	Client      *http.Client  `yaml:"-"`
+}
+
+// Enabled reports whether an OpenID provider is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// Validate checks the configuration of an enabled provider
+func (c OIDCConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	var errs []error
+	if u, err := url.Parse(c.Issuer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("issuer must be an http(s) URL, got %q", c.Issuer))
+	}
+	if u, err := url.Parse(c.Resource); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("resource must be the http(s) URL of the MCP endpoint, got %q", c.Resource))
+	}
+	if (c.ClientID == "") != (c.ClientSecret == "") {
+		errs = append(errs, fmt.Errorf("client_id and client_secret must be set together"))
+	}
+	if c.IntrospectAll && c.ClientID == "" {
+		errs = append(errs, fmt.Errorf("introspect_all needs client_id and client_secret"))
+	}
+	if c.IntrospectionCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("introspection_cache_ttl must not be negative, got %s", c.IntrospectionCacheTTL))
+	}
+	if c.JWKSRefresh < 0 {
+		errs = append(errs, fmt.Errorf("jwks_refresh must not be negative, got %s", c.JWKSRefresh))
+	}
+	return errors.Join(errs...)
+}
+
+// ProviderMetadata is the part of an OpenID provider's discovery document used by the
+// server. The full document is kept in Raw.
+type ProviderMetadata struct {
+	Issuer                string          `json:"issuer"`
+	AuthorizationEndpoint string          `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint         string          `json:"token_endpoint,omitempty"`
+	JWKSURI               string          `json:"jwks_uri,omitempty"`
+	IntrospectionEndpoint string          `json:"introspection_endpoint,omitempty"`
+	RegistrationEndpoint  string          `json:"registration_endpoint,omitempty"`
+	ScopesSupported       []string        `json:"scopes_supported,omitempty"`
+	Raw                   json.RawMessage `json:"-"`
+}
+
+// DiscoverProvider fetches the discovery document of issuer from
+// {issuer}/.well-known/openid-configuration, or from the RFC 8414
+// {issuer}/.well-known/oauth-authorization-server when the provider has none. The
+// document must name the same issuer.
+func DiscoverProvider(ctx context.Context, client *http.Client, issuer string) (*ProviderMetadata, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	issuer = strings.TrimRight(issuer, "/")
+
+	var errs []error
+	for _, path := range []string{OpenIDConfigurationPath, AuthorizationServerMetadataPath} {
+		metadata, err := fetchProviderMetadata(ctx, client, issuer+path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if strings.TrimRight(metadata.Issuer, "/") != issuer {
+			return nil, fmt.Errorf("discovery document names issuer %q, expected %q", metadata.Issuer, issuer)
+		}
+		return metadata, nil
+	}
+	return nil, fmt.Errorf("OIDC discovery failed: %w", errors.Join(errs...))
+}
+
+// fetchProviderMetadata reads one metadata document
+func fetchProviderMetadata(ctx context.Context, client *http.Client, location string) (*ProviderMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", location, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", location, resp.StatusCode)
+	}
+
+	var metadata ProviderMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", location, err)
+	}
+	metadata.Raw = body
+	return &metadata, nil
+}
+
+// ProtectedResourceMetadata describes the MCP endpoint to OAuth clients (RFC 9728), so
+// they can find the authorization server to get a token from
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+}
+
+// ResourceMetadataPath returns the path the metadata of a resource is published at: the
+// well-known path followed by the resource's path (RFC 9728), e.g.
+// /.well-known/oauth-protected-resource/mcp for https://mcp.example.com/mcp
+func ResourceMetadataPath(resource string) string {
+	u, err := url.Parse(resource)
+	if err != nil {
+		return ProtectedResourceMetadataPath
+	}
+	return ProtectedResourceMetadataPath + strings.TrimRight(u.Path, "/")
+}
+
+// ResourceMetadataURL returns the URL of a resource's metadata on the resource's host
+func ResourceMetadataURL(resource string) string {
+	u, err := url.Parse(resource)
+	if err != nil {
+		return ""
+	}
+	u.Path = ResourceMetadataPath(resource)
+	u.RawPath, u.RawQuery, u.Fragment = "", "", ""
+	return u.String()
+}
+
+// OIDCVerifier accepts access tokens issued by an OpenID provider: JWTs are verified
+// against the provider's published keys and opaque tokens are introspected
+type OIDCVerifier struct {
+	config   OIDCConfig
+	metadata *ProviderMetadata
+	keys     *KeyManager // nil when the provider publishes no jwks_uri
+	jwt      *JWTValidator
+
+	mu             sync.Mutex
+	introspections map[string]cachedIntrospection
+}
+
+// cachedIntrospection is the outcome of introspecting a token and when to ask again
+type cachedIntrospection struct {
+	claims    *Claims
+	err       error
+	expiresAt time.Time
+}
+
+// NewOIDCVerifier discovers the provider and loads its signing keys
+func NewOIDCVerifier(ctx context.Context, cfg OIDCConfig) (*OIDCVerifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	if cfg.Audience == "" {
+		cfg.Audience = cfg.Resource
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = DefaultTenantClaim
+	}
+	if len(cfg.ScopesSupported) == 0 {
+		cfg.ScopesSupported = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+	}
+	if cfg.IntrospectionCacheTTL <= 0 {
+		cfg.IntrospectionCacheTTL = time.Minute
+	}
+	if cfg.JWKSRefresh <= 0 {
+		cfg.JWKSRefresh = 15 * time.Minute
+	}
+
+	metadata, err := DiscoverProvider(ctx, cfg.Client, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	v := &OIDCVerifier{
+		config:         cfg,
+		metadata:       metadata,
+		introspections: make(map[string]cachedIntrospection),
+	}
+
+	if metadata.JWKSURI != "" {
+		v.keys = NewKeyManager(NewJWKSKeySource(metadata.JWKSURI, cfg.Client))
+		if err := v.keys.Reload(ctx); err != nil {
+			return nil, err
+		}
+		v.jwt, err = NewJWTValidator(Config{Keys: v.keys, Issuer: cfg.Issuer, Audience: cfg.Audience})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v.keys == nil && !v.canIntrospect() {
+		return nil, fmt.Errorf("provider %s publishes no jwks_uri and token introspection is not configured", cfg.Issuer)
+	}
+	return v, nil
+}
+
+// Start reloads the provider's signing keys every JWKSRefresh until ctx is cancelled
+func (v *OIDCVerifier) Start(ctx context.Context) {
+	if v.keys != nil {
+		v.keys.Start(ctx, v.config.JWKSRefresh)
+	}
+}
+
+// Metadata returns the provider's discovery document
+func (v *OIDCVerifier) Metadata() *ProviderMetadata {
+	return v.metadata
+}
+
+// ResourceMetadata returns the protected resource metadata of the MCP endpoint
+func (v *OIDCVerifier) ResourceMetadata() ProtectedResourceMetadata {
+	return ProtectedResourceMetadata{
+		Resource:               v.config.Resource,
+		AuthorizationServers:   []string{v.config.Issuer},
+		ScopesSupported:        v.config.ScopesSupported,
+		BearerMethodsSupported: []string{"header"},
+	}
+}
+
+// Handles reports whether a token is the provider's: opaque tokens, and JWTs naming the
+// provider as their issuer. The server's own JWTs are left to its JWTValidator.
+func (v *OIDCVerifier) Handles(token string) bool {
+	token = strings.TrimPrefix(token, "Bearer ")
+	if !isJWT(token) {
+		return true
+	}
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return false
+	}
+	return strings.TrimRight(claims.Issuer, "/") == v.config.Issuer
+}
+
+// VerifyToken validates an access token of the provider and returns its claims. The
+// tenant ID is read from the configured tenant claim and the scopes from "scope" (space
+// separated), "scp" or "scopes".
+func (v *OIDCVerifier) VerifyToken(ctx context.Context, token string) (*Claims, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+
+	if isJWT(token) && v.jwt != nil {
+		claims, err := v.verifyJWT(token)
+		if err != nil || !v.config.IntrospectAll {
+			return claims, err
+		}
+	}
+	if !v.canIntrospect() {
+		return nil, fmt.Errorf("opaque access tokens need token introspection, which is not configured")
+	}
+	return v.introspect(ctx, token)
+}
+
+// isJWT reports whether a token has the three segments of a signed JWT
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// canIntrospect reports whether tokens can be introspected at the provider
+func (v *OIDCVerifier) canIntrospect() bool {
+	return v.metadata.IntrospectionEndpoint != "" && v.config.ClientID != ""
+}
+
+// verifyJWT checks the signature, issuer, audience and expiry of a provider JWT
+func (v *OIDCVerifier) verifyJWT(token string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, mapClaims, v.jwt.VerificationKeys,
+		jwt.WithIssuer(v.config.Issuer),
+		jwt.WithAudience(v.config.Audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	return v.claimsFrom(mapClaims)
+}
+
+// introspect asks the provider whether a token is active (RFC 7662), reusing recent answers
+func (v *OIDCVerifier) introspect(ctx context.Context, token string) (*Claims, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	v.mu.Lock()
+	cached, ok := v.introspections[key]
+	v.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.claims, cached.err
+	}
+
+	claims, err := v.requestIntrospection(ctx, token)
+	if err != nil && ctx.Err() != nil {
+		return nil, err // not the token's fault; do not cache
+	}
+
+	expiresAt := now.Add(v.config.IntrospectionCacheTTL)
+	if claims != nil && claims.ExpiresAt != nil && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	v.mu.Lock()
+	if len(v.introspections) >= maxCachedIntrospections {
+		for k, entry := range v.introspections {
+			if !now.Before(entry.expiresAt) {
+				delete(v.introspections, k)
+			}
+		}
+		if len(v.introspections) >= maxCachedIntrospections {
+			v.introspections = make(map[string]cachedIntrospection)
+		}
+	}
+	v.introspections[key] = cachedIntrospection{claims: claims, err: err, expiresAt: expiresAt}
+	v.mu.Unlock()
+	return claims, err
+}
+
+// requestIntrospection posts a token to the provider's introspection endpoint
+func (v *OIDCVerifier) requestIntrospection(ctx context.Context, token string) (*Claims, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.metadata.IntrospectionEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(v.config.ClientID), url.QueryEscape(v.config.ClientSecret))
+
+	resp, err := v.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read introspection response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	if active, _ := response["active"].(bool); !active {
+		return nil, fmt.Errorf("token is not active")
+	}
+
+	// Introspection responses carry the same claims as JWTs; those present must match
+	claims := jwt.MapClaims(response)
+	if iss, _ := claims.GetIssuer(); iss != "" && strings.TrimRight(iss, "/") != v.config.Issuer {
+		return nil, fmt.Errorf("invalid issuer: expected %s, got %s", v.config.Issuer, iss)
+	}
+	if aud, _ := claims.GetAudience(); len(aud) > 0 && !slices.Contains(aud, v.config.Audience) {
+		return nil, fmt.Errorf("invalid audience")
+	}
+	if exp, _ := claims.GetExpirationTime(); exp != nil && exp.Before(time.Now()) {
+		return nil, fmt.Errorf("token expired")
+	}
+	return v.claimsFrom(claims)
+}
+
+// claimsFrom maps provider claims onto the server's claims
+func (v *OIDCVerifier) claimsFrom(m jwt.MapClaims) (*Claims, error) {
+	claims := &Claims{}
+	claims.TenantID, _ = m[v.config.TenantClaim].(string)
+	if claims.TenantID == "" {
+		return nil, fmt.Errorf("%s claim is required", v.config.TenantClaim)
+	}
+	if err := ValidateTenantID(claims.TenantID); err != nil {
+		return nil, err
+	}
+
+	claims.Subject, _ = m.GetSubject()
+	claims.UserID = claims.Subject
+	if userID, ok := m["user_id"].(string); ok && userID != "" {
+		claims.UserID = userID
+	}
+	claims.Email, _ = m["email"].(string)
+	claims.Role, _ = m["role"].(string)
+	claims.Issuer, _ = m.GetIssuer()
+	claims.Audience, _ = m.GetAudience()
+	claims.ExpiresAt, _ = m.GetExpirationTime()
+
+	for _, name := range []string{"scope", "scp", "scopes"} {
+		switch value := m[name].(type) {
+		case string:
+			claims.Scopes = append(claims.Scopes, strings.Fields(value)...)
+		case []interface{}:
+			for _, scope := range value {
+				if s, ok := scope.(string); ok {
+					claims.Scopes = append(claims.Scopes, s)
+				}
+			}
+		}
+	}
+	return claims, nil
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oidcTestTenant = "11111111-1111-1111-1111-111111111111"
+
+// testProvider is an OpenID provider serving discovery, JWKS and introspection
+type testProvider struct {
+	server         *httptest.Server
+	key            *rsa.PrivateKey
+	active         map[string]map[string]interface{} // opaque token -> introspection response
+	introspections atomic.Int32
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	key, _ := generateTestKeyPair(t)
+	p := &testProvider{key: key, active: map[string]map[string]interface{}{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(OpenIDConfigurationPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+			"introspection_endpoint": p.server.URL + "/introspect",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			{
+				"kty": "RSA",
+				"kid": "sig-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		}})
+	})
+	mux.HandleFunc("/introspect", func(w http.ResponseWriter, r *http.Request) {
+		p.introspections.Add(1)
+		if id, secret, ok := r.BasicAuth(); !ok || id != "mcp-server" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := p.active[r.PostFormValue("token")]
+		if !ok {
+			response = map[string]interface{}{"active": false}
+		}
+		json.NewEncoder(w).Encode(response)
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// sign signs claims with the provider's key
+func (p *testProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "sig-1"
+	signed, err := token.SignedString(p.key)
+	require.NoError(t, err)
+	return signed
+}
+
+// claims returns valid access token claims of the provider
+func (p *testProvider) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":       p.server.URL,
+		"aud":       "https://mcp.example.com/mcp",
+		"sub":       "user-456",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": oidcTestTenant,
+		"scope":     "read write",
+	}
+}
+
+func newTestVerifier(t *testing.T, p *testProvider, cfg OIDCConfig) *OIDCVerifier {
+	t.Helper()
+	cfg.Issuer = p.server.URL
+	cfg.Resource = "https://mcp.example.com/mcp"
+	verifier, err := NewOIDCVerifier(context.Background(), cfg)
+	require.NoError(t, err)
+	return verifier
+}
+
+func TestOIDCConfig_Validate(t *testing.T) {
+	assert.NoError(t, OIDCConfig{}.Validate(), "disabled")
+
+	valid := OIDCConfig{Issuer: "https://idp.example.com", Resource: "https://mcp.example.com/mcp"}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name    string
+		mutate  func(c *OIDCConfig)
+		wantErr string
+	}{
+		{"issuer", func(c *OIDCConfig) { c.Issuer = "idp.example.com" }, "issuer"},
+		{"resource", func(c *OIDCConfig) { c.Resource = "" }, "resource"},
+		{"client secret", func(c *OIDCConfig) { c.ClientID = "mcp-server" }, "client_id and client_secret"},
+		{"introspect all", func(c *OIDCConfig) { c.IntrospectAll = true }, "introspect_all"},
+		{"cache ttl", func(c *OIDCConfig) { c.IntrospectionCacheTTL = -time.Second }, "introspection_cache_ttl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.mutate(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDiscoverProvider(t *testing.T) {
+	p := newTestProvider(t)
+
+	metadata, err := DiscoverProvider(context.Background(), nil, p.server.URL+"/")
+	require.NoError(t, err)
+	assert.Equal(t, p.server.URL+"/jwks", metadata.JWKSURI)
+	assert.Equal(t, p.server.URL+"/introspect", metadata.IntrospectionEndpoint)
+	assert.Contains(t, string(metadata.Raw), "authorization_endpoint")
+
+	_, err = DiscoverProvider(context.Background(), nil, p.server.URL+"/realms/other")
+	assert.Error(t, err)
+}
+
+func TestOIDCVerifier_JWT(t *testing.T) {
+	p := newTestProvider(t)
+	verifier := newTestVerifier(t, p, OIDCConfig{})
+
+	claims := p.claims()
+	claims["scp"] = []interface{}{"admin"}
+	token := p.sign(t, claims)
+	assert.True(t, verifier.Handles("Bearer "+token))
+
+	got, err := verifier.VerifyToken(context.Background(), "Bearer "+token)
+	require.NoError(t, err)
+	assert.Equal(t, oidcTestTenant, got.TenantID)
+	assert.Equal(t, "user-456", got.UserID)
+	assert.ElementsMatch(t, []string{"read", "write", "admin"}, got.Scopes)
+	assert.Zero(t, p.introspections.Load(), "JWTs are verified locally")
+
+	tests := []struct {
+		name   string
+		mutate func(c jwt.MapClaims)
+	}{
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "https://other.example.com" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{"no tenant", func(c jwt.MapClaims) { delete(c, "tenant_id") }},
+		{"malformed tenant", func(c jwt.MapClaims) { c["tenant_id"] = "acme" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := p.claims()
+			tt.mutate(claims)
+			_, err := verifier.VerifyToken(context.Background(), p.sign(t, claims))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDCVerifier_Handles(t *testing.T) {
+	p := newTestProvider(t)
+	verifier := newTestVerifier(t, p, OIDCConfig{})
+
+	ownKey, _ := generateTestKeyPair(t)
+	own := signTestToken(t, jwt.SigningMethodRS256, ownKey, "")
+	assert.False(t, verifier.Handles("Bearer "+own), "the server's own JWTs")
+	assert.True(t, verifier.Handles("Bearer opaque-token"))
+	assert.True(t, verifier.Handles(p.sign(t, p.claims())))
+}
+
+func TestOIDCVerifier_Introspection(t *testing.T) {
+	p := newTestProvider(t)
+	p.active["opaque-token"] = map[string]interface{}{
+		"active":    true,
+		"iss":       p.server.URL,
+		"sub":       "user-789",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": oidcTestTenant,
+		"scope":     "read",
+	}
+	p.active["other-audience"] = map[string]interface{}{
+		"active":    true,
+		"aud":       "https://other.example.com",
+		"tenant_id": oidcTestTenant,
+	}
+	verifier := newTestVerifier(t, p, OIDCConfig{ClientID: "mcp-server", ClientSecret: "s3cret"})
+	ctx := context.Background()
+
+	claims, err := verifier.VerifyToken(ctx, "Bearer opaque-token")
+	require.NoError(t, err)
+	assert.Equal(t, "user-789", claims.UserID)
+	assert.Equal(t, []string{"read"}, claims.Scopes)
+
+	_, err = verifier.VerifyToken(ctx, "opaque-token")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), p.introspections.Load(), "results are cached")
+
+	_, err = verifier.VerifyToken(ctx, "revoked-token")
+	assert.ErrorContains(t, err, "not active")
+	_, err = verifier.VerifyToken(ctx, "other-audience")
+	assert.ErrorContains(t, err, "audience")
+}
+
+func TestOIDCVerifier_IntrospectAll(t *testing.T) {
+	p := newTestProvider(t)
+	verifier := newTestVerifier(t, p, OIDCConfig{ClientID: "mcp-server", ClientSecret: "s3cret", IntrospectAll: true})
+
+	// A validly signed JWT the provider no longer reports as active
+	_, err := verifier.VerifyToken(context.Background(), p.sign(t, p.claims()))
+	assert.ErrorContains(t, err, "not active")
+	assert.Equal(t, int32(1), p.introspections.Load())
+}
+
+func TestOIDCVerifier_OpaqueTokenWithoutIntrospection(t *testing.T) {
+	p := newTestProvider(t)
+	verifier := newTestVerifier(t, p, OIDCConfig{})
+
+	_, err := verifier.VerifyToken(context.Background(), "opaque-token")
+	assert.ErrorContains(t, err, "introspection")
+}
+
+func TestResourceMetadataURL(t *testing.T) {
+	assert.Equal(t, "https://mcp.example.com/.well-known/oauth-protected-resource/mcp",
+		ResourceMetadataURL("https://mcp.example.com/mcp"))
+	assert.Equal(t, "https://mcp.example.com/.well-known/oauth-protected-resource",
+		ResourceMetadataURL("https://mcp.example.com/"))
+	assert.Equal(t, ProtectedResourceMetadataPath+"/tenants/mcp", ResourceMetadataPath("https://mcp.example.com/tenants/mcp?x=1"))
+
+	p := newTestProvider(t)
+	metadata := newTestVerifier(t, p, OIDCConfig{}).ResourceMetadata()
+	assert.Equal(t, "https://mcp.example.com/mcp", metadata.Resource)
+	assert.Equal(t, []string{p.server.URL}, metadata.AuthorizationServers)
+	assert.Equal(t, []string{ScopeRead, ScopeWrite, ScopeAdmin}, metadata.ScopesSupported)
+	assert.Equal(t, []string{"header"}, metadata.BearerMethodsSupported)
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scopes granted by the built-in roles
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// ScopeProvision lets a platform operator onboard new tenants. No tenant role grants
+// it, so it can only come from a token's own scopes.
+const ScopeProvision = "provision"
+
+// ScopeOperator lets a platform operator change server-wide settings such as safe mode.
+// Like ScopeProvision, no tenant role grants it.
+const ScopeOperator = "operator"
+
+// Role is a named bundle of scopes assigned to a user within a tenant
+type Role string
+
+// Built-in roles, from least to most privileged
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// roleScopes maps each role to the scopes it grants
+var roleScopes = map[Role][]string{
+	RoleViewer: {ScopeRead},
+	RoleEditor: {ScopeRead, ScopeWrite},
+	RoleAdmin:  {ScopeRead, ScopeWrite, ScopeAdmin},
+}
+
+// ErrRoleNotAssigned is returned when a user has no role assignment in a tenant
+var ErrRoleNotAssigned = errors.New("role not assigned")
+
+// Roles returns the built-in roles from least to most privileged
+func Roles() []Role {
+	return []Role{RoleViewer, RoleEditor, RoleAdmin}
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleScopes[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (expected viewer, editor or admin)", name)
+	}
+	return role, nil
+}
+
+// Scopes returns the scopes granted by the role, or nil for an unknown role
+func (r Role) Scopes() []string {
+	return append([]string(nil), roleScopes[r]...)
+}
+
+// RoleAssignment grants a role to a user within a tenant
+type RoleAssignment struct {
+	TenantID   string    `json:"tenant_id"`
+	UserID     string    `json:"user_id"`
+	Role       Role      `json:"role"`
+	AssignedBy string    `json:"assigned_by,omitempty"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// RoleStore persists tenant role assignments
+type RoleStore interface {
+	// GetRoleAssignment returns a user's role assignment, or ErrRoleNotAssigned
+	GetRoleAssignment(ctx context.Context, tenantID, userID string) (*RoleAssignment, error)
+
+	// ListRoleAssignments returns a tenant's role assignments ordered by user ID
+	ListRoleAssignments(ctx context.Context, tenantID string) ([]RoleAssignment, error)
+
+	// AssignRole creates or replaces a user's role assignment
+	AssignRole(ctx context.Context, assignment RoleAssignment) error
+
+	// RevokeRole removes a user's role assignment, or returns ErrRoleNotAssigned
+	RevokeRole(ctx context.Context, tenantID, userID string) error
+}
+
+// ExpandScopes returns the claims' scopes together with the scopes of the claimed role
+// and of the role assigned to the user in the tenant, without duplicates. An unknown
+// claimed role grants nothing.
+func ExpandScopes(claims *Claims, assigned Role) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	add := func(values []string) {
+		for _, scope := range values {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	add(claims.Scopes)
+	add(Role(claims.Role).Scopes())
+	add(assigned.Scopes())
+	return scopes
+}
+
+// RoleResolver expands authenticated claims with the scopes of the user's roles.
+// Assignments are cached for a short TTL so a role lookup isn't needed on every request.
+type RoleResolver struct {
+	store RoleStore
+	ttl   time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedRole
+}
+
+type cachedRole struct {
+	role      Role
+	expiresAt time.Time
+}
+
+// NewRoleResolver creates a role resolver. A zero ttl caches assignments for one minute.
+func NewRoleResolver(store RoleStore, ttl time.Duration) *RoleResolver {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return &RoleResolver{
+		store: store,
+		ttl:   ttl,
+		cache: make(map[string]cachedRole),
+	}
+}
+
+// Resolve returns a copy of claims whose scopes include those granted by the claimed
+// role and by the user's assigned role
+func (r *RoleResolver) Resolve(ctx context.Context, claims *Claims) (*Claims, error) {
+	assigned, err := r.assignedRole(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *claims
+	resolved.Scopes = ExpandScopes(claims, assigned)
+	return &resolved, nil
+}
+
+// Invalidate drops a cached assignment so the next request sees a changed role
+func (r *RoleResolver) Invalidate(tenantID, userID string) {
+	r.mu.Lock()
+	delete(r.cache, tenantID+"/"+userID)
+	r.mu.Unlock()
+}
+
+// assignedRole returns the user's assigned role, or "" if there is none
+func (r *RoleResolver) assignedRole(ctx context.Context, tenantID, userID string) (Role, error) {
+	if tenantID == "" || userID == "" {
+		return "", nil
+	}
+
+	key := tenantID + "/" + userID
+	r.mu.RLock()
+	cached, ok := r.cache[key]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.role, nil
+	}
+
+	var role Role
+	assignment, err := r.store.GetRoleAssignment(ctx, tenantID, userID)
+	switch {
+	case err == nil:
+		role = assignment.Role
+	case !errors.Is(err, ErrRoleNotAssigned):
+		return "", fmt.Errorf("failed to look up role: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cache[key] = cachedRole{role: role, expiresAt: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return role, nil
+}
+
+// MemoryRoleStore keeps role assignments in memory, for the local storage drivers and tests
+type MemoryRoleStore struct {
+	mu          sync.RWMutex
+	assignments map[string]map[string]RoleAssignment // tenant ID -> user ID -> assignment
+}
+
+// Ensure MemoryRoleStore implements RoleStore
+var _ RoleStore = (*MemoryRoleStore)(nil)
+
+// NewMemoryRoleStore creates an empty in-memory role store
+func NewMemoryRoleStore() *MemoryRoleStore {
+	return &MemoryRoleStore{assignments: make(map[string]map[string]RoleAssignment)}
+}
+
+// GetRoleAssignment implements RoleStore
+func (s *MemoryRoleStore) GetRoleAssignment(ctx context.Context, tenantID, userID string) (*RoleAssignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	assignment, ok := s.assignments[tenantID][userID]
+	if !ok {
+		return nil, ErrRoleNotAssigned
+	}
+	return &assignment, nil
+}
+
+// ListRoleAssignments implements RoleStore
+func (s *MemoryRoleStore) ListRoleAssignments(ctx context.Context, tenantID string) ([]RoleAssignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	assignments := make([]RoleAssignment, 0, len(s.assignments[tenantID]))
+	for _, assignment := range s.assignments[tenantID] {
+		assignments = append(assignments, assignment)
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].UserID < assignments[j].UserID })
+	return assignments, nil
+}
+
+// AssignRole implements RoleStore
+func (s *MemoryRoleStore) AssignRole(ctx context.Context, assignment RoleAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if assignment.AssignedAt.IsZero() {
+		assignment.AssignedAt = time.Now()
+	}
+	if s.assignments[assignment.TenantID] == nil {
+		s.assignments[assignment.TenantID] = make(map[string]RoleAssignment)
+	}
+	s.assignments[assignment.TenantID][assignment.UserID] = assignment
+	return nil
+}
+
+// RevokeRole implements RoleStore
+func (s *MemoryRoleStore) RevokeRole(ctx context.Context, tenantID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.assignments[tenantID][userID]; !ok {
+		return ErrRoleNotAssigned
+	}
+	delete(s.assignments[tenantID], userID)
+	return nil
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	for _, role := range Roles() {
+		parsed, err := ParseRole(string(role))
+		require.NoError(t, err)
+		assert.Equal(t, role, parsed)
+	}
+
+	_, err := ParseRole("owner")
+	assert.ErrorContains(t, err, `unknown role "owner"`)
+}
+
+func TestExpandScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   Claims
+		assigned Role
+		want     []string
+	}{
+		{"token scopes only", Claims{Scopes: []string{"read"}}, "", []string{"read"}},
+		{"claimed role", Claims{Role: "editor"}, "", []string{"read", "write"}},
+		{"assigned role", Claims{Scopes: []string{"read"}}, RoleAdmin, []string{"read", "write", "admin"}},
+		{"unknown claimed role grants nothing", Claims{Role: "owner"}, "", nil},
+		{"custom scopes are kept", Claims{Scopes: []string{"reports"}, Role: "viewer"}, "", []string{"reports", "read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExpandScopes(&tt.claims, tt.assigned))
+		})
+	}
+}
+
+// countingRoleStore counts lookups and can fail them
+type countingRoleStore struct {
+	*MemoryRoleStore
+	lookups int
+	err     error
+}
+
+func (s *countingRoleStore) GetRoleAssignment(ctx context.Context, tenantID, userID string) (*RoleAssignment, error) {
+	s.lookups++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MemoryRoleStore.GetRoleAssignment(ctx, tenantID, userID)
+}
+
+func TestRoleResolver(t *testing.T) {
+	ctx := context.Background()
+	store := &countingRoleStore{MemoryRoleStore: NewMemoryRoleStore()}
+	require.NoError(t, store.AssignRole(ctx, RoleAssignment{TenantID: "tenant-123", UserID: "alice", Role: RoleEditor}))
+
+	resolver := NewRoleResolver(store, time.Hour)
+	claims := &Claims{TenantID: "tenant-123", UserID: "alice", Scopes: []string{"read"}}
+
+	resolved, err := resolver.Resolve(ctx, claims)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "write"}, resolved.Scopes)
+	assert.Equal(t, []string{"read"}, claims.Scopes, "input claims are not modified")
+
+	// Assignments are cached until invalidated
+	require.NoError(t, store.AssignRole(ctx, RoleAssignment{TenantID: "tenant-123", UserID: "alice", Role: RoleAdmin}))
+	resolved, err = resolver.Resolve(ctx, claims)
+	require.NoError(t, err)
+	assert.NotContains(t, resolved.Scopes, ScopeAdmin)
+	assert.Equal(t, 1, store.lookups)
+
+	resolver.Invalidate("tenant-123", "alice")
+	resolved, err = resolver.Resolve(ctx, claims)
+	require.NoError(t, err)
+	assert.Contains(t, resolved.Scopes, ScopeAdmin)
+	assert.Equal(t, 2, store.lookups)
+
+	// Lookup failures are reported rather than treated as "no role"
+	store.err = errors.New("database unavailable")
+	resolver.Invalidate("tenant-123", "alice")
+	_, err = resolver.Resolve(ctx, claims)
+	assert.ErrorContains(t, err, "database unavailable")
+}
+
+func TestMemoryRoleStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRoleStore()
+
+	require.NoError(t, store.AssignRole(ctx, RoleAssignment{TenantID: "tenant-123", UserID: "bob", Role: RoleViewer}))
+	require.NoError(t, store.AssignRole(ctx, RoleAssignment{TenantID: "tenant-123", UserID: "alice", Role: RoleAdmin}))
+	require.NoError(t, store.AssignRole(ctx, RoleAssignment{TenantID: "tenant-456", UserID: "carol", Role: RoleEditor}))
+
+	assignments, err := store.ListRoleAssignments(ctx, "tenant-123")
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, "alice", assignments[0].UserID)
+	assert.False(t, assignments[0].AssignedAt.IsZero())
+
+	require.NoError(t, store.RevokeRole(ctx, "tenant-123", "bob"))
+	assert.ErrorIs(t, store.RevokeRole(ctx, "tenant-123", "bob"), ErrRoleNotAssigned)
+	_, err = store.GetRoleAssignment(ctx, "tenant-123", "carol")
+	assert.ErrorIs(t, err, ErrRoleNotAssigned)
+}
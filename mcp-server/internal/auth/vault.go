@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures a HashiCorp Vault key source
+type VaultConfig struct {
+	Addr      string       `yaml:"addr"`      // Vault address, e.g. https://vault.example.com:8200
+	Token     string       `yaml:"token"`     // Vault token with read access to Path
+	Namespace string       `yaml:"namespace"` // Optional Vault Enterprise namespace
+	Path      string       `yaml:"path"`      // Secret path, e.g. secret/data/mcp/jwt-keys for KV v2
+	Client    *http.Client `yaml:"-"`
+}
+
+// VaultKeySource loads keys from a Vault KV secret (version 1 or 2). Each field of
+// the secret holding PEM data becomes a key whose ID is the field name; other
+// fields are ignored.
+type VaultKeySource struct {
+	cfg VaultConfig
+}
+
+// NewVaultKeySource creates a Vault key source
+func NewVaultKeySource(cfg VaultConfig) (*VaultKeySource, error) {
+	if cfg.Addr == "" || cfg.Path == "" {
+		return nil, fmt.Errorf("vault address and secret path are required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("vault token is required")
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.Addr = strings.TrimRight(cfg.Addr, "/")
+	cfg.Path = strings.Trim(cfg.Path, "/")
+	return &VaultKeySource{cfg: cfg}, nil
+}
+
+// Name implements KeySource
+func (s *VaultKeySource) Name() string { return "vault " + s.cfg.Path }
+
+// LoadKeys implements KeySource
+func (s *VaultKeySource) LoadKeys(ctx context.Context) ([]VerificationKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.Addr+"/v1/"+s.cfg.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", s.cfg.Token)
+	if s.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.cfg.Namespace)
+	}
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV v2 nests the secret's fields under data.data next to data.metadata
+	fields := secret.Data
+	if nested, ok := fields["data"].(map[string]interface{}); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = nested
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var keys []VerificationKey
+	for _, name := range names {
+		value, ok := fields[name].(string)
+		if !ok {
+			continue
+		}
+		fieldKeys, err := ParsePublicKeysPEM(name, []byte(value))
+		if errors.Is(err, ErrNoPEMKeys) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fieldKeys...)
+	}
+	return keys, nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVault(t *testing.T, path string, body map[string]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/"+path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultKeySource(t *testing.T) {
+	_, currentPEM := generateTestKeyPair(t)
+	_, previousPEM := generateTestECKeyPair(t)
+	fields := map[string]interface{}{
+		"current":  currentPEM,
+		"previous": previousPEM,
+		"comment":  "rotated 2024-06-01",
+		"version":  2,
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body map[string]interface{}
+	}{
+		{
+			name: "KV v2",
+			path: "secret/data/mcp/jwt-keys",
+			body: map[string]interface{}{"data": map[string]interface{}{
+				"data":     fields,
+				"metadata": map[string]interface{}{"version": 3},
+			}},
+		},
+		{
+			name: "KV v1",
+			path: "kv/mcp/jwt-keys",
+			body: map[string]interface{}{"data": fields},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := newTestVault(t, tt.path, tt.body)
+			source, err := NewVaultKeySource(VaultConfig{Addr: vault.URL + "/", Token: "test-token", Path: "/" + tt.path})
+			require.NoError(t, err)
+
+			keys, err := source.LoadKeys(context.Background())
+			require.NoError(t, err)
+			require.Len(t, keys, 2)
+			assert.Equal(t, "current", keys[0].ID)
+			assert.Equal(t, "previous", keys[1].ID)
+		})
+	}
+}
+
+func TestVaultKeySource_Errors(t *testing.T) {
+	vault := newTestVault(t, "secret/data/jwt", map[string]interface{}{
+		"data": map[string]interface{}{"data": map[string]interface{}{"bad": "-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n"}, "metadata": map[string]interface{}{}},
+	})
+
+	_, err := NewVaultKeySource(VaultConfig{Addr: vault.URL, Path: "secret/data/jwt"})
+	assert.ErrorContains(t, err, "token is required")
+
+	source, err := NewVaultKeySource(VaultConfig{Addr: vault.URL, Token: "wrong", Path: "secret/data/jwt"})
+	require.NoError(t, err)
+	_, err = source.LoadKeys(context.Background())
+	assert.ErrorContains(t, err, "status 403")
+
+	source, err = NewVaultKeySource(VaultConfig{Addr: vault.URL, Token: "test-token", Path: "secret/data/jwt"})
+	require.NoError(t, err)
+	_, err = source.LoadKeys(context.Background())
+	assert.ErrorContains(t, err, `key "bad"`)
+}
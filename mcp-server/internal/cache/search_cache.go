@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "mcp:search"
+
+// Config holds search cache configuration
+type Config struct {
+	TTL time.Duration // How long cached results are kept (default 5m)
+	// Regions, if set, scopes cache entries to the tenant's data region so a
+	// tenant moved between regions is never served results from the old one
+	Regions database.RegionResolver
+}
+
+// SearchCache wraps a storage.Store with a tenant-scoped Redis cache for search results.
+// Cache entries are versioned by a per-tenant generation counter, so a document change
+// invalidates every cached search for that tenant with a single INCR.
+type SearchCache struct {
+	storage.Store
+	redis   *redis.Client
+	ttl     time.Duration
+	regions database.RegionResolver
+	metrics *observability.Metrics
+}
+
+// NewSearchCache creates a new search cache around the given store
+func NewSearchCache(store storage.Store, redisClient *redis.Client, cfg Config, metrics *observability.Metrics) *SearchCache {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+
+	return &SearchCache{
+		Store:   store,
+		redis:   redisClient,
+		ttl:     cfg.TTL,
+		regions: cfg.Regions,
+		metrics: metrics,
+	}
+}
+
+// SearchDocuments returns cached text search results or queries the underlying store
+func (c *SearchCache) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	params := struct {
+		Collection string                 `json:"c"`
+		Query      string                 `json:"q"`
+		Limit      int                    `json:"l"`
+		Filter     storage.MetadataFilter `json:"f,omitempty"`
+	}{storage.CollectionOrDefault(collection), normalizeQuery(query), limit, filter}
+
+	var documents []*storage.Document
+	key := c.key(ctx, tenantID, "search_documents", params)
+	if c.get(ctx, key, "search_documents", &documents) {
+		return documents, nil
+	}
+
+	documents, err := c.Store.SearchDocuments(ctx, tenantID, collection, query, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(ctx, key, documents)
+	return documents, nil
+}
+
+// HybridSearch returns cached hybrid search results or queries the underlying store
+func (c *SearchCache) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	return c.hybrid(ctx, tenantID, "hybrid_search", params, c.Store.HybridSearch)
+}
+
+// SimpleHybridSearch returns cached weighted hybrid search results or queries the underlying store
+func (c *SearchCache) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	return c.hybrid(ctx, tenantID, "simple_hybrid_search", params, c.Store.SimpleHybridSearch)
+}
+
+// SimilarDocuments returns cached similar documents or queries the underlying store
+func (c *SearchCache) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	params := struct {
+		DocID  string                 `json:"d"`
+		Limit  int                    `json:"l"`
+		Filter storage.MetadataFilter `json:"f,omitempty"`
+	}{docID, limit, filter}
+
+	var results []storage.ScoredDocument
+	key := c.key(ctx, tenantID, "similar_documents", params)
+	if c.get(ctx, key, "similar_documents", &results) {
+		return results, nil
+	}
+
+	results, err := c.Store.SimilarDocuments(ctx, tenantID, docID, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(ctx, key, results)
+	return results, nil
+}
+
+// InvalidateTenant drops all cached search results for a tenant.
+// Its signature matches database.DocumentChangeHook.
+func (c *SearchCache) InvalidateTenant(ctx context.Context, tenantID, docID string) {
+	if err := c.redis.Incr(ctx, generationKey(tenantID)).Err(); err != nil {
+		slog.ErrorContext(ctx, "Failed to invalidate search cache", "tenant_id", tenantID, "error", err)
+	}
+}
+
+// hybrid implements caching for both hybrid search variants
+func (c *SearchCache) hybrid(
+	ctx context.Context,
+	tenantID, operation string,
+	params storage.HybridSearchParams,
+	search func(context.Context, string, storage.HybridSearchParams) ([]storage.HybridSearchResult, error),
+) ([]storage.HybridSearchResult, error) {
+	normalized := params
+	normalized.Collection = storage.CollectionOrDefault(params.Collection)
+	normalized.Query = normalizeQuery(params.Query)
+	normalized.Embedding = nil
+
+	keyParams := struct {
+		Params    storage.HybridSearchParams `json:"p"`
+		Embedding string                     `json:"e"`
+	}{normalized, hashEmbedding(params.Embedding)}
+
+	var results []storage.HybridSearchResult
+	key := c.key(ctx, tenantID, operation, keyParams)
+	if c.get(ctx, key, operation, &results) {
+		return results, nil
+	}
+
+	results, err := search(ctx, tenantID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(ctx, key, results)
+	return results, nil
+}
+
+// key builds the cache key for an operation and its normalized parameters.
+// An empty key means the cache is unavailable and the lookup should be skipped.
+func (c *SearchCache) key(ctx context.Context, tenantID, operation string, params interface{}) string {
+	region := database.DefaultRegion
+	if c.regions != nil {
+		var err error
+		if region, err = c.regions.TenantRegion(ctx, tenantID); err != nil {
+			return ""
+		}
+	}
+
+	generation, err := c.redis.Get(ctx, generationKey(tenantID)).Result()
+	if err == redis.Nil {
+		generation = "0"
+	} else if err != nil {
+		slog.WarnContext(ctx, "Search cache unavailable", "error", err)
+		return ""
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+
+	return fmt.Sprintf("%s:%s:%s:%s:%s:%s", keyPrefix, tenantID, region, generation, operation, hex.EncodeToString(sum[:]))
+}
+
+// get loads a cached value into dest and reports whether it was a hit
+func (c *SearchCache) get(ctx context.Context, key, operation string, dest interface{}) bool {
+	if key == "" {
+		return false
+	}
+
+	hit := false
+	data, err := c.redis.Get(ctx, key).Bytes()
+	if err == nil && json.Unmarshal(data, dest) == nil {
+		hit = true
+	} else if err != nil && err != redis.Nil {
+		slog.WarnContext(ctx, "Search cache read failed", "error", err)
+	}
+
+	if c.metrics != nil {
+		c.metrics.RecordCacheLookup(ctx, operation, hit)
+	}
+	return hit
+}
+
+// set stores a value in the cache, logging failures without failing the request
+func (c *SearchCache) set(ctx context.Context, key string, value interface{}) {
+	if key == "" {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := c.redis.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		slog.WarnContext(ctx, "Search cache write failed", "error", err)
+	}
+}
+
+// generationKey returns the key holding a tenant's cache generation
+func generationKey(tenantID string) string {
+	return fmt.Sprintf("%s:%s:gen", keyPrefix, tenantID)
+}
+
+// normalizeQuery lowercases a query and collapses whitespace
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// hashEmbedding returns a compact, stable digest of an embedding vector
+func hashEmbedding(embedding []float32) string {
+	if len(embedding) == 0 {
+		return ""
+	}
+
+	h := sha256.New()
+	buf := make([]byte, 4)
+	for _, v := range embedding {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(v))
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
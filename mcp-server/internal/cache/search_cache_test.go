@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore is a storage.Store that counts calls to the search methods
+type countingStore struct {
+	searchCalls  int
+	hybridCalls  int
+	similarCalls int
+	err          error
+}
+
+func (s *countingStore) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
+	return &storage.Document{ID: docID, TenantID: tenantID}, nil
+}
+
+func (s *countingStore) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	s.searchCalls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*storage.Document{{ID: "doc-1", TenantID: tenantID, Title: "Result for " + query}}, nil
+}
+
+func (s *countingStore) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*storage.Document, error) {
+	return nil, nil
+}
+
+func (s *countingStore) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	s.hybridCalls++
+	return []storage.HybridSearchResult{{Document: storage.Document{ID: "doc-1"}, CombinedScore: 0.5}}, nil
+}
+
+func (s *countingStore) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	return s.HybridSearch(ctx, tenantID, params)
+}
+
+func (s *countingStore) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	s.similarCalls++
+	return []storage.ScoredDocument{{Document: &storage.Document{ID: "doc-2", TenantID: tenantID}, Score: 0.9}}, nil
+}
+
+func setupCache(t *testing.T) (*SearchCache, *countingStore, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := &countingStore{}
+	return NewSearchCache(store, client, Config{TTL: time.Minute}, nil), store, mr
+}
+
+func TestSearchCache_SearchDocuments_HitAfterMiss(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	first, err := c.SearchDocuments(ctx, "tenant-1", "", "Security Policy", 10, nil)
+	require.NoError(t, err)
+
+	// Normalized query (case and whitespace) should hit the same entry
+	second, err := c.SearchDocuments(ctx, "tenant-1", "", "  security   policy ", 10, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, store.searchCalls)
+	assert.Equal(t, first[0].ID, second[0].ID)
+}
+
+func TestSearchCache_FiltersArePartOfKey(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, storage.MetadataFilter{"category": {"security"}})
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, storage.MetadataFilter{"category": {"security"}})
+	assert.Equal(t, 2, store.searchCalls)
+
+	params := storage.HybridSearchParams{Query: "policy"}
+	_, _ = c.HybridSearch(ctx, "tenant-1", params)
+	params.Filters = storage.MetadataFilter{"category": {"hr"}}
+	_, _ = c.HybridSearch(ctx, "tenant-1", params)
+	assert.Equal(t, 2, store.hybridCalls)
+}
+
+func TestSearchCache_TenantIsolation(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	_, err := c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.NoError(t, err)
+	results, err := c.SearchDocuments(ctx, "tenant-2", "", "policy", 10, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, store.searchCalls)
+	assert.Equal(t, "tenant-2", results[0].TenantID)
+}
+
+func TestSearchCache_DifferentParamsMiss(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	params := storage.HybridSearchParams{Query: "policy", Limit: 10, BM25Weight: 0.5, VectorWeight: 0.5}
+	_, err := c.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+
+	params.Limit = 20
+	_, err = c.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+
+	params.Embedding = []float32{0.1, 0.2}
+	_, err = c.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+
+	_, err = c.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, store.hybridCalls)
+}
+
+func TestSearchCache_SimilarDocuments(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	first, err := c.SimilarDocuments(ctx, "tenant-1", "doc-1", 5, nil)
+	require.NoError(t, err)
+	second, err := c.SimilarDocuments(ctx, "tenant-1", "doc-1", 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, first[0].Document.ID, second[0].Document.ID)
+	assert.Equal(t, 1, store.similarCalls)
+
+	_, _ = c.SimilarDocuments(ctx, "tenant-1", "doc-2", 5, nil)
+	_, _ = c.SimilarDocuments(ctx, "tenant-1", "doc-1", 5, storage.MetadataFilter{"category": {"security"}})
+	assert.Equal(t, 3, store.similarCalls)
+
+	c.InvalidateTenant(ctx, "tenant-1", "doc-1")
+	_, _ = c.SimilarDocuments(ctx, "tenant-1", "doc-1", 5, nil)
+	assert.Equal(t, 4, store.similarCalls)
+}
+
+func TestSearchCache_InvalidateTenant(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	_, _ = c.SearchDocuments(ctx, "tenant-2", "", "policy", 10, nil)
+
+	c.InvalidateTenant(ctx, "tenant-1", "doc-1")
+
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	_, _ = c.SearchDocuments(ctx, "tenant-2", "", "policy", 10, nil)
+
+	// tenant-1 was re-queried, tenant-2 was still cached
+	assert.Equal(t, 3, store.searchCalls)
+}
+
+func TestSearchCache_ErrorsAreNotCached(t *testing.T) {
+	c, store, _ := setupCache(t)
+	ctx := context.Background()
+
+	store.err = errors.New("database down")
+	_, err := c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.Error(t, err)
+
+	store.err = nil
+	_, err = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, store.searchCalls)
+}
+
+func TestSearchCache_RedisUnavailable(t *testing.T) {
+	c, store, mr := setupCache(t)
+	ctx := context.Background()
+
+	mr.Close()
+
+	_, err := c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.NoError(t, err)
+	_, err = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, store.searchCalls)
+}
+
+func TestSearchCache_PassThrough(t *testing.T) {
+	c, _, _ := setupCache(t)
+
+	doc, err := c.GetDocument(context.Background(), "tenant-1", "doc-9")
+	require.NoError(t, err)
+	assert.Equal(t, "doc-9", doc.ID)
+}
+
+type staticRegions struct {
+	regions map[string]string
+}
+
+func (s *staticRegions) TenantRegion(ctx context.Context, tenantID string) (string, error) {
+	region, ok := s.regions[tenantID]
+	if !ok {
+		return "", errors.New("tenant not found")
+	}
+	return region, nil
+}
+
+func TestSearchCache_RegionScoping(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := &countingStore{}
+	regions := &staticRegions{regions: map[string]string{"tenant-1": "eu-west"}}
+	c := NewSearchCache(store, client, Config{TTL: time.Minute, Regions: regions}, nil)
+	ctx := context.Background()
+
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	assert.Equal(t, 1, store.searchCalls)
+
+	// Moving the tenant to another region must not serve the old region's entries
+	regions.regions["tenant-1"] = "us-east"
+	_, _ = c.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	assert.Equal(t, 2, store.searchCalls)
+
+	// Unknown region bypasses the cache entirely
+	_, _ = c.SearchDocuments(ctx, "tenant-2", "", "policy", 10, nil)
+	_, _ = c.SearchDocuments(ctx, "tenant-2", "", "policy", 10, nil)
+	assert.Equal(t, 4, store.searchCalls)
+}
@@ -0,0 +1,827 @@
+// Package config loads the MCP server configuration. Settings are merged from built-in
+// defaults, an optional YAML file, environment variables and command-line flags, in
+// that order of precedence, and validated before the server starts.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/dedup"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/embeddings"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/llm"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/middleware"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/outbox"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/redaction"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resilience"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/sessions"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/bhatti/mcp-a2a-go/shared/health"
+	"github.com/bhatti/mcp-a2a-go/shared/httpserver"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/mtls"
+	"gopkg.in/yaml.v3"
+)
+
+// Document storage drivers selectable with DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+// FileEnv names the environment variable holding the config file path
+const FileEnv = "CONFIG_FILE"
+
+// Config holds the server configuration
+type Config struct {
+	Port          string          `yaml:"port"`
+	DBDriver      string          `yaml:"db_driver"`
+	SQLitePath    string          `yaml:"sqlite_path"`
+	Database      database.Config `yaml:"database"`
+	RedisAddr     string          `yaml:"redis_addr"`
+	RateLimit     int             `yaml:"rate_limit"`
+	Environment   string          `yaml:"environment"`
+	OTLPEndpoint  string          `yaml:"otlp_endpoint"`
+	SamplingRate  float64         `yaml:"sampling_rate"`
+	EnableTracing bool            `yaml:"enable_tracing"`
+	EnableMetrics bool            `yaml:"enable_metrics"`
+	// Bucket boundaries of histograms by name, overriding the defaults for durations
+	HistogramBuckets map[string][]float64 `yaml:"histogram_buckets"`
+	// Exemplars link histogram buckets to the traces of sampled requests
+	Exemplars bool `yaml:"exemplars"`
+	// How often connection pools are sampled for the pool gauges
+	PoolStatsInterval time.Duration `yaml:"pool_stats_interval"`
+	// How long shutdown waits for in-flight requests before cancelling them
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// MCP sessions without an open notification stream are dropped after this long without requests
+	SessionIdleTimeout time.Duration `yaml:"session_idle_timeout"`
+	// How long each dependency has to answer a readiness probe
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+	// Circuit breakers failing Postgres and Redis calls fast while the dependency is down
+	CircuitBreaker resilience.Config `yaml:"circuit_breaker"`
+	// Largest request body accepted, after decompressing gzip bodies
+	MaxRequestBytes int `yaml:"max_request_bytes"`
+	// Gzip compression of responses of at least GzipMinBytes for clients that accept it
+	GzipResponses bool `yaml:"gzip_responses"`
+	GzipMinBytes  int  `yaml:"gzip_min_bytes"`
+	// How requests are rate limited while Redis is unavailable: counted in memory per
+	// server with RateLimitLocalFallback, or else by RateLimitFailurePolicy
+	RateLimitFailurePolicy string `yaml:"rate_limit_failure_policy"`
+	RateLimitLocalFallback bool   `yaml:"rate_limit_local_fallback"`
+	// How long a tool waits for the client to answer sampling/createMessage before it
+	// falls back to working without the client's model
+	SamplingTimeout time.Duration `yaml:"sampling_timeout"`
+	// Structured logging
+	Logging logging.Config `yaml:"logging"`
+	// Document access log
+	AccessLogEnabled    bool    `yaml:"access_log_enabled"`
+	AccessLogSampleRate float64 `yaml:"access_log_sample_rate"`
+	// Tool call audit log
+	AuditLogEnabled bool          `yaml:"audit_log_enabled"`
+	AuditRetention  time.Duration `yaml:"audit_retention"`
+	// Embedding consistency checker
+	EmbeddingCheckEnabled  bool          `yaml:"embedding_check_enabled"`
+	EmbeddingCheckInterval time.Duration `yaml:"embedding_check_interval"`
+	// Duplicate document detector and its review endpoint (/admin/duplicates)
+	DedupEnabled bool         `yaml:"dedup_enabled"`
+	Dedup        dedup.Config `yaml:"dedup"`
+	// PII redaction of ingested documents and tool output
+	RedactionEnabled bool             `yaml:"redaction_enabled"`
+	Redaction        redaction.Config `yaml:"redaction"`
+	// How often tenants' usage of their document and storage quotas is reported
+	QuotaReportInterval time.Duration `yaml:"quota_report_interval"`
+	// Document event outbox relay, which runs when database.outbox records events
+	OutboxChannelPrefix string        `yaml:"outbox_channel_prefix"`
+	OutboxRelayInterval time.Duration `yaml:"outbox_relay_interval"`
+	OutboxRetention     time.Duration `yaml:"outbox_retention"`
+	// File the safe mode state is saved to so it survives restarts; empty keeps it in memory
+	SafeModeStateFile string `yaml:"safe_mode_state_file"`
+	// How long a user's assigned role is cached before it is looked up again
+	RoleCacheTTL time.Duration `yaml:"role_cache_ttl"`
+	// Search result cache
+	SearchCacheEnabled bool          `yaml:"search_cache_enabled"`
+	SearchCacheTTL     time.Duration `yaml:"search_cache_ttl"`
+	// Data residency: additional regional databases keyed by region name. In the config
+	// file each region inherits the primary database settings it does not set.
+	DataRegions map[string]database.Config `yaml:"-"`
+	// Tool execution timeouts
+	ToolTimeout  time.Duration            `yaml:"tool_timeout"`
+	ToolTimeouts map[string]time.Duration `yaml:"tool_timeouts"`
+	// Largest tool result content returned, zero for no limit; the rest of a larger result
+	// can be fetched with read_continuation for ContinuationTTL
+	MaxToolResponseBytes int           `yaml:"max_tool_response_bytes"`
+	ContinuationTTL      time.Duration `yaml:"continuation_ttl"`
+	// Tenant-uploaded WASM tools (needs a build with -tags wazero)
+	WASMToolsEnabled bool             `yaml:"wasm_tools_enabled"`
+	WASM             tools.WASMLimits `yaml:"wasm"`
+	// Content-addressed storage of images too large to inline in tool results, served at
+	// /blobs/{hash}; off without a backend
+	Blobs tools.BlobsConfig `yaml:"blobs"`
+	// Operator-defined read-only SQL template tools (Postgres only)
+	SQLTools []tools.SQLToolSpec `yaml:"sql_tools"`
+	// federated_search latency budget and remote MCP servers it can query
+	Federation tools.FederationConfig `yaml:"federation"`
+	// Tenant onboarding endpoint (POST /admin/tenants)
+	OnboardingEnabled bool              `yaml:"onboarding_enabled"`
+	Onboarding        onboarding.Config `yaml:"onboarding"`
+	// Default hybrid_search fusion method and RRF constant
+	HybridFusion string `yaml:"hybrid_fusion"`
+	HybridRRFK   int    `yaml:"hybrid_rrf_k"`
+	// Provider of the query embeddings of semantic and hybrid search_documents calls
+	Embeddings embeddings.Config `yaml:"embeddings"`
+	// LLM provider that answer_question generates answers with; the tool is off without one
+	LLM llm.Config `yaml:"llm"`
+	// tiktoken rank file (e.g. cl100k_base.tiktoken) that OpenAI models' tokens are counted
+	// with exactly; without one they are estimated
+	TokenizerBPEFile string `yaml:"tokenizer_bpe_file"`
+	// JWT verification keys
+	DevMode           bool             `yaml:"dev_mode"`
+	DemoKeysDir       string           `yaml:"demo_keys_dir"` // where DEV_MODE saves the demo key pair for the UI
+	JWTIssuer         string           `yaml:"jwt_issuer"`
+	JWTAudience       string           `yaml:"jwt_audience"`
+	JWTPublicKeys     string           `yaml:"jwt_public_keys"`      // PEM, one or more keys
+	JWTPublicKeyFiles []string         `yaml:"jwt_public_key_files"` // PEM files
+	JWTKeysDir        string           `yaml:"jwt_keys_dir"`         // directory of PEM files, e.g. a mounted Kubernetes secret
+	JWTKeysRefresh    time.Duration    `yaml:"jwt_keys_refresh"`
+	JWTSigningKeyFile string           `yaml:"jwt_signing_key_file"` // PEM private key that signs onboarding API keys
+	Vault             auth.VaultConfig `yaml:"vault"`
+	// OIDC accepts the access tokens of an external OpenID Connect provider (optional)
+	OIDC auth.OIDCConfig `yaml:"oidc"`
+	// TLS serves HTTPS, with mutual TLS when a CA is set; the certificate also
+	// authenticates calls to the A2A server
+	TLS mtls.Config `yaml:"tls"`
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	return Config{
+		Port:       "8080",
+		DBDriver:   DriverPostgres,
+		SQLitePath: "mcp.db",
+		Database: database.Config{
+			Host:     "localhost",
+			Port:     5432,
+			User:     "mcp_user",
+			Password: "mcp_password",
+			DBName:   "mcp_db",
+			SSLMode:  "disable",
+			MaxConns: 25,
+			MinConns: 5,
+
+			SlowQueryThreshold: 500 * time.Millisecond,
+		},
+		RedisAddr:     "localhost:6379",
+		RateLimit:     100, // requests per minute
+		Environment:   "development",
+		OTLPEndpoint:  "jaeger:4318",
+		SamplingRate:  1.0,
+		EnableTracing: true,
+		EnableMetrics: true,
+		Exemplars:     true,
+
+		PoolStatsInterval: observability.DefaultPoolStatsInterval,
+
+		DrainTimeout:       10 * time.Second,
+		SessionIdleTimeout: 30 * time.Minute,
+		HealthCheckTimeout: health.DefaultTimeout,
+		CircuitBreaker:     resilience.DefaultConfig(),
+
+		MaxRequestBytes: httpserver.DefaultMaxRequestBytes,
+		GzipResponses:   true,
+		GzipMinBytes:    httpserver.DefaultGzipMinBytes,
+
+		RateLimitFailurePolicy: middleware.RateLimitFailOpen,
+		RateLimitLocalFallback: true,
+
+		SamplingTimeout: sessions.DefaultRequestTimeout,
+
+		Logging: logging.Config{Level: "info", Format: logging.FormatJSON},
+
+		AccessLogEnabled:    true,
+		AccessLogSampleRate: 1.0,
+
+		AuditLogEnabled: true,
+		AuditRetention:  365 * 24 * time.Hour,
+
+		EmbeddingCheckEnabled:  true,
+		EmbeddingCheckInterval: 10 * time.Minute,
+
+		DedupEnabled: true,
+		Dedup:        dedup.DefaultConfig(),
+
+		RedactionEnabled: true,
+		Redaction:        redaction.Config{PolicyTTL: time.Minute},
+
+		QuotaReportInterval: time.Minute,
+
+		OutboxChannelPrefix: outbox.DefaultChannelPrefix,
+		OutboxRelayInterval: time.Second,
+		OutboxRetention:     24 * time.Hour,
+
+		RoleCacheTTL: time.Minute,
+
+		SearchCacheEnabled: true,
+		SearchCacheTTL:     5 * time.Minute,
+
+		DataRegions: map[string]database.Config{},
+
+		ToolTimeout:  30 * time.Second,
+		ToolTimeouts: map[string]time.Duration{},
+
+		MaxToolResponseBytes: tools.DefaultMaxResponseBytes,
+		ContinuationTTL:      tools.DefaultContinuationTTL,
+
+		WASMToolsEnabled: true,
+		WASM:             tools.DefaultWASMLimits(),
+
+		Blobs: tools.BlobsConfig{
+			BaseURL:        "http://localhost:8080",
+			MaxInlineBytes: tools.DefaultMaxInlineBytes,
+		},
+
+		Federation: tools.FederationConfig{Timeout: tools.DefaultFederationTimeout},
+
+		OnboardingEnabled: true,
+		Onboarding:        onboarding.DefaultConfig(),
+
+		HybridFusion: storage.FusionWeighted,
+		HybridRRFK:   storage.DefaultRRFK,
+
+		Embeddings: embeddings.Config{
+			URL:     embeddings.DefaultURL,
+			Model:   embeddings.DefaultModel,
+			Timeout: embeddings.DefaultTimeout,
+		},
+		LLM: llm.Config{
+			Timeout:   llm.DefaultTimeout,
+			MaxTokens: llm.DefaultMaxTokens,
+		},
+
+		DemoKeysDir:    "/tmp/demo-keys",
+		JWTIssuer:      "mcp-server-demo",
+		JWTAudience:    "mcp-server",
+		JWTKeysRefresh: 5 * time.Minute,
+		Vault: auth.VaultConfig{
+			Addr: "http://127.0.0.1:8200",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file named by the -config
+// flag or CONFIG_FILE, the environment and the remaining flags, and validates it
+func Load(args []string) (Config, error) {
+	flags := newFlagSet()
+	if err := flags.parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+
+	path := flags.configFile
+	if path == "" {
+		path = os.Getenv(FileEnv)
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	applyEnv(&cfg)
+	flags.apply(&cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadFile merges a YAML config file into cfg. Unknown keys are rejected so typos
+// do not silently fall back to defaults.
+func loadFile(cfg *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	if err := decode(cfg, f); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// decode merges YAML from r into cfg
+func decode(cfg *Config, r io.Reader) error {
+	file := struct {
+		Config      `yaml:",inline"`
+		DataRegions map[string]yaml.Node `yaml:"data_regions"`
+	}{Config: *cfg}
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	for region, node := range file.DataRegions {
+		regionCfg := file.Config.Database
+		regionCfg.Replicas = nil // replicas belong to the primary database
+		if err := node.Decode(&regionCfg); err != nil {
+			return fmt.Errorf("data_regions.%s: %w", region, err)
+		}
+		if file.Config.DataRegions == nil {
+			file.Config.DataRegions = map[string]database.Config{}
+		}
+		file.Config.DataRegions[region] = regionCfg
+	}
+
+	*cfg = file.Config
+	return nil
+}
+
+// applyEnv overrides cfg with the environment variables that are set
+func applyEnv(cfg *Config) {
+	cfg.Port = getEnv("PORT", cfg.Port)
+	cfg.DBDriver = getEnv("DB_DRIVER", cfg.DBDriver)
+	cfg.SQLitePath = getEnv("SQLITE_PATH", cfg.SQLitePath)
+	cfg.Database.Host = getEnv("DB_HOST", cfg.Database.Host)
+	cfg.Database.Port = getEnvInt("DB_PORT", cfg.Database.Port)
+	cfg.Database.User = getEnv("DB_USER", cfg.Database.User)
+	cfg.Database.Password = getEnv("DB_PASSWORD", cfg.Database.Password)
+	cfg.Database.DBName = getEnv("DB_NAME", cfg.Database.DBName)
+	cfg.Database.SSLMode = getEnv("DB_SSLMODE", cfg.Database.SSLMode)
+	cfg.Database.MaxConns = int32(getEnvInt("DB_MAX_CONNS", int(cfg.Database.MaxConns)))
+	cfg.Database.MinConns = int32(getEnvInt("DB_MIN_CONNS", int(cfg.Database.MinConns)))
+	cfg.Database.Quantization.DualWrite = getEnvBool("VECTOR_QUANTIZATION_DUAL_WRITE", cfg.Database.Quantization.DualWrite)
+	cfg.Database.Quantization.Search = getEnv("VECTOR_QUANTIZATION_SEARCH", cfg.Database.Quantization.Search)
+	cfg.Database.Quantization.RerankFactor = getEnvInt("VECTOR_QUANTIZATION_RERANK_FACTOR", cfg.Database.Quantization.RerankFactor)
+	cfg.Database.StatementCache.Mode = getEnv("DB_STATEMENT_CACHE_MODE", cfg.Database.StatementCache.Mode)
+	cfg.Database.StatementCache.Capacity = getEnvInt("DB_STATEMENT_CACHE_CAPACITY", cfg.Database.StatementCache.Capacity)
+	cfg.Database.SessionReads = getEnvBool("DB_SESSION_READS", cfg.Database.SessionReads)
+	cfg.Database.SlowQueryThreshold = getEnvDuration("DB_SLOW_QUERY_THRESHOLD", cfg.Database.SlowQueryThreshold)
+	cfg.Database.AutoMigrate = getEnvBool("DB_AUTO_MIGRATE", cfg.Database.AutoMigrate)
+	cfg.Database.MigrationUser = getEnv("DB_MIGRATION_USER", cfg.Database.MigrationUser)
+	cfg.Database.MigrationPassword = getEnv("DB_MIGRATION_PASSWORD", cfg.Database.MigrationPassword)
+	if replicas := getEnvList("DB_REPLICAS"); replicas != nil {
+		cfg.Database.Replicas = parseReplicas(replicas)
+	}
+	cfg.RedisAddr = getEnv("REDIS_ADDR", cfg.RedisAddr)
+	cfg.RateLimit = getEnvInt("RATE_LIMIT", cfg.RateLimit)
+	cfg.RateLimitFailurePolicy = getEnv("RATE_LIMIT_FAILURE_POLICY", cfg.RateLimitFailurePolicy)
+	cfg.RateLimitLocalFallback = getEnvBool("RATE_LIMIT_LOCAL_FALLBACK", cfg.RateLimitLocalFallback)
+	cfg.Environment = getEnv("ENVIRONMENT", cfg.Environment)
+	cfg.OTLPEndpoint = getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", cfg.OTLPEndpoint)
+	cfg.SamplingRate = getEnvFloat("OTEL_TRACES_SAMPLER_ARG", cfg.SamplingRate)
+	cfg.EnableTracing = getEnvBool("OTEL_ENABLE_TRACING", cfg.EnableTracing)
+	cfg.EnableMetrics = getEnvBool("OTEL_ENABLE_METRICS", cfg.EnableMetrics)
+	cfg.Exemplars = getEnvBool("OTEL_EXEMPLARS", cfg.Exemplars)
+	cfg.PoolStatsInterval = getEnvDuration("POOL_STATS_INTERVAL", cfg.PoolStatsInterval)
+	for name, boundaries := range getEnvHistogramBuckets("OTEL_HISTOGRAM_BUCKETS") {
+		if cfg.HistogramBuckets == nil {
+			cfg.HistogramBuckets = map[string][]float64{}
+		}
+		cfg.HistogramBuckets[name] = boundaries
+	}
+
+	cfg.Logging.Level = getEnv("LOG_LEVEL", cfg.Logging.Level)
+	cfg.Logging.Format = getEnv("LOG_FORMAT", cfg.Logging.Format)
+	cfg.Logging.AddSource = getEnvBool("LOG_ADD_SOURCE", cfg.Logging.AddSource)
+
+	cfg.AccessLogEnabled = getEnvBool("ACCESS_LOG_ENABLED", cfg.AccessLogEnabled)
+	cfg.AccessLogSampleRate = getEnvFloat("ACCESS_LOG_SAMPLE_RATE", cfg.AccessLogSampleRate)
+
+	cfg.DrainTimeout = getEnvDuration("SHUTDOWN_DRAIN_TIMEOUT", cfg.DrainTimeout)
+	cfg.SessionIdleTimeout = getEnvDuration("MCP_SESSION_IDLE_TIMEOUT", cfg.SessionIdleTimeout)
+	cfg.SamplingTimeout = getEnvDuration("MCP_SAMPLING_TIMEOUT", cfg.SamplingTimeout)
+	cfg.HealthCheckTimeout = getEnvDuration("HEALTH_CHECK_TIMEOUT", cfg.HealthCheckTimeout)
+	cfg.CircuitBreaker.Enabled = getEnvBool("BREAKER_ENABLED", cfg.CircuitBreaker.Enabled)
+	cfg.CircuitBreaker.FailureRate = getEnvFloat("BREAKER_FAILURE_RATE", cfg.CircuitBreaker.FailureRate)
+	cfg.CircuitBreaker.MinRequests = getEnvInt("BREAKER_MIN_REQUESTS", cfg.CircuitBreaker.MinRequests)
+	cfg.CircuitBreaker.Window = getEnvDuration("BREAKER_WINDOW", cfg.CircuitBreaker.Window)
+	cfg.CircuitBreaker.OpenTimeout = getEnvDuration("BREAKER_OPEN_TIMEOUT", cfg.CircuitBreaker.OpenTimeout)
+	cfg.CircuitBreaker.HalfOpenRequests = getEnvInt("BREAKER_HALF_OPEN_REQUESTS", cfg.CircuitBreaker.HalfOpenRequests)
+	cfg.MaxRequestBytes = getEnvInt("MAX_REQUEST_BYTES", cfg.MaxRequestBytes)
+	cfg.GzipResponses = getEnvBool("GZIP_RESPONSES", cfg.GzipResponses)
+	cfg.GzipMinBytes = getEnvInt("GZIP_MIN_BYTES", cfg.GzipMinBytes)
+
+	cfg.AuditLogEnabled = getEnvBool("AUDIT_LOG_ENABLED", cfg.AuditLogEnabled)
+	cfg.AuditRetention = getEnvDuration("AUDIT_RETENTION", cfg.AuditRetention)
+
+	cfg.EmbeddingCheckEnabled = getEnvBool("EMBEDDING_CHECK_ENABLED", cfg.EmbeddingCheckEnabled)
+	cfg.EmbeddingCheckInterval = getEnvDuration("EMBEDDING_CHECK_INTERVAL", cfg.EmbeddingCheckInterval)
+	cfg.DedupEnabled = getEnvBool("DEDUP_ENABLED", cfg.DedupEnabled)
+	cfg.Dedup.Interval = getEnvDuration("DEDUP_INTERVAL", cfg.Dedup.Interval)
+	cfg.Dedup.Threshold = getEnvFloat("DEDUP_THRESHOLD", cfg.Dedup.Threshold)
+	cfg.Dedup.AutoAction = getEnv("DEDUP_AUTO_ACTION", cfg.Dedup.AutoAction)
+	cfg.Dedup.AutoThreshold = getEnvFloat("DEDUP_AUTO_THRESHOLD", cfg.Dedup.AutoThreshold)
+	cfg.RedactionEnabled = getEnvBool("REDACTION_ENABLED", cfg.RedactionEnabled)
+	cfg.Redaction.Ingest = getEnvBool("REDACTION_INGEST", cfg.Redaction.Ingest)
+	cfg.Redaction.Output = getEnvBool("REDACTION_OUTPUT", cfg.Redaction.Output)
+	if types := getEnvList("REDACTION_TYPES"); types != nil {
+		cfg.Redaction.Types = types
+	}
+	cfg.Redaction.PolicyTTL = getEnvDuration("REDACTION_POLICY_TTL", cfg.Redaction.PolicyTTL)
+	cfg.QuotaReportInterval = getEnvDuration("QUOTA_REPORT_INTERVAL", cfg.QuotaReportInterval)
+	cfg.Database.Outbox = getEnvBool("DOCUMENT_OUTBOX_ENABLED", cfg.Database.Outbox)
+	cfg.OutboxChannelPrefix = getEnv("DOCUMENT_EVENTS_CHANNEL_PREFIX", cfg.OutboxChannelPrefix)
+	cfg.OutboxRelayInterval = getEnvDuration("DOCUMENT_OUTBOX_RELAY_INTERVAL", cfg.OutboxRelayInterval)
+	cfg.OutboxRetention = getEnvDuration("DOCUMENT_OUTBOX_RETENTION", cfg.OutboxRetention)
+	cfg.SafeModeStateFile = getEnv("SAFE_MODE_STATE_FILE", cfg.SafeModeStateFile)
+
+	cfg.RoleCacheTTL = getEnvDuration("ROLE_CACHE_TTL", cfg.RoleCacheTTL)
+
+	cfg.SearchCacheEnabled = getEnvBool("SEARCH_CACHE_ENABLED", cfg.SearchCacheEnabled)
+	cfg.SearchCacheTTL = getEnvDuration("SEARCH_CACHE_TTL", cfg.SearchCacheTTL)
+
+	applyRegionEnv(cfg)
+
+	cfg.ToolTimeout = getEnvDuration("TOOL_TIMEOUT", cfg.ToolTimeout)
+	for name, timeout := range getEnvDurationMap("TOOL_TIMEOUTS") {
+		if cfg.ToolTimeouts == nil {
+			cfg.ToolTimeouts = map[string]time.Duration{}
+		}
+		cfg.ToolTimeouts[name] = timeout
+	}
+	cfg.MaxToolResponseBytes = getEnvInt("MAX_TOOL_RESPONSE_BYTES", cfg.MaxToolResponseBytes)
+	cfg.ContinuationTTL = getEnvDuration("CONTINUATION_TTL", cfg.ContinuationTTL)
+
+	cfg.WASMToolsEnabled = getEnvBool("WASM_TOOLS_ENABLED", cfg.WASMToolsEnabled)
+	cfg.WASM.MaxModuleBytes = getEnvInt("WASM_MAX_MODULE_BYTES", cfg.WASM.MaxModuleBytes)
+	cfg.WASM.MaxMemoryPages = uint32(getEnvInt("WASM_MAX_MEMORY_PAGES", int(cfg.WASM.MaxMemoryPages)))
+	cfg.WASM.MaxOutputBytes = uint32(getEnvInt("WASM_MAX_OUTPUT_BYTES", int(cfg.WASM.MaxOutputBytes)))
+	cfg.WASM.Timeout = getEnvDuration("WASM_TIMEOUT", cfg.WASM.Timeout)
+	cfg.WASM.MaxToolsPerTenant = getEnvInt("WASM_MAX_TOOLS_PER_TENANT", cfg.WASM.MaxToolsPerTenant)
+
+	cfg.Blobs.Store.Backend = getEnv("BLOB_BACKEND", cfg.Blobs.Store.Backend)
+	cfg.Blobs.Store.Dir = getEnv("BLOB_DIR", cfg.Blobs.Store.Dir)
+	cfg.Blobs.Store.Endpoint = getEnv("BLOB_ENDPOINT", cfg.Blobs.Store.Endpoint)
+	cfg.Blobs.Store.Bucket = getEnv("BLOB_BUCKET", cfg.Blobs.Store.Bucket)
+	cfg.Blobs.Store.Region = getEnv("BLOB_REGION", cfg.Blobs.Store.Region)
+	cfg.Blobs.Store.AccessKeyID = getEnv("BLOB_ACCESS_KEY_ID", cfg.Blobs.Store.AccessKeyID)
+	cfg.Blobs.Store.SecretAccessKey = getEnv("BLOB_SECRET_ACCESS_KEY", cfg.Blobs.Store.SecretAccessKey)
+	cfg.Blobs.Store.Prefix = getEnv("BLOB_PREFIX", cfg.Blobs.Store.Prefix)
+	cfg.Blobs.BaseURL = getEnv("BLOB_BASE_URL", cfg.Blobs.BaseURL)
+	cfg.Blobs.MaxInlineBytes = getEnvInt("BLOB_MAX_INLINE_BYTES", cfg.Blobs.MaxInlineBytes)
+
+	cfg.Federation.Timeout = getEnvDuration("FEDERATION_TIMEOUT", cfg.Federation.Timeout)
+
+	cfg.OnboardingEnabled = getEnvBool("ONBOARDING_ENABLED", cfg.OnboardingEnabled)
+	cfg.Onboarding.DefaultTier = getEnv("ONBOARDING_DEFAULT_TIER", cfg.Onboarding.DefaultTier)
+	cfg.Onboarding.APIKeyTTL = getEnvDuration("ONBOARDING_API_KEY_TTL", cfg.Onboarding.APIKeyTTL)
+	cfg.Onboarding.A2AURL = getEnv("A2A_URL", cfg.Onboarding.A2AURL)
+	cfg.Onboarding.A2AAdminToken = getEnv("A2A_ADMIN_TOKEN", cfg.Onboarding.A2AAdminToken)
+
+	cfg.HybridFusion = getEnv("HYBRID_FUSION", cfg.HybridFusion)
+	cfg.HybridRRFK = getEnvInt("HYBRID_RRF_K", cfg.HybridRRFK)
+
+	cfg.Embeddings.Provider = getEnv("EMBEDDINGS_PROVIDER", cfg.Embeddings.Provider)
+	cfg.Embeddings.URL = getEnv("EMBEDDINGS_URL", cfg.Embeddings.URL)
+	cfg.Embeddings.Model = getEnv("EMBEDDINGS_MODEL", cfg.Embeddings.Model)
+	cfg.Embeddings.APIKey = getEnv("EMBEDDINGS_API_KEY", getEnv("OPENAI_API_KEY", cfg.Embeddings.APIKey))
+	cfg.Embeddings.Timeout = getEnvDuration("EMBEDDINGS_TIMEOUT", cfg.Embeddings.Timeout)
+
+	cfg.LLM.Provider = getEnv("LLM_PROVIDER", cfg.LLM.Provider)
+	cfg.LLM.URL = getEnv("LLM_URL", cfg.LLM.URL)
+	cfg.LLM.Model = getEnv("LLM_MODEL", cfg.LLM.Model)
+	switch cfg.LLM.Provider {
+	case llm.ProviderOpenAI:
+		cfg.LLM.APIKey = getEnv("OPENAI_API_KEY", cfg.LLM.APIKey)
+	case llm.ProviderAnthropic:
+		cfg.LLM.APIKey = getEnv("ANTHROPIC_API_KEY", cfg.LLM.APIKey)
+	}
+	cfg.LLM.APIKey = getEnv("LLM_API_KEY", cfg.LLM.APIKey)
+	cfg.LLM.Timeout = getEnvDuration("LLM_TIMEOUT", cfg.LLM.Timeout)
+	cfg.LLM.MaxTokens = getEnvInt("LLM_MAX_TOKENS", cfg.LLM.MaxTokens)
+	cfg.TokenizerBPEFile = getEnv("TOKENIZER_BPE_FILE", cfg.TokenizerBPEFile)
+
+	cfg.DevMode = getEnvBool("DEV_MODE", cfg.DevMode)
+	cfg.DemoKeysDir = getEnv("DEMO_KEYS_DIR", cfg.DemoKeysDir)
+	cfg.JWTIssuer = getEnv("JWT_ISSUER", cfg.JWTIssuer)
+	cfg.JWTAudience = getEnv("JWT_AUDIENCE", cfg.JWTAudience)
+	cfg.JWTPublicKeys = getEnv("JWT_PUBLIC_KEYS", cfg.JWTPublicKeys)
+	if files := getEnvList("JWT_PUBLIC_KEY_FILES"); files != nil {
+		cfg.JWTPublicKeyFiles = files
+	}
+	cfg.JWTKeysDir = getEnv("JWT_KEYS_DIR", cfg.JWTKeysDir)
+	cfg.JWTKeysRefresh = getEnvDuration("JWT_KEYS_REFRESH", cfg.JWTKeysRefresh)
+	cfg.JWTSigningKeyFile = getEnv("JWT_SIGNING_KEY_FILE", cfg.JWTSigningKeyFile)
+	cfg.Vault.Addr = getEnv("VAULT_ADDR", cfg.Vault.Addr)
+	cfg.Vault.Token = getEnv("VAULT_TOKEN", cfg.Vault.Token)
+	cfg.Vault.Namespace = getEnv("VAULT_NAMESPACE", cfg.Vault.Namespace)
+	cfg.Vault.Path = getEnv("JWT_VAULT_PATH", cfg.Vault.Path)
+	cfg.OIDC.Issuer = getEnv("OIDC_ISSUER", cfg.OIDC.Issuer)
+	cfg.OIDC.Resource = getEnv("OIDC_RESOURCE", cfg.OIDC.Resource)
+	cfg.OIDC.Audience = getEnv("OIDC_AUDIENCE", cfg.OIDC.Audience)
+	cfg.OIDC.ClientID = getEnv("OIDC_CLIENT_ID", cfg.OIDC.ClientID)
+	cfg.OIDC.ClientSecret = getEnv("OIDC_CLIENT_SECRET", cfg.OIDC.ClientSecret)
+	cfg.OIDC.IntrospectAll = getEnvBool("OIDC_INTROSPECT_ALL", cfg.OIDC.IntrospectAll)
+	cfg.OIDC.TenantClaim = getEnv("OIDC_TENANT_CLAIM", cfg.OIDC.TenantClaim)
+	cfg.TLS.CertFile = getEnv("TLS_CERT_FILE", cfg.TLS.CertFile)
+	cfg.TLS.KeyFile = getEnv("TLS_KEY_FILE", cfg.TLS.KeyFile)
+	cfg.TLS.CAFile = getEnv("TLS_CA_FILE", cfg.TLS.CAFile)
+	cfg.TLS.ReloadInterval = getEnvDuration("TLS_RELOAD_INTERVAL", cfg.TLS.ReloadInterval)
+	if identities := os.Getenv("TLS_CLIENT_IDENTITIES"); identities != "" {
+		cfg.TLS.Identities = mtls.ParseIdentities(identities)
+	}
+}
+
+// applyRegionEnv adds the regional databases listed in DATA_REGIONS (e.g. "eu-west,us-east").
+// Each region inherits the primary settings, or its settings from the config file, and
+// overrides them with DB_<REGION>_HOST, DB_<REGION>_PORT, DB_<REGION>_USER,
+// DB_<REGION>_PASSWORD and DB_<REGION>_NAME. Its blob store is set likewise with
+// BLOB_<REGION>_DIR, BLOB_<REGION>_ENDPOINT, BLOB_<REGION>_BUCKET, BLOB_<REGION>_REGION,
+// BLOB_<REGION>_ACCESS_KEY_ID, BLOB_<REGION>_SECRET_ACCESS_KEY and BLOB_<REGION>_PREFIX.
+func applyRegionEnv(cfg *Config) {
+	for _, region := range getEnvList("DATA_REGIONS") {
+		if region == database.DefaultRegion {
+			continue
+		}
+
+		base, ok := cfg.DataRegions[region]
+		if !ok {
+			base = cfg.Database
+			base.Replicas = nil
+		}
+
+		prefix := "DB_" + strings.ToUpper(strings.ReplaceAll(region, "-", "_")) + "_"
+		regionCfg := base
+		regionCfg.Host = getEnv(prefix+"HOST", base.Host)
+		regionCfg.Port = getEnvInt(prefix+"PORT", base.Port)
+		regionCfg.User = getEnv(prefix+"USER", base.User)
+		regionCfg.Password = getEnv(prefix+"PASSWORD", base.Password)
+		regionCfg.DBName = getEnv(prefix+"NAME", base.DBName)
+
+		if cfg.DataRegions == nil {
+			cfg.DataRegions = map[string]database.Config{}
+		}
+		cfg.DataRegions[region] = regionCfg
+
+		prefix = "BLOB_" + strings.ToUpper(strings.ReplaceAll(region, "-", "_")) + "_"
+		blobCfg := cfg.Blobs.Regions[region]
+		blobCfg.Dir = getEnv(prefix+"DIR", blobCfg.Dir)
+		blobCfg.Endpoint = getEnv(prefix+"ENDPOINT", blobCfg.Endpoint)
+		blobCfg.Bucket = getEnv(prefix+"BUCKET", blobCfg.Bucket)
+		blobCfg.Region = getEnv(prefix+"REGION", blobCfg.Region)
+		blobCfg.AccessKeyID = getEnv(prefix+"ACCESS_KEY_ID", blobCfg.AccessKeyID)
+		blobCfg.SecretAccessKey = getEnv(prefix+"SECRET_ACCESS_KEY", blobCfg.SecretAccessKey)
+		blobCfg.Prefix = getEnv(prefix+"PREFIX", blobCfg.Prefix)
+		if blobCfg != (blobs.Config{}) {
+			if cfg.Blobs.Regions == nil {
+				cfg.Blobs.Regions = map[string]blobs.Config{}
+			}
+			cfg.Blobs.Regions[region] = blobCfg
+		}
+	}
+}
+
+// parseReplicas parses DB_REPLICAS entries of the form "host" or "host:port". A replica
+// without a port uses the primary's port. Malformed entries are logged and skipped.
+func parseReplicas(entries []string) []database.ReplicaConfig {
+	var replicas []database.ReplicaConfig
+	for _, entry := range entries {
+		host, portValue, found := strings.Cut(entry, ":")
+		port := 0
+		if found {
+			var err error
+			if port, err = strconv.Atoi(portValue); err != nil || host == "" {
+				slog.Warn("Ignoring invalid entry", "key", "DB_REPLICAS", "entry", entry)
+				continue
+			}
+		}
+		replicas = append(replicas, database.ReplicaConfig{Host: host, Port: port})
+	}
+	return replicas
+}
+
+// Validate checks that every setting is within range, reporting all problems at once
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Port)
+	check(err == nil && validPort(port), "port must be between 1 and 65535, got %q", c.Port)
+
+	switch c.DBDriver {
+	case DriverPostgres:
+		check(validPort(c.Database.Port), "database.port must be between 1 and 65535, got %d", c.Database.Port)
+		check(c.Database.MaxConns > 0, "database.max_conns must be positive, got %d", c.Database.MaxConns)
+		check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns,
+			"database.min_conns must be between 0 and max_conns, got %d", c.Database.MinConns)
+		err := c.Database.Quantization.Validate()
+		check(err == nil, "database.quantization.%v", err)
+		err = c.Database.StatementCache.Validate()
+		check(err == nil, "database.statement_cache.%v", err)
+		check(c.Database.SlowQueryThreshold >= 0, "database.slow_query_threshold must not be negative, got %s", c.Database.SlowQueryThreshold)
+		for i, replica := range c.Database.Replicas {
+			check(replica.Host != "", "database.replicas[%d].host is required", i)
+			check(replica.Port == 0 || validPort(replica.Port), "database.replicas[%d].port must be between 1 and 65535, got %d", i, replica.Port)
+		}
+		for region, regionCfg := range c.DataRegions {
+			check(validPort(regionCfg.Port), "data_regions.%s.port must be between 1 and 65535, got %d", region, regionCfg.Port)
+		}
+	case DriverSQLite:
+		check(c.SQLitePath != "", "sqlite_path is required with db_driver %s", DriverSQLite)
+	case DriverMemory:
+	default:
+		check(false, "db_driver must be %s, %s or %s, got %q", DriverPostgres, DriverSQLite, DriverMemory, c.DBDriver)
+	}
+
+	check(c.RateLimit > 0, "rate_limit must be positive, got %d", c.RateLimit)
+	if err := middleware.ValidateRateLimitPolicy(c.RateLimitFailurePolicy); err != nil {
+		errs = append(errs, fmt.Errorf("rate_limit_failure_policy: %w", err))
+	}
+	check(c.SamplingRate >= 0 && c.SamplingRate <= 1, "sampling_rate must be between 0 and 1, got %g", c.SamplingRate)
+	check(c.AccessLogSampleRate >= 0 && c.AccessLogSampleRate <= 1,
+		"access_log_sample_rate must be between 0 and 1, got %g", c.AccessLogSampleRate)
+
+	if err := observability.ValidateHistogramBuckets(c.HistogramBuckets); err != nil {
+		errs = append(errs, fmt.Errorf("histogram_buckets: %w", err))
+	}
+
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "", logging.FormatJSON, logging.FormatText:
+	default:
+		check(false, "logging.format must be %s or %s, got %q", logging.FormatJSON, logging.FormatText, c.Logging.Format)
+	}
+
+	check(!c.AuditLogEnabled || c.AuditRetention > 0, "audit_retention must be positive, got %s", c.AuditRetention)
+	check(!c.Database.Outbox || c.OutboxRelayInterval > 0, "outbox_relay_interval must be positive, got %s", c.OutboxRelayInterval)
+	check(!c.Database.Outbox || c.OutboxRetention > 0, "outbox_retention must be positive, got %s", c.OutboxRetention)
+	check(!c.EmbeddingCheckEnabled || c.EmbeddingCheckInterval > 0,
+		"embedding_check_interval must be positive, got %s", c.EmbeddingCheckInterval)
+	if c.DedupEnabled {
+		if err := c.Dedup.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("dedup: %w", err))
+		}
+		check(c.Dedup.Interval > 0, "dedup.interval must be positive, got %s", c.Dedup.Interval)
+		check(c.Dedup.TenantTimeout > 0, "dedup.tenant_timeout must be positive, got %s", c.Dedup.TenantTimeout)
+	}
+	if c.RedactionEnabled {
+		if err := c.Redaction.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("redaction: %w", err))
+		}
+	}
+	check(c.QuotaReportInterval > 0, "quota_report_interval must be positive, got %s", c.QuotaReportInterval)
+	check(!c.SearchCacheEnabled || c.SearchCacheTTL > 0, "search_cache_ttl must be positive, got %s", c.SearchCacheTTL)
+	check(c.RoleCacheTTL >= 0, "role_cache_ttl must not be negative, got %s", c.RoleCacheTTL)
+	check(c.JWTKeysRefresh >= 0, "jwt_keys_refresh must not be negative, got %s", c.JWTKeysRefresh)
+	if err := c.OIDC.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("oidc: %w", err))
+	}
+	if err := c.TLS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("tls: %w", err))
+	}
+	check(c.DrainTimeout >= 0, "drain_timeout must not be negative, got %s", c.DrainTimeout)
+	check(c.SessionIdleTimeout > 0, "session_idle_timeout must be positive, got %s", c.SessionIdleTimeout)
+	check(c.SamplingTimeout > 0, "sampling_timeout must be positive, got %s", c.SamplingTimeout)
+	check(c.PoolStatsInterval > 0, "pool_stats_interval must be positive, got %s", c.PoolStatsInterval)
+	check(c.HealthCheckTimeout > 0, "health_check_timeout must be positive, got %s", c.HealthCheckTimeout)
+	if err := c.CircuitBreaker.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("circuit_breaker: %w", err))
+	}
+	check(c.MaxRequestBytes > 0, "max_request_bytes must be positive, got %d", c.MaxRequestBytes)
+	check(c.GzipMinBytes >= 0, "gzip_min_bytes must not be negative, got %d", c.GzipMinBytes)
+	check(c.ToolTimeout >= 0, "tool_timeout must not be negative, got %s", c.ToolTimeout)
+	for name, timeout := range c.ToolTimeouts {
+		check(timeout >= 0, "tool_timeouts.%s must not be negative, got %s", name, timeout)
+	}
+	check(c.MaxToolResponseBytes >= 0, "max_tool_response_bytes must not be negative, got %d", c.MaxToolResponseBytes)
+	check(c.MaxToolResponseBytes == 0 || c.ContinuationTTL > 0, "continuation_ttl must be positive, got %s", c.ContinuationTTL)
+
+	if c.WASMToolsEnabled {
+		check(c.WASM.MaxModuleBytes > 0, "wasm.max_module_bytes must be positive, got %d", c.WASM.MaxModuleBytes)
+		check(c.WASM.MaxMemoryPages > 0 && c.WASM.MaxMemoryPages <= 65536,
+			"wasm.max_memory_pages must be between 1 and 65536, got %d", c.WASM.MaxMemoryPages)
+		check(c.WASM.MaxOutputBytes > 0, "wasm.max_output_bytes must be positive, got %d", c.WASM.MaxOutputBytes)
+		check(c.WASM.Timeout > 0, "wasm.timeout must be positive, got %s", c.WASM.Timeout)
+		check(c.WASM.MaxToolsPerTenant > 0, "wasm.max_tools_per_tenant must be positive, got %d", c.WASM.MaxToolsPerTenant)
+	}
+
+	if c.Blobs.Enabled() {
+		if err := c.Blobs.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("blobs: %w", err))
+		}
+		if c.DBDriver == DriverPostgres {
+			for region := range c.DataRegions {
+				_, ok := c.Blobs.Regions[region]
+				check(ok, "blobs.regions.%s is required: tool output must be stored in each data region", region)
+			}
+		}
+	}
+
+	sqlToolNames := make(map[string]bool, len(c.SQLTools))
+	for _, spec := range c.SQLTools {
+		if _, err := tools.NewSQLTool(spec, nil); err != nil {
+			errs = append(errs, fmt.Errorf("sql_tools: %w", err))
+		}
+		check(!sqlToolNames[spec.Name], "sql_tools: duplicate tool name %q", spec.Name)
+		sqlToolNames[spec.Name] = true
+	}
+	check(len(c.SQLTools) == 0 || c.DBDriver == DriverPostgres, "sql_tools need db_driver %s", DriverPostgres)
+	if err := c.Federation.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("federation: %w", err))
+	}
+
+	if c.OnboardingEnabled {
+		if err := c.Onboarding.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("onboarding: %w", err))
+		}
+		check(c.Onboarding.A2AURL == "" || c.Onboarding.A2AAdminToken != "",
+			"onboarding.a2a_admin_token is required with onboarding.a2a_url")
+	}
+
+	if _, err := storage.NewFusion(c.HybridFusion, c.HybridRRFK); err != nil {
+		errs = append(errs, fmt.Errorf("hybrid_fusion: %w", err))
+	}
+	check(c.HybridRRFK >= 0, "hybrid_rrf_k must not be negative, got %d", c.HybridRRFK)
+	if err := c.Embeddings.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("embeddings: %w", err))
+	}
+	if err := c.LLM.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("llm: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validPort reports whether port is a valid TCP port
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+// flagSet holds the command-line flags; only flags given explicitly override other sources
+type flagSet struct {
+	*flag.FlagSet
+	configFile string
+	port       string
+	dbDriver   string
+	rateLimit  int
+	logLevel   string
+	logFormat  string
+}
+
+// newFlagSet defines the command-line flags
+func newFlagSet() *flagSet {
+	f := &flagSet{FlagSet: flag.NewFlagSet("mcp-server", flag.ContinueOnError)}
+	f.StringVar(&f.configFile, "config", "", "YAML config file (default $"+FileEnv+")")
+	f.StringVar(&f.port, "port", "", "HTTP port (overrides PORT)")
+	f.StringVar(&f.dbDriver, "db-driver", "", "document storage: postgres, sqlite or memory (overrides DB_DRIVER)")
+	f.IntVar(&f.rateLimit, "rate-limit", 0, "requests per minute per tenant (overrides RATE_LIMIT)")
+	f.StringVar(&f.logLevel, "log-level", "", "debug, info, warn or error (overrides LOG_LEVEL)")
+	f.StringVar(&f.logFormat, "log-format", "", "json or text (overrides LOG_FORMAT)")
+	return f
+}
+
+// parse parses the flags
+func (f *flagSet) parse(args []string) error {
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if f.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(f.Args(), " "))
+	}
+	return nil
+}
+
+// apply overrides cfg with the flags that were set
+func (f *flagSet) apply(cfg *Config) {
+	f.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "port":
+			cfg.Port = f.port
+		case "db-driver":
+			cfg.DBDriver = f.dbDriver
+		case "rate-limit":
+			cfg.RateLimit = f.rateLimit
+		case "log-level":
+			cfg.Logging.Level = f.logLevel
+		case "log-format":
+			cfg.Logging.Format = f.logFormat
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig writes a config file into a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	assert.NoError(t, Default().Validate())
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfig(t, `
+port: "9000"
+rate_limit: 50
+db_driver: memory
+logging:
+  level: debug
+  format: text
+tool_timeout: 10s
+tool_timeouts:
+  hybrid_search: 20s
+database:
+  host: db.internal
+  max_conns: 10
+  min_conns: 2
+data_regions:
+  eu-west:
+    host: db.eu.internal
+`)
+	t.Setenv("RATE_LIMIT", "75")
+	t.Setenv("TOOL_TIMEOUTS", "list_documents=2s")
+
+	cfg, err := Load([]string{"-config", path, "-log-level", "warn"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "9000", cfg.Port)              // file
+	assert.Equal(t, DriverMemory, cfg.DBDriver)    // file
+	assert.Equal(t, 75, cfg.RateLimit)             // env over file
+	assert.Equal(t, "warn", cfg.Logging.Level)     // flag over file
+	assert.Equal(t, "text", cfg.Logging.Format)    // file
+	assert.Equal(t, "mcp_db", cfg.Database.DBName) // default kept
+	assert.Equal(t, 10*time.Second, cfg.ToolTimeout)
+	assert.Equal(t, map[string]time.Duration{
+		"hybrid_search":  20 * time.Second,
+		"list_documents": 2 * time.Second,
+	}, cfg.ToolTimeouts)
+
+	// Regions inherit the primary database settings they do not set
+	require.Contains(t, cfg.DataRegions, "eu-west")
+	assert.Equal(t, "db.eu.internal", cfg.DataRegions["eu-west"].Host)
+	assert.Equal(t, int32(10), cfg.DataRegions["eu-west"].MaxConns)
+}
+
+func TestLoad_Replicas(t *testing.T) {
+	path := writeConfig(t, `
+database:
+  host: db.internal
+  replicas:
+    - host: replica-a.internal
+data_regions:
+  eu-west:
+    host: db.eu.internal
+`)
+	t.Setenv("DATA_REGIONS", "us-east")
+
+	cfg, err := Load([]string{"-config", path})
+	require.NoError(t, err)
+	assert.Equal(t, []database.ReplicaConfig{{Host: "replica-a.internal"}}, cfg.Database.Replicas)
+	assert.Empty(t, cfg.DataRegions["eu-west"].Replicas, "regions do not inherit the primary's replicas")
+	assert.Empty(t, cfg.DataRegions["us-east"].Replicas)
+
+	t.Setenv("DB_REPLICAS", "replica-b.internal:5433, replica-c.internal,:bad,replica-d:x")
+	cfg, err = Load([]string{"-config", path})
+	require.NoError(t, err)
+	assert.Equal(t, []database.ReplicaConfig{
+		{Host: "replica-b.internal", Port: 5433},
+		{Host: "replica-c.internal"},
+	}, cfg.Database.Replicas)
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	cfg, err := Load([]string{"-config", "../../config.example.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, cfg.ToolTimeouts["hybrid_search"])
+	require.Len(t, cfg.SQLTools, 1)
+	assert.Equal(t, "documents_by_category", cfg.SQLTools[0].Name)
+	assert.Equal(t, 150, cfg.Onboarding.Tiers["startup"].RateLimitPerMinute)
+	assert.Contains(t, cfg.Onboarding.Tiers, "free", "built-in tiers are kept")
+}
+
+func TestLoad_ConfigFileEnv(t *testing.T) {
+	t.Setenv(FileEnv, writeConfig(t, "port: 9100\n"))
+
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "9100", cfg.Port)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		args    []string
+		wantErr string
+	}{
+		{"unknown key", "rate_limt: 10\n", nil, "rate_limt"},
+		{"port out of range", "port: 70000\n", nil, "port must be between 1 and 65535"},
+		{"sampling rate", "sampling_rate: 1.5\n", nil, "sampling_rate must be between 0 and 1"},
+		{"unknown driver", "", []string{"-db-driver", "mysql"}, "db_driver must be"},
+		{"log level", "logging:\n  level: loud\n", nil, "logging.level"},
+		{"fusion", "hybrid_fusion: average\n", nil, "hybrid_fusion"},
+		{"sql tool", "sql_tools:\n  - name: purge\n    query: DELETE FROM documents\n", nil, "sql_tools"},
+		{"onboarding tier", "onboarding:\n  default_tier: gold\n", nil, "default_tier"},
+		{"dedup action", "dedup:\n  auto_action: delete\n", nil, "dedup: auto_action"},
+		{"redaction types", "redaction:\n  types: [\"\"]\n", nil, "redaction: types"},
+		{"oidc resource", "oidc:\n  issuer: https://idp.example.com\n", nil, "oidc: resource"},
+		{"tls identity", "tls:\n  cert_file: server.pem\n  key_file: server-key.pem\n  ca_file: ca.pem\n  identities:\n    - san: a2a.internal\n", nil, "tls: identities[0]"},
+		{"quota report interval", "quota_report_interval: 0s\n", nil, "quota_report_interval must be positive"},
+		{"unexpected argument", "", []string{"serve"}, "unexpected arguments"},
+		{"statement cache", "database:\n  statement_cache:\n    mode: prepared\n", nil, "database.statement_cache.mode"},
+		{"replica host", "database:\n  replicas:\n    - port: 5433\n", nil, "database.replicas[0].host is required"},
+		{"session idle timeout", "session_idle_timeout: 0s\n", nil, "session_idle_timeout must be positive"},
+		{"tool response size", "max_tool_response_bytes: -1\n", nil, "max_tool_response_bytes must not be negative"},
+		{"blob store", "blobs:\n  backend: s3\n  bucket: tool-output\n", nil, "blobs: s3 backend requires endpoint"},
+		{"regional blob store", "blobs:\n  backend: filesystem\ndata_regions:\n  eu-west:\n    host: postgres-eu\n", nil, "blobs.regions.eu-west is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeConfig(t, tt.file)}, args...)
+			}
+			_, err := Load(args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := Default()
+	cfg.Port = "0"
+	cfg.SamplingRate = -1
+	cfg.RateLimit = 0
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port")
+	assert.Contains(t, err.Error(), "sampling_rate")
+	assert.Contains(t, err.Error(), "rate_limit")
+}
+
+func TestReloader_AppliesReloadableSettings(t *testing.T) {
+	current := Default()
+	next := Default()
+	next.RateLimit = 500
+	next.Logging.Level = "debug"
+	next.ToolTimeout = time.Second
+	next.Port = "9999" // requires a restart
+
+	reloader := NewReloader(current, nil)
+	reloader.load = func([]string) (Config, error) { return next, nil }
+
+	var applied Config
+	reloader.OnReload(func(cfg Config) { applied = cfg })
+
+	require.NoError(t, reloader.Reload())
+	assert.Equal(t, 500, applied.RateLimit)
+	assert.Equal(t, "debug", applied.Logging.Level)
+	assert.Equal(t, time.Second, applied.ToolTimeout)
+	assert.Equal(t, current.Port, applied.Port)
+	assert.Equal(t, applied, reloader.Current())
+}
+
+func TestReloader_RejectsInvalidConfig(t *testing.T) {
+	reloader := NewReloader(Default(), []string{"-config", writeConfig(t, "rate_limit: -1\n")})
+
+	called := false
+	reloader.OnReload(func(Config) { called = true })
+
+	assert.Error(t, reloader.Reload())
+	assert.False(t, called)
+	assert.Equal(t, Default().RateLimit, reloader.Current().RateLimit)
+}
+
+func TestRestartRequired(t *testing.T) {
+	old := Default()
+	next := Default()
+	next.RateLimit = 1
+	next.Logging.Level = "error"
+	assert.Empty(t, RestartRequired(old, next))
+
+	next.Port = "9999"
+	next.Logging.Format = "text"
+	next.DataRegions = map[string]database.Config{"eu-west": {}}
+	assert.ElementsMatch(t, []string{"port", "logging", "data_regions"}, RestartRequired(old, next))
+}
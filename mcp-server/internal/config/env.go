@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+)
+
+// getEnv retrieves an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt retrieves an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		var intValue int
+		if _, err := fmt.Sscanf(value, "%d", &intValue); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat retrieves a float environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		var floatValue float64
+		if _, err := fmt.Sscanf(value, "%f", &floatValue); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration retrieves a duration environment variable (e.g. "30s", "5m") or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDurationMap parses "name=duration" pairs separated by commas, e.g. "hybrid_search=10s,list_documents=2s".
+// Malformed entries are logged and skipped.
+func getEnvDurationMap(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, "=")
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || err != nil {
+			slog.Warn("Ignoring invalid entry", "key", key, "entry", pair)
+			continue
+		}
+		result[strings.TrimSpace(name)] = duration
+	}
+	return result
+}
+
+// getEnvHistogramBuckets parses bucket boundaries per histogram, e.g.
+// "mcp.request.duration=1,5,10;mcp.db.query.duration=0.1,0.5,1". A malformed value is
+// logged and ignored.
+func getEnvHistogramBuckets(key string) map[string][]float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	buckets, err := observability.ParseHistogramBuckets(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid histogram buckets", "key", key, "error", err)
+		return nil
+	}
+	return buckets
+}
+
+// getEnvList retrieves a comma-separated environment variable as a list, skipping empty items
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvBool retrieves a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if value == "true" || value == "1" || value == "yes" {
+			return true
+		}
+		if value == "false" || value == "0" || value == "no" {
+			return false
+		}
+	}
+	return defaultValue
+}
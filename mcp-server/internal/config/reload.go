@@ -0,0 +1,137 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+)
+
+// Reloader reloads the configuration when the process receives SIGHUP. Only settings
+// that can change while the server runs (rate limit, log level and tool timeouts) are
+// applied; changes to other settings are logged and take effect after a restart.
+type Reloader struct {
+	args     []string
+	load     func(args []string) (Config, error)
+	handlers []func(Config)
+
+	mu      sync.Mutex
+	current Config
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewReloader creates a reloader for the running configuration, reloading it from the
+// same command-line arguments it was loaded from
+func NewReloader(current Config, args []string) *Reloader {
+	return &Reloader{
+		args:    args,
+		load:    Load,
+		current: current,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// OnReload registers a handler called with the configuration after every successful reload
+func (r *Reloader) OnReload(handler func(Config)) {
+	r.handlers = append(r.handlers, handler)
+}
+
+// Current returns the running configuration
+func (r *Reloader) Current() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Start reloads the configuration on every SIGHUP until the reloader is closed
+func (r *Reloader) Start() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				if err := r.Reload(); err != nil {
+					slog.Error("Config reload failed, keeping current configuration", "error", err)
+				}
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops listening for SIGHUP
+func (r *Reloader) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+// Reload loads and validates the configuration again and applies its reloadable
+// settings. An invalid configuration is rejected as a whole.
+func (r *Reloader) Reload() error {
+	next, err := r.load(r.args)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if changed := RestartRequired(r.current, next); len(changed) > 0 {
+		slog.Warn("Config changes require a restart and were not applied", "settings", changed)
+	}
+
+	applied := r.current
+	applied.RateLimit = next.RateLimit
+	applied.Logging.Level = next.Logging.Level
+	applied.ToolTimeout = next.ToolTimeout
+	applied.ToolTimeouts = next.ToolTimeouts
+	r.current = applied
+
+	for _, handler := range r.handlers {
+		handler(applied)
+	}
+	slog.Info("Config reloaded",
+		"rate_limit", applied.RateLimit,
+		"log_level", applied.Logging.Level,
+		"tool_timeout", applied.ToolTimeout.String(),
+	)
+	return nil
+}
+
+// RestartRequired returns the config keys that differ between old and next, other than
+// the settings a reload applies
+func RestartRequired(old, next Config) []string {
+	// Ignore the reloadable settings
+	next.RateLimit = old.RateLimit
+	next.Logging.Level = old.Logging.Level
+	next.ToolTimeout = old.ToolTimeout
+	next.ToolTimeouts = old.ToolTimeouts
+
+	var changed []string
+	oldValue, nextValue := reflect.ValueOf(old), reflect.ValueOf(next)
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			continue
+		}
+		name := field.Tag.Get("yaml")
+		if field.Name == "DataRegions" {
+			name = "data_regions" // decoded separately so regions inherit the primary database
+		}
+		changed = append(changed, name)
+	}
+	return changed
+}
@@ -0,0 +1,150 @@
+// Package consistency runs background checks that keep derived data in line with
+// document content, starting with embeddings generated from outdated text.
+package consistency
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+)
+
+// Config holds embedding consistency checker configuration
+type Config struct {
+	// Interval is how often all tenants are checked (default 10m)
+	Interval time.Duration
+	// TenantTimeout bounds the check of a single tenant (default 1m)
+	TenantTimeout time.Duration
+}
+
+// Checker periodically flags documents whose embedding was generated from older
+// content, queues them for re-embedding and publishes the per-tenant stale count
+type Checker struct {
+	tenants database.TenantLister
+	store   database.EmbeddingStore
+	metrics *observability.Metrics
+	config  Config
+
+	// Checks, which queue re-embedding jobs, are skipped while safe mode is on
+	safeMode *safemode.Switch
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewChecker creates a new embedding consistency checker. Tenants are listed from
+// the control plane and checked through store, which may route to regional databases.
+func NewChecker(tenants database.TenantLister, store database.EmbeddingStore, metrics *observability.Metrics, cfg Config) *Checker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	if cfg.TenantTimeout <= 0 {
+		cfg.TenantTimeout = time.Minute
+	}
+
+	return &Checker{
+		tenants: tenants,
+		store:   store,
+		metrics: metrics,
+		config:  cfg,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// SetSafeMode skips scheduled checks while safe mode is on
+func (c *Checker) SetSafeMode(safeMode *safemode.Switch) {
+	c.safeMode = safeMode
+}
+
+// Start runs a check right away and then on every interval
+func (c *Checker) Start() {
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Close stops the background checks and waits for a running check to finish
+func (c *Checker) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+	c.wg.Wait()
+}
+
+// run checks all tenants on every interval until the checker is closed
+func (c *Checker) run() {
+	defer c.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.safeMode.Check("re-embedding"); err != nil {
+			slog.WarnContext(ctx, "Skipping embedding consistency check", "reason", err.Error())
+		} else if _, err := c.CheckAll(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Embedding consistency check failed", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// CheckAll checks every active tenant and returns the statuses of the tenants that
+// were checked. A failing tenant is logged and skipped so it cannot block the others.
+func (c *Checker) CheckAll(ctx context.Context) ([]database.EmbeddingStatus, error) {
+	tenantIDs, err := c.tenants.ListActiveTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]database.EmbeddingStatus, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return statuses, ctx.Err()
+		}
+
+		status, err := c.checkTenant(ctx, tenantID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Embedding consistency check failed for tenant", "tenant_id", tenantID, "error", err)
+			continue
+		}
+		statuses = append(statuses, *status)
+
+		if c.metrics != nil {
+			c.metrics.RecordStaleEmbeddings(ctx, tenantID, int64(status.Stale))
+		}
+		if status.Flagged > 0 {
+			slog.InfoContext(ctx, "Queued documents for re-embedding",
+				"tenant_id", tenantID, "flagged", status.Flagged, "stale", status.Stale, "documents", status.Documents)
+		}
+		if status.Requantized > 0 {
+			slog.InfoContext(ctx, "Backfilled quantized embeddings", "tenant_id", tenantID, "documents", status.Requantized)
+		}
+	}
+	return statuses, nil
+}
+
+// checkTenant checks a single tenant within the tenant timeout
+func (c *Checker) checkTenant(ctx context.Context, tenantID string) (*database.EmbeddingStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.TenantTimeout)
+	defer cancel()
+	return c.store.CheckEmbeddings(ctx, tenantID)
+}
@@ -0,0 +1,119 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmbeddingStore serves canned statuses and counts checks per tenant
+type fakeEmbeddingStore struct {
+	tenants  []string
+	statuses map[string]database.EmbeddingStatus
+	failing  map[string]bool
+
+	mu     sync.Mutex
+	checks map[string]int
+}
+
+func (f *fakeEmbeddingStore) ListActiveTenants(ctx context.Context) ([]string, error) {
+	return f.tenants, nil
+}
+
+func (f *fakeEmbeddingStore) CheckEmbeddings(ctx context.Context, tenantID string) (*database.EmbeddingStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.checks == nil {
+		f.checks = make(map[string]int)
+	}
+	f.checks[tenantID]++
+
+	if f.failing[tenantID] {
+		return nil, errors.New("database unavailable")
+	}
+	status := f.statuses[tenantID]
+	status.TenantID = tenantID
+	return &status, nil
+}
+
+func (f *fakeEmbeddingStore) checkCount(tenantID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.checks[tenantID]
+}
+
+func TestChecker_CheckAll(t *testing.T) {
+	store := &fakeEmbeddingStore{
+		tenants: []string{"tenant-a", "tenant-b", "tenant-c"},
+		statuses: map[string]database.EmbeddingStatus{
+			"tenant-a": {Documents: 10, Stale: 2, Flagged: 1},
+			"tenant-c": {Documents: 5, Missing: 5},
+		},
+		failing: map[string]bool{"tenant-b": true},
+	}
+
+	checker := NewChecker(store, store, nil, Config{})
+	statuses, err := checker.CheckAll(context.Background())
+	require.NoError(t, err)
+
+	// The failing tenant is skipped without stopping the others
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "tenant-a", statuses[0].TenantID)
+	assert.Equal(t, 2, statuses[0].Stale)
+	assert.Equal(t, "tenant-c", statuses[1].TenantID)
+	assert.Equal(t, 1, store.checkCount("tenant-b"))
+}
+
+func TestChecker_CheckAllCancelled(t *testing.T) {
+	store := &fakeEmbeddingStore{tenants: []string{"tenant-a"}}
+	checker := NewChecker(store, store, nil, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := checker.CheckAll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, store.checkCount("tenant-a"))
+}
+
+func TestChecker_StartAndClose(t *testing.T) {
+	store := &fakeEmbeddingStore{tenants: []string{"tenant-a"}}
+	checker := NewChecker(store, store, nil, Config{Interval: 10 * time.Millisecond})
+
+	checker.Start()
+	assert.Eventually(t, func() bool { return store.checkCount("tenant-a") >= 2 }, time.Second, 5*time.Millisecond)
+	checker.Close()
+
+	// No checks run after Close returns
+	count := store.checkCount("tenant-a")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, store.checkCount("tenant-a"))
+	checker.Close()
+}
+
+func TestChecker_SkipsChecksInSafeMode(t *testing.T) {
+	safeMode, err := safemode.New(context.Background(), safemode.NewMemoryStore())
+	require.NoError(t, err)
+	_, err = safeMode.Set(context.Background(), true, "incident", "oncall")
+	require.NoError(t, err)
+
+	store := &fakeEmbeddingStore{tenants: []string{"tenant-a"}}
+	checker := NewChecker(store, store, nil, Config{Interval: 10 * time.Millisecond})
+	checker.SetSafeMode(safeMode)
+	checker.Start()
+	defer checker.Close()
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 0, store.checkCount("tenant-a"))
+
+	// Checks resume once safe mode is lifted
+	_, err = safeMode.Set(context.Background(), false, "", "oncall")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return store.checkCount("tenant-a") >= 1 }, time.Second, 5*time.Millisecond)
+}
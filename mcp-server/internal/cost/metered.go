@@ -0,0 +1,88 @@
+package cost
+
+import (
+	"context"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/embeddings"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/llm"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+)
+
+// meteredEmbedder records the cost of each embedding with the tracker
+type meteredEmbedder struct {
+	provider embeddings.Provider
+	model    string
+	tracker  *Tracker
+}
+
+// MeterEmbedder returns an embeddings provider recording the cost of each successful
+// embedding, estimated from the tokens of the embedded text
+func (t *Tracker) MeterEmbedder(provider embeddings.Provider, model string) embeddings.Provider {
+	return &meteredEmbedder{provider: provider, model: model, tracker: t}
+}
+
+// Embed implements embeddings.Provider
+func (e *meteredEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embedding, err := e.provider.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	e.tracker.Record(ctx, usageFor(ctx, Usage{
+		Operation:   OperationEmbedding,
+		Model:       e.model,
+		InputTokens: tokens.Count(e.model, text),
+	}))
+	return embedding, nil
+}
+
+// meteredLLM records the cost of each completion with the tracker
+type meteredLLM struct {
+	provider llm.Provider
+	model    string
+	tracker  *Tracker
+}
+
+// MeterLLM returns an LLM provider recording the cost of each successful completion.
+// Token counts come from the response and are estimated when the API does not report them.
+func (t *Tracker) MeterLLM(provider llm.Provider, model string) llm.Provider {
+	return &meteredLLM{provider: provider, model: model, tracker: t}
+}
+
+// Complete implements llm.Provider
+func (p *meteredLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	response, err := p.provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := Usage{
+		Operation:    OperationCompletion,
+		Model:        response.Model,
+		InputTokens:  response.InputTokens,
+		OutputTokens: response.OutputTokens,
+	}
+	if usage.Model == "" {
+		usage.Model = p.model
+	}
+	if usage.InputTokens == 0 {
+		usage.InputTokens = tokens.Count(usage.Model, req.System)
+		for _, message := range req.Messages {
+			usage.InputTokens += tokens.Count(usage.Model, message.Content)
+		}
+	}
+	if usage.OutputTokens == 0 {
+		usage.OutputTokens = tokens.Count(usage.Model, response.Text)
+	}
+	p.tracker.Record(ctx, usageFor(ctx, usage))
+	return response, nil
+}
+
+// usageFor attributes usage to the calling tenant and the executing tool. Calls made
+// outside a tenant's request, such as by background jobs, have an empty tenant.
+func usageFor(ctx context.Context, usage Usage) Usage {
+	usage.TenantID, _ = auth.ExtractTenantID(ctx)
+	usage.Tool = tools.ToolName(ctx)
+	return usage
+}
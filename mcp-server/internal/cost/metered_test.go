@@ -0,0 +1,109 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/llm"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEmbedder struct {
+	err error
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []float32{0.1, 0.2}, nil
+}
+
+type fakeLLM struct {
+	response *llm.Response
+	err      error
+}
+
+func (p *fakeLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	return p.response, p.err
+}
+
+// callTool is a tool running fn, so that fn sees the tool name on its context
+type callTool struct {
+	fn func(ctx context.Context)
+}
+
+func (t *callTool) Definition() protocol.Tool {
+	return protocol.Tool{Name: "metered_tool"}
+}
+
+func (t *callTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	t.fn(ctx)
+	return protocol.ToolCallResult{}, nil
+}
+
+// runTool runs fn as a tool call of tenant-1
+func runTool(t *testing.T, fn func(ctx context.Context)) {
+	registry := tools.NewRegistry()
+	registry.Register(&callTool{fn: fn})
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-1")
+	_, err := registry.Execute(ctx, "metered_tool", nil)
+	require.NoError(t, err)
+}
+
+func TestMeterEmbedder(t *testing.T) {
+	tracker := NewTracker(DefaultPriceTable(), 0)
+	recorder := &fakeRecorder{}
+	tracker.SetRecorder(recorder)
+	embedder := tracker.MeterEmbedder(&fakeEmbedder{}, "text-embedding-3-small")
+
+	runTool(t, func(ctx context.Context) {
+		_, err := embedder.Embed(ctx, "deep learning frameworks")
+		require.NoError(t, err)
+	})
+	require.Len(t, recorder.costs, 1)
+	assert.Equal(t, "tenant-1", recorder.costs[0].tenantID)
+	assert.Equal(t, "metered_tool", recorder.costs[0].tool)
+	assert.Equal(t, OperationEmbedding, recorder.costs[0].operation)
+	expected := float64(tokens.Count("text-embedding-3-small", "deep learning frameworks")) * 0.02 / 1_000_000
+	assert.InDelta(t, expected, recorder.costs[0].costUSD, 1e-12)
+
+	failing := tracker.MeterEmbedder(&fakeEmbedder{err: errors.New("down")}, "text-embedding-3-small")
+	_, err := failing.Embed(context.Background(), "text")
+	assert.Error(t, err)
+	assert.Len(t, recorder.costs, 1, "failed calls are not charged")
+}
+
+func TestMeterLLM(t *testing.T) {
+	tracker := NewTracker(DefaultPriceTable(), 0)
+	recorder := &fakeRecorder{}
+	tracker.SetRecorder(recorder)
+
+	reported := tracker.MeterLLM(&fakeLLM{response: &llm.Response{Text: "ok", Model: "gpt-4o-mini-2024-07-18", InputTokens: 1000, OutputTokens: 100}}, "gpt-4o-mini")
+	estimated := tracker.MeterLLM(&fakeLLM{response: &llm.Response{Text: "Five days."}}, "gpt-4o")
+	runTool(t, func(ctx context.Context) {
+		_, err := reported.Complete(ctx, llm.Request{Messages: []llm.Message{{Role: llm.RoleUser, Content: "question"}}})
+		require.NoError(t, err)
+		_, err = estimated.Complete(ctx, llm.Request{System: "Be brief.", Messages: []llm.Message{{Role: llm.RoleUser, Content: "How long?"}}})
+		require.NoError(t, err)
+	})
+
+	require.Len(t, recorder.costs, 2)
+	assert.Equal(t, "gpt-4o-mini-2024-07-18", recorder.costs[0].model, "the model that answered is charged")
+	assert.InDelta(t, 0.00021, recorder.costs[0].costUSD, 1e-12)
+	assert.Equal(t, "gpt-4o", recorder.costs[1].model)
+	report := tracker.Report("tenant-1", tracker.now(), tracker.now())
+	assert.Equal(t, tokens.Count("gpt-4o", "Be brief.")+tokens.Count("gpt-4o", "How long?")+1000, report.Total.InputTokens, "missing token counts are estimated")
+	assert.Equal(t, 100+tokens.Count("gpt-4o", "Five days."), report.Total.OutputTokens)
+
+	failing := tracker.MeterLLM(&fakeLLM{err: errors.New("rate limited")}, "gpt-4o")
+	_, err := failing.Complete(context.Background(), llm.Request{})
+	assert.Error(t, err)
+	assert.Len(t, recorder.costs, 2)
+}
@@ -0,0 +1,84 @@
+// Package cost attributes the estimated cost of the embedding and LLM calls the server
+// makes to the tenant and tool they were made for
+package cost
+
+import (
+	"strings"
+)
+
+// Token units model prices are quoted in
+const (
+	UnitPer1K = "per_1k"
+	UnitPer1M = "per_1m"
+)
+
+// ModelPrice is the price of a model's input and output tokens
+type ModelPrice struct {
+	InputUSD  float64 `json:"input_usd" yaml:"input_usd"`
+	OutputUSD float64 `json:"output_usd" yaml:"output_usd"`
+	// Unit is the number of tokens the prices are for; empty means UnitPer1M
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+}
+
+// tokensPerUnit returns the number of tokens the price's unit stands for
+func (p ModelPrice) tokensPerUnit() float64 {
+	if p.Unit == UnitPer1K {
+		return 1000
+	}
+	return 1_000_000
+}
+
+// PriceTable prices the models the server calls by model name. Names returned by the
+// APIs often carry a version suffix, e.g. gpt-4o-mini-2024-07-18; they are priced as the
+// longest model name they start with.
+type PriceTable map[string]ModelPrice
+
+// builtinPrices are the list prices per 1M tokens of OpenAI and Anthropic as of 2024.
+// Models missing from the table, such as those served by a local Ollama, cost nothing.
+var builtinPrices = PriceTable{
+	"text-embedding-ada-002": {InputUSD: 0.10},
+	"text-embedding-3-small": {InputUSD: 0.02},
+	"text-embedding-3-large": {InputUSD: 0.13},
+	"gpt-4o-mini":            {InputUSD: 0.15, OutputUSD: 0.60},
+	"gpt-4o":                 {InputUSD: 2.50, OutputUSD: 10.00},
+	"gpt-4-turbo":            {InputUSD: 10.00, OutputUSD: 30.00},
+	"gpt-3.5-turbo":          {InputUSD: 0.50, OutputUSD: 1.50},
+	"claude-3-5-haiku":       {InputUSD: 0.80, OutputUSD: 4.00},
+	"claude-3-5-sonnet":      {InputUSD: 3.00, OutputUSD: 15.00},
+	"claude-3-opus":          {InputUSD: 15.00, OutputUSD: 75.00},
+	"claude-3-haiku":         {InputUSD: 0.25, OutputUSD: 1.25},
+	"claude-3-sonnet":        {InputUSD: 3.00, OutputUSD: 15.00},
+}
+
+// DefaultPriceTable returns a copy of the built-in prices
+func DefaultPriceTable() PriceTable {
+	prices := make(PriceTable, len(builtinPrices))
+	for name, price := range builtinPrices {
+		prices[name] = price
+	}
+	return prices
+}
+
+// Cost prices a model's input and output tokens
+func (t PriceTable) Cost(model string, inputTokens, outputTokens int) float64 {
+	price, ok := t.lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(inputTokens)*price.InputUSD + float64(outputTokens)*price.OutputUSD) / price.tokensPerUnit()
+}
+
+// lookup finds the price of model, or of the longest model name it starts with
+func (t PriceTable) lookup(model string) (ModelPrice, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	best, found := "", false
+	var price ModelPrice
+	for name, p := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best, price, found = name, p, true
+		}
+	}
+	return price, found
+}
@@ -0,0 +1,205 @@
+package cost
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Operations whose cost is tracked
+const (
+	OperationEmbedding  = "embedding"
+	OperationCompletion = "completion"
+)
+
+// DefaultRetention is how long daily cost totals are kept
+const DefaultRetention = 90 * 24 * time.Hour
+
+// Usage is one embedding or LLM call made while serving a tenant's tool call
+type Usage struct {
+	TenantID     string
+	Tool         string
+	Operation    string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	// CostUSD is computed from the price table when zero
+	CostUSD   float64
+	Timestamp time.Time
+}
+
+// Recorder records cost metrics
+type Recorder interface {
+	RecordCost(ctx context.Context, tenantID, tool, operation, model string, costUSD float64)
+}
+
+// Totals sums the calls, tokens and estimated cost of a set of usages
+type Totals struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+func (t *Totals) add(other Totals) {
+	t.Calls += other.Calls
+	t.InputTokens += other.InputTokens
+	t.OutputTokens += other.OutputTokens
+	t.CostUSD += other.CostUSD
+}
+
+// Line is the totals of one tool, model or day in a report
+type Line struct {
+	Key string `json:"key"`
+	Totals
+}
+
+// Report is a tenant's cost over a period, in total and broken down by tool, model and day
+type Report struct {
+	TenantID string    `json:"tenant_id"`
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	Total    Totals    `json:"total"`
+	ByTool   []Line    `json:"by_tool"`
+	ByModel  []Line    `json:"by_model"`
+	ByDay    []Line    `json:"by_day"`
+}
+
+// bucketKey identifies the daily totals of a tenant's tool, operation and model
+type bucketKey struct {
+	day       time.Time
+	tenantID  string
+	tool      string
+	operation string
+	model     string
+}
+
+// Tracker attributes the estimated cost of embedding and LLM calls to tenants and tools.
+// Totals are kept in memory per UTC day for the retention period, so each server
+// instance reports the calls it made itself.
+type Tracker struct {
+	prices    PriceTable
+	retention time.Duration
+	metrics   Recorder
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*Totals
+}
+
+// NewTracker creates a tracker pricing calls with prices and keeping daily totals for
+// retention; zero retention means DefaultRetention
+func NewTracker(prices PriceTable, retention time.Duration) *Tracker {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Tracker{
+		prices:    prices,
+		retention: retention,
+		now:       time.Now,
+		buckets:   make(map[bucketKey]*Totals),
+	}
+}
+
+// SetRecorder records the cost of every call as a metric
+func (t *Tracker) SetRecorder(recorder Recorder) {
+	t.metrics = recorder
+}
+
+// Record adds a call to the tenant's totals and returns it with its cost and timestamp
+func (t *Tracker) Record(ctx context.Context, usage Usage) Usage {
+	if usage.Timestamp.IsZero() {
+		usage.Timestamp = t.now()
+	}
+	if usage.CostUSD == 0 {
+		usage.CostUSD = t.prices.Cost(usage.Model, usage.InputTokens, usage.OutputTokens)
+	}
+
+	key := bucketKey{
+		day:       day(usage.Timestamp),
+		tenantID:  usage.TenantID,
+		tool:      usage.Tool,
+		operation: usage.Operation,
+		model:     usage.Model,
+	}
+	t.mu.Lock()
+	totals, ok := t.buckets[key]
+	if !ok {
+		totals = &Totals{}
+		t.buckets[key] = totals
+		t.pruneLocked()
+	}
+	totals.add(Totals{Calls: 1, InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens, CostUSD: usage.CostUSD})
+	t.mu.Unlock()
+
+	if t.metrics != nil {
+		t.metrics.RecordCost(ctx, usage.TenantID, usage.Tool, usage.Operation, usage.Model, usage.CostUSD)
+	}
+	return usage
+}
+
+// pruneLocked drops the totals of days past the retention period
+func (t *Tracker) pruneLocked() {
+	oldest := day(t.now().Add(-t.retention))
+	for key := range t.buckets {
+		if key.day.Before(oldest) {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+// Report returns a tenant's cost for the UTC days from since through until
+func (t *Tracker) Report(tenantID string, since, until time.Time) Report {
+	report := Report{
+		TenantID: tenantID,
+		Since:    since,
+		Until:    until,
+		ByTool:   []Line{},
+		ByModel:  []Line{},
+		ByDay:    []Line{},
+	}
+	first, last := day(since), day(until)
+	byTool := make(map[string]*Totals)
+	byModel := make(map[string]*Totals)
+	byDay := make(map[string]*Totals)
+
+	t.mu.Lock()
+	for key, totals := range t.buckets {
+		if key.tenantID != tenantID || key.day.Before(first) || key.day.After(last) {
+			continue
+		}
+		report.Total.add(*totals)
+		addTo(byTool, key.tool, *totals)
+		addTo(byModel, key.model, *totals)
+		addTo(byDay, key.day.Format(time.DateOnly), *totals)
+	}
+	t.mu.Unlock()
+
+	report.ByTool = lines(byTool)
+	report.ByModel = lines(byModel)
+	report.ByDay = lines(byDay)
+	return report
+}
+
+// day returns the start of the UTC day of ts
+func day(ts time.Time) time.Time {
+	return ts.UTC().Truncate(24 * time.Hour)
+}
+
+func addTo(totals map[string]*Totals, key string, other Totals) {
+	if totals[key] == nil {
+		totals[key] = &Totals{}
+	}
+	totals[key].add(other)
+}
+
+// lines returns the totals sorted by key
+func lines(totals map[string]*Totals) []Line {
+	result := make([]Line, 0, len(totals))
+	for key, t := range totals {
+		result = append(result, Line{Key: key, Totals: *t})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
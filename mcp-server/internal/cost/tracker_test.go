@@ -0,0 +1,86 @@
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedCost is a cost metric recorded by fakeRecorder
+type recordedCost struct {
+	tenantID, tool, operation, model string
+	costUSD                          float64
+}
+
+type fakeRecorder struct {
+	costs []recordedCost
+}
+
+func (r *fakeRecorder) RecordCost(ctx context.Context, tenantID, tool, operation, model string, costUSD float64) {
+	r.costs = append(r.costs, recordedCost{tenantID, tool, operation, model, costUSD})
+}
+
+func TestPriceTableCost(t *testing.T) {
+	prices := DefaultPriceTable()
+	assert.InDelta(t, 0.15+0.60, prices.Cost("gpt-4o-mini", 1_000_000, 1_000_000), 1e-9)
+	assert.InDelta(t, 0.15, prices.Cost("gpt-4o-mini-2024-07-18", 1_000_000, 0), 1e-9, "versioned names use the longest prefix")
+	assert.InDelta(t, 2.50, prices.Cost("gpt-4o-2024-08-06", 1_000_000, 0), 1e-9)
+	assert.Zero(t, prices.Cost("llama3.1", 1_000_000, 1_000_000), "unpriced models are free")
+
+	prices["custom"] = ModelPrice{InputUSD: 0.01, OutputUSD: 0.02, Unit: UnitPer1K}
+	assert.InDelta(t, 0.03, prices.Cost("custom", 1000, 1000), 1e-9)
+	_, ok := builtinPrices["custom"]
+	assert.False(t, ok, "the default table is a copy")
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(DefaultPriceTable(), 7*24*time.Hour)
+	tracker.now = func() time.Time { return now }
+	recorder := &fakeRecorder{}
+	tracker.SetRecorder(recorder)
+	ctx := context.Background()
+
+	usage := tracker.Record(ctx, Usage{TenantID: "tenant-1", Tool: "answer_question", Operation: OperationCompletion, Model: "gpt-4o", InputTokens: 1000, OutputTokens: 200})
+	assert.InDelta(t, 0.0025+0.002, usage.CostUSD, 1e-9)
+	assert.Equal(t, now, usage.Timestamp)
+	tracker.Record(ctx, Usage{TenantID: "tenant-1", Tool: "answer_question", Operation: OperationEmbedding, Model: "text-embedding-3-small", InputTokens: 100, Timestamp: now.Add(-24 * time.Hour)})
+	tracker.Record(ctx, Usage{TenantID: "tenant-1", Tool: "hybrid_search", Operation: OperationEmbedding, Model: "text-embedding-3-small", InputTokens: 100})
+	tracker.Record(ctx, Usage{TenantID: "tenant-2", Tool: "answer_question", Operation: OperationCompletion, Model: "gpt-4o", InputTokens: 1000})
+
+	require.Len(t, recorder.costs, 4)
+	assert.Equal(t, recordedCost{"tenant-1", "answer_question", OperationCompletion, "gpt-4o", usage.CostUSD}, recorder.costs[0])
+
+	report := tracker.Report("tenant-1", now.Add(-48*time.Hour), now)
+	assert.Equal(t, 3, report.Total.Calls)
+	assert.Equal(t, 1200, report.Total.InputTokens)
+	assert.Equal(t, 200, report.Total.OutputTokens)
+	assert.InDelta(t, 0.0045+0.000004, report.Total.CostUSD, 1e-9)
+	assert.Equal(t, []string{"answer_question", "hybrid_search"}, keys(report.ByTool))
+	assert.Equal(t, 2, report.ByTool[0].Calls)
+	assert.Equal(t, []string{"gpt-4o", "text-embedding-3-small"}, keys(report.ByModel))
+	assert.Equal(t, []string{"2024-06-09", "2024-06-10"}, keys(report.ByDay))
+
+	report = tracker.Report("tenant-1", now, now)
+	assert.Equal(t, 2, report.Total.Calls, "only the days in the period are reported")
+	report = tracker.Report("tenant-3", now.Add(-48*time.Hour), now)
+	assert.Zero(t, report.Total.Calls)
+	assert.NotNil(t, report.ByTool)
+
+	// Days past the retention period are dropped when new totals are added
+	now = now.Add(8 * 24 * time.Hour)
+	tracker.Record(ctx, Usage{TenantID: "tenant-1", Tool: "hybrid_search", Operation: OperationEmbedding, Model: "text-embedding-3-small", InputTokens: 100})
+	report = tracker.Report("tenant-1", now.Add(-30*24*time.Hour), now)
+	assert.Equal(t, 1, report.Total.Calls)
+}
+
+func keys(lines []Line) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = line.Key
+	}
+	return result
+}
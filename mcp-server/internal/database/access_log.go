@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DocumentAccess represents a single read of a document by a user
+type DocumentAccess struct {
+	TenantID   string    `json:"tenant_id"`
+	DocumentID string    `json:"document_id"`
+	UserID     string    `json:"user_id"`
+	Tool       string    `json:"tool"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// AccessLogStore defines the storage operations for the document access log
+type AccessLogStore interface {
+	// RecordDocumentAccess stores a batch of access entries for a tenant
+	RecordDocumentAccess(ctx context.Context, tenantID string, entries []DocumentAccess) error
+
+	// ListAccessByDocument returns who read a document, most recent first
+	ListAccessByDocument(ctx context.Context, tenantID, docID string, limit, offset int) ([]DocumentAccess, error)
+
+	// ListAccessByUser returns what a user read, most recent first
+	ListAccessByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]DocumentAccess, error)
+}
+
+// Ensure DB implements AccessLogStore interface
+var _ AccessLogStore = (*DB)(nil)
+
+// RecordDocumentAccess inserts access log entries in a single transaction
+func (db *DB) RecordDocumentAccess(ctx context.Context, tenantID string, entries []DocumentAccess) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO document_access_log (tenant_id, document_id, user_id, tool, accessed_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for _, entry := range entries {
+		accessedAt := entry.AccessedAt
+		if accessedAt.IsZero() {
+			accessedAt = time.Now()
+		}
+		if _, err := tx.Exec(ctx, query, tenantID, entry.DocumentID, entry.UserID, entry.Tool, accessedAt); err != nil {
+			return fmt.Errorf("failed to record document access: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListAccessByDocument lists access log entries for a document
+func (db *DB) ListAccessByDocument(ctx context.Context, tenantID, docID string, limit, offset int) ([]DocumentAccess, error) {
+	query := `
+		SELECT tenant_id, document_id, COALESCE(user_id, ''), tool, accessed_at
+		FROM document_access_log
+		WHERE document_id = $1
+		ORDER BY accessed_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return db.listDocumentAccess(ctx, tenantID, query, docID, limit, offset)
+}
+
+// ListAccessByUser lists access log entries for a user
+func (db *DB) ListAccessByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]DocumentAccess, error) {
+	query := `
+		SELECT tenant_id, document_id, COALESCE(user_id, ''), tool, accessed_at
+		FROM document_access_log
+		WHERE user_id = $1
+		ORDER BY accessed_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return db.listDocumentAccess(ctx, tenantID, query, userID, limit, offset)
+}
+
+// listDocumentAccess runs an access log query filtered by a single key
+func (db *DB) listDocumentAccess(ctx context.Context, tenantID, query, key string, limit, offset int) ([]DocumentAccess, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, key, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query access log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []DocumentAccess
+	for rows.Next() {
+		var entry DocumentAccess
+		if err := rows.Scan(
+			&entry.TenantID,
+			&entry.DocumentID,
+			&entry.UserID,
+			&entry.Tool,
+			&entry.AccessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan access log entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditEntry represents a single audited tool call
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	UserID     string    `json:"user_id"`
+	Tool       string    `json:"tool"`
+	ArgsDigest string    `json:"args_digest"`
+	Status     string    `json:"status"`
+	LatencyMs  int64     `json:"latency_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditFilter selects audit entries; zero-valued fields match everything
+type AuditFilter struct {
+	UserID string
+	Tool   string
+	Status string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+// AuditLogStore defines the storage operations for the tool call audit log
+type AuditLogStore interface {
+	// RecordAuditEntries stores a batch of audit entries for a tenant
+	RecordAuditEntries(ctx context.Context, tenantID string, entries []AuditEntry) error
+
+	// ListAuditEntries returns a tenant's audit entries matching the filter, most recent first
+	ListAuditEntries(ctx context.Context, tenantID string, filter AuditFilter) ([]AuditEntry, error)
+
+	// PurgeAuditEntries deletes a tenant's audit entries created before the cutoff
+	PurgeAuditEntries(ctx context.Context, tenantID string, before time.Time) (int64, error)
+}
+
+// Ensure DB implements AuditLogStore interface
+var _ AuditLogStore = (*DB)(nil)
+
+// RecordAuditEntries inserts audit entries in a single transaction
+func (db *DB) RecordAuditEntries(ctx context.Context, tenantID string, entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO audit_log (tenant_id, user_id, tool, args_digest, status, latency_ms, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7)
+	`
+
+	for _, entry := range entries {
+		createdAt := entry.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		if _, err := tx.Exec(ctx, query, tenantID, entry.UserID, entry.Tool, entry.ArgsDigest,
+			entry.Status, entry.LatencyMs, createdAt); err != nil {
+			return fmt.Errorf("failed to record audit entry: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListAuditEntries lists a tenant's audit entries matching the filter
+func (db *DB) ListAuditEntries(ctx context.Context, tenantID string, filter AuditFilter) ([]AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != "" {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.Tool != "" {
+		add("tool = $%d", filter.Tool)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("created_at < $%d", filter.Until)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+		SELECT id, tenant_id, COALESCE(user_id, ''), tool, args_digest, status, latency_ms, created_at
+		FROM audit_log
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.TenantID,
+			&entry.UserID,
+			&entry.Tool,
+			&entry.ArgsDigest,
+			&entry.Status,
+			&entry.LatencyMs,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// PurgeAuditEntries deletes a tenant's audit entries older than the cutoff
+func (db *DB) PurgeAuditEntries(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM audit_log WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge audit log: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// SettingCollectionQuotas is the tenant setting holding per-collection document limits,
+// e.g. {"collection_max_documents": {"policies": 500, "tickets": 10000}}. Collections
+// without an entry are unlimited.
+const SettingCollectionQuotas = "collection_max_documents"
+
+// checkCollectionQuota returns a *storage.QuotaError when a collection already holds
+// as many documents as its quota allows. Inserts into the collection are serialized
+// until the transaction ends so concurrent inserts cannot overshoot the quota.
+func (db *DB) checkCollectionQuota(ctx context.Context, tx pgx.Tx, tenantID, collection string) error {
+	query := `
+		SELECT CASE WHEN jsonb_typeof(settings->$2::text->$3::text) = 'number'
+			THEN (settings->$2::text->>$3::text)::numeric::int END
+		FROM tenants WHERE id = $1
+	`
+
+	var quota *int
+	err := tx.QueryRow(ctx, query, tenantID, SettingCollectionQuotas, collection).Scan(&quota)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && quota == nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get collection quota: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1 || '/' || $2))`, tenantID, collection); err != nil {
+		return fmt.Errorf("failed to lock collection: %w", err)
+	}
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM documents WHERE collection = $1`, collection).Scan(&count); err != nil {
+		return fmt.Errorf("failed to count collection documents: %w", err)
+	}
+	if count >= *quota {
+		return &storage.QuotaError{
+			Quota:      storage.QuotaCollectionDocuments,
+			Collection: collection,
+			Limit:      int64(*quota),
+			Used:       int64(count),
+		}
+	}
+	return nil
+}
+
+// CollectionStore manages the registered collections of a tenant
+type CollectionStore interface {
+	// ListCollections returns a tenant's registered collections and those that only
+	// exist through their documents, sorted by name
+	ListCollections(ctx context.Context, tenantID string) ([]storage.Collection, error)
+
+	// GetCollection returns a collection, or storage.ErrNotFound when it is neither
+	// registered nor holds documents
+	GetCollection(ctx context.Context, tenantID, name string) (*storage.Collection, error)
+
+	// PutCollection registers a collection or updates its description and embedding
+	// settings. Dimensions that documents already in the collection do not have are
+	// rejected with storage.ErrEmbeddingDimensions.
+	PutCollection(ctx context.Context, tenantID string, collection storage.Collection) (*storage.Collection, error)
+
+	// DeleteCollection unregisters an empty collection, returning storage.ErrCollectionNotEmpty
+	// while it holds documents and storage.ErrNotFound when it is not registered
+	DeleteCollection(ctx context.Context, tenantID, name string) error
+}
+
+// Ensure DB implements CollectionStore interface
+var _ CollectionStore = (*DB)(nil)
+
+// collectionsSQL selects registered collections and those known from documents, with
+// their document counts. The caller appends conditions on name.
+const collectionsSQL = `
+	SELECT COALESCE(c.name, d.collection), COALESCE(c.description, ''), COALESCE(c.embedding_model, ''),
+		COALESCE(c.embedding_dimensions, 0), COALESCE(d.documents, 0), c.name IS NOT NULL,
+		c.created_at, c.updated_at
+	FROM collections c
+	FULL OUTER JOIN (
+		SELECT collection, COUNT(*) AS documents FROM documents GROUP BY collection
+	) d ON d.collection = c.name
+`
+
+func scanCollection(row pgx.Row) (*storage.Collection, error) {
+	var collection storage.Collection
+	err := row.Scan(&collection.Name, &collection.Description, &collection.EmbeddingModel,
+		&collection.EmbeddingDimensions, &collection.Documents, &collection.Registered,
+		&collection.CreatedAt, &collection.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// ListCollections returns the collections visible to the tenant
+func (db *DB) ListCollections(ctx context.Context, tenantID string) ([]storage.Collection, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, collectionsSQL+` ORDER BY 1`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []storage.Collection{}
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, *collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return collections, nil
+}
+
+// GetCollection returns a collection with its document count
+func (db *DB) GetCollection(ctx context.Context, tenantID, name string) (*storage.Collection, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	return getCollection(ctx, tx, storage.CollectionOrDefault(name))
+}
+
+func getCollection(ctx context.Context, tx pgx.Tx, name string) (*storage.Collection, error) {
+	collection, err := scanCollection(tx.QueryRow(ctx, collectionsSQL+` WHERE COALESCE(c.name, d.collection) = $1`, name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: collection %q", storage.ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection, nil
+}
+
+// PutCollection upserts a collection's registration
+func (db *DB) PutCollection(ctx context.Context, tenantID string, collection storage.Collection) (*storage.Collection, error) {
+	if err := collection.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if collection.EmbeddingDimensions > 0 {
+		if err := checkEmbeddingColumn(ctx, tx, collection.EmbeddingDimensions); err != nil {
+			return nil, err
+		}
+		var mismatched int64
+		query := `
+			SELECT COUNT(*) FROM documents
+			WHERE collection = $1 AND embedding IS NOT NULL AND vector_dims(embedding) <> $2
+		`
+		if err := tx.QueryRow(ctx, query, collection.Name, collection.EmbeddingDimensions).Scan(&mismatched); err != nil {
+			return nil, fmt.Errorf("failed to check collection embeddings: %w", err)
+		}
+		if mismatched > 0 {
+			return nil, fmt.Errorf("%w: %d documents of collection %q have embeddings of another size",
+				storage.ErrEmbeddingDimensions, mismatched, collection.Name)
+		}
+	}
+
+	query := `
+		INSERT INTO collections (tenant_id, name, description, embedding_model, embedding_dimensions)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, name) DO UPDATE
+		SET description = EXCLUDED.description,
+			embedding_model = EXCLUDED.embedding_model,
+			embedding_dimensions = EXCLUDED.embedding_dimensions,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err = tx.Exec(ctx, query, tenantID, collection.Name, collection.Description,
+		collection.EmbeddingModel, collection.EmbeddingDimensions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save collection: %w", err)
+	}
+
+	saved, err := getCollection(ctx, tx, collection.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// checkEmbeddingColumn rejects dimensions the documents table cannot store; the
+// embedding column is created as vector(1536)
+func checkEmbeddingColumn(ctx context.Context, tx pgx.Tx, dimensions int) error {
+	var columnDimensions int
+	query := `SELECT atttypmod FROM pg_attribute WHERE attrelid = 'documents'::regclass AND attname = 'embedding'`
+	if err := tx.QueryRow(ctx, query).Scan(&columnDimensions); err != nil {
+		return fmt.Errorf("failed to check embedding column: %w", err)
+	}
+	if columnDimensions > 0 && columnDimensions != dimensions {
+		return fmt.Errorf("%w: the documents table stores %d-dimension embeddings, got %d",
+			storage.ErrEmbeddingDimensions, columnDimensions, dimensions)
+	}
+	return nil
+}
+
+// DeleteCollection removes an empty collection's registration
+func (db *DB) DeleteCollection(ctx context.Context, tenantID, name string) error {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var documents int64
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM documents WHERE collection = $1`, name).Scan(&documents); err != nil {
+		return fmt.Errorf("failed to count collection documents: %w", err)
+	}
+	if documents > 0 {
+		return fmt.Errorf("%w: collection %q holds %d documents", storage.ErrCollectionNotEmpty, name, documents)
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM collections WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: collection %q", storage.ErrNotFound, name)
+	}
+	return tx.Commit(ctx)
+}
+
+// checkCollectionEmbedding returns storage.ErrEmbeddingDimensions when an embedding
+// written to or searched in a collection does not have its registered dimensions
+func checkCollectionEmbedding(ctx context.Context, tx pgx.Tx, name string, embedding []float32) error {
+	if embedding == nil {
+		return nil
+	}
+	collection := storage.Collection{Name: name}
+	query := `SELECT embedding_model, embedding_dimensions FROM collections WHERE name = $1`
+	err := tx.QueryRow(ctx, query, name).Scan(&collection.EmbeddingModel, &collection.EmbeddingDimensions)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection.CheckEmbedding(embedding)
+}
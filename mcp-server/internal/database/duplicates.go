@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// Duplicate resolution actions
+const (
+	// DuplicateMerge merges the duplicates' metadata into the kept document before archiving them
+	DuplicateMerge = "merge"
+	// DuplicateSoftDelete archives the duplicates
+	DuplicateSoftDelete = "soft_delete"
+)
+
+// DuplicateScan configures a duplicate scan of a tenant's documents
+type DuplicateScan struct {
+	Collection string  // Collection scanned; empty scans every collection
+	Threshold  float64 // Embedding cosine similarity at or above which documents are near-duplicates
+	Neighbors  int     // Nearest documents compared with each document
+}
+
+// DuplicateDocument identifies a document of a duplicate pair
+type DuplicateDocument struct {
+	ID         string    `json:"id"`
+	Collection string    `json:"collection"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DuplicatePair is two documents of a collection with the same title and content
+// (Exact) or with embeddings at least the scan threshold similar
+type DuplicatePair struct {
+	First      DuplicateDocument
+	Second     DuplicateDocument
+	Similarity float64 // 1 for exact duplicates
+	Exact      bool
+}
+
+// DuplicateStore finds duplicate documents and archives them. Archived documents leave
+// every search and listing but can be restored with their original ID.
+type DuplicateStore interface {
+	// FindDuplicates returns the pairs of exact and near-duplicate documents of a tenant
+	FindDuplicates(ctx context.Context, tenantID string, scan DuplicateScan) ([]DuplicatePair, error)
+
+	// ArchiveDuplicates moves duplicates of the kept document out of its collection into
+	// archived_documents; with DuplicateMerge their metadata is merged into the kept
+	// document's first (see storage.MergeMetadata). It returns storage.ErrNotFound, and
+	// changes nothing, unless the kept document and every duplicate are in the collection.
+	ArchiveDuplicates(ctx context.Context, tenantID, keepID string, duplicateIDs []string, action string) error
+
+	// RestoreDocuments moves archived documents back and returns the IDs restored.
+	// Restored documents are not counted against collection quotas.
+	RestoreDocuments(ctx context.Context, tenantID string, ids []string) ([]string, error)
+}
+
+// Ensure DB implements DuplicateStore
+var _ DuplicateStore = (*DB)(nil)
+
+// FindDuplicates pairs documents of a collection with the same content hash, and each
+// document with an embedding with its nearest neighbors at or above the threshold
+func (db *DB) FindDuplicates(ctx context.Context, tenantID string, scan DuplicateScan) ([]DuplicatePair, error) {
+	tx, err := db.BeginReadTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Every copy of a text is paired with its oldest copy
+	exactQuery := `
+		WITH copies AS (
+			SELECT id, collection, title, created_at,
+				first_value(id) OVER (PARTITION BY collection, content_hash ORDER BY created_at, id) AS first_id
+			FROM documents
+			WHERE $1 = '' OR collection = $1
+		)
+		SELECT f.id::text, f.collection, f.title, f.created_at, c.id::text, c.collection, c.title, c.created_at, 1.0, true
+		FROM copies c
+		JOIN documents f ON f.id = c.first_id
+		WHERE c.id <> c.first_id
+	`
+	pairs, err := scanDuplicatePairs(tx.Query(ctx, exactQuery, scan.Collection))
+	if err != nil {
+		return nil, err
+	}
+	if scan.Threshold <= 0 || scan.Neighbors <= 0 {
+		return pairs, nil
+	}
+
+	if err := tuneVectorSearch(ctx, tx, 0, 0); err != nil {
+		return nil, err
+	}
+	// Each pair is found from the document with the smaller ID; exact copies are already paired
+	nearQuery := `
+		SELECT d.id::text, d.collection, d.title, d.created_at, o.id::text, o.collection, o.title, o.created_at, n.score, false
+		FROM documents d
+		CROSS JOIN LATERAL (` + db.quantization.nearestSQL("d.embedding", "$2", " AND tenant_id = $4 AND collection = d.collection AND id <> d.id") + `
+		) n
+		JOIN documents o ON o.id = n.id
+		WHERE d.embedding IS NOT NULL
+			AND ($1 = '' OR d.collection = $1)
+			AND n.score >= $3
+			AND d.id < o.id
+			AND d.content_hash <> o.content_hash
+	`
+	near, err := scanDuplicatePairs(tx.Query(ctx, nearQuery, scan.Collection, scan.Neighbors, scan.Threshold, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return append(pairs, near...), nil
+}
+
+// scanDuplicatePairs reads the rows of a duplicate pair query
+func scanDuplicatePairs(rows pgx.Rows, err error) ([]DuplicatePair, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []DuplicatePair
+	for rows.Next() {
+		var pair DuplicatePair
+		err := rows.Scan(
+			&pair.First.ID, &pair.First.Collection, &pair.First.Title, &pair.First.CreatedAt,
+			&pair.Second.ID, &pair.Second.Collection, &pair.Second.Title, &pair.Second.CreatedAt,
+			&pair.Similarity, &pair.Exact,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	return pairs, nil
+}
+
+// ArchiveDuplicates implements DuplicateStore
+func (db *DB) ArchiveDuplicates(ctx context.Context, tenantID, keepID string, duplicateIDs []string, action string) error {
+	if action != DuplicateMerge && action != DuplicateSoftDelete {
+		return fmt.Errorf("action must be %s or %s, got %q", DuplicateMerge, DuplicateSoftDelete, action)
+	}
+
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var collection string
+	var metadata map[string]interface{}
+	err = tx.QueryRow(ctx, `SELECT collection, metadata FROM documents WHERE id = $1 FOR UPDATE`, keepID).Scan(&collection, &metadata)
+	if err == pgx.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
+	query := `
+		WITH moved AS (
+			DELETE FROM documents
+			WHERE id = ANY($1::uuid[]) AND id <> $2 AND collection = $3
+			RETURNING id, tenant_id, collection, title, content, metadata, embedding, embedding_hash, created_at, updated_at, created_by
+		)
+		INSERT INTO archived_documents (id, tenant_id, collection, title, content, metadata, embedding, embedding_hash,
+			created_at, updated_at, created_by, duplicate_of, reason)
+		SELECT id, tenant_id, collection, title, content, metadata, embedding, embedding_hash,
+			created_at, updated_at, created_by, $2, $4
+		FROM moved
+		RETURNING id::text, metadata
+	`
+	rows, err := tx.Query(ctx, query, duplicateIDs, keepID, collection, action)
+	if err != nil {
+		return fmt.Errorf("failed to archive duplicates: %w", err)
+	}
+	archived := make(map[string]map[string]interface{}, len(duplicateIDs))
+	for rows.Next() {
+		var id string
+		var duplicateMetadata map[string]interface{}
+		if err := rows.Scan(&id, &duplicateMetadata); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan archived duplicate: %w", err)
+		}
+		archived[id] = duplicateMetadata
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to archive duplicates: %w", err)
+	}
+	for _, id := range duplicateIDs {
+		if _, ok := archived[id]; !ok {
+			return fmt.Errorf("%w: duplicate %s is not in collection %q", storage.ErrNotFound, id, collection)
+		}
+	}
+
+	if action == DuplicateMerge {
+		duplicates := make([]map[string]interface{}, 0, len(duplicateIDs))
+		for _, id := range duplicateIDs {
+			duplicates = append(duplicates, archived[id])
+		}
+		merged := storage.MergeMetadata(metadata, duplicates...)
+		if _, err := tx.Exec(ctx, `UPDATE documents SET metadata = $1 WHERE id = $2`, merged, keepID); err != nil {
+			return fmt.Errorf("failed to merge metadata: %w", err)
+		}
+		if err := db.recordDocumentEvent(ctx, tx, tenantID, keepID, collection, DocumentUpdated); err != nil {
+			return err
+		}
+	}
+	for id := range archived {
+		if err := db.recordDocumentEvent(ctx, tx, tenantID, id, collection, DocumentDeleted); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if action == DuplicateMerge {
+		db.notifyDocumentChange(ctx, tenantID, keepID)
+	}
+	for id := range archived {
+		db.notifyDocumentChange(ctx, tenantID, id)
+	}
+	return nil
+}
+
+// RestoreDocuments implements DuplicateStore
+func (db *DB) RestoreDocuments(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		WITH restored AS (
+			DELETE FROM archived_documents
+			WHERE id = ANY($1::uuid[])
+			RETURNING id, tenant_id, collection, title, content, metadata, embedding, embedding_hash, created_at, updated_at, created_by
+		)
+		INSERT INTO documents (id, tenant_id, collection, title, content, metadata, embedding, embedding_hash,
+			created_at, updated_at, created_by)
+		SELECT id, tenant_id, collection, title, content, metadata, embedding, embedding_hash,
+			created_at, updated_at, created_by
+		FROM restored
+		RETURNING id::text, collection
+	`
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore documents: %w", err)
+	}
+	restored := make(map[string]string, len(ids)) // ID -> collection
+	for rows.Next() {
+		var id, collection string
+		if err := rows.Scan(&id, &collection); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan restored document: %w", err)
+		}
+		restored[id] = collection
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to restore documents: %w", err)
+	}
+
+	restoredIDs := make([]string, 0, len(restored))
+	for _, id := range ids {
+		collection, ok := restored[id]
+		if !ok {
+			continue
+		}
+		delete(restored, id) // IDs may repeat
+		if err := db.quantizeDocument(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		if err := db.recordDocumentEvent(ctx, tx, tenantID, id, collection, DocumentCreated); err != nil {
+			return nil, err
+		}
+		restoredIDs = append(restoredIDs, id)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, id := range restoredIDs {
+		db.notifyDocumentChange(ctx, tenantID, id)
+	}
+	return restoredIDs, nil
+}
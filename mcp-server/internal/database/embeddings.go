@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// EmbeddingStatus summarizes how well a tenant's embeddings match their documents
+type EmbeddingStatus struct {
+	TenantID  string `json:"tenant_id"`
+	Documents int    `json:"documents"`
+	Missing   int    `json:"missing"` // Documents without an embedding
+	Stale     int    `json:"stale"`   // Documents whose content changed after their embedding was generated
+	Flagged   int    `json:"flagged"` // Stale documents newly flagged and queued by this check
+	// Requantized counts documents whose quantized embeddings this check backfilled
+	Requantized int `json:"requantized,omitempty"`
+}
+
+// EmbeddingStore detects embeddings that no longer match their document content.
+// Every document stores the hash of its title and content (content_hash) and the hash
+// the embedding was generated from (embedding_hash); a mismatch means the embedding is stale.
+type EmbeddingStore interface {
+	// CheckEmbeddings flags stale documents, queues them for re-embedding and
+	// returns the tenant's embedding status
+	CheckEmbeddings(ctx context.Context, tenantID string) (*EmbeddingStatus, error)
+}
+
+// TenantLister lists the tenants served by a database
+type TenantLister interface {
+	// ListActiveTenants returns the IDs of all active tenants
+	ListActiveTenants(ctx context.Context) ([]string, error)
+}
+
+// Ensure DB implements EmbeddingStore and TenantLister
+var (
+	_ EmbeddingStore = (*DB)(nil)
+	_ TenantLister   = (*DB)(nil)
+)
+
+// CheckEmbeddings flags documents whose content changed after their embedding was
+// generated and adds them to the embedding queue in a single transaction. With
+// quantization dual-write enabled it also backfills the quantized embeddings.
+func (db *DB) CheckEmbeddings(ctx context.Context, tenantID string) (*EmbeddingStatus, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	status := &EmbeddingStatus{TenantID: tenantID}
+
+	// Documents re-embedded or reverted since they were flagged are no longer stale
+	clearQuery := `
+		WITH cleared AS (
+			UPDATE documents
+			SET embedding_stale_at = NULL
+			WHERE embedding_stale_at IS NOT NULL
+				AND (embedding IS NULL OR embedding_hash = content_hash)
+			RETURNING id
+		)
+		DELETE FROM embedding_queue WHERE document_id IN (SELECT id FROM cleared)
+	`
+	if _, err := tx.Exec(ctx, clearQuery); err != nil {
+		return nil, fmt.Errorf("failed to clear re-embedded documents: %w", err)
+	}
+
+	flagQuery := `
+		WITH flagged AS (
+			UPDATE documents
+			SET embedding_stale_at = CURRENT_TIMESTAMP
+			WHERE embedding IS NOT NULL
+				AND embedding_hash IS DISTINCT FROM content_hash
+				AND embedding_stale_at IS NULL
+			RETURNING id, tenant_id
+		)
+		INSERT INTO embedding_queue (document_id, tenant_id)
+		SELECT id, tenant_id FROM flagged
+		ON CONFLICT (document_id) DO NOTHING
+	`
+	result, err := tx.Exec(ctx, flagQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag stale embeddings: %w", err)
+	}
+	status.Flagged = int(result.RowsAffected())
+
+	// With dual-write on, the check also backfills quantized embeddings
+	if status.Requantized, err = db.requantize(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	countQuery := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE embedding IS NULL),
+			COUNT(*) FILTER (WHERE embedding IS NOT NULL AND embedding_hash IS DISTINCT FROM content_hash)
+		FROM documents
+	`
+	if err := tx.QueryRow(ctx, countQuery).Scan(&status.Documents, &status.Missing, &status.Stale); err != nil {
+		return nil, fmt.Errorf("failed to count stale embeddings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ListActiveTenants returns the IDs of all active tenants
+func (db *DB) ListActiveTenants(ctx context.Context) ([]string, error) {
+	rows, err := db.pool.Query(ctx, `SELECT id::text FROM tenants WHERE is_active = true ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenantIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenantIDs = append(tenantIDs, id)
+	}
+	return tenantIDs, rows.Err()
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// metadataFilterSQL renders a metadata filter as conditions on the documents table,
+// numbering placeholders from next. Each key becomes OR-ed JSONB containment tests against
+// {"key": value} and {"key": [value]}, so scalar metadata must equal one of the values and
+// array metadata must contain one. Containment (@>) can use the GIN index on metadata.
+// The returned SQL is empty for an empty filter and otherwise starts with " AND ".
+func metadataFilterSQL(filter storage.MetadataFilter, next int) (string, []interface{}, error) {
+	var sql strings.Builder
+	var args []interface{}
+
+	for _, key := range filter.Keys() {
+		var conditions []string
+		for _, value := range filter[key] {
+			scalar, err := json.Marshal(map[string]interface{}{key: value})
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid filter %q: %w", key, err)
+			}
+			array, err := json.Marshal(map[string]interface{}{key: []interface{}{value}})
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid filter %q: %w", key, err)
+			}
+			for _, candidate := range [][]byte{scalar, array} {
+				conditions = append(conditions, fmt.Sprintf("metadata @> $%d::jsonb", next+len(args)))
+				args = append(args, string(candidate))
+			}
+		}
+		sql.WriteString(" AND (" + strings.Join(conditions, " OR ") + ")")
+	}
+
+	return sql.String(), args, nil
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFilterSQL(t *testing.T) {
+	sql, args, err := metadataFilterSQL(nil, 3)
+	require.NoError(t, err)
+	assert.Empty(t, sql)
+	assert.Empty(t, args)
+
+	sql, args, err = metadataFilterSQL(storage.MetadataFilter{
+		"department": {"eng", "ops"},
+		"category":   {"security"},
+	}, 3)
+	require.NoError(t, err)
+
+	// Keys are rendered in sorted order so the statement text is stable
+	assert.Equal(t,
+		" AND (metadata @> $3::jsonb OR metadata @> $4::jsonb)"+
+			" AND (metadata @> $5::jsonb OR metadata @> $6::jsonb OR metadata @> $7::jsonb OR metadata @> $8::jsonb)",
+		sql)
+	assert.Equal(t, []interface{}{
+		`{"category":"security"}`, `{"category":["security"]}`,
+		`{"department":"eng"}`, `{"department":["eng"]}`,
+		`{"department":"ops"}`, `{"department":["ops"]}`,
+	}, args)
+}
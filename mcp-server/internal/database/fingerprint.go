@@ -0,0 +1,44 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	sqlLineComment  = regexp.MustCompile(`--[^\n]*`)
+	sqlBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	sqlString       = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlParam        = regexp.MustCompile(`\$\d+`)
+	sqlNumber       = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlValueList    = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	sqlWhitespace   = regexp.MustCompile(`\s+`)
+)
+
+// NormalizeSQL strips comments and literals from a statement so queries that differ
+// only in their values share a fingerprint. String and numeric literals and bind
+// parameters become "?", value lists collapse to "(?)" and whitespace is collapsed.
+func NormalizeSQL(sql string) string {
+	normalized := sqlBlockComment.ReplaceAllString(sql, " ")
+	normalized = sqlLineComment.ReplaceAllString(normalized, " ")
+	normalized = sqlString.ReplaceAllString(normalized, "?")
+	normalized = sqlParam.ReplaceAllString(normalized, "?")
+	normalized = sqlNumber.ReplaceAllString(normalized, "?")
+	normalized = sqlValueList.ReplaceAllString(normalized, "(?)")
+	normalized = sqlWhitespace.ReplaceAllString(normalized, " ")
+	return strings.TrimSpace(normalized)
+}
+
+// Fingerprint returns a short stable identifier for a normalized statement
+func Fingerprint(normalizedSQL string) string {
+	sum := sha256.Sum256([]byte(normalizedSQL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// sqlOperation returns the leading keyword of a statement (SELECT, INSERT, WITH, ...)
+func sqlOperation(normalizedSQL string) string {
+	operation, _, _ := strings.Cut(normalizedSQL, " ")
+	return strings.ToUpper(operation)
+}
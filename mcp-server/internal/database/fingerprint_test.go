@@ -0,0 +1,137 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/shared/telemetry"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "bind parameters",
+			sql:      "SELECT id FROM documents WHERE id = $1 LIMIT $2",
+			expected: "SELECT id FROM documents WHERE id = ? LIMIT ?",
+		},
+		{
+			name:     "string literal",
+			sql:      "SET LOCAL app.current_tenant_id = '11111111-1111-1111-1111-111111111111'",
+			expected: "SET LOCAL app.current_tenant_id = ?",
+		},
+		{
+			name:     "escaped quote and numbers",
+			sql:      "SELECT * FROM t WHERE name = 'O''Brien' AND score > 0.75 LIMIT 10",
+			expected: "SELECT * FROM t WHERE name = ? AND score > ? LIMIT ?",
+		},
+		{
+			name:     "value list collapses",
+			sql:      "SELECT * FROM t WHERE id IN (1, 2, 3)",
+			expected: "SELECT * FROM t WHERE id IN (?)",
+		},
+		{
+			name:     "comments and whitespace",
+			sql:      "\n\t\tSELECT id -- primary key\n\t\tFROM t1 /* table */\n",
+			expected: "SELECT id FROM t1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeSQL(tt.sql))
+		})
+	}
+}
+
+func TestFingerprint_IgnoresLiterals(t *testing.T) {
+	a := Fingerprint(NormalizeSQL("SET LOCAL statement_timeout = 100"))
+	b := Fingerprint(NormalizeSQL("SET LOCAL  statement_timeout = 2500"))
+	c := Fingerprint(NormalizeSQL("SET LOCAL app.current_tenant_id = 'x'"))
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 16)
+}
+
+func TestQueryTracer_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewQueryTracer(&observability.Telemetry{Telemetry: telemetry.Telemetry{Tracer: provider.Tracer("test")}})
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL: "SELECT id FROM documents WHERE title = 'secret' LIMIT $1",
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 3")})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "DELETE FROM documents WHERE id = $1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("permission denied")})
+
+	tracer.TraceAcquire(context.Background(), PrimaryPool, time.Now().Add(-5*time.Millisecond), nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	attrs := make(map[string]interface{})
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	assert.Equal(t, "db.query SELECT", spans[0].Name())
+	assert.Equal(t, "SELECT id FROM documents WHERE title = ? LIMIT ?", attrs["db.statement"])
+	assert.NotContains(t, attrs["db.statement"], "secret")
+	assert.Equal(t, int64(3), attrs["db.rows_affected"])
+	assert.NotEmpty(t, attrs["db.fingerprint"])
+
+	assert.Equal(t, "db.query DELETE", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	assert.Equal(t, "db.pool.acquire", spans[2].Name())
+	assert.GreaterOrEqual(t, spans[2].EndTime().Sub(spans[2].StartTime()), 5*time.Millisecond)
+}
+
+func TestQueryTracer_SlowQueryLog(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	tracer := NewQueryTracer(&observability.Telemetry{})
+	query := func(sql string, delay time.Duration) {
+		ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+		time.Sleep(delay)
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 2")})
+	}
+
+	// Zero disables the log
+	query("UPDATE documents SET title = 'secret' WHERE id = $1", time.Millisecond)
+	assert.Empty(t, logs.String())
+
+	tracer.SetSlowQueryThreshold(5 * time.Millisecond)
+	query("SELECT 1", 0)
+	assert.Empty(t, logs.String(), "fast queries are not logged")
+
+	query("UPDATE documents SET title = 'secret' WHERE id = $1", 10*time.Millisecond)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "Slow query", entry["msg"])
+	assert.Equal(t, "UPDATE", entry["operation"])
+	assert.Equal(t, "UPDATE documents SET title = ? WHERE id = ?", entry["statement"])
+	assert.NotContains(t, logs.String(), "secret")
+	assert.GreaterOrEqual(t, entry["duration_ms"], 10.0)
+	assert.Equal(t, 2.0, entry["rows"])
+}
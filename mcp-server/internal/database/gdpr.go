@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// UsageRecord represents a row in the usage_logs table
+type UsageRecord struct {
+	ID               string                 `json:"id"`
+	TenantID         string                 `json:"tenant_id"`
+	UserID           string                 `json:"user_id"`
+	Operation        string                 `json:"operation"`
+	Model            string                 `json:"model,omitempty"`
+	PromptTokens     int                    `json:"prompt_tokens"`
+	CompletionTokens int                    `json:"completion_tokens"`
+	TotalTokens      int                    `json:"total_tokens"`
+	CostUSD          float64                `json:"cost_usd"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+// UserData holds all data associated with a user within a tenant
+type UserData struct {
+	Documents    []*storage.Document `json:"documents"`
+	UsageRecords []UsageRecord       `json:"usage_records"`
+	AccessLog    []DocumentAccess    `json:"access_log"`
+	AuditLog     []AuditEntry        `json:"audit_log"`
+}
+
+// UserErasure reports how many rows were affected by a user data erasure
+type UserErasure struct {
+	DocumentIDs      []string `json:"document_ids"`
+	DocumentsDeleted int      `json:"documents_deleted"`
+	DocumentsUpdated int      `json:"documents_anonymized"`
+	UsageRecords     int      `json:"usage_records"`
+	AccessLogEntries int      `json:"access_log_entries"`
+	AuditLogEntries  int      `json:"audit_log_entries"`
+}
+
+// UserDataStore defines the storage operations used for data subject requests
+type UserDataStore interface {
+	// ExportUserData returns all rows associated with a user
+	ExportUserData(ctx context.Context, tenantID, userID string) (*UserData, error)
+
+	// EraseUserData deletes or pseudonymizes all rows associated with a user
+	EraseUserData(ctx context.Context, tenantID, userID, pseudonym string) (*UserErasure, error)
+}
+
+// Ensure DB implements UserDataStore interface
+var _ UserDataStore = (*DB)(nil)
+
+// ExportUserData collects every row associated with a user in a tenant
+func (db *DB) ExportUserData(ctx context.Context, tenantID, userID string) (*UserData, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	data := &UserData{
+		Documents:    []*storage.Document{},
+		UsageRecords: []UsageRecord{},
+		AccessLog:    []DocumentAccess{},
+		AuditLog:     []AuditEntry{},
+	}
+
+	docRows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, title, content, metadata, created_at, updated_at, created_by
+		FROM documents
+		WHERE created_by = $1
+		ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export documents: %w", err)
+	}
+	for docRows.Next() {
+		doc := &storage.Document{}
+		if err := docRows.Scan(
+			&doc.ID,
+			&doc.TenantID,
+			&doc.Title,
+			&doc.Content,
+			&doc.Metadata,
+			&doc.CreatedAt,
+			&doc.UpdatedAt,
+			&doc.CreatedBy,
+		); err != nil {
+			docRows.Close()
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		data.Documents = append(data.Documents, doc)
+	}
+	docRows.Close()
+
+	// usage_logs has no RLS policy, so filter by tenant explicitly
+	usageRows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, COALESCE(user_id, ''), operation, COALESCE(model, ''),
+			prompt_tokens, completion_tokens, total_tokens, cost_usd::float8, metadata, created_at
+		FROM usage_logs
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY created_at
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export usage records: %w", err)
+	}
+	for usageRows.Next() {
+		var record UsageRecord
+		if err := usageRows.Scan(
+			&record.ID,
+			&record.TenantID,
+			&record.UserID,
+			&record.Operation,
+			&record.Model,
+			&record.PromptTokens,
+			&record.CompletionTokens,
+			&record.TotalTokens,
+			&record.CostUSD,
+			&record.Metadata,
+			&record.CreatedAt,
+		); err != nil {
+			usageRows.Close()
+			return nil, fmt.Errorf("failed to scan usage record: %w", err)
+		}
+		data.UsageRecords = append(data.UsageRecords, record)
+	}
+	usageRows.Close()
+
+	accessRows, err := tx.Query(ctx, `
+		SELECT tenant_id, document_id, COALESCE(user_id, ''), tool, accessed_at
+		FROM document_access_log
+		WHERE user_id = $1
+		ORDER BY accessed_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export access log: %w", err)
+	}
+	for accessRows.Next() {
+		var entry DocumentAccess
+		if err := accessRows.Scan(
+			&entry.TenantID,
+			&entry.DocumentID,
+			&entry.UserID,
+			&entry.Tool,
+			&entry.AccessedAt,
+		); err != nil {
+			accessRows.Close()
+			return nil, fmt.Errorf("failed to scan access log entry: %w", err)
+		}
+		data.AccessLog = append(data.AccessLog, entry)
+	}
+	accessRows.Close()
+	if err := accessRows.Err(); err != nil {
+		return nil, err
+	}
+
+	auditRows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, COALESCE(user_id, ''), tool, args_digest, status, latency_ms, created_at
+		FROM audit_log
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export audit log: %w", err)
+	}
+	defer auditRows.Close()
+	for auditRows.Next() {
+		var entry AuditEntry
+		if err := auditRows.Scan(
+			&entry.ID,
+			&entry.TenantID,
+			&entry.UserID,
+			&entry.Tool,
+			&entry.ArgsDigest,
+			&entry.Status,
+			&entry.LatencyMs,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		data.AuditLog = append(data.AuditLog, entry)
+	}
+
+	return data, auditRows.Err()
+}
+
+// EraseUserData removes a user's data from a tenant in a single transaction.
+// If pseudonym is empty, the user's documents and records are deleted;
+// otherwise the user ID is replaced by the pseudonym and documents are kept.
+func (db *DB) EraseUserData(ctx context.Context, tenantID, userID, pseudonym string) (*UserErasure, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	erasure := &UserErasure{DocumentIDs: []string{}}
+
+	var docQuery, eventType string
+	var docArgs []interface{}
+	if pseudonym == "" {
+		docQuery = `DELETE FROM documents WHERE created_by = $1 RETURNING id, collection`
+		docArgs = []interface{}{userID}
+		eventType = DocumentDeleted
+	} else {
+		docQuery = `UPDATE documents SET created_by = $2 WHERE created_by = $1 RETURNING id, collection`
+		docArgs = []interface{}{userID, pseudonym}
+		eventType = DocumentUpdated
+	}
+
+	rows, err := tx.Query(ctx, docQuery, docArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase documents: %w", err)
+	}
+	var collections []string
+	for rows.Next() {
+		var id, collection string
+		if err := rows.Scan(&id, &collection); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan erased document: %w", err)
+		}
+		erasure.DocumentIDs = append(erasure.DocumentIDs, id)
+		collections = append(collections, collection)
+	}
+	rows.Close()
+	for i, id := range erasure.DocumentIDs {
+		if err := db.recordDocumentEvent(ctx, tx, tenantID, id, collections[i], eventType); err != nil {
+			return nil, err
+		}
+	}
+	if pseudonym == "" {
+		erasure.DocumentsDeleted = len(erasure.DocumentIDs)
+	} else {
+		erasure.DocumentsUpdated = len(erasure.DocumentIDs)
+	}
+
+	var usageQuery, accessQuery, auditQuery string
+	var usageArgs, userArgs []interface{}
+	if pseudonym == "" {
+		usageQuery = `DELETE FROM usage_logs WHERE tenant_id = $1 AND user_id = $2`
+		usageArgs = []interface{}{tenantID, userID}
+		accessQuery = `DELETE FROM document_access_log WHERE user_id = $1`
+		auditQuery = `DELETE FROM audit_log WHERE user_id = $1`
+		userArgs = []interface{}{userID}
+	} else {
+		usageQuery = `UPDATE usage_logs SET user_id = $3 WHERE tenant_id = $1 AND user_id = $2`
+		usageArgs = []interface{}{tenantID, userID, pseudonym}
+		accessQuery = `UPDATE document_access_log SET user_id = $2 WHERE user_id = $1`
+		auditQuery = `UPDATE audit_log SET user_id = $2 WHERE user_id = $1`
+		userArgs = []interface{}{userID, pseudonym}
+	}
+
+	usageResult, err := tx.Exec(ctx, usageQuery, usageArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase usage records: %w", err)
+	}
+	erasure.UsageRecords = int(usageResult.RowsAffected())
+
+	accessResult, err := tx.Exec(ctx, accessQuery, userArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase access log: %w", err)
+	}
+	erasure.AccessLogEntries = int(accessResult.RowsAffected())
+
+	// audit_log is scoped to the tenant by its RLS policy, like document_access_log
+	auditResult, err := tx.Exec(ctx, auditQuery, userArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase audit log: %w", err)
+	}
+	erasure.AuditLogEntries = int(auditResult.RowsAffected())
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, docID := range erasure.DocumentIDs {
+		db.notifyDocumentChange(ctx, tenantID, docID)
+	}
+
+	return erasure, nil
+}
@@ -0,0 +1,24 @@
+package database
+
+import "context"
+
+// DocumentChangeHook is called after a document is inserted, updated, or deleted
+type DocumentChangeHook func(ctx context.Context, tenantID, docID string)
+
+// AddDocumentChangeHook registers a hook that runs after document writes commit
+func (db *DB) AddDocumentChangeHook(hook DocumentChangeHook) {
+	db.hooksMu.Lock()
+	defer db.hooksMu.Unlock()
+	db.changeHooks = append(db.changeHooks, hook)
+}
+
+// notifyDocumentChange runs all registered change hooks
+func (db *DB) notifyDocumentChange(ctx context.Context, tenantID, docID string) {
+	db.hooksMu.RLock()
+	hooks := db.changeHooks
+	db.hooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(ctx, tenantID, docID)
+	}
+}
@@ -3,124 +3,90 @@ package database
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/jackc/pgx/v5"
 	"github.com/pgvector/pgvector-go"
 )
 
-// HybridSearchParams holds parameters for hybrid search
-type HybridSearchParams struct {
-	Query         string
-	Embedding     []float32
-	Limit         int
-	BM25Weight    float64 // Weight for lexical search (0.0 to 1.0)
-	VectorWeight  float64 // Weight for semantic search (0.0 to 1.0)
-	MinBM25Score  float64 // Minimum BM25 score threshold
-	MinVectorSim  float64 // Minimum vector similarity threshold
-}
-
-// HybridSearchResult represents a result from hybrid search
-type HybridSearchResult struct {
-	Document      Document
-	BM25Score     float64
-	VectorScore   float64
-	CombinedScore float64
+// hybridCandidatePool returns how many documents each ranking contributes to fusion
+func hybridCandidatePool(limit int) int {
+	if limit <= 0 {
+		limit = 10
+	}
+	if pool := limit * 5; pool > 50 {
+		return pool
+	}
+	return 50
 }
 
 // HybridSearch performs a hybrid search combining BM25 (full-text) and vector similarity
-// This implements a Reciprocal Rank Fusion (RRF) approach for combining results
-func (db *DB) HybridSearch(ctx context.Context, tenantID string, params HybridSearchParams) ([]HybridSearchResult, error) {
-	tx, err := db.BeginTx(ctx, tenantID)
+// The top candidates of each ranking are fetched and combined with params.Fusion
+// (Reciprocal Rank Fusion by default)
+func (db *DB) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	fusion, err := storage.NewFusion(params.Fusion, params.RRFK)
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback(ctx)
 
-	// Normalize weights if they don't sum to 1.0
-	totalWeight := params.BM25Weight + params.VectorWeight
-	if totalWeight == 0 {
-		params.BM25Weight = 0.5
-		params.VectorWeight = 0.5
-		totalWeight = 1.0
+	filterSQL, filterArgs, err := metadataFilterSQL(params.Filters, 6)
+	if err != nil {
+		return nil, err
 	}
-	bm25Weight := params.BM25Weight / totalWeight
-	vectorWeight := params.VectorWeight / totalWeight
 
-	if params.Limit <= 0 {
-		params.Limit = 10
+	tx, err := db.BeginReadTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tuneVectorSearch(ctx, tx, params.EfSearch, params.Probes); err != nil {
+		return nil, err
+	}
+	if err := checkCollectionEmbedding(ctx, tx, storage.CollectionOrDefault(params.Collection), params.Embedding); err != nil {
+		return nil, err
+	}
+	tsquery, tsqueryArg, err := lexicalQuery(ctx, tx, params)
+	if err != nil {
+		return nil, err
 	}
 
-	// Hybrid search query using PostgreSQL's full-text search (BM25-like) and pgvector
-	// We use ts_rank_cd which implements a ranking similar to BM25
+	// Fetch the top candidates of the full-text (BM25-like ts_rank_cd) and pgvector
+	// rankings with their raw scores; fusion happens in Go so that any method can be used.
+	// The vector ranking names the tenant so a collection's partial vector index applies.
 	query := `
 		WITH bm25_results AS (
 			SELECT
 				id,
-				tenant_id,
-				title,
-				content,
-				metadata,
-				embedding,
-				created_at,
-				updated_at,
-				created_by,
 				ts_rank_cd(
 					to_tsvector('english', title || ' ' || content),
-					plainto_tsquery('english', $1)
-				) AS bm25_score,
-				ROW_NUMBER() OVER (ORDER BY ts_rank_cd(
-					to_tsvector('english', title || ' ' || content),
-					plainto_tsquery('english', $1)
-				) DESC) AS bm25_rank
+					` + tsquery + `
+				) AS bm25_score
 			FROM documents
-			WHERE to_tsvector('english', title || ' ' || content) @@ plainto_tsquery('english', $1)
+			WHERE collection = $4
+				AND to_tsvector('english', title || ' ' || content) @@ ` + tsquery + filterSQL + `
+			ORDER BY bm25_score DESC
+			LIMIT $3
 		),
 		vector_results AS (
-			SELECT
-				id,
-				tenant_id,
-				title,
-				content,
-				metadata,
-				embedding,
-				created_at,
-				updated_at,
-				created_by,
-				1 - (embedding <=> $2) AS vector_score,
-				ROW_NUMBER() OVER (ORDER BY embedding <=> $2) AS vector_rank
-			FROM documents
-			WHERE embedding IS NOT NULL
+			SELECT id, score AS vector_score
+			FROM (` + db.quantization.nearestSQL("$2", "$3", " AND $2::vector IS NOT NULL AND tenant_id = $5 AND collection = $4"+filterSQL) + `
+			) nearest
 		),
-		combined AS (
-			SELECT
-				COALESCE(b.id, v.id) AS id,
-				COALESCE(b.tenant_id, v.tenant_id) AS tenant_id,
-				COALESCE(b.title, v.title) AS title,
-				COALESCE(b.content, v.content) AS content,
-				COALESCE(b.metadata, v.metadata) AS metadata,
-				COALESCE(b.embedding, v.embedding) AS embedding,
-				COALESCE(b.created_at, v.created_at) AS created_at,
-				COALESCE(b.updated_at, v.updated_at) AS updated_at,
-				COALESCE(b.created_by, v.created_by) AS created_by,
-				COALESCE(b.bm25_score, 0) AS bm25_score,
-				COALESCE(v.vector_score, 0) AS vector_score,
-				-- Reciprocal Rank Fusion score
-				(
-					COALESCE(1.0 / (60 + b.bm25_rank), 0) * $3 +
-					COALESCE(1.0 / (60 + v.vector_rank), 0) * $4
-				) AS combined_score
-			FROM bm25_results b
-			FULL OUTER JOIN vector_results v ON b.id = v.id
-			WHERE
-				COALESCE(b.bm25_score, 0) >= $5
-				OR COALESCE(v.vector_score, 0) >= $6
+		candidates AS (
+			SELECT id FROM bm25_results
+			UNION
+			SELECT id FROM vector_results
 		)
 		SELECT
-			id, tenant_id, title, content, metadata, embedding,
-			created_at, updated_at, created_by,
-			bm25_score, vector_score, combined_score
-		FROM combined
-		ORDER BY combined_score DESC
-		LIMIT $7
+			d.id, d.tenant_id, d.collection, d.title, d.content, d.metadata, d.embedding,
+			d.created_at, d.updated_at, d.created_by,
+			b.bm25_score, v.vector_score
+		FROM candidates c
+		JOIN documents d ON d.id = c.id
+		LEFT JOIN bm25_results b ON b.id = c.id
+		LEFT JOIN vector_results v ON v.id = c.id
 	`
 
 	var embedding interface{}
@@ -128,29 +94,30 @@ func (db *DB) HybridSearch(ctx context.Context, tenantID string, params HybridSe
 		embedding = pgvector.NewVector(params.Embedding)
 	}
 
-	rows, err := tx.Query(ctx, query,
-		params.Query,
+	args := append([]interface{}{
+		tsqueryArg,
 		embedding,
-		bm25Weight,
-		vectorWeight,
-		params.MinBM25Score,
-		params.MinVectorSim,
-		params.Limit,
-	)
+		hybridCandidatePool(params.Limit),
+		storage.CollectionOrDefault(params.Collection),
+		tenantID,
+	}, filterArgs...)
+
+	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform hybrid search: %w", err)
 	}
 	defer rows.Close()
 
-	var results []HybridSearchResult
+	var lexical, vector []storage.ScoredDocument
 	for rows.Next() {
-		var doc Document
-		var bm25Score, vectorScore, combinedScore float64
-		var dbEmbedding *pgvector.Vector // Use pointer to handle NULL
+		doc := &storage.Document{}
+		var bm25Score, vectorScore *float64 // NULL when the document is missing from a ranking
+		var dbEmbedding *pgvector.Vector    // Use pointer to handle NULL
 
 		err := rows.Scan(
 			&doc.ID,
 			&doc.TenantID,
+			&doc.Collection,
 			&doc.Title,
 			&doc.Content,
 			&doc.Metadata,
@@ -160,7 +127,6 @@ func (db *DB) HybridSearch(ctx context.Context, tenantID string, params HybridSe
 			&doc.CreatedBy,
 			&bm25Score,
 			&vectorScore,
-			&combinedScore,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan hybrid search result: %w", err)
@@ -170,26 +136,57 @@ func (db *DB) HybridSearch(ctx context.Context, tenantID string, params HybridSe
 			doc.Embedding = dbEmbedding.Slice()
 		}
 
-		results = append(results, HybridSearchResult{
-			Document:      doc,
-			BM25Score:     bm25Score,
-			VectorScore:   vectorScore,
-			CombinedScore: combinedScore,
-		})
+		if bm25Score != nil {
+			lexical = append(lexical, storage.ScoredDocument{Document: doc, Score: *bm25Score})
+		}
+		if vectorScore != nil {
+			vector = append(vector, storage.ScoredDocument{Document: doc, Score: *vectorScore})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hybrid search results: %w", err)
 	}
 
-	return results, nil
+	sortByScore(lexical)
+	sortByScore(vector)
+	return storage.FuseRankings(fusion, lexical, vector, params), nil
+}
+
+// sortByScore orders a ranking best first, breaking ties by document ID
+func sortByScore(ranking []storage.ScoredDocument) {
+	sort.SliceStable(ranking, func(i, j int) bool {
+		if ranking[i].Score != ranking[j].Score {
+			return ranking[i].Score > ranking[j].Score
+		}
+		return ranking[i].Document.ID < ranking[j].Document.ID
+	})
 }
 
 // SimpleHybridSearch performs a simpler version of hybrid search
 // Uses weighted average of BM25 and vector similarity scores
-func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params HybridSearchParams) ([]HybridSearchResult, error) {
-	tx, err := db.BeginTx(ctx, tenantID)
+func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	filterSQL, filterArgs, err := metadataFilterSQL(params.Filters, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginReadTx(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	if err := tuneVectorSearch(ctx, tx, params.EfSearch, params.Probes); err != nil {
+		return nil, err
+	}
+	if err := checkCollectionEmbedding(ctx, tx, storage.CollectionOrDefault(params.Collection), params.Embedding); err != nil {
+		return nil, err
+	}
+	tsquery, tsqueryArg, err := lexicalQuery(ctx, tx, params)
+	if err != nil {
+		return nil, err
+	}
+
 	// Normalize weights
 	totalWeight := params.BM25Weight + params.VectorWeight
 	if totalWeight == 0 {
@@ -207,11 +204,11 @@ func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params Hy
 	// Simpler hybrid query using weighted scores
 	query := `
 		SELECT
-			id, tenant_id, title, content, metadata, embedding,
+			id, tenant_id, collection, title, content, metadata, embedding,
 			created_at, updated_at, created_by,
 			ts_rank_cd(
 				to_tsvector('english', title || ' ' || content),
-				plainto_tsquery('english', $1)
+				` + tsquery + `
 			) AS bm25_score,
 			CASE
 				WHEN embedding IS NOT NULL THEN 1 - (embedding <=> $2)
@@ -220,7 +217,7 @@ func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params Hy
 			(
 				ts_rank_cd(
 					to_tsvector('english', title || ' ' || content),
-					plainto_tsquery('english', $1)
+					` + tsquery + `
 				) * $3 +
 				CASE
 					WHEN embedding IS NOT NULL THEN (1 - (embedding <=> $2)) * $4
@@ -228,9 +225,10 @@ func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params Hy
 				END
 			) AS combined_score
 		FROM documents
-		WHERE
-			to_tsvector('english', title || ' ' || content) @@ plainto_tsquery('english', $1)
+		WHERE collection = $7 AND (
+			to_tsvector('english', title || ' ' || content) @@ ` + tsquery + `
 			OR (embedding IS NOT NULL AND (1 - (embedding <=> $2)) >= $6)
+		)` + filterSQL + `
 		ORDER BY combined_score DESC
 		LIMIT $5
 	`
@@ -240,28 +238,32 @@ func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params Hy
 		embedding = pgvector.NewVector(params.Embedding)
 	}
 
-	rows, err := tx.Query(ctx, query,
-		params.Query,
+	args := append([]interface{}{
+		tsqueryArg,
 		embedding,
 		bm25Weight,
 		vectorWeight,
 		params.Limit,
 		params.MinVectorSim,
-	)
+		storage.CollectionOrDefault(params.Collection),
+	}, filterArgs...)
+
+	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform simple hybrid search: %w", err)
 	}
 	defer rows.Close()
 
-	var results []HybridSearchResult
+	var results []storage.HybridSearchResult
 	for rows.Next() {
-		var doc Document
+		var doc storage.Document
 		var bm25Score, vectorScore, combinedScore float64
 		var dbEmbedding *pgvector.Vector // Use pointer to handle NULL
 
 		err := rows.Scan(
 			&doc.ID,
 			&doc.TenantID,
+			&doc.Collection,
 			&doc.Title,
 			&doc.Content,
 			&doc.Metadata,
@@ -281,7 +283,7 @@ func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params Hy
 			doc.Embedding = dbEmbedding.Slice()
 		}
 
-		results = append(results, HybridSearchResult{
+		results = append(results, storage.HybridSearchResult{
 			Document:      doc,
 			BM25Score:     bm25Score,
 			VectorScore:   vectorScore,
@@ -291,3 +293,62 @@ func (db *DB) SimpleHybridSearch(ctx context.Context, tenantID string, params Hy
 
 	return results, nil
 }
+
+// lexicalQuery returns the tsquery expression of a hybrid search's lexical ranking and
+// its $1 argument: plainto_tsquery of the query, or to_tsquery of the query after the
+// requested spelling correction and synonym expansion
+func lexicalQuery(ctx context.Context, tx pgx.Tx, params storage.HybridSearchParams) (string, string, error) {
+	if !params.CorrectSpelling && len(params.Synonyms) == 0 {
+		return "plainto_tsquery('english', $1)", params.Query, nil
+	}
+
+	query := storage.ParseLexicalQuery(params.Query)
+	if params.CorrectSpelling {
+		corrections, err := correctSpelling(ctx, tx, storage.CollectionOrDefault(params.Collection), query.CorrectableWords())
+		if err != nil {
+			return "", "", err
+		}
+		query = query.Correct(corrections)
+	}
+	return "to_tsquery('english', $1)", query.ExpandSynonyms(params.Synonyms).TSQuery(), nil
+}
+
+// correctSpelling maps the words missing from a collection's vocabulary (search_terms) to
+// the most similar word by trigram similarity, preferring words in more documents. A word
+// in the vocabulary is its own most similar word and stays as it is.
+func correctSpelling(ctx context.Context, tx pgx.Tx, collection string, words []string) (map[string]string, error) {
+	corrections := make(map[string]string)
+	if len(words) == 0 {
+		return corrections, nil
+	}
+
+	query := `
+		SELECT w.word, c.term
+		FROM unnest($1::text[]) AS w(word)
+		CROSS JOIN LATERAL (
+			SELECT term
+			FROM search_terms
+			WHERE collection = $2 AND term % w.word
+			ORDER BY similarity(term, w.word) DESC, ndoc DESC, term
+			LIMIT 1
+		) c
+		WHERE c.term <> w.word
+	`
+	rows, err := tx.Query(ctx, query, words, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to correct query spelling: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var word, correction string
+		if err := rows.Scan(&word, &correction); err != nil {
+			return nil, fmt.Errorf("failed to scan spelling correction: %w", err)
+		}
+		corrections[word] = correction
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to correct query spelling: %w", err)
+	}
+	return corrections, nil
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Document lifecycle event types
+const (
+	DocumentCreated = "created"
+	DocumentUpdated = "updated"
+	DocumentDeleted = "deleted"
+)
+
+// DocumentEvent is a document change recorded in the outbox (see scripts/apply-document-outbox.sql).
+// IDs increase per database, so consumers can drop events they have already seen.
+type DocumentEvent struct {
+	ID         int64     `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	DocumentID string    `json:"document_id"`
+	Collection string    `json:"collection"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// OutboxStore relays document events recorded in the outbox
+type OutboxStore interface {
+	// RelayDocumentEvents passes up to limit of the tenant's unpublished events, oldest
+	// first, to publish and marks them published once it returns nil. Events are locked
+	// while they are published, so concurrent relays never publish the same batch, but an
+	// event is published again if the relay stops before marking it: delivery is at least once.
+	RelayDocumentEvents(ctx context.Context, tenantID string, limit int, publish func([]DocumentEvent) error) (int, error)
+	// PurgeDocumentEvents deletes the tenant's events published before the cutoff
+	PurgeDocumentEvents(ctx context.Context, tenantID string, before time.Time) (int64, error)
+}
+
+// Ensure DB implements OutboxStore
+var _ OutboxStore = (*DB)(nil)
+
+// recordDocumentEvent adds an event to the outbox within the transaction that changed
+// the document, so the event exists exactly when the change commits
+func (db *DB) recordDocumentEvent(ctx context.Context, tx pgx.Tx, tenantID, docID, collection, eventType string) error {
+	if !db.outbox {
+		return nil
+	}
+	query := `
+		INSERT INTO document_events (tenant_id, document_id, collection, event_type)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(ctx, query, tenantID, docID, collection, eventType); err != nil {
+		return fmt.Errorf("failed to record document event: %w", err)
+	}
+	return nil
+}
+
+// RelayDocumentEvents implements OutboxStore
+func (db *DB) RelayDocumentEvents(ctx context.Context, tenantID string, limit int, publish func([]DocumentEvent) error) (int, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT id, tenant_id::text, document_id::text, collection, event_type, occurred_at
+		FROM document_events
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read document events: %w", err)
+	}
+	var events []DocumentEvent
+	for rows.Next() {
+		var event DocumentEvent
+		if err := rows.Scan(&event.ID, &event.TenantID, &event.DocumentID, &event.Collection, &event.Type, &event.OccurredAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan document event: %w", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read document events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(events); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	if _, err := tx.Exec(ctx, `UPDATE document_events SET published_at = CURRENT_TIMESTAMP WHERE id = ANY($1)`, ids); err != nil {
+		return 0, fmt.Errorf("failed to mark document events published: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// PurgeDocumentEvents implements OutboxStore
+func (db *DB) PurgeDocumentEvents(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM document_events WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge document events: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
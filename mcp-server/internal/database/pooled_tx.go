@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pooledTx is a transaction on an explicitly acquired connection.
+// The connection is returned to the pool when the transaction ends.
+type pooledTx struct {
+	pgx.Tx
+	conn        *pgxpool.Conn
+	releaseOnce sync.Once
+}
+
+// begin starts a read-write transaction on the primary
+func (db *DB) begin(ctx context.Context) (pgx.Tx, error) {
+	return db.beginOn(ctx, PrimaryPool, db.pool, pgx.TxOptions{})
+}
+
+// beginOn acquires a connection from pool and starts a transaction on it
+func (db *DB) beginOn(ctx context.Context, name string, pool *pgxpool.Pool, opts pgx.TxOptions) (pgx.Tx, error) {
+	conn, err := db.acquire(ctx, name, pool)
+	if err != nil {
+		return nil, err
+	}
+	return beginPooled(ctx, conn, opts)
+}
+
+// acquire takes a connection from the named pool, timing the wait for the tracer. Acquires
+// from the primary pool go through the circuit breaker, which fails them fast with a
+// *resilience.OpenError while the database is down; replicas fall back on their own.
+func (db *DB) acquire(ctx context.Context, name string, pool *pgxpool.Pool) (*pgxpool.Conn, error) {
+	var conn *pgxpool.Conn
+	acquire := func(ctx context.Context) error {
+		start := time.Now()
+		var err error
+		conn, err = pool.Acquire(ctx)
+		db.tracer.TraceAcquire(ctx, name, start, err)
+		return err
+	}
+	var err error
+	if name == PrimaryPool {
+		err = db.breaker.Do(ctx, acquire)
+	} else {
+		err = acquire(ctx)
+	}
+	return conn, err
+}
+
+// beginPooled starts a transaction on conn, releasing the connection if that fails
+func beginPooled(ctx context.Context, conn *pgxpool.Conn, opts pgx.TxOptions) (pgx.Tx, error) {
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+
+	return &pooledTx{Tx: tx, conn: conn}, nil
+}
+
+// Commit commits the transaction and releases the connection
+func (t *pooledTx) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(ctx)
+	t.release()
+	return err
+}
+
+// Rollback rolls back the transaction and releases the connection
+func (t *pooledTx) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(ctx)
+	t.release()
+	return err
+}
+
+func (t *pooledTx) release() {
+	t.releaseOnce.Do(t.conn.Release)
+}
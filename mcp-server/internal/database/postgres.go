@@ -3,51 +3,137 @@ package database
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resilience"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pgvector/pgvector-go"
 )
 
 // Config holds database configuration
 type Config struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
-	MaxConns int32
-	MinConns int32
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"dbname"`
+	SSLMode  string `yaml:"sslmode"`
+	MaxConns int32  `yaml:"max_conns"`
+	MinConns int32  `yaml:"min_conns"`
+	// Tracer, if set, traces every statement and pool acquire
+	Tracer *QueryTracer `yaml:"-"`
+	// SlowQueryThreshold is the duration over which the server's Tracer logs a statement;
+	// zero disables the slow query log
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+	// Quantization controls the halfvec and bit embedding columns
+	Quantization QuantizationConfig `yaml:"quantization"`
+	// Outbox records document lifecycle events in document_events for the outbox relay
+	// (see scripts/apply-document-outbox.sql)
+	Outbox bool `yaml:"outbox"`
+	// StatementCache controls statement preparation and caching per connection
+	StatementCache StatementCacheConfig `yaml:"statement_cache"`
+	// SessionReads runs document fetches, listings and text searches as session reads
+	// rather than in read-only transactions; see DB.readSession
+	SessionReads bool `yaml:"session_reads"`
+	// Replicas serve read-only queries, falling back to this database when none is available
+	Replicas []ReplicaConfig `yaml:"replicas"`
+	// AutoMigrate applies pending schema migrations at startup
+	AutoMigrate bool `yaml:"auto_migrate"`
+	// MigrationUser and MigrationPassword, when set, are the schema owner's credentials
+	// that migrations run with; the application user may lack the privileges
+	MigrationUser     string `yaml:"migration_user"`
+	MigrationPassword string `yaml:"migration_password"`
 }
 
 // DB represents the database connection pool
 type DB struct {
-	pool *pgxpool.Pool
-}
-
-// Document represents a document with embeddings
-type Document struct {
-	ID        string                 `json:"id"`
-	TenantID  string                 `json:"tenant_id"`
-	Title     string                 `json:"title"`
-	Content   string                 `json:"content"`
-	Metadata  map[string]interface{} `json:"metadata"`
-	Embedding []float32              `json:"embedding,omitempty"`
-	CreatedAt time.Time              `json:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at"`
-	CreatedBy *string                `json:"created_by,omitempty"` // Use pointer to handle NULL
+	pool         *pgxpool.Pool
+	addr         string
+	tracer       *QueryTracer
+	quantization QuantizationConfig
+	outbox       bool
+
+	replicas    []*replica
+	nextReplica atomic.Uint64
+	sessions    *sessionConns
+	// sessionReads makes openRead use session reads
+	sessionReads bool
+	breaker      *resilience.Breaker
+
+	hooksMu     sync.RWMutex
+	changeHooks []DocumentChangeHook
 }
 
 // SearchResult represents a document with similarity score
 type SearchResult struct {
-	Document Document
+	Document storage.Document
 	Score    float64
 }
 
-// NewDB creates a new database connection pool
+// NewDB creates a new database connection pool, plus one per configured read replica
 func NewDB(ctx context.Context, cfg Config) (*DB, error) {
+	sessions := &sessionConns{}
+	poolConfig, err := newPoolConfig(cfg, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+
+	// Test connection
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	replicas, err := newReplicas(ctx, cfg, sessions)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return &DB{
+		pool:         pool,
+		addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		tracer:       cfg.Tracer,
+		quantization: cfg.Quantization,
+		outbox:       cfg.Outbox,
+		replicas:     replicas,
+		sessions:     sessions,
+		sessionReads: cfg.SessionReads,
+	}, nil
+}
+
+// ConnectForMigrations opens a single connection for schema migrations, as the migration
+// user when one is configured
+func ConnectForMigrations(ctx context.Context, cfg Config) (*pgx.Conn, error) {
+	user, password := cfg.User, cfg.Password
+	if cfg.MigrationUser != "" {
+		user, password = cfg.MigrationUser, cfg.MigrationPassword
+	}
+	conn, err := pgx.Connect(ctx, fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, user, password, cfg.DBName, cfg.SSLMode,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect for migrations: %w", err)
+	}
+	return conn, nil
+}
+
+// newPoolConfig builds the pool configuration for the database at cfg.Host and cfg.Port.
+// Connections used for session reads are reset through sessions before they are reused.
+func newPoolConfig(cfg Config, sessions *sessionConns) (*pgxpool.Config, error) {
 	connString := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s pool_max_conns=%d pool_min_conns=%d",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode, cfg.MaxConns, cfg.MinConns,
@@ -64,6 +150,12 @@ func NewDB(ctx context.Context, cfg Config) (*DB, error) {
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 	poolConfig.HealthCheckPeriod = 1 * time.Minute
+	cfg.StatementCache.apply(poolConfig.ConnConfig)
+	if cfg.Tracer != nil {
+		poolConfig.ConnConfig.Tracer = cfg.Tracer
+	}
+
+	poolConfig.AfterRelease = sessions.afterRelease
 
 	// Register pgvector type
 	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
@@ -71,31 +163,57 @@ func NewDB(ctx context.Context, cfg Config) (*DB, error) {
 		return nil
 	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
-	}
-
-	// Test connection
-	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
+	return poolConfig, nil
+}
 
-	return &DB{pool: pool}, nil
+// SetBreaker guards transactions on the primary with breaker, failing them fast while
+// the database is down
+func (db *DB) SetBreaker(breaker *resilience.Breaker) {
+	db.breaker = breaker
 }
 
-// Close closes the database connection pool
+// Close closes the primary and replica connection pools
 func (db *DB) Close() {
 	db.pool.Close()
+	closeReplicas(db.replicas)
+}
+
+// Ping checks that the primary database answers. Replicas are not pinged since reads
+// fall back to the primary while they are down.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.pool.Ping(ctx)
+}
+
+// execer runs a statement on a connection or in a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+// setLocal sets configuration parameters, given as name/value pairs, for the rest of a
+// transaction in one round trip. SET takes no bind parameters, so set_config is used and
+// no value is ever spliced into the SQL.
+func setLocal(ctx context.Context, tx pgx.Tx, settings ...string) error {
+	return setConfig(ctx, tx, true, settings...)
 }
 
-// SetTenantContext sets the tenant ID for row-level security
+// setConfig sets configuration parameters, given as name/value pairs, for the rest of the
+// transaction when local is true and for the session otherwise
+func setConfig(ctx context.Context, conn execer, local bool, settings ...string) error {
+	calls := make([]string, 0, len(settings)/2)
+	args := make([]interface{}, 0, len(settings)+1)
+	args = append(args, local)
+	for i := 0; i+1 < len(settings); i += 2 {
+		calls = append(calls, fmt.Sprintf("set_config($%d, $%d, $1)", i+2, i+3))
+		args = append(args, settings[i], settings[i+1])
+	}
+	_, err := conn.Exec(ctx, "SELECT "+strings.Join(calls, ", "), args...)
+	return err
+}
+
+// SetTenantContext sets the tenant ID for row-level security. The ID is bound as a
+// parameter; the policies cast it to a UUID, so a malformed ID matches no rows.
 func (db *DB) SetTenantContext(ctx context.Context, tx pgx.Tx, tenantID string) error {
-	// Note: SET commands don't support parameter binding ($1), so we use fmt.Sprintf
-	// The tenantID is validated to be a UUID by the JWT validator, so this is safe
-	query := fmt.Sprintf("SET LOCAL app.current_tenant_id = '%s'", tenantID)
-	_, err := tx.Exec(ctx, query)
-	if err != nil {
+	if err := setLocal(ctx, tx, "app.current_tenant_id", tenantID); err != nil {
 		return fmt.Errorf("failed to set tenant context: %w", err)
 	}
 	return nil
@@ -103,30 +221,62 @@ func (db *DB) SetTenantContext(ctx context.Context, tx pgx.Tx, tenantID string)
 
 // BeginTx starts a new transaction with tenant context
 func (db *DB) BeginTx(ctx context.Context, tenantID string) (pgx.Tx, error) {
-	tx, err := db.pool.Begin(ctx)
+	tx, err := db.begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-
-	if err := db.SetTenantContext(ctx, tx, tenantID); err != nil {
+	if err := db.setTxContext(ctx, tx, tenantID); err != nil {
 		tx.Rollback(ctx)
 		return nil, err
 	}
-
 	return tx, nil
 }
 
+// setTxContext sets the tenant for row-level security and the statement timeout for a new transaction
+func (db *DB) setTxContext(ctx context.Context, tx pgx.Tx, tenantID string) error {
+	if err := setLocal(ctx, tx, tenantSettings(ctx, tenantID)...); err != nil {
+		return fmt.Errorf("failed to set tenant context: %w", err)
+	}
+	return nil
+}
+
+// tenantSettings returns the tenant for row-level security and, when ctx has a deadline,
+// a statement timeout bounding server-side execution by it, so Postgres stops working on
+// a query whose caller has already given up
+func tenantSettings(ctx context.Context, tenantID string) []string {
+	settings := []string{"app.current_tenant_id", tenantID}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline).Milliseconds()
+		if remaining < 1 {
+			remaining = 1
+		}
+		settings = append(settings, "statement_timeout", strconv.FormatInt(remaining, 10))
+	}
+	return settings
+}
+
 // InsertDocument inserts a new document
-func (db *DB) InsertDocument(ctx context.Context, tenantID string, doc *Document) error {
+func (db *DB) InsertDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
 	tx, err := db.BeginTx(ctx, tenantID)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
+	doc.Collection = storage.CollectionOrDefault(doc.Collection)
+	if err := db.checkTenantQuota(ctx, tx, tenantID, doc); err != nil {
+		return err
+	}
+	if err := db.checkCollectionQuota(ctx, tx, tenantID, doc.Collection); err != nil {
+		return err
+	}
+	if err := checkCollectionEmbedding(ctx, tx, doc.Collection, doc.Embedding); err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO documents (tenant_id, title, content, metadata, embedding, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO documents (tenant_id, title, content, metadata, embedding, created_by, embedding_hash, collection)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $5::vector IS NULL THEN NULL ELSE md5($2 || E'\n' || $3) END, $7)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -142,35 +292,46 @@ func (db *DB) InsertDocument(ctx context.Context, tenantID string, doc *Document
 		doc.Metadata,
 		embedding,
 		doc.CreatedBy,
+		doc.Collection,
 	).Scan(&doc.ID, &doc.CreatedAt, &doc.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert document: %w", err)
 	}
+	if err := db.quantizeDocument(ctx, tx, doc.ID); err != nil {
+		return err
+	}
+	if err := db.recordDocumentEvent(ctx, tx, tenantID, doc.ID, doc.Collection, DocumentCreated); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
 
-	return tx.Commit(ctx)
+	db.notifyDocumentChange(ctx, tenantID, doc.ID)
+	return nil
 }
 
+// getDocumentSQL is constant so the statement is prepared once per connection
+const getDocumentSQL = `SELECT id, tenant_id, collection, title, content, metadata, embedding, created_at, updated_at, created_by
+	FROM documents WHERE id = $1`
+
 // GetDocument retrieves a document by ID
-func (db *DB) GetDocument(ctx context.Context, tenantID, docID string) (*Document, error) {
-	tx, err := db.BeginTx(ctx, tenantID)
+func (db *DB) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
+	conn, done, err := db.openRead(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback(ctx)
+	defer done()
 
-	query := `
-		SELECT id, tenant_id, title, content, metadata, embedding, created_at, updated_at, created_by
-		FROM documents
-		WHERE id = $1
-	`
-
-	doc := &Document{}
+	doc := &storage.Document{}
 	var embedding *pgvector.Vector // Use pointer to handle NULL
 
-	err = tx.QueryRow(ctx, query, docID).Scan(
+	err = conn.QueryRow(ctx, getDocumentSQL, docID).Scan(
 		&doc.ID,
 		&doc.TenantID,
+		&doc.Collection,
 		&doc.Title,
 		&doc.Content,
 		&doc.Metadata,
@@ -181,7 +342,7 @@ func (db *DB) GetDocument(ctx context.Context, tenantID, docID string) (*Documen
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("document not found")
+		return nil, storage.ErrNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get document: %w", err)
@@ -195,38 +356,36 @@ func (db *DB) GetDocument(ctx context.Context, tenantID, docID string) (*Documen
 	return doc, nil
 }
 
-// SearchDocuments performs a text search on documents
-func (db *DB) SearchDocuments(ctx context.Context, tenantID, query string, limit int) ([]*Document, error) {
-	tx, err := db.BeginTx(ctx, tenantID)
-	if err != nil {
+// SearchDocuments performs a text search on the documents of a collection matching the metadata filter
+func (db *DB) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	var q queryBuilder
+	pattern := q.arg("%" + query + "%")
+	q.write("SELECT ", documentColumns, " FROM documents WHERE collection = ", q.arg(storage.CollectionOrDefault(collection)),
+		" AND (title ILIKE ", pattern, " OR content ILIKE ", pattern, " OR metadata::text ILIKE ", pattern, ")")
+	if err := q.metadataFilter(filter); err != nil {
 		return nil, err
 	}
-	defer tx.Rollback(ctx)
+	q.write(" ORDER BY created_at DESC LIMIT ", q.arg(limit))
 
-	searchQuery := `
-		SELECT id, tenant_id, title, content, metadata, created_at, updated_at, created_by
-		FROM documents
-		WHERE
-			title ILIKE $1 OR
-			content ILIKE $1 OR
-			metadata::text ILIKE $1
-		ORDER BY created_at DESC
-		LIMIT $2
-	`
+	conn, done, err := db.openRead(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 
-	searchPattern := "%" + query + "%"
-	rows, err := tx.Query(ctx, searchQuery, searchPattern, limit)
+	rows, err := conn.Query(ctx, q.String(), q.Args()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search documents: %w", err)
 	}
 	defer rows.Close()
 
-	var documents []*Document
+	var documents []*storage.Document
 	for rows.Next() {
-		doc := &Document{}
+		doc := &storage.Document{}
 		err := rows.Scan(
 			&doc.ID,
 			&doc.TenantID,
+			&doc.Collection,
 			&doc.Title,
 			&doc.Content,
 			&doc.Metadata,
@@ -243,26 +402,34 @@ func (db *DB) SearchDocuments(ctx context.Context, tenantID, query string, limit
 	return documents, nil
 }
 
-// VectorSearch performs similarity search using pgvector
-func (db *DB) VectorSearch(ctx context.Context, tenantID string, embedding []float32, limit int) ([]SearchResult, error) {
-	tx, err := db.BeginTx(ctx, tenantID)
+// VectorSearch performs similarity search within a collection using pgvector, through the
+// quantized index with exact re-ranking when quantized search is configured
+func (db *DB) VectorSearch(ctx context.Context, tenantID, collection string, embedding []float32, limit int) ([]SearchResult, error) {
+	tx, err := db.BeginReadTx(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	if err := tuneVectorSearch(ctx, tx, 0, 0); err != nil {
+		return nil, err
+	}
+	if err := checkCollectionEmbedding(ctx, tx, storage.CollectionOrDefault(collection), embedding); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT
-			id, tenant_id, title, content, metadata, embedding, created_at, updated_at, created_by,
-			1 - (embedding <=> $1) AS similarity_score
-		FROM documents
-		WHERE embedding IS NOT NULL
-		ORDER BY embedding <=> $1
-		LIMIT $2
+			d.id, d.tenant_id, d.collection, d.title, d.content, d.metadata, d.embedding, d.created_at, d.updated_at, d.created_by,
+			n.score AS similarity_score
+		FROM (` + db.quantization.nearestSQL("$1", "$2", " AND tenant_id = $4 AND collection = $3") + `
+		) n
+		JOIN documents d ON d.id = n.id
+		ORDER BY n.score DESC
 	`
 
 	vec := pgvector.NewVector(embedding)
-	rows, err := tx.Query(ctx, query, vec, limit)
+	rows, err := tx.Query(ctx, query, vec, limit, storage.CollectionOrDefault(collection), tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform vector search: %w", err)
 	}
@@ -270,13 +437,14 @@ func (db *DB) VectorSearch(ctx context.Context, tenantID string, embedding []flo
 
 	var results []SearchResult
 	for rows.Next() {
-		doc := &Document{}
+		doc := &storage.Document{}
 		var score float64
 		var dbEmbedding pgvector.Vector
 
 		err := rows.Scan(
 			&doc.ID,
 			&doc.TenantID,
+			&doc.Collection,
 			&doc.Title,
 			&doc.Content,
 			&doc.Metadata,
@@ -300,33 +468,119 @@ func (db *DB) VectorSearch(ctx context.Context, tenantID string, embedding []flo
 	return results, nil
 }
 
-// ListDocuments lists all documents for a tenant
-func (db *DB) ListDocuments(ctx context.Context, tenantID string, limit, offset int) ([]*Document, error) {
-	tx, err := db.BeginTx(ctx, tenantID)
+// SimilarDocuments returns the documents of a document's collection nearest to its
+// embedding, through the quantized index when quantized search is configured
+func (db *DB) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	filterSQL, filterArgs, err := metadataFilterSQL(filter, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginReadTx(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	var collection string
+	var embedding *pgvector.Vector // NULL when the document has no embedding
+	err = tx.QueryRow(ctx, `SELECT collection, embedding FROM documents WHERE id = $1`, docID).Scan(&collection, &embedding)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	if embedding == nil || len(embedding.Slice()) == 0 {
+		return nil, storage.ErrNoEmbedding
+	}
+
+	if err := tuneVectorSearch(ctx, tx, 0, 0); err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, tenant_id, title, content, metadata, created_at, updated_at, created_by
+		SELECT
+			d.id, d.tenant_id, d.collection, d.title, d.content, d.metadata, d.embedding, d.created_at, d.updated_at, d.created_by,
+			n.score AS similarity_score
+		FROM (` + db.quantization.nearestSQL("$1", "$2", " AND tenant_id = $4 AND collection = $3 AND id <> $5"+filterSQL) + `
+		) n
+		JOIN documents d ON d.id = n.id
+		ORDER BY n.score DESC, d.id
+	`
+
+	args := append([]interface{}{*embedding, limit, collection, tenantID, docID}, filterArgs...)
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar documents: %w", err)
+	}
+	defer rows.Close()
+
+	var results []storage.ScoredDocument
+	for rows.Next() {
+		doc := &storage.Document{}
+		var score float64
+		var dbEmbedding pgvector.Vector
+
+		err := rows.Scan(
+			&doc.ID,
+			&doc.TenantID,
+			&doc.Collection,
+			&doc.Title,
+			&doc.Content,
+			&doc.Metadata,
+			&dbEmbedding,
+			&doc.CreatedAt,
+			&doc.UpdatedAt,
+			&doc.CreatedBy,
+			&score,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar document: %w", err)
+		}
+
+		doc.Embedding = dbEmbedding.Slice()
+		results = append(results, storage.ScoredDocument{Document: doc, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read similar documents: %w", err)
+	}
+
+	return results, nil
+}
+
+// ListDocuments lists the documents of a tenant's collection
+func (db *DB) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*storage.Document, error) {
+	conn, done, err := db.openRead(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	query := `
+		SELECT id, tenant_id, collection, title, content, metadata, created_at, updated_at, created_by
 		FROM documents
+		WHERE collection = $3
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := tx.Query(ctx, query, limit, offset)
+	rows, err := conn.Query(ctx, query, limit, offset, storage.CollectionOrDefault(collection))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list documents: %w", err)
 	}
 	defer rows.Close()
 
-	var documents []*Document
+	var documents []*storage.Document
 	for rows.Next() {
-		doc := &Document{}
+		doc := &storage.Document{}
 		err := rows.Scan(
 			&doc.ID,
 			&doc.TenantID,
+			&doc.Collection,
 			&doc.Title,
 			&doc.Content,
 			&doc.Metadata,
@@ -344,18 +598,43 @@ func (db *DB) ListDocuments(ctx context.Context, tenantID string, limit, offset
 }
 
 // UpdateDocument updates an existing document
-func (db *DB) UpdateDocument(ctx context.Context, tenantID string, doc *Document) error {
+func (db *DB) UpdateDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
 	tx, err := db.BeginTx(ctx, tenantID)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
+	if doc.Embedding != nil {
+		var collection string
+		err := tx.QueryRow(ctx, `SELECT collection FROM documents WHERE id = $1`, doc.ID).Scan(&collection)
+		if err == pgx.ErrNoRows {
+			return storage.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get document collection: %w", err)
+		}
+		if err := checkCollectionEmbedding(ctx, tx, collection, doc.Embedding); err != nil {
+			return err
+		}
+	}
+
+	// A new embedding is taken to be generated from the new content. Passing the
+	// current embedding back unchanged keeps its hash, so the consistency checker
+	// notices that the content moved on.
 	query := `
 		UPDATE documents
-		SET title = $1, content = $2, metadata = $3, embedding = $4
+		SET title = $1, content = $2, metadata = $3, embedding = $4,
+			embedding_hash = CASE
+				WHEN $4::vector IS NULL THEN NULL
+				WHEN embedding IS NOT DISTINCT FROM $4::vector THEN embedding_hash
+				ELSE md5($1 || E'\n' || $2)
+			END,
+			embedding_stale_at = CASE
+				WHEN embedding IS NOT DISTINCT FROM $4::vector THEN embedding_stale_at
+			END
 		WHERE id = $5
-		RETURNING updated_at
+		RETURNING updated_at, collection
 	`
 
 	var embedding interface{}
@@ -369,16 +648,37 @@ func (db *DB) UpdateDocument(ctx context.Context, tenantID string, doc *Document
 		doc.Metadata,
 		embedding,
 		doc.ID,
-	).Scan(&doc.UpdatedAt)
+	).Scan(&doc.UpdatedAt, &doc.Collection)
 
 	if err == pgx.ErrNoRows {
-		return fmt.Errorf("document not found")
+		return storage.ErrNotFound
 	}
 	if err != nil {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
+	if err := db.quantizeDocument(ctx, tx, doc.ID); err != nil {
+		return err
+	}
+
+	// Documents that no longer have a stale embedding leave the re-embedding queue
+	dequeue := `
+		DELETE FROM embedding_queue q
+		USING documents d
+		WHERE q.document_id = $1 AND d.id = q.document_id AND d.embedding_stale_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, dequeue, doc.ID); err != nil {
+		return fmt.Errorf("failed to update embedding queue: %w", err)
+	}
+	if err := db.recordDocumentEvent(ctx, tx, tenantID, doc.ID, doc.Collection, DocumentUpdated); err != nil {
+		return err
+	}
 
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	db.notifyDocumentChange(ctx, tenantID, doc.ID)
+	return nil
 }
 
 // DeleteDocument deletes a document by ID
@@ -389,18 +689,26 @@ func (db *DB) DeleteDocument(ctx context.Context, tenantID, docID string) error
 	}
 	defer tx.Rollback(ctx)
 
-	query := `DELETE FROM documents WHERE id = $1`
+	query := `DELETE FROM documents WHERE id = $1 RETURNING collection`
 
-	result, err := tx.Exec(ctx, query, docID)
+	var collection string
+	err = tx.QueryRow(ctx, query, docID).Scan(&collection)
+	if err == pgx.ErrNoRows {
+		return storage.ErrNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
+	if err := db.recordDocumentEvent(ctx, tx, tenantID, docID, collection, DocumentDeleted); err != nil {
+		return err
+	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("document not found")
+	if err := tx.Commit(ctx); err != nil {
+		return err
 	}
 
-	return tx.Commit(ctx)
+	db.notifyDocumentChange(ctx, tenantID, docID)
+	return nil
 }
 
 // GetTenantSettings retrieves tenant settings
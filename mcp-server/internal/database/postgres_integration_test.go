@@ -4,10 +4,16 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,9 +57,9 @@ func TestGetDocument_WithNullEmbedding(t *testing.T) {
 	ctx := context.Background()
 
 	// Insert a test document WITHOUT embedding
-	testDoc := &Document{
+	testDoc := &storage.Document{
 		TenantID:  testTenantID,
-		Title:     "Test Document Without Embedding",
+		Title:     "Test storage.Document Without Embedding",
 		Content:   "This document has no embedding vector and should not cause scan errors",
 		Metadata:  map[string]interface{}{"test": true, "category": "integration-test"},
 		Embedding: nil, // Explicitly no embedding
@@ -61,7 +67,7 @@ func TestGetDocument_WithNullEmbedding(t *testing.T) {
 
 	err := db.InsertDocument(ctx, testTenantID, testDoc)
 	require.NoError(t, err, "Failed to insert test document")
-	require.NotEmpty(t, testDoc.ID, "Document ID should be generated")
+	require.NotEmpty(t, testDoc.ID, "storage.Document ID should be generated")
 
 	// Now retrieve the document - this should NOT fail with NULL scan error
 	retrieved, err := db.GetDocument(ctx, testTenantID, testDoc.ID)
@@ -90,9 +96,9 @@ func TestGetDocument_WithEmbedding(t *testing.T) {
 	}
 
 	// Insert a test document WITH embedding
-	testDoc := &Document{
+	testDoc := &storage.Document{
 		TenantID:  testTenantID,
-		Title:     "Test Document With Embedding",
+		Title:     "Test storage.Document With Embedding",
 		Content:   "This document has an embedding vector",
 		Metadata:  map[string]interface{}{"test": true, "category": "integration-test"},
 		Embedding: embedding,
@@ -100,7 +106,7 @@ func TestGetDocument_WithEmbedding(t *testing.T) {
 
 	err := db.InsertDocument(ctx, testTenantID, testDoc)
 	require.NoError(t, err, "Failed to insert test document")
-	require.NotEmpty(t, testDoc.ID, "Document ID should be generated")
+	require.NotEmpty(t, testDoc.ID, "storage.Document ID should be generated")
 
 	// Retrieve the document
 	retrieved, err := db.GetDocument(ctx, testTenantID, testDoc.ID)
@@ -124,7 +130,7 @@ func TestListDocuments_WithMixedEmbeddings(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test documents with and without embeddings
-	docs := []*Document{
+	docs := []*storage.Document{
 		{
 			TenantID:  testTenantID,
 			Title:     "Doc 1 - No Embedding",
@@ -155,7 +161,7 @@ func TestListDocuments_WithMixedEmbeddings(t *testing.T) {
 	}
 
 	// List documents should handle mixed embeddings
-	listed, err := db.ListDocuments(ctx, testTenantID, 10, 0)
+	listed, err := db.ListDocuments(ctx, testTenantID, "", 10, 0)
 	require.NoError(t, err, "Failed to list documents")
 	assert.GreaterOrEqual(t, len(listed), 3, "Should have at least 3 documents")
 
@@ -173,7 +179,7 @@ func TestSearchDocuments_WithNullEmbeddings(t *testing.T) {
 	ctx := context.Background()
 
 	// Insert test document without embedding
-	testDoc := &Document{
+	testDoc := &storage.Document{
 		TenantID:  testTenantID,
 		Title:     "Security Policy Test",
 		Content:   "Test content about security and authentication",
@@ -185,7 +191,7 @@ func TestSearchDocuments_WithNullEmbeddings(t *testing.T) {
 	require.NoError(t, err, "Failed to insert test document")
 
 	// Search should work even with NULL embeddings
-	results, err := db.SearchDocuments(ctx, testTenantID, "security", 10)
+	results, err := db.SearchDocuments(ctx, testTenantID, "", "security", 10, nil)
 	require.NoError(t, err, "Failed to search documents")
 	assert.GreaterOrEqual(t, len(results), 1, "Should find at least one document")
 
@@ -205,6 +211,358 @@ func TestSearchDocuments_WithNullEmbeddings(t *testing.T) {
 	require.NoError(t, err, "Failed to delete test document")
 }
 
+func TestSearch_MetadataFilters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	docs := []*storage.Document{
+		{Title: "Filter Test Security", Content: "filtertest rotation policy", Metadata: map[string]interface{}{"category": "security", "tags": []interface{}{"mfa", "vpn"}}},
+		{Title: "Filter Test HR", Content: "filtertest vacation policy", Metadata: map[string]interface{}{"category": "hr", "tags": []interface{}{"leave"}}},
+	}
+	for _, doc := range docs {
+		require.NoError(t, db.InsertDocument(ctx, testTenantID, doc))
+		defer db.DeleteDocument(ctx, testTenantID, doc.ID)
+	}
+
+	results, err := db.SearchDocuments(ctx, testTenantID, "", "filtertest", 10, storage.MetadataFilter{"category": {"security"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, docs[0].ID, results[0].ID)
+
+	// Array metadata matches on membership; a list of values matches any of them
+	results, err = db.SearchDocuments(ctx, testTenantID, "", "filtertest", 10, storage.MetadataFilter{"tags": {"leave", "sso"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, docs[1].ID, results[0].ID)
+
+	hybrid, err := db.SimpleHybridSearch(ctx, testTenantID, storage.HybridSearchParams{
+		Query:      "filtertest",
+		Limit:      10,
+		BM25Weight: 1,
+		Filters:    storage.MetadataFilter{"category": {"hr"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, hybrid, 1)
+	assert.Equal(t, docs[1].ID, hybrid[0].Document.ID)
+
+	hybrid, err = db.HybridSearch(ctx, testTenantID, storage.HybridSearchParams{
+		Query:      "filtertest",
+		Limit:      10,
+		BM25Weight: 1,
+		Filters:    storage.MetadataFilter{"category": {"security"}, "tags": {"vpn"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, hybrid, 1)
+	assert.Equal(t, docs[0].ID, hybrid[0].Document.ID)
+}
+
+func TestHybridSearch_FusionMethods(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	doc := &storage.Document{Title: "Fusion Test", Content: "fusiontest ranking normalization"}
+	require.NoError(t, db.InsertDocument(ctx, testTenantID, doc))
+	defer db.DeleteDocument(ctx, testTenantID, doc.ID)
+
+	for _, fusion := range []string{storage.FusionRRF, storage.FusionMinMax, storage.FusionZScore, storage.FusionWeighted} {
+		results, err := db.HybridSearch(ctx, testTenantID, storage.HybridSearchParams{
+			Query:      "fusiontest",
+			Limit:      10,
+			BM25Weight: 1,
+			Fusion:     fusion,
+		})
+		require.NoError(t, err, fusion)
+		require.Len(t, results, 1, fusion)
+		assert.Equal(t, doc.ID, results[0].Document.ID)
+		assert.Greater(t, results[0].BM25Score, 0.0)
+	}
+
+	_, err := db.HybridSearch(ctx, testTenantID, storage.HybridSearchParams{Query: "fusiontest", Fusion: "borda"})
+	assert.Error(t, err)
+}
+
+// Needs the search_terms vocabulary of the schema migrations
+func TestHybridSearch_QueryPreprocessing(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenant := onboarding.Tenant{Name: fmt.Sprintf("preprocessing-test-%d", time.Now().UnixNano())}
+	require.NoError(t, db.CreateTenant(ctx, &tenant))
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+
+	doc := &storage.Document{Title: "Automobile maintenance", Content: "Changing the engine oil of an automobile"}
+	require.NoError(t, db.InsertDocument(ctx, tenant.ID, doc))
+
+	search := func(params storage.HybridSearchParams) []storage.HybridSearchResult {
+		params.Limit, params.BM25Weight = 10, 1
+		results, err := db.HybridSearch(ctx, tenant.ID, params)
+		require.NoError(t, err)
+		return results
+	}
+
+	assert.Empty(t, search(storage.HybridSearchParams{Query: "automobeel maintenence"}))
+	results := search(storage.HybridSearchParams{Query: "automobeel maintenence", CorrectSpelling: true})
+	require.Len(t, results, 1)
+	assert.Equal(t, doc.ID, results[0].Document.ID)
+
+	assert.Empty(t, search(storage.HybridSearchParams{Query: "car engine"}))
+	results = search(storage.HybridSearchParams{Query: "car engine", Synonyms: map[string][]string{"car": {"automobile", "motor vehicle"}}})
+	require.Len(t, results, 1)
+	assert.Greater(t, results[0].BM25Score, 0.0)
+
+	// Deleted documents leave the vocabulary
+	require.NoError(t, db.DeleteDocument(ctx, tenant.ID, doc.ID))
+	tx, err := db.BeginReadTx(ctx, tenant.ID)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	var terms int
+	require.NoError(t, tx.QueryRow(ctx, `SELECT count(*) FROM search_terms`).Scan(&terms))
+	assert.Zero(t, terms)
+}
+
+func TestSynonyms(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.PutSynonyms(ctx, testTenantID, synonyms.Dictionary{"car": {"automobile"}}))
+	defer db.PutSynonyms(ctx, testTenantID, nil)
+
+	got, err := db.GetSynonyms(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, synonyms.Dictionary{"car": {"automobile"}}, got)
+
+	require.NoError(t, db.PutSynonyms(ctx, testTenantID, nil))
+	got, err = db.GetSynonyms(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	// Other settings are preserved
+	settings, err := db.GetTenantSettings(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Contains(t, settings, "monthly_budget_usd")
+}
+
+func TestSearchProfiles(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	weight := 0.25
+	profile := profiles.Profile{Name: "integration-test", Limit: 7, BM25Weight: &weight, Filters: map[string]interface{}{"category": "security"}}
+	require.NoError(t, db.PutProfile(ctx, testTenantID, profile))
+	defer db.DeleteProfile(ctx, testTenantID, profile.Name)
+
+	got, err := db.GetProfile(ctx, testTenantID, profile.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 7, got.Limit)
+	assert.Equal(t, 0.25, *got.BM25Weight)
+	assert.Equal(t, "security", got.Filters["category"])
+
+	list, err := db.ListProfiles(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, list)
+
+	// Other settings are preserved
+	settings, err := db.GetTenantSettings(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Contains(t, settings, "monthly_budget_usd")
+
+	require.NoError(t, db.DeleteProfile(ctx, testTenantID, profile.Name))
+	_, err = db.GetProfile(ctx, testTenantID, profile.Name)
+	assert.ErrorIs(t, err, profiles.ErrNotFound)
+	assert.ErrorIs(t, db.DeleteProfile(ctx, testTenantID, profile.Name), profiles.ErrNotFound)
+}
+
+func TestRoleAssignments(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	userID := "integration-test-user"
+	defer db.RevokeRole(ctx, testTenantID, userID)
+
+	require.NoError(t, db.AssignRole(ctx, auth.RoleAssignment{TenantID: testTenantID, UserID: userID, Role: auth.RoleViewer}))
+	require.NoError(t, db.AssignRole(ctx, auth.RoleAssignment{TenantID: testTenantID, UserID: userID, Role: auth.RoleEditor, AssignedBy: "admin"}))
+
+	got, err := db.GetRoleAssignment(ctx, testTenantID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleEditor, got.Role)
+	assert.Equal(t, "admin", got.AssignedBy)
+
+	list, err := db.ListRoleAssignments(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, list)
+
+	require.NoError(t, db.RevokeRole(ctx, testTenantID, userID))
+	_, err = db.GetRoleAssignment(ctx, testTenantID, userID)
+	assert.ErrorIs(t, err, auth.ErrRoleNotAssigned)
+	assert.ErrorIs(t, db.RevokeRole(ctx, testTenantID, userID), auth.ErrRoleNotAssigned)
+}
+
+func TestAuditLog(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	userID := fmt.Sprintf("audit-test-%d", time.Now().UnixNano())
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, db.RecordAuditEntries(ctx, testTenantID, []AuditEntry{
+		{UserID: userID, Tool: "search_documents", ArgsDigest: "sha256:old", Status: "success", LatencyMs: 12, CreatedAt: old},
+		{UserID: userID, Tool: "retrieve_document", ArgsDigest: "sha256:new", Status: "denied", LatencyMs: 1},
+	}))
+
+	entries, err := db.ListAuditEntries(ctx, testTenantID, AuditFilter{UserID: userID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "retrieve_document", entries[0].Tool, "most recent first")
+
+	entries, err = db.ListAuditEntries(ctx, testTenantID, AuditFilter{UserID: userID, Status: "denied", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entries, err = db.ListAuditEntries(ctx, testTenantID, AuditFilter{UserID: userID, Until: old.Add(time.Minute), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "sha256:old", entries[0].ArgsDigest)
+
+	// Entries are invisible to other tenants
+	entries, err = db.ListAuditEntries(ctx, "22222222-2222-2222-2222-222222222222", AuditFilter{UserID: userID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	deleted, err := db.PurgeAuditEntries(ctx, testTenantID, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(1))
+
+	entries, err = db.ListAuditEntries(ctx, testTenantID, AuditFilter{UserID: userID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestQueryReadOnly(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	rows, err := db.QueryReadOnly(ctx, testTenantID, "SELECT id, title, created_at FROM documents ORDER BY created_at LIMIT $1", []interface{}{5}, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+	assert.LessOrEqual(t, len(rows), 2)
+	assert.IsType(t, "", rows[0]["id"], "UUIDs are returned as strings")
+
+	// Row-level security hides other tenants' documents
+	rows, err = db.QueryReadOnly(ctx, "22222222-2222-2222-2222-222222222222", "SELECT id FROM documents WHERE tenant_id = $1::uuid", []interface{}{testTenantID}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	// Writes fail in the read-only transaction
+	_, err = db.QueryReadOnly(ctx, testTenantID, "WITH d AS (DELETE FROM documents RETURNING id) SELECT id FROM d", nil, 10)
+	assert.Error(t, err)
+}
+
+func TestCreateTenant(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	name := fmt.Sprintf("onboarding-test-%d", time.Now().UnixNano())
+	tenant := onboarding.Tenant{Name: name, Settings: map[string]interface{}{onboarding.SettingRateLimit: 42}}
+	require.NoError(t, db.CreateTenant(ctx, &tenant))
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	assert.NotEmpty(t, tenant.ID)
+	assert.Equal(t, DefaultRegion, tenant.DataRegion)
+
+	limit, err := db.TenantRateLimit(ctx, tenant.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 42, limit)
+
+	err = db.CreateTenant(ctx, &onboarding.Tenant{Name: name})
+	assert.ErrorIs(t, err, onboarding.ErrTenantExists)
+
+	// beta-inc's seed settings carry its own limit
+	limit, err = db.TenantRateLimit(ctx, "22222222-2222-2222-2222-222222222222")
+	require.NoError(t, err)
+	assert.Equal(t, 50, limit)
+}
+
+func TestCollections_IsolationAndQuota(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenant := onboarding.Tenant{
+		Name:     fmt.Sprintf("collections-test-%d", time.Now().UnixNano()),
+		Settings: map[string]interface{}{SettingCollectionQuotas: map[string]interface{}{"tickets": 1}},
+	}
+	require.NoError(t, db.CreateTenant(ctx, &tenant))
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+
+	policy := &storage.Document{Title: "Password policy", Content: "Passwords rotate every 90 days"}
+	ticket := &storage.Document{Collection: "tickets", Title: "Password reset", Content: "Cannot reset my password"}
+	require.NoError(t, db.InsertDocument(ctx, tenant.ID, policy))
+	require.NoError(t, db.InsertDocument(ctx, tenant.ID, ticket))
+	assert.Equal(t, storage.DefaultCollection, policy.Collection)
+
+	results, err := db.SearchDocuments(ctx, tenant.ID, "tickets", "password", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, ticket.ID, results[0].ID)
+	assert.Equal(t, "tickets", results[0].Collection)
+
+	listed, err := db.ListDocuments(ctx, tenant.ID, "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, policy.ID, listed[0].ID)
+
+	// The tickets quota is full; the default collection has none
+	err = db.InsertDocument(ctx, tenant.ID, &storage.Document{Collection: "tickets", Title: "Second", Content: "ticket"})
+	assert.ErrorIs(t, err, storage.ErrQuotaExceeded)
+	require.NoError(t, db.InsertDocument(ctx, tenant.ID, &storage.Document{Title: "Second", Content: "policy"}))
+}
+
+func TestTenantQuotas(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenant := onboarding.Tenant{
+		Name: fmt.Sprintf("quota-test-%d", time.Now().UnixNano()),
+		Settings: map[string]interface{}{
+			onboarding.SettingMaxDocuments:    2,
+			onboarding.SettingMaxStorageBytes: 40,
+		},
+	}
+	require.NoError(t, db.CreateTenant(ctx, &tenant))
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+
+	require.NoError(t, db.InsertDocument(ctx, tenant.ID, &storage.Document{Title: "Policy", Content: "Rotate yearly"}))
+
+	// 19 bytes stored, 31 more do not fit in 40
+	err := db.InsertDocument(ctx, tenant.ID, &storage.Document{Title: "Runbook", Content: "Restart the primary node"})
+	var quotaErr *storage.QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, storage.QuotaStorageBytes, quotaErr.Quota)
+	assert.Equal(t, int64(19), quotaErr.Used)
+
+	require.NoError(t, db.InsertDocument(ctx, tenant.ID, &storage.Document{Title: "FAQ", Content: "Ask"}))
+	err = db.InsertDocument(ctx, tenant.ID, &storage.Document{Title: "A", Content: "B"})
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, storage.QuotaDocuments, quotaErr.Quota)
+	assert.ErrorIs(t, err, storage.ErrQuotaExceeded)
+
+	usage, err := db.TenantQuotaUsage(ctx, tenant.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &storage.QuotaUsage{
+		TenantID: tenant.ID, Documents: 2, StorageBytes: 25, MaxDocuments: 2, MaxStorageBytes: 40,
+	}, usage)
+}
+
 func TestVectorSearch_SkipsNullEmbeddings(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -218,7 +576,7 @@ func TestVectorSearch_SkipsNullEmbeddings(t *testing.T) {
 	}
 
 	// Vector search should only return documents with embeddings
-	results, err := db.VectorSearch(ctx, testTenantID, queryEmbedding, 5)
+	results, err := db.VectorSearch(ctx, testTenantID, "", queryEmbedding, 5)
 	require.NoError(t, err, "Vector search should not fail")
 
 	// All returned documents should have embeddings
@@ -227,6 +585,115 @@ func TestVectorSearch_SkipsNullEmbeddings(t *testing.T) {
 	}
 }
 
+func TestSimilarDocuments(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenant := onboarding.Tenant{Name: fmt.Sprintf("similar-test-%d", time.Now().UnixNano())}
+	require.NoError(t, db.CreateTenant(ctx, &tenant))
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+
+	embedding := func(x, y float32) []float32 {
+		e := make([]float32, 1536)
+		e[0], e[1] = x, y
+		return e
+	}
+	docs := []*storage.Document{
+		{Title: "Password Policy", Content: "rotate", Embedding: embedding(1, 0), Metadata: map[string]interface{}{"category": "security"}},
+		{Title: "Password Policy (copy)", Content: "rotate", Embedding: embedding(0.99, 0.01), Metadata: map[string]interface{}{"category": "security"}},
+		{Title: "Vacation Policy", Content: "days", Embedding: embedding(0, 1), Metadata: map[string]interface{}{"category": "hr"}},
+		{Title: "No Embedding", Content: "none"},
+		{Title: "Other Collection", Collection: "tickets", Content: "ticket", Embedding: embedding(1, 0)},
+	}
+	for _, doc := range docs {
+		require.NoError(t, db.InsertDocument(ctx, tenant.ID, doc))
+	}
+
+	results, err := db.SimilarDocuments(ctx, tenant.ID, docs[0].ID, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2, "the document itself, documents without embeddings and other collections are left out")
+	assert.Equal(t, docs[1].ID, results[0].Document.ID)
+	assert.Equal(t, docs[2].ID, results[1].Document.ID)
+	assert.Greater(t, results[0].Score, 0.99)
+
+	results, err = db.SimilarDocuments(ctx, tenant.ID, docs[0].ID, 10, storage.MetadataFilter{"category": {"hr"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, docs[2].ID, results[0].Document.ID)
+
+	_, err = db.SimilarDocuments(ctx, testTenantID, docs[0].ID, 10, nil)
+	assert.ErrorIs(t, err, storage.ErrNotFound, "other tenants' documents are invisible")
+	_, err = db.SimilarDocuments(ctx, tenant.ID, docs[3].ID, 10, nil)
+	assert.ErrorIs(t, err, storage.ErrNoEmbedding)
+}
+
+func TestDuplicates_FindArchiveRestore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenant := onboarding.Tenant{Name: fmt.Sprintf("duplicates-test-%d", time.Now().UnixNano())}
+	require.NoError(t, db.CreateTenant(ctx, &tenant))
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+
+	embedding := func(x, y float32) []float32 {
+		e := make([]float32, 1536)
+		e[0], e[1] = x, y
+		return e
+	}
+	docs := []*storage.Document{
+		{Title: "Password Policy", Content: "rotate", Embedding: embedding(1, 0), Metadata: map[string]interface{}{"tags": []interface{}{"security"}}},
+		{Title: "Password Policy", Content: "rotate", Metadata: map[string]interface{}{"tags": []interface{}{"passwords"}, "owner": "it"}},
+		{Title: "Password Policy (v2)", Content: "rotate often", Embedding: embedding(0.99, 0.01)},
+		{Title: "Vacation Policy", Content: "days", Embedding: embedding(0, 1)},
+		{Title: "Password Policy", Collection: "tickets", Content: "rotate", Embedding: embedding(1, 0)},
+	}
+	for _, doc := range docs {
+		require.NoError(t, db.InsertDocument(ctx, tenant.ID, doc))
+	}
+
+	pairs, err := db.FindDuplicates(ctx, tenant.ID, DuplicateScan{Threshold: 0.95, Neighbors: 5})
+	require.NoError(t, err)
+	byExact := map[bool][]DuplicatePair{}
+	for _, pair := range pairs {
+		byExact[pair.Exact] = append(byExact[pair.Exact], pair)
+	}
+	require.Len(t, byExact[true], 1, "copies in other collections are not duplicates")
+	assert.Equal(t, docs[0].ID, byExact[true][0].First.ID)
+	assert.Equal(t, docs[1].ID, byExact[true][0].Second.ID)
+	require.Len(t, byExact[false], 1)
+	assert.ElementsMatch(t, []string{docs[0].ID, docs[2].ID}, []string{byExact[false][0].First.ID, byExact[false][0].Second.ID})
+	assert.Greater(t, byExact[false][0].Similarity, 0.99)
+
+	// Duplicates must be in the kept document's collection
+	err = db.ArchiveDuplicates(ctx, tenant.ID, docs[0].ID, []string{docs[1].ID, docs[4].ID}, DuplicateMerge)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = db.GetDocument(ctx, tenant.ID, docs[1].ID)
+	require.NoError(t, err, "a failed resolution archives nothing")
+
+	require.NoError(t, db.ArchiveDuplicates(ctx, tenant.ID, docs[0].ID, []string{docs[1].ID}, DuplicateMerge))
+	kept, err := db.GetDocument(ctx, tenant.ID, docs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"security", "passwords"}, kept.Metadata["tags"])
+	assert.Equal(t, "it", kept.Metadata["owner"])
+	_, err = db.GetDocument(ctx, tenant.ID, docs[1].ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, db.ArchiveDuplicates(ctx, tenant.ID, docs[0].ID, []string{docs[2].ID}, DuplicateSoftDelete))
+	pairs, err = db.FindDuplicates(ctx, tenant.ID, DuplicateScan{Threshold: 0.95, Neighbors: 5})
+	require.NoError(t, err)
+	assert.Empty(t, pairs)
+
+	restored, err := db.RestoreDocuments(ctx, tenant.ID, []string{docs[1].ID, docs[2].ID, docs[3].ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{docs[1].ID, docs[2].ID}, restored, "only archived documents are restored")
+	doc, err := db.GetDocument(ctx, tenant.ID, docs[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Password Policy (v2)", doc.Title)
+	assert.NotNil(t, doc.Embedding)
+}
+
 func TestHybridSearch_HandlesNullEmbeddings(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -239,7 +706,7 @@ func TestHybridSearch_HandlesNullEmbeddings(t *testing.T) {
 		queryEmbedding[i] = 0.1
 	}
 
-	params := HybridSearchParams{
+	params := storage.HybridSearchParams{
 		Query:        "security policy",
 		Embedding:    queryEmbedding,
 		Limit:        10,
@@ -273,7 +740,7 @@ func TestSimpleHybridSearch_HandlesNullEmbeddings(t *testing.T) {
 		queryEmbedding[i] = 0.1
 	}
 
-	params := HybridSearchParams{
+	params := storage.HybridSearchParams{
 		Query:        "security",
 		Embedding:    queryEmbedding,
 		Limit:        10,
@@ -300,7 +767,7 @@ func TestGetDocument_FromInitialSampleData(t *testing.T) {
 	ctx := context.Background()
 
 	// List documents to get actual IDs from sample data
-	docs, err := db.ListDocuments(ctx, testTenantID, 10, 0)
+	docs, err := db.ListDocuments(ctx, testTenantID, "", 10, 0)
 	require.NoError(t, err, "Failed to list documents")
 	require.NotEmpty(t, docs, "Should have sample documents from init-db.sql")
 
@@ -333,7 +800,7 @@ func TestUpdateDocument_PreservesEmbedding(t *testing.T) {
 	}
 
 	// Insert document with embedding
-	doc := &Document{
+	doc := &storage.Document{
 		TenantID:  testTenantID,
 		Title:     "Original Title",
 		Content:   "Original Content",
@@ -364,6 +831,52 @@ func TestUpdateDocument_PreservesEmbedding(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCheckEmbeddings_FlagsStaleEmbeddings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	embedding := make([]float32, 1536)
+	for i := range embedding {
+		embedding[i] = 0.1
+	}
+	doc := &storage.Document{Title: "Staleness Test", Content: "Original content", Embedding: embedding}
+	require.NoError(t, db.InsertDocument(ctx, testTenantID, doc))
+	defer db.DeleteDocument(ctx, testTenantID, doc.ID)
+
+	before, err := db.CheckEmbeddings(ctx, testTenantID)
+	require.NoError(t, err)
+
+	// Changing the content while keeping the old embedding makes it stale
+	doc.Content = "Rewritten content"
+	require.NoError(t, db.UpdateDocument(ctx, testTenantID, doc))
+
+	status, err := db.CheckEmbeddings(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, testTenantID, status.TenantID)
+	assert.Equal(t, 1, status.Flagged)
+	assert.Equal(t, before.Stale+1, status.Stale)
+
+	// Already flagged documents are not queued again
+	status, err = db.CheckEmbeddings(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Flagged)
+	assert.Equal(t, before.Stale+1, status.Stale)
+
+	// A new embedding for the new content clears the flag
+	newEmbedding := make([]float32, 1536)
+	for i := range newEmbedding {
+		newEmbedding[i] = 0.2
+	}
+	doc.Embedding = newEmbedding
+	require.NoError(t, db.UpdateDocument(ctx, testTenantID, doc))
+
+	status, err = db.CheckEmbeddings(ctx, testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, before.Stale, status.Stale)
+}
+
 func TestTenantIsolation(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -388,9 +901,9 @@ func TestTenantIsolation(t *testing.T) {
 	tx.Commit(ctx)
 
 	// Insert document for tenant 1
-	doc1 := &Document{
+	doc1 := &storage.Document{
 		TenantID: testTenantID,
-		Title:    "Tenant 1 Document",
+		Title:    "Tenant 1 storage.Document",
 		Content:  "This belongs to tenant 1",
 		Metadata: map[string]interface{}{"tenant": 1},
 	}
@@ -423,7 +936,7 @@ func TestConcurrentRetrievals(t *testing.T) {
 	ctx := context.Background()
 
 	// Get sample documents
-	docs, err := db.ListDocuments(ctx, testTenantID, 5, 0)
+	docs, err := db.ListDocuments(ctx, testTenantID, "", 5, 0)
 	require.NoError(t, err)
 	require.NotEmpty(t, docs)
 
@@ -464,3 +977,180 @@ func TestConcurrentRetrievals(t *testing.T) {
 
 	t.Log("✓ All concurrent retrievals completed successfully")
 }
+
+func TestCollections_Registry(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	name := fmt.Sprintf("registry-%d", time.Now().UnixNano())
+	embedding := make([]float32, 1536)
+	embedding[0] = 1
+
+	_, err := db.PutCollection(ctx, testTenantID, storage.Collection{Name: name, EmbeddingDimensions: 768})
+	assert.ErrorIs(t, err, storage.ErrEmbeddingDimensions, "the documents table stores 1536 dimensions")
+
+	collection, err := db.PutCollection(ctx, testTenantID, storage.Collection{
+		Name: name, EmbeddingModel: "text-embedding-3-small", EmbeddingDimensions: 1536,
+	})
+	require.NoError(t, err)
+	assert.True(t, collection.Registered)
+	assert.Zero(t, collection.Documents)
+
+	doc := &storage.Document{Collection: name, Title: "Registry", Content: "registry test", Embedding: embedding[:8]}
+	assert.ErrorIs(t, db.InsertDocument(ctx, testTenantID, doc), storage.ErrEmbeddingDimensions)
+	doc.Embedding = embedding
+	require.NoError(t, db.InsertDocument(ctx, testTenantID, doc))
+	defer db.DeleteDocument(ctx, testTenantID, doc.ID)
+
+	_, err = db.HybridSearch(ctx, testTenantID, storage.HybridSearchParams{Collection: name, Query: "registry", Embedding: embedding[:8]})
+	assert.ErrorIs(t, err, storage.ErrEmbeddingDimensions)
+
+	collection, err = db.GetCollection(ctx, testTenantID, name)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), collection.Documents)
+	assert.Equal(t, "text-embedding-3-small", collection.EmbeddingModel)
+	assert.ErrorIs(t, db.DeleteCollection(ctx, testTenantID, name), storage.ErrCollectionNotEmpty)
+
+	require.NoError(t, db.DeleteDocument(ctx, testTenantID, doc.ID))
+	require.NoError(t, db.DeleteCollection(ctx, testTenantID, name))
+	_, err = db.GetCollection(ctx, testTenantID, name)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestSetTenantContext_BindsTenantID(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, tenantID := range []string{
+		"x'; SET LOCAL row_security = off; --",
+		testTenantID + "' OR '1'='1",
+	} {
+		tx, err := db.BeginTx(ctx, tenantID)
+		require.NoError(t, err, "the tenant ID is stored verbatim, never executed")
+
+		var setting string
+		require.NoError(t, tx.QueryRow(ctx, `SELECT current_setting('app.current_tenant_id')`).Scan(&setting))
+		assert.Equal(t, tenantID, setting)
+		var rowSecurity string
+		require.NoError(t, tx.QueryRow(ctx, `SHOW row_security`).Scan(&rowSecurity))
+		assert.Equal(t, "on", rowSecurity)
+
+		// The RLS policies cast the setting to a UUID, so no rows are exposed
+		var count int
+		err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM documents`).Scan(&count)
+		assert.Error(t, err)
+		tx.Rollback(ctx)
+	}
+}
+
+func TestReadReplicas_FallBackToPrimary(t *testing.T) {
+	cfg := getTestDBConfig()
+	cfg.Replicas = []ReplicaConfig{
+		{Host: "127.0.0.1", Port: 1}, // nothing listens here
+		{Host: cfg.Host, Port: cfg.Port},
+	}
+	db, err := NewDB(context.Background(), cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, err := db.ListDocuments(ctx, testTenantID, "", 5, 0)
+		require.NoError(t, err)
+	}
+
+	stats := db.PoolStats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, PrimaryPool, stats[0].Name)
+	assert.False(t, stats[1].Healthy, "the unreachable replica is skipped")
+	assert.True(t, stats[2].Healthy)
+	assert.Positive(t, stats[2].AcquireCount, "reads are served by the reachable replica")
+
+	// Read transactions are read-only wherever they run
+	tx, err := db.BeginReadTx(ctx, testTenantID)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "DELETE FROM documents WHERE tenant_id = $1", testTenantID)
+	assert.Error(t, err)
+}
+
+func TestReadSession_ResetsTenantContext(t *testing.T) {
+	cfg := getTestDBConfig()
+	cfg.MaxConns, cfg.MinConns = 1, 1 // every read reuses the one connection
+	cfg.SessionReads = true
+	db, err := NewDB(context.Background(), cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.readSession(ctx, testTenantID)
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "DELETE FROM documents WHERE tenant_id = $1", testTenantID)
+	assert.Error(t, err, "session reads are read-only")
+	conn.Release()
+
+	_, err = db.ListDocuments(ctx, testTenantID, "", 5, 0)
+	require.NoError(t, err)
+
+	// The connection comes back from the pool without the reader's session settings
+	conn, err = db.pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+	var tenant, readOnly string
+	require.NoError(t, conn.QueryRow(ctx,
+		"SELECT coalesce(current_setting('app.current_tenant_id', true), ''), current_setting('default_transaction_read_only')").
+		Scan(&tenant, &readOnly))
+	assert.Empty(t, tenant)
+	assert.Equal(t, "off", readOnly)
+}
+
+func TestDocumentEvents_Outbox(t *testing.T) {
+	cfg := getTestDBConfig()
+	cfg.Outbox = true
+	db, err := NewDB(context.Background(), cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	// Publish whatever earlier tests left behind
+	for {
+		n, err := db.RelayDocumentEvents(ctx, testTenantID, 1000, func([]DocumentEvent) error { return nil })
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+
+	doc := &storage.Document{TenantID: testTenantID, Title: "Outbox", Content: "Lifecycle events"}
+	require.NoError(t, db.InsertDocument(ctx, testTenantID, doc))
+	doc.Content = "Lifecycle events, revised"
+	require.NoError(t, db.UpdateDocument(ctx, testTenantID, doc))
+	require.NoError(t, db.DeleteDocument(ctx, testTenantID, doc.ID))
+
+	// A failed publish leaves the events in the outbox
+	_, err = db.RelayDocumentEvents(ctx, testTenantID, 10, func([]DocumentEvent) error { return fmt.Errorf("broker down") })
+	require.Error(t, err)
+
+	var events []DocumentEvent
+	n, err := db.RelayDocumentEvents(ctx, testTenantID, 10, func(batch []DocumentEvent) error {
+		events = batch
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	for i, eventType := range []string{DocumentCreated, DocumentUpdated, DocumentDeleted} {
+		assert.Equal(t, eventType, events[i].Type)
+		assert.Equal(t, doc.ID, events[i].DocumentID)
+		assert.Equal(t, storage.DefaultCollection, events[i].Collection)
+	}
+
+	n, err = db.RelayDocumentEvents(ctx, testTenantID, 10, func([]DocumentEvent) error { return nil })
+	require.NoError(t, err)
+	assert.Zero(t, n, "published events are not relayed again")
+
+	purged, err := db.PurgeDocumentEvents(ctx, testTenantID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(3))
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Quantized embedding columns that vector search can scan instead of the full
+// precision embedding (see scripts/apply-vector-quantization.sql)
+const (
+	QuantizationNone    = ""        // scan the vector index, exact distances
+	QuantizationHalfvec = "halfvec" // embedding_half: 16-bit floats, half the size
+	QuantizationBit     = "bit"     // embedding_bit: one bit per dimension, Hamming distance
+)
+
+// DefaultRerankFactor is how many quantized candidates per requested result are
+// re-ranked by exact distance when no factor is configured
+const DefaultRerankFactor = 4
+
+// QuantizationConfig controls the quantized embedding columns. Rolling them out takes
+// three steps: apply the migration, turn on DualWrite so new and updated embeddings are
+// quantized and the consistency checker backfills the rest, then set Search.
+type QuantizationConfig struct {
+	// DualWrite keeps embedding_half and embedding_bit in step with embedding
+	DualWrite bool `yaml:"dual_write"`
+	// Search selects the quantized column searches scan for candidates, or "" for none
+	Search string `yaml:"search"`
+	// RerankFactor times the requested results are fetched from the quantized index
+	// and re-ranked by the full precision embedding (default 4)
+	RerankFactor int `yaml:"rerank_factor"`
+}
+
+// Validate checks the search mode and rerank factor
+func (q QuantizationConfig) Validate() error {
+	switch q.Search {
+	case QuantizationNone, QuantizationHalfvec, QuantizationBit:
+	default:
+		return fmt.Errorf("search must be %q, %q or empty, got %q", QuantizationHalfvec, QuantizationBit, q.Search)
+	}
+	if q.RerankFactor < 0 {
+		return fmt.Errorf("rerank_factor must not be negative, got %d", q.RerankFactor)
+	}
+	return nil
+}
+
+// nearestSQL returns a query for the id and cosine similarity (score) of the limitParam
+// documents nearest to vectorParam that satisfy conditions, closest first. With quantized
+// search the quantized index supplies RerankFactor times as many candidates, which are
+// then ordered by their full precision embedding.
+func (q QuantizationConfig) nearestSQL(vectorParam, limitParam, conditions string) string {
+	var column, distance string
+	switch q.Search {
+	case QuantizationHalfvec:
+		column, distance = "embedding_half", fmt.Sprintf("embedding_half <=> %s::vector::halfvec", vectorParam)
+	case QuantizationBit:
+		column, distance = "embedding_bit", fmt.Sprintf("embedding_bit <~> binary_quantize(%s::vector)", vectorParam)
+	default:
+		return fmt.Sprintf(`
+			SELECT id, 1 - (embedding <=> %[1]s) AS score
+			FROM documents
+			WHERE embedding IS NOT NULL%[3]s
+			ORDER BY embedding <=> %[1]s
+			LIMIT %[2]s`, vectorParam, limitParam, conditions)
+	}
+
+	factor := q.RerankFactor
+	if factor <= 0 {
+		factor = DefaultRerankFactor
+	}
+	return fmt.Sprintf(`
+			SELECT id, 1 - (embedding <=> %[1]s) AS score
+			FROM (
+				SELECT id, embedding
+				FROM documents
+				WHERE %[3]s IS NOT NULL%[4]s
+				ORDER BY %[5]s
+				LIMIT %[2]s * %[6]d
+			) quantized
+			ORDER BY embedding <=> %[1]s
+			LIMIT %[2]s`, vectorParam, limitParam, column, conditions, distance, factor)
+}
+
+// quantizeSQL sets the quantized columns of the documents matching a condition from
+// their embedding, clearing them when the embedding is NULL
+const quantizeSQL = `
+	UPDATE documents
+	SET embedding_half = embedding::halfvec, embedding_bit = binary_quantize(embedding)
+	WHERE `
+
+// quantizeDocument dual-writes the quantized embeddings of a document when enabled
+func (db *DB) quantizeDocument(ctx context.Context, tx pgx.Tx, docID string) error {
+	if !db.quantization.DualWrite {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, quantizeSQL+`id = $1`, docID); err != nil {
+		return fmt.Errorf("failed to quantize embedding: %w", err)
+	}
+	return nil
+}
+
+// requantize backfills the quantized embeddings that do not match their document's
+// embedding, such as those written before dual-write was enabled, and returns how many
+// documents it updated
+func (db *DB) requantize(ctx context.Context, tx pgx.Tx) (int, error) {
+	if !db.quantization.DualWrite {
+		return 0, nil
+	}
+	result, err := tx.Exec(ctx, quantizeSQL+`
+		embedding_half IS DISTINCT FROM embedding::halfvec
+		OR embedding_bit IS DISTINCT FROM binary_quantize(embedding)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill quantized embeddings: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantizationConfig_Validate(t *testing.T) {
+	assert.NoError(t, QuantizationConfig{}.Validate())
+	assert.NoError(t, QuantizationConfig{DualWrite: true, Search: QuantizationBit, RerankFactor: 8}.Validate())
+	assert.Error(t, QuantizationConfig{Search: "int8"}.Validate())
+	assert.Error(t, QuantizationConfig{Search: QuantizationHalfvec, RerankFactor: -1}.Validate())
+}
+
+func TestQuantizationConfig_NearestSQL(t *testing.T) {
+	exact := QuantizationConfig{}.nearestSQL("$1", "$2", "")
+	assert.Contains(t, exact, "ORDER BY embedding <=> $1")
+	assert.NotContains(t, exact, "quantized")
+
+	half := QuantizationConfig{Search: QuantizationHalfvec}.nearestSQL("$2", "$3", " AND x")
+	assert.Contains(t, half, "WHERE embedding_half IS NOT NULL AND x")
+	assert.Contains(t, half, "ORDER BY embedding_half <=> $2::vector::halfvec")
+	assert.Contains(t, half, "LIMIT $3 * 4", "default rerank factor")
+	assert.Contains(t, half, "ORDER BY embedding <=> $2", "candidates are re-ranked exactly")
+
+	bit := QuantizationConfig{Search: QuantizationBit, RerankFactor: 10}.nearestSQL("$1", "$2", "")
+	assert.Contains(t, bit, "ORDER BY embedding_bit <~> binary_quantize($1::vector)")
+	assert.Contains(t, bit, "LIMIT $2 * 10")
+}
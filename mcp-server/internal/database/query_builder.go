@@ -0,0 +1,54 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// documentColumns are the documents columns that listings and searches return, without the embedding
+const documentColumns = "id, tenant_id, collection, title, content, metadata, created_at, updated_at, created_by"
+
+// queryBuilder assembles a statement from fixed SQL fragments and numbers a placeholder
+// for each argument. Values never become part of the SQL text, so every call with the
+// same shape produces the same statement and hits the per-connection statement cache.
+type queryBuilder struct {
+	sql  strings.Builder
+	args []interface{}
+}
+
+// write appends SQL fragments
+func (b *queryBuilder) write(fragments ...string) *queryBuilder {
+	for _, fragment := range fragments {
+		b.sql.WriteString(fragment)
+	}
+	return b
+}
+
+// arg adds an argument and returns its placeholder
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// metadataFilter appends the filter's conditions, each starting with " AND "
+func (b *queryBuilder) metadataFilter(filter storage.MetadataFilter) error {
+	sql, args, err := metadataFilterSQL(filter, len(b.args)+1)
+	if err != nil {
+		return err
+	}
+	b.sql.WriteString(sql)
+	b.args = append(b.args, args...)
+	return nil
+}
+
+// String returns the statement
+func (b *queryBuilder) String() string {
+	return b.sql.String()
+}
+
+// Args returns the arguments in placeholder order
+func (b *queryBuilder) Args() []interface{} {
+	return b.args
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// QuotaStore reports tenants' document usage against their quotas
+type QuotaStore interface {
+	// TenantQuotaUsage returns a tenant's document count and storage bytes with the
+	// quotas in its settings (onboarding.SettingMaxDocuments and SettingMaxStorageBytes)
+	TenantQuotaUsage(ctx context.Context, tenantID string) (*storage.QuotaUsage, error)
+}
+
+// Ensure DB implements QuotaStore
+var _ QuotaStore = (*DB)(nil)
+
+// tenantQuotaQuery reads a tenant's quotas, 0 where unset
+const tenantQuotaQuery = `
+	SELECT
+		CASE WHEN jsonb_typeof(settings->$2::text) = 'number' THEN (settings->>$2::text)::numeric::bigint ELSE 0 END,
+		CASE WHEN jsonb_typeof(settings->$3::text) = 'number' THEN (settings->>$3::text)::numeric::bigint ELSE 0 END
+	FROM tenants WHERE id = $1
+`
+
+// tenantUsageQuery counts the documents visible to the transaction's tenant and the
+// bytes they count against storage quotas (see storage.DocumentBytes)
+const tenantUsageQuery = `
+	SELECT COUNT(*), COALESCE(SUM(octet_length(title) + octet_length(content)), 0)::bigint
+	FROM documents
+`
+
+// checkTenantQuota returns a *storage.QuotaError when a tenant's documents plus doc
+// would exceed its document or storage quota. Inserts of the tenant are serialized until
+// the transaction ends so concurrent inserts cannot overshoot the quotas.
+func (db *DB) checkTenantQuota(ctx context.Context, tx pgx.Tx, tenantID string, doc *storage.Document) error {
+	var maxDocuments, maxBytes int64
+	err := tx.QueryRow(ctx, tenantQuotaQuery, tenantID, onboarding.SettingMaxDocuments, onboarding.SettingMaxStorageBytes).
+		Scan(&maxDocuments, &maxBytes)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && maxDocuments <= 0 && maxBytes <= 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tenant quotas: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, tenantID); err != nil {
+		return fmt.Errorf("failed to lock tenant: %w", err)
+	}
+	var documents, bytes int64
+	if err := tx.QueryRow(ctx, tenantUsageQuery).Scan(&documents, &bytes); err != nil {
+		return fmt.Errorf("failed to get tenant usage: %w", err)
+	}
+	if maxDocuments > 0 && documents >= maxDocuments {
+		return &storage.QuotaError{Quota: storage.QuotaDocuments, Limit: maxDocuments, Used: documents}
+	}
+	if maxBytes > 0 && bytes+storage.DocumentBytes(doc) > maxBytes {
+		return &storage.QuotaError{Quota: storage.QuotaStorageBytes, Limit: maxBytes, Used: bytes}
+	}
+	return nil
+}
+
+// TenantQuotaUsage implements QuotaStore
+func (db *DB) TenantQuotaUsage(ctx context.Context, tenantID string) (*storage.QuotaUsage, error) {
+	tx, err := db.BeginReadTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	usage := &storage.QuotaUsage{TenantID: tenantID}
+	err = tx.QueryRow(ctx, tenantQuotaQuery, tenantID, onboarding.SettingMaxDocuments, onboarding.SettingMaxStorageBytes).
+		Scan(&usage.MaxDocuments, &usage.MaxStorageBytes)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant quotas: %w", err)
+	}
+	if err := tx.QueryRow(ctx, tenantUsageQuery).Scan(&usage.Documents, &usage.StorageBytes); err != nil {
+		return nil, fmt.Errorf("failed to get tenant usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ReadOnlyQuerier runs operator-defined queries, such as SQL tool templates, for a tenant
+type ReadOnlyQuerier interface {
+	// QueryReadOnly runs query with positional args in a read-only transaction scoped
+	// to the tenant and returns at most maxRows rows keyed by column name
+	QueryReadOnly(ctx context.Context, tenantID, query string, args []interface{}, maxRows int) ([]map[string]interface{}, error)
+}
+
+// Ensure DB implements ReadOnlyQuerier
+var _ ReadOnlyQuerier = (*DB)(nil)
+
+// QueryReadOnly runs query in a read-only transaction with the tenant's row-level
+// security context, so a template can neither write nor see other tenants' rows.
+// Queries run on a read replica when one is configured.
+func (db *DB) QueryReadOnly(ctx context.Context, tenantID, query string, args []interface{}, maxRows int) ([]map[string]interface{}, error) {
+	tx, err := db.BeginReadTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	var results []map[string]interface{}
+	for rows.Next() && len(results) < maxRows {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		row := make(map[string]interface{}, len(fields))
+		for i, field := range fields {
+			row[field.Name] = jsonValue(values[i])
+		}
+		results = append(results, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// jsonValue converts pgx values without a natural JSON encoding
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16])
+	case pgtype.Numeric:
+		f, err := v.Float64Value()
+		if err != nil || !f.Valid {
+			return nil
+		}
+		return f.Float64
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
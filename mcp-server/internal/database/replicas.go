@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pool names reported in pool metrics and stats. Replicas are named replica-1, replica-2, ...
+const PrimaryPool = "primary"
+
+const (
+	// replicaRetryInterval is how long reads skip a replica after it failed to start a transaction
+	replicaRetryInterval = 30 * time.Second
+	// replicaConnectTimeout bounds dialing a replica so an unreachable one falls back
+	// to the primary instead of using up the request deadline
+	replicaConnectTimeout = 3 * time.Second
+)
+
+// ReplicaConfig locates a read replica. The user, password, database name, SSL mode
+// and pool sizes are inherited from the primary, as is the port when it is zero.
+type ReplicaConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+// replica is a read replica connection pool
+type replica struct {
+	name string
+	addr string
+	pool *pgxpool.Pool
+	// downUntil is when, in Unix nanoseconds, the replica is tried again after a failure
+	downUntil atomic.Int64
+}
+
+func (r *replica) available(now time.Time) bool {
+	return now.UnixNano() >= r.downUntil.Load()
+}
+
+func (r *replica) markDown(now time.Time) {
+	r.downUntil.Store(now.Add(replicaRetryInterval).UnixNano())
+}
+
+// newReplicas creates a pool per replica. Pools connect lazily, so a replica that is
+// down at startup is skipped by reads until it comes back rather than failing startup.
+func newReplicas(ctx context.Context, cfg Config, sessions *sessionConns) ([]*replica, error) {
+	replicas := make([]*replica, 0, len(cfg.Replicas))
+	for i, replicaCfg := range cfg.Replicas {
+		poolCfg := cfg
+		poolCfg.Host = replicaCfg.Host
+		if replicaCfg.Port != 0 {
+			poolCfg.Port = replicaCfg.Port
+		}
+		poolConfig, err := newPoolConfig(poolCfg, sessions)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, err
+		}
+		poolConfig.ConnConfig.ConnectTimeout = replicaConnectTimeout
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("failed to create replica connection pool: %w", err)
+		}
+		replicas = append(replicas, &replica{
+			name: fmt.Sprintf("replica-%d", i+1),
+			addr: fmt.Sprintf("%s:%d", poolCfg.Host, poolCfg.Port),
+			pool: pool,
+		})
+	}
+	return replicas, nil
+}
+
+func closeReplicas(replicas []*replica) {
+	for _, r := range replicas {
+		r.pool.Close()
+	}
+}
+
+// BeginReadTx starts a read-only transaction with tenant context. It runs on a replica
+// when one is configured and available, and on the primary otherwise, so reads may lag
+// writes by the replication delay.
+func (db *DB) BeginReadTx(ctx context.Context, tenantID string) (pgx.Tx, error) {
+	tx, err := db.beginRead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := db.setTxContext(ctx, tx, tenantID); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// beginRead starts a read-only transaction on a connection from acquireRead
+func (db *DB) beginRead(ctx context.Context) (pgx.Tx, error) {
+	conn, err := db.acquireRead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return beginPooled(ctx, conn, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+}
+
+// acquireRead acquires a connection from the next available replica in round-robin
+// order. A replica that fails is skipped for replicaRetryInterval, and the primary
+// serves the read when no replica can.
+func (db *DB) acquireRead(ctx context.Context) (*pgxpool.Conn, error) {
+	if n := len(db.replicas); n > 0 {
+		now := time.Now()
+		first := int(db.nextReplica.Add(1) % uint64(n))
+		for i := 0; i < n; i++ {
+			r := db.replicas[(first+i)%n]
+			if !r.available(now) {
+				continue
+			}
+			conn, err := db.acquire(ctx, r.name, r.pool)
+			if err == nil {
+				return conn, nil
+			}
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			r.markDown(now)
+			slog.WarnContext(ctx, "Read replica unavailable, falling back",
+				"pool", r.name, "addr", r.addr, "retry_in", replicaRetryInterval, "error", err)
+		}
+	}
+	return db.acquire(ctx, PrimaryPool, db.pool)
+}
+
+// PoolStats is a snapshot of one connection pool
+type PoolStats struct {
+	Name string `json:"name"`
+	Addr string `json:"addr"`
+	// Healthy is false while reads skip a replica after a failure
+	Healthy              bool          `json:"healthy"`
+	MaxConns             int32         `json:"max_conns"`
+	TotalConns           int32         `json:"total_conns"`
+	AcquiredConns        int32         `json:"acquired_conns"`
+	IdleConns            int32         `json:"idle_conns"`
+	ConstructingConns    int32         `json:"constructing_conns"`
+	AcquireCount         int64         `json:"acquire_count"`
+	EmptyAcquireCount    int64         `json:"empty_acquire_count"`
+	CanceledAcquireCount int64         `json:"canceled_acquire_count"`
+	AcquireDuration      time.Duration `json:"acquire_duration_ns"`
+}
+
+// PoolStats returns stats for the primary pool followed by each replica pool
+func (db *DB) PoolStats() []PoolStats {
+	stats := make([]PoolStats, 0, 1+len(db.replicas))
+	stats = append(stats, poolStats(PrimaryPool, db.addr, true, db.pool.Stat()))
+	now := time.Now()
+	for _, r := range db.replicas {
+		stats = append(stats, poolStats(r.name, r.addr, r.available(now), r.pool.Stat()))
+	}
+	return stats
+}
+
+// PoolSamples returns the pool stats as samples for the pool gauges, labelled with region
+func (db *DB) PoolSamples(region string) []observability.PoolSample {
+	stats := db.PoolStats()
+	samples := make([]observability.PoolSample, len(stats))
+	for i, stat := range stats {
+		samples[i] = observability.PoolSample{
+			System:   "postgresql",
+			Pool:     stat.Name,
+			Region:   region,
+			Max:      int64(stat.MaxConns),
+			Total:    int64(stat.TotalConns),
+			Acquired: int64(stat.AcquiredConns),
+			Idle:     int64(stat.IdleConns),
+			Timeouts: stat.CanceledAcquireCount,
+		}
+	}
+	return samples
+}
+
+func poolStats(name, addr string, healthy bool, stat *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		Name:                 name,
+		Addr:                 addr,
+		Healthy:              healthy,
+		MaxConns:             stat.MaxConns(),
+		TotalConns:           stat.TotalConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		IdleConns:            stat.IdleConns(),
+		ConstructingConns:    stat.ConstructingConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDuration:      stat.AcquireDuration(),
+	}
+}
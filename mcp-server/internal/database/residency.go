@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultRegion is the data region of tenants that have not been assigned one
+const DefaultRegion = "default"
+
+// RegionResolver looks up the data region a tenant's data must be stored in
+type RegionResolver interface {
+	// TenantRegion returns the data region of an active tenant
+	TenantRegion(ctx context.Context, tenantID string) (string, error)
+}
+
+// Ensure DB implements RegionResolver interface
+var _ RegionResolver = (*DB)(nil)
+
+// TenantRegion returns the data region recorded for a tenant
+func (db *DB) TenantRegion(ctx context.Context, tenantID string) (string, error) {
+	query := `SELECT data_region FROM tenants WHERE id = $1 AND is_active = true`
+
+	var region string
+	err := db.pool.QueryRow(ctx, query, tenantID).Scan(&region)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("tenant not found or inactive")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant region: %w", err)
+	}
+
+	return region, nil
+}
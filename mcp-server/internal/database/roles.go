@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ensure DB implements auth.RoleStore. Role assignments are control plane data,
+// so they are served by the primary database.
+var _ auth.RoleStore = (*DB)(nil)
+
+// GetRoleAssignment returns a user's role assignment within a tenant
+func (db *DB) GetRoleAssignment(ctx context.Context, tenantID, userID string) (*auth.RoleAssignment, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT tenant_id::text, user_id, role, COALESCE(assigned_by, ''), assigned_at
+		FROM tenant_role_assignments
+		WHERE user_id = $1
+	`
+
+	var assignment auth.RoleAssignment
+	err = tx.QueryRow(ctx, query, userID).Scan(
+		&assignment.TenantID, &assignment.UserID, &assignment.Role,
+		&assignment.AssignedBy, &assignment.AssignedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, auth.ErrRoleNotAssigned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role assignment: %w", err)
+	}
+	return &assignment, nil
+}
+
+// ListRoleAssignments returns a tenant's role assignments ordered by user ID
+func (db *DB) ListRoleAssignments(ctx context.Context, tenantID string) ([]auth.RoleAssignment, error) {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT tenant_id::text, user_id, role, COALESCE(assigned_by, ''), assigned_at
+		FROM tenant_role_assignments
+		ORDER BY user_id
+	`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []auth.RoleAssignment{}
+	for rows.Next() {
+		var assignment auth.RoleAssignment
+		if err := rows.Scan(
+			&assignment.TenantID, &assignment.UserID, &assignment.Role,
+			&assignment.AssignedBy, &assignment.AssignedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// AssignRole creates or replaces a user's role assignment
+func (db *DB) AssignRole(ctx context.Context, assignment auth.RoleAssignment) error {
+	if assignment.AssignedAt.IsZero() {
+		assignment.AssignedAt = time.Now()
+	}
+
+	tx, err := db.BeginTx(ctx, assignment.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO tenant_role_assignments (tenant_id, user_id, role, assigned_by, assigned_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE
+		SET role = EXCLUDED.role, assigned_by = EXCLUDED.assigned_by, assigned_at = EXCLUDED.assigned_at
+	`
+
+	if _, err := tx.Exec(ctx, query,
+		assignment.TenantID, assignment.UserID, string(assignment.Role),
+		assignment.AssignedBy, assignment.AssignedAt,
+	); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// RevokeRole removes a user's role assignment
+func (db *DB) RevokeRole(ctx context.Context, tenantID, userID string) error {
+	tx, err := db.BeginTx(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM tenant_role_assignments WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return auth.ErrRoleNotAssigned
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ensure DB implements profiles.Store. Profiles live in the tenant's settings
+// under profiles.SettingsKey, so they are served by the control plane database.
+var _ profiles.Store = (*DB)(nil)
+
+// GetProfile returns a tenant's search profile by name
+func (db *DB) GetProfile(ctx context.Context, tenantID, name string) (*profiles.Profile, error) {
+	query := `SELECT settings->$2::text->$3::text FROM tenants WHERE id = $1 AND is_active = true`
+
+	var data []byte
+	err := db.pool.QueryRow(ctx, query, tenantID, profiles.SettingsKey, name).Scan(&data)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("tenant not found or inactive")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search profile: %w", err)
+	}
+	if data == nil {
+		return nil, profiles.ErrNotFound
+	}
+
+	var profile profiles.Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode search profile: %w", err)
+	}
+	profile.Name = name
+	return &profile, nil
+}
+
+// ListProfiles returns a tenant's search profiles sorted by name
+func (db *DB) ListProfiles(ctx context.Context, tenantID string) ([]profiles.Profile, error) {
+	query := `SELECT settings->$2::text FROM tenants WHERE id = $1 AND is_active = true`
+
+	var data []byte
+	err := db.pool.QueryRow(ctx, query, tenantID, profiles.SettingsKey).Scan(&data)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("tenant not found or inactive")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list search profiles: %w", err)
+	}
+
+	list := []profiles.Profile{}
+	if data == nil {
+		return list, nil
+	}
+
+	var byName map[string]profiles.Profile
+	if err := json.Unmarshal(data, &byName); err != nil {
+		return nil, fmt.Errorf("failed to decode search profiles: %w", err)
+	}
+	for name, profile := range byName {
+		profile.Name = name
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// PutProfile creates or replaces a tenant's search profile
+func (db *DB) PutProfile(ctx context.Context, tenantID string, profile profiles.Profile) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to encode search profile: %w", err)
+	}
+
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(
+				COALESCE(settings, '{}'::jsonb), ARRAY[$2::text],
+				COALESCE(settings->$2::text, '{}'::jsonb) || jsonb_build_object($3::text, $4::jsonb)),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_active = true
+	`
+
+	result, err := db.pool.Exec(ctx, query, tenantID, profiles.SettingsKey, profile.Name, data)
+	if err != nil {
+		return fmt.Errorf("failed to save search profile: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("tenant not found or inactive")
+	}
+	return nil
+}
+
+// DeleteProfile removes a tenant's search profile
+func (db *DB) DeleteProfile(ctx context.Context, tenantID, name string) error {
+	query := `
+		UPDATE tenants
+		SET settings = settings #- ARRAY[$2::text, $3::text],
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_active = true AND settings->$2::text ? $3::text
+	`
+
+	result, err := db.pool.Exec(ctx, query, tenantID, profiles.SettingsKey, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete search profile: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return profiles.ErrNotFound
+	}
+	return nil
+}
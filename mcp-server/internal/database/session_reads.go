@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// sessionResetTimeout bounds resetting a released session read connection
+const sessionResetTimeout = 5 * time.Second
+
+// sessionConns tracks the connections whose session settings were changed for reads
+// outside a transaction
+type sessionConns struct {
+	conns sync.Map // *pgx.Conn -> struct{}
+}
+
+func (s *sessionConns) mark(conn *pgx.Conn) {
+	s.conns.Store(conn, struct{}{})
+}
+
+// afterRelease is the pools' AfterRelease hook. It runs before a released connection goes
+// back to the pool and resets the session of a marked one, so no tenant context outlives
+// the read; the pool destroys the connection if the reset fails.
+func (s *sessionConns) afterRelease(conn *pgx.Conn) bool {
+	if s == nil {
+		return true
+	}
+	if _, ok := s.conns.LoadAndDelete(conn); !ok {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionResetTimeout)
+	defer cancel()
+	_, err := conn.Exec(ctx, "RESET ALL")
+	return err == nil
+}
+
+// reader runs the queries of a read, on a session read connection or in a transaction
+type reader interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// openRead starts a read for the tenant: a session read with Config.SessionReads, or else
+// a read-only transaction. Call done when finished with it.
+func (db *DB) openRead(ctx context.Context, tenantID string) (r reader, done func(), err error) {
+	if db.sessionReads {
+		conn, err := db.readSession(ctx, tenantID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return conn, conn.Release, nil
+	}
+	tx, err := db.BeginReadTx(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, func() { tx.Rollback(ctx) }, nil
+}
+
+// readSession acquires a read connection, from a replica when one is available, and sets
+// the tenant context, read-only transactions and the statement timeout for its session
+// in one round trip. Each statement then runs in its own implicit transaction, so a read
+// needs no BEGIN or ROLLBACK; row-level security applies as it does in BeginTx. The
+// session is reset with RESET ALL when the connection is released, though, so whether
+// this saves time over a transaction depends on the deployment: measure it with
+// BenchmarkGetDocument_ReadPath before enabling SessionReads.
+// Release the connection when done; the session is reset before the connection is reused.
+func (db *DB) readSession(ctx context.Context, tenantID string) (*pgxpool.Conn, error) {
+	conn, err := db.acquireRead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	// Mark first so even a partly applied session is reset
+	db.sessions.mark(conn.Conn())
+	settings := append(tenantSettings(ctx, tenantID), "default_transaction_read_only", "on")
+	if err := setConfig(ctx, conn, false, settings...); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to set tenant context: %w", err)
+	}
+	return conn, nil
+}
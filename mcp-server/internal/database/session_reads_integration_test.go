@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkGetDocument_ReadPath compares fetching a document in a read-only transaction
+// (BEGIN, set_config, SELECT, ROLLBACK) with a session read (set_config, SELECT), run in
+// parallel to show the effect on pool throughput.
+// Run with: go test -tags=integration -run '^$' -bench ReadPath -cpu 1,8,32 ./internal/database/
+func BenchmarkGetDocument_ReadPath(b *testing.B) {
+	ctx := context.Background()
+	db, err := NewDB(ctx, getTestDBConfig())
+	require.NoError(b, err)
+	defer db.Close()
+
+	doc := &storage.Document{TenantID: testTenantID, Title: "Read path benchmark", Content: "Session reads skip BEGIN and ROLLBACK"}
+	require.NoError(b, db.InsertDocument(ctx, testTenantID, doc))
+	defer db.DeleteDocument(ctx, testTenantID, doc.ID)
+
+	b.Run("transaction", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				tx, err := db.BeginReadTx(ctx, testTenantID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var title string
+				err = tx.QueryRow(ctx, "SELECT title FROM documents WHERE id = $1", doc.ID).Scan(&title)
+				tx.Rollback(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+
+	b.Run("session", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				conn, err := db.readSession(ctx, testTenantID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var title string
+				err = conn.QueryRow(ctx, "SELECT title FROM documents WHERE id = $1", doc.ID).Scan(&title)
+				conn.Release()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
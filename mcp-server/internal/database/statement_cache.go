@@ -0,0 +1,80 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Statement execution modes, as in pgx.QueryExecMode
+const (
+	// StatementModeCacheStatement prepares each distinct statement once per connection and
+	// reuses it, so repeated queries skip parsing and planning on the server (default)
+	StatementModeCacheStatement = "cache_statement"
+	// StatementModeCacheDescribe caches only result descriptions and sends statements
+	// unnamed, for poolers such as PgBouncer in transaction mode
+	StatementModeCacheDescribe = "cache_describe"
+	// StatementModeDescribeExec describes every statement before running it
+	StatementModeDescribeExec = "describe_exec"
+	// StatementModeExec sends every statement unnamed with text-encoded arguments
+	StatementModeExec = "exec"
+	// StatementModeSimpleProtocol interpolates arguments client-side and uses the simple protocol
+	StatementModeSimpleProtocol = "simple_protocol"
+)
+
+// DefaultStatementCacheCapacity is how many statements or descriptions each connection keeps
+const DefaultStatementCacheCapacity = 512
+
+// StatementCacheConfig controls how statements are prepared and cached per connection.
+// Statements are cached by their SQL text, so queries must be built from fixed fragments
+// (see queryBuilder) rather than with values spliced in.
+type StatementCacheConfig struct {
+	// Mode is one of the StatementMode constants, "" for cache_statement
+	Mode string `yaml:"mode"`
+	// Capacity is how many statements (or descriptions with cache_describe) each
+	// connection caches, evicting the least recently used (default 512)
+	Capacity int `yaml:"capacity"`
+}
+
+// Validate checks the mode and capacity
+func (c StatementCacheConfig) Validate() error {
+	if _, ok := statementModes[c.Mode]; !ok {
+		return fmt.Errorf("mode must be %q, %q, %q, %q or %q, got %q", StatementModeCacheStatement,
+			StatementModeCacheDescribe, StatementModeDescribeExec, StatementModeExec, StatementModeSimpleProtocol, c.Mode)
+	}
+	if c.Capacity < 0 {
+		return fmt.Errorf("capacity must not be negative, got %d", c.Capacity)
+	}
+	return nil
+}
+
+var statementModes = map[string]pgx.QueryExecMode{
+	"":                          pgx.QueryExecModeCacheStatement,
+	StatementModeCacheStatement: pgx.QueryExecModeCacheStatement,
+	StatementModeCacheDescribe:  pgx.QueryExecModeCacheDescribe,
+	StatementModeDescribeExec:   pgx.QueryExecModeDescribeExec,
+	StatementModeExec:           pgx.QueryExecModeExec,
+	StatementModeSimpleProtocol: pgx.QueryExecModeSimpleProtocol,
+}
+
+// apply sets the execution mode and cache sizes on a connection config
+func (c StatementCacheConfig) apply(connConfig *pgx.ConnConfig) {
+	mode, ok := statementModes[c.Mode]
+	if !ok {
+		mode = pgx.QueryExecModeCacheStatement
+	}
+	capacity := c.Capacity
+	if capacity == 0 {
+		capacity = DefaultStatementCacheCapacity
+	}
+
+	connConfig.DefaultQueryExecMode = mode
+	connConfig.StatementCacheCapacity = 0
+	connConfig.DescriptionCacheCapacity = 0
+	switch mode {
+	case pgx.QueryExecModeCacheStatement:
+		connConfig.StatementCacheCapacity = capacity
+	case pgx.QueryExecModeCacheDescribe:
+		connConfig.DescriptionCacheCapacity = capacity
+	}
+}
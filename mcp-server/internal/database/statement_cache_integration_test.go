@@ -0,0 +1,76 @@
+//go:build integration
+// +build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// Benchmarks of the hot-path reads under each statement mode. cache_statement should beat
+// exec, which has the server parse and plan every statement.
+// Run with: go test -tags=integration -run '^$' -bench StatementCache ./internal/database/
+
+var benchmarkStatementModes = []string{
+	StatementModeExec,
+	StatementModeDescribeExec,
+	StatementModeCacheDescribe,
+	StatementModeCacheStatement,
+}
+
+// statementModeDB connects with the given statement mode and inserts a document to read
+func statementModeDB(b *testing.B, mode string) (*DB, string) {
+	ctx := context.Background()
+	cfg := getTestDBConfig()
+	cfg.StatementCache.Mode = mode
+	db, err := NewDB(ctx, cfg)
+	require.NoError(b, err)
+	b.Cleanup(db.Close)
+
+	doc := &storage.Document{
+		TenantID: testTenantID,
+		Title:    "Statement cache benchmark",
+		Content:  "Prepared statements skip parsing and planning",
+		Metadata: map[string]interface{}{"department": "eng"},
+	}
+	require.NoError(b, db.InsertDocument(ctx, testTenantID, doc))
+	b.Cleanup(func() { db.DeleteDocument(ctx, testTenantID, doc.ID) })
+	return db, doc.ID
+}
+
+func BenchmarkGetDocument_StatementCache(b *testing.B) {
+	ctx := context.Background()
+	for _, mode := range benchmarkStatementModes {
+		b.Run(mode, func(b *testing.B) {
+			db, docID := statementModeDB(b, mode)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := db.GetDocument(ctx, testTenantID, docID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSearchDocuments_StatementCache(b *testing.B) {
+	ctx := context.Background()
+	filter := storage.MetadataFilter{"department": {"eng"}}
+	for _, mode := range benchmarkStatementModes {
+		b.Run(mode, func(b *testing.B) {
+			db, _ := statementModeDB(b, mode)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := db.SearchDocuments(ctx, testTenantID, "", "prepared", 10, filter); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementCacheConfig(t *testing.T) {
+	assert.NoError(t, StatementCacheConfig{}.Validate())
+	assert.NoError(t, StatementCacheConfig{Mode: StatementModeCacheDescribe, Capacity: 64}.Validate())
+	assert.ErrorContains(t, StatementCacheConfig{Mode: "prepared"}.Validate(), `got "prepared"`)
+	assert.EqualError(t, StatementCacheConfig{Capacity: -1}.Validate(), "capacity must not be negative, got -1")
+
+	connConfig := &pgx.ConnConfig{}
+	StatementCacheConfig{}.apply(connConfig)
+	assert.Equal(t, pgx.QueryExecModeCacheStatement, connConfig.DefaultQueryExecMode)
+	assert.Equal(t, DefaultStatementCacheCapacity, connConfig.StatementCacheCapacity)
+	assert.Zero(t, connConfig.DescriptionCacheCapacity)
+
+	StatementCacheConfig{Mode: StatementModeCacheDescribe, Capacity: 64}.apply(connConfig)
+	assert.Equal(t, pgx.QueryExecModeCacheDescribe, connConfig.DefaultQueryExecMode)
+	assert.Zero(t, connConfig.StatementCacheCapacity)
+	assert.Equal(t, 64, connConfig.DescriptionCacheCapacity)
+
+	StatementCacheConfig{Mode: StatementModeExec}.apply(connConfig)
+	assert.Equal(t, pgx.QueryExecModeExec, connConfig.DefaultQueryExecMode)
+	assert.Zero(t, connConfig.StatementCacheCapacity)
+	assert.Zero(t, connConfig.DescriptionCacheCapacity)
+}
+
+func TestQueryBuilder(t *testing.T) {
+	build := func(values ...string) *queryBuilder {
+		var q queryBuilder
+		q.write("SELECT id FROM documents WHERE collection = ", q.arg("policies"))
+		require.NoError(t, q.metadataFilter(storage.MetadataFilter{"department": {values[0]}}))
+		q.write(" LIMIT ", q.arg(10))
+		return &q
+	}
+
+	q := build("eng")
+	assert.Equal(t, "SELECT id FROM documents WHERE collection = $1"+
+		" AND (metadata @> $2::jsonb OR metadata @> $3::jsonb) LIMIT $4", q.String())
+	assert.Equal(t, []interface{}{"policies", `{"department":"eng"}`, `{"department":["eng"]}`, 10}, q.Args())
+
+	// Statements differing only in their values share the text, and so the cached statement
+	assert.Equal(t, q.String(), build("o'brien").String())
+}
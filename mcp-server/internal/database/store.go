@@ -0,0 +1,6 @@
+package database
+
+import "github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+
+// Ensure DB implements the storage interfaces
+var _ storage.ReadWriter = (*DB)(nil)
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ensure DB implements synonyms.Store. Dictionaries live in the tenant's settings
+// under synonyms.SettingsKey, so they are served by the control plane database.
+var _ synonyms.Store = (*DB)(nil)
+
+// GetSynonyms returns a tenant's synonym dictionary
+func (db *DB) GetSynonyms(ctx context.Context, tenantID string) (synonyms.Dictionary, error) {
+	query := `SELECT settings->$2::text FROM tenants WHERE id = $1 AND is_active = true`
+
+	var data []byte
+	err := db.pool.QueryRow(ctx, query, tenantID, synonyms.SettingsKey).Scan(&data)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("tenant not found or inactive")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get synonyms: %w", err)
+	}
+
+	dictionary := synonyms.Dictionary{}
+	if data == nil {
+		return dictionary, nil
+	}
+	if err := json.Unmarshal(data, &dictionary); err != nil {
+		return nil, fmt.Errorf("failed to decode synonyms: %w", err)
+	}
+	return dictionary, nil
+}
+
+// PutSynonyms replaces a tenant's synonym dictionary, removing it when empty
+func (db *DB) PutSynonyms(ctx context.Context, tenantID string, dictionary synonyms.Dictionary) error {
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(COALESCE(settings, '{}'::jsonb), ARRAY[$2::text], $3::jsonb),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_active = true
+	`
+	args := []interface{}{tenantID, synonyms.SettingsKey}
+	if len(dictionary) == 0 {
+		query = `
+			UPDATE tenants
+			SET settings = settings - $2::text,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND is_active = true
+		`
+	} else {
+		data, err := json.Marshal(dictionary)
+		if err != nil {
+			return fmt.Errorf("failed to encode synonyms: %w", err)
+		}
+		args = append(args, data)
+	}
+
+	result, err := db.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save synonyms: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("tenant not found or inactive")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ensure DB implements onboarding.TenantStore
+var _ onboarding.TenantStore = (*DB)(nil)
+
+// CreateTenant inserts an active tenant. A tenant that already has an ID, such as one
+// copied into a regional database, keeps it.
+func (db *DB) CreateTenant(ctx context.Context, tenant *onboarding.Tenant) error {
+	if tenant.DataRegion == "" {
+		tenant.DataRegion = DefaultRegion
+	}
+	settings := tenant.Settings
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode tenant settings: %w", err)
+	}
+
+	query := `
+		INSERT INTO tenants (id, name, settings, data_region)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id::text, created_at
+	`
+
+	err = db.pool.QueryRow(ctx, query, tenant.ID, tenant.Name, data, tenant.DataRegion).Scan(&tenant.ID, &tenant.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return onboarding.ErrTenantExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// TenantRateLimit returns the requests per minute recorded in a tenant's settings,
+// or 0 if the tenant has no limit of its own
+func (db *DB) TenantRateLimit(ctx context.Context, tenantID string) (int, error) {
+	query := `
+		SELECT CASE WHEN jsonb_typeof(settings->$2::text) = 'number'
+			THEN (settings->>$2::text)::numeric::int ELSE 0 END
+		FROM tenants WHERE id = $1 AND is_active = true
+	`
+
+	var limit int
+	err := db.pool.QueryRow(ctx, query, tenantID, onboarding.SettingRateLimit).Scan(&limit)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("tenant not found or inactive")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tenant rate limit: %w", err)
+	}
+	return limit, nil
+}
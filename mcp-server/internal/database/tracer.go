@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer implements pgx.QueryTracer. It creates a span per statement with the
+// normalized SQL and fingerprint, records query and pool acquire durations, and logs
+// statements slower than the slow query threshold.
+type QueryTracer struct {
+	tracer        trace.Tracer
+	metrics       *observability.Metrics
+	slowThreshold time.Duration
+}
+
+// Ensure QueryTracer implements pgx.QueryTracer
+var _ pgx.QueryTracer = (*QueryTracer)(nil)
+
+// NewQueryTracer creates a query tracer from the server telemetry
+func NewQueryTracer(telemetry *observability.Telemetry) *QueryTracer {
+	t := &QueryTracer{}
+	if telemetry != nil {
+		t.tracer = telemetry.Tracer
+		t.metrics = telemetry.Metrics
+	}
+	return t
+}
+
+// SetSlowQueryThreshold logs statements that take longer than threshold with their
+// normalized SQL; zero disables the log
+func (t *QueryTracer) SetSlowQueryThreshold(threshold time.Duration) {
+	t.slowThreshold = threshold
+}
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	start       time.Time
+	operation   string
+	statement   string
+	fingerprint string
+	span        trace.Span
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	normalized := NormalizeSQL(data.SQL)
+	qt := &queryTrace{
+		start:       time.Now(),
+		operation:   sqlOperation(normalized),
+		statement:   normalized,
+		fingerprint: Fingerprint(normalized),
+	}
+
+	if t.tracer != nil {
+		ctx, qt.span = t.tracer.Start(ctx, "db.query "+qt.operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation", qt.operation),
+				attribute.String("db.statement", normalized),
+				attribute.String("db.fingerprint", qt.fingerprint),
+			),
+		)
+	}
+
+	return context.WithValue(ctx, queryTraceKey{}, qt)
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	qt, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(qt.start)
+	if t.metrics != nil {
+		t.metrics.RecordDBQuery(ctx, qt.operation, qt.fingerprint, float64(duration.Microseconds())/1000, data.Err)
+	}
+	if t.slowThreshold > 0 && duration > t.slowThreshold {
+		t.logSlowQuery(ctx, conn, qt, duration, data)
+	}
+
+	if qt.span != nil {
+		qt.span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+		if data.Err != nil {
+			qt.span.SetStatus(codes.Error, data.Err.Error())
+			qt.span.RecordError(data.Err)
+		}
+		qt.span.End()
+	}
+}
+
+// logSlowQuery logs a statement over the slow query threshold. Only the normalized SQL is
+// logged, never the arguments, so literals and bound values stay out of the logs.
+func (t *QueryTracer) logSlowQuery(ctx context.Context, conn *pgx.Conn, qt *queryTrace, duration time.Duration, data pgx.TraceQueryEndData) {
+	args := []any{
+		"operation", qt.operation,
+		"fingerprint", qt.fingerprint,
+		"statement", qt.statement,
+		"duration_ms", float64(duration.Microseconds()) / 1000,
+		"threshold_ms", t.slowThreshold.Milliseconds(),
+		"rows", data.CommandTag.RowsAffected(),
+	}
+	if conn != nil {
+		args = append(args, "db_host", conn.Config().Host)
+	}
+	if data.Err != nil {
+		args = append(args, "error", data.Err)
+	}
+	slog.WarnContext(ctx, "Slow query", args...)
+}
+
+// TraceAcquire records how long it took to acquire a connection from the named pool that was requested at start
+func (t *QueryTracer) TraceAcquire(ctx context.Context, pool string, start time.Time, err error) {
+	if t == nil {
+		return
+	}
+
+	duration := time.Since(start)
+	if t.metrics != nil {
+		t.metrics.RecordDBPoolAcquire(ctx, pool, float64(duration.Microseconds())/1000, err)
+	}
+
+	if t.tracer != nil {
+		_, span := t.tracer.Start(ctx, "db.pool.acquire",
+			trace.WithTimestamp(start),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.pool", pool),
+			),
+		)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
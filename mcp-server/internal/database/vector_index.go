@@ -0,0 +1,387 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// SettingVectorIndexes is the tenant setting holding the specs of the tenant's vector
+// indexes keyed by collection, e.g. {"vector_indexes": {"policies": {"method": "hnsw"}}}
+const SettingVectorIndexes = "vector_indexes"
+
+// Index methods of a VectorIndexSpec
+const (
+	VectorIndexHNSW    = "hnsw"    // graph index: better recall/latency, slower to build
+	VectorIndexIVFFlat = "ivfflat" // inverted lists: fast to build, needs data to train on
+)
+
+// Defaults pgvector uses for unset index options
+const (
+	DefaultHNSWM              = 16
+	DefaultHNSWEfConstruction = 64
+	DefaultIVFFlatLists       = 100
+)
+
+// VectorIndexSpec describes a partial pgvector index over the embeddings of one tenant
+// collection. Options that are zero take pgvector's defaults.
+type VectorIndexSpec struct {
+	Method string `json:"method"`
+	// M is the maximum number of connections per HNSW layer (2-100)
+	M int `json:"m,omitempty"`
+	// EfConstruction is the HNSW build candidate list size (4-1000, at least 2*M)
+	EfConstruction int `json:"ef_construction,omitempty"`
+	// Lists is the number of IVFFlat lists (1-32768); rows/1000 suits up to a million rows
+	Lists int `json:"lists,omitempty"`
+}
+
+// Validate checks the method and that only its options are set, within pgvector's bounds
+func (s VectorIndexSpec) Validate() error {
+	switch s.Method {
+	case VectorIndexHNSW:
+		if s.Lists != 0 {
+			return errors.New("lists only applies to ivfflat indexes")
+		}
+		m, efConstruction := s.hnswOptions()
+		if m < 2 || m > 100 {
+			return fmt.Errorf("m must be between 2 and 100, got %d", m)
+		}
+		if efConstruction < 4 || efConstruction > 1000 {
+			return fmt.Errorf("ef_construction must be between 4 and 1000, got %d", efConstruction)
+		}
+		if efConstruction < 2*m {
+			return fmt.Errorf("ef_construction must be at least twice m, got %d for m %d", efConstruction, m)
+		}
+	case VectorIndexIVFFlat:
+		if s.M != 0 || s.EfConstruction != 0 {
+			return errors.New("m and ef_construction only apply to hnsw indexes")
+		}
+		if s.Lists < 0 || s.Lists > storage.MaxProbes {
+			return fmt.Errorf("lists must be between 1 and %d, got %d", storage.MaxProbes, s.Lists)
+		}
+	default:
+		return fmt.Errorf("method must be %q or %q, got %q", VectorIndexHNSW, VectorIndexIVFFlat, s.Method)
+	}
+	return nil
+}
+
+// hnswOptions returns M and EfConstruction with defaults applied
+func (s VectorIndexSpec) hnswOptions() (int, int) {
+	m, efConstruction := s.M, s.EfConstruction
+	if m == 0 {
+		m = DefaultHNSWM
+	}
+	if efConstruction == 0 {
+		efConstruction = max(DefaultHNSWEfConstruction, 2*m)
+	}
+	return m, efConstruction
+}
+
+// withSQL returns the storage parameters of the index
+func (s VectorIndexSpec) withSQL() string {
+	if s.Method == VectorIndexHNSW {
+		m, efConstruction := s.hnswOptions()
+		return fmt.Sprintf("m = %d, ef_construction = %d", m, efConstruction)
+	}
+	lists := s.Lists
+	if lists == 0 {
+		lists = DefaultIVFFlatLists
+	}
+	return fmt.Sprintf("lists = %d", lists)
+}
+
+// VectorIndex is a tenant collection's vector index with its usage statistics
+type VectorIndex struct {
+	Collection string          `json:"collection"`
+	Name       string          `json:"name"`
+	Spec       VectorIndexSpec `json:"spec"`
+	// Exists is false when the index is missing from the catalog, e.g. dropped by hand
+	Exists bool `json:"exists"`
+	// Valid is false while a concurrent build runs and after one failed; rebuild to repair
+	Valid         bool  `json:"valid"`
+	SizeBytes     int64 `json:"size_bytes"`
+	Scans         int64 `json:"scans"`
+	TuplesRead    int64 `json:"tuples_read"`
+	TuplesFetched int64 `json:"tuples_fetched"`
+}
+
+// VectorIndexStore manages per-collection vector indexes. Index builds run
+// concurrently with writes, and searches keep using the previous index, if any, until
+// a replacement is built.
+type VectorIndexStore interface {
+	// ListVectorIndexes returns a tenant's vector indexes sorted by collection
+	ListVectorIndexes(ctx context.Context, tenantID string) ([]VectorIndex, error)
+
+	// GetVectorIndex returns a collection's vector index, or storage.ErrNotFound
+	GetVectorIndex(ctx context.Context, tenantID, collection string) (*VectorIndex, error)
+
+	// PutVectorIndex creates or replaces a collection's vector index
+	PutVectorIndex(ctx context.Context, tenantID, collection string, spec VectorIndexSpec) (*VectorIndex, error)
+
+	// RebuildVectorIndex rebuilds a collection's vector index from its spec, e.g. after
+	// bulk loads degraded an IVFFlat index, or returns storage.ErrNotFound
+	RebuildVectorIndex(ctx context.Context, tenantID, collection string) (*VectorIndex, error)
+
+	// DropVectorIndex removes a collection's vector index, or returns storage.ErrNotFound
+	DropVectorIndex(ctx context.Context, tenantID, collection string) error
+}
+
+// Ensure DB implements VectorIndexStore interface
+var _ VectorIndexStore = (*DB)(nil)
+
+// vectorIndexName names a collection's index after a hash of the tenant and collection,
+// keeping it within Postgres' 63 byte identifier limit
+func vectorIndexName(tenantID, collection string) string {
+	sum := sha256.Sum256([]byte(tenantID + "/" + collection))
+	return "idx_documents_embedding_" + hex.EncodeToString(sum[:8])
+}
+
+// quoteLiteral quotes a string as an SQL literal, for statements that take no parameters
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// createVectorIndexSQL builds a partial index over the embeddings of a tenant collection.
+// The predicate repeats the tenant and collection conditions vector searches add, so the
+// planner can match the index.
+func createVectorIndexSQL(name, tenantID, collection string, spec VectorIndexSpec) string {
+	return fmt.Sprintf(`CREATE INDEX CONCURRENTLY %s ON documents USING %s (embedding vector_cosine_ops) WITH (%s)
+		WHERE tenant_id = %s::uuid AND collection = %s`,
+		pgx.Identifier{name}.Sanitize(), spec.Method, spec.withSQL(), quoteLiteral(tenantID), quoteLiteral(collection))
+}
+
+// vectorIndexSpecs returns the specs in a tenant's settings keyed by collection
+func (db *DB) vectorIndexSpecs(ctx context.Context, tenantID string) (map[string]VectorIndexSpec, error) {
+	query := `SELECT settings->$2::text FROM tenants WHERE id = $1 AND is_active = true`
+
+	var data []byte
+	err := db.pool.QueryRow(ctx, query, tenantID, SettingVectorIndexes).Scan(&data)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("tenant not found or inactive")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vector indexes: %w", err)
+	}
+
+	specs := make(map[string]VectorIndexSpec)
+	if data != nil {
+		if err := json.Unmarshal(data, &specs); err != nil {
+			return nil, fmt.Errorf("failed to decode vector indexes: %w", err)
+		}
+	}
+	return specs, nil
+}
+
+// vectorIndexStats reads the catalog state and usage statistics of indexes
+func (db *DB) vectorIndexStats(ctx context.Context, indexes []VectorIndex) error {
+	names := make([]string, len(indexes))
+	byName := make(map[string]*VectorIndex, len(indexes))
+	for i := range indexes {
+		names[i] = indexes[i].Name
+		byName[indexes[i].Name] = &indexes[i]
+	}
+
+	query := `
+		SELECT c.relname, i.indisvalid, pg_relation_size(c.oid),
+			COALESCE(s.idx_scan, 0), COALESCE(s.idx_tup_read, 0), COALESCE(s.idx_tup_fetch, 0)
+		FROM pg_class c
+		JOIN pg_index i ON i.indexrelid = c.oid
+		LEFT JOIN pg_stat_user_indexes s ON s.indexrelid = c.oid
+		WHERE c.relname = ANY($1) AND c.relnamespace = 'public'::regnamespace
+	`
+
+	rows, err := db.pool.Query(ctx, query, names)
+	if err != nil {
+		return fmt.Errorf("failed to get vector index stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var index VectorIndex
+		if err := rows.Scan(&name, &index.Valid, &index.SizeBytes, &index.Scans, &index.TuplesRead, &index.TuplesFetched); err != nil {
+			return fmt.Errorf("failed to scan vector index stats: %w", err)
+		}
+		if target, ok := byName[name]; ok {
+			target.Exists = true
+			target.Valid, target.SizeBytes = index.Valid, index.SizeBytes
+			target.Scans, target.TuplesRead, target.TuplesFetched = index.Scans, index.TuplesRead, index.TuplesFetched
+		}
+	}
+	return rows.Err()
+}
+
+// ListVectorIndexes returns a tenant's vector indexes with their stats
+func (db *DB) ListVectorIndexes(ctx context.Context, tenantID string) ([]VectorIndex, error) {
+	specs, err := db.vectorIndexSpecs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]VectorIndex, 0, len(specs))
+	for collection, spec := range specs {
+		indexes = append(indexes, VectorIndex{Collection: collection, Name: vectorIndexName(tenantID, collection), Spec: spec})
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Collection < indexes[j].Collection })
+	if len(indexes) == 0 {
+		return indexes, nil
+	}
+	if err := db.vectorIndexStats(ctx, indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// GetVectorIndex returns a collection's vector index with its stats
+func (db *DB) GetVectorIndex(ctx context.Context, tenantID, collection string) (*VectorIndex, error) {
+	collection = storage.CollectionOrDefault(collection)
+	specs, err := db.vectorIndexSpecs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := specs[collection]
+	if !ok {
+		return nil, fmt.Errorf("%w: collection %q has no vector index", storage.ErrNotFound, collection)
+	}
+
+	indexes := []VectorIndex{{Collection: collection, Name: vectorIndexName(tenantID, collection), Spec: spec}}
+	if err := db.vectorIndexStats(ctx, indexes); err != nil {
+		return nil, err
+	}
+	return &indexes[0], nil
+}
+
+// PutVectorIndex builds the index under a temporary name and swaps it in, so searches
+// keep their previous index until the new one is ready, then records the spec
+func (db *DB) PutVectorIndex(ctx context.Context, tenantID, collection string, spec VectorIndexSpec) (*VectorIndex, error) {
+	collection = storage.CollectionOrDefault(collection)
+	if err := storage.ValidateCollection(collection); err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := db.vectorIndexSpecs(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	name := vectorIndexName(tenantID, collection)
+	if err := db.buildVectorIndex(ctx, name, tenantID, collection, spec); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode vector index: %w", err)
+	}
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(
+				COALESCE(settings, '{}'::jsonb), ARRAY[$2::text],
+				COALESCE(settings->$2::text, '{}'::jsonb) || jsonb_build_object($3::text, $4::jsonb)),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_active = true
+	`
+	if _, err := db.pool.Exec(ctx, query, tenantID, SettingVectorIndexes, collection, data); err != nil {
+		return nil, fmt.Errorf("failed to save vector index: %w", err)
+	}
+	return db.GetVectorIndex(ctx, tenantID, collection)
+}
+
+// buildVectorIndex creates the index as name, replacing any existing index of that name.
+// CREATE INDEX CONCURRENTLY cannot run in a transaction, so the statements run on the pool.
+func (db *DB) buildVectorIndex(ctx context.Context, name, tenantID, collection string, spec VectorIndexSpec) error {
+	building := name + "_new"
+	// A failed concurrent build leaves an invalid index behind
+	if _, err := db.pool.Exec(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+pgx.Identifier{building}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to drop incomplete vector index: %w", err)
+	}
+	if _, err := db.pool.Exec(ctx, createVectorIndexSQL(building, tenantID, collection, spec)); err != nil {
+		db.pool.Exec(context.WithoutCancel(ctx), "DROP INDEX CONCURRENTLY IF EXISTS "+pgx.Identifier{building}.Sanitize())
+		return fmt.Errorf("failed to build vector index: %w", err)
+	}
+	if _, err := db.pool.Exec(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+pgx.Identifier{name}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to drop previous vector index: %w", err)
+	}
+	if _, err := db.pool.Exec(ctx, fmt.Sprintf("ALTER INDEX %s RENAME TO %s",
+		pgx.Identifier{building}.Sanitize(), pgx.Identifier{name}.Sanitize())); err != nil {
+		return fmt.Errorf("failed to rename vector index: %w", err)
+	}
+	return nil
+}
+
+// RebuildVectorIndex reindexes a collection's index concurrently, recreating it from its
+// spec when it is missing or invalid
+func (db *DB) RebuildVectorIndex(ctx context.Context, tenantID, collection string) (*VectorIndex, error) {
+	index, err := db.GetVectorIndex(ctx, tenantID, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if index.Exists && index.Valid {
+		if _, err := db.pool.Exec(ctx, "REINDEX INDEX CONCURRENTLY "+pgx.Identifier{index.Name}.Sanitize()); err != nil {
+			return nil, fmt.Errorf("failed to rebuild vector index: %w", err)
+		}
+	} else if err := db.buildVectorIndex(ctx, index.Name, tenantID, index.Collection, index.Spec); err != nil {
+		return nil, err
+	}
+	return db.GetVectorIndex(ctx, tenantID, index.Collection)
+}
+
+// DropVectorIndex removes a collection's index and its spec
+func (db *DB) DropVectorIndex(ctx context.Context, tenantID, collection string) error {
+	collection = storage.CollectionOrDefault(collection)
+	query := `
+		UPDATE tenants
+		SET settings = settings #- ARRAY[$2::text, $3::text],
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_active = true AND settings->$2::text ? $3::text
+	`
+
+	result, err := db.pool.Exec(ctx, query, tenantID, SettingVectorIndexes, collection)
+	if err != nil {
+		return fmt.Errorf("failed to delete vector index: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: collection %q has no vector index", storage.ErrNotFound, collection)
+	}
+
+	name := vectorIndexName(tenantID, collection)
+	if _, err := db.pool.Exec(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+pgx.Identifier{name}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to drop vector index: %w", err)
+	}
+	return nil
+}
+
+// tuneVectorSearch applies per-query ANN settings (0 keeps the server's) for the rest of
+// a transaction. It also forces custom plans so the planner sees the tenant and
+// collection values that partial vector indexes are matched against.
+func tuneVectorSearch(ctx context.Context, tx pgx.Tx, efSearch, probes int) error {
+	if efSearch < 0 || efSearch > storage.MaxEfSearch {
+		return fmt.Errorf("ef_search must be between 1 and %d, got %d", storage.MaxEfSearch, efSearch)
+	}
+	if probes < 0 || probes > storage.MaxProbes {
+		return fmt.Errorf("probes must be between 1 and %d, got %d", storage.MaxProbes, probes)
+	}
+
+	settings := []string{"plan_cache_mode", "force_custom_plan"}
+	if efSearch > 0 {
+		settings = append(settings, "hnsw.ef_search", strconv.Itoa(efSearch))
+	}
+	if probes > 0 {
+		settings = append(settings, "ivfflat.probes", strconv.Itoa(probes))
+	}
+	if err := setLocal(ctx, tx, settings...); err != nil {
+		return fmt.Errorf("failed to tune vector search: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorIndexSpecValidate(t *testing.T) {
+	valid := []VectorIndexSpec{
+		{Method: VectorIndexHNSW},
+		{Method: VectorIndexHNSW, M: 48},
+		{Method: VectorIndexHNSW, M: 8, EfConstruction: 16},
+		{Method: VectorIndexIVFFlat},
+		{Method: VectorIndexIVFFlat, Lists: 1000},
+	}
+	for _, spec := range valid {
+		assert.NoError(t, spec.Validate(), "%+v", spec)
+	}
+
+	invalid := map[string]VectorIndexSpec{
+		`method must be "hnsw" or "ivfflat", got "btree"`:           {Method: "btree"},
+		"m must be between 2 and 100, got 200":                      {Method: VectorIndexHNSW, M: 200},
+		"ef_construction must be at least twice m, got 40 for m 32": {Method: VectorIndexHNSW, M: 32, EfConstruction: 40},
+		"lists only applies to ivfflat indexes":                     {Method: VectorIndexHNSW, Lists: 10},
+		"m and ef_construction only apply to hnsw indexes":          {Method: VectorIndexIVFFlat, EfConstruction: 64},
+		"lists must be between 1 and 32768, got 40000":              {Method: VectorIndexIVFFlat, Lists: 40000},
+	}
+	for message, spec := range invalid {
+		assert.EqualError(t, spec.Validate(), message)
+	}
+}
+
+func TestCreateVectorIndexSQL(t *testing.T) {
+	name := vectorIndexName("11111111-1111-1111-1111-111111111111", "policies")
+	assert.Len(t, name, 40)
+	assert.NotEqual(t, name, vectorIndexName("11111111-1111-1111-1111-111111111111", "tickets"))
+
+	sql := createVectorIndexSQL(name, "11111111-1111-1111-1111-111111111111", "policies", VectorIndexSpec{Method: VectorIndexHNSW, M: 48})
+	assert.Contains(t, sql, `CREATE INDEX CONCURRENTLY "`+name+`" ON documents USING hnsw (embedding vector_cosine_ops) WITH (m = 48, ef_construction = 96)`)
+	assert.Contains(t, sql, "WHERE tenant_id = '11111111-1111-1111-1111-111111111111'::uuid AND collection = 'policies'")
+
+	sql = createVectorIndexSQL(name, "tenant", "o'brien", VectorIndexSpec{Method: VectorIndexIVFFlat})
+	assert.Contains(t, sql, "USING ivfflat (embedding vector_cosine_ops) WITH (lists = 100)")
+	assert.Contains(t, sql, "collection = 'o''brien'")
+}
@@ -0,0 +1,122 @@
+package dedup
+
+import (
+	"sort"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// Member is a document of a cluster that duplicates its canonical document
+type Member struct {
+	database.DuplicateDocument
+	// Similarity is the highest similarity of the document to another cluster document
+	Similarity float64 `json:"similarity"`
+	// Exact is set when the document has the same title and content as another cluster document
+	Exact bool `json:"exact"`
+}
+
+// Cluster is a group of documents of a collection linked by duplicate pairs. The
+// oldest document is the canonical one the others are resolved into.
+type Cluster struct {
+	Collection string                     `json:"collection"`
+	Canonical  database.DuplicateDocument `json:"canonical"`
+	Duplicates []Member                   `json:"duplicates"`
+	// MinSimilarity is the lowest similarity of the pairs linking the cluster; members of
+	// a chain of near-duplicates can be less similar to each other than this
+	MinSimilarity float64 `json:"min_similarity"`
+	// Exact is set when every pair linking the cluster is an exact duplicate
+	Exact bool `json:"exact"`
+	// Resolution is the action a scan resolved the cluster with, if any
+	Resolution string `json:"resolution,omitempty"`
+}
+
+// DuplicateIDs returns the IDs of the cluster's duplicates
+func (c Cluster) DuplicateIDs() []string {
+	ids := make([]string, len(c.Duplicates))
+	for i, member := range c.Duplicates {
+		ids[i] = member.ID
+	}
+	return ids
+}
+
+// BuildClusters groups duplicate pairs into clusters of transitively duplicated documents.
+// Clusters are ordered by collection and canonical document, members by age.
+func BuildClusters(pairs []database.DuplicatePair) []Cluster {
+	documents := make(map[string]database.DuplicateDocument)
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, pair := range pairs {
+		for _, doc := range []database.DuplicateDocument{pair.First, pair.Second} {
+			if _, ok := documents[doc.ID]; !ok {
+				documents[doc.ID] = doc
+				parent[doc.ID] = doc.ID
+			}
+		}
+		parent[find(pair.First.ID)] = find(pair.Second.ID)
+	}
+
+	// Similarity and exactness of each document and each cluster
+	members := make(map[string]*Member, len(documents))
+	for id, doc := range documents {
+		members[id] = &Member{DuplicateDocument: doc}
+	}
+	clusters := make(map[string]*Cluster)
+	for _, pair := range pairs {
+		root := find(pair.First.ID)
+		cluster, ok := clusters[root]
+		if !ok {
+			cluster = &Cluster{Collection: pair.First.Collection, MinSimilarity: pair.Similarity, Exact: true}
+			clusters[root] = cluster
+		}
+		if pair.Similarity < cluster.MinSimilarity {
+			cluster.MinSimilarity = pair.Similarity
+		}
+		cluster.Exact = cluster.Exact && pair.Exact
+		for _, id := range []string{pair.First.ID, pair.Second.ID} {
+			member := members[id]
+			if pair.Similarity > member.Similarity {
+				member.Similarity = pair.Similarity
+			}
+			member.Exact = member.Exact || pair.Exact
+		}
+	}
+
+	// The oldest document of a cluster is its canonical document
+	ids := make([]string, 0, len(documents))
+	for id := range documents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := documents[ids[i]], documents[ids[j]]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	for _, id := range ids {
+		cluster := clusters[find(id)]
+		if cluster.Canonical.ID == "" {
+			cluster.Canonical = documents[id]
+			continue
+		}
+		cluster.Duplicates = append(cluster.Duplicates, *members[id])
+	}
+
+	result := make([]Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		result = append(result, *cluster)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Collection != result[j].Collection {
+			return result[i].Collection < result[j].Collection
+		}
+		return result[i].Canonical.ID < result[j].Canonical.ID
+	})
+	return result
+}
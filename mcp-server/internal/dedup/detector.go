@@ -0,0 +1,316 @@
+// Package dedup finds documents that duplicate an older document of their collection,
+// by content hash and by embedding similarity, and reports them in clusters for review.
+// Duplicates can be resolved by merging them into the oldest document or soft deleting
+// them; either way they are archived and can be restored.
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+)
+
+// Config holds duplicate detector configuration
+type Config struct {
+	// Interval is how often all tenants are scanned (default 1h)
+	Interval time.Duration `yaml:"interval"`
+	// TenantTimeout bounds the scan of a single tenant (default 5m)
+	TenantTimeout time.Duration `yaml:"tenant_timeout"`
+	// Threshold is the embedding cosine similarity at or above which documents are
+	// near-duplicates (default 0.95); 0 only finds exact duplicates
+	Threshold float64 `yaml:"threshold"`
+	// Neighbors is how many nearest documents each document is compared with (default 5)
+	Neighbors int `yaml:"neighbors"`
+	// AutoAction resolves clusters during scheduled scans with database.DuplicateMerge or
+	// database.DuplicateSoftDelete; empty only reports them
+	AutoAction string `yaml:"auto_action"`
+	// AutoThreshold is the similarity at or above which every pair of a cluster must be
+	// for the cluster to be resolved automatically; 0 only resolves exact duplicates
+	AutoThreshold float64 `yaml:"auto_threshold"`
+}
+
+// DefaultConfig returns the built-in duplicate detector configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval:      time.Hour,
+		TenantTimeout: 5 * time.Minute,
+		Threshold:     0.95,
+		Neighbors:     5,
+	}
+}
+
+// Validate checks the thresholds and the automatic action
+func (c Config) Validate() error {
+	var errs []error
+	if c.Threshold < 0 || c.Threshold > 1 {
+		errs = append(errs, fmt.Errorf("threshold must be between 0 and 1, got %g", c.Threshold))
+	}
+	if c.Neighbors < 0 {
+		errs = append(errs, fmt.Errorf("neighbors must not be negative, got %d", c.Neighbors))
+	}
+	if err := ValidateAction(c.AutoAction); c.AutoAction != "" && err != nil {
+		errs = append(errs, fmt.Errorf("auto_action: %w", err))
+	}
+	if c.AutoThreshold != 0 && (c.AutoThreshold < c.Threshold || c.AutoThreshold > 1) {
+		errs = append(errs, fmt.Errorf("auto_threshold must be 0 or between threshold and 1, got %g", c.AutoThreshold))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAction checks a duplicate resolution action
+func ValidateAction(action string) error {
+	if action != database.DuplicateMerge && action != database.DuplicateSoftDelete {
+		return fmt.Errorf("action must be %s or %s, got %q", database.DuplicateMerge, database.DuplicateSoftDelete, action)
+	}
+	return nil
+}
+
+// Report is the result of a tenant's latest duplicate scan
+type Report struct {
+	TenantID    string    `json:"tenant_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Threshold   float64   `json:"threshold"`
+	Clusters    []Cluster `json:"clusters"`
+	// Duplicates is the number of duplicate documents, excluding canonical documents
+	Duplicates int `json:"duplicates"`
+	// Resolved is the number of duplicates the scan resolved automatically
+	Resolved int `json:"resolved"`
+}
+
+// Detector periodically scans every tenant for duplicate documents and keeps the latest
+// report of each tenant for review. Scheduled scans can resolve clusters automatically.
+type Detector struct {
+	tenants database.TenantLister
+	store   database.DuplicateStore
+	metrics *observability.Metrics
+	config  Config
+
+	// Automatic resolution, which archives documents, is skipped while safe mode is on
+	safeMode *safemode.Switch
+
+	mu      sync.RWMutex
+	reports map[string]*Report
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewDetector creates a new duplicate detector. Tenants are listed from the control
+// plane and scanned through store, which may route to regional databases.
+func NewDetector(tenants database.TenantLister, store database.DuplicateStore, metrics *observability.Metrics, cfg Config) *Detector {
+	defaults := DefaultConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.TenantTimeout <= 0 {
+		cfg.TenantTimeout = defaults.TenantTimeout
+	}
+	if cfg.Neighbors <= 0 {
+		cfg.Neighbors = defaults.Neighbors
+	}
+
+	return &Detector{
+		tenants: tenants,
+		store:   store,
+		metrics: metrics,
+		config:  cfg,
+		reports: make(map[string]*Report),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// SetSafeMode skips automatic resolution while safe mode is on
+func (d *Detector) SetSafeMode(safeMode *safemode.Switch) {
+	d.safeMode = safeMode
+}
+
+// Start runs a scan right away and then on every interval
+func (d *Detector) Start() {
+	d.wg.Add(1)
+	go d.run()
+}
+
+// Close stops the background scans and waits for a running scan to finish
+func (d *Detector) Close() {
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+	d.wg.Wait()
+}
+
+// run scans all tenants on every interval until the detector is closed
+func (d *Detector) run() {
+	defer d.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-d.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.ScanAll(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Duplicate scan failed", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// ScanAll scans every active tenant, resolving clusters when an automatic action is
+// configured, and returns the reports of the tenants that were scanned. A failing
+// tenant is logged and skipped so it cannot block the others.
+func (d *Detector) ScanAll(ctx context.Context) ([]*Report, error) {
+	tenantIDs, err := d.tenants.ListActiveTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	autoResolve := d.config.AutoAction != ""
+	if autoResolve {
+		if err := d.safeMode.Check("duplicate resolution"); err != nil {
+			slog.WarnContext(ctx, "Skipping automatic duplicate resolution", "reason", err.Error())
+			autoResolve = false
+		}
+	}
+
+	reports := make([]*Report, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return reports, ctx.Err()
+		}
+
+		report, err := d.scan(ctx, tenantID, autoResolve)
+		if err != nil {
+			slog.ErrorContext(ctx, "Duplicate scan failed for tenant", "tenant_id", tenantID, "error", err)
+			continue
+		}
+		reports = append(reports, report)
+
+		if report.Duplicates > 0 {
+			slog.InfoContext(ctx, "Found duplicate documents",
+				"tenant_id", tenantID, "clusters", len(report.Clusters), "duplicates", report.Duplicates, "resolved", report.Resolved)
+		}
+	}
+	return reports, nil
+}
+
+// Scan scans a tenant and returns its report, without resolving any clusters
+func (d *Detector) Scan(ctx context.Context, tenantID string) (*Report, error) {
+	return d.scan(ctx, tenantID, false)
+}
+
+// Report returns the tenant's latest report, or nil if it has not been scanned since
+// it last resolved or restored documents
+func (d *Detector) Report(tenantID string) *Report {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.reports[tenantID]
+}
+
+// Resolve merges or soft deletes duplicates of the canonical document
+func (d *Detector) Resolve(ctx context.Context, tenantID, canonicalID string, duplicateIDs []string, action string) error {
+	if err := ValidateAction(action); err != nil {
+		return err
+	}
+	if err := d.store.ArchiveDuplicates(ctx, tenantID, canonicalID, duplicateIDs, action); err != nil {
+		return err
+	}
+	d.forget(tenantID)
+	return nil
+}
+
+// Restore moves resolved duplicates back and returns the IDs of the documents restored
+func (d *Detector) Restore(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	restored, err := d.store.RestoreDocuments(ctx, tenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(restored) > 0 {
+		d.forget(tenantID)
+	}
+	return restored, nil
+}
+
+// scan finds a tenant's duplicates within the tenant timeout, optionally resolves them,
+// and keeps the report
+func (d *Detector) scan(ctx context.Context, tenantID string, autoResolve bool) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.TenantTimeout)
+	defer cancel()
+
+	pairs, err := d.store.FindDuplicates(ctx, tenantID, database.DuplicateScan{
+		Threshold: d.config.Threshold,
+		Neighbors: d.config.Neighbors,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		TenantID:    tenantID,
+		GeneratedAt: time.Now().UTC(),
+		Threshold:   d.config.Threshold,
+		Clusters:    BuildClusters(pairs),
+	}
+	for i := range report.Clusters {
+		cluster := &report.Clusters[i]
+		report.Duplicates += len(cluster.Duplicates)
+
+		if !autoResolve || !d.autoResolvable(cluster) {
+			continue
+		}
+		err := d.store.ArchiveDuplicates(ctx, tenantID, cluster.Canonical.ID, cluster.DuplicateIDs(), d.config.AutoAction)
+		if err != nil {
+			// The documents may have changed since the scan; the next scan retries
+			slog.WarnContext(ctx, "Failed to resolve duplicate cluster",
+				"tenant_id", tenantID, "canonical_id", cluster.Canonical.ID, "error", err)
+			continue
+		}
+		cluster.Resolution = d.config.AutoAction
+		report.Resolved += len(cluster.Duplicates)
+	}
+
+	if d.metrics != nil {
+		d.metrics.RecordDuplicateDocuments(ctx, tenantID, int64(report.Duplicates-report.Resolved))
+	}
+
+	d.mu.Lock()
+	d.reports[tenantID] = report
+	d.mu.Unlock()
+	return report, nil
+}
+
+// autoResolvable reports whether a cluster is similar enough to resolve without review
+func (d *Detector) autoResolvable(cluster *Cluster) bool {
+	if cluster.Exact {
+		return true
+	}
+	return d.config.AutoThreshold > 0 && cluster.MinSimilarity >= d.config.AutoThreshold
+}
+
+// forget drops a tenant's report once its documents were resolved or restored, so the
+// next review scans again
+func (d *Detector) forget(tenantID string) {
+	d.mu.Lock()
+	delete(d.reports, tenantID)
+	d.mu.Unlock()
+}
@@ -0,0 +1,245 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func doc(id string, age int) database.DuplicateDocument {
+	return database.DuplicateDocument{ID: id, Collection: "policies", Title: "Doc " + id, CreatedAt: base.Add(time.Duration(age) * time.Hour)}
+}
+
+// fakeDuplicateStore serves canned pairs and records archived documents
+type fakeDuplicateStore struct {
+	tenants []string
+	pairs   map[string][]database.DuplicatePair
+	failing map[string]bool
+
+	mu       sync.Mutex
+	scans    map[string]int
+	archived map[string]string // duplicate ID -> action
+}
+
+func (f *fakeDuplicateStore) ListActiveTenants(ctx context.Context) ([]string, error) {
+	return f.tenants, nil
+}
+
+func (f *fakeDuplicateStore) FindDuplicates(ctx context.Context, tenantID string, scan database.DuplicateScan) ([]database.DuplicatePair, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.scans == nil {
+		f.scans = make(map[string]int)
+	}
+	f.scans[tenantID]++
+
+	if f.failing[tenantID] {
+		return nil, errors.New("database unavailable")
+	}
+	return f.pairs[tenantID], nil
+}
+
+func (f *fakeDuplicateStore) ArchiveDuplicates(ctx context.Context, tenantID, keepID string, duplicateIDs []string, action string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.archived == nil {
+		f.archived = make(map[string]string)
+	}
+	for _, id := range duplicateIDs {
+		f.archived[id] = action
+	}
+	return nil
+}
+
+func (f *fakeDuplicateStore) RestoreDocuments(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var restored []string
+	for _, id := range ids {
+		if _, ok := f.archived[id]; ok {
+			delete(f.archived, id)
+			restored = append(restored, id)
+		}
+	}
+	return restored, nil
+}
+
+func (f *fakeDuplicateStore) scanCount(tenantID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.scans[tenantID]
+}
+
+func TestBuildClusters(t *testing.T) {
+	clusters := BuildClusters([]database.DuplicatePair{
+		// a <- b exact, b ~ c: one cluster around the oldest document a
+		{First: doc("a", 0), Second: doc("b", 1), Similarity: 1, Exact: true},
+		{First: doc("b", 1), Second: doc("c", 2), Similarity: 0.96},
+		// d ~ e, listed newest first
+		{First: doc("e", 4), Second: doc("d", 3), Similarity: 0.97},
+	})
+
+	require.Len(t, clusters, 2)
+
+	assert.Equal(t, "a", clusters[0].Canonical.ID)
+	assert.Equal(t, []string{"b", "c"}, clusters[0].DuplicateIDs())
+	assert.False(t, clusters[0].Exact)
+	assert.Equal(t, 0.96, clusters[0].MinSimilarity)
+	assert.True(t, clusters[0].Duplicates[0].Exact)
+	assert.Equal(t, 1.0, clusters[0].Duplicates[0].Similarity)
+	assert.False(t, clusters[0].Duplicates[1].Exact)
+	assert.Equal(t, 0.96, clusters[0].Duplicates[1].Similarity)
+
+	assert.Equal(t, "d", clusters[1].Canonical.ID)
+	assert.Equal(t, []string{"e"}, clusters[1].DuplicateIDs())
+
+	assert.Empty(t, BuildClusters(nil))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+
+	cfg := DefaultConfig()
+	cfg.AutoAction = database.DuplicateMerge
+	cfg.AutoThreshold = 0.99
+	assert.NoError(t, cfg.Validate())
+
+	cfg.AutoAction = "delete"
+	cfg.AutoThreshold = 0.5
+	cfg.Threshold = 1.5
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auto_action")
+	assert.Contains(t, err.Error(), "auto_threshold")
+	assert.Contains(t, err.Error(), "threshold must be between 0 and 1")
+}
+
+func TestDetector_ScanAll(t *testing.T) {
+	store := &fakeDuplicateStore{
+		tenants: []string{"tenant-a", "tenant-b", "tenant-c"},
+		pairs: map[string][]database.DuplicatePair{
+			"tenant-a": {
+				{First: doc("a", 0), Second: doc("b", 1), Similarity: 1, Exact: true},
+				{First: doc("c", 2), Second: doc("d", 3), Similarity: 0.96},
+			},
+		},
+		failing: map[string]bool{"tenant-b": true},
+	}
+
+	detector := NewDetector(store, store, nil, DefaultConfig())
+	reports, err := detector.ScanAll(context.Background())
+	require.NoError(t, err)
+
+	// The failing tenant is skipped without stopping the others
+	require.Len(t, reports, 2)
+	assert.Equal(t, "tenant-a", reports[0].TenantID)
+	assert.Len(t, reports[0].Clusters, 2)
+	assert.Equal(t, 2, reports[0].Duplicates)
+	assert.Zero(t, reports[0].Resolved)
+	assert.Empty(t, store.archived, "clusters are only reported without an automatic action")
+	assert.Equal(t, "tenant-c", reports[1].TenantID)
+	assert.Equal(t, 1, store.scanCount("tenant-b"))
+
+	assert.Same(t, reports[0], detector.Report("tenant-a"))
+	assert.Nil(t, detector.Report("tenant-b"))
+}
+
+func TestDetector_AutoResolve(t *testing.T) {
+	pairs := []database.DuplicatePair{
+		{First: doc("a", 0), Second: doc("b", 1), Similarity: 1, Exact: true},
+		{First: doc("c", 2), Second: doc("d", 3), Similarity: 0.96},
+		{First: doc("e", 4), Second: doc("f", 5), Similarity: 0.995},
+	}
+
+	t.Run("exact duplicates only", func(t *testing.T) {
+		store := &fakeDuplicateStore{tenants: []string{"tenant-a"}, pairs: map[string][]database.DuplicatePair{"tenant-a": pairs}}
+		cfg := DefaultConfig()
+		cfg.AutoAction = database.DuplicateSoftDelete
+		detector := NewDetector(store, store, nil, cfg)
+
+		reports, err := detector.ScanAll(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"b": database.DuplicateSoftDelete}, store.archived)
+		assert.Equal(t, 1, reports[0].Resolved)
+		assert.Equal(t, database.DuplicateSoftDelete, reports[0].Clusters[0].Resolution)
+		assert.Empty(t, reports[0].Clusters[1].Resolution)
+	})
+
+	t.Run("near-duplicates above the auto threshold", func(t *testing.T) {
+		store := &fakeDuplicateStore{tenants: []string{"tenant-a"}, pairs: map[string][]database.DuplicatePair{"tenant-a": pairs}}
+		cfg := DefaultConfig()
+		cfg.AutoAction = database.DuplicateMerge
+		cfg.AutoThreshold = 0.99
+		detector := NewDetector(store, store, nil, cfg)
+
+		_, err := detector.ScanAll(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"b": database.DuplicateMerge, "f": database.DuplicateMerge}, store.archived)
+	})
+
+	t.Run("skipped in safe mode", func(t *testing.T) {
+		store := &fakeDuplicateStore{tenants: []string{"tenant-a"}, pairs: map[string][]database.DuplicatePair{"tenant-a": pairs}}
+		cfg := DefaultConfig()
+		cfg.AutoAction = database.DuplicateMerge
+		detector := NewDetector(store, store, nil, cfg)
+		safeMode, err := safemode.New(context.Background(), safemode.NewMemoryStore())
+		require.NoError(t, err)
+		_, err = safeMode.Set(context.Background(), true, "incident", "oncall")
+		require.NoError(t, err)
+		detector.SetSafeMode(safeMode)
+
+		reports, err := detector.ScanAll(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, store.archived)
+		assert.Equal(t, 3, reports[0].Duplicates, "duplicates are still reported")
+	})
+}
+
+func TestDetector_ResolveAndRestore(t *testing.T) {
+	store := &fakeDuplicateStore{
+		tenants: []string{"tenant-a"},
+		pairs: map[string][]database.DuplicatePair{
+			"tenant-a": {{First: doc("a", 0), Second: doc("b", 1), Similarity: 0.97}},
+		},
+	}
+	detector := NewDetector(store, store, nil, DefaultConfig())
+	ctx := context.Background()
+
+	_, err := detector.Scan(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.NotNil(t, detector.Report("tenant-a"))
+
+	assert.Error(t, detector.Resolve(ctx, "tenant-a", "a", []string{"b"}, "delete"))
+	require.NoError(t, detector.Resolve(ctx, "tenant-a", "a", []string{"b"}, database.DuplicateMerge))
+	assert.Equal(t, map[string]string{"b": database.DuplicateMerge}, store.archived)
+	assert.Nil(t, detector.Report("tenant-a"), "resolving drops the outdated report")
+
+	restored, err := detector.Restore(ctx, "tenant-a", []string{"b", "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, restored)
+	assert.Empty(t, store.archived)
+}
+
+func TestDetector_StartAndClose(t *testing.T) {
+	store := &fakeDuplicateStore{tenants: []string{"tenant-a"}}
+	detector := NewDetector(store, store, nil, Config{Interval: 10 * time.Millisecond})
+
+	detector.Start()
+	assert.Eventually(t, func() bool { return store.scanCount("tenant-a") >= 2 }, time.Second, 5*time.Millisecond)
+	detector.Close()
+
+	// No scans run after Close returns
+	count := store.scanCount("tenant-a")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, store.scanCount("tenant-a"))
+	detector.Close()
+}
@@ -0,0 +1,162 @@
+// Package deprecation marks HTTP endpoints, JSON-RPC methods and tools as deprecated.
+// Callers of a deprecated surface get Deprecation (RFC 9745), Sunset (RFC 8594) and Link
+// headers, JSON-RPC responses also carry a warning, and every use is counted so
+// maintainers can tell when nobody calls it anymore.
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of surface being deprecated
+type Kind string
+
+const (
+	KindEndpoint Kind = "endpoint" // an HTTP path, or a path prefix ending in "/"
+	KindMethod   Kind = "method"   // a JSON-RPC method such as "resources/read"
+	KindTool     Kind = "tool"     // an MCP tool name
+)
+
+// Notice describes a deprecation
+type Notice struct {
+	// Since is when the surface was deprecated; zero emits "Deprecation: true"
+	Since time.Time
+	// Sunset is when the surface will be removed; zero means no date has been set
+	Sunset time.Time
+	// Replacement names what callers should use instead, if anything
+	Replacement string
+	// Link points to migration documentation
+	Link string
+}
+
+// Message describes the deprecation for humans, e.g. in a JSON-RPC warning
+func (n Notice) Message(kind Kind, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s is deprecated", kind, name)
+	if !n.Sunset.IsZero() {
+		fmt.Fprintf(&b, " and will be removed after %s", n.Sunset.UTC().Format(time.DateOnly))
+	}
+	if n.Replacement != "" {
+		fmt.Fprintf(&b, "; use %s instead", n.Replacement)
+	}
+	return b.String()
+}
+
+// WriteHeaders adds the Deprecation, Sunset and Link headers for a notice. When a
+// response covers several deprecated surfaces the earliest sunset wins.
+func WriteHeaders(h http.Header, n Notice) {
+	if n.Since.IsZero() {
+		if h.Get("Deprecation") == "" {
+			h.Set("Deprecation", "true")
+		}
+	} else {
+		h.Set("Deprecation", fmt.Sprintf("@%d", n.Since.Unix()))
+	}
+	if !n.Sunset.IsZero() {
+		existing, err := http.ParseTime(h.Get("Sunset"))
+		if err != nil || n.Sunset.Before(existing) {
+			h.Set("Sunset", n.Sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+	if n.Link != "" {
+		h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, n.Link))
+	}
+}
+
+// Recorder counts uses of deprecated surfaces
+type Recorder interface {
+	RecordDeprecatedUsage(ctx context.Context, kind, name string)
+}
+
+// Registry holds the deprecated surfaces of a server
+type Registry struct {
+	mu       sync.RWMutex
+	notices  map[Kind]map[string]Notice
+	recorder Recorder
+}
+
+// NewRegistry creates an empty deprecation registry
+func NewRegistry() *Registry {
+	return &Registry{notices: make(map[Kind]map[string]Notice)}
+}
+
+// SetRecorder counts every use of a deprecated surface
+func (r *Registry) SetRecorder(recorder Recorder) {
+	r.recorder = recorder
+}
+
+// Deprecate marks a surface as deprecated
+func (r *Registry) Deprecate(kind Kind, name string, notice Notice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.notices[kind] == nil {
+		r.notices[kind] = make(map[string]Notice)
+	}
+	r.notices[kind][name] = notice
+}
+
+// Lookup returns the notice of a deprecated surface. Endpoints also match a deprecated
+// prefix ending in "/", the longest one winning.
+func (r *Registry) Lookup(kind Kind, name string) (Notice, bool) {
+	_, notice, ok := r.lookup(kind, name)
+	return notice, ok
+}
+
+// lookup returns the registered name that matched along with its notice
+func (r *Registry) lookup(kind Kind, name string) (string, Notice, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	notices := r.notices[kind]
+	if notice, ok := notices[name]; ok {
+		return name, notice, true
+	}
+	if kind != KindEndpoint {
+		return "", Notice{}, false
+	}
+
+	var match string
+	for prefix := range notices {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(name, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return "", Notice{}, false
+	}
+	return match, notices[match], true
+}
+
+// Use looks up a surface and, if it is deprecated, records and logs the call. Uses of
+// an endpoint prefix are counted under the prefix.
+func (r *Registry) Use(ctx context.Context, kind Kind, name string) (Notice, bool) {
+	matched, notice, ok := r.lookup(kind, name)
+	if !ok {
+		return Notice{}, false
+	}
+	if r.recorder != nil {
+		r.recorder.RecordDeprecatedUsage(ctx, string(kind), matched)
+	}
+	slog.InfoContext(ctx, "deprecated surface used", "kind", kind, "name", matched, "replacement", notice.Replacement)
+	return notice, true
+}
+
+// Handler adds deprecation headers to responses from deprecated endpoints
+func (r *Registry) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		notice, ok := r.Lookup(KindEndpoint, req.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+		WriteHeaders(w.Header(), notice)
+		next.ServeHTTP(w, req)
+		// Logged afterwards so the entry carries the tenant set during authentication
+		r.Use(req.Context(), KindEndpoint, req.URL.Path)
+	})
+}
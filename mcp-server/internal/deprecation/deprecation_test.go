@@ -0,0 +1,85 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingRecorder counts uses per kind and name
+type countingRecorder map[string]int
+
+func (c countingRecorder) RecordDeprecatedUsage(ctx context.Context, kind, name string) {
+	c[kind+":"+name]++
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	registry := NewRegistry()
+	registry.Deprecate(KindEndpoint, "/v1/", Notice{Replacement: "/v2/"})
+	registry.Deprecate(KindEndpoint, "/v1/search/", Notice{Replacement: "/mcp"})
+	registry.Deprecate(KindEndpoint, "/health", Notice{})
+	registry.Deprecate(KindTool, "search_documents", Notice{})
+
+	notice, ok := registry.Lookup(KindEndpoint, "/v1/search/fast")
+	assert.True(t, ok)
+	assert.Equal(t, "/mcp", notice.Replacement, "longest prefix wins")
+	notice, ok = registry.Lookup(KindEndpoint, "/v1/documents")
+	assert.True(t, ok)
+	assert.Equal(t, "/v2/", notice.Replacement)
+
+	_, ok = registry.Lookup(KindEndpoint, "/health")
+	assert.True(t, ok)
+	_, ok = registry.Lookup(KindEndpoint, "/healthz")
+	assert.False(t, ok, "only names ending in / are prefixes")
+	_, ok = registry.Lookup(KindTool, "search_documents")
+	assert.True(t, ok)
+	_, ok = registry.Lookup(KindMethod, "search_documents")
+	assert.False(t, ok, "kinds are separate")
+}
+
+func TestWriteHeaders(t *testing.T) {
+	header := http.Header{}
+	WriteHeaders(header, Notice{})
+	assert.Equal(t, "true", header.Get("Deprecation"))
+	assert.Empty(t, header.Get("Sunset"))
+
+	later := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	WriteHeaders(header, Notice{Since: time.Unix(1767225600, 0), Sunset: later, Link: "https://example.com/a"})
+	WriteHeaders(header, Notice{Sunset: earlier, Link: "https://example.com/b"})
+	WriteHeaders(header, Notice{Sunset: later})
+
+	assert.Equal(t, "@1767225600", header.Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Dec 2026 00:00:00 GMT", header.Get("Sunset"), "earliest sunset wins")
+	assert.Len(t, header.Values("Link"), 2)
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	recorder := countingRecorder{}
+	registry := NewRegistry()
+	registry.SetRecorder(recorder)
+	registry.Deprecate(KindEndpoint, "/admin/legacy/", Notice{Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	handler := registry.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	rr := serve("/admin/legacy/items/1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", rr.Header().Get("Sunset"))
+	serve("/admin/legacy/items/2")
+
+	rr = serve("/mcp")
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+	assert.Equal(t, countingRecorder{"endpoint:/admin/legacy/": 2}, recorder, "counted under the prefix")
+}
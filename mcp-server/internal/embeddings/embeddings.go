@@ -0,0 +1,132 @@
+// Package embeddings computes embedding vectors for text, such as search queries given
+// without an embedding, with an OpenAI-compatible embeddings API.
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Providers selectable in Config.Provider
+const (
+	// ProviderNone disables server-side embeddings
+	ProviderNone = ""
+	// ProviderOpenAI calls the OpenAI embeddings API or a compatible server such as
+	// Ollama or vLLM
+	ProviderOpenAI = "openai"
+)
+
+// Defaults for the OpenAI provider; ada-002 embeddings have the 1536 dimensions of the
+// documents table
+const (
+	DefaultURL     = "https://api.openai.com/v1"
+	DefaultModel   = "text-embedding-ada-002"
+	DefaultTimeout = 10 * time.Second
+)
+
+// maxResponseBytes bounds the response read from the embeddings API
+const maxResponseBytes = 4 << 20
+
+// Provider computes the embedding of a text
+type Provider interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// Config selects and configures the embeddings provider
+type Config struct {
+	Provider string `yaml:"provider"`
+	// URL is the API's base URL; requests go to {URL}/embeddings
+	URL     string        `yaml:"url"`
+	Model   string        `yaml:"model"`
+	APIKey  string        `yaml:"api_key"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	switch c.Provider {
+	case ProviderNone:
+		return nil
+	case ProviderOpenAI:
+	default:
+		return fmt.Errorf("provider must be %q or empty, got %q", ProviderOpenAI, c.Provider)
+	}
+	if c.URL == "" || c.Model == "" {
+		return errors.New("url and model are required")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", c.Timeout)
+	}
+	return nil
+}
+
+// New creates the configured provider; it returns nil for ProviderNone
+func New(config Config) (Provider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Provider == ProviderNone {
+		return nil, nil
+	}
+	return NewOpenAI(config), nil
+}
+
+// OpenAI calls an OpenAI-compatible embeddings API
+type OpenAI struct {
+	config Config
+	client *http.Client
+}
+
+var _ Provider = (*OpenAI)(nil)
+
+// NewOpenAI creates a client for the embeddings API config locates
+func NewOpenAI(config Config) *OpenAI {
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return &OpenAI{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Embed implements Provider
+func (p *OpenAI) Embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": p.config.Model, "input": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embeddings API returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid embeddings response: %w", err)
+	}
+	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
+		return nil, errors.New("embeddings API returned no embedding")
+	}
+	return result.Data[0].Embedding, nil
+}
@@ -0,0 +1,63 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAI_Embed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"model": "nomic-embed-text", "input": "deep learning"}, body)
+		w.Write([]byte(`{"data": [{"embedding": [0.25, -0.5]}]}`))
+	}))
+	defer srv.Close()
+
+	provider, err := New(Config{Provider: ProviderOpenAI, URL: srv.URL + "/v1/", Model: "nomic-embed-text", APIKey: "sk-test", Timeout: DefaultTimeout})
+	require.NoError(t, err)
+	embedding, err := provider.Embed(context.Background(), "deep learning")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.25, -0.5}, embedding)
+}
+
+func TestOpenAI_EmbedErrors(t *testing.T) {
+	status := http.StatusTooManyRequests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, "quota exceeded", status)
+			return
+		}
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer srv.Close()
+
+	provider := NewOpenAI(Config{URL: srv.URL, Model: DefaultModel, Timeout: DefaultTimeout})
+	_, err := provider.Embed(context.Background(), "query")
+	assert.ErrorContains(t, err, "429 Too Many Requests: quota exceeded")
+
+	status = http.StatusOK
+	_, err = provider.Embed(context.Background(), "query")
+	assert.ErrorContains(t, err, "no embedding")
+}
+
+func TestNew(t *testing.T) {
+	provider, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, provider, "embeddings are off by default")
+
+	_, err = New(Config{Provider: "cohere", URL: DefaultURL, Model: DefaultModel, Timeout: DefaultTimeout})
+	assert.Error(t, err)
+	_, err = New(Config{Provider: ProviderOpenAI, Model: DefaultModel, Timeout: DefaultTimeout})
+	assert.Error(t, err, "url is required")
+	_, err = New(Config{Provider: ProviderOpenAI, URL: DefaultURL, Model: DefaultModel})
+	assert.Error(t, err, "timeout must be positive")
+}
@@ -0,0 +1,115 @@
+package gdpr
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A2AUserData is what the A2A server keeps about a user, as exported by its
+// GET /admin/users/{user_id}/data endpoint
+type A2AUserData struct {
+	Tasks     []json.RawMessage `json:"tasks"`
+	Usage     []json.RawMessage `json:"usage"`
+	Schedules []json.RawMessage `json:"schedules"`
+}
+
+// A2AUserErasure counts what the A2A server deleted, or handed to the pseudonym
+type A2AUserErasure struct {
+	Tasks     int `json:"tasks"`
+	Usage     int `json:"usage"`
+	Schedules int `json:"schedules"`
+}
+
+// A2ASource covers the A2A server's tasks, usage records and schedules through its admin
+// endpoint, authenticating with the server's admin token. A2A users are not scoped to a
+// tenant, so the subject's data is exported and erased whatever tenant it was created
+// for. The A2A server refuses erasure while any of the user's tasks is unfinished.
+type A2ASource struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewA2ASource creates a source for the A2A server at baseURL
+func NewA2ASource(baseURL, token string) *A2ASource {
+	return &A2ASource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SetTLSConfig sets the TLS configuration of the connections to the A2A server, e.g. to
+// present a client certificate
+func (s *A2ASource) SetTLSConfig(config *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	s.client.Transport = transport
+}
+
+// Name implements Source
+func (s *A2ASource) Name() string {
+	return "a2a"
+}
+
+// Export implements Source
+func (s *A2ASource) Export(ctx context.Context, subject Subject) (*SourceExport, error) {
+	var data A2AUserData
+	if err := s.do(ctx, http.MethodGet, subject.UserID, "", &data); err != nil {
+		return nil, err
+	}
+	return &SourceExport{
+		Records: len(data.Tasks) + len(data.Usage) + len(data.Schedules),
+		Data:    data,
+	}, nil
+}
+
+// Erase implements Source
+func (s *A2ASource) Erase(ctx context.Context, subject Subject, mode Mode, pseudonym string) (*SourceErasure, error) {
+	if mode == ModeDelete {
+		pseudonym = ""
+	}
+	var erasure A2AUserErasure
+	if err := s.do(ctx, http.MethodDelete, subject.UserID, pseudonym, &erasure); err != nil {
+		return nil, err
+	}
+	return &SourceErasure{
+		Records: erasure.Tasks + erasure.Usage + erasure.Schedules,
+		Details: erasure,
+	}, nil
+}
+
+// do calls the user data endpoint and decodes its response into result
+func (s *A2ASource) do(ctx context.Context, method, userID, pseudonym string, result interface{}) error {
+	endpoint := s.baseURL + "/admin/users/" + url.PathEscape(userID) + "/data"
+	if pseudonym != "" {
+		endpoint += "?pseudonym=" + url.QueryEscape(pseudonym)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the A2A server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("A2A user data request failed: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode A2A user data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package gdpr
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Mode selects how an erasure treats a user's data
+type Mode string
+
+const (
+	// ModeDelete removes the user's data entirely
+	ModeDelete Mode = "delete"
+	// ModeAnonymize replaces the user's identity with a random pseudonym and keeps the data
+	ModeAnonymize Mode = "anonymize"
+)
+
+// Valid reports whether the mode is supported
+func (m Mode) Valid() bool {
+	return m == ModeDelete || m == ModeAnonymize
+}
+
+// Subject identifies the user a data request is about
+type Subject struct {
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+}
+
+// SourceExport is the data a source holds about a subject
+type SourceExport struct {
+	Records int         `json:"records"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// SourceErasure describes what a source removed or pseudonymized
+type SourceErasure struct {
+	Records int         `json:"records"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Source is a store that holds user data (Postgres, Redis, blob storage, ...)
+type Source interface {
+	// Name identifies the source in archives and reports
+	Name() string
+
+	// Export returns everything the source holds about the subject
+	Export(ctx context.Context, subject Subject) (*SourceExport, error)
+
+	// Erase deletes the subject's data, or replaces the user ID with pseudonym in anonymize mode
+	Erase(ctx context.Context, subject Subject, mode Mode, pseudonym string) (*SourceErasure, error)
+}
+
+// Manifest describes the contents of an export archive
+type Manifest struct {
+	Subject     Subject          `json:"subject"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Sources     []ManifestSource `json:"sources"`
+}
+
+// ManifestSource describes one source file in an export archive
+type ManifestSource struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Records int    `json:"records"`
+}
+
+// SourceReport is the per-source result of an erasure
+type SourceReport struct {
+	Name      string      `json:"name"`
+	Records   int         `json:"records"`
+	Details   interface{} `json:"details,omitempty"`
+	Remaining int         `json:"remaining"`
+	Verified  bool        `json:"verified"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// ErasureReport is the verification report returned by an erasure
+type ErasureReport struct {
+	Subject     Subject        `json:"subject"`
+	Mode        Mode           `json:"mode"`
+	StartedAt   time.Time      `json:"started_at"`
+	CompletedAt time.Time      `json:"completed_at"`
+	Sources     []SourceReport `json:"sources"`
+	Verified    bool           `json:"verified"`
+}
+
+// Service runs data subject export and erasure requests across all registered sources
+type Service struct {
+	sources []Source
+}
+
+// NewService creates a new GDPR service over the given sources
+func NewService(sources ...Source) *Service {
+	return &Service{sources: sources}
+}
+
+// WriteArchive writes a zip archive with one JSON file per source and a manifest
+func (s *Service) WriteArchive(ctx context.Context, subject Subject, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{
+		Subject:     subject,
+		GeneratedAt: time.Now().UTC(),
+		Sources:     make([]ManifestSource, 0, len(s.sources)),
+	}
+
+	archive := zip.NewWriter(w)
+	for _, source := range s.sources {
+		export, err := source.Export(ctx, subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export from %s: %w", source.Name(), err)
+		}
+
+		file := source.Name() + ".json"
+		if err := writeJSON(archive, file, export.Data); err != nil {
+			return nil, err
+		}
+
+		manifest.Sources = append(manifest.Sources, ManifestSource{
+			Name:    source.Name(),
+			File:    file,
+			Records: export.Records,
+		})
+	}
+
+	if err := writeJSON(archive, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Erase erases the subject's data from every source and verifies that nothing
+// is left by exporting again afterwards. A failing source does not stop the
+// remaining sources; it is reported and leaves the report unverified.
+func (s *Service) Erase(ctx context.Context, subject Subject, mode Mode) (*ErasureReport, error) {
+	if !mode.Valid() {
+		return nil, fmt.Errorf("unsupported erasure mode: %q", mode)
+	}
+
+	var pseudonym string
+	if mode == ModeAnonymize {
+		var err error
+		if pseudonym, err = newPseudonym(); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &ErasureReport{
+		Subject:   subject,
+		Mode:      mode,
+		StartedAt: time.Now().UTC(),
+		Sources:   make([]SourceReport, 0, len(s.sources)),
+		Verified:  true,
+	}
+
+	for _, source := range s.sources {
+		result := SourceReport{Name: source.Name()}
+
+		erasure, err := source.Erase(ctx, subject, mode, pseudonym)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Records = erasure.Records
+			result.Details = erasure.Details
+
+			remaining, err := source.Export(ctx, subject)
+			if err != nil {
+				result.Error = fmt.Sprintf("verification failed: %v", err)
+			} else {
+				result.Remaining = remaining.Records
+				result.Verified = remaining.Records == 0
+			}
+		}
+
+		if !result.Verified {
+			report.Verified = false
+		}
+		report.Sources = append(report.Sources, result)
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	return report, nil
+}
+
+// newPseudonym returns a random replacement user ID that cannot be linked back to the user
+func newPseudonym() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate pseudonym: %w", err)
+	}
+	return "erased-" + hex.EncodeToString(buf), nil
+}
+
+// writeJSON adds a pretty-printed JSON file to the archive
+func writeJSON(archive *zip.Writer, name string, value interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package gdpr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUserDataStore keeps user data in memory, keyed by user ID
+type fakeUserDataStore struct {
+	documents map[string][]*storage.Document
+	usage     map[string][]database.UsageRecord
+	eraseErr  error
+}
+
+func newFakeUserDataStore() *fakeUserDataStore {
+	return &fakeUserDataStore{
+		documents: map[string][]*storage.Document{
+			"user-1": {{ID: "doc-1", TenantID: "tenant-1", Title: "Notes"}},
+		},
+		usage: map[string][]database.UsageRecord{
+			"user-1": {{ID: "usage-1", TenantID: "tenant-1", UserID: "user-1", Operation: "search"}},
+		},
+	}
+}
+
+func (f *fakeUserDataStore) ExportUserData(ctx context.Context, tenantID, userID string) (*database.UserData, error) {
+	return &database.UserData{
+		Documents:    f.documents[userID],
+		UsageRecords: f.usage[userID],
+	}, nil
+}
+
+func (f *fakeUserDataStore) EraseUserData(ctx context.Context, tenantID, userID, pseudonym string) (*database.UserErasure, error) {
+	if f.eraseErr != nil {
+		return nil, f.eraseErr
+	}
+
+	erasure := &database.UserErasure{UsageRecords: len(f.usage[userID])}
+	for _, doc := range f.documents[userID] {
+		erasure.DocumentIDs = append(erasure.DocumentIDs, doc.ID)
+	}
+
+	if pseudonym == "" {
+		erasure.DocumentsDeleted = len(erasure.DocumentIDs)
+	} else {
+		erasure.DocumentsUpdated = len(erasure.DocumentIDs)
+		f.documents[pseudonym] = f.documents[userID]
+		f.usage[pseudonym] = f.usage[userID]
+	}
+	delete(f.documents, userID)
+	delete(f.usage, userID)
+	return erasure, nil
+}
+
+type fakeInvalidator struct {
+	tenants []string
+}
+
+func (f *fakeInvalidator) InvalidateTenant(ctx context.Context, tenantID, docID string) {
+	f.tenants = append(f.tenants, tenantID)
+}
+
+func TestService_WriteArchive(t *testing.T) {
+	service := NewService(NewPostgresSource(newFakeUserDataStore()), NewCacheSource(&fakeInvalidator{}))
+
+	var buf bytes.Buffer
+	manifest, err := service.WriteArchive(context.Background(), Subject{TenantID: "tenant-1", UserID: "user-1"}, &buf)
+	require.NoError(t, err)
+	require.Len(t, manifest.Sources, 2)
+	assert.Equal(t, 2, manifest.Sources[0].Records)
+	assert.Equal(t, 0, manifest.Sources[1].Records)
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+
+	assert.Contains(t, files, "manifest.json")
+	assert.Contains(t, files, "redis.json")
+	require.Contains(t, files, "postgres.json")
+
+	var exported database.UserData
+	require.NoError(t, json.Unmarshal([]byte(files["postgres.json"]), &exported))
+	require.Len(t, exported.Documents, 1)
+	assert.Equal(t, "doc-1", exported.Documents[0].ID)
+	assert.Len(t, exported.UsageRecords, 1)
+}
+
+func TestService_Erase(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"delete", ModeDelete},
+		{"anonymize", ModeAnonymize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeUserDataStore()
+			invalidator := &fakeInvalidator{}
+			service := NewService(NewPostgresSource(store), NewCacheSource(invalidator))
+
+			report, err := service.Erase(context.Background(), Subject{TenantID: "tenant-1", UserID: "user-1"}, tt.mode)
+			require.NoError(t, err)
+
+			assert.True(t, report.Verified)
+			assert.Equal(t, tt.mode, report.Mode)
+			require.Len(t, report.Sources, 2)
+			assert.Equal(t, "postgres", report.Sources[0].Name)
+			assert.Equal(t, 2, report.Sources[0].Records)
+			assert.Equal(t, 0, report.Sources[0].Remaining)
+			assert.Equal(t, []string{"tenant-1"}, invalidator.tenants)
+
+			if tt.mode == ModeAnonymize {
+				require.Len(t, store.documents, 1)
+				for pseudonym := range store.documents {
+					assert.True(t, strings.HasPrefix(pseudonym, "erased-"))
+				}
+			} else {
+				assert.Empty(t, store.documents)
+			}
+		})
+	}
+}
+
+func TestService_Erase_SourceFailure(t *testing.T) {
+	store := newFakeUserDataStore()
+	store.eraseErr = errors.New("connection refused")
+	invalidator := &fakeInvalidator{}
+	service := NewService(NewPostgresSource(store), NewCacheSource(invalidator))
+
+	report, err := service.Erase(context.Background(), Subject{TenantID: "tenant-1", UserID: "user-1"}, ModeDelete)
+	require.NoError(t, err)
+
+	assert.False(t, report.Verified)
+	assert.Equal(t, "connection refused", report.Sources[0].Error)
+	assert.True(t, report.Sources[1].Verified, "later sources should still be erased")
+	assert.Len(t, invalidator.tenants, 1)
+}
+
+func TestService_Erase_InvalidMode(t *testing.T) {
+	service := NewService()
+
+	_, err := service.Erase(context.Background(), Subject{TenantID: "tenant-1", UserID: "user-1"}, Mode("shred"))
+	assert.Error(t, err)
+}
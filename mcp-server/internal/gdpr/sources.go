@@ -0,0 +1,157 @@
+package gdpr
+
+import (
+	"context"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// PostgresSource exports and erases documents, usage records, access log and audit log
+// entries
+type PostgresSource struct {
+	store database.UserDataStore
+}
+
+// NewPostgresSource creates a source backed by the relational store
+func NewPostgresSource(store database.UserDataStore) *PostgresSource {
+	return &PostgresSource{store: store}
+}
+
+// Name implements Source
+func (s *PostgresSource) Name() string {
+	return "postgres"
+}
+
+// Export implements Source
+func (s *PostgresSource) Export(ctx context.Context, subject Subject) (*SourceExport, error) {
+	data, err := s.store.ExportUserData(ctx, subject.TenantID, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SourceExport{
+		Records: len(data.Documents) + len(data.UsageRecords) + len(data.AccessLog) + len(data.AuditLog),
+		Data:    data,
+	}, nil
+}
+
+// Erase implements Source
+func (s *PostgresSource) Erase(ctx context.Context, subject Subject, mode Mode, pseudonym string) (*SourceErasure, error) {
+	if mode == ModeDelete {
+		pseudonym = ""
+	}
+
+	erasure, err := s.store.EraseUserData(ctx, subject.TenantID, subject.UserID, pseudonym)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SourceErasure{
+		Records: len(erasure.DocumentIDs) + erasure.UsageRecords + erasure.AccessLogEntries + erasure.AuditLogEntries,
+		Details: erasure,
+	}, nil
+}
+
+// TenantInvalidator drops cached data for a tenant, e.g. cache.SearchCache
+type TenantInvalidator interface {
+	InvalidateTenant(ctx context.Context, tenantID, docID string)
+}
+
+// CacheSource covers Redis. Nothing in Redis is keyed by user, but cached search
+// results may still contain erased documents, so erasure invalidates the tenant's cache.
+type CacheSource struct {
+	cache TenantInvalidator
+}
+
+// NewCacheSource creates a source for the Redis search cache
+func NewCacheSource(cache TenantInvalidator) *CacheSource {
+	return &CacheSource{cache: cache}
+}
+
+// Name implements Source
+func (s *CacheSource) Name() string {
+	return "redis"
+}
+
+// Export implements Source. Cached entries are copies of Postgres data, so there is nothing to add.
+func (s *CacheSource) Export(ctx context.Context, subject Subject) (*SourceExport, error) {
+	return &SourceExport{Data: []interface{}{}}, nil
+}
+
+// Erase implements Source
+func (s *CacheSource) Erase(ctx context.Context, subject Subject, mode Mode, pseudonym string) (*SourceErasure, error) {
+	s.cache.InvalidateTenant(ctx, subject.TenantID, "")
+	return &SourceErasure{Details: map[string]string{"search_cache": "invalidated"}}, nil
+}
+
+// BlobOwners finds and releases the objects users stored, e.g. blobs.ContentStore
+type BlobOwners interface {
+	Owned(ctx context.Context, tenantID, userID string) ([]string, error)
+	Disown(ctx context.Context, tenantID, userID, newOwner string) (int, error)
+}
+
+// BlobSource covers blob storage: the images offloaded from the user's tool results.
+// Objects are stored once per tenant by content hash, so erasure only deletes the objects
+// no other user of the tenant stored too.
+type BlobSource struct {
+	blobs BlobOwners
+}
+
+// NewBlobSource creates a source for the content-addressed blob store
+func NewBlobSource(blobs BlobOwners) *BlobSource {
+	return &BlobSource{blobs: blobs}
+}
+
+// Name implements Source
+func (s *BlobSource) Name() string {
+	return "blobs"
+}
+
+// Export implements Source. The objects themselves are served by hash from /blobs/.
+func (s *BlobSource) Export(ctx context.Context, subject Subject) (*SourceExport, error) {
+	hashes, err := s.blobs.Owned(ctx, subject.TenantID, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if hashes == nil {
+		hashes = []string{}
+	}
+	return &SourceExport{Records: len(hashes), Data: map[string][]string{"hashes": hashes}}, nil
+}
+
+// Erase implements Source
+func (s *BlobSource) Erase(ctx context.Context, subject Subject, mode Mode, pseudonym string) (*SourceErasure, error) {
+	if mode == ModeDelete {
+		pseudonym = ""
+	}
+	n, err := s.blobs.Disown(ctx, subject.TenantID, subject.UserID, pseudonym)
+	if err != nil {
+		return nil, err
+	}
+	return &SourceErasure{Records: n}, nil
+}
+
+// CostSource covers the cost tracker. It keeps daily totals per tenant, tool and model,
+// none of them per user, so there is nothing to export or erase; the source records that
+// the tracker was checked.
+type CostSource struct{}
+
+// NewCostSource creates a source for the embedding and LLM cost tracker
+func NewCostSource() *CostSource {
+	return &CostSource{}
+}
+
+// Name implements Source
+func (s *CostSource) Name() string {
+	return "costs"
+}
+
+// Export implements Source
+func (s *CostSource) Export(ctx context.Context, subject Subject) (*SourceExport, error) {
+	return &SourceExport{Data: []interface{}{}}, nil
+}
+
+// Erase implements Source
+func (s *CostSource) Erase(ctx context.Context, subject Subject, mode Mode, pseudonym string) (*SourceErasure, error) {
+	return &SourceErasure{Details: map[string]string{"cost_totals": "kept, not attributed to users"}}, nil
+}
@@ -0,0 +1,102 @@
+package gdpr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobSource(t *testing.T) {
+	ctx := context.Background()
+	files, err := blobs.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	store := blobs.NewContentStore(files)
+	own, err := store.Put(ctx, "tenant-1", "user-1", []byte("chart of user-1"))
+	require.NoError(t, err)
+	shared, err := store.Put(ctx, "tenant-1", "user-1", []byte("shared chart"))
+	require.NoError(t, err)
+	_, err = store.Put(ctx, "tenant-1", "user-2", []byte("shared chart"))
+	require.NoError(t, err)
+
+	source := NewBlobSource(store)
+	service := NewService(source)
+	subject := Subject{TenantID: "tenant-1", UserID: "user-1"}
+	export, err := source.Export(ctx, subject)
+	require.NoError(t, err)
+	assert.Equal(t, 2, export.Records)
+	assert.ElementsMatch(t, []string{own, shared}, export.Data.(map[string][]string)["hashes"])
+
+	report, err := service.Erase(ctx, subject, ModeDelete)
+	require.NoError(t, err)
+	assert.True(t, report.Verified)
+	assert.Equal(t, 2, report.Sources[0].Records)
+
+	// Objects other users stored too are kept for them
+	_, err = store.Get(ctx, "tenant-1", own)
+	assert.ErrorIs(t, err, blobs.ErrNotFound)
+	r, err := store.Get(ctx, "tenant-1", shared)
+	require.NoError(t, err)
+	r.Close()
+
+	// Anonymizing hands the objects to the pseudonym
+	report, err = service.Erase(ctx, Subject{TenantID: "tenant-1", UserID: "user-2"}, ModeAnonymize)
+	require.NoError(t, err)
+	assert.True(t, report.Verified)
+	r, err = store.Get(ctx, "tenant-1", shared)
+	require.NoError(t, err)
+	r.Close()
+}
+
+func TestA2ASource(t *testing.T) {
+	var requests []string
+	a2a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/admin/users/busy/data" && r.Method == http.MethodDelete:
+			http.Error(w, "user has unfinished tasks", http.StatusConflict)
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(A2AUserData{
+				Tasks: []json.RawMessage{json.RawMessage(`{"id":"task-1"}`)},
+				Usage: []json.RawMessage{json.RawMessage(`{"task_id":"task-1"}`), json.RawMessage(`{"task_id":"task-2"}`)},
+			})
+		default:
+			json.NewEncoder(w).Encode(A2AUserErasure{Tasks: 1, Usage: 2, Schedules: 1})
+		}
+	}))
+	defer a2a.Close()
+
+	ctx := context.Background()
+	source := NewA2ASource(a2a.URL+"/", "secret")
+	export, err := source.Export(ctx, Subject{TenantID: "tenant-1", UserID: "user 1"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, export.Records)
+	data, err := json.Marshal(export.Data)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"task-1"`)
+
+	erasure, err := source.Erase(ctx, Subject{TenantID: "tenant-1", UserID: "user-1"}, ModeAnonymize, "erased-1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, erasure.Records)
+	_, err = source.Erase(ctx, Subject{TenantID: "tenant-1", UserID: "user-1"}, ModeDelete, "erased-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"GET /admin/users/user%201/data",
+		"DELETE /admin/users/user-1/data?pseudonym=erased-1",
+		"DELETE /admin/users/user-1/data",
+	}, requests)
+
+	_, err = source.Erase(ctx, Subject{TenantID: "tenant-1", UserID: "busy"}, ModeDelete, "")
+	assert.ErrorContains(t, err, "unfinished tasks")
+	_, err = NewA2ASource(a2a.URL, "wrong").Export(ctx, Subject{UserID: "user-1"})
+	assert.ErrorContains(t, err, "401")
+}
@@ -0,0 +1,202 @@
+// Package llm generates text completions with a configured LLM provider: the OpenAI chat
+// completions API or a compatible server, the Anthropic messages API, or Ollama.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Providers selectable in Config.Provider
+const (
+	// ProviderNone disables server-side generation
+	ProviderNone = ""
+	// ProviderOpenAI calls the OpenAI chat completions API or a compatible server such as vLLM
+	ProviderOpenAI = "openai"
+	// ProviderAnthropic calls the Anthropic messages API
+	ProviderAnthropic = "anthropic"
+	// ProviderOllama calls a local Ollama server's chat API
+	ProviderOllama = "ollama"
+)
+
+// Defaults; the URL and model default per provider
+const (
+	DefaultTimeout   = 60 * time.Second
+	DefaultMaxTokens = 1024
+
+	DefaultOpenAIURL      = "https://api.openai.com/v1"
+	DefaultOpenAIModel    = "gpt-4o-mini"
+	DefaultAnthropicURL   = "https://api.anthropic.com/v1"
+	DefaultAnthropicModel = "claude-3-5-haiku-latest"
+	DefaultOllamaURL      = "http://localhost:11434"
+	DefaultOllamaModel    = "llama3.1"
+)
+
+// maxResponseBytes bounds the response read from the LLM API
+const maxResponseBytes = 4 << 20
+
+// Message roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a turn of the conversation sent to the model
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Request asks the model for a completion
+type Request struct {
+	// System is the system prompt
+	System   string
+	Messages []Message
+	// MaxTokens bounds the completion; zero uses the configured maximum
+	MaxTokens   int
+	Temperature *float64
+}
+
+// Response is the model's completion
+type Response struct {
+	Text         string
+	Model        string
+	StopReason   string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider generates completions
+type Provider interface {
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// Config selects and configures the LLM provider
+type Config struct {
+	Provider string `yaml:"provider"`
+	// URL is the API's base URL; empty uses the provider's default
+	URL string `yaml:"url"`
+	// Model is the model to call; empty uses the provider's default
+	Model   string        `yaml:"model"`
+	APIKey  string        `yaml:"api_key"`
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxTokens bounds completions whose request does not set a limit
+	MaxTokens int `yaml:"max_tokens"`
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	switch c.Provider {
+	case ProviderNone:
+		return nil
+	case ProviderOpenAI, ProviderOllama:
+	case ProviderAnthropic:
+		if c.APIKey == "" {
+			return errors.New("api_key is required for the anthropic provider")
+		}
+	default:
+		return fmt.Errorf("provider must be %q, %q, %q or empty, got %q", ProviderOpenAI, ProviderAnthropic, ProviderOllama, c.Provider)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", c.Timeout)
+	}
+	if c.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive, got %d", c.MaxTokens)
+	}
+	return nil
+}
+
+// New creates the configured provider; it returns nil for ProviderNone
+func New(config Config) (Provider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	switch config.Provider {
+	case ProviderOpenAI:
+		return NewOpenAI(config), nil
+	case ProviderAnthropic:
+		return NewAnthropic(config), nil
+	case ProviderOllama:
+		return NewOllama(config), nil
+	default:
+		return nil, nil
+	}
+}
+
+// DefaultModel returns the model provider calls when the configuration names none
+func DefaultModel(provider string) string {
+	switch provider {
+	case ProviderOpenAI:
+		return DefaultOpenAIModel
+	case ProviderAnthropic:
+		return DefaultAnthropicModel
+	case ProviderOllama:
+		return DefaultOllamaModel
+	}
+	return ""
+}
+
+// client holds what every provider needs to call its API
+type client struct {
+	config Config
+	http   *http.Client
+}
+
+func newClient(config Config, defaultURL, defaultModel string) client {
+	if config.URL == "" {
+		config.URL = defaultURL
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	if config.Model == "" {
+		config.Model = defaultModel
+	}
+	if config.MaxTokens <= 0 {
+		config.MaxTokens = DefaultMaxTokens
+	}
+	return client{config: config, http: &http.Client{Timeout: config.Timeout}}
+}
+
+// maxTokens returns the request's completion limit, or the configured one
+func (c client) maxTokens(req Request) int {
+	if req.MaxTokens > 0 {
+		return req.MaxTokens
+	}
+	return c.config.MaxTokens
+}
+
+// post sends body as JSON to path and decodes the JSON response into out
+func (c client) post(ctx context.Context, path string, header http.Header, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("LLM API returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		return fmt.Errorf("invalid LLM response: %w", err)
+	}
+	return nil
+}
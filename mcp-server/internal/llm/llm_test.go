@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRequest = Request{
+	System:   "Answer briefly.",
+	Messages: []Message{{Role: RoleUser, Content: "How long do refunds take?"}},
+}
+
+// decodeBody decodes the JSON request body
+func decodeBody(t *testing.T, r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	return body
+}
+
+func TestOpenAI_Complete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		body := decodeBody(t, r)
+		assert.Equal(t, "gpt-test", body["model"])
+		assert.Equal(t, float64(DefaultMaxTokens), body["max_tokens"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"role": "system", "content": "Answer briefly."},
+			map[string]interface{}{"role": "user", "content": "How long do refunds take?"},
+		}, body["messages"])
+		w.Write([]byte(`{"model": "gpt-test-0125", "choices": [{"message": {"role": "assistant", "content": "5 days [1]"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 30, "completion_tokens": 4}}`))
+	}))
+	defer srv.Close()
+
+	provider, err := New(Config{Provider: ProviderOpenAI, URL: srv.URL + "/v1/", Model: "gpt-test", APIKey: "sk-test", Timeout: DefaultTimeout, MaxTokens: DefaultMaxTokens})
+	require.NoError(t, err)
+	response, err := provider.Complete(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "5 days [1]", Model: "gpt-test-0125", StopReason: "stop", InputTokens: 30, OutputTokens: 4}, response)
+}
+
+func TestAnthropic_Complete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "sk-ant-test", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+		body := decodeBody(t, r)
+		assert.Equal(t, "Answer briefly.", body["system"])
+		assert.Equal(t, float64(200), body["max_tokens"])
+		assert.Len(t, body["messages"], 1, "the system prompt is not a message")
+		w.Write([]byte(`{"model": "claude-test", "content": [{"type": "text", "text": "5 days "}, {"type": "text", "text": "[1]"}],
+			"stop_reason": "end_turn", "usage": {"input_tokens": 30, "output_tokens": 4}}`))
+	}))
+	defer srv.Close()
+
+	provider := NewAnthropic(Config{URL: srv.URL + "/v1", APIKey: "sk-ant-test", Timeout: DefaultTimeout})
+	req := testRequest
+	req.MaxTokens = 200
+	response, err := provider.Complete(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "5 days [1]", Model: "claude-test", StopReason: "end_turn", InputTokens: 30, OutputTokens: 4}, response)
+}
+
+func TestOllama_Complete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		body := decodeBody(t, r)
+		assert.Equal(t, DefaultOllamaModel, body["model"])
+		assert.Equal(t, false, body["stream"])
+		assert.Equal(t, map[string]interface{}{"num_predict": float64(DefaultMaxTokens), "temperature": 0.2}, body["options"])
+		w.Write([]byte(`{"model": "llama3.1", "message": {"role": "assistant", "content": "5 days [1]"}, "done_reason": "stop",
+			"prompt_eval_count": 30, "eval_count": 4}`))
+	}))
+	defer srv.Close()
+
+	provider := NewOllama(Config{URL: srv.URL, Timeout: DefaultTimeout})
+	req := testRequest
+	temperature := 0.2
+	req.Temperature = &temperature
+	response, err := provider.Complete(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "5 days [1]", Model: "llama3.1", StopReason: "stop", InputTokens: 30, OutputTokens: 4}, response)
+}
+
+func TestComplete_Errors(t *testing.T) {
+	status := http.StatusTooManyRequests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, "rate limited", status)
+			return
+		}
+		w.Write([]byte(`{"choices": []}`))
+	}))
+	defer srv.Close()
+
+	provider := NewOpenAI(Config{URL: srv.URL, Timeout: DefaultTimeout})
+	_, err := provider.Complete(context.Background(), testRequest)
+	assert.ErrorContains(t, err, "429 Too Many Requests: rate limited")
+
+	status = http.StatusOK
+	_, err = provider.Complete(context.Background(), testRequest)
+	assert.ErrorContains(t, err, "no choices")
+}
+
+func TestNew(t *testing.T) {
+	provider, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, provider, "generation is off by default")
+
+	_, err = New(Config{Provider: "cohere", Timeout: DefaultTimeout, MaxTokens: DefaultMaxTokens})
+	assert.Error(t, err)
+	_, err = New(Config{Provider: ProviderAnthropic, Timeout: DefaultTimeout, MaxTokens: DefaultMaxTokens})
+	assert.Error(t, err, "api_key is required")
+	_, err = New(Config{Provider: ProviderOllama, MaxTokens: DefaultMaxTokens})
+	assert.Error(t, err, "timeout must be positive")
+	_, err = New(Config{Provider: ProviderOllama, Timeout: DefaultTimeout})
+	assert.Error(t, err, "max_tokens must be positive")
+
+	provider, err = New(Config{Provider: ProviderOllama, Timeout: DefaultTimeout, MaxTokens: DefaultMaxTokens})
+	require.NoError(t, err)
+	assert.IsType(t, &Ollama{}, provider)
+}
@@ -0,0 +1,197 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// OpenAI calls an OpenAI-compatible chat completions API
+type OpenAI struct {
+	client
+}
+
+var _ Provider = (*OpenAI)(nil)
+
+// NewOpenAI creates a client for the chat completions API config locates
+func NewOpenAI(config Config) *OpenAI {
+	return &OpenAI{newClient(config, DefaultOpenAIURL, DefaultOpenAIModel)}
+}
+
+// Complete implements Provider
+func (p *OpenAI) Complete(ctx context.Context, req Request) (*Response, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	messages := make([]message, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, message{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		messages = append(messages, message{Role: m.Role, Content: m.Content})
+	}
+	body := map[string]interface{}{
+		"model":      p.config.Model,
+		"messages":   messages,
+		"max_tokens": p.maxTokens(req),
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	header := http.Header{}
+	if p.config.APIKey != "" {
+		header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+
+	var result struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message      message `json:"message"`
+			FinishReason string  `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := p.post(ctx, "/chat/completions", header, body, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Choices) == 0 {
+		return nil, errors.New("LLM API returned no choices")
+	}
+	return &Response{
+		Text:         result.Choices[0].Message.Content,
+		Model:        result.Model,
+		StopReason:   result.Choices[0].FinishReason,
+		InputTokens:  result.Usage.PromptTokens,
+		OutputTokens: result.Usage.CompletionTokens,
+	}, nil
+}
+
+// anthropicVersion is the Anthropic API version requests are written against
+const anthropicVersion = "2023-06-01"
+
+// Anthropic calls the Anthropic messages API
+type Anthropic struct {
+	client
+}
+
+var _ Provider = (*Anthropic)(nil)
+
+// NewAnthropic creates a client for the messages API config locates
+func NewAnthropic(config Config) *Anthropic {
+	return &Anthropic{newClient(config, DefaultAnthropicURL, DefaultAnthropicModel)}
+}
+
+// Complete implements Provider
+func (p *Anthropic) Complete(ctx context.Context, req Request) (*Response, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	messages := make([]message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, message{Role: m.Role, Content: m.Content})
+	}
+	body := map[string]interface{}{
+		"model":      p.config.Model,
+		"messages":   messages,
+		"max_tokens": p.maxTokens(req),
+	}
+	if req.System != "" {
+		body["system"] = req.System
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	header := http.Header{}
+	header.Set("x-api-key", p.config.APIKey)
+	header.Set("anthropic-version", anthropicVersion)
+
+	var result struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := p.post(ctx, "/messages", header, body, &result); err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return &Response{
+		Text:         text.String(),
+		Model:        result.Model,
+		StopReason:   result.StopReason,
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+	}, nil
+}
+
+// Ollama calls the chat API of an Ollama server
+type Ollama struct {
+	client
+}
+
+var _ Provider = (*Ollama)(nil)
+
+// NewOllama creates a client for the Ollama server config locates
+func NewOllama(config Config) *Ollama {
+	return &Ollama{newClient(config, DefaultOllamaURL, DefaultOllamaModel)}
+}
+
+// Complete implements Provider
+func (p *Ollama) Complete(ctx context.Context, req Request) (*Response, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	messages := make([]message, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, message{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		messages = append(messages, message{Role: m.Role, Content: m.Content})
+	}
+	options := map[string]interface{}{"num_predict": p.maxTokens(req)}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	body := map[string]interface{}{
+		"model":    p.config.Model,
+		"messages": messages,
+		"stream":   false,
+		"options":  options,
+	}
+
+	var result struct {
+		Model           string  `json:"model"`
+		Message         message `json:"message"`
+		DoneReason      string  `json:"done_reason"`
+		PromptEvalCount int     `json:"prompt_eval_count"`
+		EvalCount       int     `json:"eval_count"`
+	}
+	if err := p.post(ctx, "/api/chat", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &Response{
+		Text:         result.Message.Content,
+		Model:        result.Model,
+		StopReason:   result.DoneReason,
+		InputTokens:  result.PromptEvalCount,
+		OutputTokens: result.EvalCount,
+	}, nil
+}
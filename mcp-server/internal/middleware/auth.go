@@ -3,10 +3,14 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/mtls"
 )
 
 // AuthMiddleware validates JWT tokens and adds auth context
@@ -14,6 +18,21 @@ type AuthMiddleware struct {
 	validator *auth.JWTValidator
 	// allowUnauthenticated allows requests without auth for certain methods
 	allowUnauthenticated map[string]bool
+	// roles expands claims with the scopes of the user's roles (optional)
+	roles *auth.RoleResolver
+	// verifier validates tokens of an external authorization server (optional)
+	verifier TokenVerifier
+	// resourceMetadataURL is advertised to unauthenticated callers (optional)
+	resourceMetadataURL string
+}
+
+// TokenVerifier validates access tokens issued by an external authorization server, such
+// as an auth.OIDCVerifier
+type TokenVerifier interface {
+	// Handles reports whether the verifier is responsible for a token, e.g. by its issuer
+	Handles(token string) bool
+	// VerifyToken validates a token and returns its claims
+	VerifyToken(ctx context.Context, token string) (*auth.Claims, error)
 }
 
 // NewAuthMiddleware creates a new auth middleware
@@ -26,25 +45,76 @@ func NewAuthMiddleware(validator *auth.JWTValidator) *AuthMiddleware {
 	}
 }
 
+// SetRoleResolver expands each authenticated caller's scopes with the scopes granted
+// by the role in the token and the role assigned to the user in the tenant
+func (m *AuthMiddleware) SetRoleResolver(resolver *auth.RoleResolver) {
+	m.roles = resolver
+}
+
+// SetTokenVerifier accepts the tokens a verifier handles next to the server's own JWTs
+func (m *AuthMiddleware) SetTokenVerifier(verifier TokenVerifier) {
+	m.verifier = verifier
+}
+
+// SetResourceMetadataURL points 401 responses at the protected resource metadata
+// (RFC 9728) in a WWW-Authenticate header, so OAuth clients can find the authorization
+// server to get a token from
+func (m *AuthMiddleware) SetResourceMetadataURL(url string) {
+	m.resourceMetadataURL = url
+}
+
+// validateToken validates the server's own JWTs and the tokens of the external verifier
+func (m *AuthMiddleware) validateToken(ctx context.Context, authHeader string) (*auth.Claims, error) {
+	if m.verifier != nil && m.verifier.Handles(authHeader) {
+		return m.verifier.VerifyToken(ctx, authHeader)
+	}
+	return m.validator.ValidateToken(authHeader)
+}
+
+// authenticate validates the token and resolves the caller's roles into scopes
+func (m *AuthMiddleware) authenticate(w http.ResponseWriter, r *http.Request, authHeader string) (context.Context, bool) {
+	claims, err := m.validateToken(r.Context(), authHeader)
+	if err != nil {
+		m.sendError(w, r, http.StatusUnauthorized, nil, protocol.AuthenticationRequired, "Invalid token: "+err.Error())
+		return nil, false
+	}
+
+	// A certificate bound to a tenant only makes that tenant's requests
+	if identity, ok := mtls.IdentityFrom(r.Context()); ok && identity.TenantID != "" && identity.TenantID != claims.TenantID {
+		m.sendError(w, r, http.StatusForbidden, nil, protocol.AuthorizationFailed, "Client certificate is not valid for the token's tenant")
+		return nil, false
+	}
+
+	if m.roles != nil {
+		claims, err = m.roles.Resolve(r.Context(), claims)
+		if err != nil {
+			m.sendError(w, r, http.StatusInternalServerError, nil, protocol.InternalError, "Failed to resolve roles")
+			return nil, false
+		}
+	}
+
+	logging.AddAttrs(r.Context(),
+		slog.String(logging.TenantIDKey, claims.TenantID),
+		slog.String(logging.UserIDKey, claims.UserID),
+	)
+	return auth.WithAuth(r.Context(), claims), true
+}
+
 // Handler wraps an HTTP handler with authentication
 func (m *AuthMiddleware) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract token from Authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			m.sendError(w, nil, protocol.AuthenticationRequired, "Authorization header required")
+			m.sendError(w, r, http.StatusUnauthorized, nil, protocol.AuthenticationRequired, "Authorization header required")
 			return
 		}
 
-		// Validate token
-		claims, err := m.validator.ValidateToken(authHeader)
-		if err != nil {
-			m.sendError(w, nil, protocol.AuthenticationRequired, "Invalid token: "+err.Error())
+		// Validate token and add auth context to request
+		ctx, ok := m.authenticate(w, r, authHeader)
+		if !ok {
 			return
 		}
-
-		// Add auth context to request
-		ctx := auth.WithAuth(r.Context(), claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -56,15 +126,13 @@ func (m *AuthMiddleware) OptionalHandler(next http.Handler) http.Handler {
 		// Try to extract and validate token if present
 		authHeader := r.Header.Get("Authorization")
 		if authHeader != "" {
-			claims, err := m.validator.ValidateToken(authHeader)
-			if err == nil {
-				// Valid token - add context
-				ctx := auth.WithAuth(r.Context(), claims)
-				next.ServeHTTP(w, r.WithContext(ctx))
+			// A token that is present must be valid
+			ctx, ok := m.authenticate(w, r, authHeader)
+			if !ok {
 				return
 			}
-			// Invalid token but present - this is an error
-			m.sendError(w, nil, protocol.AuthenticationRequired, "Invalid token: "+err.Error())
+			// Valid token - add context
+			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
@@ -73,12 +141,32 @@ func (m *AuthMiddleware) OptionalHandler(next http.Handler) http.Handler {
 	})
 }
 
+// RequireScope wraps an authenticated handler so it is only reached by callers holding
+// scope, directly or through a role
+func (m *AuthMiddleware) RequireScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := auth.ExtractTenantID(r.Context()); err != nil {
+			m.sendError(w, r, http.StatusUnauthorized, nil, protocol.AuthenticationRequired, "Authentication required")
+			return
+		}
+		if !auth.HasScope(r.Context(), scope) {
+			m.sendError(w, r, http.StatusForbidden, nil, protocol.AuthorizationFailed, "Scope required: "+scope)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // sendError sends a JSON-RPC error response
-func (m *AuthMiddleware) sendError(w http.ResponseWriter, id interface{}, code int, message string) {
+func (m *AuthMiddleware) sendError(w http.ResponseWriter, r *http.Request, status int, id interface{}, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusUnauthorized)
+	if status == http.StatusUnauthorized && m.resourceMetadataURL != "" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata=%q`, m.resourceMetadataURL))
+	}
+	w.WriteHeader(status)
 
 	response := protocol.NewErrorResponse(id, code, message, nil)
+	response.Error.SetRequestID(logging.RequestID(r.Context()))
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -7,13 +7,16 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/mtls"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -58,7 +61,7 @@ func TestAuthMiddleware_Handler_ValidToken(t *testing.T) {
 	validator, privateKey, _ := setupTestAuth(t)
 
 	// Generate a valid token
-	token, err := auth.GenerateDemoToken("tenant-123", "user-456", []string{"admin"}, privateKey)
+	token, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"admin"}, privateKey)
 	require.NoError(t, err)
 
 	middleware := NewAuthMiddleware(validator)
@@ -71,7 +74,7 @@ func TestAuthMiddleware_Handler_ValidToken(t *testing.T) {
 		// Verify auth context was added
 		tenantID, err := auth.ExtractTenantID(r.Context())
 		assert.NoError(t, err)
-		assert.Equal(t, "tenant-123", tenantID)
+		assert.Equal(t, "11111111-1111-1111-1111-111111111111", tenantID)
 
 		userID, err := auth.ExtractUserID(r.Context())
 		assert.NoError(t, err)
@@ -154,7 +157,7 @@ func TestAuthMiddleware_Handler_ExpiredToken(t *testing.T) {
 	middleware := NewAuthMiddleware(validator)
 
 	// Generate an expired token
-	expiredToken, err := auth.GenerateDemoTokenWithExpiry("tenant-123", "user-456", []string{"admin"}, privateKey, -time.Hour)
+	expiredToken, err := auth.GenerateDemoTokenWithExpiry("11111111-1111-1111-1111-111111111111", "user-456", []string{"admin"}, privateKey, -time.Hour)
 	require.NoError(t, err)
 
 	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -178,7 +181,7 @@ func TestAuthMiddleware_OptionalHandler_ValidToken(t *testing.T) {
 	validator, privateKey, _ := setupTestAuth(t)
 
 	// Generate a valid token
-	token, err := auth.GenerateDemoToken("tenant-123", "user-456", []string{"admin"}, privateKey)
+	token, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"admin"}, privateKey)
 	require.NoError(t, err)
 
 	middleware := NewAuthMiddleware(validator)
@@ -191,7 +194,7 @@ func TestAuthMiddleware_OptionalHandler_ValidToken(t *testing.T) {
 		// Verify auth context was added
 		tenantID, err := auth.ExtractTenantID(r.Context())
 		assert.NoError(t, err)
-		assert.Equal(t, "tenant-123", tenantID)
+		assert.Equal(t, "11111111-1111-1111-1111-111111111111", tenantID)
 
 		w.WriteHeader(http.StatusOK)
 	})
@@ -266,6 +269,181 @@ func TestAuthMiddleware_OptionalHandler_InvalidToken(t *testing.T) {
 	assert.Equal(t, protocol.AuthenticationRequired, response.Error.Code)
 }
 
+func TestAuthMiddleware_RoleResolver(t *testing.T) {
+	validator, privateKey, _ := setupTestAuth(t)
+
+	roles := auth.NewMemoryRoleStore()
+	require.NoError(t, roles.AssignRole(context.Background(), auth.RoleAssignment{
+		TenantID: "11111111-1111-1111-1111-111111111111", UserID: "user-456", Role: auth.RoleEditor,
+	}))
+
+	middleware := NewAuthMiddleware(validator)
+	middleware.SetRoleResolver(auth.NewRoleResolver(roles, time.Minute))
+
+	tests := []struct {
+		name   string
+		userID string
+		scopes []string
+	}{
+		{"assigned role adds its scopes", "user-456", []string{"read", "write"}},
+		{"unassigned user keeps token scopes", "user-789", []string{"read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", tt.userID, []string{"read"}, privateKey)
+			require.NoError(t, err)
+
+			var scopes []string
+			handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				scopes, _ = auth.ExtractScopes(r.Context())
+			}))
+
+			req := httptest.NewRequest("POST", "/mcp", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.ElementsMatch(t, tt.scopes, scopes)
+		})
+	}
+}
+
+// fakeVerifier accepts tokens with a prefix as an external authorization server's
+type fakeVerifier struct {
+	prefix string
+}
+
+func (v fakeVerifier) Handles(token string) bool {
+	return strings.HasPrefix(token, "Bearer "+v.prefix)
+}
+
+func (v fakeVerifier) VerifyToken(ctx context.Context, token string) (*auth.Claims, error) {
+	if token != "Bearer "+v.prefix+"valid" {
+		return nil, fmt.Errorf("token is not active")
+	}
+	return &auth.Claims{TenantID: "22222222-2222-2222-2222-222222222222", UserID: "idp-user"}, nil
+}
+
+func TestAuthMiddleware_TokenVerifier(t *testing.T) {
+	validator, privateKey, _ := setupTestAuth(t)
+	middleware := NewAuthMiddleware(validator)
+	middleware.SetTokenVerifier(fakeVerifier{prefix: "idp-"})
+	middleware.SetResourceMetadataURL("https://mcp.example.com/.well-known/oauth-protected-resource/mcp")
+
+	ownToken, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"read"}, privateKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{"server token", "Bearer " + ownToken, http.StatusOK, "11111111-1111-1111-1111-111111111111"},
+		{"verifier token", "Bearer idp-valid", http.StatusOK, "22222222-2222-2222-2222-222222222222"},
+		{"rejected verifier token", "Bearer idp-revoked", http.StatusUnauthorized, ""},
+		{"missing token", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenantID string
+			handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenantID, _ = auth.ExtractTenantID(r.Context())
+			}))
+
+			req := httptest.NewRequest("POST", "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantTenant, tenantID)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`,
+					rr.Header().Get("WWW-Authenticate"))
+			} else {
+				assert.Empty(t, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_ClientCertificateTenant(t *testing.T) {
+	validator, privateKey, _ := setupTestAuth(t)
+	middleware := NewAuthMiddleware(validator)
+
+	token, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"read"}, privateKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		identity   mtls.Identity
+		wantStatus int
+	}{
+		{"service certificate", mtls.Identity{SAN: "a2a.internal", Service: "a2a-server"}, http.StatusOK},
+		{"certificate of the token's tenant", mtls.Identity{SAN: "acme.clients", TenantID: "11111111-1111-1111-1111-111111111111"}, http.StatusOK},
+		{"certificate of another tenant", mtls.Identity{SAN: "globex.clients", TenantID: "22222222-2222-2222-2222-222222222222"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest("POST", "/mcp", nil)
+			req = req.WithContext(mtls.WithIdentity(req.Context(), tt.identity))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_RequireScope(t *testing.T) {
+	validator, privateKey, _ := setupTestAuth(t)
+	middleware := NewAuthMiddleware(validator)
+
+	handler := middleware.Handler(middleware.RequireScope(auth.ScopeAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	tests := []struct {
+		name   string
+		scopes []string
+		status int
+		code   int
+	}{
+		{"scope held", []string{"admin"}, http.StatusNoContent, 0},
+		{"scope missing", []string{"read", "write"}, http.StatusForbidden, protocol.AuthorizationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", tt.scopes, privateKey)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("GET", "/admin/roles", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.code != 0 {
+				var response protocol.Response
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				require.NotNil(t, response.Error)
+				assert.Equal(t, tt.code, response.Error.Code)
+			}
+		})
+	}
+}
+
 func TestWithContext(t *testing.T) {
 	// Create context handler
 	handlerCalled := false
@@ -295,7 +473,7 @@ func TestWithContext(t *testing.T) {
 // Benchmark tests
 func BenchmarkAuthMiddleware_Handler(b *testing.B) {
 	validator, privateKey, _ := setupTestAuth(&testing.T{})
-	token, _ := auth.GenerateDemoToken("tenant-123", "user-456", []string{"admin"}, privateKey)
+	token, _ := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"admin"}, privateKey)
 
 	middleware := NewAuthMiddleware(validator)
 
@@ -3,29 +3,130 @@ package middleware
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/bhatti/mcp-a2a-go/shared/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+// Rate limit failure policies, deciding requests while Redis cannot be reached and the
+// local fallback is off
+const (
+	// RateLimitFailOpen lets requests through unlimited
+	RateLimitFailOpen = ratelimit.FailOpen
+	// RateLimitFailClosed refuses requests with 503 Service Unavailable
+	RateLimitFailClosed = ratelimit.FailClosed
 )
 
-// RateLimiter implements token bucket rate limiting using Redis
+// ValidateRateLimitPolicy checks that policy is a known rate limit failure policy
+func ValidateRateLimitPolicy(policy string) error {
+	return ratelimit.ValidatePolicy(policy)
+}
+
+// DegradedRecorder counts rate limit decisions made without Redis
+type DegradedRecorder = ratelimit.DegradedRecorder
+
+// TenantLimits looks up tenants' own rate limits, such as those set by tenant onboarding
+type TenantLimits interface {
+	// TenantRateLimit returns the tenant's limit in requests per minute, or 0 for the default
+	TenantRateLimit(ctx context.Context, tenantID string) (int, error)
+}
+
+// RateLimiter limits each tenant's requests per minute with the shared Redis limiter,
+// answering refused requests with JSON-RPC errors
 type RateLimiter struct {
-	redis        *redis.Client
-	defaultLimit int // requests per minute
-	window       time.Duration
+	limiter *ratelimit.Limiter
+
+	// Per-tenant limits, cached for limitTTL; nil applies the default to every tenant
+	tenantLimits TenantLimits
+	limitTTL     time.Duration
+	limitsMu     sync.RWMutex
+	limits       map[string]cachedLimit
+}
+
+type cachedLimit struct {
+	limit     int
+	expiresAt time.Time
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(redisClient *redis.Client, defaultLimit int) *RateLimiter {
-	return &RateLimiter{
-		redis:        redisClient,
-		defaultLimit: defaultLimit,
-		window:       time.Minute,
+	rl := &RateLimiter{limiter: ratelimit.NewLimiter(redisClient, "ratelimit", defaultLimit)}
+	rl.limiter.SetKeyLimit(rl.tenantLimit)
+	return rl
+}
+
+// SetLimit changes the per-tenant limit in requests per minute
+func (rl *RateLimiter) SetLimit(limit int) {
+	rl.limiter.SetLimit(limit)
+}
+
+// Limit returns the per-tenant limit in requests per minute
+func (rl *RateLimiter) Limit() int {
+	return rl.limiter.Limit()
+}
+
+// SetFailurePolicy decides requests while Redis is unavailable. With localFallback each
+// server counts requests in memory and holds every tenant to its limit on its own, so a
+// tenant may get up to one limit per server; without it, policy lets every request
+// through (RateLimitFailOpen) or refuses them (RateLimitFailClosed).
+func (rl *RateLimiter) SetFailurePolicy(policy string, localFallback bool) {
+	rl.limiter.SetFailurePolicy(policy, localFallback)
+}
+
+// SetRecorder counts decisions made while Redis is unavailable
+func (rl *RateLimiter) SetRecorder(recorder DegradedRecorder) {
+	rl.limiter.SetRecorder(recorder)
+}
+
+// SetTenantLimits lets tenants' own limits override the default. A tenant's limit is
+// cached for ttl (one minute if zero), so a change takes up to ttl to apply.
+func (rl *RateLimiter) SetTenantLimits(limits TenantLimits, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	rl.limitsMu.Lock()
+	defer rl.limitsMu.Unlock()
+	rl.tenantLimits = limits
+	rl.limitTTL = ttl
+	rl.limits = make(map[string]cachedLimit)
+}
+
+// tenantLimit returns the tenant's own limit in requests per minute, or 0 for the
+// default when it has none or its limit cannot be looked up
+func (rl *RateLimiter) tenantLimit(ctx context.Context, tenantID string) int {
+	rl.limitsMu.RLock()
+	lookup := rl.tenantLimits
+	cached, ok := rl.limits[tenantID]
+	rl.limitsMu.RUnlock()
+	if lookup == nil {
+		return 0
+	}
+
+	if !ok || time.Now().After(cached.expiresAt) {
+		limit, err := lookup.TenantRateLimit(ctx, tenantID)
+		if err != nil {
+			slog.WarnContext(ctx, "Tenant rate limit lookup failed; using the default", "tenant_id", tenantID, "error", err)
+			return 0
+		}
+		cached = cachedLimit{limit: limit, expiresAt: time.Now().Add(rl.limitTTL)}
+		rl.limitsMu.Lock()
+		rl.limits[tenantID] = cached
+		rl.limitsMu.Unlock()
 	}
+	return cached.limit
+}
+
+// TenantLimit returns the limit in requests per minute the tenant is currently held to
+func (rl *RateLimiter) TenantLimit(ctx context.Context, tenantID string) int {
+	return rl.limiter.KeyLimit(ctx, tenantID)
 }
 
 // Handler wraps an HTTP handler with rate limiting
@@ -41,17 +142,14 @@ func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
 			return
 		}
 
-		// Check rate limit
-		allowed, err := rl.checkLimit(ctx, tenantID)
-		if err != nil {
-			// Log error but don't block request
-			fmt.Printf("Rate limit check error: %v\n", err)
-			next.ServeHTTP(w, r)
+		// Check rate limit; while Redis is unavailable the failure policy decides
+		allowed, err := rl.limiter.Allow(ctx, tenantID)
+		if errors.Is(err, ratelimit.ErrUnavailable) {
+			rl.sendUnavailable(w, r, nil)
 			return
 		}
-
 		if !allowed {
-			rl.sendError(w, nil, protocol.RateLimitExceeded, "Rate limit exceeded for tenant")
+			rl.sendError(w, r, nil, protocol.RateLimitExceeded, "Rate limit exceeded for tenant")
 			return
 		}
 
@@ -59,32 +157,27 @@ func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
 	})
 }
 
-// checkLimit checks if the tenant is within rate limits
-func (rl *RateLimiter) checkLimit(ctx context.Context, tenantID string) (bool, error) {
-	key := fmt.Sprintf("ratelimit:%s:%d", tenantID, time.Now().Unix()/60)
-
-	// Increment counter
-	count, err := rl.redis.Incr(ctx, key).Result()
-	if err != nil {
-		return false, fmt.Errorf("failed to increment counter: %w", err)
-	}
-
-	// Set expiration on first request
-	if count == 1 {
-		rl.redis.Expire(ctx, key, rl.window)
-	}
+// sendUnavailable refuses a request because its rate limit cannot be checked
+func (rl *RateLimiter) sendUnavailable(w http.ResponseWriter, r *http.Request, id interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "5")
+	w.WriteHeader(http.StatusServiceUnavailable)
 
-	// Check against limit
-	return count <= int64(rl.defaultLimit), nil
+	response := protocol.NewErrorResponse(id, protocol.ServerError, "Rate limiter unavailable", map[string]interface{}{
+		"retry_after": 5,
+	})
+	response.Error.SetRequestID(logging.RequestID(r.Context()))
+	json.NewEncoder(w).Encode(response)
 }
 
 // sendError sends a JSON-RPC error response
-func (rl *RateLimiter) sendError(w http.ResponseWriter, id interface{}, code int, message string) {
+func (rl *RateLimiter) sendError(w http.ResponseWriter, r *http.Request, id interface{}, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusTooManyRequests)
 
 	response := protocol.NewErrorResponse(id, code, message, map[string]interface{}{
-		"retry_after": rl.window.Seconds(),
+		"retry_after": rl.limiter.Window().Seconds(),
 	})
+	response.Error.SetRequestID(logging.RequestID(r.Context()))
 	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"sort"
+	"time"
+)
+
+// RateLimitSimulation reports how a per-minute limit would have treated recorded requests
+type RateLimitSimulation struct {
+	LimitPerMinute int `json:"limit_per_minute"`
+	Requests       int `json:"requests"`
+	Rejected       int `json:"rejected"`
+	// MinutesOverLimit counts the minutes in which at least one request was rejected
+	MinutesOverLimit int        `json:"minutes_over_limit"`
+	PeakPerMinute    int        `json:"peak_per_minute"`
+	FirstRejectedAt  *time.Time `json:"first_rejected_at,omitempty"`
+}
+
+// SimulateRateLimit replays request times against a per-minute limit the way the rate
+// limiter applies it: within each clock minute the first limitPerMinute requests pass and
+// the rest are rejected. A limit of zero or less rejects nothing.
+func SimulateRateLimit(requests []time.Time, limitPerMinute int) RateLimitSimulation {
+	sim := RateLimitSimulation{LimitPerMinute: limitPerMinute, Requests: len(requests)}
+
+	sorted := append([]time.Time(nil), requests...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	var minute int64 = -1
+	count := 0
+	for _, at := range sorted {
+		if m := at.Unix() / 60; m != minute {
+			minute, count = m, 0
+		}
+		count++
+		sim.PeakPerMinute = max(sim.PeakPerMinute, count)
+
+		if limitPerMinute <= 0 || count <= limitPerMinute {
+			continue
+		}
+		sim.Rejected++
+		if count == limitPerMinute+1 {
+			sim.MinutesOverLimit++
+		}
+		if sim.FirstRejectedAt == nil {
+			first := at
+			sim.FirstRejectedAt = &first
+		}
+	}
+	return sim
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateRateLimit(t *testing.T) {
+	minute := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	var requests []time.Time
+	// Five requests in the first minute, two in the next; passed out of order
+	for i := 4; i >= 0; i-- {
+		requests = append(requests, minute.Add(time.Duration(i)*time.Second))
+	}
+	requests = append(requests, minute.Add(time.Minute), minute.Add(time.Minute+time.Second))
+
+	sim := SimulateRateLimit(requests, 3)
+	assert.Equal(t, 3, sim.LimitPerMinute)
+	assert.Equal(t, 7, sim.Requests)
+	assert.Equal(t, 2, sim.Rejected)
+	assert.Equal(t, 1, sim.MinutesOverLimit)
+	assert.Equal(t, 5, sim.PeakPerMinute)
+	require.NotNil(t, sim.FirstRejectedAt)
+	assert.Equal(t, minute.Add(3*time.Second), *sim.FirstRejectedAt)
+
+	unlimited := SimulateRateLimit(requests, 0)
+	assert.Zero(t, unlimited.Rejected)
+	assert.Nil(t, unlimited.FirstRejectedAt)
+}
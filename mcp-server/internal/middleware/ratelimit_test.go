@@ -46,18 +46,17 @@ func TestNewRateLimiter(t *testing.T) {
 	limiter := NewRateLimiter((*redis.Client)(nil), 100)
 
 	assert.NotNil(t, limiter)
-	assert.Equal(t, 100, limiter.defaultLimit)
-	assert.Equal(t, time.Minute, limiter.window)
+	assert.Equal(t, 100, limiter.Limit())
+	assert.Equal(t, time.Minute, limiter.limiter.Window())
+
+	limiter.SetLimit(250)
+	assert.Equal(t, 250, limiter.Limit())
 }
 
 func TestRateLimiter_Handler_WithinLimit(t *testing.T) {
 	// Setup mock Redis
 	mockRedis := &redis.Client{}
-	limiter := &RateLimiter{
-		redis:        mockRedis,
-		defaultLimit: 100,
-		window:       time.Minute,
-	}
+	limiter := NewRateLimiter(mockRedis, 100)
 
 	// Create test handler
 	handlerCalled := false
@@ -162,7 +161,7 @@ func TestRateLimiter_sendError(t *testing.T) {
 	limiter := NewRateLimiter((*redis.Client)(nil), 100)
 
 	rr := httptest.NewRecorder()
-	limiter.sendError(rr, nil, protocol.RateLimitExceeded, "Rate limit exceeded")
+	limiter.sendError(rr, httptest.NewRequest("POST", "/mcp", nil), nil, protocol.RateLimitExceeded, "Rate limit exceeded")
 
 	// Verify response
 	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
@@ -307,7 +306,7 @@ func TestRateLimiter_WithRedis_DifferentTenants(t *testing.T) {
 	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
 }
 
-func TestRateLimiter_checkLimit(t *testing.T) {
+func TestRateLimiter_Allow(t *testing.T) {
 	mr, redisClient := setupMiniRedis(t)
 	defer mr.Close()
 
@@ -315,19 +314,111 @@ func TestRateLimiter_checkLimit(t *testing.T) {
 	ctx := context.Background()
 
 	// First check
-	allowed, err := limiter.checkLimit(ctx, "tenant-123")
+	allowed, err := limiter.limiter.Allow(ctx, "tenant-123")
 	assert.NoError(t, err)
 	assert.True(t, allowed)
 
 	// Check multiple times within limit
 	for i := 0; i < 50; i++ {
-		allowed, err := limiter.checkLimit(ctx, "tenant-123")
+		allowed, err := limiter.limiter.Allow(ctx, "tenant-123")
 		assert.NoError(t, err)
 		assert.True(t, allowed)
 	}
 }
 
+// fakeTenantLimits returns fixed per-tenant limits and counts lookups
+type fakeTenantLimits struct {
+	limits  map[string]int
+	lookups int
+}
+
+func (f *fakeTenantLimits) TenantRateLimit(ctx context.Context, tenantID string) (int, error) {
+	f.lookups++
+	return f.limits[tenantID], nil
+}
+
+func TestRateLimiter_TenantLimits(t *testing.T) {
+	mr, redisClient := setupMiniRedis(t)
+	defer mr.Close()
+
+	limiter := NewRateLimiter(redisClient, 3)
+	limits := &fakeTenantLimits{limits: map[string]int{"tenant-big": 5}}
+	limiter.SetTenantLimits(limits, time.Minute)
+	ctx := context.Background()
+
+	allowed := func(tenantID string) int {
+		n := 0
+		for i := 0; i < 10; i++ {
+			ok, err := limiter.limiter.Allow(ctx, tenantID)
+			require.NoError(t, err)
+			if ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	// A tenant's own limit overrides the default; tenants without one keep the default
+	assert.Equal(t, 5, allowed("tenant-big"))
+	assert.Equal(t, 3, allowed("tenant-default"))
+
+	// Limits are cached rather than looked up on every request
+	assert.Equal(t, 2, limits.lookups)
+}
+
 // Benchmark tests
+// degradedDecisions counts rate limit decisions made without Redis
+type degradedDecisions map[string]int
+
+func (d degradedDecisions) RecordRateLimitDegraded(ctx context.Context, policy, decision string) {
+	d[policy+"/"+decision]++
+}
+
+func TestRateLimiter_RedisUnavailable(t *testing.T) {
+	mr, redisClient := setupMiniRedis(t)
+	mr.Close()
+
+	serve := func(limiter *RateLimiter) int {
+		handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest("POST", "/mcp", nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.ContextKeyTenantID, "tenant-123"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("local fallback", func(t *testing.T) {
+		limiter := NewRateLimiter(redisClient, 2)
+		limiter.SetFailurePolicy(RateLimitFailClosed, true)
+		decisions := degradedDecisions{}
+		limiter.SetRecorder(decisions)
+
+		assert.Equal(t, http.StatusOK, serve(limiter))
+		assert.Equal(t, http.StatusOK, serve(limiter))
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter), "the fallback holds the tenant to its limit")
+		assert.Equal(t, degradedDecisions{"local/allowed": 2, "local/denied": 1}, decisions)
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		limiter := NewRateLimiter(redisClient, 1)
+		limiter.SetFailurePolicy(RateLimitFailOpen, false)
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, serve(limiter))
+		}
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		limiter := NewRateLimiter(redisClient, 100)
+		limiter.SetFailurePolicy(RateLimitFailClosed, false)
+		decisions := degradedDecisions{}
+		limiter.SetRecorder(decisions)
+		assert.Equal(t, http.StatusServiceUnavailable, serve(limiter))
+		assert.Equal(t, degradedDecisions{"fail_closed/denied": 1}, decisions)
+	})
+}
+
 func BenchmarkRateLimiter_Handler_NoAuth(b *testing.B) {
 	limiter := NewRateLimiter((*redis.Client)(nil), 100)
 
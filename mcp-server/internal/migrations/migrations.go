@@ -0,0 +1,27 @@
+// Package migrations versions the MCP server's Postgres schema with the shared migration
+// runner. Its migrations are embedded from the sql directory and recorded in the
+// schema_migrations table.
+//
+// The A2A server versions its own tables (tasks, usage_records) in the same database
+// with its own version table.
+package migrations
+
+import (
+	"embed"
+
+	sharedmigrations "github.com/bhatti/mcp-a2a-go/shared/migrations"
+)
+
+// VersionTable records the applied migrations
+const VersionTable = "schema_migrations"
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Migrator applies and reverts migrations on one database
+type Migrator = sharedmigrations.Migrator
+
+// New returns a migrator for the embedded migrations
+func New(conn sharedmigrations.Conn) (*Migrator, error) {
+	return sharedmigrations.New(conn, VersionTable, embedded)
+}
@@ -0,0 +1,246 @@
+-- Baseline schema of the MCP server, matching scripts/init-db.sql. Every statement is
+-- idempotent so databases created from init-db.sql (brought up to date with the
+-- scripts/apply-*.sql scripts) adopt it without changes. Demo data and the app_user role
+-- stay in init-db.sql.
+
+-- Enable pgvector extension
+CREATE EXTENSION IF NOT EXISTS vector;
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- Create tenants table
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    settings JSONB DEFAULT '{}'::jsonb,
+    data_region VARCHAR(64) NOT NULL DEFAULT 'default'  -- Where the tenant's data must be stored
+);
+
+-- Create documents table with tenant isolation
+CREATE TABLE IF NOT EXISTS documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    collection VARCHAR(64) NOT NULL DEFAULT 'default',  -- Named corpus of the tenant, e.g. policies or tickets
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    embedding vector(1536),  -- OpenAI ada-002 dimension
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255),
+    content_hash TEXT GENERATED ALWAYS AS (md5(title || E'\n' || content)) STORED,
+    embedding_hash TEXT,  -- content_hash of the text the embedding was generated from
+    embedding_stale_at TIMESTAMP WITH TIME ZONE,  -- Set by the consistency checker when content changed after embedding
+    embedding_half halfvec(1536),  -- 16-bit copy of embedding, written when quantization dual-write is on
+    embedding_bit bit(1536),  -- Binary quantized embedding, written with embedding_half
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+-- Create index on embeddings for fast similarity search
+CREATE INDEX IF NOT EXISTS idx_documents_embedding ON documents USING ivfflat (embedding vector_cosine_ops)
+WITH (lists = 100);
+
+-- HNSW indexes over the quantized embeddings; searches re-rank their candidates by embedding
+CREATE INDEX IF NOT EXISTS idx_documents_embedding_half ON documents USING hnsw (embedding_half halfvec_cosine_ops);
+CREATE INDEX IF NOT EXISTS idx_documents_embedding_bit ON documents USING hnsw (embedding_bit bit_hamming_ops);
+
+-- Create index on tenant_id for efficient filtering
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_id ON documents(tenant_id);
+
+-- Listing, searching and quota counts are scoped to a tenant's collection
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_collection ON documents(tenant_id, collection, created_at DESC);
+
+-- Create index on metadata for JSON queries
+CREATE INDEX IF NOT EXISTS idx_documents_metadata ON documents USING gin(metadata);
+
+-- Create full-text search index for BM25-like ranking
+CREATE INDEX IF NOT EXISTS idx_documents_fulltext ON documents USING gin(to_tsvector('english', title || ' ' || content));
+
+-- Enable Row-Level Security
+ALTER TABLE documents ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant isolation
+DROP POLICY IF EXISTS tenant_isolation_policy ON documents;
+CREATE POLICY tenant_isolation_policy ON documents
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Registered collections: the embedding model and dimensions their documents and
+-- queries must use. Collections also exist implicitly through their documents.
+CREATE TABLE IF NOT EXISTS collections (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,  -- Matches documents.collection
+    description TEXT NOT NULL DEFAULT '',
+    embedding_model VARCHAR(255) NOT NULL DEFAULT '',
+    embedding_dimensions INTEGER NOT NULL DEFAULT 0,  -- 0 accepts embeddings of any size
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, name)
+);
+
+ALTER TABLE collections ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON collections;
+CREATE POLICY tenant_isolation_policy ON collections
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Create usage tracking table for cost control
+CREATE TABLE IF NOT EXISTS usage_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255),
+    operation VARCHAR(50) NOT NULL,
+    model VARCHAR(100),
+    prompt_tokens INTEGER DEFAULT 0,
+    completion_tokens INTEGER DEFAULT 0,
+    total_tokens INTEGER DEFAULT 0,
+    cost_usd DECIMAL(10, 6) DEFAULT 0,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for usage queries
+CREATE INDEX IF NOT EXISTS idx_usage_logs_tenant_user ON usage_logs(tenant_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_usage_logs_created_at ON usage_logs(created_at DESC);
+
+-- Create document access log for "who viewed what" compliance reporting
+CREATE TABLE IF NOT EXISTS document_access_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+    user_id VARCHAR(255),
+    tool VARCHAR(100) NOT NULL,
+    accessed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_access_log_document ON document_access_log(tenant_id, document_id, accessed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_document_access_log_user ON document_access_log(tenant_id, user_id, accessed_at DESC);
+
+ALTER TABLE document_access_log ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON document_access_log;
+CREATE POLICY tenant_isolation_policy ON document_access_log
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Queue of documents whose embedding must be regenerated, filled by the embedding
+-- consistency checker and drained when a new embedding is written
+CREATE TABLE IF NOT EXISTS embedding_queue (
+    document_id UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    queued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_queue_tenant ON embedding_queue(tenant_id, queued_at);
+
+ALTER TABLE embedding_queue ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON embedding_queue;
+CREATE POLICY tenant_isolation_policy ON embedding_queue
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Outbox of document lifecycle events, written in the transaction that changes the
+-- document and relayed to the event stream (see DOCUMENT_OUTBOX_ENABLED)
+CREATE TABLE IF NOT EXISTS document_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,  -- No foreign key: deleted documents keep their events
+    collection VARCHAR(64) NOT NULL,
+    event_type VARCHAR(16) NOT NULL CHECK (event_type IN ('created', 'updated', 'deleted')),
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_events_pending ON document_events(tenant_id, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_document_events_published ON document_events(tenant_id, published_at) WHERE published_at IS NOT NULL;
+
+ALTER TABLE document_events ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON document_events;
+CREATE POLICY tenant_isolation_policy ON document_events
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Audit log of every MCP tool call, kept as SOC2 evidence. Arguments are stored as a
+-- digest only; rows are removed by the retention job, never updated.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255),
+    tool VARCHAR(100) NOT NULL,
+    args_digest VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log(tenant_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tool ON audit_log(tenant_id, tool, created_at DESC);
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON audit_log;
+CREATE POLICY tenant_isolation_policy ON audit_log
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Role assignments (viewer, editor, admin) granting scope bundles to users per tenant
+CREATE TABLE IF NOT EXISTS tenant_role_assignments (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    assigned_by VARCHAR(255),
+    assigned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+ALTER TABLE tenant_role_assignments ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON tenant_role_assignments;
+CREATE POLICY tenant_isolation_policy ON tenant_role_assignments
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Create function to update updated_at timestamp
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Create trigger to auto-update updated_at (bookkeeping such as embedding_stale_at leaves it alone)
+DROP TRIGGER IF EXISTS update_documents_updated_at ON documents;
+CREATE TRIGGER update_documents_updated_at BEFORE UPDATE OF title, content, metadata, embedding ON documents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_tenants_updated_at ON tenants;
+CREATE TRIGGER update_tenants_updated_at BEFORE UPDATE ON tenants
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- The application connects as app_user where it exists; audit entries are append-only for it
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'app_user') THEN
+        GRANT USAGE ON SCHEMA public TO app_user;
+        GRANT ALL PRIVILEGES ON tenants, documents, collections, usage_logs, document_access_log,
+            embedding_queue, document_events, tenant_role_assignments TO app_user;
+        GRANT SELECT, INSERT, DELETE ON audit_log TO app_user;
+        REVOKE UPDATE ON audit_log FROM app_user;
+        GRANT USAGE, SELECT ON SEQUENCE document_access_log_id_seq, document_events_id_seq, audit_log_id_seq TO app_user;
+    END IF;
+END
+$$;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS maintain_search_terms ON documents;
+DROP FUNCTION IF EXISTS maintain_search_terms();
+DROP TABLE IF EXISTS search_terms;
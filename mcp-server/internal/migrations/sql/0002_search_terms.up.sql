@@ -0,0 +1,67 @@
+-- Vocabulary of every tenant's collections for spelling correction of lexical queries:
+-- the words of the documents' title and content (lowercased, unstemmed) with the number
+-- of documents containing them, trigram indexed for similarity lookups
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS search_terms (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    collection VARCHAR(64) NOT NULL,
+    term TEXT NOT NULL,
+    ndoc INTEGER NOT NULL,
+    PRIMARY KEY (tenant_id, collection, term)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_terms_trgm ON search_terms USING gin (term gin_trgm_ops);
+
+ALTER TABLE search_terms ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON search_terms;
+CREATE POLICY tenant_isolation_policy ON search_terms
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Keeps search_terms in step with documents. It runs as the table owner so that
+-- maintenance jobs writing documents outside a tenant context keep the counts right.
+CREATE OR REPLACE FUNCTION maintain_search_terms()
+RETURNS TRIGGER
+SECURITY DEFINER
+SET search_path = public
+AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE search_terms
+        SET ndoc = ndoc - 1
+        WHERE tenant_id = OLD.tenant_id AND collection = OLD.collection
+            AND term = ANY (tsvector_to_array(to_tsvector('simple', OLD.title || ' ' || OLD.content)));
+        DELETE FROM search_terms
+        WHERE tenant_id = OLD.tenant_id AND collection = OLD.collection AND ndoc <= 0;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO search_terms (tenant_id, collection, term, ndoc)
+        SELECT NEW.tenant_id, NEW.collection, term, 1
+        FROM unnest(tsvector_to_array(to_tsvector('simple', NEW.title || ' ' || NEW.content))) AS term
+        ON CONFLICT (tenant_id, collection, term) DO UPDATE SET ndoc = search_terms.ndoc + 1;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS maintain_search_terms ON documents;
+CREATE TRIGGER maintain_search_terms AFTER INSERT OR DELETE OR UPDATE OF tenant_id, collection, title, content ON documents
+    FOR EACH ROW EXECUTE FUNCTION maintain_search_terms();
+
+-- Existing documents
+INSERT INTO search_terms (tenant_id, collection, term, ndoc)
+SELECT tenant_id, collection, term, count(*)
+FROM documents, unnest(tsvector_to_array(to_tsvector('simple', title || ' ' || content))) AS term
+GROUP BY tenant_id, collection, term
+ON CONFLICT (tenant_id, collection, term) DO UPDATE SET ndoc = EXCLUDED.ndoc;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'app_user') THEN
+        GRANT SELECT ON search_terms TO app_user;
+    END IF;
+END
+$$;
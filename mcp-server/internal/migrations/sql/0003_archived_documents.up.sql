@@ -0,0 +1,36 @@
+-- Documents archived by duplicate resolution: soft deleted, out of every search, and
+-- restorable into documents with their original ID
+CREATE TABLE IF NOT EXISTS archived_documents (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    collection VARCHAR(64) NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    embedding vector(1536),
+    embedding_hash TEXT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255),
+    duplicate_of UUID NOT NULL,  -- Document kept in its place; not a foreign key, it may be deleted later
+    reason VARCHAR(32) NOT NULL,  -- merge or soft_delete
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_archived_documents_tenant ON archived_documents(tenant_id, archived_at DESC);
+
+ALTER TABLE archived_documents ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON archived_documents;
+CREATE POLICY tenant_isolation_policy ON archived_documents
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'app_user') THEN
+        GRANT SELECT, INSERT, DELETE ON archived_documents TO app_user;
+    END IF;
+END
+$$;
@@ -0,0 +1,22 @@
+package observability
+
+// Default bucket boundaries in milliseconds. The SDK default tops out its fine buckets at
+// 5ms and 10ms, where most MCP calls and nearly all queries land.
+var (
+	// RequestDurationBuckets cover MCP requests, from cache hits to slow searches
+	RequestDurationBuckets = []float64{0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+	// ToolDurationBuckets cover tool executions, up to the 30s tool timeout
+	ToolDurationBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+	// DBDurationBuckets cover database queries and pool acquires, mostly well under a millisecond
+	DBDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 1000}
+)
+
+// DefaultHistogramBuckets returns the bucket boundaries of each duration histogram
+func DefaultHistogramBuckets() map[string][]float64 {
+	return map[string][]float64{
+		"mcp.request.duration":         RequestDurationBuckets,
+		"mcp.tool.execution.duration":  ToolDurationBuckets,
+		"mcp.db.query.duration":        DBDurationBuckets,
+		"mcp.db.pool.acquire.duration": DBDurationBuckets,
+	}
+}
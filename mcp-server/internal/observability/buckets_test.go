@@ -0,0 +1,60 @@
+package observability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/shared/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestValidateHistogramBuckets(t *testing.T) {
+	assert.NoError(t, ValidateHistogramBuckets(DefaultHistogramBuckets()))
+}
+
+func TestHistogramViews(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(telemetry.HistogramViews(DefaultHistogramBuckets(), map[string][]float64{"mcp.request.duration": {1, 10}})...),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	)
+	metrics, err := NewMetrics(provider.Meter("test"))
+	require.NoError(t, err)
+
+	// A measurement made within a sampled span carries its trace as an exemplar
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	metrics.RecordRequest(ctx, "tools/call", "success", 0.4)
+	metrics.RecordDBQuery(ctx, "select", "SELECT 1", 0.2, nil)
+
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+	histograms := make(map[string]metricdata.HistogramDataPoint[float64])
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if histogram, ok := m.Data.(metricdata.Histogram[float64]); ok && len(histogram.DataPoints) > 0 {
+				histograms[m.Name] = histogram.DataPoints[0]
+			}
+		}
+	}
+
+	request := histograms["mcp.request.duration"]
+	assert.Equal(t, []float64{1, 10}, request.Bounds, "configured buckets override the defaults")
+	assert.Equal(t, []uint64{1, 0, 0}, request.BucketCounts)
+	require.Len(t, request.Exemplars, 1)
+	assert.Equal(t, spanCtx.TraceID().String(), trace.TraceID(request.Exemplars[0].TraceID).String())
+
+	query := histograms["mcp.db.query.duration"]
+	assert.Equal(t, DBDurationBuckets, query.Bounds)
+	assert.Equal(t, uint64(1), query.BucketCounts[2], "0.2ms lands in the (0.1, 0.25] bucket")
+}
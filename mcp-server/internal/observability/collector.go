@@ -0,0 +1,158 @@
+package observability
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CollectorService describes a service whose telemetry the collector receives
+type CollectorService struct {
+	// Name is the service name, used as the scrape job name
+	Name string
+	// Tracing means the service sends OTLP traces to the collector
+	Tracing bool
+	// MetricsTarget is the host:port the collector scrapes; empty if metrics are disabled
+	MetricsTarget string
+	// MetricsPath is the scrape path (default /metrics)
+	MetricsPath string
+}
+
+// CollectorOptions configures a generated OpenTelemetry Collector configuration
+type CollectorOptions struct {
+	Services []CollectorService
+	// OTLPEndpoint is the address the OTLP HTTP receiver listens on (default 0.0.0.0:4318)
+	OTLPEndpoint string
+	// TracesEndpoint is the OTLP HTTP endpoint traces are forwarded to (default http://jaeger:4318)
+	TracesEndpoint string
+	// PrometheusEndpoint is where the collector exposes scraped metrics (default 0.0.0.0:8889)
+	PrometheusEndpoint string
+	// ScrapeInterval is how often service metrics are scraped (default 15s)
+	ScrapeInterval time.Duration
+}
+
+// collectorConfig mirrors the top-level sections of a collector configuration file
+type collectorConfig struct {
+	Receivers  map[string]interface{} `yaml:"receivers"`
+	Processors map[string]interface{} `yaml:"processors"`
+	Exporters  map[string]interface{} `yaml:"exporters"`
+	Service    collectorPipelines     `yaml:"service"`
+}
+
+type collectorPipelines struct {
+	Pipelines map[string]collectorPipeline `yaml:"pipelines"`
+}
+
+type collectorPipeline struct {
+	Receivers  []string `yaml:"receivers"`
+	Processors []string `yaml:"processors"`
+	Exporters  []string `yaml:"exporters"`
+}
+
+// CollectorConfigHeader is written above every generated configuration
+const CollectorConfigHeader = "# Generated by `mcp-server gen-otel-config`. Do not edit; regenerate instead.\n"
+
+// GenerateCollectorConfig renders an OpenTelemetry Collector configuration that receives
+// exactly the signals the given services emit: OTLP traces forwarded to a tracing backend,
+// and Prometheus metrics scraped from each service and re-exposed for Prometheus.
+func GenerateCollectorConfig(opts CollectorOptions) ([]byte, error) {
+	if opts.OTLPEndpoint == "" {
+		opts.OTLPEndpoint = "0.0.0.0:4318"
+	}
+	if opts.TracesEndpoint == "" {
+		opts.TracesEndpoint = "http://jaeger:4318"
+	}
+	if opts.PrometheusEndpoint == "" {
+		opts.PrometheusEndpoint = "0.0.0.0:8889"
+	}
+	if opts.ScrapeInterval <= 0 {
+		opts.ScrapeInterval = 15 * time.Second
+	}
+
+	var tracing bool
+	var scrapeConfigs []interface{}
+	for _, svc := range opts.Services {
+		if svc.Name == "" {
+			return nil, errors.New("service name is required")
+		}
+		tracing = tracing || svc.Tracing
+		if svc.MetricsTarget == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(svc.MetricsTarget); err != nil {
+			return nil, fmt.Errorf("invalid metrics target %q for %s: %w", svc.MetricsTarget, svc.Name, err)
+		}
+		path := svc.MetricsPath
+		if path == "" {
+			path = "/metrics"
+		}
+		scrapeConfigs = append(scrapeConfigs, map[string]interface{}{
+			"job_name":        svc.Name,
+			"scrape_interval": opts.ScrapeInterval.String(),
+			"metrics_path":    path,
+			"static_configs":  []interface{}{map[string]interface{}{"targets": []string{svc.MetricsTarget}}},
+		})
+	}
+	if !tracing && len(scrapeConfigs) == 0 {
+		return nil, errors.New("no service has tracing or metrics enabled")
+	}
+
+	processors := []string{"memory_limiter", "batch"}
+	cfg := collectorConfig{
+		Receivers: map[string]interface{}{},
+		Processors: map[string]interface{}{
+			"memory_limiter": map[string]interface{}{
+				"check_interval":         "1s",
+				"limit_percentage":       80,
+				"spike_limit_percentage": 25,
+			},
+			"batch": map[string]interface{}{},
+		},
+		Exporters: map[string]interface{}{},
+		Service:   collectorPipelines{Pipelines: map[string]collectorPipeline{}},
+	}
+
+	if tracing {
+		cfg.Receivers["otlp"] = map[string]interface{}{
+			"protocols": map[string]interface{}{
+				"http": map[string]interface{}{"endpoint": opts.OTLPEndpoint},
+			},
+		}
+		cfg.Exporters["otlphttp/traces"] = map[string]interface{}{
+			"endpoint": opts.TracesEndpoint,
+			"tls":      map[string]interface{}{"insecure": !strings.HasPrefix(opts.TracesEndpoint, "https://")},
+		}
+		cfg.Service.Pipelines["traces"] = collectorPipeline{
+			Receivers:  []string{"otlp"},
+			Processors: processors,
+			Exporters:  []string{"otlphttp/traces"},
+		}
+	}
+
+	if len(scrapeConfigs) > 0 {
+		cfg.Receivers["prometheus"] = map[string]interface{}{
+			"config": map[string]interface{}{"scrape_configs": scrapeConfigs},
+		}
+		cfg.Exporters["prometheus"] = map[string]interface{}{
+			"endpoint":                         opts.PrometheusEndpoint,
+			"resource_to_telemetry_conversion": map[string]interface{}{"enabled": true},
+			// OpenMetrics carries the exemplars linking histogram buckets to traces
+			"enable_open_metrics": true,
+		}
+		cfg.Service.Pipelines["metrics"] = collectorPipeline{
+			Receivers:  []string{"prometheus"},
+			Processors: processors,
+			Exporters:  []string{"prometheus"},
+		}
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render collector config: %w", err)
+	}
+	return append([]byte(CollectorConfigHeader), data...), nil
+}
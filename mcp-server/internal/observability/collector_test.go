@@ -0,0 +1,120 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateCollectorConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		services      []CollectorService
+		wantPipelines []string
+		wantJobs      []string
+		wantErr       string
+	}{
+		{
+			name: "traces and metrics",
+			services: []CollectorService{
+				{Name: "mcp-server", Tracing: true, MetricsTarget: "mcp-server:8080"},
+				{Name: "a2a-server", Tracing: true, MetricsTarget: "a2a-server:8081"},
+			},
+			wantPipelines: []string{"metrics", "traces"},
+			wantJobs:      []string{"mcp-server", "a2a-server"},
+		},
+		{
+			name:          "traces only",
+			services:      []CollectorService{{Name: "mcp-server", Tracing: true}},
+			wantPipelines: []string{"traces"},
+		},
+		{
+			name:          "metrics only",
+			services:      []CollectorService{{Name: "mcp-server", MetricsTarget: "mcp-server:8080"}},
+			wantPipelines: []string{"metrics"},
+			wantJobs:      []string{"mcp-server"},
+		},
+		{
+			name:     "no signals",
+			services: []CollectorService{{Name: "mcp-server"}},
+			wantErr:  "no service has tracing or metrics enabled",
+		},
+		{
+			name:     "invalid target",
+			services: []CollectorService{{Name: "mcp-server", MetricsTarget: "mcp-server"}},
+			wantErr:  "invalid metrics target",
+		},
+		{
+			name:     "missing name",
+			services: []CollectorService{{Tracing: true}},
+			wantErr:  "service name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := GenerateCollectorConfig(CollectorOptions{Services: tt.services})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(data), CollectorConfigHeader))
+
+			var cfg struct {
+				Receivers map[string]struct {
+					Config struct {
+						ScrapeConfigs []struct {
+							JobName string `yaml:"job_name"`
+						} `yaml:"scrape_configs"`
+					} `yaml:"config"`
+				} `yaml:"receivers"`
+				Exporters map[string]interface{} `yaml:"exporters"`
+				Service   struct {
+					Pipelines map[string]collectorPipeline `yaml:"pipelines"`
+				} `yaml:"service"`
+			}
+			require.NoError(t, yaml.Unmarshal(data, &cfg))
+
+			var pipelines []string
+			for name := range cfg.Service.Pipelines {
+				pipelines = append(pipelines, name)
+			}
+			assert.ElementsMatch(t, tt.wantPipelines, pipelines)
+
+			var jobs []string
+			for _, sc := range cfg.Receivers["prometheus"].Config.ScrapeConfigs {
+				jobs = append(jobs, sc.JobName)
+			}
+			assert.Equal(t, tt.wantJobs, jobs)
+
+			// Every exporter and receiver referenced by a pipeline must be defined
+			for _, pipeline := range cfg.Service.Pipelines {
+				for _, receiver := range pipeline.Receivers {
+					assert.Contains(t, cfg.Receivers, receiver)
+				}
+				for _, exporter := range pipeline.Exporters {
+					assert.Contains(t, cfg.Exporters, exporter)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateCollectorConfig_Endpoints(t *testing.T) {
+	data, err := GenerateCollectorConfig(CollectorOptions{
+		Services:       []CollectorService{{Name: "mcp-server", Tracing: true}},
+		OTLPEndpoint:   "0.0.0.0:14318",
+		TracesEndpoint: "https://tempo.example.com:4318",
+	})
+	require.NoError(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, "endpoint: 0.0.0.0:14318")
+	assert.Contains(t, out, "endpoint: https://tempo.example.com:4318")
+	assert.Contains(t, out, "insecure: false")
+}
@@ -3,6 +3,7 @@ package observability
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
@@ -22,8 +23,12 @@ type Metrics struct {
 	// Database metrics
 	DBQueryDuration       metric.Float64Histogram
 	DBQueryCount          metric.Int64Counter
-	DBConnectionPoolActive metric.Int64UpDownCounter
-	DBConnectionPoolIdle   metric.Int64UpDownCounter
+	DBConnectionPoolActive metric.Int64ObservableGauge
+	DBConnectionPoolIdle   metric.Int64ObservableGauge
+	DBConnectionPoolTotal  metric.Int64ObservableGauge
+	DBConnectionPoolMax    metric.Int64ObservableGauge
+	DBConnectionPoolTimeouts metric.Int64ObservableCounter
+	DBPoolAcquireDuration  metric.Float64Histogram
 
 	// Search metrics
 	SearchResultCount metric.Int64Histogram
@@ -32,8 +37,46 @@ type Metrics struct {
 	// Document metrics
 	DocumentsRetrieved metric.Int64Counter
 
+	// Cache metrics
+	CacheHits   metric.Int64Counter
+	CacheMisses metric.Int64Counter
+
+	// Embedding consistency metrics
+	StaleEmbeddings metric.Int64Gauge
+
+	// Deduplication metrics
+	DuplicateDocuments metric.Int64Gauge
+
+	// Tenant quota metrics
+	QuotaUsage       metric.Int64Gauge
+	QuotaUtilization metric.Float64Gauge
+
+	// PII redaction metrics
+	Redactions metric.Int64Counter
+
+	// Shutdown metrics
+	DrainCancelled metric.Int64Counter
+
+	// Deprecation metrics
+	DeprecatedUsage metric.Int64Counter
+
+	// Rate limit metrics
+	RateLimitDegraded metric.Int64Counter
+
+	// Circuit breaker metrics
+	BreakerState       metric.Int64Gauge
+	BreakerTransitions metric.Int64Counter
+	BreakerRejected    metric.Int64Counter
+
+	// Cost metrics
+	CostTotal metric.Float64Counter
+
 	// Error metrics
 	ErrorCount metric.Int64Counter
+
+	// Latest connection pool samples, reported by the pool gauge callbacks
+	poolsMu sync.RWMutex
+	pools   []PoolSample
 }
 
 // NewMetrics creates and registers all metrics instruments
@@ -107,24 +150,65 @@ func NewMetrics(meter metric.Meter) (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create db query count metric: %w", err)
 	}
 
-	m.DBConnectionPoolActive, err = meter.Int64UpDownCounter(
+	m.DBConnectionPoolActive, err = meter.Int64ObservableGauge(
 		"mcp.db.connection_pool.active",
-		metric.WithDescription("Number of active database connections"),
+		metric.WithDescription("Number of database and Redis connections in use, per pool"),
 		metric.WithUnit("{connection}"),
+		metric.WithInt64Callback(m.observePools(func(p PoolSample) int64 { return p.Acquired })),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create db connection pool active metric: %w", err)
 	}
 
-	m.DBConnectionPoolIdle, err = meter.Int64UpDownCounter(
+	m.DBConnectionPoolIdle, err = meter.Int64ObservableGauge(
 		"mcp.db.connection_pool.idle",
-		metric.WithDescription("Number of idle database connections"),
+		metric.WithDescription("Number of idle database and Redis connections, per pool"),
 		metric.WithUnit("{connection}"),
+		metric.WithInt64Callback(m.observePools(func(p PoolSample) int64 { return p.Idle })),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create db connection pool idle metric: %w", err)
 	}
 
+	m.DBConnectionPoolTotal, err = meter.Int64ObservableGauge(
+		"mcp.db.connection_pool.total",
+		metric.WithDescription("Number of open database and Redis connections, per pool"),
+		metric.WithUnit("{connection}"),
+		metric.WithInt64Callback(m.observePools(func(p PoolSample) int64 { return p.Total })),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db connection pool total metric: %w", err)
+	}
+
+	m.DBConnectionPoolMax, err = meter.Int64ObservableGauge(
+		"mcp.db.connection_pool.max",
+		metric.WithDescription("Maximum number of database and Redis connections, per pool"),
+		metric.WithUnit("{connection}"),
+		metric.WithInt64Callback(m.observePools(func(p PoolSample) int64 { return p.Max })),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db connection pool max metric: %w", err)
+	}
+
+	m.DBConnectionPoolTimeouts, err = meter.Int64ObservableCounter(
+		"mcp.db.connection_pool.timeouts",
+		metric.WithDescription("Connection acquires that gave up waiting on an exhausted pool"),
+		metric.WithUnit("{acquire}"),
+		metric.WithInt64Callback(m.observePools(func(p PoolSample) int64 { return p.Timeouts })),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db connection pool timeouts metric: %w", err)
+	}
+
+	m.DBPoolAcquireDuration, err = meter.Float64Histogram(
+		"mcp.db.pool.acquire.duration",
+		metric.WithDescription("Time spent waiting for a database connection in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db pool acquire duration metric: %w", err)
+	}
+
 	// Search metrics
 	m.SearchResultCount, err = meter.Int64Histogram(
 		"mcp.search.results",
@@ -154,6 +238,141 @@ func NewMetrics(meter metric.Meter) (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create documents retrieved metric: %w", err)
 	}
 
+	// Cache metrics
+	m.CacheHits, err = meter.Int64Counter(
+		"mcp.cache.hits",
+		metric.WithDescription("Total number of search cache hits"),
+		metric.WithUnit("{hit}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache hits metric: %w", err)
+	}
+
+	m.CacheMisses, err = meter.Int64Counter(
+		"mcp.cache.misses",
+		metric.WithDescription("Total number of search cache misses"),
+		metric.WithUnit("{miss}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache misses metric: %w", err)
+	}
+
+	// Embedding consistency metrics
+	m.StaleEmbeddings, err = meter.Int64Gauge(
+		"mcp.embeddings.stale",
+		metric.WithDescription("Number of documents whose content changed after their embedding was generated"),
+		metric.WithUnit("{document}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stale embeddings metric: %w", err)
+	}
+
+	// Deduplication metrics
+	m.DuplicateDocuments, err = meter.Int64Gauge(
+		"mcp.documents.duplicates",
+		metric.WithDescription("Number of documents found to duplicate an older document of their collection"),
+		metric.WithUnit("{document}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duplicate documents metric: %w", err)
+	}
+
+	// Tenant quota metrics
+	m.QuotaUsage, err = meter.Int64Gauge(
+		"mcp.tenant.quota.usage",
+		metric.WithDescription("Documents or storage bytes a tenant uses, by quota"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quota usage metric: %w", err)
+	}
+
+	m.QuotaUtilization, err = meter.Float64Gauge(
+		"mcp.tenant.quota.utilization",
+		metric.WithDescription("Fraction of a tenant's quota in use, for tenants with a limit"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quota utilization metric: %w", err)
+	}
+
+	// PII redaction metrics
+	m.Redactions, err = meter.Int64Counter(
+		"mcp.redactions",
+		metric.WithDescription("PII masked in ingested documents and tool output, by stage and type"),
+		metric.WithUnit("{redaction}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create redactions metric: %w", err)
+	}
+
+	// Shutdown metrics
+	m.DrainCancelled, err = meter.Int64Counter(
+		"mcp.shutdown.cancelled",
+		metric.WithDescription("In-flight work forcibly cancelled when the shutdown drain period expired"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create drain cancelled metric: %w", err)
+	}
+
+	// Deprecation metrics
+	m.DeprecatedUsage, err = meter.Int64Counter(
+		"mcp.deprecated.usage",
+		metric.WithDescription("Calls to deprecated endpoints, methods and tools"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deprecated usage metric: %w", err)
+	}
+
+	// Rate limit metrics
+	m.RateLimitDegraded, err = meter.Int64Counter(
+		"mcp.ratelimit.degraded",
+		metric.WithDescription("Rate limit decisions made without Redis, by failure policy and decision"),
+		metric.WithUnit("{decision}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit degraded metric: %w", err)
+	}
+
+	// Circuit breaker metrics
+	m.BreakerState, err = meter.Int64Gauge(
+		"mcp.circuit_breaker.state",
+		metric.WithDescription("Circuit breaker state per dependency: 0 closed, 1 half-open, 2 open"),
+		metric.WithUnit("{state}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create circuit breaker state metric: %w", err)
+	}
+
+	m.BreakerTransitions, err = meter.Int64Counter(
+		"mcp.circuit_breaker.transitions",
+		metric.WithDescription("Circuit breaker state transitions"),
+		metric.WithUnit("{transition}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create circuit breaker transitions metric: %w", err)
+	}
+
+	m.BreakerRejected, err = meter.Int64Counter(
+		"mcp.circuit_breaker.rejected",
+		metric.WithDescription("Dependency calls rejected by an open circuit breaker"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create circuit breaker rejected metric: %w", err)
+	}
+
+	// Cost metrics
+	m.CostTotal, err = meter.Float64Counter(
+		"mcp.cost.total",
+		metric.WithDescription("Estimated cost of embedding and LLM calls by tenant, tool and model"),
+		metric.WithUnit("USD"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cost total metric: %w", err)
+	}
+
 	// Error metrics
 	m.ErrorCount, err = meter.Int64Counter(
 		"mcp.error.count",
@@ -189,8 +408,9 @@ func (m *Metrics) RecordToolExecution(ctx context.Context, toolName string, stat
 	m.ToolExecutionDuration.Record(ctx, durationMs, attrs)
 }
 
-// RecordDBQuery records metrics for a database query
-func (m *Metrics) RecordDBQuery(ctx context.Context, queryType string, durationMs float64, err error) {
+// RecordDBQuery records metrics for a database query.
+// The fingerprint identifies the normalized statement for per-query dashboards.
+func (m *Metrics) RecordDBQuery(ctx context.Context, queryType string, fingerprint string, durationMs float64, err error) {
 	status := "success"
 	if err != nil {
 		status = "error"
@@ -198,6 +418,7 @@ func (m *Metrics) RecordDBQuery(ctx context.Context, queryType string, durationM
 
 	attrs := metric.WithAttributes(
 		attribute.String("query.type", queryType),
+		attribute.String("query.fingerprint", fingerprint),
 		attribute.String("status", status),
 	)
 
@@ -205,6 +426,45 @@ func (m *Metrics) RecordDBQuery(ctx context.Context, queryType string, durationM
 	m.DBQueryDuration.Record(ctx, durationMs, attrs)
 }
 
+// RecordDBPoolAcquire records how long a request waited for a connection from the named pool
+func (m *Metrics) RecordDBPoolAcquire(ctx context.Context, pool string, durationMs float64, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	m.DBPoolAcquireDuration.Record(ctx, durationMs, metric.WithAttributes(
+		attribute.String("db.pool", pool),
+		attribute.String("status", status),
+	))
+}
+
+// RecordPoolStats replaces the connection pool samples the pool gauges report
+func (m *Metrics) RecordPoolStats(samples []PoolSample) {
+	m.poolsMu.Lock()
+	defer m.poolsMu.Unlock()
+	m.pools = samples
+}
+
+// observePools returns a callback observing value of each sampled pool
+func (m *Metrics) observePools(value func(PoolSample) int64) metric.Int64Callback {
+	return func(ctx context.Context, o metric.Int64Observer) error {
+		m.poolsMu.RLock()
+		defer m.poolsMu.RUnlock()
+		for _, pool := range m.pools {
+			attrs := []attribute.KeyValue{
+				attribute.String("db.system", pool.System),
+				attribute.String("db.pool", pool.Pool),
+			}
+			if pool.Region != "" {
+				attrs = append(attrs, attribute.String("db.region", pool.Region))
+			}
+			o.Observe(value(pool), metric.WithAttributes(attrs...))
+		}
+		return nil
+	}
+}
+
 // RecordSearchResults records the number of search results
 func (m *Metrics) RecordSearchResults(ctx context.Context, searchType string, count int64) {
 	attrs := metric.WithAttributes(
@@ -214,6 +474,109 @@ func (m *Metrics) RecordSearchResults(ctx context.Context, searchType string, co
 	m.SearchResultCount.Record(ctx, count, attrs)
 }
 
+// RecordCacheLookup records a cache hit or miss for an operation
+func (m *Metrics) RecordCacheLookup(ctx context.Context, operation string, hit bool) {
+	attrs := metric.WithAttributes(
+		attribute.String("cache.operation", operation),
+	)
+
+	if hit {
+		m.CacheHits.Add(ctx, 1, attrs)
+	} else {
+		m.CacheMisses.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordStaleEmbeddings records the number of stale embeddings found for a tenant
+func (m *Metrics) RecordStaleEmbeddings(ctx context.Context, tenantID string, stale int64) {
+	m.StaleEmbeddings.Record(ctx, stale, metric.WithAttributes(
+		attribute.String("tenant.id", tenantID),
+	))
+}
+
+// RecordDuplicateDocuments records the number of duplicate documents found for a tenant
+func (m *Metrics) RecordDuplicateDocuments(ctx context.Context, tenantID string, duplicates int64) {
+	m.DuplicateDocuments.Record(ctx, duplicates, metric.WithAttributes(
+		attribute.String("tenant.id", tenantID),
+	))
+}
+
+// RecordQuotaUsage records a tenant's usage of a quota (storage.QuotaDocuments or
+// QuotaStorageBytes) and, when the tenant has a limit, the fraction of it in use
+func (m *Metrics) RecordQuotaUsage(ctx context.Context, tenantID, quota string, used, limit int64) {
+	attrs := metric.WithAttributes(
+		attribute.String("tenant.id", tenantID),
+		attribute.String("quota", quota),
+	)
+	m.QuotaUsage.Record(ctx, used, attrs)
+	if limit > 0 {
+		m.QuotaUtilization.Record(ctx, float64(used)/float64(limit), attrs)
+	}
+}
+
+// RecordRedactions records PII of a type masked at a stage (redaction.StageIngest or StageOutput)
+func (m *Metrics) RecordRedactions(ctx context.Context, stage, piiType string, count int64) {
+	m.Redactions.Add(ctx, count, metric.WithAttributes(
+		attribute.String("stage", stage),
+		attribute.String("type", piiType),
+	))
+}
+
+// RecordDrainCancelled records in-flight work of a kind cancelled at shutdown
+func (m *Metrics) RecordDrainCancelled(ctx context.Context, kind string, count int64) {
+	m.DrainCancelled.Add(ctx, count, metric.WithAttributes(
+		attribute.String("kind", kind),
+	))
+}
+
+// RecordDeprecatedUsage records a call to a deprecated endpoint, method or tool
+func (m *Metrics) RecordDeprecatedUsage(ctx context.Context, kind, name string) {
+	m.DeprecatedUsage.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("kind", kind),
+		attribute.String("name", name),
+	))
+}
+
+// RecordRateLimitDegraded records a rate limit decision made without Redis
+func (m *Metrics) RecordRateLimitDegraded(ctx context.Context, policy, decision string) {
+	m.RateLimitDegraded.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("policy", policy),
+		attribute.String("decision", decision),
+	))
+}
+
+// RecordBreakerState records a circuit breaker entering state, coming from the state
+// named from; from is empty for the initial state
+func (m *Metrics) RecordBreakerState(ctx context.Context, name, from, to string, state int64) {
+	m.BreakerState.Record(ctx, state, metric.WithAttributes(
+		attribute.String("dependency", name),
+	))
+	if from != "" {
+		m.BreakerTransitions.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("dependency", name),
+			attribute.String("from", from),
+			attribute.String("to", to),
+		))
+	}
+}
+
+// RecordBreakerRejected records a dependency call rejected by an open circuit breaker
+func (m *Metrics) RecordBreakerRejected(ctx context.Context, name string) {
+	m.BreakerRejected.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("dependency", name),
+	))
+}
+
+// RecordCost records the estimated cost of an embedding or LLM call made for a tenant's tool call
+func (m *Metrics) RecordCost(ctx context.Context, tenantID, tool, operation, model string, costUSD float64) {
+	m.CostTotal.Add(ctx, costUSD, metric.WithAttributes(
+		attribute.String("tenant.id", tenantID),
+		attribute.String("tool", tool),
+		attribute.String("operation", operation),
+		attribute.String("model", model),
+	))
+}
+
 // RecordError records an error occurrence
 func (m *Metrics) RecordError(ctx context.Context, errorType string, operation string) {
 	attrs := metric.WithAttributes(
@@ -0,0 +1,116 @@
+package observability
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultPoolStatsInterval is how often connection pools are sampled by default
+const DefaultPoolStatsInterval = 15 * time.Second
+
+// PoolSample is a snapshot of one connection pool
+type PoolSample struct {
+	// System is the pooled service, such as "postgresql" or "redis"
+	System string
+	// Pool names the pool within the system, such as "primary" or a replica
+	Pool string
+	// Region is the data region of a regional database pool, empty otherwise
+	Region string
+
+	Max      int64
+	Total    int64
+	Acquired int64
+	Idle     int64
+	// Timeouts counts acquires that gave up waiting for a connection, since the pool started
+	Timeouts int64
+}
+
+// PoolSource returns a snapshot of each pool it covers
+type PoolSource func() []PoolSample
+
+// PoolReporter samples connection pools on an interval and publishes them as the
+// connection pool gauges
+type PoolReporter struct {
+	metrics  *Metrics
+	interval time.Duration
+
+	mu      sync.Mutex
+	sources []PoolSource
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewPoolReporter creates a reporter sampling every interval; zero means
+// DefaultPoolStatsInterval
+func NewPoolReporter(metrics *Metrics, interval time.Duration) *PoolReporter {
+	if interval <= 0 {
+		interval = DefaultPoolStatsInterval
+	}
+	return &PoolReporter{
+		metrics:  metrics,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Add registers the pools source returns
+func (r *PoolReporter) Add(source PoolSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, source)
+}
+
+// Start samples the pools right away and then on every interval
+func (r *PoolReporter) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Close stops sampling
+func (r *PoolReporter) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+func (r *PoolReporter) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.Sample()
+
+		select {
+		case <-ticker.C:
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Sample snapshots every pool and publishes the snapshots
+func (r *PoolReporter) Sample() {
+	r.mu.Lock()
+	sources := r.sources
+	r.mu.Unlock()
+
+	var samples []PoolSample
+	for _, source := range sources {
+		samples = append(samples, source()...)
+	}
+	if r.metrics != nil {
+		r.metrics.RecordPoolStats(samples)
+	}
+	for _, sample := range samples {
+		if sample.Max > 0 && sample.Acquired >= sample.Max {
+			slog.Warn("Connection pool exhausted",
+				"system", sample.System, "pool", sample.Pool, "region", sample.Region, "max_conns", sample.Max)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package observability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestPoolReporter_Sample(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	metrics, err := NewMetrics(provider.Meter("test"))
+	require.NoError(t, err)
+
+	acquired := int64(3)
+	reporter := NewPoolReporter(metrics, 0)
+	reporter.Add(func() []PoolSample {
+		return []PoolSample{{System: "postgresql", Pool: "primary", Region: "eu", Max: 25, Total: 5, Acquired: acquired, Idle: 5 - acquired, Timeouts: 2}}
+	})
+	reporter.Add(func() []PoolSample {
+		return []PoolSample{{System: "redis", Pool: "default", Max: 10, Total: 1, Idle: 1}}
+	})
+
+	collect := func() map[string]map[string]int64 {
+		var data metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &data))
+		values := make(map[string]map[string]int64)
+		for _, scope := range data.ScopeMetrics {
+			for _, m := range scope.Metrics {
+				var points []metricdata.DataPoint[int64]
+				switch d := m.Data.(type) {
+				case metricdata.Gauge[int64]:
+					points = d.DataPoints
+				case metricdata.Sum[int64]:
+					points = d.DataPoints
+				}
+				for _, point := range points {
+					system, _ := point.Attributes.Value(attribute.Key("db.system"))
+					if values[m.Name] == nil {
+						values[m.Name] = make(map[string]int64)
+					}
+					values[m.Name][system.AsString()] = point.Value
+				}
+			}
+		}
+		return values
+	}
+
+	// Nothing is reported before the first sample
+	assert.Empty(t, collect()["mcp.db.connection_pool.active"])
+
+	reporter.Sample()
+	values := collect()
+	assert.Equal(t, map[string]int64{"postgresql": 3, "redis": 0}, values["mcp.db.connection_pool.active"])
+	assert.Equal(t, map[string]int64{"postgresql": 2, "redis": 1}, values["mcp.db.connection_pool.idle"])
+	assert.Equal(t, map[string]int64{"postgresql": 25, "redis": 10}, values["mcp.db.connection_pool.max"])
+	assert.Equal(t, int64(2), values["mcp.db.connection_pool.timeouts"]["postgresql"])
+
+	// Gauges report the latest sample
+	acquired = 5
+	reporter.Sample()
+	assert.Equal(t, int64(5), collect()["mcp.db.connection_pool.active"]["postgresql"])
+}
@@ -0,0 +1,71 @@
+package onboarding
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A2ABudgetClient sets user budgets through the A2A server's admin endpoint,
+// PUT /admin/budgets/{user_id}
+type A2ABudgetClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// Ensure A2ABudgetClient implements BudgetSetter
+var _ BudgetSetter = (*A2ABudgetClient)(nil)
+
+// NewA2ABudgetClient creates a client for the A2A server at baseURL that authenticates
+// with the server's admin token
+func NewA2ABudgetClient(baseURL, token string) *A2ABudgetClient {
+	return &A2ABudgetClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetTLSConfig sets the TLS configuration of the connections to the A2A server, e.g. to
+// present a client certificate
+func (c *A2ABudgetClient) SetTLSConfig(config *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	c.client.Transport = transport
+}
+
+// SetBudget implements BudgetSetter
+func (c *A2ABudgetClient) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error {
+	body, err := json.Marshal(map[string]float64{"monthly_limit_usd": monthlyLimitUSD})
+	if err != nil {
+		return err
+	}
+
+	endpoint := c.baseURL + "/admin/budgets/" + url.PathEscape(userID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to set A2A budget: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to set A2A budget: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
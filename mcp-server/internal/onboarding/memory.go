@@ -0,0 +1,58 @@
+package onboarding
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryTenantStore keeps tenants in memory, for the local storage drivers and tests
+type MemoryTenantStore struct {
+	mu      sync.RWMutex
+	tenants map[string]Tenant // ID -> tenant
+	names   map[string]string // name -> ID
+}
+
+// Ensure MemoryTenantStore implements TenantStore
+var _ TenantStore = (*MemoryTenantStore)(nil)
+
+// NewMemoryTenantStore creates an empty in-memory tenant store
+func NewMemoryTenantStore() *MemoryTenantStore {
+	return &MemoryTenantStore{
+		tenants: make(map[string]Tenant),
+		names:   make(map[string]string),
+	}
+}
+
+// CreateTenant implements TenantStore
+func (s *MemoryTenantStore) CreateTenant(ctx context.Context, tenant *Tenant) error {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	id[6] = id[6]&0x0f | 0x40 // version 4
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.names[tenant.Name]; exists {
+		return ErrTenantExists
+	}
+	tenant.ID = fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+	tenant.CreatedAt = time.Now().UTC()
+	s.tenants[tenant.ID] = *tenant
+	s.names[tenant.Name] = tenant.ID
+	return nil
+}
+
+// TenantRateLimit returns the rate limit recorded in a tenant's settings, or 0 if it has none
+func (s *MemoryTenantStore) TenantRateLimit(ctx context.Context, tenantID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit, _ := s.tenants[tenantID].Settings[SettingRateLimit].(int)
+	return limit, nil
+}
@@ -0,0 +1,376 @@
+// Package onboarding provisions a new tenant in one call: the tenant and its settings,
+// the limits of its tier, an admin role and API key for its first user, optional sample
+// documents and the user's budget on the A2A server. It replaces the manual SQL and
+// per-service setup a new tenant used to need.
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+var (
+	// ErrInvalidRequest is returned for onboarding requests that fail validation
+	ErrInvalidRequest = errors.New("invalid onboarding request")
+	// ErrTenantExists is returned when the tenant name is already taken
+	ErrTenantExists = errors.New("tenant already exists")
+	// ErrIncomplete is returned with the bundle when the tenant was created but a later step failed
+	ErrIncomplete = errors.New("onboarding incomplete")
+)
+
+// Tenant settings keys written by onboarding
+const (
+	SettingTier          = "tier"
+	SettingRateLimit     = "rate_limit_per_minute"
+	SettingMonthlyBudget = "monthly_budget_usd"
+	// Document quotas, enforced when documents are inserted; absent or 0 is unlimited
+	SettingMaxDocuments    = "max_documents"
+	SettingMaxStorageBytes = "max_storage_bytes"
+)
+
+// Onboarding steps, in the order they run
+const (
+	StepCreateTenant    = "create_tenant"
+	StepAssignAdminRole = "assign_admin_role"
+	StepIssueAPIKey     = "issue_api_key"
+	StepSampleDocuments = "seed_sample_documents"
+	StepA2ABudget       = "set_a2a_budget"
+)
+
+// Step outcomes
+const (
+	StepDone    = "done"
+	StepSkipped = "skipped"
+	StepFailed  = "failed"
+)
+
+// namePattern restricts tenant names to lowercase slugs such as "acme-corp"
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// Tier is a named bundle of limits applied to new tenants
+type Tier struct {
+	RateLimitPerMinute int     `yaml:"rate_limit_per_minute" json:"rate_limit_per_minute"`
+	MonthlyBudgetUSD   float64 `yaml:"monthly_budget_usd" json:"monthly_budget_usd"`
+	// Document quotas; 0 is unlimited
+	MaxDocuments    int64 `yaml:"max_documents" json:"max_documents,omitempty"`
+	MaxStorageBytes int64 `yaml:"max_storage_bytes" json:"max_storage_bytes,omitempty"`
+}
+
+// DefaultTiers returns the built-in tiers, matching the demo budgets of the A2A server
+func DefaultTiers() map[string]Tier {
+	return map[string]Tier{
+		"free":       {RateLimitPerMinute: 60, MonthlyBudgetUSD: 10, MaxDocuments: 1000, MaxStorageBytes: 100 << 20},
+		"pro":        {RateLimitPerMinute: 300, MonthlyBudgetUSD: 50, MaxDocuments: 100000, MaxStorageBytes: 10 << 30},
+		"enterprise": {RateLimitPerMinute: 1000, MonthlyBudgetUSD: 200},
+	}
+}
+
+// Config holds onboarding configuration
+type Config struct {
+	Tiers       map[string]Tier `yaml:"tiers"`
+	DefaultTier string          `yaml:"default_tier"`
+	// APIKeyTTL is how long the admin API key issued to a new tenant is valid
+	APIKeyTTL time.Duration `yaml:"api_key_ttl"`
+	// A2AURL is the A2A server base URL budgets are set on, and GDPR requests export and
+	// erase A2A user data from; empty skips both
+	A2AURL string `yaml:"a2a_url"`
+	// A2AAdminToken authenticates to the A2A server's admin endpoints
+	A2AAdminToken string `yaml:"a2a_admin_token"`
+}
+
+// DefaultConfig returns the built-in onboarding configuration
+func DefaultConfig() Config {
+	return Config{
+		Tiers:       DefaultTiers(),
+		DefaultTier: "free",
+		APIKeyTTL:   90 * 24 * time.Hour,
+	}
+}
+
+// Validate checks the tiers and the default tier
+func (c Config) Validate() error {
+	var errs []error
+	if _, ok := c.Tiers[c.DefaultTier]; !ok {
+		errs = append(errs, fmt.Errorf("default_tier %q is not a configured tier", c.DefaultTier))
+	}
+	for name, tier := range c.Tiers {
+		if tier.RateLimitPerMinute <= 0 {
+			errs = append(errs, fmt.Errorf("tiers.%s.rate_limit_per_minute must be positive, got %d", name, tier.RateLimitPerMinute))
+		}
+		if tier.MonthlyBudgetUSD < 0 {
+			errs = append(errs, fmt.Errorf("tiers.%s.monthly_budget_usd must not be negative, got %g", name, tier.MonthlyBudgetUSD))
+		}
+		if tier.MaxDocuments < 0 || tier.MaxStorageBytes < 0 {
+			errs = append(errs, fmt.Errorf("tiers.%s.max_documents and max_storage_bytes must not be negative", name))
+		}
+	}
+	if c.APIKeyTTL <= 0 {
+		errs = append(errs, fmt.Errorf("api_key_ttl must be positive, got %s", c.APIKeyTTL))
+	}
+	return errors.Join(errs...)
+}
+
+// Tenant is a provisioned tenant
+type Tenant struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	DataRegion string                 `json:"data_region,omitempty"`
+	Settings   map[string]interface{} `json:"settings"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// TenantStore persists tenants
+type TenantStore interface {
+	// CreateTenant stores a new active tenant and fills in its ID and creation time, and
+	// its data region if none is set. It returns ErrTenantExists if the name is taken.
+	CreateTenant(ctx context.Context, tenant *Tenant) error
+}
+
+// BudgetSetter sets a user's monthly budget, e.g. on the A2A server
+type BudgetSetter interface {
+	SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error
+}
+
+// Request describes a tenant to onboard. Limits left at zero come from the tier.
+type Request struct {
+	Name               string                 `json:"name"`
+	Tier               string                 `json:"tier,omitempty"`
+	DataRegion         string                 `json:"data_region,omitempty"`
+	AdminUserID        string                 `json:"admin_user_id"`
+	RateLimitPerMinute int                    `json:"rate_limit_per_minute,omitempty"`
+	MonthlyBudgetUSD   float64                `json:"monthly_budget_usd,omitempty"`
+	MaxDocuments       int64                  `json:"max_documents,omitempty"`
+	MaxStorageBytes    int64                  `json:"max_storage_bytes,omitempty"`
+	Settings           map[string]interface{} `json:"settings,omitempty"` // additional tenant settings
+	SampleDocuments    bool                   `json:"sample_documents,omitempty"`
+}
+
+// Bundle is everything a new tenant needs to start: its ID, limits and admin credentials
+type Bundle struct {
+	Tenant             Tenant    `json:"tenant"`
+	Tier               string    `json:"tier"`
+	RateLimitPerMinute int       `json:"rate_limit_per_minute"`
+	MonthlyBudgetUSD   float64   `json:"monthly_budget_usd"`
+	MaxDocuments       int64     `json:"max_documents,omitempty"`
+	MaxStorageBytes    int64     `json:"max_storage_bytes,omitempty"`
+	AdminUserID        string    `json:"admin_user_id"`
+	AdminRole          auth.Role `json:"admin_role"`
+	APIKey             *APIKey   `json:"api_key,omitempty"`
+	SampleDocuments    int       `json:"sample_documents"`
+	Steps              []Step    `json:"steps"`
+}
+
+// APIKey is a bearer token for the tenant's admin user
+type APIKey struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Step records the outcome of one onboarding step
+type Step struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Service runs the onboarding steps
+type Service struct {
+	tenants TenantStore
+	roles   auth.RoleStore
+	config  Config
+	regions map[string]bool
+
+	documents storage.Writer
+	issuer    *auth.TokenIssuer
+	budgets   BudgetSetter
+}
+
+// NewService creates an onboarding service. Requests cannot choose a data region
+// until SetRegions is called; the tenant store places them in its default.
+func NewService(tenants TenantStore, roles auth.RoleStore, config Config) *Service {
+	return &Service{
+		tenants: tenants,
+		roles:   roles,
+		config:  config,
+		regions: map[string]bool{},
+	}
+}
+
+// SetRegions sets the data regions requests may place new tenants in
+func (s *Service) SetRegions(regions []string) {
+	s.regions = make(map[string]bool, len(regions))
+	for _, region := range regions {
+		s.regions[region] = true
+	}
+}
+
+// SetDocumentStore enables seeding sample documents into new tenants
+func (s *Service) SetDocumentStore(documents storage.Writer) {
+	s.documents = documents
+}
+
+// SetTokenIssuer enables issuing an API key for the new tenant's admin user
+func (s *Service) SetTokenIssuer(issuer *auth.TokenIssuer) {
+	s.issuer = issuer
+}
+
+// SetBudgets enables setting the admin user's budget, e.g. with an A2ABudgetClient
+func (s *Service) SetBudgets(budgets BudgetSetter) {
+	s.budgets = budgets
+}
+
+// Onboard creates the tenant and runs the remaining steps. Validation errors wrap
+// ErrInvalidRequest and nothing is created. Once the tenant exists every step runs;
+// if any fails the bundle is returned with ErrIncomplete and its steps say which.
+func (s *Service) Onboard(ctx context.Context, req Request) (*Bundle, error) {
+	tierName := req.Tier
+	if tierName == "" {
+		tierName = s.config.DefaultTier
+	}
+	tier, err := s.validate(req, tierName)
+	if err != nil {
+		return nil, err
+	}
+	if req.RateLimitPerMinute > 0 {
+		tier.RateLimitPerMinute = req.RateLimitPerMinute
+	}
+	if req.MonthlyBudgetUSD > 0 {
+		tier.MonthlyBudgetUSD = req.MonthlyBudgetUSD
+	}
+	if req.MaxDocuments > 0 {
+		tier.MaxDocuments = req.MaxDocuments
+	}
+	if req.MaxStorageBytes > 0 {
+		tier.MaxStorageBytes = req.MaxStorageBytes
+	}
+
+	settings := make(map[string]interface{}, len(req.Settings)+3)
+	for key, value := range req.Settings {
+		settings[key] = value
+	}
+	settings[SettingTier] = tierName
+	settings[SettingRateLimit] = tier.RateLimitPerMinute
+	settings[SettingMonthlyBudget] = tier.MonthlyBudgetUSD
+	if tier.MaxDocuments > 0 {
+		settings[SettingMaxDocuments] = tier.MaxDocuments
+	}
+	if tier.MaxStorageBytes > 0 {
+		settings[SettingMaxStorageBytes] = tier.MaxStorageBytes
+	}
+
+	tenant := Tenant{Name: req.Name, DataRegion: req.DataRegion, Settings: settings}
+	if err := s.tenants.CreateTenant(ctx, &tenant); err != nil {
+		if errors.Is(err, ErrTenantExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	bundle := &Bundle{
+		Tenant:             tenant,
+		Tier:               tierName,
+		RateLimitPerMinute: tier.RateLimitPerMinute,
+		MonthlyBudgetUSD:   tier.MonthlyBudgetUSD,
+		MaxDocuments:       tier.MaxDocuments,
+		MaxStorageBytes:    tier.MaxStorageBytes,
+		AdminUserID:        req.AdminUserID,
+		AdminRole:          auth.RoleAdmin,
+		Steps:              []Step{{Name: StepCreateTenant, Status: StepDone}},
+	}
+	record := func(name string, err error) {
+		step := Step{Name: name, Status: StepDone}
+		if err != nil {
+			step.Status, step.Error = StepFailed, err.Error()
+			slog.ErrorContext(ctx, "Onboarding step failed", "tenant_id", tenant.ID, "step", name, "error", err)
+		}
+		bundle.Steps = append(bundle.Steps, step)
+	}
+	skip := func(name string) {
+		bundle.Steps = append(bundle.Steps, Step{Name: name, Status: StepSkipped})
+	}
+
+	record(StepAssignAdminRole, s.roles.AssignRole(ctx, auth.RoleAssignment{
+		TenantID:   tenant.ID,
+		UserID:     req.AdminUserID,
+		Role:       auth.RoleAdmin,
+		AssignedBy: "onboarding",
+		AssignedAt: time.Now().UTC(),
+	}))
+
+	// The key carries no scopes of its own: the admin role grants them, so revoking
+	// the role through /admin/roles also disarms the key
+	if s.issuer != nil {
+		token, expiresAt, err := s.issuer.Issue(tenant.ID, req.AdminUserID, nil, s.config.APIKeyTTL)
+		if err == nil {
+			bundle.APIKey = &APIKey{Token: token, ExpiresAt: expiresAt}
+		}
+		record(StepIssueAPIKey, err)
+	} else {
+		skip(StepIssueAPIKey)
+	}
+
+	if req.SampleDocuments && s.documents != nil {
+		count, err := SeedSampleDocuments(ctx, s.documents, tenant.ID)
+		bundle.SampleDocuments = count
+		record(StepSampleDocuments, err)
+	} else {
+		skip(StepSampleDocuments)
+	}
+
+	if s.budgets != nil {
+		record(StepA2ABudget, s.budgets.SetBudget(ctx, req.AdminUserID, tier.MonthlyBudgetUSD))
+	} else {
+		skip(StepA2ABudget)
+	}
+
+	for _, step := range bundle.Steps {
+		if step.Status == StepFailed {
+			return bundle, ErrIncomplete
+		}
+	}
+	slog.InfoContext(ctx, "Tenant onboarded", "tenant_id", tenant.ID, "tenant", tenant.Name, "tier", tierName, "data_region", tenant.DataRegion)
+	return bundle, nil
+}
+
+// validate checks the request and returns the tier it selects
+func (s *Service) validate(req Request, tierName string) (Tier, error) {
+	invalid := func(format string, args ...interface{}) (Tier, error) {
+		return Tier{}, fmt.Errorf("%w: %s", ErrInvalidRequest, fmt.Sprintf(format, args...))
+	}
+
+	if !namePattern.MatchString(req.Name) {
+		return invalid("name %q must be 2-63 lowercase letters, digits or '-'", req.Name)
+	}
+	if req.AdminUserID == "" {
+		return invalid("admin_user_id is required")
+	}
+	tier, ok := s.config.Tiers[tierName]
+	if !ok {
+		names := make([]string, 0, len(s.config.Tiers))
+		for name := range s.config.Tiers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return invalid("unknown tier %q (expected one of %v)", tierName, names)
+	}
+	if req.DataRegion != "" && !s.regions[req.DataRegion] {
+		return invalid("unknown data_region %q", req.DataRegion)
+	}
+	if req.RateLimitPerMinute < 0 || req.MonthlyBudgetUSD < 0 || req.MaxDocuments < 0 || req.MaxStorageBytes < 0 {
+		return invalid("rate_limit_per_minute, monthly_budget_usd, max_documents and max_storage_bytes must not be negative")
+	}
+	for _, key := range []string{SettingTier, SettingRateLimit, SettingMonthlyBudget, SettingMaxDocuments, SettingMaxStorageBytes} {
+		if _, ok := req.Settings[key]; ok {
+			return invalid("settings.%s is set by onboarding; use the request fields instead", key)
+		}
+	}
+	return tier, nil
+}
@@ -0,0 +1,186 @@
+package onboarding
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBudgets records budgets and optionally fails
+type fakeBudgets struct {
+	budgets map[string]float64
+	err     error
+}
+
+func (f *fakeBudgets) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.budgets[userID] = monthlyLimitUSD
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *MemoryTenantStore, *auth.MemoryRoleStore, *storage.MemoryStore, *fakeBudgets, *auth.TokenIssuer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	issuer, err := auth.NewTokenIssuer(key, "test", "mcp-server-demo", "mcp-server")
+	require.NoError(t, err)
+
+	tenants := NewMemoryTenantStore()
+	roles := auth.NewMemoryRoleStore()
+	documents := storage.NewMemoryStore()
+	budgets := &fakeBudgets{budgets: map[string]float64{}}
+
+	service := NewService(tenants, roles, DefaultConfig())
+	service.SetRegions([]string{"default", "eu-west"})
+	service.SetDocumentStore(documents)
+	service.SetTokenIssuer(issuer)
+	service.SetBudgets(budgets)
+	return service, tenants, roles, documents, budgets, issuer
+}
+
+func TestService_Onboard(t *testing.T) {
+	service, tenants, roles, documents, budgets, issuer := newTestService(t)
+	ctx := context.Background()
+
+	bundle, err := service.Onboard(ctx, Request{
+		Name:               "delta-co",
+		Tier:               "pro",
+		DataRegion:         "eu-west",
+		AdminUserID:        "alice",
+		RateLimitPerMinute: 500,
+		MaxDocuments:       2000,
+		Settings:           map[string]interface{}{"industry": "retail"},
+		SampleDocuments:    true,
+	})
+	require.NoError(t, err)
+
+	tenantID := bundle.Tenant.ID
+	assert.NotEmpty(t, tenantID)
+	assert.Equal(t, "eu-west", bundle.Tenant.DataRegion)
+	assert.Equal(t, "pro", bundle.Tier)
+	assert.Equal(t, 500, bundle.RateLimitPerMinute, "request overrides the tier")
+	assert.Equal(t, 50.0, bundle.MonthlyBudgetUSD, "unset limits come from the tier")
+	assert.Equal(t, map[string]interface{}{
+		"industry": "retail", SettingTier: "pro", SettingRateLimit: 500, SettingMonthlyBudget: 50.0,
+		SettingMaxDocuments: int64(2000), SettingMaxStorageBytes: int64(10 << 30),
+	}, bundle.Tenant.Settings)
+	assert.Equal(t, int64(2000), bundle.MaxDocuments)
+	for _, step := range bundle.Steps {
+		assert.Equal(t, StepDone, step.Status, step.Name)
+	}
+	assert.Len(t, bundle.Steps, 5)
+
+	// The rate limiter sees the tenant's own limit
+	limit, err := tenants.TenantRateLimit(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, 500, limit)
+
+	assignment, err := roles.GetRoleAssignment(ctx, tenantID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleAdmin, assignment.Role)
+
+	docs, err := documents.ListDocuments(ctx, tenantID, "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, docs, bundle.SampleDocuments)
+	assert.Len(t, docs, len(SampleDocuments()))
+
+	assert.Equal(t, map[string]float64{"alice": 50}, budgets.budgets)
+
+	// The API key authenticates as the admin user of the new tenant
+	validator, err := auth.NewJWTValidator(auth.Config{
+		Keys:     auth.KeySet{issuer.VerificationKey()},
+		Issuer:   "mcp-server-demo",
+		Audience: "mcp-server",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, bundle.APIKey)
+	claims, err := validator.ValidateToken(bundle.APIKey.Token)
+	require.NoError(t, err)
+	assert.Equal(t, tenantID, claims.TenantID)
+	assert.Equal(t, "alice", claims.UserID)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), bundle.APIKey.ExpiresAt, time.Minute)
+}
+
+func TestService_Onboard_SkipsUnconfiguredSteps(t *testing.T) {
+	service := NewService(NewMemoryTenantStore(), auth.NewMemoryRoleStore(), DefaultConfig())
+
+	bundle, err := service.Onboard(context.Background(), Request{Name: "echo", AdminUserID: "bob", SampleDocuments: true})
+	require.NoError(t, err)
+	assert.Equal(t, "free", bundle.Tier)
+	assert.Nil(t, bundle.APIKey)
+	assert.Equal(t, []Step{
+		{Name: StepCreateTenant, Status: StepDone},
+		{Name: StepAssignAdminRole, Status: StepDone},
+		{Name: StepIssueAPIKey, Status: StepSkipped},
+		{Name: StepSampleDocuments, Status: StepSkipped},
+		{Name: StepA2ABudget, Status: StepSkipped},
+	}, bundle.Steps)
+}
+
+func TestService_Onboard_Errors(t *testing.T) {
+	service, _, _, _, budgets, _ := newTestService(t)
+	ctx := context.Background()
+
+	invalid := []Request{
+		{Name: "Bad Name", AdminUserID: "alice"},
+		{Name: "foxtrot"},
+		{Name: "foxtrot", AdminUserID: "alice", Tier: "gold"},
+		{Name: "foxtrot", AdminUserID: "alice", DataRegion: "mars"},
+		{Name: "foxtrot", AdminUserID: "alice", MonthlyBudgetUSD: -1},
+		{Name: "foxtrot", AdminUserID: "alice", Settings: map[string]interface{}{SettingTier: "enterprise"}},
+	}
+	for _, req := range invalid {
+		_, err := service.Onboard(ctx, req)
+		assert.ErrorIs(t, err, ErrInvalidRequest, "%+v", req)
+	}
+
+	_, err := service.Onboard(ctx, Request{Name: "foxtrot", AdminUserID: "alice"})
+	require.NoError(t, err)
+	_, err = service.Onboard(ctx, Request{Name: "foxtrot", AdminUserID: "carol"})
+	assert.ErrorIs(t, err, ErrTenantExists)
+
+	// A failed step after the tenant exists still returns the bundle
+	budgets.err = errors.New("a2a unavailable")
+	bundle, err := service.Onboard(ctx, Request{Name: "golf", AdminUserID: "dave"})
+	assert.ErrorIs(t, err, ErrIncomplete)
+	require.NotNil(t, bundle)
+	assert.NotEmpty(t, bundle.Tenant.ID)
+	assert.NotNil(t, bundle.APIKey)
+	last := bundle.Steps[len(bundle.Steps)-1]
+	assert.Equal(t, Step{Name: StepA2ABudget, Status: StepFailed, Error: "a2a unavailable"}, last)
+}
+
+func TestA2ABudgetClient(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]float64
+	a2a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		if gotAuth != "Bearer secret" {
+			http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer a2a.Close()
+
+	client := NewA2ABudgetClient(a2a.URL+"/", "secret")
+	require.NoError(t, client.SetBudget(context.Background(), "alice", 25))
+	assert.Equal(t, "/admin/budgets/alice", gotPath)
+	assert.Equal(t, map[string]float64{"monthly_limit_usd": 25}, gotBody)
+
+	err := NewA2ABudgetClient(a2a.URL, "wrong").SetBudget(context.Background(), "alice", 25)
+	assert.ErrorContains(t, err, "Invalid admin token")
+}
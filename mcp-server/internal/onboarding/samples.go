@@ -0,0 +1,45 @@
+package onboarding
+
+import (
+	"context"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// SampleDocuments returns a few of the init-db.sql demo documents, for trying out
+// search in a new tenant
+func SampleDocuments() []storage.Document {
+	return []storage.Document{
+		{
+			Title:    "Q4 Security Policy",
+			Content:  "All employees must use MFA for accessing production systems. Password rotation is required every 90 days. VPN access is mandatory for remote work. Security training is required annually. Report security incidents immediately to the security team.",
+			Metadata: map[string]interface{}{"category": "security", "department": "engineering", "version": "2024.Q4"},
+		},
+		{
+			Title:    "Remote Work Guidelines",
+			Content:  "Remote work is permitted with manager approval. Employees must maintain regular working hours and be available during core hours (10am-3pm local time). Use company-approved collaboration tools for meetings. Ensure secure home network setup.",
+			Metadata: map[string]interface{}{"category": "hr", "department": "all", "version": "2024.1"},
+		},
+		{
+			Title:    "API Design Standards",
+			Content:  "All APIs must follow RESTful principles. Use JSON for request/response payloads. Implement proper error handling with standard HTTP status codes. Version APIs using URL paths (e.g., /api/v1/). Document all endpoints using OpenAPI/Swagger.",
+			Metadata: map[string]interface{}{"category": "engineering", "department": "engineering", "version": "2024.2"},
+		},
+		{
+			Title:    "Data Privacy and GDPR Compliance",
+			Content:  "Handle customer data according to GDPR requirements. Implement data retention policies. Obtain explicit consent for data collection. Provide mechanisms for data export and deletion. Encrypt sensitive data at rest and in transit.",
+			Metadata: map[string]interface{}{"category": "compliance", "department": "legal", "version": "2024.3"},
+		},
+	}
+}
+
+// SeedSampleDocuments inserts the sample documents into a tenant and returns how many were inserted
+func SeedSampleDocuments(ctx context.Context, store storage.Writer, tenantID string) (int, error) {
+	docs := SampleDocuments()
+	for i := range docs {
+		if err := store.InsertDocument(ctx, tenantID, &docs[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(docs), nil
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultChannelPrefix prefixes the Redis channel of each tenant's document events
+const DefaultChannelPrefix = "mcp:documents"
+
+// Publisher delivers document events to subscribers
+type Publisher interface {
+	// Publish delivers a batch of events, in order. An error leaves the whole batch
+	// unpublished in the outbox, so it is retried.
+	Publish(ctx context.Context, events []database.DocumentEvent) error
+}
+
+// RedisPublisher publishes each event as JSON on the Redis Pub/Sub channel
+// "<prefix>:<tenant_id>". Subscribe to "<prefix>:*" with PSUBSCRIBE for every tenant.
+type RedisPublisher struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisPublisher creates a Redis Pub/Sub publisher; an empty prefix uses DefaultChannelPrefix
+func NewRedisPublisher(client *redis.Client, prefix string) *RedisPublisher {
+	if prefix == "" {
+		prefix = DefaultChannelPrefix
+	}
+	return &RedisPublisher{client: client, prefix: prefix}
+}
+
+// Channel returns the channel a tenant's events are published on
+func (p *RedisPublisher) Channel(tenantID string) string {
+	return p.prefix + ":" + tenantID
+}
+
+// Publish implements Publisher, sending the batch in one pipeline
+func (p *RedisPublisher) Publish(ctx context.Context, events []database.DocumentEvent) error {
+	pipe := p.client.Pipeline()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode document event %d: %w", event.ID, err)
+		}
+		pipe.Publish(ctx, p.Channel(event.TenantID), data)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish document events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// Package outbox relays the document lifecycle events recorded in the database outbox
+// to an event stream, so downstream consumers such as embedding pipelines, caches and
+// external indexers can follow document changes.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// Config holds outbox relay configuration
+type Config struct {
+	// Interval is how often the outbox is polled (default 1s)
+	Interval time.Duration
+	// BatchSize is how many events are published per batch (default 100)
+	BatchSize int
+	// Retention is how long published events are kept (default 24h)
+	Retention time.Duration
+	// PurgeInterval is how often published events past their retention are deleted (default 1h)
+	PurgeInterval time.Duration
+	// TenantTimeout bounds relaying a single tenant's events (default 30s)
+	TenantTimeout time.Duration
+}
+
+// Relay polls every tenant's outbox and publishes new events. Events are marked
+// published only after the publisher accepts them, so delivery is at least once:
+// consumers should skip event IDs they have already handled.
+type Relay struct {
+	tenants   database.TenantLister
+	store     database.OutboxStore
+	publisher Publisher
+	config    Config
+
+	lastPurge time.Time
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewRelay creates a new outbox relay. Tenants are listed from the control plane and
+// relayed through store, which may route to regional databases.
+func NewRelay(tenants database.TenantLister, store database.OutboxStore, publisher Publisher, cfg Config) *Relay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = time.Hour
+	}
+	if cfg.TenantTimeout <= 0 {
+		cfg.TenantTimeout = 30 * time.Second
+	}
+
+	return &Relay{
+		tenants:   tenants,
+		store:     store,
+		publisher: publisher,
+		config:    cfg,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start relays events right away and then on every interval
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Close stops the relay and waits for a running pass to finish
+func (r *Relay) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+// run relays all tenants on every interval until the relay is closed
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RelayAll(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Document event relay failed", "error", err)
+		}
+		if time.Since(r.lastPurge) >= r.config.PurgeInterval {
+			r.PurgeAll(ctx)
+			r.lastPurge = time.Now()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// RelayAll publishes the pending events of every active tenant and returns how many
+// were published. A failing tenant is logged and skipped so it cannot block the others.
+func (r *Relay) RelayAll(ctx context.Context) (int, error) {
+	tenantIDs, err := r.tenants.ListActiveTenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+		n, err := r.relayTenant(ctx, tenantID)
+		published += n
+		if err != nil {
+			slog.ErrorContext(ctx, "Document event relay failed for tenant", "tenant_id", tenantID, "error", err)
+		}
+	}
+	return published, nil
+}
+
+// relayTenant publishes a tenant's pending events batch by batch within the tenant timeout
+func (r *Relay) relayTenant(ctx context.Context, tenantID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.TenantTimeout)
+	defer cancel()
+
+	published := 0
+	for {
+		n, err := r.store.RelayDocumentEvents(ctx, tenantID, r.config.BatchSize, func(events []database.DocumentEvent) error {
+			return r.publisher.Publish(ctx, events)
+		})
+		published += n
+		if err != nil || n < r.config.BatchSize {
+			return published, err
+		}
+	}
+}
+
+// PurgeAll deletes every tenant's published events past the retention
+func (r *Relay) PurgeAll(ctx context.Context) {
+	tenantIDs, err := r.tenants.ListActiveTenants(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Document event purge failed", "error", err)
+		return
+	}
+
+	before := time.Now().Add(-r.config.Retention)
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		tenantCtx, cancel := context.WithTimeout(ctx, r.config.TenantTimeout)
+		deleted, err := r.store.PurgeDocumentEvents(tenantCtx, tenantID, before)
+		cancel()
+		if err != nil {
+			slog.ErrorContext(ctx, "Document event purge failed for tenant", "tenant_id", tenantID, "error", err)
+		} else if deleted > 0 {
+			slog.InfoContext(ctx, "Purged published document events", "tenant_id", tenantID, "deleted", deleted)
+		}
+	}
+}
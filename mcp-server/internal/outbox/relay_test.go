@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTenants []string
+
+func (f fakeTenants) ListActiveTenants(ctx context.Context) ([]string, error) {
+	return f, nil
+}
+
+// fakeOutbox keeps each tenant's pending events in order and marks them published
+// only when the publish callback succeeds, as the database does
+type fakeOutbox struct {
+	mu        sync.Mutex
+	pending   map[string][]database.DocumentEvent
+	published map[string]int
+	purged    map[string]time.Time
+}
+
+func newFakeOutbox(events map[string]int) *fakeOutbox {
+	f := &fakeOutbox{pending: map[string][]database.DocumentEvent{}, published: map[string]int{}, purged: map[string]time.Time{}}
+	id := int64(0)
+	for tenantID, n := range events {
+		for i := 0; i < n; i++ {
+			id++
+			f.pending[tenantID] = append(f.pending[tenantID], database.DocumentEvent{
+				ID: id, TenantID: tenantID, DocumentID: fmt.Sprintf("doc-%d", id), Collection: "default", Type: database.DocumentCreated,
+			})
+		}
+	}
+	return f
+}
+
+func (f *fakeOutbox) RelayDocumentEvents(ctx context.Context, tenantID string, limit int, publish func([]database.DocumentEvent) error) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	batch := f.pending[tenantID]
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(batch); err != nil {
+		return 0, err
+	}
+	f.pending[tenantID] = f.pending[tenantID][len(batch):]
+	f.published[tenantID] += len(batch)
+	return len(batch), nil
+}
+
+func (f *fakeOutbox) PurgeDocumentEvents(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.purged[tenantID] = before
+	return 0, nil
+}
+
+// recordingPublisher records published batches and fails while failing is set
+type recordingPublisher struct {
+	mu      sync.Mutex
+	events  []database.DocumentEvent
+	failing bool
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, events []database.DocumentEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failing {
+		return errors.New("broker unavailable")
+	}
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func TestRelay_RelayAll(t *testing.T) {
+	store := newFakeOutbox(map[string]int{"tenant-a": 250, "tenant-b": 3})
+	publisher := &recordingPublisher{}
+	relay := NewRelay(fakeTenants{"tenant-a", "tenant-b", "tenant-c"}, store, publisher, Config{})
+
+	published, err := relay.RelayAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 253, published)
+	assert.Equal(t, 250, store.published["tenant-a"], "batches continue until the outbox is drained")
+	assert.Len(t, publisher.events, 253)
+
+	// Each tenant's events are published in order
+	for i := 1; i < 250; i++ {
+		assert.Less(t, publisher.events[i-1].ID, publisher.events[i].ID)
+	}
+}
+
+func TestRelay_KeepsEventsWhenPublishFails(t *testing.T) {
+	store := newFakeOutbox(map[string]int{"tenant-a": 5})
+	publisher := &recordingPublisher{failing: true}
+	relay := NewRelay(fakeTenants{"tenant-a"}, store, publisher, Config{})
+
+	published, err := relay.RelayAll(context.Background())
+	require.NoError(t, err, "a failing tenant is logged and skipped")
+	assert.Zero(t, published)
+	assert.Len(t, store.pending["tenant-a"], 5)
+
+	publisher.failing = false
+	published, err = relay.RelayAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, published)
+}
+
+func TestRelay_StartAndClose(t *testing.T) {
+	store := newFakeOutbox(map[string]int{"tenant-a": 2})
+	relay := NewRelay(fakeTenants{"tenant-a"}, store, &recordingPublisher{}, Config{Interval: time.Hour, Retention: time.Hour})
+	relay.Start()
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.published["tenant-a"] == 2 && !store.purged["tenant-a"].IsZero()
+	}, time.Second, 10*time.Millisecond)
+	relay.Close()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), store.purged["tenant-a"], time.Minute)
+}
+
+func TestRedisPublisher(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	sub := client.PSubscribe(ctx, DefaultChannelPrefix+":*")
+	defer sub.Close()
+	_, err := sub.Receive(ctx) // subscription confirmation
+	require.NoError(t, err)
+
+	publisher := NewRedisPublisher(client, "")
+	events := []database.DocumentEvent{
+		{ID: 1, TenantID: "tenant-a", DocumentID: "doc-1", Collection: "default", Type: database.DocumentCreated},
+		{ID: 2, TenantID: "tenant-b", DocumentID: "doc-2", Collection: "tickets", Type: database.DocumentDeleted},
+	}
+	require.NoError(t, publisher.Publish(ctx, events))
+
+	for _, want := range events {
+		msg, err := sub.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "mcp:documents:"+want.TenantID, msg.Channel)
+		var got database.DocumentEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &got))
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestSubscriber(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	received := make(chan database.DocumentEvent, 2)
+	subscriber := NewSubscriber(client, "", func(event database.DocumentEvent) { received <- event })
+	ctx := context.Background()
+	require.NoError(t, subscriber.Start(ctx))
+	defer subscriber.Close()
+
+	require.NoError(t, client.Publish(ctx, "mcp:documents:tenant-a", "not json").Err())
+	want := database.DocumentEvent{ID: 7, TenantID: "tenant-a", DocumentID: "doc-7", Collection: "default", Type: database.DocumentUpdated}
+	require.NoError(t, NewRedisPublisher(client, "").Publish(ctx, []database.DocumentEvent{want}))
+
+	select {
+	case got := <-received:
+		assert.Equal(t, want, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
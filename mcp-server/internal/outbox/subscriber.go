@@ -0,0 +1,67 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// Subscriber receives the document events RedisPublisher publishes for every tenant.
+// Pub/Sub delivers only to connected subscribers, so events published while the
+// subscriber is disconnected are missed.
+type Subscriber struct {
+	client  *redis.Client
+	pattern string
+	handle  func(database.DocumentEvent)
+
+	pubsub *redis.PubSub
+	wg     sync.WaitGroup
+}
+
+// NewSubscriber creates a subscriber calling handle for each event; an empty prefix uses DefaultChannelPrefix
+func NewSubscriber(client *redis.Client, prefix string, handle func(database.DocumentEvent)) *Subscriber {
+	if prefix == "" {
+		prefix = DefaultChannelPrefix
+	}
+	return &Subscriber{client: client, pattern: prefix + ":*", handle: handle}
+}
+
+// Start subscribes to every tenant's channel and handles events in the background
+// until Close. It returns once the subscription is confirmed.
+func (s *Subscriber) Start(ctx context.Context) error {
+	s.pubsub = s.client.PSubscribe(ctx, s.pattern)
+	if _, err := s.pubsub.Receive(ctx); err != nil {
+		s.pubsub.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", s.pattern, err)
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Close unsubscribes and waits for the handler to return
+func (s *Subscriber) Close() {
+	if s.pubsub == nil {
+		return
+	}
+	s.pubsub.Close()
+	s.wg.Wait()
+}
+
+func (s *Subscriber) run() {
+	defer s.wg.Done()
+	for msg := range s.pubsub.Channel() {
+		var event database.DocumentEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			slog.Warn("Ignoring malformed document event", "channel", msg.Channel, "error", err)
+			continue
+		}
+		s.handle(event)
+	}
+}
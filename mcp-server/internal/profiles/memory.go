@@ -0,0 +1,70 @@
+package profiles
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps profiles in memory, for the local storage drivers and tests
+type MemoryStore struct {
+	mu       sync.RWMutex
+	profiles map[string]map[string]Profile // tenant ID -> name -> profile
+}
+
+// Ensure MemoryStore implements Store
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory profile store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{profiles: make(map[string]map[string]Profile)}
+}
+
+// GetProfile implements Store
+func (s *MemoryStore) GetProfile(ctx context.Context, tenantID, name string) (*Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, ok := s.profiles[tenantID][name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &profile, nil
+}
+
+// ListProfiles implements Store
+func (s *MemoryStore) ListProfiles(ctx context.Context, tenantID string) ([]Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := make([]Profile, 0, len(s.profiles[tenantID]))
+	for _, profile := range s.profiles[tenantID] {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// PutProfile implements Store
+func (s *MemoryStore) PutProfile(ctx context.Context, tenantID string, profile Profile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.profiles[tenantID] == nil {
+		s.profiles[tenantID] = make(map[string]Profile)
+	}
+	s.profiles[tenantID][profile.Name] = profile
+	return nil
+}
+
+// DeleteProfile implements Store
+func (s *MemoryStore) DeleteProfile(ctx context.Context, tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[tenantID][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.profiles[tenantID], name)
+	return nil
+}
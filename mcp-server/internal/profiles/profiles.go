@@ -0,0 +1,146 @@
+// Package profiles manages tenant search profiles: named sets of search defaults
+// (weights, limits, re-ranking, query preprocessing and filters) that tools apply when
+// called with "profile", so clients don't have to carry tuning parameters in their prompts.
+package profiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// ErrNotFound is returned when a tenant has no profile with the requested name
+var ErrNotFound = errors.New("search profile not found")
+
+// SettingsKey is the tenant settings key that holds the profiles, keyed by name
+const SettingsKey = "search_profiles"
+
+// namePattern restricts profile names to short, URL-safe identifiers such as "support-kb"
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Profile is a named set of search defaults. Unset fields leave the tool's own
+// defaults in place, and arguments passed with a call override the profile.
+type Profile struct {
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description,omitempty"`
+	Limit           int                    `json:"limit,omitempty"`
+	BM25Weight      *float64               `json:"bm25_weight,omitempty"`
+	VectorWeight    *float64               `json:"vector_weight,omitempty"`
+	Fusion          string                 `json:"fusion,omitempty"`
+	RRFK            int                    `json:"rrf_k,omitempty"`
+	Diversify       *bool                  `json:"diversify,omitempty"` // MMR re-ranking on or off
+	DiversityLambda *float64               `json:"diversity_lambda,omitempty"`
+	Filters         map[string]interface{} `json:"filters,omitempty"`
+	CorrectSpelling *bool                  `json:"correct_spelling,omitempty"`
+	ExpandSynonyms  *bool                  `json:"expand_synonyms,omitempty"`
+}
+
+// Store persists search profiles per tenant
+type Store interface {
+	// GetProfile returns a tenant's profile by name, or ErrNotFound
+	GetProfile(ctx context.Context, tenantID, name string) (*Profile, error)
+
+	// ListProfiles returns a tenant's profiles sorted by name
+	ListProfiles(ctx context.Context, tenantID string) ([]Profile, error)
+
+	// PutProfile creates or replaces a profile
+	PutProfile(ctx context.Context, tenantID string, profile Profile) error
+
+	// DeleteProfile removes a profile, or returns ErrNotFound
+	DeleteProfile(ctx context.Context, tenantID, name string) error
+}
+
+// ValidateName checks that name can be used as a profile name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("profile name %q must be 1-64 lowercase letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// Validate checks the profile's name and settings against the limits the search tools enforce
+func (p Profile) Validate() error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	if p.Limit < 0 || p.Limit > 100 {
+		return fmt.Errorf("limit must be between 0 and 100")
+	}
+	for name, weight := range map[string]*float64{"bm25_weight": p.BM25Weight, "vector_weight": p.VectorWeight, "diversity_lambda": p.DiversityLambda} {
+		if weight != nil && (*weight < 0 || *weight > 1) {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if p.RRFK < 0 {
+		return fmt.Errorf("rrf_k must not be negative")
+	}
+	if p.Fusion != "" {
+		if _, err := storage.NewFusion(p.Fusion, p.RRFK); err != nil {
+			return err
+		}
+	}
+	if _, err := storage.ParseMetadataFilter(p.Filters); err != nil {
+		return fmt.Errorf("invalid filters: %w", err)
+	}
+	return nil
+}
+
+// Apply returns a copy of a tool call's arguments with the profile's settings filled in
+// where the call leaves them unset. Filters are merged, with the call's keys taking
+// precedence. Tools ignore settings they don't support.
+func (p Profile) Apply(args map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(args)+8)
+	for key, value := range args {
+		merged[key] = value
+	}
+
+	setDefault := func(key string, value interface{}) {
+		if _, ok := merged[key]; !ok {
+			merged[key] = value
+		}
+	}
+	if p.Limit > 0 {
+		setDefault("limit", p.Limit)
+	}
+	if p.BM25Weight != nil {
+		setDefault("bm25_weight", *p.BM25Weight)
+	}
+	if p.VectorWeight != nil {
+		setDefault("vector_weight", *p.VectorWeight)
+	}
+	if p.Fusion != "" {
+		setDefault("fusion", p.Fusion)
+	}
+	if p.RRFK > 0 {
+		setDefault("rrf_k", p.RRFK)
+	}
+	if p.Diversify != nil {
+		setDefault("diversify", *p.Diversify)
+	}
+	if p.DiversityLambda != nil {
+		setDefault("diversity_lambda", *p.DiversityLambda)
+	}
+	if p.CorrectSpelling != nil {
+		setDefault("correct_spelling", *p.CorrectSpelling)
+	}
+	if p.ExpandSynonyms != nil {
+		setDefault("expand_synonyms", *p.ExpandSynonyms)
+	}
+
+	// Malformed call filters are left for the tool to reject
+	callFilters, ok := merged["filters"].(map[string]interface{})
+	if _, present := merged["filters"]; len(p.Filters) > 0 && (ok || !present) {
+		filters := make(map[string]interface{}, len(p.Filters)+len(callFilters))
+		for key, value := range p.Filters {
+			filters[key] = value
+		}
+		for key, value := range callFilters {
+			filters[key] = value
+		}
+		merged["filters"] = filters
+	}
+	return merged
+}
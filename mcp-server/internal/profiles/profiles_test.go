@@ -0,0 +1,126 @@
+package profiles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float(v float64) *float64 { return &v }
+func boolean(v bool) *bool     { return &v }
+
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr bool
+	}{
+		{"minimal", Profile{Name: "support-kb"}, false},
+		{"full", Profile{
+			Name: "support_kb2", Limit: 20, BM25Weight: float(0.3), VectorWeight: float(0.7),
+			Fusion: "rrf", RRFK: 30, Diversify: boolean(true), DiversityLambda: float(0.5),
+			Filters: map[string]interface{}{"category": "support"},
+		}, false},
+		{"empty name", Profile{}, true},
+		{"uppercase name", Profile{Name: "Support"}, true},
+		{"name with slash", Profile{Name: "a/b"}, true},
+		{"limit too large", Profile{Name: "p", Limit: 101}, true},
+		{"weight out of range", Profile{Name: "p", BM25Weight: float(1.5)}, true},
+		{"lambda out of range", Profile{Name: "p", DiversityLambda: float(-0.1)}, true},
+		{"unknown fusion", Profile{Name: "p", Fusion: "borda"}, true},
+		{"negative rrf_k", Profile{Name: "p", RRFK: -1}, true},
+		{"invalid filters", Profile{Name: "p", Filters: map[string]interface{}{"tags": []interface{}{}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProfileApply(t *testing.T) {
+	profile := Profile{
+		Name:         "support-kb",
+		Limit:        20,
+		BM25Weight:   float(0.2),
+		VectorWeight: float(0.8),
+		Fusion:       "minmax",
+		Diversify:    boolean(false),
+		Filters:      map[string]interface{}{"category": "support", "status": "published"},
+
+		ExpandSynonyms: boolean(true),
+	}
+
+	args := map[string]interface{}{
+		"query":   "reset password",
+		"profile": "support-kb",
+		"limit":   5,
+		"filters": map[string]interface{}{"status": []interface{}{"published", "draft"}},
+	}
+	merged := profile.Apply(args)
+
+	assert.Equal(t, "reset password", merged["query"])
+	assert.Equal(t, 5, merged["limit"], "call arguments take precedence")
+	assert.Equal(t, 0.2, merged["bm25_weight"])
+	assert.Equal(t, 0.8, merged["vector_weight"])
+	assert.Equal(t, "minmax", merged["fusion"])
+	assert.Equal(t, false, merged["diversify"])
+	assert.Equal(t, true, merged["expand_synonyms"])
+	assert.NotContains(t, merged, "correct_spelling")
+	assert.NotContains(t, merged, "rrf_k")
+	assert.NotContains(t, merged, "diversity_lambda")
+	assert.Equal(t, map[string]interface{}{
+		"category": "support",
+		"status":   []interface{}{"published", "draft"},
+	}, merged["filters"])
+
+	// The call's arguments are not modified
+	assert.NotContains(t, args, "fusion")
+	assert.Len(t, args["filters"], 1)
+
+	// Malformed call filters are passed through for the tool to reject
+	merged = profile.Apply(map[string]interface{}{"filters": "category=support"})
+	assert.Equal(t, "category=support", merged["filters"])
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	_, err := store.GetProfile(ctx, "tenant-1", "support-kb")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.PutProfile(ctx, "tenant-1", Profile{Name: "support-kb", Limit: 5}))
+	require.NoError(t, store.PutProfile(ctx, "tenant-1", Profile{Name: "legal", Limit: 10}))
+	require.NoError(t, store.PutProfile(ctx, "tenant-2", Profile{Name: "support-kb", Limit: 50}))
+
+	profile, err := store.GetProfile(ctx, "tenant-1", "support-kb")
+	require.NoError(t, err)
+	assert.Equal(t, 5, profile.Limit)
+
+	list, err := store.ListProfiles(ctx, "tenant-1")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "legal", list[0].Name)
+	assert.Equal(t, "support-kb", list[1].Name)
+
+	require.NoError(t, store.DeleteProfile(ctx, "tenant-1", "support-kb"))
+	assert.ErrorIs(t, store.DeleteProfile(ctx, "tenant-1", "support-kb"), ErrNotFound)
+
+	// Other tenants are unaffected
+	profile, err = store.GetProfile(ctx, "tenant-2", "support-kb")
+	require.NoError(t, err)
+	assert.Equal(t, 50, profile.Limit)
+
+	list, err = store.ListProfiles(ctx, "tenant-3")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -3,13 +3,15 @@ package protocol
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/shared/jsonrpc"
 )
 
 // JSON-RPC 2.0 Specification Implementation
 // https://www.jsonrpc.org/specification
 
 const (
-	JSONRPCVersion = "2.0"
+	JSONRPCVersion = jsonrpc.Version
 )
 
 // Request represents a JSON-RPC 2.0 request
@@ -26,23 +28,32 @@ type Response struct {
 	ID      interface{} `json:"id,omitempty"`
 	Result  interface{} `json:"result,omitempty"`
 	Error   *Error      `json:"error,omitempty"`
+	// Warnings are non-fatal notices about the request, such as use of a deprecated method or tool
+	Warnings []Warning `json:"warnings,omitempty"`
 }
 
-// Error represents a JSON-RPC 2.0 error object
-type Error struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+// Warning is a non-fatal notice attached to a response
+type Warning struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Sunset      string `json:"sunset,omitempty"` // RFC 3339 removal date
+	Replacement string `json:"replacement,omitempty"`
 }
 
+// WarningDeprecated is the code of warnings about deprecated methods and tools
+const WarningDeprecated = "deprecated"
+
+// Error represents a JSON-RPC 2.0 error object
+type Error = jsonrpc.Error
+
 // Standard JSON-RPC error codes
 const (
-	ParseError     = -32700 // Invalid JSON was received
-	InvalidRequest = -32600 // The JSON sent is not a valid Request object
-	MethodNotFound = -32601 // The method does not exist / is not available
-	InvalidParams  = -32602 // Invalid method parameter(s)
-	InternalError  = -32603 // Internal JSON-RPC error
-	ServerError    = -32000 // Generic server error
+	ParseError     = jsonrpc.ParseError
+	InvalidRequest = jsonrpc.InvalidRequest
+	MethodNotFound = jsonrpc.MethodNotFound
+	InvalidParams  = jsonrpc.InvalidParams
+	InternalError  = jsonrpc.InternalError
+	ServerError    = jsonrpc.ServerError
 )
 
 // MCP-specific error codes (extending JSON-RPC)
@@ -52,6 +63,10 @@ const (
 	RateLimitExceeded      = -32003 // Rate limit exceeded
 	ResourceNotFound       = -32004 // Requested resource not found
 	ValidationError        = -32005 // Input validation failed
+	RequestTimeout         = -32006 // Request did not complete in time
+	SafeModeActive         = -32007 // Operation disabled while safe mode is on
+	RequestTooLarge        = -32008 // Request body exceeds the size limit
+	QuotaExceeded          = -32009 // Tenant or collection quota exhausted
 )
 
 // NewRequest creates a new JSON-RPC request
@@ -148,6 +163,14 @@ func ErrorFromCode(code int) string {
 		return "Resource not found"
 	case ValidationError:
 		return "Validation error"
+	case RequestTimeout:
+		return "Request timeout"
+	case SafeModeActive:
+		return "Safe mode active"
+	case RequestTooLarge:
+		return "Request too large"
+	case QuotaExceeded:
+		return "Quota exceeded"
 	default:
 		return "Unknown error"
 	}
@@ -22,8 +22,12 @@ type ClientCapabilities struct {
 	Tools     *ToolCapabilities     `json:"tools,omitempty"`
 	Resources *ResourceCapabilities `json:"resources,omitempty"`
 	Prompts   *PromptCapabilities   `json:"prompts,omitempty"`
+	Sampling  *SamplingCapability   `json:"sampling,omitempty"`
 }
 
+// SamplingCapability indicates the client answers sampling/createMessage requests
+type SamplingCapability struct{}
+
 // ToolCapabilities describes tool-related capabilities
 type ToolCapabilities struct {
 	SupportsProgress bool `json:"supportsProgress,omitempty"`
@@ -80,6 +84,14 @@ type Tool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations *ToolAnnotations       `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations describe the current availability of a tool
+type ToolAnnotations struct {
+	// Disabled is set while calls to the tool are refused, e.g. in safe mode
+	Disabled       bool   `json:"disabled,omitempty"`
+	DisabledReason string `json:"disabledReason,omitempty"`
 }
 
 // ToolsListResult is the response to tools/list
@@ -97,14 +109,31 @@ type ToolCallRequest struct {
 type ToolCallResult struct {
 	Content []ContentBlock `json:"content"`
 	IsError bool           `json:"isError,omitempty"`
+	// IsTruncated marks a result cut to the server's response size limit; the rest is
+	// returned by the read_continuation tool given ContinuationToken
+	IsTruncated       bool   `json:"is_truncated,omitempty"`
+	ContinuationToken string `json:"continuation_token,omitempty"`
 }
 
+// Content block types
+const (
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeResource = "resource"
+	// ContentTypeResourceLink refers to content by URI instead of carrying it
+	ContentTypeResourceLink = "resource_link"
+)
+
 // ContentBlock represents a piece of content in a response
 type ContentBlock struct {
-	Type string `json:"type"` // "text", "image", "resource"
+	Type string `json:"type"` // "text", "image", "resource", "resource_link"
 	Text string `json:"text,omitempty"`
-	Data string `json:"data,omitempty"`
+	Data string `json:"data,omitempty"` // base64 encoded image
 	MimeType string `json:"mimeType,omitempty"`
+	// URI, Name and Size describe the content a resource link refers to
+	URI  string `json:"uri,omitempty"`
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size,omitempty"`
 }
 
 // Resource represents an MCP resource
@@ -131,6 +160,16 @@ type ResourceReadResult struct {
 	Contents []ResourceContents `json:"contents"`
 }
 
+// ResourceSubscribeRequest is the request to subscribe to or unsubscribe from a resource
+type ResourceSubscribeRequest struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedNotification is sent to subscribers when a resource changes
+type ResourceUpdatedNotification struct {
+	URI string `json:"uri"`
+}
+
 // ResourceContents represents the contents of a resource
 type ResourceContents struct {
 	URI      string `json:"uri"`
@@ -182,15 +221,59 @@ type ProgressNotification struct {
 	Total         float64 `json:"total,omitempty"`
 }
 
+// CreateMessageRequest asks the client to sample an LLM completion (sampling/createMessage)
+type CreateMessageRequest struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	// IncludeContext is "none", "thisServer" or "allServers"
+	IncludeContext string   `json:"includeContext,omitempty"`
+	Temperature    *float64 `json:"temperature,omitempty"`
+	MaxTokens      int      `json:"maxTokens"`
+	StopSequences  []string `json:"stopSequences,omitempty"`
+}
+
+// SamplingMessage is a message in a sampling conversation
+type SamplingMessage struct {
+	Role    string       `json:"role"` // "user", "assistant"
+	Content ContentBlock `json:"content"`
+}
+
+// ModelPreferences guide the client's model choice; priorities range from 0 to 1
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         float64     `json:"costPriority,omitempty"`
+	SpeedPriority        float64     `json:"speedPriority,omitempty"`
+	IntelligencePriority float64     `json:"intelligencePriority,omitempty"`
+}
+
+// ModelHint suggests a model by (partial) name
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// CreateMessageResult is the client's answer to sampling/createMessage
+type CreateMessageResult struct {
+	Role       string       `json:"role"`
+	Content    ContentBlock `json:"content"`
+	Model      string       `json:"model"`
+	StopReason string       `json:"stopReason,omitempty"`
+}
+
 // MCP Method Names
 const (
 	MethodInitialize    = "initialize"
 	MethodInitialized   = "notifications/initialized"
 	MethodToolsList     = "tools/list"
 	MethodToolsCall     = "tools/call"
+	MethodToolsListChanged = "notifications/tools/list_changed"
 	MethodResourcesList = "resources/list"
 	MethodResourcesRead = "resources/read"
+	MethodResourcesSubscribe   = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
+	MethodResourcesUpdated     = "notifications/resources/updated"
 	MethodPromptsList   = "prompts/list"
 	MethodPromptsGet    = "prompts/get"
 	MethodProgress      = "notifications/progress"
+	MethodSamplingCreateMessage = "sampling/createMessage"
 )
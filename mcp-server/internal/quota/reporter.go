@@ -0,0 +1,134 @@
+// Package quota publishes tenants' document usage against the quotas in their settings,
+// which database.DB enforces when documents are inserted.
+package quota
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// Config holds quota reporter configuration
+type Config struct {
+	// Interval is how often the usage of all tenants is reported (default 1m)
+	Interval time.Duration
+	// TenantTimeout bounds the usage query of a single tenant (default 30s)
+	TenantTimeout time.Duration
+}
+
+// Reporter periodically publishes each tenant's document count and storage bytes, and
+// the fraction of its quotas they use, as gauges
+type Reporter struct {
+	tenants database.TenantLister
+	store   database.QuotaStore
+	metrics *observability.Metrics
+	config  Config
+
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewReporter creates a new quota reporter. Tenants are listed from the control plane
+// and their usage read through store, which may route to regional databases.
+func NewReporter(tenants database.TenantLister, store database.QuotaStore, metrics *observability.Metrics, cfg Config) *Reporter {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.TenantTimeout <= 0 {
+		cfg.TenantTimeout = 30 * time.Second
+	}
+
+	return &Reporter{
+		tenants: tenants,
+		store:   store,
+		metrics: metrics,
+		config:  cfg,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start reports right away and then on every interval
+func (r *Reporter) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Close stops the background reports and waits for a running report to finish
+func (r *Reporter) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+}
+
+// run reports all tenants on every interval until the reporter is closed
+func (r *Reporter) run() {
+	defer r.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ReportAll(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Quota usage report failed", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// ReportAll records the usage of every active tenant and returns the usage of the
+// tenants that were read. A failing tenant is logged and skipped so it cannot block the others.
+func (r *Reporter) ReportAll(ctx context.Context) ([]storage.QuotaUsage, error) {
+	tenantIDs, err := r.tenants.ListActiveTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]storage.QuotaUsage, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return usages, ctx.Err()
+		}
+
+		usage, err := r.tenantUsage(ctx, tenantID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Quota usage report failed for tenant", "tenant_id", tenantID, "error", err)
+			continue
+		}
+		usages = append(usages, *usage)
+
+		if r.metrics != nil {
+			r.metrics.RecordQuotaUsage(ctx, tenantID, storage.QuotaDocuments, usage.Documents, usage.MaxDocuments)
+			r.metrics.RecordQuotaUsage(ctx, tenantID, storage.QuotaStorageBytes, usage.StorageBytes, usage.MaxStorageBytes)
+		}
+	}
+	return usages, nil
+}
+
+// tenantUsage reads a single tenant's usage within the tenant timeout
+func (r *Reporter) tenantUsage(ctx context.Context, tenantID string) (*storage.QuotaUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.TenantTimeout)
+	defer cancel()
+	return r.store.TenantQuotaUsage(ctx, tenantID)
+}
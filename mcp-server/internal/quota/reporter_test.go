@@ -0,0 +1,109 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// fakeQuotaStore serves canned usage and counts reads per tenant
+type fakeQuotaStore struct {
+	tenants []string
+	usage   map[string]storage.QuotaUsage
+	failing map[string]bool
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (f *fakeQuotaStore) ListActiveTenants(ctx context.Context) ([]string, error) {
+	return f.tenants, nil
+}
+
+func (f *fakeQuotaStore) TenantQuotaUsage(ctx context.Context, tenantID string) (*storage.QuotaUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.reads == nil {
+		f.reads = make(map[string]int)
+	}
+	f.reads[tenantID]++
+
+	if f.failing[tenantID] {
+		return nil, errors.New("database unavailable")
+	}
+	usage := f.usage[tenantID]
+	usage.TenantID = tenantID
+	return &usage, nil
+}
+
+func (f *fakeQuotaStore) readCount(tenantID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reads[tenantID]
+}
+
+func TestReporter_ReportAll(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	metrics, err := observability.NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	require.NoError(t, err)
+
+	store := &fakeQuotaStore{
+		tenants: []string{"tenant-a", "tenant-b", "tenant-c"},
+		usage: map[string]storage.QuotaUsage{
+			"tenant-a": {Documents: 250, StorageBytes: 4096, MaxDocuments: 1000},
+			"tenant-c": {Documents: 3},
+		},
+		failing: map[string]bool{"tenant-b": true},
+	}
+
+	reporter := NewReporter(store, store, metrics, Config{})
+	usages, err := reporter.ReportAll(context.Background())
+	require.NoError(t, err)
+
+	// The failing tenant is skipped without stopping the others
+	require.Len(t, usages, 2)
+	assert.Equal(t, "tenant-a", usages[0].TenantID)
+	assert.Equal(t, "tenant-c", usages[1].TenantID)
+	assert.Equal(t, 1, store.readCount("tenant-b"))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	utilization := map[string]float64{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "mcp.tenant.quota.utilization" {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Gauge[float64]).DataPoints {
+				tenant, _ := point.Attributes.Value("tenant.id")
+				quota, _ := point.Attributes.Value("quota")
+				utilization[tenant.AsString()+"/"+quota.AsString()] = point.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"tenant-a/documents": 0.25}, utilization, "only quotas with a limit have a utilization")
+}
+
+func TestReporter_StartAndClose(t *testing.T) {
+	store := &fakeQuotaStore{tenants: []string{"tenant-a"}}
+	reporter := NewReporter(store, store, nil, Config{Interval: 10 * time.Millisecond})
+
+	reporter.Start()
+	assert.Eventually(t, func() bool { return store.readCount("tenant-a") >= 2 }, time.Second, 5*time.Millisecond)
+	reporter.Close()
+
+	// No reports run after Close returns
+	count := store.readCount("tenant-a")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, store.readCount("tenant-a"))
+	reporter.Close()
+}
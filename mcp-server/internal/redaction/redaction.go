@@ -0,0 +1,296 @@
+// Package redaction masks personally identifiable information (PII) such as email
+// addresses, US social security numbers and phone numbers. Documents are redacted at
+// ingest and tool output at response time, as each tenant's policy asks.
+package redaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in PII types
+const (
+	TypeEmail = "email"
+	TypeSSN   = "ssn"
+	TypePhone = "phone"
+)
+
+// Stages text is redacted at
+const (
+	// StageIngest redacts documents before they are embedded and stored
+	StageIngest = "ingest"
+	// StageOutput redacts the text of tool results before they are returned
+	StageOutput = "output"
+)
+
+// SettingPolicy is the tenant setting that overrides the default policy, e.g.
+// {"pii_redaction": {"ingest": true, "output": true, "types": ["email", "ssn"]}}.
+// Fields the setting leaves out keep their default.
+const SettingPolicy = "pii_redaction"
+
+// Match is PII found in a text, as byte offsets
+type Match struct {
+	Type  string
+	Start int
+	End   int
+}
+
+// Detector finds PII in text. Detectors other than the built-in regular expressions,
+// such as a named entity recognizer, are added with Redactor.AddDetector.
+type Detector interface {
+	Detect(text string) []Match
+}
+
+// RegexDetector reports every match of a regular expression as one type of PII
+type RegexDetector struct {
+	Type    string
+	Pattern *regexp.Regexp
+}
+
+// Detect implements Detector
+func (d *RegexDetector) Detect(text string) []Match {
+	var matches []Match
+	for _, loc := range d.Pattern.FindAllStringIndex(text, -1) {
+		matches = append(matches, Match{Type: d.Type, Start: loc[0], End: loc[1]})
+	}
+	return matches
+}
+
+// DefaultDetectors returns detectors for email addresses, dashed social security numbers
+// and North American phone numbers written with separators, e.g. (555) 123-4567 or
+// +1 555.123.4567. Bare digit runs are left alone since they are mostly IDs.
+func DefaultDetectors() []Detector {
+	return []Detector{
+		&RegexDetector{Type: TypeEmail, Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+		&RegexDetector{Type: TypeSSN, Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+		&RegexDetector{Type: TypePhone, Pattern: regexp.MustCompile(`(?:\+1[-. ]?)?(?:\(\d{3}\) ?|\b\d{3}[-. ])\d{3}[-. ]\d{4}\b`)},
+	}
+}
+
+// Mask returns the text that replaces PII of a type, e.g. [REDACTED_EMAIL]
+func Mask(piiType string) string {
+	return "[REDACTED_" + strings.ToUpper(piiType) + "]"
+}
+
+// Policy selects the stages and PII types a tenant's text is redacted at
+type Policy struct {
+	Ingest bool     `yaml:"ingest" json:"ingest"`
+	Output bool     `yaml:"output" json:"output"`
+	Types  []string `yaml:"types" json:"types,omitempty"` // empty redacts every detected type
+}
+
+// Redacts reports whether the policy redacts PII of a type at a stage
+func (p Policy) Redacts(stage, piiType string) bool {
+	switch {
+	case stage == StageIngest && !p.Ingest, stage == StageOutput && !p.Output:
+		return false
+	case len(p.Types) == 0:
+		return true
+	}
+	for _, t := range p.Types {
+		if t == piiType {
+			return true
+		}
+	}
+	return false
+}
+
+// enabled reports whether the policy redacts anything at a stage
+func (p Policy) enabled(stage string) bool {
+	return (stage == StageIngest && p.Ingest) || (stage == StageOutput && p.Output)
+}
+
+// Config holds redaction configuration
+type Config struct {
+	// Policy applies to tenants without their own SettingPolicy
+	Policy `yaml:",inline"`
+	// PolicyTTL is how long a tenant's policy is cached (default 1m)
+	PolicyTTL time.Duration `yaml:"policy_ttl"`
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	for _, t := range c.Types {
+		if strings.TrimSpace(t) == "" {
+			return fmt.Errorf("types must not contain empty names")
+		}
+	}
+	if c.PolicyTTL < 0 {
+		return fmt.Errorf("policy_ttl must not be negative, got %s", c.PolicyTTL)
+	}
+	return nil
+}
+
+// SettingsLookup returns a tenant's settings
+type SettingsLookup interface {
+	GetTenantSettings(ctx context.Context, tenantID string) (map[string]interface{}, error)
+}
+
+// Recorder counts redactions
+type Recorder interface {
+	RecordRedactions(ctx context.Context, stage, piiType string, count int64)
+}
+
+// Redactor masks PII in text as each tenant's policy asks
+type Redactor struct {
+	config   Config
+	recorder Recorder
+
+	mu        sync.RWMutex
+	detectors []Detector
+	settings  SettingsLookup
+	policies  map[string]cachedPolicy
+}
+
+// cachedPolicy is a tenant's policy and when it has to be looked up again
+type cachedPolicy struct {
+	policy    Policy
+	expiresAt time.Time
+}
+
+// NewRedactor creates a redactor with the default detectors that applies the configured
+// policy to every tenant until SetSettings is called
+func NewRedactor(cfg Config) *Redactor {
+	if cfg.PolicyTTL <= 0 {
+		cfg.PolicyTTL = time.Minute
+	}
+	return &Redactor{
+		config:    cfg,
+		detectors: DefaultDetectors(),
+		policies:  make(map[string]cachedPolicy),
+	}
+}
+
+// AddDetector adds a detector whose matches are masked along with the built-in types
+func (r *Redactor) AddDetector(detector Detector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detectors = append(r.detectors, detector)
+}
+
+// SetSettings lets tenants' SettingPolicy override the default policy. A tenant's policy
+// is cached for the policy TTL, so a change takes up to that long to apply.
+func (r *Redactor) SetSettings(settings SettingsLookup) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings = settings
+	r.policies = make(map[string]cachedPolicy)
+}
+
+// SetRecorder counts redactions by stage and type as a metric
+func (r *Redactor) SetRecorder(recorder Recorder) {
+	r.recorder = recorder
+}
+
+// Policy returns the policy a tenant's text is redacted by. Tenants whose settings cannot
+// be read get the default policy.
+func (r *Redactor) Policy(ctx context.Context, tenantID string) Policy {
+	r.mu.RLock()
+	lookup := r.settings
+	cached, ok := r.policies[tenantID]
+	r.mu.RUnlock()
+	if lookup == nil {
+		return r.config.Policy
+	}
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.policy
+	}
+
+	settings, err := lookup.GetTenantSettings(ctx, tenantID)
+	if err != nil {
+		slog.WarnContext(ctx, "Tenant redaction policy lookup failed; using the default", "tenant_id", tenantID, "error", err)
+		return r.config.Policy
+	}
+	policy := policyFromSettings(r.config.Policy, settings)
+	r.mu.Lock()
+	r.policies[tenantID] = cachedPolicy{policy: policy, expiresAt: time.Now().Add(r.config.PolicyTTL)}
+	r.mu.Unlock()
+	return policy
+}
+
+// policyFromSettings overlays a tenant's SettingPolicy on the default policy
+func policyFromSettings(defaults Policy, settings map[string]interface{}) Policy {
+	setting, ok := settings[SettingPolicy].(map[string]interface{})
+	if !ok {
+		return defaults
+	}
+	policy := defaults
+	if ingest, ok := setting["ingest"].(bool); ok {
+		policy.Ingest = ingest
+	}
+	if output, ok := setting["output"].(bool); ok {
+		policy.Output = output
+	}
+	if types, ok := setting["types"].([]interface{}); ok {
+		policy.Types = nil
+		for _, t := range types {
+			if name, ok := t.(string); ok {
+				policy.Types = append(policy.Types, name)
+			}
+		}
+	}
+	return policy
+}
+
+// Redact masks the PII in text that the tenant's policy redacts at the stage
+func (r *Redactor) Redact(ctx context.Context, tenantID, stage, text string) string {
+	policy := r.Policy(ctx, tenantID)
+	if !policy.enabled(stage) || text == "" {
+		return text
+	}
+
+	redacted, counts := r.redact(text, func(piiType string) bool { return policy.Redacts(stage, piiType) })
+	if r.recorder != nil {
+		for piiType, count := range counts {
+			r.recorder.RecordRedactions(ctx, stage, piiType, count)
+		}
+	}
+	return redacted
+}
+
+// redact masks the matches of the types selected by include and counts them by type.
+// Overlapping matches are masked once, as the earliest and then longest match.
+func (r *Redactor) redact(text string, include func(piiType string) bool) (string, map[string]int64) {
+	r.mu.RLock()
+	detectors := r.detectors
+	r.mu.RUnlock()
+
+	var matches []Match
+	for _, detector := range detectors {
+		for _, m := range detector.Detect(text) {
+			if m.Start >= 0 && m.End <= len(text) && m.Start < m.End && include(m.Type) {
+				matches = append(matches, m)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return text, nil
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+
+	var b strings.Builder
+	counts := make(map[string]int64)
+	last := 0
+	for _, m := range matches {
+		if m.Start < last {
+			continue
+		}
+		b.WriteString(text[last:m.Start])
+		b.WriteString(Mask(m.Type))
+		counts[m.Type]++
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String(), counts
+}
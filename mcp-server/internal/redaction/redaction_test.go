@@ -0,0 +1,92 @@
+package redaction
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSettings serves canned tenant settings and counts lookups
+type fakeSettings struct {
+	settings map[string]map[string]interface{}
+	lookups  int
+}
+
+func (f *fakeSettings) GetTenantSettings(ctx context.Context, tenantID string) (map[string]interface{}, error) {
+	f.lookups++
+	settings, ok := f.settings[tenantID]
+	if !ok {
+		return nil, errors.New("tenant not found")
+	}
+	return settings, nil
+}
+
+// countingRecorder sums redactions by stage and type
+type countingRecorder map[string]int64
+
+func (c countingRecorder) RecordRedactions(ctx context.Context, stage, piiType string, count int64) {
+	c[stage+"/"+piiType] += count
+}
+
+func TestDefaultDetectors(t *testing.T) {
+	redactor := NewRedactor(Config{Policy: Policy{Ingest: true}})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Mail jane.doe+hr@corp.example.com today", "Mail [REDACTED_EMAIL] today"},
+		{"SSN 123-45-6789.", "SSN [REDACTED_SSN]."},
+		{"Call (555) 123-4567 or +1 555.123.4567", "Call [REDACTED_PHONE] or [REDACTED_PHONE]"},
+		{"Ticket 5551234567 and version 1.2.3", "Ticket 5551234567 and version 1.2.3"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, redactor.Redact(context.Background(), "tenant-a", StageIngest, tt.text))
+	}
+}
+
+func TestRedactor_PolicyAndMetrics(t *testing.T) {
+	settings := &fakeSettings{settings: map[string]map[string]interface{}{
+		"tenant-a": {SettingPolicy: map[string]interface{}{"output": true, "types": []interface{}{TypeSSN}}},
+		"tenant-b": {"monthly_budget_usd": 10.0},
+	}}
+	recorder := countingRecorder{}
+	redactor := NewRedactor(Config{Policy: Policy{Ingest: true}})
+	redactor.SetSettings(settings)
+	redactor.SetRecorder(recorder)
+	ctx := context.Background()
+	text := "jane@example.com, 123-45-6789"
+
+	// tenant-a redacts SSNs at both stages: output from its setting, ingest from the default
+	assert.Equal(t, "jane@example.com, [REDACTED_SSN]", redactor.Redact(ctx, "tenant-a", StageIngest, text))
+	assert.Equal(t, "jane@example.com, [REDACTED_SSN]", redactor.Redact(ctx, "tenant-a", StageOutput, text))
+	assert.Equal(t, 1, settings.lookups, "the policy is cached")
+
+	// tenant-b has no policy of its own; unknown tenants fall back to the default
+	assert.Equal(t, "[REDACTED_EMAIL], [REDACTED_SSN]", redactor.Redact(ctx, "tenant-b", StageIngest, text))
+	assert.Equal(t, text, redactor.Redact(ctx, "tenant-b", StageOutput, text))
+	assert.Equal(t, Policy{Ingest: true}, redactor.Policy(ctx, "tenant-x"))
+
+	assert.Equal(t, countingRecorder{"ingest/ssn": 2, "ingest/email": 1, "output/ssn": 1}, recorder)
+}
+
+func TestRedactor_AddDetector(t *testing.T) {
+	redactor := NewRedactor(Config{Policy: Policy{Output: true}})
+	redactor.AddDetector(&RegexDetector{Type: "employee_id", Pattern: regexp.MustCompile(`\bEMP-\d{6}\b`)})
+
+	// Overlapping matches are masked once, by the match that starts first
+	redactor.AddDetector(&RegexDetector{Type: "contact", Pattern: regexp.MustCompile(`Contact: \S+@\S+`)})
+
+	got := redactor.Redact(context.Background(), "tenant-a", StageOutput, "EMP-004211. Contact: ops@example.com")
+	assert.Equal(t, "[REDACTED_EMPLOYEE_ID]. [REDACTED_CONTACT]", got)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{Policy: Policy{Types: []string{TypeEmail}}}.Validate())
+	assert.Error(t, Config{Policy: Policy{Types: []string{" "}}}.Validate())
+	assert.Error(t, Config{PolicyTTL: -1}.Validate())
+}
@@ -0,0 +1,547 @@
+package residency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+var (
+	// ErrRegionUnavailable is returned when a tenant's region has no configured backend.
+	// Requests fail rather than fall back to another region.
+	ErrRegionUnavailable = errors.New("data region unavailable")
+
+	// ErrResidencyViolation is returned when a backend returns data belonging to another tenant
+	ErrResidencyViolation = errors.New("data residency violation")
+)
+
+// Backend is the set of storage operations served by a regional database
+type Backend interface {
+	storage.ReadWriter
+	database.AccessLogStore
+	database.AuditLogStore
+	database.UserDataStore
+	database.EmbeddingStore
+	database.ReadOnlyQuerier
+	database.VectorIndexStore
+	database.CollectionStore
+	database.OutboxStore
+	database.DuplicateStore
+	database.QuotaStore
+	onboarding.TenantStore
+}
+
+// Ensure DB can serve as a regional backend
+var _ Backend = (*database.DB)(nil)
+
+// Config holds residency router configuration
+type Config struct {
+	// RegionTTL is how long a tenant's region is cached (default 1m)
+	RegionTTL time.Duration
+}
+
+type cachedRegion struct {
+	region    string
+	expiresAt time.Time
+}
+
+// Router sends each tenant's reads and writes to the backend of its data region.
+// Tenant regions are looked up in the control plane database.
+type Router struct {
+	resolver database.RegionResolver
+	backends map[string]Backend
+	ttl      time.Duration
+
+	mu      sync.RWMutex
+	regions map[string]cachedRegion
+}
+
+// NewRouter creates a new residency router over the given regional backends
+func NewRouter(resolver database.RegionResolver, backends map[string]Backend, cfg Config) *Router {
+	if cfg.RegionTTL <= 0 {
+		cfg.RegionTTL = time.Minute
+	}
+
+	return &Router{
+		resolver: resolver,
+		backends: backends,
+		ttl:      cfg.RegionTTL,
+		regions:  make(map[string]cachedRegion),
+	}
+}
+
+// Ensure Router can replace a single database
+var (
+	_ storage.Store           = (*Router)(nil)
+	_ database.AccessLogStore = (*Router)(nil)
+	_ database.AuditLogStore  = (*Router)(nil)
+	_ database.UserDataStore  = (*Router)(nil)
+	_ database.EmbeddingStore = (*Router)(nil)
+	_ database.RegionResolver = (*Router)(nil)
+	_ Backend                 = (*Router)(nil)
+)
+
+// TenantRegion returns the tenant's data region, cached for the configured TTL
+func (r *Router) TenantRegion(ctx context.Context, tenantID string) (string, error) {
+	r.mu.RLock()
+	cached, ok := r.regions[tenantID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.region, nil
+	}
+
+	region, err := r.resolver.TenantRegion(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.regions[tenantID] = cachedRegion{region: region, expiresAt: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+
+	return region, nil
+}
+
+// Backend returns the backend holding a tenant's data
+func (r *Router) Backend(ctx context.Context, tenantID string) (Backend, error) {
+	region, err := r.TenantRegion(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, ok := r.backends[region]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRegionUnavailable, region)
+	}
+	return backend, nil
+}
+
+// CreateTenant creates a tenant in the control plane, the default region's backend, and
+// copies it with the same ID into the database of its data region, where its documents
+// reference it. An unconfigured region fails before anything is created.
+func (r *Router) CreateTenant(ctx context.Context, tenant *onboarding.Tenant) error {
+	if tenant.DataRegion == "" {
+		tenant.DataRegion = database.DefaultRegion
+	}
+	control, ok := r.backends[database.DefaultRegion]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRegionUnavailable, database.DefaultRegion)
+	}
+	backend, ok := r.backends[tenant.DataRegion]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRegionUnavailable, tenant.DataRegion)
+	}
+
+	if err := control.CreateTenant(ctx, tenant); err != nil {
+		return err
+	}
+	if backend == control {
+		return nil
+	}
+	regional := *tenant
+	if err := backend.CreateTenant(ctx, &regional); err != nil {
+		return fmt.Errorf("failed to create tenant in region %s: %w", tenant.DataRegion, err)
+	}
+	return nil
+}
+
+// GetDocument implements storage.Store
+func (r *Router) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := backend.GetDocument(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenant(tenantID, doc.TenantID); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// SearchDocuments implements storage.Store
+func (r *Router) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := backend.SearchDocuments(ctx, tenantID, collection, query, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	return checkDocuments(tenantID, documents)
+}
+
+// ListDocuments implements storage.Store
+func (r *Router) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*storage.Document, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := backend.ListDocuments(ctx, tenantID, collection, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return checkDocuments(tenantID, documents)
+}
+
+// HybridSearch implements storage.Store
+func (r *Router) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := backend.HybridSearch(ctx, tenantID, params)
+	if err != nil {
+		return nil, err
+	}
+	return checkResults(tenantID, results)
+}
+
+// SimpleHybridSearch implements storage.Store
+func (r *Router) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := backend.SimpleHybridSearch(ctx, tenantID, params)
+	if err != nil {
+		return nil, err
+	}
+	return checkResults(tenantID, results)
+}
+
+// SimilarDocuments implements storage.Store
+func (r *Router) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := backend.SimilarDocuments(ctx, tenantID, docID, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if err := checkTenant(tenantID, result.Document.TenantID); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// InsertDocument writes a document to the tenant's region
+func (r *Router) InsertDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.InsertDocument(ctx, tenantID, doc)
+}
+
+// UpdateDocument updates a document in the tenant's region
+func (r *Router) UpdateDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.UpdateDocument(ctx, tenantID, doc)
+}
+
+// DeleteDocument deletes a document from the tenant's region
+func (r *Router) DeleteDocument(ctx context.Context, tenantID, docID string) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.DeleteDocument(ctx, tenantID, docID)
+}
+
+// RecordDocumentAccess implements database.AccessLogStore
+func (r *Router) RecordDocumentAccess(ctx context.Context, tenantID string, entries []database.DocumentAccess) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.RecordDocumentAccess(ctx, tenantID, entries)
+}
+
+// ListAccessByDocument implements database.AccessLogStore
+func (r *Router) ListAccessByDocument(ctx context.Context, tenantID, docID string, limit, offset int) ([]database.DocumentAccess, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListAccessByDocument(ctx, tenantID, docID, limit, offset)
+}
+
+// ListAccessByUser implements database.AccessLogStore
+func (r *Router) ListAccessByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]database.DocumentAccess, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListAccessByUser(ctx, tenantID, userID, limit, offset)
+}
+
+// RecordAuditEntries implements database.AuditLogStore
+func (r *Router) RecordAuditEntries(ctx context.Context, tenantID string, entries []database.AuditEntry) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.RecordAuditEntries(ctx, tenantID, entries)
+}
+
+// ListAuditEntries implements database.AuditLogStore
+func (r *Router) ListAuditEntries(ctx context.Context, tenantID string, filter database.AuditFilter) ([]database.AuditEntry, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListAuditEntries(ctx, tenantID, filter)
+}
+
+// PurgeAuditEntries implements database.AuditLogStore
+func (r *Router) PurgeAuditEntries(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return backend.PurgeAuditEntries(ctx, tenantID, before)
+}
+
+// ExportUserData implements database.UserDataStore
+func (r *Router) ExportUserData(ctx context.Context, tenantID, userID string) (*database.UserData, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ExportUserData(ctx, tenantID, userID)
+}
+
+// EraseUserData implements database.UserDataStore
+func (r *Router) EraseUserData(ctx context.Context, tenantID, userID, pseudonym string) (*database.UserErasure, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.EraseUserData(ctx, tenantID, userID, pseudonym)
+}
+
+// CheckEmbeddings implements database.EmbeddingStore
+func (r *Router) CheckEmbeddings(ctx context.Context, tenantID string) (*database.EmbeddingStatus, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := backend.CheckEmbeddings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenant(tenantID, status.TenantID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// QueryReadOnly implements database.ReadOnlyQuerier
+func (r *Router) QueryReadOnly(ctx context.Context, tenantID, query string, args []interface{}, maxRows int) ([]map[string]interface{}, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.QueryReadOnly(ctx, tenantID, query, args, maxRows)
+}
+
+// ListVectorIndexes implements database.VectorIndexStore
+func (r *Router) ListVectorIndexes(ctx context.Context, tenantID string) ([]database.VectorIndex, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListVectorIndexes(ctx, tenantID)
+}
+
+// GetVectorIndex implements database.VectorIndexStore
+func (r *Router) GetVectorIndex(ctx context.Context, tenantID, collection string) (*database.VectorIndex, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.GetVectorIndex(ctx, tenantID, collection)
+}
+
+// PutVectorIndex implements database.VectorIndexStore; the index is built in the
+// tenant's regional database, next to its documents
+func (r *Router) PutVectorIndex(ctx context.Context, tenantID, collection string, spec database.VectorIndexSpec) (*database.VectorIndex, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.PutVectorIndex(ctx, tenantID, collection, spec)
+}
+
+// RebuildVectorIndex implements database.VectorIndexStore
+func (r *Router) RebuildVectorIndex(ctx context.Context, tenantID, collection string) (*database.VectorIndex, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.RebuildVectorIndex(ctx, tenantID, collection)
+}
+
+// DropVectorIndex implements database.VectorIndexStore
+func (r *Router) DropVectorIndex(ctx context.Context, tenantID, collection string) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.DropVectorIndex(ctx, tenantID, collection)
+}
+
+// ListCollections implements database.CollectionStore
+func (r *Router) ListCollections(ctx context.Context, tenantID string) ([]storage.Collection, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListCollections(ctx, tenantID)
+}
+
+// GetCollection implements database.CollectionStore
+func (r *Router) GetCollection(ctx context.Context, tenantID, name string) (*storage.Collection, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.GetCollection(ctx, tenantID, name)
+}
+
+// PutCollection implements database.CollectionStore; collections are registered in the
+// tenant's regional database, where their documents are checked against them
+func (r *Router) PutCollection(ctx context.Context, tenantID string, collection storage.Collection) (*storage.Collection, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.PutCollection(ctx, tenantID, collection)
+}
+
+// DeleteCollection implements database.CollectionStore
+func (r *Router) DeleteCollection(ctx context.Context, tenantID, name string) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.DeleteCollection(ctx, tenantID, name)
+}
+
+// RelayDocumentEvents implements database.OutboxStore
+func (r *Router) RelayDocumentEvents(ctx context.Context, tenantID string, limit int, publish func([]database.DocumentEvent) error) (int, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return backend.RelayDocumentEvents(ctx, tenantID, limit, func(events []database.DocumentEvent) error {
+		for _, event := range events {
+			if err := checkTenant(tenantID, event.TenantID); err != nil {
+				return err
+			}
+		}
+		return publish(events)
+	})
+}
+
+// PurgeDocumentEvents implements database.OutboxStore
+func (r *Router) PurgeDocumentEvents(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return backend.PurgeDocumentEvents(ctx, tenantID, before)
+}
+
+// FindDuplicates implements database.DuplicateStore
+func (r *Router) FindDuplicates(ctx context.Context, tenantID string, scan database.DuplicateScan) ([]database.DuplicatePair, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.FindDuplicates(ctx, tenantID, scan)
+}
+
+// ArchiveDuplicates implements database.DuplicateStore
+func (r *Router) ArchiveDuplicates(ctx context.Context, tenantID, keepID string, duplicateIDs []string, action string) error {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return backend.ArchiveDuplicates(ctx, tenantID, keepID, duplicateIDs, action)
+}
+
+// RestoreDocuments implements database.DuplicateStore
+func (r *Router) RestoreDocuments(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return backend.RestoreDocuments(ctx, tenantID, ids)
+}
+
+// TenantQuotaUsage implements database.QuotaStore
+func (r *Router) TenantQuotaUsage(ctx context.Context, tenantID string) (*storage.QuotaUsage, error) {
+	backend, err := r.Backend(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := backend.TenantQuotaUsage(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenant(tenantID, usage.TenantID); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// checkTenant guards against a backend returning another tenant's data
+func checkTenant(expected, actual string) error {
+	if actual != expected {
+		return fmt.Errorf("%w: expected tenant %s, got %s", ErrResidencyViolation, expected, actual)
+	}
+	return nil
+}
+
+// checkDocuments verifies that every document belongs to the tenant
+func checkDocuments(tenantID string, documents []*storage.Document) ([]*storage.Document, error) {
+	for _, doc := range documents {
+		if err := checkTenant(tenantID, doc.TenantID); err != nil {
+			return nil, err
+		}
+	}
+	return documents, nil
+}
+
+// checkResults verifies that every search result belongs to the tenant
+func checkResults(tenantID string, results []storage.HybridSearchResult) ([]storage.HybridSearchResult, error) {
+	for _, result := range results {
+		if err := checkTenant(tenantID, result.Document.TenantID); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,164 @@
+package residency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend serves documents for a single region; unused Backend methods panic
+type fakeBackend struct {
+	Backend
+	region   string
+	tenantID string // overrides the tenant of returned documents when set
+	inserted []*storage.Document
+	tenants  []onboarding.Tenant
+}
+
+func (f *fakeBackend) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
+	return f.document(tenantID, docID), nil
+}
+
+func (f *fakeBackend) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	return []*storage.Document{f.document(tenantID, "doc-1")}, nil
+}
+
+func (f *fakeBackend) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	return []storage.HybridSearchResult{{Document: *f.document(tenantID, "doc-1")}}, nil
+}
+
+func (f *fakeBackend) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	return []storage.ScoredDocument{{Document: f.document(tenantID, "doc-2")}}, nil
+}
+
+func (f *fakeBackend) InsertDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
+	f.inserted = append(f.inserted, doc)
+	return nil
+}
+
+func (f *fakeBackend) CreateTenant(ctx context.Context, tenant *onboarding.Tenant) error {
+	if tenant.ID == "" {
+		tenant.ID = "tenant-new"
+	}
+	f.tenants = append(f.tenants, *tenant)
+	return nil
+}
+
+func (f *fakeBackend) document(tenantID, docID string) *storage.Document {
+	if f.tenantID != "" {
+		tenantID = f.tenantID
+	}
+	return &storage.Document{ID: docID, TenantID: tenantID, Title: f.region}
+}
+
+// countingResolver resolves regions from a map and counts lookups
+type countingResolver struct {
+	regions map[string]string
+	calls   int
+}
+
+func (c *countingResolver) TenantRegion(ctx context.Context, tenantID string) (string, error) {
+	c.calls++
+	region, ok := c.regions[tenantID]
+	if !ok {
+		return "", errors.New("tenant not found or inactive")
+	}
+	return region, nil
+}
+
+func setupRouter() (*Router, *countingResolver, map[string]*fakeBackend) {
+	resolver := &countingResolver{regions: map[string]string{
+		"tenant-eu": "eu-west",
+		"tenant-us": "us-east",
+		"tenant-ap": "ap-south",
+	}}
+	fakes := map[string]*fakeBackend{
+		"eu-west": {region: "eu-west"},
+		"us-east": {region: "us-east"},
+	}
+	backends := map[string]Backend{
+		"eu-west": fakes["eu-west"],
+		"us-east": fakes["us-east"],
+	}
+	return NewRouter(resolver, backends, Config{}), resolver, fakes
+}
+
+func TestRouter_RoutesByTenantRegion(t *testing.T) {
+	router, resolver, fakes := setupRouter()
+	ctx := context.Background()
+
+	doc, err := router.GetDocument(ctx, "tenant-eu", "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west", doc.Title)
+
+	docs, err := router.SearchDocuments(ctx, "tenant-us", "", "policy", 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east", docs[0].Title)
+
+	require.NoError(t, router.InsertDocument(ctx, "tenant-eu", &storage.Document{Title: "new"}))
+	assert.Len(t, fakes["eu-west"].inserted, 1)
+	assert.Empty(t, fakes["us-east"].inserted)
+
+	// Region lookups are cached per tenant
+	_, err = router.GetDocument(ctx, "tenant-eu", "doc-2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, resolver.calls)
+}
+
+func TestRouter_UnconfiguredRegionFailsClosed(t *testing.T) {
+	router, _, _ := setupRouter()
+
+	_, err := router.SearchDocuments(context.Background(), "tenant-ap", "", "policy", 10, nil)
+	assert.ErrorIs(t, err, ErrRegionUnavailable)
+
+	_, err = router.SearchDocuments(context.Background(), "tenant-unknown", "", "policy", 10, nil)
+	assert.Error(t, err)
+}
+
+func TestRouter_RejectsForeignTenantData(t *testing.T) {
+	router, _, fakes := setupRouter()
+	fakes["eu-west"].tenantID = "tenant-us"
+	ctx := context.Background()
+
+	_, err := router.GetDocument(ctx, "tenant-eu", "doc-1")
+	assert.ErrorIs(t, err, ErrResidencyViolation)
+
+	_, err = router.SearchDocuments(ctx, "tenant-eu", "", "policy", 10, nil)
+	assert.ErrorIs(t, err, ErrResidencyViolation)
+
+	_, err = router.HybridSearch(ctx, "tenant-eu", storage.HybridSearchParams{Query: "policy"})
+	assert.ErrorIs(t, err, ErrResidencyViolation)
+
+	_, err = router.SimilarDocuments(ctx, "tenant-eu", "doc-1", 10, nil)
+	assert.ErrorIs(t, err, ErrResidencyViolation)
+}
+
+func TestRouter_CreateTenantInRegion(t *testing.T) {
+	control := &fakeBackend{region: database.DefaultRegion}
+	eu := &fakeBackend{region: "eu-west"}
+	router := NewRouter(&countingResolver{}, map[string]Backend{database.DefaultRegion: control, "eu-west": eu}, Config{})
+	ctx := context.Background()
+
+	// The control plane assigns the ID and the region's database gets the same tenant
+	tenant := onboarding.Tenant{Name: "delta-co", DataRegion: "eu-west"}
+	require.NoError(t, router.CreateTenant(ctx, &tenant))
+	assert.Equal(t, "tenant-new", tenant.ID)
+	require.Len(t, control.tenants, 1)
+	require.Len(t, eu.tenants, 1)
+	assert.Equal(t, "tenant-new", eu.tenants[0].ID)
+
+	// Tenants of the default region are created once
+	require.NoError(t, router.CreateTenant(ctx, &onboarding.Tenant{Name: "echo"}))
+	assert.Len(t, control.tenants, 2)
+	assert.Len(t, eu.tenants, 1)
+
+	err := router.CreateTenant(ctx, &onboarding.Tenant{Name: "foxtrot", DataRegion: "ap-south"})
+	assert.ErrorIs(t, err, ErrRegionUnavailable)
+	assert.Len(t, control.tenants, 2)
+}
@@ -0,0 +1,323 @@
+// Package resilience guards calls to dependencies such as Postgres and Redis with
+// circuit breakers, so an outage fails requests fast instead of tying them up in
+// connection timeouts, and the dependency gets room to recover.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+// Breaker states. A closed breaker lets calls through and tracks their failure rate; an
+// open one rejects calls until OpenTimeout passes; a half-open one lets a few probe calls
+// through and closes once they succeed.
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// String returns the state name used in logs, metrics and errors
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// windowBuckets is the number of buckets the failure rate window is split into
+const windowBuckets = 10
+
+// Config holds circuit breaker configuration
+type Config struct {
+	// Enabled guards dependency calls with a breaker
+	Enabled bool `yaml:"enabled"`
+	// FailureRate opens the breaker once this share of calls in Window failed (0 to 1)
+	FailureRate float64 `yaml:"failure_rate"`
+	// MinRequests is the number of calls in Window needed before the failure rate counts
+	MinRequests int `yaml:"min_requests"`
+	// Window is the rolling period the failure rate is computed over
+	Window time.Duration `yaml:"window"`
+	// OpenTimeout is how long the breaker rejects calls before probing the dependency
+	OpenTimeout time.Duration `yaml:"open_timeout"`
+	// HalfOpenRequests is the number of probe calls that must succeed to close the breaker
+	HalfOpenRequests int `yaml:"half_open_requests"`
+}
+
+// DefaultConfig returns the built-in breaker configuration
+func DefaultConfig() Config {
+	return Config{
+		Enabled:          true,
+		FailureRate:      0.5,
+		MinRequests:      20,
+		Window:           30 * time.Second,
+		OpenTimeout:      15 * time.Second,
+		HalfOpenRequests: 3,
+	}
+}
+
+// Validate checks the breaker configuration
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.FailureRate <= 0 || c.FailureRate > 1 {
+		return fmt.Errorf("failure_rate must be in (0, 1], got %g", c.FailureRate)
+	}
+	if c.MinRequests < 1 {
+		return fmt.Errorf("min_requests must be positive, got %d", c.MinRequests)
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("window must be positive, got %s", c.Window)
+	}
+	if c.OpenTimeout <= 0 {
+		return fmt.Errorf("open_timeout must be positive, got %s", c.OpenTimeout)
+	}
+	if c.HalfOpenRequests < 1 {
+		return fmt.Errorf("half_open_requests must be positive, got %d", c.HalfOpenRequests)
+	}
+	return nil
+}
+
+// OpenError is returned for a call rejected because the dependency's breaker is open
+type OpenError struct {
+	Dependency string
+	State      State
+	// RetryAfter is how long until the breaker lets probe calls through
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s unavailable: circuit breaker %s, retry in %gs", e.Dependency, e.State, e.retryAfterSeconds())
+}
+
+// Data returns structured details for the JSON-RPC error response
+func (e *OpenError) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"dependency":      e.Dependency,
+		"circuit_breaker": e.State.String(),
+		"retry_after":     e.retryAfterSeconds(),
+	}
+}
+
+// retryAfterSeconds rounds RetryAfter up to whole seconds
+func (e *OpenError) retryAfterSeconds() float64 {
+	return math.Ceil(e.RetryAfter.Seconds())
+}
+
+// Recorder counts breaker state transitions and rejected calls
+type Recorder interface {
+	RecordBreakerState(ctx context.Context, name string, from, to string, state int64)
+	RecordBreakerRejected(ctx context.Context, name string)
+}
+
+type bucket struct {
+	slot      int64
+	successes int
+	failures  int
+}
+
+// Breaker is a circuit breaker guarding one dependency
+type Breaker struct {
+	name      string
+	config    Config
+	isFailure func(error) bool
+	recorder  Recorder
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	buckets  [windowBuckets]bucket
+	openedAt time.Time
+	// probes counts probe calls let through, and successes those that succeeded, while half-open
+	probes    int
+	successes int
+	// generation changes with every transition so results of calls let through in an
+	// earlier state are ignored
+	generation uint64
+}
+
+// NewBreaker creates a closed breaker for the named dependency. isFailure decides which
+// call errors count against the dependency; nil counts every error but cancellation.
+func NewBreaker(name string, cfg Config, isFailure func(error) bool) *Breaker {
+	if isFailure == nil {
+		isFailure = IsFailure
+	}
+	return &Breaker{name: name, config: cfg, isFailure: isFailure, now: time.Now}
+}
+
+// IsFailure counts every error except the caller cancelling the call
+func IsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// SetRecorder records state transitions and rejected calls
+func (b *Breaker) SetRecorder(recorder Recorder) {
+	b.recorder = recorder
+	if recorder != nil {
+		recorder.RecordBreakerState(context.Background(), b.name, "", b.State().String(), int64(b.State()))
+	}
+}
+
+// Name returns the dependency the breaker guards
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state, moving an open breaker whose timeout passed to half-open
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.config.OpenTimeout)) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Do calls fn unless the breaker is open, in which case it returns an *OpenError right
+// away, and counts fn's error against the dependency. A nil breaker always calls fn.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b == nil {
+		return fn(ctx)
+	}
+	generation, err := b.allow(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	b.record(ctx, generation, err)
+	return err
+}
+
+// allow reports whether a call may go ahead, returning the generation to record its result under
+func (b *Breaker) allow(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	now := b.now()
+	if b.state == StateOpen {
+		if reopen := b.openedAt.Add(b.config.OpenTimeout); now.Before(reopen) {
+			b.mu.Unlock()
+			return 0, b.reject(ctx, StateOpen, reopen.Sub(now))
+		}
+		b.transition(ctx, StateHalfOpen)
+	}
+	if b.state == StateHalfOpen {
+		if b.probes >= b.config.HalfOpenRequests {
+			b.mu.Unlock()
+			// Probes are in flight; their results decide the state shortly
+			return 0, b.reject(ctx, StateHalfOpen, time.Second)
+		}
+		b.probes++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	return generation, nil
+}
+
+// record counts the result of a call let through under generation
+func (b *Breaker) record(ctx context.Context, generation uint64, err error) {
+	failed := b.isFailure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		if failed {
+			b.transition(ctx, StateOpen)
+			return
+		}
+		if err != nil {
+			// A cancelled probe tells nothing about the dependency; free its slot
+			b.probes--
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			b.transition(ctx, StateClosed)
+		}
+	case StateClosed:
+		slot := b.now().UnixNano() / max(int64(b.config.Window/windowBuckets), 1)
+		current := &b.buckets[slot%windowBuckets]
+		if current.slot != slot {
+			*current = bucket{slot: slot}
+		}
+		if failed {
+			current.failures++
+		} else {
+			current.successes++
+		}
+
+		var total, failures int
+		for _, bucket := range b.buckets {
+			if slot-bucket.slot < windowBuckets {
+				total += bucket.successes + bucket.failures
+				failures += bucket.failures
+			}
+		}
+		if total >= b.config.MinRequests && float64(failures) >= b.config.FailureRate*float64(total) {
+			b.transition(ctx, StateOpen)
+		}
+	}
+}
+
+// transition moves the breaker to state and starts a new generation. It is called with b.mu held.
+func (b *Breaker) transition(ctx context.Context, state State) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.probes, b.successes = 0, 0
+	switch state {
+	case StateOpen:
+		b.openedAt = b.now()
+	case StateClosed:
+		b.buckets = [windowBuckets]bucket{}
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("circuit_breaker.name", b.name),
+		attribute.String("circuit_breaker.from", from.String()),
+		attribute.String("circuit_breaker.to", state.String()),
+	}
+	trace.SpanFromContext(ctx).AddEvent("circuit_breaker.state_change", trace.WithAttributes(attrs...))
+	if state == StateOpen {
+		slog.WarnContext(ctx, "Circuit breaker opened", "dependency", b.name, "from", from.String(), "retry_in", b.config.OpenTimeout)
+	} else {
+		slog.InfoContext(ctx, "Circuit breaker state changed", "dependency", b.name, "from", from.String(), "to", state.String())
+	}
+	if b.recorder != nil {
+		b.recorder.RecordBreakerState(ctx, b.name, from.String(), state.String(), int64(state))
+	}
+}
+
+// reject returns the error for a call the breaker does not let through
+func (b *Breaker) reject(ctx context.Context, state State, retryAfter time.Duration) error {
+	trace.SpanFromContext(ctx).AddEvent("circuit_breaker.rejected", trace.WithAttributes(
+		attribute.String("circuit_breaker.name", b.name),
+		attribute.String("circuit_breaker.state", state.String()),
+	))
+	if b.recorder != nil {
+		b.recorder.RecordBreakerRejected(ctx, b.name)
+	}
+	return &OpenError{Dependency: b.name, State: state, RetryAfter: retryAfter}
+}
@@ -0,0 +1,135 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDown = errors.New("connection refused")
+
+// transitions records the states a breaker moves through
+type transitions []string
+
+func (t *transitions) RecordBreakerState(ctx context.Context, name, from, to string, state int64) {
+	*t = append(*t, to)
+}
+
+func (t *transitions) RecordBreakerRejected(ctx context.Context, name string) {}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker("postgres", Config{
+		Enabled:          true,
+		FailureRate:      0.5,
+		MinRequests:      4,
+		Window:           10 * time.Second,
+		OpenTimeout:      5 * time.Second,
+		HalfOpenRequests: 2,
+	}, nil)
+	breaker.now = func() time.Time { return now }
+	var recorded transitions
+	breaker.SetRecorder(&recorded)
+
+	call := func(err error) error {
+		return breaker.Do(context.Background(), func(ctx context.Context) error { return err })
+	}
+
+	// Failures below MinRequests, and cancellations, do not open the breaker
+	assert.ErrorIs(t, call(errDown), errDown)
+	assert.ErrorIs(t, call(errDown), errDown)
+	assert.ErrorIs(t, call(context.Canceled), context.Canceled)
+	assert.Equal(t, StateClosed, breaker.State())
+
+	// Failures older than the window no longer count
+	now = now.Add(11 * time.Second)
+	assert.NoError(t, call(nil))
+	assert.ErrorIs(t, call(errDown), errDown)
+	assert.NoError(t, call(nil))
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.ErrorIs(t, call(errDown), errDown)
+	assert.Equal(t, StateOpen, breaker.State(), "2 failures out of 4 calls open the breaker")
+
+	// An open breaker fails fast without calling the dependency
+	called := false
+	err := breaker.Do(context.Background(), func(ctx context.Context) error { called = true; return nil })
+	var open *OpenError
+	require.ErrorAs(t, err, &open)
+	assert.False(t, called)
+	assert.Equal(t, StateOpen, open.State)
+	assert.Equal(t, 5*time.Second, open.RetryAfter)
+	assert.Equal(t, map[string]interface{}{"dependency": "postgres", "circuit_breaker": "open", "retry_after": 5.0}, open.Data())
+
+	// After OpenTimeout a failing probe opens the breaker again
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	assert.ErrorIs(t, call(errDown), errDown)
+	require.ErrorAs(t, call(nil), &open)
+
+	// Probes beyond HalfOpenRequests wait for the ones in flight; successful probes close it
+	now = now.Add(5 * time.Second)
+	release := make(chan struct{})
+	done := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- breaker.Do(context.Background(), func(ctx context.Context) error { <-release; return nil })
+		}()
+	}
+	require.Eventually(t, func() bool {
+		breaker.mu.Lock()
+		defer breaker.mu.Unlock()
+		return breaker.probes == 2
+	}, time.Second, time.Millisecond)
+	require.ErrorAs(t, call(nil), &open)
+	assert.Equal(t, StateHalfOpen, open.State)
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+	assert.Equal(t, StateClosed, breaker.State())
+
+	assert.Equal(t, transitions{"closed", "open", "half_open", "open", "half_open", "closed"}, recorded)
+}
+
+func TestRedisHook(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer client.Close()
+	breaker := NewBreaker("redis", Config{
+		Enabled:          true,
+		FailureRate:      0.4,
+		MinRequests:      2,
+		Window:           time.Minute,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	}, IsRedisFailure)
+	client.AddHook(RedisHook(breaker))
+	ctx := context.Background()
+
+	// Redis answering, even with a missing key or an error reply, is not a failure
+	assert.ErrorIs(t, client.Get(ctx, "missing").Err(), redis.Nil)
+	require.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+	assert.Error(t, client.Incr(ctx, "key").Err())
+	assert.Equal(t, StateClosed, breaker.State())
+
+	// 2 failures out of 5 calls open the breaker
+	mr.Close()
+	for i := 0; i < 2; i++ {
+		assert.Error(t, client.Ping(ctx).Err())
+	}
+	assert.Equal(t, StateOpen, breaker.State())
+
+	var open *OpenError
+	require.ErrorAs(t, client.Get(ctx, "key").Err(), &open)
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, "counter")
+		return nil
+	})
+	require.ErrorAs(t, err, &open)
+	assert.Equal(t, "redis unavailable: circuit breaker open, retry in 60s", open.Error())
+}
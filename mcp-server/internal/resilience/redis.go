@@ -0,0 +1,63 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook returns a go-redis hook that runs every command and pipeline through
+// breaker. Add it with client.AddHook.
+func RedisHook(breaker *Breaker) redis.Hook {
+	return redisHook{breaker: breaker}
+}
+
+// IsRedisFailure counts errors reaching Redis, such as dial errors, timeouts and an
+// exhausted pool. A missing key or an error reply means Redis answered.
+func IsRedisFailure(err error) bool {
+	if !IsFailure(err) || errors.Is(err, redis.Nil) {
+		return false
+	}
+	var reply redis.Error
+	return !errors.As(err, &reply)
+}
+
+type redisHook struct {
+	breaker *Breaker
+}
+
+func (h redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := h.breaker.Do(ctx, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+		var open *OpenError
+		if errors.As(err, &open) {
+			cmd.SetErr(err)
+		}
+		return err
+	}
+}
+
+func (h redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := h.breaker.Do(ctx, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+		var open *OpenError
+		if errors.As(err, &open) {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,227 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+)
+
+// URI schemes served by the document providers
+const (
+	SummaryScheme  = "documents-summary"
+	DocumentScheme = "documents"
+)
+
+// DocumentURIs returns the resource URIs whose contents change with a document: the
+// document itself, its summary and the summary listing
+func DocumentURIs(docID string) []string {
+	return []string{
+		DocumentScheme + "://" + docID,
+		SummaryScheme + "://" + docID,
+		SummaryScheme + "://",
+	}
+}
+
+// Summary listing page sizes
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// DocumentSummary is the token-efficient view of a document returned by documents-summary://
+type DocumentSummary struct {
+	ID            string                 `json:"id"`
+	URI           string                 `json:"uri"` // documents:// URI with the full content
+	Title         string                 `json:"title"`
+	Summary       string                 `json:"summary"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	ContentLength int                    `json:"content_length"` // characters in the full content
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// SummaryPage is one page of a documents-summary:// listing
+type SummaryPage struct {
+	Documents []DocumentSummary `json:"documents"`
+	Next      string            `json:"next,omitempty"` // URI of the next page, if any
+}
+
+// SummaryProvider serves documents-summary:// resources. Summaries are generated from
+// the document content on every read, so they never go stale.
+//
+//	documents-summary://                     first page of the tenant's documents, newest first
+//	documents-summary://?limit=20&offset=40  a later page
+//	documents-summary://{id}                 a single document summary
+type SummaryProvider struct {
+	db            storage.Store
+	summaryLength int
+}
+
+// NewSummaryProvider creates a documents-summary:// provider
+func NewSummaryProvider(db storage.Store) *SummaryProvider {
+	return &SummaryProvider{db: db, summaryLength: DefaultSummaryLength}
+}
+
+// Scheme implements Provider
+func (p *SummaryProvider) Scheme() string {
+	return SummaryScheme
+}
+
+// List implements Provider
+func (p *SummaryProvider) List(ctx context.Context) ([]protocol.Resource, error) {
+	return []protocol.Resource{{
+		URI:  SummaryScheme + "://",
+		Name: "Document summaries",
+		Description: "Paginated listing of document titles, short summaries and metadata. " +
+			"Use limit and offset query parameters to page; read each document's uri for the full content.",
+		MimeType: "application/json",
+	}}, nil
+}
+
+// Read implements Provider
+func (p *SummaryProvider) Read(ctx context.Context, uri *url.URL) ([]protocol.ResourceContents, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if docID := uri.Host; docID != "" {
+		doc, err := p.db.GetDocument(ctx, tenantID, docID)
+		if err != nil {
+			return nil, documentError(err, uri)
+		}
+		return jsonContents(uri, p.summarize(doc))
+	}
+
+	limit, offset, err := pageParams(uri.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra document to learn whether there is a next page
+	docs, err := p.db.ListDocuments(ctx, tenantID, "", limit+1, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	page := SummaryPage{Documents: make([]DocumentSummary, 0, limit)}
+	if len(docs) > limit {
+		docs = docs[:limit]
+		page.Next = fmt.Sprintf("%s://?limit=%d&offset=%d", SummaryScheme, limit, offset+limit)
+	}
+	for _, doc := range docs {
+		page.Documents = append(page.Documents, p.summarize(doc))
+	}
+	return jsonContents(uri, page)
+}
+
+// summarize converts a document to its summary view
+func (p *SummaryProvider) summarize(doc *storage.Document) DocumentSummary {
+	return DocumentSummary{
+		ID:            doc.ID,
+		URI:           DocumentScheme + "://" + doc.ID,
+		Title:         doc.Title,
+		Summary:       Summarize(doc.Content, p.summaryLength),
+		Metadata:      doc.Metadata,
+		ContentLength: len([]rune(doc.Content)),
+		UpdatedAt:     doc.UpdatedAt,
+	}
+}
+
+// DocumentProvider serves the full content of a document at documents://{id}
+type DocumentProvider struct {
+	recorder tools.AccessRecorder
+	db       storage.Store
+}
+
+// NewDocumentProvider creates a documents:// provider
+func NewDocumentProvider(db storage.Store) *DocumentProvider {
+	return &DocumentProvider{db: db}
+}
+
+// SetAccessRecorder sets the recorder used to log document reads
+func (p *DocumentProvider) SetAccessRecorder(recorder tools.AccessRecorder) {
+	p.recorder = recorder
+}
+
+// Scheme implements Provider
+func (p *DocumentProvider) Scheme() string {
+	return DocumentScheme
+}
+
+// List implements Provider. Documents are discovered through documents-summary://,
+// so none are listed individually.
+func (p *DocumentProvider) List(ctx context.Context) ([]protocol.Resource, error) {
+	return nil, nil
+}
+
+// Read implements Provider
+func (p *DocumentProvider) Read(ctx context.Context, uri *url.URL) ([]protocol.ResourceContents, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uri.Host == "" {
+		return nil, fmt.Errorf("%w: %s requires a document ID", ErrInvalidURI, uri)
+	}
+
+	doc, err := p.db.GetDocument(ctx, tenantID, uri.Host)
+	if err != nil {
+		return nil, documentError(err, uri)
+	}
+
+	if p.recorder != nil {
+		p.recorder.RecordAccess(ctx, protocol.MethodResourcesRead, doc.ID)
+	}
+
+	doc.Embedding = nil
+	return jsonContents(uri, doc)
+}
+
+// pageParams parses the limit and offset query parameters of a listing URI
+func pageParams(query url.Values) (limit, offset int, err error) {
+	limit, offset = defaultPageSize, 0
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("%w: invalid limit %q", ErrInvalidURI, v)
+		}
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("%w: invalid offset %q", ErrInvalidURI, v)
+		}
+	}
+	return limit, offset, nil
+}
+
+// documentError maps a missing document to ErrNotFound
+func documentError(err error, uri *url.URL) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, uri)
+	}
+	return fmt.Errorf("failed to get document: %w", err)
+}
+
+// jsonContents encodes v as the JSON contents of a resource
+func jsonContents(uri *url.URL, v interface{}) ([]protocol.ResourceContents, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	return []protocol.ResourceContents{{
+		URI:      uri.String(),
+		MimeType: "application/json",
+		Text:     string(data),
+	}}, nil
+}
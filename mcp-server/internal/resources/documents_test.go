@@ -0,0 +1,201 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedAccess struct {
+	tool   string
+	docIDs []string
+}
+
+type fakeRecorder struct {
+	accesses []recordedAccess
+}
+
+func (f *fakeRecorder) RecordAccess(ctx context.Context, tool string, documentIDs ...string) {
+	f.accesses = append(f.accesses, recordedAccess{tool: tool, docIDs: documentIDs})
+}
+
+func newTestRegistry(t *testing.T, docs int) (*Registry, *storage.MemoryStore, []string) {
+	t.Helper()
+
+	store := storage.NewMemoryStore()
+	ids := make([]string, 0, docs)
+	for i := 0; i < docs; i++ {
+		doc := &storage.Document{
+			Title:     fmt.Sprintf("Document %d", i),
+			Content:   fmt.Sprintf("Document %d opens with a summary sentence. ", i) + strings.Repeat("Body text follows. ", 40),
+			Metadata:  map[string]interface{}{"index": float64(i)},
+			Embedding: []float32{0.1, 0.2},
+		}
+		require.NoError(t, store.InsertDocument(context.Background(), "tenant-1", doc))
+		ids = append(ids, doc.ID)
+	}
+
+	registry := NewRegistry()
+	registry.Register(NewSummaryProvider(store))
+	registry.Register(NewDocumentProvider(store))
+	return registry, store, ids
+}
+
+func tenantContext(tenantID string) context.Context {
+	return context.WithValue(context.Background(), auth.ContextKeyTenantID, tenantID)
+}
+
+func decodeContents(t *testing.T, contents []protocol.ResourceContents, v interface{}) {
+	t.Helper()
+	require.Len(t, contents, 1)
+	assert.Equal(t, "application/json", contents[0].MimeType)
+	require.NoError(t, json.Unmarshal([]byte(contents[0].Text), v))
+}
+
+func TestRegistry_List(t *testing.T) {
+	registry, _, _ := newTestRegistry(t, 0)
+
+	listed, err := registry.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "documents-summary://", listed[0].URI)
+}
+
+func TestRegistry_ReadErrors(t *testing.T) {
+	registry, _, _ := newTestRegistry(t, 1)
+	ctx := tenantContext("tenant-1")
+
+	_, err := registry.Read(ctx, "unknown://thing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = registry.Read(ctx, "no-scheme")
+	assert.ErrorIs(t, err, ErrInvalidURI)
+
+	_, err = registry.Read(ctx, "documents-summary://?limit=abc")
+	assert.ErrorIs(t, err, ErrInvalidURI)
+
+	_, err = registry.Read(ctx, "documents-summary://?offset=-1")
+	assert.ErrorIs(t, err, ErrInvalidURI)
+
+	_, err = registry.Read(ctx, "documents://")
+	assert.ErrorIs(t, err, ErrInvalidURI)
+
+	_, err = registry.Read(context.Background(), "documents-summary://")
+	assert.Error(t, err)
+}
+
+func TestRegistry_SubscriptionURI(t *testing.T) {
+	registry, _, _ := newTestRegistry(t, 0)
+
+	for uri, want := range map[string]string{
+		"documents://doc-1":                     "documents://doc-1",
+		"documents-summary://":                  "documents-summary://",
+		"documents-summary://?limit=5&offset=5": "documents-summary://",
+		"documents-summary://doc-1#title":       "documents-summary://doc-1",
+	} {
+		got, err := registry.SubscriptionURI(uri)
+		require.NoError(t, err, uri)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := registry.SubscriptionURI("unknown://thing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = registry.SubscriptionURI("no-scheme")
+	assert.ErrorIs(t, err, ErrInvalidURI)
+
+	assert.Equal(t, []string{"documents://doc-1", "documents-summary://doc-1", "documents-summary://"}, DocumentURIs("doc-1"))
+}
+
+func TestSummaryProvider_Pagination(t *testing.T) {
+	registry, _, ids := newTestRegistry(t, 5)
+	ctx := tenantContext("tenant-1")
+
+	seen := map[string]bool{}
+	uri := "documents-summary://?limit=2"
+	pages := 0
+	for uri != "" {
+		contents, err := registry.Read(ctx, uri)
+		require.NoError(t, err)
+
+		var page SummaryPage
+		decodeContents(t, contents, &page)
+		assert.LessOrEqual(t, len(page.Documents), 2)
+		for _, doc := range page.Documents {
+			seen[doc.ID] = true
+			assert.Equal(t, "documents://"+doc.ID, doc.URI)
+			assert.LessOrEqual(t, len([]rune(doc.Summary)), DefaultSummaryLength)
+			assert.Less(t, len(doc.Summary), doc.ContentLength)
+			assert.Contains(t, doc.Metadata, "index")
+		}
+		uri = page.Next
+		pages++
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Len(t, seen, len(ids))
+}
+
+func TestSummaryProvider_OtherTenantIsEmpty(t *testing.T) {
+	registry, _, _ := newTestRegistry(t, 3)
+
+	contents, err := registry.Read(tenantContext("tenant-2"), "documents-summary://")
+	require.NoError(t, err)
+
+	var page SummaryPage
+	decodeContents(t, contents, &page)
+	assert.Empty(t, page.Documents)
+	assert.Empty(t, page.Next)
+}
+
+func TestSummaryProvider_SingleDocument(t *testing.T) {
+	registry, _, ids := newTestRegistry(t, 2)
+	ctx := tenantContext("tenant-1")
+
+	contents, err := registry.Read(ctx, "documents-summary://"+ids[0])
+	require.NoError(t, err)
+
+	var summary DocumentSummary
+	decodeContents(t, contents, &summary)
+	assert.Equal(t, ids[0], summary.ID)
+	assert.Equal(t, "Document 0", summary.Title)
+	assert.True(t, strings.HasPrefix(summary.Summary, "Document 0 opens with a summary sentence."))
+
+	_, err = registry.Read(ctx, "documents-summary://missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = registry.Read(tenantContext("tenant-2"), "documents-summary://"+ids[0])
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDocumentProvider_Read(t *testing.T) {
+	registry, store, ids := newTestRegistry(t, 1)
+	recorder := &fakeRecorder{}
+	registry.SetAccessRecorder(recorder)
+	ctx := tenantContext("tenant-1")
+
+	contents, err := registry.Read(ctx, "documents://"+ids[0])
+	require.NoError(t, err)
+
+	var doc storage.Document
+	decodeContents(t, contents, &doc)
+	original, err := store.GetDocument(ctx, "tenant-1", ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, original.Content, doc.Content)
+	assert.Empty(t, doc.Embedding)
+
+	require.Len(t, recorder.accesses, 1)
+	assert.Equal(t, protocol.MethodResourcesRead, recorder.accesses[0].tool)
+	assert.Equal(t, []string{ids[0]}, recorder.accesses[0].docIDs)
+
+	_, err = registry.Read(ctx, "documents://missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, recorder.accesses, 1)
+}
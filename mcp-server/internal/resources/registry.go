@@ -0,0 +1,102 @@
+// Package resources implements MCP resources: read-only, URI-addressed content
+// that clients can browse with resources/list and fetch with resources/read.
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+)
+
+// Resource read errors
+var (
+	ErrNotFound   = errors.New("resource not found")
+	ErrInvalidURI = errors.New("invalid resource URI")
+)
+
+// Provider serves the resources under one URI scheme
+type Provider interface {
+	// Scheme returns the URI scheme handled by the provider, e.g. "documents-summary"
+	Scheme() string
+	// List returns the resources advertised by resources/list
+	List(ctx context.Context) ([]protocol.Resource, error)
+	// Read returns the contents of a resource
+	Read(ctx context.Context, uri *url.URL) ([]protocol.ResourceContents, error)
+}
+
+// Registry routes resource URIs to providers by scheme
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates a new resource registry
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+	}
+}
+
+// Register registers a provider for its scheme
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Scheme()] = provider
+}
+
+// List returns the resources advertised by every provider, ordered by scheme
+func (r *Registry) List(ctx context.Context) ([]protocol.Resource, error) {
+	schemes := make([]string, 0, len(r.providers))
+	for scheme := range r.providers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	resources := []protocol.Resource{}
+	for _, scheme := range schemes {
+		listed, err := r.providers[scheme].List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s resources: %w", scheme, err)
+		}
+		resources = append(resources, listed...)
+	}
+	return resources, nil
+}
+
+// Read returns the contents of the resource at uri
+func (r *Registry) Read(ctx context.Context, uri string) ([]protocol.ResourceContents, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidURI, uri)
+	}
+
+	provider, ok := r.providers[parsed.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrNotFound, parsed.Scheme)
+	}
+	return provider.Read(ctx, parsed)
+}
+
+// SubscriptionURI returns the URI a subscription to uri is kept under: the query, such as
+// paging parameters, is dropped so every page of a listing shares one subscription
+func (r *Registry) SubscriptionURI(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidURI, uri)
+	}
+	if _, ok := r.providers[parsed.Scheme]; !ok {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrNotFound, parsed.Scheme)
+	}
+	return parsed.Scheme + "://" + parsed.Host + parsed.Path, nil
+}
+
+// SetAccessRecorder attaches a document access recorder to every provider that returns document content
+func (r *Registry) SetAccessRecorder(recorder tools.AccessRecorder) {
+	for _, provider := range r.providers {
+		if setter, ok := provider.(tools.AccessRecorderSetter); ok {
+			setter.SetAccessRecorder(recorder)
+		}
+	}
+}
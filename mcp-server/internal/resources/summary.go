@@ -0,0 +1,72 @@
+package resources
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultSummaryLength is the summary length, in characters, used by the summary provider
+const DefaultSummaryLength = 240
+
+// Summarize builds an extractive summary of content: whole leading sentences that fit
+// in maxChars, or the first sentence cut at a word boundary when even that is too long.
+// Whitespace is collapsed so the summary is a single line.
+func Summarize(content string, maxChars int) string {
+	text := strings.Join(strings.Fields(content), " ")
+	if len([]rune(text)) <= maxChars {
+		return text
+	}
+
+	summary := ""
+	for _, sentence := range splitSentences(text) {
+		candidate := strings.TrimSpace(summary + " " + sentence)
+		if len([]rune(candidate)) > maxChars {
+			break
+		}
+		summary = candidate
+	}
+	if summary != "" {
+		return summary
+	}
+
+	return truncateWords(text, maxChars)
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by a space
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		if (r == '.' || r == '!' || r == '?') && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			sentences = append(sentences, strings.TrimSpace(string(runes[start:i+1])))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// truncateWords cuts text to at most maxChars characters, including the ellipsis,
+// without splitting a word
+func truncateWords(text string, maxChars int) string {
+	const ellipsis = "..."
+	runes := []rune(text)
+	limit := maxChars - len(ellipsis)
+	if limit <= 0 {
+		return ellipsis
+	}
+
+	cut := string(runes[:limit])
+	if !unicode.IsSpace(runes[limit]) {
+		// The cut lands inside a word; drop the partial word
+		if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
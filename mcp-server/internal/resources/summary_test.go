@@ -0,0 +1,55 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxChars int
+		want     string
+	}{
+		{
+			name:     "short content returned as is",
+			content:  "A short note.",
+			maxChars: 50,
+			want:     "A short note.",
+		},
+		{
+			name:     "whitespace collapsed",
+			content:  "Line one.\n\n  Line   two.",
+			maxChars: 50,
+			want:     "Line one. Line two.",
+		},
+		{
+			name:     "whole leading sentences that fit",
+			content:  "First sentence. Second sentence! Third sentence is much longer than the rest.",
+			maxChars: 40,
+			want:     "First sentence. Second sentence!",
+		},
+		{
+			name:     "long first sentence cut at word boundary",
+			content:  "This opening sentence keeps going well past the summary limit without a break.",
+			maxChars: 30,
+			want:     "This opening sentence keeps...",
+		},
+		{
+			name:     "empty content",
+			content:  "   ",
+			maxChars: 10,
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.content, tt.maxChars)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, len([]rune(got)), tt.maxChars)
+		})
+	}
+}
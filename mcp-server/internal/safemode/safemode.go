@@ -0,0 +1,224 @@
+// Package safemode implements a global safe mode for incident response. While it is on,
+// expensive and destructive operations (bulk imports, tool uploads and deletions,
+// re-embedding jobs, costly tools) are refused and reads stay available. The state is
+// persisted so a restart during an incident does not silently lift it.
+package safemode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State is the safe mode setting and who last changed it
+type State struct {
+	Enabled   bool      `json:"enabled"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Store persists the safe mode state
+type Store interface {
+	// Load returns the saved state, or the zero State if none was saved
+	Load(ctx context.Context) (State, error)
+	// Save replaces the saved state
+	Save(ctx context.Context, state State) error
+}
+
+// MemoryStore keeps the state in memory; it does not survive a restart
+type MemoryStore struct {
+	mu    sync.Mutex
+	state State
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(ctx context.Context) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, nil
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(ctx context.Context, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	return nil
+}
+
+// FileStore keeps the state in a JSON file, replaced atomically on every save
+type FileStore struct {
+	path string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates a store backed by the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements Store
+func (s *FileStore) Load(ctx context.Context) (State, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("failed to read safe mode state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("invalid safe mode state in %s: %w", s.path, err)
+	}
+	return state, nil
+}
+
+// Save implements Store
+func (s *FileStore) Save(ctx context.Context, state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save safe mode state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save safe mode state: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save safe mode state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save safe mode state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save safe mode state: %w", err)
+	}
+	return nil
+}
+
+// Switch is the process-wide safe mode toggle. It is safe for concurrent use.
+type Switch struct {
+	store Store
+
+	mu    sync.RWMutex
+	state State
+}
+
+// New creates a switch restored from the store's saved state
+func New(ctx context.Context, store Store) (*Switch, error) {
+	state, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Switch{store: store, state: state}, nil
+}
+
+// State returns the current state
+func (s *Switch) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// Enabled reports whether safe mode is on
+func (s *Switch) Enabled() bool {
+	return s.State().Enabled
+}
+
+// Set turns safe mode on or off. The new state is saved before it takes effect, so a
+// change that cannot be persisted is not applied.
+func (s *Switch) Set(ctx context.Context, enabled bool, reason, updatedBy string) (State, error) {
+	state := State{
+		Enabled:   enabled,
+		Reason:    reason,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.Save(ctx, state); err != nil {
+		return s.state, err
+	}
+	s.state = state
+	return state, nil
+}
+
+// Error is returned for an operation refused because safe mode is on
+type Error struct {
+	Operation string
+	Reason    string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s is disabled while safe mode is on", e.Operation)
+	}
+	return fmt.Sprintf("%s is disabled while safe mode is on: %s", e.Operation, e.Reason)
+}
+
+// Data returns structured details for the JSON-RPC error response
+func (e *Error) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"operation": e.Operation,
+		"safe_mode": true,
+		"reason":    e.Reason,
+	}
+}
+
+// Check returns an *Error for operation if safe mode is on, and nil otherwise. A nil
+// switch is never on.
+func (s *Switch) Check(operation string) error {
+	if s == nil {
+		return nil
+	}
+	state := s.State()
+	if !state.Enabled {
+		return nil
+	}
+	return &Error{Operation: operation, Reason: state.Reason}
+}
+
+// Guard refuses requests that change state with 503 Service Unavailable while safe mode
+// is on; GET, HEAD and OPTIONS requests pass through so reads stay available
+func (s *Switch) Guard(operation string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := s.Check(operation); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "300")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":     err.Error(),
+				"safe_mode": s.State(),
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
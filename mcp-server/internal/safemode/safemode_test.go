@@ -0,0 +1,94 @@
+package safemode
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwitch_PersistsAcrossRestarts(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "safe-mode.json")
+
+	s, err := New(ctx, NewFileStore(path))
+	require.NoError(t, err)
+	assert.False(t, s.Enabled(), "a missing state file starts with safe mode off")
+
+	_, err = s.Set(ctx, true, "primary database degraded", "oncall")
+	require.NoError(t, err)
+
+	restarted, err := New(ctx, NewFileStore(path))
+	require.NoError(t, err)
+	state := restarted.State()
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "primary database degraded", state.Reason)
+	assert.Equal(t, "oncall", state.UpdatedBy)
+	assert.False(t, state.UpdatedAt.IsZero())
+}
+
+func TestSwitch_SetKeepsStateWhenSaveFails(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(ctx, NewFileStore(filepath.Join(t.TempDir(), "missing", "safe-mode.json")))
+	require.NoError(t, err)
+
+	_, err = s.Set(ctx, true, "incident", "oncall")
+	assert.Error(t, err)
+	assert.False(t, s.Enabled())
+}
+
+func TestSwitch_Check(t *testing.T) {
+	var off *Switch
+	assert.NoError(t, off.Check("bulk import"), "a nil switch is never on")
+
+	s, err := New(context.Background(), NewMemoryStore())
+	require.NoError(t, err)
+	assert.NoError(t, s.Check("bulk import"))
+
+	_, err = s.Set(context.Background(), true, "incident", "oncall")
+	require.NoError(t, err)
+	var safeModeErr *Error
+	require.ErrorAs(t, s.Check("bulk import"), &safeModeErr)
+	assert.Equal(t, "bulk import", safeModeErr.Operation)
+	assert.Equal(t, "bulk import is disabled while safe mode is on: incident", safeModeErr.Error())
+}
+
+func TestSwitch_Guard(t *testing.T) {
+	s, err := New(context.Background(), NewMemoryStore())
+	require.NoError(t, err)
+	_, err = s.Set(context.Background(), true, "incident", "oncall")
+	require.NoError(t, err)
+
+	handler := s.Guard("tool uploads", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/wasm-tools", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code, "reads stay available")
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, "/admin/wasm-tools", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code, method)
+
+		var body struct {
+			Error    string `json:"error"`
+			SafeMode State  `json:"safe_mode"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		assert.True(t, body.SafeMode.Enabled)
+		assert.Contains(t, body.Error, "tool uploads")
+	}
+
+	_, err = s.Set(context.Background(), false, "", "oncall")
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/wasm-tools", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
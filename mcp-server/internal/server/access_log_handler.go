@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// AdminScope is the scope required for administrative endpoints
+const AdminScope = auth.ScopeAdmin
+
+// AccessLogHandler serves "who viewed what" reports from the document access log
+type AccessLogHandler struct {
+	store database.AccessLogStore
+}
+
+// NewAccessLogHandler creates a new access log report handler
+func NewAccessLogHandler(store database.AccessLogStore) *AccessLogHandler {
+	return &AccessLogHandler{store: store}
+}
+
+// AccessLogReport is the response body for access log queries
+type AccessLogReport struct {
+	DocumentID string                    `json:"document_id,omitempty"`
+	UserID     string                    `json:"user_id,omitempty"`
+	Limit      int                       `json:"limit"`
+	Offset     int                       `json:"offset"`
+	Entries    []database.DocumentAccess `json:"entries"`
+}
+
+// ServeHTTP handles GET /admin/access-log?document_id=... or ?user_id=...
+func (h *AccessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	report := AccessLogReport{
+		DocumentID: query.Get("document_id"),
+		UserID:     query.Get("user_id"),
+		Limit:      parseIntParam(query.Get("limit"), 100),
+		Offset:     parseIntParam(query.Get("offset"), 0),
+	}
+	if report.Limit <= 0 || report.Limit > 1000 {
+		report.Limit = 100
+	}
+	if report.Offset < 0 {
+		report.Offset = 0
+	}
+
+	switch {
+	case report.DocumentID != "" && report.UserID != "":
+		http.Error(w, "Specify either document_id or user_id, not both", http.StatusBadRequest)
+		return
+	case report.DocumentID != "":
+		report.Entries, err = h.store.ListAccessByDocument(ctx, tenantID, report.DocumentID, report.Limit, report.Offset)
+	case report.UserID != "":
+		report.Entries, err = h.store.ListAccessByUser(ctx, tenantID, report.UserID, report.Limit, report.Offset)
+	default:
+		http.Error(w, "document_id or user_id is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to query access log", http.StatusInternalServerError)
+		return
+	}
+	if report.Entries == nil {
+		report.Entries = []database.DocumentAccess{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// parseIntParam parses an integer query parameter, falling back to a default
+func parseIntParam(value string, defaultValue int) int {
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
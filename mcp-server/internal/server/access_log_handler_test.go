@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAccessLogStore implements database.AccessLogStore for testing
+type MockAccessLogStore struct {
+	mock.Mock
+}
+
+func (m *MockAccessLogStore) RecordDocumentAccess(ctx context.Context, tenantID string, entries []database.DocumentAccess) error {
+	args := m.Called(ctx, tenantID, entries)
+	return args.Error(0)
+}
+
+func (m *MockAccessLogStore) ListAccessByDocument(ctx context.Context, tenantID, docID string, limit, offset int) ([]database.DocumentAccess, error) {
+	args := m.Called(ctx, tenantID, docID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DocumentAccess), args.Error(1)
+}
+
+func (m *MockAccessLogStore) ListAccessByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]database.DocumentAccess, error) {
+	args := m.Called(ctx, tenantID, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DocumentAccess), args.Error(1)
+}
+
+func adminRequest(target string, scopes ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	ctx := auth.WithAuth(req.Context(), &auth.Claims{
+		TenantID: "tenant-123",
+		UserID:   "auditor",
+		Scopes:   scopes,
+	})
+	return req.WithContext(ctx)
+}
+
+func TestAccessLogHandler_ByDocument(t *testing.T) {
+	store := new(MockAccessLogStore)
+	store.On("ListAccessByDocument", mock.Anything, "tenant-123", "doc-1", 100, 0).
+		Return([]database.DocumentAccess{
+			{TenantID: "tenant-123", DocumentID: "doc-1", UserID: "user-1", Tool: "retrieve_document", AccessedAt: time.Now()},
+		}, nil)
+
+	rr := httptest.NewRecorder()
+	NewAccessLogHandler(store).ServeHTTP(rr, adminRequest("/admin/access-log?document_id=doc-1", AdminScope))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report AccessLogReport
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	assert.Equal(t, "doc-1", report.DocumentID)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "user-1", report.Entries[0].UserID)
+	store.AssertExpectations(t)
+}
+
+func TestAccessLogHandler_ByUser(t *testing.T) {
+	store := new(MockAccessLogStore)
+	store.On("ListAccessByUser", mock.Anything, "tenant-123", "user-1", 10, 20).
+		Return(nil, nil)
+
+	rr := httptest.NewRecorder()
+	NewAccessLogHandler(store).ServeHTTP(rr, adminRequest("/admin/access-log?user_id=user-1&limit=10&offset=20", AdminScope))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"entries":[]`)
+	store.AssertExpectations(t)
+}
+
+func TestAccessLogHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"unauthenticated", httptest.NewRequest(http.MethodGet, "/admin/access-log?user_id=u", nil), http.StatusUnauthorized},
+		{"missing admin scope", adminRequest("/admin/access-log?user_id=u", "read"), http.StatusForbidden},
+		{"missing filter", adminRequest("/admin/access-log", AdminScope), http.StatusBadRequest},
+		{"both filters", adminRequest("/admin/access-log?user_id=u&document_id=d", AdminScope), http.StatusBadRequest},
+		{"wrong method", httptest.NewRequest(http.MethodPost, "/admin/access-log", nil), http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			NewAccessLogHandler(new(MockAccessLogStore)).ServeHTTP(rr, tt.req)
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
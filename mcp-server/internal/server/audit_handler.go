@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+)
+
+// AuditHandler serves queries over the calling tenant's tool call audit log
+type AuditHandler struct {
+	store database.AuditLogStore
+}
+
+// NewAuditHandler creates a new audit log query handler
+func NewAuditHandler(store database.AuditLogStore) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// AuditReport is the response body for audit log queries
+type AuditReport struct {
+	UserID  string                `json:"user_id,omitempty"`
+	Tool    string                `json:"tool,omitempty"`
+	Status  string                `json:"status,omitempty"`
+	Since   *time.Time            `json:"since,omitempty"`
+	Until   *time.Time            `json:"until,omitempty"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+	Entries []database.AuditEntry `json:"entries"`
+}
+
+// ServeHTTP handles GET /admin/audit with optional user_id, tool, status, since and
+// until (RFC 3339) filters, paginated with limit and offset
+func (h *AuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := database.AuditFilter{
+		UserID: query.Get("user_id"),
+		Tool:   query.Get("tool"),
+		Status: query.Get("status"),
+		Limit:  parseIntParam(query.Get("limit"), 100),
+		Offset: parseIntParam(query.Get("offset"), 0),
+	}
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid "+param+": expected an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		*target = parsed
+	}
+
+	entries, err := h.store.ListAuditEntries(ctx, tenantID, filter)
+	if err != nil {
+		http.Error(w, "Failed to query audit log", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []database.AuditEntry{}
+	}
+
+	report := AuditReport{
+		UserID:  filter.UserID,
+		Tool:    filter.Tool,
+		Status:  filter.Status,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		Entries: entries,
+	}
+	if !filter.Since.IsZero() {
+		report.Since = &filter.Since
+	}
+	if !filter.Until.IsZero() {
+		report.Until = &filter.Until
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAuditLogStore implements database.AuditLogStore for testing
+type MockAuditLogStore struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogStore) RecordAuditEntries(ctx context.Context, tenantID string, entries []database.AuditEntry) error {
+	args := m.Called(ctx, tenantID, entries)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogStore) ListAuditEntries(ctx context.Context, tenantID string, filter database.AuditFilter) ([]database.AuditEntry, error) {
+	args := m.Called(ctx, tenantID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.AuditEntry), args.Error(1)
+}
+
+func (m *MockAuditLogStore) PurgeAuditEntries(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	args := m.Called(ctx, tenantID, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestAuditHandler_Filters(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	store := new(MockAuditLogStore)
+	store.On("ListAuditEntries", mock.Anything, "tenant-123", database.AuditFilter{
+		UserID: "user-1",
+		Tool:   "search_documents",
+		Status: "denied",
+		Since:  since,
+		Until:  until,
+		Limit:  10,
+		Offset: 20,
+	}).Return([]database.AuditEntry{
+		{ID: 7, TenantID: "tenant-123", UserID: "user-1", Tool: "search_documents", Status: "denied", ArgsDigest: "sha256:ab", CreatedAt: since},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+	NewAuditHandler(store).ServeHTTP(rr, adminRequest(
+		"/admin/audit?user_id=user-1&tool=search_documents&status=denied&since=2024-01-01T00:00:00Z&until=2024-02-01T00:00:00Z&limit=10&offset=20",
+		AdminScope))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report AuditReport
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	assert.Equal(t, "search_documents", report.Tool)
+	require.NotNil(t, report.Since)
+	assert.True(t, since.Equal(*report.Since))
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, int64(7), report.Entries[0].ID)
+	store.AssertExpectations(t)
+}
+
+func TestAuditHandler_Defaults(t *testing.T) {
+	store := new(MockAuditLogStore)
+	store.On("ListAuditEntries", mock.Anything, "tenant-123", database.AuditFilter{Limit: 100}).Return(nil, nil)
+
+	rr := httptest.NewRecorder()
+	NewAuditHandler(store).ServeHTTP(rr, adminRequest("/admin/audit?limit=5000", AdminScope))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"limit":100,"offset":0,"entries":[]}`, rr.Body.String())
+	store.AssertExpectations(t)
+}
+
+func TestAuditHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"unauthenticated", httptest.NewRequest(http.MethodGet, "/admin/audit", nil), http.StatusUnauthorized},
+		{"missing admin scope", adminRequest("/admin/audit", "read"), http.StatusForbidden},
+		{"invalid since", adminRequest("/admin/audit?since=yesterday", AdminScope), http.StatusBadRequest},
+		{"method not allowed", httptest.NewRequest(http.MethodPost, "/admin/audit", nil), http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			NewAuditHandler(new(MockAuditLogStore)).ServeHTTP(rr, tt.req)
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
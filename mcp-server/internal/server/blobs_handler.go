@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+)
+
+// BlobsPath is the blob endpoint, followed by a blob's content hash
+const BlobsPath = tools.BlobsPath
+
+// BlobsHandler serves the binary tool output that resource links in tool results refer to
+type BlobsHandler struct {
+	store *blobs.ContentStore
+}
+
+// NewBlobsHandler creates a new blob handler
+func NewBlobsHandler(store *blobs.ContentStore) *BlobsHandler {
+	return &BlobsHandler{store: store}
+}
+
+// ServeHTTP handles
+//
+//	GET /blobs/{hash}  the caller's tenant's blob with that SHA-256 content hash
+//
+// Blobs of other tenants are not found, like missing ones, so a hash tells a caller
+// nothing about what other tenants stored. Blobs never change, so they are cached for
+// good and revalidated by their hash. Their content type is sniffed, and they are served
+// sandboxed so an HTML or SVG blob cannot run scripts in the server's origin.
+func (h *BlobsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hash := strings.TrimPrefix(r.URL.Path, BlobsPath)
+	if !blobs.ValidHash(hash) {
+		http.Error(w, "Blob not found", http.StatusNotFound)
+		return
+	}
+
+	blob, err := h.store.Get(ctx, tenantID, hash)
+	if errors.Is(err, blobs.ErrNotFound) {
+		http.Error(w, "Blob not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, blobs.ErrRegionUnavailable) {
+		slog.ErrorContext(ctx, "No blob store for tenant's region", "tenant_id", tenantID, "error", err)
+		http.Error(w, "Blob storage unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to read blob", "hash", hash, "error", err)
+		http.Error(w, "Failed to read blob", http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	// Revalidated only once the blob is known to be the caller's
+	etag := `"` + hash + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	reader := bufio.NewReaderSize(blob, 512)
+	head, _ := reader.Peek(512)
+	w.Header().Set("Content-Type", http.DetectContentType(head))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox; default-src 'none'; img-src 'self' data:")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, reader); err != nil {
+		slog.WarnContext(ctx, "Failed to send blob", "hash", hash, "error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader starts every PNG image
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func newBlobStore(t *testing.T) *blobs.ContentStore {
+	files, err := blobs.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	return blobs.NewContentStore(files)
+}
+
+func TestBlobsHandler(t *testing.T) {
+	store := newBlobStore(t)
+	image := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 1000)...)
+	hash, err := store.Put(context.Background(), "tenant-123", "user-1", image)
+	require.NoError(t, err)
+	handler := NewBlobsHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, BlobsPath+hash, nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminRequest(BlobsPath+hash))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, image, rr.Body.Bytes())
+	assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	assert.Contains(t, rr.Header().Get("Content-Security-Policy"), "sandbox")
+	etag := rr.Header().Get("ETag")
+	assert.Equal(t, `"`+hash+`"`, etag)
+
+	req := adminRequest(BlobsPath + hash)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.Bytes())
+
+	// Other tenants' blobs are not found, even when revalidating
+	req = adminRequest(BlobsPath + hash)
+	req = req.WithContext(auth.WithAuth(req.Context(), &auth.Claims{TenantID: "tenant-456", UserID: "user-2"}))
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	for _, path := range []string{BlobsPath + blobs.Hash([]byte("missing")), BlobsPath + "../secrets", BlobsPath} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, adminRequest(path))
+		assert.Equal(t, http.StatusNotFound, rr.Code, path)
+	}
+}
+
+// imageTool returns an image of the given size
+type imageTool struct {
+	size int
+}
+
+func (t *imageTool) Definition() protocol.Tool {
+	return protocol.Tool{Name: "render_chart"}
+}
+
+func (t *imageTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	image := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{1}, t.size)...)
+	return protocol.ToolCallResult{Content: []protocol.ContentBlock{tools.ImageContent(image, "image/png")}}, nil
+}
+
+func TestMCPHandler_ToolsCall_OffloadsImages(t *testing.T) {
+	store := newBlobStore(t)
+	registry := tools.NewRegistry()
+	registry.Register(&imageTool{size: 1 << 20})
+	handler := NewMCPHandler(registry, nil)
+	handler.SetBlobs(tools.NewBlobs(store, "https://mcp.example.com", 0))
+
+	callReq, err := protocol.NewRequest("1", protocol.MethodToolsCall, protocol.ToolCallRequest{Name: "render_chart"})
+	require.NoError(t, err)
+	_, response := serveMCP(t, handler, callReq, "tenant-123")
+	require.Nil(t, response.Error)
+
+	data, err := json.Marshal(response.Result)
+	require.NoError(t, err)
+	var result protocol.ToolCallResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.Content, 1)
+	link := result.Content[0]
+	assert.Equal(t, protocol.ContentTypeResourceLink, link.Type)
+	assert.Empty(t, link.Data)
+	assert.Equal(t, int64(len(pngHeader)+1<<20), link.Size)
+
+	// The link is served by the blob endpoint
+	rr := httptest.NewRecorder()
+	NewBlobsHandler(store).ServeHTTP(rr, adminRequest(link.URI[len("https://mcp.example.com"):]))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int(link.Size), rr.Body.Len())
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// CollectionsPath is the admin endpoint prefix for tenant document collections
+const CollectionsPath = "/admin/collections"
+
+// CollectionsHandler manages the calling tenant's collections
+type CollectionsHandler struct {
+	store database.CollectionStore
+}
+
+// NewCollectionsHandler creates a new collection admin handler
+func NewCollectionsHandler(store database.CollectionStore) *CollectionsHandler {
+	return &CollectionsHandler{store: store}
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/collections         list collections with their document counts
+//	GET    /admin/collections/{name}  get a collection
+//	PUT    /admin/collections/{name}  register a collection or update its settings
+//	DELETE /admin/collections/{name}  unregister an empty collection
+func (h *CollectionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, CollectionsPath), "/")
+	if name == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		collections, err := h.store.ListCollections(ctx, tenantID)
+		if err != nil {
+			http.Error(w, "Failed to list collections", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"collections": collections})
+		return
+	}
+
+	if err := storage.ValidateCollection(name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		collection, err := h.store.GetCollection(ctx, tenantID, name)
+		if !writeCollectionError(w, r, err, "Failed to load collection") {
+			return
+		}
+		writeJSON(w, http.StatusOK, collection)
+	case http.MethodPut:
+		var collection storage.Collection
+		if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if collection.Name != "" && collection.Name != name {
+			http.Error(w, "Collection name does not match the URL", http.StatusBadRequest)
+			return
+		}
+		collection.Name = name
+		if err := collection.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		saved, err := h.store.PutCollection(ctx, tenantID, collection)
+		if !writeCollectionError(w, r, err, "Failed to save collection") {
+			return
+		}
+		writeJSON(w, http.StatusOK, saved)
+	case http.MethodDelete:
+		if !writeCollectionError(w, r, h.store.DeleteCollection(ctx, tenantID, name), "Failed to delete collection") {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeCollectionError writes the response for a failed store call and reports whether the call succeeded
+func writeCollectionError(w http.ResponseWriter, r *http.Request, err error, message string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Collection not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrCollectionNotEmpty), errors.Is(err, storage.ErrEmbeddingDimensions):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		slog.ErrorContext(r.Context(), message, "error", err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCollectionStore keeps registered collections in memory next to document counts
+type fakeCollectionStore struct {
+	collections map[string]storage.Collection
+	documents   map[string]int64
+}
+
+func (f *fakeCollectionStore) ListCollections(ctx context.Context, tenantID string) ([]storage.Collection, error) {
+	list := []storage.Collection{}
+	for name := range f.documents {
+		if _, ok := f.collections[name]; !ok {
+			list = append(list, storage.Collection{Name: name, Documents: f.documents[name]})
+		}
+	}
+	for _, collection := range f.collections {
+		list = append(list, collection)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (f *fakeCollectionStore) GetCollection(ctx context.Context, tenantID, name string) (*storage.Collection, error) {
+	if collection, ok := f.collections[name]; ok {
+		return &collection, nil
+	}
+	if documents, ok := f.documents[name]; ok {
+		return &storage.Collection{Name: name, Documents: documents}, nil
+	}
+	return nil, fmt.Errorf("%w: collection %q", storage.ErrNotFound, name)
+}
+
+func (f *fakeCollectionStore) PutCollection(ctx context.Context, tenantID string, collection storage.Collection) (*storage.Collection, error) {
+	if collection.EmbeddingDimensions == 768 && f.documents[collection.Name] > 0 {
+		return nil, fmt.Errorf("%w: documents have embeddings of another size", storage.ErrEmbeddingDimensions)
+	}
+	collection.Registered = true
+	collection.Documents = f.documents[collection.Name]
+	f.collections[collection.Name] = collection
+	return &collection, nil
+}
+
+func (f *fakeCollectionStore) DeleteCollection(ctx context.Context, tenantID, name string) error {
+	if f.documents[name] > 0 {
+		return fmt.Errorf("%w: collection %q holds %d documents", storage.ErrCollectionNotEmpty, name, f.documents[name])
+	}
+	if _, ok := f.collections[name]; !ok {
+		return fmt.Errorf("%w: collection %q", storage.ErrNotFound, name)
+	}
+	delete(f.collections, name)
+	return nil
+}
+
+func TestCollectionsHandler(t *testing.T) {
+	store := &fakeCollectionStore{
+		collections: make(map[string]storage.Collection),
+		documents:   map[string]int64{"default": 3},
+	}
+	handler := NewCollectionsHandler(store)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, body, AdminScope))
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/admin/collections/tickets",
+		`{"description":"Support tickets","embedding_model":"nomic-embed-text","embedding_dimensions":768}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var collection storage.Collection
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &collection))
+	assert.Equal(t, "tickets", collection.Name)
+	assert.True(t, collection.Registered)
+	assert.Equal(t, 768, collection.EmbeddingDimensions)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/admin/collections/tickets", `{"name":"other"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/admin/collections/tickets", `{"embedding_dimensions":-5}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/admin/collections/Tickets", "").Code)
+	rec = serve(http.MethodPut, "/admin/collections/default", `{"embedding_dimensions":768}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "embedding dimensions do not match the collection")
+
+	rec = serve(http.MethodGet, "/admin/collections", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Collections []storage.Collection `json:"collections"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Collections, 2)
+	assert.Equal(t, "default", list.Collections[0].Name)
+	assert.False(t, list.Collections[0].Registered)
+	assert.Equal(t, int64(3), list.Collections[0].Documents)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/admin/collections/default", "").Code)
+	assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, "/admin/collections/default", "").Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/admin/collections/tickets", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/admin/collections/tickets", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/admin/collections/tickets", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/admin/collections", `{}`).Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, profileRequest(http.MethodGet, "/admin/collections", "", "read"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
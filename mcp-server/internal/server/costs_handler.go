@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/cost"
+)
+
+// CostsPath is the cost reporting endpoint
+const CostsPath = "/admin/costs"
+
+// defaultCostPeriod is the period reported when since is not given
+const defaultCostPeriod = 30 * 24 * time.Hour
+
+// CostReporter reports a tenant's estimated embedding and LLM cost
+type CostReporter interface {
+	Report(tenantID string, since, until time.Time) cost.Report
+}
+
+// CostsHandler reports the estimated cost of the embedding and LLM calls made for the
+// calling tenant, by tool, model and day
+type CostsHandler struct {
+	reporter CostReporter
+}
+
+// NewCostsHandler creates a new cost reporting handler
+func NewCostsHandler(reporter CostReporter) *CostsHandler {
+	return &CostsHandler{reporter: reporter}
+}
+
+// ServeHTTP handles GET /admin/costs with optional since and until (RFC 3339) bounds,
+// defaulting to the last 30 days. Costs are totalled per UTC day, so the days the bounds
+// fall on are reported in full.
+func (h *CostsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	var since, until time.Time
+	query := r.URL.Query()
+	for param, target := range map[string]*time.Time{"since": &since, "until": &until} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid "+param+": expected an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		*target = parsed
+	}
+	if until.IsZero() {
+		until = time.Now()
+	}
+	if since.IsZero() {
+		since = until.Add(-defaultCostPeriod)
+	}
+	if since.After(until) {
+		http.Error(w, "since must not be after until", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.reporter.Report(tenantID, since, until))
+}
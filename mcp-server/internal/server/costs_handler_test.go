@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/cost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostsHandler(t *testing.T) {
+	tracker := cost.NewTracker(cost.DefaultPriceTable(), 0)
+	ctx := context.Background()
+	tracker.Record(ctx, cost.Usage{TenantID: "tenant-123", Tool: "answer_question", Operation: cost.OperationCompletion, Model: "gpt-4o-mini", InputTokens: 1000, OutputTokens: 100})
+	tracker.Record(ctx, cost.Usage{TenantID: "tenant-123", Tool: "hybrid_search", Operation: cost.OperationEmbedding, Model: "text-embedding-3-small", InputTokens: 50})
+	tracker.Record(ctx, cost.Usage{TenantID: "other-tenant", Tool: "answer_question", Operation: cost.OperationCompletion, Model: "gpt-4o", InputTokens: 1000})
+	handler := NewCostsHandler(tracker)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminRequest(CostsPath, AdminScope))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var report cost.Report
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	assert.Equal(t, "tenant-123", report.TenantID, "only the caller's tenant is reported")
+	assert.Equal(t, 2, report.Total.Calls)
+	assert.InDelta(t, 0.00021+0.000001, report.Total.CostUSD, 1e-9)
+	require.Len(t, report.ByTool, 2)
+	assert.Equal(t, "answer_question", report.ByTool[0].Key)
+	assert.Len(t, report.ByModel, 2)
+	assert.Len(t, report.ByDay, 1)
+	assert.WithinDuration(t, report.Until.Add(-30*24*time.Hour), report.Since, time.Second)
+
+	rr = httptest.NewRecorder()
+	since := time.Now().Add(48 * time.Hour).Format(time.RFC3339)
+	handler.ServeHTTP(rr, adminRequest(CostsPath+"?since="+since+"&until="+since, AdminScope))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	assert.Zero(t, report.Total.Calls, "days outside the period are left out")
+}
+
+func TestCostsHandler_Errors(t *testing.T) {
+	handler := NewCostsHandler(cost.NewTracker(cost.DefaultPriceTable(), 0))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, CostsPath, nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminRequest(CostsPath))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminRequest(CostsPath+"?since=yesterday", AdminScope))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminRequest(CostsPath+"?since=2024-02-01T00:00:00Z&until=2024-01-01T00:00:00Z", AdminScope))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	req := adminRequest(CostsPath, AdminScope)
+	req.Method = http.MethodPost
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/dedup"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// DuplicatesPath is the admin endpoint prefix for duplicate document review
+const DuplicatesPath = "/admin/duplicates"
+
+// DuplicatesHandler reports the calling tenant's duplicate documents and resolves them
+type DuplicatesHandler struct {
+	detector *dedup.Detector
+}
+
+// NewDuplicatesHandler creates a new duplicate review handler
+func NewDuplicatesHandler(detector *dedup.Detector) *DuplicatesHandler {
+	return &DuplicatesHandler{detector: detector}
+}
+
+// ResolveDuplicatesRequest is the request body for resolving duplicates
+type ResolveDuplicatesRequest struct {
+	CanonicalID  string   `json:"canonical_id"`
+	DuplicateIDs []string `json:"duplicate_ids"`
+	Action       string   `json:"action"`
+}
+
+// RestoreDocumentsRequest is the request body for restoring resolved duplicates
+type RestoreDocumentsRequest struct {
+	DocumentIDs []string `json:"document_ids"`
+}
+
+// ServeHTTP handles
+//
+//	GET  /admin/duplicates[?refresh=true]  latest duplicate report, scanning if there is none
+//	POST /admin/duplicates/resolve         merge or soft delete duplicates of a document
+//	POST /admin/duplicates/restore         restore resolved duplicates
+func (h *DuplicatesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, DuplicatesPath), "/")
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		report := h.detector.Report(tenantID)
+		if report == nil || r.URL.Query().Get("refresh") == "true" {
+			if report, err = h.detector.Scan(ctx, tenantID); err != nil {
+				slog.ErrorContext(ctx, "Failed to scan for duplicates", "error", err)
+				http.Error(w, "Failed to scan for duplicates", http.StatusInternalServerError)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, report)
+	case action == "resolve" && r.Method == http.MethodPost:
+		var req ResolveDuplicatesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.CanonicalID == "" || len(req.DuplicateIDs) == 0 {
+			http.Error(w, "canonical_id and duplicate_ids are required", http.StatusBadRequest)
+			return
+		}
+		if err := dedup.ValidateAction(req.Action); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err := h.detector.Resolve(ctx, tenantID, req.CanonicalID, req.DuplicateIDs, req.Action)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "Documents not found in the canonical document's collection", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to resolve duplicates", "error", err)
+			http.Error(w, "Failed to resolve duplicates", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(ctx, "Duplicates resolved",
+			"tenant_id", tenantID, "canonical_id", req.CanonicalID, "duplicates", len(req.DuplicateIDs), "action", req.Action)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"canonical_id": req.CanonicalID,
+			"archived":     req.DuplicateIDs,
+			"action":       req.Action,
+		})
+	case action == "restore" && r.Method == http.MethodPost:
+		var req RestoreDocumentsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if len(req.DocumentIDs) == 0 {
+			http.Error(w, "document_ids is required", http.StatusBadRequest)
+			return
+		}
+		restored, err := h.detector.Restore(ctx, tenantID, req.DocumentIDs)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to restore documents", "error", err)
+			http.Error(w, "Failed to restore documents", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(ctx, "Documents restored", "tenant_id", tenantID, "documents", len(restored))
+		writeJSON(w, http.StatusOK, map[string]interface{}{"restored": restored})
+	case action == "" || action == "resolve" || action == "restore":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
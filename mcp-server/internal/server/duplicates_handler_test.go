@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/dedup"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDuplicateStore pairs two documents of a collection and archives them in memory
+type fakeDuplicateStore struct {
+	documents map[string]bool
+	archived  map[string]bool
+	scans     int
+}
+
+func (f *fakeDuplicateStore) ListActiveTenants(ctx context.Context) ([]string, error) {
+	return []string{"tenant-123"}, nil
+}
+
+func (f *fakeDuplicateStore) FindDuplicates(ctx context.Context, tenantID string, scan database.DuplicateScan) ([]database.DuplicatePair, error) {
+	f.scans++
+	if !f.documents["doc-2"] {
+		return nil, nil
+	}
+	now := time.Now()
+	return []database.DuplicatePair{{
+		First:      database.DuplicateDocument{ID: "doc-1", Collection: "policies", Title: "Password Policy", CreatedAt: now.Add(-time.Hour)},
+		Second:     database.DuplicateDocument{ID: "doc-2", Collection: "policies", Title: "Password policy (copy)", CreatedAt: now},
+		Similarity: 0.98,
+	}}, nil
+}
+
+func (f *fakeDuplicateStore) ArchiveDuplicates(ctx context.Context, tenantID, keepID string, duplicateIDs []string, action string) error {
+	for _, id := range append([]string{keepID}, duplicateIDs...) {
+		if !f.documents[id] {
+			return fmt.Errorf("%w: document %s", storage.ErrNotFound, id)
+		}
+	}
+	for _, id := range duplicateIDs {
+		delete(f.documents, id)
+		f.archived[id] = true
+	}
+	return nil
+}
+
+func (f *fakeDuplicateStore) RestoreDocuments(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	restored := []string{}
+	for _, id := range ids {
+		if f.archived[id] {
+			delete(f.archived, id)
+			f.documents[id] = true
+			restored = append(restored, id)
+		}
+	}
+	return restored, nil
+}
+
+func TestDuplicatesHandler(t *testing.T) {
+	store := &fakeDuplicateStore{documents: map[string]bool{"doc-1": true, "doc-2": true}, archived: map[string]bool{}}
+	handler := NewDuplicatesHandler(dedup.NewDetector(store, store, nil, dedup.DefaultConfig()))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, body, AdminScope))
+		return rec
+	}
+
+	// The first review scans, later ones reuse the report until refreshed
+	rec := serve(http.MethodGet, "/admin/duplicates", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var report dedup.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "tenant-123", report.TenantID)
+	assert.Equal(t, 1, report.Duplicates)
+	require.Len(t, report.Clusters, 1)
+	assert.Equal(t, "doc-1", report.Clusters[0].Canonical.ID)
+	assert.Equal(t, []string{"doc-2"}, report.Clusters[0].DuplicateIDs())
+
+	serve(http.MethodGet, "/admin/duplicates", "")
+	assert.Equal(t, 1, store.scans)
+	serve(http.MethodGet, "/admin/duplicates?refresh=true", "")
+	assert.Equal(t, 2, store.scans)
+
+	rec = serve(http.MethodPost, "/admin/duplicates/resolve", `{"canonical_id":"doc-1","duplicate_ids":["doc-2"],"action":"delete"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(http.MethodPost, "/admin/duplicates/resolve", `{"canonical_id":"doc-1","duplicate_ids":["doc-9"],"action":"merge"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodPost, "/admin/duplicates/resolve", `{"canonical_id":"doc-1","duplicate_ids":["doc-2"],"action":"merge"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.True(t, store.archived["doc-2"])
+
+	rec = serve(http.MethodGet, "/admin/duplicates", "")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Zero(t, report.Duplicates, "resolving rescans on the next review")
+
+	rec = serve(http.MethodPost, "/admin/duplicates/restore", `{"document_ids":["doc-2","doc-3"]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"restored":["doc-2"]}`, rec.Body.String())
+	assert.True(t, store.documents["doc-2"])
+
+	rec = serve(http.MethodPost, "/admin/duplicates/restore", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(http.MethodDelete, "/admin/duplicates", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	rec = serve(http.MethodPost, "/admin/duplicates/other", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestDuplicatesHandler_RequiresAdmin(t *testing.T) {
+	store := &fakeDuplicateStore{documents: map[string]bool{}, archived: map[string]bool{}}
+	handler := NewDuplicatesHandler(dedup.NewDetector(store, store, nil, dedup.DefaultConfig()))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, profileRequest(http.MethodGet, "/admin/duplicates", ""))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Zero(t, store.scans)
+}
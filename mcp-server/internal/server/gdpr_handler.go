@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/gdpr"
+)
+
+// GDPRHandler serves data subject export and erasure requests
+type GDPRHandler struct {
+	service *gdpr.Service
+}
+
+// NewGDPRHandler creates a new GDPR handler
+func NewGDPRHandler(service *gdpr.Service) *GDPRHandler {
+	return &GDPRHandler{service: service}
+}
+
+// EraseRequest is the request body for an erasure
+type EraseRequest struct {
+	UserID string    `json:"user_id"`
+	Mode   gdpr.Mode `json:"mode"`
+}
+
+// HandleExport handles GET /admin/gdpr/export?user_id=... and returns a zip archive
+func (h *GDPRHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	// Build the archive in memory so a failing source still yields a clean error response
+	var buf bytes.Buffer
+	subject := gdpr.Subject{TenantID: tenantID, UserID: userID}
+	if _, err := h.service.WriteArchive(r.Context(), subject, &buf); err != nil {
+		http.Error(w, "Failed to export user data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "user-data-"+userID+".zip"))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+// HandleErase handles POST /admin/gdpr/erase and returns a verification report
+func (h *GDPRHandler) HandleErase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	var req EraseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = gdpr.ModeDelete
+	}
+	if !req.Mode.Valid() {
+		http.Error(w, "mode must be delete or anonymize", http.StatusBadRequest)
+		return
+	}
+
+	subject := gdpr.Subject{TenantID: tenantID, UserID: req.UserID}
+	report, err := h.service.Erase(r.Context(), subject, req.Mode)
+	if err != nil {
+		http.Error(w, "Failed to erase user data", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Verified {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// authorize checks for an authenticated admin and returns the tenant ID
+func (h *GDPRHandler) authorize(w http.ResponseWriter, r *http.Request) (string, bool) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return "", false
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return "", false
+	}
+	return tenantID, true
+}
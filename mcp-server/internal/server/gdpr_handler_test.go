@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/gdpr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGDPRSource reports a fixed number of records and how many remain after erasure
+type stubGDPRSource struct {
+	records   int
+	remaining int
+	erased    []gdpr.Subject
+}
+
+func (s *stubGDPRSource) Name() string { return "stub" }
+
+func (s *stubGDPRSource) Export(ctx context.Context, subject gdpr.Subject) (*gdpr.SourceExport, error) {
+	if len(s.erased) > 0 {
+		return &gdpr.SourceExport{Records: s.remaining}, nil
+	}
+	return &gdpr.SourceExport{Records: s.records, Data: map[string]string{"user_id": subject.UserID}}, nil
+}
+
+func (s *stubGDPRSource) Erase(ctx context.Context, subject gdpr.Subject, mode gdpr.Mode, pseudonym string) (*gdpr.SourceErasure, error) {
+	s.erased = append(s.erased, subject)
+	return &gdpr.SourceErasure{Records: s.records}, nil
+}
+
+func TestGDPRHandler_Export(t *testing.T) {
+	handler := NewGDPRHandler(gdpr.NewService(&stubGDPRSource{records: 3}))
+
+	rr := httptest.NewRecorder()
+	handler.HandleExport(rr, adminRequest("/admin/gdpr/export?user_id=user-1", AdminScope))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Header().Get("Content-Disposition"), "user-data-user-1.zip")
+	assert.True(t, strings.HasPrefix(rr.Body.String(), "PK"), "body should be a zip archive")
+}
+
+func TestGDPRHandler_Erase(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		remaining      int
+		expectedStatus int
+	}{
+		{"delete", `{"user_id":"user-1","mode":"delete"}`, 0, http.StatusOK},
+		{"default mode", `{"user_id":"user-1"}`, 0, http.StatusOK},
+		{"verification failure", `{"user_id":"user-1","mode":"anonymize"}`, 1, http.StatusInternalServerError},
+		{"missing user", `{"mode":"delete"}`, 0, http.StatusBadRequest},
+		{"invalid mode", `{"user_id":"user-1","mode":"shred"}`, 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &stubGDPRSource{records: 2, remaining: tt.remaining}
+			handler := NewGDPRHandler(gdpr.NewService(source))
+
+			req := adminRequest("/admin/gdpr/erase", AdminScope)
+			req.Method = http.MethodPost
+			req.Body = io.NopCloser(strings.NewReader(tt.body))
+
+			rr := httptest.NewRecorder()
+			handler.HandleErase(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				assert.Empty(t, source.erased)
+				return
+			}
+
+			var report gdpr.ErasureReport
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+			assert.Equal(t, tt.remaining == 0, report.Verified)
+			assert.Equal(t, "tenant-123", report.Subject.TenantID)
+			require.Len(t, source.erased, 1)
+			assert.Equal(t, "user-1", source.erased[0].UserID)
+		})
+	}
+}
+
+func TestGDPRHandler_RequiresAdminScope(t *testing.T) {
+	handler := NewGDPRHandler(gdpr.NewService(&stubGDPRSource{}))
+
+	rr := httptest.NewRecorder()
+	handler.HandleExport(rr, adminRequest("/admin/gdpr/export?user_id=user-1", "read"))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.HandleExport(rr, httptest.NewRequest(http.MethodGet, "/admin/gdpr/export?user_id=user-1", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
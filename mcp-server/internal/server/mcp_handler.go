@@ -3,14 +3,27 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/audit"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/deprecation"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/observability"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/redaction"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resilience"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resources"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/sessions"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/lifecycle"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -24,8 +37,21 @@ const (
 
 // MCPHandler handles MCP JSON-RPC requests
 type MCPHandler struct {
-	toolRegistry *tools.Registry
-	telemetry    *observability.Telemetry
+	toolRegistry     *tools.Registry
+	resourceRegistry *resources.Registry
+	telemetry        *observability.Telemetry
+	auditor          ToolCallAuditor
+	deprecations     *deprecation.Registry
+	sessions         *sessions.Hub
+	lifecycle        *lifecycle.Manager
+	redactor         *redaction.Redactor
+	blobs            *tools.Blobs
+	truncator        *tools.Truncator
+}
+
+// ToolCallAuditor records the outcome of every tools/call
+type ToolCallAuditor interface {
+	RecordToolCall(ctx context.Context, tool string, args map[string]interface{}, status string, latency time.Duration)
 }
 
 // NewMCPHandler creates a new MCP handler
@@ -36,11 +62,60 @@ func NewMCPHandler(toolRegistry *tools.Registry, telemetry *observability.Teleme
 	}
 }
 
+// SetResourceRegistry enables resources/list and resources/read
+func (h *MCPHandler) SetResourceRegistry(registry *resources.Registry) {
+	h.resourceRegistry = registry
+}
+
+// SetAuditor records every tools/call in the audit log
+func (h *MCPHandler) SetAuditor(auditor ToolCallAuditor) {
+	h.auditor = auditor
+}
+
+// SetDeprecations warns callers of deprecated methods and tools
+func (h *MCPHandler) SetDeprecations(registry *deprecation.Registry) {
+	h.deprecations = registry
+}
+
+// SetSessions enables sessions: initialize returns an Mcp-Session-Id, GET opens the
+// session's notification stream, and resources/subscribe and tool list changes are
+// delivered over it
+func (h *MCPHandler) SetSessions(hub *sessions.Hub) {
+	h.sessions = hub
+}
+
+// SetLifecycle ends notification streams when the server starts draining
+func (h *MCPHandler) SetLifecycle(manager *lifecycle.Manager) {
+	h.lifecycle = manager
+}
+
+// SetRedactor masks PII in the text of tool results when the tenant's policy redacts
+// tool output
+func (h *MCPHandler) SetRedactor(redactor *redaction.Redactor) {
+	h.redactor = redactor
+}
+
+// SetBlobs moves large images out of tool results into blob storage, linking to them
+func (h *MCPHandler) SetBlobs(store *tools.Blobs) {
+	h.blobs = store
+}
+
+// SetTruncator limits the size of tool results, keeping the rest for read_continuation
+func (h *MCPHandler) SetTruncator(truncator *tools.Truncator) {
+	h.truncator = truncator
+}
+
 // ServeHTTP implements http.Handler
 func (h *MCPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	startTime := time.Now()
 
+	// GET opens a session's notification stream and DELETE ends the session
+	if h.sessions != nil && (r.Method == http.MethodGet || r.Method == http.MethodDelete) {
+		h.serveSession(w, r)
+		return
+	}
+
 	// Only accept POST requests
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -49,8 +124,15 @@ func (h *MCPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Read request body
 	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.sendResponse(ctx, w, protocol.NewErrorResponse(nil, protocol.RequestTooLarge,
+			fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit),
+			map[string]interface{}{"max_bytes": tooLarge.Limit}))
+		return
+	}
 	if err != nil {
-		h.sendErrorResponse(w, nil, protocol.ParseError, "Failed to read request body")
+		h.sendErrorResponse(ctx, w, nil, protocol.ParseError, "Failed to read request body")
 		return
 	}
 	defer r.Body.Close()
@@ -58,16 +140,25 @@ func (h *MCPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Parse JSON-RPC request
 	var req protocol.Request
 	if err := json.Unmarshal(body, &req); err != nil {
-		h.sendErrorResponse(w, nil, protocol.ParseError, "Invalid JSON")
+		h.sendErrorResponse(ctx, w, nil, protocol.ParseError, "Invalid JSON")
+		return
+	}
+
+	// Clients POST their responses to server requests, such as sampling/createMessage
+	if req.Method == "" && h.sessions != nil && h.resolveClientResponse(w, r, body) {
 		return
 	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		h.sendErrorResponse(w, req.ID, protocol.InvalidRequest, err.Error())
+		h.sendErrorResponse(ctx, w, req.ID, protocol.InvalidRequest, err.Error())
 		return
 	}
 
+	if id := r.Header.Get(sessions.Header); id != "" {
+		ctx = context.WithValue(ctx, sessionIDKey{}, id)
+	}
+
 	// Start tracing span
 	var span trace.Span
 	if h.telemetry != nil && h.telemetry.Tracer != nil {
@@ -86,6 +177,8 @@ func (h *MCPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Handle the request
 	response := h.handleRequest(ctx, &req)
+	h.warnDeprecated(ctx, w.Header(), &req, response)
+	h.startSession(ctx, w.Header(), &req, response)
 
 	// Record metrics and span status
 	duration := time.Since(startTime)
@@ -103,11 +196,11 @@ func (h *MCPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if h.telemetry != nil && h.telemetry.Metrics != nil {
-		h.telemetry.Metrics.RecordRequest(ctx, req.Method, status, float64(duration.Milliseconds()))
+		h.telemetry.Metrics.RecordRequest(ctx, req.Method, status, float64(duration.Microseconds())/1000)
 	}
 
 	// Send response
-	h.sendResponse(w, response)
+	h.sendResponse(ctx, w, response)
 }
 
 // handleRequest processes a JSON-RPC request and returns a response
@@ -119,10 +212,21 @@ func (h *MCPHandler) handleRequest(ctx context.Context, req *protocol.Request) *
 		return h.handleToolsList(ctx, req)
 	case protocol.MethodToolsCall:
 		return h.handleToolsCall(ctx, req)
-	default:
-		return protocol.NewErrorResponse(req.ID, protocol.MethodNotFound,
-			fmt.Sprintf("Method not found: %s", req.Method), nil)
+	case protocol.MethodResourcesList:
+		if h.resourceRegistry != nil {
+			return h.handleResourcesList(ctx, req)
+		}
+	case protocol.MethodResourcesRead:
+		if h.resourceRegistry != nil {
+			return h.handleResourcesRead(ctx, req)
+		}
+	case protocol.MethodResourcesSubscribe, protocol.MethodResourcesUnsubscribe:
+		if h.resourceRegistry != nil && h.sessions != nil {
+			return h.handleResourcesSubscribe(ctx, req)
+		}
 	}
+	return protocol.NewErrorResponse(req.ID, protocol.MethodNotFound,
+		fmt.Sprintf("Method not found: %s", req.Method), nil)
 }
 
 // handleInitialize handles the initialize request
@@ -137,7 +241,7 @@ func (h *MCPHandler) handleInitialize(ctx context.Context, req *protocol.Request
 		ProtocolVersion: MCPProtocolVersion,
 		Capabilities: protocol.ServerCapabilities{
 			Tools: &protocol.ToolsCapability{
-				ListChanged: false,
+				ListChanged: h.sessions != nil,
 			},
 		},
 		ServerInfo: protocol.ServerInfo{
@@ -145,13 +249,16 @@ func (h *MCPHandler) handleInitialize(ctx context.Context, req *protocol.Request
 			Version: ServerVersion,
 		},
 	}
+	if h.resourceRegistry != nil {
+		result.Capabilities.Resources = &protocol.ResourcesCapability{Subscribe: h.sessions != nil}
+	}
 
 	return protocol.NewResponse(req.ID, result)
 }
 
 // handleToolsList handles the tools/list request
 func (h *MCPHandler) handleToolsList(ctx context.Context, req *protocol.Request) *protocol.Response {
-	tools := h.toolRegistry.List()
+	tools := h.toolRegistry.ListFor(ctx)
 
 	result := protocol.ToolsListResult{
 		Tools: tools,
@@ -181,29 +288,84 @@ func (h *MCPHandler) handleToolsCall(ctx context.Context, req *protocol.Request)
 
 	startTime := time.Now()
 
+	// Tools can ask clients that declared sampling for LLM completions over their stream
+	if session := h.session(ctx); session != nil && session.SupportsSampling() {
+		ctx = tools.WithSampler(ctx, session)
+	}
+
 	// Execute tool
 	result, err := h.toolRegistry.Execute(ctx, toolReq.Name, toolReq.Arguments)
 	duration := time.Since(startTime)
 
 	if err != nil {
+		var timeoutErr *tools.TimeoutError
+		isTimeout := errors.As(err, &timeoutErr)
+		var permissionErr *tools.PermissionError
+		isDenied := errors.As(err, &permissionErr)
+		var safeModeErr *safemode.Error
+		isSafeMode := errors.As(err, &safeModeErr)
+		var argumentErr *tools.ArgumentError
+		isInvalid := errors.As(err, &argumentErr)
+		var unavailableErr *resilience.OpenError
+		isUnavailable := errors.As(err, &unavailableErr)
+		var quotaErr *storage.QuotaError
+		isQuota := errors.As(err, &quotaErr)
+
+		status, errorType := audit.StatusError, "tool_execution_failed"
+		switch {
+		case isTimeout:
+			status, errorType = audit.StatusTimeout, "tool_execution_timeout"
+		case isDenied:
+			status, errorType = audit.StatusDenied, "tool_permission_denied"
+		case isSafeMode:
+			status, errorType = audit.StatusDenied, "tool_safe_mode"
+		case isInvalid:
+			errorType = "tool_invalid_arguments"
+		case isUnavailable:
+			errorType = "tool_dependency_unavailable"
+		case isQuota:
+			errorType = "tool_quota_exceeded"
+		}
+		h.audit(ctx, toolReq, status, duration)
+
 		// Record error metrics
 		if h.telemetry != nil && h.telemetry.Metrics != nil {
-			h.telemetry.Metrics.RecordToolExecution(ctx, toolReq.Name, "error", float64(duration.Milliseconds()))
-			h.telemetry.Metrics.RecordError(ctx, "tool_execution_failed", toolReq.Name)
+			h.telemetry.Metrics.RecordToolExecution(ctx, toolReq.Name, status, float64(duration.Microseconds())/1000)
+			h.telemetry.Metrics.RecordError(ctx, errorType, toolReq.Name)
 		}
 		if span != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
 		}
 
+		if isTimeout {
+			return protocol.NewErrorResponse(req.ID, protocol.RequestTimeout,
+				fmt.Sprintf("Tool execution timed out: %s", err.Error()), timeoutErr.Data())
+		}
+		if isDenied {
+			return protocol.NewErrorResponse(req.ID, protocol.AuthorizationFailed,
+				fmt.Sprintf("Permission denied: %s", err.Error()), permissionErr.Data())
+		}
+		if isSafeMode {
+			return protocol.NewErrorResponse(req.ID, protocol.SafeModeActive, safeModeErr.Error(), safeModeErr.Data())
+		}
+		if isInvalid {
+			return protocol.NewErrorResponse(req.ID, protocol.InvalidParams, argumentErr.Error(), argumentErr.Data())
+		}
+		if isUnavailable {
+			return protocol.NewErrorResponse(req.ID, protocol.ServerError, unavailableErr.Error(), unavailableErr.Data())
+		}
+		if isQuota {
+			return protocol.NewErrorResponse(req.ID, protocol.QuotaExceeded, quotaErr.Error(), quotaErr.Data())
+		}
 		return protocol.NewErrorResponse(req.ID, protocol.InternalError,
 			fmt.Sprintf("Tool execution failed: %s", err.Error()), nil)
 	}
 
 	// Record success metrics
-	status := "success"
+	status := audit.StatusSuccess
 	if result.IsError {
-		status = "error"
+		status = audit.StatusError
 		if span != nil {
 			span.SetStatus(codes.Error, "tool returned error")
 		}
@@ -213,16 +375,186 @@ func (h *MCPHandler) handleToolsCall(ctx context.Context, req *protocol.Request)
 		}
 	}
 
+	h.audit(ctx, toolReq, status, duration)
 	if h.telemetry != nil && h.telemetry.Metrics != nil {
-		h.telemetry.Metrics.RecordToolExecution(ctx, toolReq.Name, status, float64(duration.Milliseconds()))
+		h.telemetry.Metrics.RecordToolExecution(ctx, toolReq.Name, status, float64(duration.Microseconds())/1000)
 	}
 
-	return protocol.NewResponse(req.ID, result)
+	// Truncated last, so continuations are redacted and the limit applies to what is sent
+	result = h.redact(ctx, h.offload(ctx, result))
+	return protocol.NewResponse(req.ID, h.truncator.Truncate(ctx, result))
 }
 
-// sendResponse sends a JSON-RPC response
-func (h *MCPHandler) sendResponse(w http.ResponseWriter, response *protocol.Response) {
+// offload replaces the large images of a tool result with links to stored copies,
+// returning them inline when they cannot be stored
+func (h *MCPHandler) offload(ctx context.Context, result protocol.ToolCallResult) protocol.ToolCallResult {
+	offloaded, err := h.blobs.Offload(ctx, result)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to store tool output, returning it inline", "error", err)
+		return result
+	}
+	return offloaded
+}
+
+// redact masks PII in the text blocks of a tool result as the tenant's policy asks
+func (h *MCPHandler) redact(ctx context.Context, result protocol.ToolCallResult) protocol.ToolCallResult {
+	if h.redactor == nil {
+		return result
+	}
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return result
+	}
+
+	content := make([]protocol.ContentBlock, len(result.Content))
+	for i, block := range result.Content {
+		if block.Type == "text" {
+			block.Text = h.redactor.Redact(ctx, tenantID, redaction.StageOutput, block.Text)
+		}
+		content[i] = block
+	}
+	result.Content = content
+	return result
+}
+
+// warnDeprecated adds a warning and deprecation headers for a deprecated method or tool
+func (h *MCPHandler) warnDeprecated(ctx context.Context, header http.Header, req *protocol.Request, response *protocol.Response) {
+	if h.deprecations == nil {
+		return
+	}
+
+	warn := func(kind deprecation.Kind, name string) {
+		notice, ok := h.deprecations.Use(ctx, kind, name)
+		if !ok {
+			return
+		}
+		warning := protocol.Warning{
+			Code:        protocol.WarningDeprecated,
+			Message:     notice.Message(kind, name),
+			Replacement: notice.Replacement,
+		}
+		if !notice.Sunset.IsZero() {
+			warning.Sunset = notice.Sunset.UTC().Format(time.RFC3339)
+		}
+		response.Warnings = append(response.Warnings, warning)
+		deprecation.WriteHeaders(header, notice)
+	}
+
+	warn(deprecation.KindMethod, req.Method)
+	if req.Method == protocol.MethodToolsCall {
+		var toolReq protocol.ToolCallRequest
+		if err := req.ParseParams(&toolReq); err == nil && toolReq.Name != "" {
+			warn(deprecation.KindTool, toolReq.Name)
+		}
+	}
+}
+
+// audit records a tool call outcome if an auditor is set
+func (h *MCPHandler) audit(ctx context.Context, toolReq protocol.ToolCallRequest, status string, duration time.Duration) {
+	if h.auditor != nil {
+		h.auditor.RecordToolCall(ctx, toolReq.Name, toolReq.Arguments, status, duration)
+	}
+}
+
+// handleResourcesList handles the resources/list request
+func (h *MCPHandler) handleResourcesList(ctx context.Context, req *protocol.Request) *protocol.Response {
+	if _, err := auth.ExtractTenantID(ctx); err != nil {
+		return protocol.NewErrorResponse(req.ID, protocol.AuthenticationRequired, "Authentication required", nil)
+	}
+
+	listed, err := h.resourceRegistry.List(ctx)
+	if err != nil {
+		return protocol.NewErrorResponse(req.ID, protocol.InternalError,
+			fmt.Sprintf("Failed to list resources: %s", err.Error()), nil)
+	}
+
+	return protocol.NewResponse(req.ID, protocol.ResourcesListResult{Resources: listed})
+}
+
+// handleResourcesRead handles the resources/read request
+func (h *MCPHandler) handleResourcesRead(ctx context.Context, req *protocol.Request) *protocol.Response {
+	var readReq protocol.ResourceReadRequest
+	if err := req.ParseParams(&readReq); err != nil || readReq.URI == "" {
+		return protocol.NewErrorResponse(req.ID, protocol.InvalidParams, "Invalid resource read params: uri is required", nil)
+	}
+	if _, err := auth.ExtractTenantID(ctx); err != nil {
+		return protocol.NewErrorResponse(req.ID, protocol.AuthenticationRequired, "Authentication required", nil)
+	}
+
+	var span trace.Span
+	if h.telemetry != nil && h.telemetry.Tracer != nil {
+		ctx, span = h.telemetry.Tracer.Start(ctx, "mcp.resource.read",
+			trace.WithAttributes(
+				attribute.String("resource.uri", readReq.URI),
+			),
+		)
+		defer span.End()
+	}
+
+	contents, err := h.resourceRegistry.Read(ctx, readReq.URI)
+	if err != nil {
+		if span != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		var unavailableErr *resilience.OpenError
+		switch {
+		case errors.As(err, &unavailableErr):
+			return protocol.NewErrorResponse(req.ID, protocol.ServerError, unavailableErr.Error(), unavailableErr.Data())
+		case errors.Is(err, resources.ErrNotFound):
+			return protocol.NewErrorResponse(req.ID, protocol.ResourceNotFound, err.Error(), map[string]interface{}{"uri": readReq.URI})
+		case errors.Is(err, resources.ErrInvalidURI):
+			return protocol.NewErrorResponse(req.ID, protocol.InvalidParams, err.Error(), nil)
+		default:
+			return protocol.NewErrorResponse(req.ID, protocol.InternalError,
+				fmt.Sprintf("Failed to read resource: %s", err.Error()), nil)
+		}
+	}
+
+	return protocol.NewResponse(req.ID, protocol.ResourceReadResult{Contents: contents})
+}
+
+// handleResourcesSubscribe handles the resources/subscribe and resources/unsubscribe requests
+func (h *MCPHandler) handleResourcesSubscribe(ctx context.Context, req *protocol.Request) *protocol.Response {
+	var subscribeReq protocol.ResourceSubscribeRequest
+	if err := req.ParseParams(&subscribeReq); err != nil || subscribeReq.URI == "" {
+		return protocol.NewErrorResponse(req.ID, protocol.InvalidParams, "Invalid resource subscribe params: uri is required", nil)
+	}
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.NewErrorResponse(req.ID, protocol.AuthenticationRequired, "Authentication required", nil)
+	}
+
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	session, err := h.sessions.Get(id, tenantID)
+	if err != nil {
+		return protocol.NewErrorResponse(req.ID, protocol.InvalidRequest,
+			"Unknown or missing "+sessions.Header+" header: call initialize to start a session", nil)
+	}
+
+	uri, err := h.resourceRegistry.SubscriptionURI(subscribeReq.URI)
+	if err != nil {
+		if errors.Is(err, resources.ErrNotFound) {
+			return protocol.NewErrorResponse(req.ID, protocol.ResourceNotFound, err.Error(), map[string]interface{}{"uri": subscribeReq.URI})
+		}
+		return protocol.NewErrorResponse(req.ID, protocol.InvalidParams, err.Error(), nil)
+	}
+
+	if req.Method == protocol.MethodResourcesSubscribe {
+		session.Subscribe(uri)
+	} else {
+		session.Unsubscribe(uri)
+	}
+	return protocol.NewResponse(req.ID, map[string]interface{}{})
+}
+
+// sendResponse sends a JSON-RPC response; errors carry the request ID so callers can
+// quote it when reporting them
+func (h *MCPHandler) sendResponse(ctx context.Context, w http.ResponseWriter, response *protocol.Response) {
 	w.Header().Set("Content-Type", "application/json")
+	if response.Error != nil {
+		response.Error.SetRequestID(logging.RequestID(ctx))
+	}
 
 	// Set HTTP status based on error type
 	// JSON-RPC 2.0 protocol errors return HTTP 200 (the HTTP request succeeded)
@@ -230,14 +562,22 @@ func (h *MCPHandler) sendResponse(w http.ResponseWriter, response *protocol.Resp
 	if response.Error != nil {
 		switch response.Error.Code {
 		// MCP application-level errors - use semantic HTTP codes
-		case protocol.AuthenticationRequired, protocol.AuthorizationFailed:
+		case protocol.AuthenticationRequired:
 			w.WriteHeader(http.StatusUnauthorized)
+		case protocol.AuthorizationFailed:
+			w.WriteHeader(http.StatusForbidden)
 		case protocol.RateLimitExceeded:
 			w.WriteHeader(http.StatusTooManyRequests)
 		case protocol.ResourceNotFound:
 			w.WriteHeader(http.StatusNotFound)
 		case protocol.ValidationError:
 			w.WriteHeader(http.StatusBadRequest)
+		case protocol.SafeModeActive:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case protocol.RequestTooLarge:
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case protocol.QuotaExceeded:
+			w.WriteHeader(http.StatusForbidden)
 		// Standard JSON-RPC protocol errors - return HTTP 200
 		case protocol.ParseError, protocol.InvalidRequest, protocol.MethodNotFound,
 			protocol.InvalidParams, protocol.InternalError, protocol.ServerError:
@@ -256,7 +596,7 @@ func (h *MCPHandler) sendResponse(w http.ResponseWriter, response *protocol.Resp
 }
 
 // sendErrorResponse sends a JSON-RPC error response
-func (h *MCPHandler) sendErrorResponse(w http.ResponseWriter, id interface{}, code int, message string) {
+func (h *MCPHandler) sendErrorResponse(ctx context.Context, w http.ResponseWriter, id interface{}, code int, message string) {
 	response := protocol.NewErrorResponse(id, code, message, nil)
-	h.sendResponse(w, response)
+	h.sendResponse(ctx, w, response)
 }
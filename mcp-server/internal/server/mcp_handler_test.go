@@ -6,60 +6,75 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/deprecation"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/redaction"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resources"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/bhatti/mcp-a2a-go/shared/httpserver"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockStore implements database.Store for testing
+// MockStore implements storage.Store for testing
 type MockStore struct {
 	mock.Mock
 }
 
-func (m *MockStore) SearchDocuments(ctx context.Context, tenantID, query string, limit int) ([]*database.Document, error) {
-	args := m.Called(ctx, tenantID, query, limit)
+func (m *MockStore) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	args := m.Called(ctx, tenantID, query, limit, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*database.Document), args.Error(1)
+	return args.Get(0).([]*storage.Document), args.Error(1)
 }
 
-func (m *MockStore) GetDocument(ctx context.Context, tenantID, docID string) (*database.Document, error) {
+func (m *MockStore) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
 	args := m.Called(ctx, tenantID, docID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*database.Document), args.Error(1)
+	return args.Get(0).(*storage.Document), args.Error(1)
 }
 
-func (m *MockStore) ListDocuments(ctx context.Context, tenantID string, limit, offset int) ([]*database.Document, error) {
+func (m *MockStore) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*storage.Document, error) {
 	args := m.Called(ctx, tenantID, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*database.Document), args.Error(1)
+	return args.Get(0).([]*storage.Document), args.Error(1)
 }
 
-func (m *MockStore) HybridSearch(ctx context.Context, tenantID string, params database.HybridSearchParams) ([]database.HybridSearchResult, error) {
+func (m *MockStore) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
 	args := m.Called(ctx, tenantID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]database.HybridSearchResult), args.Error(1)
+	return args.Get(0).([]storage.HybridSearchResult), args.Error(1)
 }
 
-func (m *MockStore) SimpleHybridSearch(ctx context.Context, tenantID string, params database.HybridSearchParams) ([]database.HybridSearchResult, error) {
+func (m *MockStore) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
 	args := m.Called(ctx, tenantID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]database.HybridSearchResult), args.Error(1)
+	return args.Get(0).([]storage.HybridSearchResult), args.Error(1)
+}
+
+func (m *MockStore) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	args := m.Called(ctx, tenantID, docID, limit, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]storage.ScoredDocument), args.Error(1)
 }
 
 func TestNewMCPHandler(t *testing.T) {
@@ -105,6 +120,24 @@ func TestMCPHandler_ServeHTTP_InvalidJSON(t *testing.T) {
 	assert.Equal(t, protocol.ParseError, response.Error.Code)
 }
 
+func TestMCPHandler_ServeHTTP_ErrorCarriesRequestID(t *testing.T) {
+	registry := tools.NewRegistry()
+	handler := httpserver.NewLoggingMiddleware(nil).Handler(NewMCPHandler(registry, nil))
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"unknown/method"}`))
+	req.Header.Set(httpserver.RequestIDHeader, "req-123")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "req-123", rr.Header().Get(httpserver.RequestIDHeader))
+	var response protocol.Response
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.MethodNotFound, response.Error.Code)
+	assert.Equal(t, "req-123", response.Error.Data.(map[string]interface{})["request_id"])
+}
+
 func TestMCPHandler_ServeHTTP_InvalidRequest(t *testing.T) {
 	registry := tools.NewRegistry()
 	handler := NewMCPHandler(registry, nil)
@@ -127,6 +160,29 @@ func TestMCPHandler_ServeHTTP_InvalidRequest(t *testing.T) {
 	assert.Equal(t, protocol.InvalidRequest, response.Error.Code)
 }
 
+func TestMCPHandler_ServeHTTP_RequestTooLarge(t *testing.T) {
+	handler := httpserver.NewBodyMiddleware(httpserver.BodyConfig{MaxRequestBytes: 64}).Handler(
+		NewMCPHandler(tools.NewRegistry(), nil))
+
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": "search_documents", "arguments": map[string]interface{}{"query": strings.Repeat("x", 100)}},
+	})
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	var response protocol.Response
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.RequestTooLarge, response.Error.Code)
+	assert.Equal(t, "Request body exceeds 64 bytes", response.Error.Message)
+}
+
 func TestMCPHandler_Initialize(t *testing.T) {
 	registry := tools.NewRegistry()
 	handler := NewMCPHandler(registry, nil)
@@ -211,8 +267,8 @@ func TestMCPHandler_ToolsCall_Success(t *testing.T) {
 	mockDB := new(MockStore)
 
 	// Setup mock to return documents
-	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "test query", 10).
-		Return([]*database.Document{
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "test query", 10, storage.MetadataFilter(nil)).
+		Return([]*storage.Document{
 			{ID: "doc-1", Title: "Test Doc", Content: "Test content"},
 		}, nil)
 
@@ -279,6 +335,315 @@ func TestMCPHandler_ToolsCall_ToolNotFound(t *testing.T) {
 	assert.Contains(t, response.Error.Message, "tool not found")
 }
 
+func TestMCPHandler_ToolsCall_Timeout(t *testing.T) {
+	mockDB := new(MockStore)
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "slow query", 10, storage.MetadataFilter(nil)).
+		After(time.Second).
+		Return([]*storage.Document{}, nil)
+
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewSearchTool(mockDB))
+	registry.SetTimeout("search_documents", 20*time.Millisecond)
+
+	handler := NewMCPHandler(registry, nil)
+
+	callReq, err := protocol.NewRequest("5", protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name: "search_documents",
+		Arguments: map[string]interface{}{
+			"query": "slow query",
+			"limit": 10,
+		},
+	})
+	require.NoError(t, err)
+
+	reqBody, err := json.Marshal(callReq)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	ctx := context.WithValue(req.Context(), auth.ContextKeyTenantID, "tenant-123")
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	var response protocol.Response
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.RequestTimeout, response.Error.Code)
+
+	data, ok := response.Error.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "search_documents", data["tool"])
+	assert.Equal(t, float64(20), data["timeout_ms"])
+	assert.GreaterOrEqual(t, data["elapsed_ms"], float64(20))
+}
+
+func TestMCPHandler_ToolsCall_PermissionDenied(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewSearchTool(new(MockStore)))
+	registry.SetRequiredScope("search_documents", auth.ScopeRead)
+
+	handler := NewMCPHandler(registry, nil)
+
+	callReq, err := protocol.NewRequest("6", protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name:      "search_documents",
+		Arguments: map[string]interface{}{"query": "test"},
+	})
+	require.NoError(t, err)
+
+	reqBody, err := json.Marshal(callReq)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	ctx := auth.WithAuth(req.Context(), &auth.Claims{TenantID: "tenant-123", UserID: "user-456", Scopes: []string{"write"}})
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	var response protocol.Response
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.AuthorizationFailed, response.Error.Code)
+
+	data, ok := response.Error.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "search_documents", data["tool"])
+	assert.Equal(t, "read", data["required_scope"])
+}
+
+// fullWriter rejects every insert with a tenant quota error
+type fullWriter struct {
+	storage.Writer
+}
+
+func (w fullWriter) InsertDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
+	return &storage.QuotaError{Quota: storage.QuotaDocuments, Limit: 1000, Used: 1000}
+}
+
+func TestMCPHandler_ToolsCall_QuotaExceeded(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewIngestTool(fullWriter{}))
+
+	handler := NewMCPHandler(registry, nil)
+
+	callReq, err := protocol.NewRequest("8", protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name:      "ingest_document",
+		Arguments: map[string]interface{}{"title": "Runbook", "content": "Restart the service"},
+	})
+	require.NoError(t, err)
+
+	reqBody, err := json.Marshal(callReq)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	ctx := auth.WithAuth(req.Context(), &auth.Claims{TenantID: "tenant-123", UserID: "user-456", Scopes: []string{"write"}})
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	var response protocol.Response
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.QuotaExceeded, response.Error.Code)
+	assert.Contains(t, response.Error.Message, "tenant holds 1000 of 1000 documents")
+
+	data, ok := response.Error.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, storage.QuotaDocuments, data["quota"])
+	assert.Equal(t, float64(1000), data["limit"])
+	assert.Equal(t, float64(1000), data["used"])
+}
+
+func TestMCPHandler_ToolsCall_RedactsOutput(t *testing.T) {
+	store := storage.NewMemoryStore()
+	doc := &storage.Document{Title: "Escalations", Content: "Page jane@example.com or call (555) 123-4567."}
+	require.NoError(t, store.InsertDocument(context.Background(), "tenant-123", doc))
+
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewRetrieveTool(store))
+	handler := NewMCPHandler(registry, nil)
+	handler.SetRedactor(redaction.NewRedactor(redaction.Config{Policy: redaction.Policy{Output: true, Types: []string{redaction.TypeEmail}}}))
+
+	callReq, err := protocol.NewRequest("9", protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name:      "retrieve_document",
+		Arguments: map[string]interface{}{"document_id": doc.ID},
+	})
+	require.NoError(t, err)
+
+	_, response := serveMCP(t, handler, callReq, "tenant-123")
+	require.Nil(t, response.Error)
+
+	resultJSON, _ := json.Marshal(response.Result)
+	assert.Contains(t, string(resultJSON), "Page [REDACTED_EMAIL] or call (555) 123-4567.")
+	assert.NotContains(t, string(resultJSON), "jane@example.com")
+}
+
+func TestMCPHandler_ToolsCall_TruncatesOutput(t *testing.T) {
+	store := storage.NewMemoryStore()
+	doc := &storage.Document{Title: "Runbook", Content: strings.Repeat("Restart the worker, then check the queue. ", 50)}
+	require.NoError(t, store.InsertDocument(context.Background(), "tenant-123", doc))
+
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewRetrieveTool(store))
+	truncator := tools.NewTruncator(500, time.Minute)
+	registry.Register(tools.NewContinuationTool(truncator))
+	handler := NewMCPHandler(registry, nil)
+	handler.SetTruncator(truncator)
+
+	call := func(name string, args map[string]interface{}) protocol.ToolCallResult {
+		callReq, err := protocol.NewRequest("1", protocol.MethodToolsCall, protocol.ToolCallRequest{Name: name, Arguments: args})
+		require.NoError(t, err)
+		_, response := serveMCP(t, handler, callReq, "tenant-123")
+		require.Nil(t, response.Error)
+		data, err := json.Marshal(response.Result)
+		require.NoError(t, err)
+		var result protocol.ToolCallResult
+		require.NoError(t, json.Unmarshal(data, &result))
+		return result
+	}
+
+	// Following the continuations returns the whole document, at most 500 bytes at a time
+	// with the truncation notice
+	var text strings.Builder
+	result := call("retrieve_document", map[string]interface{}{"document_id": doc.ID})
+	for result.IsTruncated {
+		require.Len(t, result.Content, 2, "the content and the truncation notice")
+		assert.LessOrEqual(t, len(result.Content[0].Text)+len(result.Content[1].Text), 500)
+		assert.Contains(t, result.Content[1].Text, result.ContinuationToken)
+		text.WriteString(result.Content[0].Text)
+		result = call(tools.ContinuationToolName, map[string]interface{}{"continuation_token": result.ContinuationToken})
+	}
+	require.Len(t, result.Content, 1)
+	text.WriteString(result.Content[0].Text)
+	assert.Contains(t, text.String(), "Content:\n"+doc.Content+"\n")
+}
+
+func TestMCPHandler_ToolsCall_InvalidArguments(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewSearchTool(new(MockStore)))
+
+	handler := NewMCPHandler(registry, nil)
+
+	callReq, err := protocol.NewRequest("8", protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name:      "search_documents",
+		Arguments: map[string]interface{}{"query": 42},
+	})
+	require.NoError(t, err)
+
+	reqBody, err := json.Marshal(callReq)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	ctx := auth.WithAuth(req.Context(), &auth.Claims{TenantID: "tenant-123", UserID: "user-456", Scopes: []string{"read"}})
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	var response protocol.Response
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.InvalidParams, response.Error.Code)
+
+	data, err := json.Marshal(response.Error.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tool": "search_documents", "violations": [{"field": "/query", "message": "must be of type string"}]}`, string(data))
+}
+
+func TestMCPHandler_ToolsCall_SafeMode(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewSearchTool(new(MockStore)))
+	registry.SetRestricted("search_documents")
+
+	safeMode, err := safemode.New(context.Background(), safemode.NewMemoryStore())
+	require.NoError(t, err)
+	_, err = safeMode.Set(context.Background(), true, "database failover", "oncall")
+	require.NoError(t, err)
+	registry.SetSafeMode(safeMode)
+
+	handler := NewMCPHandler(registry, nil)
+
+	callReq, err := protocol.NewRequest("7", protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name:      "search_documents",
+		Arguments: map[string]interface{}{"query": "test"},
+	})
+	require.NoError(t, err)
+
+	reqBody, err := json.Marshal(callReq)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	ctx := auth.WithAuth(req.Context(), &auth.Claims{TenantID: "tenant-123", UserID: "user-456", Scopes: []string{"read"}})
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	var response protocol.Response
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.SafeModeActive, response.Error.Code)
+
+	data, ok := response.Error.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, true, data["safe_mode"])
+	assert.Equal(t, "database failover", data["reason"])
+}
+
+// recordingAuditor collects audited tool calls
+type recordingAuditor struct {
+	calls []string
+}
+
+func (a *recordingAuditor) RecordToolCall(ctx context.Context, tool string, args map[string]interface{}, status string, latency time.Duration) {
+	a.calls = append(a.calls, tool+":"+status)
+}
+
+func TestMCPHandler_ToolsCall_Audited(t *testing.T) {
+	mockDB := new(MockStore)
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "test", 10, storage.MetadataFilter(nil)).
+		Return([]*storage.Document{}, nil)
+
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewSearchTool(mockDB))
+	registry.Register(tools.NewRetrieveTool(mockDB))
+	registry.SetRequiredScope("retrieve_document", auth.ScopeAdmin)
+
+	auditor := &recordingAuditor{}
+	handler := NewMCPHandler(registry, nil)
+	handler.SetAuditor(auditor)
+
+	call := func(name string, args map[string]interface{}) {
+		callReq, err := protocol.NewRequest("7", protocol.MethodToolsCall, protocol.ToolCallRequest{Name: name, Arguments: args})
+		require.NoError(t, err)
+		reqBody, err := json.Marshal(callReq)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+		ctx := auth.WithAuth(req.Context(), &auth.Claims{TenantID: "tenant-123", UserID: "user-456", Scopes: []string{"read"}})
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	}
+
+	call("search_documents", map[string]interface{}{"query": "test"})
+	call("retrieve_document", map[string]interface{}{"document_id": "doc-1"})
+	call("missing_tool", nil)
+
+	assert.Equal(t, []string{"search_documents:success", "retrieve_document:denied", "missing_tool:error"}, auditor.calls)
+}
+
 func TestMCPHandler_ToolsCall_InvalidParams(t *testing.T) {
 	mockDB := new(MockStore)
 	registry := tools.NewRegistry()
@@ -328,6 +693,181 @@ func TestMCPHandler_MethodNotFound(t *testing.T) {
 	assert.Contains(t, response.Error.Message, "unknown/method")
 }
 
+func newResourceHandler(t *testing.T) (*MCPHandler, string) {
+	t.Helper()
+
+	store := storage.NewMemoryStore()
+	doc := &storage.Document{Title: "Security Policy", Content: "All services use mTLS. Keys rotate every 90 days."}
+	require.NoError(t, store.InsertDocument(context.Background(), "tenant-123", doc))
+
+	resourceRegistry := resources.NewRegistry()
+	resourceRegistry.Register(resources.NewSummaryProvider(store))
+	resourceRegistry.Register(resources.NewDocumentProvider(store))
+
+	handler := NewMCPHandler(tools.NewRegistry(), nil)
+	handler.SetResourceRegistry(resourceRegistry)
+	return handler, doc.ID
+}
+
+func serveMCP(t *testing.T, handler *MCPHandler, req *protocol.Request, tenantID string) (*httptest.ResponseRecorder, protocol.Response) {
+	t.Helper()
+
+	reqBody, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest("POST", "/mcp", bytes.NewBuffer(reqBody))
+	if tenantID != "" {
+		httpReq = httpReq.WithContext(context.WithValue(httpReq.Context(), auth.ContextKeyTenantID, tenantID))
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httpReq)
+
+	var response protocol.Response
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return rr, response
+}
+
+func TestMCPHandler_Initialize_AdvertisesResources(t *testing.T) {
+	handler, _ := newResourceHandler(t)
+
+	initReq, err := protocol.NewRequest("1", protocol.MethodInitialize, protocol.InitializeRequest{ProtocolVersion: "2024-11-05"})
+	require.NoError(t, err)
+
+	_, response := serveMCP(t, handler, initReq, "")
+	require.Nil(t, response.Error)
+
+	resultJSON, _ := json.Marshal(response.Result)
+	var initResult protocol.InitializeResult
+	require.NoError(t, json.Unmarshal(resultJSON, &initResult))
+	assert.NotNil(t, initResult.Capabilities.Resources)
+}
+
+func TestMCPHandler_ResourcesList(t *testing.T) {
+	handler, _ := newResourceHandler(t)
+
+	listReq, err := protocol.NewRequest("2", protocol.MethodResourcesList, nil)
+	require.NoError(t, err)
+
+	_, response := serveMCP(t, handler, listReq, "tenant-123")
+	require.Nil(t, response.Error)
+
+	resultJSON, _ := json.Marshal(response.Result)
+	var listResult protocol.ResourcesListResult
+	require.NoError(t, json.Unmarshal(resultJSON, &listResult))
+	require.Len(t, listResult.Resources, 1)
+	assert.Equal(t, "documents-summary://", listResult.Resources[0].URI)
+
+	// Unauthenticated listing is rejected
+	_, response = serveMCP(t, handler, listReq, "")
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.AuthenticationRequired, response.Error.Code)
+}
+
+func TestMCPHandler_ResourcesRead(t *testing.T) {
+	handler, docID := newResourceHandler(t)
+
+	readReq, err := protocol.NewRequest("3", protocol.MethodResourcesRead, protocol.ResourceReadRequest{URI: "documents-summary://"})
+	require.NoError(t, err)
+
+	_, response := serveMCP(t, handler, readReq, "tenant-123")
+	require.Nil(t, response.Error)
+
+	resultJSON, _ := json.Marshal(response.Result)
+	var readResult protocol.ResourceReadResult
+	require.NoError(t, json.Unmarshal(resultJSON, &readResult))
+	require.Len(t, readResult.Contents, 1)
+
+	var page resources.SummaryPage
+	require.NoError(t, json.Unmarshal([]byte(readResult.Contents[0].Text), &page))
+	require.Len(t, page.Documents, 1)
+	assert.Equal(t, docID, page.Documents[0].ID)
+	assert.Equal(t, "Security Policy", page.Documents[0].Title)
+	assert.Equal(t, "documents://"+docID, page.Documents[0].URI)
+}
+
+func TestMCPHandler_ResourcesRead_Errors(t *testing.T) {
+	handler, _ := newResourceHandler(t)
+
+	tests := []struct {
+		name     string
+		uri      string
+		tenantID string
+		wantCode int
+		wantHTTP int
+	}{
+		{"missing document", "documents://missing", "tenant-123", protocol.ResourceNotFound, http.StatusNotFound},
+		{"unknown scheme", "files://etc/passwd", "tenant-123", protocol.ResourceNotFound, http.StatusNotFound},
+		{"invalid page size", "documents-summary://?limit=zero", "tenant-123", protocol.InvalidParams, http.StatusOK},
+		{"missing uri", "", "tenant-123", protocol.InvalidParams, http.StatusOK},
+		{"unauthenticated", "documents-summary://", "", protocol.AuthenticationRequired, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readReq, err := protocol.NewRequest("4", protocol.MethodResourcesRead, protocol.ResourceReadRequest{URI: tt.uri})
+			require.NoError(t, err)
+
+			rr, response := serveMCP(t, handler, readReq, tt.tenantID)
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.wantCode, response.Error.Code)
+			assert.Equal(t, tt.wantHTTP, rr.Code)
+		})
+	}
+}
+
+func TestMCPHandler_ResourcesWithoutRegistry(t *testing.T) {
+	handler := NewMCPHandler(tools.NewRegistry(), nil)
+
+	listReq, err := protocol.NewRequest("5", protocol.MethodResourcesList, nil)
+	require.NoError(t, err)
+
+	_, response := serveMCP(t, handler, listReq, "tenant-123")
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.MethodNotFound, response.Error.Code)
+}
+
+func TestMCPHandler_DeprecationWarnings(t *testing.T) {
+	handler, _ := newResourceHandler(t)
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	deprecations := deprecation.NewRegistry()
+	deprecations.Deprecate(deprecation.KindMethod, protocol.MethodResourcesList, deprecation.Notice{Since: since})
+	deprecations.Deprecate(deprecation.KindTool, "search_documents", deprecation.Notice{
+		Since: since, Sunset: sunset, Replacement: "hybrid_search", Link: "https://example.com/migrate",
+	})
+	handler.SetDeprecations(deprecations)
+
+	listReq, err := protocol.NewRequest("1", protocol.MethodResourcesList, nil)
+	require.NoError(t, err)
+	rr, response := serveMCP(t, handler, listReq, "tenant-123")
+	require.Nil(t, response.Error)
+	require.Len(t, response.Warnings, 1)
+	assert.Equal(t, protocol.WarningDeprecated, response.Warnings[0].Code)
+	assert.Equal(t, "method resources/list is deprecated", response.Warnings[0].Message)
+	assert.Equal(t, "@1767225600", rr.Header().Get("Deprecation"))
+	assert.Empty(t, rr.Header().Get("Sunset"))
+
+	// Deprecated tools warn even when the call fails
+	callReq, err := protocol.NewRequest("2", protocol.MethodToolsCall, protocol.ToolCallRequest{Name: "search_documents"})
+	require.NoError(t, err)
+	rr, response = serveMCP(t, handler, callReq, "tenant-123")
+	require.NotNil(t, response.Error)
+	assert.Equal(t, []protocol.Warning{{
+		Code:        protocol.WarningDeprecated,
+		Message:     "tool search_documents is deprecated and will be removed after 2026-07-01; use hybrid_search instead",
+		Sunset:      "2026-07-01T00:00:00Z",
+		Replacement: "hybrid_search",
+	}}, response.Warnings)
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", rr.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"; type="text/html"`, rr.Header().Get("Link"))
+
+	initReq, err := protocol.NewRequest("3", protocol.MethodInitialize, protocol.InitializeRequest{ProtocolVersion: "2024-11-05"})
+	require.NoError(t, err)
+	rr, response = serveMCP(t, handler, initReq, "")
+	assert.Empty(t, response.Warnings)
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+}
+
 func TestMCPHandler_ResponseHeaders(t *testing.T) {
 	registry := tools.NewRegistry()
 	handler := NewMCPHandler(registry, nil)
@@ -399,7 +939,7 @@ func TestMCPHandler_SendResponse_AuthError(t *testing.T) {
 	rr := httptest.NewRecorder()
 	response := protocol.NewErrorResponse("1", protocol.AuthenticationRequired, "Auth required", nil)
 
-	handler.sendResponse(rr, response)
+	handler.sendResponse(context.Background(), rr, response)
 
 	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
@@ -412,7 +952,7 @@ func TestMCPHandler_SendResponse_RateLimitError(t *testing.T) {
 	rr := httptest.NewRecorder()
 	response := protocol.NewErrorResponse("1", protocol.RateLimitExceeded, "Rate limit exceeded", nil)
 
-	handler.sendResponse(rr, response)
+	handler.sendResponse(context.Background(), rr, response)
 
 	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
 }
@@ -424,7 +964,7 @@ func TestMCPHandler_SendResponse_NotFoundError(t *testing.T) {
 	rr := httptest.NewRecorder()
 	response := protocol.NewErrorResponse("1", protocol.ResourceNotFound, "Not found", nil)
 
-	handler.sendResponse(rr, response)
+	handler.sendResponse(context.Background(), rr, response)
 
 	assert.Equal(t, http.StatusNotFound, rr.Code)
 }
@@ -436,7 +976,7 @@ func TestMCPHandler_SendResponse_ValidationError(t *testing.T) {
 	rr := httptest.NewRecorder()
 	response := protocol.NewErrorResponse("1", protocol.ValidationError, "Validation failed", nil)
 
-	handler.sendResponse(rr, response)
+	handler.sendResponse(context.Background(), rr, response)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
@@ -449,7 +989,7 @@ func TestMCPHandler_SendResponse_UnknownError(t *testing.T) {
 	// Use an error code that doesn't match any known cases
 	response := protocol.NewErrorResponse("1", -99999, "Unknown error", nil)
 
-	handler.sendResponse(rr, response)
+	handler.sendResponse(context.Background(), rr, response)
 
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 }
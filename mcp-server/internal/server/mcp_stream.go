@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/sessions"
+)
+
+// streamKeepAlive is how often an idle notification stream gets a comment line, so
+// proxies do not close it
+const streamKeepAlive = 25 * time.Second
+
+// sessionIDKey is the context key of the request's Mcp-Session-Id
+type sessionIDKey struct{}
+
+// startSession starts a session for a successful authenticated initialize and returns its ID in the header
+func (h *MCPHandler) startSession(ctx context.Context, header http.Header, req *protocol.Request, response *protocol.Response) {
+	if h.sessions == nil || req.Method != protocol.MethodInitialize || response.Error != nil {
+		return
+	}
+	// Sessions are bound to a tenant, so anonymous clients do not get one
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return
+	}
+	session := h.sessions.Create(tenantID)
+	var initReq protocol.InitializeRequest
+	if err := req.ParseParams(&initReq); err == nil {
+		session.SetCapabilities(initReq.Capabilities)
+	}
+	header.Set(sessions.Header, session.ID)
+}
+
+// session returns the caller's session, or nil for requests without a valid Mcp-Session-Id
+func (h *MCPHandler) session(ctx context.Context) *sessions.Session {
+	if h.sessions == nil {
+		return nil
+	}
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if id == "" || err != nil {
+		return nil
+	}
+	session, err := h.sessions.Get(id, tenantID)
+	if err != nil {
+		return nil
+	}
+	return session
+}
+
+// clientResponse is a client's answer to a server request
+type clientResponse struct {
+	ID     interface{}     `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *protocol.Error `json:"error"`
+}
+
+// resolveClientResponse hands a POSTed response to the server request of the session
+// waiting for it and answers 202 Accepted. It reports false for bodies that are not
+// responses, leaving them to request validation.
+func (h *MCPHandler) resolveClientResponse(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	var response clientResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.ID == nil || (response.Result == nil && response.Error == nil) {
+		return false
+	}
+
+	ctx := r.Context()
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return true
+	}
+	session, err := h.sessions.Get(r.Header.Get(sessions.Header), tenantID)
+	if err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return true
+	}
+	if !session.Resolve(fmt.Sprint(response.ID), response.Result, response.Error) {
+		// The request already timed out or was answered
+		slog.DebugContext(ctx, "Dropped response to unknown server request", "tenant_id", tenantID, "id", response.ID)
+	}
+	w.WriteHeader(http.StatusAccepted)
+	return true
+}
+
+// serveSession handles
+//
+//	GET    /mcp  open the session's notification stream (text/event-stream)
+//	DELETE /mcp  end the session
+//
+// Both need the Mcp-Session-Id header returned by initialize.
+func (h *MCPHandler) serveSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	id := r.Header.Get(sessions.Header)
+	if id == "" {
+		http.Error(w, sessions.Header+" header required", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.sessions.Delete(id, tenantID); err != nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	session, err := h.sessions.Get(id, tenantID)
+	if err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	notifications, release, err := session.Stream()
+	if errors.Is(err, sessions.ErrStreamOpen) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	defer release()
+
+	flusher, ok := startSSE(w)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Streams end when the server starts draining so shutdown is not held up
+	var draining <-chan struct{}
+	if h.lifecycle != nil {
+		draining = h.lifecycle.Stream(ctx)
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-draining:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case notification := <-notifications:
+			if err := writeSSE(w, flusher, notification); err != nil {
+				slog.DebugContext(ctx, "Notification stream closed", "tenant_id", tenantID, "error", err)
+				return
+			}
+		}
+	}
+}
+
+// startSSE sets the SSE headers and lifts the server read and write timeouts for a
+// long-lived stream; the read deadline would otherwise cancel the request context
+func startSSE(w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	// Not every ResponseWriter supports deadlines (httptest.ResponseRecorder does not)
+	controller := http.NewResponseController(w)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	return flusher, true
+}
+
+// writeSSE writes data as JSON in an SSE message
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", body); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
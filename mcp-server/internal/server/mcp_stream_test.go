@@ -0,0 +1,247 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resources"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/sessions"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionServer serves a resource handler with sessions, authenticating every request as tenant-123
+func newSessionServer(t *testing.T) (*httptest.Server, *sessions.Hub, string) {
+	t.Helper()
+
+	handler, docID := newResourceHandler(t)
+	hub := sessions.NewHub(sessions.Config{})
+	handler.SetSessions(hub)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auth.ContextKeyTenantID, "tenant-123")))
+	}))
+	t.Cleanup(server.Close)
+	return server, hub, docID
+}
+
+// postMCP sends a JSON-RPC request in the given session
+func postMCP(t *testing.T, url, sessionID, method string, params interface{}) (*http.Response, protocol.Response) {
+	t.Helper()
+
+	req, err := protocol.NewRequest("1", method, params)
+	require.NoError(t, err)
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	if sessionID != "" {
+		httpReq.Header.Set(sessions.Header, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response protocol.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return resp, response
+}
+
+// openStream opens a session's notification stream
+func openStream(t *testing.T, url, sessionID string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessions.Header, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestMCPHandler_ResourcesSubscribe(t *testing.T) {
+	server, hub, docID := newSessionServer(t)
+
+	resp, response := postMCP(t, server.URL, "", protocol.MethodInitialize, protocol.InitializeRequest{ProtocolVersion: "2024-11-05"})
+	require.Nil(t, response.Error)
+	sessionID := resp.Header.Get(sessions.Header)
+	require.NotEmpty(t, sessionID)
+	resultJSON, _ := json.Marshal(response.Result)
+	var initResult protocol.InitializeResult
+	require.NoError(t, json.Unmarshal(resultJSON, &initResult))
+	assert.True(t, initResult.Capabilities.Resources.Subscribe)
+	assert.True(t, initResult.Capabilities.Tools.ListChanged)
+
+	_, response = postMCP(t, server.URL, sessionID, protocol.MethodResourcesSubscribe, protocol.ResourceSubscribeRequest{URI: "documents://" + docID})
+	require.Nil(t, response.Error)
+	_, response = postMCP(t, server.URL, sessionID, protocol.MethodResourcesSubscribe, protocol.ResourceSubscribeRequest{URI: "documents-summary://?limit=5"})
+	require.Nil(t, response.Error)
+
+	stream := openStream(t, server.URL, sessionID)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	// One stream per session
+	second := openStream(t, server.URL, sessionID)
+	second.Body.Close()
+	assert.Equal(t, http.StatusConflict, second.StatusCode)
+
+	assert.Equal(t, 2, hub.NotifyResourceUpdated("tenant-123", resources.DocumentURIs(docID)...))
+	reader := bufio.NewReader(stream.Body)
+	for _, want := range []string{"documents://" + docID, "documents-summary://"} {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(line, "data: "), line)
+		var notification protocol.Request
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &notification))
+		assert.Equal(t, protocol.MethodResourcesUpdated, notification.Method)
+		var params protocol.ResourceUpdatedNotification
+		require.NoError(t, json.Unmarshal(notification.Params, &params))
+		assert.Equal(t, want, params.URI)
+		_, err = reader.ReadString('\n') // blank line ending the event
+		require.NoError(t, err)
+	}
+
+	_, response = postMCP(t, server.URL, sessionID, protocol.MethodResourcesUnsubscribe, protocol.ResourceSubscribeRequest{URI: "documents://" + docID})
+	require.Nil(t, response.Error)
+	assert.Equal(t, 1, hub.NotifyResourceUpdated("tenant-123", resources.DocumentURIs(docID)...))
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(sessions.Header, sessionID)
+	deleted, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	deleted.Body.Close()
+	assert.Equal(t, http.StatusNoContent, deleted.StatusCode)
+}
+
+func TestMCPHandler_ResourcesSubscribe_Errors(t *testing.T) {
+	server, hub, _ := newSessionServer(t)
+	sessionID := hub.Create("tenant-123").ID
+	otherTenant := hub.Create("tenant-456").ID
+
+	tests := []struct {
+		name      string
+		sessionID string
+		uri       string
+		wantCode  int
+	}{
+		{"missing session", "", "documents://doc-1", protocol.InvalidRequest},
+		{"unknown session", "unknown", "documents://doc-1", protocol.InvalidRequest},
+		{"session of another tenant", otherTenant, "documents://doc-1", protocol.InvalidRequest},
+		{"missing uri", sessionID, "", protocol.InvalidParams},
+		{"unknown scheme", sessionID, "files://etc/passwd", protocol.ResourceNotFound},
+		{"invalid uri", sessionID, "doc-1", protocol.InvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, response := postMCP(t, server.URL, tt.sessionID, protocol.MethodResourcesSubscribe, protocol.ResourceSubscribeRequest{URI: tt.uri})
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.wantCode, response.Error.Code)
+		})
+	}
+
+	missing := openStream(t, server.URL, "unknown")
+	missing.Body.Close()
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+	foreign := openStream(t, server.URL, otherTenant)
+	foreign.Body.Close()
+	assert.Equal(t, http.StatusNotFound, foreign.StatusCode)
+}
+
+func TestMCPHandler_ResourcesSubscribe_WithoutSessions(t *testing.T) {
+	handler, _ := newResourceHandler(t)
+
+	subscribeReq, err := protocol.NewRequest("6", protocol.MethodResourcesSubscribe, protocol.ResourceSubscribeRequest{URI: "documents-summary://"})
+	require.NoError(t, err)
+	rr, response := serveMCP(t, handler, subscribeReq, "tenant-123")
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.MethodNotFound, response.Error.Code)
+	assert.Empty(t, rr.Header().Get(sessions.Header))
+}
+
+func TestMCPHandler_Sampling(t *testing.T) {
+	store := storage.NewMemoryStore()
+	doc := &storage.Document{Title: "Security Policy", Content: "All services use mTLS. Keys rotate every 90 days."}
+	require.NoError(t, store.InsertDocument(context.Background(), "tenant-123", doc))
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewSummarizeTool(store, resources.Summarize))
+	handler := NewMCPHandler(registry, nil)
+	handler.SetSessions(sessions.NewHub(sessions.Config{}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auth.ContextKeyTenantID, "tenant-123")))
+	}))
+	defer server.Close()
+
+	resp, response := postMCP(t, server.URL, "", protocol.MethodInitialize, protocol.InitializeRequest{
+		ProtocolVersion: "2024-11-05",
+		Capabilities:    protocol.ClientCapabilities{Sampling: &protocol.SamplingCapability{}},
+	})
+	require.Nil(t, response.Error)
+	sessionID := resp.Header.Get(sessions.Header)
+	stream := openStream(t, server.URL, sessionID)
+	defer stream.Body.Close()
+
+	results := make(chan protocol.Response, 1)
+	go func() {
+		_, response := postMCP(t, server.URL, sessionID, protocol.MethodToolsCall, protocol.ToolCallRequest{
+			Name:      "summarize_document",
+			Arguments: map[string]interface{}{"document_id": doc.ID},
+		})
+		results <- response
+	}()
+
+	// The tool's sampling request arrives on the stream
+	line, err := bufio.NewReader(stream.Body).ReadString('\n')
+	require.NoError(t, err)
+	var request protocol.Request
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &request))
+	assert.Equal(t, protocol.MethodSamplingCreateMessage, request.Method)
+	require.NotNil(t, request.ID)
+
+	answer, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      request.ID,
+		"result":  protocol.CreateMessageResult{Role: "assistant", Content: protocol.ContentBlock{Type: "text", Text: "Services use mTLS with 90-day keys."}, Model: "test-model"},
+	})
+	require.NoError(t, err)
+	httpReq, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(answer))
+	require.NoError(t, err)
+	httpReq.Header.Set(sessions.Header, sessionID)
+	accepted, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	accepted.Body.Close()
+	assert.Equal(t, http.StatusAccepted, accepted.StatusCode)
+
+	response = <-results
+	require.Nil(t, response.Error)
+	resultJSON, _ := json.Marshal(response.Result)
+	var result protocol.ToolCallResult
+	require.NoError(t, json.Unmarshal(resultJSON, &result))
+	assert.Contains(t, result.Content[0].Text, "Services use mTLS with 90-day keys.")
+	assert.Contains(t, result.Content[0].Text, "Summary source: sampling (test-model)")
+
+	// Without the sampling capability the tool falls back at once
+	resp, _ = postMCP(t, server.URL, "", protocol.MethodInitialize, protocol.InitializeRequest{ProtocolVersion: "2024-11-05"})
+	_, response = postMCP(t, server.URL, resp.Header.Get(sessions.Header), protocol.MethodToolsCall, protocol.ToolCallRequest{
+		Name:      "summarize_document",
+		Arguments: map[string]interface{}{"document_id": doc.ID},
+	})
+	require.Nil(t, response.Error)
+	resultJSON, _ = json.Marshal(response.Result)
+	require.NoError(t, json.Unmarshal(resultJSON, &result))
+	assert.Contains(t, result.Content[0].Text, "Summary source: extractive")
+}
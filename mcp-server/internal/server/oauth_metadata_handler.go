@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+)
+
+// OAuthMetadataHandler publishes the documents MCP clients read to start the OAuth
+// authorization handshake, without authentication:
+//
+//	GET /.well-known/oauth-protected-resource[/mcp]  protected resource metadata (RFC 9728)
+//	GET /.well-known/oauth-authorization-server      the provider's metadata, for clients
+//	                                                 that look for it on the MCP server
+type OAuthMetadataHandler struct {
+	resource *auth.ProtectedResourceMetadata
+	provider *auth.ProviderMetadata
+}
+
+// NewOAuthMetadataHandler creates a handler for the metadata of the MCP endpoint and of
+// the OpenID provider that issues its tokens
+func NewOAuthMetadataHandler(resource auth.ProtectedResourceMetadata, provider *auth.ProviderMetadata) *OAuthMetadataHandler {
+	return &OAuthMetadataHandler{resource: &resource, provider: provider}
+}
+
+// ServeHTTP implements http.Handler
+func (h *OAuthMetadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	// Browser-based MCP clients fetch the metadata cross-origin
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	switch {
+	case r.URL.Path == auth.AuthorizationServerMetadataPath && h.provider != nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(h.provider.Raw)
+	case r.URL.Path == auth.ProtectedResourceMetadataPath || r.URL.Path == auth.ResourceMetadataPath(h.resource.Resource):
+		writeJSON(w, http.StatusOK, h.resource)
+	default:
+		http.NotFound(w, r)
+	}
+}
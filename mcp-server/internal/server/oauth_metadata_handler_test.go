@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthMetadataHandler(t *testing.T) {
+	handler := NewOAuthMetadataHandler(auth.ProtectedResourceMetadata{
+		Resource:               "https://mcp.example.com/mcp",
+		AuthorizationServers:   []string{"https://idp.example.com"},
+		ScopesSupported:        []string{"read", "write"},
+		BearerMethodsSupported: []string{"header"},
+	}, &auth.ProviderMetadata{
+		Issuer: "https://idp.example.com",
+		Raw:    json.RawMessage(`{"issuer":"https://idp.example.com","token_endpoint":"https://idp.example.com/token"}`),
+	})
+
+	for _, path := range []string{auth.ProtectedResourceMetadataPath, auth.ProtectedResourceMetadataPath + "/mcp"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rr.Code, path)
+		assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+
+		var metadata auth.ProtectedResourceMetadata
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&metadata))
+		assert.Equal(t, "https://mcp.example.com/mcp", metadata.Resource)
+		assert.Equal(t, []string{"https://idp.example.com"}, metadata.AuthorizationServers)
+	}
+
+	// The provider's own document is passed through unchanged
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, auth.AuthorizationServerMetadataPath, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"issuer":"https://idp.example.com","token_endpoint":"https://idp.example.com/token"}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, auth.ProtectedResourceMetadataPath+"/other", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, auth.ProtectedResourceMetadataPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+)
+
+// TenantsPath is the admin endpoint that onboards new tenants
+const TenantsPath = "/admin/tenants"
+
+// OnboardingHandler creates tenants for platform operators
+type OnboardingHandler struct {
+	service *onboarding.Service
+}
+
+// NewOnboardingHandler creates a new tenant onboarding handler
+func NewOnboardingHandler(service *onboarding.Service) *OnboardingHandler {
+	return &OnboardingHandler{service: service}
+}
+
+// ServeHTTP handles
+//
+//	POST /admin/tenants  onboard a tenant ({"name", "admin_user_id", "tier", "data_region",
+//	                     "rate_limit_per_minute", "monthly_budget_usd", "settings", "sample_documents"})
+//
+// and returns the bootstrap bundle: 201 when every step succeeded, 500 with the bundle
+// when the tenant was created but a later step failed.
+func (h *OnboardingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := auth.ExtractTenantID(ctx); err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, auth.ScopeProvision) {
+		http.Error(w, "Provision scope required", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req onboarding.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := h.service.Onboard(ctx, req)
+	switch {
+	case errors.Is(err, onboarding.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, onboarding.ErrTenantExists):
+		http.Error(w, "Tenant already exists", http.StatusConflict)
+	case errors.Is(err, onboarding.ErrIncomplete):
+		writeJSON(w, http.StatusInternalServerError, bundle)
+	case err != nil:
+		http.Error(w, "Failed to onboard tenant", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusCreated, bundle)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/onboarding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnboardingHandler(t *testing.T) {
+	service := onboarding.NewService(onboarding.NewMemoryTenantStore(), auth.NewMemoryRoleStore(), onboarding.DefaultConfig())
+	handler := NewOnboardingHandler(service)
+
+	serve := func(method, body string, scopes ...string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, TenantsPath, body, scopes...))
+		return rec
+	}
+	body := `{"name": "hotel", "admin_user_id": "alice", "tier": "enterprise"}`
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, TenantsPath, nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// A tenant admin cannot create other tenants
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, body, AdminScope).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "", auth.ScopeProvision).Code)
+
+	rec = serve(http.MethodPost, body, auth.ScopeProvision)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var bundle onboarding.Bundle
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bundle))
+	assert.NotEmpty(t, bundle.Tenant.ID)
+	assert.Equal(t, "hotel", bundle.Tenant.Name)
+	assert.Equal(t, 1000, bundle.RateLimitPerMinute)
+
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, body, auth.ScopeProvision).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{"name": "india"}`, auth.ScopeProvision).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{`, auth.ScopeProvision).Code)
+}
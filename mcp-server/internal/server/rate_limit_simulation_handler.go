@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/middleware"
+)
+
+// RateLimitSimulationPath is the rate limit dry-run endpoint
+const RateLimitSimulationPath = "/admin/simulations/rate-limit"
+
+// maxSimulatedRequests bounds the audit entries one simulation reads
+const maxSimulatedRequests = 100000
+
+// CurrentRateLimit returns the limit in requests per minute a tenant is held to now
+type CurrentRateLimit func(ctx context.Context, tenantID string) int
+
+// RateLimitSimulationHandler evaluates a proposed rate limit against the calling tenant's
+// recorded tool calls before it is applied
+type RateLimitSimulationHandler struct {
+	store   database.AuditLogStore
+	current CurrentRateLimit
+}
+
+// NewRateLimitSimulationHandler creates a new rate limit simulation handler
+func NewRateLimitSimulationHandler(store database.AuditLogStore, current CurrentRateLimit) *RateLimitSimulationHandler {
+	return &RateLimitSimulationHandler{store: store, current: current}
+}
+
+// RateLimitSimulationRequest is the request body of a rate limit simulation
+type RateLimitSimulationRequest struct {
+	RateLimitPerMinute int `json:"rate_limit_per_minute"`
+	// Window of recorded traffic to replay; defaults to the last 24 hours
+	Since time.Time `json:"since,omitempty"`
+	Until time.Time `json:"until,omitempty"`
+}
+
+// RateLimitSimulationReport compares the current and the proposed limit on the same traffic
+type RateLimitSimulationReport struct {
+	Since    time.Time                      `json:"since"`
+	Until    time.Time                      `json:"until"`
+	Current  middleware.RateLimitSimulation `json:"current"`
+	Proposed middleware.RateLimitSimulation `json:"proposed"`
+	// Truncated is set when the window held more requests than a simulation reads
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ServeHTTP handles POST /admin/simulations/rate-limit. Only audited tool calls are
+// replayed, so other MCP requests counted by the limiter make the real rejections a
+// little higher than reported.
+func (h *RateLimitSimulationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	var req RateLimitSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.RateLimitPerMinute <= 0 {
+		http.Error(w, "rate_limit_per_minute must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.Until.IsZero() {
+		req.Until = time.Now()
+	}
+	if req.Since.IsZero() {
+		req.Since = req.Until.Add(-24 * time.Hour)
+	}
+	if !req.Since.Before(req.Until) {
+		http.Error(w, "since must be before until", http.StatusBadRequest)
+		return
+	}
+
+	// The window is closed, so offsets stay stable while new calls are recorded
+	const pageSize = 1000
+	var requests []time.Time
+	filter := database.AuditFilter{Since: req.Since, Until: req.Until, Limit: pageSize}
+	truncated := false
+	for {
+		entries, err := h.store.ListAuditEntries(ctx, tenantID, filter)
+		if err != nil {
+			http.Error(w, "Failed to read audit log", http.StatusInternalServerError)
+			return
+		}
+		for _, entry := range entries {
+			requests = append(requests, entry.CreatedAt)
+		}
+		if len(entries) < pageSize {
+			break
+		}
+		if len(requests) >= maxSimulatedRequests {
+			truncated = true
+			break
+		}
+		filter.Offset += pageSize
+	}
+
+	writeJSON(w, http.StatusOK, RateLimitSimulationReport{
+		Since:     req.Since,
+		Until:     req.Until,
+		Current:   middleware.SimulateRateLimit(requests, h.current(ctx, tenantID)),
+		Proposed:  middleware.SimulateRateLimit(requests, req.RateLimitPerMinute),
+		Truncated: truncated,
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// simulationRequest is an admin request posting the body
+func simulationRequest(body string, scopes ...string) *http.Request {
+	req := adminRequest(RateLimitSimulationPath, scopes...)
+	return httptest.NewRequest(http.MethodPost, RateLimitSimulationPath, strings.NewReader(body)).WithContext(req.Context())
+}
+
+func TestRateLimitSimulationHandler_ComparesLimits(t *testing.T) {
+	since := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+
+	var entries []database.AuditEntry
+	for i := 0; i < 4; i++ {
+		entries = append(entries, database.AuditEntry{CreatedAt: since.Add(time.Duration(i) * time.Second)})
+	}
+	store := new(MockAuditLogStore)
+	store.On("ListAuditEntries", mock.Anything, "tenant-123",
+		database.AuditFilter{Since: since, Until: until, Limit: 1000}).Return(entries, nil)
+	current := func(ctx context.Context, tenantID string) int { return 10 }
+
+	body := `{"rate_limit_per_minute":2,"since":"2024-01-01T10:00:00Z","until":"2024-01-01T11:00:00Z"}`
+	rr := httptest.NewRecorder()
+	NewRateLimitSimulationHandler(store, current).ServeHTTP(rr, simulationRequest(body, AdminScope))
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var report RateLimitSimulationReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 10, report.Current.LimitPerMinute)
+	assert.Zero(t, report.Current.Rejected)
+	assert.Equal(t, 2, report.Proposed.LimitPerMinute)
+	assert.Equal(t, 2, report.Proposed.Rejected)
+	assert.False(t, report.Truncated)
+	store.AssertExpectations(t)
+}
+
+func TestRateLimitSimulationHandler_Errors(t *testing.T) {
+	current := func(ctx context.Context, tenantID string) int { return 10 }
+	handler := NewRateLimitSimulationHandler(new(MockAuditLogStore), current)
+
+	unauthenticated := httptest.NewRequest(http.MethodPost, RateLimitSimulationPath, strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, unauthenticated)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, simulationRequest(`{"rate_limit_per_minute":5}`))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, simulationRequest(`{"rate_limit_per_minute":0}`, AdminScope))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, simulationRequest(
+		`{"rate_limit_per_minute":5,"since":"2024-01-02T00:00:00Z","until":"2024-01-01T00:00:00Z"}`, AdminScope))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+)
+
+// RolesPath is the admin endpoint prefix for tenant role assignments
+const RolesPath = "/admin/roles"
+
+// RolesHandler manages the role assignments of the calling tenant's users
+type RolesHandler struct {
+	store    auth.RoleStore
+	resolver *auth.RoleResolver
+}
+
+// NewRolesHandler creates a new role assignment admin handler
+func NewRolesHandler(store auth.RoleStore) *RolesHandler {
+	return &RolesHandler{store: store}
+}
+
+// SetRoleResolver makes role changes take effect on the resolver's next lookup
+// instead of after its cache expires
+func (h *RolesHandler) SetRoleResolver(resolver *auth.RoleResolver) {
+	h.resolver = resolver
+}
+
+// roleRequest is the request body for assigning a role
+type roleRequest struct {
+	Role string `json:"role"`
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/roles            list role assignments
+//	GET    /admin/roles/{user_id}  get a user's role assignment
+//	PUT    /admin/roles/{user_id}  assign a role ({"role": "viewer|editor|admin"})
+//	DELETE /admin/roles/{user_id}  revoke a user's role
+func (h *RolesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	userID := strings.Trim(strings.TrimPrefix(r.URL.Path, RolesPath), "/")
+	if userID == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		assignments, err := h.store.ListRoleAssignments(ctx, tenantID)
+		if err != nil {
+			http.Error(w, "Failed to list role assignments", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"assignments": assignments})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		assignment, err := h.store.GetRoleAssignment(ctx, tenantID, userID)
+		if errors.Is(err, auth.ErrRoleNotAssigned) {
+			http.Error(w, "Role not assigned", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to load role assignment", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, assignment)
+	case http.MethodPut:
+		var req roleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		role, err := auth.ParseRole(req.Role)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		assignedBy, _ := auth.ExtractUserID(ctx)
+		assignment := auth.RoleAssignment{
+			TenantID:   tenantID,
+			UserID:     userID,
+			Role:       role,
+			AssignedBy: assignedBy,
+			AssignedAt: time.Now().UTC(),
+		}
+		if err := h.store.AssignRole(ctx, assignment); err != nil {
+			http.Error(w, "Failed to assign role", http.StatusInternalServerError)
+			return
+		}
+		h.invalidate(tenantID, userID)
+		writeJSON(w, http.StatusOK, assignment)
+	case http.MethodDelete:
+		err := h.store.RevokeRole(ctx, tenantID, userID)
+		if errors.Is(err, auth.ErrRoleNotAssigned) {
+			http.Error(w, "Role not assigned", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to revoke role", http.StatusInternalServerError)
+			return
+		}
+		h.invalidate(tenantID, userID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// invalidate drops the resolver's cached role for the user, if a resolver is set
+func (h *RolesHandler) invalidate(tenantID, userID string) {
+	if h.resolver != nil {
+		h.resolver.Invalidate(tenantID, userID)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolesHandler_CRUD(t *testing.T) {
+	store := auth.NewMemoryRoleStore()
+	resolver := auth.NewRoleResolver(store, time.Hour)
+	handler := NewRolesHandler(store)
+	handler.SetRoleResolver(resolver)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, body, AdminScope))
+		return rec
+	}
+
+	// Warm the resolver's cache before the assignment
+	claims := &auth.Claims{TenantID: "tenant-123", UserID: "alice"}
+	resolved, err := resolver.Resolve(context.Background(), claims)
+	require.NoError(t, err)
+	assert.Empty(t, resolved.Scopes)
+
+	rec := serve(http.MethodPut, "/admin/roles/alice", `{"role":"editor"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var assignment auth.RoleAssignment
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &assignment))
+	assert.Equal(t, auth.RoleEditor, assignment.Role)
+	assert.Equal(t, "admin", assignment.AssignedBy)
+
+	// The change is visible without waiting for the cache to expire
+	resolved, err = resolver.Resolve(context.Background(), claims)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"read", "write"}, resolved.Scopes)
+
+	rec = serve(http.MethodPut, "/admin/roles/bob", `{"role":"viewer"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodGet, "/admin/roles", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Assignments []auth.RoleAssignment `json:"assignments"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Assignments, 2)
+	assert.Equal(t, "alice", list.Assignments[0].UserID)
+	assert.Equal(t, auth.RoleViewer, list.Assignments[1].Role)
+
+	rec = serve(http.MethodGet, "/admin/roles/bob", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodDelete, "/admin/roles/alice", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = serve(http.MethodGet, "/admin/roles/alice", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodDelete, "/admin/roles/alice", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	resolved, err = resolver.Resolve(context.Background(), claims)
+	require.NoError(t, err)
+	assert.Empty(t, resolved.Scopes)
+
+	// Assignments are scoped to the caller's tenant
+	_, err = store.GetRoleAssignment(context.Background(), "tenant-456", "bob")
+	assert.ErrorIs(t, err, auth.ErrRoleNotAssigned)
+}
+
+func TestRolesHandler_Errors(t *testing.T) {
+	handler := NewRolesHandler(auth.NewMemoryRoleStore())
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"unauthenticated", httptest.NewRequest(http.MethodGet, "/admin/roles", nil), http.StatusUnauthorized},
+		{"missing admin scope", profileRequest(http.MethodGet, "/admin/roles", "", "read", "write"), http.StatusForbidden},
+		{"invalid body", profileRequest(http.MethodPut, "/admin/roles/alice", "{", AdminScope), http.StatusBadRequest},
+		{"unknown role", profileRequest(http.MethodPut, "/admin/roles/alice", `{"role":"owner"}`, AdminScope), http.StatusBadRequest},
+		{"method not allowed on list", profileRequest(http.MethodPost, "/admin/roles", `{"role":"viewer"}`, AdminScope), http.StatusMethodNotAllowed},
+		{"method not allowed", profileRequest(http.MethodPatch, "/admin/roles/alice", "{}", AdminScope), http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusBadRequest && strings.Contains(tt.name, "role") {
+				assert.Contains(t, rec.Body.String(), "unknown role")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+)
+
+// SafeModePath is the safe mode endpoint
+const SafeModePath = "/admin/safe-mode"
+
+// SafeModeHandler reads and toggles the server-wide safe mode
+type SafeModeHandler struct {
+	safeMode *safemode.Switch
+}
+
+// NewSafeModeHandler creates a new safe mode handler
+func NewSafeModeHandler(safeMode *safemode.Switch) *SafeModeHandler {
+	return &SafeModeHandler{safeMode: safeMode}
+}
+
+// SafeModeRequest is the request body for changing safe mode
+type SafeModeRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// ServeHTTP handles
+//
+//	GET /admin/safe-mode  the current state (admin or operator scope)
+//	PUT /admin/safe-mode  turn safe mode on or off ({"enabled", "reason"}; operator scope)
+//
+// Safe mode applies to every tenant, so changing it takes the operator scope no tenant
+// role grants.
+func (h *SafeModeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := auth.ExtractTenantID(ctx); err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !auth.HasScope(ctx, AdminScope) && !auth.HasScope(ctx, auth.ScopeOperator) {
+			http.Error(w, "Admin scope required", http.StatusForbidden)
+			return
+		}
+		writeJSON(w, http.StatusOK, h.safeMode.State())
+
+	case http.MethodPut:
+		if !auth.HasScope(ctx, auth.ScopeOperator) {
+			http.Error(w, "Operator scope required", http.StatusForbidden)
+			return
+		}
+		var req SafeModeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+			http.Error(w, "Invalid request body: enabled is required", http.StatusBadRequest)
+			return
+		}
+		if *req.Enabled && req.Reason == "" {
+			http.Error(w, "reason is required to enable safe mode", http.StatusBadRequest)
+			return
+		}
+
+		userID, _ := auth.ExtractUserID(ctx)
+		state, err := h.safeMode.Set(ctx, *req.Enabled, req.Reason, userID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to change safe mode", "error", err)
+			http.Error(w, "Failed to persist safe mode state", http.StatusInternalServerError)
+			return
+		}
+		slog.WarnContext(ctx, "Safe mode changed", "enabled", state.Enabled, "reason", state.Reason, "updated_by", state.UpdatedBy)
+		writeJSON(w, http.StatusOK, state)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// safeModeRequest builds an authenticated PUT /admin/safe-mode request
+func safeModeRequest(body string, scopes ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, SafeModePath, strings.NewReader(body))
+	return req.WithContext(adminRequest(SafeModePath, scopes...).Context())
+}
+
+func TestSafeModeHandler(t *testing.T) {
+	safeMode, err := safemode.New(context.Background(), safemode.NewMemoryStore())
+	require.NoError(t, err)
+	handler := NewSafeModeHandler(safeMode)
+
+	// Tenant admins can read the state but not change it
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminRequest(SafeModePath, AdminScope))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, safeModeRequest(`{"enabled":true,"reason":"incident"}`, AdminScope))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.False(t, safeMode.Enabled())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, safeModeRequest(`{"enabled":true}`, auth.ScopeOperator))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "enabling needs a reason")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, safeModeRequest(`{"enabled":true,"reason":"incident"}`, auth.ScopeOperator))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var state safemode.State
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&state))
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "incident", state.Reason)
+	assert.Equal(t, "auditor", state.UpdatedBy)
+	assert.True(t, safeMode.Enabled())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, safeModeRequest(`{"enabled":false}`, auth.ScopeOperator))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, safeMode.Enabled())
+}
+
+func TestSafeModeHandler_RequiresAuth(t *testing.T) {
+	safeMode, err := safemode.New(context.Background(), safemode.NewMemoryStore())
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	NewSafeModeHandler(safeMode).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, SafeModePath, nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
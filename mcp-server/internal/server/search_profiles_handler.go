@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+)
+
+// SearchProfilesPath is the admin endpoint prefix for tenant search profiles
+const SearchProfilesPath = "/admin/search-profiles"
+
+// SearchProfilesHandler manages the calling tenant's search profiles
+type SearchProfilesHandler struct {
+	store profiles.Store
+}
+
+// NewSearchProfilesHandler creates a new search profile admin handler
+func NewSearchProfilesHandler(store profiles.Store) *SearchProfilesHandler {
+	return &SearchProfilesHandler{store: store}
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/search-profiles         list profiles
+//	POST   /admin/search-profiles         create a profile
+//	GET    /admin/search-profiles/{name}  get a profile
+//	PUT    /admin/search-profiles/{name}  create or replace a profile
+//	DELETE /admin/search-profiles/{name}  delete a profile
+func (h *SearchProfilesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, SearchProfilesPath), "/")
+	if name == "" {
+		switch r.Method {
+		case http.MethodGet:
+			list, err := h.store.ListProfiles(ctx, tenantID)
+			if err != nil {
+				http.Error(w, "Failed to list search profiles", http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"profiles": list})
+		case http.MethodPost:
+			profile, ok := decodeProfile(w, r, "")
+			if !ok {
+				return
+			}
+			if _, err := h.store.GetProfile(ctx, tenantID, profile.Name); err == nil {
+				http.Error(w, "Search profile already exists", http.StatusConflict)
+				return
+			} else if !errors.Is(err, profiles.ErrNotFound) {
+				http.Error(w, "Failed to load search profile", http.StatusInternalServerError)
+				return
+			}
+			if err := h.store.PutProfile(ctx, tenantID, profile); err != nil {
+				http.Error(w, "Failed to save search profile", http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusCreated, profile)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if err := profiles.ValidateName(name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := h.store.GetProfile(ctx, tenantID, name)
+		if errors.Is(err, profiles.ErrNotFound) {
+			http.Error(w, "Search profile not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to load search profile", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, profile)
+	case http.MethodPut:
+		profile, ok := decodeProfile(w, r, name)
+		if !ok {
+			return
+		}
+		if err := h.store.PutProfile(ctx, tenantID, profile); err != nil {
+			http.Error(w, "Failed to save search profile", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, profile)
+	case http.MethodDelete:
+		err := h.store.DeleteProfile(ctx, tenantID, name)
+		if errors.Is(err, profiles.ErrNotFound) {
+			http.Error(w, "Search profile not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to delete search profile", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeProfile reads and validates a profile from the request body. A name in the
+// URL path takes precedence; a conflicting name in the body is rejected.
+func decodeProfile(w http.ResponseWriter, r *http.Request, name string) (profiles.Profile, bool) {
+	var profile profiles.Profile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return profile, false
+	}
+	if name != "" {
+		if profile.Name != "" && profile.Name != name {
+			http.Error(w, "Profile name does not match the URL", http.StatusBadRequest)
+			return profile, false
+		}
+		profile.Name = name
+	}
+	if err := profile.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return profile, false
+	}
+	return profile, true
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func profileRequest(method, target, body string, scopes ...string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := auth.WithAuth(req.Context(), &auth.Claims{
+		TenantID: "tenant-123",
+		UserID:   "admin",
+		Scopes:   scopes,
+	})
+	return req.WithContext(ctx)
+}
+
+func TestSearchProfilesHandler_CRUD(t *testing.T) {
+	store := profiles.NewMemoryStore()
+	handler := NewSearchProfilesHandler(store)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, body, AdminScope))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/admin/search-profiles", `{"name":"support-kb","limit":5,"filters":{"category":"support"}}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = serve(http.MethodPost, "/admin/search-profiles", `{"name":"support-kb"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve(http.MethodPut, "/admin/search-profiles/legal", `{"bm25_weight":0.8,"vector_weight":0.2}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = serve(http.MethodGet, "/admin/search-profiles", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Profiles []profiles.Profile `json:"profiles"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Profiles, 2)
+	assert.Equal(t, "legal", list.Profiles[0].Name)
+	assert.Equal(t, 0.8, *list.Profiles[0].BM25Weight)
+
+	rec = serve(http.MethodGet, "/admin/search-profiles/support-kb", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var profile profiles.Profile
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &profile))
+	assert.Equal(t, 5, profile.Limit)
+
+	rec = serve(http.MethodDelete, "/admin/search-profiles/support-kb", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = serve(http.MethodGet, "/admin/search-profiles/support-kb", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(http.MethodDelete, "/admin/search-profiles/support-kb", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Profiles are scoped to the caller's tenant
+	_, err := store.GetProfile(context.Background(), "tenant-123", "legal")
+	assert.NoError(t, err)
+	_, err = store.GetProfile(context.Background(), "tenant-456", "legal")
+	assert.ErrorIs(t, err, profiles.ErrNotFound)
+}
+
+func TestSearchProfilesHandler_Errors(t *testing.T) {
+	handler := NewSearchProfilesHandler(profiles.NewMemoryStore())
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"unauthenticated", httptest.NewRequest(http.MethodGet, "/admin/search-profiles", nil), http.StatusUnauthorized},
+		{"missing admin scope", profileRequest(http.MethodGet, "/admin/search-profiles", "", "read"), http.StatusForbidden},
+		{"invalid body", profileRequest(http.MethodPost, "/admin/search-profiles", "{", AdminScope), http.StatusBadRequest},
+		{"invalid settings", profileRequest(http.MethodPut, "/admin/search-profiles/p", `{"fusion":"borda"}`, AdminScope), http.StatusBadRequest},
+		{"name mismatch", profileRequest(http.MethodPut, "/admin/search-profiles/p", `{"name":"q"}`, AdminScope), http.StatusBadRequest},
+		{"invalid name", profileRequest(http.MethodGet, "/admin/search-profiles/Bad%20Name", "", AdminScope), http.StatusBadRequest},
+		{"method not allowed", profileRequest(http.MethodPatch, "/admin/search-profiles/p", "{}", AdminScope), http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
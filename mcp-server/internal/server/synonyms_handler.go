@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
+)
+
+// SynonymsPath is the admin endpoint for the tenant's synonym dictionary
+const SynonymsPath = "/admin/synonyms"
+
+// SynonymsHandler manages the calling tenant's synonym dictionary
+type SynonymsHandler struct {
+	store synonyms.Store
+}
+
+// NewSynonymsHandler creates a new synonym dictionary admin handler
+func NewSynonymsHandler(store synonyms.Store) *SynonymsHandler {
+	return &SynonymsHandler{store: store}
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/synonyms  get the dictionary
+//	PUT    /admin/synonyms  replace the dictionary: {"synonyms": {"car": ["automobile"]}}
+//	DELETE /admin/synonyms  remove the dictionary
+func (h *SynonymsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		dictionary, err := h.store.GetSynonyms(ctx, tenantID)
+		if err != nil {
+			http.Error(w, "Failed to load synonyms", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"synonyms": dictionary})
+	case http.MethodPut:
+		var body struct {
+			Synonyms synonyms.Dictionary `json:"synonyms"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := body.Synonyms.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body.Synonyms == nil {
+			body.Synonyms = synonyms.Dictionary{}
+		}
+		if err := h.store.PutSynonyms(ctx, tenantID, body.Synonyms); err != nil {
+			http.Error(w, "Failed to save synonyms", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"synonyms": body.Synonyms})
+	case http.MethodDelete:
+		if err := h.store.PutSynonyms(ctx, tenantID, nil); err != nil {
+			http.Error(w, "Failed to delete synonyms", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynonymsHandler(t *testing.T) {
+	store := synonyms.NewMemoryStore()
+	handler := NewSynonymsHandler(store)
+
+	serve := func(method, body string, scopes ...string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, SynonymsPath, body, scopes...))
+		return rec
+	}
+
+	rec := serve(http.MethodPut, `{"synonyms":{"car":["automobile","motor vehicle"]}}`, AdminScope)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = serve(http.MethodGet, "", AdminScope)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Synonyms synonyms.Dictionary `json:"synonyms"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, synonyms.Dictionary{"car": {"automobile", "motor vehicle"}}, body.Synonyms)
+
+	rec = serve(http.MethodPut, `{"synonyms":{"the":["a"]}}`, AdminScope)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(http.MethodPut, `{"synonyms":`, AdminScope)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(http.MethodGet, "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serve(http.MethodPost, "{}", AdminScope)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = serve(http.MethodDelete, "", AdminScope)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	dictionary, err := store.GetSynonyms(context.Background(), "tenant-123")
+	require.NoError(t, err)
+	assert.Empty(t, dictionary)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+)
+
+// ToolsPath is the admin endpoint prefix for the built-in tools
+const ToolsPath = "/admin/tools"
+
+// ToolsHandler withdraws built-in tools from the registry and restores them at runtime.
+// Withdrawn tools are kept by the handler only, so a restart registers them again.
+type ToolsHandler struct {
+	registry *tools.Registry
+
+	mu        sync.Mutex
+	withdrawn map[string]tools.Tool
+}
+
+// NewToolsHandler creates a new built-in tool admin handler
+func NewToolsHandler(registry *tools.Registry) *ToolsHandler {
+	return &ToolsHandler{registry: registry, withdrawn: make(map[string]tools.Tool)}
+}
+
+// ToolStatus describes a built-in tool and whether it is registered
+type ToolStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Registered  bool   `json:"registered"`
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/tools         list built-in tools, including withdrawn ones (admin or operator scope)
+//	DELETE /admin/tools/{name}  withdraw a tool (operator scope)
+//	PUT    /admin/tools/{name}  restore a withdrawn tool (operator scope)
+//
+// Built-in tools are shared by every tenant, so changing them takes the operator scope.
+// Clients with an open session stream get notifications/tools/list_changed.
+func (h *ToolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := auth.ExtractTenantID(ctx); err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, ToolsPath), "/")
+	if name == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !auth.HasScope(ctx, AdminScope) && !auth.HasScope(ctx, auth.ScopeOperator) {
+			http.Error(w, "Admin scope required", http.StatusForbidden)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tools": h.list()})
+		return
+	}
+
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !auth.HasScope(ctx, auth.ScopeOperator) {
+		http.Error(w, "Operator scope required", http.StatusForbidden)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	userID, _ := auth.ExtractUserID(ctx)
+
+	if r.Method == http.MethodDelete {
+		tool, ok := h.registry.Unregister(name)
+		if !ok {
+			http.Error(w, "Tool not found", http.StatusNotFound)
+			return
+		}
+		h.withdrawn[name] = tool
+		slog.WarnContext(ctx, "Tool withdrawn", "tool", name, "updated_by", userID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tool, ok := h.withdrawn[name]
+	if !ok {
+		if _, registered := h.registry.Get(name); registered {
+			http.Error(w, "Tool is already registered", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Tool not found", http.StatusNotFound)
+		return
+	}
+	delete(h.withdrawn, name)
+	h.registry.Register(tool)
+	slog.WarnContext(ctx, "Tool restored", "tool", name, "updated_by", userID)
+	def := tool.Definition()
+	writeJSON(w, http.StatusOK, ToolStatus{Name: def.Name, Description: def.Description, Registered: true})
+}
+
+// list returns the registered and withdrawn tools, ordered by name
+func (h *ToolsHandler) list() []ToolStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	statuses := []ToolStatus{}
+	for _, def := range h.registry.List() {
+		statuses = append(statuses, ToolStatus{Name: def.Name, Description: def.Description, Registered: true})
+	}
+	for _, tool := range h.withdrawn {
+		def := tool.Definition()
+		statuses = append(statuses, ToolStatus{Name: def.Name, Description: def.Description})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolsHandler(t *testing.T) {
+	registry := tools.NewRegistry()
+	store := storage.NewMemoryStore()
+	registry.Register(tools.NewSearchTool(store))
+	registry.Register(tools.NewRetrieveTool(store))
+	var changes []string
+	registry.OnListChanged(func(tenantID string) { changes = append(changes, tenantID) })
+	handler := NewToolsHandler(registry)
+
+	serve := func(method, target string, scopes ...string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, "", scopes...))
+		return rec
+	}
+	list := func() []ToolStatus {
+		rec := serve(http.MethodGet, ToolsPath, AdminScope)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Tools []ToolStatus `json:"tools"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Tools
+	}
+
+	// Tenant admins can list the tools but not change them
+	require.Len(t, list(), 2)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodDelete, ToolsPath+"/search_documents", AdminScope).Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, ToolsPath, "read").Code)
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, ToolsPath+"/search_documents", auth.ScopeOperator).Code)
+	_, ok := registry.Get("search_documents")
+	assert.False(t, ok)
+	statuses := list()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, ToolStatus{Name: "search_documents", Description: statuses[1].Description}, statuses[1])
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, ToolsPath+"/search_documents", auth.ScopeOperator).Code)
+
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPut, ToolsPath+"/retrieve_document", auth.ScopeOperator).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, ToolsPath+"/unknown", auth.ScopeOperator).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, ToolsPath+"/search_documents", auth.ScopeOperator).Code)
+	_, ok = registry.Get("search_documents")
+	assert.True(t, ok)
+	assert.True(t, list()[1].Registered)
+
+	assert.Equal(t, []string{"", ""}, changes)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, ToolsPath, auth.ScopeOperator).Code)
+	assert.Equal(t, http.StatusUnauthorized, func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ToolsPath, nil))
+		return rec.Code
+	}())
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// VectorIndexesPath is the admin endpoint prefix for per-collection vector indexes
+const VectorIndexesPath = "/admin/vector-indexes"
+
+// VectorIndexesHandler manages the vector indexes of the calling tenant's collections
+type VectorIndexesHandler struct {
+	store database.VectorIndexStore
+}
+
+// NewVectorIndexesHandler creates a new vector index admin handler
+func NewVectorIndexesHandler(store database.VectorIndexStore) *VectorIndexesHandler {
+	return &VectorIndexesHandler{store: store}
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/vector-indexes                       list indexes with their stats
+//	GET    /admin/vector-indexes/{collection}          get an index with its stats
+//	PUT    /admin/vector-indexes/{collection}          create or replace an index
+//	POST   /admin/vector-indexes/{collection}/rebuild  rebuild an index
+//	DELETE /admin/vector-indexes/{collection}          drop an index
+//
+// Builds run within the request, so large collections need a generous write timeout.
+func (h *VectorIndexesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, VectorIndexesPath), "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		indexes, err := h.store.ListVectorIndexes(ctx, tenantID)
+		if err != nil {
+			http.Error(w, "Failed to list vector indexes", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"indexes": indexes})
+		return
+	}
+
+	collection, action, _ := strings.Cut(path, "/")
+	if err := storage.ValidateCollection(collection); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		index, err := h.store.GetVectorIndex(ctx, tenantID, collection)
+		if !h.checkStoreError(w, r, err, "Failed to load vector index") {
+			return
+		}
+		writeJSON(w, http.StatusOK, index)
+	case action == "" && r.Method == http.MethodPut:
+		var spec database.VectorIndexSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := spec.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		index, err := h.store.PutVectorIndex(ctx, tenantID, collection, spec)
+		if !h.checkStoreError(w, r, err, "Failed to build vector index") {
+			return
+		}
+		slog.InfoContext(ctx, "Vector index built", "tenant_id", tenantID, "collection", collection, "method", spec.Method)
+		writeJSON(w, http.StatusOK, index)
+	case action == "" && r.Method == http.MethodDelete:
+		if !h.checkStoreError(w, r, h.store.DropVectorIndex(ctx, tenantID, collection), "Failed to drop vector index") {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "rebuild" && r.Method == http.MethodPost:
+		index, err := h.store.RebuildVectorIndex(ctx, tenantID, collection)
+		if !h.checkStoreError(w, r, err, "Failed to rebuild vector index") {
+			return
+		}
+		slog.InfoContext(ctx, "Vector index rebuilt", "tenant_id", tenantID, "collection", collection)
+		writeJSON(w, http.StatusOK, index)
+	case action == "" || action == "rebuild":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// checkStoreError writes the response for a failed store call and reports whether the call succeeded
+func (h *VectorIndexesHandler) checkStoreError(w http.ResponseWriter, r *http.Request, err error, message string) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Vector index not found", http.StatusNotFound)
+		return false
+	}
+	slog.ErrorContext(r.Context(), message, "error", err)
+	http.Error(w, message, http.StatusInternalServerError)
+	return false
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVectorIndexStore keeps index specs in memory, counting rebuilds as scans
+type fakeVectorIndexStore struct {
+	indexes map[string]database.VectorIndex
+}
+
+func (f *fakeVectorIndexStore) ListVectorIndexes(ctx context.Context, tenantID string) ([]database.VectorIndex, error) {
+	list := []database.VectorIndex{}
+	for _, index := range f.indexes {
+		list = append(list, index)
+	}
+	return list, nil
+}
+
+func (f *fakeVectorIndexStore) GetVectorIndex(ctx context.Context, tenantID, collection string) (*database.VectorIndex, error) {
+	index, ok := f.indexes[collection]
+	if !ok {
+		return nil, fmt.Errorf("%w: collection %q has no vector index", storage.ErrNotFound, collection)
+	}
+	return &index, nil
+}
+
+func (f *fakeVectorIndexStore) PutVectorIndex(ctx context.Context, tenantID, collection string, spec database.VectorIndexSpec) (*database.VectorIndex, error) {
+	f.indexes[collection] = database.VectorIndex{Collection: collection, Name: "idx_" + collection, Spec: spec, Exists: true, Valid: true}
+	return f.GetVectorIndex(ctx, tenantID, collection)
+}
+
+func (f *fakeVectorIndexStore) RebuildVectorIndex(ctx context.Context, tenantID, collection string) (*database.VectorIndex, error) {
+	index, err := f.GetVectorIndex(ctx, tenantID, collection)
+	if err != nil {
+		return nil, err
+	}
+	index.Scans++
+	f.indexes[collection] = *index
+	return index, nil
+}
+
+func (f *fakeVectorIndexStore) DropVectorIndex(ctx context.Context, tenantID, collection string) error {
+	if _, err := f.GetVectorIndex(ctx, tenantID, collection); err != nil {
+		return err
+	}
+	delete(f.indexes, collection)
+	return nil
+}
+
+func TestVectorIndexesHandler(t *testing.T) {
+	store := &fakeVectorIndexStore{indexes: make(map[string]database.VectorIndex)}
+	handler := NewVectorIndexesHandler(store)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, body, AdminScope))
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/admin/vector-indexes/policies", `{"method":"hnsw","m":32}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var index database.VectorIndex
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &index))
+	assert.Equal(t, "policies", index.Collection)
+	assert.Equal(t, database.VectorIndexSpec{Method: "hnsw", M: 32}, index.Spec)
+
+	rec = serve(http.MethodPut, "/admin/vector-indexes/policies", `{"method":"ivfflat","m":32}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "m and ef_construction only apply to hnsw indexes")
+	rec = serve(http.MethodPut, "/admin/vector-indexes/Bad%20Name", `{"method":"hnsw"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(http.MethodGet, "/admin/vector-indexes", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Indexes []database.VectorIndex `json:"indexes"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Indexes, 1)
+
+	rec = serve(http.MethodPost, "/admin/vector-indexes/policies/rebuild", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &index))
+	assert.Equal(t, int64(1), index.Scans)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/admin/vector-indexes/policies/rebuild", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/admin/vector-indexes/policies/analyze", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/admin/vector-indexes/policies", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/admin/vector-indexes/policies", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/admin/vector-indexes/policies/rebuild", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/admin/vector-indexes/policies", "").Code)
+}
+
+func TestVectorIndexesHandler_RequiresAdminScope(t *testing.T) {
+	handler := NewVectorIndexesHandler(&fakeVectorIndexStore{indexes: make(map[string]database.VectorIndex)})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, profileRequest(http.MethodGet, "/admin/vector-indexes", "", "read"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/vector-indexes", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
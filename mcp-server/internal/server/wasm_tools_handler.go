@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+)
+
+// WASMToolsPath is the admin endpoint prefix for tenant WASM tools
+const WASMToolsPath = "/admin/wasm-tools"
+
+// wasmUploadOverhead leaves room for the JSON fields around the base64 module
+const wasmUploadOverhead = 64 << 10
+
+// WASMToolsHandler manages the WASM tools of the calling tenant
+type WASMToolsHandler struct {
+	store    *tools.WASMTools
+	registry *tools.Registry
+}
+
+// NewWASMToolsHandler creates a new WASM tool admin handler. Uploads may not reuse the
+// name of a tool in registry.
+func NewWASMToolsHandler(store *tools.WASMTools, registry *tools.Registry) *WASMToolsHandler {
+	return &WASMToolsHandler{store: store, registry: registry}
+}
+
+// wasmToolRequest is the request body for uploading a WASM tool
+type wasmToolRequest struct {
+	tools.WASMToolSpec
+	// Module is the base64-encoded WASM binary
+	Module []byte `json:"module"`
+}
+
+// ServeHTTP handles
+//
+//	GET    /admin/wasm-tools         list the tenant's WASM tools
+//	POST   /admin/wasm-tools         upload a tool ({"name", "description", "input_schema", "module": base64})
+//	GET    /admin/wasm-tools/{name}  get a WASM tool
+//	DELETE /admin/wasm-tools/{name}  remove a WASM tool
+func (h *WASMToolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasScope(ctx, AdminScope) {
+		http.Error(w, "Admin scope required", http.StatusForbidden)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, WASMToolsPath), "/")
+	if name == "" {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"tools": h.store.List(tenantID)})
+		case http.MethodPost:
+			h.upload(w, r, tenantID)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		info, err := h.store.Get(tenantID, name)
+		if errors.Is(err, tools.ErrWASMToolNotFound) {
+			http.Error(w, "WASM tool not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	case http.MethodDelete:
+		if err := h.store.Remove(ctx, tenantID, name); errors.Is(err, tools.ErrWASMToolNotFound) {
+			http.Error(w, "WASM tool not found", http.StatusNotFound)
+			return
+		}
+		h.registry.TenantToolsChanged(tenantID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// upload compiles and registers a WASM tool for the tenant
+func (h *WASMToolsHandler) upload(w http.ResponseWriter, r *http.Request, tenantID string) {
+	if limit := h.store.Limits().MaxModuleBytes; limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(limit)*4/3+wasmUploadOverhead)
+	}
+
+	var req wasmToolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Module too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, ok := h.registry.Get(req.Name); ok {
+		http.Error(w, "Tool name is reserved by a built-in tool", http.StatusConflict)
+		return
+	}
+
+	registeredBy, _ := auth.ExtractUserID(r.Context())
+	info, err := h.store.Register(r.Context(), tenantID, registeredBy, req.WASMToolSpec, req.Module)
+	switch {
+	case errors.Is(err, tools.ErrWASMLimit):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.registry.TenantToolsChanged(tenantID)
+	writeJSON(w, http.StatusCreated, info)
+}
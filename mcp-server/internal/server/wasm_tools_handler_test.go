@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubWASMRuntime accepts any module starting with the WASM magic bytes
+type stubWASMRuntime struct{}
+
+func (stubWASMRuntime) Compile(ctx context.Context, wasm []byte) (tools.WASMModule, error) {
+	if len(wasm) < 4 || string(wasm[:4]) != "\x00asm" {
+		return nil, tools.ErrWASMABI
+	}
+	return stubWASMModule{}, nil
+}
+
+func (stubWASMRuntime) Close(ctx context.Context) error { return nil }
+
+type stubWASMModule struct{}
+
+func (stubWASMModule) Call(ctx context.Context, input []byte) ([]byte, error) {
+	return []byte(`{"content":[{"type":"text","text":"ok"}]}`), nil
+}
+
+func (stubWASMModule) Close(ctx context.Context) error { return nil }
+
+func wasmUpload(name string, module []byte) string {
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":        name,
+		"description": "Uploaded tool",
+		"module":      base64.StdEncoding.EncodeToString(module),
+	})
+	return string(body)
+}
+
+func TestWASMToolsHandler_CRUD(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewListTool(nil))
+	var changes []string
+	registry.OnListChanged(func(tenantID string) { changes = append(changes, tenantID) })
+	store := tools.NewWASMTools(stubWASMRuntime{}, tools.DefaultWASMLimits())
+	handler := NewWASMToolsHandler(store, registry)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(method, target, body, AdminScope))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/admin/wasm-tools", wasmUpload("word_count", []byte("\x00asm\x01\x00\x00\x00")))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var info tools.WASMToolInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, "word_count", info.Name)
+	assert.Equal(t, "tenant-123", info.TenantID)
+	assert.Equal(t, 8, info.SizeBytes)
+
+	rec = serve(http.MethodGet, "/admin/wasm-tools", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Tools []tools.WASMToolInfo `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Tools, 1)
+
+	rec = serve(http.MethodGet, "/admin/wasm-tools/word_count", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodDelete, "/admin/wasm-tools/word_count", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = serve(http.MethodGet, "/admin/wasm-tools/word_count", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, []string{"tenant-123", "tenant-123"}, changes, "uploads and removals change the tenant's tool list")
+}
+
+func TestWASMToolsHandler_RejectsInvalidUploads(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewListTool(nil))
+	limits := tools.DefaultWASMLimits()
+	limits.MaxModuleBytes = 16
+	handler := NewWASMToolsHandler(tools.NewWASMTools(stubWASMRuntime{}, limits), registry)
+
+	serve := func(body string, scopes ...string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, profileRequest(http.MethodPost, "/admin/wasm-tools", body, scopes...))
+		return rec.Code
+	}
+
+	valid := []byte("\x00asm\x01\x00\x00\x00")
+	assert.Equal(t, http.StatusForbidden, serve(wasmUpload("word_count", valid)))
+	assert.Equal(t, http.StatusConflict, serve(wasmUpload("list_documents", valid), AdminScope))
+	assert.Equal(t, http.StatusBadRequest, serve(wasmUpload("word_count", []byte("not wasm")), AdminScope))
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(wasmUpload("word_count", make([]byte, 32)), AdminScope))
+	assert.Equal(t, http.StatusBadRequest, serve("{", AdminScope))
+}
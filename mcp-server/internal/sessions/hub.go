@@ -0,0 +1,259 @@
+// Package sessions keeps MCP client sessions and delivers server notifications to
+// their event streams
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// Header carries the session ID returned by initialize on later requests and streams
+const Header = "Mcp-Session-Id"
+
+// Session defaults
+const (
+	DefaultIdleTimeout    = 30 * time.Minute
+	DefaultBufferSize     = 64
+	DefaultRequestTimeout = 20 * time.Second
+)
+
+var (
+	// ErrNotFound is returned for unknown and expired sessions, and for sessions of another tenant
+	ErrNotFound = errors.New("session not found")
+	// ErrStreamOpen is returned when a session already has an open event stream
+	ErrStreamOpen = errors.New("session already has an open event stream")
+)
+
+// Config holds session settings
+type Config struct {
+	// IdleTimeout drops sessions without an open stream that saw no request for this long
+	IdleTimeout time.Duration
+	// BufferSize is the number of notifications held for a session; notifications are
+	// dropped while the buffer is full, e.g. when no stream is open
+	BufferSize int
+	// RequestTimeout bounds requests the server sends to the client, such as
+	// sampling/createMessage
+	RequestTimeout time.Duration
+}
+
+// Session is a client session bound to the tenant that created it
+type Session struct {
+	ID       string
+	TenantID string
+
+	notifications  chan *protocol.Request
+	requestTimeout time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]struct{}
+	lastSeen      time.Time
+	streaming     bool
+	capabilities  protocol.ClientCapabilities
+	// pending holds the requests sent to the client that await a response, by request ID
+	pending   map[string]chan clientResponse
+	requestID uint64
+}
+
+// Subscribe adds a resource URI to the session's subscriptions
+func (s *Session) Subscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[uri] = struct{}{}
+}
+
+// Unsubscribe removes a resource URI from the session's subscriptions
+func (s *Session) Unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, uri)
+}
+
+// Subscribed reports whether the session is subscribed to uri
+func (s *Session) Subscribed(uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subscriptions[uri]
+	return ok
+}
+
+// Stream claims the session's notifications for one event stream. Call release
+// when the stream ends so the client can reconnect.
+func (s *Session) Stream() (notifications <-chan *protocol.Request, release func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streaming {
+		return nil, nil, ErrStreamOpen
+	}
+	s.streaming = true
+	return s.notifications, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.streaming = false
+		s.lastSeen = time.Now()
+	}, nil
+}
+
+// notify queues a notification without blocking and reports whether it was queued
+func (s *Session) notify(notification *protocol.Request) bool {
+	select {
+	case s.notifications <- notification:
+		return true
+	default:
+		return false
+	}
+}
+
+// idle reports whether the session has no open stream and saw no request since before
+func (s *Session) idle(before time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.streaming && s.lastSeen.Before(before)
+}
+
+// Hub tracks the sessions of connected clients. Sessions live in memory, so a client
+// reconnecting to another server instance has to initialize again.
+type Hub struct {
+	config Config
+
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewHub creates a session hub
+func NewHub(config Config) *Hub {
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultBufferSize
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	return &Hub{config: config, sessions: make(map[string]*Session)}
+}
+
+// Create starts a session for a tenant, dropping idle sessions on the way
+func (h *Hub) Create(tenantID string) *Session {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	session := &Session{
+		ID:             hex.EncodeToString(id),
+		TenantID:       tenantID,
+		notifications:  make(chan *protocol.Request, h.config.BufferSize),
+		requestTimeout: h.config.RequestTimeout,
+		subscriptions:  make(map[string]struct{}),
+		pending:        make(map[string]chan clientResponse),
+		lastSeen:       time.Now(),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expireLocked()
+	h.sessions[session.ID] = session
+	return session
+}
+
+// Get returns the tenant's session with the given ID and marks it as active
+func (h *Hub) Get(id, tenantID string) (*Session, error) {
+	h.mu.RLock()
+	session, ok := h.sessions[id]
+	h.mu.RUnlock()
+	if !ok || session.TenantID != tenantID || session.idle(time.Now().Add(-h.config.IdleTimeout)) {
+		return nil, ErrNotFound
+	}
+
+	session.mu.Lock()
+	session.lastSeen = time.Now()
+	session.mu.Unlock()
+	return session, nil
+}
+
+// Delete ends the tenant's session with the given ID
+func (h *Hub) Delete(id, tenantID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	session, ok := h.sessions[id]
+	if !ok || session.TenantID != tenantID {
+		return ErrNotFound
+	}
+	delete(h.sessions, id)
+	return nil
+}
+
+// Len returns the number of sessions
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.sessions)
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated for each of the URIs to the
+// tenant's sessions subscribed to it, and returns the number of notifications queued
+func (h *Hub) NotifyResourceUpdated(tenantID string, uris ...string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	queued := 0
+	for _, session := range h.sessions {
+		if session.TenantID != tenantID {
+			continue
+		}
+		for _, uri := range uris {
+			if !session.Subscribed(uri) {
+				continue
+			}
+			notification, err := protocol.NewRequest(nil, protocol.MethodResourcesUpdated, protocol.ResourceUpdatedNotification{URI: uri})
+			if err != nil {
+				continue
+			}
+			if !session.notify(notification) {
+				slog.Warn("Dropped resource notification, session buffer full", "tenant_id", tenantID, "uri", uri)
+				continue
+			}
+			queued++
+		}
+	}
+	return queued
+}
+
+// NotifyToolsListChanged sends notifications/tools/list_changed to the tenant's sessions,
+// or to every session when tenantID is "", and returns the number of notifications queued
+func (h *Hub) NotifyToolsListChanged(tenantID string) int {
+	notification, err := protocol.NewRequest(nil, protocol.MethodToolsListChanged, nil)
+	if err != nil {
+		return 0
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	queued := 0
+	for _, session := range h.sessions {
+		if tenantID != "" && session.TenantID != tenantID {
+			continue
+		}
+		if !session.notify(notification) {
+			slog.Warn("Dropped tools list notification, session buffer full", "tenant_id", session.TenantID)
+			continue
+		}
+		queued++
+	}
+	return queued
+}
+
+// expireLocked drops idle sessions; h.mu must be held
+func (h *Hub) expireLocked() {
+	before := time.Now().Add(-h.config.IdleTimeout)
+	for id, session := range h.sessions {
+		if session.idle(before) {
+			delete(h.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package sessions
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_SessionsAreBoundToTenant(t *testing.T) {
+	hub := NewHub(Config{})
+	session := hub.Create("tenant-a")
+	assert.Len(t, session.ID, 32)
+
+	got, err := hub.Get(session.ID, "tenant-a")
+	require.NoError(t, err)
+	assert.Same(t, session, got)
+
+	_, err = hub.Get(session.ID, "tenant-b")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, hub.Delete(session.ID, "tenant-b"), ErrNotFound)
+
+	require.NoError(t, hub.Delete(session.ID, "tenant-a"))
+	_, err = hub.Get(session.ID, "tenant-a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestHub_NotifyResourceUpdated(t *testing.T) {
+	hub := NewHub(Config{BufferSize: 1})
+	watcher := hub.Create("tenant-a")
+	watcher.Subscribe("documents://doc-1")
+	other := hub.Create("tenant-b")
+	other.Subscribe("documents://doc-1")
+	hub.Create("tenant-a") // not subscribed
+
+	assert.Equal(t, 1, hub.NotifyResourceUpdated("tenant-a", "documents://doc-1", "documents-summary://doc-1"))
+	// The buffer holds one notification; later ones are dropped until the stream reads
+	assert.Equal(t, 0, hub.NotifyResourceUpdated("tenant-a", "documents://doc-1"))
+
+	notifications, release, err := watcher.Stream()
+	require.NoError(t, err)
+	notification := <-notifications
+	assert.Equal(t, protocol.MethodResourcesUpdated, notification.Method)
+	assert.Nil(t, notification.ID)
+	var params protocol.ResourceUpdatedNotification
+	require.NoError(t, json.Unmarshal(notification.Params, &params))
+	assert.Equal(t, "documents://doc-1", params.URI)
+
+	_, _, err = watcher.Stream()
+	assert.ErrorIs(t, err, ErrStreamOpen)
+	release()
+	_, release, err = watcher.Stream()
+	require.NoError(t, err)
+	release()
+
+	watcher.Unsubscribe("documents://doc-1")
+	assert.Equal(t, 0, hub.NotifyResourceUpdated("tenant-a", "documents://doc-1"))
+	assert.Len(t, other.notifications, 0)
+}
+
+func TestHub_ExpiresIdleSessions(t *testing.T) {
+	hub := NewHub(Config{IdleTimeout: 50 * time.Millisecond})
+	idle := hub.Create("tenant-a")
+	streaming := hub.Create("tenant-a")
+	_, release, err := streaming.Stream()
+	require.NoError(t, err)
+	defer release()
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = hub.Get(idle.ID, "tenant-a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	hub.Create("tenant-a")
+	assert.Equal(t, 2, hub.Len(), "idle session dropped, streaming session kept")
+	_, err = hub.Get(streaming.ID, "tenant-a")
+	assert.NoError(t, err)
+}
+
+func TestHub_NotifyToolsListChanged(t *testing.T) {
+	hub := NewHub(Config{})
+	a := hub.Create("tenant-a")
+	b := hub.Create("tenant-b")
+
+	assert.Equal(t, 1, hub.NotifyToolsListChanged("tenant-a"))
+	assert.Len(t, b.notifications, 0)
+	assert.Equal(t, 2, hub.NotifyToolsListChanged(""))
+
+	notification := <-a.notifications
+	assert.Equal(t, protocol.MethodToolsListChanged, notification.Method)
+	assert.Nil(t, notification.ID)
+	assert.Len(t, a.notifications, 1)
+	assert.Len(t, b.notifications, 1)
+}
@@ -0,0 +1,124 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+var (
+	// ErrNoStream is returned for requests to a client that has no open event stream to receive them
+	ErrNoStream = errors.New("session has no open event stream")
+	// ErrSamplingUnsupported is returned for sampling requests to a client that did not
+	// declare the sampling capability in initialize
+	ErrSamplingUnsupported = errors.New("client does not support sampling")
+)
+
+// ClientError is a JSON-RPC error the client answered a server request with
+type ClientError struct {
+	Method  string
+	Code    int
+	Message string
+}
+
+// Error implements the error interface
+func (e *ClientError) Error() string {
+	return fmt.Sprintf("client rejected %s: %s (code %d)", e.Method, e.Message, e.Code)
+}
+
+// clientResponse is the client's answer to a server request
+type clientResponse struct {
+	result json.RawMessage
+	err    *protocol.Error
+}
+
+// SetCapabilities records the capabilities the client declared in initialize
+func (s *Session) SetCapabilities(capabilities protocol.ClientCapabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = capabilities
+}
+
+// SupportsSampling reports whether the client answers sampling/createMessage
+func (s *Session) SupportsSampling() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capabilities.Sampling != nil
+}
+
+// CreateMessage asks the client to sample an LLM completion
+func (s *Session) CreateMessage(ctx context.Context, req protocol.CreateMessageRequest) (*protocol.CreateMessageResult, error) {
+	if !s.SupportsSampling() {
+		return nil, ErrSamplingUnsupported
+	}
+	raw, err := s.Request(ctx, protocol.MethodSamplingCreateMessage, req)
+	if err != nil {
+		return nil, err
+	}
+	var result protocol.CreateMessageResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid %s result: %w", protocol.MethodSamplingCreateMessage, err)
+	}
+	return &result, nil
+}
+
+// Request sends a request to the client over its event stream and waits for the client
+// to POST the response, at most the hub's RequestTimeout
+func (s *Session) Request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	s.mu.Lock()
+	if !s.streaming {
+		s.mu.Unlock()
+		return nil, ErrNoStream
+	}
+	s.requestID++
+	id := fmt.Sprintf("server-%d", s.requestID)
+	responses := make(chan clientResponse, 1)
+	s.pending[id] = responses
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	request, err := protocol.NewRequest(id, method, params)
+	if err != nil {
+		return nil, err
+	}
+	if !s.notify(request) {
+		return nil, fmt.Errorf("%s not sent: session buffer full", method)
+	}
+
+	timer := time.NewTimer(s.requestTimeout)
+	defer timer.Stop()
+	select {
+	case response := <-responses:
+		if response.err != nil {
+			return nil, &ClientError{Method: method, Code: response.err.Code, Message: response.err.Message}
+		}
+		return response.result, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%s: no response from client within %s: %w", method, s.requestTimeout, context.DeadlineExceeded)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Resolve delivers the client's response to the server request with the given ID and
+// reports whether a request was waiting for it
+func (s *Session) Resolve(id string, result json.RawMessage, rpcErr *protocol.Error) bool {
+	s.mu.Lock()
+	responses, ok := s.pending[id]
+	delete(s.pending, id)
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	responses <- clientResponse{result: result, err: rpcErr}
+	return true
+}
@@ -0,0 +1,79 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_CreateMessage(t *testing.T) {
+	hub := NewHub(Config{RequestTimeout: time.Second})
+	session := hub.Create("tenant-a")
+	req := protocol.CreateMessageRequest{
+		Messages:  []protocol.SamplingMessage{{Role: "user", Content: protocol.ContentBlock{Type: "text", Text: "Summarize"}}},
+		MaxTokens: 100,
+	}
+
+	_, err := session.CreateMessage(context.Background(), req)
+	assert.ErrorIs(t, err, ErrSamplingUnsupported)
+
+	session.SetCapabilities(protocol.ClientCapabilities{Sampling: &protocol.SamplingCapability{}})
+	_, err = session.CreateMessage(context.Background(), req)
+	assert.ErrorIs(t, err, ErrNoStream, "requests need a stream to reach the client")
+
+	requests, release, err := session.Stream()
+	require.NoError(t, err)
+	defer release()
+
+	// The client reads the request from its stream and POSTs the response
+	go func() {
+		request := <-requests
+		assert.Equal(t, protocol.MethodSamplingCreateMessage, request.Method)
+		var params protocol.CreateMessageRequest
+		assert.NoError(t, json.Unmarshal(request.Params, &params))
+		assert.Equal(t, 100, params.MaxTokens)
+		assert.True(t, session.Resolve(request.ID.(string), json.RawMessage(`{"role":"assistant","content":{"type":"text","text":"Short."},"model":"test-model"}`), nil))
+	}()
+	result, err := session.CreateMessage(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "Short.", result.Content.Text)
+	assert.Equal(t, "test-model", result.Model)
+
+	// The client refuses
+	go func() {
+		request := <-requests
+		session.Resolve(request.ID.(string), nil, &protocol.Error{Code: -1, Message: "User rejected sampling request"})
+	}()
+	_, err = session.CreateMessage(context.Background(), req)
+	var clientErr *ClientError
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, "User rejected sampling request", clientErr.Message)
+
+	// Responses to unknown or answered requests are dropped
+	assert.False(t, session.Resolve("server-1", json.RawMessage(`{}`), nil))
+}
+
+func TestSession_RequestTimeout(t *testing.T) {
+	hub := NewHub(Config{RequestTimeout: 20 * time.Millisecond})
+	session := hub.Create("tenant-a")
+	requests, release, err := session.Stream()
+	require.NoError(t, err)
+	defer release()
+
+	_, err = session.Request(context.Background(), protocol.MethodSamplingCreateMessage, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A late response finds no waiting request
+	request := <-requests
+	assert.False(t, session.Resolve(request.ID.(string), json.RawMessage(`{}`), nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = session.Request(ctx, protocol.MethodSamplingCreateMessage, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
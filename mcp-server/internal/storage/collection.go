@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultCollection holds a tenant's documents that were not put in a named collection
+const DefaultCollection = "default"
+
+// ErrInvalidCollection is returned for collection names that are not allowed
+var ErrInvalidCollection = errors.New("invalid collection name")
+
+// ErrQuotaExceeded is returned, wrapped in a *QuotaError, when a document does not fit
+// its tenant's or collection's quota
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrEmbeddingDimensions is returned when an embedding's size differs from the dimensions
+// registered for its collection
+var ErrEmbeddingDimensions = errors.New("embedding dimensions do not match the collection")
+
+// ErrCollectionNotEmpty is returned when deleting a collection that still holds documents
+var ErrCollectionNotEmpty = errors.New("collection is not empty")
+
+// MaxEmbeddingDimensions is the largest vector pgvector stores
+const MaxEmbeddingDimensions = 16000
+
+// Collection describes a named corpus of a tenant. Collections come into existence with
+// their first document; registering one records which embedding model its documents and
+// queries must be embedded with.
+type Collection struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// EmbeddingModel names the model embeddings are generated with, e.g. text-embedding-3-small
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+	// EmbeddingDimensions is the size embeddings must have; 0 accepts any size
+	EmbeddingDimensions int `json:"embedding_dimensions,omitempty"`
+	// Documents counts the collection's documents (read-only)
+	Documents int64 `json:"documents"`
+	// Registered is false for collections that only exist through their documents
+	Registered bool       `json:"registered"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks the name and embedding settings of a collection
+func (c Collection) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCollection)
+	}
+	if err := ValidateCollection(c.Name); err != nil {
+		return err
+	}
+	if len(c.EmbeddingModel) > 255 {
+		return errors.New("embedding_model must be at most 255 characters")
+	}
+	if c.EmbeddingDimensions < 0 || c.EmbeddingDimensions > MaxEmbeddingDimensions {
+		return fmt.Errorf("embedding_dimensions must be between 1 and %d, got %d", MaxEmbeddingDimensions, c.EmbeddingDimensions)
+	}
+	return nil
+}
+
+// CheckEmbedding returns ErrEmbeddingDimensions when a non-nil embedding does not have
+// the collection's dimensions
+func (c Collection) CheckEmbedding(embedding []float32) error {
+	if embedding == nil || c.EmbeddingDimensions == 0 || len(embedding) == c.EmbeddingDimensions {
+		return nil
+	}
+	return fmt.Errorf("%w: collection %q takes %d-dimension embeddings (%s), got %d",
+		ErrEmbeddingDimensions, c.Name, c.EmbeddingDimensions, c.modelName(), len(embedding))
+}
+
+func (c Collection) modelName() string {
+	if c.EmbeddingModel == "" {
+		return "model unspecified"
+	}
+	return c.EmbeddingModel
+}
+
+// collectionName allows lowercase letters, digits, "-" and "_", starting with a letter or digit
+var collectionName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateCollection checks a collection name; the empty name selects DefaultCollection
+func ValidateCollection(name string) error {
+	if name != "" && !collectionName.MatchString(name) {
+		return fmt.Errorf("%w %q: use up to 64 lowercase letters, digits, '-' or '_'", ErrInvalidCollection, name)
+	}
+	return nil
+}
+
+// CollectionOrDefault returns name, or DefaultCollection when it is empty
+func CollectionOrDefault(name string) string {
+	if name == "" {
+		return DefaultCollection
+	}
+	return name
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCollection(t *testing.T) {
+	for _, name := range []string{"", "default", "policies", "support-tickets", "kb_2024"} {
+		assert.NoError(t, ValidateCollection(name), name)
+	}
+	for _, name := range []string{"Policies", "-tickets", "a b", "x/y", string(make([]byte, 65))} {
+		assert.ErrorIs(t, ValidateCollection(name), ErrInvalidCollection, name)
+	}
+	assert.Equal(t, DefaultCollection, CollectionOrDefault(""))
+	assert.Equal(t, "tickets", CollectionOrDefault("tickets"))
+}
+
+func TestCollectionValidate(t *testing.T) {
+	assert.NoError(t, Collection{Name: "tickets"}.Validate())
+	assert.NoError(t, Collection{Name: "tickets", EmbeddingModel: "nomic-embed-text", EmbeddingDimensions: 768}.Validate())
+	assert.ErrorIs(t, Collection{}.Validate(), ErrInvalidCollection)
+	assert.ErrorIs(t, Collection{Name: "Tickets"}.Validate(), ErrInvalidCollection)
+	assert.EqualError(t, Collection{Name: "tickets", EmbeddingDimensions: -1}.Validate(),
+		"embedding_dimensions must be between 1 and 16000, got -1")
+
+	collection := Collection{Name: "tickets", EmbeddingModel: "nomic-embed-text", EmbeddingDimensions: 3}
+	assert.NoError(t, collection.CheckEmbedding(nil))
+	assert.NoError(t, collection.CheckEmbedding([]float32{1, 2, 3}))
+	err := collection.CheckEmbedding([]float32{1, 2})
+	assert.ErrorIs(t, err, ErrEmbeddingDimensions)
+	assert.EqualError(t, err, `embedding dimensions do not match the collection: collection "tickets" takes 3-dimension embeddings (nomic-embed-text), got 2`)
+	assert.NoError(t, Collection{Name: "any"}.CheckEmbedding([]float32{1, 2}))
+}
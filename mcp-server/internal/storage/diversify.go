@@ -0,0 +1,61 @@
+package storage
+
+// DefaultMMRLambda balances relevance against novelty in Diversify. 1 keeps the
+// original ranking; 0 picks each next result purely for being unlike those already chosen.
+const DefaultMMRLambda = 0.7
+
+// Diversify re-ranks results with Maximal Marginal Relevance and returns at most k of them.
+// Each pick maximizes
+//
+//	lambda * relevance - (1 - lambda) * max similarity to the results already picked
+//
+// where relevance is the combined score min-max normalized over the candidates and
+// similarity is the cosine similarity of the document embeddings. Documents without
+// embeddings are never penalized as duplicates. Results keep their original scores.
+func Diversify(results []HybridSearchResult, lambda float64, k int) []HybridSearchResult {
+	if k <= 0 || k > len(results) {
+		k = len(results)
+	}
+	if len(results) == 0 {
+		return results
+	}
+
+	relevance := make([]float64, len(results))
+	lo, hi := results[0].CombinedScore, results[0].CombinedScore
+	for _, result := range results {
+		lo = min(lo, result.CombinedScore)
+		hi = max(hi, result.CombinedScore)
+	}
+	for i, result := range results {
+		relevance[i] = 1
+		if hi > lo {
+			relevance[i] = (result.CombinedScore - lo) / (hi - lo)
+		}
+	}
+
+	// maxSimilarity[i] is the highest similarity of candidate i to any picked result
+	maxSimilarity := make([]float64, len(results))
+	picked := make([]bool, len(results))
+	diversified := make([]HybridSearchResult, 0, k)
+	for len(diversified) < k {
+		best, bestScore := -1, 0.0
+		for i := range results {
+			if picked[i] {
+				continue
+			}
+			score := lambda*relevance[i] - (1-lambda)*maxSimilarity[i]
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		picked[best] = true
+		diversified = append(diversified, results[best])
+		for i := range results {
+			if !picked[i] {
+				maxSimilarity[i] = max(maxSimilarity[i], CosineSimilarity(results[i].Document.Embedding, results[best].Document.Embedding))
+			}
+		}
+	}
+	return diversified
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diversifyIDs(results []HybridSearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Document.ID
+	}
+	return ids
+}
+
+func TestDiversify(t *testing.T) {
+	// Three chunks of the same document rank highest; two distinct documents follow
+	results := []HybridSearchResult{
+		{Document: Document{ID: "a1", Embedding: []float32{1, 0, 0}}, CombinedScore: 1.0},
+		{Document: Document{ID: "a2", Embedding: []float32{0.99, 0.1, 0}}, CombinedScore: 0.95},
+		{Document: Document{ID: "a3", Embedding: []float32{0.98, 0.12, 0}}, CombinedScore: 0.9},
+		{Document: Document{ID: "b", Embedding: []float32{0, 1, 0}}, CombinedScore: 0.85},
+		{Document: Document{ID: "c", Embedding: []float32{0, 0, 1}}, CombinedScore: 0.5},
+	}
+
+	tests := []struct {
+		name   string
+		lambda float64
+		k      int
+		want   []string
+	}{
+		{"lambda 1 keeps the ranking", 1, 3, []string{"a1", "a2", "a3"}},
+		{"default lambda promotes a distinct document", DefaultMMRLambda, 3, []string{"a1", "b", "a2"}},
+		{"lambda 0 maximizes novelty", 0, 3, []string{"a1", "b", "c"}},
+		{"k larger than candidates returns all", DefaultMMRLambda, 10, []string{"a1", "b", "a2", "a3", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diversified := Diversify(results, tt.lambda, tt.k)
+			assert.Equal(t, tt.want, diversifyIDs(diversified))
+		})
+	}
+
+	// Scores are preserved
+	diversified := Diversify(results, DefaultMMRLambda, 2)
+	assert.Equal(t, 0.85, diversified[1].CombinedScore)
+}
+
+func TestDiversify_WithoutEmbeddings(t *testing.T) {
+	results := []HybridSearchResult{
+		{Document: Document{ID: "a"}, CombinedScore: 3},
+		{Document: Document{ID: "b"}, CombinedScore: 2},
+		{Document: Document{ID: "c"}, CombinedScore: 1},
+	}
+
+	// Nothing to compare, so the ranking is unchanged
+	assert.Equal(t, []string{"a", "b", "c"}, diversifyIDs(Diversify(results, 0.5, 3)))
+	assert.Empty(t, Diversify(nil, 0.5, 3))
+}
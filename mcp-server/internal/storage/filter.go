@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MetadataFilter restricts search results by document metadata. Every key must match.
+// A key matches when the metadata value equals one of the listed values or, for
+// array-valued metadata such as tags, contains one of them.
+type MetadataFilter map[string][]interface{}
+
+// ParseMetadataFilter converts a tool's "filters" argument, e.g.
+// {"category": "security", "department": ["eng", "ops"]}, into a MetadataFilter.
+// Values must be strings, numbers or booleans, or non-empty arrays of them.
+func ParseMetadataFilter(raw map[string]interface{}) (MetadataFilter, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	filter := make(MetadataFilter, len(raw))
+	for key, value := range raw {
+		if key == "" {
+			return nil, fmt.Errorf("filter keys must not be empty")
+		}
+
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("filter %q must list at least one value", key)
+		}
+		for _, v := range values {
+			switch v.(type) {
+			case string, float64, bool:
+			default:
+				return nil, fmt.Errorf("filter %q values must be strings, numbers or booleans", key)
+			}
+		}
+		filter[key] = values
+	}
+	return filter, nil
+}
+
+// Keys returns the filter keys in sorted order
+func (f MetadataFilter) Keys() []string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Matches reports whether metadata satisfies every condition in the filter
+func (f MetadataFilter) Matches(metadata map[string]interface{}) bool {
+	for key, allowed := range f {
+		value, ok := metadata[key]
+		if !ok || !matchesAny(value, allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the documents whose metadata matches the filter
+func (f MetadataFilter) Apply(docs []*Document) []*Document {
+	if len(f) == 0 {
+		return docs
+	}
+
+	matched := make([]*Document, 0, len(docs))
+	for _, doc := range docs {
+		if f.Matches(doc.Metadata) {
+			matched = append(matched, doc)
+		}
+	}
+	return matched
+}
+
+// matchesAny reports whether value equals, or is an array containing, one of allowed
+func matchesAny(value interface{}, allowed []interface{}) bool {
+	candidates, ok := value.([]interface{})
+	if !ok {
+		candidates = []interface{}{value}
+	}
+
+	for _, candidate := range candidates {
+		for _, a := range allowed {
+			if scalarEqual(candidate, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scalarEqual compares JSON scalars, treating all numeric types as float64
+func scalarEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	switch a.(type) {
+	case string, bool:
+		return a == b
+	}
+	return false
+}
+
+// toFloat converts the numeric types produced by JSON decoding and Go literals
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
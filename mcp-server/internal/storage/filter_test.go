@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadataFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     map[string]interface{}
+		want    MetadataFilter
+		wantErr bool
+	}{
+		{name: "empty", raw: nil, want: nil},
+		{
+			name: "scalars and lists",
+			raw:  map[string]interface{}{"category": "security", "department": []interface{}{"eng", "ops"}, "version": 2.0},
+			want: MetadataFilter{"category": {"security"}, "department": {"eng", "ops"}, "version": {2.0}},
+		},
+		{name: "empty list", raw: map[string]interface{}{"category": []interface{}{}}, wantErr: true},
+		{name: "nested object", raw: map[string]interface{}{"category": map[string]interface{}{"a": "b"}}, wantErr: true},
+		{name: "null value", raw: map[string]interface{}{"category": nil}, wantErr: true},
+		{name: "empty key", raw: map[string]interface{}{"": "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMetadataFilter(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMetadataFilter_Matches(t *testing.T) {
+	metadata := map[string]interface{}{
+		"category": "security",
+		"version":  float64(3),
+		"tags":     []interface{}{"mfa", "vpn"},
+	}
+
+	tests := []struct {
+		name   string
+		filter MetadataFilter
+		want   bool
+	}{
+		{name: "empty filter", filter: nil, want: true},
+		{name: "scalar equality", filter: MetadataFilter{"category": {"security"}}, want: true},
+		{name: "any of values", filter: MetadataFilter{"category": {"hr", "security"}}, want: true},
+		{name: "no value matches", filter: MetadataFilter{"category": {"hr"}}, want: false},
+		{name: "array membership", filter: MetadataFilter{"tags": {"vpn"}}, want: true},
+		{name: "numbers", filter: MetadataFilter{"version": {3}}, want: true},
+		{name: "missing key", filter: MetadataFilter{"department": {"eng"}}, want: false},
+		{name: "all keys must match", filter: MetadataFilter{"category": {"security"}, "tags": {"sso"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(metadata))
+		})
+	}
+}
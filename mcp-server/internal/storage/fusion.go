@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+)
+
+// Fusion methods for combining the lexical and vector rankings of a hybrid search
+const (
+	FusionRRF      = "rrf"      // Reciprocal Rank Fusion: uses ranks only, ignores score scales
+	FusionMinMax   = "minmax"   // weighted sum of scores min-max normalized to [0, 1] per ranking
+	FusionZScore   = "zscore"   // weighted sum of scores standardized to mean 0, stddev 1 per ranking
+	FusionWeighted = "weighted" // weighted sum of raw BM25 and cosine scores
+)
+
+// DefaultRRFK is the Reciprocal Rank Fusion constant used when none is configured
+const DefaultRRFK = 60
+
+// Fusion combines the lexical and vector rankings of a hybrid search into one score per document
+type Fusion interface {
+	// Name returns the fusion method name, e.g. "rrf"
+	Name() string
+
+	// Fuse returns the combined score of every document in either ranking, keyed by document ID.
+	// Rankings are ordered best first and the weights sum to 1.
+	Fuse(lexical, vector []ScoredDocument, lexicalWeight, vectorWeight float64) map[string]float64
+}
+
+// NewFusion returns the fusion for a method name. An empty method selects RRF, and
+// rrfK <= 0 selects DefaultRRFK.
+func NewFusion(method string, rrfK int) (Fusion, error) {
+	switch method {
+	case "", FusionRRF:
+		if rrfK <= 0 {
+			rrfK = DefaultRRFK
+		}
+		return RRFFusion{K: rrfK}, nil
+	case FusionMinMax:
+		return MinMaxFusion{}, nil
+	case FusionZScore:
+		return ZScoreFusion{}, nil
+	case FusionWeighted:
+		return WeightedFusion{}, nil
+	default:
+		return nil, fmt.Errorf("unknown fusion method %q: must be one of %s, %s, %s or %s",
+			method, FusionRRF, FusionMinMax, FusionZScore, FusionWeighted)
+	}
+}
+
+// RRFFusion scores each document by weight / (K + rank) summed over the rankings it appears in
+type RRFFusion struct {
+	K int
+}
+
+// Name implements Fusion
+func (f RRFFusion) Name() string { return FusionRRF }
+
+// Fuse implements Fusion
+func (f RRFFusion) Fuse(lexical, vector []ScoredDocument, lexicalWeight, vectorWeight float64) map[string]float64 {
+	scores := make(map[string]float64, len(lexical)+len(vector))
+	for rank, match := range lexical {
+		scores[match.Document.ID] += lexicalWeight / float64(f.K+rank+1)
+	}
+	for rank, match := range vector {
+		scores[match.Document.ID] += vectorWeight / float64(f.K+rank+1)
+	}
+	return scores
+}
+
+// MinMaxFusion rescales each ranking's scores to [0, 1] before the weighted sum.
+// A document missing from a ranking contributes 0 for it.
+type MinMaxFusion struct{}
+
+// Name implements Fusion
+func (MinMaxFusion) Name() string { return FusionMinMax }
+
+// Fuse implements Fusion
+func (MinMaxFusion) Fuse(lexical, vector []ScoredDocument, lexicalWeight, vectorWeight float64) map[string]float64 {
+	scores := make(map[string]float64, len(lexical)+len(vector))
+	addNormalized(scores, lexical, lexicalWeight, minMaxNormalize)
+	addNormalized(scores, vector, vectorWeight, minMaxNormalize)
+	return scores
+}
+
+// ZScoreFusion standardizes each ranking's scores before the weighted sum. A document
+// missing from a ranking contributes that ranking's lowest standardized score, so it
+// never outranks a document that was retrieved.
+type ZScoreFusion struct{}
+
+// Name implements Fusion
+func (ZScoreFusion) Name() string { return FusionZScore }
+
+// Fuse implements Fusion
+func (ZScoreFusion) Fuse(lexical, vector []ScoredDocument, lexicalWeight, vectorWeight float64) map[string]float64 {
+	lexicalZ, lexicalFloor := zScoreNormalize(lexical)
+	vectorZ, vectorFloor := zScoreNormalize(vector)
+
+	scores := make(map[string]float64, len(lexical)+len(vector))
+	for _, ranking := range [][]ScoredDocument{lexical, vector} {
+		for _, match := range ranking {
+			id := match.Document.ID
+			scores[id] = lexicalWeight*scoreOr(lexicalZ, id, lexicalFloor) +
+				vectorWeight*scoreOr(vectorZ, id, vectorFloor)
+		}
+	}
+	return scores
+}
+
+// WeightedFusion sums the raw scores. BM25 and cosine similarity have different
+// scales, so the weights do not reflect their actual influence; prefer the other methods.
+type WeightedFusion struct{}
+
+// Name implements Fusion
+func (WeightedFusion) Name() string { return FusionWeighted }
+
+// Fuse implements Fusion
+func (WeightedFusion) Fuse(lexical, vector []ScoredDocument, lexicalWeight, vectorWeight float64) map[string]float64 {
+	scores := make(map[string]float64, len(lexical)+len(vector))
+	addNormalized(scores, lexical, lexicalWeight, rawScores)
+	addNormalized(scores, vector, vectorWeight, rawScores)
+	return scores
+}
+
+// FuseRankings combines the lexical and vector rankings with fusion, applies the score
+// thresholds in params and returns the top params.Limit results.
+func FuseRankings(fusion Fusion, lexical, vector []ScoredDocument, params HybridSearchParams) []HybridSearchResult {
+	bm25Weight, vectorWeight, limit := normalizeParams(params)
+	combined := fusion.Fuse(lexical, vector, bm25Weight, vectorWeight)
+
+	candidates := make(map[string]*HybridSearchResult)
+	var order []string
+	candidate := func(doc *Document) *HybridSearchResult {
+		if result, ok := candidates[doc.ID]; ok {
+			return result
+		}
+		result := &HybridSearchResult{Document: *doc, CombinedScore: combined[doc.ID]}
+		candidates[doc.ID] = result
+		order = append(order, doc.ID)
+		return result
+	}
+
+	for _, match := range lexical {
+		candidate(match.Document).BM25Score = match.Score
+	}
+	for _, match := range vector {
+		candidate(match.Document).VectorScore = match.Score
+	}
+
+	var results []HybridSearchResult
+	for _, id := range order {
+		result := candidates[id]
+		if result.BM25Score >= params.MinBM25Score || result.VectorScore >= params.MinVectorSim {
+			results = append(results, *result)
+		}
+	}
+	return topResults(results, limit)
+}
+
+// addNormalized adds weight * normalized score to each document in ranking
+func addNormalized(scores map[string]float64, ranking []ScoredDocument, weight float64, normalize func([]ScoredDocument) []float64) {
+	for i, score := range normalize(ranking) {
+		scores[ranking[i].Document.ID] += weight * score
+	}
+}
+
+// rawScores returns the scores of a ranking unchanged
+func rawScores(ranking []ScoredDocument) []float64 {
+	scores := make([]float64, len(ranking))
+	for i, match := range ranking {
+		scores[i] = match.Score
+	}
+	return scores
+}
+
+// minMaxNormalize rescales scores to [0, 1]. When every score is equal, each maps to 1.
+func minMaxNormalize(ranking []ScoredDocument) []float64 {
+	scores := rawScores(ranking)
+	if len(scores) == 0 {
+		return scores
+	}
+
+	lo, hi := scores[0], scores[0]
+	for _, score := range scores {
+		lo = math.Min(lo, score)
+		hi = math.Max(hi, score)
+	}
+	for i, score := range scores {
+		if hi == lo {
+			scores[i] = 1
+		} else {
+			scores[i] = (score - lo) / (hi - lo)
+		}
+	}
+	return scores
+}
+
+// zScoreNormalize standardizes scores by document ID and returns the lowest standardized
+// score. When every score is equal, each maps to 0.
+func zScoreNormalize(ranking []ScoredDocument) (map[string]float64, float64) {
+	normalized := make(map[string]float64, len(ranking))
+	if len(ranking) == 0 {
+		return normalized, 0
+	}
+
+	mean := 0.0
+	for _, match := range ranking {
+		mean += match.Score
+	}
+	mean /= float64(len(ranking))
+
+	variance := 0.0
+	for _, match := range ranking {
+		variance += (match.Score - mean) * (match.Score - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(ranking)))
+
+	floor := math.Inf(1)
+	for _, match := range ranking {
+		z := 0.0
+		if stddev > 0 {
+			z = (match.Score - mean) / stddev
+		}
+		normalized[match.Document.ID] = z
+		floor = math.Min(floor, z)
+	}
+	return normalized, floor
+}
+
+// scoreOr returns the score of id, or fallback when id is missing
+func scoreOr(scores map[string]float64, id string, fallback float64) float64 {
+	if score, ok := scores[id]; ok {
+		return score
+	}
+	return fallback
+}
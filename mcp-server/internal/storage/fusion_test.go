@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticRankings returns a lexical ranking on the BM25 scale and a vector ranking on
+// the cosine scale:
+//
+//	lexical: A 12.0, B 11.5, C 2.0
+//	vector:  C 0.95, B 0.40, D 0.39
+func syntheticRankings() (lexical, vector []ScoredDocument) {
+	docs := map[string]*Document{}
+	for _, id := range []string{"A", "B", "C", "D"} {
+		docs[id] = &Document{ID: id, Title: "Doc " + id}
+	}
+	lexical = []ScoredDocument{{docs["A"], 12.0}, {docs["B"], 11.5}, {docs["C"], 2.0}}
+	vector = []ScoredDocument{{docs["C"], 0.95}, {docs["B"], 0.40}, {docs["D"], 0.39}}
+	return lexical, vector
+}
+
+func TestNewFusion(t *testing.T) {
+	tests := []struct {
+		method string
+		rrfK   int
+		want   Fusion
+	}{
+		{"", 0, RRFFusion{K: DefaultRRFK}},
+		{FusionRRF, 10, RRFFusion{K: 10}},
+		{FusionMinMax, 0, MinMaxFusion{}},
+		{FusionZScore, 0, ZScoreFusion{}},
+		{FusionWeighted, 0, WeightedFusion{}},
+	}
+	for _, tt := range tests {
+		fusion, err := NewFusion(tt.method, tt.rrfK)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, fusion)
+	}
+
+	_, err := NewFusion("borda", 0)
+	assert.Error(t, err)
+}
+
+func TestRRFFusion(t *testing.T) {
+	lexical, vector := syntheticRankings()
+
+	scores := RRFFusion{K: 60}.Fuse(lexical, vector, 0.5, 0.5)
+	assert.InDelta(t, 0.5/61, scores["A"], 1e-12)
+	assert.InDelta(t, 0.5/62+0.5/62, scores["B"], 1e-12)
+	assert.InDelta(t, 0.5/63+0.5/61, scores["C"], 1e-12)
+	assert.InDelta(t, 0.5/63, scores["D"], 1e-12)
+
+	// Raw scores are ignored: rescaling a ranking does not change the result
+	scaled := make([]ScoredDocument, len(lexical))
+	for i, match := range lexical {
+		scaled[i] = ScoredDocument{Document: match.Document, Score: match.Score * 1000}
+	}
+	assert.Equal(t, scores, RRFFusion{K: 60}.Fuse(scaled, vector, 0.5, 0.5))
+
+	// A smaller k favours documents ranked first in either list
+	small := RRFFusion{K: 1}.Fuse(lexical, vector, 0.5, 0.5)
+	assert.Greater(t, small["C"]-small["B"], scores["C"]-scores["B"])
+}
+
+func TestMinMaxFusion(t *testing.T) {
+	lexical, vector := syntheticRankings()
+
+	scores := MinMaxFusion{}.Fuse(lexical, vector, 0.5, 0.5)
+	assert.InDelta(t, 0.5*1, scores["A"], 1e-12)
+	assert.InDelta(t, 0.5*(9.5/10)+0.5*(0.01/0.56), scores["B"], 1e-12)
+	assert.InDelta(t, 0.5*0+0.5*1, scores["C"], 1e-12)
+	assert.InDelta(t, 0.0, scores["D"], 1e-12)
+
+	// Equal scores normalize to 1
+	tied := MinMaxFusion{}.Fuse(lexical[:1], nil, 1, 0)
+	assert.InDelta(t, 1.0, tied["A"], 1e-12)
+}
+
+func TestZScoreFusion(t *testing.T) {
+	lexical, vector := syntheticRankings()
+
+	lexicalMean, lexicalStd := meanStd(12.0, 11.5, 2.0)
+	vectorMean, vectorStd := meanStd(0.95, 0.40, 0.39)
+	z := func(score, mean, std float64) float64 { return (score - mean) / std }
+
+	scores := ZScoreFusion{}.Fuse(lexical, vector, 0.5, 0.5)
+	assert.InDelta(t, 0.5*z(11.5, lexicalMean, lexicalStd)+0.5*z(0.40, vectorMean, vectorStd), scores["B"], 1e-9)
+
+	// Documents missing from a ranking get that ranking's lowest z-score
+	assert.InDelta(t, 0.5*z(12.0, lexicalMean, lexicalStd)+0.5*z(0.39, vectorMean, vectorStd), scores["A"], 1e-9)
+	assert.InDelta(t, 0.5*z(2.0, lexicalMean, lexicalStd)+0.5*z(0.39, vectorMean, vectorStd), scores["D"], 1e-9)
+
+	// Equal scores standardize to 0
+	tied := ZScoreFusion{}.Fuse(lexical[:1], nil, 1, 0)
+	assert.InDelta(t, 0.0, tied["A"], 1e-12)
+}
+
+func TestWeightedFusion(t *testing.T) {
+	lexical, vector := syntheticRankings()
+
+	scores := WeightedFusion{}.Fuse(lexical, vector, 0.5, 0.5)
+	assert.InDelta(t, 6.0, scores["A"], 1e-12)
+	assert.InDelta(t, 5.75+0.2, scores["B"], 1e-12)
+	assert.InDelta(t, 1.0+0.475, scores["C"], 1e-12)
+	assert.InDelta(t, 0.195, scores["D"], 1e-12)
+}
+
+func TestFusion_ScaleSensitivity(t *testing.T) {
+	lexical, vector := syntheticRankings()
+
+	// C is the best semantic match and B is second in both rankings. With equal weights,
+	// normalized methods rank C above B; on raw scores the BM25 scale buries C.
+	for _, fusion := range []Fusion{RRFFusion{K: DefaultRRFK}, MinMaxFusion{}, ZScoreFusion{}} {
+		scores := fusion.Fuse(lexical, vector, 0.5, 0.5)
+		assert.Greater(t, scores["C"], scores["B"], fusion.Name())
+	}
+
+	raw := WeightedFusion{}.Fuse(lexical, vector, 0.5, 0.5)
+	assert.Less(t, raw["C"], raw["B"])
+}
+
+func TestFuseRankings(t *testing.T) {
+	lexical, vector := syntheticRankings()
+
+	results := FuseRankings(MinMaxFusion{}, lexical, vector, HybridSearchParams{Limit: 3, BM25Weight: 1, VectorWeight: 1})
+	require.Len(t, results, 3)
+	assert.Equal(t, "A", results[0].Document.ID)
+	assert.Equal(t, "C", results[1].Document.ID)
+	assert.Equal(t, "B", results[2].Document.ID)
+	assert.Equal(t, 11.5, results[2].BM25Score)
+	assert.Equal(t, 0.40, results[2].VectorScore)
+
+	// Thresholds apply to raw scores: A fails both (no vector score) and D's similarity is too low
+	results = FuseRankings(MinMaxFusion{}, lexical, vector, HybridSearchParams{Limit: 10, MinBM25Score: 100, MinVectorSim: 0.395})
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Document.ID)
+	}
+	assert.ElementsMatch(t, []string{"B", "C"}, ids)
+}
+
+func meanStd(values ...float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory document store for local development and tests.
+// Data is lost when the process exits.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	documents map[string]map[string]*Document // tenant ID -> document ID -> document
+}
+
+// Ensure MemoryStore implements ReadWriter interface
+var _ ReadWriter = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a new empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		documents: make(map[string]map[string]*Document),
+	}
+}
+
+// GetDocument retrieves a document by ID for a specific tenant
+func (s *MemoryStore) GetDocument(ctx context.Context, tenantID, docID string) (*Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc, ok := s.documents[tenantID][docID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyDocument(doc), nil
+}
+
+// SearchDocuments matches the query as a case-insensitive substring of the title,
+// content or metadata, newest first
+func (s *MemoryStore) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter MetadataFilter) ([]*Document, error) {
+	needle := strings.ToLower(query)
+
+	var matches []*Document
+	for _, doc := range filter.Apply(s.collectionDocuments(tenantID, collection)) {
+		metadata, _ := json.Marshal(doc.Metadata)
+		if strings.Contains(strings.ToLower(doc.Title), needle) ||
+			strings.Contains(strings.ToLower(doc.Content), needle) ||
+			strings.Contains(strings.ToLower(string(metadata)), needle) {
+			matches = append(matches, doc)
+		}
+	}
+
+	return paginate(matches, limit, 0), nil
+}
+
+// ListDocuments lists the documents of a collection with pagination, newest first
+func (s *MemoryStore) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*Document, error) {
+	return paginate(s.collectionDocuments(tenantID, collection), limit, offset), nil
+}
+
+// HybridSearch performs BM25 + brute-force vector search fused with params.Fusion
+func (s *MemoryStore) HybridSearch(ctx context.Context, tenantID string, params HybridSearchParams) ([]HybridSearchResult, error) {
+	docs := params.Filters.Apply(s.collectionDocuments(tenantID, params.Collection))
+	return FuseHybrid(ScoreQuery(PrepareLexicalQuery(params, docs), docs), docs, params)
+}
+
+// SimpleHybridSearch performs weighted BM25 + brute-force vector search
+func (s *MemoryStore) SimpleHybridSearch(ctx context.Context, tenantID string, params HybridSearchParams) ([]HybridSearchResult, error) {
+	docs := params.Filters.Apply(s.collectionDocuments(tenantID, params.Collection))
+	return FuseWeighted(ScoreQuery(PrepareLexicalQuery(params, docs), docs), docs, params), nil
+}
+
+// SimilarDocuments ranks the other documents of the document's collection by brute-force
+// cosine similarity to its embedding
+func (s *MemoryStore) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter MetadataFilter) ([]ScoredDocument, error) {
+	doc, err := s.GetDocument(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+	return RankSimilar(doc, filter.Apply(s.collectionDocuments(tenantID, doc.Collection)), limit)
+}
+
+// InsertDocument inserts a new document and fills in its ID and timestamps
+func (s *MemoryStore) InsertDocument(ctx context.Context, tenantID string, doc *Document) error {
+	id, err := NewDocumentID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	doc.ID = id
+	doc.TenantID = tenantID
+	doc.Collection = CollectionOrDefault(doc.Collection)
+	doc.CreatedAt = now
+	doc.UpdatedAt = now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.documents[tenantID] == nil {
+		s.documents[tenantID] = make(map[string]*Document)
+	}
+	s.documents[tenantID][doc.ID] = copyDocument(doc)
+	return nil
+}
+
+// UpdateDocument updates a document's title, content, metadata and embedding
+func (s *MemoryStore) UpdateDocument(ctx context.Context, tenantID string, doc *Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.documents[tenantID][doc.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	updated := copyDocument(existing)
+	updated.Title = doc.Title
+	updated.Content = doc.Content
+	updated.Metadata = doc.Metadata
+	updated.Embedding = doc.Embedding
+	updated.UpdatedAt = time.Now().UTC()
+	s.documents[tenantID][doc.ID] = updated
+
+	doc.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+// DeleteDocument deletes a document
+func (s *MemoryStore) DeleteDocument(ctx context.Context, tenantID, docID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.documents[tenantID][docID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.documents[tenantID], docID)
+	return nil
+}
+
+// collectionDocuments returns copies of the documents in a tenant's collection, newest first
+func (s *MemoryStore) collectionDocuments(tenantID, collection string) []*Document {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	collection = CollectionOrDefault(collection)
+	docs := make([]*Document, 0, len(s.documents[tenantID]))
+	for _, doc := range s.documents[tenantID] {
+		if doc.Collection == collection {
+			docs = append(docs, copyDocument(doc))
+		}
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].CreatedAt.Equal(docs[j].CreatedAt) {
+			return docs[i].ID < docs[j].ID
+		}
+		return docs[i].CreatedAt.After(docs[j].CreatedAt)
+	})
+	return docs
+}
+
+// paginate applies limit and offset to a slice of documents
+func paginate(docs []*Document, limit, offset int) []*Document {
+	if offset >= len(docs) {
+		return nil
+	}
+	docs = docs[offset:]
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+// copyDocument returns a copy that does not share slices or maps with the original
+func copyDocument(doc *Document) *Document {
+	c := *doc
+	if doc.Metadata != nil {
+		c.Metadata = make(map[string]interface{}, len(doc.Metadata))
+		for k, v := range doc.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	if doc.Embedding != nil {
+		c.Embedding = append([]float32(nil), doc.Embedding...)
+	}
+	return &c
+}
+
+// NewDocumentID returns a random RFC 4122 version 4 UUID
+func NewDocumentID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate document ID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedMemoryStore(t *testing.T) *MemoryStore {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	docs := []*Document{
+		{Title: "Password Policy", Content: "Passwords must rotate every 90 days", Metadata: map[string]interface{}{"category": "security"}, Embedding: []float32{1, 0, 0}},
+		{Title: "Vacation Policy", Content: "Employees receive 20 vacation days", Metadata: map[string]interface{}{"category": "hr"}, Embedding: []float32{0, 1, 0}},
+		{Title: "Network Security", Content: "All traffic is encrypted with TLS", Metadata: map[string]interface{}{"category": "security"}, Embedding: []float32{0.9, 0.1, 0}},
+	}
+	for _, doc := range docs {
+		require.NoError(t, store.InsertDocument(ctx, "tenant-1", doc))
+	}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-2", &Document{Title: "Password Policy", Content: "Other tenant"}))
+	return store
+}
+
+func TestMemoryStore_CRUD(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	doc := &Document{Title: "Title", Content: "Content", Metadata: map[string]interface{}{"k": "v"}}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", doc))
+	assert.NotEmpty(t, doc.ID)
+	assert.Equal(t, "tenant-1", doc.TenantID)
+
+	got, err := store.GetDocument(ctx, "tenant-1", doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", got.Title)
+
+	// Returned documents are copies
+	got.Metadata["k"] = "changed"
+	again, _ := store.GetDocument(ctx, "tenant-1", doc.ID)
+	assert.Equal(t, "v", again.Metadata["k"])
+
+	// Tenants are isolated
+	_, err = store.GetDocument(ctx, "tenant-2", doc.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	doc.Title = "Updated"
+	require.NoError(t, store.UpdateDocument(ctx, "tenant-1", doc))
+	got, _ = store.GetDocument(ctx, "tenant-1", doc.ID)
+	assert.Equal(t, "Updated", got.Title)
+
+	require.NoError(t, store.DeleteDocument(ctx, "tenant-1", doc.ID))
+	_, err = store.GetDocument(ctx, "tenant-1", doc.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.DeleteDocument(ctx, "tenant-1", doc.ID), ErrNotFound)
+}
+
+func TestMemoryStore_SearchAndList(t *testing.T) {
+	store := seedMemoryStore(t)
+	ctx := context.Background()
+
+	docs, err := store.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, docs, 2)
+
+	docs, err = store.SearchDocuments(ctx, "tenant-1", "", "security", 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, docs, 2, "metadata should be searchable")
+
+	docs, err = store.SearchDocuments(ctx, "tenant-1", "", "policy", 10, MetadataFilter{"category": {"security"}})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Password Policy", docs[0].Title)
+
+	docs, err = store.ListDocuments(ctx, "tenant-1", "", 2, 0)
+	require.NoError(t, err)
+	assert.Len(t, docs, 2)
+
+	docs, err = store.ListDocuments(ctx, "tenant-1", "", 2, 2)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+
+	docs, err = store.ListDocuments(ctx, "tenant-1", "", 10, 5)
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+}
+
+func TestMemoryStore_Collections(t *testing.T) {
+	store := seedMemoryStore(t)
+	ctx := context.Background()
+
+	ticket := &Document{Collection: "tickets", Title: "Password reset ticket", Content: "User cannot reset the password policy", Embedding: []float32{1, 0, 0}}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", ticket))
+
+	docs, err := store.SearchDocuments(ctx, "tenant-1", "tickets", "password", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "tickets", docs[0].Collection)
+
+	// The default collection does not see the ticket, and vice versa
+	docs, err = store.SearchDocuments(ctx, "tenant-1", "", "password", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, DefaultCollection, docs[0].Collection)
+
+	docs, err = store.ListDocuments(ctx, "tenant-1", "tickets", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+	docs, err = store.ListDocuments(ctx, "tenant-2", "tickets", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+
+	results, err := store.HybridSearch(ctx, "tenant-1", HybridSearchParams{
+		Collection: "tickets", Query: "password", Embedding: []float32{1, 0, 0}, Limit: 10, BM25Weight: 0.5, VectorWeight: 0.5,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, ticket.ID, results[0].Document.ID)
+}
+
+func TestMemoryStore_HybridSearch(t *testing.T) {
+	store := seedMemoryStore(t)
+	ctx := context.Background()
+
+	params := HybridSearchParams{
+		Query:        "password",
+		Embedding:    []float32{1, 0, 0},
+		Limit:        10,
+		BM25Weight:   0.5,
+		VectorWeight: 0.5,
+		MinVectorSim: 0.5,
+	}
+
+	results, err := store.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+	assert.Greater(t, results[0].BM25Score, 0.0)
+	assert.InDelta(t, 1.0, results[0].VectorScore, 1e-6)
+	for _, result := range results {
+		assert.Equal(t, "tenant-1", result.Document.TenantID)
+	}
+
+	results, err = store.SimpleHybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	require.Len(t, results, 2, "vacation policy is below the similarity threshold and has no text match")
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+	assert.Equal(t, "Network Security", results[1].Document.Title)
+
+	for _, fusion := range []string{FusionRRF, FusionMinMax, FusionZScore, FusionWeighted} {
+		params.Fusion = fusion
+		results, err = store.HybridSearch(ctx, "tenant-1", params)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "Password Policy", results[0].Document.Title, fusion)
+	}
+
+	params.Fusion = "borda"
+	_, err = store.HybridSearch(ctx, "tenant-1", params)
+	assert.Error(t, err)
+	params.Fusion = ""
+
+	params.Filters = MetadataFilter{"category": {"hr"}}
+	results, err = store.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Vacation Policy", results[0].Document.Title)
+}
+
+func TestMemoryStore_QueryPreprocessing(t *testing.T) {
+	store := seedMemoryStore(t)
+	ctx := context.Background()
+
+	params := HybridSearchParams{Query: "pasword", Limit: 10}
+	results, err := store.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	params.CorrectSpelling = true
+	results, err = store.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+
+	params = HybridSearchParams{Query: "credentials", Limit: 10, Synonyms: map[string][]string{"credentials": {"password"}}}
+	results, err = store.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+}
+
+func TestMemoryStore_SimilarDocuments(t *testing.T) {
+	store := seedMemoryStore(t)
+	ctx := context.Background()
+
+	docs, err := store.ListDocuments(ctx, "tenant-1", "", 10, 0)
+	require.NoError(t, err)
+	byTitle := make(map[string]*Document)
+	for _, doc := range docs {
+		byTitle[doc.Title] = doc
+	}
+	password := byTitle["Password Policy"]
+
+	results, err := store.SimilarDocuments(ctx, "tenant-1", password.ID, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2, "the document itself is left out")
+	assert.Equal(t, "Network Security", results[0].Document.Title)
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	results, err = store.SimilarDocuments(ctx, "tenant-1", password.ID, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	results, err = store.SimilarDocuments(ctx, "tenant-1", password.ID, 10, MetadataFilter{"category": {"hr"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Vacation Policy", results[0].Document.Title)
+
+	// Other collections are not candidates
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", &Document{Collection: "tickets", Title: "Reset", Embedding: []float32{1, 0, 0}}))
+	results, err = store.SimilarDocuments(ctx, "tenant-1", password.ID, 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	_, err = store.SimilarDocuments(ctx, "tenant-2", password.ID, 10, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	plain := &Document{Title: "No Embedding"}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", plain))
+	_, err = store.SimilarDocuments(ctx, "tenant-1", plain.ID, 10, nil)
+	assert.ErrorIs(t, err, ErrNoEmbedding)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import "encoding/json"
+
+// MergeMetadata returns a document's metadata merged with that of its duplicates, which
+// are consulted in order: keys the document lacks are copied from the first duplicate
+// that has them, and array values gain the elements they do not contain yet. Other
+// values of the document win. The maps passed in are not modified.
+func MergeMetadata(metadata map[string]interface{}, duplicates ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		merged[key] = value
+	}
+
+	for _, duplicate := range duplicates {
+		for key, value := range duplicate {
+			existing, ok := merged[key]
+			if !ok {
+				merged[key] = value
+				continue
+			}
+			existingItems, existingIsArray := existing.([]interface{})
+			items, isArray := value.([]interface{})
+			if existingIsArray && isArray {
+				merged[key] = unionItems(existingItems, items)
+			}
+		}
+	}
+	return merged
+}
+
+// unionItems appends the items missing from a to a copy of it, comparing their JSON
+func unionItems(a, b []interface{}) []interface{} {
+	union := append([]interface{}(nil), a...)
+	seen := make(map[string]bool, len(a)+len(b))
+	for _, item := range a {
+		seen[itemKey(item)] = true
+	}
+	for _, item := range b {
+		if key := itemKey(item); !seen[key] {
+			seen[key] = true
+			union = append(union, item)
+		}
+	}
+	return union
+}
+
+// itemKey identifies an array item by its JSON encoding
+func itemKey(item interface{}) string {
+	data, _ := json.Marshal(item)
+	return string(data)
+}
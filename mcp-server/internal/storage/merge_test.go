@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeMetadata(t *testing.T) {
+	kept := map[string]interface{}{"category": "security", "tags": []interface{}{"passwords"}}
+	merged := MergeMetadata(kept,
+		map[string]interface{}{"category": "hr", "tags": []interface{}{"passwords", "rotation"}, "owner": "it"},
+		map[string]interface{}{"owner": "sre", "tags": "ignored", "source": "wiki"},
+	)
+
+	assert.Equal(t, map[string]interface{}{
+		"category": "security",
+		"tags":     []interface{}{"passwords", "rotation"},
+		"owner":    "it",
+		"source":   "wiki",
+	}, merged)
+	assert.Equal(t, []interface{}{"passwords"}, kept["tags"], "the kept metadata is not modified")
+
+	assert.Equal(t, map[string]interface{}{"a": 1.0}, MergeMetadata(nil, map[string]interface{}{"a": 1.0}))
+	assert.Empty(t, MergeMetadata(nil))
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Spelling correction parameters, matching pg_trgm's defaults
+const (
+	// MinCorrectionLength is the shortest word that spelling correction replaces; shorter
+	// words have too few trigrams to be matched reliably
+	MinCorrectionLength = 4
+
+	// SimilarityThreshold is the trigram similarity a correction needs (pg_trgm.similarity_threshold)
+	SimilarityThreshold = 0.3
+)
+
+// LexicalQuery is a full-text query after spelling correction and synonym expansion.
+// A document matches when it matches every term.
+type LexicalQuery []QueryTerm
+
+// QueryTerm is a word or phrase of a query. A document matches it when it contains all
+// words of the term or of one of its synonyms.
+type QueryTerm struct {
+	Words    []string
+	Synonyms [][]string
+}
+
+// Alternatives returns the term's words followed by its synonyms
+func (t QueryTerm) Alternatives() [][]string {
+	return append([][]string{t.Words}, t.Synonyms...)
+}
+
+// ParseLexicalQuery splits a query into single-word terms, dropping stop words like
+// plainto_tsquery
+func ParseLexicalQuery(query string) LexicalQuery {
+	words := Tokenize(query)
+	q := make(LexicalQuery, len(words))
+	for i, word := range words {
+		q[i] = QueryTerm{Words: []string{word}}
+	}
+	return q
+}
+
+// CorrectableWords returns the distinct words of the query that spelling correction may
+// replace: words of at least MinCorrectionLength letters that are not numbers
+func (q LexicalQuery) CorrectableWords() []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, term := range q {
+		for _, word := range term.Words {
+			if seen[word] || utf8.RuneCountInString(word) < MinCorrectionLength || isNumber(word) {
+				continue
+			}
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Correct returns the query with misspelled words replaced by their corrections
+func (q LexicalQuery) Correct(corrections map[string]string) LexicalQuery {
+	corrected := make(LexicalQuery, len(q))
+	for i, term := range q {
+		words := make([]string, len(term.Words))
+		for j, word := range term.Words {
+			if correction, ok := corrections[word]; ok {
+				word = correction
+			}
+			words[j] = word
+		}
+		corrected[i] = QueryTerm{Words: words, Synonyms: term.Synonyms}
+	}
+	return corrected
+}
+
+// ExpandSynonyms returns the query with the synonyms of its words and phrases. Keys of
+// synonyms are words or phrases, matched longest first against consecutive query words,
+// which then form one term; a key's synonyms do not expand further.
+func (q LexicalQuery) ExpandSynonyms(synonyms map[string][]string) LexicalQuery {
+	if len(synonyms) == 0 {
+		return q
+	}
+	byPhrase := make(map[string][][]string, len(synonyms))
+	longest := 0
+	for key, values := range synonyms {
+		words := Tokenize(key)
+		if len(words) == 0 {
+			continue
+		}
+		phrase := strings.Join(words, " ")
+		for _, value := range values {
+			if alternative := Tokenize(value); len(alternative) > 0 && strings.Join(alternative, " ") != phrase {
+				byPhrase[phrase] = append(byPhrase[phrase], alternative)
+			}
+		}
+		if len(words) > longest {
+			longest = len(words)
+		}
+	}
+
+	var expanded LexicalQuery
+	for i := 0; i < len(q); {
+		matched := false
+		for n := min(longest, len(q)-i); n > 0 && !matched; n-- {
+			var words []string
+			for _, term := range q[i : i+n] {
+				words = append(words, term.Words...)
+			}
+			if alternatives, ok := byPhrase[strings.Join(words, " ")]; ok {
+				expanded = append(expanded, QueryTerm{Words: words, Synonyms: alternatives})
+				i += n
+				matched = true
+			}
+		}
+		if !matched {
+			expanded = append(expanded, q[i])
+			i++
+		}
+	}
+	return expanded
+}
+
+// String formats the query for display, e.g. "(car | automobile) & engine"
+func (q LexicalQuery) String() string {
+	return q.format(func(word string) string { return word }, " ")
+}
+
+// TSQuery formats the query as to_tsquery input, e.g. "('car' | 'automobile') & 'engine'".
+// The words of a phrase are combined with & like plainto_tsquery does.
+func (q LexicalQuery) TSQuery() string {
+	escape := strings.NewReplacer(`\`, `\\`, `'`, `''`)
+	return q.format(func(word string) string { return "'" + escape.Replace(word) + "'" }, " & ")
+}
+
+// format joins the formatted words of every alternative with and, the alternatives of a
+// term with | and the terms with &
+func (q LexicalQuery) format(word func(string) string, and string) string {
+	terms := make([]string, len(q))
+	for i, term := range q {
+		alternatives := term.Alternatives()
+		formatted := make([]string, len(alternatives))
+		for j, words := range alternatives {
+			quoted := make([]string, len(words))
+			for k, w := range words {
+				quoted[k] = word(w)
+			}
+			formatted[j] = strings.Join(quoted, and)
+		}
+		terms[i] = strings.Join(formatted, " | ")
+		if len(alternatives) > 1 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// PrepareLexicalQuery parses the query of a hybrid search and applies the requested
+// spelling correction, with docs as the vocabulary, and synonym expansion
+func PrepareLexicalQuery(params HybridSearchParams, docs []*Document) LexicalQuery {
+	q := ParseLexicalQuery(params.Query)
+	if params.CorrectSpelling {
+		q = q.Correct(SuggestCorrections(q.CorrectableWords(), Vocabulary(docs)))
+	}
+	return q.ExpandSynonyms(params.Synonyms)
+}
+
+// Vocabulary counts the documents that contain each word
+func Vocabulary(docs []*Document) map[string]int {
+	vocabulary := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, word := range Tokenize(doc.Title + " " + doc.Content) {
+			if !seen[word] {
+				seen[word] = true
+				vocabulary[word]++
+			}
+		}
+	}
+	return vocabulary
+}
+
+// SuggestCorrections maps each word missing from the vocabulary to the most similar
+// vocabulary word by trigram similarity, preferring more frequent words on ties. Words
+// without a vocabulary word of at least SimilarityThreshold similarity are left out.
+func SuggestCorrections(words []string, vocabulary map[string]int) map[string]string {
+	candidates := make([]string, 0, len(vocabulary))
+	for word := range vocabulary {
+		candidates = append(candidates, word)
+	}
+	sort.Strings(candidates)
+
+	corrections := make(map[string]string)
+	for _, word := range words {
+		if vocabulary[word] > 0 {
+			continue
+		}
+		best, bestSimilarity := "", 0.0
+		for _, candidate := range candidates {
+			similarity := TrigramSimilarity(word, candidate)
+			if similarity < SimilarityThreshold {
+				continue
+			}
+			if similarity > bestSimilarity || (similarity == bestSimilarity && vocabulary[candidate] > vocabulary[best]) {
+				best, bestSimilarity = candidate, similarity
+			}
+		}
+		if best != "" {
+			corrections[word] = best
+		}
+	}
+	return corrections
+}
+
+// TrigramSimilarity returns the similarity of two words like pg_trgm's similarity(): the
+// number of trigrams they share divided by the number of distinct trigrams of both,
+// each word padded with two spaces in front and one behind
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range ta {
+		if tb[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams returns the set of trigrams of a padded, lowercased word
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + strings.ToLower(word) + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// isNumber reports whether word consists of digits only
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexicalQuery_ExpandSynonyms(t *testing.T) {
+	synonyms := map[string][]string{
+		"car":              {"automobile", "Motor Vehicle"},
+		"machine learning": {"ML"},
+		"machine":          {"device"},
+		"the":              {"ignored"},
+	}
+
+	q := ParseLexicalQuery("The car and machine learning machine").ExpandSynonyms(synonyms)
+	require.Len(t, q, 3)
+	assert.Equal(t, QueryTerm{Words: []string{"car"}, Synonyms: [][]string{{"automobile"}, {"motor", "vehicle"}}}, q[0])
+	assert.Equal(t, QueryTerm{Words: []string{"machine", "learning"}, Synonyms: [][]string{{"ml"}}}, q[1], "the longest phrase wins")
+	assert.Equal(t, QueryTerm{Words: []string{"machine"}, Synonyms: [][]string{{"device"}}}, q[2])
+
+	assert.Equal(t, "(car | automobile | motor vehicle) & (machine learning | ml) & (machine | device)", q.String())
+	assert.Equal(t, "('car' | 'automobile' | 'motor' & 'vehicle') & ('machine' & 'learning' | 'ml') & ('machine' | 'device')", q.TSQuery())
+
+	plain := ParseLexicalQuery("engine oil")
+	assert.Equal(t, plain, plain.ExpandSynonyms(nil))
+	assert.Equal(t, "'engine' & 'oil'", plain.TSQuery())
+	assert.Equal(t, "'it''s'", LexicalQuery{{Words: []string{"it's"}}}.TSQuery())
+}
+
+func TestLexicalQuery_Correct(t *testing.T) {
+	q := ParseLexicalQuery("pasword policy for 2024 vpn pasword")
+	assert.Equal(t, []string{"pasword", "policy"}, q.CorrectableWords(), "short words and numbers are not corrected")
+
+	corrected := q.Correct(map[string]string{"pasword": "password"})
+	assert.Equal(t, "password & policy & 2024 & vpn & password", corrected.String())
+	assert.Equal(t, "pasword", q[0].Words[0], "the original query is not modified")
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, TrigramSimilarity("word", "Word"), 1e-9)
+	// pg_trgm: SELECT similarity('serch', 'search') = 0.44444445
+	assert.InDelta(t, 4.0/9, TrigramSimilarity("serch", "search"), 1e-6)
+	assert.Less(t, TrigramSimilarity("password", "vacation"), SimilarityThreshold)
+	assert.Zero(t, TrigramSimilarity("", "word"))
+}
+
+func TestSuggestCorrections(t *testing.T) {
+	vocabulary := Vocabulary([]*Document{
+		{Title: "Password policy", Content: "Passwords rotate"},
+		{Title: "Password reset", Content: "Reset a password"},
+		{Title: "Passport", Content: "Travel documents"},
+	})
+	assert.Equal(t, 2, vocabulary["password"])
+
+	corrections := SuggestCorrections([]string{"pasword", "policy", "zzzzzz"}, vocabulary)
+	assert.Equal(t, map[string]string{"pasword": "password"}, corrections)
+}
+
+func TestScoreQuery_Alternatives(t *testing.T) {
+	docs := []*Document{
+		{ID: "1", Title: "Automobile maintenance", Content: "engine oil"},
+		{ID: "2", Title: "Motor vehicle registration", Content: "engine number"},
+		{ID: "3", Title: "Motor boats", Content: "engine"},
+	}
+	q := ParseLexicalQuery("car engine").ExpandSynonyms(map[string][]string{"car": {"automobile", "motor vehicle"}})
+
+	results := ScoreQuery(q, docs)
+	require.Len(t, results, 2, "a phrase synonym needs all of its words")
+	assert.ElementsMatch(t, []string{"1", "2"}, []string{results[0].Document.ID, results[1].Document.ID})
+	assert.Empty(t, ScoreText("car engine", docs))
+}
+
+func TestPrepareLexicalQuery(t *testing.T) {
+	docs := []*Document{{Title: "Automobile maintenance", Content: "Changing the engine oil"}}
+
+	q := PrepareLexicalQuery(HybridSearchParams{Query: "car maintenence"}, docs)
+	assert.Equal(t, "car & maintenence", q.String())
+
+	q = PrepareLexicalQuery(HybridSearchParams{
+		Query:           "car maintenence",
+		CorrectSpelling: true,
+		Synonyms:        map[string][]string{"car": {"automobile"}},
+	}, docs)
+	assert.Equal(t, "(car | automobile) & maintenance", q.String())
+	assert.Len(t, ScoreQuery(q, docs), 1)
+}
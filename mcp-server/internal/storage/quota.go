@@ -0,0 +1,67 @@
+package storage
+
+import "fmt"
+
+// Quotas a document insert is checked against
+const (
+	// QuotaDocuments limits the documents of a tenant
+	QuotaDocuments = "documents"
+	// QuotaStorageBytes limits the title and content bytes of a tenant's documents
+	QuotaStorageBytes = "storage_bytes"
+	// QuotaCollectionDocuments limits the documents of one collection
+	QuotaCollectionDocuments = "collection_documents"
+)
+
+// QuotaError is returned when a document does not fit a quota. It wraps
+// ErrQuotaExceeded and carries the details clients get in the error data.
+type QuotaError struct {
+	Quota      string // One of the Quota* constants
+	Collection string // Set for collection quotas
+	Limit      int64
+	Used       int64 // Usage before the rejected document
+}
+
+// Error implements the error interface
+func (e *QuotaError) Error() string {
+	switch e.Quota {
+	case QuotaCollectionDocuments:
+		return fmt.Sprintf("%s: collection %q holds %d of %d documents", ErrQuotaExceeded, e.Collection, e.Used, e.Limit)
+	case QuotaStorageBytes:
+		return fmt.Sprintf("%s: tenant stores %d of %d bytes", ErrQuotaExceeded, e.Used, e.Limit)
+	default:
+		return fmt.Sprintf("%s: tenant holds %d of %d documents", ErrQuotaExceeded, e.Used, e.Limit)
+	}
+}
+
+// Unwrap lets errors.Is match ErrQuotaExceeded
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Data returns structured details for the JSON-RPC error response
+func (e *QuotaError) Data() map[string]interface{} {
+	data := map[string]interface{}{
+		"quota": e.Quota,
+		"limit": e.Limit,
+		"used":  e.Used,
+	}
+	if e.Collection != "" {
+		data["collection"] = e.Collection
+	}
+	return data
+}
+
+// DocumentBytes returns the size a document counts against storage quotas: the bytes of
+// its title and content. Metadata and embeddings are not counted.
+func DocumentBytes(doc *Document) int64 {
+	return int64(len(doc.Title) + len(doc.Content))
+}
+
+// QuotaUsage is a tenant's document usage and quotas; a zero limit is unlimited
+type QuotaUsage struct {
+	TenantID        string `json:"tenant_id"`
+	Documents       int64  `json:"documents"`
+	StorageBytes    int64  `json:"storage_bytes"`
+	MaxDocuments    int64  `json:"max_documents,omitempty"`
+	MaxStorageBytes int64  `json:"max_storage_bytes,omitempty"`
+}
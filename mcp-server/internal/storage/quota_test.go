@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaError(t *testing.T) {
+	err := fmt.Errorf("failed to ingest document: %w",
+		&QuotaError{Quota: QuotaCollectionDocuments, Collection: "policies", Limit: 500, Used: 500})
+
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	var quotaErr *QuotaError
+	assert.True(t, errors.As(err, &quotaErr))
+	assert.Equal(t, `quota exceeded: collection "policies" holds 500 of 500 documents`, quotaErr.Error())
+	assert.Equal(t, map[string]interface{}{
+		"quota": QuotaCollectionDocuments, "collection": "policies", "limit": int64(500), "used": int64(500),
+	}, quotaErr.Data())
+
+	bytesErr := &QuotaError{Quota: QuotaStorageBytes, Limit: 1 << 20, Used: 1<<20 - 10}
+	assert.Equal(t, "quota exceeded: tenant stores 1048566 of 1048576 bytes", bytesErr.Error())
+	assert.NotContains(t, bytesErr.Data(), "collection")
+}
+
+func TestDocumentBytes(t *testing.T) {
+	assert.Equal(t, int64(len("Título")+len("body")), DocumentBytes(&Document{
+		Title:    "Título",
+		Content:  "body",
+		Metadata: map[string]interface{}{"ignored": true},
+	}))
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopWords are dropped from queries and documents, like Postgres' english text search config
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "to": true, "was": true, "with": true,
+}
+
+// ScoredDocument is a document with a lexical relevance or vector similarity score
+type ScoredDocument struct {
+	Document *Document
+	Score    float64
+}
+
+// Tokenize lowercases text and splits it into terms, dropping stop words
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if !stopWords[field] {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// ScoreText ranks documents containing every query term by BM25 over title and content.
+// Like plainto_tsquery, all terms must match. Results are sorted by descending score.
+func ScoreText(query string, docs []*Document) []ScoredDocument {
+	return ScoreQuery(ParseLexicalQuery(query), docs)
+}
+
+// ScoreQuery ranks documents matching every term of a lexical query by BM25 over title
+// and content. A term scores as its best matching alternative: its words or one of its
+// synonyms, whose words must all occur. Results are sorted by descending score.
+func ScoreQuery(query LexicalQuery, docs []*Document) []ScoredDocument {
+	if len(query) == 0 || len(docs) == 0 {
+		return nil
+	}
+
+	type docTerms struct {
+		doc    *Document
+		freqs  map[string]int
+		length int
+	}
+
+	corpus := make([]docTerms, 0, len(docs))
+	docFreq := make(map[string]int)
+	totalLength := 0
+	for _, doc := range docs {
+		terms := Tokenize(doc.Title + " " + doc.Content)
+		freqs := make(map[string]int)
+		for _, term := range terms {
+			freqs[term]++
+		}
+		for term := range freqs {
+			docFreq[term]++
+		}
+		corpus = append(corpus, docTerms{doc: doc, freqs: freqs, length: len(terms)})
+		totalLength += len(terms)
+	}
+	avgLength := float64(totalLength) / float64(len(corpus))
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	var results []ScoredDocument
+	for _, dt := range corpus {
+		// wordScore is the BM25 contribution of a word, or false if the document lacks it
+		wordScore := func(word string) (float64, bool) {
+			tf := float64(dt.freqs[word])
+			if tf == 0 {
+				return 0, false
+			}
+			n := float64(docFreq[word])
+			idf := math.Log(1 + (float64(len(corpus))-n+0.5)/(n+0.5))
+			return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(dt.length)/avgLength)), true
+		}
+
+		score := 0.0
+		matched := true
+		for _, term := range query {
+			best, found := 0.0, false
+			for _, words := range term.Alternatives() {
+				sum, all := 0.0, true
+				for _, word := range words {
+					s, ok := wordScore(word)
+					if !ok {
+						all = false
+						break
+					}
+					sum += s
+				}
+				if all && (!found || sum > best) {
+					best, found = sum, true
+				}
+			}
+			if !found {
+				matched = false
+				break
+			}
+			score += best
+		}
+		if matched {
+			results = append(results, ScoredDocument{Document: dt.doc, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 if they are incomparable
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// FuseHybrid combines lexical matches and brute-force vector similarity over docs
+// with the fusion method selected in params, mirroring database.DB.HybridSearch.
+func FuseHybrid(text []ScoredDocument, docs []*Document, params HybridSearchParams) ([]HybridSearchResult, error) {
+	fusion, err := NewFusion(params.Fusion, params.RRFK)
+	if err != nil {
+		return nil, err
+	}
+	return FuseRankings(fusion, text, rankByVector(params.Embedding, docs), params), nil
+}
+
+// FuseWeighted combines lexical and vector scores with a weighted sum,
+// mirroring database.DB.SimpleHybridSearch.
+func FuseWeighted(text []ScoredDocument, docs []*Document, params HybridSearchParams) []HybridSearchResult {
+	bm25Weight, vectorWeight, limit := normalizeParams(params)
+
+	textScores := make(map[string]float64, len(text))
+	for _, match := range text {
+		textScores[match.Document.ID] = match.Score
+	}
+
+	var results []HybridSearchResult
+	for _, doc := range docs {
+		bm25Score, textMatch := textScores[doc.ID]
+		vectorScore := 0.0
+		if len(doc.Embedding) > 0 {
+			vectorScore = CosineSimilarity(params.Embedding, doc.Embedding)
+		}
+		if !textMatch && (len(doc.Embedding) == 0 || vectorScore < params.MinVectorSim) {
+			continue
+		}
+
+		results = append(results, HybridSearchResult{
+			Document:      *doc,
+			BM25Score:     bm25Score,
+			VectorScore:   vectorScore,
+			CombinedScore: bm25Score*bm25Weight + vectorScore*vectorWeight,
+		})
+	}
+	return topResults(results, limit)
+}
+
+// rankByVector scores documents with embeddings by similarity to the query embedding
+func rankByVector(embedding []float32, docs []*Document) []ScoredDocument {
+	if len(embedding) == 0 {
+		return nil
+	}
+
+	var results []ScoredDocument
+	for _, doc := range docs {
+		if len(doc.Embedding) == 0 {
+			continue
+		}
+		results = append(results, ScoredDocument{Document: doc, Score: CosineSimilarity(embedding, doc.Embedding)})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// RankSimilar returns up to limit of docs ordered by the similarity of their embedding to
+// that of doc (10 when limit is not positive), leaving out doc itself, mirroring database.DB.SimilarDocuments
+func RankSimilar(doc *Document, docs []*Document, limit int) ([]ScoredDocument, error) {
+	if len(doc.Embedding) == 0 {
+		return nil, ErrNoEmbedding
+	}
+
+	others := make([]*Document, 0, len(docs))
+	for _, candidate := range docs {
+		if candidate.ID != doc.ID {
+			others = append(others, candidate)
+		}
+	}
+	results := rankByVector(doc.Embedding, others)
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// normalizeParams applies the same weight normalization and limit default as Postgres
+func normalizeParams(params HybridSearchParams) (bm25Weight, vectorWeight float64, limit int) {
+	total := params.BM25Weight + params.VectorWeight
+	if total == 0 {
+		params.BM25Weight, params.VectorWeight, total = 0.5, 0.5, 1.0
+	}
+
+	limit = params.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	return params.BM25Weight / total, params.VectorWeight / total, limit
+}
+
+// topResults sorts results by combined score and truncates them to limit
+func topResults(results []HybridSearchResult, limit int) []HybridSearchResult {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].CombinedScore > results[j].CombinedScore
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"password", "policy", "2024"}, Tokenize("The Password-Policy for 2024!"))
+	assert.Empty(t, Tokenize("the and of"))
+}
+
+func TestScoreText(t *testing.T) {
+	docs := []*Document{
+		{ID: "1", Title: "Password policy", Content: "password rotation and password length"},
+		{ID: "2", Title: "Policy", Content: "general policy"},
+		{ID: "3", Title: "Password", Content: "reset instructions"},
+	}
+
+	results := ScoreText("password policy", docs)
+	if assert.Len(t, results, 1, "all query terms must match") {
+		assert.Equal(t, "1", results[0].Document.ID)
+	}
+
+	results = ScoreText("password", docs)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "1", results[0].Document.ID, "higher term frequency ranks first")
+	}
+
+	assert.Empty(t, ScoreText("the", docs))
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{1}, []float32{1, 2}))
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+}
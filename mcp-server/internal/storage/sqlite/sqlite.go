@@ -0,0 +1,463 @@
+// Package sqlite implements storage.Store on SQLite for running the MCP server
+// without Postgres. Full-text search uses FTS5 when the driver is built with the
+// sqlite_fts5 tag and falls back to in-process BM25 otherwise; vector search is
+// brute force over the tenant's documents.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS documents (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	collection TEXT NOT NULL DEFAULT 'default',
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	metadata TEXT NOT NULL DEFAULT '{}',
+	embedding BLOB,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	created_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_tenant ON documents(tenant_id, created_at DESC);
+`
+
+// collectionSchema runs after databases created before collections have gained the column
+const collectionSchema = `
+CREATE INDEX IF NOT EXISTS idx_documents_collection ON documents(tenant_id, collection, created_at DESC);
+`
+
+const ftsSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS documents_fts USING fts5(
+	title, content, content='documents', content_rowid='rowid'
+);
+
+CREATE TRIGGER IF NOT EXISTS documents_fts_insert AFTER INSERT ON documents BEGIN
+	INSERT INTO documents_fts(rowid, title, content) VALUES (new.rowid, new.title, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS documents_fts_delete AFTER DELETE ON documents BEGIN
+	INSERT INTO documents_fts(documents_fts, rowid, title, content) VALUES ('delete', old.rowid, old.title, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS documents_fts_update AFTER UPDATE ON documents BEGIN
+	INSERT INTO documents_fts(documents_fts, rowid, title, content) VALUES ('delete', old.rowid, old.title, old.content);
+	INSERT INTO documents_fts(rowid, title, content) VALUES (new.rowid, new.title, new.content);
+END;
+`
+
+const documentColumns = `id, tenant_id, collection, title, content, metadata, embedding, created_at, updated_at, created_by`
+
+// Store is a SQLite-backed document store
+type Store struct {
+	db  *sql.DB
+	fts bool
+}
+
+// Ensure Store implements storage.ReadWriter interface
+var _ storage.ReadWriter = (*Store)(nil)
+
+// Open opens (and creates if needed) a SQLite database at path.
+// Use ":memory:" for a throwaway database.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// A single connection keeps ":memory:" databases alive and serializes writes
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	if err := addCollectionColumn(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &Store{db: db, fts: true}
+	if _, err := db.ExecContext(ctx, ftsSchema); err != nil {
+		if !strings.Contains(err.Error(), "no such module: fts5") {
+			db.Close()
+			return nil, fmt.Errorf("failed to create full-text index: %w", err)
+		}
+		slog.Warn("SQLite built without FTS5 (build with -tags sqlite_fts5); using in-process text search")
+		s.fts = false
+	}
+
+	return s, nil
+}
+
+// addCollectionColumn adds the collection column to databases created without it
+func addCollectionColumn(ctx context.Context, db *sql.DB) error {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) > 0 FROM pragma_table_info('documents') WHERE name = 'collection'`,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if !exists {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE documents ADD COLUMN collection TEXT NOT NULL DEFAULT 'default'`); err != nil {
+			return fmt.Errorf("failed to add collection column: %w", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, collectionSchema); err != nil {
+		return fmt.Errorf("failed to create collection index: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Ping checks that the database file can still be read
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// GetDocument retrieves a document by ID for a specific tenant
+func (s *Store) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+documentColumns+` FROM documents WHERE tenant_id = ? AND id = ?`,
+		tenantID, docID,
+	)
+
+	doc, err := scanDocument(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	return doc, nil
+}
+
+// SearchDocuments matches the query as a case-insensitive substring of the title,
+// content or metadata, newest first. Metadata filters are applied in process.
+func (s *Store) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	sqlLimit := limit
+	if len(filter) > 0 {
+		sqlLimit = -1 // no limit; filtering happens after the query
+	}
+
+	pattern := "%" + query + "%"
+	documents, err := s.queryDocuments(ctx, `
+		SELECT `+documentColumns+` FROM documents
+		WHERE tenant_id = ? AND collection = ? AND (title LIKE ? OR content LIKE ? OR metadata LIKE ?)
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, tenantID, storage.CollectionOrDefault(collection), pattern, pattern, pattern, sqlLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	documents = filter.Apply(documents)
+	if len(documents) > limit {
+		documents = documents[:limit]
+	}
+	return documents, nil
+}
+
+// ListDocuments lists the documents of a collection with pagination, newest first
+func (s *Store) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*storage.Document, error) {
+	return s.queryDocuments(ctx, `
+		SELECT `+documentColumns+` FROM documents
+		WHERE tenant_id = ? AND collection = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, tenantID, storage.CollectionOrDefault(collection), limit, offset)
+}
+
+// HybridSearch performs BM25 + brute-force vector search fused with params.Fusion
+func (s *Store) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	if _, err := storage.NewFusion(params.Fusion, params.RRFK); err != nil {
+		return nil, err
+	}
+	docs, text, err := s.hybridInputs(ctx, tenantID, params)
+	if err != nil {
+		return nil, err
+	}
+	return storage.FuseHybrid(text, docs, params)
+}
+
+// SimpleHybridSearch performs weighted BM25 + brute-force vector search
+func (s *Store) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
+	docs, text, err := s.hybridInputs(ctx, tenantID, params)
+	if err != nil {
+		return nil, err
+	}
+	return storage.FuseWeighted(text, docs, params), nil
+}
+
+// SimilarDocuments ranks the other documents of the document's collection by brute-force
+// cosine similarity to its embedding. Metadata filters are applied in process.
+func (s *Store) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	doc, err := s.GetDocument(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Embedding) == 0 {
+		return nil, storage.ErrNoEmbedding
+	}
+
+	docs, err := s.queryDocuments(ctx, `
+		SELECT `+documentColumns+` FROM documents
+		WHERE tenant_id = ? AND collection = ? AND embedding IS NOT NULL
+		ORDER BY created_at DESC
+	`, tenantID, storage.CollectionOrDefault(doc.Collection))
+	if err != nil {
+		return nil, err
+	}
+	return storage.RankSimilar(doc, filter.Apply(docs), limit)
+}
+
+// InsertDocument inserts a new document and fills in its ID and timestamps
+func (s *Store) InsertDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
+	id, err := storage.NewDocumentID()
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(doc.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	now := time.Now().UTC()
+	collection := storage.CollectionOrDefault(doc.Collection)
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO documents (`+documentColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, tenantID, collection, doc.Title, doc.Content, string(metadata), encodeEmbedding(doc.Embedding), now, now, doc.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to insert document: %w", err)
+	}
+
+	doc.ID = id
+	doc.TenantID = tenantID
+	doc.Collection = collection
+	doc.CreatedAt = now
+	doc.UpdatedAt = now
+	return nil
+}
+
+// UpdateDocument updates a document's title, content, metadata and embedding
+func (s *Store) UpdateDocument(ctx context.Context, tenantID string, doc *storage.Document) error {
+	metadata, err := json.Marshal(doc.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE documents
+		SET title = ?, content = ?, metadata = ?, embedding = ?, updated_at = ?
+		WHERE tenant_id = ? AND id = ?
+	`, doc.Title, doc.Content, string(metadata), encodeEmbedding(doc.Embedding), now, tenantID, doc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return storage.ErrNotFound
+	}
+
+	doc.UpdatedAt = now
+	return nil
+}
+
+// DeleteDocument deletes a document
+func (s *Store) DeleteDocument(ctx context.Context, tenantID, docID string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE tenant_id = ? AND id = ?`, tenantID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// hybridInputs loads the collection's documents matching the filter for vector scoring
+// and ranks them by text relevance to the preprocessed query
+func (s *Store) hybridInputs(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]*storage.Document, []storage.ScoredDocument, error) {
+	docs, err := s.queryDocuments(ctx,
+		`SELECT `+documentColumns+` FROM documents WHERE tenant_id = ? AND collection = ? ORDER BY created_at DESC`,
+		tenantID, storage.CollectionOrDefault(params.Collection),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs = params.Filters.Apply(docs)
+	query := storage.PrepareLexicalQuery(params, docs)
+
+	if !s.fts {
+		return docs, storage.ScoreQuery(query, docs), nil
+	}
+
+	text, err := s.ftsSearch(ctx, tenantID, query, docs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return docs, text, nil
+}
+
+// ftsSearch ranks the tenant's documents with FTS5's bm25(), requiring every query term
+func (s *Store) ftsSearch(ctx context.Context, tenantID string, query storage.LexicalQuery, docs []*storage.Document) ([]storage.ScoredDocument, error) {
+	if len(query) == 0 {
+		return nil, nil
+	}
+
+	// bm25() returns lower-is-better scores, so negate them
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT d.id, -bm25(documents_fts) AS score
+		FROM documents_fts
+		JOIN documents d ON d.rowid = documents_fts.rowid
+		WHERE documents_fts MATCH ? AND d.tenant_id = ?
+		ORDER BY score DESC
+	`, ftsQuery(query), tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform full-text search: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*storage.Document, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+	}
+
+	var results []storage.ScoredDocument
+	for rows.Next() {
+		var id string
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan full-text result: %w", err)
+		}
+		if doc, ok := byID[id]; ok {
+			results = append(results, storage.ScoredDocument{Document: doc, Score: score})
+		}
+	}
+	return results, rows.Err()
+}
+
+// ftsQuery formats a lexical query as an FTS5 match expression, e.g.
+// ("car" OR "automobile") AND "engine"
+func ftsQuery(query storage.LexicalQuery) string {
+	terms := make([]string, len(query))
+	for i, term := range query {
+		alternatives := term.Alternatives()
+		formatted := make([]string, len(alternatives))
+		for j, words := range alternatives {
+			quoted := make([]string, len(words))
+			for k, word := range words {
+				quoted[k] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+			}
+			formatted[j] = strings.Join(quoted, " AND ")
+			if len(words) > 1 && len(alternatives) > 1 {
+				formatted[j] = "(" + formatted[j] + ")"
+			}
+		}
+		terms[i] = strings.Join(formatted, " OR ")
+		if len(alternatives) > 1 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// queryDocuments runs a query selecting documentColumns
+func (s *Store) queryDocuments(ctx context.Context, query string, args ...interface{}) ([]*storage.Document, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*storage.Document
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+	return documents, rows.Err()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDocument scans a row selected with documentColumns
+func scanDocument(row scanner) (*storage.Document, error) {
+	doc := &storage.Document{}
+	var metadata string
+	var embedding []byte
+	var createdBy sql.NullString
+
+	if err := row.Scan(
+		&doc.ID,
+		&doc.TenantID,
+		&doc.Collection,
+		&doc.Title,
+		&doc.Content,
+		&metadata,
+		&embedding,
+		&doc.CreatedAt,
+		&doc.UpdatedAt,
+		&createdBy,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(metadata), &doc.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	doc.Embedding = decodeEmbedding(embedding)
+	if createdBy.Valid {
+		doc.CreatedBy = &createdBy.String
+	}
+	return doc, nil
+}
+
+// encodeEmbedding stores a vector as little-endian float32s
+func encodeEmbedding(embedding []float32) []byte {
+	if len(embedding) == 0 {
+		return nil
+	}
+	buf := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeEmbedding reverses encodeEmbedding
+func decodeEmbedding(buf []byte) []float32 {
+	if len(buf) == 0 {
+		return nil
+	}
+	embedding := make([]float32, len(buf)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return embedding
+}
@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openStore(t *testing.T) *Store {
+	store, err := Open(context.Background(), filepath.Join(t.TempDir(), "mcp.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStore_CRUD(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+
+	createdBy := "user-1"
+	doc := &storage.Document{
+		Title:     "Title",
+		Content:   "Content",
+		Metadata:  map[string]interface{}{"category": "security"},
+		Embedding: []float32{0.25, -1.5, 3},
+		CreatedBy: &createdBy,
+	}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", doc))
+	assert.NotEmpty(t, doc.ID)
+
+	got, err := store.GetDocument(ctx, "tenant-1", doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", got.Title)
+	assert.Equal(t, "security", got.Metadata["category"])
+	assert.Equal(t, []float32{0.25, -1.5, 3}, got.Embedding)
+	require.NotNil(t, got.CreatedBy)
+	assert.Equal(t, "user-1", *got.CreatedBy)
+
+	_, err = store.GetDocument(ctx, "tenant-2", doc.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	doc.Title = "Updated"
+	require.NoError(t, store.UpdateDocument(ctx, "tenant-1", doc))
+	got, err = store.GetDocument(ctx, "tenant-1", doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", got.Title)
+
+	require.NoError(t, store.DeleteDocument(ctx, "tenant-1", doc.ID))
+	_, err = store.GetDocument(ctx, "tenant-1", doc.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, store.UpdateDocument(ctx, "tenant-1", doc), storage.ErrNotFound)
+}
+
+func TestStore_Search(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+
+	docs := []*storage.Document{
+		{Title: "Password Policy", Content: "Passwords must rotate every 90 days", Embedding: []float32{1, 0}, Metadata: map[string]interface{}{"tags": []interface{}{"security", "passwords"}}},
+		{Title: "Vacation Policy", Content: "Employees receive 20 vacation days", Embedding: []float32{0, 1}, Metadata: map[string]interface{}{"tags": []interface{}{"hr"}}},
+	}
+	for _, doc := range docs {
+		require.NoError(t, store.InsertDocument(ctx, "tenant-1", doc))
+	}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-2", &storage.Document{Title: "Password Policy", Content: "Other tenant"}))
+
+	found, err := store.SearchDocuments(ctx, "tenant-1", "", "policy", 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	found, err = store.SearchDocuments(ctx, "tenant-1", "", "policy", 10, storage.MetadataFilter{"tags": {"hr"}})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "Vacation Policy", found[0].Title)
+
+	listed, err := store.ListDocuments(ctx, "tenant-1", "", 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	params := storage.HybridSearchParams{
+		Query:        "password",
+		Embedding:    []float32{1, 0},
+		Limit:        10,
+		BM25Weight:   0.5,
+		VectorWeight: 0.5,
+		MinVectorSim: 0.5,
+	}
+	results, err := store.HybridSearch(ctx, "tenant-1", params)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+	assert.Greater(t, results[0].BM25Score, 0.0)
+	for _, result := range results {
+		assert.Equal(t, "tenant-1", result.Document.TenantID)
+	}
+
+	// The update trigger keeps the full-text index in sync
+	docs[1].Content = "Password resets are handled by IT"
+	require.NoError(t, store.UpdateDocument(ctx, "tenant-1", docs[1]))
+	results, err = store.SimpleHybridSearch(ctx, "tenant-1", storage.HybridSearchParams{Query: "password", Limit: 10, BM25Weight: 1})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// Spelling correction and synonyms
+	results, err = store.HybridSearch(ctx, "tenant-1", storage.HybridSearchParams{Query: "pasword rotate", Limit: 10, CorrectSpelling: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+	results, err = store.HybridSearch(ctx, "tenant-1", storage.HybridSearchParams{
+		Query: "credentials rotate", Limit: 10,
+		Synonyms: map[string][]string{"credentials": {"password", "secret key"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Password Policy", results[0].Document.Title)
+}
+
+func TestStore_SimilarDocuments(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+
+	docs := []*storage.Document{
+		{Title: "Password Policy", Embedding: []float32{1, 0}, Metadata: map[string]interface{}{"category": "security"}},
+		{Title: "Password Policy (copy)", Embedding: []float32{0.99, 0.01}, Metadata: map[string]interface{}{"category": "security"}},
+		{Title: "Vacation Policy", Embedding: []float32{0, 1}, Metadata: map[string]interface{}{"category": "hr"}},
+		{Title: "No Embedding"},
+		{Title: "Other Collection", Collection: "tickets", Embedding: []float32{1, 0}},
+	}
+	for _, doc := range docs {
+		require.NoError(t, store.InsertDocument(ctx, "tenant-1", doc))
+	}
+
+	results, err := store.SimilarDocuments(ctx, "tenant-1", docs[0].ID, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, docs[1].ID, results[0].Document.ID)
+	assert.InDelta(t, 1.0, results[0].Score, 0.01)
+	assert.Equal(t, docs[2].ID, results[1].Document.ID)
+
+	results, err = store.SimilarDocuments(ctx, "tenant-1", docs[0].ID, 10, storage.MetadataFilter{"category": {"hr"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Vacation Policy", results[0].Document.Title)
+
+	_, err = store.SimilarDocuments(ctx, "tenant-2", docs[0].ID, 10, nil)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = store.SimilarDocuments(ctx, "tenant-1", docs[3].ID, 10, nil)
+	assert.ErrorIs(t, err, storage.ErrNoEmbedding)
+}
+
+func TestFTSQuery(t *testing.T) {
+	q := storage.ParseLexicalQuery("car engine").ExpandSynonyms(map[string][]string{"car": {"automobile", "motor vehicle"}})
+	assert.Equal(t, `("car" OR "automobile" OR ("motor" AND "vehicle")) AND "engine"`, ftsQuery(q))
+}
+
+func TestStore_Collections(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", &storage.Document{Title: "Password Policy", Content: "Rotate passwords", Embedding: []float32{1, 0}}))
+	ticket := &storage.Document{Collection: "tickets", Title: "Password ticket", Content: "Cannot reset password", Embedding: []float32{1, 0}}
+	require.NoError(t, store.InsertDocument(ctx, "tenant-1", ticket))
+
+	got, err := store.GetDocument(ctx, "tenant-1", ticket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "tickets", got.Collection)
+
+	found, err := store.SearchDocuments(ctx, "tenant-1", "tickets", "password", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, ticket.ID, found[0].ID)
+
+	found, err = store.SearchDocuments(ctx, "tenant-1", "", "password", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, storage.DefaultCollection, found[0].Collection)
+
+	listed, err := store.ListDocuments(ctx, "tenant-1", "tickets", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	results, err := store.HybridSearch(ctx, "tenant-1", storage.HybridSearchParams{
+		Collection: "tickets", Query: "password", Embedding: []float32{1, 0}, Limit: 10, BM25Weight: 0.5, VectorWeight: 0.5,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, ticket.ID, results[0].Document.ID)
+}
@@ -0,0 +1,111 @@
+// Package storage defines the document store used by the MCP tools and the
+// backends that implement it: Postgres (internal/database), in-memory and SQLite.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Document represents a document with embeddings
+type Document struct {
+	ID         string                 `json:"id"`
+	TenantID   string                 `json:"tenant_id"`
+	Collection string                 `json:"collection"` // Named corpus within the tenant; empty means DefaultCollection
+	Title      string                 `json:"title"`
+	Content    string                 `json:"content"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Embedding  []float32              `json:"embedding,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	CreatedBy  *string                `json:"created_by,omitempty"` // Use pointer to handle NULL
+}
+
+// HybridSearchParams holds parameters for hybrid search
+type HybridSearchParams struct {
+	Collection   string // Collection searched; empty searches DefaultCollection
+	Query        string
+	Embedding    []float32
+	Limit        int
+	BM25Weight   float64 // Weight for lexical search (0.0 to 1.0)
+	VectorWeight float64 // Weight for semantic search (0.0 to 1.0)
+	MinBM25Score float64 // Minimum BM25 score threshold
+	MinVectorSim float64 // Minimum vector similarity threshold
+	Filters      MetadataFilter
+	Fusion       string // Fusion method used by HybridSearch (see NewFusion); empty selects RRF
+	RRFK         int    // RRF constant k; 0 selects DefaultRRFK
+	EfSearch     int    // HNSW candidate list size (hnsw.ef_search); 0 keeps the server setting
+	Probes       int    // IVFFlat lists probed (ivfflat.probes); 0 keeps the server setting
+
+	// Lexical query preprocessing (see PrepareLexicalQuery)
+	CorrectSpelling bool                // replace words missing from the collection with the most similar word
+	Synonyms        map[string][]string // word or phrase -> equivalent words or phrases matched as alternatives
+}
+
+// Largest EfSearch and Probes pgvector accepts; larger values raise recall at the cost
+// of latency
+const (
+	MaxEfSearch = 1000
+	MaxProbes   = 32768
+)
+
+// HybridSearchResult represents a result from hybrid search
+type HybridSearchResult struct {
+	Document      Document
+	BM25Score     float64
+	VectorScore   float64
+	CombinedScore float64
+}
+
+// Store defines the interface for database operations
+// This interface enables testing with mocks
+type Store interface {
+	// GetDocument retrieves a document by ID for a specific tenant
+	GetDocument(ctx context.Context, tenantID, docID string) (*Document, error)
+
+	// SearchDocuments performs full-text search on the documents of a collection matching
+	// the metadata filter; an empty collection searches DefaultCollection
+	SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter MetadataFilter) ([]*Document, error)
+
+	// ListDocuments lists the documents of a tenant's collection with pagination
+	ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*Document, error)
+
+	// HybridSearch performs hybrid BM25 + vector search, combining the rankings with params.Fusion
+	HybridSearch(ctx context.Context, tenantID string, params HybridSearchParams) ([]HybridSearchResult, error)
+
+	// SimpleHybridSearch performs simple weighted hybrid search
+	SimpleHybridSearch(ctx context.Context, tenantID string, params HybridSearchParams) ([]HybridSearchResult, error)
+
+	// SimilarDocuments returns up to limit documents of a document's collection matching the
+	// metadata filter, ordered by the cosine similarity of their embedding to its embedding;
+	// the document itself is left out. It returns ErrNotFound for an unknown document and
+	// ErrNoEmbedding for a document without an embedding.
+	SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter MetadataFilter) ([]ScoredDocument, error)
+}
+
+// Writer defines the document write operations
+type Writer interface {
+	// InsertDocument inserts a new document into its collection and fills in its ID and
+	// timestamps. Backends that enforce tenant and collection quotas return a *QuotaError.
+	InsertDocument(ctx context.Context, tenantID string, doc *Document) error
+
+	// UpdateDocument updates a document's title, content, metadata and embedding; documents
+	// stay in the collection they were inserted into
+	UpdateDocument(ctx context.Context, tenantID string, doc *Document) error
+
+	// DeleteDocument deletes a document
+	DeleteDocument(ctx context.Context, tenantID, docID string) error
+}
+
+// ReadWriter is a store that also accepts writes
+type ReadWriter interface {
+	Store
+	Writer
+}
+
+// ErrNotFound is returned when a document does not exist for the tenant
+var ErrNotFound = errors.New("document not found")
+
+// ErrNoEmbedding is returned when a similarity search starts from a document without an embedding
+var ErrNoEmbedding = errors.New("document has no embedding")
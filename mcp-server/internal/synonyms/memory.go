@@ -0,0 +1,49 @@
+package synonyms
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps dictionaries in memory, for the local storage drivers and tests
+type MemoryStore struct {
+	mu           sync.RWMutex
+	dictionaries map[string]Dictionary // tenant ID -> dictionary
+}
+
+// Ensure MemoryStore implements Store
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory synonym store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{dictionaries: make(map[string]Dictionary)}
+}
+
+// GetSynonyms implements Store
+func (s *MemoryStore) GetSynonyms(ctx context.Context, tenantID string) (Dictionary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dictionary := make(Dictionary, len(s.dictionaries[tenantID]))
+	for key, values := range s.dictionaries[tenantID] {
+		dictionary[key] = append([]string(nil), values...)
+	}
+	return dictionary, nil
+}
+
+// PutSynonyms implements Store
+func (s *MemoryStore) PutSynonyms(ctx context.Context, tenantID string, dictionary Dictionary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(dictionary) == 0 {
+		delete(s.dictionaries, tenantID)
+		return nil
+	}
+	stored := make(Dictionary, len(dictionary))
+	for key, values := range dictionary {
+		stored[key] = append([]string(nil), values...)
+	}
+	s.dictionaries[tenantID] = stored
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package synonyms manages tenant synonym dictionaries: words and phrases with their
+// equivalents, which the search tools add as alternatives to the lexical query when
+// called with "expand_synonyms".
+package synonyms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// SettingsKey is the tenant settings key that holds the dictionary
+const SettingsKey = "synonyms"
+
+// Dictionary limits keep query expansion cheap
+const (
+	MaxEntries  = 1000
+	MaxSynonyms = 20
+)
+
+// Dictionary maps a word or phrase to its synonyms. Entries are one-way: "car":
+// ["automobile"] finds automobiles for "car" but not cars for "automobile".
+type Dictionary map[string][]string
+
+// Store persists a synonym dictionary per tenant
+type Store interface {
+	// GetSynonyms returns a tenant's dictionary, empty when none is configured
+	GetSynonyms(ctx context.Context, tenantID string) (Dictionary, error)
+
+	// PutSynonyms replaces a tenant's dictionary; an empty dictionary removes it
+	PutSynonyms(ctx context.Context, tenantID string, dictionary Dictionary) error
+}
+
+// Validate checks the dictionary's size and that every word, phrase and synonym has a
+// searchable word, i.e. is not only stop words or punctuation
+func (d Dictionary) Validate() error {
+	if len(d) > MaxEntries {
+		return fmt.Errorf("a dictionary has at most %d entries", MaxEntries)
+	}
+	for key, values := range d {
+		if len(storage.Tokenize(key)) == 0 {
+			return fmt.Errorf("%q has no searchable words", key)
+		}
+		if len(values) == 0 || len(values) > MaxSynonyms {
+			return fmt.Errorf("%q must have between 1 and %d synonyms", key, MaxSynonyms)
+		}
+		for _, value := range values {
+			if len(storage.Tokenize(value)) == 0 {
+				return fmt.Errorf("synonym %q of %q has no searchable words", value, key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package synonyms
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDictionaryValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		dictionary Dictionary
+		wantErr    bool
+	}{
+		{"empty", Dictionary{}, false},
+		{"words and phrases", Dictionary{"car": {"automobile", "motor vehicle"}, "machine learning": {"ml"}}, false},
+		{"stop word key", Dictionary{"the": {"a"}}, true},
+		{"punctuation synonym", Dictionary{"car": {"--"}}, true},
+		{"no synonyms", Dictionary{"car": {}}, true},
+		{"too many synonyms", Dictionary{"car": strings.Fields(strings.Repeat("auto ", MaxSynonyms+1))}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dictionary.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	dictionary, err := store.GetSynonyms(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, dictionary)
+
+	require.NoError(t, store.PutSynonyms(ctx, "tenant-1", Dictionary{"car": {"automobile"}}))
+	dictionary, err = store.GetSynonyms(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, Dictionary{"car": {"automobile"}}, dictionary)
+
+	// Dictionaries are per tenant and copied in and out
+	dictionary["car"][0] = "changed"
+	other, err := store.GetSynonyms(ctx, "tenant-2")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+	dictionary, err = store.GetSynonyms(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, "automobile", dictionary["car"][0])
+
+	require.NoError(t, store.PutSynonyms(ctx, "tenant-1", Dictionary{}))
+	dictionary, err = store.GetSynonyms(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, dictionary)
+}
@@ -0,0 +1,31 @@
+package tools
+
+import "context"
+
+// AccessRecorder records which documents were read through a tool
+type AccessRecorder interface {
+	RecordAccess(ctx context.Context, tool string, documentIDs ...string)
+}
+
+// AccessRecorderSetter is implemented by tools that return document content
+type AccessRecorderSetter interface {
+	SetAccessRecorder(recorder AccessRecorder)
+}
+
+// documentAccess is embedded in tools that return document content
+type documentAccess struct {
+	recorder AccessRecorder
+}
+
+// SetAccessRecorder sets the recorder used to log document reads
+func (d *documentAccess) SetAccessRecorder(recorder AccessRecorder) {
+	d.recorder = recorder
+}
+
+// recordAccess forwards document reads to the recorder if one is configured
+func (d *documentAccess) recordAccess(ctx context.Context, tool string, documentIDs ...string) {
+	if d.recorder == nil || len(documentIDs) == 0 {
+		return
+	}
+	d.recorder.RecordAccess(ctx, tool, documentIDs...)
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/embeddings"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/llm"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+)
+
+// Answer limits
+const (
+	DefaultAnswerSources = 5
+	MaxAnswerSources     = 20
+	// maxPassageTokens caps the tokens of a single document in the context window
+	maxPassageTokens = 1000
+	// maxContextTokens caps the tokens of all passages in the context window
+	maxContextTokens = 4000
+)
+
+// answerSystemPrompt instructs the model to answer from the passages and cite them
+const answerSystemPrompt = "You answer questions using only the numbered passages provided. " +
+	"Cite the passages that support each statement with their markers, e.g. [1] or [2][3]. " +
+	"If the passages do not contain the answer, say that you do not know."
+
+// AnswerTool answers questions with retrieval-augmented generation: it retrieves the most
+// relevant documents, passes them to the configured LLM as numbered passages and returns
+// the answer together with the documents it was grounded in
+type AnswerTool struct {
+	documentAccess
+	db       storage.Store
+	llm      llm.Provider
+	model    string
+	embedder embeddings.Provider
+}
+
+// NewAnswerTool creates an answer tool generating answers with provider
+func NewAnswerTool(db storage.Store, provider llm.Provider) *AnswerTool {
+	return &AnswerTool{db: db, llm: provider}
+}
+
+// SetEmbedder computes the question embedding for hybrid retrieval when the caller does
+// not pass one. Without an embedding, documents are retrieved by lexical search only.
+func (t *AnswerTool) SetEmbedder(embedder embeddings.Provider) {
+	t.embedder = embedder
+}
+
+// SetModel names the model the provider calls, so that passages are counted with its
+// tokenizer and fit its context window
+func (t *AnswerTool) SetModel(model string) {
+	t.model = model
+}
+
+// Definition returns the tool definition for MCP
+func (t *AnswerTool) Definition() protocol.Tool {
+	return protocol.Tool{
+		Name: "answer_question",
+		Description: "Answer a question from the tenant's documents. The most relevant documents are retrieved with hybrid search " +
+			"and passed to the server's language model, which answers with citation markers such as [1]. " +
+			"Returns the answer and, as JSON, the cited source documents with their scores.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"question": map[string]interface{}{
+					"type":        "string",
+					"description": "The question to answer",
+					"maxLength":   2000,
+				},
+				"embedding": map[string]interface{}{
+					"type":        "array",
+					"description": "Question embedding vector; computed by the server when omitted and an embeddings provider is configured",
+					"items": map[string]interface{}{
+						"type": "number",
+					},
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of source documents given to the model (default: %d, max: %d)", DefaultAnswerSources, MaxAnswerSources),
+					"minimum":     1,
+					"maximum":     MaxAnswerSources,
+				},
+				"collection": collectionSchema(),
+				"filters":    filtersSchema(),
+			},
+			"required": []string{"question"},
+		},
+	}
+}
+
+// AnswerParams represents the parameters for answer_question
+type AnswerParams struct {
+	Question   string                 `json:"question"`
+	Embedding  []float32              `json:"embedding,omitempty"`
+	Limit      int                    `json:"limit,omitempty"`
+	Collection string                 `json:"collection,omitempty"`
+	Filters    map[string]interface{} `json:"filters,omitempty"`
+}
+
+// AnswerSource is a document given to the model, cited in the answer as [Marker]
+type AnswerSource struct {
+	Marker     int     `json:"marker"`
+	DocID      string  `json:"doc_id"`
+	Title      string  `json:"title"`
+	Collection string  `json:"collection"`
+	Score      float64 `json:"score"`
+}
+
+// AnswerResult is the structured result of answer_question
+type AnswerResult struct {
+	Answer       string         `json:"answer"`
+	Sources      []AnswerSource `json:"sources"`
+	Model        string         `json:"model,omitempty"`
+	InputTokens  int            `json:"input_tokens,omitempty"`
+	OutputTokens int            `json:"output_tokens,omitempty"`
+}
+
+// Execute answers a question
+func (t *AnswerTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	var params AnswerParams
+	if err := json.Unmarshal(argsJSON, &params); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	params.Question = strings.TrimSpace(params.Question)
+	if params.Question == "" {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("question is required")
+	}
+	if params.Limit <= 0 {
+		params.Limit = DefaultAnswerSources
+	}
+	params.Limit = min(params.Limit, MaxAnswerSources)
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+	filter, err := storage.ParseMetadataFilter(params.Filters)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	documents, scores, err := t.retrieve(ctx, tenantID, params, filter)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("search failed: %w", err)
+	}
+
+	result := AnswerResult{Sources: make([]AnswerSource, 0, len(documents))}
+	if len(documents) == 0 {
+		// Without sources the model could only guess, so it is not asked
+		result.Answer = "No documents were found that could answer the question."
+		return answerToolResult(result)
+	}
+
+	// Passages are added in rank order until the context budget is spent
+	budget := tokens.NewBudget(t.model, min(maxContextTokens, tokens.ContextWindow(t.model)/2))
+	docIDs := make([]string, 0, len(documents))
+	var passages strings.Builder
+	for i, doc := range documents {
+		passage, ok := budget.Take(fmt.Sprintf("[%d] %s\n%s", i+1, doc.Title, doc.Content), maxPassageTokens)
+		if !ok {
+			break
+		}
+		passages.WriteString(passage + "\n\n")
+		docIDs = append(docIDs, doc.ID)
+		result.Sources = append(result.Sources, AnswerSource{
+			Marker:     i + 1,
+			DocID:      doc.ID,
+			Title:      doc.Title,
+			Collection: storage.CollectionOrDefault(doc.Collection),
+			Score:      scores[i],
+		})
+	}
+	t.recordAccess(ctx, "answer_question", docIDs...)
+
+	response, err := t.llm.Complete(ctx, llm.Request{
+		System: answerSystemPrompt,
+		Messages: []llm.Message{{
+			Role:    llm.RoleUser,
+			Content: fmt.Sprintf("Passages:\n\n%sQuestion: %s", passages.String(), params.Question),
+		}},
+	})
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to generate answer: %w", err)
+	}
+	result.Answer = strings.TrimSpace(response.Text)
+	result.Model = response.Model
+	result.InputTokens = response.InputTokens
+	result.OutputTokens = response.OutputTokens
+	return answerToolResult(result)
+}
+
+// retrieve returns the documents most relevant to the question with their scores. With
+// an embedding the lexical and vector rankings are fused with reciprocal rank fusion;
+// otherwise documents come from lexical search, which does not score them.
+func (t *AnswerTool) retrieve(ctx context.Context, tenantID string, params AnswerParams, filter storage.MetadataFilter) ([]*storage.Document, []float64, error) {
+	embedding := params.Embedding
+	if len(embedding) == 0 && t.embedder != nil {
+		var err error
+		if embedding, err = t.embedder.Embed(ctx, params.Question); err != nil {
+			slog.WarnContext(ctx, "Failed to embed question, using lexical search", "error", err)
+			embedding = nil
+		}
+	}
+
+	if len(embedding) == 0 {
+		documents, err := t.db.SearchDocuments(ctx, tenantID, params.Collection, params.Question, params.Limit, filter)
+		return documents, make([]float64, len(documents)), err
+	}
+
+	results, err := t.db.HybridSearch(ctx, tenantID, storage.HybridSearchParams{
+		Collection:   params.Collection,
+		Query:        params.Question,
+		Embedding:    embedding,
+		Limit:        params.Limit,
+		BM25Weight:   0.5,
+		VectorWeight: 0.5,
+		Fusion:       storage.FusionRRF,
+		Filters:      filter,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	documents := make([]*storage.Document, len(results))
+	scores := make([]float64, len(results))
+	for i := range results {
+		documents[i] = &results[i].Document
+		scores[i] = results[i].CombinedScore
+	}
+	return documents, scores, nil
+}
+
+// answerToolResult returns the answer as text followed by the full result as JSON
+func answerToolResult(result AnswerResult) (protocol.ToolCallResult, error) {
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	text := result.Answer
+	if len(result.Sources) > 0 {
+		text += "\n\nSources:\n"
+		for _, source := range result.Sources {
+			text += fmt.Sprintf("[%d] %s (%s, score %.4f)\n", source.Marker, source.Title, source.DocID, source.Score)
+		}
+	}
+	return protocol.ToolCallResult{
+		Content: []protocol.ContentBlock{
+			{Type: "text", Text: text},
+			{Type: "text", Text: string(jsonData), MimeType: "application/json"},
+		},
+	}, nil
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/llm"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/shared/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLM answers completions with a fixed response or error
+type fakeLLM struct {
+	response *llm.Response
+	err      error
+	requests []llm.Request
+}
+
+func (p *fakeLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	p.requests = append(p.requests, req)
+	return p.response, p.err
+}
+
+func TestAnswerToolExecute(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	results := []storage.HybridSearchResult{
+		{Document: storage.Document{ID: "doc-1", Title: "Refund policy", Content: "Refunds take 5 days."}, CombinedScore: 0.032},
+		{Document: storage.Document{ID: "doc-2", Title: "Support hours", Content: "Support answers on weekdays.", Collection: "policies"}, CombinedScore: 0.016},
+	}
+
+	mockDB := new(MockStore)
+	mockDB.On("HybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(p storage.HybridSearchParams) bool {
+		return p.Query == "How long do refunds take?" && p.Fusion == storage.FusionRRF && p.Limit == DefaultAnswerSources && len(p.Embedding) == 2
+	})).Return(results, nil)
+	provider := &fakeLLM{response: &llm.Response{Text: " Refunds take 5 days [1]. ", Model: "test-model", InputTokens: 120, OutputTokens: 8}}
+	embedder := &fakeEmbedder{}
+	tool := NewAnswerTool(mockDB, provider)
+	tool.SetEmbedder(embedder)
+
+	result, err := tool.Execute(ctx, map[string]interface{}{"question": "How long do refunds take?"})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	assert.Contains(t, result.Content[0].Text, "Refunds take 5 days [1].")
+	assert.Contains(t, result.Content[0].Text, "[2] Support hours (doc-2, score 0.0160)")
+	assert.Equal(t, "application/json", result.Content[1].MimeType)
+
+	var answer AnswerResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[1].Text), &answer))
+	assert.Equal(t, "Refunds take 5 days [1].", answer.Answer)
+	assert.Equal(t, "test-model", answer.Model)
+	assert.Equal(t, 8, answer.OutputTokens)
+	assert.Equal(t, []AnswerSource{
+		{Marker: 1, DocID: "doc-1", Title: "Refund policy", Collection: storage.DefaultCollection, Score: 0.032},
+		{Marker: 2, DocID: "doc-2", Title: "Support hours", Collection: "policies", Score: 0.016},
+	}, answer.Sources)
+
+	assert.Equal(t, []string{"How long do refunds take?"}, embedder.texts)
+	require.Len(t, provider.requests, 1)
+	prompt := provider.requests[0].Messages[0].Content
+	assert.Contains(t, prompt, "[1] Refund policy\nRefunds take 5 days.")
+	assert.Contains(t, prompt, "[2] Support hours\nSupport answers on weekdays.")
+	assert.Contains(t, prompt, "Question: How long do refunds take?")
+	assert.Contains(t, provider.requests[0].System, "markers")
+}
+
+func TestAnswerToolExecute_LexicalRetrieval(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	docs := []*storage.Document{{ID: "doc-1", Title: "Refund policy", Content: "Refunds take 5 days."}}
+
+	t.Run("without an embedder", func(t *testing.T) {
+		mockDB := new(MockStore)
+		mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "refunds", 3, mock.Anything).Return(docs, nil)
+		tool := NewAnswerTool(mockDB, &fakeLLM{response: &llm.Response{Text: "5 days [1]"}})
+
+		result, err := tool.Execute(ctx, map[string]interface{}{"question": "refunds", "limit": 3})
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].Text, "5 days [1]")
+		mockDB.AssertNotCalled(t, "HybridSearch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("when embedding fails", func(t *testing.T) {
+		mockDB := new(MockStore)
+		mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "refunds", DefaultAnswerSources, mock.Anything).Return(docs, nil)
+		tool := NewAnswerTool(mockDB, &fakeLLM{response: &llm.Response{Text: "5 days [1]"}})
+		tool.SetEmbedder(&fakeEmbedder{err: errors.New("provider down")})
+
+		_, err := tool.Execute(ctx, map[string]interface{}{"question": "refunds"})
+		require.NoError(t, err)
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestAnswerToolExecute_ContextBudget(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	long := strings.Repeat("Refunds take five days. ", 2000)
+	docs := []*storage.Document{
+		{ID: "doc-1", Title: "Refunds", Content: long},
+		{ID: "doc-2", Title: "Refunds again", Content: long},
+		{ID: "doc-3", Title: "Refunds once more", Content: long},
+		{ID: "doc-4", Title: "Refunds, last time", Content: long},
+		{ID: "doc-5", Title: "Not sent", Content: long},
+	}
+	mockDB := new(MockStore)
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "refunds", DefaultAnswerSources, mock.Anything).Return(docs, nil)
+	provider := &fakeLLM{response: &llm.Response{Text: "Five days [1]."}}
+	tool := NewAnswerTool(mockDB, provider)
+	tool.SetModel("gpt-4o-mini")
+
+	result, err := tool.Execute(ctx, map[string]interface{}{"question": "refunds"})
+	require.NoError(t, err)
+	prompt := provider.requests[0].Messages[0].Content
+	assert.LessOrEqual(t, tokens.Count("gpt-4o-mini", prompt), maxContextTokens+50, "passages fit the context budget")
+	assert.Contains(t, prompt, "[4] Refunds, last time")
+	assert.NotContains(t, prompt, "[5]", "passages that do not fit are left out")
+
+	var answer AnswerResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[1].Text), &answer))
+	assert.Len(t, answer.Sources, 4, "only the passages given to the model are sources")
+}
+
+func TestAnswerToolExecute_NoDocuments(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	mockDB := new(MockStore)
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "unknown", DefaultAnswerSources, mock.Anything).Return([]*storage.Document{}, nil)
+	provider := &fakeLLM{}
+	tool := NewAnswerTool(mockDB, provider)
+
+	result, err := tool.Execute(ctx, map[string]interface{}{"question": "unknown"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].Text, "No documents were found")
+	assert.Empty(t, provider.requests, "the model is not asked without sources")
+}
+
+func TestAnswerToolExecute_Errors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	mockDB := new(MockStore)
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "refunds", DefaultAnswerSources, mock.Anything).
+		Return([]*storage.Document{{ID: "doc-1", Content: "Refunds take 5 days."}}, nil)
+	tool := NewAnswerTool(mockDB, &fakeLLM{err: errors.New("LLM API returned 429 Too Many Requests")})
+
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"question": "refunds"})
+	assert.Error(t, err, "authentication required")
+	_, err = tool.Execute(ctx, map[string]interface{}{"question": "  "})
+	assert.Error(t, err)
+	_, err = tool.Execute(ctx, map[string]interface{}{"question": "refunds", "collection": "Not Valid"})
+	assert.Error(t, err)
+	result, err := tool.Execute(ctx, map[string]interface{}{"question": "refunds"})
+	assert.ErrorContains(t, err, "failed to generate answer")
+	assert.True(t, result.IsError)
+}
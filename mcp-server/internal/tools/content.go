@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+)
+
+// BlobsPath is the path blobs are served under, followed by their content hash
+const BlobsPath = "/blobs/"
+
+// DefaultMaxInlineBytes is the largest image kept inline in tool results
+const DefaultMaxInlineBytes = 64 << 10
+
+// BlobsConfig configures the storage of binary tool output; it is off without a backend
+type BlobsConfig struct {
+	Store blobs.Config `yaml:",inline"`
+	// BaseURL is the server's public URL, which links to blobs start with
+	BaseURL string `yaml:"base_url"`
+	// MaxInlineBytes is the largest image kept inline; larger ones are stored and linked
+	MaxInlineBytes int `yaml:"max_inline_bytes"`
+	// Regions are the stores of the data regions other than the default one, keyed by
+	// region name; each inherits the Store settings it does not set. With data residency
+	// every region needs one, so tenants' tool output stays in their region.
+	Regions map[string]blobs.Config `yaml:"regions"`
+}
+
+// RegionStore returns the store config of a data region: Store, overridden by the
+// settings the region sets
+func (c BlobsConfig) RegionStore(region string) blobs.Config {
+	merged := c.Store
+	override := c.Regions[region]
+	for _, field := range []struct{ value, into *string }{
+		{&override.Backend, &merged.Backend},
+		{&override.Dir, &merged.Dir},
+		{&override.Endpoint, &merged.Endpoint},
+		{&override.Bucket, &merged.Bucket},
+		{&override.Region, &merged.Region},
+		{&override.AccessKeyID, &merged.AccessKeyID},
+		{&override.SecretAccessKey, &merged.SecretAccessKey},
+		{&override.Prefix, &merged.Prefix},
+	} {
+		if *field.value != "" {
+			*field.into = *field.value
+		}
+	}
+	return merged
+}
+
+// Enabled reports whether binary tool output is stored
+func (c BlobsConfig) Enabled() bool {
+	return c.Store.Backend != ""
+}
+
+// Validate checks the store config and that links can be made absolute
+func (c BlobsConfig) Validate() error {
+	if err := c.Store.Validate(); err != nil {
+		return err
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("base_url must be an absolute URL, got %q", c.BaseURL)
+	}
+	if c.MaxInlineBytes < 0 {
+		return fmt.Errorf("max_inline_bytes must not be negative, got %d", c.MaxInlineBytes)
+	}
+	for region := range c.Regions {
+		if err := c.RegionStore(region).Validate(); err != nil {
+			return fmt.Errorf("regions.%s: %w", region, err)
+		}
+	}
+	return nil
+}
+
+// ImageContent returns an image content block carrying data inline, base64 encoded. With
+// blob storage on, images over its inline limit are replaced by links before the result
+// is returned; see Blobs.Offload.
+func ImageContent(data []byte, mimeType string) protocol.ContentBlock {
+	return protocol.ContentBlock{
+		Type:     protocol.ContentTypeImage,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// Blobs keeps binary tool output in content-addressed storage, so results can link to it
+// instead of inlining it as base64. Output is stored for, and served to, the tenant of the
+// call only.
+type Blobs struct {
+	store     *blobs.ContentStore
+	baseURL   string
+	maxInline int
+}
+
+// NewBlobs stores content in store and links to it under baseURL; images of at most
+// maxInline bytes stay inline
+func NewBlobs(store *blobs.ContentStore, baseURL string, maxInline int) *Blobs {
+	if maxInline <= 0 {
+		maxInline = DefaultMaxInlineBytes
+	}
+	return &Blobs{store: store, baseURL: strings.TrimRight(baseURL, "/"), maxInline: maxInline}
+}
+
+// URL returns the URL the blob with the given hash is served at
+func (b *Blobs) URL(hash string) string {
+	return b.baseURL + BlobsPath + hash
+}
+
+// Link stores data for the caller's tenant, owned by the calling user so their data
+// requests cover it, and returns a resource link content block referring to it
+func (b *Blobs) Link(ctx context.Context, name string, data []byte, mimeType string) (protocol.ContentBlock, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ContentBlock{}, err
+	}
+	userID, _ := auth.ExtractUserID(ctx)
+	hash, err := b.store.Put(ctx, tenantID, userID, data)
+	if err != nil {
+		return protocol.ContentBlock{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+	if name == "" {
+		name = hash[:12]
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return protocol.ContentBlock{
+		Type:     protocol.ContentTypeResourceLink,
+		URI:      b.URL(hash),
+		Name:     name,
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+	}, nil
+}
+
+// Offload replaces the inline images of a result larger than the inline limit with links
+// to stored copies. Without blob storage, a nil Blobs, the result is returned as is.
+func (b *Blobs) Offload(ctx context.Context, result protocol.ToolCallResult) (protocol.ToolCallResult, error) {
+	if b == nil {
+		return result, nil
+	}
+	var content []protocol.ContentBlock
+	for i, block := range result.Content {
+		if block.Type != protocol.ContentTypeImage || base64.StdEncoding.DecodedLen(len(block.Data)) <= b.maxInline {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(block.Data)
+		if err != nil || len(data) <= b.maxInline {
+			continue
+		}
+		link, err := b.Link(ctx, "", data, block.MimeType)
+		if err != nil {
+			return result, err
+		}
+		if content == nil {
+			content = append([]protocol.ContentBlock(nil), result.Content...)
+		}
+		content[i] = link
+	}
+	if content != nil {
+		result.Content = content
+	}
+	return result, nil
+}
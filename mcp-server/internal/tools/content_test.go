@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/shared/blobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobs_Offload(t *testing.T) {
+	ctx := auth.WithAuth(context.Background(), &auth.Claims{TenantID: "tenant-123", UserID: "user-1"})
+	files, err := blobs.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	store := blobs.NewContentStore(files)
+	b := NewBlobs(store, "https://mcp.example.com/", 16)
+
+	small := []byte("tiny png")
+	large := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 100)
+	result := protocol.ToolCallResult{Content: []protocol.ContentBlock{
+		{Type: protocol.ContentTypeText, Text: "chart attached"},
+		ImageContent(small, "image/png"),
+		ImageContent(large, "image/png"),
+	}}
+
+	offloaded, err := b.Offload(ctx, result)
+	require.NoError(t, err)
+	require.Len(t, offloaded.Content, 3)
+	assert.Equal(t, result.Content[:2], offloaded.Content[:2], "text and small images stay inline")
+	assert.Equal(t, protocol.ContentTypeImage, result.Content[2].Type, "the original result is not changed")
+
+	link := offloaded.Content[2]
+	hash := blobs.Hash(large)
+	assert.Equal(t, protocol.ContentTypeResourceLink, link.Type)
+	assert.Equal(t, "https://mcp.example.com/blobs/"+hash, link.URI)
+	assert.Equal(t, hash[:12]+".png", link.Name)
+	assert.Equal(t, "image/png", link.MimeType)
+	assert.Equal(t, int64(len(large)), link.Size)
+	assert.Empty(t, link.Data)
+
+	r, err := store.Get(ctx, "tenant-123", hash)
+	require.NoError(t, err)
+	stored, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, large, stored)
+	owned, err := store.Owned(ctx, "tenant-123", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{hash}, owned, "the caller owns the blobs of its results")
+
+	// Without blob storage results are left as they are
+	var none *Blobs
+	unchanged, err := none.Offload(ctx, result)
+	require.NoError(t, err)
+	assert.Equal(t, result, unchanged)
+}
+
+func TestImageContent(t *testing.T) {
+	block := ImageContent([]byte{1, 2, 3}, "image/gif")
+	assert.Equal(t, protocol.ContentTypeImage, block.Type)
+	assert.Equal(t, "image/gif", block.MimeType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{1, 2, 3}), block.Data)
+}
+
+func TestBlobsConfig_Validate(t *testing.T) {
+	config := BlobsConfig{
+		Store:   blobs.Config{Backend: blobs.BackendFilesystem, Dir: "/var/lib/mcp/blobs"},
+		BaseURL: "https://mcp.example.com",
+	}
+	assert.NoError(t, config.Validate())
+
+	invalid := config
+	invalid.BaseURL = "/blobs"
+	assert.ErrorContains(t, invalid.Validate(), "base_url")
+	invalid = config
+	invalid.Store = blobs.Config{Backend: blobs.BackendS3, Bucket: "blobs"}
+	assert.ErrorContains(t, invalid.Validate(), "endpoint, region, access_key_id, secret_access_key")
+
+	// Regions inherit the settings they do not set
+	regional := config
+	regional.Regions = map[string]blobs.Config{"eu-west": {Dir: "/var/lib/mcp/blobs-eu"}}
+	assert.NoError(t, regional.Validate())
+	assert.Equal(t, blobs.Config{Backend: blobs.BackendFilesystem, Dir: "/var/lib/mcp/blobs-eu"}, regional.RegionStore("eu-west"))
+	regional.Regions = map[string]blobs.Config{"eu-west": {Backend: blobs.BackendGCS}}
+	assert.ErrorContains(t, regional.Validate(), "regions.eu-west: gcs backend requires bucket")
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// maxRemoteResponseBytes bounds the response read from a remote source
+const maxRemoteResponseBytes = 4 << 20
+
+// RemoteSource searches a remote MCP server by calling its search tool over JSON-RPC
+type RemoteSource struct {
+	config RemoteSourceConfig
+	client *http.Client
+}
+
+var _ FederatedSource = (*RemoteSource)(nil)
+
+// NewRemoteSource creates a client for a remote source. Requests are bounded by the
+// context federated_search gives each source.
+func NewRemoteSource(config RemoteSourceConfig) *RemoteSource {
+	if config.Tool == "" {
+		config.Tool = "hybrid_search"
+	}
+	return &RemoteSource{config: config, client: &http.Client{}}
+}
+
+// Search implements FederatedSource. The remote tenant is the one of the configured
+// token, not the caller's.
+func (s *RemoteSource) Search(ctx context.Context, tenantID, query string, limit int) ([]FederatedHit, error) {
+	request, err := protocol.NewRequest(1, "tools/call", protocol.ToolCallRequest{
+		Name:      s.config.Tool,
+		Arguments: map[string]interface{}{"query": query, "limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote source returned %s", resp.Status)
+	}
+
+	var rpc struct {
+		Result *protocol.ToolCallResult `json:"result"`
+		Error  *protocol.Error          `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRemoteResponseBytes)).Decode(&rpc); err != nil {
+		return nil, fmt.Errorf("invalid remote response: %w", err)
+	}
+	if rpc.Error != nil {
+		return nil, fmt.Errorf("remote error %d: %s", rpc.Error.Code, rpc.Error.Message)
+	}
+	if rpc.Result == nil || len(rpc.Result.Content) == 0 {
+		return nil, fmt.Errorf("remote tool %s returned no content", s.config.Tool)
+	}
+	if rpc.Result.IsError {
+		return nil, fmt.Errorf("remote tool %s failed: %s", s.config.Tool, rpc.Result.Content[0].Text)
+	}
+
+	var hits []FederatedHit
+	if err := json.Unmarshal([]byte(rpc.Result.Content[0].Text), &hits); err != nil {
+		return nil, fmt.Errorf("remote tool %s did not return search results: %w", s.config.Tool, err)
+	}
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
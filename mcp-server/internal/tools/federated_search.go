@@ -0,0 +1,481 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// Federated search fans a query out to several sources at once: collections of the
+// caller's tenant and remote MCP servers configured by the operator. Each source gets
+// its own latency budget; sources that fail or run out of time are reported in the
+// response and the results of the others are still returned. Rankings are merged with
+// reciprocal rank fusion, which needs no comparable scores across sources.
+//
+//	federation:
+//	  timeout: 2s
+//	  sources:
+//	    - name: public-kb
+//	      url: https://kb.example.com/mcp
+//	      token: kb-service-token
+//	      timeout: 1s
+//	      tenants: ["11111111-1111-1111-1111-111111111111"]
+
+// Prefixes of the source names results are attributed to
+const (
+	collectionSourcePrefix = "collection:"
+	remoteSourcePrefix     = "remote:"
+)
+
+// Federated source statuses
+const (
+	SourceOK      = "ok"
+	SourceTimeout = "timeout"
+	SourceError   = "error"
+)
+
+const (
+	// DefaultFederationTimeout is the latency budget of a source when none is configured
+	DefaultFederationTimeout = 2 * time.Second
+	// maxFederatedCollections caps the collections one call fans out to
+	maxFederatedCollections = 10
+	// federatedRRFK is the rank constant used to merge source rankings
+	federatedRRFK = storage.DefaultRRFK
+)
+
+// FederationConfig configures federated_search
+type FederationConfig struct {
+	// Timeout is the default latency budget of each source
+	Timeout time.Duration        `yaml:"timeout"`
+	Sources []RemoteSourceConfig `yaml:"sources"`
+}
+
+// RemoteSourceConfig configures a remote MCP server queried by federated_search. The
+// remote tool must return hybrid_search's JSON results.
+type RemoteSourceConfig struct {
+	Name string `yaml:"name"`
+	// URL is the server's JSON-RPC endpoint, e.g. https://kb.example.com/mcp
+	URL string `yaml:"url"`
+	// Token is sent as a bearer token; it decides which remote tenant is searched
+	Token string `yaml:"token"`
+	// Tool is the remote tool to call (default hybrid_search)
+	Tool string `yaml:"tool"`
+	// Timeout overrides the federation timeout for this source
+	Timeout time.Duration `yaml:"timeout"`
+	// Tenants lists the tenants allowed to query the source; empty allows all
+	Tenants []string `yaml:"tenants"`
+}
+
+// Validate checks the federation timeout and that every source has a unique name and
+// an absolute http or https URL
+func (c FederationConfig) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", c.Timeout)
+	}
+	names := make(map[string]bool, len(c.Sources))
+	for _, source := range c.Sources {
+		if source.Name == "" {
+			return fmt.Errorf("sources: name is required")
+		}
+		if names[source.Name] {
+			return fmt.Errorf("sources: duplicate name %q", source.Name)
+		}
+		names[source.Name] = true
+		u, err := url.Parse(source.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("sources.%s: url must be an absolute http or https URL", source.Name)
+		}
+		if source.Timeout < 0 {
+			return fmt.Errorf("sources.%s: timeout must not be negative, got %s", source.Name, source.Timeout)
+		}
+	}
+	return nil
+}
+
+// FederatedHit is one result of a federated source
+type FederatedHit struct {
+	DocID      string                 `json:"doc_id"`
+	Collection string                 `json:"collection,omitempty"`
+	Title      string                 `json:"title"`
+	Content    string                 `json:"content"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FederatedSource is a search backend federated_search fans out to
+type FederatedSource interface {
+	// Search returns the source's best matches for query, best first
+	Search(ctx context.Context, tenantID, query string, limit int) ([]FederatedHit, error)
+}
+
+// collectionSource searches one collection of the caller's tenant
+type collectionSource struct {
+	store      storage.Store
+	collection string
+}
+
+// Search implements FederatedSource
+func (s collectionSource) Search(ctx context.Context, tenantID, query string, limit int) ([]FederatedHit, error) {
+	documents, err := s.store.SearchDocuments(ctx, tenantID, s.collection, query, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]FederatedHit, 0, len(documents))
+	for _, doc := range documents {
+		hits = append(hits, FederatedHit{
+			DocID:      doc.ID,
+			Collection: storage.CollectionOrDefault(doc.Collection),
+			Title:      doc.Title,
+			Content:    doc.Content,
+			Metadata:   doc.Metadata,
+		})
+	}
+	return hits, nil
+}
+
+// remoteSource is a configured remote source with its client
+type remoteSource struct {
+	config  RemoteSourceConfig
+	source  FederatedSource
+	tenants map[string]bool
+}
+
+// FederatedSearchTool searches several collections and remote sources at once
+type FederatedSearchTool struct {
+	documentAccess
+	store   storage.Store
+	timeout time.Duration
+	remotes []remoteSource
+}
+
+// NewFederatedSearchTool creates a federated search over the tenant's collections in
+// store; timeout is the default latency budget of each source
+func NewFederatedSearchTool(store storage.Store, timeout time.Duration) *FederatedSearchTool {
+	if timeout <= 0 {
+		timeout = DefaultFederationTimeout
+	}
+	return &FederatedSearchTool{store: store, timeout: timeout}
+}
+
+// AddRemoteSource adds a remote MCP server as a source
+func (t *FederatedSearchTool) AddRemoteSource(config RemoteSourceConfig) error {
+	if err := (FederationConfig{Sources: []RemoteSourceConfig{config}}).Validate(); err != nil {
+		return err
+	}
+	return t.addSource(config, NewRemoteSource(config))
+}
+
+// addSource adds a source under the name and access rules of config
+func (t *FederatedSearchTool) addSource(config RemoteSourceConfig, source FederatedSource) error {
+	for _, remote := range t.remotes {
+		if remote.config.Name == config.Name {
+			return fmt.Errorf("duplicate federated source %q", config.Name)
+		}
+	}
+	var tenants map[string]bool
+	if len(config.Tenants) > 0 {
+		tenants = make(map[string]bool, len(config.Tenants))
+		for _, tenantID := range config.Tenants {
+			tenants[tenantID] = true
+		}
+	}
+	t.remotes = append(t.remotes, remoteSource{config: config, source: source, tenants: tenants})
+	return nil
+}
+
+// Definition returns the tool definition for MCP
+func (t *FederatedSearchTool) Definition() protocol.Tool {
+	return protocol.Tool{
+		Name: "federated_search",
+		Description: "Search several document collections, and remote knowledge sources configured by the operator, " +
+			"at once. Results are merged into one ranking and attributed to their source; sources that fail or " +
+			"exceed their latency budget are reported without failing the search.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The search query text",
+				},
+				"collections": map[string]interface{}{
+					"type":        "array",
+					"description": fmt.Sprintf("Collections of the tenant to search (at most %d)", maxFederatedCollections),
+					"items":       collectionSchema(),
+				},
+				"sources": map[string]interface{}{
+					"type":        "array",
+					"description": "Remote sources to search, by name. Without collections or sources, the default collection and every remote source available to the tenant are searched.",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of merged results to return (default: 10, max: 50)",
+					"default":     10,
+				},
+				"timeout_ms": map[string]interface{}{
+					"type":        "number",
+					"description": "Latency budget of each source in milliseconds; it can only lower the configured budget",
+				},
+			},
+			"required": []string{"query"},
+		},
+	}
+}
+
+// FederatedSearchParams represents the parameters for federated search
+type FederatedSearchParams struct {
+	Query       string   `json:"query"`
+	Collections []string `json:"collections,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
+	Limit       int      `json:"limit"`
+	TimeoutMs   int      `json:"timeout_ms,omitempty"`
+}
+
+// FederatedResult is a merged result attributed to its source
+type FederatedResult struct {
+	Source string `json:"source"`
+	FederatedHit
+	Score      float64 `json:"score"`
+	SourceRank int     `json:"source_rank"`
+}
+
+// SourceReport describes how a source answered
+type SourceReport struct {
+	Source    string `json:"source"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Results   int    `json:"results"`
+	Error     string `json:"error,omitempty"`
+}
+
+// FederatedSearchResponse is the JSON returned by federated_search
+type FederatedSearchResponse struct {
+	Results []FederatedResult `json:"results"`
+	Sources []SourceReport    `json:"sources"`
+	// Partial is set when some sources did not answer
+	Partial bool `json:"partial"`
+}
+
+// federatedTarget is a source selected for one call
+type federatedTarget struct {
+	name    string
+	source  FederatedSource
+	timeout time.Duration
+}
+
+// Execute performs the federated search
+func (t *FederatedSearchTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	var params FederatedSearchParams
+	if err := json.Unmarshal(argsJSON, &params); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if params.Query == "" {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("query is required")
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > 50 {
+		params.Limit = 50
+	}
+	if params.TimeoutMs < 0 {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("timeout_ms must not be negative")
+	}
+
+	targets, err := t.targets(tenantID, params)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+
+	rankings, reports := t.fanOut(ctx, tenantID, params, targets)
+	response := FederatedSearchResponse{Results: merge(targets, rankings, params.Limit), Sources: reports}
+	failed := 0
+	for _, report := range reports {
+		if report.Status != SourceOK {
+			failed++
+		}
+	}
+	if failed == len(reports) {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("federated search failed: no source answered")
+	}
+	response.Partial = failed > 0
+
+	var localIDs []string
+	for _, result := range response.Results {
+		if strings.HasPrefix(result.Source, collectionSourcePrefix) {
+			localIDs = append(localIDs, result.DocID)
+		}
+	}
+	t.recordAccess(ctx, "federated_search", localIDs...)
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to marshal results: %w", err)
+	}
+	return protocol.ToolCallResult{
+		Content: []protocol.ContentBlock{{Type: "text", Text: string(jsonData)}},
+	}, nil
+}
+
+// targets resolves the collections and remote sources a call searches
+func (t *FederatedSearchTool) targets(tenantID string, params FederatedSearchParams) ([]federatedTarget, error) {
+	budget := func(configured time.Duration) time.Duration {
+		if configured <= 0 {
+			configured = t.timeout
+		}
+		if requested := time.Duration(params.TimeoutMs) * time.Millisecond; requested > 0 && requested < configured {
+			return requested
+		}
+		return configured
+	}
+
+	collections, sources := params.Collections, params.Sources
+	allRemotes := len(collections) == 0 && len(sources) == 0
+	if allRemotes {
+		collections = []string{storage.DefaultCollection}
+	}
+	if len(collections) > maxFederatedCollections {
+		return nil, fmt.Errorf("at most %d collections can be searched at once", maxFederatedCollections)
+	}
+
+	var targets []federatedTarget
+	seen := make(map[string]bool)
+	for _, collection := range collections {
+		if err := storage.ValidateCollection(collection); err != nil {
+			return nil, err
+		}
+		name := collectionSourcePrefix + storage.CollectionOrDefault(collection)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		targets = append(targets, federatedTarget{
+			name:    name,
+			source:  collectionSource{store: t.store, collection: collection},
+			timeout: budget(0),
+		})
+	}
+
+	requested := make(map[string]bool, len(sources))
+	for _, name := range sources {
+		requested[name] = true
+	}
+	for _, remote := range t.remotes {
+		allowed := remote.tenants == nil || remote.tenants[tenantID]
+		if !allowed || (!allRemotes && !requested[remote.config.Name]) {
+			continue
+		}
+		delete(requested, remote.config.Name)
+		targets = append(targets, federatedTarget{
+			name:    remoteSourcePrefix + remote.config.Name,
+			source:  remote.source,
+			timeout: budget(remote.config.Timeout),
+		})
+	}
+	// Sources the tenant may not use are reported as unknown, like missing ones
+	for _, name := range sources {
+		if requested[name] {
+			return nil, fmt.Errorf("unknown federated source %q", name)
+		}
+	}
+	return targets, nil
+}
+
+// fanOut searches every target concurrently, each within its own latency budget
+func (t *FederatedSearchTool) fanOut(ctx context.Context, tenantID string, params FederatedSearchParams, targets []federatedTarget) ([][]FederatedHit, []SourceReport) {
+	rankings := make([][]FederatedHit, len(targets))
+	reports := make([]SourceReport, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sourceCtx, cancel := context.WithTimeout(ctx, target.timeout)
+			defer cancel()
+
+			start := time.Now()
+			hits, err := searchWithin(sourceCtx, target.source, tenantID, params.Query, params.Limit)
+			report := SourceReport{Source: target.name, Status: SourceOK, LatencyMs: time.Since(start).Milliseconds()}
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				report.Status = SourceTimeout
+				report.Error = fmt.Sprintf("no answer within %s", target.timeout)
+			case err != nil:
+				report.Status = SourceError
+				report.Error = err.Error()
+			default:
+				report.Results = len(hits)
+				rankings[i] = hits
+			}
+			reports[i] = report
+		}()
+	}
+	wg.Wait()
+	return rankings, reports
+}
+
+// searchWithin returns when the source answers or ctx is done, whichever comes first,
+// so a source that ignores cancellation cannot hold up the response
+func searchWithin(ctx context.Context, source FederatedSource, tenantID, query string, limit int) ([]FederatedHit, error) {
+	type answer struct {
+		hits []FederatedHit
+		err  error
+	}
+	answered := make(chan answer, 1)
+	go func() {
+		hits, err := source.Search(ctx, tenantID, query, limit)
+		answered <- answer{hits, err}
+	}()
+	select {
+	case a := <-answered:
+		if a.err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return a.hits, a.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// merge fuses the source rankings with reciprocal rank fusion, best first; ties keep
+// the order of the sources
+func merge(targets []federatedTarget, rankings [][]FederatedHit, limit int) []FederatedResult {
+	results := []FederatedResult{}
+	for i, hits := range rankings {
+		for rank, hit := range hits {
+			results = append(results, FederatedResult{
+				Source:       targets[i].name,
+				FederatedHit: hit,
+				Score:        1 / float64(federatedRRFK+rank+1),
+				SourceRank:   rank + 1,
+			})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
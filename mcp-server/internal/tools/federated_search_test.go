@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSource answers with fixed hits after a delay, or fails
+type stubSource struct {
+	hits  []FederatedHit
+	delay time.Duration
+	err   error
+}
+
+func (s stubSource) Search(ctx context.Context, tenantID, query string, limit int) ([]FederatedHit, error) {
+	select {
+	case <-time.After(s.delay):
+		return s.hits, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func seedFederatedStore(t *testing.T) storage.Store {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+	docs := []*storage.Document{
+		{Collection: "policies", Title: "Password policy", Content: "Passwords rotate every 90 days"},
+		{Collection: "tickets", Title: "Password reset", Content: "Cannot reset my password"},
+		{Title: "Password FAQ", Content: "How to change a password"},
+	}
+	for _, doc := range docs {
+		require.NoError(t, store.InsertDocument(ctx, "tenant-1", doc))
+	}
+	return store
+}
+
+func executeFederated(t *testing.T, tool *FederatedSearchTool, tenantID string, args map[string]interface{}) (FederatedSearchResponse, error) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, tenantID)
+	result, err := tool.Execute(ctx, args)
+	if err != nil {
+		return FederatedSearchResponse{}, err
+	}
+	var response FederatedSearchResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &response))
+	return response, nil
+}
+
+func TestFederatedSearch_Collections(t *testing.T) {
+	tool := NewFederatedSearchTool(seedFederatedStore(t), time.Second)
+
+	response, err := executeFederated(t, tool, "tenant-1", map[string]interface{}{
+		"query":       "password",
+		"collections": []string{"policies", "tickets"},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Results, 2)
+	assert.False(t, response.Partial)
+	sources := map[string]string{}
+	for _, result := range response.Results {
+		sources[result.Source] = result.Collection
+		assert.Equal(t, 1, result.SourceRank)
+	}
+	assert.Equal(t, map[string]string{"collection:policies": "policies", "collection:tickets": "tickets"}, sources)
+
+	// Without collections or sources the default collection is searched
+	response, err = executeFederated(t, tool, "tenant-1", map[string]interface{}{"query": "password"})
+	require.NoError(t, err)
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "Password FAQ", response.Results[0].Title)
+
+	_, err = executeFederated(t, tool, "tenant-1", map[string]interface{}{"query": "password", "collections": []string{"Bad Name"}})
+	assert.ErrorIs(t, err, storage.ErrInvalidCollection)
+	_, err = executeFederated(t, tool, "tenant-1", map[string]interface{}{"query": "password", "sources": []string{"missing"}})
+	assert.Error(t, err)
+}
+
+func TestFederatedSearch_RemoteSource(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer kb-token", r.Header.Get("Authorization"))
+		var req protocol.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "tools/call", req.Method)
+
+		hits, _ := json.Marshal([]map[string]interface{}{
+			{"doc_id": "kb-1", "title": "Remote password guide", "content": "From the KB", "score": 0.9},
+		})
+		json.NewEncoder(w).Encode(protocol.Response{
+			JSONRPC: protocol.JSONRPCVersion,
+			ID:      req.ID,
+			Result:  protocol.ToolCallResult{Content: []protocol.ContentBlock{{Type: "text", Text: string(hits)}}},
+		})
+	}))
+	defer remote.Close()
+
+	tool := NewFederatedSearchTool(seedFederatedStore(t), time.Second)
+	require.NoError(t, tool.AddRemoteSource(RemoteSourceConfig{Name: "kb", URL: remote.URL, Token: "kb-token", Tenants: []string{"tenant-1"}}))
+	assert.Error(t, tool.AddRemoteSource(RemoteSourceConfig{Name: "kb", URL: remote.URL}), "duplicate name")
+	assert.Error(t, tool.AddRemoteSource(RemoteSourceConfig{Name: "bad", URL: "kb.example.com"}))
+
+	response, err := executeFederated(t, tool, "tenant-1", map[string]interface{}{"query": "password"})
+	require.NoError(t, err)
+	require.Len(t, response.Results, 2)
+	var remoteResult *FederatedResult
+	for i := range response.Results {
+		if response.Results[i].Source == "remote:kb" {
+			remoteResult = &response.Results[i]
+		}
+	}
+	require.NotNil(t, remoteResult)
+	assert.Equal(t, "kb-1", remoteResult.DocID)
+
+	// Sources are limited to their tenants
+	response, err = executeFederated(t, tool, "tenant-2", map[string]interface{}{"query": "password"})
+	require.NoError(t, err)
+	require.Len(t, response.Sources, 1)
+	_, err = executeFederated(t, tool, "tenant-2", map[string]interface{}{"query": "password", "sources": []string{"kb"}})
+	assert.Error(t, err)
+}
+
+func TestFederatedSearch_DegradesOnSlowSources(t *testing.T) {
+	tool := NewFederatedSearchTool(seedFederatedStore(t), time.Second)
+	fast := []FederatedHit{{DocID: "fast-1", Title: "Fast"}}
+	require.NoError(t, tool.addSource(RemoteSourceConfig{Name: "fast"}, stubSource{hits: fast}))
+	require.NoError(t, tool.addSource(RemoteSourceConfig{Name: "slow", Timeout: 20 * time.Millisecond}, stubSource{hits: fast, delay: time.Second}))
+	require.NoError(t, tool.addSource(RemoteSourceConfig{Name: "broken"}, stubSource{err: errors.New("connection refused")}))
+
+	start := time.Now()
+	response, err := executeFederated(t, tool, "tenant-1", map[string]interface{}{
+		"query":   "password",
+		"sources": []string{"fast", "slow", "broken"},
+	})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the slow source's budget bounds the call")
+	assert.True(t, response.Partial)
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "remote:fast", response.Results[0].Source)
+
+	statuses := map[string]string{}
+	for _, report := range response.Sources {
+		statuses[report.Source] = report.Status
+	}
+	assert.Equal(t, map[string]string{"remote:fast": SourceOK, "remote:slow": SourceTimeout, "remote:broken": SourceError}, statuses)
+
+	// A per-call budget below the configured one applies to every source
+	response, err = executeFederated(t, tool, "tenant-1", map[string]interface{}{"query": "password", "sources": []string{"fast", "slow"}, "timeout_ms": 10})
+	require.NoError(t, err)
+	assert.Len(t, response.Results, 1)
+
+	// Failing when no source answers
+	_, err = executeFederated(t, tool, "tenant-1", map[string]interface{}{"query": "password", "sources": []string{"slow", "broken"}, "timeout_ms": 10})
+	assert.Error(t, err)
+}
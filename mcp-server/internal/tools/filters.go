@@ -0,0 +1,35 @@
+package tools
+
+import "github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+
+// filtersSchema documents the "filters" argument shared by the search tools
+func filtersSchema() map[string]interface{} {
+	scalar := []string{"string", "number", "boolean"}
+	return map[string]interface{}{
+		"type": "object",
+		"description": "Restrict results by document metadata. Every key must match. " +
+			"A single value requires equality (or membership, for array fields such as tags); " +
+			"a list matches any of its values. " +
+			`Example: {"category": "security", "department": ["engineering", "sre"]}`,
+		"additionalProperties": map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": scalar},
+				map[string]interface{}{
+					"type":     "array",
+					"items":    map[string]interface{}{"type": scalar},
+					"minItems": 1,
+				},
+			},
+		},
+	}
+}
+
+// collectionSchema documents the "collection" argument shared by the document tools
+func collectionSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "string",
+		"description": "Named document collection of the tenant to work in (e.g. \"policies\" or \"tickets\"). " +
+			"Collections are searched in isolation; defaults to \"" + storage.DefaultCollection + "\".",
+		"pattern": "^[a-z0-9][a-z0-9_-]{0,63}$",
+	}
+}
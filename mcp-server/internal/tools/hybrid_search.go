@@ -7,22 +7,42 @@ import (
 	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 )
 
 // HybridSearchTool implements hybrid BM25 + vector search
 type HybridSearchTool struct {
-	db database.Store
+	documentAccess
+	searchProfiles
+	querySynonyms
+	db     storage.Store
+	fusion string
+	rrfK   int
 }
 
-// NewHybridSearchTool creates a new hybrid search tool
-func NewHybridSearchTool(db database.Store) *HybridSearchTool {
-	return &HybridSearchTool{db: db}
+// NewHybridSearchTool creates a new hybrid search tool. Scores are combined with a
+// raw weighted sum unless a default fusion method is set or requested per call.
+func NewHybridSearchTool(db storage.Store) *HybridSearchTool {
+	return &HybridSearchTool{db: db, fusion: storage.FusionWeighted}
+}
+
+// SetDefaultFusion sets the fusion method and RRF constant used when a call does not specify them
+func (t *HybridSearchTool) SetDefaultFusion(method string, rrfK int) error {
+	if _, err := storage.NewFusion(method, rrfK); err != nil {
+		return err
+	}
+	if rrfK < 0 {
+		return fmt.Errorf("rrf_k must not be negative")
+	}
+	t.fusion = method
+	t.rrfK = rrfK
+	return nil
 }
 
 // Definition returns the tool definition for MCP
 func (t *HybridSearchTool) Definition() protocol.Tool {
+	correctSpelling, expandSynonyms := queryPreprocessingSchema("")
 	return protocol.Tool{
 		Name:        "hybrid_search",
 		Description: "Perform hybrid search combining BM25 lexical search with vector semantic similarity. Returns the most relevant documents using both keyword matching and semantic understanding.",
@@ -55,6 +75,44 @@ func (t *HybridSearchTool) Definition() protocol.Tool {
 					"description": "Weight for vector semantic search (0.0 to 1.0, default: 0.5)",
 					"default":     0.5,
 				},
+				"fusion": map[string]interface{}{
+					"type": "string",
+					"description": "How lexical and vector results are combined: rrf (reciprocal rank fusion), " +
+						"minmax (weighted sum of min-max normalized scores), zscore (weighted sum of z-score normalized scores) " +
+						"or weighted (weighted sum of raw scores). Defaults to the server configuration.",
+					"enum": []string{storage.FusionRRF, storage.FusionMinMax, storage.FusionZScore, storage.FusionWeighted},
+				},
+				"rrf_k": map[string]interface{}{
+					"type":        "number",
+					"description": "Rank constant k for rrf fusion; larger values flatten the contribution of top ranks (default: 60)",
+				},
+				"diversify": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Re-rank results with maximal marginal relevance so near-duplicate documents do not crowd out the top results (default: false)",
+					"default":     false,
+				},
+				"diversity_lambda": map[string]interface{}{
+					"type":        "number",
+					"description": "Relevance/novelty trade-off for diversify, from 0 (most diverse) to 1 (original ranking) (default: 0.7)",
+					"default":     storage.DefaultMMRLambda,
+				},
+				"ef_search": map[string]interface{}{
+					"type":        "integer",
+					"description": "HNSW search candidate list size; higher values raise recall at the cost of latency (default: server setting, usually 40)",
+					"minimum":     1,
+					"maximum":     storage.MaxEfSearch,
+				},
+				"probes": map[string]interface{}{
+					"type":        "integer",
+					"description": "IVFFlat lists probed; higher values raise recall at the cost of latency (default: server setting, usually 1)",
+					"minimum":     1,
+					"maximum":     storage.MaxProbes,
+				},
+				"correct_spelling": correctSpelling,
+				"expand_synonyms":  expandSynonyms,
+				"collection":       collectionSchema(),
+				"filters":          filtersSchema(),
+				"profile":          profileSchema(),
 			},
 			"required": []string{"query"},
 		},
@@ -63,13 +121,27 @@ func (t *HybridSearchTool) Definition() protocol.Tool {
 
 // HybridSearchParams represents the parameters for hybrid search
 type HybridSearchParams struct {
-	Query        string    `json:"query"`
-	Embedding    []float32 `json:"embedding,omitempty"`
-	Limit        int       `json:"limit"`
-	BM25Weight   float64   `json:"bm25_weight"`
-	VectorWeight float64   `json:"vector_weight"`
+	Query        string                 `json:"query"`
+	Embedding    []float32              `json:"embedding,omitempty"`
+	Limit        int                    `json:"limit"`
+	Collection   string                 `json:"collection,omitempty"`
+	BM25Weight   float64                `json:"bm25_weight"`
+	VectorWeight float64                `json:"vector_weight"`
+	Filters      map[string]interface{} `json:"filters,omitempty"`
+	Fusion       string                 `json:"fusion,omitempty"`
+	RRFK         int                    `json:"rrf_k,omitempty"`
+	Diversify    bool                   `json:"diversify,omitempty"`
+	MMRLambda    *float64               `json:"diversity_lambda,omitempty"` // nil when unset; 0 is valid
+	EfSearch     int                    `json:"ef_search,omitempty"`
+	Probes       int                    `json:"probes,omitempty"`
+
+	CorrectSpelling bool `json:"correct_spelling,omitempty"`
+	ExpandSynonyms  bool `json:"expand_synonyms,omitempty"`
 }
 
+// diversifyCandidateFactor is how many candidates per requested result are fetched for diversification
+const diversifyCandidateFactor = 3
+
 // Execute performs the hybrid search operation
 func (t *HybridSearchTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
 	// Extract tenant ID from context
@@ -78,6 +150,11 @@ func (t *HybridSearchTool) Execute(ctx context.Context, args map[string]interfac
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
 	}
 
+	args, err = t.applyProfile(ctx, tenantID, args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+
 	// Parse parameters
 	argsJSON, err := json.Marshal(args)
 	if err != nil {
@@ -103,9 +180,40 @@ func (t *HybridSearchTool) Execute(ctx context.Context, args map[string]interfac
 		params.BM25Weight = 0.5
 		params.VectorWeight = 0.5
 	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+	filter, err := storage.ParseMetadataFilter(params.Filters)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid filters: %w", err)
+	}
+	if params.Fusion == "" {
+		params.Fusion = t.fusion
+	}
+	if params.RRFK == 0 {
+		params.RRFK = t.rrfK
+	}
+	if params.RRFK < 0 {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("rrf_k must not be negative")
+	}
+	if _, err := storage.NewFusion(params.Fusion, params.RRFK); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid fusion: %w", err)
+	}
+	lambda := storage.DefaultMMRLambda
+	if params.MMRLambda != nil {
+		lambda = *params.MMRLambda
+		if lambda < 0 || lambda > 1 {
+			return protocol.ToolCallResult{IsError: true}, fmt.Errorf("diversity_lambda must be between 0 and 1")
+		}
+	}
+	synonyms, err := t.synonyms(ctx, tenantID, params.ExpandSynonyms)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
 
 	// Perform hybrid search
-	dbParams := database.HybridSearchParams{
+	dbParams := storage.HybridSearchParams{
+		Collection:   params.Collection,
 		Query:        params.Query,
 		Embedding:    params.Embedding,
 		Limit:        params.Limit,
@@ -113,17 +221,39 @@ func (t *HybridSearchTool) Execute(ctx context.Context, args map[string]interfac
 		VectorWeight: params.VectorWeight,
 		MinBM25Score: 0.0,
 		MinVectorSim: 0.0,
+		Filters:      filter,
+		EfSearch:     params.EfSearch,
+		Probes:       params.Probes,
+
+		CorrectSpelling: params.CorrectSpelling,
+		Synonyms:        synonyms,
+	}
+	if params.Diversify {
+		// Over-fetch so that diversification has alternatives to near-duplicates
+		dbParams.Limit = params.Limit * diversifyCandidateFactor
 	}
 
-	results, err := t.db.SimpleHybridSearch(ctx, tenantID, dbParams)
+	// The raw weighted sum keeps the original SimpleHybridSearch candidate selection
+	var results []storage.HybridSearchResult
+	if params.Fusion == storage.FusionWeighted {
+		results, err = t.db.SimpleHybridSearch(ctx, tenantID, dbParams)
+	} else {
+		dbParams.Fusion = params.Fusion
+		dbParams.RRFK = params.RRFK
+		results, err = t.db.HybridSearch(ctx, tenantID, dbParams)
+	}
 	if err != nil {
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("hybrid search failed: %w", err)
 	}
+	if params.Diversify {
+		results = storage.Diversify(results, lambda, params.Limit)
+	}
 
 	// Format results as JSON for UI consumption
 	type DocumentResult struct {
 		DocID       string                 `json:"doc_id"`
 		TenantID    string                 `json:"tenant_id"`
+		Collection  string                 `json:"collection"`
 		Title       string                 `json:"title"`
 		Content     string                 `json:"content"`
 		Score       float64                `json:"score"`
@@ -135,12 +265,19 @@ func (t *HybridSearchTool) Execute(ctx context.Context, args map[string]interfac
 		CreatedAt   string                 `json:"created_at"`
 	}
 
+	docIDs := make([]string, 0, len(results))
+	for _, result := range results {
+		docIDs = append(docIDs, result.Document.ID)
+	}
+	t.recordAccess(ctx, "hybrid_search", docIDs...)
+
 	var jsonResults []DocumentResult
 	for i, result := range results {
 		doc := result.Document
 		jsonResults = append(jsonResults, DocumentResult{
 			DocID:       doc.ID,
 			TenantID:    doc.TenantID,
+			Collection:  storage.CollectionOrDefault(doc.Collection),
 			Title:       doc.Title,
 			Content:     doc.Content,
 			Score:       result.CombinedScore,
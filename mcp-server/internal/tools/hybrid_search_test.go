@@ -6,8 +6,9 @@ import (
 	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -52,9 +53,9 @@ func TestHybridSearchToolExecute(t *testing.T) {
 				"vector_weight": 0.4,
 			},
 			setupMock: func(m *MockStore) {
-				results := []database.HybridSearchResult{
+				results := []storage.HybridSearchResult{
 					{
-						Document: database.Document{
+						Document: storage.Document{
 							ID:        "doc-1",
 							TenantID:  "tenant-123",
 							Title:     "ML Guide",
@@ -67,7 +68,7 @@ func TestHybridSearchToolExecute(t *testing.T) {
 						CombinedScore: 1.84, // (2.5 * 0.6) + (0.85 * 0.4)
 					},
 				}
-				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params database.HybridSearchParams) bool {
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
 					return params.Query == "machine learning" &&
 						params.Limit == 5 &&
 						params.BM25Weight == 0.6 &&
@@ -101,8 +102,8 @@ func TestHybridSearchToolExecute(t *testing.T) {
 				"vector_weight": 0.5,
 			},
 			setupMock: func(m *MockStore) {
-				results := []database.HybridSearchResult{}
-				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params database.HybridSearchParams) bool {
+				results := []storage.HybridSearchResult{}
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
 					return params.Query == "AI" &&
 						len(params.Embedding) == 3 &&
 						params.Embedding[0] == 0.1
@@ -116,6 +117,22 @@ func TestHybridSearchToolExecute(t *testing.T) {
 				assert.True(t, text == "null" || text == "[]", "Expected 'null' or '[]', got: %s", text)
 			},
 		},
+		{
+			name: "hybrid search with metadata filters",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":   "policy",
+				"filters": map[string]interface{}{"tags": []interface{}{"mfa", "vpn"}},
+			},
+			setupMock: func(m *MockStore) {
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+					return assert.ObjectsAreEqual(storage.MetadataFilter{"tags": {"mfa", "vpn"}}, params.Filters)
+				})).Return([]storage.HybridSearchResult{}, nil)
+			},
+			wantErr: false,
+		},
 		{
 			name: "search with no results",
 			setupAuth: func(ctx context.Context) context.Context {
@@ -126,7 +143,7 @@ func TestHybridSearchToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.Anything).
-					Return([]database.HybridSearchResult{}, nil)
+					Return([]storage.HybridSearchResult{}, nil)
 			},
 			wantErr: false,
 			validate: func(t *testing.T, result protocol.ToolCallResult) {
@@ -184,11 +201,11 @@ func TestHybridSearchToolExecute(t *testing.T) {
 				"query": "test",
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params database.HybridSearchParams) bool {
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
 					return params.Limit == 10 &&
 						params.BM25Weight == 0.5 &&
 						params.VectorWeight == 0.5
-				})).Return([]database.HybridSearchResult{}, nil)
+				})).Return([]storage.HybridSearchResult{}, nil)
 			},
 			wantErr: false,
 		},
@@ -202,9 +219,9 @@ func TestHybridSearchToolExecute(t *testing.T) {
 				"limit": 100,
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params database.HybridSearchParams) bool {
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
 					return params.Limit == 50
-				})).Return([]database.HybridSearchResult{}, nil)
+				})).Return([]storage.HybridSearchResult{}, nil)
 			},
 			wantErr: false,
 		},
@@ -219,11 +236,116 @@ func TestHybridSearchToolExecute(t *testing.T) {
 				"vector_weight": 0.3,
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params database.HybridSearchParams) bool {
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
 					return params.BM25Weight == 0.7 && params.VectorWeight == 0.3
-				})).Return([]database.HybridSearchResult{}, nil)
+				})).Return([]storage.HybridSearchResult{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "normalized fusion uses HybridSearch",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":  "test",
+				"fusion": "minmax",
+			},
+			setupMock: func(m *MockStore) {
+				m.On("HybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+					return params.Fusion == storage.FusionMinMax
+				})).Return([]storage.HybridSearchResult{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "rrf fusion with custom k",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":  "test",
+				"fusion": "rrf",
+				"rrf_k":  20,
+			},
+			setupMock: func(m *MockStore) {
+				m.On("HybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+					return params.Fusion == storage.FusionRRF && params.RRFK == 20
+				})).Return([]storage.HybridSearchResult{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "diversify over-fetches and drops near duplicates",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":     "test",
+				"limit":     2,
+				"diversify": true,
+			},
+			setupMock: func(m *MockStore) {
+				results := []storage.HybridSearchResult{
+					{Document: storage.Document{ID: "chunk-1", Title: "Chunk 1", Embedding: []float32{1, 0}}, CombinedScore: 1.0},
+					{Document: storage.Document{ID: "chunk-2", Title: "Chunk 2", Embedding: []float32{1, 0.01}}, CombinedScore: 0.98},
+					{Document: storage.Document{ID: "other", Title: "Other", Embedding: []float32{0, 1}}, CombinedScore: 0.9},
+					{Document: storage.Document{ID: "tail", Title: "Tail", Embedding: []float32{0.7, 0.7}}, CombinedScore: 0.1},
+				}
+				m.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+					return params.Limit == 6
+				})).Return(results, nil)
 			},
 			wantErr: false,
+			validate: func(t *testing.T, result protocol.ToolCallResult) {
+				assert.Contains(t, result.Content[0].Text, `"doc_id":"chunk-1"`)
+				assert.Contains(t, result.Content[0].Text, `"doc_id":"other"`)
+				assert.NotContains(t, result.Content[0].Text, `"doc_id":"chunk-2"`)
+			},
+		},
+		{
+			name: "diversity_lambda out of range",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":            "test",
+				"diversify":        true,
+				"diversity_lambda": 1.5,
+			},
+			setupMock: func(m *MockStore) {
+				// Rejected before reaching the store
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown fusion",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":  "test",
+				"fusion": "borda",
+			},
+			setupMock: func(m *MockStore) {
+				// Rejected before reaching the store
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative rrf_k",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":  "test",
+				"fusion": "rrf",
+				"rrf_k":  -1,
+			},
+			setupMock: func(m *MockStore) {
+				// Rejected before reaching the store
+			},
+			wantErr: true,
 		},
 		{
 			name: "database error",
@@ -265,6 +387,78 @@ func TestHybridSearchToolExecute(t *testing.T) {
 	}
 }
 
+func TestHybridSearchToolDefaultFusion(t *testing.T) {
+	mockDB := new(MockStore)
+	tool := NewHybridSearchTool(mockDB)
+
+	assert.Error(t, tool.SetDefaultFusion("borda", 0))
+	assert.Error(t, tool.SetDefaultFusion(storage.FusionRRF, -5))
+	assert.NoError(t, tool.SetDefaultFusion(storage.FusionZScore, 30))
+
+	mockDB.On("HybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+		return params.Fusion == storage.FusionZScore && params.RRFK == 30
+	})).Return([]storage.HybridSearchResult{}, nil).Once()
+	mockDB.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.Anything).
+		Return([]storage.HybridSearchResult{}, nil).Once()
+
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+
+	// Configured default applies when the call does not choose a method
+	_, err := tool.Execute(ctx, map[string]interface{}{"query": "test"})
+	assert.NoError(t, err)
+
+	// A per-call method overrides the default
+	_, err = tool.Execute(ctx, map[string]interface{}{"query": "test", "fusion": "weighted"})
+	assert.NoError(t, err)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestHybridSearchToolANNTuning(t *testing.T) {
+	mockDB := new(MockStore)
+	tool := NewHybridSearchTool(mockDB)
+
+	mockDB.On("HybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+		return params.EfSearch == 200 && params.Probes == 10
+	})).Return([]storage.HybridSearchResult{}, nil).Once()
+
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	_, err := tool.Execute(ctx, map[string]interface{}{"query": "test", "fusion": "rrf", "ef_search": 200, "probes": 10})
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+
+	violations, err := ValidateArguments(tool.Definition().InputSchema, map[string]interface{}{"query": "test", "ef_search": 5000})
+	assert.NoError(t, err)
+	assert.Equal(t, []Violation{{Field: "/ef_search", Message: "maximum: got 5,000, want 1,000"}}, violations)
+}
+
+func TestHybridSearchToolQueryPreprocessing(t *testing.T) {
+	mockDB := new(MockStore)
+	tool := NewHybridSearchTool(mockDB)
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+
+	// Synonym expansion needs a dictionary lookup
+	_, err := tool.Execute(ctx, map[string]interface{}{"query": "car", "expand_synonyms": true})
+	assert.ErrorContains(t, err, "synonym expansion is not enabled")
+
+	lookup := synonyms.NewMemoryStore()
+	assert.NoError(t, lookup.PutSynonyms(ctx, "tenant-123", synonyms.Dictionary{"car": {"automobile"}}))
+	tool.SetSynonymLookup(lookup)
+
+	mockDB.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+		return params.CorrectSpelling && assert.ObjectsAreEqual(map[string][]string{"car": {"automobile"}}, params.Synonyms)
+	})).Return([]storage.HybridSearchResult{}, nil).Once()
+	mockDB.On("SimpleHybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+		return !params.CorrectSpelling && params.Synonyms == nil
+	})).Return([]storage.HybridSearchResult{}, nil).Once()
+
+	_, err = tool.Execute(ctx, map[string]interface{}{"query": "car", "correct_spelling": true, "expand_synonyms": true})
+	assert.NoError(t, err)
+	_, err = tool.Execute(ctx, map[string]interface{}{"query": "car"})
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
 func TestHybridSearchToolInvalidArguments(t *testing.T) {
 	mockDB := new(MockStore)
 	tool := NewHybridSearchTool(mockDB)
@@ -285,9 +479,9 @@ func BenchmarkHybridSearchToolExecute(b *testing.B) {
 	mockDB := new(MockStore)
 	now := time.Now()
 
-	results := []database.HybridSearchResult{
+	results := []storage.HybridSearchResult{
 		{
-			Document: database.Document{
+			Document: storage.Document{
 				ID:        "doc-1",
 				Title:     "Benchmark Doc",
 				Content:   "Content for benchmarking",
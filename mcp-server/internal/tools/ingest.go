@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/embeddings"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/redaction"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// MaxIngestContentBytes bounds the content of one ingested document
+const MaxIngestContentBytes = 1 << 20
+
+// IngestTool adds documents to the caller's tenant
+type IngestTool struct {
+	db       storage.Writer
+	embedder embeddings.Provider
+	redactor *redaction.Redactor
+}
+
+// NewIngestTool creates an ingest tool writing documents to db
+func NewIngestTool(db storage.Writer) *IngestTool {
+	return &IngestTool{db: db}
+}
+
+// SetEmbedder computes the embedding of documents ingested without one, so they can be
+// found by vector and hybrid search
+func (t *IngestTool) SetEmbedder(embedder embeddings.Provider) {
+	t.embedder = embedder
+}
+
+// SetRedactor masks PII in the title and content of ingested documents, before they are
+// embedded, when the tenant's policy redacts at ingest
+func (t *IngestTool) SetRedactor(redactor *redaction.Redactor) {
+	t.redactor = redactor
+}
+
+// Definition returns the tool definition for MCP
+func (t *IngestTool) Definition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "ingest_document",
+		Description: "Add a document to the tenant's documents. Returns the ID of the new document.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "The document title",
+					"maxLength":   500,
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "The document text",
+					"maxLength":   MaxIngestContentBytes,
+				},
+				"metadata": map[string]interface{}{
+					"type":        "object",
+					"description": "Metadata to filter searches by, e.g. {\"category\": \"security\"}",
+				},
+				"embedding": map[string]interface{}{
+					"type":        "array",
+					"description": "Document embedding vector; computed by the server when omitted and an embeddings provider is configured",
+					"items": map[string]interface{}{
+						"type": "number",
+					},
+				},
+				"collection": map[string]interface{}{
+					"type":        "string",
+					"description": "Collection to add the document to (default: default)",
+					"pattern":     "^[a-z0-9][a-z0-9_-]{0,63}$",
+				},
+			},
+			"required": []string{"title", "content"},
+		},
+	}
+}
+
+// IngestParams represents the parameters for ingest_document
+type IngestParams struct {
+	Title      string                 `json:"title"`
+	Content    string                 `json:"content"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Embedding  []float32              `json:"embedding,omitempty"`
+	Collection string                 `json:"collection,omitempty"`
+}
+
+// Execute inserts a document
+func (t *IngestTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	var params IngestParams
+	if err := json.Unmarshal(argsJSON, &params); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	params.Title = strings.TrimSpace(params.Title)
+	if params.Title == "" || strings.TrimSpace(params.Content) == "" {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("title and content are required")
+	}
+	if len(params.Content) > MaxIngestContentBytes {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("content exceeds %d bytes", MaxIngestContentBytes)
+	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+
+	if t.redactor != nil {
+		params.Title = t.redactor.Redact(ctx, tenantID, redaction.StageIngest, params.Title)
+		params.Content = t.redactor.Redact(ctx, tenantID, redaction.StageIngest, params.Content)
+	}
+
+	if len(params.Embedding) == 0 && t.embedder != nil {
+		if params.Embedding, err = t.embedder.Embed(ctx, params.Title+"\n\n"+params.Content); err != nil {
+			// The document is still found by lexical search
+			slog.WarnContext(ctx, "Failed to embed document, storing it without an embedding", "error", err)
+			params.Embedding = nil
+		}
+	}
+
+	doc := &storage.Document{
+		Collection: params.Collection,
+		Title:      params.Title,
+		Content:    params.Content,
+		Metadata:   params.Metadata,
+		Embedding:  params.Embedding,
+	}
+	if userID, err := auth.ExtractUserID(ctx); err == nil {
+		doc.CreatedBy = &userID
+	}
+	if err := t.db.InsertDocument(ctx, tenantID, doc); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to ingest document: %w", err)
+	}
+
+	return protocol.ToolCallResult{
+		Content: []protocol.ContentBlock{{
+			Type: "text",
+			Text: fmt.Sprintf("Document ingested: %s (collection %s)", doc.ID, storage.CollectionOrDefault(doc.Collection)),
+		}},
+	}, nil
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/redaction"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestToolExecute(t *testing.T) {
+	ctx := auth.WithAuth(context.Background(), &auth.Claims{TenantID: "tenant-123", UserID: "alice"})
+	store := storage.NewMemoryStore()
+	embedder := &fakeEmbedder{}
+	tool := NewIngestTool(store)
+	tool.SetEmbedder(embedder)
+
+	result, err := tool.Execute(ctx, map[string]interface{}{
+		"title":      " Refund policy ",
+		"content":    "Refunds take 5 days.",
+		"metadata":   map[string]interface{}{"category": "billing"},
+		"collection": "policies",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].Text, "collection policies")
+
+	docs, err := store.ListDocuments(ctx, "tenant-123", "policies", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Contains(t, result.Content[0].Text, docs[0].ID)
+	assert.Equal(t, "Refund policy", docs[0].Title)
+	assert.Equal(t, "billing", docs[0].Metadata["category"])
+	assert.Equal(t, []float32{0.1, 0.2}, docs[0].Embedding)
+	assert.Equal(t, "alice", *docs[0].CreatedBy)
+	assert.Equal(t, []string{"Refund policy\n\nRefunds take 5 days."}, embedder.texts)
+
+	// Documents are stored without an embedding when embedding fails
+	tool.SetEmbedder(&fakeEmbedder{err: errors.New("provider down")})
+	_, err = tool.Execute(ctx, map[string]interface{}{"title": "Support hours", "content": "Weekdays."})
+	require.NoError(t, err)
+	docs, err = store.ListDocuments(ctx, "tenant-123", "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Empty(t, docs[0].Embedding)
+}
+
+func TestIngestToolExecute_Errors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	tool := NewIngestTool(storage.NewMemoryStore())
+
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"title": "t", "content": "c"})
+	assert.ErrorContains(t, err, "authentication required")
+	_, err = tool.Execute(ctx, map[string]interface{}{"title": "t", "content": "  "})
+	assert.ErrorContains(t, err, "title and content are required")
+	_, err = tool.Execute(ctx, map[string]interface{}{"title": "t", "content": strings.Repeat("x", MaxIngestContentBytes+1)})
+	assert.ErrorContains(t, err, "content exceeds")
+	_, err = tool.Execute(ctx, map[string]interface{}{"title": "t", "content": "c", "collection": "Not Valid"})
+	assert.Error(t, err)
+}
+
+func TestIngestToolExecute_Redaction(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	store := storage.NewMemoryStore()
+	embedder := &fakeEmbedder{}
+	tool := NewIngestTool(store)
+	tool.SetEmbedder(embedder)
+	tool.SetRedactor(redaction.NewRedactor(redaction.Config{Policy: redaction.Policy{Ingest: true}}))
+
+	_, err := tool.Execute(ctx, map[string]interface{}{"title": "Contact jane@example.com", "content": "SSN 123-45-6789"})
+	require.NoError(t, err)
+
+	docs, err := store.ListDocuments(ctx, "tenant-123", "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Contact [REDACTED_EMAIL]", docs[0].Title)
+	assert.Equal(t, "SSN [REDACTED_SSN]", docs[0].Content)
+	assert.Equal(t, []string{"Contact [REDACTED_EMAIL]\n\nSSN [REDACTED_SSN]"}, embedder.texts, "redacted text is embedded")
+}
@@ -6,17 +6,18 @@ import (
 	"fmt"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 )
 
 // ListTool implements document listing
 type ListTool struct {
-	db database.Store
+	documentAccess
+	db storage.Store
 }
 
 // NewListTool creates a new list tool
-func NewListTool(db database.Store) *ListTool {
+func NewListTool(db storage.Store) *ListTool {
 	return &ListTool{db: db}
 }
 
@@ -24,7 +25,7 @@ func NewListTool(db database.Store) *ListTool {
 func (t *ListTool) Definition() protocol.Tool {
 	return protocol.Tool{
 		Name:        "list_documents",
-		Description: "List all documents for the current tenant with pagination support.",
+		Description: "List the documents of a collection of the current tenant with pagination support.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -38,6 +39,7 @@ func (t *ListTool) Definition() protocol.Tool {
 					"description": "Number of documents to skip (default: 0)",
 					"default":     0,
 				},
+				"collection": collectionSchema(),
 			},
 		},
 	}
@@ -45,8 +47,9 @@ func (t *ListTool) Definition() protocol.Tool {
 
 // ListParams represents the parameters for list
 type ListParams struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Collection string `json:"collection,omitempty"`
 }
 
 // Execute lists documents
@@ -80,13 +83,22 @@ func (t *ListTool) Execute(ctx context.Context, args map[string]interface{}) (pr
 	if params.Offset < 0 {
 		params.Offset = 0
 	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
 
 	// List documents
-	documents, err := t.db.ListDocuments(ctx, tenantID, params.Limit, params.Offset)
+	documents, err := t.db.ListDocuments(ctx, tenantID, params.Collection, params.Limit, params.Offset)
 	if err != nil {
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to list documents: %w", err)
 	}
 
+	docIDs := make([]string, 0, len(documents))
+	for _, doc := range documents {
+		docIDs = append(docIDs, doc.ID)
+	}
+	t.recordAccess(ctx, "list_documents", docIDs...)
+
 	// Format results
 	var resultText string
 	if len(documents) == 0 {
@@ -6,8 +6,8 @@ import (
 	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -45,7 +45,7 @@ func TestListToolExecute(t *testing.T) {
 				"offset": 0,
 			},
 			setupMock: func(m *MockStore) {
-				docs := []*database.Document{
+				docs := []*storage.Document{
 					{
 						ID:        "doc-1",
 						TenantID:  "tenant-123",
@@ -88,7 +88,7 @@ func TestListToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				m.On("ListDocuments", mock.Anything, "tenant-123", 10, 0).
-					Return([]*database.Document{}, nil)
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 			validate: func(t *testing.T, result protocol.ToolCallResult) {
@@ -117,7 +117,7 @@ func TestListToolExecute(t *testing.T) {
 			args: map[string]interface{}{},
 			setupMock: func(m *MockStore) {
 				m.On("ListDocuments", mock.Anything, "tenant-123", 20, 0).
-					Return([]*database.Document{}, nil)
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
@@ -132,7 +132,7 @@ func TestListToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				m.On("ListDocuments", mock.Anything, "tenant-123", 5, 10).
-					Return([]*database.Document{}, nil)
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
@@ -147,7 +147,7 @@ func TestListToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				m.On("ListDocuments", mock.Anything, "tenant-123", 100, 0).
-					Return([]*database.Document{}, nil)
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
@@ -162,7 +162,7 @@ func TestListToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				m.On("ListDocuments", mock.Anything, "tenant-123", 10, 0).
-					Return([]*database.Document{}, nil)
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
@@ -177,7 +177,7 @@ func TestListToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				m.On("ListDocuments", mock.Anything, "tenant-123", 20, 0).
-					Return([]*database.Document{}, nil)
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
@@ -241,7 +241,7 @@ func BenchmarkListToolExecute(b *testing.B) {
 	mockDB := new(MockStore)
 	now := time.Now()
 
-	docs := []*database.Document{
+	docs := []*storage.Document{
 		{ID: "doc-1", Title: "Doc 1", Content: "Content 1", Metadata: map[string]interface{}{}, CreatedAt: now},
 		{ID: "doc-2", Title: "Doc 2", Content: "Content 2", Metadata: map[string]interface{}{}, CreatedAt: now},
 	}
@@ -0,0 +1,22 @@
+package tools
+
+import "fmt"
+
+// PermissionError is returned when the caller lacks the scope a tool requires
+type PermissionError struct {
+	Tool  string
+	Scope string
+}
+
+// Error implements the error interface
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("tool %s requires the %q scope", e.Tool, e.Scope)
+}
+
+// Data returns structured details for the JSON-RPC error response
+func (e *PermissionError) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"tool":           e.Tool,
+		"required_scope": e.Scope,
+	}
+}
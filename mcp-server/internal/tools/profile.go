@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+)
+
+// ProfileLookup resolves a tenant's named search profile
+type ProfileLookup interface {
+	GetProfile(ctx context.Context, tenantID, name string) (*profiles.Profile, error)
+}
+
+// ProfileLookupSetter is implemented by tools that accept a "profile" argument
+type ProfileLookupSetter interface {
+	SetProfileLookup(lookup ProfileLookup)
+}
+
+// searchProfiles is embedded in tools that accept a "profile" argument
+type searchProfiles struct {
+	lookup ProfileLookup
+}
+
+// SetProfileLookup sets where profiles named in tool calls are resolved
+func (s *searchProfiles) SetProfileLookup(lookup ProfileLookup) {
+	s.lookup = lookup
+}
+
+// applyProfile fills in the settings of the profile named by the "profile" argument.
+// Arguments passed with the call take precedence over the profile.
+func (s *searchProfiles) applyProfile(ctx context.Context, tenantID string, args map[string]interface{}) (map[string]interface{}, error) {
+	raw, ok := args["profile"]
+	if !ok || raw == nil {
+		return args, nil
+	}
+	name, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("profile must be a string")
+	}
+	if name == "" {
+		return args, nil
+	}
+	if s.lookup == nil {
+		return nil, fmt.Errorf("search profiles are not enabled")
+	}
+
+	profile, err := s.lookup.GetProfile(ctx, tenantID, name)
+	if errors.Is(err, profiles.ErrNotFound) {
+		return nil, fmt.Errorf("unknown search profile %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load search profile: %w", err)
+	}
+	return profile.Apply(args), nil
+}
+
+// profileSchema documents the "profile" argument shared by the search tools
+func profileSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "string",
+		"description": "Name of a search profile configured for the tenant (e.g. \"support-kb\"). " +
+			"The profile supplies defaults for the other arguments; arguments passed explicitly take precedence.",
+	}
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/profiles"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfileLookup(t *testing.T) *profiles.MemoryStore {
+	weight := 0.9
+	diversify := true
+	store := profiles.NewMemoryStore()
+	require.NoError(t, store.PutProfile(context.Background(), "tenant-123", profiles.Profile{
+		Name:       "support-kb",
+		Limit:      7,
+		BM25Weight: &weight,
+		Fusion:     storage.FusionRRF,
+		Diversify:  &diversify,
+		Filters:    map[string]interface{}{"category": "support"},
+	}))
+	return store
+}
+
+func TestSearchProfiles(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+
+	t.Run("hybrid_search applies the profile", func(t *testing.T) {
+		mockDB := new(MockStore)
+		mockDB.On("HybridSearch", mock.Anything, "tenant-123", mock.MatchedBy(func(params storage.HybridSearchParams) bool {
+			return params.Limit == 7*diversifyCandidateFactor &&
+				params.BM25Weight == 0.9 &&
+				params.VectorWeight == 0 &&
+				params.Fusion == storage.FusionRRF &&
+				params.Filters["category"][0] == "support"
+		})).Return([]storage.HybridSearchResult{}, nil)
+
+		registry := NewRegistry()
+		registry.Register(NewHybridSearchTool(mockDB))
+		registry.SetProfileLookup(newProfileLookup(t))
+
+		result, err := registry.Execute(ctx, "hybrid_search", map[string]interface{}{"query": "refunds", "profile": "support-kb"})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("call arguments override the profile", func(t *testing.T) {
+		mockDB := new(MockStore)
+		mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "refunds", 3, storage.MetadataFilter{
+			"category": {"billing"},
+		}).Return([]*storage.Document{}, nil)
+
+		tool := NewSearchTool(mockDB)
+		tool.SetProfileLookup(newProfileLookup(t))
+
+		_, err := tool.Execute(ctx, map[string]interface{}{
+			"query":   "refunds",
+			"profile": "support-kb",
+			"limit":   3,
+			"filters": map[string]interface{}{"category": "billing"},
+		})
+		require.NoError(t, err)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		tool := NewSearchTool(new(MockStore))
+		tool.SetProfileLookup(newProfileLookup(t))
+
+		result, err := tool.Execute(ctx, map[string]interface{}{"query": "refunds", "profile": "legal"})
+		assert.True(t, result.IsError)
+		assert.ErrorContains(t, err, `unknown search profile "legal"`)
+	})
+
+	t.Run("profiles not configured", func(t *testing.T) {
+		tool := NewHybridSearchTool(new(MockStore))
+
+		result, err := tool.Execute(ctx, map[string]interface{}{"query": "refunds", "profile": "support-kb"})
+		assert.True(t, result.IsError)
+		assert.ErrorContains(t, err, "not enabled")
+	})
+}
@@ -2,11 +2,25 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
 )
 
+// toolNameKey is the context key of the name of the executing tool
+type toolNameKey struct{}
+
+// ToolName returns the name of the tool executing with ctx, or "" outside tool calls
+func ToolName(ctx context.Context) string {
+	name, _ := ctx.Value(toolNameKey{}).(string)
+	return name
+}
+
 // Tool represents an MCP tool that can be executed
 type Tool interface {
 	// Definition returns the MCP tool definition
@@ -15,32 +29,105 @@ type Tool interface {
 	Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error)
 }
 
+// TenantTools supplies tools registered by individual tenants, such as uploaded WASM modules
+type TenantTools interface {
+	// Tools returns the tools the tenant registered
+	Tools(tenantID string) []Tool
+}
+
+// ListChangedFunc is called after the tool list changes. tenantID is the tenant whose
+// own tools changed, or "" when the built-in tools every tenant sees changed.
+type ListChangedFunc func(tenantID string)
+
 // Registry manages available tools
 type Registry struct {
-	tools map[string]Tool
+	// Built-in tools can be registered and unregistered while requests run
+	toolsMu   sync.RWMutex
+	tools     map[string]Tool
+	listeners []ListChangedFunc
+
+	// Tools only the registering tenant can list and call; built-in tools take precedence
+	tenantTools TenantTools
+
+	// Execution timeouts; zero means no limit. They can change while tools run.
+	timeoutsMu     sync.RWMutex
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+
+	// Scopes callers need to execute a tool; tools without an entry are open to any caller
+	requiredScopes map[string]string
+
+	// Tools refused while safe mode is on; tenant tools always are
+	safeMode   *safemode.Switch
+	restricted map[string]bool
 }
 
 // NewRegistry creates a new tool registry
 func NewRegistry() *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
+		tools:          make(map[string]Tool),
+		timeouts:       make(map[string]time.Duration),
+		requiredScopes: make(map[string]string),
+		restricted:     make(map[string]bool),
 	}
 }
 
-// Register registers a new tool
+// Register registers a new tool, replacing a tool of the same name
 func (r *Registry) Register(tool Tool) {
 	def := tool.Definition()
+	r.toolsMu.Lock()
 	r.tools[def.Name] = tool
+	r.toolsMu.Unlock()
+	r.listChanged("")
+}
+
+// Unregister removes a tool and returns it, if it was registered
+func (r *Registry) Unregister(name string) (Tool, bool) {
+	r.toolsMu.Lock()
+	tool, ok := r.tools[name]
+	delete(r.tools, name)
+	r.toolsMu.Unlock()
+	if ok {
+		r.listChanged("")
+	}
+	return tool, ok
+}
+
+// OnListChanged registers a function called after tools are registered or unregistered,
+// and after a tenant's own tools change
+func (r *Registry) OnListChanged(fn ListChangedFunc) {
+	r.toolsMu.Lock()
+	defer r.toolsMu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// TenantToolsChanged reports that the tools a tenant registered changed
+func (r *Registry) TenantToolsChanged(tenantID string) {
+	r.listChanged(tenantID)
+}
+
+// listChanged calls the list change listeners
+func (r *Registry) listChanged(tenantID string) {
+	r.toolsMu.RLock()
+	listeners := r.listeners
+	r.toolsMu.RUnlock()
+	for _, fn := range listeners {
+		fn(tenantID)
+	}
 }
 
 // Get retrieves a tool by name
 func (r *Registry) Get(name string) (Tool, bool) {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
 	tool, ok := r.tools[name]
 	return tool, ok
 }
 
 // List returns all registered tools
 func (r *Registry) List() []protocol.Tool {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
 	tools := make([]protocol.Tool, 0, len(r.tools))
 	for _, tool := range r.tools {
 		tools = append(tools, tool.Definition())
@@ -48,14 +135,233 @@ func (r *Registry) List() []protocol.Tool {
 	return tools
 }
 
-// Execute executes a tool by name
+// SetTenantTools adds the tools each tenant registered to what its callers can list and call
+func (r *Registry) SetTenantTools(tenantTools TenantTools) {
+	r.tenantTools = tenantTools
+}
+
+// ListFor returns the registered tools plus the tools of the caller's tenant. While safe
+// mode is on, tools it disables are annotated as such.
+func (r *Registry) ListFor(ctx context.Context) []protocol.Tool {
+	tools := r.List()
+	for _, tool := range r.tenantToolsFor(ctx) {
+		if def := tool.Definition(); !r.isBuiltin(def.Name) {
+			tools = append(tools, def)
+		}
+	}
+
+	if r.safeMode == nil {
+		return tools
+	}
+	state := r.safeMode.State()
+	if !state.Enabled {
+		return tools
+	}
+	for i := range tools {
+		if r.isRestricted(tools[i].Name) {
+			reason := "disabled while safe mode is on"
+			if state.Reason != "" {
+				reason += ": " + state.Reason
+			}
+			tools[i].Annotations = &protocol.ToolAnnotations{Disabled: true, DisabledReason: reason}
+		}
+	}
+	return tools
+}
+
+// lookup finds a registered tool, falling back to the tools of the caller's tenant
+func (r *Registry) lookup(ctx context.Context, name string) (Tool, bool) {
+	if tool, ok := r.Get(name); ok {
+		return tool, true
+	}
+	for _, tool := range r.tenantToolsFor(ctx) {
+		if tool.Definition().Name == name {
+			return tool, true
+		}
+	}
+	return nil, false
+}
+
+// tenantToolsFor returns the tools registered by the caller's tenant, if any
+func (r *Registry) tenantToolsFor(ctx context.Context) []Tool {
+	if r.tenantTools == nil {
+		return nil
+	}
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return nil
+	}
+	return r.tenantTools.Tools(tenantID)
+}
+
+// SetAccessRecorder attaches a document access recorder to every registered tool that returns document content
+func (r *Registry) SetAccessRecorder(recorder AccessRecorder) {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
+	for _, tool := range r.tools {
+		if setter, ok := tool.(AccessRecorderSetter); ok {
+			setter.SetAccessRecorder(recorder)
+		}
+	}
+}
+
+// SetProfileLookup attaches a search profile lookup to every registered tool that accepts a "profile" argument
+func (r *Registry) SetProfileLookup(lookup ProfileLookup) {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
+	for _, tool := range r.tools {
+		if setter, ok := tool.(ProfileLookupSetter); ok {
+			setter.SetProfileLookup(lookup)
+		}
+	}
+}
+
+// SetSynonymLookup attaches a synonym dictionary lookup to every registered tool that accepts an "expand_synonyms" argument
+func (r *Registry) SetSynonymLookup(lookup SynonymLookup) {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
+	for _, tool := range r.tools {
+		if setter, ok := tool.(SynonymLookupSetter); ok {
+			setter.SetSynonymLookup(lookup)
+		}
+	}
+}
+
+// SetDefaultTimeout sets the execution timeout for tools without an override
+func (r *Registry) SetDefaultTimeout(timeout time.Duration) {
+	r.timeoutsMu.Lock()
+	defer r.timeoutsMu.Unlock()
+	r.defaultTimeout = timeout
+}
+
+// SetTimeout overrides the execution timeout for a single tool
+func (r *Registry) SetTimeout(name string, timeout time.Duration) {
+	r.timeoutsMu.Lock()
+	defer r.timeoutsMu.Unlock()
+	r.timeouts[name] = timeout
+}
+
+// SetTimeouts replaces the default timeout and every per-tool override at once
+func (r *Registry) SetTimeouts(defaultTimeout time.Duration, overrides map[string]time.Duration) {
+	timeouts := make(map[string]time.Duration, len(overrides))
+	for name, timeout := range overrides {
+		timeouts[name] = timeout
+	}
+
+	r.timeoutsMu.Lock()
+	defer r.timeoutsMu.Unlock()
+	r.defaultTimeout = defaultTimeout
+	r.timeouts = timeouts
+}
+
+// Timeout returns the execution timeout for a tool
+func (r *Registry) Timeout(name string) time.Duration {
+	r.timeoutsMu.RLock()
+	defer r.timeoutsMu.RUnlock()
+	if timeout, ok := r.timeouts[name]; ok {
+		return timeout
+	}
+	return r.defaultTimeout
+}
+
+// SetRequiredScope requires callers to hold scope, directly or through a role, to execute a tool
+func (r *Registry) SetRequiredScope(name, scope string) {
+	r.requiredScopes[name] = scope
+}
+
+// RequiredScope returns the scope required to execute a tool, or "" if there is none
+func (r *Registry) RequiredScope(name string) string {
+	return r.requiredScopes[name]
+}
+
+// SetSafeMode refuses restricted tools and tenant tools while safe mode is on
+func (r *Registry) SetSafeMode(safeMode *safemode.Switch) {
+	r.safeMode = safeMode
+}
+
+// SetRestricted marks a built-in tool as too expensive or destructive to run in safe mode
+func (r *Registry) SetRestricted(name string) {
+	r.restricted[name] = true
+}
+
+// isRestricted reports whether safe mode disables a tool: built-in tools marked with
+// SetRestricted and every tenant tool, whose cost is unknown
+func (r *Registry) isRestricted(name string) bool {
+	if !r.isBuiltin(name) {
+		return true
+	}
+	return r.restricted[name]
+}
+
+// isBuiltin reports whether name is a registered built-in tool
+func (r *Registry) isBuiltin(name string) bool {
+	_, ok := r.Get(name)
+	return ok
+}
+
+// Execute executes a tool by name.
+// A *PermissionError is returned if the caller lacks the tool's required scope, a
+// *safemode.Error if the tool is restricted while safe mode is on, and an *ArgumentError
+// if args do not match the tool's input schema; the tool does not run in those cases.
+// If the tool has a timeout, its context is cancelled when the timeout expires and a
+// *TimeoutError is returned right away, even if the tool itself ignores cancellation.
 func (r *Registry) Execute(ctx context.Context, name string, args map[string]interface{}) (protocol.ToolCallResult, error) {
-	tool, ok := r.Get(name)
+	tool, ok := r.lookup(ctx, name)
 	if !ok {
 		return protocol.ToolCallResult{
 			IsError: true,
 		}, fmt.Errorf("tool not found: %s", name)
 	}
 
-	return tool.Execute(ctx, args)
+	if scope := r.RequiredScope(name); scope != "" && !auth.HasScope(ctx, scope) {
+		return protocol.ToolCallResult{IsError: true}, &PermissionError{Tool: name, Scope: scope}
+	}
+
+	if r.isRestricted(name) {
+		if err := r.safeMode.Check("tool " + name); err != nil {
+			return protocol.ToolCallResult{IsError: true}, err
+		}
+	}
+
+	violations, err := ValidateArguments(tool.Definition().InputSchema, args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("tool %s: %w", name, err)
+	}
+	if len(violations) > 0 {
+		return protocol.ToolCallResult{IsError: true}, &ArgumentError{Tool: name, Violations: violations}
+	}
+
+	ctx = context.WithValue(ctx, toolNameKey{}, name)
+	timeout := r.Timeout(name)
+	if timeout <= 0 {
+		return tool.Execute(ctx, args)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result protocol.ToolCallResult
+		err    error
+	}
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := tool.Execute(ctx, args)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return protocol.ToolCallResult{IsError: true}, &TimeoutError{Tool: name, Timeout: timeout, Elapsed: time.Since(start)}
+		}
+		return out.result, out.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return protocol.ToolCallResult{IsError: true}, &TimeoutError{Tool: name, Timeout: timeout, Elapsed: time.Since(start)}
+		}
+		return protocol.ToolCallResult{IsError: true}, ctx.Err()
+	}
 }
@@ -3,10 +3,14 @@ package tools
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/safemode"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,6 +37,25 @@ func TestRegistryRegister(t *testing.T) {
 	assert.Equal(t, "search_documents", tool.Definition().Name)
 }
 
+func TestRegistryUnregister_NotifiesListeners(t *testing.T) {
+	registry := NewRegistry()
+	var changes []string
+	registry.OnListChanged(func(tenantID string) { changes = append(changes, tenantID) })
+
+	registry.Register(NewSearchTool(new(MockStore)))
+	tool, ok := registry.Unregister("search_documents")
+	require.True(t, ok)
+	assert.Equal(t, "search_documents", tool.Definition().Name)
+	_, ok = registry.Get("search_documents")
+	assert.False(t, ok)
+
+	_, ok = registry.Unregister("search_documents")
+	assert.False(t, ok, "unregistering a missing tool is not a change")
+	registry.TenantToolsChanged("tenant-a")
+
+	assert.Equal(t, []string{"", "", "tenant-a"}, changes)
+}
+
 func TestRegistryRegisterMultipleTools(t *testing.T) {
 	registry := NewRegistry()
 	mockDB := new(MockStore)
@@ -139,8 +162,8 @@ func TestRegistryExecute(t *testing.T) {
 
 	t.Run("successful execute", func(t *testing.T) {
 		// Setup mock
-		mockDB.On("SearchDocuments", ctx, "tenant-123", "test", 10).
-			Return([]*database.Document{}, nil).Once()
+		mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "test", 10, storage.MetadataFilter(nil)).
+			Return([]*storage.Document{}, nil).Once()
 
 		// Execute tool
 		result, err := registry.Execute(ctx, "search_documents", map[string]interface{}{
@@ -153,6 +176,23 @@ func TestRegistryExecute(t *testing.T) {
 		mockDB.AssertExpectations(t)
 	})
 
+	t.Run("invalid arguments", func(t *testing.T) {
+		// The tool does not run, so the mock expects no call
+		result, err := registry.Execute(ctx, "search_documents", map[string]interface{}{
+			"limit": "ten",
+		})
+
+		var argumentErr *ArgumentError
+		require.ErrorAs(t, err, &argumentErr)
+		assert.True(t, result.IsError)
+		assert.Equal(t, []Violation{
+			{Field: "/limit", Message: "must be of type number"},
+			{Field: "/query", Message: "is required"},
+		}, argumentErr.Violations)
+		assert.EqualError(t, err, "invalid arguments for tool search_documents: limit must be of type number; query is required")
+		assert.Equal(t, "search_documents", argumentErr.Data()["tool"])
+	})
+
 	t.Run("tool not found", func(t *testing.T) {
 		// Execute non-existent tool
 		result, err := registry.Execute(ctx, "unknown_tool", map[string]interface{}{})
@@ -163,6 +203,60 @@ func TestRegistryExecute(t *testing.T) {
 	})
 }
 
+func TestBuiltinToolSchemas(t *testing.T) {
+	store := new(MockStore)
+	for _, tool := range []Tool{
+		NewSearchTool(store),
+		NewRetrieveTool(store),
+		NewListTool(store),
+		NewHybridSearchTool(store),
+		NewSimilarDocumentsTool(store),
+		NewFederatedSearchTool(store, time.Second),
+	} {
+		definition := tool.Definition()
+		_, err := ValidateArguments(definition.InputSchema, nil)
+		assert.NoError(t, err, "%s has a valid input schema", definition.Name)
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"ids": map[string]interface{}{
+				"type":     "array",
+				"items":    map[string]interface{}{"type": "string", "pattern": "^doc-"},
+				"maxItems": 2,
+			},
+			"mode": map[string]interface{}{"enum": []string{"exact", "fuzzy"}},
+		},
+		"additionalProperties": false,
+	}
+
+	violations, err := ValidateArguments(schema, map[string]interface{}{"ids": []string{"doc-1"}, "mode": "exact"})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = ValidateArguments(schema, map[string]interface{}{
+		"ids":   []interface{}{"doc-1", "x", "doc-3"},
+		"other": true,
+	})
+	require.NoError(t, err)
+	fields := make([]string, len(violations))
+	for i, violation := range violations {
+		fields[i] = violation.Field
+	}
+	assert.Equal(t, []string{"/ids", "/ids/1", "/other"}, fields)
+
+	violations, err = ValidateArguments(nil, map[string]interface{}{"anything": true})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	// Schemas cannot load references from elsewhere
+	_, err = ValidateArguments(map[string]interface{}{"$ref": "file:///etc/passwd"}, nil)
+	assert.Error(t, err)
+}
+
 // Benchmark tests
 func BenchmarkRegistryGet(b *testing.B) {
 	registry := NewRegistry()
@@ -195,3 +289,204 @@ func BenchmarkRegistryList(b *testing.B) {
 		_ = registry.List()
 	}
 }
+
+// slowTool blocks until its context is cancelled or the delay passes
+type slowTool struct {
+	name  string
+	delay time.Duration
+	// ignoreCancel makes the tool keep running after cancellation
+	ignoreCancel bool
+}
+
+func (t *slowTool) Definition() protocol.Tool {
+	return protocol.Tool{Name: t.name}
+}
+
+func (t *slowTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	if t.ignoreCancel {
+		time.Sleep(t.delay)
+		return protocol.ToolCallResult{}, nil
+	}
+
+	select {
+	case <-time.After(t.delay):
+		return protocol.ToolCallResult{}, nil
+	case <-ctx.Done():
+		return protocol.ToolCallResult{IsError: true}, ctx.Err()
+	}
+}
+
+func TestRegistryTimeouts(t *testing.T) {
+	tests := []struct {
+		name         string
+		tool         *slowTool
+		defaultLimit time.Duration
+		override     time.Duration
+		expectErr    bool
+	}{
+		{"no timeout", &slowTool{name: "slow", delay: 10 * time.Millisecond}, 0, 0, false},
+		{"within default", &slowTool{name: "slow", delay: 10 * time.Millisecond}, time.Second, 0, false},
+		{"exceeds default", &slowTool{name: "slow", delay: time.Second}, 20 * time.Millisecond, 0, true},
+		{"override extends default", &slowTool{name: "slow", delay: 50 * time.Millisecond}, 10 * time.Millisecond, time.Second, false},
+		{"override shortens default", &slowTool{name: "slow", delay: time.Second}, time.Minute, 20 * time.Millisecond, true},
+		{"tool ignores cancellation", &slowTool{name: "slow", delay: time.Second, ignoreCancel: true}, 20 * time.Millisecond, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			registry.Register(tt.tool)
+			registry.SetDefaultTimeout(tt.defaultLimit)
+			if tt.override > 0 {
+				registry.SetTimeout("slow", tt.override)
+			}
+
+			start := time.Now()
+			_, err := registry.Execute(context.Background(), "slow", nil)
+
+			if !tt.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var timeoutErr *TimeoutError
+			require.ErrorAs(t, err, &timeoutErr)
+			assert.Equal(t, "slow", timeoutErr.Tool)
+			assert.Equal(t, registry.Timeout("slow"), timeoutErr.Timeout)
+			assert.GreaterOrEqual(t, timeoutErr.Elapsed, timeoutErr.Timeout)
+			assert.Less(t, time.Since(start), 500*time.Millisecond, "handler must not wait for the tool")
+		})
+	}
+}
+
+func TestRegistrySetTimeouts(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetDefaultTimeout(time.Minute)
+	registry.SetTimeout("slow", time.Second)
+
+	// Replacing the timeouts drops overrides that are no longer configured
+	registry.SetTimeouts(5*time.Second, map[string]time.Duration{"fast": time.Millisecond})
+	assert.Equal(t, 5*time.Second, registry.Timeout("slow"))
+	assert.Equal(t, time.Millisecond, registry.Timeout("fast"))
+}
+
+func TestRegistryExecute_ParentCancellation(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&slowTool{name: "slow", delay: time.Second})
+	registry.SetDefaultTimeout(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := registry.Execute(ctx, "slow", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// nameRecordingTool records the tool name its context carries
+type nameRecordingTool struct {
+	seen string
+}
+
+func (t *nameRecordingTool) Definition() protocol.Tool {
+	return protocol.Tool{Name: "named"}
+}
+
+func (t *nameRecordingTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	t.seen = ToolName(ctx)
+	return protocol.ToolCallResult{}, nil
+}
+
+func TestRegistryExecute_ToolName(t *testing.T) {
+	tool := &nameRecordingTool{}
+	registry := NewRegistry()
+	registry.Register(tool)
+
+	_, err := registry.Execute(context.Background(), "named", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "named", tool.seen)
+	assert.Empty(t, ToolName(context.Background()))
+}
+
+func TestRegistryRequiredScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		scopes    []string
+		required  string
+		expectErr bool
+	}{
+		{"no scope required", nil, "", false},
+		{"scope held", []string{"read"}, "read", false},
+		{"scope missing", []string{"read"}, "write", true},
+		{"unauthenticated", nil, "read", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			registry.Register(&slowTool{name: "slow"})
+			if tt.required != "" {
+				registry.SetRequiredScope("slow", tt.required)
+			}
+
+			ctx := context.Background()
+			if tt.scopes != nil {
+				ctx = auth.WithAuth(ctx, &auth.Claims{TenantID: "tenant-123", UserID: "user-456", Scopes: tt.scopes})
+			}
+
+			_, err := registry.Execute(ctx, "slow", nil)
+			if !tt.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var permissionErr *PermissionError
+			require.ErrorAs(t, err, &permissionErr)
+			assert.Equal(t, "slow", permissionErr.Tool)
+			assert.Equal(t, tt.required, permissionErr.Scope)
+		})
+	}
+}
+
+func TestRegistrySafeMode(t *testing.T) {
+	ctx := context.Background()
+	safeMode, err := safemode.New(ctx, safemode.NewMemoryStore())
+	require.NoError(t, err)
+
+	registry := NewRegistry()
+	registry.Register(&slowTool{name: "cheap"})
+	registry.Register(&slowTool{name: "expensive"})
+	registry.SetRestricted("expensive")
+	registry.SetSafeMode(safeMode)
+
+	_, err = registry.Execute(ctx, "expensive", nil)
+	assert.NoError(t, err, "restricted tools run while safe mode is off")
+	for _, tool := range registry.ListFor(ctx) {
+		assert.Nil(t, tool.Annotations)
+	}
+
+	_, err = safeMode.Set(ctx, true, "incident 42", "oncall")
+	require.NoError(t, err)
+
+	_, err = registry.Execute(ctx, "cheap", nil)
+	assert.NoError(t, err)
+
+	_, err = registry.Execute(ctx, "expensive", nil)
+	var safeModeErr *safemode.Error
+	require.ErrorAs(t, err, &safeModeErr)
+	assert.Equal(t, "tool expensive", safeModeErr.Operation)
+	assert.Equal(t, "incident 42", safeModeErr.Reason)
+
+	annotations := make(map[string]*protocol.ToolAnnotations)
+	for _, tool := range registry.ListFor(ctx) {
+		annotations[tool.Name] = tool.Annotations
+	}
+	assert.Nil(t, annotations["cheap"])
+	require.NotNil(t, annotations["expensive"])
+	assert.True(t, annotations["expensive"].Disabled)
+	assert.Contains(t, annotations["expensive"].DisabledReason, "incident 42")
+
+	_, err = safeMode.Set(ctx, false, "", "oncall")
+	require.NoError(t, err)
+	_, err = registry.Execute(ctx, "expensive", nil)
+	assert.NoError(t, err)
+}
@@ -6,17 +6,18 @@ import (
 	"fmt"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 )
 
 // RetrieveTool implements document retrieval by ID
 type RetrieveTool struct {
-	db database.Store
+	documentAccess
+	db storage.Store
 }
 
 // NewRetrieveTool creates a new retrieve tool
-func NewRetrieveTool(db database.Store) *RetrieveTool {
+func NewRetrieveTool(db storage.Store) *RetrieveTool {
 	return &RetrieveTool{db: db}
 }
 
@@ -32,6 +33,11 @@ func (t *RetrieveTool) Definition() protocol.Tool {
 					"type":        "string",
 					"description": "The unique identifier of the document to retrieve",
 				},
+				"collection": map[string]interface{}{
+					"type":        "string",
+					"description": "Only return the document if it belongs to this collection",
+					"pattern":     "^[a-z0-9][a-z0-9_-]{0,63}$",
+				},
 			},
 			"required": []string{"document_id"},
 		},
@@ -41,6 +47,7 @@ func (t *RetrieveTool) Definition() protocol.Tool {
 // RetrieveParams represents the parameters for retrieve
 type RetrieveParams struct {
 	DocumentID string `json:"document_id"`
+	Collection string `json:"collection,omitempty"`
 }
 
 // Execute retrieves a document by ID
@@ -65,18 +72,28 @@ func (t *RetrieveTool) Execute(ctx context.Context, args map[string]interface{})
 	if params.DocumentID == "" {
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("document_id is required")
 	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
 
 	// Retrieve document
 	doc, err := t.db.GetDocument(ctx, tenantID, params.DocumentID)
+	if err == nil && params.Collection != "" && storage.CollectionOrDefault(doc.Collection) != params.Collection {
+		// Documents of other collections are not revealed to collection-scoped callers
+		err = storage.ErrNotFound
+	}
 	if err != nil {
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to retrieve document: %w", err)
 	}
 
+	t.recordAccess(ctx, "retrieve_document", doc.ID)
+
 	// Format result
 	metadataJSON, _ := json.Marshal(doc.Metadata)
 	resultText := fmt.Sprintf("Document Retrieved:\n\n")
 	resultText += fmt.Sprintf("ID: %s\n", doc.ID)
 	resultText += fmt.Sprintf("Title: %s\n", doc.Title)
+	resultText += fmt.Sprintf("Collection: %s\n", storage.CollectionOrDefault(doc.Collection))
 	resultText += fmt.Sprintf("Content:\n%s\n\n", doc.Content)
 	resultText += fmt.Sprintf("Metadata: %s\n", string(metadataJSON))
 	resultText += fmt.Sprintf("Created: %s\n", doc.CreatedAt.Format("2006-01-02 15:04:05"))
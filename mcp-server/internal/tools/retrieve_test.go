@@ -6,10 +6,11 @@ import (
 	"time"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRetrieveToolDefinition(t *testing.T) {
@@ -50,7 +51,7 @@ func TestRetrieveToolExecute(t *testing.T) {
 			},
 			setupMock: func(m *MockStore) {
 				createdBy := "user-123"
-				doc := &database.Document{
+				doc := &storage.Document{
 					ID:        "doc-1",
 					TenantID:  "tenant-123",
 					Title:     "Test Document",
@@ -137,7 +138,7 @@ func TestRetrieveToolExecute(t *testing.T) {
 				"document_id": "doc-2",
 			},
 			setupMock: func(m *MockStore) {
-				doc := &database.Document{
+				doc := &storage.Document{
 					ID:        "doc-2",
 					TenantID:  "tenant-123",
 					Title:     "System Document",
@@ -200,13 +201,32 @@ func TestRetrieveToolInvalidArguments(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRetrieveToolCollection(t *testing.T) {
+	mockDB := new(MockStore)
+	mockDB.On("GetDocument", mock.Anything, "tenant-123", "doc-1").
+		Return(&storage.Document{ID: "doc-1", Collection: "tickets", Title: "Ticket"}, nil)
+	tool := NewRetrieveTool(mockDB)
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+
+	result, err := tool.Execute(ctx, map[string]interface{}{"document_id": "doc-1", "collection": "tickets"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].Text, "Collection: tickets")
+
+	// A document of another collection is reported as missing
+	_, err = tool.Execute(ctx, map[string]interface{}{"document_id": "doc-1", "collection": "policies"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = tool.Execute(ctx, map[string]interface{}{"document_id": "doc-1", "collection": "Bad Name"})
+	assert.ErrorIs(t, err, storage.ErrInvalidCollection)
+}
+
 // Benchmark tests
 func BenchmarkRetrieveToolExecute(b *testing.B) {
 	mockDB := new(MockStore)
 	now := time.Now()
 
 	createdBy := "bench-user"
-	doc := &database.Document{
+	doc := &storage.Document{
 		ID:        "doc-1",
 		TenantID:  "tenant-123",
 		Title:     "Benchmark Document",
@@ -232,3 +252,32 @@ func BenchmarkRetrieveToolExecute(b *testing.B) {
 		_, _ = tool.Execute(ctx, args)
 	}
 }
+
+// recordingAccessRecorder captures RecordAccess calls for testing
+type recordingAccessRecorder struct {
+	tool        string
+	documentIDs []string
+}
+
+func (r *recordingAccessRecorder) RecordAccess(ctx context.Context, tool string, documentIDs ...string) {
+	r.tool = tool
+	r.documentIDs = append(r.documentIDs, documentIDs...)
+}
+
+func TestRetrieveToolRecordsAccess(t *testing.T) {
+	mockDB := new(MockStore)
+	mockDB.On("GetDocument", mock.Anything, "tenant-123", "doc-1").
+		Return(&storage.Document{ID: "doc-1", Title: "Test"}, nil)
+
+	recorder := &recordingAccessRecorder{}
+	registry := NewRegistry()
+	registry.Register(NewRetrieveTool(mockDB))
+	registry.SetAccessRecorder(recorder)
+
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	_, err := registry.Execute(ctx, "retrieve_document", map[string]interface{}{"document_id": "doc-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "retrieve_document", recorder.tool)
+	assert.Equal(t, []string{"doc-1"}, recorder.documentIDs)
+}
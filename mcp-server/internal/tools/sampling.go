@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// Sampler asks the calling client's LLM for a completion (MCP sampling), so tools can use
+// a model without the server holding LLM credentials
+type Sampler interface {
+	CreateMessage(ctx context.Context, req protocol.CreateMessageRequest) (*protocol.CreateMessageResult, error)
+}
+
+// samplerKey is the context key of the calling client's sampler
+type samplerKey struct{}
+
+// WithSampler returns a context whose tool calls can sample completions from sampler
+func WithSampler(ctx context.Context, sampler Sampler) context.Context {
+	return context.WithValue(ctx, samplerKey{}, sampler)
+}
+
+// SamplerFrom returns the calling client's sampler, or nil when the client cannot sample
+func SamplerFrom(ctx context.Context) Sampler {
+	sampler, _ := ctx.Value(samplerKey{}).(Sampler)
+	return sampler
+}
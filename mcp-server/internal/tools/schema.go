@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Violation is one way tool arguments fail to match the tool's input schema
+type Violation struct {
+	// Field is the JSON Pointer of the offending value, e.g. /filters/0/field; it is
+	// empty when the arguments as a whole are at fault
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return "arguments " + v.Message
+	}
+	return strings.TrimPrefix(v.Field, "/") + " " + v.Message
+}
+
+// ArgumentError is returned when tool arguments do not match the tool's input schema
+type ArgumentError struct {
+	Tool       string
+	Violations []Violation
+}
+
+// Error implements the error interface
+func (e *ArgumentError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		violations[i] = violation.String()
+	}
+	return fmt.Sprintf("invalid arguments for tool %s: %s", e.Tool, strings.Join(violations, "; "))
+}
+
+// Data returns structured details for the JSON-RPC error response
+func (e *ArgumentError) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"tool":       e.Tool,
+		"violations": e.Violations,
+	}
+}
+
+// schemaURL is the location input schemas are compiled under; references to other
+// locations are not loaded
+const schemaURL = "urn:mcp:input-schema"
+
+// compiledSchemas caches compiled input schemas by their JSON encoding
+var compiledSchemas sync.Map
+
+var messages = message.NewPrinter(language.English)
+
+// ValidateArguments checks arguments against a tool's input schema, a JSON Schema of
+// draft 2020-12 unless its $schema names another draft. It returns the violations sorted
+// by field, and an error if the schema itself is invalid.
+func ValidateArguments(schema map[string]interface{}, args map[string]interface{}) ([]Violation, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("arguments are not JSON: %w", err)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("arguments are not JSON: %w", err)
+	}
+
+	var invalid *jsonschema.ValidationError
+	if err := compiled.Validate(value); err == nil {
+		return nil, nil
+	} else if !errors.As(err, &invalid) {
+		return nil, err
+	}
+	violations := collectViolations(invalid, nil)
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	return violations, nil
+}
+
+// compileSchema compiles an input schema, caching the result
+func compileSchema(schema map[string]interface{}) (*jsonschema.Schema, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	if compiled, ok := compiledSchemas.Load(string(data)); ok {
+		return compiled.(*jsonschema.Schema), nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiledSchemas.Store(string(data), compiled)
+	return compiled, nil
+}
+
+// collectViolations flattens a validation error into the failures at its leaves,
+// reporting missing and unexpected properties against the properties themselves
+func collectViolations(err *jsonschema.ValidationError, violations []Violation) []Violation {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			violations = collectViolations(cause, violations)
+		}
+		return violations
+	}
+
+	field := jsonPointer(err.InstanceLocation)
+	switch k := err.ErrorKind.(type) {
+	case *kind.Required:
+		for _, name := range k.Missing {
+			violations = append(violations, Violation{Field: field + "/" + escapePointer(name), Message: "is required"})
+		}
+	case *kind.AdditionalProperties:
+		for _, name := range k.Properties {
+			violations = append(violations, Violation{Field: field + "/" + escapePointer(name), Message: "is not allowed"})
+		}
+	case *kind.Type:
+		violations = append(violations, Violation{Field: field, Message: "must be of type " + strings.Join(k.Want, " or ")})
+	default:
+		violations = append(violations, Violation{Field: field, Message: err.ErrorKind.LocalizedString(messages)})
+	}
+	return violations
+}
+
+// jsonPointer formats an instance location as a JSON Pointer
+func jsonPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/" + escapePointer(token))
+	}
+	return b.String()
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -6,25 +6,44 @@ import (
 	"fmt"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/embeddings"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// Search modes of search_documents
+const (
+	SearchModeLexical  = "lexical"
+	SearchModeSemantic = "semantic"
+	SearchModeHybrid   = "hybrid"
 )
 
 // SearchTool implements document text search
 type SearchTool struct {
-	db database.Store
+	documentAccess
+	searchProfiles
+	querySynonyms
+	db       storage.Store
+	embedder embeddings.Provider
 }
 
 // NewSearchTool creates a new search tool
-func NewSearchTool(db database.Store) *SearchTool {
+func NewSearchTool(db storage.Store) *SearchTool {
 	return &SearchTool{db: db}
 }
 
+// SetEmbedder computes the query embedding of semantic and hybrid searches that do not
+// pass one. Without an embedder those modes need the caller's embedding.
+func (t *SearchTool) SetEmbedder(embedder embeddings.Provider) {
+	t.embedder = embedder
+}
+
 // Definition returns the tool definition for MCP
 func (t *SearchTool) Definition() protocol.Tool {
+	correctSpelling, expandSynonyms := queryPreprocessingSchema(" in hybrid mode")
 	return protocol.Tool{
 		Name:        "search_documents",
-		Description: "Search documents by text query. Searches across title, content, and metadata fields.",
+		Description: "Search documents by text query. Searches across title, content, and metadata fields, or by meaning in semantic and hybrid mode.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -37,6 +56,24 @@ func (t *SearchTool) Definition() protocol.Tool {
 					"description": "Maximum number of results to return (default: 10, max: 100)",
 					"default":     10,
 				},
+				"mode": map[string]interface{}{
+					"type": "string",
+					"description": "lexical matches the query text; semantic ranks documents by embedding similarity " +
+						"and hybrid fuses both rankings. Semantic and hybrid use the given embedding or compute one " +
+						"from the query (default: lexical)",
+					"enum":    []string{SearchModeLexical, SearchModeSemantic, SearchModeHybrid},
+					"default": SearchModeLexical,
+				},
+				"embedding": map[string]interface{}{
+					"type":        "array",
+					"description": "Query embedding vector for semantic and hybrid mode",
+					"items":       map[string]interface{}{"type": "number"},
+				},
+				"correct_spelling": correctSpelling,
+				"expand_synonyms":  expandSynonyms,
+				"collection":       collectionSchema(),
+				"filters":          filtersSchema(),
+				"profile":          profileSchema(),
 			},
 			"required": []string{"query"},
 		},
@@ -45,8 +82,15 @@ func (t *SearchTool) Definition() protocol.Tool {
 
 // SearchParams represents the parameters for search
 type SearchParams struct {
-	Query string `json:"query"`
-	Limit int    `json:"limit"`
+	Query      string                 `json:"query"`
+	Limit      int                    `json:"limit"`
+	Mode       string                 `json:"mode,omitempty"`
+	Embedding  []float32              `json:"embedding,omitempty"`
+	Collection string                 `json:"collection,omitempty"`
+	Filters    map[string]interface{} `json:"filters,omitempty"`
+
+	CorrectSpelling bool `json:"correct_spelling,omitempty"`
+	ExpandSynonyms  bool `json:"expand_synonyms,omitempty"`
 }
 
 // Execute performs the search operation
@@ -57,6 +101,11 @@ func (t *SearchTool) Execute(ctx context.Context, args map[string]interface{}) (
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
 	}
 
+	args, err = t.applyProfile(ctx, tenantID, args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+
 	// Parse parameters
 	argsJSON, err := json.Marshal(args)
 	if err != nil {
@@ -78,13 +127,35 @@ func (t *SearchTool) Execute(ctx context.Context, args map[string]interface{}) (
 	if params.Limit > 100 {
 		params.Limit = 100
 	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+	filter, err := storage.ParseMetadataFilter(params.Filters)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid filters: %w", err)
+	}
+	if params.Mode == "" {
+		params.Mode = SearchModeLexical
+	}
 
 	// Perform search
-	documents, err := t.db.SearchDocuments(ctx, tenantID, params.Query, params.Limit)
+	var documents []*storage.Document
+	var scores []float64
+	if params.Mode == SearchModeLexical {
+		documents, err = t.db.SearchDocuments(ctx, tenantID, params.Collection, params.Query, params.Limit, filter)
+	} else {
+		documents, scores, err = t.searchByEmbedding(ctx, tenantID, params, filter)
+	}
 	if err != nil {
 		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("search failed: %w", err)
 	}
 
+	docIDs := make([]string, 0, len(documents))
+	for _, doc := range documents {
+		docIDs = append(docIDs, doc.ID)
+	}
+	t.recordAccess(ctx, "search_documents", docIDs...)
+
 	// Format results
 	var resultText string
 	if len(documents) == 0 {
@@ -95,6 +166,9 @@ func (t *SearchTool) Execute(ctx context.Context, args map[string]interface{}) (
 			resultText += fmt.Sprintf("Document %d:\n", i+1)
 			resultText += fmt.Sprintf("  ID: %s\n", doc.ID)
 			resultText += fmt.Sprintf("  Title: %s\n", doc.Title)
+			if scores != nil {
+				resultText += fmt.Sprintf("  Score: %.4f\n", scores[i])
+			}
 			resultText += fmt.Sprintf("  Content Preview: %.200s...\n", doc.Content)
 			if doc.Metadata != nil {
 				metadataJSON, _ := json.Marshal(doc.Metadata)
@@ -115,3 +189,52 @@ func (t *SearchTool) Execute(ctx context.Context, args map[string]interface{}) (
 		IsError: false,
 	}, nil
 }
+
+// searchByEmbedding runs a semantic or hybrid search, computing the query embedding if
+// the caller did not pass one. Semantic search is a weighted hybrid search that gives the
+// lexical score no weight; hybrid search fuses the two rankings with reciprocal rank fusion.
+func (t *SearchTool) searchByEmbedding(ctx context.Context, tenantID string, params SearchParams, filter storage.MetadataFilter) ([]*storage.Document, []float64, error) {
+	embedding := params.Embedding
+	if len(embedding) == 0 {
+		if t.embedder == nil {
+			return nil, nil, fmt.Errorf("%s mode needs an embedding: pass one or configure an embeddings provider", params.Mode)
+		}
+		var err error
+		if embedding, err = t.embedder.Embed(ctx, params.Query); err != nil {
+			return nil, nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+
+	dbParams := storage.HybridSearchParams{
+		Collection: params.Collection,
+		Query:      params.Query,
+		Embedding:  embedding,
+		Limit:      params.Limit,
+		Filters:    filter,
+	}
+	var results []storage.HybridSearchResult
+	var err error
+	if params.Mode == SearchModeSemantic {
+		dbParams.VectorWeight = 1
+		results, err = t.db.SimpleHybridSearch(ctx, tenantID, dbParams)
+	} else {
+		dbParams.BM25Weight, dbParams.VectorWeight = 0.5, 0.5
+		dbParams.Fusion = storage.FusionRRF
+		dbParams.CorrectSpelling = params.CorrectSpelling
+		if dbParams.Synonyms, err = t.synonyms(ctx, tenantID, params.ExpandSynonyms); err != nil {
+			return nil, nil, err
+		}
+		results, err = t.db.HybridSearch(ctx, tenantID, dbParams)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	documents := make([]*storage.Document, len(results))
+	scores := make([]float64, len(results))
+	for i := range results {
+		documents[i] = &results[i].Document
+		scores[i] = results[i].CombinedScore
+	}
+	return documents, scores, nil
+}
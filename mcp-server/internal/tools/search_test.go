@@ -2,58 +2,68 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
-	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/database"
 	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// MockStore is a mock implementation of the database.Store interface
+// MockStore is a mock implementation of the storage.Store interface
 type MockStore struct {
 	mock.Mock
 }
 
-func (m *MockStore) SearchDocuments(ctx context.Context, tenantID, query string, limit int) ([]*database.Document, error) {
-	args := m.Called(ctx, tenantID, query, limit)
+func (m *MockStore) SearchDocuments(ctx context.Context, tenantID, collection, query string, limit int, filter storage.MetadataFilter) ([]*storage.Document, error) {
+	args := m.Called(ctx, tenantID, query, limit, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*database.Document), args.Error(1)
+	return args.Get(0).([]*storage.Document), args.Error(1)
 }
 
-func (m *MockStore) GetDocument(ctx context.Context, tenantID, docID string) (*database.Document, error) {
+func (m *MockStore) GetDocument(ctx context.Context, tenantID, docID string) (*storage.Document, error) {
 	args := m.Called(ctx, tenantID, docID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*database.Document), args.Error(1)
+	return args.Get(0).(*storage.Document), args.Error(1)
 }
 
-func (m *MockStore) ListDocuments(ctx context.Context, tenantID string, limit, offset int) ([]*database.Document, error) {
+func (m *MockStore) ListDocuments(ctx context.Context, tenantID, collection string, limit, offset int) ([]*storage.Document, error) {
 	args := m.Called(ctx, tenantID, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*database.Document), args.Error(1)
+	return args.Get(0).([]*storage.Document), args.Error(1)
 }
 
-func (m *MockStore) HybridSearch(ctx context.Context, tenantID string, params database.HybridSearchParams) ([]database.HybridSearchResult, error) {
+func (m *MockStore) HybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
 	args := m.Called(ctx, tenantID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]database.HybridSearchResult), args.Error(1)
+	return args.Get(0).([]storage.HybridSearchResult), args.Error(1)
 }
 
-func (m *MockStore) SimpleHybridSearch(ctx context.Context, tenantID string, params database.HybridSearchParams) ([]database.HybridSearchResult, error) {
+func (m *MockStore) SimpleHybridSearch(ctx context.Context, tenantID string, params storage.HybridSearchParams) ([]storage.HybridSearchResult, error) {
 	args := m.Called(ctx, tenantID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]database.HybridSearchResult), args.Error(1)
+	return args.Get(0).([]storage.HybridSearchResult), args.Error(1)
+}
+
+func (m *MockStore) SimilarDocuments(ctx context.Context, tenantID, docID string, limit int, filter storage.MetadataFilter) ([]storage.ScoredDocument, error) {
+	args := m.Called(ctx, tenantID, docID, limit, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]storage.ScoredDocument), args.Error(1)
 }
 
 func TestSearchToolDefinition(t *testing.T) {
@@ -92,7 +102,7 @@ func TestSearchToolExecute(t *testing.T) {
 				"limit": 10,
 			},
 			setupMock: func(m *MockStore) {
-				docs := []*database.Document{
+				docs := []*storage.Document{
 					{
 						ID:       "doc-1",
 						Title:    "Test Document 1",
@@ -105,7 +115,7 @@ func TestSearchToolExecute(t *testing.T) {
 						Content: "Content 2",
 					},
 				}
-				m.On("SearchDocuments", mock.Anything, "tenant-123", "test query", 10).
+				m.On("SearchDocuments", mock.Anything, "tenant-123", "test query", 10, storage.MetadataFilter(nil)).
 					Return(docs, nil)
 			},
 			wantErr: false,
@@ -126,8 +136,8 @@ func TestSearchToolExecute(t *testing.T) {
 				"query": "nonexistent",
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SearchDocuments", mock.Anything, "tenant-123", "nonexistent", 10).
-					Return([]*database.Document{}, nil)
+				m.On("SearchDocuments", mock.Anything, "tenant-123", "nonexistent", 10, storage.MetadataFilter(nil)).
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 			validate: func(t *testing.T, result protocol.ToolCallResult) {
@@ -170,8 +180,8 @@ func TestSearchToolExecute(t *testing.T) {
 				"query": "test",
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SearchDocuments", mock.Anything, "tenant-123", "test", 10).
-					Return([]*database.Document{}, nil)
+				m.On("SearchDocuments", mock.Anything, "tenant-123", "test", 10, storage.MetadataFilter(nil)).
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
@@ -185,11 +195,44 @@ func TestSearchToolExecute(t *testing.T) {
 				"limit": 500,
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SearchDocuments", mock.Anything, "tenant-123", "test", 100).
-					Return([]*database.Document{}, nil)
+				m.On("SearchDocuments", mock.Anything, "tenant-123", "test", 100, storage.MetadataFilter(nil)).
+					Return([]*storage.Document{}, nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "metadata filters passed to store",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":   "policy",
+				"filters": map[string]interface{}{"category": "security", "department": []interface{}{"engineering", "sre"}},
+			},
+			setupMock: func(m *MockStore) {
+				filter := storage.MetadataFilter{
+					"category":   {"security"},
+					"department": {"engineering", "sre"},
+				}
+				m.On("SearchDocuments", mock.Anything, "tenant-123", "policy", 10, filter).
+					Return([]*storage.Document{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid metadata filter",
+			setupAuth: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+			},
+			args: map[string]interface{}{
+				"query":   "policy",
+				"filters": map[string]interface{}{"category": map[string]interface{}{"nested": true}},
+			},
+			setupMock: func(m *MockStore) {
+				// Rejected before reaching the store
+			},
+			wantErr: true,
+		},
 		{
 			name: "database error",
 			setupAuth: func(ctx context.Context) context.Context {
@@ -199,7 +242,7 @@ func TestSearchToolExecute(t *testing.T) {
 				"query": "test",
 			},
 			setupMock: func(m *MockStore) {
-				m.On("SearchDocuments", mock.Anything, "tenant-123", "test", 10).
+				m.On("SearchDocuments", mock.Anything, "tenant-123", "test", 10, storage.MetadataFilter(nil)).
 					Return(nil, assert.AnError)
 			},
 			wantErr: true,
@@ -245,11 +288,77 @@ func TestSearchToolInvalidArguments(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// fakeEmbedder returns a fixed embedding and records the texts it embedded
+type fakeEmbedder struct {
+	texts []string
+	err   error
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.texts = append(e.texts, text)
+	return []float32{0.1, 0.2}, e.err
+}
+
+func TestSearchToolModes(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	results := []storage.HybridSearchResult{
+		{Document: storage.Document{ID: "doc-1", Title: "Neural networks"}, CombinedScore: 0.92},
+	}
+
+	t.Run("semantic with a computed embedding", func(t *testing.T) {
+		mockDB := new(MockStore)
+		mockDB.On("SimpleHybridSearch", ctx, "tenant-123", storage.HybridSearchParams{
+			Query: "deep learning", Embedding: []float32{0.1, 0.2}, Limit: 10, VectorWeight: 1,
+		}).Return(results, nil).Once()
+		embedder := &fakeEmbedder{}
+		tool := NewSearchTool(mockDB)
+		tool.SetEmbedder(embedder)
+
+		result, err := tool.Execute(ctx, map[string]interface{}{"query": "deep learning", "mode": "semantic"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"deep learning"}, embedder.texts)
+		assert.Contains(t, result.Content[0].Text, "doc-1")
+		assert.Contains(t, result.Content[0].Text, "Score: 0.9200")
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("hybrid with the caller's embedding", func(t *testing.T) {
+		mockDB := new(MockStore)
+		mockDB.On("HybridSearch", ctx, "tenant-123", storage.HybridSearchParams{
+			Query: "deep learning", Embedding: []float32{1, 0}, Limit: 5,
+			BM25Weight: 0.5, VectorWeight: 0.5, Fusion: storage.FusionRRF,
+		}).Return(results, nil).Once()
+		embedder := &fakeEmbedder{}
+		tool := NewSearchTool(mockDB)
+		tool.SetEmbedder(embedder)
+
+		_, err := tool.Execute(ctx, map[string]interface{}{
+			"query": "deep learning", "mode": "hybrid", "limit": 5, "embedding": []interface{}{1.0, 0.0},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, embedder.texts, "the caller's embedding is used")
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("semantic without an embedder", func(t *testing.T) {
+		tool := NewSearchTool(new(MockStore))
+		_, err := tool.Execute(ctx, map[string]interface{}{"query": "deep learning", "mode": "semantic"})
+		assert.ErrorContains(t, err, "semantic mode needs an embedding")
+	})
+
+	t.Run("embedding failure", func(t *testing.T) {
+		tool := NewSearchTool(new(MockStore))
+		tool.SetEmbedder(&fakeEmbedder{err: errors.New("provider down")})
+		_, err := tool.Execute(ctx, map[string]interface{}{"query": "deep learning", "mode": "hybrid"})
+		assert.ErrorContains(t, err, "failed to embed query: provider down")
+	})
+}
+
 // Benchmark tests
 func BenchmarkSearchToolExecute(b *testing.B) {
 	mockDB := new(MockStore)
-	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "benchmark query", 10).
-		Return([]*database.Document{
+	mockDB.On("SearchDocuments", mock.Anything, "tenant-123", "benchmark query", 10, storage.MetadataFilter(nil)).
+		Return([]*storage.Document{
 			{ID: "doc-1", Title: "Doc 1", Content: "Content 1"},
 		}, nil)
 
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// SimilarDocumentsTool finds the documents nearest to a document by embedding similarity
+// (more like this), for recommendations and finding duplicates
+type SimilarDocumentsTool struct {
+	documentAccess
+	db storage.Store
+}
+
+// NewSimilarDocumentsTool creates a new similar documents tool
+func NewSimilarDocumentsTool(db storage.Store) *SimilarDocumentsTool {
+	return &SimilarDocumentsTool{db: db}
+}
+
+// Definition returns the tool definition for MCP
+func (t *SimilarDocumentsTool) Definition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "similar_documents",
+		Description: "Find the documents most similar to a given document by embedding similarity (more like this), excluding the document itself. Useful for recommendations and finding near-duplicates.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"document_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The document to find similar documents for; it must have an embedding",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of results to return (default: 10, max: 50)",
+					"default":     10,
+				},
+				"min_score": map[string]interface{}{
+					"type":        "number",
+					"description": "Only return documents with at least this cosine similarity, from -1 to 1; e.g. 0.95 for near-duplicates (default: no threshold)",
+				},
+				"collection": map[string]interface{}{
+					"type":        "string",
+					"description": "Only search if the document belongs to this collection; similar documents always come from the document's own collection",
+					"pattern":     "^[a-z0-9][a-z0-9_-]{0,63}$",
+				},
+				"filters": filtersSchema(),
+			},
+			"required": []string{"document_id"},
+		},
+	}
+}
+
+// SimilarDocumentsParams represents the parameters for similar documents
+type SimilarDocumentsParams struct {
+	DocumentID string                 `json:"document_id"`
+	Limit      int                    `json:"limit"`
+	MinScore   *float64               `json:"min_score,omitempty"` // nil when unset
+	Collection string                 `json:"collection,omitempty"`
+	Filters    map[string]interface{} `json:"filters,omitempty"`
+}
+
+// Execute finds the documents similar to a document
+func (t *SimilarDocumentsTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	// Extract tenant ID from context
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+
+	// Parse parameters
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	var params SimilarDocumentsParams
+	if err := json.Unmarshal(argsJSON, &params); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	// Validate parameters
+	if params.DocumentID == "" {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("document_id is required")
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > 50 {
+		params.Limit = 50
+	}
+	if params.MinScore != nil && (*params.MinScore < -1 || *params.MinScore > 1) {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("min_score must be between -1 and 1")
+	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+	filter, err := storage.ParseMetadataFilter(params.Filters)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	if params.Collection != "" {
+		// Documents of other collections are not revealed to collection-scoped callers
+		doc, err := t.db.GetDocument(ctx, tenantID, params.DocumentID)
+		if err == nil && storage.CollectionOrDefault(doc.Collection) != params.Collection {
+			err = storage.ErrNotFound
+		}
+		if err != nil {
+			return protocol.ToolCallResult{IsError: true}, fmt.Errorf("similar documents failed: %w", err)
+		}
+	}
+
+	results, err := t.db.SimilarDocuments(ctx, tenantID, params.DocumentID, params.Limit, filter)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("similar documents failed: %w", err)
+	}
+	if params.MinScore != nil {
+		kept := results[:0]
+		for _, result := range results {
+			if result.Score >= *params.MinScore {
+				kept = append(kept, result)
+			}
+		}
+		results = kept
+	}
+
+	// Format results as JSON, like hybrid_search
+	type DocumentResult struct {
+		DocID      string                 `json:"doc_id"`
+		TenantID   string                 `json:"tenant_id"`
+		Collection string                 `json:"collection"`
+		Title      string                 `json:"title"`
+		Content    string                 `json:"content"`
+		Score      float64                `json:"score"`
+		Metadata   map[string]interface{} `json:"metadata,omitempty"`
+		CreatedAt  string                 `json:"created_at"`
+	}
+
+	docIDs := make([]string, 0, len(results))
+	jsonResults := make([]DocumentResult, 0, len(results))
+	for _, result := range results {
+		doc := result.Document
+		docIDs = append(docIDs, doc.ID)
+		jsonResults = append(jsonResults, DocumentResult{
+			DocID:      doc.ID,
+			TenantID:   doc.TenantID,
+			Collection: storage.CollectionOrDefault(doc.Collection),
+			Title:      doc.Title,
+			Content:    doc.Content,
+			Score:      result.Score,
+			Metadata:   doc.Metadata,
+			CreatedAt:  doc.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	t.recordAccess(ctx, "similar_documents", docIDs...)
+
+	jsonData, err := json.Marshal(jsonResults)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	return protocol.ToolCallResult{
+		Content: []protocol.ContentBlock{
+			{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+		IsError: false,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarDocumentsToolDefinition(t *testing.T) {
+	tool := NewSimilarDocumentsTool(new(MockStore))
+
+	def := tool.Definition()
+
+	assert.Equal(t, "similar_documents", def.Name)
+	assert.NotEmpty(t, def.Description)
+	required, ok := def.InputSchema["required"].([]string)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"document_id"}, required)
+	_, err := ValidateArguments(def.InputSchema, map[string]interface{}{"document_id": "doc-1", "filters": map[string]interface{}{"category": "security"}})
+	assert.NoError(t, err)
+}
+
+func TestSimilarDocumentsToolExecute(t *testing.T) {
+	now := time.Now()
+	similar := []storage.ScoredDocument{
+		{Document: &storage.Document{ID: "doc-2", TenantID: "tenant-123", Collection: "policies", Title: "Password Policy v2", CreatedAt: now}, Score: 0.98},
+		{Document: &storage.Document{ID: "doc-3", TenantID: "tenant-123", Collection: "policies", Title: "Access Control", CreatedAt: now}, Score: 0.71},
+	}
+	withTenant := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, auth.ContextKeyTenantID, "tenant-123")
+	}
+
+	tests := []struct {
+		name      string
+		setupAuth func(ctx context.Context) context.Context
+		args      map[string]interface{}
+		setupMock func(m *MockStore)
+		wantErr   bool
+		validate  func(t *testing.T, result protocol.ToolCallResult)
+	}{
+		{
+			name:      "similar documents with filters",
+			setupAuth: withTenant,
+			args: map[string]interface{}{
+				"document_id": "doc-1",
+				"limit":       5,
+				"filters":     map[string]interface{}{"category": "security"},
+			},
+			setupMock: func(m *MockStore) {
+				m.On("SimilarDocuments", mock.Anything, "tenant-123", "doc-1", 5, storage.MetadataFilter{"category": {"security"}}).
+					Return(similar, nil)
+			},
+			validate: func(t *testing.T, result protocol.ToolCallResult) {
+				var docs []map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &docs))
+				require.Len(t, docs, 2)
+				assert.Equal(t, "doc-2", docs[0]["doc_id"])
+				assert.Equal(t, "policies", docs[0]["collection"])
+				assert.Equal(t, 0.98, docs[0]["score"])
+			},
+		},
+		{
+			name:      "min_score keeps near-duplicates",
+			setupAuth: withTenant,
+			args:      map[string]interface{}{"document_id": "doc-1", "min_score": 0.95},
+			setupMock: func(m *MockStore) {
+				m.On("SimilarDocuments", mock.Anything, "tenant-123", "doc-1", 10, storage.MetadataFilter(nil)).
+					Return(append([]storage.ScoredDocument(nil), similar...), nil)
+			},
+			validate: func(t *testing.T, result protocol.ToolCallResult) {
+				var docs []map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &docs))
+				require.Len(t, docs, 1)
+				assert.Equal(t, "doc-2", docs[0]["doc_id"])
+			},
+		},
+		{
+			name:      "no similar documents",
+			setupAuth: withTenant,
+			args:      map[string]interface{}{"document_id": "doc-1", "limit": 500},
+			setupMock: func(m *MockStore) {
+				m.On("SimilarDocuments", mock.Anything, "tenant-123", "doc-1", 50, storage.MetadataFilter(nil)).
+					Return(nil, nil)
+			},
+			validate: func(t *testing.T, result protocol.ToolCallResult) {
+				assert.Equal(t, "[]", result.Content[0].Text)
+			},
+		},
+		{
+			name:      "document in another collection",
+			setupAuth: withTenant,
+			args:      map[string]interface{}{"document_id": "doc-1", "collection": "tickets"},
+			setupMock: func(m *MockStore) {
+				m.On("GetDocument", mock.Anything, "tenant-123", "doc-1").
+					Return(&storage.Document{ID: "doc-1", TenantID: "tenant-123", Collection: "policies"}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:      "document without embedding",
+			setupAuth: withTenant,
+			args:      map[string]interface{}{"document_id": "doc-1"},
+			setupMock: func(m *MockStore) {
+				m.On("SimilarDocuments", mock.Anything, "tenant-123", "doc-1", 10, storage.MetadataFilter(nil)).
+					Return(nil, storage.ErrNoEmbedding)
+			},
+			wantErr: true,
+		},
+		{
+			name:      "min_score out of range",
+			setupAuth: withTenant,
+			args:      map[string]interface{}{"document_id": "doc-1", "min_score": 2},
+			setupMock: func(m *MockStore) {},
+			wantErr:   true,
+		},
+		{
+			name:      "missing document_id",
+			setupAuth: withTenant,
+			args:      map[string]interface{}{},
+			setupMock: func(m *MockStore) {},
+			wantErr:   true,
+		},
+		{
+			name:      "missing authentication",
+			setupAuth: func(ctx context.Context) context.Context { return ctx },
+			args:      map[string]interface{}{"document_id": "doc-1"},
+			setupMock: func(m *MockStore) {},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockStore)
+			tt.setupMock(mockDB)
+
+			tool := NewSimilarDocumentsTool(mockDB)
+			result, err := tool.Execute(tt.setupAuth(context.Background()), tt.args)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, result.IsError)
+			} else {
+				require.NoError(t, err)
+				assert.False(t, result.IsError)
+				if tt.validate != nil {
+					tt.validate(t, result)
+				}
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// SQL tools let operators expose a parameterized, read-only query as an MCP tool
+// without writing Go. A template names its parameters with ":name" placeholders,
+// which are rewritten to positional "$N" parameters, so argument values are only ever
+// bound and never spliced into the SQL text. ":tenant_id" is reserved: every template
+// must use it and it is always bound to the caller's tenant from the JWT. Queries run
+// in a read-only transaction with the tenant's row-level security context.
+//
+//	sql_tools:
+//	  - name: documents_by_author
+//	    description: Documents written by an author
+//	    query: SELECT id, title FROM documents WHERE tenant_id = :tenant_id AND metadata->>'author' = :author LIMIT :limit
+//	    params:
+//	      - {name: author, type: string, required: true}
+//	      - {name: limit, type: integer, default: 20}
+
+// TenantParam is the reserved template parameter bound to the caller's tenant
+const TenantParam = "tenant_id"
+
+// SQL tool parameter types
+const (
+	SQLParamString    = "string"
+	SQLParamInteger   = "integer"
+	SQLParamNumber    = "number"
+	SQLParamBoolean   = "boolean"
+	SQLParamTimestamp = "timestamp"
+)
+
+// DefaultSQLMaxRows caps the rows a SQL tool returns when its spec sets no limit
+const DefaultSQLMaxRows = 100
+
+// ErrInvalidSQLTool is returned when a SQL tool spec or template is rejected
+var ErrInvalidSQLTool = errors.New("invalid sql tool")
+
+// SQLQuerier runs read-only queries in a tenant's context
+type SQLQuerier interface {
+	// QueryReadOnly runs query with positional args in a read-only transaction scoped
+	// to the tenant and returns at most maxRows rows keyed by column name
+	QueryReadOnly(ctx context.Context, tenantID, query string, args []interface{}, maxRows int) ([]map[string]interface{}, error)
+}
+
+// SQLParam describes a typed template parameter
+type SQLParam struct {
+	Name        string      `yaml:"name" json:"name"`
+	Type        string      `yaml:"type" json:"type"`
+	Description string      `yaml:"description" json:"description,omitempty"`
+	Required    bool        `yaml:"required" json:"required,omitempty"`
+	Default     interface{} `yaml:"default" json:"default,omitempty"`
+}
+
+// SQLToolSpec defines a SQL tool
+type SQLToolSpec struct {
+	Name        string     `yaml:"name" json:"name"`
+	Description string     `yaml:"description" json:"description"`
+	Query       string     `yaml:"query" json:"query"`
+	Params      []SQLParam `yaml:"params" json:"params,omitempty"`
+	MaxRows     int        `yaml:"max_rows" json:"max_rows,omitempty"` // default DefaultSQLMaxRows
+}
+
+// SQLTool runs an operator-defined query template
+type SQLTool struct {
+	spec     SQLToolSpec
+	query    string   // template rewritten to positional parameters
+	bindings []string // parameter name bound to each positional parameter
+	params   map[string]SQLParam
+	defaults map[string]interface{}
+	db       SQLQuerier
+}
+
+// NewSQLTool validates spec and compiles its template. db may be nil to only validate.
+func NewSQLTool(spec SQLToolSpec, db SQLQuerier) (*SQLTool, error) {
+	if !toolNamePattern.MatchString(spec.Name) {
+		return nil, fmt.Errorf("%w: name %q must match %s", ErrInvalidSQLTool, spec.Name, toolNamePattern)
+	}
+	if spec.MaxRows < 0 {
+		return nil, fmt.Errorf("%w %s: max_rows must not be negative", ErrInvalidSQLTool, spec.Name)
+	}
+	if spec.MaxRows == 0 {
+		spec.MaxRows = DefaultSQLMaxRows
+	}
+
+	tool := &SQLTool{
+		spec:     spec,
+		params:   make(map[string]SQLParam, len(spec.Params)),
+		defaults: make(map[string]interface{}),
+		db:       db,
+	}
+	for _, param := range spec.Params {
+		if err := tool.addParam(param); err != nil {
+			return nil, fmt.Errorf("%w %s: %v", ErrInvalidSQLTool, spec.Name, err)
+		}
+	}
+
+	query, bindings, err := compileSQLTemplate(spec.Query)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrInvalidSQLTool, spec.Name, err)
+	}
+	used := make(map[string]bool, len(bindings))
+	for _, name := range bindings {
+		if _, ok := tool.params[name]; !ok && name != TenantParam {
+			return nil, fmt.Errorf("%w %s: query uses undeclared parameter :%s", ErrInvalidSQLTool, spec.Name, name)
+		}
+		used[name] = true
+	}
+	if !used[TenantParam] {
+		return nil, fmt.Errorf("%w %s: query must filter by :%s", ErrInvalidSQLTool, spec.Name, TenantParam)
+	}
+	for name := range tool.params {
+		if !used[name] {
+			return nil, fmt.Errorf("%w %s: parameter %s is not used by the query", ErrInvalidSQLTool, spec.Name, name)
+		}
+	}
+	tool.query = query
+	tool.bindings = bindings
+	return tool, nil
+}
+
+// addParam validates a parameter declaration and its default
+func (t *SQLTool) addParam(param SQLParam) error {
+	if !toolNamePattern.MatchString(param.Name) {
+		return fmt.Errorf("parameter name %q must match %s", param.Name, toolNamePattern)
+	}
+	if param.Name == TenantParam {
+		return fmt.Errorf("parameter %s is reserved for the caller's tenant", TenantParam)
+	}
+	if _, ok := t.params[param.Name]; ok {
+		return fmt.Errorf("duplicate parameter %s", param.Name)
+	}
+	if _, ok := sqlParamSchemaTypes[param.Type]; !ok {
+		return fmt.Errorf("parameter %s: unknown type %q", param.Name, param.Type)
+	}
+	if param.Default != nil {
+		if param.Required {
+			return fmt.Errorf("parameter %s: a required parameter cannot have a default", param.Name)
+		}
+		value, err := bindSQLParam(param, param.Default)
+		if err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+		t.defaults[param.Name] = value
+	}
+	t.params[param.Name] = param
+	return nil
+}
+
+// sqlParamSchemaTypes maps parameter types to JSON schema types
+var sqlParamSchemaTypes = map[string]string{
+	SQLParamString:    "string",
+	SQLParamInteger:   "integer",
+	SQLParamNumber:    "number",
+	SQLParamBoolean:   "boolean",
+	SQLParamTimestamp: "string",
+}
+
+// Definition returns the tool definition for MCP, with a schema generated from the parameters
+func (t *SQLTool) Definition() protocol.Tool {
+	properties := make(map[string]interface{}, len(t.spec.Params))
+	required := []string{}
+	for _, param := range t.spec.Params {
+		property := map[string]interface{}{"type": sqlParamSchemaTypes[param.Type]}
+		if param.Type == SQLParamTimestamp {
+			property["format"] = "date-time"
+		}
+		if param.Description != "" {
+			property["description"] = param.Description
+		}
+		if param.Default != nil {
+			property["default"] = param.Default
+		}
+		properties[param.Name] = property
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return protocol.Tool{
+		Name:        t.spec.Name,
+		Description: t.spec.Description,
+		InputSchema: schema,
+	}
+}
+
+// SQLResult is the JSON body a SQL tool returns
+type SQLResult struct {
+	Rows      []map[string]interface{} `json:"rows"`
+	RowCount  int                      `json:"row_count"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// Execute binds the arguments and runs the query for the caller's tenant
+func (t *SQLTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+
+	values, err := t.bind(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	positional := make([]interface{}, len(t.bindings))
+	for i, name := range t.bindings {
+		if name == TenantParam {
+			positional[i] = tenantID
+		} else {
+			positional[i] = values[name]
+		}
+	}
+
+	// Fetch one extra row to report truncation
+	rows, err := t.db.QueryReadOnly(ctx, tenantID, t.query, positional, t.spec.MaxRows+1)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("query failed: %w", err)
+	}
+	result := SQLResult{Rows: rows}
+	if len(rows) > t.spec.MaxRows {
+		result.Rows = rows[:t.spec.MaxRows]
+		result.Truncated = true
+	}
+	if result.Rows == nil {
+		result.Rows = []map[string]interface{}{}
+	}
+	result.RowCount = len(result.Rows)
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to encode rows: %w", err)
+	}
+	return protocol.ToolCallResult{
+		Content: []protocol.ContentBlock{{Type: "text", Text: string(body)}},
+	}, nil
+}
+
+// bind checks the arguments against the declared parameters and converts them to query values
+func (t *SQLTool) bind(args map[string]interface{}) (map[string]interface{}, error) {
+	for name := range args {
+		if _, ok := t.params[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+
+	values := make(map[string]interface{}, len(t.params))
+	for name, param := range t.params {
+		arg, ok := args[name]
+		switch {
+		case ok && arg != nil:
+			value, err := bindSQLParam(param, arg)
+			if err != nil {
+				return nil, err
+			}
+			values[name] = value
+		case param.Required:
+			return nil, fmt.Errorf("parameter %s is required", name)
+		default:
+			values[name] = t.defaults[name] // nil binds NULL
+		}
+	}
+	return values, nil
+}
+
+// bindSQLParam converts an argument to the Go value bound for its parameter type.
+// Values are never coerced across types: "5" is not an integer and 1 is not a boolean.
+func bindSQLParam(param SQLParam, arg interface{}) (interface{}, error) {
+	switch param.Type {
+	case SQLParamString:
+		if s, ok := arg.(string); ok {
+			return s, nil
+		}
+	case SQLParamInteger:
+		switch v := arg.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+				return int64(v), nil
+			}
+		}
+	case SQLParamNumber:
+		switch v := arg.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case SQLParamBoolean:
+		if b, ok := arg.(bool); ok {
+			return b, nil
+		}
+	case SQLParamTimestamp:
+		switch v := arg.(type) {
+		case string:
+			ts, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s must be an RFC 3339 timestamp", param.Name)
+			}
+			return ts, nil
+		case time.Time:
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("parameter %s must be of type %s", param.Name, param.Type)
+}
+
+// compileSQLTemplate rewrites ":name" placeholders to "$N" and returns the parameter
+// name bound to each position. Only a single SELECT or WITH statement is accepted;
+// literal "$" and ";" are rejected outside string literals and comments.
+func compileSQLTemplate(template string) (string, []string, error) {
+	if keyword := firstSQLKeyword(template); keyword != "select" && keyword != "with" {
+		return "", nil, errors.New("query must be a single SELECT or WITH statement")
+	}
+
+	var out strings.Builder
+	var bindings []string
+	positions := make(map[string]int)
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(template[i+1:], c)
+			if end < 0 {
+				return "", nil, errors.New("unterminated quoted string")
+			}
+			out.WriteString(template[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(template[i:], "--"):
+			end := strings.IndexByte(template[i:], '\n')
+			if end < 0 {
+				end = len(template) - i
+			}
+			i += end - 1
+		case c == '/' && strings.HasPrefix(template[i:], "/*"):
+			end := strings.Index(template[i+2:], "*/")
+			if end < 0 {
+				return "", nil, errors.New("unterminated comment")
+			}
+			out.WriteByte(' ')
+			i += end + 3
+		case c == ';':
+			return "", nil, errors.New("query must be a single statement")
+		case c == '$':
+			return "", nil, errors.New("use :name parameters instead of $ placeholders")
+		case c == ':' && i+1 < len(template) && template[i+1] == ':':
+			out.WriteString("::") // type cast
+			i++
+		case c == ':' && i+1 < len(template) && isSQLNameStart(template[i+1]):
+			end := i + 1
+			for end < len(template) && isSQLNameChar(template[end]) {
+				end++
+			}
+			name := template[i+1 : end]
+			position, ok := positions[name]
+			if !ok {
+				bindings = append(bindings, name)
+				position = len(bindings)
+				positions[name] = position
+			}
+			fmt.Fprintf(&out, "$%d", position)
+			i = end - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), bindings, nil
+}
+
+// firstSQLKeyword returns the lower-cased first word of a query, skipping comments
+func firstSQLKeyword(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			end := 0
+			for end < len(query) && isSQLNameChar(query[end]) {
+				end++
+			}
+			return strings.ToLower(query[:end])
+		}
+	}
+}
+
+func isSQLNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSQLNameChar(c byte) bool {
+	return isSQLNameStart(c) || (c >= '0' && c <= '9')
+}
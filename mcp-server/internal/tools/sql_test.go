@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQLQuerier records the last query and returns canned rows
+type fakeSQLQuerier struct {
+	tenantID string
+	query    string
+	args     []interface{}
+	maxRows  int
+	rows     []map[string]interface{}
+}
+
+func (f *fakeSQLQuerier) QueryReadOnly(ctx context.Context, tenantID, query string, args []interface{}, maxRows int) ([]map[string]interface{}, error) {
+	f.tenantID, f.query, f.args, f.maxRows = tenantID, query, args, maxRows
+	if len(f.rows) > maxRows {
+		return f.rows[:maxRows], nil
+	}
+	return f.rows, nil
+}
+
+func authorToolSpec() SQLToolSpec {
+	return SQLToolSpec{
+		Name:        "documents_by_author",
+		Description: "Documents written by an author",
+		Query: `SELECT id, title FROM documents -- :ignored in comments
+			WHERE tenant_id = :tenant_id::uuid AND metadata->>'author' = :author
+			AND created_at >= :since AND title <> ':not_a_param' LIMIT :limit`,
+		Params: []SQLParam{
+			{Name: "author", Type: SQLParamString, Required: true},
+			{Name: "since", Type: SQLParamTimestamp},
+			{Name: "limit", Type: SQLParamInteger, Default: 20},
+		},
+		MaxRows: 2,
+	}
+}
+
+func TestSQLTool_CompilesTemplate(t *testing.T) {
+	tool, err := NewSQLTool(authorToolSpec(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"tenant_id", "author", "since", "limit"}, tool.bindings)
+	assert.Contains(t, tool.query, "tenant_id = $1::uuid AND metadata->>'author' = $2")
+	assert.Contains(t, tool.query, "created_at >= $3 AND title <> ':not_a_param' LIMIT $4")
+	assert.NotContains(t, tool.query, ":ignored")
+
+	def := tool.Definition()
+	assert.Equal(t, "documents_by_author", def.Name)
+	assert.Equal(t, []string{"author"}, def.InputSchema["required"])
+	properties := def.InputSchema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, properties["since"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "default": 20}, properties["limit"])
+	assert.Equal(t, false, def.InputSchema["additionalProperties"])
+}
+
+func TestSQLTool_Execute(t *testing.T) {
+	db := &fakeSQLQuerier{rows: []map[string]interface{}{{"id": "1"}, {"id": "2"}, {"id": "3"}}}
+	tool, err := NewSQLTool(authorToolSpec(), db)
+	require.NoError(t, err)
+
+	result, err := tool.Execute(tenantContext("tenant-a"), map[string]interface{}{
+		"author": "ada",
+		"since":  "2024-01-02T03:04:05Z",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	// The tenant comes from the caller, defaults fill in omitted parameters and
+	// unset optional parameters bind NULL
+	assert.Equal(t, "tenant-a", db.tenantID)
+	assert.Equal(t, []interface{}{"tenant-a", "ada", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), int64(20)}, db.args)
+	assert.Equal(t, 3, db.maxRows)
+
+	var body SQLResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &body))
+	assert.Equal(t, 2, body.RowCount)
+	assert.True(t, body.Truncated)
+
+	_, err = tool.Execute(tenantContext("tenant-a"), map[string]interface{}{"author": "ada", "since": nil})
+	require.NoError(t, err)
+	assert.Nil(t, db.args[2])
+}
+
+func TestSQLTool_StrictArguments(t *testing.T) {
+	tool, err := NewSQLTool(authorToolSpec(), &fakeSQLQuerier{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing required", map[string]interface{}{}},
+		{"wrong type", map[string]interface{}{"author": 42}},
+		{"string integer", map[string]interface{}{"author": "ada", "limit": "5"}},
+		{"fractional integer", map[string]interface{}{"author": "ada", "limit": 2.5}},
+		{"bad timestamp", map[string]interface{}{"author": "ada", "since": "yesterday"}},
+		{"unknown parameter", map[string]interface{}{"author": "ada", "extra": true}},
+		{"tenant override", map[string]interface{}{"author": "ada", "tenant_id": "tenant-b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(tenantContext("tenant-a"), tt.args)
+			assert.ErrorContains(t, err, "invalid arguments")
+			assert.True(t, result.IsError)
+		})
+	}
+
+	_, err = tool.Execute(context.Background(), map[string]interface{}{"author": "ada"})
+	assert.ErrorContains(t, err, "authentication required")
+}
+
+func TestNewSQLTool_RejectsInvalidSpecs(t *testing.T) {
+	param := func(name, typ string) []SQLParam { return []SQLParam{{Name: name, Type: typ}} }
+	tests := []struct {
+		name string
+		spec SQLToolSpec
+	}{
+		{"bad name", SQLToolSpec{Name: "Bad Name", Query: "SELECT :tenant_id"}},
+		{"write", SQLToolSpec{Name: "purge", Query: "DELETE FROM documents WHERE tenant_id = :tenant_id"}},
+		{"multiple statements", SQLToolSpec{Name: "multi", Query: "SELECT :tenant_id; DROP TABLE documents"}},
+		{"positional parameter", SQLToolSpec{Name: "positional", Query: "SELECT * FROM documents WHERE tenant_id = $1"}},
+		{"no tenant", SQLToolSpec{Name: "all", Query: "SELECT * FROM documents"}},
+		{"undeclared parameter", SQLToolSpec{Name: "undeclared", Query: "SELECT :tenant_id, :x"}},
+		{"unused parameter", SQLToolSpec{Name: "unused", Query: "SELECT :tenant_id", Params: param("x", SQLParamString)}},
+		{"reserved parameter", SQLToolSpec{Name: "reserved", Query: "SELECT :tenant_id", Params: param("tenant_id", SQLParamString)}},
+		{"unknown type", SQLToolSpec{Name: "typed", Query: "SELECT :tenant_id, :x", Params: param("x", "uuid")}},
+		{"bad default", SQLToolSpec{Name: "defaulted", Query: "SELECT :tenant_id, :x",
+			Params: []SQLParam{{Name: "x", Type: SQLParamInteger, Default: "ten"}}}},
+		{"unterminated string", SQLToolSpec{Name: "quote", Query: "SELECT :tenant_id, 'oops"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSQLTool(tt.spec, nil)
+			assert.ErrorIs(t, err, ErrInvalidSQLTool)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+)
+
+// Summary limits
+const (
+	DefaultSummaryTokens = 256
+	MaxSummaryTokens     = 1024
+	// charsPerToken sizes the extractive fallback to roughly the requested tokens
+	charsPerToken = 4
+	// maxSampledContent caps the document characters sent to the client's model
+	maxSampledContent = 24000
+)
+
+// Summary sources reported in the tool result
+const (
+	SummarySourceSampling   = "sampling"
+	SummarySourceExtractive = "extractive"
+)
+
+// ExtractiveSummarizer summarizes content in at most maxChars characters without a model
+type ExtractiveSummarizer func(content string, maxChars int) string
+
+// SummarizeTool summarizes a document with the calling client's LLM through MCP sampling,
+// falling back to an extractive summary when the client cannot sample, refuses, or does
+// not answer in time
+type SummarizeTool struct {
+	documentAccess
+	db       storage.Store
+	fallback ExtractiveSummarizer
+}
+
+// NewSummarizeTool creates a summarize tool using fallback when sampling is not possible
+func NewSummarizeTool(db storage.Store, fallback ExtractiveSummarizer) *SummarizeTool {
+	return &SummarizeTool{db: db, fallback: fallback}
+}
+
+// Definition returns the tool definition for MCP
+func (t *SummarizeTool) Definition() protocol.Tool {
+	return protocol.Tool{
+		Name: "summarize_document",
+		Description: "Summarize a document by its ID. Clients that support sampling are asked to write the summary " +
+			"with their model; otherwise the leading sentences of the document are returned.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"document_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The unique identifier of the document to summarize",
+				},
+				"collection": map[string]interface{}{
+					"type":        "string",
+					"description": "Only summarize the document if it belongs to this collection",
+					"pattern":     "^[a-z0-9][a-z0-9_-]{0,63}$",
+				},
+				"focus": map[string]interface{}{
+					"type":        "string",
+					"description": "What the summary should concentrate on, e.g. \"pricing changes\"",
+					"maxLength":   200,
+				},
+				"max_tokens": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum summary length in tokens (default: %d)", DefaultSummaryTokens),
+					"minimum":     16,
+					"maximum":     MaxSummaryTokens,
+				},
+			},
+			"required": []string{"document_id"},
+		},
+	}
+}
+
+// SummarizeParams represents the parameters for summarize
+type SummarizeParams struct {
+	DocumentID string `json:"document_id"`
+	Collection string `json:"collection,omitempty"`
+	Focus      string `json:"focus,omitempty"`
+	MaxTokens  int    `json:"max_tokens,omitempty"`
+}
+
+// Execute summarizes a document
+func (t *SummarizeTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	var params SummarizeParams
+	if err := json.Unmarshal(argsJSON, &params); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if params.DocumentID == "" {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("document_id is required")
+	}
+	if err := storage.ValidateCollection(params.Collection); err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+	if params.MaxTokens <= 0 {
+		params.MaxTokens = DefaultSummaryTokens
+	}
+	params.MaxTokens = min(params.MaxTokens, MaxSummaryTokens)
+
+	doc, err := t.db.GetDocument(ctx, tenantID, params.DocumentID)
+	if err == nil && params.Collection != "" && storage.CollectionOrDefault(doc.Collection) != params.Collection {
+		// Documents of other collections are not revealed to collection-scoped callers
+		err = storage.ErrNotFound
+	}
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("failed to retrieve document: %w", err)
+	}
+
+	t.recordAccess(ctx, "summarize_document", doc.ID)
+
+	summary, source, err := t.sample(ctx, doc, params)
+	if err != nil {
+		slog.InfoContext(ctx, "Sampling unavailable, using extractive summary", "document_id", doc.ID, "reason", err.Error())
+		summary, source = t.fallback(doc.Content, params.MaxTokens*charsPerToken), SummarySourceExtractive
+	}
+
+	resultText := fmt.Sprintf("Summary of %q (%s):\n\n%s\n\nSummary source: %s\n", doc.Title, doc.ID, summary, source)
+	return protocol.ToolCallResult{
+		Content: []protocol.ContentBlock{{Type: "text", Text: resultText}},
+	}, nil
+}
+
+// sample asks the calling client's model for the summary and returns it with its source
+func (t *SummarizeTool) sample(ctx context.Context, doc *storage.Document, params SummarizeParams) (string, string, error) {
+	sampler := SamplerFrom(ctx)
+	if sampler == nil {
+		return "", "", errors.New("client does not support sampling")
+	}
+
+	content := doc.Content
+	if runes := []rune(content); len(runes) > maxSampledContent {
+		content = string(runes[:maxSampledContent])
+	}
+	prompt := fmt.Sprintf("Summarize the following document titled %q.", doc.Title)
+	if params.Focus != "" {
+		prompt += " Focus on: " + params.Focus + "."
+	}
+	prompt += "\n\n" + content
+
+	result, err := sampler.CreateMessage(ctx, protocol.CreateMessageRequest{
+		Messages: []protocol.SamplingMessage{
+			{Role: "user", Content: protocol.ContentBlock{Type: "text", Text: prompt}},
+		},
+		SystemPrompt:     "You write concise, factual summaries of documents. Answer with the summary only.",
+		IncludeContext:   "none",
+		MaxTokens:        params.MaxTokens,
+		ModelPreferences: &protocol.ModelPreferences{SpeedPriority: 0.7, CostPriority: 0.5},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	summary := strings.TrimSpace(result.Content.Text)
+	if result.Content.Type != "text" || summary == "" {
+		return "", "", fmt.Errorf("client returned no text (content type %q)", result.Content.Type)
+	}
+	source := SummarySourceSampling
+	if result.Model != "" {
+		source += " (" + result.Model + ")"
+	}
+	return summary, source, nil
+}
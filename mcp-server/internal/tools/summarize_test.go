@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSampler answers sampling requests with a fixed result or error
+type fakeSampler struct {
+	result   *protocol.CreateMessageResult
+	err      error
+	requests []protocol.CreateMessageRequest
+}
+
+func (s *fakeSampler) CreateMessage(ctx context.Context, req protocol.CreateMessageRequest) (*protocol.CreateMessageResult, error) {
+	s.requests = append(s.requests, req)
+	return s.result, s.err
+}
+
+func firstSentence(content string, maxChars int) string {
+	sentence, _, _ := strings.Cut(content, ".")
+	return sentence + "."
+}
+
+func TestSummarizeToolExecute(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	doc := &storage.Document{ID: "doc-1", TenantID: "tenant-123", Title: "Refund policy", Content: "Refunds take 5 days. Contact support for exceptions."}
+
+	tests := []struct {
+		name        string
+		sampler     *fakeSampler
+		wantSummary string
+		wantSource  string
+	}{
+		{
+			name:        "client samples the summary",
+			sampler:     &fakeSampler{result: &protocol.CreateMessageResult{Role: "assistant", Content: protocol.ContentBlock{Type: "text", Text: " Refunds in 5 days. "}, Model: "test-model"}},
+			wantSummary: "Refunds in 5 days.",
+			wantSource:  "Summary source: sampling (test-model)",
+		},
+		{
+			name:        "client without sampling",
+			wantSummary: "Refunds take 5 days.",
+			wantSource:  "Summary source: extractive",
+		},
+		{
+			name:        "client times out",
+			sampler:     &fakeSampler{err: context.DeadlineExceeded},
+			wantSummary: "Refunds take 5 days.",
+			wantSource:  "Summary source: extractive",
+		},
+		{
+			name:        "client answers with an image",
+			sampler:     &fakeSampler{result: &protocol.CreateMessageResult{Content: protocol.ContentBlock{Type: "image", Data: "aGk="}}},
+			wantSummary: "Refunds take 5 days.",
+			wantSource:  "Summary source: extractive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockStore)
+			mockDB.On("GetDocument", mock.Anything, "tenant-123", "doc-1").Return(doc, nil)
+			tool := NewSummarizeTool(mockDB, firstSentence)
+
+			callCtx := ctx
+			if tt.sampler != nil {
+				callCtx = WithSampler(ctx, tt.sampler)
+			}
+			result, err := tool.Execute(callCtx, map[string]interface{}{"document_id": "doc-1", "focus": "timelines"})
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			assert.Contains(t, result.Content[0].Text, tt.wantSummary)
+			assert.Contains(t, result.Content[0].Text, tt.wantSource)
+
+			if tt.sampler != nil {
+				require.Len(t, tt.sampler.requests, 1)
+				req := tt.sampler.requests[0]
+				assert.Equal(t, DefaultSummaryTokens, req.MaxTokens)
+				assert.Contains(t, req.Messages[0].Content.Text, "Focus on: timelines.")
+				assert.Contains(t, req.Messages[0].Content.Text, doc.Content)
+			}
+		})
+	}
+}
+
+func TestSummarizeToolExecute_Errors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextKeyTenantID, "tenant-123")
+	mockDB := new(MockStore)
+	mockDB.On("GetDocument", mock.Anything, "tenant-123", "missing").Return(nil, storage.ErrNotFound)
+	mockDB.On("GetDocument", mock.Anything, "tenant-123", "doc-1").Return(&storage.Document{ID: "doc-1", Collection: "tickets"}, nil)
+	tool := NewSummarizeTool(mockDB, firstSentence)
+
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"document_id": "doc-1"})
+	assert.Error(t, err, "authentication required")
+	_, err = tool.Execute(ctx, map[string]interface{}{})
+	assert.Error(t, err)
+	_, err = tool.Execute(ctx, map[string]interface{}{"document_id": "missing"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = tool.Execute(ctx, map[string]interface{}{"document_id": "doc-1", "collection": "policies"})
+	assert.ErrorIs(t, err, storage.ErrNotFound, "documents of other collections are not revealed")
+}
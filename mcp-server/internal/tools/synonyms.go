@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/synonyms"
+)
+
+// SynonymLookup resolves a tenant's synonym dictionary
+type SynonymLookup interface {
+	GetSynonyms(ctx context.Context, tenantID string) (synonyms.Dictionary, error)
+}
+
+// SynonymLookupSetter is implemented by tools that accept an "expand_synonyms" argument
+type SynonymLookupSetter interface {
+	SetSynonymLookup(lookup SynonymLookup)
+}
+
+// querySynonyms is embedded in tools that accept an "expand_synonyms" argument
+type querySynonyms struct {
+	lookup SynonymLookup
+}
+
+// SetSynonymLookup sets where the tenant's synonyms are resolved
+func (s *querySynonyms) SetSynonymLookup(lookup SynonymLookup) {
+	s.lookup = lookup
+}
+
+// synonyms returns the tenant's dictionary when the call asks for synonym expansion
+func (s *querySynonyms) synonyms(ctx context.Context, tenantID string, expand bool) (map[string][]string, error) {
+	if !expand {
+		return nil, nil
+	}
+	if s.lookup == nil {
+		return nil, fmt.Errorf("synonym expansion is not enabled")
+	}
+	dictionary, err := s.lookup.GetSynonyms(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load synonyms: %w", err)
+	}
+	return dictionary, nil
+}
+
+// queryPreprocessingSchema documents the lexical query preprocessing arguments shared by
+// the search tools; scope qualifies when they apply, e.g. " in hybrid mode"
+func queryPreprocessingSchema(scope string) (correctSpelling, expandSynonyms map[string]interface{}) {
+	correctSpelling = map[string]interface{}{
+		"type": "boolean",
+		"description": "Replace query words that appear in no document of the collection with the most similar " +
+			"word by trigram similarity before lexical matching" + scope + " (default: false)",
+		"default": false,
+	}
+	expandSynonyms = map[string]interface{}{
+		"type":        "boolean",
+		"description": "Also match the synonyms of query words and phrases from the tenant's synonym dictionary" + scope + " (default: false)",
+		"default":     false,
+	}
+	return correctSpelling, expandSynonyms
+}
@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a tool does not finish within its execution timeout
+type TimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+	Elapsed time.Duration
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("tool %s timed out after %s (timeout %s)",
+		e.Tool, e.Elapsed.Round(time.Millisecond), e.Timeout)
+}
+
+// Data returns structured details for the JSON-RPC error response
+func (e *TimeoutError) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"tool":       e.Tool,
+		"timeout_ms": e.Timeout.Milliseconds(),
+		"elapsed_ms": e.Elapsed.Milliseconds(),
+	}
+}
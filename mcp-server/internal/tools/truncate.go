@@ -0,0 +1,306 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/redis/go-redis/v9"
+)
+
+// ContinuationToolName is the built-in tool returning the rest of a truncated result
+const ContinuationToolName = "read_continuation"
+
+const (
+	// DefaultMaxResponseBytes is the default limit on the content of a tool result
+	DefaultMaxResponseBytes = 1 << 20
+	// DefaultContinuationTTL is how long the rest of a truncated result can be fetched
+	DefaultContinuationTTL = 10 * time.Minute
+	// maxContinuations bounds the remainders kept in memory; the oldest are dropped first
+	maxContinuations = 1000
+	// continuationKeyPrefix prefixes the Redis keys of remainders
+	continuationKeyPrefix = "mcp:continuation:"
+)
+
+// ErrContinuationNotFound is returned for unknown, expired or already read continuation tokens
+var ErrContinuationNotFound = errors.New("continuation not found or expired")
+
+// Truncator limits the content of tool results to a maximum size. Content past the limit
+// is kept for the caller's tenant and returned, truncated again if need be, by the
+// read_continuation tool with the result's continuation token. Remainders are kept in
+// Redis when it is set, so any replica can return them, and in memory otherwise or while
+// Redis fails.
+type Truncator struct {
+	maxBytes int
+	ttl      time.Duration
+	now      func() time.Time
+	redis    *redis.Client
+
+	mu         sync.Mutex
+	remainders map[string]*remainder
+	// tokens in the order they were issued, oldest first
+	tokens []string
+}
+
+// remainder is the content cut off a result
+type remainder struct {
+	tenantID string
+	content  []protocol.ContentBlock
+	expires  time.Time
+}
+
+// NewTruncator limits results to maxBytes of content, keeping what is cut off for ttl
+func NewTruncator(maxBytes int, ttl time.Duration) *Truncator {
+	if ttl <= 0 {
+		ttl = DefaultContinuationTTL
+	}
+	return &Truncator{
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		now:        time.Now,
+		remainders: make(map[string]*remainder),
+	}
+}
+
+// SetRedis keeps remainders in Redis, under the caller's tenant and the token, until they
+// expire
+func (t *Truncator) SetRedis(client *redis.Client) {
+	t.redis = client
+}
+
+// ContentSize returns the bytes a content block counts against the limit: its text,
+// base64 image data or link
+func ContentSize(block protocol.ContentBlock) int {
+	return len(block.Text) + len(block.Data) + len(block.URI) + len(block.Name)
+}
+
+// Truncate cuts a result to the size limit, the truncation notice included. Blocks are
+// kept in order while they fit; the first that does not is cut at a character boundary if
+// it is text, or else moved with every later block to the continuation. A result always
+// keeps some content, so reading continuations makes progress, even when that takes it
+// over a limit smaller than the notice. Without a limit, a nil Truncator, the result is
+// returned as is.
+func (t *Truncator) Truncate(ctx context.Context, result protocol.ToolCallResult) protocol.ToolCallResult {
+	if t == nil || t.maxBytes <= 0 {
+		return result
+	}
+	total := 0
+	for _, block := range result.Content {
+		total += ContentSize(block)
+	}
+	if total <= t.maxBytes {
+		return result
+	}
+
+	// The rest is smaller than the whole, so its notice is no longer than this one
+	token := newContinuationToken()
+	limit := max(t.maxBytes-len(truncationNotice(total, token)), 0)
+	kept, rest := splitContent(result.Content, limit)
+	t.save(ctx, tenantOf(ctx), token, rest)
+	restBytes := 0
+	for _, block := range rest {
+		restBytes += ContentSize(block)
+	}
+	result.Content = append(kept, protocol.ContentBlock{
+		Type: protocol.ContentTypeText,
+		Text: truncationNotice(restBytes, token),
+	})
+	result.IsTruncated = true
+	result.ContinuationToken = token
+	return result
+}
+
+// truncationNotice tells the caller how to read the rest of a truncated result
+func truncationNotice(restBytes int, token string) string {
+	return fmt.Sprintf("[Result truncated: %d more bytes. Call %s with {\"continuation_token\": %q} for the rest.]",
+		restBytes, ContinuationToolName, token)
+}
+
+// splitContent splits content into the blocks that fit in limit bytes and the rest
+func splitContent(content []protocol.ContentBlock, limit int) (kept, rest []protocol.ContentBlock) {
+	budget := limit
+	for i, block := range content {
+		size := ContentSize(block)
+		if size <= budget {
+			budget -= size
+			continue
+		}
+
+		kept = append([]protocol.ContentBlock(nil), content[:i]...)
+		if block.Type == protocol.ContentTypeText {
+			cut := textCut(block.Text, budget, i == 0)
+			if cut > 0 {
+				head := block
+				head.Text = block.Text[:cut]
+				kept = append(kept, head)
+			}
+			tail := block
+			tail.Text = block.Text[cut:]
+			rest = append([]protocol.ContentBlock{tail}, content[i+1:]...)
+			return kept, rest
+		}
+		if i == 0 {
+			// A block that cannot be cut goes out whole rather than never
+			i = 1
+			kept = append(kept, block)
+		}
+		return kept, append([]protocol.ContentBlock(nil), content[i:]...)
+	}
+	return content, nil
+}
+
+// textCut returns where to cut text to at most n bytes without splitting a character;
+// with progress, at least one character is kept
+func textCut(text string, n int, progress bool) int {
+	if n >= len(text) {
+		return len(text)
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	if n == 0 && progress {
+		_, size := utf8.DecodeRuneInString(text)
+		return size
+	}
+	return n
+}
+
+// tenantOf returns the caller's tenant, or "" for unauthenticated calls
+func tenantOf(ctx context.Context) string {
+	tenantID, _ := auth.ExtractTenantID(ctx)
+	return tenantID
+}
+
+// newContinuationToken returns a random token naming a remainder
+func newContinuationToken() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// continuationKey returns the Redis key of a tenant's remainder
+func continuationKey(tenantID, token string) string {
+	return continuationKeyPrefix + tenantID + ":" + token
+}
+
+// save keeps content for the tenant under token
+func (t *Truncator) save(ctx context.Context, tenantID, token string, content []protocol.ContentBlock) {
+	if t.redis != nil {
+		data, err := json.Marshal(content)
+		if err == nil {
+			err = t.redis.Set(ctx, continuationKey(tenantID, token), data, t.ttl).Err()
+		}
+		if err == nil {
+			return
+		}
+		slog.WarnContext(ctx, "Failed to keep continuation in Redis, keeping it in memory", "error", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	live := t.tokens[:0]
+	for _, existing := range t.tokens {
+		if r, ok := t.remainders[existing]; ok && now.Before(r.expires) {
+			live = append(live, existing)
+		} else {
+			delete(t.remainders, existing)
+		}
+	}
+	t.tokens = live
+	for len(t.tokens) >= maxContinuations {
+		delete(t.remainders, t.tokens[0])
+		t.tokens = t.tokens[1:]
+	}
+
+	t.remainders[token] = &remainder{tenantID: tenantID, content: content, expires: now.Add(t.ttl)}
+	t.tokens = append(t.tokens, token)
+}
+
+// Take returns the content kept under a continuation token and forgets it; each token
+// can be read once, by the tenant whose result was truncated
+func (t *Truncator) Take(ctx context.Context, token string) ([]protocol.ContentBlock, error) {
+	if t.redis != nil {
+		data, err := t.redis.GetDel(ctx, continuationKey(tenantOf(ctx), token)).Bytes()
+		if err == nil {
+			var content []protocol.ContentBlock
+			if err := json.Unmarshal(data, &content); err != nil {
+				return nil, fmt.Errorf("failed to decode continuation: %w", err)
+			}
+			return content, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			slog.WarnContext(ctx, "Failed to read continuation from Redis", "error", err)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.remainders[token]
+	if !ok || r.tenantID != tenantOf(ctx) || !t.now().Before(r.expires) {
+		return nil, ErrContinuationNotFound
+	}
+	delete(t.remainders, token)
+	return r.content, nil
+}
+
+// ContinuationTool returns the rest of a truncated tool result
+type ContinuationTool struct {
+	truncator *Truncator
+}
+
+// NewContinuationTool creates a new continuation tool
+func NewContinuationTool(truncator *Truncator) *ContinuationTool {
+	return &ContinuationTool{truncator: truncator}
+}
+
+// Definition returns the tool definition for MCP
+func (t *ContinuationTool) Definition() protocol.Tool {
+	return protocol.Tool{
+		Name: ContinuationToolName,
+		Description: "Return the rest of a tool result that was truncated to the server's response size limit. " +
+			"Pass the continuation_token of the truncated result; the rest may itself be truncated, with a new token.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"continuation_token": map[string]interface{}{
+					"type":        "string",
+					"description": "The continuation_token of the truncated result",
+				},
+			},
+			"required": []string{"continuation_token"},
+		},
+	}
+}
+
+// ContinuationParams represents the parameters for read_continuation
+type ContinuationParams struct {
+	ContinuationToken string `json:"continuation_token"`
+}
+
+// Execute returns the content kept under the token
+func (t *ContinuationTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	var params ContinuationParams
+	if err := json.Unmarshal(argsJSON, &params); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	content, err := t.truncator.Take(ctx, params.ContinuationToken)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, err
+	}
+	return protocol.ToolCallResult{Content: content}, nil
+}
@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func text(s string) protocol.ContentBlock {
+	return protocol.ContentBlock{Type: protocol.ContentTypeText, Text: s}
+}
+
+func TestSplitContent(t *testing.T) {
+	image := protocol.ContentBlock{Type: protocol.ContentTypeImage, Data: "aW1hZ2U=", MimeType: "image/png"}
+	tests := []struct {
+		name       string
+		content    []protocol.ContentBlock
+		limit      int
+		kept, rest []protocol.ContentBlock
+	}{
+		{"fits", []protocol.ContentBlock{text("abc"), text("de")}, 5, []protocol.ContentBlock{text("abc"), text("de")}, nil},
+		{"cuts text", []protocol.ContentBlock{text("abc"), text("defgh"), text("ij")}, 5,
+			[]protocol.ContentBlock{text("abc"), text("de")}, []protocol.ContentBlock{text("fgh"), text("ij")}},
+		{"keeps characters whole", []protocol.ContentBlock{text("añb")}, 2,
+			[]protocol.ContentBlock{text("a")}, []protocol.ContentBlock{text("ñb")}},
+		{"moves blocks that cannot be cut", []protocol.ContentBlock{text("abc"), image, text("d")}, 5,
+			[]protocol.ContentBlock{text("abc")}, []protocol.ContentBlock{image, text("d")}},
+		{"sends a first block whole", []protocol.ContentBlock{image, text("d")}, 5,
+			[]protocol.ContentBlock{image}, []protocol.ContentBlock{text("d")}},
+		{"keeps a first character", []protocol.ContentBlock{text("ñb")}, 1,
+			[]protocol.ContentBlock{text("ñ")}, []protocol.ContentBlock{text("b")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, rest := splitContent(tt.content, tt.limit)
+			assert.Equal(t, tt.kept, kept)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
+
+// contentSize sums the sizes of content blocks
+func contentSize(content []protocol.ContentBlock) int {
+	size := 0
+	for _, block := range content {
+		size += ContentSize(block)
+	}
+	return size
+}
+
+func TestTruncator(t *testing.T) {
+	ctx := auth.WithAuth(context.Background(), &auth.Claims{TenantID: "tenant-1"})
+	truncator := NewTruncator(200, time.Minute)
+	tool := NewContinuationTool(truncator)
+	document := strings.Repeat("0123456789", 30) + "end"
+
+	// The notice counts toward the limit
+	result := truncator.Truncate(ctx, protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}})
+	require.True(t, result.IsTruncated)
+	require.NotEmpty(t, result.ContinuationToken)
+	require.Len(t, result.Content, 2)
+	assert.LessOrEqual(t, contentSize(result.Content), 200)
+	head := result.Content[0].Text
+	assert.True(t, strings.HasPrefix(document, head))
+	assert.Contains(t, result.Content[1].Text, fmt.Sprintf("%d more bytes", len(document)-len(head)))
+	assert.Contains(t, result.Content[1].Text, result.ContinuationToken)
+
+	// The rest is returned once, to the same tenant, and truncated again by the caller
+	other := auth.WithAuth(context.Background(), &auth.Claims{TenantID: "tenant-2"})
+	_, err := tool.Execute(other, map[string]interface{}{"continuation_token": result.ContinuationToken})
+	assert.ErrorIs(t, err, ErrContinuationNotFound)
+	rest, err := tool.Execute(ctx, map[string]interface{}{"continuation_token": result.ContinuationToken})
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.ContentBlock{text(document[len(head):])}, rest.Content)
+	_, err = tool.Execute(ctx, map[string]interface{}{"continuation_token": result.ContinuationToken})
+	assert.ErrorIs(t, err, ErrContinuationNotFound)
+
+	next := truncator.Truncate(ctx, rest)
+	require.True(t, next.IsTruncated)
+	assert.NotEqual(t, result.ContinuationToken, next.ContinuationToken)
+	last, err := truncator.Take(ctx, next.ContinuationToken)
+	require.NoError(t, err)
+	final := truncator.Truncate(ctx, protocol.ToolCallResult{Content: last})
+	assert.False(t, final.IsTruncated)
+	assert.Equal(t, document, head+next.Content[0].Text+final.Content[0].Text)
+
+	// Continuations expire
+	expired := truncator.Truncate(ctx, protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}})
+	truncator.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = truncator.Take(ctx, expired.ContinuationToken)
+	assert.ErrorIs(t, err, ErrContinuationNotFound)
+
+	// A limit smaller than the notice still returns some content
+	tiny := NewTruncator(10, time.Minute).Truncate(ctx, protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}})
+	assert.Equal(t, "0", tiny.Content[0].Text)
+
+	// Without a limit results are left as they are
+	var none *Truncator
+	small := protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}}
+	assert.Equal(t, small, none.Truncate(ctx, small))
+}
+
+func TestTruncator_Redis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	ctx := auth.WithAuth(context.Background(), &auth.Claims{TenantID: "tenant-1"})
+	document := strings.Repeat("0123456789", 20) + "end"
+
+	// Replicas sharing Redis return each other's remainders
+	replica1, replica2 := NewTruncator(200, time.Minute), NewTruncator(200, time.Minute)
+	replica1.SetRedis(client)
+	replica2.SetRedis(client)
+	result := replica1.Truncate(ctx, protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}})
+	require.True(t, result.IsTruncated)
+	assert.True(t, mr.Exists("mcp:continuation:tenant-1:"+result.ContinuationToken))
+	assert.Equal(t, time.Minute, mr.TTL("mcp:continuation:tenant-1:"+result.ContinuationToken))
+
+	other := auth.WithAuth(context.Background(), &auth.Claims{TenantID: "tenant-2"})
+	_, err := replica2.Take(other, result.ContinuationToken)
+	assert.ErrorIs(t, err, ErrContinuationNotFound)
+	rest, err := replica2.Take(ctx, result.ContinuationToken)
+	require.NoError(t, err)
+	assert.Equal(t, document, result.Content[0].Text+rest[0].Text)
+	_, err = replica1.Take(ctx, result.ContinuationToken)
+	assert.ErrorIs(t, err, ErrContinuationNotFound)
+
+	// Continuations expire with their keys
+	expired := replica1.Truncate(ctx, protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}})
+	mr.FastForward(2 * time.Minute)
+	_, err = replica2.Take(ctx, expired.ContinuationToken)
+	assert.ErrorIs(t, err, ErrContinuationNotFound)
+
+	// While Redis is down remainders are kept in memory
+	mr.Close()
+	fallback := replica1.Truncate(ctx, protocol.ToolCallResult{Content: []protocol.ContentBlock{text(document)}})
+	rest, err = replica1.Take(ctx, fallback.ContinuationToken)
+	require.NoError(t, err)
+	assert.Equal(t, document, fallback.Content[0].Text+rest[0].Text)
+}
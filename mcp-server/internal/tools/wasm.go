@@ -0,0 +1,326 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// WASM tool ABI. An uploaded module must export
+//
+//	memory                           its linear memory
+//	alloc(size i32) -> i32           returns a buffer of size bytes for the input
+//	call(ptr i32, len i32) -> i64    runs the tool on the input buffer and returns the
+//	                                 output buffer as (ptr << 32 | len)
+//
+// and import nothing, so it has no network, filesystem or clock access. The input is the
+// JSON object {"tenant_id": "...", "arguments": {...}} and the output must be a JSON
+// tools/call result: {"content": [{"type": "text", "text": "..."}], "isError": false}.
+// Every call runs in a fresh instance, so no state survives between calls.
+const (
+	wasmExportMemory = "memory"
+	wasmExportAlloc  = "alloc"
+	wasmExportCall   = "call"
+)
+
+var (
+	// ErrWASMUnavailable is returned when the server was built without a WASM runtime
+	ErrWASMUnavailable = errors.New("WASM tools are not available in this build (build with -tags wazero)")
+	// ErrWASMABI is returned for modules that do not implement the tool ABI
+	ErrWASMABI = errors.New("module does not implement the WASM tool ABI")
+	// ErrWASMToolNotFound is returned when a tenant has no WASM tool with the given name
+	ErrWASMToolNotFound = errors.New("WASM tool not found")
+	// ErrWASMLimit is returned when an upload or call exceeds a configured limit
+	ErrWASMLimit = errors.New("WASM limit exceeded")
+)
+
+// toolNamePattern restricts uploaded and configured tool names to the style of the built-in tools
+var toolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// WASMLimits bounds what uploaded modules can use
+type WASMLimits struct {
+	MaxModuleBytes    int           `yaml:"max_module_bytes"`
+	MaxMemoryPages    uint32        `yaml:"max_memory_pages"` // 64 KiB pages per instance
+	MaxOutputBytes    uint32        `yaml:"max_output_bytes"`
+	Timeout           time.Duration `yaml:"timeout"` // per call
+	MaxToolsPerTenant int           `yaml:"max_tools_per_tenant"`
+}
+
+// DefaultWASMLimits returns the limits used when none are configured
+func DefaultWASMLimits() WASMLimits {
+	return WASMLimits{
+		MaxModuleBytes:    1 << 20, // 1 MiB
+		MaxMemoryPages:    256,     // 16 MiB
+		MaxOutputBytes:    1 << 20,
+		Timeout:           5 * time.Second,
+		MaxToolsPerTenant: 20,
+	}
+}
+
+// WASMRuntime compiles uploaded modules
+type WASMRuntime interface {
+	// Compile checks a module against the tool ABI and prepares it for execution
+	Compile(ctx context.Context, wasm []byte) (WASMModule, error)
+	// Close releases the runtime and every module it compiled
+	Close(ctx context.Context) error
+}
+
+// WASMModule is a compiled tool module
+type WASMModule interface {
+	// Call runs the module's call export on input in a fresh sandboxed instance and
+	// returns its output; it must stop when ctx is done
+	Call(ctx context.Context, input []byte) ([]byte, error)
+	// Close releases the compiled module
+	Close(ctx context.Context) error
+}
+
+// WASMToolSpec describes an uploaded tool as it appears in tools/list
+type WASMToolSpec struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+}
+
+// WASMToolInfo describes a registered WASM tool for the admin API
+type WASMToolInfo struct {
+	WASMToolSpec
+	TenantID     string    `json:"tenant_id"`
+	SHA256       string    `json:"sha256"`
+	SizeBytes    int       `json:"size_bytes"`
+	RegisteredBy string    `json:"registered_by,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// wasmCallInput is the JSON document passed to a module's call export
+type wasmCallInput struct {
+	TenantID  string                 `json:"tenant_id"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// WASMTool runs an uploaded module as an MCP tool for the tenant that registered it
+type WASMTool struct {
+	info   WASMToolInfo
+	module WASMModule
+	limits WASMLimits
+}
+
+var _ Tool = (*WASMTool)(nil)
+
+// Definition returns the tool definition for MCP
+func (t *WASMTool) Definition() protocol.Tool {
+	schema := t.info.InputSchema
+	if schema == nil {
+		schema = map[string]interface{}{"type": "object"}
+	}
+	return protocol.Tool{
+		Name:        t.info.Name,
+		Description: t.info.Description,
+		InputSchema: schema,
+	}
+}
+
+// Execute runs the module with the call arguments under the configured time limit
+func (t *WASMTool) Execute(ctx context.Context, args map[string]interface{}) (protocol.ToolCallResult, error) {
+	tenantID, err := auth.ExtractTenantID(ctx)
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("authentication required: %w", err)
+	}
+	if tenantID != t.info.TenantID {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("tool not found: %s", t.info.Name)
+	}
+
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	input, err := json.Marshal(wasmCallInput{TenantID: tenantID, Arguments: args})
+	if err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if t.limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.limits.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	output, err := t.module.Call(ctx, input)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && t.limits.Timeout > 0 {
+			return protocol.ToolCallResult{IsError: true}, &TimeoutError{Tool: t.info.Name, Timeout: t.limits.Timeout, Elapsed: time.Since(start)}
+		}
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("wasm tool %s failed: %w", t.info.Name, err)
+	}
+	if t.limits.MaxOutputBytes > 0 && len(output) > int(t.limits.MaxOutputBytes) {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("%w: wasm tool %s returned %d bytes, max %d",
+			ErrWASMLimit, t.info.Name, len(output), t.limits.MaxOutputBytes)
+	}
+
+	var result protocol.ToolCallResult
+	if err := json.Unmarshal(output, &result); err != nil {
+		return protocol.ToolCallResult{IsError: true}, fmt.Errorf("wasm tool %s returned invalid output: %w", t.info.Name, err)
+	}
+	return result, nil
+}
+
+// WASMTools holds the WASM tools each tenant registered. Tools are kept in memory, so
+// tenants re-upload them after a restart.
+type WASMTools struct {
+	runtime WASMRuntime
+	limits  WASMLimits
+
+	mu    sync.RWMutex
+	tools map[string]map[string]*WASMTool // tenant ID -> tool name -> tool
+}
+
+var _ TenantTools = (*WASMTools)(nil)
+
+// NewWASMTools creates an empty WASM tool store that compiles modules with runtime
+func NewWASMTools(runtime WASMRuntime, limits WASMLimits) *WASMTools {
+	return &WASMTools{
+		runtime: runtime,
+		limits:  limits,
+		tools:   make(map[string]map[string]*WASMTool),
+	}
+}
+
+// Limits returns the limits applied to uploads and calls
+func (s *WASMTools) Limits() WASMLimits {
+	return s.limits
+}
+
+// Register compiles module and registers it as a tool for the tenant, replacing any
+// tool of the same name the tenant registered before
+func (s *WASMTools) Register(ctx context.Context, tenantID, registeredBy string, spec WASMToolSpec, module []byte) (WASMToolInfo, error) {
+	if !toolNamePattern.MatchString(spec.Name) {
+		return WASMToolInfo{}, fmt.Errorf("invalid tool name %q: use lowercase letters, digits and underscores", spec.Name)
+	}
+	if len(spec.InputSchema) > 0 {
+		if _, err := compileSchema(spec.InputSchema); err != nil {
+			return WASMToolInfo{}, err
+		}
+	}
+	if len(module) == 0 {
+		return WASMToolInfo{}, fmt.Errorf("%w: module is empty", ErrWASMABI)
+	}
+	if s.limits.MaxModuleBytes > 0 && len(module) > s.limits.MaxModuleBytes {
+		return WASMToolInfo{}, fmt.Errorf("%w: module is %d bytes, max %d", ErrWASMLimit, len(module), s.limits.MaxModuleBytes)
+	}
+
+	s.mu.RLock()
+	_, replacing := s.tools[tenantID][spec.Name]
+	count := len(s.tools[tenantID])
+	s.mu.RUnlock()
+	if !replacing && s.limits.MaxToolsPerTenant > 0 && count >= s.limits.MaxToolsPerTenant {
+		return WASMToolInfo{}, fmt.Errorf("%w: tenant already has %d WASM tools", ErrWASMLimit, count)
+	}
+
+	compiled, err := s.runtime.Compile(ctx, module)
+	if err != nil {
+		return WASMToolInfo{}, err
+	}
+
+	sum := sha256.Sum256(module)
+	tool := &WASMTool{
+		info: WASMToolInfo{
+			WASMToolSpec: spec,
+			TenantID:     tenantID,
+			SHA256:       hex.EncodeToString(sum[:]),
+			SizeBytes:    len(module),
+			RegisteredBy: registeredBy,
+			RegisteredAt: time.Now().UTC(),
+		},
+		module: compiled,
+		limits: s.limits,
+	}
+
+	s.mu.Lock()
+	if s.tools[tenantID] == nil {
+		s.tools[tenantID] = make(map[string]*WASMTool)
+	}
+	previous := s.tools[tenantID][spec.Name]
+	s.tools[tenantID][spec.Name] = tool
+	s.mu.Unlock()
+
+	if previous != nil {
+		previous.module.Close(ctx)
+	}
+	return tool.info, nil
+}
+
+// Remove unregisters a tenant's WASM tool
+func (s *WASMTools) Remove(ctx context.Context, tenantID, name string) error {
+	s.mu.Lock()
+	tool, ok := s.tools[tenantID][name]
+	if ok {
+		delete(s.tools[tenantID], name)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrWASMToolNotFound
+	}
+	return tool.module.Close(ctx)
+}
+
+// Get returns a tenant's WASM tool
+func (s *WASMTools) Get(tenantID, name string) (WASMToolInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tool, ok := s.tools[tenantID][name]
+	if !ok {
+		return WASMToolInfo{}, ErrWASMToolNotFound
+	}
+	return tool.info, nil
+}
+
+// List returns a tenant's WASM tools sorted by name
+func (s *WASMTools) List(tenantID string) []WASMToolInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]WASMToolInfo, 0, len(s.tools[tenantID]))
+	for _, tool := range s.tools[tenantID] {
+		infos = append(infos, tool.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Tools returns a tenant's WASM tools for the tool registry
+func (s *WASMTools) Tools(tenantID string) []Tool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(s.tools[tenantID]))
+	for _, tool := range s.tools[tenantID] {
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// Close releases every registered module and the runtime
+func (s *WASMTools) Close(ctx context.Context) error {
+	s.mu.Lock()
+	tools := s.tools
+	s.tools = make(map[string]map[string]*WASMTool)
+	s.mu.Unlock()
+
+	for _, byName := range tools {
+		for _, tool := range byName {
+			tool.module.Close(ctx)
+		}
+	}
+	return s.runtime.Close(ctx)
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWASMRuntime "compiles" modules whose bytes name a Go behaviour
+type fakeWASMRuntime struct {
+	closed bool
+}
+
+func (r *fakeWASMRuntime) Compile(ctx context.Context, wasm []byte) (WASMModule, error) {
+	switch string(wasm) {
+	case "echo", "sleep", "garbage":
+		return &fakeWASMModule{behaviour: string(wasm)}, nil
+	default:
+		return nil, ErrWASMABI
+	}
+}
+
+func (r *fakeWASMRuntime) Close(ctx context.Context) error {
+	r.closed = true
+	return nil
+}
+
+type fakeWASMModule struct {
+	behaviour string
+	closed    bool
+}
+
+func (m *fakeWASMModule) Call(ctx context.Context, input []byte) ([]byte, error) {
+	switch m.behaviour {
+	case "sleep":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "garbage":
+		return []byte("not json"), nil
+	}
+	result := protocol.ToolCallResult{Content: []protocol.ContentBlock{{Type: "text", Text: string(input)}}}
+	return json.Marshal(result)
+}
+
+func (m *fakeWASMModule) Close(ctx context.Context) error {
+	m.closed = true
+	return nil
+}
+
+func tenantContext(tenantID string) context.Context {
+	return auth.WithAuth(context.Background(), &auth.Claims{TenantID: tenantID, UserID: "user-1"})
+}
+
+func TestWASMTools_RegisterAndExecute(t *testing.T) {
+	store := NewWASMTools(&fakeWASMRuntime{}, DefaultWASMLimits())
+	ctx := context.Background()
+
+	info, err := store.Register(ctx, "tenant-a", "admin", WASMToolSpec{Name: "word_count", Description: "Counts words"}, []byte("echo"))
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", info.TenantID)
+	assert.Equal(t, "admin", info.RegisteredBy)
+	assert.Equal(t, 4, info.SizeBytes)
+	assert.Len(t, info.SHA256, 64)
+
+	_, err = store.Register(ctx, "tenant-a", "admin", WASMToolSpec{
+		Name: "broken", InputSchema: map[string]interface{}{"type": "text"},
+	}, []byte("echo"))
+	assert.ErrorContains(t, err, "invalid input schema")
+
+	tools := store.Tools("tenant-a")
+	require.Len(t, tools, 1)
+	assert.Equal(t, map[string]interface{}{"type": "object"}, tools[0].Definition().InputSchema)
+
+	result, err := tools[0].Execute(tenantContext("tenant-a"), map[string]interface{}{"text": "a b c"})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+
+	var input wasmCallInput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &input))
+	assert.Equal(t, "tenant-a", input.TenantID)
+	assert.Equal(t, "a b c", input.Arguments["text"])
+
+	// Other tenants neither see nor run the tool
+	assert.Empty(t, store.Tools("tenant-b"))
+	_, err = tools[0].Execute(tenantContext("tenant-b"), nil)
+	assert.Error(t, err)
+}
+
+func TestWASMTools_RegisterValidation(t *testing.T) {
+	limits := DefaultWASMLimits()
+	limits.MaxModuleBytes = 8
+	limits.MaxToolsPerTenant = 1
+	store := NewWASMTools(&fakeWASMRuntime{}, limits)
+	ctx := context.Background()
+
+	_, err := store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "Bad-Name"}, []byte("echo"))
+	assert.Error(t, err)
+
+	_, err = store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "big"}, []byte("0123456789"))
+	assert.ErrorIs(t, err, ErrWASMLimit)
+
+	_, err = store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "invalid"}, []byte("nope"))
+	assert.ErrorIs(t, err, ErrWASMABI)
+
+	_, err = store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "first"}, []byte("echo"))
+	require.NoError(t, err)
+	_, err = store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "second"}, []byte("echo"))
+	assert.ErrorIs(t, err, ErrWASMLimit)
+
+	// Replacing an existing tool does not count against the limit
+	_, err = store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "first", Description: "v2"}, []byte("echo"))
+	require.NoError(t, err)
+	info, err := store.Get("tenant-a", "first")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", info.Description)
+}
+
+func TestWASMTool_Timeout(t *testing.T) {
+	limits := DefaultWASMLimits()
+	limits.Timeout = 10 * time.Millisecond
+	store := NewWASMTools(&fakeWASMRuntime{}, limits)
+
+	_, err := store.Register(context.Background(), "tenant-a", "", WASMToolSpec{Name: "slow"}, []byte("sleep"))
+	require.NoError(t, err)
+
+	_, err = store.Tools("tenant-a")[0].Execute(tenantContext("tenant-a"), nil)
+	var timeoutErr *TimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, "slow", timeoutErr.Tool)
+}
+
+func TestWASMTool_InvalidOutput(t *testing.T) {
+	store := NewWASMTools(&fakeWASMRuntime{}, DefaultWASMLimits())
+
+	_, err := store.Register(context.Background(), "tenant-a", "", WASMToolSpec{Name: "broken"}, []byte("garbage"))
+	require.NoError(t, err)
+
+	result, err := store.Tools("tenant-a")[0].Execute(tenantContext("tenant-a"), nil)
+	assert.Error(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestWASMTools_RemoveAndClose(t *testing.T) {
+	runtime := &fakeWASMRuntime{}
+	store := NewWASMTools(runtime, DefaultWASMLimits())
+	ctx := context.Background()
+
+	_, err := store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "one"}, []byte("echo"))
+	require.NoError(t, err)
+	_, err = store.Register(ctx, "tenant-a", "", WASMToolSpec{Name: "two"}, []byte("echo"))
+	require.NoError(t, err)
+
+	module := store.tools["tenant-a"]["one"].module.(*fakeWASMModule)
+	require.NoError(t, store.Remove(ctx, "tenant-a", "one"))
+	assert.True(t, module.closed)
+	assert.ErrorIs(t, store.Remove(ctx, "tenant-a", "one"), ErrWASMToolNotFound)
+
+	infos := store.List("tenant-a")
+	require.Len(t, infos, 1)
+	assert.Equal(t, "two", infos[0].Name)
+
+	require.NoError(t, store.Close(ctx))
+	assert.True(t, runtime.closed)
+	assert.Empty(t, store.List("tenant-a"))
+}
+
+func TestRegistry_TenantTools(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewListTool(new(MockStore)))
+
+	store := NewWASMTools(&fakeWASMRuntime{}, DefaultWASMLimits())
+	_, err := store.Register(context.Background(), "tenant-a", "", WASMToolSpec{Name: "word_count"}, []byte("echo"))
+	require.NoError(t, err)
+	registry.SetTenantTools(store)
+
+	assert.Len(t, registry.ListFor(tenantContext("tenant-a")), 2)
+	assert.Len(t, registry.ListFor(tenantContext("tenant-b")), 1)
+	assert.Len(t, registry.ListFor(context.Background()), 1)
+
+	result, err := registry.Execute(tenantContext("tenant-a"), "word_count", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	_, err = registry.Execute(tenantContext("tenant-b"), "word_count", map[string]interface{}{})
+	assert.ErrorContains(t, err, "tool not found")
+}
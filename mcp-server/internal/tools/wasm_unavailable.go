@@ -0,0 +1,10 @@
+//go:build !wazero
+
+package tools
+
+import "context"
+
+// NewWASMRuntime returns ErrWASMUnavailable; build with -tags wazero for the wazero runtime
+func NewWASMRuntime(ctx context.Context, limits WASMLimits) (WASMRuntime, error) {
+	return nil, ErrWASMUnavailable
+}
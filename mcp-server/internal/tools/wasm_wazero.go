@@ -0,0 +1,134 @@
+//go:build wazero
+
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// wazeroRuntime runs WASM tools with wazero, a pure Go runtime. No host modules are
+// instantiated, so modules that import anything fail to compile and tools have no
+// network, filesystem or clock access.
+type wazeroRuntime struct {
+	runtime wazero.Runtime
+	limits  WASMLimits
+}
+
+// NewWASMRuntime creates a wazero runtime enforcing the memory limit; calls are aborted
+// when their context is done
+func NewWASMRuntime(ctx context.Context, limits WASMLimits) (WASMRuntime, error) {
+	config := wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true)
+	if limits.MaxMemoryPages > 0 {
+		config = config.WithMemoryLimitPages(limits.MaxMemoryPages)
+	}
+	return &wazeroRuntime{
+		runtime: wazero.NewRuntimeWithConfig(ctx, config),
+		limits:  limits,
+	}, nil
+}
+
+// Compile checks the module's imports and exports against the tool ABI
+func (r *wazeroRuntime) Compile(ctx context.Context, wasm []byte) (WASMModule, error) {
+	compiled, err := r.runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWASMABI, err)
+	}
+	if err := checkABI(compiled); err != nil {
+		compiled.Close(ctx)
+		return nil, err
+	}
+	return &wazeroModule{runtime: r.runtime, compiled: compiled}, nil
+}
+
+// Close releases the runtime and every module it compiled
+func (r *wazeroRuntime) Close(ctx context.Context) error {
+	return r.runtime.Close(ctx)
+}
+
+// checkABI verifies a compiled module imports nothing and exports the ABI functions
+func checkABI(compiled wazero.CompiledModule) error {
+	if len(compiled.ImportedFunctions()) > 0 || len(compiled.ImportedMemories()) > 0 {
+		return fmt.Errorf("%w: modules must not import host functions or memory", ErrWASMABI)
+	}
+	if _, ok := compiled.ExportedMemories()[wasmExportMemory]; !ok {
+		return fmt.Errorf("%w: missing %q export", ErrWASMABI, wasmExportMemory)
+	}
+
+	exports := compiled.ExportedFunctions()
+	signatures := map[string]struct{ params, results []api.ValueType }{
+		wasmExportAlloc: {[]api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}},
+		wasmExportCall:  {[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}},
+	}
+	for name, want := range signatures {
+		def, ok := exports[name]
+		if !ok {
+			return fmt.Errorf("%w: missing %q export", ErrWASMABI, name)
+		}
+		if !sameTypes(def.ParamTypes(), want.params) || !sameTypes(def.ResultTypes(), want.results) {
+			return fmt.Errorf("%w: %q has the wrong signature", ErrWASMABI, name)
+		}
+	}
+	return nil
+}
+
+// sameTypes reports whether two value type lists are equal
+func sameTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// wazeroModule is a compiled tool module
+type wazeroModule struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// Call instantiates the module, copies input into a buffer from alloc and runs call
+func (m *wazeroModule) Call(ctx context.Context, input []byte) ([]byte, error) {
+	// Anonymous instances can coexist, so concurrent calls each get their own
+	config := wazero.NewModuleConfig().WithName("").WithStartFunctions()
+	mod, err := m.runtime.InstantiateModule(ctx, m.compiled, config)
+	if err != nil {
+		return nil, err
+	}
+	defer mod.Close(ctx)
+
+	results, err := mod.ExportedFunction(wasmExportAlloc).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("alloc: %w", err)
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, input) {
+		return nil, fmt.Errorf("%w: alloc returned a buffer outside memory", ErrWASMABI)
+	}
+
+	results, err = mod.ExportedFunction(wasmExportCall).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("call: %w", err)
+	}
+	outPtr, outLen := uint32(results[0]>>32), uint32(results[0])
+	output, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("%w: call returned a buffer outside memory", ErrWASMABI)
+	}
+
+	// The view is only valid until the instance is closed
+	return append([]byte(nil), output...), nil
+}
+
+// Close releases the compiled module
+func (m *wazeroModule) Close(ctx context.Context) error {
+	return m.compiled.Close(ctx)
+}
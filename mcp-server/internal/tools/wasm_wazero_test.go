@@ -0,0 +1,104 @@
+//go:build wazero
+
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Hand-assembled call bodies for toolModule
+var (
+	// echoCall returns the input buffer: (ptr << 32) | len
+	echoCall = []byte{0x00, 0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b}
+	// spinCall loops forever
+	spinCall = []byte{0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x00, 0x0b}
+)
+
+// toolModule assembles a module implementing the tool ABI with the given minimum memory
+// pages and call body; alloc always returns offset 1024
+func toolModule(pages byte, callBody []byte) []byte {
+	return assembleModule(pages, wasmExportCall, callBody)
+}
+
+// assembleModule assembles a tool module exporting its call function as callName
+func assembleModule(pages byte, callName string, callBody []byte) []byte {
+	alloc := []byte{0x00, 0x41, 0x80, 0x08, 0x0b}
+	section := func(id byte, contents ...byte) []byte {
+		return append([]byte{id, byte(len(contents))}, contents...)
+	}
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+
+	exports := []byte{0x03}
+	exports = append(append(exports, name(wasmExportMemory)...), 0x02, 0x00)
+	exports = append(append(exports, name(wasmExportAlloc)...), 0x00, 0x00)
+	exports = append(append(exports, name(callName)...), 0x00, 0x01)
+
+	code := []byte{0x02, byte(len(alloc))}
+	code = append(append(code, alloc...), byte(len(callBody)))
+	code = append(code, callBody...)
+
+	module := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(0x01, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e)...)
+	module = append(module, section(0x03, 0x02, 0x00, 0x01)...)
+	module = append(module, section(0x05, 0x01, 0x00, pages)...)
+	module = append(module, section(0x07, exports...)...)
+	return append(module, section(0x0a, code...)...)
+}
+
+func newTestWASMRuntime(t *testing.T, limits WASMLimits) WASMRuntime {
+	t.Helper()
+	runtime, err := NewWASMRuntime(context.Background(), limits)
+	require.NoError(t, err)
+	t.Cleanup(func() { runtime.Close(context.Background()) })
+	return runtime
+}
+
+func TestWazeroRuntime_Call(t *testing.T) {
+	ctx := context.Background()
+	runtime := newTestWASMRuntime(t, DefaultWASMLimits())
+
+	module, err := runtime.Compile(ctx, toolModule(1, echoCall))
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	output, err := module.Call(ctx, []byte(`{"query":"hello"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"query":"hello"}`, string(output))
+}
+
+func TestWazeroRuntime_Compile_ABI(t *testing.T) {
+	ctx := context.Background()
+	runtime := newTestWASMRuntime(t, DefaultWASMLimits())
+
+	_, err := runtime.Compile(ctx, []byte("not wasm"))
+	assert.ErrorIs(t, err, ErrWASMABI)
+
+	// A module without the call export does not implement the ABI
+	_, err = runtime.Compile(ctx, assembleModule(1, "run", echoCall))
+	assert.ErrorIs(t, err, ErrWASMABI)
+}
+
+func TestWazeroRuntime_MemoryLimit(t *testing.T) {
+	limits := DefaultWASMLimits()
+	limits.MaxMemoryPages = 1
+	runtime := newTestWASMRuntime(t, limits)
+
+	_, err := runtime.Compile(context.Background(), toolModule(2, echoCall))
+	assert.Error(t, err)
+}
+
+func TestWazeroRuntime_CallStopsWithContext(t *testing.T) {
+	runtime := newTestWASMRuntime(t, DefaultWASMLimits())
+	module, err := runtime.Compile(context.Background(), toolModule(1, spinCall))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = module.Call(ctx, []byte("{}"))
+	assert.Error(t, err)
+}
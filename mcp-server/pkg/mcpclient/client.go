@@ -0,0 +1,285 @@
+// Package mcpclient is a Go client for MCP servers that speak JSON-RPC over HTTP, such as
+// this repository's mcp-server. It manages request IDs, the initialize handshake and
+// session, retries of refused requests, trace propagation, and the notification stream.
+//
+//	client := mcpclient.New(mcpclient.Config{URL: "http://localhost:8080/mcp", Token: token})
+//	if _, err := client.Initialize(ctx); err != nil { ... }
+//	result, err := client.CallTool(ctx, "hybrid_search", map[string]interface{}{"query": "mTLS"})
+package mcpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProtocolVersion is the MCP protocol version the client negotiates
+const ProtocolVersion = "2024-11-05"
+
+// SessionHeader carries the session ID returned by initialize
+const SessionHeader = "Mcp-Session-Id"
+
+// Client defaults
+const (
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 200 * time.Millisecond
+	DefaultClientName   = "mcpclient"
+)
+
+// maxResponseBytes bounds the responses read from the server
+const maxResponseBytes = 16 << 20
+
+// maxRetryAfter is the longest Retry-After the client waits for; longer ones fail the call
+const maxRetryAfter = 30 * time.Second
+
+// tracerName names the client spans
+const tracerName = "github.com/bhatti/mcp-a2a-go/mcp-server/pkg/mcpclient"
+
+// Config configures a client for one MCP server
+type Config struct {
+	// URL is the server's JSON-RPC endpoint, e.g. http://mcp-server:8080/mcp
+	URL string
+	// Token, when set, is sent as a bearer token; WithToken overrides it per call
+	Token string
+	// Timeout bounds each HTTP request, except the notification stream; zero means no limit
+	Timeout time.Duration
+	// MaxRetries is how often a request is retried after a connection error or a 429, 502
+	// or 503 response; zero uses DefaultMaxRetries and a negative value disables retries
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for each later one; a
+	// Retry-After header takes precedence
+	RetryBackoff time.Duration
+	// ClientName and ClientVersion are sent in clientInfo during initialize
+	ClientName    string
+	ClientVersion string
+	// HTTPClient sends the requests; nil uses a client with Timeout
+	HTTPClient *http.Client
+}
+
+// Error is a JSON-RPC error returned by the server
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// StatusError is returned when the server answers without a JSON-RPC response
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("MCP server returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// tokenKey is the context key of a per-call bearer token
+type tokenKey struct{}
+
+// WithToken returns a context whose calls authenticate with token instead of Config.Token,
+// e.g. to forward the token of the user a service acts for
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// Client calls an MCP server. It is safe for concurrent use.
+type Client struct {
+	config Config
+	client *http.Client
+	nextID atomic.Int64
+
+	mu        sync.Mutex
+	server    *InitializeResult
+	sessionID string
+}
+
+// New creates a client for the server at config.URL
+func New(config Config) *Client {
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultRetryBackoff
+	}
+	if config.ClientName == "" {
+		config.ClientName = DefaultClientName
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+	return &Client{config: config, client: client}
+}
+
+// SessionID returns the session the server started during initialize, or "" if it did not start one
+func (c *Client) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+// Server returns the result of the last initialize, or nil before it
+func (c *Client) Server() *InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.server
+}
+
+// Call sends a JSON-RPC request and decodes its result into out, which may be nil.
+// JSON-RPC errors are returned as *Error, other failed responses as *StatusError.
+func (c *Client) Call(ctx context.Context, method string, params, out interface{}) error {
+	_, err := c.call(ctx, method, params, out)
+	return err
+}
+
+// call sends a request with retries and returns the final HTTP response headers
+func (c *Client) call(ctx context.Context, method string, params, out interface{}) (header http.Header, err error) {
+	id := c.nextID.Add(1)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "mcp."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method),
+			attribute.Int64("rpc.jsonrpc.request_id", id),
+		))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		header, retryAfter, err := c.send(ctx, id, body, out)
+		if retryAfter < 0 || attempt >= c.config.MaxRetries {
+			return header, err
+		}
+		if retryAfter == 0 {
+			retryAfter = c.config.RetryBackoff << attempt
+		}
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt+1), attribute.String("error", err.Error())))
+		select {
+		case <-ctx.Done():
+			return header, err
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// send posts one request. retryAfter is negative when the request must not be retried,
+// positive when the server asked for a delay and zero for the default backoff.
+func (c *Client) send(ctx context.Context, id int64, body []byte, out interface{}) (header http.Header, retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(ctx, req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, -1, err
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.Header, 0, fmt.Errorf("failed to read MCP response: %w", err)
+	}
+
+	var rpc struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(data, &rpc); err != nil || (rpc.Error == nil && rpc.Result == nil) {
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(data))}
+		return resp.Header, retryDelay(resp), statusErr
+	}
+	if rpc.Error != nil {
+		// Of the JSON-RPC errors only rate limiting clears up by itself; safe mode and
+		// the like would only be hit again
+		if rpc.Error.Code != CodeRateLimitExceeded {
+			return resp.Header, -1, rpc.Error
+		}
+		return resp.Header, retryDelay(resp), rpc.Error
+	}
+	if string(rpc.ID) != strconv.FormatInt(id, 10) {
+		return resp.Header, -1, fmt.Errorf("MCP response id %s does not match request id %d", rpc.ID, id)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rpc.Result, out); err != nil {
+			return resp.Header, -1, fmt.Errorf("invalid MCP result: %w", err)
+		}
+	}
+	return resp.Header, -1, nil
+}
+
+// setHeaders adds the bearer token, session ID and trace context to a request
+func (c *Client) setHeaders(ctx context.Context, req *http.Request) {
+	token, ok := ctx.Value(tokenKey{}).(string)
+	if !ok {
+		token = c.config.Token
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if sessionID := c.SessionID(); sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// retryDelay returns how long to wait before retrying a refused request, or -1 when the
+// response is final
+func retryDelay(resp *http.Response) time.Duration {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+	default:
+		return -1
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	switch {
+	case err != nil || seconds <= 0:
+		return 0
+	case time.Duration(seconds)*time.Second > maxRetryAfter:
+		return -1
+	default:
+		return time.Duration(seconds) * time.Second
+	}
+}
+
+// ErrNoSession is returned by calls that need the session initialize starts
+var ErrNoSession = errors.New("no MCP session: call Initialize first; the server starts sessions for authenticated clients only")
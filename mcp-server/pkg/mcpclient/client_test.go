@@ -0,0 +1,282 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/auth"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/resources"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/server"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/sessions"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/storage"
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newMCPServer serves the real MCP handler; the bearer token "tenant-123" authenticates as that tenant
+func newMCPServer(t *testing.T) (*httptest.Server, *sessions.Hub, string) {
+	t.Helper()
+
+	store := storage.NewMemoryStore()
+	doc := &storage.Document{Title: "Security Policy", Content: "All services use mTLS. Keys rotate every 90 days."}
+	require.NoError(t, store.InsertDocument(context.Background(), "tenant-123", doc))
+
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register(tools.NewSearchTool(store))
+	resourceRegistry := resources.NewRegistry()
+	resourceRegistry.Register(resources.NewSummaryProvider(store))
+	resourceRegistry.Register(resources.NewDocumentProvider(store))
+	hub := sessions.NewHub(sessions.Config{})
+
+	handler := server.NewMCPHandler(toolRegistry, nil)
+	handler.SetResourceRegistry(resourceRegistry)
+	handler.SetSessions(hub)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != "" {
+			r = r.WithContext(auth.WithAuth(r.Context(), &auth.Claims{TenantID: token, UserID: "user-1"}))
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hub, doc.ID
+}
+
+func TestClient(t *testing.T) {
+	srv, hub, docID := newMCPServer(t)
+	ctx := context.Background()
+	client := New(Config{URL: srv.URL, Token: "tenant-123", ClientName: "test"})
+
+	initResult, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "mcp-rag-server", initResult.ServerInfo.Name)
+	assert.True(t, initResult.Capabilities.Resources.Subscribe)
+	assert.NotEmpty(t, client.SessionID())
+	assert.Same(t, initResult, client.Server())
+
+	toolList, err := client.ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, toolList, 1)
+	assert.Equal(t, "search_documents", toolList[0].Name)
+
+	result, err := client.CallTool(ctx, "search_documents", map[string]interface{}{"query": "mTLS"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, Text(result), "Security Policy")
+
+	_, err = client.CallTool(ctx, "missing_tool", nil)
+	var rpcErr *Error
+	require.True(t, errors.As(err, &rpcErr), err)
+	assert.Contains(t, rpcErr.Message, "missing_tool")
+
+	listed, err := client.ListResources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "documents-summary://", listed[0].URI)
+	contents, err := client.ReadResource(ctx, "documents://"+docID)
+	require.NoError(t, err)
+	assert.Contains(t, contents[0].Text, "Keys rotate")
+	_, err = client.ReadResource(ctx, "documents://missing")
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, CodeResourceNotFound, rpcErr.Code)
+
+	require.NoError(t, client.Subscribe(ctx, "documents://"+docID))
+	stream, err := client.OpenStream(ctx)
+	require.NoError(t, err)
+	hub.NotifyToolsListChanged("")
+	hub.NotifyResourceUpdated("tenant-123", resources.DocumentURIs(docID)...)
+
+	var received []Notification
+	for len(received) < 2 {
+		select {
+		case notification := <-stream.Notifications():
+			received = append(received, notification)
+		case <-time.After(5 * time.Second):
+			t.Fatal("notifications were not delivered")
+		}
+	}
+	assert.Equal(t, NotificationToolsListChanged, received[0].Method)
+	assert.Equal(t, "documents://"+docID, received[1].ResourceURI())
+
+	require.NoError(t, stream.Close())
+	_, open := <-stream.Notifications()
+	assert.False(t, open)
+	assert.NoError(t, stream.Err())
+
+	require.NoError(t, client.Close(ctx))
+	assert.Empty(t, client.SessionID())
+	assert.ErrorIs(t, client.Subscribe(ctx, "documents://"+docID), ErrNoSession)
+}
+
+func TestClient_AnonymousHasNoSession(t *testing.T) {
+	srv, _, _ := newMCPServer(t)
+	ctx := context.Background()
+	client := New(Config{URL: srv.URL})
+
+	_, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, client.SessionID())
+	_, err = client.OpenStream(ctx)
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	_, err = client.ListResources(ctx)
+	var rpcErr *Error
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, CodeAuthenticationRequired, rpcErr.Code)
+
+	// A per-call token overrides the configured one
+	_, err = client.ListResources(WithToken(ctx, "tenant-123"))
+	assert.NoError(t, err)
+}
+
+func TestClient_ReadContinuation(t *testing.T) {
+	store := storage.NewMemoryStore()
+	doc := &storage.Document{Title: "Runbook", Content: strings.Repeat("Restart the worker. ", 40)}
+	require.NoError(t, store.InsertDocument(context.Background(), "tenant-123", doc))
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register(tools.NewRetrieveTool(store))
+	truncator := tools.NewTruncator(300, time.Minute)
+	toolRegistry.Register(tools.NewContinuationTool(truncator))
+	handler := server.NewMCPHandler(toolRegistry, nil)
+	handler.SetTruncator(truncator)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(auth.WithAuth(r.Context(), &auth.Claims{TenantID: "tenant-123", UserID: "user-1"})))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client := New(Config{URL: srv.URL})
+
+	result, err := client.CallTool(ctx, "retrieve_document", map[string]interface{}{"document_id": doc.ID})
+	require.NoError(t, err)
+	require.True(t, result.IsTruncated)
+	var text strings.Builder
+	for result.IsTruncated {
+		text.WriteString(result.Content[0].Text)
+		token := result.ContinuationToken
+		result, err = client.ReadContinuation(ctx, token)
+		require.NoError(t, err)
+
+		// Tokens are read once
+		_, err = client.ReadContinuation(ctx, token)
+		var rpcErr *Error
+		require.True(t, errors.As(err, &rpcErr), err)
+		assert.Contains(t, rpcErr.Message, "continuation not found")
+	}
+	text.WriteString(Text(result))
+	assert.Contains(t, text.String(), doc.Content)
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		retryAfter   string
+		wantAttempts int32
+	}{
+		{"draining server", http.StatusServiceUnavailable, "Server is shutting down", "", 3},
+		{"rate limited", http.StatusTooManyRequests, `{"jsonrpc":"2.0","error":{"code":-32003,"message":"Rate limit exceeded"}}`, "1", 3},
+		{"safe mode", http.StatusServiceUnavailable, `{"jsonrpc":"2.0","error":{"code":-32007,"message":"safe mode"}}`, "", 1},
+		{"long retry after", http.StatusServiceUnavailable, "Safe mode", "300", 1},
+		{"bad request", http.StatusBadRequest, "bad", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client := New(Config{URL: srv.URL, RetryBackoff: time.Millisecond})
+			err := client.Call(context.Background(), "tools/list", nil, nil)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestClient_RetriesUntilSuccess(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, req.ID)
+	}))
+	defer srv.Close()
+
+	client := New(Config{URL: srv.URL, RetryBackoff: time.Millisecond})
+	toolList, err := client.ListTools(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, toolList)
+	assert.Equal(t, int32(2), attempts.Load())
+
+	// Retries can be disabled
+	attempts.Store(0)
+	client = New(Config{URL: srv.URL, MaxRetries: -1})
+	_, err = client.ListTools(context.Background())
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+}
+
+func TestClient_RejectsMismatchedResponseID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":999,"result":{}}`)
+	}))
+	defer srv.Close()
+
+	err := New(Config{URL: srv.URL}).Call(context.Background(), "tools/list", nil, nil)
+	assert.ErrorContains(t, err, "does not match request id")
+}
+
+func TestClient_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`)
+	}))
+	defer srv.Close()
+
+	_, err := New(Config{URL: srv.URL}).ListTools(context.Background())
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "mcp.tools/list", spans[0].Name())
+	assert.Contains(t, traceparent, spans[0].SpanContext().TraceID().String())
+}
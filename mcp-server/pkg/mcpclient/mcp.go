@@ -0,0 +1,171 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// MCP types, shared with the server so both sides stay in step
+type (
+	InitializeResult   = protocol.InitializeResult
+	ServerCapabilities = protocol.ServerCapabilities
+	Tool               = protocol.Tool
+	ToolCallResult     = protocol.ToolCallResult
+	ContentBlock       = protocol.ContentBlock
+	Resource           = protocol.Resource
+	ResourceContents   = protocol.ResourceContents
+)
+
+// JSON-RPC error codes the server returns
+const (
+	CodeInvalidParams          = protocol.InvalidParams
+	CodeMethodNotFound         = protocol.MethodNotFound
+	CodeAuthenticationRequired = protocol.AuthenticationRequired
+	CodeAuthorizationFailed    = protocol.AuthorizationFailed
+	CodeRateLimitExceeded      = protocol.RateLimitExceeded
+	CodeResourceNotFound       = protocol.ResourceNotFound
+	CodeRequestTimeout         = protocol.RequestTimeout
+	CodeSafeModeActive         = protocol.SafeModeActive
+	CodeRequestTooLarge        = protocol.RequestTooLarge
+	CodeQuotaExceeded          = protocol.QuotaExceeded
+)
+
+// Initialize runs the initialize handshake, checks the protocol version and keeps the
+// session the server starts for authenticated clients. Calling it again starts a new session.
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	params := protocol.InitializeRequest{
+		ProtocolVersion: ProtocolVersion,
+		ClientInfo:      protocol.ClientInfo{Name: c.config.ClientName, Version: c.config.ClientVersion},
+	}
+
+	c.mu.Lock()
+	c.sessionID = ""
+	c.mu.Unlock()
+
+	var result InitializeResult
+	header, err := c.call(ctx, protocol.MethodInitialize, params, &result)
+	if err != nil {
+		return nil, fmt.Errorf("MCP initialize: %w", err)
+	}
+	if result.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("MCP server speaks protocol %q, want %q", result.ProtocolVersion, ProtocolVersion)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.server = &result
+	c.sessionID = header.Get(SessionHeader)
+	return &result, nil
+}
+
+// ListTools returns the tools the caller can use
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var result protocol.ToolsListResult
+	if err := c.Call(ctx, protocol.MethodToolsList, nil, &result); err != nil {
+		return nil, fmt.Errorf("MCP tools/list: %w", err)
+	}
+	return result.Tools, nil
+}
+
+// CallTool calls a tool. A tool that ran but failed returns a result with IsError set
+// and a nil error; use Text for its message.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*ToolCallResult, error) {
+	var result ToolCallResult
+	params := protocol.ToolCallRequest{Name: name, Arguments: args}
+	if err := c.Call(ctx, protocol.MethodToolsCall, params, &result); err != nil {
+		return nil, fmt.Errorf("MCP tool %s: %w", name, err)
+	}
+	return &result, nil
+}
+
+// continuationTool is the tool serving the rest of truncated results
+const continuationTool = "read_continuation"
+
+// ReadContinuation returns the next part of a result the server truncated, given its
+// ContinuationToken. Each token can be read once; the part returned may itself be truncated.
+func (c *Client) ReadContinuation(ctx context.Context, token string) (*ToolCallResult, error) {
+	return c.CallTool(ctx, continuationTool, map[string]interface{}{"continuation_token": token})
+}
+
+// ListResources returns the resources the server advertises
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var result protocol.ResourcesListResult
+	if err := c.Call(ctx, protocol.MethodResourcesList, nil, &result); err != nil {
+		return nil, fmt.Errorf("MCP resources/list: %w", err)
+	}
+	return result.Resources, nil
+}
+
+// ReadResource returns the contents of the resource at uri
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	var result protocol.ResourceReadResult
+	params := protocol.ResourceReadRequest{URI: uri}
+	if err := c.Call(ctx, protocol.MethodResourcesRead, params, &result); err != nil {
+		return nil, fmt.Errorf("MCP resource %s: %w", uri, err)
+	}
+	return result.Contents, nil
+}
+
+// Subscribe watches a resource; changes arrive on the notification stream as
+// notifications/resources/updated. It needs the session started by Initialize.
+func (c *Client) Subscribe(ctx context.Context, uri string) error {
+	return c.subscription(ctx, protocol.MethodResourcesSubscribe, uri)
+}
+
+// Unsubscribe stops watching a resource
+func (c *Client) Unsubscribe(ctx context.Context, uri string) error {
+	return c.subscription(ctx, protocol.MethodResourcesUnsubscribe, uri)
+}
+
+func (c *Client) subscription(ctx context.Context, method, uri string) error {
+	if c.SessionID() == "" {
+		return ErrNoSession
+	}
+	if err := c.Call(ctx, method, protocol.ResourceSubscribeRequest{URI: uri}, nil); err != nil {
+		return fmt.Errorf("MCP %s %s: %w", method, uri, err)
+	}
+	return nil
+}
+
+// Close ends the session started by Initialize, if any
+func (c *Client) Close(ctx context.Context) error {
+	sessionID := c.SessionID()
+	if sessionID == "" {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.config.URL, nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(ctx, req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("MCP session close: %w", err)
+	}
+	resp.Body.Close()
+
+	c.mu.Lock()
+	if c.sessionID == sessionID {
+		c.sessionID = ""
+	}
+	c.mu.Unlock()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("MCP session close: %w", &StatusError{StatusCode: resp.StatusCode})
+	}
+	return nil
+}
+
+// Text returns the text of a tool result's text content blocks, joined by newlines
+func Text(result *ToolCallResult) string {
+	var texts []string
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,152 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bhatti/mcp-a2a-go/mcp-server/internal/protocol"
+)
+
+// Notification methods the server sends on the stream
+const (
+	NotificationResourceUpdated  = protocol.MethodResourcesUpdated
+	NotificationToolsListChanged = protocol.MethodToolsListChanged
+)
+
+// ErrStreamClosed is the Err of a stream the server ended, e.g. while shutting down;
+// open a new one to keep receiving notifications
+var ErrStreamClosed = errors.New("MCP stream closed by the server")
+
+// Notification is a JSON-RPC notification received on the stream
+type Notification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// ResourceURI returns the URI of a notifications/resources/updated notification, or ""
+func (n Notification) ResourceURI() string {
+	var params protocol.ResourceUpdatedNotification
+	if n.Method != NotificationResourceUpdated || json.Unmarshal(n.Params, &params) != nil {
+		return ""
+	}
+	return params.URI
+}
+
+// Stream receives the notifications of the client's session
+type Stream struct {
+	notifications chan Notification
+	body          io.ReadCloser
+	cancel        context.CancelFunc
+
+	mu  sync.Mutex
+	err error
+}
+
+// OpenStream opens the session's notification stream. The server allows one stream per
+// session; it ends when ctx is done, Close is called or the server closes it.
+func (c *Client) OpenStream(ctx context.Context) (*Stream, error) {
+	if c.SessionID() == "" {
+		return nil, ErrNoSession
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.URL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.setHeaders(ctx, req)
+
+	// The stream outlives any request timeout
+	client := *c.client
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("MCP stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		defer cancel()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("MCP stream: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(data))})
+	}
+
+	stream := &Stream{notifications: make(chan Notification), body: resp.Body, cancel: cancel}
+	go stream.read(ctx)
+	return stream, nil
+}
+
+// Notifications returns the channel notifications arrive on; it is closed when the stream ends
+func (s *Stream) Notifications() <-chan Notification {
+	return s.notifications
+}
+
+// Err returns why the stream ended, or nil if it was closed by the client or still runs
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close ends the stream
+func (s *Stream) Close() error {
+	s.cancel()
+	return s.body.Close()
+}
+
+// read parses SSE events until the stream ends. Only data lines are used; comments such
+// as keep-alives are skipped.
+func (s *Stream) read(ctx context.Context) {
+	defer close(s.notifications)
+	defer s.body.Close()
+
+	scanner := bufio.NewScanner(s.body)
+	scanner.Buffer(make([]byte, 64<<10), maxResponseBytes)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		var notification Notification
+		err := json.Unmarshal([]byte(data.String()), &notification)
+		data.Reset()
+		if err != nil {
+			s.fail(fmt.Errorf("invalid MCP notification: %w", err))
+			return
+		}
+		select {
+		case s.notifications <- notification:
+		case <-ctx.Done():
+			return
+		}
+	}
+	switch err := scanner.Err(); {
+	case ctx.Err() != nil:
+	case err != nil:
+		s.fail(fmt.Errorf("MCP stream: %w", err))
+	default:
+		s.fail(ErrStreamClosed)
+	}
+}
+
+func (s *Stream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
@@ -0,0 +1,5 @@
+-- Script to add per-task cost caps of A2A tasks to an existing database
+-- (new databases get it from init-db.sql). Tasks created before it have no cap.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS max_cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Script to record the price table version of A2A usage records in an existing database
+-- (new databases get it from init-db.sql). Records written before it have an empty version.
+
+ALTER TABLE usage_records
+    ADD COLUMN IF NOT EXISTS price_version VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Script to add the artifacts of A2A tasks to an existing database
+-- (new databases get it from init-db.sql). Tasks completed before it have none.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS artifacts JSONB;
@@ -0,0 +1,7 @@
+-- Script to add the dependencies of A2A tasks to an existing database
+-- (new databases get it from init-db.sql). Tasks created before it have none.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS depends_on TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_tasks_depends_on ON tasks USING GIN (depends_on);
@@ -0,0 +1,7 @@
+-- Script to add the priority of A2A tasks to an existing database
+-- (new databases get it from init-db.sql). Tasks created before it run at normal priority.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_pending_priority ON tasks(priority, created_at) WHERE state = 'pending';
@@ -0,0 +1,6 @@
+-- Script to add the trace context of A2A tasks to an existing database
+-- (new databases get it from init-db.sql). Tasks created before it have none and
+-- start a new trace when they run.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS trace_context JSONB;
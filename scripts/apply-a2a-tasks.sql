@@ -0,0 +1,27 @@
+-- Script to add the A2A server's task table to an existing database
+-- (new databases get it from init-db.sql). Used when the A2A server runs with TASK_STORE=postgres.
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id VARCHAR(64) PRIMARY KEY,
+    agent_id VARCHAR(255) NOT NULL,
+    context_id VARCHAR(255) NOT NULL DEFAULT '',
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    capability VARCHAR(255) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    input JSONB,
+    result JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    input_hash VARCHAR(100) NOT NULL DEFAULT '',
+    result_hash VARCHAR(100) NOT NULL DEFAULT '',
+    speculative BOOLEAN NOT NULL DEFAULT false,
+    speculation JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_state ON tasks(state, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_user ON tasks(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_agent ON tasks(agent_id, created_at);
+
+GRANT ALL PRIVILEGES ON tasks TO app_user;
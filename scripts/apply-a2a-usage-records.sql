@@ -0,0 +1,22 @@
+-- Script to add the A2A server's usage records table to an existing database
+-- (new databases get it from init-db.sql). Used when the A2A server runs with COST_STORE=postgres.
+
+CREATE TABLE IF NOT EXISTS usage_records (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    task_id VARCHAR(64) NOT NULL DEFAULT '',
+    capability VARCHAR(255) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    price_version VARCHAR(100) NOT NULL DEFAULT '',
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_user ON usage_records(user_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_recorded_at ON usage_records(recorded_at);
+
+GRANT ALL PRIVILEGES ON usage_records TO app_user;
+GRANT USAGE, SELECT ON SEQUENCE usage_records_id_seq TO app_user;
@@ -0,0 +1,30 @@
+-- Script to add the tool call audit log to an existing database
+-- (new databases get it from init-db.sql)
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255),
+    tool VARCHAR(100) NOT NULL,
+    args_digest VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log(tenant_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tool ON audit_log(tenant_id, tool, created_at DESC);
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON audit_log;
+CREATE POLICY tenant_isolation_policy ON audit_log
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Audit entries are append-only for the application
+GRANT SELECT, INSERT, DELETE ON audit_log TO app_user;
+GRANT USAGE, SELECT ON SEQUENCE audit_log_id_seq TO app_user;
+REVOKE UPDATE ON audit_log FROM app_user;
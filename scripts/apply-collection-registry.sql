@@ -0,0 +1,24 @@
+-- Script to add the collection registry to an existing database
+-- (new databases get it from init-db.sql). Apply scripts/apply-collections.sql first.
+-- Existing collections stay unregistered until PUT /admin/collections/{name}.
+
+CREATE TABLE IF NOT EXISTS collections (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,  -- Matches documents.collection
+    description TEXT NOT NULL DEFAULT '',
+    embedding_model VARCHAR(255) NOT NULL DEFAULT '',
+    embedding_dimensions INTEGER NOT NULL DEFAULT 0,  -- 0 accepts embeddings of any size
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, name)
+);
+
+ALTER TABLE collections ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON collections;
+CREATE POLICY tenant_isolation_policy ON collections
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+GRANT ALL PRIVILEGES ON collections TO app_user;
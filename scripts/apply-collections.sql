@@ -0,0 +1,9 @@
+-- Script to add named document collections to an existing database
+-- (new databases get them from init-db.sql). Existing documents land in the
+-- "default" collection.
+
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS collection VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Listing, searching and quota counts are scoped to a tenant's collection
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_collection ON documents(tenant_id, collection, created_at DESC);
@@ -0,0 +1,28 @@
+-- Script to add the document event outbox to an existing database
+-- (new databases get it from init-db.sql)
+
+-- Outbox of document lifecycle events, written in the transaction that changes the
+-- document and relayed to the event stream (see DOCUMENT_OUTBOX_ENABLED)
+CREATE TABLE IF NOT EXISTS document_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,  -- No foreign key: deleted documents keep their events
+    collection VARCHAR(64) NOT NULL,
+    event_type VARCHAR(16) NOT NULL CHECK (event_type IN ('created', 'updated', 'deleted')),
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_events_pending ON document_events(tenant_id, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_document_events_published ON document_events(tenant_id, published_at) WHERE published_at IS NOT NULL;
+
+ALTER TABLE document_events ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON document_events;
+CREATE POLICY tenant_isolation_policy ON document_events
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+GRANT ALL PRIVILEGES ON document_events TO app_user;
+GRANT USAGE, SELECT ON SEQUENCE document_events_id_seq TO app_user;
@@ -0,0 +1,36 @@
+-- Script to add embedding consistency tracking to an existing database
+-- (new databases get it from init-db.sql)
+
+-- Hash of the text each embedding was generated from
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS content_hash TEXT GENERATED ALWAYS AS (md5(title || E'\n' || content)) STORED,
+    ADD COLUMN IF NOT EXISTS embedding_hash TEXT,
+    ADD COLUMN IF NOT EXISTS embedding_stale_at TIMESTAMP WITH TIME ZONE;
+
+-- Existing embeddings are assumed to match the current content
+UPDATE documents SET embedding_hash = content_hash
+WHERE embedding IS NOT NULL AND embedding_hash IS NULL;
+
+-- Queue of documents whose embedding must be regenerated
+CREATE TABLE IF NOT EXISTS embedding_queue (
+    document_id UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    queued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_queue_tenant ON embedding_queue(tenant_id, queued_at);
+
+ALTER TABLE embedding_queue ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON embedding_queue;
+CREATE POLICY tenant_isolation_policy ON embedding_queue
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+GRANT ALL PRIVILEGES ON embedding_queue TO app_user;
+
+-- Only content changes bump updated_at, not the checker's bookkeeping
+DROP TRIGGER IF EXISTS update_documents_updated_at ON documents;
+CREATE TRIGGER update_documents_updated_at BEFORE UPDATE OF title, content, metadata, embedding ON documents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
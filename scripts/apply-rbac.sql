@@ -0,0 +1,21 @@
+-- Script to add tenant role assignments to an existing database
+-- (new databases get them from init-db.sql)
+
+CREATE TABLE IF NOT EXISTS tenant_role_assignments (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    assigned_by VARCHAR(255),
+    assigned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+ALTER TABLE tenant_role_assignments ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation_policy ON tenant_role_assignments;
+CREATE POLICY tenant_isolation_policy ON tenant_role_assignments
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+GRANT ALL PRIVILEGES ON tenant_role_assignments TO app_user;
@@ -0,0 +1,15 @@
+-- Script to add quantized embedding columns to an existing database
+-- (new databases get them from init-db.sql). Requires pgvector 0.7 or later.
+--
+-- Rollout: apply this script, set database.quantization.dual_write (or
+-- VECTOR_QUANTIZATION_DUAL_WRITE=true) so writes fill the new columns and the embedding
+-- consistency checker backfills existing rows, then set database.quantization.search
+-- (VECTOR_QUANTIZATION_SEARCH) to halfvec or bit.
+
+-- 16-bit float copy (half the storage) and binary quantization (1 bit per dimension)
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS embedding_half halfvec(1536),
+    ADD COLUMN IF NOT EXISTS embedding_bit bit(1536);
+
+CREATE INDEX IF NOT EXISTS idx_documents_embedding_half ON documents USING hnsw (embedding_half halfvec_cosine_ops);
+CREATE INDEX IF NOT EXISTS idx_documents_embedding_bit ON documents USING hnsw (embedding_bit bit_hamming_ops);
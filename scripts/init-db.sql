@@ -1,4 +1,6 @@
 -- Initialize database with pgvector extension and multi-tenant schema
+-- The servers' embedded migrations (internal/migrations) adopt this schema; add new
+-- schema changes there rather than here
 
 -- Enable pgvector extension
 CREATE EXTENSION IF NOT EXISTS vector;
@@ -11,13 +13,15 @@ CREATE TABLE IF NOT EXISTS tenants (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     is_active BOOLEAN DEFAULT TRUE,
-    settings JSONB DEFAULT '{}'::jsonb
+    settings JSONB DEFAULT '{}'::jsonb,
+    data_region VARCHAR(64) NOT NULL DEFAULT 'default'  -- Where the tenant's data must be stored
 );
 
 -- Create documents table with tenant isolation
 CREATE TABLE IF NOT EXISTS documents (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    collection VARCHAR(64) NOT NULL DEFAULT 'default',  -- Named corpus of the tenant, e.g. policies or tickets
     title TEXT NOT NULL,
     content TEXT NOT NULL,
     metadata JSONB DEFAULT '{}'::jsonb,
@@ -25,6 +29,11 @@ CREATE TABLE IF NOT EXISTS documents (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     created_by VARCHAR(255),
+    content_hash TEXT GENERATED ALWAYS AS (md5(title || E'\n' || content)) STORED,
+    embedding_hash TEXT,  -- content_hash of the text the embedding was generated from
+    embedding_stale_at TIMESTAMP WITH TIME ZONE,  -- Set by the consistency checker when content changed after embedding
+    embedding_half halfvec(1536),  -- 16-bit copy of embedding, written when quantization dual-write is on
+    embedding_bit bit(1536),  -- Binary quantized embedding, written with embedding_half
     CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id)
 );
 
@@ -32,9 +41,16 @@ CREATE TABLE IF NOT EXISTS documents (
 CREATE INDEX IF NOT EXISTS idx_documents_embedding ON documents USING ivfflat (embedding vector_cosine_ops)
 WITH (lists = 100);
 
+-- HNSW indexes over the quantized embeddings; searches re-rank their candidates by embedding
+CREATE INDEX IF NOT EXISTS idx_documents_embedding_half ON documents USING hnsw (embedding_half halfvec_cosine_ops);
+CREATE INDEX IF NOT EXISTS idx_documents_embedding_bit ON documents USING hnsw (embedding_bit bit_hamming_ops);
+
 -- Create index on tenant_id for efficient filtering
 CREATE INDEX IF NOT EXISTS idx_documents_tenant_id ON documents(tenant_id);
 
+-- Listing, searching and quota counts are scoped to a tenant's collection
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_collection ON documents(tenant_id, collection, created_at DESC);
+
 -- Create index on metadata for JSON queries
 CREATE INDEX IF NOT EXISTS idx_documents_metadata ON documents USING gin(metadata);
 
@@ -50,6 +66,26 @@ CREATE POLICY tenant_isolation_policy ON documents
     USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
     WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
 
+-- Registered collections: the embedding model and dimensions their documents and
+-- queries must use. Collections also exist implicitly through their documents.
+CREATE TABLE IF NOT EXISTS collections (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,  -- Matches documents.collection
+    description TEXT NOT NULL DEFAULT '',
+    embedding_model VARCHAR(255) NOT NULL DEFAULT '',
+    embedding_dimensions INTEGER NOT NULL DEFAULT 0,  -- 0 accepts embeddings of any size
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, name)
+);
+
+ALTER TABLE collections ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_policy ON collections
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
 -- Create usage tracking table for cost control
 CREATE TABLE IF NOT EXISTS usage_logs (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -69,9 +105,160 @@ CREATE TABLE IF NOT EXISTS usage_logs (
 CREATE INDEX IF NOT EXISTS idx_usage_logs_tenant_user ON usage_logs(tenant_id, user_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_usage_logs_created_at ON usage_logs(created_at DESC);
 
+-- Create document access log for "who viewed what" compliance reporting
+CREATE TABLE IF NOT EXISTS document_access_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+    user_id VARCHAR(255),
+    tool VARCHAR(100) NOT NULL,
+    accessed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_access_log_document ON document_access_log(tenant_id, document_id, accessed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_document_access_log_user ON document_access_log(tenant_id, user_id, accessed_at DESC);
+
+ALTER TABLE document_access_log ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_policy ON document_access_log
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Queue of documents whose embedding must be regenerated, filled by the embedding
+-- consistency checker and drained when a new embedding is written
+CREATE TABLE IF NOT EXISTS embedding_queue (
+    document_id UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    queued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_queue_tenant ON embedding_queue(tenant_id, queued_at);
+
+ALTER TABLE embedding_queue ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_policy ON embedding_queue
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Outbox of document lifecycle events, written in the transaction that changes the
+-- document and relayed to the event stream (see DOCUMENT_OUTBOX_ENABLED)
+CREATE TABLE IF NOT EXISTS document_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,  -- No foreign key: deleted documents keep their events
+    collection VARCHAR(64) NOT NULL,
+    event_type VARCHAR(16) NOT NULL CHECK (event_type IN ('created', 'updated', 'deleted')),
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_events_pending ON document_events(tenant_id, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_document_events_published ON document_events(tenant_id, published_at) WHERE published_at IS NOT NULL;
+
+ALTER TABLE document_events ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_policy ON document_events
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Audit log of every MCP tool call, kept as SOC2 evidence. Arguments are stored as a
+-- digest only; rows are removed by the retention job, and a user's rows are deleted or
+-- pseudonymized by their GDPR erasure requests.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255),
+    tool VARCHAR(100) NOT NULL,
+    args_digest VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log(tenant_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tool ON audit_log(tenant_id, tool, created_at DESC);
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_policy ON audit_log
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+-- Tasks of the A2A server when it runs with TASK_STORE=postgres. Not tenant scoped:
+-- the A2A server authorizes by user and agent itself.
+CREATE TABLE IF NOT EXISTS tasks (
+    id VARCHAR(64) PRIMARY KEY,
+    agent_id VARCHAR(255) NOT NULL,
+    context_id VARCHAR(255) NOT NULL DEFAULT '',
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    capability VARCHAR(255) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    input JSONB,
+    result JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    input_hash VARCHAR(100) NOT NULL DEFAULT '',
+    result_hash VARCHAR(100) NOT NULL DEFAULT '',
+    speculative BOOLEAN NOT NULL DEFAULT false,
+    speculation JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    trace_context JSONB,
+    priority VARCHAR(10) NOT NULL DEFAULT 'normal',
+    depends_on TEXT[],
+    max_cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    artifacts JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_state ON tasks(state, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_pending_priority ON tasks(priority, created_at) WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS idx_tasks_user ON tasks(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_agent ON tasks(agent_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_depends_on ON tasks USING GIN (depends_on);
+
+-- Usage records of the A2A server's cost tracker when it runs with COST_STORE=postgres
+CREATE TABLE IF NOT EXISTS usage_records (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    task_id VARCHAR(64) NOT NULL DEFAULT '',
+    capability VARCHAR(255) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    price_version VARCHAR(100) NOT NULL DEFAULT '',
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_user ON usage_records(user_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_recorded_at ON usage_records(recorded_at);
+
+-- Role assignments (viewer, editor, admin) granting scope bundles to users per tenant
+CREATE TABLE IF NOT EXISTS tenant_role_assignments (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    assigned_by VARCHAR(255),
+    assigned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+ALTER TABLE tenant_role_assignments ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_policy ON tenant_role_assignments
+    FOR ALL
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid)
+    WITH CHECK (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
 -- Insert demo tenants
 INSERT INTO tenants (id, name, settings) VALUES
-    ('11111111-1111-1111-1111-111111111111', 'acme-corp', '{"monthly_budget_usd": 1000, "rate_limit_per_minute": 100}'::jsonb),
+    ('11111111-1111-1111-1111-111111111111', 'acme-corp', '{"monthly_budget_usd": 1000, "rate_limit_per_minute": 100, "search_profiles": {"security-policies": {"name": "security-policies", "description": "Security policy lookups", "limit": 5, "filters": {"category": "security"}}}}'::jsonb),
     ('22222222-2222-2222-2222-222222222222', 'beta-inc', '{"monthly_budget_usd": 500, "rate_limit_per_minute": 50}'::jsonb),
     ('33333333-3333-3333-3333-333333333333', 'gamma-ltd', '{"monthly_budget_usd": 2000, "rate_limit_per_minute": 200}'::jsonb)
 ON CONFLICT (name) DO NOTHING;
@@ -149,8 +336,8 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
--- Create trigger to auto-update updated_at
-CREATE TRIGGER update_documents_updated_at BEFORE UPDATE ON documents
+-- Create trigger to auto-update updated_at (bookkeeping such as embedding_stale_at leaves it alone)
+CREATE TRIGGER update_documents_updated_at BEFORE UPDATE OF title, content, metadata, embedding ON documents
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
 CREATE TRIGGER update_tenants_updated_at BEFORE UPDATE ON tenants
@@ -173,6 +360,9 @@ GRANT USAGE ON SCHEMA public TO app_user;
 GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO app_user;
 GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO app_user;
 
+-- Audit entries are append-only for the application
+REVOKE UPDATE ON audit_log FROM app_user;
+
 -- Also grant to mcp_user for backward compatibility
 GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO mcp_user;
 GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO mcp_user;
@@ -23,6 +23,7 @@ export DB_SSLMODE=disable
 export REDIS_ADDR=localhost:6379
 export JAEGER_URL=http://localhost:14268/api/traces
 export RATE_LIMIT=100
+export DEV_MODE=true
 
 echo "Environment:"
 echo "  PORT=$PORT"
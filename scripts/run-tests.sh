@@ -64,6 +64,16 @@ echo "${GREEN}✓ Coverage report generated: a2a-server/coverage.html${NC}"
 
 cd ..
 
+# Run Go tests for the shared module
+echo ""
+echo "${YELLOW}Running shared module tests...${NC}"
+cd shared
+go test -v $RACE_FLAG ./... || {
+    echo "${RED}✗ Shared module tests failed${NC}"
+    exit 1
+}
+cd ..
+
 # Print overall summary
 echo ""
 echo "${GREEN}=== Test Summary ===${NC}"
@@ -0,0 +1,254 @@
+// Package auth validates the JWTs callers of the MCP and A2A servers present and carries
+// their claims in the request context, so both servers accept the same tokens.
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ContextKey is a custom type for context keys to avoid collisions
+type ContextKey string
+
+const (
+	// ContextKeyTenantID is the context key for tenant ID
+	ContextKeyTenantID ContextKey = "tenant_id"
+	// ContextKeyUserID is the context key for user ID
+	ContextKeyUserID ContextKey = "user_id"
+	// ContextKeyScopes is the context key for authorization scopes
+	ContextKeyScopes ContextKey = "scopes"
+)
+
+// Claims represents the JWT claims of MCP and A2A callers
+type Claims struct {
+	TenantID string   `json:"tenant_id"`
+	UserID   string   `json:"user_id"`
+	Email    string   `json:"email,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Role     string   `json:"role,omitempty"` // viewer, editor or admin; grants the role's scopes
+	jwt.RegisteredClaims
+}
+
+// JWTValidator validates JWT tokens
+type JWTValidator struct {
+	keys     KeyProvider
+	issuer   string
+	audience string
+}
+
+// Config holds JWT validator configuration
+type Config struct {
+	PublicKeyPEM string      // RSA or ECDSA public key(s) in PEM format
+	Keys         KeyProvider // Accepted keys, e.g. a KeyManager; takes precedence over PublicKeyPEM
+	Issuer       string      // Expected token issuer
+	Audience     string      // Expected token audience
+}
+
+// KeySet is a fixed set of verification keys
+type KeySet []VerificationKey
+
+// Keys implements KeyProvider
+func (s KeySet) Keys() []VerificationKey { return s }
+
+// NewJWTValidator creates a new JWT validator
+func NewJWTValidator(cfg Config) (*JWTValidator, error) {
+	keys := cfg.Keys
+	if keys == nil {
+		publicKeys, err := ParsePublicKeysPEM("", []byte(cfg.PublicKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		keys = KeySet(publicKeys)
+	}
+
+	return &JWTValidator{
+		keys:     keys,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}, nil
+}
+
+// VerificationKeys is the validator's jwt.Keyfunc: it returns the keys that may have
+// signed token, those matching its "kid" header and signing algorithm, or every key of
+// that algorithm when no ID matches
+func (v *JWTValidator) VerificationKeys(token *jwt.Token) (interface{}, error) {
+	var matchesType func(key interface{}) bool
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		matchesType = func(key interface{}) bool { _, ok := key.(*rsa.PublicKey); return ok }
+	case *jwt.SigningMethodECDSA:
+		matchesType = func(key interface{}) bool { _, ok := key.(*ecdsa.PublicKey); return ok }
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	var byID, byType []jwt.VerificationKey
+	for _, key := range v.keys.Keys() {
+		if !matchesType(key.Key) {
+			continue
+		}
+		byType = append(byType, key.Key)
+		if kid != "" && key.ID == kid {
+			byID = append(byID, key.Key)
+		}
+	}
+
+	if len(byID) > 0 {
+		return jwt.VerificationKeySet{Keys: byID}, nil
+	}
+	if len(byType) == 0 {
+		return nil, fmt.Errorf("no verification key for signing method %v", token.Header["alg"])
+	}
+	return jwt.VerificationKeySet{Keys: byType}, nil
+}
+
+// ValidateToken validates a JWT token and returns the claims
+func (v *JWTValidator) ValidateToken(tokenString string) (*Claims, error) {
+	// Remove "Bearer " prefix if present
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
+	// Parse and validate token
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, v.VerificationKeys)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	// Validate issuer
+	if claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("invalid issuer: expected %s, got %s", v.issuer, claims.Issuer)
+	}
+
+	// Validate audience
+	validAudience := false
+	for _, aud := range claims.Audience {
+		if aud == v.audience {
+			validAudience = true
+			break
+		}
+	}
+	if !validAudience {
+		return nil, fmt.Errorf("invalid audience")
+	}
+
+	// Validate expiration
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
+		return nil, fmt.Errorf("token expired")
+	}
+
+	// Validate tenant ID is present and is the UUID of a tenant row
+	if claims.TenantID == "" {
+		return nil, fmt.Errorf("tenant_id claim is required")
+	}
+	if err := ValidateTenantID(claims.TenantID); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// tenantIDPattern matches the canonical hyphenated form of a UUID
+var tenantIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateTenantID checks that a tenant ID is a UUID, the key type of the tenants table.
+// Tokens carrying anything else are rejected before the ID reaches a database.
+func ValidateTenantID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return fmt.Errorf("tenant_id claim must be a UUID")
+	}
+	return nil
+}
+
+// ExtractTenantID extracts tenant ID from context
+func ExtractTenantID(ctx context.Context) (string, error) {
+	tenantID, ok := ctx.Value(ContextKeyTenantID).(string)
+	if !ok || tenantID == "" {
+		return "", fmt.Errorf("tenant_id not found in context")
+	}
+	return tenantID, nil
+}
+
+// ExtractUserID extracts user ID from context
+func ExtractUserID(ctx context.Context) (string, error) {
+	userID, ok := ctx.Value(ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("user_id not found in context")
+	}
+	return userID, nil
+}
+
+// ExtractScopes extracts scopes from context
+func ExtractScopes(ctx context.Context) ([]string, error) {
+	scopes, ok := ctx.Value(ContextKeyScopes).([]string)
+	if !ok {
+		return []string{}, nil
+	}
+	return scopes, nil
+}
+
+// HasScope checks if a specific scope exists
+func HasScope(ctx context.Context, requiredScope string) bool {
+	scopes, err := ExtractScopes(ctx)
+	if err != nil {
+		return false
+	}
+
+	for _, scope := range scopes {
+		if scope == requiredScope {
+			return true
+		}
+	}
+	return false
+}
+
+// WithAuth adds authentication claims to context
+func WithAuth(ctx context.Context, claims *Claims) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyTenantID, claims.TenantID)
+	ctx = context.WithValue(ctx, ContextKeyUserID, claims.UserID)
+	ctx = context.WithValue(ctx, ContextKeyScopes, claims.Scopes)
+	return ctx
+}
+
+// GenerateDemoToken generates a demo JWT token for testing (DO NOT USE IN PRODUCTION)
+// This is useful for local development and testing
+func GenerateDemoToken(tenantID, userID string, scopes []string, privateKey *rsa.PrivateKey) (string, error) {
+	return GenerateDemoTokenWithExpiry(tenantID, userID, scopes, privateKey, 24*time.Hour)
+}
+
+// GenerateDemoTokenWithExpiry generates a JWT token with custom expiry duration (for testing)
+func GenerateDemoTokenWithExpiry(tenantID, userID string, scopes []string, privateKey *rsa.PrivateKey, expiry time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		TenantID: tenantID,
+		UserID:   userID,
+		Scopes:   scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "mcp-server-demo",
+			Audience:  jwt.ClaimStrings{"mcp-server"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tokenString, err := token.SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return tokenString, nil
+}
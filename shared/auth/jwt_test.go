@@ -96,7 +96,7 @@ func TestValidateToken(t *testing.T) {
 			name: "valid token",
 			tokenFunc: func() string {
 				token, _ := GenerateDemoToken(
-					"tenant-123",
+					"11111111-1111-1111-1111-111111111111",
 					"user-456",
 					[]string{"read", "write"},
 					privateKey,
@@ -105,7 +105,7 @@ func TestValidateToken(t *testing.T) {
 			},
 			wantErr: false,
 			validate: func(t *testing.T, claims *Claims) {
-				assert.Equal(t, "tenant-123", claims.TenantID)
+				assert.Equal(t, "11111111-1111-1111-1111-111111111111", claims.TenantID)
 				assert.Equal(t, "user-456", claims.UserID)
 				assert.Contains(t, claims.Scopes, "read")
 				assert.Contains(t, claims.Scopes, "write")
@@ -115,7 +115,7 @@ func TestValidateToken(t *testing.T) {
 			name: "token with Bearer prefix",
 			tokenFunc: func() string {
 				token, _ := GenerateDemoToken(
-					"tenant-123",
+					"11111111-1111-1111-1111-111111111111",
 					"user-456",
 					[]string{"read"},
 					privateKey,
@@ -124,7 +124,7 @@ func TestValidateToken(t *testing.T) {
 			},
 			wantErr: false,
 			validate: func(t *testing.T, claims *Claims) {
-				assert.Equal(t, "tenant-123", claims.TenantID)
+				assert.Equal(t, "11111111-1111-1111-1111-111111111111", claims.TenantID)
 			},
 		},
 		{
@@ -132,7 +132,7 @@ func TestValidateToken(t *testing.T) {
 			tokenFunc: func() string {
 				now := time.Now()
 				claims := Claims{
-					TenantID: "tenant-123",
+					TenantID: "11111111-1111-1111-1111-111111111111",
 					UserID:   "user-456",
 					RegisteredClaims: jwt.RegisteredClaims{
 						Issuer:    "mcp-server-demo",
@@ -153,7 +153,7 @@ func TestValidateToken(t *testing.T) {
 			tokenFunc: func() string {
 				now := time.Now()
 				claims := Claims{
-					TenantID: "tenant-123",
+					TenantID: "11111111-1111-1111-1111-111111111111",
 					UserID:   "user-456",
 					RegisteredClaims: jwt.RegisteredClaims{
 						Issuer:    "wrong-issuer",
@@ -174,7 +174,7 @@ func TestValidateToken(t *testing.T) {
 			tokenFunc: func() string {
 				now := time.Now()
 				claims := Claims{
-					TenantID: "tenant-123",
+					TenantID: "11111111-1111-1111-1111-111111111111",
 					UserID:   "user-456",
 					RegisteredClaims: jwt.RegisteredClaims{
 						Issuer:    "mcp-server-demo",
@@ -224,7 +224,7 @@ func TestValidateToken(t *testing.T) {
 			tokenFunc: func() string {
 				// Use HS256 instead of RS256
 				claims := Claims{
-					TenantID: "tenant-123",
+					TenantID: "11111111-1111-1111-1111-111111111111",
 					UserID:   "user-456",
 					RegisteredClaims: jwt.RegisteredClaims{
 						Issuer:    "mcp-server-demo",
@@ -262,6 +262,34 @@ func TestValidateToken(t *testing.T) {
 	}
 }
 
+func TestValidateToken_RejectsMalformedTenantIDs(t *testing.T) {
+	privateKey, publicKeyPEM := generateTestKeyPair(t)
+	validator, err := NewJWTValidator(Config{
+		PublicKeyPEM: publicKeyPEM,
+		Issuer:       "mcp-server-demo",
+		Audience:     "mcp-server",
+	})
+	require.NoError(t, err)
+
+	malicious := []string{
+		"tenant-123",
+		"x'; SET LOCAL row_security = off; --",
+		"11111111-1111-1111-1111-111111111111'; DROP TABLE documents; --",
+		"11111111-1111-1111-1111-111111111111' OR '1'='1",
+		"{11111111-1111-1111-1111-111111111111}",
+		"11111111111111111111111111111111",
+		"11111111-1111-1111-1111-111111111111\n",
+	}
+	for _, tenantID := range malicious {
+		token, err := GenerateDemoToken(tenantID, "user-456", []string{"read"}, privateKey)
+		require.NoError(t, err)
+		_, err = validator.ValidateToken(token)
+		assert.EqualError(t, err, "tenant_id claim must be a UUID", tenantID)
+	}
+
+	assert.NoError(t, ValidateTenantID("AAAAAAAA-bbbb-1111-2222-333333333333"))
+}
+
 func TestExtractTenantID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -271,8 +299,8 @@ func TestExtractTenantID(t *testing.T) {
 	}{
 		{
 			name:     "valid tenant ID",
-			ctx:      context.WithValue(context.Background(), ContextKeyTenantID, "tenant-123"),
-			expected: "tenant-123",
+			ctx:      context.WithValue(context.Background(), ContextKeyTenantID, "11111111-1111-1111-1111-111111111111"),
+			expected: "11111111-1111-1111-1111-111111111111",
 			wantErr:  false,
 		},
 		{
@@ -414,7 +442,7 @@ func TestHasScope(t *testing.T) {
 
 func TestWithAuth(t *testing.T) {
 	claims := &Claims{
-		TenantID: "tenant-123",
+		TenantID: "11111111-1111-1111-1111-111111111111",
 		UserID:   "user-456",
 		Scopes:   []string{"read", "write"},
 	}
@@ -424,7 +452,7 @@ func TestWithAuth(t *testing.T) {
 	// Verify all values are set correctly
 	tenantID, err := ExtractTenantID(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, "tenant-123", tenantID)
+	assert.Equal(t, "11111111-1111-1111-1111-111111111111", tenantID)
 
 	userID, err := ExtractUserID(ctx)
 	assert.NoError(t, err)
@@ -446,19 +474,19 @@ func TestGenerateDemoToken(t *testing.T) {
 	}{
 		{
 			name:     "basic token",
-			tenantID: "tenant-123",
+			tenantID: "11111111-1111-1111-1111-111111111111",
 			userID:   "user-456",
 			scopes:   []string{"read", "write"},
 		},
 		{
 			name:     "token with no scopes",
-			tenantID: "tenant-789",
+			tenantID: "22222222-2222-2222-2222-222222222222",
 			userID:   "user-101",
 			scopes:   []string{},
 		},
 		{
 			name:     "token with many scopes",
-			tenantID: "tenant-multi",
+			tenantID: "33333333-3333-3333-3333-333333333333",
 			userID:   "user-multi",
 			scopes:   []string{"read", "write", "delete", "admin"},
 		},
@@ -506,7 +534,7 @@ func BenchmarkValidateToken(b *testing.B) {
 		Audience:     "test-audience",
 	})
 
-	tokenString, _ := GenerateDemoToken("tenant-123", "user-456", []string{"read"}, privateKey)
+	tokenString, _ := GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"read"}, privateKey)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -515,7 +543,7 @@ func BenchmarkValidateToken(b *testing.B) {
 }
 
 func BenchmarkExtractTenantID(b *testing.B) {
-	ctx := context.WithValue(context.Background(), ContextKeyTenantID, "tenant-123")
+	ctx := context.WithValue(context.Background(), ContextKeyTenantID, "11111111-1111-1111-1111-111111111111")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoPEMKeys is returned when PEM data contains no key blocks
+var ErrNoPEMKeys = errors.New("no PEM keys found")
+
+// VerificationKey is a public key accepted for token signatures
+type VerificationKey struct {
+	ID  string           // Matched against the token "kid" header
+	Key crypto.PublicKey // *rsa.PublicKey or *ecdsa.PublicKey
+}
+
+// KeyProvider supplies the keys currently accepted for token signatures
+type KeyProvider interface {
+	Keys() []VerificationKey
+}
+
+// ParsePublicKeysPEM parses every PEM block in data into a verification key with the given ID.
+// Public keys, certificates and RSA/ECDSA private keys (reduced to their public half) are accepted.
+func ParsePublicKeysPEM(id string, data []byte) ([]VerificationKey, error) {
+	var keys []VerificationKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		key, err := parsePEMBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys = append(keys, VerificationKey{ID: id, Key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoPEMKeys
+	}
+	return keys, nil
+}
+
+// parsePEMBlock extracts an RSA or ECDSA public key from a PEM block
+func parsePEMBlock(block *pem.Block) (crypto.PublicKey, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", strings.ToLower(block.Type), err)
+	}
+
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T: only RSA and ECDSA keys are accepted", key)
+	}
+}
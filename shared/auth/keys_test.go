@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestECKeyPair(t *testing.T) (*ecdsa.PrivateKey, string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return privateKey, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}))
+}
+
+// signTestToken signs valid demo claims with method and key, setting the kid header when given
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) string {
+	now := time.Now()
+	token := jwt.NewWithClaims(method, Claims{
+		TenantID: "11111111-1111-1111-1111-111111111111",
+		UserID:   "user-456",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "mcp-server-demo",
+			Audience:  jwt.ClaimStrings{"mcp-server"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestParsePublicKeysPEM(t *testing.T) {
+	rsaKey, rsaPublicPEM := generateTestKeyPair(t)
+	ecKey, ecPublicPEM := generateTestECKeyPair(t)
+
+	ecPrivateBytes, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		pem     string
+		want    []interface{}
+		wantErr bool
+	}{
+		{"RSA public key", rsaPublicPEM, []interface{}{&rsaKey.PublicKey}, false},
+		{"ECDSA public key", ecPublicPEM, []interface{}{&ecKey.PublicKey}, false},
+		{"PKCS1 RSA public key", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})), []interface{}{&rsaKey.PublicKey}, false},
+		{"RSA private key", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})), []interface{}{&rsaKey.PublicKey}, false},
+		{"EC private key", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecPrivateBytes})), []interface{}{&ecKey.PublicKey}, false},
+		{"PKCS8 private key", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes})), []interface{}{&rsaKey.PublicKey}, false},
+		{"multiple keys", rsaPublicPEM + ecPublicPEM, []interface{}{&rsaKey.PublicKey, &ecKey.PublicKey}, false},
+		{"no PEM", "not a key", nil, true},
+		{"unsupported block", string(pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte("x")})), nil, true},
+		{"corrupt key", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("garbage")})), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParsePublicKeysPEM("key-1", []byte(tt.pem))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, keys, len(tt.want))
+			for i, key := range keys {
+				assert.Equal(t, "key-1", key.ID)
+				assert.Equal(t, tt.want[i], key.Key)
+			}
+		})
+	}
+}
+
+func TestValidateToken_NoKeyForAlgorithm(t *testing.T) {
+	_, rsaPEM := generateTestKeyPair(t)
+	ecKey, _ := generateTestECKeyPair(t)
+
+	validator, err := NewJWTValidator(Config{PublicKeyPEM: rsaPEM, Issuer: "mcp-server-demo", Audience: "mcp-server"})
+	require.NoError(t, err)
+
+	_, err = validator.ValidateToken(signTestToken(t, jwt.SigningMethodES256, ecKey, ""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no verification key")
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+)
+
+// Middleware requires a valid JWT in the Authorization header of every request it wraps
+// and adds the token's claims to the request context and its log entries
+type Middleware struct {
+	validator *JWTValidator
+}
+
+// NewMiddleware creates a middleware validating tokens with validator
+func NewMiddleware(validator *JWTValidator) *Middleware {
+	return &Middleware{validator: validator}
+}
+
+// Handler wraps an HTTP handler with authentication. Requests without a valid token are
+// refused with 401 Unauthorized.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			unauthorized(w, "Authorization header required")
+			return
+		}
+		claims, err := m.validator.ValidateToken(authHeader)
+		if err != nil {
+			unauthorized(w, "Invalid token: "+err.Error())
+			return
+		}
+		logging.AddAttrs(r.Context(),
+			slog.String(logging.TenantIDKey, claims.TenantID),
+			slog.String(logging.UserIDKey, claims.UserID),
+		)
+		next.ServeHTTP(w, r.WithContext(WithAuth(r.Context(), claims)))
+	})
+}
+
+// unauthorized refuses a request without a valid bearer token
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, message, http.StatusUnauthorized)
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Handler(t *testing.T) {
+	privateKey, publicKeyPEM := generateTestKeyPair(t)
+	validator, err := NewJWTValidator(Config{PublicKeyPEM: publicKeyPEM, Issuer: "mcp-server-demo", Audience: "mcp-server"})
+	require.NoError(t, err)
+	var userID string
+	handler := NewMiddleware(validator).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ = ExtractUserID(r.Context())
+		assert.True(t, HasScope(r.Context(), "tasks:write"))
+	}))
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, http.StatusUnauthorized, serve("Bearer not-a-token").Code)
+
+	token, err := GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-1", []string{"tasks:write"}, privateKey)
+	require.NoError(t, err)
+	rr = serve("Bearer " + token)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "user-1", userID)
+}
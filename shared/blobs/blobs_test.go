@@ -0,0 +1,301 @@
+package blobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore exercises the behavior every Store shares
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := "tasks/task-1/report/0"
+
+	_, err := store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("first"), 5))
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("second"), 6))
+	r, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, key), "deleting a missing blob")
+
+	for _, invalid := range []string{"", "/etc/passwd", "../escape", "a//b", "a/./b"} {
+		assert.Error(t, store.Put(ctx, invalid, strings.NewReader("x"), 1), invalid)
+	}
+
+	for _, key := range []string{"tasks/task-2/b", "tasks/task-2/a/0", "tasks/task-20/a", "other"} {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("x"), 1))
+	}
+	keys, err := store.List(ctx, "tasks/task-2/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tasks/task-2/a/0", "tasks/task-2/b"}, keys)
+	keys, err = store.List(ctx, "missing/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	_, err = store.List(ctx, "../")
+	assert.Error(t, err)
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	testStore(t, store)
+
+	// A short read leaves no object behind
+	err = store.Put(context.Background(), "short", strings.NewReader("abc"), 10)
+	assert.Error(t, err)
+	_, err = store.Get(context.Background(), "short")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// fakeS3 keeps the objects of path-style requests that carry a Signature Version 4
+// authorization header
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	if r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.EscapedPath()] = string(data)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") == "2" {
+			f.list(w, r)
+			return
+		}
+		data, ok := f.objects[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		io.WriteString(w, data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// list answers a ListObjectsV2 request, one key per page to exercise continuation
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.EscapedPath() + "/" + r.URL.Query().Get("prefix")
+	var keys []string
+	for path := range f.objects {
+		key, _ := url.PathUnescape(path)
+		if strings.HasPrefix(key, prefix) && key > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	io.WriteString(w, "<ListBucketResult>")
+	if len(keys) > 0 {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", strings.TrimPrefix(keys[0], r.URL.EscapedPath()+"/"))
+	}
+	if len(keys) > 1 {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[0])
+	}
+	io.WriteString(w, "</ListBucketResult>")
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string]string)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	_, err := NewS3Store(S3Config{Endpoint: srv.URL, Bucket: "artifacts", Region: "us-east-1"})
+	assert.Error(t, err, "credentials are required")
+
+	store, err := NewS3Store(S3Config{
+		Endpoint: srv.URL + "/", Bucket: "artifacts", Region: "us-east-1",
+		AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", Prefix: "a2a/",
+	})
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC) }
+	testStore(t, store)
+
+	require.NoError(t, store.Put(context.Background(), "task 1/a+b", strings.NewReader("x"), 1))
+	assert.Contains(t, fake.objects, "/artifacts/a2a/task%201/a%2Bb", "keys are escaped for signing")
+	assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250310/us-east-1/s3/aws4_request, `+
+		`SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`, fake.auth[0])
+}
+
+func TestGCSStore(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string]string)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	_, err := NewGCSStore(GCSConfig{AccessKeyID: "GOOG1EXAMPLE", SecretAccessKey: "secret"})
+	assert.Error(t, err, "a bucket is required")
+
+	store, err := NewGCSStore(GCSConfig{Endpoint: srv.URL, Bucket: "blobs", AccessKeyID: "GOOG1EXAMPLE", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	testStore(t, store)
+	assert.Contains(t, fake.auth[0], "/auto/s3/aws4_request")
+}
+
+func TestContentStore(t *testing.T) {
+	ctx := context.Background()
+	files, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	store := NewContentStore(files)
+
+	hash, err := store.Put(ctx, "tenant-1", "user-1", []byte("image bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, Hash([]byte("image bytes")), hash)
+	assert.True(t, ValidHash(hash))
+	again, err := store.Put(ctx, "tenant-1", "user-1", []byte("image bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	r, err := store.Get(ctx, "tenant-1", hash)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "image bytes", string(data))
+	r, err = files.Get(ctx, "tenants/tenant-1/sha256/"+hash[:2]+"/"+hash)
+	require.NoError(t, err)
+	r.Close()
+
+	// Other tenants cannot tell the object from a missing one
+	_, err = store.Get(ctx, "tenant-2", hash)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Get(ctx, "tenant-1", Hash([]byte("other")))
+	assert.ErrorIs(t, err, ErrNotFound)
+	for _, hash := range []string{"", "../../etc/passwd", strings.ToUpper(hash), hash[:63]} {
+		_, err = store.Get(ctx, "tenant-1", hash)
+		assert.ErrorIs(t, err, ErrInvalidHash, hash)
+	}
+	for _, tenantID := range []string{"", "..", "tenant-1/../tenant-2"} {
+		_, err = store.Put(ctx, tenantID, "user-1", []byte("image bytes"))
+		assert.ErrorIs(t, err, ErrInvalidTenant, tenantID)
+	}
+	_, err = store.Put(ctx, "tenant-1", "../user-2", []byte("image bytes"))
+	assert.ErrorIs(t, err, ErrInvalidOwner)
+	_, err = store.Put(ctx, "tenant-1", "", []byte("no owner"))
+	assert.NoError(t, err, "objects need not have an owner")
+}
+
+func TestContentStore_Owners(t *testing.T) {
+	ctx := context.Background()
+	files, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	store := NewContentStore(files)
+
+	shared, err := store.Put(ctx, "tenant-1", "user-1", []byte("shared"))
+	require.NoError(t, err)
+	_, err = store.Put(ctx, "tenant-1", "user-2", []byte("shared"))
+	require.NoError(t, err)
+	own, err := store.Put(ctx, "tenant-1", "user-1", []byte("own"))
+	require.NoError(t, err)
+	other, err := store.Put(ctx, "tenant-2", "user-1", []byte("other tenant"))
+	require.NoError(t, err)
+
+	owned, err := store.Owned(ctx, "tenant-1", "user-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{shared, own}, owned)
+
+	// Objects another user also put are kept for them
+	n, err := store.Disown(ctx, "tenant-1", "user-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	owned, err = store.Owned(ctx, "tenant-1", "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, owned)
+	r, err := store.Get(ctx, "tenant-1", shared)
+	require.NoError(t, err)
+	r.Close()
+	_, err = store.Get(ctx, "tenant-1", own)
+	assert.ErrorIs(t, err, ErrNotFound)
+	r, err = store.Get(ctx, "tenant-2", other)
+	require.NoError(t, err)
+	r.Close()
+
+	// Handing objects over keeps them
+	n, err = store.Disown(ctx, "tenant-1", "user-2", "erased-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	owned, err = store.Owned(ctx, "tenant-1", "erased-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{shared}, owned)
+	r, err = store.Get(ctx, "tenant-1", shared)
+	require.NoError(t, err)
+	r.Close()
+}
+
+// fixedRegions assigns tenants to data regions
+type fixedRegions map[string]string
+
+func (r fixedRegions) TenantRegion(ctx context.Context, tenantID string) (string, error) {
+	return r[tenantID], nil
+}
+
+func TestContentStore_Regions(t *testing.T) {
+	ctx := context.Background()
+	us, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	eu, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	store := NewRegionalContentStore(fixedRegions{"tenant-us": "default", "tenant-eu": "eu-west", "tenant-ap": "ap-south"},
+		map[string]Store{"default": us, "eu-west": eu})
+
+	hash, err := store.Put(ctx, "tenant-eu", "user-1", []byte("image bytes"))
+	require.NoError(t, err)
+	r, err := eu.Get(ctx, contentKey("tenant-eu", hash))
+	require.NoError(t, err)
+	r.Close()
+	_, err = us.Get(ctx, contentKey("tenant-eu", hash))
+	assert.ErrorIs(t, err, ErrNotFound)
+	r, err = store.Get(ctx, "tenant-eu", hash)
+	require.NoError(t, err)
+	r.Close()
+
+	// Tenants of a region without a store are refused rather than stored elsewhere
+	_, err = store.Put(ctx, "tenant-ap", "user-1", []byte("image bytes"))
+	assert.ErrorIs(t, err, ErrRegionUnavailable)
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open(Config{Backend: BackendFilesystem, Dir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &FileStore{}, store)
+	store, err = Open(Config{Backend: BackendGCS, Bucket: "blobs", AccessKeyID: "id", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultGCSEndpoint, store.(*S3Store).config.Endpoint)
+
+	_, err = Open(Config{Backend: BackendFilesystem})
+	assert.Error(t, err)
+	_, err = Open(Config{Backend: "azure"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package blobs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backends Open can create a store for
+const (
+	BackendFilesystem = "filesystem"
+	BackendS3         = "s3"
+	BackendGCS        = "gcs"
+)
+
+// Config selects and locates a store, as read from a config file
+type Config struct {
+	// Backend is BackendFilesystem, BackendS3 or BackendGCS
+	Backend string `yaml:"backend"`
+	// Dir is the directory of the filesystem store
+	Dir string `yaml:"dir"`
+	// Endpoint, Bucket and Region locate the S3 bucket; GCS needs only the bucket
+	Endpoint string `yaml:"endpoint"`
+	Bucket   string `yaml:"bucket"`
+	Region   string `yaml:"region"`
+	// AccessKeyID and SecretAccessKey are the S3 credentials, or a GCS HMAC key
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	// Prefix is prepended to every key in a bucket
+	Prefix string `yaml:"prefix"`
+}
+
+// Open creates the store config selects
+func Open(config Config) (Store, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid blob store config: %w", err)
+	}
+	switch config.Backend {
+	case BackendFilesystem:
+		return NewFileStore(config.Dir)
+	case BackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:        config.Endpoint,
+			Bucket:          config.Bucket,
+			Region:          config.Region,
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+			Prefix:          config.Prefix,
+		})
+	case BackendGCS:
+		return NewGCSStore(GCSConfig{
+			Endpoint:        config.Endpoint,
+			Bucket:          config.Bucket,
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+			Prefix:          config.Prefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store backend %q", config.Backend)
+	}
+}
+
+// Validate checks that config has what its backend needs
+func (c Config) Validate() error {
+	var missing []string
+	require := func(value, name string) {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	switch c.Backend {
+	case BackendFilesystem:
+		require(c.Dir, "dir")
+	case BackendS3:
+		require(c.Endpoint, "endpoint")
+		require(c.Bucket, "bucket")
+		require(c.Region, "region")
+		require(c.AccessKeyID, "access_key_id")
+		require(c.SecretAccessKey, "secret_access_key")
+	case BackendGCS:
+		require(c.Bucket, "bucket")
+		require(c.AccessKeyID, "access_key_id")
+		require(c.SecretAccessKey, "secret_access_key")
+	default:
+		return fmt.Errorf("unknown backend %q, want %q, %q or %q", c.Backend, BackendFilesystem, BackendS3, BackendGCS)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s backend requires %s", c.Backend, strings.Join(missing, ", "))
+	}
+	return nil
+}
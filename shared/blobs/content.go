@@ -0,0 +1,210 @@
+package blobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidHash is returned for content hashes that are not lowercase hex SHA-256 digests
+var ErrInvalidHash = errors.New("invalid content hash")
+
+// Hash returns the content hash of data: its SHA-256 digest in lowercase hex
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidHash reports whether hash is a content hash as Hash returns them
+func ValidHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for i := 0; i < len(hash); i++ {
+		c := hash[i]
+		if !('0' <= c && c <= '9') && !('a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ContentStore keeps each tenant's objects under the hash of their content, so an object
+// is stored once per tenant however often it is put, and a hash always names the same
+// bytes. Tenants only ever see their own objects: one tenant cannot read, or learn of,
+// an object another stored. Each object records the users that put it, so a user's
+// objects can be exported and erased. With regions set, each tenant's objects are kept in
+// the store of its data region.
+type ContentStore struct {
+	store   Store
+	regions RegionResolver
+	stores  map[string]Store
+}
+
+// RegionResolver looks up the data region a tenant's objects must be stored in
+type RegionResolver interface {
+	TenantRegion(ctx context.Context, tenantID string) (string, error)
+}
+
+// ErrRegionUnavailable is returned for tenants whose data region has no store; their
+// objects are not stored in another region instead
+var ErrRegionUnavailable = errors.New("no blob store for data region")
+
+// ErrInvalidTenant is returned for tenant IDs that cannot be part of a key
+var ErrInvalidTenant = errors.New("invalid blob tenant")
+
+// ErrInvalidOwner is returned for user IDs that cannot be part of a key
+var ErrInvalidOwner = errors.New("invalid blob owner")
+
+// NewContentStore keeps content-addressed objects in store, under tenants/
+func NewContentStore(store Store) *ContentStore {
+	return &ContentStore{store: store}
+}
+
+// NewRegionalContentStore keeps content-addressed objects in the store of each tenant's
+// data region, as resolved by regions
+func NewRegionalContentStore(regions RegionResolver, stores map[string]Store) *ContentStore {
+	return &ContentStore{regions: regions, stores: stores}
+}
+
+// contentKey returns the key of a tenant's object with the given hash; the first byte of
+// the hash shards objects across directories
+func contentKey(tenantID, hash string) string {
+	return "tenants/" + tenantID + "/sha256/" + hash[:2] + "/" + hash
+}
+
+// ownersPrefix returns the prefix of the owner markers of a tenant's objects; the marker
+// of a user that put the object with the given hash is ownersPrefix + hash + "/" + user
+func ownersPrefix(tenantID string) string {
+	return "tenants/" + tenantID + "/owners/"
+}
+
+// validSegment reports whether an ID can be a single key segment
+func validSegment(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "/\\") && id != "." && id != ".."
+}
+
+// storeFor returns the store keeping a tenant's objects
+func (s *ContentStore) storeFor(ctx context.Context, tenantID string) (Store, error) {
+	if !validSegment(tenantID) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTenant, tenantID)
+	}
+	if s.regions == nil {
+		return s.store, nil
+	}
+	region, err := s.regions.TenantRegion(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	store, ok := s.stores[region]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRegionUnavailable, region)
+	}
+	return store, nil
+}
+
+// Put stores data for a tenant unless it already stored an object with the same content,
+// records the user, if any, as one of its owners and returns its hash
+func (s *ContentStore) Put(ctx context.Context, tenantID, userID string, data []byte) (string, error) {
+	if userID != "" && !validSegment(userID) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidOwner, userID)
+	}
+	store, err := s.storeFor(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	hash := Hash(data)
+	key := contentKey(tenantID, hash)
+	if r, err := store.Get(ctx, key); err == nil {
+		r.Close()
+	} else if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", err
+	}
+	if userID == "" {
+		return hash, nil
+	}
+	if err := store.Put(ctx, ownersPrefix(tenantID)+hash+"/"+userID, bytes.NewReader(nil), 0); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// Owned returns the hashes of the tenant's objects the user put
+func (s *ContentStore) Owned(ctx context.Context, tenantID, userID string) ([]string, error) {
+	if !validSegment(userID) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOwner, userID)
+	}
+	store, err := s.storeFor(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	markers, err := store.List(ctx, ownersPrefix(tenantID))
+	if err != nil {
+		return nil, err
+	}
+	var hashes []string
+	for _, marker := range markers {
+		hash, owner, ok := strings.Cut(strings.TrimPrefix(marker, ownersPrefix(tenantID)), "/")
+		if ok && owner == userID {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, nil
+}
+
+// Disown removes the user from the owners of every object of the tenant it put and
+// returns how many there were. With a newOwner the objects are handed over to it;
+// without one, objects left with no owner are deleted.
+func (s *ContentStore) Disown(ctx context.Context, tenantID, userID, newOwner string) (int, error) {
+	if newOwner != "" && !validSegment(newOwner) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidOwner, newOwner)
+	}
+	hashes, err := s.Owned(ctx, tenantID, userID)
+	if err != nil {
+		return 0, err
+	}
+	store, err := s.storeFor(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, hash := range hashes {
+		owners := ownersPrefix(tenantID) + hash + "/"
+		if newOwner != "" {
+			if err := store.Put(ctx, owners+newOwner, bytes.NewReader(nil), 0); err != nil {
+				return 0, err
+			}
+		}
+		if err := store.Delete(ctx, owners+userID); err != nil {
+			return 0, err
+		}
+		remaining, err := store.List(ctx, owners)
+		if err != nil {
+			return 0, err
+		}
+		if len(remaining) == 0 {
+			if err := store.Delete(ctx, contentKey(tenantID, hash)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(hashes), nil
+}
+
+// Get opens the tenant's object with the given hash; the caller closes it. Objects of
+// other tenants are ErrNotFound, like missing ones.
+func (s *ContentStore) Get(ctx context.Context, tenantID, hash string) (io.ReadCloser, error) {
+	if !ValidHash(hash) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidHash, hash)
+	}
+	store, err := s.storeFor(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(ctx, contentKey(tenantID, hash))
+}
@@ -0,0 +1,42 @@
+package blobs
+
+import (
+	"errors"
+	"net/http"
+)
+
+// DefaultGCSEndpoint is the Cloud Storage XML API endpoint
+const DefaultGCSEndpoint = "https://storage.googleapis.com"
+
+// GCSConfig locates a Google Cloud Storage bucket, accessed with an HMAC key of a
+// service account through the S3-compatible XML API
+type GCSConfig struct {
+	// Endpoint defaults to DefaultGCSEndpoint
+	Endpoint        string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Prefix is prepended to every key, so several servers can share a bucket
+	Prefix string
+	Client *http.Client
+}
+
+// NewGCSStore stores objects in the Cloud Storage bucket config locates. Cloud Storage
+// accepts Signature Version 4 requests signed with HMAC keys, with "auto" as the region.
+func NewGCSStore(config GCSConfig) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("GCS blob store requires a bucket")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = DefaultGCSEndpoint
+	}
+	return NewS3Store(S3Config{
+		Endpoint:        config.Endpoint,
+		Bucket:          config.Bucket,
+		Region:          "auto",
+		AccessKeyID:     config.AccessKeyID,
+		SecretAccessKey: config.SecretAccessKey,
+		Prefix:          config.Prefix,
+		Client:          config.Client,
+	})
+}
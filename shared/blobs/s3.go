@@ -0,0 +1,251 @@
+package blobs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Config locates a bucket of S3 or an S3-compatible service such as MinIO
+type S3Config struct {
+	// Endpoint is the service's base URL, e.g. https://s3.us-east-1.amazonaws.com; the
+	// bucket is addressed path-style under it
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Prefix is prepended to every key, so several servers can share a bucket
+	Prefix string
+	Client *http.Client
+}
+
+// S3Store keeps objects in an S3 bucket, signing requests with AWS Signature Version 4
+type S3Store struct {
+	config S3Config
+	now    func() time.Time
+}
+
+var _ Store = (*S3Store)(nil)
+
+// NewS3Store stores objects in the bucket config locates
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.Region == "" {
+		return nil, errors.New("S3 blob store requires an endpoint, bucket and region")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("S3 blob store requires an access key ID and secret access key")
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &S3Store{config: config, now: time.Now}, nil
+}
+
+// Put uploads the object in a single PUT; its payload is not signed, so it is streamed
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	resp, err := s.do(ctx, http.MethodPut, key, r, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, key)
+	}
+	return nil
+}
+
+// Get downloads the object
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp, key)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object; S3 does not report missing objects
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp, key)
+	}
+	return nil
+}
+
+// listResult is the part of a ListObjectsV2 response List reads
+type listResult struct {
+	Keys                  []string `xml:"Contents>Key"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// List pages through the bucket's objects under the store's prefix and prefix
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	if dir := strings.TrimSuffix(prefix, "/"); dir != "" {
+		if err := validKey(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var keys []string
+	token := ""
+	for {
+		// Signature Version 4 signs the query parameters sorted by name
+		query := "list-type=2&prefix=" + escapeQuery(s.config.Prefix+prefix)
+		if token != "" {
+			query = "continuation-token=" + escapeQuery(token) + "&" + query
+		}
+		resp, err := s.send(ctx, http.MethodGet, "/"+s.config.Bucket, query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		var result listResult
+		if resp.StatusCode != http.StatusOK {
+			err = s3Error(resp, prefix)
+		} else if err = xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+			err = fmt.Errorf("S3 list %s: %w", prefix, err)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range result.Keys {
+			keys = append(keys, strings.TrimPrefix(key, s.config.Prefix))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request for an object
+func (s *S3Store) do(ctx context.Context, method, key string, body io.Reader, size int64) (*http.Response, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	return s.send(ctx, method, "/"+s.config.Bucket+"/"+s.config.Prefix+key, "", body, size)
+}
+
+// send sends a signed request for a path of the service, with an already escaped query
+func (s *S3Store) send(ctx context.Context, method, path, query string, body io.Reader, size int64) (*http.Response, error) {
+	target := s.config.Endpoint + escapePath(path)
+	if query != "" {
+		target += "?" + query
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = "UNSIGNED-PAYLOAD"
+	}
+	s.sign(req, payloadHash)
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s %s: %w", method, path, err)
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 headers for the S3 service to req
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	for _, part := range []string{s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// escapePath URI-encodes an object path as Signature Version 4 expects: every byte but
+// the unreserved characters and the slashes between segments
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// escapeQuery URI-encodes a query parameter value as Signature Version 4 expects,
+// slashes included
+func escapeQuery(value string) string {
+	return strings.ReplaceAll(escapePath(value), "/", "%2F")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Error describes a failed response, mapping a missing object to ErrNotFound
+func s3Error(resp *http.Response, key string) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 %s %s returned %s: %s", resp.Request.Method, key, resp.Status, strings.TrimSpace(string(body)))
+}
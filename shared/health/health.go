@@ -0,0 +1,217 @@
+// Package health serves the liveness and readiness probes. Liveness only reports that the
+// process serves requests; readiness pings every dependency with a timeout and fails while
+// a required one is down or the server drains for shutdown.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Probe paths
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// DefaultTimeout bounds each dependency check
+const DefaultTimeout = 2 * time.Second
+
+// Overall and per-dependency statuses
+const (
+	StatusOK       = "ok"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+	StatusUp       = "up"
+	StatusDown     = "down"
+)
+
+// Check pings one dependency, failing when it is unreachable or ctx expires
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of one dependency check
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Required  bool    `json:"required"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of a readiness check
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+	// Details are reported next to the status, such as connection pool stats
+	Details map[string]interface{} `json:"-"`
+}
+
+// Ready reports whether the server should receive traffic
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+type dependency struct {
+	name     string
+	check    Check
+	required bool
+}
+
+// Checker runs the dependency checks behind the readiness probe
+type Checker struct {
+	timeout time.Duration
+
+	mu           sync.RWMutex
+	dependencies []dependency
+	details      map[string]func() interface{}
+	draining     <-chan struct{}
+}
+
+// NewChecker creates a checker that gives each dependency timeout to answer;
+// zero means DefaultTimeout
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, details: make(map[string]func() interface{})}
+}
+
+// Add registers a dependency the server cannot serve requests without
+func (c *Checker) Add(name string, check Check) {
+	c.add(dependency{name: name, check: check, required: true})
+}
+
+// AddOptional registers a dependency that is reported but, when down, leaves the server
+// ready, such as the trace exporter
+func (c *Checker) AddOptional(name string, check Check) {
+	c.add(dependency{name: name, check: check})
+}
+
+func (c *Checker) add(dep dependency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dependencies = append(c.dependencies, dep)
+}
+
+// AddDetail reports the value detail returns under name in every readiness response
+func (c *Checker) AddDetail(name string, detail func() interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details[name] = detail
+}
+
+// SetDraining makes the server not ready once draining is closed, so load balancers stop
+// routing to it while in-flight work finishes
+func (c *Checker) SetDraining(draining <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = draining
+}
+
+// Check pings every dependency concurrently and reports whether the server is ready
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	dependencies := c.dependencies
+	draining := c.draining
+	details := make(map[string]interface{}, len(c.details))
+	for name, detail := range c.details {
+		details[name] = detail()
+	}
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(dependencies))
+	var wg sync.WaitGroup
+	for i, dep := range dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, dep)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(results)), Details: details}
+	for i, result := range results {
+		report.Checks[dependencies[i].name] = result
+		if result.Required && result.Status != StatusUp {
+			report.Status = StatusNotReady
+		}
+	}
+	select {
+	case <-draining:
+		report.Status = StatusDraining
+	default:
+	}
+	return report
+}
+
+// run checks one dependency within the timeout
+func (c *Checker) run(ctx context.Context, dep dependency) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dep.check(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		Required:  dep.required,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Handler serves the probes ahead of next. It belongs outside the shutdown drain so the
+// probes keep answering, and reporting the drain, after new requests are refused.
+func (c *Checker) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case LivenessPath:
+			c.serveLiveness(w, r)
+		case ReadinessPath:
+			c.serveReadiness(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serveLiveness answers while the process serves requests, without touching dependencies,
+// so an outage of one does not get every replica restarted
+func (c *Checker) serveLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": StatusOK})
+}
+
+// serveReadiness answers 200 when the server is ready and 503 otherwise, listing each
+// dependency's status
+func (c *Checker) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+	body := make(map[string]interface{}, len(report.Details)+2)
+	for name, detail := range report.Details {
+		body[name] = detail
+	}
+	body["status"] = report.Status
+	body["checks"] = report.Checks
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
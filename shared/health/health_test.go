@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func down(ctx context.Context) error { return errors.New("connection refused") }
+
+// hang blocks until the check times out
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestChecker_Check(t *testing.T) {
+	checker := NewChecker(20 * time.Millisecond)
+	checker.Add("postgres", up)
+	checker.AddOptional("otlp_exporter", down)
+	checker.AddDetail("safe_mode", func() interface{} { return "off" })
+
+	report := checker.Check(context.Background())
+	assert.True(t, report.Ready(), "an optional dependency being down leaves the server ready")
+	assert.Equal(t, StatusUp, report.Checks["postgres"].Status)
+	assert.True(t, report.Checks["postgres"].Required)
+	assert.Equal(t, StatusDown, report.Checks["otlp_exporter"].Status)
+	assert.Equal(t, "connection refused", report.Checks["otlp_exporter"].Error)
+	assert.Equal(t, "off", report.Details["safe_mode"])
+
+	checker.Add("redis", hang)
+	report = checker.Check(context.Background())
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.Equal(t, StatusDown, report.Checks["redis"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["redis"].Error)
+}
+
+func TestChecker_Handler(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("redis", down)
+	draining := make(chan struct{})
+	checker.SetDraining(draining)
+	handler := checker.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]interface{}
+		if rr.Code != http.StatusTeapot {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		}
+		return rr, body
+	}
+
+	// Liveness does not depend on dependencies
+	rr, body := get(LivenessPath)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, StatusOK, body["status"])
+
+	rr, body = get(ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, StatusNotReady, body["status"])
+	redis := body["checks"].(map[string]interface{})["redis"].(map[string]interface{})
+	assert.Equal(t, StatusDown, redis["status"])
+
+	// Draining takes the server out of rotation while it stays alive
+	close(draining)
+	rr, body = get(ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, StatusDraining, body["status"])
+	rr, _ = get(LivenessPath)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr, _ = get("/mcp")
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+}
@@ -0,0 +1,236 @@
+// Package httpserver holds the HTTP middleware both servers wrap their routes in: request
+// IDs and access logs, request tracing, and request body limits with response compression.
+package httpserver
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Body defaults
+const (
+	DefaultMaxRequestBytes = 4 << 20 // 4 MiB
+	DefaultGzipMinBytes    = 1 << 10
+)
+
+// BodyConfig limits request bodies and selects response compression
+type BodyConfig struct {
+	// MaxRequestBytes bounds request bodies after decompression; zero means no limit
+	MaxRequestBytes int64
+	// Gzip compresses responses for clients that accept it, except event streams
+	Gzip bool
+	// GzipMinBytes is the size below which responses are sent uncompressed
+	GzipMinBytes int
+}
+
+// BodyMiddleware decompresses gzip request bodies, enforces the request size limit and
+// compresses responses. Reading a body over the limit fails with *http.MaxBytesError so
+// handlers can answer with a proper error instead of a reset connection.
+type BodyMiddleware struct {
+	config BodyConfig
+}
+
+// NewBodyMiddleware creates a body middleware
+func NewBodyMiddleware(config BodyConfig) *BodyMiddleware {
+	return &BodyMiddleware{config: config}
+}
+
+// Handler wraps an http.Handler with request decompression, the size limit and response
+// compression
+func (bm *BodyMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+		case "gzip":
+			r.Body = &gzipBody{body: r.Body}
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		default:
+			http.Error(w, "Unsupported Content-Encoding "+encoding, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if limit := bm.config.MaxRequestBytes; limit > 0 && r.Body != nil {
+			if r.ContentLength > limit {
+				// Fail on the first read instead of reading up to the limit first
+				r.Body = tooLargeBody{limit: limit, body: r.Body}
+			} else {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+		}
+
+		if !bm.config.Gzip || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, minSize: bm.config.GzipMinBytes}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether the client accepts gzip responses
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipBody decompresses a request body, reading the gzip header on the first Read so a
+// malformed body surfaces as a read error
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		reader, err := gzip.NewReader(b.body)
+		if err != nil {
+			return 0, err
+		}
+		b.reader = reader
+	}
+	return b.reader.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// tooLargeBody is a body whose declared length is over the limit
+type tooLargeBody struct {
+	limit int64
+	body  io.ReadCloser
+}
+
+func (b tooLargeBody) Read([]byte) (int, error) {
+	return 0, &http.MaxBytesError{Limit: b.limit}
+}
+
+func (b tooLargeBody) Close() error {
+	return b.body.Close()
+}
+
+// gzipResponseWriter buffers a response until it reaches minSize bytes, then compresses
+// it. Smaller responses, event streams and responses that already have an encoding are
+// sent as they are.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader holds the status until the encoding is decided
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if gw.decided {
+		gw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if gw.status == 0 {
+		gw.status = code
+	}
+}
+
+func (gw *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !gw.decided {
+		if !gw.compressible() {
+			if err := gw.decide(false); err != nil {
+				return 0, err
+			}
+		} else {
+			gw.buf = append(gw.buf, p...)
+			if len(gw.buf) < gw.minSize {
+				return len(p), nil
+			}
+			return len(p), gw.decide(true)
+		}
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(p)
+	}
+	return gw.ResponseWriter.Write(p)
+}
+
+// Flush sends what was written so far, uncompressed when the encoding is still undecided
+// since a flushing handler streams
+func (gw *gzipResponseWriter) Flush() {
+	if !gw.decided {
+		gw.decide(false)
+	}
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if flusher, ok := gw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// Close sends a response still buffered and finishes the compressed stream
+func (gw *gzipResponseWriter) Close() error {
+	if !gw.decided {
+		if err := gw.decide(false); err != nil {
+			return err
+		}
+	}
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
+
+// compressible reports whether the response may be compressed, judging by its headers
+func (gw *gzipResponseWriter) compressible() bool {
+	header := gw.Header()
+	if header.Get("Content-Encoding") != "" || strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	switch gw.status {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// decide writes the header, compressed or not, and the buffered body
+func (gw *gzipResponseWriter) decide(compress bool) error {
+	gw.decided = true
+	header := gw.Header()
+	header.Add("Vary", "Accept-Encoding")
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	if gw.status != 0 {
+		gw.ResponseWriter.WriteHeader(gw.status)
+	}
+	if len(gw.buf) == 0 {
+		return nil
+	}
+	buf := gw.buf
+	gw.buf = nil
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(buf)
+	} else {
+		_, err = gw.ResponseWriter.Write(buf)
+	}
+	return err
+}
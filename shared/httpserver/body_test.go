@@ -0,0 +1,141 @@
+package httpserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// echoBody answers with the request body, or 413 when it was over the limit
+func echoBody(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, "too large", http.StatusRequestEntityTooLarge)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.Write(body)
+	}
+}
+
+func TestBodyMiddleware_RequestLimit(t *testing.T) {
+	handler := NewBodyMiddleware(BodyConfig{MaxRequestBytes: 16}).Handler(http.HandlerFunc(echoBody))
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("small body")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "small body", rec.Body.String())
+
+	// Declared and undeclared lengths are both limited
+	rec = serve(httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	req := httptest.NewRequest(http.MethodPost, "/mcp", io.NopCloser(strings.NewReader(strings.Repeat("x", 17))))
+	req.ContentLength = -1
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(req).Code)
+
+	// The limit applies to the decompressed body, so small gzip bombs are refused too
+	req = httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(gzipBytes(t, bytes.Repeat([]byte("x"), 1000))))
+	req.Header.Set("Content-Encoding", "gzip")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(req).Code)
+}
+
+func TestBodyMiddleware_GzipRequest(t *testing.T) {
+	handler := NewBodyMiddleware(BodyConfig{MaxRequestBytes: 1 << 10}).Handler(http.HandlerFunc(echoBody))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(gzipBytes(t, []byte(`{"jsonrpc":"2.0"}`))))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"jsonrpc":"2.0"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("data"))
+	req.Header.Set("Content-Encoding", "br")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestBodyMiddleware_GzipResponse(t *testing.T) {
+	large := strings.Repeat(`{"title":"Security Policy"}`, 100)
+	handler := NewBodyMiddleware(BodyConfig{Gzip: true, GzipMinBytes: 1 << 10}).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/large":
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, large)
+			case "/small":
+				io.WriteString(w, "ok")
+			case "/events":
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, "data: "+large+"\n\n")
+				w.(http.Flusher).Flush()
+			}
+		}))
+
+	get := func(path string, acceptGzip bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptGzip {
+			req.Header.Set("Accept-Encoding", "gzip, deflate")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/large", true)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	assert.Less(t, rec.Body.Len(), len(large))
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	rec = get("/large", false)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, rec.Body.String())
+
+	rec = get("/small", true)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "ok", rec.Body.String())
+
+	rec = get("/events", true)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.True(t, rec.Flushed)
+	assert.Contains(t, rec.Body.String(), "data: ")
+}
@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs
+const maxRequestIDLength = 128
+
+// LoggingMiddleware assigns every request an ID, makes it and the request's trace and
+// tenant available to every log entry, and logs each completed request
+type LoggingMiddleware struct {
+	logger *slog.Logger
+}
+
+// NewLoggingMiddleware creates a new logging middleware; a nil logger uses slog's default
+func NewLoggingMiddleware(logger *slog.Logger) *LoggingMiddleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LoggingMiddleware{logger: logger}
+}
+
+// Handler wraps an http.Handler with request logging. It should wrap every other
+// middleware so their log entries are correlated too.
+func (lm *LoggingMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		recorder := &loggingRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if recorder.statusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		lm.logger.LogAttrs(ctx, level, "HTTP request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.statusCode),
+			slog.Int("bytes", recorder.written),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+	})
+}
+
+// validRequestID accepts client request IDs of printable ASCII within the length limit
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// loggingRecorder wraps http.ResponseWriter to capture the status code and size
+type loggingRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	written     int
+	wroteHeader bool
+}
+
+// WriteHeader captures the status code
+func (lr *loggingRecorder) WriteHeader(code int) {
+	if !lr.wroteHeader {
+		lr.statusCode = code
+		lr.wroteHeader = true
+	}
+	lr.ResponseWriter.WriteHeader(code)
+}
+
+// Write captures the response size
+func (lr *loggingRecorder) Write(b []byte) (int, error) {
+	lr.wroteHeader = true
+	n, err := lr.ResponseWriter.Write(b)
+	lr.written += n
+	return n, err
+}
+
+// Flush supports streaming responses
+func (lr *loggingRecorder) Flush() {
+	if flusher, ok := lr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (lr *loggingRecorder) Unwrap() http.ResponseWriter {
+	return lr.ResponseWriter
+}
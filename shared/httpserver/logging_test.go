@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhatti/mcp-a2a-go/shared/auth"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLines decodes JSON log lines
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// setupTestAuth returns a validator accepting the demo tokens signed with the returned key
+func setupTestAuth(t *testing.T) (*auth.JWTValidator, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	validator, err := auth.NewJWTValidator(auth.Config{
+		PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+		Issuer:       "mcp-server-demo",
+		Audience:     "mcp-server",
+	})
+	require.NoError(t, err)
+	return validator, privateKey
+}
+
+func TestLoggingMiddleware_CorrelatesRequest(t *testing.T) {
+	validator, privateKey := setupTestAuth(t)
+	token, err := auth.GenerateDemoToken("11111111-1111-1111-1111-111111111111", "user-456", []string{"read"}, privateKey)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{})
+	require.NoError(t, err)
+
+	handler := NewLoggingMiddleware(logger).Handler(
+		auth.NewMiddleware(validator).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.InfoContext(r.Context(), "inside handler")
+			w.WriteHeader(http.StatusAccepted)
+		})),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	requestID := rec.Header().Get(RequestIDHeader)
+	require.NotEmpty(t, requestID)
+
+	lines := logLines(t, &buf)
+	require.Len(t, lines, 2)
+	for _, entry := range lines {
+		assert.Equal(t, requestID, entry[logging.RequestIDKey])
+		assert.Equal(t, "11111111-1111-1111-1111-111111111111", entry[logging.TenantIDKey])
+		assert.Equal(t, "user-456", entry[logging.UserIDKey])
+	}
+	assert.Equal(t, "HTTP request", lines[1]["msg"])
+	assert.Equal(t, float64(http.StatusAccepted), lines[1]["status"])
+	assert.Equal(t, "/mcp", lines[1]["path"])
+}
+
+func TestLoggingMiddleware_RequestIDHeader(t *testing.T) {
+	handler := NewLoggingMiddleware(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(logging.RequestID(r.Context())))
+		}),
+	)
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"propagated", "client-abc-123", true},
+		{"generated when missing", "", false},
+		{"replaced when invalid", "bad id\n", false},
+		{"replaced when too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(RequestIDHeader)
+			assert.Equal(t, requestID, rec.Body.String())
+			if tt.keep {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+		})
+	}
+}
@@ -1,9 +1,9 @@
-package middleware
+package httpserver
 
 import (
 	"net/http"
 
-	"github.com/bhatti/mcp-a2a-go/a2a-server/internal/observability"
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -13,20 +13,21 @@ import (
 
 // TracingMiddleware provides HTTP request tracing with OpenTelemetry
 type TracingMiddleware struct {
-	telemetry *observability.Telemetry
+	tracer trace.Tracer
 }
 
-// NewTracingMiddleware creates a new tracing middleware
-func NewTracingMiddleware(telemetry *observability.Telemetry) *TracingMiddleware {
+// NewTracingMiddleware creates a new tracing middleware; a nil tracer passes requests
+// through untraced
+func NewTracingMiddleware(tracer trace.Tracer) *TracingMiddleware {
 	return &TracingMiddleware{
-		telemetry: telemetry,
+		tracer: tracer,
 	}
 }
 
 // Handler wraps an http.Handler with tracing
 func (tm *TracingMiddleware) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if tm.telemetry == nil || tm.telemetry.Tracer == nil {
+		if tm.tracer == nil {
 			// Tracing not enabled, pass through
 			next.ServeHTTP(w, r)
 			return
@@ -38,18 +39,22 @@ func (tm *TracingMiddleware) Handler(next http.Handler) http.Handler {
 		ctx = propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
 
 		// Start a new span for this HTTP request
-		ctx, span := tm.telemetry.Tracer.Start(ctx, "http.request",
+		ctx, span := tm.tracer.Start(ctx, "http.request",
 			trace.WithAttributes(
 				attribute.String("http.method", r.Method),
 				attribute.String("http.url", r.URL.Path),
 				attribute.String("http.scheme", r.URL.Scheme),
 				attribute.String("http.host", r.Host),
 				attribute.String("http.user_agent", r.UserAgent()),
+				attribute.String("http.request_id", logging.RequestID(ctx)),
 			),
 			trace.WithSpanKind(trace.SpanKindServer),
 		)
 		defer span.End()
 
+		// Correlate the request's completion log entry with its trace
+		logging.AddAttrs(ctx, logging.TraceAttrs(ctx)...)
+
 		// Create a response writer wrapper to capture status code
 		wrappedWriter := &statusRecorder{
 			ResponseWriter: w,
@@ -93,3 +98,15 @@ func (sr *statusRecorder) Write(b []byte) (int, error) {
 	sr.written += n
 	return n, err
 }
+
+// Flush supports streaming responses
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
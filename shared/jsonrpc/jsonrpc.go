@@ -0,0 +1,51 @@
+// Package jsonrpc holds the JSON-RPC 2.0 definitions the MCP and A2A endpoints share: the
+// protocol version, the standard error codes and the error object.
+// https://www.jsonrpc.org/specification
+package jsonrpc
+
+// Version is the only JSON-RPC version either server accepts
+const Version = "2.0"
+
+// Standard JSON-RPC error codes
+const (
+	ParseError     = -32700 // Invalid JSON was received
+	InvalidRequest = -32600 // The JSON sent is not a valid Request object
+	MethodNotFound = -32601 // The method does not exist / is not available
+	InvalidParams  = -32602 // Invalid method parameter(s)
+	InternalError  = -32603 // Internal JSON-RPC error
+	ServerError    = -32000 // Generic server error
+)
+
+// Error is a JSON-RPC 2.0 error object
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// SetRequestID adds the HTTP request ID to the error data so a client can quote it when
+// reporting a failed call. Object data gains a request_id field; other data moves under
+// details. An empty ID leaves the error unchanged.
+func (e *Error) SetRequestID(requestID string) {
+	if requestID == "" {
+		return
+	}
+	switch data := e.Data.(type) {
+	case nil:
+		e.Data = map[string]interface{}{"request_id": requestID}
+	case map[string]interface{}:
+		enriched := make(map[string]interface{}, len(data)+1)
+		for key, value := range data {
+			enriched[key] = value
+		}
+		enriched["request_id"] = requestID
+		e.Data = enriched
+	default:
+		e.Data = map[string]interface{}{"details": data, "request_id": requestID}
+	}
+}
@@ -0,0 +1,28 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorSetRequestID(t *testing.T) {
+	data := map[string]interface{}{"retry_after": 5}
+	err := &Error{Code: ServerError, Message: "Rate limiter unavailable", Data: data}
+	err.SetRequestID("req-1")
+	assert.Equal(t, map[string]interface{}{"retry_after": 5, "request_id": "req-1"}, err.Data)
+	assert.NotContains(t, data, "request_id", "shared data is copied, not modified")
+
+	err = &Error{Code: ParseError, Message: "Invalid JSON"}
+	err.SetRequestID("req-2")
+	assert.Equal(t, map[string]interface{}{"request_id": "req-2"}, err.Data)
+
+	err = &Error{Code: InvalidParams, Message: "Invalid params", Data: "query is required"}
+	err.SetRequestID("req-3")
+	assert.Equal(t, map[string]interface{}{"details": "query is required", "request_id": "req-3"}, err.Data)
+
+	err = &Error{Code: InvalidParams, Message: "Invalid params", Data: "query is required"}
+	err.SetRequestID("")
+	assert.Equal(t, "query is required", err.Data)
+	assert.Equal(t, "Invalid params", err.Error())
+}
@@ -0,0 +1,193 @@
+// Package lifecycle tracks in-flight requests and streams so the server can drain them on
+// shutdown: new work is refused, streams are asked to finish, and whatever is still running
+// when the drain period expires is cancelled and counted.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// Kinds of tracked work, used as the metric attribute for forcibly cancelled work
+const (
+	KindRequest = "request"
+	KindStream  = "stream"
+)
+
+// drainRetryAfter is the Retry-After value sent with requests refused while draining
+const drainRetryAfter = "5"
+
+// ErrDrainTimeout is returned by Drain when work was still running at the deadline
+var ErrDrainTimeout = errors.New("drain period expired with work in flight")
+
+// DrainRecorder counts the work cancelled when the drain period expires
+type DrainRecorder interface {
+	RecordDrainCancelled(ctx context.Context, kind string, count int64)
+}
+
+// work is one tracked request or stream
+type work struct {
+	kind   string
+	cancel context.CancelFunc
+}
+
+// workKey is the context key holding the tracked work of a request
+type workKey struct{}
+
+// Manager tracks in-flight work and coordinates draining it
+type Manager struct {
+	metrics DrainRecorder
+
+	mu         sync.Mutex
+	inFlight   map[*work]struct{}
+	draining   bool
+	drainCh    chan struct{}
+	idleCh     chan struct{}
+	idleClosed bool
+}
+
+// NewManager creates a lifecycle manager
+func NewManager() *Manager {
+	return &Manager{
+		inFlight: make(map[*work]struct{}),
+		drainCh:  make(chan struct{}),
+		idleCh:   make(chan struct{}),
+	}
+}
+
+// SetRecorder counts the work cancelled after the drain period in recorder's metrics
+func (m *Manager) SetRecorder(recorder DrainRecorder) {
+	m.metrics = recorder
+}
+
+// Begin registers a unit of work. The returned context is cancelled if the work is still
+// running when the drain period expires, and done must be called when the work finishes.
+// ok is false once draining has started, in which case the work must not be started.
+func (m *Manager) Begin(ctx context.Context, kind string) (workCtx context.Context, done func(), ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.draining {
+		return ctx, func() {}, false
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &work{kind: kind, cancel: cancel}
+	m.inFlight[w] = struct{}{}
+	ctx = context.WithValue(ctx, workKey{}, w)
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel()
+			m.finish(w)
+		})
+	}, true
+}
+
+// finish removes completed work and signals Drain when the last piece finishes
+func (m *Manager) finish(w *work) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.inFlight, w)
+	m.signalIdleLocked()
+}
+
+// signalIdleLocked closes idleCh once draining has started and nothing is in flight
+func (m *Manager) signalIdleLocked() {
+	if m.draining && len(m.inFlight) == 0 && !m.idleClosed {
+		close(m.idleCh)
+		m.idleClosed = true
+	}
+}
+
+// Handler tracks every request through next, refusing new requests with 503 while draining
+func (m *Manager) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, done, ok := m.Begin(r.Context(), KindRequest)
+		if !ok {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", drainRetryAfter)
+			http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		defer done()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Draining returns a channel that is closed when draining starts
+func (m *Manager) Draining() <-chan struct{} {
+	return m.drainCh
+}
+
+// InFlight returns the number of requests and streams currently tracked
+func (m *Manager) InFlight() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.inFlight)
+}
+
+// Stream marks the tracked request in ctx as a long-lived stream and returns the
+// manager's draining channel; the stream should finish when it is closed. Requests
+// not tracked by a manager get a nil channel, which never fires.
+func (m *Manager) Stream(ctx context.Context) <-chan struct{} {
+	w, ok := ctx.Value(workKey{}).(*work)
+	if !ok {
+		return nil
+	}
+
+	m.mu.Lock()
+	w.kind = KindStream
+	m.mu.Unlock()
+	return m.drainCh
+}
+
+// Drain stops accepting new work, signals streams to finish and waits for in-flight work
+// until ctx is done. Work still running at that point is cancelled, recorded in metrics and
+// reported through ErrDrainTimeout.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.draining {
+		m.draining = true
+		close(m.drainCh)
+	}
+	inFlight := len(m.inFlight)
+	m.signalIdleLocked()
+	m.mu.Unlock()
+
+	slog.InfoContext(ctx, "Draining in-flight work", "in_flight", inFlight)
+
+	select {
+	case <-m.idleCh:
+		return nil
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	cancelled := make(map[string]int64)
+	for w := range m.inFlight {
+		w.cancel()
+		cancelled[w.kind]++
+	}
+	m.mu.Unlock()
+
+	var total int64
+	for kind, count := range cancelled {
+		total += count
+		if m.metrics != nil {
+			m.metrics.RecordDrainCancelled(context.Background(), kind, count)
+		}
+		slog.Warn("Cancelled in-flight work after drain period", "kind", kind, "count", count)
+	}
+	if total == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: cancelled %d", ErrDrainTimeout, total)
+}
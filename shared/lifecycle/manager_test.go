@@ -0,0 +1,102 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_DrainWaitsForInFlightWork(t *testing.T) {
+	m := NewManager()
+
+	_, done, ok := m.Begin(context.Background(), KindRequest)
+	require.True(t, ok)
+	assert.Equal(t, 1, m.InFlight())
+
+	drained := make(chan error, 1)
+	go func() { drained <- m.Drain(context.Background()) }()
+
+	select {
+	case <-m.Draining():
+	case <-time.After(time.Second):
+		t.Fatal("draining channel not closed")
+	}
+
+	select {
+	case <-drained:
+		t.Fatal("drain returned with work in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	done()
+	select {
+	case err := <-drained:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not finish after work completed")
+	}
+	assert.Equal(t, 0, m.InFlight())
+}
+
+func TestManager_DrainCancelsWorkAfterDeadline(t *testing.T) {
+	m := NewManager()
+
+	workCtx, done, ok := m.Begin(context.Background(), KindRequest)
+	require.True(t, ok)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := m.Drain(ctx)
+	require.ErrorIs(t, err, ErrDrainTimeout)
+	assert.ErrorIs(t, workCtx.Err(), context.Canceled)
+}
+
+func TestManager_RefusesNewWorkWhileDraining(t *testing.T) {
+	m := NewManager()
+	require.NoError(t, m.Drain(context.Background()))
+
+	_, _, ok := m.Begin(context.Background(), KindRequest)
+	assert.False(t, ok)
+
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler called while draining")
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, drainRetryAfter, rr.Header().Get("Retry-After"))
+}
+
+func TestManager_StreamFinishesOnDrain(t *testing.T) {
+	m := NewManager()
+
+	finished := make(chan struct{})
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(finished)
+		select {
+		case <-m.Stream(r.Context()):
+		case <-r.Context().Done():
+		}
+	}))
+
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil))
+	require.Eventually(t, func() bool { return m.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, m.Drain(ctx))
+	<-finished
+}
+
+func TestManager_StreamUntracked(t *testing.T) {
+	m := NewManager()
+	assert.Nil(t, m.Stream(context.Background()))
+}
@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys added to correlated log entries
+const (
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+	TenantIDKey  = "tenant_id"
+	UserIDKey    = "user_id"
+)
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	attrsContextKey
+)
+
+// requestAttrs holds attributes added while a request is handled. It is shared by every
+// context derived from the request, so attributes added by inner handlers (such as the
+// tenant ID set after authentication) also appear on the request's completion entry.
+type requestAttrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithRequestID returns a context carrying the request ID and a fresh set of request attributes
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDContextKey, requestID)
+	return context.WithValue(ctx, attrsContextKey, &requestAttrs{})
+}
+
+// RequestID returns the request ID carried by the context, if any
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// AddAttrs adds attributes to every later entry logged for the request. It is a no-op
+// for contexts that did not come through the logging middleware.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	holder, ok := ctx.Value(attrsContextKey).(*requestAttrs)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	for _, attr := range attrs {
+		holder.attrs = setAttr(holder.attrs, attr)
+	}
+}
+
+// snapshot returns a copy of the attributes
+func (r *requestAttrs) snapshot() []slog.Attr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]slog.Attr(nil), r.attrs...)
+}
+
+// ContextHandler adds request correlation attributes from the context to every record
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// Ensure ContextHandler implements slog.Handler
+var _ slog.Handler = (*ContextHandler)(nil)
+
+// NewContextHandler wraps a handler with request correlation
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at the level
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the request ID, request attributes and the current trace and span IDs, then
+// passes the record on. The active span takes precedence over a trace ID added to the
+// request attributes, so entries inside child spans carry their own span ID.
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx == nil {
+		return h.next.Handle(ctx, record)
+	}
+
+	var attrs []slog.Attr
+	if requestID := RequestID(ctx); requestID != "" {
+		attrs = append(attrs, slog.String(RequestIDKey, requestID))
+	}
+	if holder, ok := ctx.Value(attrsContextKey).(*requestAttrs); ok {
+		attrs = append(attrs, holder.snapshot()...)
+	}
+	for _, attr := range TraceAttrs(ctx) {
+		attrs = setAttr(attrs, attr)
+	}
+	record.AddAttrs(attrs...)
+
+	return h.next.Handle(ctx, record)
+}
+
+// TraceAttrs returns the trace and span ID attributes of the span in the context, if any
+func TraceAttrs(ctx context.Context) []slog.Attr {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String(TraceIDKey, spanCtx.TraceID().String()),
+		slog.String(SpanIDKey, spanCtx.SpanID().String()),
+	}
+}
+
+// setAttr replaces an attribute with the same key or appends it
+func setAttr(attrs []slog.Attr, attr slog.Attr) []slog.Attr {
+	for i := range attrs {
+		if attrs[i].Key == attr.Key {
+			attrs[i] = attr
+			return attrs
+		}
+	}
+	return append(attrs, attr)
+}
+
+// WithAttrs returns a handler whose records include the attributes
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that qualifies later attributes with the group name
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,107 @@
+// Package logging configures structured logging with log/slog. Every entry logged with a
+// request context carries the request ID, trace and span IDs, and any attributes added
+// during the request (such as the tenant ID), so logs can be correlated with traces.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Log formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config holds logging configuration
+type Config struct {
+	// Level is the minimum level logged: debug, info, warn or error (default info)
+	Level string `yaml:"level"`
+	// Format is json or text (default json)
+	Format string `yaml:"format"`
+	// AddSource adds the source file and line to every entry
+	AddSource bool `yaml:"add_source"`
+}
+
+// level is the minimum level of the logger installed by Setup; SetLevel changes it at runtime
+var level = new(slog.LevelVar)
+
+// ParseLevel parses a log level name
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", level)
+	}
+}
+
+// New creates a logger writing to w that adds request correlation attributes from the context
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	minLevel, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(w, cfg, minLevel)
+}
+
+// newLogger creates a logger with the given minimum level
+func newLogger(w io.Writer, cfg Config, minLevel slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: minLevel, AddSource: cfg.AddSource}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected %s or %s)", cfg.Format, FormatJSON, FormatText)
+	}
+
+	return slog.New(NewContextHandler(handler)), nil
+}
+
+// Setup creates a logger writing to w and makes it the default, so slog's package-level
+// functions and the standard log package both write through it. Its level can be changed
+// later with SetLevel.
+func Setup(w io.Writer, cfg Config) (*slog.Logger, error) {
+	if err := SetLevel(cfg.Level); err != nil {
+		return nil, err
+	}
+	logger, err := newLogger(w, cfg, level)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	// Lines from the standard log package are already timestamped by slog
+	log.SetFlags(0)
+	return logger, nil
+}
+
+// SetLevel changes the minimum level of the logger installed by Setup
+func SetLevel(name string) error {
+	parsed, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// Fatal logs an error and exits, the slog counterpart of log.Fatalf
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
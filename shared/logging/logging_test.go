@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	return entry
+}
+
+func TestNew_Formats(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Format: FormatJSON})
+	require.NoError(t, err)
+	logger.Info("hello", "key", "value")
+	entry := decode(t, &buf)
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "value", entry["key"])
+
+	buf.Reset()
+	logger, err = New(&buf, Config{Format: FormatText})
+	require.NoError(t, err)
+	logger.Info("hello", "key", "value")
+	assert.Contains(t, buf.String(), "msg=hello key=value")
+
+	_, err = New(&buf, Config{Format: "xml"})
+	assert.Error(t, err)
+	_, err = New(&buf, Config{Level: "verbose"})
+	assert.Error(t, err)
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: "warn"})
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	assert.Empty(t, buf.String())
+	logger.Warn("kept")
+	assert.Equal(t, "kept", decode(t, &buf)["msg"])
+}
+
+func TestContextHandler_RequestCorrelation(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{})
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	AddAttrs(ctx, slog.String(TenantIDKey, "tenant-a"))
+	AddAttrs(ctx, slog.String(TenantIDKey, "tenant-b"))
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+
+	logger.InfoContext(ctx, "handled")
+	entry := decode(t, &buf)
+	assert.Equal(t, "req-1", entry[RequestIDKey])
+	assert.Equal(t, "tenant-b", entry[TenantIDKey])
+	assert.Equal(t, traceID.String(), entry[TraceIDKey])
+	assert.Equal(t, spanID.String(), entry[SpanIDKey])
+}
+
+func TestContextHandler_NoRequestContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{})
+	require.NoError(t, err)
+
+	// Adding attributes outside a request is a no-op
+	AddAttrs(context.Background(), slog.String(TenantIDKey, "tenant-a"))
+	logger.InfoContext(context.Background(), "background")
+
+	entry := decode(t, &buf)
+	assert.NotContains(t, entry, RequestIDKey)
+	assert.NotContains(t, entry, TenantIDKey)
+	assert.NotContains(t, entry, TraceIDKey)
+}
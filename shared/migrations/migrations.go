@@ -0,0 +1,287 @@
+// Package migrations versions a Postgres schema. Migrations are SQL files in an sql
+// directory, usually embedded in the binary, named NNNN_description.up.sql with an
+// optional NNNN_description.down.sql. They are applied in version order, each in its own
+// transaction that also records it in a version table. An advisory lock keeps replicas
+// that start together from applying the same migration twice.
+//
+// The MCP and A2A servers each version their own tables in the same database, with
+// their own migrations and version table.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fileName matches migration files: version, description and direction
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down reverts Up; empty when the migration cannot be rolled back
+	Down string
+}
+
+// Status is a migration and when it was applied
+type Status struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// AppliedAt is nil for pending migrations
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Conn is the connection migrations run on; *pgx.Conn and *pgxpool.Conn implement it.
+// Advisory locks are held by a session, so it must be a single connection, not a pool.
+type Conn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Migrator applies and reverts migrations on one database
+type Migrator struct {
+	conn       Conn
+	table      string
+	migrations []Migration
+}
+
+// New returns a migrator for the migrations in the sql directory of fsys, recorded in
+// table
+func New(conn Conn, table string, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(conn, table, migrations), nil
+}
+
+// NewWithMigrations returns a migrator for migrations, recorded in table
+func NewWithMigrations(conn Conn, table string, migrations []Migration) *Migrator {
+	return &Migrator{conn: conn, table: table, migrations: migrations}
+}
+
+// Load reads the migrations in the sql directory of fsys, sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q: expected NNNN_description.up.sql or .down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q: versions start at 1", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest returns the version of the newest migration, 0 when there are none
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies the pending migrations in version order and returns them. Versions recorded
+// in the database that this binary does not know, e.g. from a newer release, are left alone.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func() error {
+		done, err := m.appliedVersions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, migration, migration.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the newest applied migration and returns it, or nil when none is applied
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := m.locked(ctx, func() error {
+		done, err := m.appliedVersions(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d (%s) cannot be rolled back: it has no down migration", migration.Version, migration.Name)
+			}
+			if err := m.apply(ctx, migration, migration.Down, false); err != nil {
+				return err
+			}
+			reverted = &migration
+			return nil
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration with when it was applied, followed by applied
+// versions this binary does not know
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := m.conn.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, m.table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check for %s: %w", m.table, err)
+	}
+	done := map[int]Status{}
+	if exists {
+		var err error
+		if done, err = m.appliedVersions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if applied, ok := done[migration.Version]; ok {
+			status.AppliedAt = applied.AppliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	unknown := make([]Status, 0, len(done))
+	for _, applied := range done {
+		unknown = append(unknown, applied)
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+	return append(statuses, unknown...), nil
+}
+
+// locked runs fn holding the migrations' advisory lock, after creating the version table
+func (m *Migrator) locked(ctx context.Context, fn func() error) error {
+	if _, err := m.conn.Exec(ctx, `SELECT pg_advisory_lock(hashtext($1))`, m.table); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer m.conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, m.table)
+
+	_, err := m.conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+m.identifier()+` (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", m.table, err)
+	}
+	return fn()
+}
+
+// appliedVersions returns the versions recorded in the version table
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]Status, error) {
+	rows, err := m.conn.Query(ctx, `SELECT version, name, applied_at FROM `+m.identifier())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", m.table, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]Status)
+	for rows.Next() {
+		var status Status
+		var appliedAt time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", m.table, err)
+		}
+		status.AppliedAt = &appliedAt
+		applied[status.Version] = status
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", m.table, err)
+	}
+	return applied, nil
+}
+
+// apply runs the SQL of a migration and records (up) or forgets (down) it in one transaction
+func (m *Migrator) apply(ctx context.Context, migration Migration, sql string, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("migration %d (%s) %s failed: %w", migration.Version, migration.Name, direction, err)
+	}
+	if up {
+		_, err = tx.Exec(ctx, `INSERT INTO `+m.identifier()+` (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM `+m.identifier()+` WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// identifier returns the quoted version table name
+func (m *Migrator) identifier() string {
+	return pgx.Identifier{m.table}.Sanitize()
+}
@@ -0,0 +1,87 @@
+//go:build integration
+// +build integration
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests for the migrator; they need the schema owner, not app_user
+// Run with: go test -tags=integration -v ./migrations/
+
+func connect(t *testing.T) *pgx.Conn {
+	t.Helper()
+	connString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		getEnvOrDefault("DB_HOST", "localhost"), getEnvOrDefault("DB_PORT", "5432"),
+		getEnvOrDefault("DB_MIGRATION_USER", "mcp_user"), getEnvOrDefault("DB_PASSWORD", "mcp_password"),
+		getEnvOrDefault("DB_NAME", "mcp_db"))
+	conn, err := pgx.Connect(context.Background(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(context.Background()) })
+	return conn
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	ctx := context.Background()
+	conn := connect(t)
+	table := "test_schema_migrations"
+	cleanup := func() {
+		conn.Exec(ctx, `DROP TABLE IF EXISTS migration_test, `+table)
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	migrations, err := Load(fstest.MapFS{
+		"sql/0001_create.up.sql":   {Data: []byte("CREATE TABLE migration_test (id INT);")},
+		"sql/0001_create.down.sql": {Data: []byte("DROP TABLE migration_test;")},
+		"sql/0002_column.up.sql":   {Data: []byte("ALTER TABLE migration_test ADD COLUMN name TEXT;")},
+		"sql/0002_column.down.sql": {Data: []byte("ALTER TABLE migration_test DROP COLUMN name;")},
+		"sql/0003_broken.down.sql": {Data: []byte("SELECT 1;")},
+		"sql/0003_broken.up.sql":   {Data: []byte("ALTER TABLE migration_test ADD COLUMN name TEXT;")},
+	})
+	require.NoError(t, err)
+
+	m := NewWithMigrations(conn, table, migrations[:2])
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, statuses[0].AppliedAt)
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+	_, err = conn.Exec(ctx, `INSERT INTO migration_test (id, name) VALUES (1, 'a')`)
+	require.NoError(t, err)
+
+	// A failing migration is rolled back and not recorded
+	_, err = NewWithMigrations(conn, table, migrations).Up(ctx)
+	assert.ErrorContains(t, err, "migration 3 (broken) up failed")
+	statuses, err = NewWithMigrations(conn, table, migrations).Status(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, statuses[2].AppliedAt)
+
+	reverted, err := m.Down(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reverted.Version)
+	reverted, err = m.Down(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted.Version)
+	reverted, err = m.Down(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, reverted)
+}
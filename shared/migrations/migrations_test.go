@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_add_tags.up.sql":   {Data: []byte("ALTER TABLE documents ADD COLUMN tags TEXT[];")},
+		"sql/0002_add_tags.down.sql": {Data: []byte("ALTER TABLE documents DROP COLUMN tags;")},
+		"sql/0001_initial.up.sql":    {Data: []byte("CREATE TABLE documents (id UUID);")},
+		"sql/0010_index.up.sql":      {Data: []byte("CREATE INDEX idx ON documents(id);")},
+	}
+
+	migrations, err := Load(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "initial", Up: "CREATE TABLE documents (id UUID);"},
+		{Version: 2, Name: "add_tags", Up: "ALTER TABLE documents ADD COLUMN tags TEXT[];", Down: "ALTER TABLE documents DROP COLUMN tags;"},
+		{Version: 10, Name: "index", Up: "CREATE INDEX idx ON documents(id);"},
+	}, migrations)
+	assert.Equal(t, 10, NewWithMigrations(nil, "schema_migrations", migrations).Latest())
+	assert.Zero(t, NewWithMigrations(nil, "schema_migrations", nil).Latest())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":      {"sql/initial.up.sql": {Data: []byte("SELECT 1")}},
+		"no direction":  {"sql/0001_initial.sql": {Data: []byte("SELECT 1")}},
+		"version zero":  {"sql/0000_initial.up.sql": {Data: []byte("SELECT 1")}},
+		"down only":     {"sql/0001_initial.down.sql": {Data: []byte("SELECT 1")}},
+		"two names":     {"sql/0001_a.up.sql": {Data: []byte("SELECT 1")}, "sql/0001_b.up.sql": {Data: []byte("SELECT 1")}},
+		"no sql folder": {"0001_initial.up.sql": {Data: []byte("SELECT 1")}},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
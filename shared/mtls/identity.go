@@ -0,0 +1,96 @@
+package mtls
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/bhatti/mcp-a2a-go/shared/logging"
+)
+
+// IdentityKey is the log attribute of the client certificate's identity
+const IdentityKey = "client_identity"
+
+// identityKey is the context key of the client certificate's identity
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the identity of the client certificate
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the identity of the request's client certificate, if it has one
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Middleware requires a verified client certificate on TLS requests and maps it to its
+// identity, refusing certificates that match none
+type Middleware struct {
+	identities map[string]Identity
+	exempt     map[string]bool
+}
+
+// NewMiddleware creates a middleware for the identities. Without identities every
+// verified client certificate is accepted and none is mapped.
+func NewMiddleware(identities []Identity) *Middleware {
+	m := &Middleware{
+		identities: make(map[string]Identity, len(identities)),
+		exempt:     make(map[string]bool),
+	}
+	for _, identity := range identities {
+		m.identities[identity.SAN] = identity
+	}
+	return m
+}
+
+// SetExemptPaths serves paths without a client certificate, e.g. the health probes of
+// an orchestrator that cannot present one
+func (m *Middleware) SetExemptPaths(paths ...string) {
+	for _, path := range paths {
+		m.exempt[path] = true
+	}
+}
+
+// Identify returns the identity of the first SAN of the request's client certificate
+// that is mapped to one
+func (m *Middleware) Identify(r *http.Request) (Identity, bool) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return Identity{}, false
+	}
+	for _, san := range SANs(r.TLS.PeerCertificates[0]) {
+		if identity, ok := m.identities[san]; ok {
+			return identity, true
+		}
+	}
+	return Identity{}, false
+}
+
+// Handler wraps an HTTP handler with the client certificate check. Requests that did
+// not arrive over TLS are passed through.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || m.exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "Client certificate required", http.StatusUnauthorized)
+			return
+		}
+		if len(m.identities) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, ok := m.Identify(r)
+		if !ok {
+			slog.WarnContext(r.Context(), "Client certificate matches no identity", "sans", SANs(r.TLS.PeerCertificates[0]))
+			http.Error(w, "Client certificate not allowed", http.StatusForbidden)
+			return
+		}
+		logging.AddAttrs(r.Context(), slog.String(IdentityKey, identity.String()))
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
@@ -0,0 +1,298 @@
+// Package mtls provides mutual TLS between the services: listener and client TLS
+// configurations whose certificates are reloaded when the files are rotated, and the
+// identities that client certificates are mapped to by their subject alternative names.
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often the certificate files are checked for changes
+const DefaultReloadInterval = time.Minute
+
+// Config configures the certificate a service presents and the CA it trusts
+type Config struct {
+	CertFile string `yaml:"cert_file"` // PEM certificate chain; empty disables TLS
+	KeyFile  string `yaml:"key_file"`  // PEM private key of the certificate
+	// CAFile is the PEM bundle peer certificates are verified against. On a listener it
+	// turns on mutual TLS: clients must present a certificate signed by it.
+	CAFile string `yaml:"ca_file"`
+	// ReloadInterval is how often the files are checked for rotated certificates
+	// (default 1m, negative disables reloading)
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+	// Identities maps client certificate SANs to tenants and services; when set, clients
+	// whose certificate matches none are refused
+	Identities []Identity `yaml:"identities"`
+}
+
+// Enabled reports whether TLS is configured
+func (c Config) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		if c.KeyFile != "" || c.CAFile != "" || len(c.Identities) > 0 {
+			return fmt.Errorf("cert_file is required with key_file, ca_file or identities")
+		}
+		return nil
+	}
+	var errs []error
+	if c.KeyFile == "" {
+		errs = append(errs, fmt.Errorf("key_file is required with cert_file"))
+	}
+	if len(c.Identities) > 0 && c.CAFile == "" {
+		errs = append(errs, fmt.Errorf("identities need ca_file to verify client certificates"))
+	}
+	for i, identity := range c.Identities {
+		if err := identity.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("identities[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Certificates holds the certificate, key and CA pool loaded from a Config and reloads
+// them when the files change. The TLS configurations it returns always use the latest
+// files, so rotated certificates apply to new connections without a restart.
+type Certificates struct {
+	config Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// Load reads the certificate, key and CA files of cfg
+func Load(cfg Config) (*Certificates, error) {
+	if cfg.ReloadInterval == 0 {
+		cfg.ReloadInterval = DefaultReloadInterval
+	}
+	c := &Certificates{config: cfg}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// files returns the files the certificates are loaded from
+func (c *Certificates) files() []string {
+	files := []string{c.config.CertFile, c.config.KeyFile}
+	if c.config.CAFile != "" {
+		files = append(files, c.config.CAFile)
+	}
+	return files
+}
+
+// Reload reads the files again. If one cannot be read or parsed, the current
+// certificates stay in use and an error is returned.
+func (c *Certificates) Reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range c.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.config.CertFile, c.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate %s: %w", c.config.CertFile, err)
+	}
+	var pool *x509.CertPool
+	if c.config.CAFile != "" {
+		data, err := os.ReadFile(c.config.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in %s", c.config.CAFile)
+		}
+	}
+
+	c.mu.Lock()
+	c.cert, c.pool, c.modTimes = &cert, pool, modTimes
+	c.mu.Unlock()
+	return nil
+}
+
+// changed reports whether any file was modified since it was last loaded
+func (c *Certificates) changed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, file := range c.files() {
+		if info, err := os.Stat(file); err == nil && !info.ModTime().Equal(c.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start reloads the certificates every ReloadInterval when the files changed, until ctx
+// is cancelled. Failed reloads are logged and the previous certificates stay in use.
+func (c *Certificates) Start(ctx context.Context) {
+	if c.config.ReloadInterval < 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(c.config.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !c.changed() {
+					continue
+				}
+				if err := c.Reload(); err != nil {
+					slog.WarnContext(ctx, "TLS certificate reload failed, keeping current certificate", "error", err)
+					continue
+				}
+				slog.InfoContext(ctx, "TLS certificate reloaded", "cert_file", c.config.CertFile)
+			}
+		}
+	}()
+}
+
+// current returns the loaded certificate and CA pool
+func (c *Certificates) current() (*tls.Certificate, *x509.CertPool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, c.pool
+}
+
+// ServerConfig returns the TLS configuration of a listener. With a CA file, client
+// certificates are verified against it; the Middleware then requires one on every path
+// but those exempt, such as health probes that cannot present one.
+func (c *Certificates) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := c.current()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if pool != nil {
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				config.ClientCAs = pool
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig returns the TLS configuration of a client presenting the certificate.
+// With a CA file, servers are verified against it instead of the system roots.
+func (c *Certificates) ClientConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := c.current()
+			return cert, nil
+		},
+	}
+	if c.config.CAFile == "" {
+		return config
+	}
+
+	// RootCAs cannot change after the config is used, so the server's chain is verified
+	// here against the current pool, as crypto/tls would with RootCAs
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		_, pool := c.current()
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("server presented no certificate")
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+			DNSName:       state.ServerName,
+			Roots:         pool,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+	return config
+}
+
+// Identity is what a client certificate stands for: a tenant, whose requests it may
+// only make, or a service, which makes requests for any tenant
+type Identity struct {
+	// SAN matches a DNS name, URI (e.g. a SPIFFE ID), email address or IP address of
+	// the certificate
+	SAN      string `yaml:"san"`
+	TenantID string `yaml:"tenant_id"`
+	Service  string `yaml:"service"`
+}
+
+// validate checks that an identity names exactly one tenant or service
+func (i Identity) validate() error {
+	if i.SAN == "" {
+		return fmt.Errorf("san is required")
+	}
+	if (i.TenantID == "") == (i.Service == "") {
+		return fmt.Errorf("one of tenant_id and service is required for %s", i.SAN)
+	}
+	return nil
+}
+
+// String names the identity in logs
+func (i Identity) String() string {
+	if i.TenantID != "" {
+		return "tenant:" + i.TenantID
+	}
+	return "service:" + i.Service
+}
+
+// ParseIdentities parses a comma-separated list of san=tenant:<id> or san=service:<name>
+// pairs, e.g. "spiffe://mesh/a2a-server=service:a2a-server,acme.clients=tenant:1111...".
+// Malformed entries are kept without a tenant or service, so Validate reports them.
+func ParseIdentities(s string) []Identity {
+	var identities []Identity
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		san, value, _ := strings.Cut(pair, "=")
+		identity := Identity{SAN: strings.TrimSpace(san)}
+		switch kind, name, _ := strings.Cut(strings.TrimSpace(value), ":"); kind {
+		case "tenant":
+			identity.TenantID = name
+		case "service":
+			identity.Service = name
+		}
+		identities = append(identities, identity)
+	}
+	return identities
+}
+
+// SANs returns the subject alternative names of a certificate
+func SANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
@@ -0,0 +1,243 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues certificates for the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate for the SANs and its key into dir, returning their paths
+func (ca *testCA) issue(t *testing.T, dir, name string, sans ...string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if u, err := url.Parse(san); err == nil && u.Scheme != "" {
+			template.URIs = append(template.URIs, u)
+		} else {
+			template.DNSNames = append(template.DNSNames, san)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+// writeCA writes the CA certificate into dir
+func (ca *testCA) write(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(path, ca.pem, 0600))
+	return path
+}
+
+// startTLSServer starts an HTTPS test server presenting certificates
+func startTLSServer(t *testing.T, certificates *Certificates, handler http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = certificates.ServerConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// get sends a GET with a client using config
+func get(t *testing.T, config *tls.Config, url string) (*http.Response, error) {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get(url)
+	if err == nil {
+		t.Cleanup(func() { resp.Body.Close() })
+	}
+	return resp, err
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{CertFile: "c.pem", KeyFile: "k.pem", CAFile: "ca.pem",
+		Identities: []Identity{{SAN: "a2a.internal", Service: "a2a-server"}}}.Validate())
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{"key without cert", Config{KeyFile: "k.pem"}, "cert_file is required"},
+		{"cert without key", Config{CertFile: "c.pem"}, "key_file is required"},
+		{"identities without CA", Config{CertFile: "c.pem", KeyFile: "k.pem",
+			Identities: []Identity{{SAN: "a", Service: "s"}}}, "ca_file"},
+		{"tenant and service", Config{CertFile: "c.pem", KeyFile: "k.pem", CAFile: "ca.pem",
+			Identities: []Identity{{SAN: "a", Service: "s", TenantID: "t"}}}, "identities[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.config.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseIdentities(t *testing.T) {
+	identities := ParseIdentities("spiffe://mesh/a2a-server=service:a2a-server, acme.clients=tenant:1111,broken")
+	assert.Equal(t, []Identity{
+		{SAN: "spiffe://mesh/a2a-server", Service: "a2a-server"},
+		{SAN: "acme.clients", TenantID: "1111"},
+		{SAN: "broken"},
+	}, identities)
+	assert.Error(t, identities[2].validate(), "malformed entries fail validation")
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test CA")
+	certFile, keyFile := ca.issue(t, dir, "server", "127.0.0.1")
+	clientCert, clientKey := ca.issue(t, dir, "client", "spiffe://mesh/a2a-server")
+	caFile := ca.write(t, dir)
+
+	serverCerts, err := Load(Config{CertFile: certFile, KeyFile: keyFile, CAFile: caFile})
+	require.NoError(t, err)
+	clientCerts, err := Load(Config{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile})
+	require.NoError(t, err)
+
+	var identity Identity
+	middleware := NewMiddleware([]Identity{{SAN: "spiffe://mesh/a2a-server", Service: "a2a-server"}})
+	middleware.SetExemptPaths("/healthz")
+	server := startTLSServer(t, serverCerts, middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = IdentityFrom(r.Context())
+	})))
+
+	resp, err := get(t, clientCerts.ClientConfig(), server.URL+"/mcp")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, Identity{SAN: "spiffe://mesh/a2a-server", Service: "a2a-server"}, identity)
+
+	// Without a client certificate only exempt paths are served
+	anonymous := &tls.Config{RootCAs: x509.NewCertPool()}
+	anonymous.RootCAs.AddCert(ca.cert)
+	resp, err = get(t, anonymous, server.URL+"/mcp")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, err = get(t, anonymous, server.URL+"/healthz")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A certificate of the CA whose SAN is not mapped is refused
+	otherCert, otherKey := ca.issue(t, dir, "other", "other.internal")
+	otherCerts, err := Load(Config{CertFile: otherCert, KeyFile: otherKey, CAFile: caFile})
+	require.NoError(t, err)
+	resp, err = get(t, otherCerts.ClientConfig(), server.URL+"/mcp")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "not allowed")
+
+	// Certificates of another CA fail the handshake, both ways
+	rogue := newTestCA(t, "rogue CA")
+	rogueCert, rogueKey := rogue.issue(t, t.TempDir(), "client", "spiffe://mesh/a2a-server")
+	rogueCerts, err := Load(Config{CertFile: rogueCert, KeyFile: rogueKey, CAFile: caFile})
+	require.NoError(t, err)
+	_, err = get(t, rogueCerts.ClientConfig(), server.URL+"/mcp")
+	assert.Error(t, err)
+
+	rogueServerCerts, err := Load(Config{CertFile: rogueCert, KeyFile: rogueKey})
+	require.NoError(t, err)
+	rogueServer := startTLSServer(t, rogueServerCerts, http.NotFoundHandler())
+	_, err = get(t, clientCerts.ClientConfig(), rogueServer.URL)
+	assert.Error(t, err, "the client verifies the server against the CA")
+}
+
+func TestCertificates_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test CA")
+	certFile, keyFile := ca.issue(t, dir, "server", "127.0.0.1")
+
+	certificates, err := Load(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	server := startTLSServer(t, certificates, http.NotFoundHandler())
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	served := func() *big.Int {
+		resp, err := get(t, &tls.Config{RootCAs: pool}, server.URL)
+		require.NoError(t, err)
+		return resp.TLS.PeerCertificates[0].SerialNumber
+	}
+	first := served()
+	assert.False(t, certificates.changed())
+
+	// Rotate the certificate in place
+	ca.issue(t, dir, "server", "127.0.0.1")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	assert.True(t, certificates.changed())
+	require.NoError(t, certificates.Reload())
+	assert.NotEqual(t, first, served(), "new connections get the rotated certificate")
+
+	// A broken file keeps the current certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0600))
+	assert.Error(t, certificates.Reload())
+	assert.NotNil(t, served())
+}
@@ -0,0 +1,241 @@
+// Package ratelimit limits how many requests each caller makes per window, counting them
+// in Redis so every replica of a server holds callers to one shared limit. Both the MCP
+// and A2A servers limit their callers with it.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Failure policies, deciding requests while Redis cannot be reached and the local
+// fallback is off
+const (
+	// FailOpen lets requests through unlimited
+	FailOpen = "fail_open"
+	// FailClosed refuses requests with 503 Service Unavailable
+	FailClosed = "fail_closed"
+)
+
+// unavailableRetryAfter is the Retry-After of requests refused while Redis is unavailable
+const unavailableRetryAfter = 5 * time.Second
+
+// ErrUnavailable is returned when a request is refused because Redis cannot be reached
+// and the failure policy is FailClosed
+var ErrUnavailable = errors.New("rate limiter unavailable")
+
+// ValidatePolicy checks that policy is a known failure policy
+func ValidatePolicy(policy string) error {
+	switch policy {
+	case FailOpen, FailClosed:
+		return nil
+	}
+	return fmt.Errorf("must be %q or %q, got %q", FailOpen, FailClosed, policy)
+}
+
+// DegradedRecorder counts rate limit decisions made without Redis
+type DegradedRecorder interface {
+	RecordRateLimitDegraded(ctx context.Context, policy, decision string)
+}
+
+// Limiter counts each key's requests in fixed windows in Redis
+type Limiter struct {
+	redis  *redis.Client
+	prefix string
+	limit  atomic.Int64 // requests per window
+	window time.Duration
+
+	// keyLimit returns a key's own limit, or 0 for the default; nil applies the default
+	// to every key
+	keyLimit func(ctx context.Context, key string) int
+
+	// Decisions while Redis is unavailable
+	failurePolicy string
+	fallback      *localLimiter
+	recorder      DegradedRecorder
+}
+
+// NewLimiter creates a limiter allowing limit requests per key and minute, counted in
+// Redis under keys starting with prefix
+func NewLimiter(client *redis.Client, prefix string, limit int) *Limiter {
+	l := &Limiter{
+		redis:         client,
+		prefix:        prefix,
+		window:        time.Minute,
+		failurePolicy: FailOpen,
+	}
+	l.SetLimit(limit)
+	return l
+}
+
+// SetLimit changes the limit in requests per window
+func (l *Limiter) SetLimit(limit int) {
+	l.limit.Store(int64(limit))
+}
+
+// Limit returns the limit in requests per window
+func (l *Limiter) Limit() int {
+	return int(l.limit.Load())
+}
+
+// Window returns the window requests are counted in
+func (l *Limiter) Window() time.Duration {
+	return l.window
+}
+
+// SetFailurePolicy decides requests while Redis is unavailable. With localFallback each
+// server counts requests in memory and holds every key to the limit on its own, so a key
+// may get up to one limit per server; without it, policy lets every request through
+// (FailOpen) or refuses them (FailClosed).
+func (l *Limiter) SetFailurePolicy(policy string, localFallback bool) {
+	l.failurePolicy = policy
+	l.fallback = nil
+	if localFallback {
+		l.fallback = newLocalLimiter(l.window)
+	}
+}
+
+// SetKeyLimit lets keys have their own limits: keyLimit returns a key's limit in requests
+// per window, or 0 to hold it to the default. It is called on every request, so it
+// should cache limits looked up elsewhere.
+func (l *Limiter) SetKeyLimit(keyLimit func(ctx context.Context, key string) int) {
+	l.keyLimit = keyLimit
+}
+
+// KeyLimit returns the limit in requests per window the key is held to
+func (l *Limiter) KeyLimit(ctx context.Context, key string) int {
+	return int(l.limitFor(ctx, key))
+}
+
+// limitFor returns the key's own limit, or the default when it has none
+func (l *Limiter) limitFor(ctx context.Context, key string) int64 {
+	if l.keyLimit != nil {
+		if limit := l.keyLimit(ctx, key); limit > 0 {
+			return int64(limit)
+		}
+	}
+	return l.limit.Load()
+}
+
+// SetRecorder counts decisions made while Redis is unavailable
+func (l *Limiter) SetRecorder(recorder DegradedRecorder) {
+	l.recorder = recorder
+}
+
+// Allow counts a request of key and reports whether it is within the limit. While Redis
+// is unavailable the failure policy decides, and ErrUnavailable is returned for requests
+// it refuses.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, error) {
+	allowed, err := l.count(ctx, key)
+	if err == nil {
+		return allowed, nil
+	}
+
+	allowed, policy := l.degraded(ctx, key)
+	slog.WarnContext(ctx, "Rate limit check failed; deciding without Redis",
+		"key", key, "policy", policy, "allowed", allowed, "error", err)
+	if !allowed && policy == FailClosed {
+		return false, ErrUnavailable
+	}
+	return allowed, nil
+}
+
+// count counts a request of key in Redis and reports whether it is within the limit
+func (l *Limiter) count(ctx context.Context, key string) (bool, error) {
+	counter := fmt.Sprintf("%s:%s:%d", l.prefix, key, time.Now().UnixNano()/int64(l.window))
+
+	count, err := l.redis.Incr(ctx, counter).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to increment counter: %w", err)
+	}
+	// Set expiration on first request
+	if count == 1 {
+		l.redis.Expire(ctx, counter, l.window)
+	}
+	return count <= l.limitFor(ctx, key), nil
+}
+
+// degraded decides a request while Redis is unavailable and returns the policy that
+// decided it: the local fallback limiter when enabled, the failure policy otherwise
+func (l *Limiter) degraded(ctx context.Context, key string) (bool, string) {
+	policy, allowed := "local", true
+	switch {
+	case l.fallback != nil:
+		allowed = l.fallback.allow(key, l.limitFor(ctx, key))
+	case l.failurePolicy == FailClosed:
+		policy, allowed = FailClosed, false
+	default:
+		policy = FailOpen
+	}
+
+	if l.recorder != nil {
+		decision := "allowed"
+		if !allowed {
+			decision = "denied"
+		}
+		l.recorder.RecordRateLimitDegraded(ctx, policy, decision)
+	}
+	return allowed, policy
+}
+
+// Handler wraps an HTTP handler, limiting the requests of the key that key returns for
+// them: 429 Too Many Requests once a key used up its limit, 503 Service Unavailable when
+// Redis is down and the failure policy refuses requests. Requests key returns "" for are
+// not limited.
+func (l *Limiter) Handler(key func(r *http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k := key(r)
+		if k == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, err := l.Allow(r.Context(), k)
+		switch {
+		case errors.Is(err, ErrUnavailable):
+			w.Header().Set("Retry-After", strconv.Itoa(int(unavailableRetryAfter.Seconds())))
+			http.Error(w, "Rate limiter unavailable", http.StatusServiceUnavailable)
+			return
+		case !allowed:
+			w.Header().Set("Retry-After", strconv.Itoa(int(l.window.Seconds())))
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// localLimiter counts requests per key in memory in fixed windows, standing in for Redis
+// while it is unavailable
+type localLimiter struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	current int64
+	counts  map[string]int64
+}
+
+func newLocalLimiter(window time.Duration) *localLimiter {
+	return &localLimiter{window: window, counts: make(map[string]int64)}
+}
+
+// allow counts a request of the key and reports whether it is within limit
+func (l *localLimiter) allow(key string, limit int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if current := time.Now().UnixNano() / int64(l.window); current != l.current {
+		l.current = current
+		clear(l.counts)
+	}
+	l.counts[key]++
+	return l.counts[key] <= limit
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := NewLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test:ratelimit", 2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, err := limiter.Allow(ctx, "user-1")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := limiter.Allow(ctx, "user-1")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Keys are limited separately
+	allowed, err = limiter.Allow(ctx, "user-2")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.NotEmpty(t, mr.Keys())
+}
+
+func TestLimiter_KeyLimit(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := NewLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test:ratelimit", 2)
+	limiter.SetKeyLimit(func(ctx context.Context, key string) int {
+		if key == "big" {
+			return 4
+		}
+		return 0
+	})
+	ctx := context.Background()
+
+	allowed := func(key string) int {
+		n := 0
+		for i := 0; i < 6; i++ {
+			ok, err := limiter.Allow(ctx, key)
+			require.NoError(t, err)
+			if ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	// A key's own limit overrides the default; keys without one keep the default
+	assert.Equal(t, 4, allowed("big"))
+	assert.Equal(t, 2, allowed("small"))
+	assert.Equal(t, 4, limiter.KeyLimit(ctx, "big"))
+	assert.Equal(t, 2, limiter.KeyLimit(ctx, "small"))
+}
+
+type recorder struct{ decisions []string }
+
+func (r *recorder) RecordRateLimitDegraded(ctx context.Context, policy, decision string) {
+	r.decisions = append(r.decisions, policy+":"+decision)
+}
+
+func TestLimiter_RedisUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	mr.Close()
+	ctx := context.Background()
+
+	t.Run("local fallback", func(t *testing.T) {
+		limiter := NewLimiter(client, "test:ratelimit", 1)
+		limiter.SetFailurePolicy(FailClosed, true)
+		rec := &recorder{}
+		limiter.SetRecorder(rec)
+		allowed, err := limiter.Allow(ctx, "user-1")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		allowed, err = limiter.Allow(ctx, "user-1")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, []string{"local:allowed", "local:denied"}, rec.decisions)
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		limiter := NewLimiter(client, "test:ratelimit", 1)
+		for i := 0; i < 3; i++ {
+			allowed, err := limiter.Allow(ctx, "user-1")
+			require.NoError(t, err)
+			assert.True(t, allowed)
+		}
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		limiter := NewLimiter(client, "test:ratelimit", 100)
+		limiter.SetFailurePolicy(FailClosed, false)
+		allowed, err := limiter.Allow(ctx, "user-1")
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.False(t, allowed)
+
+		rr := httptest.NewRecorder()
+		limiter.Handler(func(r *http.Request) string { return "user-1" }, http.NotFoundHandler()).
+			ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+	})
+}
+
+func TestLimiter_Handler(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := NewLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test:ratelimit", 1)
+	handler := limiter.Handler(func(r *http.Request) string { return r.Header.Get("X-User") },
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		req.Header.Set("X-User", user)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	assert.Equal(t, http.StatusOK, serve("user-1").Code)
+	rr := serve("user-1")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	// Requests without a key are not limited
+	assert.Equal(t, http.StatusOK, serve("").Code)
+	assert.Equal(t, http.StatusOK, serve("").Code)
+}
+
+func TestValidatePolicy(t *testing.T) {
+	assert.NoError(t, ValidatePolicy(FailOpen))
+	assert.NoError(t, ValidatePolicy(FailClosed))
+	assert.Error(t, ValidatePolicy("maybe"))
+}
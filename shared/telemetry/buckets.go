@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// ParseHistogramBuckets parses bucket boundaries per histogram such as
+// "mcp.request.duration=1,5,10;a2a.task.duration=100,1000,10000"
+func ParseHistogramBuckets(value string) (map[string][]float64, error) {
+	buckets := make(map[string][]float64)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid histogram buckets %q, want name=boundary,...", entry)
+		}
+		var boundaries []float64
+		for _, field := range strings.Split(list, ",") {
+			boundary, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bucket boundary for %s: %w", name, err)
+			}
+			boundaries = append(boundaries, boundary)
+		}
+		buckets[name] = boundaries
+	}
+	return buckets, nil
+}
+
+// ValidateHistogramBuckets checks that the boundaries of each histogram are increasing
+func ValidateHistogramBuckets(buckets map[string][]float64) error {
+	for _, name := range slices.Sorted(maps.Keys(buckets)) {
+		boundaries := buckets[name]
+		if len(boundaries) == 0 {
+			return fmt.Errorf("histogram %s has no bucket boundaries", name)
+		}
+		for i := 1; i < len(boundaries); i++ {
+			if boundaries[i] <= boundaries[i-1] {
+				return fmt.Errorf("bucket boundaries of histogram %s must increase, got %v", name, boundaries)
+			}
+		}
+	}
+	return nil
+}
+
+// HistogramViews returns a view per histogram giving it explicit bucket boundaries, the
+// defaults overridden by buckets
+func HistogramViews(defaults, buckets map[string][]float64) []metric.View {
+	merged := maps.Clone(defaults)
+	if merged == nil {
+		merged = make(map[string][]float64)
+	}
+	maps.Copy(merged, buckets)
+
+	views := make([]metric.View, 0, len(merged))
+	for _, name := range slices.Sorted(maps.Keys(merged)) {
+		views = append(views, metric.NewView(
+			metric.Instrument{Name: name, Kind: metric.InstrumentKindHistogram},
+			metric.Stream{Aggregation: metric.AggregationExplicitBucketHistogram{Boundaries: merged[name]}},
+		))
+	}
+	return views
+}
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestParseHistogramBuckets(t *testing.T) {
+	buckets, err := ParseHistogramBuckets("mcp.request.duration=1, 5,10 ; mcp.db.query.duration=0.1,0.5;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]float64{
+		"mcp.request.duration":  {1, 5, 10},
+		"mcp.db.query.duration": {0.1, 0.5},
+	}, buckets)
+
+	_, err = ParseHistogramBuckets("mcp.request.duration")
+	assert.Error(t, err)
+	_, err = ParseHistogramBuckets("mcp.request.duration=1,fast")
+	assert.Error(t, err)
+}
+
+func TestValidateHistogramBuckets(t *testing.T) {
+	assert.NoError(t, ValidateHistogramBuckets(map[string][]float64{"mcp.request.duration": {1, 5}}))
+	assert.Error(t, ValidateHistogramBuckets(map[string][]float64{"mcp.request.duration": {5, 1}}))
+	assert.Error(t, ValidateHistogramBuckets(map[string][]float64{"mcp.request.duration": {}}))
+}
+
+func TestHistogramViews(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	defaults := map[string][]float64{
+		"a2a.request.duration": {1, 5, 10},
+		"a2a.task.duration":    {100, 1000},
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(HistogramViews(defaults, map[string][]float64{"a2a.request.duration": {1, 10}})...),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	)
+	meter := provider.Meter("test")
+	request, err := meter.Float64Histogram("a2a.request.duration")
+	require.NoError(t, err)
+	task, err := meter.Float64Histogram("a2a.task.duration")
+	require.NoError(t, err)
+
+	// A measurement made within a sampled span carries its trace as an exemplar
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	request.Record(ctx, 0.4)
+	task.Record(ctx, 200)
+
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+	histograms := make(map[string]metricdata.HistogramDataPoint[float64])
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if histogram, ok := m.Data.(metricdata.Histogram[float64]); ok && len(histogram.DataPoints) > 0 {
+				histograms[m.Name] = histogram.DataPoints[0]
+			}
+		}
+	}
+
+	requests := histograms["a2a.request.duration"]
+	assert.Equal(t, []float64{1, 10}, requests.Bounds, "configured buckets override the defaults")
+	assert.Equal(t, []uint64{1, 0, 0}, requests.BucketCounts)
+	require.Len(t, requests.Exemplars, 1)
+	assert.Equal(t, spanCtx.TraceID().String(), trace.TraceID(requests.Exemplars[0].TraceID).String())
+
+	tasks := histograms["a2a.task.duration"]
+	assert.Equal(t, []float64{100, 1000}, tasks.Bounds)
+	assert.Equal(t, uint64(1), tasks.BucketCounts[1], "200ms lands in the (100, 1000] bucket")
+}
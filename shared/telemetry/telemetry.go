@@ -0,0 +1,233 @@
+// Package telemetry sets up the OpenTelemetry tracer and meter providers both servers
+// export their traces and metrics with. Each server creates its own instruments from
+// Telemetry.Meter.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config holds the configuration for telemetry setup
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	OTLPEndpoint   string
+	SamplingRate   float64 // 0.0 to 1.0, default 1.0 (100%)
+	EnableTracing  bool
+	EnableMetrics  bool
+	// DefaultHistogramBuckets are the bucket boundaries of the server's duration
+	// histograms by name; HistogramBuckets overrides them
+	DefaultHistogramBuckets map[string][]float64
+	HistogramBuckets        map[string][]float64
+	// Exemplars attaches the trace and span IDs of sampled spans to histogram buckets
+	Exemplars bool
+}
+
+// Telemetry holds the OpenTelemetry providers and helpers
+type Telemetry struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *metric.MeterProvider
+	Tracer         trace.Tracer
+	// Meter creates the server's instruments; nil when metrics are disabled
+	Meter  otelmetric.Meter
+	config Config
+}
+
+// NewTelemetry initializes OpenTelemetry with tracing and metrics
+func NewTelemetry(ctx context.Context, cfg Config) (*Telemetry, error) {
+	// Set defaults
+	if cfg.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+	if cfg.ServiceVersion == "" {
+		cfg.ServiceVersion = "1.0.0"
+	}
+	if cfg.Environment == "" {
+		cfg.Environment = "development"
+	}
+	if cfg.SamplingRate == 0 {
+		cfg.SamplingRate = 1.0 // 100% by default
+	}
+	if cfg.OTLPEndpoint == "" {
+		cfg.OTLPEndpoint = "http://jaeger:4318" // HTTP endpoint for OTLP
+	}
+
+	// Create resource with service information
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(cfg.ServiceVersion),
+			semconv.DeploymentEnvironmentName(cfg.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	t := &Telemetry{
+		config: cfg,
+	}
+
+	// Initialize tracing
+	if cfg.EnableTracing {
+		if err := t.initTracing(ctx, res); err != nil {
+			return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+		}
+		slog.Info("OpenTelemetry tracing initialized",
+			"endpoint", cfg.OTLPEndpoint, "sampling_rate", cfg.SamplingRate)
+	}
+
+	// Initialize metrics
+	if cfg.EnableMetrics {
+		if err := t.initMetrics(res); err != nil {
+			return nil, fmt.Errorf("failed to initialize metrics: %w", err)
+		}
+		slog.Info("OpenTelemetry metrics initialized (Prometheus exporter)")
+	}
+
+	return t, nil
+}
+
+// initTracing sets up the trace provider with OTLP exporter
+func (t *Telemetry) initTracing(ctx context.Context, res *resource.Resource) error {
+	// Create OTLP HTTP exporter
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpoint(t.config.OTLPEndpoint),
+		otlptracehttp.WithInsecure(), // Use insecure for local development
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	// Create sampler based on configuration
+	sampler := sdktrace.ParentBased(
+		sdktrace.TraceIDRatioBased(t.config.SamplingRate),
+	)
+
+	// Create trace provider
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithBatchTimeout(5*time.Second),
+			sdktrace.WithMaxExportBatchSize(512),
+		),
+		sdktrace.WithResource(res),
+	)
+
+	// Set global trace provider
+	otel.SetTracerProvider(tp)
+
+	// Set global propagator for W3C Trace Context
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	t.TracerProvider = tp
+	t.Tracer = tp.Tracer(t.config.ServiceName)
+
+	return nil
+}
+
+// initMetrics sets up the meter provider with Prometheus exporter
+func (t *Telemetry) initMetrics(res *resource.Resource) error {
+	// Create Prometheus exporter
+	exporter, err := prometheus.New()
+	if err != nil {
+		return fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+
+	// Duration histograms get buckets for millisecond calls, and exemplars link their
+	// buckets to traces
+	if err := ValidateHistogramBuckets(t.config.HistogramBuckets); err != nil {
+		return err
+	}
+	exemplarFilter := exemplar.AlwaysOffFilter
+	if t.config.Exemplars {
+		exemplarFilter = exemplar.TraceBasedFilter
+	}
+
+	// Create meter provider
+	mp := metric.NewMeterProvider(
+		metric.WithResource(res),
+		metric.WithReader(exporter),
+		metric.WithView(HistogramViews(t.config.DefaultHistogramBuckets, t.config.HistogramBuckets)...),
+		metric.WithExemplarFilter(exemplarFilter),
+	)
+
+	// Set global meter provider
+	otel.SetMeterProvider(mp)
+
+	t.MeterProvider = mp
+	t.Meter = mp.Meter(t.config.ServiceName)
+
+	return nil
+}
+
+// CheckExporter connects to the OTLP endpoint to check that spans can be exported. The
+// exporter batches in the background, so an unreachable collector otherwise only shows
+// up as lost traces.
+func (t *Telemetry) CheckExporter(ctx context.Context) error {
+	addr := t.config.OTLPEndpoint
+	if strings.Contains(addr, "://") {
+		endpoint, err := url.Parse(addr)
+		if err != nil {
+			return fmt.Errorf("invalid OTLP endpoint: %w", err)
+		}
+		addr = endpoint.Host
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "4318")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("OTLP endpoint unreachable: %w", err)
+	}
+	return conn.Close()
+}
+
+// Shutdown gracefully shuts down the telemetry providers
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var err error
+
+	if t.TracerProvider != nil {
+		if shutdownErr := t.TracerProvider.Shutdown(ctx); shutdownErr != nil {
+			err = fmt.Errorf("failed to shutdown tracer provider: %w", shutdownErr)
+		}
+	}
+
+	if t.MeterProvider != nil {
+		if shutdownErr := t.MeterProvider.Shutdown(ctx); shutdownErr != nil {
+			if err != nil {
+				err = fmt.Errorf("%v; failed to shutdown meter provider: %w", err, shutdownErr)
+			} else {
+				err = fmt.Errorf("failed to shutdown meter provider: %w", shutdownErr)
+			}
+		}
+	}
+
+	return err
+}
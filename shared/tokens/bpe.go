@@ -0,0 +1,263 @@
+package tokens
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BPE is a byte pair encoding in the format of tiktoken's rank files, such as
+// cl100k_base.tiktoken. Text is split into pieces like tiktoken's cl100k pattern does
+// before the pieces are merged by rank.
+type BPE struct {
+	ranks map[string]int
+}
+
+// NewBPE creates an encoding from the rank of every mergeable byte sequence
+func NewBPE(ranks map[string]int) *BPE {
+	return &BPE{ranks: ranks}
+}
+
+// LoadBPE reads a tiktoken rank file: one base64 token and its rank per line
+func LoadBPE(r io.Reader) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		encoded, rankText, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a token and a rank", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(rankText)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("no tokens")
+	}
+	return NewBPE(ranks), nil
+}
+
+// LoadBPEFile reads a tiktoken rank file from path
+func LoadBPEFile(path string) (*BPE, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	encoding, err := LoadBPE(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return encoding, nil
+}
+
+// Count implements Encoder
+func (e *BPE) Count(text string) int {
+	count := 0
+	for _, piece := range pretokenize(text) {
+		count += len(e.encode(piece))
+	}
+	return count
+}
+
+// Truncate implements Encoder
+func (e *BPE) Truncate(text string, maxTokens int) string {
+	used, end := 0, 0
+	for _, piece := range pretokenize(text) {
+		parts := e.encode(piece)
+		if used+len(parts) > maxTokens {
+			for _, part := range parts[:max(maxTokens-used, 0)] {
+				end += len(part)
+			}
+			// A token can end inside a multi-byte character; the character is dropped
+			return strings.ToValidUTF8(text[:end], "")
+		}
+		used += len(parts)
+		end += len(piece)
+	}
+	return text
+}
+
+// encode splits a piece into its tokens by merging the adjacent byte pair of lowest rank
+// until no pair is mergeable
+func (e *BPE) encode(piece string) []string {
+	if _, ok := e.ranks[piece]; ok {
+		return []string{piece}
+	}
+	// bounds are the start offsets of the parts and the end of the piece
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := e.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+	}
+	parts := make([]string, len(bounds)-1)
+	for i := range parts {
+		parts[i] = piece[bounds[i]:bounds[i+1]]
+	}
+	return parts
+}
+
+// estimatedBPE estimates the BPE tokens of each piece without the rank file. Common words
+// are single tokens in OpenAI's encodings; longer and non-Latin words split into more.
+type estimatedBPE struct{}
+
+// lettersPerToken is the estimated length of the tokens a long ASCII word splits into
+const lettersPerToken = 8
+
+func (estimatedBPE) Count(text string) int {
+	count := 0
+	for _, piece := range pretokenize(text) {
+		count += estimatePiece(piece)
+	}
+	return count
+}
+
+func (estimatedBPE) Truncate(text string, maxTokens int) string {
+	used, end := 0, 0
+	for _, piece := range pretokenize(text) {
+		n := estimatePiece(piece)
+		if used+n > maxTokens {
+			// Keep the share of the piece's characters that fits
+			runes := []rune(piece)
+			keep := len(runes) * max(maxTokens-used, 0) / n
+			return text[:end] + string(runes[:keep])
+		}
+		used += n
+		end += len(piece)
+	}
+	return text
+}
+
+// estimatePiece estimates the tokens of one pre-tokenized piece
+func estimatePiece(piece string) int {
+	first, _ := utf8.DecodeRuneInString(piece)
+	switch {
+	case unicode.IsSpace(first) && strings.TrimSpace(piece) == "":
+		// Runs of spaces, as in indented code, are single tokens up to a length
+		return 1 + utf8.RuneCountInString(piece)/16
+	case unicode.IsNumber(first):
+		return 1
+	}
+
+	letters, other, symbols := 0, 0, 0
+	for _, r := range piece {
+		switch {
+		case r < utf8.RuneSelf && unicode.IsLetter(r):
+			letters++
+		case unicode.IsLetter(r):
+			other++
+		case !unicode.IsSpace(r):
+			symbols++
+		}
+	}
+	if letters+other == 0 {
+		// Punctuation runs such as "));" or "..." merge in pairs and triples
+		return max((symbols+1)/2, 1)
+	}
+	// Non-ASCII letters, e.g. of CJK scripts, are often a token each
+	if letters == 0 {
+		return other
+	}
+	return 1 + (letters-1)/lettersPerToken + other
+}
+
+// contractions are the English suffixes the cl100k pattern splits off, after the apostrophe
+var contractions = []string{"s", "t", "re", "ve", "m", "ll", "d"}
+
+// pretokenize splits text like tiktoken's cl100k_base pattern:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func pretokenize(text string) []string {
+	var pieces []string
+	runes := []rune(text)
+	isNewline := func(r rune) bool { return r == '\r' || r == '\n' }
+	isOther := func(r rune) bool { return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r) }
+	// run returns the end of the run of runes matching f from i, at most limit long
+	run := func(i, limit int, f func(rune) bool) int {
+		j := i
+		for j < len(runes) && j-i < limit && f(runes[j]) {
+			j++
+		}
+		return j
+	}
+
+	for i := 0; i < len(runes); {
+		end := i
+		r := runes[i]
+		switch {
+		case r == '\'' && contraction(runes[i+1:]) > 0:
+			end = i + 1 + contraction(runes[i+1:])
+		case unicode.IsLetter(r):
+			end = run(i, len(runes), unicode.IsLetter)
+		case !isNewline(r) && !unicode.IsNumber(r) && i+1 < len(runes) && unicode.IsLetter(runes[i+1]):
+			end = run(i+1, len(runes), unicode.IsLetter)
+		case unicode.IsNumber(r):
+			end = run(i, 3, unicode.IsNumber)
+		case isOther(r) || (r == ' ' && i+1 < len(runes) && isOther(runes[i+1])):
+			start := i
+			if r == ' ' {
+				start++
+			}
+			end = run(run(start, len(runes), isOther), len(runes), isNewline)
+		default:
+			// Whitespace: up to its last newline, else all but the space before a word
+			spaces := run(i, len(runes), unicode.IsSpace)
+			for j := spaces - 1; j >= i; j-- {
+				if isNewline(runes[j]) {
+					end = j + 1
+					break
+				}
+			}
+			if end == i {
+				end = spaces
+				if spaces < len(runes) && spaces-i > 1 {
+					end--
+				}
+			}
+		}
+		pieces = append(pieces, string(runes[i:end]))
+		i = end
+	}
+	return pieces
+}
+
+// contraction returns the length of the contraction suffix at the start of runes, or 0
+func contraction(runes []rune) int {
+	for _, suffix := range contractions {
+		if len(runes) >= len(suffix) && strings.EqualFold(string(runes[:len(suffix)]), suffix) {
+			return len(suffix)
+		}
+	}
+	return 0
+}
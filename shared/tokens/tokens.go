@@ -0,0 +1,150 @@
+// Package tokens estimates how many tokens text takes for a model and budgets context
+// windows. OpenAI models are counted with a tiktoken-style byte pair encoder: exactly when
+// the encoding's rank file is loaded with LoadBPEFile, otherwise by estimating the BPE
+// tokens of each pre-tokenized piece. Other models are counted with a characters-per-token
+// heuristic.
+package tokens
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Encoder counts and truncates the tokens of text for one tokenizer
+type Encoder interface {
+	// Count returns the number of tokens of text
+	Count(text string) int
+	// Truncate returns the longest prefix of text of at most maxTokens tokens
+	Truncate(text string, maxTokens int) string
+}
+
+// bytesPerToken is the heuristic's average; English text is about four bytes per token
+const bytesPerToken = 4
+
+// bpe is the loaded OpenAI encoding, if any
+var bpe atomic.Pointer[BPE]
+
+// SetBPE makes OpenAI models count tokens exactly with encoding; nil restores the estimate
+func SetBPE(encoding *BPE) {
+	bpe.Store(encoding)
+}
+
+// openAIPrefixes are the model name prefixes tokenized with OpenAI's BPE encodings
+var openAIPrefixes = []string{"gpt-", "o1", "o3", "o4", "text-embedding-", "chatgpt-"}
+
+// IsOpenAI reports whether model is tokenized with an OpenAI BPE encoding
+func IsOpenAI(model string) bool {
+	model = strings.ToLower(model)
+	for _, prefix := range openAIPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the encoder of model
+func For(model string) Encoder {
+	if !IsOpenAI(model) {
+		return heuristic{}
+	}
+	if encoding := bpe.Load(); encoding != nil {
+		return encoding
+	}
+	return estimatedBPE{}
+}
+
+// Count returns the number of tokens text takes for model
+func Count(model, text string) int {
+	return For(model).Count(text)
+}
+
+// Truncate returns the longest prefix of text of at most maxTokens tokens for model
+func Truncate(model, text string, maxTokens int) string {
+	return For(model).Truncate(text, maxTokens)
+}
+
+// DefaultContextWindow is the context window assumed for unknown models
+const DefaultContextWindow = 8192
+
+// contextWindows are the context windows in tokens by model name prefix, most specific first
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1047576},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"claude", 200000},
+	{"llama3", 128000},
+	{"mistral", 32768},
+}
+
+// ContextWindow returns the number of tokens model reads and writes per request
+func ContextWindow(model string) int {
+	model = strings.ToLower(model)
+	for _, window := range contextWindows {
+		if strings.HasPrefix(model, window.prefix) {
+			return window.tokens
+		}
+	}
+	return DefaultContextWindow
+}
+
+// Budget hands out a fixed number of tokens to the parts of a prompt
+type Budget struct {
+	encoder   Encoder
+	remaining int
+}
+
+// NewBudget creates a budget of limit tokens counted for model
+func NewBudget(model string, limit int) *Budget {
+	return &Budget{encoder: For(model), remaining: max(limit, 0)}
+}
+
+// Remaining returns the tokens left in the budget
+func (b *Budget) Remaining() int {
+	return b.remaining
+}
+
+// Take spends the tokens of text, truncated to at most limit tokens and to what remains
+// of the budget; limit <= 0 means no per-text limit. It returns the text to use and false
+// when none of it fit.
+func (b *Budget) Take(text string, limit int) (string, bool) {
+	if limit <= 0 || limit > b.remaining {
+		limit = b.remaining
+	}
+	if limit == 0 {
+		return "", false
+	}
+	count := b.encoder.Count(text)
+	if count > limit {
+		text = b.encoder.Truncate(text, limit)
+		count = b.encoder.Count(text)
+	}
+	b.remaining -= count
+	return text, text != ""
+}
+
+// heuristic counts tokens at bytesPerToken bytes per token
+type heuristic struct{}
+
+func (heuristic) Count(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+func (heuristic) Truncate(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if n := maxTokens * bytesPerToken; len(text) > n {
+		return strings.ToValidUTF8(text[:n], "")
+	}
+	return text
+}
@@ -0,0 +1,103 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPretokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"I'm 12345 ok!!\n\n  x", []string{"I", "'m", " ", "123", "45", " ok", "!!\n\n", " ", " x"}},
+		{"func main() {\n\treturn\n}", []string{"func", " main", "()", " {\n", "\treturn", "\n", "}"}},
+		{"trailing  ", []string{"trailing", "  "}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, pretokenize(tt.text), tt.text)
+		assert.Equal(t, tt.text, strings.Join(pretokenize(tt.text), ""), "pieces cover the text")
+	}
+}
+
+// testRanks is a small encoding in the tiktoken rank file format
+const testRanks = `YQ== 0
+Yg== 1
+Yw== 2
+IA== 3
+YWI= 4
+IGE= 5
+IGFi 6
+YWJj 7
+`
+
+func TestBPE(t *testing.T) {
+	encoding, err := LoadBPE(strings.NewReader(testRanks))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"abc"}, encoding.encode("abc"))
+	assert.Equal(t, []string{" ab", "c"}, encoding.encode(" abc"), "the lowest-ranked pair merges first")
+	assert.Equal(t, []string{"ab", "abc"}, encoding.encode("ababc"))
+	assert.Equal(t, 3, encoding.Count("abc abc"))
+	assert.Equal(t, "abc ab", encoding.Truncate("abc abc", 2))
+	assert.Equal(t, "abc abc", encoding.Truncate("abc abc", 3))
+
+	_, err = LoadBPE(strings.NewReader("YQ==\n"))
+	assert.Error(t, err)
+	_, err = LoadBPE(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestCount(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog."
+	assert.Equal(t, 10, Count("gpt-4o", text), "one token per common word and the period")
+	assert.Equal(t, (len(text)+3)/4, Count("claude-3-5-haiku-latest", text))
+	assert.Zero(t, Count("gpt-4", ""))
+	assert.Greater(t, Count("gpt-4", "internationalization"), 1)
+	assert.Equal(t, 4, Count("gpt-4", "日本語だ"), "a token per CJK character")
+
+	encoding, err := LoadBPE(strings.NewReader(testRanks))
+	require.NoError(t, err)
+	SetBPE(encoding)
+	defer SetBPE(nil)
+	assert.Equal(t, 3, Count("gpt-3.5-turbo", "abc abc"))
+	assert.Equal(t, 2, Count("llama3.1", "abc abc"), "other models keep the heuristic")
+}
+
+func TestTruncate(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog."
+	assert.Equal(t, "The quick brown", Truncate("gpt-4o", text, 3))
+	assert.Equal(t, text, Truncate("gpt-4o", text, 100))
+	assert.Equal(t, "The quick br", Truncate("claude-3-opus", text, 3))
+	assert.Equal(t, "日", Truncate("claude-3-opus", "日本語", 1), "characters are not split")
+	assert.Empty(t, Truncate("gpt-4o", text, 0))
+}
+
+func TestContextWindow(t *testing.T) {
+	assert.Equal(t, 128000, ContextWindow("gpt-4o-mini"))
+	assert.Equal(t, 8192, ContextWindow("gpt-4-0613"))
+	assert.Equal(t, 128000, ContextWindow("gpt-4-turbo"))
+	assert.Equal(t, 200000, ContextWindow("claude-3-5-haiku-latest"))
+	assert.Equal(t, DefaultContextWindow, ContextWindow("unknown"))
+}
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget("gpt-4o", 6)
+
+	text, ok := budget.Take("The quick brown fox", 3)
+	assert.True(t, ok)
+	assert.Equal(t, "The quick brown", text, "truncated to the per-text limit")
+	assert.Equal(t, 3, budget.Remaining())
+
+	text, ok = budget.Take("jumps over the lazy dog", 0)
+	assert.True(t, ok)
+	assert.Equal(t, "jumps over the", text, "truncated to the remaining budget")
+	assert.Zero(t, budget.Remaining())
+
+	_, ok = budget.Take("dog", 0)
+	assert.False(t, ok)
+}
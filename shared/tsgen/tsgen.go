@@ -0,0 +1,327 @@
+// Package tsgen generates TypeScript declarations from Go types so JavaScript clients
+// stay in lockstep with the JSON wire format. Types are read with reflection and field
+// names and optionality follow the encoding/json struct tags.
+package tsgen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generator collects Go types and constants and writes them as TypeScript
+type Generator struct {
+	names  map[reflect.Type]string
+	enums  map[reflect.Type][]interface{}
+	types  []reflect.Type // declaration order: added types, then their dependencies
+	queued map[reflect.Type]bool
+	consts []tsConst
+}
+
+type tsConst struct {
+	name  string
+	value interface{}
+}
+
+// New creates an empty generator
+func New() *Generator {
+	return &Generator{
+		names:  make(map[reflect.Type]string),
+		enums:  make(map[reflect.Type][]interface{}),
+		queued: make(map[reflect.Type]bool),
+	}
+}
+
+// Add declares the types of the given values, e.g. Add(protocol.Task{}). Named types
+// they reference are declared too.
+func (g *Generator) Add(values ...interface{}) {
+	for _, value := range values {
+		g.queue(indirect(reflect.TypeOf(value)))
+	}
+}
+
+// Name declares the type of value under a different TypeScript name, for Go names
+// that are ambiguous or clash with TypeScript globals such as Error
+func (g *Generator) Name(value interface{}, name string) {
+	t := indirect(reflect.TypeOf(value))
+	g.names[t] = name
+	g.queue(t)
+}
+
+// Enum declares a named Go type as a union of its allowed values,
+// e.g. Enum(TaskStatePending, TaskStateRunning)
+func (g *Generator) Enum(members ...interface{}) {
+	if len(members) == 0 {
+		return
+	}
+	t := reflect.TypeOf(members[0])
+	g.enums[t] = append(g.enums[t], members...)
+	g.queue(t)
+}
+
+// Const declares an exported constant, e.g. a method name or error code
+func (g *Generator) Const(name string, value interface{}) {
+	g.consts = append(g.consts, tsConst{name: name, value: value})
+}
+
+// queue schedules a named type for declaration
+func (g *Generator) queue(t reflect.Type) {
+	if g.queued[t] {
+		return
+	}
+	g.queued[t] = true
+	g.types = append(g.types, t)
+}
+
+// Write writes the declarations, preceded by header as a line comment
+func (g *Generator) Write(w io.Writer, header string) error {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(header, "\n") {
+		fmt.Fprintf(&buf, "// %s\n", line)
+	}
+
+	// TypeScript has one namespace for constants and types here, so names must be unique
+	declared := make(map[string]string)
+	if len(g.consts) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, c := range g.consts {
+		if other, ok := declared[c.name]; ok {
+			return fmt.Errorf("const %s is declared twice (%s)", c.name, other)
+		}
+		declared[c.name] = "const"
+		value, err := json.Marshal(c.value)
+		if err != nil {
+			return fmt.Errorf("const %s: %w", c.name, err)
+		}
+		fmt.Fprintf(&buf, "export const %s = %s;\n", c.name, value)
+	}
+
+	// Declaring a type can queue the types it references, so g.types grows while we loop
+	for i := 0; i < len(g.types); i++ {
+		t := g.types[i]
+		name := g.typeName(t)
+		if other, ok := declared[name]; ok {
+			return fmt.Errorf("%s clashes with %s named %s", t, other, name)
+		}
+		declared[name] = t.String()
+
+		buf.WriteString("\n")
+		if err := g.declare(&buf, t, name); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// declare writes the declaration of a named type
+func (g *Generator) declare(buf *bytes.Buffer, t reflect.Type, name string) error {
+	if members, ok := g.enums[t]; ok {
+		values := make([]string, len(members))
+		for i, member := range members {
+			value, err := json.Marshal(member)
+			if err != nil {
+				return fmt.Errorf("enum %s: %w", name, err)
+			}
+			values[i] = string(value)
+		}
+		fmt.Fprintf(buf, "export type %s = %s;\n", name, strings.Join(values, " | "))
+		return nil
+	}
+
+	if t.Kind() != reflect.Struct || isOpaque(t) {
+		ts, err := g.render(t)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t, err)
+		}
+		fmt.Fprintf(buf, "export type %s = %s;\n", name, ts)
+		return nil
+	}
+
+	fields, err := g.fields(t, "  ")
+	if err != nil {
+		return fmt.Errorf("%s: %w", t, err)
+	}
+	fmt.Fprintf(buf, "export interface %s {\n%s}\n", name, fields)
+	return nil
+}
+
+// fields renders the JSON fields of a struct, one per line
+func (g *Generator) fields(t reflect.Type, indent string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs are flattened into the parent, as encoding/json does
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			embedded, err := g.fields(indirect(field.Type), indent)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(embedded)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		optional := hasOption(opts, "omitempty")
+		var ts string
+		if hasOption(opts, "string") {
+			ts = "string"
+		} else {
+			var err error
+			if ts, err = g.tsType(field.Type, !optional); err != nil {
+				return "", fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+
+		marker := ""
+		if optional {
+			marker = "?"
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", indent, propertyName(name), marker, ts)
+	}
+	return b.String(), nil
+}
+
+// tsType returns the TypeScript type of a field or element. nullable reports whether
+// a nil pointer can reach the encoder, which writes it as null.
+func (g *Generator) tsType(t reflect.Type, nullable bool) (string, error) {
+	if t.Kind() == reflect.Pointer {
+		elem, err := g.tsType(t.Elem(), false)
+		if err != nil || !nullable {
+			return elem, err
+		}
+		return elem + " | null", nil
+	}
+
+	if t == timeType {
+		return "string", nil
+	}
+	if t.Name() != "" && t.PkgPath() != "" && !isOpaque(t) && t.Kind() != reflect.Interface {
+		g.queue(t)
+		return g.typeName(t), nil
+	}
+	return g.render(t)
+}
+
+// render returns the structure of a type as TypeScript, without using its name
+func (g *Generator) render(t reflect.Type) (string, error) {
+	switch {
+	case t == timeType:
+		return "string", nil
+	case implements(t, jsonMarshalerType):
+		return "unknown", nil
+	case implements(t, textMarshalerType):
+		return "string", nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number", nil
+	case reflect.Interface:
+		return "unknown", nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "string", nil // base64
+		}
+		elem, err := g.tsType(t.Elem(), true)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]", nil
+	case reflect.Map:
+		value, err := g.tsType(t.Elem(), true)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Record<string, %s>", value), nil
+	case reflect.Struct:
+		fields, err := g.fields(t, "")
+		if err != nil {
+			return "", err
+		}
+		return "{ " + strings.ReplaceAll(strings.TrimSuffix(fields, "\n"), "\n", " ") + " }", nil
+	default:
+		return "", fmt.Errorf("unsupported kind %s", t.Kind())
+	}
+}
+
+// typeName returns the TypeScript name of a named Go type
+func (g *Generator) typeName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	return t.Name()
+}
+
+// isOpaque reports whether a type encodes itself, so its Go fields say nothing about its JSON
+func isOpaque(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	return implements(t, jsonMarshalerType) || implements(t, textMarshalerType)
+}
+
+// implements reports whether t or *t implements iface
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyName quotes JSON names that are not valid TypeScript identifiers
+func propertyName(name string) string {
+	for i, r := range name {
+		valid := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')
+		if !valid {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
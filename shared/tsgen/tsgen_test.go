@@ -0,0 +1,102 @@
+package tsgen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type state string
+
+const (
+	stateOpen   state = "open"
+	stateClosed state = "closed"
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type item struct {
+	base
+	State    state                  `json:"state"`
+	Parent   *item                  `json:"parent"`
+	Child    *item                  `json:"child,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
+	Scores   map[string]float64     `json:"scores"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+	Raw      json.RawMessage        `json:"raw,omitempty"`
+	Blob     []byte                 `json:"blob"`
+	At       time.Time              `json:"at"`
+	Count    int64                  `json:"count,string"`
+	Point    struct{ X, Y int }     `json:"point"`
+	Ignored  string                 `json:"-"`
+	internal string
+	Untagged bool
+	Dashed   string `json:"content-type"`
+}
+
+func generate(t *testing.T, build func(g *Generator)) string {
+	t.Helper()
+	g := New()
+	build(g)
+	var b strings.Builder
+	require.NoError(t, g.Write(&b, "Generated for tests."))
+	return b.String()
+}
+
+func TestGenerator_Struct(t *testing.T) {
+	out := generate(t, func(g *Generator) {
+		g.Enum(stateOpen, stateClosed)
+		g.Add(item{})
+	})
+
+	assert.Equal(t, `// Generated for tests.
+
+export type state = "open" | "closed";
+
+export interface item {
+  id: string;
+  state: state;
+  parent: item | null;
+  child?: item;
+  tags?: string[];
+  scores: Record<string, number>;
+  extra?: Record<string, unknown>;
+  raw?: unknown;
+  blob: string;
+  at: string;
+  count: string;
+  point: { X: number; Y: number; };
+  Untagged: boolean;
+  "content-type": string;
+}
+`, out)
+}
+
+func TestGenerator_ConstsAndNames(t *testing.T) {
+	out := generate(t, func(g *Generator) {
+		g.Const("Version", "2.0")
+		g.Const("NotFound", -32001)
+		g.Name(base{}, "Base")
+	})
+
+	assert.Contains(t, out, "export const Version = \"2.0\";\nexport const NotFound = -32001;\n")
+	assert.Contains(t, out, "export interface Base {\n  id: string;\n}\n")
+}
+
+func TestGenerator_DependenciesAndClashes(t *testing.T) {
+	// Referenced named types are declared after the types that were added
+	out := generate(t, func(g *Generator) { g.Add(item{}) })
+	assert.Contains(t, out, "export type state = string;\n")
+	assert.Less(t, strings.Index(out, "interface item"), strings.Index(out, "type state"))
+
+	g := New()
+	g.Const("item", 1)
+	g.Add(item{})
+	assert.Error(t, g.Write(&strings.Builder{}, ""))
+}
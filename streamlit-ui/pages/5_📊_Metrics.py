@@ -284,8 +284,8 @@ with col1:
 with col2:
     st.subheader("Connection Pool")
 
-    active_conn = query_prometheus('mcp_db_connection_pool_active')
-    idle_conn = query_prometheus('mcp_db_connection_pool_idle')
+    active_conn = query_prometheus('sum(mcp_db_connection_pool_active{db_system="postgresql"})')
+    idle_conn = query_prometheus('sum(mcp_db_connection_pool_idle{db_system="postgresql"})')
 
     if active_conn and idle_conn:
         active = int(float(active_conn[0]['value'][1]))
@@ -312,8 +312,10 @@ with st.expander("MCP Server Metrics"):
     ### Database Metrics
     - `mcp_db_query_duration` - Database query duration histogram (milliseconds)
     - `mcp_db_query_count` - Database query count (labels: query_type, status)
-    - `mcp_db_connection_pool_active` - Active database connections (gauge)
-    - `mcp_db_connection_pool_idle` - Idle database connections (gauge)
+    - `mcp_db_connection_pool_active` - Connections in use (gauge, labels: db_system, db_pool, db_region)
+    - `mcp_db_connection_pool_idle` - Idle connections (gauge, labels: db_system, db_pool, db_region)
+    - `mcp_db_connection_pool_max` - Pool size limit (gauge)
+    - `mcp_db_connection_pool_timeouts_total` - Acquires that timed out on an exhausted pool
 
     ### Search Metrics
     - `mcp_search_results` - Number of search results histogram